@@ -0,0 +1,417 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/bench"
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/storage"
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
+)
+
+// Bench scenarios.
+const (
+	benchScenarioHibernation = "hibernation"
+	benchScenarioChunkSizes  = "chunk-sizes"
+	benchScenarioWorkers     = "workers"
+)
+
+// benchHeapSampleInterval is how often the live heap is sampled during a run.
+const benchHeapSampleInterval = 20 * time.Millisecond
+
+const bytesPerMB = 1 << 20
+
+// ErrUnknownBenchScenario is returned for --scenario values other than the
+// known scenarios.
+var ErrUnknownBenchScenario = errors.New("unknown bench scenario")
+
+// BenchCommand holds the flags of the bench command.
+type BenchCommand struct {
+	path        string
+	analyzerIDs []string
+	limit       int
+	scenarios   []string
+	chunkSize   int
+	chunkSizes  []int
+	workers     []int
+	format      string
+	output      string
+	baseline    string
+	threshold   float64
+	profileDir  string
+}
+
+// NewBenchCommand creates the bench command, which times the history pipeline
+// on a repository and compares the results against a saved baseline.
+func NewBenchCommand() *cobra.Command {
+	bc := &BenchCommand{}
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark the history pipeline on a repository",
+		Long: `Run the history pipeline over the last --limit first-parent commits of a
+repository once per scenario variant and report duration, throughput and
+peak heap of each run.
+
+Scenarios:
+  hibernation  one run at --chunk-size, measuring heap freed by Hibernate
+  chunk-sizes  one run per --chunk-sizes value
+  workers      one run per --workers value, at --chunk-size
+
+Save a baseline with --format json --output base.json, then compare later runs
+against it with --baseline base.json. The command fails when any metric is worse
+than the baseline by more than --threshold percent.`,
+		RunE: func(cobraCmd *cobra.Command, _ []string) error {
+			return bc.run(cobraCmd.Context(), cobraCmd.OutOrStdout(), cobraCmd.ErrOrStderr())
+		},
+	}
+
+	cmd.Flags().StringVarP(&bc.path, "path", "p", ".", "Repository to benchmark")
+	cmd.Flags().StringSliceVarP(&bc.analyzerIDs, "analyzers", "a", []string{"history/file-history"},
+		"History analyzer IDs or glob patterns to run")
+	cmd.Flags().IntVar(&bc.limit, "limit", 1000, "Number of most recent commits to process (0 = all)")
+	cmd.Flags().StringSliceVar(&bc.scenarios, "scenario",
+		[]string{benchScenarioHibernation, benchScenarioChunkSizes, benchScenarioWorkers},
+		"Scenarios to run: hibernation, chunk-sizes, workers")
+	cmd.Flags().IntVar(&bc.chunkSize, "chunk-size", 500, "Commits per chunk for the hibernation and workers scenarios")
+	cmd.Flags().IntSliceVar(&bc.chunkSizes, "chunk-sizes", []int{100, 500, 2000}, "Chunk sizes for the chunk-sizes scenario")
+	cmd.Flags().IntSliceVar(&bc.workers, "workers", []int{1, 2, 4}, "Worker counts for the workers scenario")
+	cmd.Flags().StringVar(&bc.format, "format", "text", "Output format: text or json")
+	cmd.Flags().StringVarP(&bc.output, "output", "o", "", "Write results to this file instead of stdout")
+	cmd.Flags().StringVar(&bc.baseline, "baseline", "", "Compare results against a report saved with --format json")
+	cmd.Flags().Float64Var(&bc.threshold, "threshold", 10, "Percent a metric may worsen against --baseline before failing")
+	cmd.Flags().StringVar(&bc.profileDir, "profile-dir", "",
+		"Write heap profiles around each Hibernate of the hibernation scenario to this directory")
+
+	return cmd
+}
+
+// benchVariant is one pipeline run of a scenario.
+type benchVariant struct {
+	scenario  string
+	variant   string
+	chunkSize int
+	workers   int
+}
+
+func (bc *BenchCommand) variants() ([]benchVariant, error) {
+	var variants []benchVariant
+
+	for _, scenario := range bc.scenarios {
+		switch scenario {
+		case benchScenarioHibernation:
+			variants = append(variants, benchVariant{
+				scenario: scenario, variant: strconv.Itoa(bc.chunkSize), chunkSize: bc.chunkSize,
+			})
+		case benchScenarioChunkSizes:
+			for _, size := range bc.chunkSizes {
+				variants = append(variants, benchVariant{scenario: scenario, variant: strconv.Itoa(size), chunkSize: size})
+			}
+		case benchScenarioWorkers:
+			for _, workers := range bc.workers {
+				variants = append(variants, benchVariant{
+					scenario: scenario, variant: strconv.Itoa(workers), chunkSize: bc.chunkSize, workers: workers,
+				})
+			}
+		default:
+			return nil, fmt.Errorf("%w: %q (want %s, %s or %s)", ErrUnknownBenchScenario, scenario,
+				benchScenarioHibernation, benchScenarioChunkSizes, benchScenarioWorkers)
+		}
+	}
+
+	return variants, nil
+}
+
+func (bc *BenchCommand) run(ctx context.Context, stdout, stderr io.Writer) error {
+	if bc.format != analyze.FormatText && bc.format != analyze.FormatJSON {
+		return fmt.Errorf("%w: %s", analyze.ErrUnsupportedFormat, bc.format)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	variants, err := bc.variants()
+	if err != nil {
+		return err
+	}
+
+	var baseline bench.Report
+
+	if bc.baseline != "" {
+		baseline, err = bench.ReadReport(bc.baseline)
+		if err != nil {
+			return err
+		}
+	}
+
+	keys, err := historyKeys(bc.analyzerIDs)
+	if err != nil {
+		return err
+	}
+
+	repository, err := gitlib.LoadRepository(bc.path)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrRepositoryLoad, bc.path)
+	}
+	defer repository.Free()
+
+	commits, err := gitlib.LoadCommits(ctx, repository, gitlib.CommitLoadOptions{Limit: bc.limit, FirstParent: true})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		for _, c := range commits {
+			c.Free()
+		}
+	}()
+
+	report := bench.Report{
+		Version:   bench.ReportVersion,
+		Repo:      bc.path,
+		Analyzers: keys,
+		Commits:   len(commits),
+		GoVersion: runtime.Version(),
+		CPUs:      runtime.NumCPU(),
+	}
+
+	for _, v := range variants {
+		fmt.Fprintf(stderr, "bench: %s %s (%d commits)\n", v.scenario, v.variant, len(commits))
+
+		result, runErr := bc.runVariant(ctx, repository, keys, commits, v)
+		if runErr != nil {
+			return fmt.Errorf("%s %s: %w", v.scenario, v.variant, runErr)
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	var regressions []bench.Regression
+
+	if bc.baseline != "" {
+		regressions = bench.Compare(baseline, report, bc.threshold)
+	}
+
+	err = bc.writeReport(stdout, report, regressions)
+	if err != nil {
+		return err
+	}
+
+	if len(regressions) > 0 {
+		return fmt.Errorf("%w: %d metric(s) worse than %s by more than %g%%",
+			bench.ErrRegression, len(regressions), bc.baseline, bc.threshold)
+	}
+
+	return nil
+}
+
+func (bc *BenchCommand) writeReport(stdout io.Writer, report bench.Report, regressions []bench.Regression) error {
+	if bc.output == "" {
+		return bc.encodeReport(stdout, report, regressions)
+	}
+
+	f, err := storage.Create(bc.output)
+	if err != nil {
+		return fmt.Errorf("create bench output: %w", err)
+	}
+
+	err = bc.encodeReport(f, report, regressions)
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+func (bc *BenchCommand) encodeReport(w io.Writer, report bench.Report, regressions []bench.Regression) error {
+	if bc.format == analyze.FormatJSON {
+		return bench.WriteJSON(w, report)
+	}
+
+	return bench.WriteText(w, report, regressions)
+}
+
+// runVariant runs a fresh pipeline over commits in chunks of v.chunkSize,
+// hibernating and booting the analyzers between chunks as streaming does.
+func (bc *BenchCommand) runVariant(
+	ctx context.Context, repository *gitlib.Repository, keys []string, commits []*gitlib.Commit, v benchVariant,
+) (bench.Result, error) {
+	pl := buildPipeline(repository)
+
+	leaves, err := configureAndSelect(pl, keys)
+	if err != nil {
+		return bench.Result{}, err
+	}
+
+	config := framework.DefaultCoordinatorConfig()
+	config.FirstParent = true
+
+	if v.workers > 0 {
+		config.Workers = v.workers
+	}
+
+	analyzers := append(append([]analyze.HistoryAnalyzer{}, pl.Core...), leaves...)
+
+	runner := framework.NewRunnerWithConfig(repository, bc.path, config, analyzers...)
+	runner.CoreCount = len(pl.Core)
+
+	var hibernatables []streaming.Hibernatable
+
+	for _, a := range analyzers {
+		if h, ok := a.(streaming.Hibernatable); ok {
+			hibernatables = append(hibernatables, h)
+		}
+	}
+
+	measureHeap := v.scenario == benchScenarioHibernation
+
+	var hibernateTime time.Duration
+
+	var freed, hibernations float64
+
+	runtime.GC()
+
+	sampler := bench.StartHeapSampler(benchHeapSampleInterval)
+	start := time.Now()
+
+	err = runner.Initialize()
+	if err != nil {
+		sampler.Stop()
+
+		return bench.Result{}, err
+	}
+
+	chunkSize := max(v.chunkSize, 1)
+
+	for offset, chunk := 0, 0; offset < len(commits); offset, chunk = offset+chunkSize, chunk+1 {
+		if chunk > 0 {
+			var before uint64
+
+			if measureHeap {
+				before = bc.heapInUse(fmt.Sprintf("heap_chunk_%d_before_hibernate.prof", chunk))
+			}
+
+			hibernateStart := time.Now()
+
+			err = hibernateAndBoot(hibernatables)
+			if err != nil {
+				sampler.Stop()
+
+				return bench.Result{}, err
+			}
+
+			hibernateTime += time.Since(hibernateStart)
+			hibernations++
+
+			if measureHeap {
+				after := bc.heapInUse(fmt.Sprintf("heap_chunk_%d_after_hibernate.prof", chunk))
+				freed += (float64(before) - float64(after)) / bytesPerMB
+			}
+		}
+
+		end := min(offset+chunkSize, len(commits))
+
+		_, err = runner.ProcessChunk(ctx, commits[offset:end], offset, chunk)
+		if err != nil {
+			sampler.Stop()
+
+			return bench.Result{}, err
+		}
+	}
+
+	_, err = runner.FinalizeWithAggregators(ctx)
+	elapsed := time.Since(start)
+	peak := sampler.Stop()
+
+	if err != nil {
+		return bench.Result{}, err
+	}
+
+	metrics := map[string]float64{
+		bench.MetricDurationMS:    float64(elapsed.Milliseconds()),
+		bench.MetricCommitsPerSec: float64(len(commits)) / elapsed.Seconds(),
+		bench.MetricPeakHeapMB:    float64(peak) / bytesPerMB,
+	}
+
+	if measureHeap && hibernations > 0 {
+		metrics[bench.MetricHibernateMS] = float64(hibernateTime.Milliseconds()) / hibernations
+		metrics[bench.MetricHibernateFreedMB] = freed / hibernations
+	}
+
+	return bench.Result{Scenario: v.scenario, Variant: v.variant, Metrics: metrics}, nil
+}
+
+// hibernateAndBoot hibernates every analyzer, then boots them all again.
+func hibernateAndBoot(hibernatables []streaming.Hibernatable) error {
+	for _, h := range hibernatables {
+		err := h.Hibernate()
+		if err != nil {
+			return fmt.Errorf("hibernate: %w", err)
+		}
+	}
+
+	for _, h := range hibernatables {
+		err := h.Boot()
+		if err != nil {
+			return fmt.Errorf("boot: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// heapInUse collects garbage and returns the in-use heap bytes, writing a heap
+// profile named name to --profile-dir when set.
+func (bc *BenchCommand) heapInUse(name string) uint64 {
+	runtime.GC()
+
+	if bc.profileDir != "" {
+		bc.writeHeapProfile(name)
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	return m.HeapInuse
+}
+
+func (bc *BenchCommand) writeHeapProfile(name string) {
+	path := filepath.Join(bc.profileDir, name)
+
+	err := os.MkdirAll(bc.profileDir, 0o750)
+	if err != nil {
+		slog.Default().Warn("bench: heap profile skipped", "path", path, "error", err)
+
+		return
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		slog.Default().Warn("bench: heap profile skipped", "path", path, "error", err)
+
+		return
+	}
+	defer f.Close()
+
+	err = pprof.WriteHeapProfile(f)
+	if err != nil {
+		slog.Default().Warn("bench: heap profile skipped", "path", path, "error", err)
+	}
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchCommand_Variants(t *testing.T) {
+	t.Parallel()
+
+	bc := &BenchCommand{
+		scenarios:  []string{benchScenarioHibernation, benchScenarioChunkSizes, benchScenarioWorkers},
+		chunkSize:  500,
+		chunkSizes: []int{100, 1000},
+		workers:    []int{2},
+	}
+
+	variants, err := bc.variants()
+	require.NoError(t, err)
+	assert.Equal(t, []benchVariant{
+		{scenario: benchScenarioHibernation, variant: "500", chunkSize: 500},
+		{scenario: benchScenarioChunkSizes, variant: "100", chunkSize: 100},
+		{scenario: benchScenarioChunkSizes, variant: "1000", chunkSize: 1000},
+		{scenario: benchScenarioWorkers, variant: "2", chunkSize: 500, workers: 2},
+	}, variants)
+}
+
+func TestBenchCommand_UnknownScenario(t *testing.T) {
+	t.Parallel()
+
+	bc := &BenchCommand{scenarios: []string{"latency"}}
+
+	_, err := bc.variants()
+	require.ErrorIs(t, err, ErrUnknownBenchScenario)
+}
+
+func TestNewBenchCommand_Flags(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewBenchCommand()
+
+	for _, name := range []string{"path", "analyzers", "limit", "scenario", "chunk-size", "chunk-sizes",
+		"workers", "format", "output", "baseline", "threshold", "profile-dir"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), name)
+	}
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/changelog"
+	"github.com/Sumatoshi-tech/codefang/pkg/codefang"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// ErrChangelogEmpty is returned when the revision range has no commits.
+var ErrChangelogEmpty = errors.New("no commits in range")
+
+// ErrInvalidModuleDepth is returned when --module-depth is below 1.
+var ErrInvalidModuleDepth = errors.New("--module-depth must be at least 1")
+
+// ChangelogCommand holds the flags of the changelog command.
+type ChangelogCommand struct {
+	from        string
+	to          string
+	path        string
+	moduleDepth int
+	complexity  bool
+	silent      bool
+}
+
+// NewChangelogCommand creates the changelog command, which renders the
+// commits between two revisions as Markdown release notes.
+func NewChangelogCommand() *cobra.Command {
+	cc := &ChangelogCommand{}
+
+	cmd := &cobra.Command{
+		Use:   "changelog [path]",
+		Short: "Generate Markdown release notes for a revision range",
+		Long: `List the commits reachable from --to but not from --from, as in
+git log FROM..TO, grouped by Conventional Commits type (feat, fix, ...) and by
+the module where each commit changed the most files. Breaking changes, marked
+by "!" or a BREAKING CHANGE footer, are repeated at the top. Commits that do
+not follow the convention are listed under Other Changes; merge commits are
+skipped.
+
+Unless --complexity=false, the history/complexity analyzer runs over the same
+range and every commit and module shows the cyclomatic complexity it added to
+or removed from the functions it changed.`,
+		Example: `  codefang changelog --from v1.2.0 --to v1.3.0
+  codefang changelog --from v1.3.0 --complexity=false > RELEASE_NOTES.md`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				cc.path = args[0]
+			}
+
+			if cc.moduleDepth < 1 {
+				return fmt.Errorf("%w: %d", ErrInvalidModuleDepth, cc.moduleDepth)
+			}
+
+			progress := cobraCmd.ErrOrStderr()
+			if cc.silent {
+				progress = io.Discard
+			}
+
+			return cc.run(cobraCmd.Context(), cobraCmd.OutOrStdout(), progress)
+		},
+	}
+
+	cmd.Flags().StringVar(&cc.from, "from", "", "Revision of the previous release; its commits are excluded")
+	cmd.Flags().StringVar(&cc.to, "to", "HEAD", "Revision of the release")
+	cmd.Flags().StringVarP(&cc.path, "path", "p", ".", "Repository path")
+	cmd.Flags().IntVar(&cc.moduleDepth, "module-depth", changelog.DefaultModuleDepth,
+		"Number of directory levels that name a module")
+	cmd.Flags().BoolVar(&cc.complexity, "complexity", true, "Show the complexity deltas of commits and modules")
+	cmd.Flags().BoolVar(&cc.silent, "silent", false, "Disable progress output")
+
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+func (cc *ChangelogCommand) run(ctx context.Context, stdout, progress io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	entries, err := cc.entries(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return fmt.Errorf("%w: %s..%s", ErrChangelogEmpty, cc.from, cc.to)
+	}
+
+	fmt.Fprintf(progress, "changelog %s..%s commits=%d\n", cc.from, cc.to, len(entries))
+
+	if cc.complexity {
+		deltas, complexityErr := changelog.Complexity(ctx, codefang.Options{Path: cc.path, From: cc.from, To: cc.to})
+		if complexityErr != nil {
+			return complexityErr
+		}
+
+		changelog.WithComplexity(entries, deltas)
+	}
+
+	return changelog.Build(cc.from, cc.to, entries, cc.moduleDepth).WriteMarkdown(stdout)
+}
+
+// entries loads and classifies the commits of the range.
+func (cc *ChangelogCommand) entries(ctx context.Context) ([]changelog.Entry, error) {
+	repository, err := gitlib.LoadRepository(cc.path)
+	if err != nil {
+		return nil, err
+	}
+	defer repository.Free()
+
+	commits, err := gitlib.LoadCommits(ctx, repository, gitlib.CommitLoadOptions{From: cc.from, To: cc.to})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		for _, commit := range commits {
+			commit.Free()
+		}
+	}()
+
+	return changelog.Entries(ctx, repository, commits)
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var changelogFixture = filepath.Join("..", "..", "..", "testdata", "fixture.git")
+
+func TestChangelogCommand_RequiresFrom(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewChangelogCommand()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{changelogFixture})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "from")
+}
+
+func TestChangelogCommand_Fixture(t *testing.T) {
+	t.Parallel()
+
+	var stdout bytes.Buffer
+
+	cmd := NewChangelogCommand()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--from", "ea6e293", "--to", "42e2240", changelogFixture})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	out := stdout.String()
+	assert.Contains(t, out, "# Changes from ea6e293 to 42e2240")
+	assert.Contains(t, out, "4 commits by 3 authors. Cyclomatic complexity of the changed functions:")
+	assert.Contains(t, out, "## Other Changes")
+	assert.Contains(t, out, "- Add median (dca9d40, Carol)")
+	assert.NotContains(t, out, "Merge feature")
+	assert.NotContains(t, out, "Inline greet")
+}
+
+func TestChangelogCommand_EmptyRange(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewChangelogCommand()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--from", "HEAD", "--complexity=false", changelogFixture})
+
+	err := cmd.Execute()
+	require.ErrorIs(t, err, ErrChangelogEmpty)
+}
+
+func TestChangelogCommand_RejectsModuleDepth(t *testing.T) {
+	t.Parallel()
+
+	for _, depth := range []string{"0", "-1"} {
+		cmd := NewChangelogCommand()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"--from", "HEAD", "--module-depth", depth, changelogFixture})
+
+		err := cmd.Execute()
+		require.ErrorIs(t, err, ErrInvalidModuleDepth, depth)
+	}
+}
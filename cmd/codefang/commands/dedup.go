@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// NewDedupCommand creates the dedup command, which merges NDJSON outputs of
+// interrupted and resumed runs into one stream without duplicate records.
+func NewDedupCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dedup [file...]",
+		Short: "Drop duplicate records from --format ndjson output",
+		Long: `Read --format ndjson output from the given files (or stdin) and write it to
+stdout, keeping only the first record per analyzer and commit hash.
+
+Use it when the outputs of an interrupted run and its resumed run overlap:
+  codefang dedup run1.ndjson run2.ndjson > merged.ndjson`,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return runDedup(args, cobraCmd.InOrStdin(), cobraCmd.OutOrStdout(), cobraCmd.ErrOrStderr())
+		},
+	}
+}
+
+func runDedup(paths []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	input := stdin
+
+	if len(paths) > 0 {
+		readers := make([]io.Reader, 0, len(paths))
+
+		for _, path := range paths {
+			f, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("open %s: %w", path, err)
+			}
+
+			defer f.Close()
+
+			// The newline keeps a file without a trailing one from running into the next.
+			readers = append(readers, f, strings.NewReader("\n"))
+		}
+
+		input = io.MultiReader(readers...)
+	}
+
+	stats, err := analyze.DedupNDJSON(input, stdout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "dedup: %d records, %d duplicates dropped\n", stats.Records, stats.Duplicates)
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/renderer"
+)
+
+// Timeouts of the file page server.
+const (
+	filesReadTimeout  = 10 * time.Second
+	filesWriteTimeout = 60 * time.Second
+)
+
+// FilesCommand holds configuration for the files command.
+type FilesCommand struct {
+	inputFormat string
+	analyzerIDs []string
+	file        string
+	addr        string
+	resultStore string
+
+	registryFn registryProvider
+}
+
+// NewFilesCommand creates the files command, which serves per-file detail
+// pages of a saved report.
+func NewFilesCommand() *cobra.Command {
+	return newFilesCommandWithDeps(defaultRegistry)
+}
+
+func newFilesCommandWithDeps(registryFn registryProvider) *cobra.Command {
+	fc := &FilesCommand{registryFn: registryFn}
+
+	cmd := &cobra.Command{
+		Use:   "files <report>",
+		Short: "Browse per-file detail pages of a report",
+		Long: `Serve an index of the files in a report written by "codefang run" or
+"codefang snapshot" (--format json or bin), with one detail page per file
+(owners, churn, coupling partners and function complexity) and one per author.
+Pages are rendered when requested, and each has a search box over files,
+authors and functions.
+
+With --file, write the detail page of that file to stdout instead.
+
+With --result-store, the argument names a stored run instead, as org/repo/run,
+or org/repo for the latest run of a repository.`,
+		Args: cobra.ExactArgs(1),
+		RunE: fc.run,
+	}
+
+	cmd.Flags().StringVar(&fc.inputFormat, "input-format", analyze.InputFormatAuto, "Input format: auto, json, bin")
+	cmd.Flags().StringSliceVarP(&fc.analyzerIDs, "analyzers", "a", nil,
+		"Analyzer IDs of the run that wrote a history-only bin report")
+	cmd.Flags().StringVar(&fc.file, "file", "", "Write the detail page of this file to stdout and exit")
+	cmd.Flags().StringVar(&fc.addr, "addr", "127.0.0.1:8090", "Address to serve pages on")
+	cmd.Flags().StringVar(&fc.resultStore, "result-store", "",
+		"Open a run of this report store (directory or s3:// URL) named by the argument")
+
+	return cmd
+}
+
+func (fc *FilesCommand) run(cmd *cobra.Command, args []string) error {
+	idx, err := fc.loadIndex(args[0])
+	if err != nil {
+		return err
+	}
+
+	if fc.file != "" {
+		return idx.RenderFile(cmd.OutOrStdout(), fc.file)
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "serving %d files on http://%s\n", len(idx.Paths()), fc.addr)
+
+	server := &http.Server{
+		Addr:         fc.addr,
+		Handler:      idx.Handler(),
+		ReadTimeout:  filesReadTimeout,
+		WriteTimeout: filesWriteTimeout,
+	}
+
+	err = server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve file pages: %w", err)
+	}
+
+	return nil
+}
+
+func (fc *FilesCommand) loadIndex(reportPath string) (*renderer.FileIndex, error) {
+	registry, err := fc.registryFn()
+	if err != nil {
+		return nil, err
+	}
+
+	if fc.resultStore != "" {
+		reportPath, err = storedRunLocation(fc.resultStore, reportPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	model, err := loadReportModel(registry, reportPath, fc.inputFormat, fc.analyzerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderer.NewFileIndex(model)
+}
+
+// loadReportModel reads and decodes the report at reportPath. analyzerIDs
+// name the analyzers of the run that wrote a history-only bin report.
+func loadReportModel(
+	registry *analyze.Registry,
+	reportPath, inputFormat string,
+	analyzerIDs []string,
+) (analyze.UnifiedModel, error) {
+	inputFormat, err := analyze.ResolveInputFormat(reportPath, inputFormat)
+	if err != nil {
+		return analyze.UnifiedModel{}, err
+	}
+
+	input, _, err := readReportFile(reportPath)
+	if err != nil {
+		return analyze.UnifiedModel{}, fmt.Errorf("read report: %w", err)
+	}
+
+	var orderedIDs []string
+
+	if len(analyzerIDs) > 0 {
+		ids, selectErr := registry.SelectedIDs(analyzerIDs)
+		if selectErr != nil {
+			return analyze.UnifiedModel{}, selectErr
+		}
+
+		orderedIDs, err = analyze.OrderedRunIDs(registry, ids)
+		if err != nil {
+			return analyze.UnifiedModel{}, err
+		}
+	}
+
+	return analyze.DecodeInputModel(input, inputFormat, orderedIDs, registry)
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/renderer"
+)
+
+func TestFilesCommand_WritesFilePage(t *testing.T) {
+	t.Parallel()
+
+	model := analyze.NewUnifiedModel([]analyze.AnalyzerResult{{
+		ID:   "static/complexity",
+		Mode: analyze.ModeStatic,
+		Report: analyze.Report{
+			"function_complexity": []any{map[string]any{"name": "Handle", "file": "pkg/api.go"}},
+		},
+	}})
+
+	data, err := json.Marshal(model)
+	require.NoError(t, err)
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(reportPath, data, 0o600))
+
+	var stdout bytes.Buffer
+
+	command := newFilesCommandWithDeps(stubRunRegistry)
+	command.SetOut(&stdout)
+	command.SetArgs([]string{reportPath, "--file", "pkg/api.go"})
+
+	require.NoError(t, command.Execute())
+	require.Contains(t, stdout.String(), "Handle")
+
+	command = newFilesCommandWithDeps(stubRunRegistry)
+	command.SetArgs([]string{reportPath, "--file", "missing.go"})
+
+	require.ErrorIs(t, command.Execute(), renderer.ErrUnknownFile)
+}
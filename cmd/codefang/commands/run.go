@@ -13,6 +13,7 @@ import (
 	"os/signal"
 	"runtime"
 	"slices"
+	"strings"
 	"syscall"
 	"time"
 
@@ -24,27 +25,50 @@ import (
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/anomaly"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/arch"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/burndown"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/codeowners"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/cohesion"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/comments"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/commitsize"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/renderer"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/complexity"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/couples"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/deadcode"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/defects"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/devs"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/dora"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/effort"
 	filehistory "github.com/Sumatoshi-tech/codefang/pkg/analyzers/file_history"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/halstead"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/imports"
-	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/lifecycle"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/policy"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/quality"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/reverts"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/sensitive"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/sentiment"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/shotness"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/signing"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/typos"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/workhours"
 	"github.com/Sumatoshi-tech/codefang/pkg/budget"
 	"github.com/Sumatoshi-tech/codefang/pkg/checkpoint"
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
+	"github.com/Sumatoshi-tech/codefang/pkg/codefang"
+	pkgcodeowners "github.com/Sumatoshi-tech/codefang/pkg/codeowners"
+	"github.com/Sumatoshi-tech/codefang/pkg/config"
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 	"github.com/Sumatoshi-tech/codefang/pkg/framework"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/observability"
 	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/redact"
+	"github.com/Sumatoshi-tech/codefang/pkg/reportstore"
+	"github.com/Sumatoshi-tech/codefang/pkg/storage"
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
 	"github.com/Sumatoshi-tech/codefang/pkg/version"
 )
 
@@ -73,12 +97,56 @@ type HistoryRunOptions struct {
 
 	CPUProfile  string
 	HeapProfile string
+	PprofAddr   string
+
+	// CPUProfileSplit labels pipeline stages and analyzers in the CPU profile
+	// and writes one extra profile per stage and per analyzer.
+	CPUProfileSplit bool
 
 	Limit       int
 	FirstParent bool
 	Head        bool
 	Since       string
 
+	SampleEvery    int
+	SampleStrategy string
+
+	// Seed makes random sampling and randomized analyzers reproducible.
+	Seed uint64
+
+	OnCommitError string
+
+	// AnalyzerTimeout and AnalyzerChunkTimeout limit the time of every leaf
+	// analyzer per commit and per chunk, as parsed by
+	// framework.ParseAnalyzerTimeouts.
+	AnalyzerTimeout      string
+	AnalyzerChunkTimeout string
+
+	// CommitLookahead prepares the next commit for sequential analyzers
+	// (burndown) while the current one is consumed.
+	CommitLookahead bool
+
+	// WithCommitTable adds a commits table (hash, author, tick, timestamp,
+	// files changed, insertions, deletions, languages) to the output.
+	WithCommitTable bool
+
+	// SinkBuffer, SinkPolicy and SinkSampleEvery configure the buffered
+	// NDJSON output sink. The sink is unbuffered when all are left unset.
+	SinkBuffer      int
+	SinkPolicy      string
+	SinkSampleEvery int
+
+	// OutputPartition splits --format timeseries output into one NDJSON file
+	// per tick or month, written to OutputDir instead of the output writer.
+	// With --format graph, OutputDir receives a GraphML and a GEXF file per
+	// developer network instead.
+	OutputPartition string
+	OutputDir       string
+
+	// OutputSink, when set, receives the NDJSON records instead of the
+	// output writer.
+	OutputSink analyze.OutputSink
+
 	Workers         int
 	BufferSize      int
 	CommitBatchSize int
@@ -87,24 +155,101 @@ type HistoryRunOptions struct {
 	BlobArenaSize   string
 	MemoryBudget    string
 
+	// BlobCacheCaps caps the blob cache per file extension
+	// (e.g. ".json=64MB,.svg=0"). Empty means no caps.
+	BlobCacheCaps string
+
+	// SpillCodec names the codec compressing aggregator spill files
+	// (none, zstd, snappy). Empty means none.
+	SpillCodec string
+
+	// EncryptAtRest encrypts checkpoint and spill files with the key from
+	// CODEFANG_ENCRYPTION_KEY or CODEFANG_ENCRYPTION_KEY_COMMAND.
+	EncryptAtRest bool
+
+	// Redact lists what to pseudonymize in reports and records
+	// (authors, paths, messages, all). Empty means nothing.
+	Redact string
+
+	// DiskBudget caps the total size of spill and checkpoint files
+	// (e.g. "20GB"). Empty means no budget.
+	DiskBudget string
+
+	// SpillDir is the parent directory of aggregator spill files. Empty
+	// means the system temp directory.
+	SpillDir string
+
+	// StoreDir is the parent directory of analyzer working-state stores,
+	// such as hibernated burndown files. Empty means the system temp
+	// directory.
+	StoreDir string
+
+	// UASTService is the base URL of a "uast server" that parses files for
+	// the UAST pipeline. Empty means in-process parsing.
+	UASTService string
+
+	// ChunkWorkers are the base URLs of "codefang worker" processes that run
+	// the blob, diff and UAST stages of chunks. Empty means in-process.
+	ChunkWorkers []string
+
+	// Nice runs the analysis as a low-priority background job. See
+	// framework.EnterNiceMode.
+	Nice bool
+
+	// PackOrder loads the blobs of each commit batch in pack file order.
+	PackOrder bool
+
+	// BalanceChunks pre-scans the blob sizes of every commit and cuts chunks
+	// of about equal work instead of equal commit counts.
+	BalanceChunks bool
+
+	// PipelineDebug writes the queue statistics of the coordinator stages to
+	// stderr after the run.
+	PipelineDebug bool
+
+	// ODBCacheDir, when set, mirrors the pack files of the repository into
+	// this directory on local storage and reads objects from the mirror.
+	ODBCacheDir string
+
 	Checkpoint      *bool
 	CheckpointDir   string
 	Resume          *bool
 	ClearCheckpoint bool
 
 	DebugTrace bool
+
+	// AnalyzerFacts holds analyzer configuration options set via their CLI flags,
+	// keyed by option name. They override the options' defaults.
+	AnalyzerFacts map[string]any
+
+	// Control receives the control requests of the diagnostics server
+	// (--pprof-addr). Nil means the run cannot be steered.
+	Control *framework.Control
 }
 
 var (
 	// ErrNoAnalyzersSelected is returned when no analyzer IDs match the selection.
 	ErrNoAnalyzersSelected = errors.New(
 		"no analyzers selected. Use -a flag, e.g.: -a burndown,couples\n" +
-			"Available: anomaly, burndown, couples, devs, file-history, imports, quality, sentiment, shotness, typos",
+			"Available: anomaly, arch, burndown, codeowners, cohesion, comments, commitsize, complexity, couples, deadcode, defects, devs, dora, effort, file-history, halstead, imports, lifecycle, policy, quality, reverts, sensitive, sentiment, shotness, signing, typos, workhours",
 	)
 	// ErrUnknownAnalyzer indicates a requested analyzer ID is not in the registry.
-	ErrUnknownAnalyzer = errors.New("unknown analyzer")
+	ErrUnknownAnalyzer = codefang.ErrUnknownAnalyzer
 	// ErrRepositoryLoad indicates a failure to open or load the git repository.
 	ErrRepositoryLoad = errors.New("failed to load repository")
+	// ErrOutputPartitionUsage indicates --output-partition was used without its required flags.
+	ErrOutputPartitionUsage = errors.New("--output-partition requires --format timeseries and --output-dir")
+	// ErrCPUProfileSplitUsage indicates --cpuprofile-split was used without --cpuprofile.
+	ErrCPUProfileSplitUsage = errors.New("--cpuprofile-split requires --cpuprofile")
+	// ErrSummaryMetricsFormat indicates --emit-summary-metrics was used with an output
+	// format that does not go through the unified model.
+	ErrSummaryMetricsFormat = errors.New("--emit-summary-metrics requires --format json, yaml, bin, plot or timeseries")
+	// ErrRedactUsage indicates --redact was combined with a run it cannot redact.
+	ErrRedactUsage = errors.New("--redact supports history analyzers only")
+	// ErrEventsFormat indicates --events was used with an output format without charts.
+	ErrEventsFormat = errors.New("--events requires --format plot")
+	// ErrForecastUsage indicates --forecast-days was used with a run whose output has no forecasts.
+	ErrForecastUsage = errors.New("--forecast-days requires a history run with --format timeseries or plot")
 )
 
 // RunCommand holds configuration and dependencies for the unified run command.
@@ -122,21 +267,68 @@ type RunCommand struct {
 
 	debugTrace bool
 
-	cpuprofile  string
-	heapprofile string
+	cpuprofile      string
+	cpuprofileSplit bool
+	heapprofile     string
+	pprofAddr       string
 
 	limit       int
 	firstParent bool
 	head        bool
 	since       string
 
+	sampleEvery    int
+	sampleStrategy string
+	seed           uint64
+
+	onCommitError        string
+	analyzerTimeout      string
+	analyzerChunkTimeout string
+	commitLookahead      bool
+
+	withCommitTable bool
+
+	sinkBuffer      int
+	sinkPolicy      string
+	sinkSampleEvery int
+
+	outputPartition string
+	outputDir       string
+
+	summaryMetrics string
+	events         string
+	forecastDays   int
+
+	configPath string
+	outputPath string
+	outputSink analyze.OutputSink
+	// outputCipher encrypts the report kept in --result-store with --encrypt-at-rest.
+	outputCipher *crypt.Cipher
+
+	resultStore string
+	namespace   string
+
 	workers         int
 	bufferSize      int
 	commitBatchSize int
 	blobCacheSize   string
+	blobCacheCaps   string
 	diffCacheSize   int
 	blobArenaSize   string
 	memoryBudget    string
+	spillCodec      string
+	encryptAtRest   bool
+	redact          string
+	diskBudget      string
+	spillDir        string
+	storeDir        string
+	uastService     string
+	chunkWorkers    []string
+	nice            bool
+	packOrder       bool
+	balanceChunks   bool
+	pipelineDebug   bool
+	odbCacheDir     string
 
 	checkpointDir   string
 	clearCheckpoint bool
@@ -149,25 +341,53 @@ type RunCommand struct {
 
 // NewRunCommand creates the unified run command.
 func NewRunCommand() *cobra.Command {
+	registerRenderers()
+
+	return newRunCommandWithDeps(runStaticAnalyzers, runHistoryAnalyzers, defaultRegistry, observability.Init)
+}
+
+// registerRenderers registers the plot sections, time series extractors,
+// forecast series and derived metrics used to render converted output.
+func registerRenderers() {
 	anomaly.RegisterPlotSections()
+	arch.RegisterPlotSections()
 	burndown.RegisterPlotSections()
+	codeowners.RegisterPlotSections()
 	cohesion.RegisterPlotSections()
 	comments.RegisterPlotSections()
+	commitsize.RegisterPlotSections()
 	complexity.RegisterPlotSections()
+	deadcode.RegisterPlotSections()
+	defects.RegisterPlotSections()
+	dora.RegisterPlotSections()
+	effort.RegisterPlotSections()
 	couples.RegisterPlotSections()
 	filehistory.RegisterPlotSections()
 	halstead.RegisterPlotSections()
 	imports.RegisterPlotSections()
+	lifecycle.RegisterPlotSections()
+	policy.RegisterPlotSections()
 	quality.RegisterPlotSections()
+	reverts.RegisterPlotSections()
+	sensitive.RegisterPlotSections()
 	sentiment.RegisterPlotSections()
 	shotness.RegisterPlotSections()
+	signing.RegisterPlotSections()
 	typos.RegisterPlotSections()
+	workhours.RegisterPlotSections()
 
 	quality.RegisterTimeSeriesExtractor()
 	sentiment.RegisterTimeSeriesExtractor()
-	renderer.RegisterPlotRenderer()
 
-	return newRunCommandWithDeps(runStaticAnalyzers, runHistoryAnalyzers, defaultRegistry, observability.Init)
+	burndown.RegisterForecastSeries()
+	devs.RegisterForecastSeries()
+	quality.RegisterForecastSeries()
+
+	anomaly.RegisterDerivedMetrics()
+	devs.RegisterDerivedMetrics()
+	shotness.RegisterDerivedMetrics()
+
+	renderer.RegisterPlotRenderer()
 }
 
 func newRunCommandWithDeps(
@@ -195,9 +415,14 @@ func newRunCommandWithDeps(
 	cmd.Flags().StringSliceVarP(&rc.analyzerIDs, "analyzers", "a", nil,
 		"Analyzer IDs or glob patterns (example: static/complexity,history/*,*)")
 	cmd.Flags().StringVar(&rc.format, "format", analyze.FormatJSON,
-		"Output format: json, yaml, plot, bin, timeseries, ndjson, text, compact")
-	cmd.Flags().StringVar(&rc.inputPath, "input", "", "Input report path for cross-format conversion")
+		"Output format: json, yaml, plot, bin, timeseries, ndjson, text, compact, graph")
+	cmd.Flags().StringVar(&rc.inputPath, "input", "", "Input report path or s3:// URL for cross-format conversion")
+	cmd.Flags().StringVarP(&rc.outputPath, "output", "o", "", "Write the report to this path or s3:// URL instead of stdout")
 	cmd.Flags().StringVar(&rc.inputFormat, "input-format", analyze.InputFormatAuto, "Input format: auto, json, bin")
+	cmd.Flags().StringVar(&rc.resultStore, "result-store", "",
+		"Keep the report in this report store (directory or s3:// URL) under --namespace instead of writing it to stdout")
+	cmd.Flags().StringVar(&rc.namespace, "namespace", "",
+		"Namespace of the run in --result-store: org/repo or org/repo/run (default run: the start time)")
 	cmd.Flags().IntVar(&rc.gogc, "gogc", 0, "GC percent for history pipeline (0 = auto, >0 = exact)")
 	cmd.Flags().StringVar(&rc.ballastSize, "ballast-size", "0", "Optional GC ballast size for history pipeline (0 = disabled)")
 	cmd.Flags().BoolVarP(&rc.verbose, "verbose", "v", false, "Show full static report details")
@@ -208,20 +433,87 @@ func newRunCommandWithDeps(
 	cmd.Flags().BoolVar(&rc.debugTrace, "debug-trace", false, "Enable 100% trace sampling for debugging")
 
 	cmd.Flags().StringVar(&rc.cpuprofile, "cpuprofile", "", "Write CPU profile to file")
+	cmd.Flags().BoolVar(&rc.cpuprofileSplit, "cpuprofile-split", false,
+		"Label the CPU profile by pipeline stage and analyzer and write one extra profile per label")
 	cmd.Flags().StringVar(&rc.heapprofile, "heapprofile", "", "Write heap profile to file")
+	cmd.Flags().StringVar(&rc.pprofAddr, "pprof-addr", "",
+		"Serve net/http/pprof on this address (empty = disabled, 'auto' = free loopback port)")
 
 	cmd.Flags().IntVar(&rc.limit, "limit", 0, "Limit number of commits to analyze (0 = no limit)")
 	cmd.Flags().BoolVar(&rc.firstParent, "first-parent", false, "Follow only first parent of merge commits")
 	cmd.Flags().BoolVar(&rc.head, "head", false, "Analyze only HEAD commit")
 	cmd.Flags().StringVar(&rc.since, "since", "", "Only analyze commits after this time (e.g., '24h', '2024-01-01', RFC3339)")
-
+	cmd.Flags().IntVar(&rc.sampleEvery, "sample-every", 0,
+		"Analyze about one commit in N for approximate trends on huge repos (0 = every commit)")
+	cmd.Flags().StringVar(&rc.sampleStrategy, "sample-strategy", string(gitlib.SampleUniform),
+		"Commit sampling strategy: uniform, random, release-tags (release-tags ignores --sample-every)")
+	cmd.Flags().Uint64Var(&rc.seed, "seed", 0,
+		"Seed for random sampling and randomized analyzers; runs with the same seed give the same results")
+	cmd.Flags().StringVar(&rc.onCommitError, "on-commit-error", string(framework.CommitErrorAbort),
+		"How to handle a commit that fails to process: abort, skip, retry (retry re-reads blobs, then skips)")
+	cmd.Flags().StringVar(&rc.analyzerTimeout, "analyzer-timeout", "",
+		"Skip a commit for an analyzer that spends longer on it, e.g. 30s or 30s,sentiment=2m (empty = no limit)")
+	cmd.Flags().StringVar(&rc.analyzerChunkTimeout, "analyzer-chunk-timeout", "",
+		"Skip the rest of a chunk for an analyzer that spends longer on it, e.g. 10m,shotness=20m (empty = no limit)")
+	cmd.Flags().BoolVar(&rc.commitLookahead, "commit-lookahead", false,
+		"Prepare the next commit for sequential analyzers (burndown) while the current one is consumed")
+	cmd.Flags().BoolVar(&rc.withCommitTable, "with-commit-table", false,
+		"Add a commits table (hash, author, tick, timestamp, files changed, insertions, deletions, languages) to the output")
+	cmd.Flags().IntVar(&rc.sinkBuffer, "sink-buffer", 0,
+		"Records buffered between the pipeline and --format ndjson output (0 = write synchronously)")
+	cmd.Flags().StringVar(&rc.sinkPolicy, "sink-policy", string(analyze.SinkPolicyBlock),
+		"What to do when the ndjson output buffer is full: block, drop (drop never stalls the pipeline)")
+	cmd.Flags().IntVar(&rc.sinkSampleEvery, "sink-sample-every", 0,
+		"Write only every Nth ndjson record (0 = every record)")
+	cmd.Flags().StringVar(&rc.outputPartition, "output-partition", "",
+		"Split --format timeseries into one NDJSON file per partition: tick, month (requires --output-dir)")
+	cmd.Flags().StringVar(&rc.outputDir, "output-dir", "",
+		"Directory or s3:// URL for partitioned output files and --format graph files")
+	cmd.Flags().StringVar(&rc.summaryMetrics, "emit-summary-metrics", "",
+		"Publish headline numbers as OpenMetrics to a file, or to a Pushgateway when given an http(s) URL")
+	cmd.Flags().StringVar(&rc.events, "events", "",
+		"YAML or JSON file of dated events (releases, incidents) to mark on the time-series charts of --format plot")
+	cmd.Flags().IntVar(&rc.forecastDays, "forecast-days", 0,
+		"Project lines of code, active contributors and complexity this many days ahead "+
+			"in --format timeseries and plot (0 = no forecast)")
+
+	cmd.Flags().StringVar(&rc.configPath, "config", "",
+		"Config file (default: .codefang.yaml in the current or home directory); its output.sinks receive the output")
 	cmd.Flags().IntVar(&rc.workers, "workers", 0, "Number of parallel workers (0 = use CPU count)")
 	cmd.Flags().IntVar(&rc.bufferSize, "buffer-size", 0, "Size of internal pipeline channels (0 = workers*2)")
 	cmd.Flags().IntVar(&rc.commitBatchSize, "commit-batch-size", 0, "Commits per processing batch (0 = default 100)")
 	cmd.Flags().StringVar(&rc.blobCacheSize, "blob-cache-size", "", "Max blob cache size (e.g., '256MB', '1GB'; empty = default 1GB)")
+	cmd.Flags().StringVar(&rc.blobCacheCaps, "blob-cache-ext-caps", "",
+		"Per-extension blob cache caps (e.g., '.json=64MB,.svg=0'; 0 keeps an extension out of the cache)")
 	cmd.Flags().IntVar(&rc.diffCacheSize, "diff-cache-size", 0, "Max diff cache entries (0 = default 10000)")
 	cmd.Flags().StringVar(&rc.blobArenaSize, "blob-arena-size", "", "Memory arena size for blob loading (e.g., '4MB'; empty = default 4MB)")
 	cmd.Flags().StringVar(&rc.memoryBudget, "memory-budget", "", "Memory budget for auto-tuning (e.g., '512MB', '2GB')")
+	cmd.Flags().StringVar(&rc.spillCodec, "spill-codec", codec.None,
+		"Compression of aggregator spill files: none, zstd, snappy (zstd is smallest, snappy is fastest)")
+	cmd.Flags().BoolVar(&rc.encryptAtRest, "encrypt-at-rest", false,
+		"Encrypt checkpoint, spill and result store files with AES-256-GCM (key from "+crypt.EnvKey+" or "+crypt.EnvKeyCommand+")")
+	cmd.Flags().StringVar(&rc.redact, "redact", "",
+		"Pseudonymize authors, paths and/or messages in reports (comma-separated: authors, paths, messages, all; key from "+
+			redact.EnvKey+")")
+	cmd.Flags().StringVar(&rc.diskBudget, "disk-budget", "",
+		"Max total size of spill and checkpoint files (e.g., '20GB'; empty = unlimited)")
+	cmd.Flags().StringVar(&rc.spillDir, "spill-dir", "", "Parent directory for aggregator spill files (default: system temp dir)")
+	cmd.Flags().StringVar(&rc.storeDir, "store-dir", "",
+		"Parent directory for analyzer state kept on disk, such as hibernated burndown files (default: system temp dir)")
+	cmd.Flags().StringVar(&rc.uastService, "uast-service", "",
+		"Parse files on a 'uast server' at this URL instead of in-process (e.g., 'http://uast:8080')")
+	cmd.Flags().StringSliceVar(&rc.chunkWorkers, "chunk-workers", nil,
+		"Run the blob, diff and UAST stages of chunks on 'codefang worker' processes at these URLs (comma-separated)")
+	cmd.Flags().BoolVar(&rc.nice, "nice", false,
+		"Run history analysis at low priority: fewer CPUs, lower CPU and I/O priority, pauses between chunks")
+	cmd.Flags().BoolVar(&rc.packOrder, "pack-order", false,
+		"Load blobs in pack file order to cut random object reads on slow or network filesystems")
+	cmd.Flags().BoolVar(&rc.balanceChunks, "balance-chunks", false,
+		"Pre-scan blob sizes to cut chunks of about equal work instead of equal commit counts (one extra history walk)")
+	cmd.Flags().BoolVar(&rc.pipelineDebug, "pipeline-debug", false,
+		"Print the commit queues between pipeline stages and the stage that held the pipeline up after the run")
+	cmd.Flags().StringVar(&rc.odbCacheDir, "odb-cache-dir", "",
+		"Mirror the repository's pack files into this local directory and read objects from there (for repositories on NFS)")
 
 	cmd.Flags().Bool("checkpoint", true, "Enable checkpointing for crash recovery")
 	cmd.Flags().StringVar(&rc.checkpointDir, "checkpoint-dir", "", "Checkpoint directory (default: ~/.codefang/checkpoints)")
@@ -297,23 +589,155 @@ func (rc *RunCommand) run(cmd *cobra.Command, args []string) (runResult error) {
 
 	rc.progressf(silent, progressWriter, "selected analyzers: total=%d", len(ids))
 
+	_, err = redact.ParseModes(rc.redact)
+	if err != nil {
+		return fmt.Errorf("redact: %w", err)
+	}
+
+	if rc.redact != "" && rc.inputPath != "" {
+		return fmt.Errorf("%w: --input converts a finished report", ErrRedactUsage)
+	}
+
+	err = rc.loadEvents()
+	if err != nil {
+		return err
+	}
+
+	err = rc.setForecastHorizon()
+	if err != nil {
+		return err
+	}
+
+	storeEntry, err := rc.prepareResultStore(ids)
+	if err != nil {
+		return err
+	}
+
+	writer, finishOutput, err := rc.openOutput(cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+
 	var runErr error
 
 	if rc.inputPath != "" {
-		runErr = rc.runInputConversion(cmd.OutOrStdout(), registry, ids, silent, progressWriter)
+		runErr = rc.runInputConversion(ctx, writer, registry, ids, silent, progressWriter)
 	} else {
-		runErr = rc.runDirect(ctx, path, ids, registry, silent, progressWriter, cmd.OutOrStdout(), cmd)
+		runErr = rc.runDirect(ctx, path, ids, registry, silent, progressWriter, writer, cmd)
 	}
 
+	outputErr := finishOutput(runErr == nil)
 	if runErr != nil {
 		return runErr
 	}
 
+	if outputErr != nil {
+		return fmt.Errorf("write output: %w", outputErr)
+	}
+
+	if storeEntry != nil {
+		err = reportstore.New(rc.resultStore).Record(*storeEntry)
+		if err != nil {
+			return err
+		}
+
+		rc.progressf(silent, progressWriter, "stored run %s in %s", storeEntry.Namespace(), rc.resultStore)
+	}
+
 	rc.progressf(silent, progressWriter, "run completed")
 
 	return nil
 }
 
+// openOutput returns the writer of the run's report. --output replaces
+// stdout with a local file or an object storage URL. When the config file
+// lists output sinks, the report and NDJSON records go to them instead; finish
+// hands a successful run's report to the sinks and closes them and the output.
+func (rc *RunCommand) openOutput(stdout io.Writer) (writer io.Writer, finish func(ok bool) error, err error) {
+	cfg, err := config.LoadConfig(rc.configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closeOutput := func() error { return nil }
+
+	if rc.outputPath != "" {
+		file, createErr := storage.Create(rc.outputPath)
+		if createErr != nil {
+			return nil, nil, fmt.Errorf("output: %w", createErr)
+		}
+
+		stdout = file
+		closeOutput = file.Close
+
+		if rc.outputCipher != nil {
+			encrypted, encryptErr := rc.outputCipher.NewWriter(file)
+			if encryptErr != nil {
+				return nil, nil, errors.Join(fmt.Errorf("output: %w", encryptErr), file.Close())
+			}
+
+			stdout = encrypted
+			closeOutput = func() error { return errors.Join(encrypted.Close(), file.Close()) }
+		}
+	}
+
+	if len(cfg.Output.Sinks) == 0 {
+		return stdout, func(bool) error { return closeOutput() }, nil
+	}
+
+	specs, err := outputSinkSpecs(cfg.Output.Sinks)
+	if err != nil {
+		return nil, nil, errors.Join(err, closeOutput())
+	}
+
+	sinks, err := analyze.NewOutputSinks(specs, stdout)
+	if err != nil {
+		return nil, nil, errors.Join(err, closeOutput())
+	}
+
+	rc.outputSink = sinks
+	report := analyze.NewReportWriter(sinks, rc.format)
+
+	finish = func(ok bool) error {
+		var flushErr error
+		if ok {
+			flushErr = report.Flush()
+		}
+
+		return errors.Join(flushErr, sinks.Close(), closeOutput())
+	}
+
+	return report, finish, nil
+}
+
+// outputSinkSpecs converts the output sinks of the config file.
+func outputSinkSpecs(sinks []config.OutputSinkConfig) ([]analyze.OutputSinkSpec, error) {
+	specs := make([]analyze.OutputSinkSpec, 0, len(sinks))
+
+	for _, sink := range sinks {
+		spec := analyze.OutputSinkSpec{
+			Type:      sink.Type,
+			Path:      sink.Path,
+			URL:       sink.URL,
+			Headers:   sink.Headers,
+			BatchSize: sink.BatchSize,
+		}
+
+		if sink.Timeout != "" {
+			timeout, err := time.ParseDuration(sink.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("output sink timeout: %w", err)
+			}
+
+			spec.Timeout = timeout
+		}
+
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
 func (rc *RunCommand) initObservability() (observability.Providers, error) {
 	cfg := observability.DefaultConfig()
 	cfg.ServiceVersion = version.Version
@@ -363,6 +787,7 @@ func (rc *RunCommand) resolvePath(args []string) string {
 }
 
 func (rc *RunCommand) runInputConversion(
+	ctx context.Context,
 	writer io.Writer,
 	registry *analyze.Registry,
 	ids []string,
@@ -382,9 +807,9 @@ func (rc *RunCommand) runInputConversion(
 		return err
 	}
 
-	inputBytes, err := os.ReadFile(rc.inputPath)
+	inputBytes, err := storage.ReadFile(rc.inputPath)
 	if err != nil {
-		return fmt.Errorf("read input %s: %w", rc.inputPath, err)
+		return fmt.Errorf("read input: %w", err)
 	}
 
 	orderedIDs, err := analyze.OrderedRunIDs(registry, ids)
@@ -397,7 +822,81 @@ func (rc *RunCommand) runInputConversion(
 		return err
 	}
 
-	return analyze.WriteConvertedOutput(model, outputFormat, writer)
+	err = analyze.WriteConvertedOutput(model, outputFormat, writer)
+	if err != nil {
+		return err
+	}
+
+	return rc.emitSummaryMetrics(ctx, model, silent, progressWriter)
+}
+
+// loadEvents registers the events of --events to be marked on the charts of
+// the plot output. The commit table ties the ticks of the charts to dates.
+func (rc *RunCommand) loadEvents() error {
+	if rc.events == "" {
+		return nil
+	}
+
+	if analyze.NormalizeFormat(rc.format) != analyze.FormatPlot {
+		return fmt.Errorf("%w, got %s", ErrEventsFormat, rc.format)
+	}
+
+	data, err := storage.ReadFile(rc.events)
+	if err != nil {
+		return fmt.Errorf("read events: %w", err)
+	}
+
+	events, err := plotpage.ParseEvents(data)
+	if err != nil {
+		return err
+	}
+
+	plotpage.SetEvents(events)
+
+	return nil
+}
+
+// setForecastHorizon sets how far ahead --forecast-days projects the series
+// of the history reports.
+func (rc *RunCommand) setForecastHorizon() error {
+	if rc.forecastDays <= 0 {
+		return nil
+	}
+
+	format := analyze.NormalizeFormat(rc.format)
+	if format != analyze.FormatTimeSeries && format != analyze.FormatPlot {
+		return fmt.Errorf("%w, got %s", ErrForecastUsage, rc.format)
+	}
+
+	if rc.inputPath != "" {
+		return fmt.Errorf("%w: --input converts a finished report", ErrForecastUsage)
+	}
+
+	analyze.SetForecastHorizon(time.Duration(rc.forecastDays) * day)
+
+	return nil
+}
+
+// emitSummaryMetrics publishes the summary metrics of model when
+// --emit-summary-metrics is set.
+func (rc *RunCommand) emitSummaryMetrics(
+	ctx context.Context,
+	model analyze.UnifiedModel,
+	silent bool,
+	progressWriter io.Writer,
+) error {
+	if rc.summaryMetrics == "" {
+		return nil
+	}
+
+	err := emitSummaryMetrics(ctx, model, rc.summaryMetrics)
+	if err != nil {
+		return err
+	}
+
+	rc.progressf(silent, progressWriter, "summary metrics emitted to %s", rc.summaryMetrics)
+
+	return nil
 }
 
 func (rc *RunCommand) runDirect(
@@ -428,12 +927,27 @@ func (rc *RunCommand) runDirect(
 	rc.progressf(silent, progressWriter, "resolved analyzers: static=%d history=%d output_format=%s",
 		len(staticIDs), len(historyIDs), resolvedOutputFormat)
 
+	if rc.redact != "" && len(staticIDs) > 0 {
+		return fmt.Errorf("%w: static analyzers selected: %s", ErrRedactUsage, strings.Join(staticIDs, ", "))
+	}
+
 	if len(staticIDs) > 0 && len(historyIDs) > 0 {
 		rc.progressf(silent, progressWriter, "mixed run detected: rendering combined output")
 
 		return rc.renderCombinedDirect(ctx, path, staticIDs, historyIDs, registry, staticFormat, silent, progressWriter, writer, cmd)
 	}
 
+	// Summary metrics are computed from the unified model, so single-mode
+	// runs take the combined path too.
+	if rc.summaryMetrics != "" {
+		if _, formatErr := analyze.ValidateUniversalFormat(resolvedOutputFormat); formatErr != nil {
+			return fmt.Errorf("%w: %w", ErrSummaryMetricsFormat, formatErr)
+		}
+
+		return rc.renderCombinedDirect(ctx, path, staticIDs, historyIDs, registry, resolvedOutputFormat,
+			silent, progressWriter, writer, cmd)
+	}
+
 	err = rc.runStaticPhase(path, staticIDs, staticFormat, silent, progressWriter, writer)
 	if err != nil {
 		return err
@@ -512,30 +1026,11 @@ func (rc *RunCommand) renderCombinedDirect(
 ) error {
 	var raw bytes.Buffer
 
-	startedAt := time.Now()
-
-	rc.progressf(silent, progressWriter, "combined static phase started")
-
-	err := rc.staticExec(path, staticIDs, analyze.FormatBinary, rc.verbose, rc.noColor, &raw)
-	if err != nil {
-		return fmt.Errorf("render combined static phase: %w", err)
-	}
-
-	rc.progressf(silent, progressWriter, "combined static phase finished in %s", time.Since(startedAt).Round(time.Millisecond))
-
-	startedAt = time.Now()
-
-	rc.progressf(silent, progressWriter, "combined history phase started")
-
-	opts := rc.buildHistoryRunOptions(cmd)
-
-	err = rc.historyExec(ctx, path, historyIDs, analyze.FormatBinary, silent, opts, &raw)
+	err := rc.collectCombined(ctx, path, staticIDs, historyIDs, silent, progressWriter, &raw, cmd)
 	if err != nil {
-		return fmt.Errorf("render combined history phase: %w", err)
+		return err
 	}
 
-	rc.progressf(silent, progressWriter, "combined history phase finished in %s", time.Since(startedAt).Round(time.Millisecond))
-
 	orderedIDs := make([]string, 0, len(staticIDs)+len(historyIDs))
 	orderedIDs = append(orderedIDs, staticIDs...)
 	orderedIDs = append(orderedIDs, historyIDs...)
@@ -545,9 +1040,11 @@ func (rc *RunCommand) renderCombinedDirect(
 		return fmt.Errorf("decode combined payload: %w", err)
 	}
 
+	model.Seed = rc.seed
+
 	rc.progressf(silent, progressWriter, "combined payload decoded")
 
-	startedAt = time.Now()
+	startedAt := time.Now()
 
 	rc.progressf(silent, progressWriter, "combined output rendering started")
 
@@ -558,6 +1055,49 @@ func (rc *RunCommand) renderCombinedDirect(
 
 	rc.progressf(silent, progressWriter, "combined output rendering finished in %s", time.Since(startedAt).Round(time.Millisecond))
 
+	return rc.emitSummaryMetrics(ctx, model, silent, progressWriter)
+}
+
+// collectCombined writes the binary output of the static and then the history
+// phase to raw, skipping a phase without analyzers.
+func (rc *RunCommand) collectCombined(
+	ctx context.Context,
+	path string,
+	staticIDs []string,
+	historyIDs []string,
+	silent bool,
+	progressWriter io.Writer,
+	raw io.Writer,
+	cmd *cobra.Command,
+) error {
+	if len(staticIDs) > 0 {
+		startedAt := time.Now()
+
+		rc.progressf(silent, progressWriter, "combined static phase started")
+
+		err := rc.staticExec(path, staticIDs, analyze.FormatBinary, rc.verbose, rc.noColor, raw)
+		if err != nil {
+			return fmt.Errorf("render combined static phase: %w", err)
+		}
+
+		rc.progressf(silent, progressWriter, "combined static phase finished in %s", time.Since(startedAt).Round(time.Millisecond))
+	}
+
+	if len(historyIDs) > 0 {
+		startedAt := time.Now()
+
+		rc.progressf(silent, progressWriter, "combined history phase started")
+
+		opts := rc.buildHistoryRunOptions(cmd)
+
+		err := rc.historyExec(ctx, path, historyIDs, analyze.FormatBinary, silent, opts, raw)
+		if err != nil {
+			return fmt.Errorf("render combined history phase: %w", err)
+		}
+
+		rc.progressf(silent, progressWriter, "combined history phase finished in %s", time.Since(startedAt).Round(time.Millisecond))
+	}
+
 	return nil
 }
 
@@ -566,23 +1106,51 @@ func (rc *RunCommand) buildHistoryRunOptions(cmd *cobra.Command) HistoryRunOptio
 		GCPercent:       rc.gogc,
 		BallastSize:     rc.ballastSize,
 		CPUProfile:      rc.cpuprofile,
+		CPUProfileSplit: rc.cpuprofileSplit,
 		HeapProfile:     rc.heapprofile,
+		PprofAddr:       rc.pprofAddr,
 		Limit:           rc.limit,
 		FirstParent:     rc.firstParent,
 		Head:            rc.head,
 		Since:           rc.since,
+		SampleEvery:     rc.sampleEvery,
+		SampleStrategy:  rc.sampleStrategy,
+		OnCommitError:   rc.onCommitError,
+		CommitLookahead: rc.commitLookahead,
+		WithCommitTable: rc.withCommitTable || rc.events != "",
+		SinkBuffer:      rc.sinkBuffer,
+		SinkPolicy:      rc.sinkPolicy,
+		SinkSampleEvery: rc.sinkSampleEvery,
+		OutputPartition: rc.outputPartition,
+		OutputDir:       rc.outputDir,
+		OutputSink:      rc.outputSink,
 		Workers:         rc.workers,
 		BufferSize:      rc.bufferSize,
 		CommitBatchSize: rc.commitBatchSize,
 		BlobCacheSize:   rc.blobCacheSize,
+		BlobCacheCaps:   rc.blobCacheCaps,
 		DiffCacheSize:   rc.diffCacheSize,
 		BlobArenaSize:   rc.blobArenaSize,
 		MemoryBudget:    rc.memoryBudget,
+		SpillCodec:      rc.spillCodec,
+		EncryptAtRest:   rc.encryptAtRest,
+		Redact:          rc.redact,
+		DiskBudget:      rc.diskBudget,
+		SpillDir:        rc.spillDir,
+		StoreDir:        rc.storeDir,
+		UASTService:     rc.uastService,
+		ChunkWorkers:    rc.chunkWorkers,
+		Nice:            rc.nice,
+		PackOrder:       rc.packOrder,
+		BalanceChunks:   rc.balanceChunks,
+		ODBCacheDir:     rc.odbCacheDir,
 		CheckpointDir:   rc.checkpointDir,
 		ClearCheckpoint: rc.clearCheckpoint,
 		DebugTrace:      rc.debugTrace,
 	}
 
+	opts.PipelineDebug = rc.pipelineDebug
+
 	if cmd.Flags().Changed("checkpoint") {
 		v, err := cmd.Flags().GetBool("checkpoint")
 		if err != nil {
@@ -601,6 +1169,11 @@ func (rc *RunCommand) buildHistoryRunOptions(cmd *cobra.Command) HistoryRunOptio
 		opts.Resume = &v
 	}
 
+	opts.Seed = rc.seed
+	opts.AnalyzerTimeout = rc.analyzerTimeout
+	opts.AnalyzerChunkTimeout = rc.analyzerChunkTimeout
+	opts.AnalyzerFacts = analyzerFlagFacts(cmd)
+
 	return opts
 }
 
@@ -629,16 +1202,36 @@ func runHistoryAnalyzers(
 	restoreLogger := suppressStandardLogger(silent)
 	defer restoreLogger()
 
+	if opts.CPUProfileSplit && opts.CPUProfile == "" {
+		return ErrCPUProfileSplitUsage
+	}
+
+	framework.EnableProfileLabels(opts.CPUProfileSplit)
+
 	stopProfiler, err := framework.MaybeStartCPUProfile(opts.CPUProfile)
 	if err != nil {
 		return err
 	}
 
-	defer stopProfiler()
+	defer stopCPUProfile(opts, stopProfiler)
 	defer framework.MaybeWriteHeapProfile(opts.HeapProfile, nil)
 
+	if opts.PprofAddr != "" {
+		opts.Control = framework.NewControl()
+	}
+
+	stopPprof, _, err := framework.MaybeStartPprofServer(ctx, nil, opts.PprofAddr, opts.Control)
+	if err != nil {
+		return err
+	}
+
+	defer stopPprof()
+
 	configureLibgit2MemoryLimits(opts.MemoryBudget)
 
+	closeMirror := mirrorODB(ctx, path, opts.ODBCacheDir)
+	defer closeMirror()
+
 	result, err := initHistoryPipeline(ctx, path, analyzerIDs, format, opts)
 	if err != nil {
 		return err
@@ -651,7 +1244,7 @@ func runHistoryAnalyzers(
 
 	return executeHistoryPipeline(
 		ctx, result.pipeline, path, result.selectedLeaves,
-		result.commits, result.commitIter, result.commitCount,
+		result.commits, result.commitIter, result.commitCount, result.commitWeights, result.sampleFactor,
 		result.analyzerKeys, result.format, opts, result.repository, writer,
 	)
 }
@@ -663,6 +1256,8 @@ type initResult struct {
 	commits        []*gitlib.Commit   // Used only for HeadOnly mode.
 	commitIter     *gitlib.CommitIter // Iterator for streaming mode.
 	commitCount    int                // Total commits for streaming mode.
+	commitWeights  []int64            // Work estimate per commit; nil unless --balance-chunks.
+	sampleFactor   float64            // Ratio of walked to sampled commits; 1 without sampling.
 	selectedLeaves []analyze.HistoryAnalyzer
 	analyzerKeys   []string
 	format         string
@@ -689,7 +1284,7 @@ func initHistoryPipeline(
 		return initResult{}, ErrNoAnalyzersSelected
 	}
 
-	normalizedFormat, err := analyze.ValidateUniversalFormat(format)
+	normalizedFormat, err := analyze.ValidateFormat(format, analyze.HistoryOutputFormats())
 	if err != nil {
 		return initResult{}, err
 	}
@@ -700,6 +1295,7 @@ func initHistoryPipeline(
 	}
 
 	pl = buildPipeline(repository)
+	pl.CommitTable = opts.WithCommitTable
 
 	if slices.Contains(analyzerKeys, "burndown") && !opts.FirstParent {
 		opts.FirstParent = true
@@ -707,11 +1303,11 @@ func initHistoryPipeline(
 
 	// HeadOnly mode: load a single commit, no iterator needed.
 	if opts.Head {
-		return initHeadOnly(ctx, repository, pl, analyzerKeys, normalizedFormat, initSpan)
+		return initHeadOnly(ctx, repository, pl, analyzerKeys, normalizedFormat, opts, initSpan)
 	}
 
 	// Streaming mode: count commits and create a reverse iterator.
-	return initStreamingIterator(repository, pl, analyzerKeys, normalizedFormat, opts, initSpan)
+	return initStreamingIterator(ctx, repository, pl, analyzerKeys, normalizedFormat, opts, initSpan)
 }
 
 // initHeadOnly loads only the HEAD commit and returns an initResult for head-only analysis.
@@ -721,6 +1317,7 @@ func initHeadOnly(
 	pl *historyPipeline,
 	analyzerKeys []string,
 	normalizedFormat string,
+	opts HistoryRunOptions,
 	initSpan trace.Span,
 ) (initResult, error) {
 	commits, loadErr := gitlib.LoadCommits(ctx, repository, gitlib.CommitLoadOptions{
@@ -732,7 +1329,8 @@ func initHeadOnly(
 		return initResult{}, loadErr
 	}
 
-	selectedLeaves, configErr := configureAndSelect(pl, analyzerKeys)
+	selectedLeaves, configErr := configureAndSelect(pl, analyzerKeys, opts.AnalyzerFacts, storeDirFacts(opts),
+		headTreeFacts(ctx, repository))
 	if configErr != nil {
 		repository.Free()
 
@@ -756,6 +1354,7 @@ func initHeadOnly(
 
 // initStreamingIterator counts commits and creates a reverse iterator for streaming analysis.
 func initStreamingIterator(
+	ctx context.Context,
 	repository *gitlib.Repository,
 	pl *historyPipeline,
 	analyzerKeys []string,
@@ -785,6 +1384,13 @@ func initStreamingIterator(
 		return initResult{}, fmt.Errorf("failed to count commits: %w", err)
 	}
 
+	sampleFactor, commitCount, err := applySampling(repository, logOpts, opts, commitCount)
+	if err != nil {
+		repository.Free()
+
+		return initResult{}, err
+	}
+
 	if opts.Limit > 0 && opts.Limit < commitCount {
 		commitCount = opts.Limit
 	}
@@ -793,6 +1399,17 @@ func initStreamingIterator(
 	// for --first-parent.
 	logOpts.Reverse = true
 
+	var commitWeights []int64
+
+	if opts.BalanceChunks {
+		commitWeights, err = scanCommitWeights(ctx, repository, logOpts, commitCount)
+		if err != nil {
+			repository.Free()
+
+			return initResult{}, err
+		}
+	}
+
 	iter, err := repository.Log(logOpts)
 	if err != nil {
 		repository.Free()
@@ -800,7 +1417,8 @@ func initStreamingIterator(
 		return initResult{}, fmt.Errorf("failed to create commit iterator: %w", err)
 	}
 
-	selectedLeaves, configErr := configureAndSelect(pl, analyzerKeys)
+	selectedLeaves, configErr := configureAndSelect(pl, analyzerKeys, opts.AnalyzerFacts, seedFacts(opts),
+		storeDirFacts(opts), headTreeFacts(ctx, repository))
 	if configErr != nil {
 		iter.Close()
 		repository.Free()
@@ -819,39 +1437,261 @@ func initStreamingIterator(
 		repository:     repository,
 		commitIter:     iter,
 		commitCount:    commitCount,
+		commitWeights:  commitWeights,
+		sampleFactor:   sampleFactor,
 		selectedLeaves: selectedLeaves,
 		analyzerKeys:   analyzerKeys,
 		format:         normalizedFormat,
 	}, nil
 }
 
-// configureAndSelect configures core analyzers with facts and selects leaf analyzers.
-func configureAndSelect(pl *historyPipeline, analyzerKeys []string) ([]analyze.HistoryAnalyzer, error) {
-	facts := buildFacts(pl)
+// applySampling enables commit sampling on logOpts when requested and returns
+// the ratio of walked to sampled commits, 1 without sampling, together with
+// the sampled commit count.
+func applySampling(
+	repository *gitlib.Repository, logOpts *gitlib.LogOptions, opts HistoryRunOptions, commitCount int,
+) (float64, int, error) {
+	strategy, err := gitlib.ParseSampleStrategy(opts.SampleStrategy)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid --sample-strategy: %w", err)
+	}
+
+	logOpts.SampleEvery = opts.SampleEvery
+	logOpts.SampleStrategy = strategy
+	logOpts.SampleSeed = opts.Seed
 
-	// Configure core (plumbing) analyzers first so they can publish facts
-	// (e.g. TicksSinceStart publishes FactCommitsByTick) that leaves depend on.
-	err := configureAnalyzers(pl.Core, facts)
+	if !logOpts.Sampled() {
+		return 1, commitCount, nil
+	}
+
+	sampledCount, err := repository.CommitCount(logOpts)
 	if err != nil {
-		return nil, err
+		return 0, 0, fmt.Errorf("failed to count sampled commits: %w", err)
+	}
+
+	if sampledCount == 0 {
+		return 1, 0, nil
 	}
 
-	selectedLeaves, err := selectLeaves(pl.Leaves, analyzerKeys, facts)
+	factor := float64(commitCount) / float64(sampledCount)
+
+	slog.Default().Info("commit sampling enabled",
+		"strategy", string(strategy), "commits", commitCount, "sampled", sampledCount, "factor", factor)
+
+	return factor, sampledCount, nil
+}
+
+// scanCommitWeights estimates the work of each of the commitCount commits of
+// logOpts from the sizes of the blobs they change, for balancing chunks.
+func scanCommitWeights(
+	ctx context.Context, repository *gitlib.Repository, logOpts *gitlib.LogOptions, commitCount int,
+) ([]int64, error) {
+	start := time.Now()
+
+	workloads, err := repository.ScanWorkload(ctx, logOpts, commitCount)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("balance-chunks: %w", err)
+	}
+
+	weights := make([]int64, len(workloads))
+
+	var blobBytes int64
+
+	for i, workload := range workloads {
+		weights[i] = workload.Weight()
+		blobBytes += workload.BlobBytes
 	}
 
-	return selectedLeaves, nil
+	slog.Default().Info("commit workload scanned",
+		"commits", len(weights), "blob_bytes", blobBytes, "duration", time.Since(start))
+
+	return weights, nil
 }
 
-func executeHistoryPipeline(
-	ctx context.Context,
-	pl *historyPipeline,
-	path string,
+// seedFacts returns the fact seeding the randomness of analyzers with --seed.
+func seedFacts(opts HistoryRunOptions) map[string]any {
+	return map[string]any{pkgplumbing.FactSeed: opts.Seed}
+}
+
+// storeDirFacts returns the fact placing analyzer working-state stores in
+// --store-dir, or nil when it is unset.
+func storeDirFacts(opts HistoryRunOptions) map[string]any {
+	if opts.StoreDir == "" {
+		return nil
+	}
+
+	return map[string]any{pkgplumbing.FactStoreDir: opts.StoreDir}
+}
+
+// headTreeFacts returns the facts derived from the HEAD tree: the projects
+// found in it and its CODEOWNERS file, or nil when it has neither. Both are
+// best effort: a repository whose tree cannot be read is analyzed as a
+// single, unowned project.
+func headTreeFacts(ctx context.Context, repository *gitlib.Repository) map[string]any {
+	head, err := repository.Head()
+	if err != nil {
+		return nil
+	}
+
+	commit, err := repository.LookupCommit(ctx, head)
+	if err != nil {
+		return nil
+	}
+	defer commit.Free()
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil
+	}
+	defer tree.Free()
+
+	facts := make(map[string]any)
+
+	if projects := discoverProjects(repository, tree); len(projects) > 0 {
+		slog.Default().Info("projects discovered", "count", len(projects))
+
+		facts[pkgplumbing.FactProjects] = projects
+	}
+
+	if owners := readCodeOwners(ctx, repository, tree); owners != nil {
+		facts[pkgplumbing.FactCodeOwners] = owners
+	}
+
+	return facts
+}
+
+// discoverProjects finds the project roots among the files of tree.
+func discoverProjects(repository *gitlib.Repository, tree *gitlib.Tree) pkgplumbing.Projects {
+	files, err := gitlib.TreeFiles(repository, tree)
+	if err != nil {
+		slog.Default().Warn("project discovery failed", "error", err)
+
+		return nil
+	}
+
+	names := make([]string, len(files))
+
+	for i, file := range files {
+		names[i] = file.Name
+	}
+
+	return pkgplumbing.DiscoverProjects(names)
+}
+
+// readCodeOwners parses the first CODEOWNERS file of tree, or returns nil
+// when it has none.
+func readCodeOwners(ctx context.Context, repository *gitlib.Repository, tree *gitlib.Tree) *pkgcodeowners.Ruleset {
+	for _, path := range pkgcodeowners.Paths {
+		entry, err := tree.EntryByPath(path)
+		if err != nil || !entry.IsBlob() {
+			continue
+		}
+
+		blob, err := repository.LookupBlob(ctx, entry.Hash())
+		if err != nil {
+			slog.Default().Warn("read CODEOWNERS failed", "path", path, "error", err)
+
+			return nil
+		}
+
+		owners := pkgcodeowners.Parse(blob.Contents())
+		blob.Free()
+
+		slog.Default().Info("CODEOWNERS loaded", "path", path, "rules", owners.Len())
+
+		return owners
+	}
+
+	return nil
+}
+
+// mirrorODB mirrors the pack files of the repository at path into dir, so
+// every repository handle of the run reads objects from local storage.
+// Returns a function closing the mirror. A failed mirror is logged and the
+// run reads from the repository as usual.
+func mirrorODB(ctx context.Context, path, dir string) func() {
+	if dir == "" {
+		return func() {}
+	}
+
+	start := time.Now()
+
+	mirror, err := gitlib.MirrorODB(ctx, path, dir)
+	if err != nil {
+		slog.Default().WarnContext(ctx, "odb mirror failed, reading objects from the repository", "error", err)
+
+		return func() {}
+	}
+
+	stats := mirror.Stats()
+	slog.Default().InfoContext(ctx, "odb mirror ready",
+		"dir", mirror.Dir(), "packs", stats.Packs, "bytes", stats.Bytes,
+		"copied_bytes", stats.CopiedBytes, "removed_files", stats.Removed,
+		"duration", time.Since(start))
+
+	return mirror.Close
+}
+
+// prepareWorkDirs creates the --spill-dir and --store-dir directories and
+// logs each one on a memory-backed filesystem, such as a tmpfs /tmp, where
+// spilled state stays in RAM and the memory budget no longer holds.
+func prepareWorkDirs(ctx context.Context, opts HistoryRunOptions) error {
+	warned := make(map[string]bool)
+
+	for _, wd := range []struct{ flag, dir string }{
+		{"spill-dir", opts.SpillDir},
+		{"store-dir", opts.StoreDir},
+	} {
+		dir := wd.dir
+		if dir == "" {
+			dir = os.TempDir()
+		} else {
+			err := os.MkdirAll(dir, 0o750)
+			if err != nil {
+				return fmt.Errorf("%s: %w", wd.flag, err)
+			}
+		}
+
+		fsType, memoryBacked := streaming.MemoryBackedFS(dir)
+		if !memoryBacked || warned[dir] {
+			continue
+		}
+
+		warned[dir] = true
+
+		// An explicit choice is likely a mistake; a tmpfs /tmp is a distro
+		// default and only matters once the run actually spills.
+		level := slog.LevelWarn
+		if wd.dir == "" {
+			level = slog.LevelInfo
+		}
+
+		slog.Default().Log(ctx, level, "spill directory is memory-backed; spilled state still uses RAM, "+
+			"point --"+wd.flag+" at a disk-backed directory to keep within the memory budget",
+			"dir", dir, "fs", fsType)
+	}
+
+	return nil
+}
+
+// configureAndSelect configures core analyzers with facts and selects leaf analyzers.
+// Extra facts (CLI-set options, the seed) override defaults.
+func configureAndSelect(
+	pl *historyPipeline, analyzerKeys []string, extraFacts ...map[string]any,
+) ([]analyze.HistoryAnalyzer, error) {
+	return pl.Configure(analyzerKeys, extraFacts...)
+}
+
+func executeHistoryPipeline(
+	ctx context.Context,
+	pl *historyPipeline,
+	path string,
 	selectedLeaves []analyze.HistoryAnalyzer,
 	commits []*gitlib.Commit,
 	commitIter *gitlib.CommitIter,
 	commitCount int,
+	commitWeights []int64,
+	sampleFactor float64,
 	analyzerKeys []string,
 	normalizedFormat string,
 	opts HistoryRunOptions,
@@ -874,29 +1714,124 @@ func executeHistoryPipeline(
 		MemoryBudget:    opts.MemoryBudget,
 		GCPercent:       opts.GCPercent,
 		BallastSize:     opts.BallastSize,
+		BlobCacheCaps:   opts.BlobCacheCaps,
 	}, budget.SolveForBudget)
 	if err != nil {
 		return err
 	}
 
 	coordConfig.FirstParent = opts.FirstParent
+	coordConfig.UASTServiceURL = opts.UASTService
+	coordConfig.Queues = framework.NewPipelineQueues()
+
+	if len(opts.ChunkWorkers) > 0 {
+		coordConfig.ChunkWorkers = framework.NewChunkWorkers(opts.ChunkWorkers)
+	}
+
+	if opts.Nice {
+		framework.EnterNiceMode(ctx, slog.Default(), &coordConfig)
+	}
+
+	if opts.PackOrder {
+		framework.EnablePackOrder(ctx, slog.Default(), &coordConfig, repository)
+	}
+
+	onCommitError, err := framework.ParseCommitErrorPolicy(opts.OnCommitError)
+	if err != nil {
+		return err
+	}
+
+	commitTimeouts, err := framework.ParseAnalyzerTimeouts(opts.AnalyzerTimeout)
+	if err != nil {
+		return fmt.Errorf("analyzer-timeout: %w", err)
+	}
+
+	chunkTimeouts, err := framework.ParseAnalyzerTimeouts(opts.AnalyzerChunkTimeout)
+	if err != nil {
+		return fmt.Errorf("analyzer-chunk-timeout: %w", err)
+	}
+
+	spillCodec, err := codec.Parse(opts.SpillCodec)
+	if err != nil {
+		return fmt.Errorf("spill-codec: %w", err)
+	}
+
+	var cipher *crypt.Cipher
+
+	if opts.EncryptAtRest {
+		cipher, err = crypt.FromEnv()
+		if err != nil {
+			return fmt.Errorf("encrypt-at-rest: %w", err)
+		}
+
+		if !strings.HasSuffix(spillCodec.Name(), codec.EncryptedSuffix) {
+			spillCodec = codec.Encrypted(spillCodec, cipher)
+		}
+	}
+
+	err = prepareWorkDirs(ctx, opts)
+	if err != nil {
+		return err
+	}
 
-	if !needsUAST(selectedLeaves) {
-		coordConfig.UASTPipelineWorkers = 0
+	partition, err := parseOutputPartition(opts, normalizedFormat)
+	if err != nil {
+		return err
 	}
 
 	runner := framework.NewRunnerWithConfig(repository, path, coordConfig, allAnalyzers...)
 	runner.CoreCount = len(pl.Core)
+	runner.OnCommitError = onCommitError
+	runner.CommitTimeouts = commitTimeouts
+	runner.ChunkTimeouts = chunkTimeouts
+	runner.SpillCodec = spillCodec
+	runner.SpillDir = opts.SpillDir
+	runner.Control = opts.Control
+	runner.Nice = opts.Nice
+	runner.CommitLookahead = opts.CommitLookahead
+	runner.CommitTable = opts.WithCommitTable
+	runner.SampleFactor = sampleFactor
+
+	redactModes, err := redact.ParseModes(opts.Redact)
+	if err != nil {
+		return fmt.Errorf("redact: %w", err)
+	}
+
+	if redactModes != 0 {
+		runner.Redactor = redact.FromEnv(redactModes)
+	}
+
+	runner.DerivedMetrics, err = analyze.RegisteredDerivedMetrics()
+	if err != nil {
+		return err
+	}
 
 	red, analysisMetrics, metricsErr := createRunMetrics()
 	if metricsErr != nil {
 		return metricsErr
 	}
 
+	metricsErr = observability.RegisterPipelineMetrics(otel.Meter("codefang"), coordConfig.Queues)
+	if metricsErr != nil {
+		return fmt.Errorf("create pipeline metrics: %w", metricsErr)
+	}
+
 	done := red.TrackInflight(ctx, "cli.run")
 	runStart := time.Now()
 
-	streamConfig := buildStreamingConfig(path, analyzerKeys, memBudget, opts, analysisMetrics, normalizedFormat, writer)
+	streamConfig, bufferedSink, err := buildStreamingConfig(
+		path, analyzerKeys, memBudget, opts, analysisMetrics, normalizedFormat, writer)
+	if err != nil {
+		return err
+	}
+
+	streamConfig.CommitWeights = commitWeights
+	streamConfig.Checkpoint.Cipher = cipher
+
+	series, err := attachPartitionedTimeSeries(ctx, &streamConfig, runner, selectedLeaves, partition, opts)
+	if err != nil {
+		return err
+	}
 
 	var results map[analyze.HistoryAnalyzer]analyze.Report
 
@@ -906,28 +1841,92 @@ func executeHistoryPipeline(
 		results, err = framework.RunStreaming(ctx, runner, commits, allAnalyzers, streamConfig)
 	}
 
+	closeBufferedSink(ctx, bufferedSink, analysisMetrics)
+	reportPromisorFetches(ctx, repository, analysisMetrics)
 	recordRunCompletion(ctx, red, done, runStart, err)
 
+	if opts.PipelineDebug {
+		dumpPipelineQueues(os.Stderr, coordConfig.Queues)
+	}
+
 	if err != nil {
+		if series != nil {
+			series.Abort()
+		}
+
 		return fmt.Errorf("pipeline execution failed: %w", err)
 	}
 
+	if qs := runner.Quality(); qs != nil {
+		slog.Default().Warn("commits skipped after errors",
+			"skipped_commits", qs.SkippedCommits, "failures", len(qs.Failures), "timeouts", qs.Timeouts,
+			"disabled", qs.Disabled, "policy", string(onCommitError))
+	}
+
 	// In NDJSON mode, output was already written by the sink.
 	if normalizedFormat == analyze.FormatNDJSON {
 		return nil
 	}
 
-	enrichAnomalyReport(selectedLeaves, results)
+	if series != nil {
+		return series.Close(ctx, analyze.BuildForecasts(selectedLeaves, results))
+	}
+
+	if normalizedFormat == analyze.FormatGraph && opts.OutputDir != "" {
+		return analyze.OutputGraphFiles(selectedLeaves, results, opts.OutputDir)
+	}
+
+	return renderReport(ctx, selectedLeaves, results, normalizedFormat, writer, opts.Seed)
+}
+
+// attachPartitionedTimeSeries makes the streaming run write the partitioned
+// time series of --output-partition chunk by chunk: the TCs of a chunk are
+// observed into a ChunkedTimeSeries, which writes and releases them once the
+// chunk is done. It returns nil without a partition.
+func attachPartitionedTimeSeries(
+	ctx context.Context, cfg *framework.StreamingConfig, runner *framework.Runner,
+	leaves []analyze.HistoryAnalyzer, partition analyze.OutputPartition, opts HistoryRunOptions,
+) (*analyze.ChunkedTimeSeries, error) {
+	if partition == analyze.PartitionNone {
+		return nil, nil //nolint:nilnil // no partition, nothing to attach.
+	}
+
+	writer, err := analyze.NewPartitionWriter(partition, opts.OutputDir, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	series := analyze.NewChunkedTimeSeries(leaves, writer, opts.Seed)
+	series.AuthorName = runner.AuthorName
+
+	cfg.TCObserver = series.Observe
+	cfg.OnProgress = func(framework.Progress) { series.FlushChunk(ctx) }
+
+	return series, nil
+}
+
+// parseOutputPartition validates --output-partition against the output format and directory.
+func parseOutputPartition(opts HistoryRunOptions, normalizedFormat string) (analyze.OutputPartition, error) {
+	partition, err := analyze.ParseOutputPartition(opts.OutputPartition)
+	if err != nil {
+		return "", err
+	}
+
+	if partition != analyze.PartitionNone && (normalizedFormat != analyze.FormatTimeSeries || opts.OutputDir == "") {
+		return "", ErrOutputPartitionUsage
+	}
 
-	return renderReport(ctx, selectedLeaves, results, normalizedFormat, writer)
+	return partition, nil
 }
 
 // buildStreamingConfig creates a StreamingConfig, wiring a TCSink when NDJSON format is requested.
+// The returned BufferedSink is non-nil when the sink options ask for buffering and must be closed
+// after the run.
 func buildStreamingConfig(
 	path string, analyzerKeys []string, memBudget int64,
 	opts HistoryRunOptions, analysisMetrics *observability.AnalysisMetrics,
 	normalizedFormat string, writer io.Writer,
-) framework.StreamingConfig {
+) (framework.StreamingConfig, *analyze.BufferedSink, error) {
 	cfg := framework.StreamingConfig{
 		MemBudget:       memBudget,
 		Logger:          slog.Default(),
@@ -938,13 +1937,120 @@ func buildStreamingConfig(
 		AnalysisMetrics: analysisMetrics,
 	}
 
+	if opts.DiskBudget != "" {
+		diskBudget, err := humanize.ParseBytes(opts.DiskBudget)
+		if err != nil {
+			return cfg, nil, fmt.Errorf("disk-budget: %w", err)
+		}
+
+		cfg.DiskBudget = framework.SafeInt64(diskBudget)
+	}
+
+	sinkPolicy, err := analyze.ParseSinkPolicy(opts.SinkPolicy)
+	if err != nil {
+		return cfg, nil, err
+	}
+
 	// NDJSON mode: write one JSON line per TC directly to writer, bypass aggregators.
-	if normalizedFormat == analyze.FormatNDJSON {
-		sink := analyze.NewStreamingSink(writer)
-		cfg.TCSink = sink.WriteTC
+	if normalizedFormat != analyze.FormatNDJSON {
+		return cfg, nil, nil
 	}
 
-	return cfg
+	var writeTC analyze.TCSink = analyze.NewStreamingSink(writer).WriteTC
+	if opts.OutputSink != nil {
+		writeTC = opts.OutputSink.WriteTC
+	}
+
+	cfg.TCSink = writeTC
+
+	if opts.SinkBuffer <= 0 && opts.SinkSampleEvery <= 1 && sinkPolicy == analyze.SinkPolicyBlock {
+		return cfg, nil, nil
+	}
+
+	buffered := analyze.NewBufferedSink(writeTC, analyze.BufferedSinkOptions{
+		Buffer:      opts.SinkBuffer,
+		Policy:      sinkPolicy,
+		SampleEvery: opts.SinkSampleEvery,
+	})
+	cfg.TCSink = buffered.WriteTC
+	cfg.SinkOnWritten = buffered.OnWritten
+	cfg.SinkFlush = buffered.Flush
+
+	return cfg, buffered, nil
+}
+
+// dumpPipelineQueues writes the queue statistics of the coordinator stages
+// for --pipeline-debug.
+func dumpPipelineQueues(w io.Writer, queues *framework.PipelineQueues) {
+	err := framework.WritePipelineDebug(w, queues.Stats())
+	if err != nil {
+		slog.Default().Warn("pipeline debug dump failed", "error", err)
+	}
+}
+
+// reportPromisorFetches logs and records the objects a partial clone fetched
+// from its promisor remote during the run.
+func reportPromisorFetches(ctx context.Context, repository *gitlib.Repository, analysisMetrics *observability.AnalysisMetrics) {
+	promisor := repository.Promisor()
+	if promisor == nil {
+		return
+	}
+
+	stats := promisor.Stats()
+
+	analysisMetrics.RecordPromisorFetches(ctx, observability.PromisorFetchStats{
+		Fetches:  stats.Fetches,
+		Objects:  stats.Objects,
+		Failures: stats.Failures,
+	})
+
+	slog.Default().Info("partial clone: fetched missing objects",
+		"remote", stats.Remote, "fetches", stats.Fetches, "objects", stats.Objects,
+		"failures", stats.Failures, "duration", stats.Duration)
+}
+
+// closeBufferedSink flushes the buffered NDJSON sink and reports its record counters.
+// Like unbuffered sink writes, output errors are logged rather than failing the run.
+func closeBufferedSink(ctx context.Context, sink *analyze.BufferedSink, analysisMetrics *observability.AnalysisMetrics) {
+	if sink == nil {
+		return
+	}
+
+	closeErr := sink.Close()
+	stats := sink.Stats()
+
+	analysisMetrics.RecordSink(ctx, observability.SinkStats{
+		Written: int64(stats.Written),
+		Dropped: int64(stats.Dropped),
+		Sampled: int64(stats.Sampled),
+		Failed:  int64(stats.Failed),
+	})
+
+	if stats.Dropped > 0 || stats.Failed > 0 {
+		slog.Default().Warn("ndjson records not written",
+			"dropped", stats.Dropped, "failed", stats.Failed, "written", stats.Written, "error", closeErr)
+	}
+}
+
+// stopCPUProfile stops CPU profiling and, with --cpuprofile-split, writes one
+// profile per pipeline stage and per analyzer next to the CPU profile.
+func stopCPUProfile(opts HistoryRunOptions, stop func()) {
+	stop()
+
+	if !opts.CPUProfileSplit {
+		return
+	}
+
+	framework.EnableProfileLabels(false)
+
+	files, err := framework.SplitCPUProfile(opts.CPUProfile, framework.ProfileLabelStage, framework.ProfileLabelAnalyzer)
+	if err != nil {
+		slog.Default().Warn("could not split cpu profile", "path", opts.CPUProfile, "error", err)
+
+		return
+	}
+
+	slog.Default().Info("cpu profile split by stage and analyzer", "files", files)
 }
 
 // renderReport writes analysis results in the requested format, wrapped in a tracing span.
@@ -954,6 +2060,7 @@ func renderReport(
 	results map[analyze.HistoryAnalyzer]analyze.Report,
 	normalizedFormat string,
 	writer io.Writer,
+	seed uint64,
 ) error {
 	tr := otel.Tracer("codefang")
 	_, reportSpan := tr.Start(ctx, "codefang.report",
@@ -962,7 +2069,7 @@ func renderReport(
 			attribute.Int("report.analyzers", len(selectedLeaves)),
 		))
 
-	reportErr := analyze.OutputHistoryResults(selectedLeaves, results, normalizedFormat, writer)
+	reportErr := analyze.OutputHistoryResults(selectedLeaves, results, normalizedFormat, writer, seed)
 
 	reportSpan.End()
 
@@ -1001,112 +2108,6 @@ func recordRunCompletion(ctx context.Context, red *observability.REDMetrics, don
 	red.RecordRequest(ctx, "cli.run", status, duration)
 }
 
-// enrichAnomalyReport runs cross-analyzer anomaly detection on time series
-// from other analyzers and injects results into the anomaly report.
-func enrichAnomalyReport(
-	leaves []analyze.HistoryAnalyzer,
-	results map[analyze.HistoryAnalyzer]analyze.Report,
-) {
-	var anomalyAnalyzer *anomaly.Analyzer
-
-	var anomalyReport analyze.Report
-
-	for _, leaf := range leaves {
-		if a, ok := leaf.(*anomaly.Analyzer); ok {
-			anomalyAnalyzer = a
-			anomalyReport = results[leaf]
-
-			break
-		}
-	}
-
-	if anomalyAnalyzer == nil || anomalyReport == nil {
-		return
-	}
-
-	otherReports := make(map[string]analyze.Report)
-
-	for _, leaf := range leaves {
-		if leaf == anomalyAnalyzer {
-			continue
-		}
-
-		if rep := results[leaf]; rep != nil {
-			otherReports[leaf.Flag()] = rep
-		}
-	}
-
-	if len(otherReports) == 0 {
-		return
-	}
-
-	anomaly.EnrichFromReports(
-		anomalyReport,
-		otherReports,
-		anomalyAnalyzer.WindowSize,
-		float64(anomalyAnalyzer.Threshold),
-	)
-}
-
-func selectLeaves(
-	leaves map[string]analyze.HistoryAnalyzer,
-	keys []string,
-	facts map[string]any,
-) ([]analyze.HistoryAnalyzer, error) {
-	var selected []analyze.HistoryAnalyzer
-
-	for _, name := range keys {
-		leaf, found := leaves[name]
-		if !found {
-			return nil, fmt.Errorf(
-				"%w: %s\nAvailable: anomaly, burndown, couples, devs, file-history, imports, quality, sentiment, shotness, typos",
-				ErrUnknownAnalyzer, name,
-			)
-		}
-
-		err := leaf.Configure(facts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to configure %s: %w", name, err)
-		}
-
-		selected = append(selected, leaf)
-	}
-
-	return selected, nil
-}
-
-func buildFacts(pl *historyPipeline) map[string]any {
-	facts := map[string]any{}
-
-	allAnalyzers := make([]analyze.HistoryAnalyzer, 0, len(pl.Core)+len(pl.Leaves))
-	allAnalyzers = append(allAnalyzers, pl.Core...)
-
-	for _, leaf := range pl.Leaves {
-		allAnalyzers = append(allAnalyzers, leaf)
-	}
-
-	for _, a := range allAnalyzers {
-		for _, opt := range a.ListConfigurationOptions() {
-			if opt.Default != nil {
-				facts[opt.Name] = opt.Default
-			}
-		}
-	}
-
-	return facts
-}
-
-func configureAnalyzers(analyzers []analyze.HistoryAnalyzer, facts map[string]any) error {
-	for _, a := range analyzers {
-		err := a.Configure(facts)
-		if err != nil {
-			return fmt.Errorf("failed to configure %s: %w", a.Name(), err)
-		}
-	}
-
-	return nil
-}
-
 func buildCheckpointParams(opts HistoryRunOptions) framework.CheckpointParams {
 	params := framework.CheckpointParams{
 		Enabled:   true,
@@ -1127,22 +2128,19 @@ func buildCheckpointParams(opts HistoryRunOptions) framework.CheckpointParams {
 		params.Resume = *opts.Resume
 	}
 
+	// A resumed run has not seen the paths and messages of the commits
+	// before the checkpoint, so it could not redact them.
+	if opts.Redact != "" {
+		params.Enabled = false
+	}
+
 	return params
 }
 
 func registerAnalyzerFlags(cobraCmd *cobra.Command) {
-	dummyPipeline := buildPipeline(nil)
-
-	allAnalyzers := make([]analyze.HistoryAnalyzer, 0, len(dummyPipeline.Core)+len(dummyPipeline.Leaves))
-	allAnalyzers = append(allAnalyzers, dummyPipeline.Core...)
-
-	for _, leaf := range dummyPipeline.Leaves {
-		allAnalyzers = append(allAnalyzers, leaf)
-	}
-
 	registeredFlags := make(map[string]bool)
 
-	for _, a := range allAnalyzers {
+	for _, a := range buildPipeline(nil).Analyzers() {
 		for _, opt := range a.ListConfigurationOptions() {
 			if registeredFlags[opt.Flag] {
 				continue
@@ -1154,6 +2152,51 @@ func registerAnalyzerFlags(cobraCmd *cobra.Command) {
 	}
 }
 
+// analyzerFlagFacts collects the analyzer configuration flags explicitly set
+// on the command line, keyed by configuration option name.
+func analyzerFlagFacts(cobraCmd *cobra.Command) map[string]any {
+	facts := make(map[string]any)
+
+	for _, a := range buildPipeline(nil).Analyzers() {
+		for _, opt := range a.ListConfigurationOptions() {
+			if !cobraCmd.Flags().Changed(opt.Flag) {
+				continue
+			}
+
+			if v, ok := configFlagValue(cobraCmd, opt); ok {
+				facts[opt.Name] = v
+			}
+		}
+	}
+
+	return facts
+}
+
+// configFlagValue reads the value of a flag registered by registerConfigFlag.
+func configFlagValue(cobraCmd *cobra.Command, opt pipeline.ConfigurationOption) (any, bool) {
+	var (
+		v   any
+		err error
+	)
+
+	switch opt.Type {
+	case pipeline.BoolConfigurationOption:
+		v, err = cobraCmd.Flags().GetBool(opt.Flag)
+	case pipeline.IntConfigurationOption:
+		v, err = cobraCmd.Flags().GetInt(opt.Flag)
+	case pipeline.StringConfigurationOption, pipeline.PathConfigurationOption:
+		v, err = cobraCmd.Flags().GetString(opt.Flag)
+	case pipeline.StringsConfigurationOption:
+		v, err = cobraCmd.Flags().GetStringSlice(opt.Flag)
+	case pipeline.FloatConfigurationOption:
+		v, err = cobraCmd.Flags().GetFloat64(opt.Flag)
+	default:
+		return nil, false
+	}
+
+	return v, err == nil
+}
+
 func registerConfigFlag(cobraCmd *cobra.Command, opt pipeline.ConfigurationOption) {
 	switch opt.Type {
 	case pipeline.BoolConfigurationOption:
@@ -1183,20 +2226,6 @@ func registerConfigFlag(cobraCmd *cobra.Command, opt pipeline.ConfigurationOptio
 	}
 }
 
-type uastDependent interface {
-	NeedsUAST() bool
-}
-
-func needsUAST(leaves []analyze.HistoryAnalyzer) bool {
-	for _, leaf := range leaves {
-		if ud, ok := leaf.(uastDependent); ok && ud.NeedsUAST() {
-			return true
-		}
-	}
-
-	return false
-}
-
 func (rc *RunCommand) isSilent(cmd *cobra.Command) bool {
 	if rc.silent {
 		return true
@@ -1273,128 +2302,15 @@ func suppressStandardLogger(silent bool) func() {
 	}
 }
 
-type historyPipeline struct {
-	Core   []analyze.HistoryAnalyzer
-	Leaves map[string]analyze.HistoryAnalyzer
-}
+// historyPipeline is the history analyzer pipeline shared with the library API.
+type historyPipeline = codefang.Pipeline
 
-func buildPipeline(repository *gitlib.Repository) *historyPipeline { //nolint:funlen // Expected length for pipeline initialization.
-	treeDiff := &plumbing.TreeDiffAnalyzer{Repository: repository}
-	identity := &plumbing.IdentityDetector{}
-	ticks := &plumbing.TicksSinceStart{}
-	blobCache := &plumbing.BlobCacheAnalyzer{TreeDiff: treeDiff, Repository: repository}
-	fileDiff := &plumbing.FileDiffAnalyzer{BlobCache: blobCache, TreeDiff: treeDiff}
-	lineStats := &plumbing.LinesStatsCalculator{TreeDiff: treeDiff, BlobCache: blobCache, FileDiff: fileDiff}
-	langDetect := &plumbing.LanguagesDetectionAnalyzer{TreeDiff: treeDiff, BlobCache: blobCache}
-	uastChanges := &plumbing.UASTChangesAnalyzer{TreeDiff: treeDiff, BlobCache: blobCache}
-
-	return &historyPipeline{
-		Core: []analyze.HistoryAnalyzer{
-			treeDiff, identity, ticks, blobCache, fileDiff, lineStats, langDetect, uastChanges,
-		},
-		Leaves: map[string]analyze.HistoryAnalyzer{
-			"anomaly": func() *anomaly.Analyzer {
-				a := anomaly.NewAnalyzer()
-				a.TreeDiff = treeDiff
-				a.Ticks = ticks
-				a.LineStats = lineStats
-				a.Languages = langDetect
-				a.Identity = identity
-
-				return a
-			}(),
-			"burndown": func() *burndown.HistoryAnalyzer {
-				a := burndown.NewHistoryAnalyzer()
-				a.BlobCache = blobCache
-				a.Ticks = ticks
-				a.Identity = identity
-				a.FileDiff = fileDiff
-				a.TreeDiff = treeDiff
-
-				return a
-			}(),
-			"couples": func() *couples.HistoryAnalyzer {
-				a := couples.NewHistoryAnalyzer()
-				a.Identity = identity
-				a.TreeDiff = treeDiff
-
-				return a
-			}(),
-			"devs": func() *devs.Analyzer {
-				a := devs.NewAnalyzer()
-				a.Identity = identity
-				a.TreeDiff = treeDiff
-				a.Ticks = ticks
-				a.Languages = langDetect
-				a.LineStats = lineStats
-
-				return a
-			}(),
-			"file-history": func() *filehistory.HistoryAnalyzer {
-				a := filehistory.NewAnalyzer()
-				a.Identity = identity
-				a.TreeDiff = treeDiff
-				a.LineStats = lineStats
-
-				return a
-			}(),
-			"imports": func() *imports.HistoryAnalyzer {
-				a := imports.NewHistoryAnalyzer()
-				a.TreeDiff = treeDiff
-				a.BlobCache = blobCache
-				a.Identity = identity
-				a.Ticks = ticks
-
-				return a
-			}(),
-			"quality": func() *quality.Analyzer {
-				a := quality.NewAnalyzer()
-				a.UAST = uastChanges
-				a.Ticks = ticks
-
-				return a
-			}(),
-			"sentiment": func() *sentiment.Analyzer {
-				a := sentiment.NewAnalyzer()
-				a.UAST = uastChanges
-				a.Ticks = ticks
-
-				return a
-			}(),
-			"shotness": func() *shotness.Analyzer {
-				a := shotness.NewAnalyzer()
-				a.FileDiff = fileDiff
-				a.UAST = uastChanges
-
-				return a
-			}(),
-			"typos": func() *typos.Analyzer {
-				a := typos.NewAnalyzer()
-				a.UAST = uastChanges
-				a.BlobCache = blobCache
-				a.FileDiff = fileDiff
-
-				return a
-			}(),
-		},
-	}
+func buildPipeline(repository *gitlib.Repository) *historyPipeline {
+	return codefang.BuildPipeline(repository)
 }
 
 func defaultHistoryLeaves() []analyze.HistoryAnalyzer {
-	leaves := buildPipeline(nil).Leaves
-
-	return []analyze.HistoryAnalyzer{
-		leaves["anomaly"],
-		leaves["burndown"],
-		leaves["couples"],
-		leaves["devs"],
-		leaves["file-history"],
-		leaves["imports"],
-		leaves["quality"],
-		leaves["sentiment"],
-		leaves["shotness"],
-		leaves["typos"],
-	}
+	return codefang.HistoryLeaves()
 }
 
 func defaultStaticAnalyzers() []analyze.StaticAnalyzer {
@@ -1404,5 +2320,7 @@ func defaultStaticAnalyzers() []analyze.StaticAnalyzer {
 		halstead.NewAnalyzer(),
 		cohesion.NewAnalyzer(),
 		imports.NewAnalyzer(),
+		arch.NewAnalyzer(),
+		deadcode.NewAnalyzer(),
 	}
 }
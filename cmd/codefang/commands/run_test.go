@@ -5,24 +5,33 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"gopkg.in/yaml.v3"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/renderer"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/config"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/observability"
 	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/redact"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
 )
 
@@ -133,6 +142,122 @@ func TestRunCommand_DispatchesBothModes(t *testing.T) {
 	require.Equal(t, analyze.FormatBinary, historyFormat)
 }
 
+func TestRunCommand_ConfigOutputSinks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	reportPath := filepath.Join(dir, "report.json")
+	cfgPath := filepath.Join(dir, "codefang.yaml")
+	content := "output:\n  sinks:\n    - type: stdout\n    - type: file\n      path: " + reportPath + "\n"
+	require.NoError(t, os.WriteFile(cfgPath, []byte(content), 0o600))
+
+	var sink analyze.OutputSink
+
+	command := newRunCommandWithDeps(
+		func(_ string, _ []string, _ string, _ bool, _ bool, _ io.Writer) error {
+			return nil
+		},
+		func(_ context.Context, _ string, _ []string, _ string, _ bool, opts HistoryRunOptions, writer io.Writer) error {
+			sink = opts.OutputSink
+
+			_, err := io.WriteString(writer, `{"devs":{}}`)
+
+			return err
+		},
+		stubRunRegistry,
+		noopObservabilityInit,
+	)
+
+	var out bytes.Buffer
+
+	command.SetOut(&out)
+	command.SetErr(io.Discard)
+	command.SetArgs([]string{"-a", "history/devs", "--config", cfgPath})
+	require.NoError(t, command.Execute())
+
+	require.NotNil(t, sink)
+	require.JSONEq(t, `{"devs":{}}`, out.String())
+
+	written, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"devs":{}}`, string(written))
+}
+
+func TestRunCommand_OutputFlag(t *testing.T) {
+	t.Parallel()
+
+	outputPath := filepath.Join(t.TempDir(), "report.json")
+
+	command := newRunCommandWithDeps(
+		func(_ string, _ []string, _ string, _ bool, _ bool, _ io.Writer) error {
+			return nil
+		},
+		func(_ context.Context, _ string, _ []string, _ string, _ bool, _ HistoryRunOptions, writer io.Writer) error {
+			_, err := io.WriteString(writer, `{"devs":{}}`)
+
+			return err
+		},
+		stubRunRegistry,
+		noopObservabilityInit,
+	)
+
+	var out bytes.Buffer
+
+	command.SetOut(&out)
+	command.SetErr(io.Discard)
+	command.SetArgs([]string{"-a", "history/devs", "--output", outputPath})
+	require.NoError(t, command.Execute())
+	require.Empty(t, out.String())
+
+	written, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"devs":{}}`, string(written))
+}
+
+func TestRunCommand_ConfigOutputSinks_Invalid(t *testing.T) {
+	t.Parallel()
+
+	cfgPath := filepath.Join(t.TempDir(), "codefang.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte("output:\n  sinks:\n    - type: kafka\n"), 0o600))
+
+	command := newRunCommandWithDeps(
+		func(_ string, _ []string, _ string, _ bool, _ bool, _ io.Writer) error {
+			t.Fatal("static executor should not be called")
+
+			return nil
+		},
+		func(_ context.Context, _ string, _ []string, _ string, _ bool, _ HistoryRunOptions, _ io.Writer) error {
+			t.Fatal("history executor should not be called")
+
+			return nil
+		},
+		stubRunRegistry,
+		noopObservabilityInit,
+	)
+
+	command.SetOut(io.Discard)
+	command.SetErr(io.Discard)
+	command.SetArgs([]string{"-a", "history/devs", "--config", cfgPath})
+	require.ErrorIs(t, command.Execute(), config.ErrInvalidOutputSinkType)
+}
+
+func TestBuildStreamingConfig_OutputSink(t *testing.T) {
+	t.Parallel()
+
+	var stdout, sinkOut bytes.Buffer
+
+	opts := HistoryRunOptions{OutputSink: analyze.NewWriterSink(&sinkOut)}
+
+	cfg, buffered, err := buildStreamingConfig(".", nil, 0, opts, nil, analyze.FormatNDJSON, &stdout)
+	require.NoError(t, err)
+	require.Nil(t, buffered)
+	require.NotNil(t, cfg.TCSink)
+
+	require.NoError(t, cfg.TCSink(analyze.TC{Data: 1}, "devs"))
+	require.Empty(t, stdout.String())
+	require.Contains(t, sinkOut.String(), `"analyzer":"devs"`)
+}
+
 func TestRunCommand_StaticOnly(t *testing.T) {
 	t.Parallel()
 
@@ -278,6 +403,10 @@ func TestRunCommand_ForwardsCommitSelectionFlags(t *testing.T) {
 		"--first-parent",
 		"--head",
 		"--since", "2024-01-01",
+		"--on-commit-error", "skip",
+		"--sample-every", "10",
+		"--sample-strategy", "random",
+		"--with-commit-table",
 	})
 
 	err := command.Execute()
@@ -286,6 +415,191 @@ func TestRunCommand_ForwardsCommitSelectionFlags(t *testing.T) {
 	require.True(t, seenOptions.FirstParent)
 	require.True(t, seenOptions.Head)
 	require.Equal(t, "2024-01-01", seenOptions.Since)
+	require.Equal(t, "skip", seenOptions.OnCommitError)
+	require.Equal(t, 10, seenOptions.SampleEvery)
+	require.Equal(t, "random", seenOptions.SampleStrategy)
+	require.True(t, seenOptions.WithCommitTable)
+}
+
+func TestRunCommand_ForwardsOutputPartitionFlags(t *testing.T) {
+	t.Parallel()
+
+	var seenOptions HistoryRunOptions
+
+	command := newRunCommandWithDeps(
+		func(_ string, _ []string, _ string, _ bool, _ bool, _ io.Writer) error {
+			return nil
+		},
+		func(_ context.Context, _ string, _ []string, _ string, _ bool, opts HistoryRunOptions, _ io.Writer) error {
+			seenOptions = opts
+
+			return nil
+		},
+		stubRunRegistry,
+		noopObservabilityInit,
+	)
+
+	command.SetArgs([]string{
+		"-a", "history/devs",
+		"--format", "timeseries",
+		"--output-partition", "month",
+		"--output-dir", "out",
+	})
+
+	err := command.Execute()
+	require.NoError(t, err)
+	require.Equal(t, "month", seenOptions.OutputPartition)
+	require.Equal(t, "out", seenOptions.OutputDir)
+}
+
+func TestRunCommand_Redact(t *testing.T) {
+	t.Parallel()
+
+	var seenOptions HistoryRunOptions
+
+	newCommand := func() *cobra.Command {
+		return newRunCommandWithDeps(
+			func(_ string, _ []string, _ string, _ bool, _ bool, _ io.Writer) error {
+				return nil
+			},
+			func(_ context.Context, _ string, _ []string, _ string, _ bool, opts HistoryRunOptions, _ io.Writer) error {
+				seenOptions = opts
+
+				return nil
+			},
+			stubRunRegistry,
+			noopObservabilityInit,
+		)
+	}
+
+	command := newCommand()
+	command.SetArgs([]string{"-a", "history/devs", "--redact", "authors,paths"})
+	require.NoError(t, command.Execute())
+	require.Equal(t, "authors,paths", seenOptions.Redact)
+	require.False(t, buildCheckpointParams(seenOptions).Enabled, "redacted runs do not checkpoint")
+
+	command = newCommand()
+	command.SetArgs([]string{"-a", "history/devs", "--redact", "emails"})
+	require.ErrorIs(t, command.Execute(), redact.ErrUnknownMode)
+
+	command = newCommand()
+	command.SetArgs([]string{"-a", "static/complexity", "--redact", "paths"})
+	require.ErrorIs(t, command.Execute(), ErrRedactUsage)
+}
+
+// TestRunCommand_Events sets the plot events of the process, so it does not
+// run in parallel.
+func TestRunCommand_Events(t *testing.T) {
+	t.Cleanup(func() { plotpage.SetEvents(nil) })
+
+	eventsPath := filepath.Join(t.TempDir(), "events.yaml")
+	require.NoError(t, os.WriteFile(eventsPath, []byte("- date: 2026-03-01\n  label: v2.0\n  kind: release\n"), 0o600))
+
+	var seenOptions HistoryRunOptions
+
+	newCommand := func() *cobra.Command {
+		return newRunCommandWithDeps(
+			nil,
+			func(_ context.Context, _ string, _ []string, _ string, _ bool, opts HistoryRunOptions, _ io.Writer) error {
+				seenOptions = opts
+
+				return nil
+			},
+			stubRunRegistry,
+			noopObservabilityInit,
+		)
+	}
+
+	command := newCommand()
+	command.SetArgs([]string{"-a", "history/devs", "--silent", "--format", "plot", "--events", eventsPath})
+	require.NoError(t, command.Execute())
+	require.True(t, seenOptions.WithCommitTable, "events are placed on ticks through the commit table")
+
+	command = newCommand()
+	command.SetArgs([]string{"-a", "history/devs", "--silent", "--events", eventsPath})
+	require.ErrorIs(t, command.Execute(), ErrEventsFormat)
+
+	badPath := filepath.Join(t.TempDir(), "bad.yaml")
+	require.NoError(t, os.WriteFile(badPath, []byte("- label: no date\n"), 0o600))
+
+	command = newCommand()
+	command.SetArgs([]string{"-a", "history/devs", "--silent", "--format", "plot", "--events", badPath})
+	require.ErrorIs(t, command.Execute(), plotpage.ErrInvalidEvent)
+}
+
+// TestRunCommand_ForecastDays sets the forecast horizon of the process, so it
+// does not run in parallel.
+func TestRunCommand_ForecastDays(t *testing.T) {
+	t.Cleanup(func() { analyze.SetForecastHorizon(0) })
+
+	newCommand := func() *cobra.Command {
+		return newRunCommandWithDeps(
+			nil,
+			func(_ context.Context, _ string, _ []string, _ string, _ bool, _ HistoryRunOptions, _ io.Writer) error {
+				return nil
+			},
+			stubRunRegistry,
+			noopObservabilityInit,
+		)
+	}
+
+	command := newCommand()
+	command.SetArgs([]string{"-a", "history/devs", "--silent", "--format", "timeseries", "--forecast-days", "180"})
+	require.NoError(t, command.Execute())
+
+	command = newCommand()
+	command.SetArgs([]string{"-a", "history/devs", "--silent", "--format", "json", "--forecast-days", "180"})
+	require.ErrorIs(t, command.Execute(), ErrForecastUsage)
+
+	command = newCommand()
+	command.SetArgs([]string{"--input", "report.json", "--format", "plot", "--forecast-days", "180"})
+	require.ErrorIs(t, command.Execute(), ErrForecastUsage)
+}
+
+func TestParseOutputPartition_RequiresTimeSeriesAndDir(t *testing.T) {
+	t.Parallel()
+
+	partition, err := parseOutputPartition(HistoryRunOptions{}, analyze.FormatJSON)
+	require.NoError(t, err)
+	require.Equal(t, analyze.PartitionNone, partition)
+
+	partition, err = parseOutputPartition(HistoryRunOptions{OutputPartition: "tick", OutputDir: "out"}, analyze.FormatTimeSeries)
+	require.NoError(t, err)
+	require.Equal(t, analyze.PartitionTick, partition)
+
+	_, err = parseOutputPartition(HistoryRunOptions{OutputPartition: "tick"}, analyze.FormatTimeSeries)
+	require.ErrorIs(t, err, ErrOutputPartitionUsage)
+
+	_, err = parseOutputPartition(HistoryRunOptions{OutputPartition: "tick", OutputDir: "out"}, analyze.FormatJSON)
+	require.ErrorIs(t, err, ErrOutputPartitionUsage)
+
+	_, err = parseOutputPartition(HistoryRunOptions{OutputPartition: "week", OutputDir: "out"}, analyze.FormatTimeSeries)
+	require.ErrorIs(t, err, analyze.ErrUnknownOutputPartition)
+}
+
+func TestRunCommand_ForwardsAnalyzerConfigFlags(t *testing.T) {
+	t.Parallel()
+
+	var seenOptions HistoryRunOptions
+
+	command := newRunCommandWithDeps(
+		func(_ string, _ []string, _ string, _ bool, _ bool, _ io.Writer) error {
+			return nil
+		},
+		func(_ context.Context, _ string, _ []string, _ string, _ bool, opts HistoryRunOptions, _ io.Writer) error {
+			seenOptions = opts
+
+			return nil
+		},
+		stubRunRegistry,
+		noopObservabilityInit,
+	)
+
+	command.SetArgs([]string{"-a", "history/devs", "--tick-granularity", "week"})
+
+	err := command.Execute()
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{plumbing.ConfigTicksSinceStartGranularity: "week"}, seenOptions.AnalyzerFacts)
 }
 
 func TestRunCommand_ForwardsProfilingFlags(t *testing.T) {
@@ -309,13 +623,17 @@ func TestRunCommand_ForwardsProfilingFlags(t *testing.T) {
 	command.SetArgs([]string{
 		"-a", "history/devs",
 		"--cpuprofile", "/tmp/cpu.prof",
+		"--cpuprofile-split",
 		"--heapprofile", "/tmp/heap.prof",
+		"--pprof-addr", "auto",
 	})
 
 	err := command.Execute()
 	require.NoError(t, err)
 	require.Equal(t, "/tmp/cpu.prof", seenOptions.CPUProfile)
+	require.True(t, seenOptions.CPUProfileSplit)
 	require.Equal(t, "/tmp/heap.prof", seenOptions.HeapProfile)
+	require.Equal(t, "auto", seenOptions.PprofAddr)
 }
 
 func TestRunCommand_ForwardsResourceTuningFlags(t *testing.T) {
@@ -345,6 +663,7 @@ func TestRunCommand_ForwardsResourceTuningFlags(t *testing.T) {
 		"--diff-cache-size", "5000",
 		"--blob-arena-size", "8MB",
 		"--memory-budget", "2GB",
+		"--commit-lookahead",
 	})
 
 	err := command.Execute()
@@ -356,6 +675,7 @@ func TestRunCommand_ForwardsResourceTuningFlags(t *testing.T) {
 	require.Equal(t, 5000, seenOptions.DiffCacheSize)
 	require.Equal(t, "8MB", seenOptions.BlobArenaSize)
 	require.Equal(t, "2GB", seenOptions.MemoryBudget)
+	require.True(t, seenOptions.CommitLookahead)
 }
 
 func TestRunCommand_ForwardsCheckpointFlags(t *testing.T) {
@@ -1298,3 +1618,127 @@ func TestRunCommand_RootSpanAttributes(t *testing.T) {
 	require.Equal(t, false, rootAttrs["error"], "error should be false on success")
 	require.Contains(t, rootAttrs, "codefang.duration_class", "root span should have duration_class")
 }
+
+func summaryMetricsInput(t *testing.T) string {
+	t.Helper()
+
+	inputPath := filepath.Join(t.TempDir(), "report.json")
+	input := `{
+  "version": "codefang.run.v1",
+  "analyzers": [
+    {
+      "id": "static/complexity",
+      "mode": "static",
+      "report": {"aggregate": {"average_complexity": 2.5}}
+    },
+    {
+      "id": "history/devs",
+      "mode": "history",
+      "report": {"aggregate": {"total_commits": 42, "project_bus_factor": 1}}
+    }
+  ]
+}`
+	require.NoError(t, os.WriteFile(inputPath, []byte(input), 0o600))
+
+	return inputPath
+}
+
+func TestRunCommand_EmitSummaryMetricsFile(t *testing.T) {
+	t.Parallel()
+
+	inputPath := summaryMetricsInput(t)
+	metricsPath := filepath.Join(t.TempDir(), "summary.prom")
+
+	command := newRunCommandWithDeps(nil, nil, stubRunRegistry, noopObservabilityInit)
+	command.SetOut(io.Discard)
+	command.SetArgs([]string{
+		"--input", inputPath,
+		"-a", "static/complexity,history/devs",
+		"--emit-summary-metrics", metricsPath,
+	})
+
+	require.NoError(t, command.Execute())
+
+	data, err := os.ReadFile(metricsPath)
+	require.NoError(t, err)
+
+	text := string(data)
+	require.Contains(t, text, "codefang_commits_analyzed 42.0")
+	require.Contains(t, text, "codefang_bus_factor_min 1.0")
+	require.Contains(t, text, "codefang_average_complexity 2.5")
+	require.True(t, strings.HasSuffix(text, "# EOF\n"))
+}
+
+func TestRunCommand_EmitSummaryMetricsPushgateway(t *testing.T) {
+	t.Parallel()
+
+	var pushedPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushedPath = r.URL.Path
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	command := newRunCommandWithDeps(nil, nil, stubRunRegistry, noopObservabilityInit)
+	command.SetOut(io.Discard)
+	command.SetArgs([]string{
+		"--input", summaryMetricsInput(t),
+		"-a", "static/complexity,history/devs",
+		"--emit-summary-metrics", server.URL,
+	})
+
+	require.NoError(t, command.Execute())
+	require.Equal(t, "/metrics/job/codefang", pushedPath)
+}
+
+func TestStoreDirFacts(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, storeDirFacts(HistoryRunOptions{}))
+	require.Equal(t, map[string]any{pkgplumbing.FactStoreDir: "/data/store"},
+		storeDirFacts(HistoryRunOptions{StoreDir: "/data/store"}))
+}
+
+func TestSeedFacts(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, map[string]any{pkgplumbing.FactSeed: uint64(0)}, seedFacts(HistoryRunOptions{}))
+	require.Equal(t, map[string]any{pkgplumbing.FactSeed: uint64(42)}, seedFacts(HistoryRunOptions{Seed: 42}))
+}
+
+func TestDumpPipelineQueues(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	dumpPipelineQueues(&buf, nil)
+
+	require.Contains(t, buf.String(), "QUEUE")
+	require.Contains(t, buf.String(), "No stage held")
+}
+
+func TestPrepareWorkDirs_CreatesDirs(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	opts := HistoryRunOptions{
+		SpillDir: filepath.Join(root, "spill"),
+		StoreDir: filepath.Join(root, "store", "nested"),
+	}
+
+	require.NoError(t, prepareWorkDirs(context.Background(), opts))
+	require.DirExists(t, opts.SpillDir)
+	require.DirExists(t, opts.StoreDir)
+}
+
+func TestPrepareWorkDirs_Unwritable(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	err := prepareWorkDirs(context.Background(), HistoryRunOptions{SpillDir: filepath.Join(file, "spill")})
+	require.ErrorContains(t, err, "spill-dir")
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/golden"
+)
+
+// ErrSelftestFailed is returned when any analyzer report differs from its
+// golden report.
+var ErrSelftestFailed = errors.New("selftest failed")
+
+// SelftestCommand holds the flags of the selftest command.
+type SelftestCommand struct {
+	repo        string
+	goldenDir   string
+	analyzerIDs []string
+	update      bool
+}
+
+// NewSelftestCommand creates the selftest command, which runs the history
+// analyzers over a fixture repository and compares their reports against
+// golden reports.
+func NewSelftestCommand() *cobra.Command {
+	sc := &SelftestCommand{}
+
+	cmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "Check history analyzer reports on a fixture repository against golden reports",
+		Long: `Run the history analyzers over the first-parent history of a fixture
+repository and compare each JSON report with <golden>/<analyzer>.json, e.g.
+testdata/golden/history_burndown.json. Reports are compared after sorting keys.
+
+Regenerate the golden reports with --update after intended output changes and
+review the diff. The fixture itself is built by scripts/make-fixture.sh.`,
+		RunE: func(cobraCmd *cobra.Command, _ []string) error {
+			return sc.run(cobraCmd.Context(), cobraCmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&sc.repo, "repo", filepath.Join("testdata", "fixture.git"), "Fixture repository")
+	cmd.Flags().StringVar(&sc.goldenDir, "golden", filepath.Join("testdata", "golden"), "Directory of golden reports")
+	cmd.Flags().StringSliceVarP(&sc.analyzerIDs, "analyzers", "a", []string{"history/*"},
+		"History analyzer IDs or glob patterns to check")
+	cmd.Flags().BoolVar(&sc.update, "update", false, "Write the reports as the new golden reports")
+
+	return cmd
+}
+
+func (sc *SelftestCommand) run(ctx context.Context, stdout io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	keys, err := historyKeys(sc.analyzerIDs)
+	if err != nil {
+		return err
+	}
+
+	repository, err := gitlib.LoadRepository(sc.repo)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrRepositoryLoad, sc.repo)
+	}
+	defer repository.Free()
+
+	pl := buildPipeline(repository)
+
+	leaves, err := configureAndSelect(pl, keys)
+	if err != nil {
+		return err
+	}
+
+	reports, err := golden.RunHistory(ctx, repository, pl.Core, leaves)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+
+	for _, leaf := range leaves {
+		id := leaf.Descriptor().ID
+
+		verifyErr := golden.Verify(filepath.Join(sc.goldenDir, golden.FileName(id)), reports[id], sc.update)
+
+		switch {
+		case verifyErr != nil:
+			failed++
+
+			fmt.Fprintf(stdout, "FAIL %s: %v\n", id, verifyErr)
+		case sc.update:
+			fmt.Fprintf(stdout, "updated %s\n", id)
+		default:
+			fmt.Fprintf(stdout, "ok   %s\n", id)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d analyzers differ from %s (rerun with --update to accept)",
+			ErrSelftestFailed, failed, len(leaves), sc.goldenDir)
+	}
+
+	return nil
+}
+
+// historyKeys resolves analyzer IDs and glob patterns to history pipeline
+// keys. Static analyzers matched by a pattern are ignored.
+func historyKeys(patterns []string) ([]string, error) {
+	registry, err := defaultRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := registry.SelectedIDs(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	_, historyIDs, err := registry.Split(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := analyze.HistoryKeysByID(buildPipeline(nil).Leaves, historyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoAnalyzersSelected
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryKeys(t *testing.T) {
+	t.Parallel()
+
+	keys, err := historyKeys([]string{"history/burndown", "history/devs"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"burndown", "devs"}, keys)
+}
+
+func TestHistoryKeys_GlobSkipsStatic(t *testing.T) {
+	t.Parallel()
+
+	keys, err := historyKeys([]string{"*"})
+	require.NoError(t, err)
+	assert.Contains(t, keys, "burndown")
+
+	historyOnly, err := historyKeys([]string{"history/*"})
+	require.NoError(t, err)
+	assert.Equal(t, historyOnly, keys)
+
+	_, err = historyKeys([]string{"static/complexity"})
+	require.ErrorIs(t, err, ErrNoAnalyzersSelected)
+}
+
+func TestNewSelftestCommand_Defaults(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewSelftestCommand()
+
+	assert.Equal(t, "testdata/fixture.git", cmd.Flags().Lookup("repo").DefValue)
+	assert.Equal(t, "testdata/golden", cmd.Flags().Lookup("golden").DefValue)
+	assert.Equal(t, "false", cmd.Flags().Lookup("update").DefValue)
+}
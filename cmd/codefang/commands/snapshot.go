@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+)
+
+// snapshotPatterns is the default snapshot selection: every static analyzer
+// plus the history analyzers whose head-only report describes the current
+// tree rather than its past.
+var snapshotPatterns = []string{"static/*", "history/imports", "history/quality", "history/sentiment"}
+
+// snapshotFormats are the output formats of the snapshot command.
+var snapshotFormats = []string{analyze.FormatJSON, analyze.FormatYAML, analyze.FormatPlot, analyze.FormatBinary}
+
+// SnapshotCommand holds configuration and dependencies for the snapshot command.
+type SnapshotCommand struct {
+	format       string
+	analyzerIDs  []string
+	path         string
+	silent       bool
+	noColor      bool
+	workers      int
+	memoryBudget string
+
+	staticExec  staticExecutor
+	historyExec historyExecutor
+	registryFn  registryProvider
+}
+
+// NewSnapshotCommand creates the snapshot command, which reports the state of
+// the repository at HEAD in one combined document.
+func NewSnapshotCommand() *cobra.Command {
+	registerRenderers()
+
+	return newSnapshotCommandWithDeps(runStaticAnalyzers, runHistoryAnalyzers, defaultRegistry)
+}
+
+func newSnapshotCommandWithDeps(
+	staticExec staticExecutor,
+	historyExec historyExecutor,
+	registryFn registryProvider,
+) *cobra.Command {
+	sc := &SnapshotCommand{
+		staticExec:  staticExec,
+		historyExec: historyExec,
+		registryFn:  registryFn,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "snapshot [path]",
+		Short: "Report the state of the repository at HEAD",
+		Long: `Run static analyzers on the working tree and history analyzers on the HEAD
+commit only, and write one combined report of the repository as it is today.
+
+History analyzers share one identity detector, so --people-dict and
+--exact-signatures apply to every author they report, including the author of
+the HEAD commit in the report's commits table.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: sc.run,
+	}
+
+	cmd.Flags().StringSliceVarP(&sc.analyzerIDs, "analyzers", "a", snapshotPatterns,
+		"Analyzer IDs or glob patterns (history analyzers see only the HEAD commit)")
+	cmd.Flags().StringVar(&sc.format, "format", analyze.FormatJSON, "Output format: json, yaml, plot, bin")
+	cmd.Flags().StringVarP(&sc.path, "path", "p", ".", "Folder/repository path to analyze")
+	cmd.Flags().BoolVar(&sc.silent, "silent", false, "Disable progress output")
+	cmd.Flags().BoolVar(&sc.noColor, "no-color", false, "Disable colored static output")
+	cmd.Flags().IntVar(&sc.workers, "workers", 0, "Number of parallel workers (0 = use CPU count)")
+	cmd.Flags().StringVar(&sc.memoryBudget, "memory-budget", "", "Memory budget for auto-tuning (e.g., '512MB', '2GB')")
+
+	registerAnalyzerFlags(cmd)
+
+	return cmd
+}
+
+func (sc *SnapshotCommand) run(cmd *cobra.Command, args []string) error {
+	outputFormat, err := analyze.ValidateFormat(sc.format, snapshotFormats)
+	if err != nil {
+		return err
+	}
+
+	path := sc.path
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	registry, err := sc.registryFn()
+	if err != nil {
+		return err
+	}
+
+	ids, err := registry.SelectedIDs(sc.analyzerIDs)
+	if err != nil {
+		return err
+	}
+
+	staticIDs, historyIDs, err := registry.Split(ids)
+	if err != nil {
+		return err
+	}
+
+	progress := cmd.ErrOrStderr()
+	if sc.silent {
+		progress = io.Discard
+	}
+
+	fmt.Fprintf(progress, "snapshot path=%s static=%d history=%d\n", path, len(staticIDs), len(historyIDs))
+
+	var raw bytes.Buffer
+
+	err = sc.collect(cmd.Context(), path, staticIDs, historyIDs, sc.historyRunOptions(cmd), progress, &raw)
+	if err != nil {
+		return err
+	}
+
+	orderedIDs := make([]string, 0, len(staticIDs)+len(historyIDs))
+	orderedIDs = append(orderedIDs, staticIDs...)
+	orderedIDs = append(orderedIDs, historyIDs...)
+
+	model, err := analyze.DecodeBinaryInputModel(raw.Bytes(), orderedIDs, registry)
+	if err != nil {
+		return fmt.Errorf("decode snapshot payload: %w", err)
+	}
+
+	err = analyze.WriteConvertedOutput(model, outputFormat, cmd.OutOrStdout())
+	if err != nil {
+		return fmt.Errorf("render snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// collect writes the binary reports of the static analyzers, then of the
+// history analyzers and the HEAD commit table, to raw.
+func (sc *SnapshotCommand) collect(
+	ctx context.Context,
+	path string,
+	staticIDs []string,
+	historyIDs []string,
+	opts HistoryRunOptions,
+	progress io.Writer,
+	raw io.Writer,
+) error {
+	if len(staticIDs) > 0 {
+		startedAt := time.Now()
+
+		err := sc.staticExec(path, staticIDs, analyze.FormatBinary, false, sc.noColor, raw)
+		if err != nil {
+			return fmt.Errorf("snapshot static phase: %w", err)
+		}
+
+		fmt.Fprintf(progress, "static phase finished in %s\n", time.Since(startedAt).Round(time.Millisecond))
+	}
+
+	if len(historyIDs) > 0 {
+		startedAt := time.Now()
+
+		err := sc.historyExec(ctx, path, historyIDs, analyze.FormatBinary, true, opts, raw)
+		if err != nil {
+			return fmt.Errorf("snapshot history phase: %w", err)
+		}
+
+		fmt.Fprintf(progress, "history phase finished in %s\n", time.Since(startedAt).Round(time.Millisecond))
+	}
+
+	return nil
+}
+
+// historyRunOptions returns head-only history options. The commit table
+// carries the HEAD commit and its resolved author into the report, and
+// checkpoints are off since a single commit is never resumed.
+func (sc *SnapshotCommand) historyRunOptions(cmd *cobra.Command) HistoryRunOptions {
+	disabled := false
+
+	return HistoryRunOptions{
+		Head:            true,
+		WithCommitTable: true,
+		Workers:         sc.workers,
+		MemoryBudget:    sc.memoryBudget,
+		OnCommitError:   string(framework.CommitErrorAbort),
+		Checkpoint:      &disabled,
+		Resume:          &disabled,
+		AnalyzerFacts:   analyzerFlagFacts(cmd),
+	}
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+)
+
+func TestSnapshotCommand_CombinesStaticAndHeadHistory(t *testing.T) {
+	t.Parallel()
+
+	var seenOptions HistoryRunOptions
+
+	command := newSnapshotCommandWithDeps(
+		func(_ string, ids []string, format string, _ bool, _ bool, writer io.Writer) error {
+			require.Equal(t, []string{"static/complexity"}, ids)
+			require.Equal(t, analyze.FormatBinary, format)
+
+			return reportutil.EncodeBinaryEnvelope(analyze.Report{"source": "static"}, writer)
+		},
+		func(_ context.Context, _ string, ids []string, format string, _ bool, opts HistoryRunOptions, writer io.Writer) error {
+			seenOptions = opts
+
+			require.Equal(t, []string{"history/devs"}, ids)
+			require.Equal(t, analyze.FormatBinary, format)
+
+			err := reportutil.EncodeBinaryEnvelope(analyze.Report{"source": "history"}, writer)
+			if err != nil {
+				return err
+			}
+
+			return reportutil.EncodeBinaryEnvelope(analyze.CommitTable{Commits: []analyze.CommitRow{
+				{Hash: "abc", Author: "Alice"},
+			}}, writer)
+		},
+		stubRunRegistry,
+	)
+
+	var stdout bytes.Buffer
+
+	command.SetOut(&stdout)
+	command.SetErr(io.Discard)
+	command.SetArgs([]string{"-a", "static/complexity,history/devs", "--people-dict", "people.txt"})
+
+	err := command.Execute()
+	require.NoError(t, err)
+
+	require.True(t, seenOptions.Head)
+	require.True(t, seenOptions.WithCommitTable)
+	require.NotNil(t, seenOptions.Checkpoint)
+	require.False(t, *seenOptions.Checkpoint)
+	require.Equal(t, map[string]any{plumbing.ConfigIdentityDetectorPeopleDictPath: "people.txt"}, seenOptions.AnalyzerFacts)
+
+	var model analyze.UnifiedModel
+
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &model))
+	require.Len(t, model.Analyzers, 2)
+	require.Equal(t, "static/complexity", model.Analyzers[0].ID)
+	require.Equal(t, "history/devs", model.Analyzers[1].ID)
+	require.Equal(t, []analyze.CommitRow{{Hash: "abc", Author: "Alice"}}, model.Commits)
+}
+
+func TestSnapshotCommand_RejectsStreamingFormats(t *testing.T) {
+	t.Parallel()
+
+	command := newSnapshotCommandWithDeps(nil, nil, stubRunRegistry)
+	command.SetArgs([]string{"--format", "ndjson"})
+
+	err := command.Execute()
+	require.ErrorIs(t, err, analyze.ErrUnsupportedFormat)
+}
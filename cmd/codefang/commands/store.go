@@ -0,0 +1,418 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
+	"github.com/Sumatoshi-tech/codefang/pkg/redact"
+	"github.com/Sumatoshi-tech/codefang/pkg/reportstore"
+	"github.com/Sumatoshi-tech/codefang/pkg/storage"
+)
+
+var (
+	// ErrEmptyReport is returned when a report file holds no JSON document.
+	ErrEmptyReport = errors.New("report is empty")
+	// ErrScrubAuthor indicates a --author value that is not an email.
+	ErrScrubAuthor = errors.New("--author must be an email")
+	// ErrResultStoreUsage indicates --result-store combined with flags it conflicts with.
+	ErrResultStoreUsage = errors.New("--result-store requires --namespace and cannot be combined with --output")
+)
+
+// StoreScrubCommand holds the flags of the store scrub command.
+type StoreScrubCommand struct {
+	author      string
+	replaceWith string
+	dryRun      bool
+}
+
+// storedReport is a report file decoded for scrubbing: its JSON documents,
+// which are binary envelopes in a bin report.
+type storedReport struct {
+	location  string
+	binary    bool
+	indented  bool
+	encrypted bool
+	docs      []any
+}
+
+// NewStoreCommand creates the store command, which maintains saved reports.
+func NewStoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "store",
+		Short: "Maintain saved reports",
+	}
+
+	cmd.AddCommand(newStoreCompareCommand(defaultRegistry))
+	cmd.AddCommand(newStoreGCCommand())
+	cmd.AddCommand(newStoreListCommand())
+	cmd.AddCommand(newStoreScrubCommand())
+
+	return cmd
+}
+
+func newStoreListCommand() *cobra.Command {
+	var root string
+
+	cmd := &cobra.Command{
+		Use:   "list [org[/repo]]",
+		Short: "List the runs of a report store",
+		Long: `List the runs kept in a report store by "codefang run --result-store", oldest
+first within each repository, optionally only those of one organization or
+repository. Open a run with "codefang files --result-store ROOT org/repo/run".`,
+		Example: `  codefang store list --root s3://reports/codefang acme`,
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			var filter reportstore.Namespace
+
+			if len(args) > 0 {
+				org, repo, _ := strings.Cut(strings.Trim(args[0], "/"), "/")
+				filter = reportstore.Namespace{Org: org, Repo: repo}
+			}
+
+			return listStoredRuns(reportstore.New(root), filter, cobraCmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&root, "root", "", "Report store directory or s3:// URL")
+
+	_ = cmd.MarkFlagRequired("root")
+
+	return cmd
+}
+
+func listStoredRuns(store *reportstore.Store, filter reportstore.Namespace, stdout io.Writer) error {
+	runs, err := store.List(filter)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "RUN\tCREATED\tFORMAT\tANALYZERS")
+
+	for _, run := range runs {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", run.Namespace(), run.Created.Format(time.RFC3339), run.Format,
+			strings.Join(run.Analyzers, ","))
+	}
+
+	return writer.Flush()
+}
+
+// storedRunLocation returns the report location of the run of a store named
+// by namespace, the latest run of the repository when it names no run.
+func storedRunLocation(root, namespace string) (string, error) {
+	ns, err := reportstore.ParseNamespace(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	store := reportstore.New(root)
+
+	entry, err := store.Find(ns)
+	if err != nil {
+		return "", err
+	}
+
+	return store.ReportLocation(entry), nil
+}
+
+// prepareResultStore points the output of the run at its place in
+// --result-store and returns the catalog entry to record once the report is
+// written, or nil without --result-store.
+func (rc *RunCommand) prepareResultStore(ids []string) (*reportstore.Entry, error) {
+	if rc.resultStore == "" {
+		return nil, nil //nolint:nilnil // no store is not an error.
+	}
+
+	if rc.namespace == "" || rc.outputPath != "" {
+		return nil, ErrResultStoreUsage
+	}
+
+	ns, err := reportstore.ParseNamespace(rc.namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	created := time.Now().UTC()
+	if ns.Run == "" {
+		ns.Run = reportstore.NewRunID(created)
+	}
+
+	format := analyze.NormalizeFormat(rc.format)
+	if format == analyze.FormatBinary {
+		format = reportstore.FormatBin
+	}
+
+	store := reportstore.New(rc.resultStore)
+
+	location, err := store.Location(ns, format)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.Prepare(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	if rc.encryptAtRest {
+		rc.outputCipher, err = crypt.FromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("encrypt-at-rest: %w", err)
+		}
+	}
+
+	rc.outputPath = location
+
+	return &reportstore.Entry{
+		Org:       ns.Org,
+		Repo:      ns.Repo,
+		Run:       ns.Run,
+		Format:    format,
+		Created:   created,
+		Analyzers: ids,
+		Encrypted: rc.outputCipher != nil,
+	}, nil
+}
+
+// readReportFile reads the report at location, decrypting it with the key of
+// crypt.FromEnv when it was written with --encrypt-at-rest.
+func readReportFile(location string) (data []byte, encrypted bool, err error) {
+	data, err = storage.ReadFile(location)
+	if err != nil || !crypt.IsEncrypted(data) {
+		return data, false, err
+	}
+
+	cipher, err := crypt.FromEnv()
+	if err != nil {
+		return nil, true, fmt.Errorf("%s is encrypted: %w", location, err)
+	}
+
+	reader, err := cipher.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, true, err
+	}
+
+	data, err = io.ReadAll(reader)
+	if err != nil {
+		return nil, true, fmt.Errorf("%s: %w", location, err)
+	}
+
+	return data, true, reader.Close()
+}
+
+// encryptReport encrypts data with the key of crypt.FromEnv.
+func encryptReport(data []byte) ([]byte, error) {
+	cipher, err := crypt.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	writer, err := cipher.NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newStoreScrubCommand() *cobra.Command {
+	sc := &StoreScrubCommand{}
+
+	cmd := &cobra.Command{
+		Use:   "scrub <report>...",
+		Short: "Erase an author from saved reports",
+		Long: `Rewrite reports written by "codefang run" or "codefang snapshot" (json, bin
+or ndjson, local or s3://) so that one author, identified by email, no longer
+appears in any analyzer's output or in the commit table.
+
+People dictionary entries and signatures holding the email, the email itself
+and the names the author committed under are replaced with --replace-with;
+the email is also replaced inside longer text such as trailers and notes.
+Counts stay and are attributed to the replacement: give it the name of
+another identity to re-attribute the author's work, or keep the default to
+leave it anonymous. Names that only appear inside free text are not found.
+
+Reports are rewritten in place. Scrub every copy, including archived ones.
+Reports of a store run with --encrypt-at-rest are decrypted and encrypted
+again with the key of ` + crypt.EnvKey + ` or ` + crypt.EnvKeyCommand + `.`,
+		Example: `  codefang store scrub --author alice@corp.example reports/*.json
+  codefang store scrub --author alice@corp.example --replace-with former-staff s3://reports/devs.bin`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return sc.run(args, cobraCmd.ErrOrStderr())
+		},
+	}
+
+	cmd.Flags().StringVar(&sc.author, "author", "", "Email of the author to erase")
+	cmd.Flags().StringVar(&sc.replaceWith, "replace-with", redact.ErasedAuthor, "Identity that replaces the author")
+	cmd.Flags().BoolVar(&sc.dryRun, "dry-run", false, "Count the values to replace without writing the reports")
+
+	_ = cmd.MarkFlagRequired("author")
+
+	return cmd
+}
+
+func (sc *StoreScrubCommand) run(locations []string, progress io.Writer) error {
+	if !strings.Contains(sc.author, "@") {
+		return fmt.Errorf("%w: %q", ErrScrubAuthor, sc.author)
+	}
+
+	reports := make([]storedReport, 0, len(locations))
+
+	for _, location := range locations {
+		data, encrypted, err := readReportFile(location)
+		if err != nil {
+			return fmt.Errorf("read report: %w", err)
+		}
+
+		report, err := decodeStoredReport(location, data)
+		if err != nil {
+			return err
+		}
+
+		report.encrypted = encrypted
+
+		reports = append(reports, report)
+	}
+
+	// Names are learned from every report first, since the people dictionary
+	// of one report may be the only place tying a name to the email.
+	eraser := redact.NewEraser(sc.author, sc.replaceWith)
+
+	for _, report := range reports {
+		for _, doc := range report.docs {
+			eraser.Learn(doc)
+		}
+	}
+
+	for _, report := range reports {
+		before := eraser.Replaced()
+
+		for i, doc := range report.docs {
+			report.docs[i] = eraser.Value(doc)
+		}
+
+		replaced := eraser.Replaced() - before
+
+		if replaced > 0 && !sc.dryRun {
+			data, err := report.encode()
+			if err != nil {
+				return err
+			}
+
+			if report.encrypted {
+				data, err = encryptReport(data)
+				if err != nil {
+					return fmt.Errorf("encrypt report: %w", err)
+				}
+			}
+
+			err = storage.WriteFile(report.location, data)
+			if err != nil {
+				return fmt.Errorf("write report: %w", err)
+			}
+		}
+
+		fmt.Fprintf(progress, "scrub: %s: %d values replaced\n", report.location, replaced)
+	}
+
+	return nil
+}
+
+// decodeStoredReport decodes the JSON documents of a report: binary
+// envelopes, one indented document, or one document per line.
+func decodeStoredReport(location string, data []byte) (storedReport, error) {
+	report := storedReport{location: location}
+
+	if bytes.HasPrefix(data, []byte(reportutil.BinaryMagic)) {
+		payloads, err := reportutil.DecodeBinaryEnvelopes(data)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", location, err)
+		}
+
+		report.binary = true
+		data = bytes.Join(payloads, []byte("\n"))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	for {
+		var doc any
+
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return report, fmt.Errorf("%s: decode report: %w", location, err)
+		}
+
+		report.docs = append(report.docs, doc)
+	}
+
+	if len(report.docs) == 0 {
+		return report, fmt.Errorf("%w: %s", ErrEmptyReport, location)
+	}
+
+	// Line-delimited documents have no newlines but those between them.
+	report.indented = bytes.Count(bytes.TrimSpace(data), []byte("\n")) >= len(report.docs)
+
+	return report, nil
+}
+
+// encode writes the documents back in the layout they were read from.
+func (r storedReport) encode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if r.binary {
+		for _, doc := range r.docs {
+			err := reportutil.EncodeBinaryEnvelope(doc, &buf)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if r.indented {
+		encoder.SetIndent("", "  ")
+	}
+
+	for _, doc := range r.docs {
+		err := encoder.Encode(doc)
+		if err != nil {
+			return nil, fmt.Errorf("encode report: %w", err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/renderer"
+	"github.com/Sumatoshi-tech/codefang/pkg/reportstore"
+)
+
+// StoreCompareCommand holds the flags of the store compare command.
+type StoreCompareCommand struct {
+	root string
+
+	registryFn registryProvider
+}
+
+func newStoreCompareCommand(registryFn registryProvider) *cobra.Command {
+	sc := &StoreCompareCommand{registryFn: registryFn}
+
+	cmd := &cobra.Command{
+		Use:   "compare <base> <head>",
+		Short: "Compare two runs of a report store",
+		Long: `Write an HTML page comparing two runs of a report store, each named as
+org/repo/run, or org/repo for the latest run of a repository:
+
+  - the headline metrics of the head run, with their change since the base run;
+  - the files that became hotspots and those that no longer are;
+  - the surviving lines of both runs on one burndown chart.
+
+A section is left out when neither run has the report it comes from.`,
+		Example: `  codefang store compare --root s3://reports/codefang acme/api/20261001T120000Z acme/api > compare.html`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return sc.run(args[0], args[1], cobraCmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&sc.root, "root", "", "Report store directory or s3:// URL")
+
+	_ = cmd.MarkFlagRequired("root")
+
+	return cmd
+}
+
+func (sc *StoreCompareCommand) run(base, head string, stdout io.Writer) error {
+	registry, err := sc.registryFn()
+	if err != nil {
+		return err
+	}
+
+	store := reportstore.New(sc.root)
+
+	baseRun, baseModel, err := loadStoredRun(store, registry, base)
+	if err != nil {
+		return err
+	}
+
+	headRun, headModel, err := loadStoredRun(store, registry, head)
+	if err != nil {
+		return err
+	}
+
+	comparison, err := renderer.Compare(baseModel, headModel)
+	if err != nil {
+		return err
+	}
+
+	return comparison.Render(stdout, baseRun.Namespace().String(), headRun.Namespace().String())
+}
+
+// loadStoredRun finds the run of store named by namespace and decodes its
+// report, using the analyzers the catalog recorded for history-only bin
+// reports.
+func loadStoredRun(
+	store *reportstore.Store,
+	registry *analyze.Registry,
+	namespace string,
+) (reportstore.Entry, analyze.UnifiedModel, error) {
+	ns, err := reportstore.ParseNamespace(namespace)
+	if err != nil {
+		return reportstore.Entry{}, analyze.UnifiedModel{}, err
+	}
+
+	entry, err := store.Find(ns)
+	if err != nil {
+		return reportstore.Entry{}, analyze.UnifiedModel{}, err
+	}
+
+	model, err := loadReportModel(registry, store.ReportLocation(entry), analyze.InputFormatAuto, entry.Analyzers)
+	if err != nil {
+		return reportstore.Entry{}, analyze.UnifiedModel{}, err
+	}
+
+	return entry, model, nil
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/reportstore"
+)
+
+func storeRun(t *testing.T, store *reportstore.Store, run string, created time.Time, hotspots ...string) {
+	t.Helper()
+
+	rows := make([]any, 0, len(hotspots))
+	for _, path := range hotspots {
+		rows = append(rows, map[string]any{"path": path, "commit_count": 9})
+	}
+
+	data, err := json.Marshal(analyze.NewUnifiedModel([]analyze.AnalyzerResult{{
+		ID:     "history/file-history",
+		Mode:   analyze.ModeHistory,
+		Report: analyze.Report{"hotspots": rows},
+	}}))
+	require.NoError(t, err)
+
+	ns := reportstore.Namespace{Org: "acme", Repo: "api", Run: run}
+	require.NoError(t, store.Prepare(ns))
+
+	location, err := store.Location(ns, reportstore.FormatJSON)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(location, data, 0o600))
+	require.NoError(t, store.Record(reportstore.Entry{
+		Org: ns.Org, Repo: ns.Repo, Run: ns.Run, Format: reportstore.FormatJSON, Created: created,
+	}))
+}
+
+func TestStoreCompare(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	store := reportstore.New(root)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	storeRun(t, store, "r1", start, "old.go", "kept.go")
+	storeRun(t, store, "r2", start.Add(time.Hour), "kept.go", "fresh.go")
+
+	var stdout bytes.Buffer
+
+	command := newStoreCompareCommand(stubRunRegistry)
+	command.SetOut(&stdout)
+	command.SetArgs([]string{"--root", root, "acme/api/r1", "acme/api"})
+
+	require.NoError(t, command.Execute())
+	require.Contains(t, stdout.String(), "acme/api/r1 vs acme/api/r2")
+	require.Contains(t, stdout.String(), "fresh.go")
+	require.Contains(t, stdout.String(), "old.go")
+
+	command = newStoreCompareCommand(stubRunRegistry)
+	command.SetOut(&bytes.Buffer{})
+	command.SetArgs([]string{"--root", root, "acme/api/missing", "acme/api"})
+	require.ErrorIs(t, command.Execute(), reportstore.ErrRunNotFound)
+}
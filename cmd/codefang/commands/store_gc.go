@@ -0,0 +1,291 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/burndown"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/spillstore"
+	"github.com/Sumatoshi-tech/codefang/pkg/checkpoint"
+	"github.com/Sumatoshi-tech/codefang/pkg/reportstore"
+	"github.com/Sumatoshi-tech/codefang/pkg/storage"
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
+)
+
+// ErrGCPolicy is returned by store gc without a retention rule.
+var ErrGCPolicy = errors.New("--keep-last or --keep-days is required")
+
+// day is the unit of --keep-days.
+const day = 24 * time.Hour
+
+// spillDirPatterns match the spill directories runs create and a crashed run
+// leaves behind.
+var spillDirPatterns = []string{
+	spillstore.DirPattern,
+	burndown.SpillDirPattern,
+	burndown.AggregatorSpillDirPattern,
+}
+
+// StoreGCCommand holds the flags of the store gc command.
+type StoreGCCommand struct {
+	root          string
+	keepLast      int
+	keepDays      int
+	checkpointDir string
+	spillDirs     []string
+	dryRun        bool
+}
+
+// gcTotals counts what store gc removed.
+type gcTotals struct {
+	runs, checkpoints, spills int
+	bytes                     int64
+}
+
+func newStoreGCCommand() *cobra.Command {
+	gc := &StoreGCCommand{}
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Prune old runs, checkpoints and spill directories",
+		Long: `Delete what a retention policy no longer keeps:
+
+  - runs of the report store at --root, unless they are among the --keep-last
+    latest runs of their repository or younger than --keep-days;
+  - checkpoints under --checkpoint-dir not written for --keep-days;
+    directories there without checkpoint metadata are reported and kept;
+  - spill directories under --spill-dir not written for --keep-days, which
+    runs that crashed or were killed leave behind.
+
+Checkpoints and spill directories are pruned by age only, so they are kept
+without --keep-days. With --dry-run, list what would be deleted.`,
+		Example: `  codefang store gc --root s3://reports/codefang --keep-last 10 --keep-days 90 --dry-run
+  codefang store gc --keep-days 7 --spill-dir /scratch`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, _ []string) error {
+			return gc.run(time.Now(), cobraCmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&gc.root, "root", "", "Report store directory or s3:// URL whose runs to prune")
+	cmd.Flags().IntVar(&gc.keepLast, "keep-last", 0, "Keep the latest N runs of every repository (0 = no count rule)")
+	cmd.Flags().IntVar(&gc.keepDays, "keep-days", 0,
+		"Keep runs, checkpoints and spills younger than N days (0 = no age rule)")
+	cmd.Flags().StringVar(&gc.checkpointDir, "checkpoint-dir", checkpoint.DefaultDir(), "Checkpoint directory to prune")
+	cmd.Flags().StringSliceVar(&gc.spillDirs, "spill-dir", []string{os.TempDir()},
+		"Parent directories of spill directories to prune (the --spill-dir and --store-dir of runs)")
+	cmd.Flags().BoolVar(&gc.dryRun, "dry-run", false, "List what would be deleted without deleting it")
+
+	return cmd
+}
+
+func (gc *StoreGCCommand) run(now time.Time, stdout io.Writer) error {
+	if gc.keepLast <= 0 && gc.keepDays <= 0 {
+		return ErrGCPolicy
+	}
+
+	maxAge := time.Duration(gc.keepDays) * day
+
+	var totals gcTotals
+
+	if gc.root != "" {
+		err := gc.pruneRuns(reportstore.Policy{KeepLast: gc.keepLast, MaxAge: maxAge}, now, stdout, &totals)
+		if err != nil {
+			return err
+		}
+	}
+
+	if maxAge > 0 {
+		err := gc.pruneCheckpoints(now.Add(-maxAge), stdout, &totals)
+		if err != nil {
+			return err
+		}
+
+		err = gc.pruneSpills(now.Add(-maxAge), stdout, &totals)
+		if err != nil {
+			return err
+		}
+	}
+
+	verb := "removed"
+	if gc.dryRun {
+		verb = "would remove"
+	}
+
+	fmt.Fprintf(stdout, "gc: %s %d runs, %d checkpoints, %d spill directories (%s on local disk)\n",
+		verb, totals.runs, totals.checkpoints, totals.spills, humanize.IBytes(uint64(totals.bytes)))
+
+	return nil
+}
+
+func (gc *StoreGCCommand) pruneRuns(
+	policy reportstore.Policy,
+	now time.Time,
+	stdout io.Writer,
+	totals *gcTotals,
+) error {
+	store := reportstore.New(gc.root)
+
+	expired, err := store.Expired(policy, now)
+	if err != nil {
+		return err
+	}
+
+	for _, run := range expired {
+		var size int64
+		if !storage.IsRemote(gc.root) {
+			size = streaming.DirSize(filepath.Dir(store.ReportLocation(run)))
+		}
+
+		fmt.Fprintf(stdout, "run %s\tcreated %s\t%s\n",
+			run.Namespace(), run.Created.Format(time.RFC3339), humanize.IBytes(uint64(size)))
+
+		totals.runs++
+		totals.bytes += size
+	}
+
+	if gc.dryRun || len(expired) == 0 {
+		return nil
+	}
+
+	return store.Remove(expired)
+}
+
+func (gc *StoreGCCommand) pruneCheckpoints(cutoff time.Time, stdout io.Writer, totals *gcTotals) error {
+	entries, err := os.ReadDir(gc.checkpointDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("read checkpoint dir: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(gc.checkpointDir, entry.Name())
+
+		// A directory without readable checkpoint metadata may not be a
+		// checkpoint at all, for example when --checkpoint-dir points at a
+		// shared directory, so it is never deleted.
+		meta, metaErr := checkpoint.NewManager(gc.checkpointDir, entry.Name()).LoadMetadata()
+		if metaErr != nil {
+			fmt.Fprintf(stdout, "skip %s\tnot a checkpoint: %v\n", dir, metaErr)
+
+			continue
+		}
+
+		removed, size, pruneErr := gc.pruneDir(dir, cutoff, "checkpoint", meta.RepoPath, stdout)
+		if pruneErr != nil {
+			return pruneErr
+		}
+
+		if removed {
+			totals.checkpoints++
+			totals.bytes += size
+		}
+	}
+
+	return nil
+}
+
+func (gc *StoreGCCommand) pruneSpills(cutoff time.Time, stdout io.Writer, totals *gcTotals) error {
+	for _, parent := range gc.spillDirs {
+		for _, pattern := range spillDirPatterns {
+			dirs, err := filepath.Glob(filepath.Join(parent, pattern))
+			if err != nil {
+				return fmt.Errorf("find spill dirs: %w", err)
+			}
+
+			for _, dir := range dirs {
+				removed, size, pruneErr := gc.pruneDir(dir, cutoff, "spill", "", stdout)
+				if pruneErr != nil {
+					return pruneErr
+				}
+
+				if removed {
+					totals.spills++
+					totals.bytes += size
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// pruneDir removes dir when nothing in it was written after cutoff, and
+// reports whether it did, or would with --dry-run, and the bytes it held.
+func (gc *StoreGCCommand) pruneDir(
+	dir string,
+	cutoff time.Time,
+	kind, note string,
+	stdout io.Writer,
+) (removed bool, size int64, err error) {
+	modified, err := lastModified(dir)
+	if err != nil {
+		return false, 0, err
+	}
+
+	if modified.After(cutoff) {
+		return false, 0, nil
+	}
+
+	size = streaming.DirSize(dir)
+
+	fmt.Fprintf(stdout, "%s %s\tmodified %s\t%s", kind, dir, modified.Format(time.RFC3339), humanize.IBytes(uint64(size)))
+
+	if note != "" {
+		fmt.Fprintf(stdout, "\t%s", note)
+	}
+
+	fmt.Fprintln(stdout)
+
+	if !gc.dryRun {
+		err = os.RemoveAll(dir)
+		if err != nil {
+			return false, 0, fmt.Errorf("remove %s %s: %w", kind, dir, err)
+		}
+	}
+
+	return true, size, nil
+}
+
+// lastModified returns the latest modification time of dir and everything
+// in it, so a directory whose files are still being written counts as fresh.
+func lastModified(dir string) (time.Time, error) {
+	var latest time.Time
+
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		info, infoErr := d.Info()
+		if infoErr == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("scan %s: %w", dir, err)
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/reportstore"
+)
+
+// writeAged creates path with its parent directories and dates both age ago.
+func writeAged(t *testing.T, path string, age time.Duration) {
+	t.Helper()
+
+	writeAgedData(t, path, []byte("data"), age)
+}
+
+// writeAgedData is writeAged with the given file content.
+func writeAgedData(t *testing.T, path string, data []byte, age time.Duration) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	stamp := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, stamp, stamp))
+	require.NoError(t, os.Chtimes(filepath.Dir(path), stamp, stamp))
+}
+
+func TestStoreGC(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "store")
+	checkpoints := filepath.Join(dir, "checkpoints")
+	spills := filepath.Join(dir, "spills")
+	now := time.Now()
+
+	store := reportstore.New(root)
+
+	for _, age := range []int{100, 50, 1} {
+		ns := reportstore.Namespace{Org: "acme", Repo: "api", Run: reportstore.NewRunID(now.Add(-time.Duration(age) * day))}
+		location, err := store.Location(ns, reportstore.FormatJSON)
+		require.NoError(t, err)
+
+		writeAged(t, location, 0)
+		require.NoError(t, store.Record(reportstore.Entry{
+			Org: ns.Org, Repo: ns.Repo, Run: ns.Run, Format: reportstore.FormatJSON,
+			Created: now.Add(-time.Duration(age) * day),
+		}))
+	}
+
+	metadata := []byte(`{"version": 2, "repo_path": "/src/api"}`)
+
+	writeAgedData(t, filepath.Join(checkpoints, "old", "checkpoint.json"), metadata, 40*day)
+	writeAgedData(t, filepath.Join(checkpoints, "fresh", "checkpoint.json"), metadata, time.Hour)
+	writeAged(t, filepath.Join(checkpoints, "photos", "holiday.jpg"), 40*day)
+	writeAged(t, filepath.Join(spills, "codefang-spill-1", "0.gob"), 40*day)
+	writeAged(t, filepath.Join(spills, "codefang-burndown-agg-2", "0.gob"), time.Hour)
+	writeAged(t, filepath.Join(spills, "unrelated", "0.gob"), 40*day)
+
+	gc := &StoreGCCommand{
+		root: root, keepLast: 1, keepDays: 30, checkpointDir: checkpoints, spillDirs: []string{spills}, dryRun: true,
+	}
+
+	var out bytes.Buffer
+
+	require.NoError(t, gc.run(now, &out))
+	require.Contains(t, out.String(), "would remove 2 runs, 1 checkpoints, 1 spill directories")
+	require.DirExists(t, filepath.Join(checkpoints, "old"), "a dry run deletes nothing")
+
+	gc.dryRun = false
+	out.Reset()
+
+	require.NoError(t, gc.run(now, &out))
+	require.Contains(t, out.String(), "removed 2 runs, 1 checkpoints, 1 spill directories")
+	require.NoDirExists(t, filepath.Join(checkpoints, "old"))
+	require.DirExists(t, filepath.Join(checkpoints, "fresh"))
+	require.FileExists(t, filepath.Join(checkpoints, "photos", "holiday.jpg"),
+		"a directory without checkpoint metadata is never deleted")
+	require.Contains(t, out.String(), "skip "+filepath.Join(checkpoints, "photos"))
+	require.NoDirExists(t, filepath.Join(spills, "codefang-spill-1"))
+	require.DirExists(t, filepath.Join(spills, "codefang-burndown-agg-2"))
+	require.DirExists(t, filepath.Join(spills, "unrelated"))
+
+	runs, err := store.List(reportstore.Namespace{})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+
+	require.ErrorIs(t, (&StoreGCCommand{}).run(now, &out), ErrGCPolicy)
+}
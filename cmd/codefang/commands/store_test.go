@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
+	"github.com/Sumatoshi-tech/codefang/pkg/reportstore"
+)
+
+const scrubReportJSON = `{
+  "version": "codefang.run.v1",
+  "analyzers": [
+    {
+      "id": "history/devs",
+      "mode": "history",
+      "report": {
+        "ReversedPeopleDict": ["alice|alice@corp.example", "bob|bob@corp.example"],
+        "commits": 1234567890123
+      }
+    }
+  ],
+  "commits": [{"hash": "abc", "author": "alice|alice@corp.example", "note": "<b>ok</b>"}]
+}
+`
+
+func runScrub(t *testing.T, args ...string) string {
+	t.Helper()
+
+	cmd := NewStoreCommand()
+
+	var stderr bytes.Buffer
+
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(append([]string{"scrub"}, args...))
+	require.NoError(t, cmd.Execute())
+
+	return stderr.String()
+}
+
+func TestStoreScrub_JSON(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(path, []byte(scrubReportJSON), 0o600))
+
+	progress := runScrub(t, "--author", "Alice@corp.example", path)
+	require.Contains(t, progress, "2 values replaced")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "alice")
+	require.Contains(t, string(data), `"erased-author"`)
+	require.Contains(t, string(data), "bob|bob@corp.example")
+	require.Contains(t, string(data), "1234567890123", "numbers are kept as written")
+	require.Contains(t, string(data), "<b>ok</b>")
+	require.Contains(t, string(data), "\n  ", "indentation is kept")
+}
+
+func TestStoreScrub_BinaryAndNDJSON(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var bin bytes.Buffer
+
+	require.NoError(t, reportutil.EncodeBinaryEnvelope(
+		map[string]any{"dict": []string{"alice <alice@corp.example>"}}, &bin))
+	require.NoError(t, reportutil.EncodeBinaryEnvelope(map[string]any{"top": "alice"}, &bin))
+
+	binPath := filepath.Join(dir, "report.bin")
+	require.NoError(t, os.WriteFile(binPath, bin.Bytes(), 0o600))
+
+	ndjsonPath := filepath.Join(dir, "records.ndjson")
+	require.NoError(t, os.WriteFile(ndjsonPath, []byte("{\"author\":\"alice\"}\n{\"author\":\"bob\"}\n"), 0o600))
+
+	runScrub(t, "--author", "alice@corp.example", "--replace-with", "former-staff", binPath, ndjsonPath)
+
+	data, err := os.ReadFile(binPath)
+	require.NoError(t, err)
+
+	payloads, err := reportutil.DecodeBinaryEnvelopes(data)
+	require.NoError(t, err)
+	require.Len(t, payloads, 2)
+	require.JSONEq(t, `{"dict":["former-staff"]}`, string(payloads[0]))
+	require.JSONEq(t, `{"top":"former-staff"}`, string(payloads[1]))
+
+	data, err = os.ReadFile(ndjsonPath)
+	require.NoError(t, err)
+	require.Equal(t, "{\"author\":\"former-staff\"}\n{\"author\":\"bob\"}\n", string(data))
+}
+
+func TestStoreScrub_DryRunAndErrors(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(path, []byte(scrubReportJSON), 0o600))
+
+	require.Contains(t, runScrub(t, "--author", "alice@corp.example", "--dry-run", path), "2 values replaced")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, scrubReportJSON, string(data))
+
+	cmd := NewStoreCommand()
+	cmd.SetArgs([]string{"scrub", "--author", "alice", path})
+	require.ErrorIs(t, cmd.Execute(), ErrScrubAuthor)
+}
+
+func TestRunCommand_ResultStore(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+
+	command := newRunCommandWithDeps(
+		func(_ string, _ []string, _ string, _ bool, _ bool, _ io.Writer) error {
+			return nil
+		},
+		func(_ context.Context, _ string, _ []string, _ string, _ bool, _ HistoryRunOptions, writer io.Writer) error {
+			_, err := writer.Write([]byte(`{"version":"codefang.run.v1","analyzers":[]}`))
+
+			return err
+		},
+		stubRunRegistry,
+		noopObservabilityInit,
+	)
+
+	command.SetArgs([]string{"-a", "history/devs", "--silent", "--result-store", root, "--namespace", "acme/api/r1"})
+	require.NoError(t, command.Execute())
+
+	location, err := storedRunLocation(root, "acme/api")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "acme", "api", "r1", "report.json"), location)
+	require.FileExists(t, location)
+
+	var listing bytes.Buffer
+
+	require.NoError(t, listStoredRuns(reportstore.New(root), reportstore.Namespace{Org: "acme"}, &listing))
+	require.Contains(t, listing.String(), "acme/api/r1")
+	require.Contains(t, listing.String(), "history/devs")
+
+	command = newRunCommandWithDeps(nil, nil, stubRunRegistry, noopObservabilityInit)
+	command.SetArgs([]string{"-a", "history/devs", "--result-store", root})
+	require.ErrorIs(t, command.Execute(), ErrResultStoreUsage)
+}
+
+func TestRunCommand_ResultStoreEncrypted(t *testing.T) {
+	t.Setenv(crypt.EnvKeyCommand, "")
+	t.Setenv(crypt.EnvKey, strings.Repeat("ab", crypt.KeySize))
+
+	root := t.TempDir()
+
+	command := newRunCommandWithDeps(
+		nil,
+		func(_ context.Context, _ string, _ []string, _ string, _ bool, _ HistoryRunOptions, writer io.Writer) error {
+			_, err := writer.Write([]byte(scrubReportJSON))
+
+			return err
+		},
+		stubRunRegistry,
+		noopObservabilityInit,
+	)
+
+	command.SetArgs([]string{
+		"-a", "history/devs", "--silent", "--result-store", root, "--namespace", "acme/api/r1", "--encrypt-at-rest",
+	})
+	require.NoError(t, command.Execute())
+
+	entry, err := reportstore.New(root).Find(reportstore.Namespace{Org: "acme", Repo: "api"})
+	require.NoError(t, err)
+	require.True(t, entry.Encrypted)
+
+	location := reportstore.New(root).ReportLocation(entry)
+
+	stored, err := os.ReadFile(location)
+	require.NoError(t, err)
+	require.True(t, crypt.IsEncrypted(stored))
+	require.NotContains(t, string(stored), "alice")
+
+	plain, encrypted, err := readReportFile(location)
+	require.NoError(t, err)
+	require.True(t, encrypted)
+	require.Equal(t, scrubReportJSON, string(plain))
+
+	require.Contains(t, runScrub(t, "--author", "alice@corp.example", location), "2 values replaced")
+
+	stored, err = os.ReadFile(location)
+	require.NoError(t, err)
+	require.True(t, crypt.IsEncrypted(stored), "scrub keeps the report encrypted")
+
+	plain, _, err = readReportFile(location)
+	require.NoError(t, err)
+	require.NotContains(t, string(plain), "alice")
+	require.Contains(t, string(plain), "bob|bob@corp.example")
+
+	t.Setenv(crypt.EnvKey, "")
+
+	_, _, err = readReportFile(location)
+	require.ErrorIs(t, err, crypt.ErrNoKey)
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/renderer"
+)
+
+// summaryMetricsJob is the Pushgateway job summary metrics are pushed under.
+const summaryMetricsJob = "codefang"
+
+// emitSummaryMetrics publishes the headline numbers of model to target: an
+// http(s) URL is taken as a Pushgateway, anything else as a file that gets
+// the metrics in OpenMetrics text format.
+func emitSummaryMetrics(ctx context.Context, model renderer.UnifiedModel, target string) error {
+	metrics, err := renderer.SummaryMetrics(model)
+	if err != nil {
+		return err
+	}
+
+	registry := prometheus.NewRegistry()
+
+	for _, m := range metrics {
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: m.Name, Help: m.Help})
+		gauge.Set(m.Value)
+
+		err = registry.Register(gauge)
+		if err != nil {
+			return fmt.Errorf("register summary metric %s: %w", m.Name, err)
+		}
+	}
+
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		err = push.New(target, summaryMetricsJob).Gatherer(registry).PushContext(ctx)
+		if err != nil {
+			return fmt.Errorf("push summary metrics: %w", err)
+		}
+
+		return nil
+	}
+
+	return writeSummaryMetrics(registry, target)
+}
+
+func writeSummaryMetrics(registry *prometheus.Registry, path string) error {
+	families, err := registry.Gather()
+	if err != nil {
+		return fmt.Errorf("gather summary metrics: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create summary metrics file: %w", err)
+	}
+
+	encoder := expfmt.NewEncoder(file, expfmt.NewFormat(expfmt.TypeOpenMetrics))
+
+	for _, family := range families {
+		err = encoder.Encode(family)
+		if err != nil {
+			file.Close()
+
+			return fmt.Errorf("encode summary metrics: %w", err)
+		}
+	}
+
+	if closer, ok := encoder.(expfmt.Closer); ok {
+		err = closer.Close()
+		if err != nil {
+			file.Close()
+
+			return fmt.Errorf("encode summary metrics: %w", err)
+		}
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("close summary metrics file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/observability"
+)
+
+// Timeouts of the chunk worker server. Responses have no write timeout: a
+// chunk is sent back only once its whole pipeline has run.
+const (
+	workerReadHeaderTimeout = 10 * time.Second
+	workerReadTimeout       = 5 * time.Minute
+)
+
+// WorkerCommand holds configuration for the worker command.
+type WorkerCommand struct {
+	path        string
+	addr        string
+	workers     int
+	uastService string
+}
+
+// NewWorkerCommand creates the worker command, which runs the chunk
+// pipelines of "codefang run --chunk-workers" on a repository mirror.
+func NewWorkerCommand() *cobra.Command {
+	wc := &WorkerCommand{}
+
+	cmd := &cobra.Command{
+		Use:   "worker",
+		Short: "Run chunk pipelines for a distributed history analysis",
+		Long: `Serve the blob, diff and UAST stages of history chunks over HTTP for
+"codefang run --chunk-workers". The run keeps walking the history and running
+the analyzers; it sends each chunk of commits to a worker, which loads the
+blobs, diffs and parses them from its own repository and sends the results back.
+
+--path must be a mirror of the analyzed repository holding every commit of the
+run, e.g. kept up to date with "git fetch". Diff, UAST and text encoding
+options come with each chunk from the run; --workers only sets how many
+repository handles the worker loads blobs with.`,
+		RunE: wc.run,
+	}
+
+	cmd.Flags().StringVarP(&wc.path, "path", "p", ".", "Repository mirror to load commits from")
+	cmd.Flags().StringVar(&wc.addr, "addr", "127.0.0.1:8091", "Address to serve chunk requests on")
+	cmd.Flags().IntVar(&wc.workers, "workers", 0, "Parallel blob loading workers (0 = default)")
+	cmd.Flags().StringVar(&wc.uastService, "uast-service", "",
+		"Parse files on a 'uast server' at this URL instead of in-process (e.g., 'http://uast:8080')")
+
+	return cmd
+}
+
+func (wc *WorkerCommand) run(cmd *cobra.Command, _ []string) error {
+	repo, err := gitlib.OpenRepository(wc.path)
+	if err != nil {
+		return fmt.Errorf("open repository: %w", err)
+	}
+
+	repoPath := repo.Path()
+	repo.Free()
+
+	config := framework.DefaultCoordinatorConfig()
+	config.UASTServiceURL = wc.uastService
+
+	if wc.workers > 0 {
+		config.Workers = wc.workers
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(framework.ChunkWorkerPath, framework.NewChunkWorkerHandler(repoPath, config))
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "serving chunks of %s on http://%s\n", repoPath, wc.addr)
+
+	server := &http.Server{
+		Addr:              wc.addr,
+		Handler:           observability.HTTPMiddleware(otel.Tracer("codefang"), slog.Default(), mux),
+		ReadHeaderTimeout: workerReadHeaderTimeout,
+		ReadTimeout:       workerReadTimeout,
+	}
+
+	err = server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve chunks: %w", err)
+	}
+
+	return nil
+}
@@ -25,7 +25,13 @@ func main() {
 		Long: `Codefang provides comprehensive code analysis tools.
 
 Commands:
-  run       Unified static + history analysis entrypoint`,
+  run       Unified static + history analysis entrypoint
+  snapshot  Report the state of the repository at HEAD
+  files     Browse per-file detail pages of a report
+  dedup     Drop duplicate records from ndjson output
+  store     Manage saved reports: list, compare, gc and scrub
+  bench     Benchmark the history pipeline on a repository
+  selftest  Check analyzer reports against golden reports`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
@@ -35,6 +41,14 @@ Commands:
 
 	// Add commands.
 	rootCmd.AddCommand(commands.NewRunCommand())
+	rootCmd.AddCommand(commands.NewSnapshotCommand())
+	rootCmd.AddCommand(commands.NewFilesCommand())
+	rootCmd.AddCommand(commands.NewDedupCommand())
+	rootCmd.AddCommand(commands.NewStoreCommand())
+	rootCmd.AddCommand(commands.NewBenchCommand())
+	rootCmd.AddCommand(commands.NewSelftestCommand())
+	rootCmd.AddCommand(commands.NewChangelogCommand())
+	rootCmd.AddCommand(commands.NewWorkerCommand())
 	rootCmd.AddCommand(versionCmd())
 
 	err := rootCmd.Execute()
@@ -1,15 +1,25 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"io"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/cohesion"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/comments"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/complexity"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/halstead"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
 )
@@ -18,45 +28,104 @@ import (
 var (
 	ErrNoFilesSpecified  = errors.New("no files specified for analysis")
 	ErrUnsupportedAnaFmt = errors.New("unsupported format")
+	ErrUnknownAnalyzer   = errors.New("unknown analyzer")
 )
 
+// Output formats of the analyze command besides JSON.
+const (
+	formatText = "text"
+	formatHTML = "html"
+)
+
+// functionsReportKey is the report key of the per-function tables.
+const functionsReportKey = "functions"
+
+// metricAnalyzers returns the codefang static analyzers uast analyze runs.
+// They are the same analyzers, with the same computations, as the static
+// analyzers of codefang run.
+func metricAnalyzers() []analyze.StaticAnalyzer {
+	return []analyze.StaticAnalyzer{
+		complexity.NewAnalyzer(),
+		halstead.NewAnalyzer(),
+		cohesion.NewAnalyzer(),
+		comments.NewAnalyzer(),
+	}
+}
+
+// fileAnalysis is the analysis of one file.
+type fileAnalysis struct {
+	File string `json:"file"`
+	// Functions are ordered by name, with the metrics of every analyzer
+	// that reports the function.
+	Functions []functionAnalysis `json:"functions"`
+	// Metrics holds, per analyzer, the metrics codefang run -f json
+	// outputs for the file.
+	Metrics map[string]json.RawMessage `json:"metrics"`
+
+	reports map[string]analyze.Report
+}
+
+// functionAnalysis holds the metrics of one function, per analyzer.
+type functionAnalysis struct {
+	Name    string                    `json:"name"`
+	Metrics map[string]map[string]any `json:"metrics"`
+}
+
 func analyzeCmd() *cobra.Command {
 	var output, format string
 
+	var analyzers []string
+
 	cmd := &cobra.Command{
 		Use:   "analyze [files...]",
 		Short: "Analyze code complexity and structure",
-		Long: `Analyze source code for complexity, structure, and patterns.
+		Long: `Analyze source code with the static analyzers of codefang: complexity,
+halstead, cohesion and comments. The metrics match codefang run; the JSON
+output adds the metrics of every analyzer per function.
 
 Examples:
   uast analyze main.go                  # Analyze single file
   uast analyze *.go                     # Analyze all Go files
-  uast analyze -f text *.go            # Text output format
-  uast analyze -o report.html *.go     # Generate HTML report`,
-		RunE: func(_ *cobra.Command, args []string) error {
-			return runAnalyze(args, output, format)
+  uast analyze -f json *.go            # Per-function JSON output
+  uast analyze -a complexity main.go   # Run one analyzer
+  uast analyze -o report.html -f html *.go  # Generate HTML report`,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return runAnalyze(cobraCmd.Context(), args, analyzers, output, format)
 		},
 	}
 
 	cmd.Flags().StringVarP(&output, "output", "o", "", "output file (default: stdout)")
-	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format (text, json, html)")
+	cmd.Flags().StringVarP(&format, "format", "f", formatText, "output format (text, json, html)")
+	cmd.Flags().StringSliceVarP(&analyzers, "analyzers", "a", nil,
+		"analyzers to run: complexity, halstead, cohesion, comments (default: all)")
 
 	return cmd
 }
 
-func runAnalyze(files []string, output, format string) error {
+func runAnalyze(ctx context.Context, files, analyzers []string, output, format string) error {
 	if len(files) == 0 {
 		return ErrNoFilesSpecified
 	}
 
-	var allResults []map[string]any
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
-	for _, file := range files {
-		parser, err := uast.NewParser()
-		if err != nil {
-			return fmt.Errorf("failed to initialize parser: %w", err)
-		}
+	names, err := selectAnalyzers(analyzers)
+	if err != nil {
+		return err
+	}
+
+	parser, err := uast.NewParser()
+	if err != nil {
+		return fmt.Errorf("failed to initialize parser: %w", err)
+	}
+
+	factory := analyze.NewFactory(metricAnalyzers())
 
+	var allResults []*fileAnalysis
+
+	for _, file := range files {
 		if !parser.IsSupported(file) {
 			fmt.Fprintf(os.Stderr, "Warning: Skipping unsupported file %s\n", file)
 
@@ -68,24 +137,138 @@ func runAnalyze(files []string, output, format string) error {
 			return fmt.Errorf("failed to read file %s: %w", file, err)
 		}
 
-		parsedNode, err := parser.Parse(context.Background(), file, code)
+		parsedNode, err := parser.Parse(ctx, file, code)
 		if err != nil {
 			return fmt.Errorf("parse error in %s: %w", file, err)
 		}
 
-		analysis := analyzeNode(parsedNode, file)
+		analysis, err := analyzeNode(ctx, factory, names, parsedNode, file)
+		if err != nil {
+			return fmt.Errorf("analyze %s: %w", file, err)
+		}
+
 		allResults = append(allResults, analysis)
 	}
 
-	return outputAnalysis(allResults, output, format)
+	return outputAnalysis(allResults, names, output, format)
 }
 
-// analyzeNode produces analysis data for a parsed UAST node.
-func analyzeNode(_ *node.Node, _ string) map[string]any {
-	return map[string]any{}
+// selectAnalyzers validates the requested analyzer names. No names selects
+// every metric analyzer.
+func selectAnalyzers(requested []string) ([]string, error) {
+	available := make([]string, 0, len(metricAnalyzers()))
+	for _, a := range metricAnalyzers() {
+		available = append(available, a.Name())
+	}
+
+	if len(requested) == 0 {
+		return available, nil
+	}
+
+	names := make([]string, 0, len(requested))
+
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(available, name) {
+			return nil, fmt.Errorf("%w: %q (want one of %s)", ErrUnknownAnalyzer, name, strings.Join(available, ", "))
+		}
+
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
 }
 
-func outputAnalysis(results []map[string]any, output, format string) error {
+// analyzeNode runs the named analyzers on a parsed UAST node.
+func analyzeNode(
+	ctx context.Context, factory *analyze.Factory, names []string, root *node.Node, file string,
+) (*fileAnalysis, error) {
+	reports, err := factory.RunAnalyzers(ctx, root, names)
+	if err != nil {
+		return nil, fmt.Errorf("run analyzers: %w", err)
+	}
+
+	analysis := &fileAnalysis{
+		File:      file,
+		Functions: mergeFunctions(names, reports),
+		Metrics:   make(map[string]json.RawMessage, len(names)),
+		reports:   reports,
+	}
+
+	for _, a := range metricAnalyzers() {
+		report, ok := reports[a.Name()]
+		if !ok {
+			continue
+		}
+
+		var buf bytes.Buffer
+
+		err = a.FormatReportJSON(report, &buf)
+		if err != nil {
+			return nil, fmt.Errorf("format %s: %w", a.Name(), err)
+		}
+
+		analysis.Metrics[a.Name()] = json.RawMessage(buf.Bytes())
+	}
+
+	return analysis, nil
+}
+
+// mergeFunctions joins the per-function tables of the reports by function
+// name. Functions sharing a name, such as methods of different types, are
+// paired in the order each analyzer reports them.
+func mergeFunctions(names []string, reports map[string]analyze.Report) []functionAnalysis {
+	functions := []functionAnalysis{}
+	byName := make(map[string][]int)
+
+	for _, analyzerName := range names {
+		entries, ok := analyze.ReportFunctionList(reports[analyzerName], functionsReportKey)
+		if !ok {
+			continue
+		}
+
+		seen := make(map[string]int)
+
+		for _, entry := range entries {
+			name := functionName(entry)
+			occurrence := seen[name]
+			seen[name]++
+
+			if occurrence == len(byName[name]) {
+				functions = append(functions, functionAnalysis{Name: name, Metrics: make(map[string]map[string]any)})
+				byName[name] = append(byName[name], len(functions)-1)
+			}
+
+			metrics := maps.Clone(entry)
+			delete(metrics, "name")
+			delete(metrics, "function")
+
+			functions[byName[name][occurrence]].Metrics[analyzerName] = metrics
+		}
+	}
+
+	slices.SortStableFunc(functions, func(a, b functionAnalysis) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return functions
+}
+
+// functionName returns the function name of a table entry. The comments
+// analyzer keys it "function", the others "name".
+func functionName(entry map[string]any) string {
+	if name, ok := entry["name"].(string); ok {
+		return name
+	}
+
+	name, _ := entry["function"].(string)
+
+	return name
+}
+
+func outputAnalysis(results []*fileAnalysis, names []string, output, format string) error {
 	var writer io.Writer = os.Stdout
 
 	if output != "" {
@@ -101,11 +284,9 @@ func outputAnalysis(results []map[string]any, output, format string) error {
 	switch format {
 	case formatJSON:
 		return outputAnalysisJSON(results, writer)
-	case "text":
-		outputAnalysisText(results, writer)
-
-		return nil
-	case "html":
+	case formatText:
+		return outputAnalysisText(results, names, writer)
+	case formatHTML:
 		generateHTMLReport(results, writer)
 
 		return nil
@@ -114,7 +295,11 @@ func outputAnalysis(results []map[string]any, output, format string) error {
 	}
 }
 
-func outputAnalysisJSON(results []map[string]any, writer io.Writer) error {
+func outputAnalysisJSON(results []*fileAnalysis, writer io.Writer) error {
+	if results == nil {
+		results = []*fileAnalysis{}
+	}
+
 	enc := json.NewEncoder(writer)
 	enc.SetIndent("", "  ")
 
@@ -126,30 +311,47 @@ func outputAnalysisJSON(results []map[string]any, writer io.Writer) error {
 	return nil
 }
 
-func outputAnalysisText(results []map[string]any, writer io.Writer) {
+// outputAnalysisText prints the reports of every file the way codefang run
+// prints them.
+func outputAnalysisText(results []*fileAnalysis, names []string, writer io.Writer) error {
 	for _, result := range results {
-		fmt.Fprintf(writer, "File: %s\n", result["file"])
-		fmt.Fprintf(writer, "  Functions: %d\n", result["functions"])
-		fmt.Fprintf(writer, "  Complexity: %.2f\n", result["complexity"])
+		fmt.Fprintf(writer, "File: %s\n\n", result.File)
 
-		types, ok := result["types"].(map[string]int)
-		if !ok {
-			continue
-		}
-
-		if len(types) > 0 {
-			fmt.Fprintf(writer, "  Node types:\n")
+		for _, a := range metricAnalyzers() {
+			report, ok := result.reports[a.Name()]
+			if !ok || !slices.Contains(names, a.Name()) {
+				continue
+			}
 
-			for nodeType, count := range types {
-				fmt.Fprintf(writer, "    %s: %d\n", nodeType, count)
+			err := a.FormatReport(report, writer)
+			if err != nil {
+				return fmt.Errorf("format %s: %w", a.Name(), err)
 			}
-		}
 
-		fmt.Fprintf(writer, "\n")
+			fmt.Fprintln(writer)
+		}
 	}
+
+	return nil
 }
 
-func generateHTMLReport(results []map[string]any, writer io.Writer) {
+// htmlColumn is a per-function metric shown in the HTML report.
+type htmlColumn struct {
+	title    string
+	analyzer string
+	key      string
+}
+
+var htmlColumns = []htmlColumn{
+	{"Cyclomatic", "complexity", "cyclomatic_complexity"},
+	{"Cognitive", "complexity", "cognitive_complexity"},
+	{"Halstead Volume", "halstead", "volume"},
+	{"Halstead Effort", "halstead", "effort"},
+	{"Cohesion", "cohesion", "cohesion"},
+	{"Comment", "comments", "assessment"},
+}
+
+func generateHTMLReport(results []*fileAnalysis, writer io.Writer) {
 	fmt.Fprintf(writer, "<!DOCTYPE html>\n<html>\n<head>\n<title>UAST Analysis Report</title>\n")
 	fmt.Fprintf(writer, "<style>\nbody{font-family:Arial,sans-serif;margin:20px;}\n")
 	fmt.Fprintf(writer, "table{border-collapse:collapse;width:100%%;}\n")
@@ -157,27 +359,38 @@ func generateHTMLReport(results []map[string]any, writer io.Writer) {
 	fmt.Fprintf(writer, "th{background-color:#f2f2f2;}\n</style>\n</head>\n<body>\n")
 
 	fmt.Fprintf(writer, "<h1>UAST Analysis Report</h1>\n")
-	fmt.Fprintf(writer, "<table>\n<tr><th>File</th><th>Functions</th><th>Complexity</th><th>Node Types</th></tr>\n")
+	fmt.Fprintf(writer, "<table>\n<tr><th>File</th><th>Function</th>")
 
-	for _, result := range results {
-		types, ok := result["types"].(map[string]int)
-		if !ok {
-			types = map[string]int{}
-		}
+	for _, column := range htmlColumns {
+		fmt.Fprintf(writer, "<th>%s</th>", column.title)
+	}
+
+	fmt.Fprintf(writer, "</tr>\n")
 
-		typeStr := ""
+	for _, result := range results {
+		for _, function := range result.Functions {
+			fmt.Fprintf(writer, "<tr><td>%s</td><td>%s</td>",
+				html.EscapeString(result.File), html.EscapeString(function.Name))
 
-		for nodeType, count := range types {
-			if typeStr != "" {
-				typeStr += ", "
+			for _, column := range htmlColumns {
+				fmt.Fprintf(writer, "<td>%s</td>", html.EscapeString(formatMetric(function.Metrics[column.analyzer][column.key])))
 			}
 
-			typeStr += fmt.Sprintf("%s: %d", nodeType, count)
+			fmt.Fprintf(writer, "</tr>\n")
 		}
-
-		fmt.Fprintf(writer, "<tr><td>%s</td><td>%d</td><td>%.2f</td><td>%s</td></tr>\n",
-			result["file"], result["functions"], result["complexity"], typeStr)
 	}
 
 	fmt.Fprintf(writer, "</table>\n</body>\n</html>\n")
 }
+
+// formatMetric renders a metric value for the HTML report.
+func formatMetric(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+func TestSelectAnalyzers(t *testing.T) {
+	t.Parallel()
+
+	names, err := selectAnalyzers(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"complexity", "halstead", "cohesion", "comments"}, names)
+
+	names, err = selectAnalyzers([]string{"halstead", " complexity", "halstead"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"halstead", "complexity"}, names)
+
+	_, err = selectAnalyzers([]string{"imports"})
+	require.ErrorIs(t, err, ErrUnknownAnalyzer)
+}
+
+func TestMergeFunctions(t *testing.T) {
+	t.Parallel()
+
+	reports := map[string]analyze.Report{
+		"complexity": {"functions": []map[string]any{
+			{"name": "run", "cyclomatic_complexity": 3},
+			{"name": "String", "cyclomatic_complexity": 1},
+			{"name": "String", "cyclomatic_complexity": 2},
+		}},
+		"comments": {"functions": []any{
+			map[string]any{"function": "run", "assessment": "documented"},
+			map[string]any{"function": "String", "assessment": "undocumented"},
+		}},
+	}
+
+	functions := mergeFunctions([]string{"complexity", "halstead", "comments"}, reports)
+	require.Len(t, functions, 3)
+
+	assert.Equal(t, "String", functions[0].Name)
+	assert.Equal(t, map[string]map[string]any{
+		"complexity": {"cyclomatic_complexity": 1},
+		"comments":   {"assessment": "undocumented"},
+	}, functions[0].Metrics)
+
+	assert.Equal(t, "String", functions[1].Name)
+	assert.Equal(t, map[string]map[string]any{"complexity": {"cyclomatic_complexity": 2}}, functions[1].Metrics)
+
+	assert.Equal(t, "run", functions[2].Name)
+	assert.Equal(t, "documented", functions[2].Metrics["comments"]["assessment"])
+
+	assert.Empty(t, mergeFunctions([]string{"halstead"}, reports))
+}
+
+func TestAnalyzeNode_MatchesStaticAnalyzers(t *testing.T) {
+	t.Parallel()
+
+	root := &node.Node{Type: "File", Children: []*node.Node{
+		{
+			Type:  node.UASTFunction,
+			Roles: []node.Role{node.RoleFunction, node.RoleDeclaration},
+			Pos:   &node.Positions{StartLine: 1, EndLine: 5},
+			Children: []*node.Node{
+				{Type: node.UASTIdentifier, Token: "run", Roles: []node.Role{node.RoleName}},
+				{Type: node.UASTIf, Children: []*node.Node{
+					{Type: node.UASTIdentifier, Token: "ok"},
+				}},
+			},
+		},
+	}}
+
+	names, err := selectAnalyzers(nil)
+	require.NoError(t, err)
+
+	analysis, err := analyzeNode(context.Background(), analyze.NewFactory(metricAnalyzers()), names, root, "main.go")
+	require.NoError(t, err)
+
+	assert.Equal(t, "main.go", analysis.File)
+	assert.Len(t, analysis.Metrics, len(names))
+
+	// The file metrics are what the static analyzer itself outputs as JSON.
+	for _, a := range metricAnalyzers() {
+		var want bytes.Buffer
+
+		require.NoError(t, a.FormatReportJSON(analysis.reports[a.Name()], &want))
+		assert.JSONEq(t, want.String(), string(analysis.Metrics[a.Name()]), a.Name())
+	}
+
+	encoded, err := json.Marshal(analysis)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"functions"`)
+	assert.Contains(t, string(encoded), `"metrics"`)
+}
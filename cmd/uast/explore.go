@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/uast"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
@@ -28,39 +29,53 @@ var (
 func exploreCmd() *cobra.Command {
 	var lang string
 
+	var plain bool
+
 	cmd := &cobra.Command{
 		Use:   "explore [file]",
 		Short: "Interactive UAST exploration",
 		Long: `Start an interactive session to explore UAST structure.
 
+On a terminal, explore opens a tree browser: fold and unfold nodes, search
+node types, tokens and roles with /, evaluate DSL queries against the tree
+with : and step through the matches with n and N. The source of the selected
+node shows below the tree, and e opens it in $EDITOR. Press ? for all keys.
+Without a terminal, or with --plain, explore reads commands line by line.
+
 Examples:
   uast explore main.go                  # Explore a file
-  uast explore -l go main.c            # Force language detection`,
+  uast explore -l go main.c            # Force language detection
+  uast explore --plain main.go         # Line-based command session`,
 		RunE: func(_ *cobra.Command, args []string) error {
 			file := ""
 			if len(args) > 0 {
 				file = args[0]
 			}
 
-			return runExplore(file, lang)
+			return runExplore(file, lang, plain)
 		},
 	}
 
 	cmd.Flags().StringVarP(&lang, "language", "l", "", "force language detection")
+	cmd.Flags().BoolVar(&plain, "plain", false, "read commands line by line instead of opening the tree browser")
 
 	return cmd
 }
 
-func runExplore(file, lang string) error {
+func runExplore(file, lang string, plain bool) error {
 	if file == "" {
 		return ErrNoFileSpecified
 	}
 
-	parsedNode, err := parseExploreFile(file, lang)
+	parsedNode, code, err := parseExploreFile(file, lang)
 	if err != nil {
 		return err
 	}
 
+	if !plain && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd())) {
+		return runExploreTUI(newExploreModel(parsedNode, file, code))
+	}
+
 	fmt.Fprintf(os.Stdout, "Exploring %s\n", sanitizeForTerminal(file))
 	fmt.Fprintln(os.Stdout, "Type 'help' for commands, 'quit' to exit")
 	fmt.Fprintln(os.Stdout)
@@ -68,19 +83,20 @@ func runExplore(file, lang string) error {
 	return runExploreLoop(parsedNode)
 }
 
-func parseExploreFile(file, lang string) (*node.Node, error) {
+// parseExploreFile parses file and returns its UAST and source code.
+func parseExploreFile(file, lang string) (*node.Node, []byte, error) {
 	parser, err := uast.NewParser()
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize parser: %w", err)
+		return nil, nil, fmt.Errorf("failed to initialize parser: %w", err)
 	}
 
 	if !parser.IsSupported(file) {
-		return nil, fmt.Errorf("%w: %s", ErrUnsupportedExploreFile, file)
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedExploreFile, file)
 	}
 
 	code, resolvedPath, err := safeReadFile(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", file, err)
+		return nil, nil, fmt.Errorf("failed to read file %s: %w", file, err)
 	}
 
 	filename := resolvedPath
@@ -91,10 +107,10 @@ func parseExploreFile(file, lang string) (*node.Node, error) {
 
 	parsedNode, err := parser.Parse(context.Background(), filename, code)
 	if err != nil {
-		return nil, fmt.Errorf("parse error in %s: %w", file, err)
+		return nil, nil, fmt.Errorf("parse error in %s: %w", file, err)
 	}
 
-	return parsedNode, nil
+	return parsedNode, code, nil
 }
 
 func runExploreLoop(parsedNode *node.Node) error {
@@ -0,0 +1,668 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// exploreInitialDepth is the depth below which nodes start folded.
+const exploreInitialDepth = 2
+
+// exploreSourceShare is the share of the screen the source pane takes.
+const exploreSourceShare = 3
+
+// exploreChromeRows are the header and status rows around the tree.
+const exploreChromeRows = 2
+
+// exploreTokenWidth caps the token shown next to a node type.
+const exploreTokenWidth = 40
+
+// exploreMaxValues caps the non-node query results shown in the status line.
+const exploreMaxValues = 5
+
+// ANSI sequences of the explore TUI.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiReverse = "\x1b[7m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiMatch   = "\x1b[1;33m"
+)
+
+// exploreMode is what the keys typed in the explore TUI act on.
+type exploreMode int
+
+const (
+	// modeBrowse moves through the tree.
+	modeBrowse exploreMode = iota
+	// modeSearch edits a search term.
+	modeSearch
+	// modeQuery edits a DSL query.
+	modeQuery
+)
+
+// exploreKey is a decoded key press: a named key or a rune.
+type exploreKey struct {
+	name string
+	r    rune
+}
+
+// Names of the special keys.
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdn"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyInterrupt = "ctrl-c"
+)
+
+// exploreRow is a visible line of the tree.
+type exploreRow struct {
+	node  *node.Node
+	depth int
+}
+
+// exploreModel is the state of the explore TUI: the tree with its folds,
+// the cursor, the search or query matches and the line being edited. It
+// does no I/O, so the terminal loop only feeds it keys and prints views.
+type exploreModel struct {
+	root    *node.Node
+	file    string
+	source  []string
+	parents map[*node.Node]*node.Node
+	folded  map[*node.Node]bool
+	rows    []exploreRow
+
+	cursor int
+	offset int
+	width  int
+	height int
+
+	mode      exploreMode
+	input     string
+	lastQuery string
+	matches   []*node.Node
+	isMatch   map[*node.Node]bool
+	match     int
+	status    string
+
+	showSource bool
+	showHelp   bool
+	// editLine is set when the user asked to open the source at a line.
+	editLine int
+	quit     bool
+}
+
+func newExploreModel(root *node.Node, file string, source []byte) *exploreModel {
+	m := &exploreModel{
+		root:       root,
+		file:       file,
+		source:     strings.Split(string(source), "\n"),
+		parents:    make(map[*node.Node]*node.Node),
+		folded:     make(map[*node.Node]bool),
+		width:      80,
+		height:     24,
+		showSource: true,
+	}
+
+	var walk func(nd *node.Node, depth int)
+
+	walk = func(nd *node.Node, depth int) {
+		if depth >= exploreInitialDepth && len(nd.Children) > 0 {
+			m.folded[nd] = true
+		}
+
+		for _, child := range nd.Children {
+			m.parents[child] = nd
+			walk(child, depth+1)
+		}
+	}
+
+	walk(root, 0)
+	m.rebuild()
+
+	return m
+}
+
+// rebuild recomputes the visible rows, keeping the cursor on its node.
+func (m *exploreModel) rebuild() {
+	current := m.selected()
+	m.rows = m.rows[:0]
+
+	var walk func(nd *node.Node, depth int)
+
+	walk = func(nd *node.Node, depth int) {
+		m.rows = append(m.rows, exploreRow{node: nd, depth: depth})
+
+		if m.folded[nd] {
+			return
+		}
+
+		for _, child := range nd.Children {
+			walk(child, depth+1)
+		}
+	}
+
+	walk(m.root, 0)
+
+	m.cursor = 0
+
+	for i, row := range m.rows {
+		if row.node == current {
+			m.cursor = i
+
+			break
+		}
+	}
+
+	m.scroll()
+}
+
+// selected returns the node under the cursor.
+func (m *exploreModel) selected() *node.Node {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return nil
+	}
+
+	return m.rows[m.cursor].node
+}
+
+func (m *exploreModel) resize(width, height int) {
+	if width > 0 && height > 0 {
+		m.width, m.height = width, height
+		m.scroll()
+	}
+}
+
+// treeHeight is the number of tree rows that fit on screen.
+func (m *exploreModel) treeHeight() int {
+	height := m.height - exploreChromeRows
+	if m.showSource {
+		height -= m.height / exploreSourceShare
+	}
+
+	return max(height, 1)
+}
+
+// scroll keeps the cursor within the visible tree rows.
+func (m *exploreModel) scroll() {
+	height := m.treeHeight()
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+
+	m.offset = max(min(m.offset, len(m.rows)-height), 0)
+}
+
+func (m *exploreModel) moveCursor(delta int) {
+	m.cursor = max(min(m.cursor+delta, len(m.rows)-1), 0)
+	m.scroll()
+}
+
+// fold folds the selected node, or moves to its parent when there is
+// nothing to fold.
+func (m *exploreModel) fold() {
+	nd := m.selected()
+	if nd == nil {
+		return
+	}
+
+	if len(nd.Children) > 0 && !m.folded[nd] {
+		m.folded[nd] = true
+		m.rebuild()
+
+		return
+	}
+
+	if parent := m.parents[nd]; parent != nil {
+		m.reveal(parent)
+	}
+}
+
+// unfold unfolds the selected node, or moves to its first child when it
+// is unfolded already.
+func (m *exploreModel) unfold() {
+	nd := m.selected()
+	if nd == nil || len(nd.Children) == 0 {
+		return
+	}
+
+	if m.folded[nd] {
+		delete(m.folded, nd)
+		m.rebuild()
+
+		return
+	}
+
+	m.moveCursor(1)
+}
+
+func (m *exploreModel) toggleFold() {
+	nd := m.selected()
+	if nd == nil || len(nd.Children) == 0 {
+		return
+	}
+
+	if m.folded[nd] {
+		delete(m.folded, nd)
+	} else {
+		m.folded[nd] = true
+	}
+
+	m.rebuild()
+}
+
+// setAllFolded folds every node below the root, or unfolds every node.
+func (m *exploreModel) setAllFolded(folded bool) {
+	clear(m.folded)
+
+	if folded {
+		for _, child := range m.root.Children {
+			child.VisitPreOrder(func(nd *node.Node) {
+				if len(nd.Children) > 0 {
+					m.folded[nd] = true
+				}
+			})
+		}
+	}
+
+	m.rebuild()
+}
+
+// reveal unfolds the ancestors of nd and moves the cursor onto it.
+func (m *exploreModel) reveal(nd *node.Node) {
+	for parent := m.parents[nd]; parent != nil; parent = m.parents[parent] {
+		delete(m.folded, parent)
+	}
+
+	m.rebuild()
+
+	for i, row := range m.rows {
+		if row.node == nd {
+			m.cursor = i
+			m.scroll()
+
+			return
+		}
+	}
+}
+
+// search matches the nodes whose type, token or roles contain term,
+// ignoring case.
+func (m *exploreModel) search(term string) {
+	term = strings.ToLower(strings.TrimSpace(term))
+	if term == "" {
+		m.setMatches(nil)
+
+		return
+	}
+
+	var found []*node.Node
+
+	m.root.VisitPreOrder(func(nd *node.Node) {
+		if nodeMatches(nd, term) {
+			found = append(found, nd)
+		}
+	})
+
+	m.setMatches(found)
+	m.status = strconv.Itoa(len(found)) + " matches for " + strconv.Quote(term)
+}
+
+func nodeMatches(nd *node.Node, term string) bool {
+	if strings.Contains(strings.ToLower(string(nd.Type)), term) ||
+		strings.Contains(strings.ToLower(nd.Token), term) {
+		return true
+	}
+
+	for _, role := range nd.Roles {
+		if strings.Contains(strings.ToLower(string(role)), term) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// query evaluates a DSL query against the tree. Result nodes of the tree
+// become matches; other results, such as counts, are shown as values.
+func (m *exploreModel) query(dsl string) {
+	dsl = strings.TrimSpace(dsl)
+	if dsl == "" {
+		return
+	}
+
+	m.lastQuery = dsl
+
+	results, err := m.root.FindDSL(dsl)
+	if err != nil {
+		m.status = "query error: " + err.Error()
+
+		return
+	}
+
+	var (
+		found  []*node.Node
+		values []string
+	)
+
+	for _, result := range results {
+		if result == m.root || m.parents[result] != nil {
+			found = append(found, result)
+		} else {
+			values = append(values, result.Token)
+		}
+	}
+
+	m.setMatches(found)
+	m.status = strconv.Itoa(len(found)) + " nodes"
+
+	if len(values) > 0 {
+		if len(values) > exploreMaxValues {
+			values = append(values[:exploreMaxValues], "...")
+		}
+
+		m.status += ", values: " + strings.Join(values, ", ")
+	}
+}
+
+// setMatches replaces the matches and moves to the first one at or after
+// the cursor.
+func (m *exploreModel) setMatches(found []*node.Node) {
+	m.matches = found
+	m.isMatch = make(map[*node.Node]bool, len(found))
+	m.match = -1
+
+	for _, nd := range found {
+		m.isMatch[nd] = true
+	}
+
+	if len(found) == 0 {
+		return
+	}
+
+	order := make(map[*node.Node]int)
+
+	m.root.VisitPreOrder(func(nd *node.Node) {
+		order[nd] = len(order)
+	})
+
+	current := order[m.selected()]
+
+	m.match = 0
+
+	for i, nd := range found {
+		if order[nd] >= current {
+			m.match = i
+
+			break
+		}
+	}
+
+	m.reveal(found[m.match])
+}
+
+// nextMatch moves to the next match, wrapping around; delta -1 moves back.
+func (m *exploreModel) nextMatch(delta int) {
+	if len(m.matches) == 0 {
+		m.status = "no matches"
+
+		return
+	}
+
+	m.match = (m.match + delta + len(m.matches)) % len(m.matches)
+	m.reveal(m.matches[m.match])
+	m.status = "match " + strconv.Itoa(m.match+1) + "/" + strconv.Itoa(len(m.matches))
+}
+
+// jumpToSource asks the terminal loop to open the file at the line of the
+// selected node.
+func (m *exploreModel) jumpToSource() {
+	nd := m.selected()
+	if nd == nil || nd.Pos == nil || nd.Pos.StartLine == 0 {
+		m.status = "node has no position"
+
+		return
+	}
+
+	m.editLine = int(nd.Pos.StartLine)
+}
+
+func (m *exploreModel) handleKey(key exploreKey) {
+	if key.name == keyInterrupt {
+		m.quit = true
+
+		return
+	}
+
+	if m.mode != modeBrowse {
+		m.handleInputKey(key)
+
+		return
+	}
+
+	m.showHelp = false
+
+	switch {
+	case key.name == keyUp || key.r == 'k':
+		m.moveCursor(-1)
+	case key.name == keyDown || key.r == 'j':
+		m.moveCursor(1)
+	case key.name == keyPageUp:
+		m.moveCursor(-m.treeHeight())
+	case key.name == keyPageDown:
+		m.moveCursor(m.treeHeight())
+	case key.name == keyHome || key.r == 'g':
+		m.moveCursor(-len(m.rows))
+	case key.name == keyEnd || key.r == 'G':
+		m.moveCursor(len(m.rows))
+	case key.name == keyLeft || key.r == 'h':
+		m.fold()
+	case key.name == keyRight || key.r == 'l':
+		m.unfold()
+	case key.name == keyEnter || key.r == ' ':
+		m.toggleFold()
+	case key.r == '-':
+		m.setAllFolded(true)
+	case key.r == '+':
+		m.setAllFolded(false)
+	case key.r == '/':
+		m.mode, m.input = modeSearch, ""
+	case key.r == ':':
+		m.mode, m.input = modeQuery, m.lastQuery
+	case key.r == 'n':
+		m.nextMatch(1)
+	case key.r == 'N':
+		m.nextMatch(-1)
+	case key.name == keyEscape:
+		m.setMatches(nil)
+		m.status = ""
+	case key.r == 's':
+		m.showSource = !m.showSource
+		m.scroll()
+	case key.r == 'e':
+		m.jumpToSource()
+	case key.r == '?':
+		m.showHelp = true
+	case key.r == 'q':
+		m.quit = true
+	}
+}
+
+// handleInputKey edits the search term or the query.
+func (m *exploreModel) handleInputKey(key exploreKey) {
+	switch key.name {
+	case keyEscape:
+		m.mode = modeBrowse
+	case keyEnter:
+		mode := m.mode
+		m.mode = modeBrowse
+
+		if mode == modeSearch {
+			m.search(m.input)
+		} else {
+			m.query(m.input)
+		}
+	case keyBackspace:
+		if runes := []rune(m.input); len(runes) > 0 {
+			m.input = string(runes[:len(runes)-1])
+		}
+	case "":
+		if unicode.IsPrint(key.r) {
+			m.input += string(key.r)
+		}
+	}
+}
+
+// view renders the screen: a header, the tree, the source of the selected
+// node and a status line.
+func (m *exploreModel) view() string {
+	var sb strings.Builder
+
+	sb.WriteString(ansiBold + m.clip(stripControl(m.file)+"  "+strconv.Itoa(len(m.rows))+" rows  ? for help") + ansiReset + "\n")
+
+	height := m.treeHeight()
+
+	for i := m.offset; i < m.offset+height; i++ {
+		if i < len(m.rows) {
+			sb.WriteString(m.renderRow(i))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	if m.showSource {
+		for _, line := range m.sourcePane(m.height / exploreSourceShare) {
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	sb.WriteString(m.statusLine())
+
+	return sb.String()
+}
+
+func (m *exploreModel) renderRow(i int) string {
+	row := m.rows[i]
+	nd := row.node
+
+	marker := "  "
+
+	if len(nd.Children) > 0 {
+		marker = "▾ "
+		if m.folded[nd] {
+			marker = "▸ "
+		}
+	}
+
+	text := strings.Repeat("  ", row.depth) + marker + stripControl(string(nd.Type))
+
+	if nd.Token != "" {
+		text += " " + strconv.Quote(truncate(nd.Token, exploreTokenWidth))
+	}
+
+	if len(nd.Roles) > 0 {
+		roles := make([]string, len(nd.Roles))
+		for j, role := range nd.Roles {
+			roles[j] = string(role)
+		}
+
+		text += " [" + stripControl(strings.Join(roles, ",")) + "]"
+	}
+
+	if nd.Pos != nil && nd.Pos.StartLine > 0 {
+		text += " :" + strconv.FormatUint(uint64(nd.Pos.StartLine), 10)
+	}
+
+	text = m.clip(text)
+
+	switch {
+	case i == m.cursor:
+		return ansiReverse + text + ansiReset
+	case m.isMatch[nd]:
+		return ansiMatch + text + ansiReset
+	default:
+		return text
+	}
+}
+
+// sourcePane returns height lines: a rule and the source lines of the
+// selected node, numbered.
+func (m *exploreModel) sourcePane(height int) []string {
+	lines := make([]string, 0, height)
+	lines = append(lines, ansiDim+strings.Repeat("─", max(m.width, 1))+ansiReset)
+
+	nd := m.selected()
+	if nd != nil && nd.Pos != nil && nd.Pos.StartLine > 0 {
+		start := int(nd.Pos.StartLine)
+		end := max(int(nd.Pos.EndLine), start)
+
+		for line := start; line <= end && line <= len(m.source) && len(lines) < height; line++ {
+			lines = append(lines, m.clip(strconv.Itoa(line)+"  "+stripControl(m.source[line-1])))
+		}
+	}
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+
+	return lines
+}
+
+func (m *exploreModel) statusLine() string {
+	switch {
+	case m.mode == modeSearch:
+		return "/" + m.input
+	case m.mode == modeQuery:
+		return ":" + m.input
+	case m.showHelp:
+		return m.clip("↑↓/jk move  ←→/hl fold  enter toggle  -/+ fold all  / search  : query  n/N next  " +
+			"s source  e editor  esc clear  q quit")
+	default:
+		return m.clip(stripControl(m.status))
+	}
+}
+
+// clip cuts text to the screen width.
+func (m *exploreModel) clip(text string) string {
+	return truncate(text, m.width)
+}
+
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+
+	return string(runes[:max(width-1, 0)]) + "…"
+}
+
+// stripControl drops control characters, so source text cannot move the
+// cursor or change colors. Tabs become spaces.
+func stripControl(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		default:
+			return r
+		}
+	}, text)
+}
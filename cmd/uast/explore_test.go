@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+const exploreTestSource = "package main\n\nfunc Run() {\n\tstart()\n}\n\nfunc stop() {}\n"
+
+// newExploreTestTree builds File > Function{Identifier, Block > Call} twice.
+func newExploreTestTree() *node.Node {
+	run := &node.Node{
+		Type:  node.UASTFunction,
+		Token: "Run",
+		Roles: []node.Role{node.RoleExported},
+		Pos:   &node.Positions{StartLine: 3, EndLine: 5},
+		Children: []*node.Node{
+			{Type: node.UASTIdentifier, Token: "Run", Pos: &node.Positions{StartLine: 3, EndLine: 3}},
+			{Type: node.UASTBlock, Pos: &node.Positions{StartLine: 3, EndLine: 5}, Children: []*node.Node{
+				{Type: "Call", Token: "start", Pos: &node.Positions{StartLine: 4, EndLine: 4}},
+			}},
+		},
+	}
+	stop := &node.Node{
+		Type:  node.UASTFunction,
+		Token: "stop",
+		Pos:   &node.Positions{StartLine: 7, EndLine: 7},
+		Children: []*node.Node{
+			{Type: node.UASTIdentifier, Token: "stop", Pos: &node.Positions{StartLine: 7, EndLine: 7}},
+		},
+	}
+
+	return &node.Node{Type: "File", Children: []*node.Node{run, stop}}
+}
+
+func newExploreTestModel(t *testing.T) *exploreModel {
+	t.Helper()
+
+	m := newExploreModel(newExploreTestTree(), "main.go", []byte(exploreTestSource))
+	m.resize(100, 30)
+
+	return m
+}
+
+func rowTypes(m *exploreModel) []string {
+	types := make([]string, len(m.rows))
+	for i, row := range m.rows {
+		types[i] = string(row.node.Type)
+	}
+
+	return types
+}
+
+func TestExploreModel_Folding(t *testing.T) {
+	t.Parallel()
+
+	m := newExploreTestModel(t)
+
+	// Nodes below exploreInitialDepth start folded.
+	assert.Equal(t, []string{"File", "Function", "Identifier", "Block", "Function", "Identifier"}, rowTypes(m))
+
+	m.handleKey(exploreKey{r: 'j'})
+	m.handleKey(exploreKey{name: keyLeft})
+	assert.Equal(t, []string{"File", "Function", "Function", "Identifier"}, rowTypes(m))
+	assert.Equal(t, "Run", m.selected().Token)
+
+	// Left on a folded node moves to its parent.
+	m.handleKey(exploreKey{name: keyLeft})
+	assert.Equal(t, "File", string(m.selected().Type))
+
+	m.handleKey(exploreKey{r: '+'})
+	assert.Len(t, m.rows, 7)
+
+	m.handleKey(exploreKey{r: '-'})
+	assert.Equal(t, []string{"File", "Function", "Function"}, rowTypes(m))
+
+	m.handleKey(exploreKey{name: keyDown})
+	m.handleKey(exploreKey{name: keyEnter})
+	assert.Equal(t, []string{"File", "Function", "Identifier", "Block", "Function"}, rowTypes(m))
+}
+
+func TestExploreModel_Search(t *testing.T) {
+	t.Parallel()
+
+	m := newExploreTestModel(t)
+
+	for _, key := range []exploreKey{{r: '/'}, {r: 'S'}, {r: 'T'}, {r: 'A'}, {name: keyBackspace}, {r: 'a'}, {name: keyEnter}} {
+		m.handleKey(key)
+	}
+
+	// "sta" matches the token of the folded call, which gets revealed.
+	assert.Equal(t, modeBrowse, m.mode)
+	require.Len(t, m.matches, 1)
+	assert.Equal(t, "start", m.selected().Token)
+	assert.Contains(t, m.status, "1 matches")
+
+	m.search("exported")
+	require.Len(t, m.matches, 1)
+	assert.Equal(t, "Run", m.selected().Token)
+
+	m.handleKey(exploreKey{name: keyEscape})
+	assert.Empty(t, m.matches)
+}
+
+func TestExploreModel_Query(t *testing.T) {
+	t.Parallel()
+
+	m := newExploreTestModel(t)
+
+	m.query(`rfilter(.type == "Identifier")`)
+	require.Len(t, m.matches, 2)
+	assert.Equal(t, "Run", m.selected().Token)
+
+	m.handleKey(exploreKey{r: 'n'})
+	assert.Equal(t, "stop", m.selected().Token)
+	assert.Equal(t, "match 2/2", m.status)
+
+	m.handleKey(exploreKey{r: 'n'})
+	assert.Equal(t, "Run", m.selected().Token)
+
+	m.handleKey(exploreKey{r: 'N'})
+	assert.Equal(t, "stop", m.selected().Token)
+
+	// Values that are not tree nodes show in the status line.
+	m.query(`rfilter(.type == "Identifier") |> reduce(count)`)
+	assert.Empty(t, m.matches)
+	assert.Contains(t, m.status, "values: 2")
+
+	// The last query is offered again for editing.
+	m.handleKey(exploreKey{r: ':'})
+	assert.Equal(t, modeQuery, m.mode)
+	assert.Equal(t, m.lastQuery, m.input)
+
+	m.handleKey(exploreKey{name: keyEscape})
+	m.query("bad syntax (")
+	assert.Contains(t, m.status, "query error")
+}
+
+func TestExploreModel_SourceAndView(t *testing.T) {
+	t.Parallel()
+
+	m := newExploreTestModel(t)
+	m.search("start")
+
+	pane := m.sourcePane(4)
+	require.Len(t, pane, 4)
+	assert.Equal(t, "4   start()", pane[1])
+
+	view := m.view()
+	assert.Contains(t, view, "main.go")
+	assert.Contains(t, view, ansiReverse+"        Call \"start\" :4"+ansiReset)
+	assert.Len(t, strings.Split(view, "\n"), 30)
+
+	m.handleKey(exploreKey{r: 'e'})
+	assert.Equal(t, 4, m.editLine)
+
+	m.handleKey(exploreKey{r: 'q'})
+	assert.True(t, m.quit)
+}
+
+func TestExploreModel_Scroll(t *testing.T) {
+	t.Parallel()
+
+	m := newExploreTestModel(t)
+	m.handleKey(exploreKey{r: '+'})
+	m.resize(100, 6)
+
+	m.handleKey(exploreKey{r: 'G'})
+	assert.Equal(t, len(m.rows)-1, m.cursor)
+	assert.Equal(t, len(m.rows)-m.treeHeight(), m.offset)
+
+	m.handleKey(exploreKey{name: keyHome})
+	assert.Zero(t, m.offset)
+}
+
+func TestReadExploreKey(t *testing.T) {
+	t.Parallel()
+
+	reader := bufio.NewReader(strings.NewReader("\x1b[A\x1b[6~jé\r\x7f\x03"))
+
+	want := []exploreKey{
+		{name: keyUp}, {name: keyPageDown}, {r: 'j'}, {r: 'é'},
+		{name: keyEnter}, {name: keyBackspace}, {name: keyInterrupt},
+	}
+
+	for _, w := range want {
+		key, err := readExploreKey(reader)
+		require.NoError(t, err)
+		assert.Equal(t, w, key)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+
+	cmd := editorCommand("main.go", 12)
+	assert.Equal(t, []string{"code", "--wait", "+12", "main.go"}, cmd.Args)
+}
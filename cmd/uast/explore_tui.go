@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Terminal control sequences of the explore TUI.
+const (
+	termAltScreen   = "\x1b[?1049h"
+	termMainScreen  = "\x1b[?1049l"
+	termHideCursor  = "\x1b[?25l"
+	termShowCursor  = "\x1b[?25h"
+	termClearScreen = "\x1b[H\x1b[2J"
+)
+
+// Raw key bytes.
+const (
+	byteInterrupt = 0x03
+	byteBackspace = 0x08
+	byteEscape    = 0x1b
+	byteDelete    = 0x7f
+)
+
+// defaultEditor opens files when $VISUAL and $EDITOR are unset.
+const defaultEditor = "vi"
+
+// ErrNotTerminal is returned when the explore TUI runs without a terminal.
+var ErrNotTerminal = errors.New("explore TUI needs a terminal")
+
+// runExploreTUI runs the explore TUI on the terminal until the user quits.
+func runExploreTUI(model *exploreModel) error {
+	inFd, outFd := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(inFd) || !term.IsTerminal(outFd) {
+		return ErrNotTerminal
+	}
+
+	state, err := term.MakeRaw(inFd)
+	if err != nil {
+		return fmt.Errorf("enter raw mode: %w", err)
+	}
+
+	fmt.Fprint(os.Stdout, termAltScreen+termHideCursor)
+
+	defer func() {
+		fmt.Fprint(os.Stdout, termShowCursor+termMainScreen)
+
+		_ = term.Restore(inFd, state) //nolint:errcheck // best effort on exit.
+	}()
+
+	reader := bufio.NewReader(os.Stdin)
+
+	for !model.quit {
+		if width, height, sizeErr := term.GetSize(outFd); sizeErr == nil {
+			model.resize(width, height)
+		}
+
+		fmt.Fprint(os.Stdout, termClearScreen+strings.ReplaceAll(model.view(), "\n", "\r\n"))
+
+		key, readErr := readExploreKey(reader)
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+
+		if readErr != nil {
+			return fmt.Errorf("read key: %w", readErr)
+		}
+
+		model.handleKey(key)
+
+		if model.editLine > 0 {
+			state, err = openInEditor(inFd, state, model.file, model.editLine)
+			if err != nil {
+				model.status = "editor: " + err.Error()
+			}
+
+			model.editLine = 0
+		}
+	}
+
+	return nil
+}
+
+// openInEditor leaves raw mode and the alternate screen, runs the editor
+// on file at line and returns to the TUI.
+func openInEditor(inFd int, state *term.State, file string, line int) (*term.State, error) {
+	fmt.Fprint(os.Stdout, termShowCursor+termMainScreen)
+
+	_ = term.Restore(inFd, state) //nolint:errcheck // raw mode is entered again below.
+
+	runErr := editorCommand(file, line).Run()
+
+	newState, err := term.MakeRaw(inFd)
+	if err != nil {
+		return state, fmt.Errorf("enter raw mode: %w", err)
+	}
+
+	fmt.Fprint(os.Stdout, termAltScreen+termHideCursor)
+
+	if runErr != nil {
+		return newState, fmt.Errorf("run editor: %w", runErr)
+	}
+
+	return newState, nil
+}
+
+// editorCommand returns the command opening file at line in $VISUAL or
+// $EDITOR. The +line argument is understood by vi, emacs, nano and most
+// other terminal editors.
+func editorCommand(file string, line int) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		fields = []string{defaultEditor}
+	}
+
+	args := append(fields[1:], "+"+strconv.Itoa(line), file)
+
+	// #nosec G204 -- the editor is chosen by the user running the command.
+	cmd := exec.Command(fields[0], args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	return cmd
+}
+
+// readExploreKey reads one key press from a terminal in raw mode. Escape
+// sequences of the arrow and paging keys are decoded when they arrive in
+// one read; a lone escape byte is the escape key.
+func readExploreKey(reader *bufio.Reader) (exploreKey, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return exploreKey{}, err //nolint:wrapcheck // io.EOF is checked by the caller.
+	}
+
+	switch b {
+	case byteInterrupt:
+		return exploreKey{name: keyInterrupt}, nil
+	case '\r', '\n':
+		return exploreKey{name: keyEnter}, nil
+	case byteBackspace, byteDelete:
+		return exploreKey{name: keyBackspace}, nil
+	case byteEscape:
+		if reader.Buffered() == 0 {
+			return exploreKey{name: keyEscape}, nil
+		}
+
+		return readEscapeSequence(reader)
+	}
+
+	if err = reader.UnreadByte(); err != nil {
+		return exploreKey{}, fmt.Errorf("unread: %w", err)
+	}
+
+	r, _, err := reader.ReadRune()
+	if err != nil {
+		return exploreKey{}, err //nolint:wrapcheck // io.EOF is checked by the caller.
+	}
+
+	return exploreKey{r: r}, nil
+}
+
+// escapeKeys maps the CSI and SS3 sequences of special keys, without the
+// leading escape, to key names.
+var escapeKeys = map[string]string{
+	"[A": keyUp, "[B": keyDown, "[C": keyRight, "[D": keyLeft,
+	"OA": keyUp, "OB": keyDown, "OC": keyRight, "OD": keyLeft,
+	"[H": keyHome, "[F": keyEnd, "OH": keyHome, "OF": keyEnd,
+	"[1~": keyHome, "[4~": keyEnd, "[5~": keyPageUp, "[6~": keyPageDown,
+}
+
+func readEscapeSequence(reader *bufio.Reader) (exploreKey, error) {
+	var seq strings.Builder
+
+	for reader.Buffered() > 0 {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return exploreKey{}, err //nolint:wrapcheck // io.EOF is checked by the caller.
+		}
+
+		seq.WriteByte(b)
+
+		// A CSI sequence ends with a byte in 0x40–0x7E; SS3 with the byte
+		// after the O.
+		if seq.Len() > 1 && b >= '@' && b <= '~' {
+			break
+		}
+	}
+
+	if name, ok := escapeKeys[seq.String()]; ok {
+		return exploreKey{name: name}, nil
+	}
+
+	return exploreKey{name: keyEscape}, nil
+}
@@ -53,6 +53,8 @@ func mappingCmd() *cobra.Command {
 	cmd.Flags().StringVar(&language, "language", "", "Language for tree-sitter parsing (language name or grammar file path)")
 	cmd.Flags().StringVar(&extensions, "extensions", "", "Comma-separated list of file extensions for language declaration")
 
+	cmd.AddCommand(mappingTestCmd())
+
 	return cmd
 }
 
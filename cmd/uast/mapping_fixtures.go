@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+)
+
+// Sentinel errors for mapping tests.
+var (
+	ErrMappingTestFailed = errors.New("mapping test failed")
+	ErrNoFixtures        = errors.New("no fixtures found")
+	ErrMappingLoadPanic  = errors.New("panic while loading mapping")
+)
+
+// snapshotSuffix is appended to a fixture path to name its expected UAST.
+const snapshotSuffix = ".uast.json"
+
+// maxSnapshotDiffs caps the differences reported per fixture.
+const maxSnapshotDiffs = 10
+
+// maxDiffValueLen caps the length of values quoted in differences.
+const maxDiffValueLen = 80
+
+// snapshotFilePerm is the permission of written snapshots.
+const snapshotFilePerm = 0o644
+
+// Outcomes of a fixture test.
+const (
+	fixturePassed  = "PASS"
+	fixtureFailed  = "FAIL"
+	fixtureUpdated = "UPDATED"
+)
+
+// fixtureResult is the outcome of testing one fixture.
+type fixtureResult struct {
+	Fixture string
+	Status  string
+	Diffs   []string
+}
+
+func mappingTestCmd() *cobra.Command {
+	var fixturesDir string
+
+	var update bool
+
+	cmd := &cobra.Command{
+		Use:   "test <mapping.uastmap>",
+		Short: "Test a mapping against expected UAST snapshots",
+		Long: `Apply a mapping to every fixture file with one of its extensions and
+compare the UAST with the snapshot next to it, <fixture>` + snapshotSuffix + `.
+--update writes the snapshots of new fixtures and of changed output.
+
+Examples:
+  uast mapping test go.uastmap --fixtures testdata/go
+  uast mapping test go.uastmap --fixtures testdata/go --update`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMappingTest(cmd.Context(), args[0], fixturesDir, update, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&fixturesDir, "fixtures", "", "directory of fixture files")
+	cmd.Flags().BoolVar(&update, "update", false, "write snapshots from the current output")
+
+	_ = cmd.MarkFlagRequired("fixtures") //nolint:errcheck // the flag is defined above.
+
+	return cmd
+}
+
+func runMappingTest(ctx context.Context, mappingPath, fixturesDir string, update bool, writer io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parser, err := loadMappingFile(mappingPath)
+	if err != nil {
+		return err
+	}
+
+	fixtures, err := collectFixtures(fixturesDir, parser.Extensions())
+	if err != nil {
+		return err
+	}
+
+	if len(fixtures) == 0 {
+		return fmt.Errorf("%w in %s for %s", ErrNoFixtures, fixturesDir, strings.Join(parser.Extensions(), ", "))
+	}
+
+	counts := make(map[string]int)
+
+	for _, fixture := range fixtures {
+		result := testFixture(ctx, parser, fixture, update)
+		counts[result.Status]++
+
+		printFixtureResult(result, writer)
+	}
+
+	fmt.Fprintf(writer, "\n%d fixtures: %d passed, %d failed, %d updated\n",
+		len(fixtures), counts[fixturePassed], counts[fixtureFailed], counts[fixtureUpdated])
+
+	if counts[fixtureFailed] > 0 {
+		return fmt.Errorf("%w: %d of %d fixtures", ErrMappingTestFailed, counts[fixtureFailed], len(fixtures))
+	}
+
+	return nil
+}
+
+// loadMappingFile loads a mapping DSL file into a parser.
+func loadMappingFile(path string) (*uast.DSLParser, error) {
+	data, _, err := safeReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping: %w", err)
+	}
+
+	return loadMapping(path, data)
+}
+
+// loadMapping loads mapping DSL source, recovering from panics of the
+// grammar loader.
+func loadMapping(name string, data []byte) (parser *uast.DSLParser, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			parser, err = nil, fmt.Errorf("%w %s: %v", ErrMappingLoadPanic, name, r)
+		}
+	}()
+
+	parser = uast.NewDSLParser(bytes.NewReader(data))
+
+	err = parser.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mapping %s: %w", name, err)
+	}
+
+	return parser, nil
+}
+
+// collectFixtures returns the files under dir with one of the extensions.
+func collectFixtures(dir string, extensions []string) ([]string, error) {
+	var fixtures []string
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if entry.IsDir() || strings.HasSuffix(path, snapshotSuffix) {
+			return nil
+		}
+
+		ext := strings.ToLower(filepath.Ext(path))
+		if slices.ContainsFunc(extensions, func(candidate string) bool { return strings.ToLower(candidate) == ext }) {
+			fixtures = append(fixtures, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk fixtures: %w", err)
+	}
+
+	return fixtures, nil
+}
+
+// testFixture parses a fixture and compares its UAST with the snapshot,
+// writing the snapshot instead when update is set and they differ.
+func testFixture(ctx context.Context, parser *uast.DSLParser, fixture string, update bool) fixtureResult {
+	result := fixtureResult{Fixture: fixture, Status: fixtureFailed}
+
+	code, err := os.ReadFile(fixture)
+	if err != nil {
+		result.Diffs = []string{err.Error()}
+
+		return result
+	}
+
+	root, err := parser.Parse(ctx, fixture, code)
+	if err != nil {
+		result.Diffs = []string{"parse: " + err.Error()}
+
+		return result
+	}
+
+	gotData, err := json.MarshalIndent(root.ToMap(), "", "  ")
+	if err != nil {
+		result.Diffs = []string{"encode: " + err.Error()}
+
+		return result
+	}
+
+	var got any
+
+	_ = json.Unmarshal(gotData, &got) //nolint:errcheck // just encoded.
+
+	snapshot := fixture + snapshotSuffix
+
+	wantData, err := os.ReadFile(snapshot)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		result.Diffs = []string{"missing snapshot " + snapshot + "; run with --update to create it"}
+	case err != nil:
+		result.Diffs = []string{err.Error()}
+	default:
+		var want any
+
+		err = json.Unmarshal(wantData, &want)
+		if err != nil {
+			result.Diffs = []string{"invalid snapshot: " + err.Error()}
+		} else {
+			result.Diffs = snapshotDiff(want, got, "", nil)
+		}
+	}
+
+	if len(result.Diffs) == 0 {
+		result.Status = fixturePassed
+
+		return result
+	}
+
+	if !update {
+		return result
+	}
+
+	err = os.WriteFile(snapshot, append(gotData, '\n'), snapshotFilePerm)
+	if err != nil {
+		result.Diffs = []string{"write snapshot: " + err.Error()}
+
+		return result
+	}
+
+	result.Status = fixtureUpdated
+
+	return result
+}
+
+// snapshotDiff appends the differences between the expected and actual
+// UAST, as JSON values, up to maxSnapshotDiffs.
+func snapshotDiff(want, got any, path string, diffs []string) []string {
+	if len(diffs) >= maxSnapshotDiffs {
+		return diffs
+	}
+
+	switch wantValue := want.(type) {
+	case map[string]any:
+		gotValue, ok := got.(map[string]any)
+		if !ok {
+			return append(diffs, valueDiff(path, want, got))
+		}
+
+		keys := slices.Sorted(maps.Keys(wantValue))
+		for key := range gotValue {
+			if _, found := wantValue[key]; !found {
+				keys = append(keys, key)
+			}
+		}
+
+		for _, key := range keys {
+			diffs = snapshotDiff(wantValue[key], gotValue[key], joinDiffPath(path, key), diffs)
+		}
+
+		return diffs
+	case []any:
+		gotValue, ok := got.([]any)
+		if !ok {
+			return append(diffs, valueDiff(path, want, got))
+		}
+
+		for idx := range min(len(wantValue), len(gotValue)) {
+			diffs = snapshotDiff(wantValue[idx], gotValue[idx], joinDiffPath(path, strconv.Itoa(idx)), diffs)
+		}
+
+		if len(wantValue) != len(gotValue) && len(diffs) < maxSnapshotDiffs {
+			diffs = append(diffs, fmt.Sprintf("%s: want %d elements, got %d", diffPathLabel(path), len(wantValue), len(gotValue)))
+		}
+
+		return diffs
+	default:
+		if reflect.DeepEqual(want, got) {
+			return diffs
+		}
+
+		return append(diffs, valueDiff(path, want, got))
+	}
+}
+
+func valueDiff(path string, want, got any) string {
+	return fmt.Sprintf("%s: want %s, got %s", diffPathLabel(path), diffValue(want), diffValue(got))
+}
+
+func joinDiffPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func diffPathLabel(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+
+	return path
+}
+
+// diffValue renders a JSON value for a difference; nil is a missing key.
+func diffValue(value any) string {
+	if value == nil {
+		return "<missing>"
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return truncate(string(data), maxDiffValueLen)
+}
+
+func printFixtureResult(result fixtureResult, writer io.Writer) {
+	switch result.Status {
+	case fixturePassed:
+		color.New(color.FgGreen).Fprintf(writer, "%s %s\n", result.Status, result.Fixture)
+	case fixtureUpdated:
+		color.New(color.FgYellow).Fprintf(writer, "%s %s\n", result.Status, result.Fixture)
+	default:
+		color.New(color.FgRed).Fprintf(writer, "%s %s\n", result.Status, result.Fixture)
+
+		for _, diff := range result.Diffs {
+			fmt.Fprintf(writer, "  - %s\n", diff)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testJSONMapping maps JSON documents, which keeps fixtures short.
+const testJSONMapping = `[language "json", extensions: ".jsonfix"]
+
+document <- (document) => uast(
+    type: "File"
+)
+
+object <- (object) => uast(
+    type: "Dict"
+)
+
+pair <- (pair) => uast(
+    type: "KeyValue",
+    children: "string", "number"
+)
+
+string <- (string) => uast(
+    token: "self",
+    type: "Literal"
+)
+
+number <- (number) => uast(
+    token: "self",
+    type: "Literal"
+)
+`
+
+func writeMappingFixtures(t *testing.T) (mappingPath, fixturesDir string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	mappingPath = filepath.Join(dir, "json.uastmap")
+	fixturesDir = filepath.Join(dir, "fixtures")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(fixturesDir, "nested"), 0o750))
+	require.NoError(t, os.WriteFile(mappingPath, []byte(testJSONMapping), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(fixturesDir, "a.jsonfix"), []byte(`{"a": 1}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(fixturesDir, "nested", "b.jsonfix"), []byte(`{"b": 2}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(fixturesDir, "README.md"), []byte("not a fixture"), 0o600))
+
+	return mappingPath, fixturesDir
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	t.Parallel()
+
+	var want, got any
+
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "File", "children": [
+		{"type": "Identifier", "roles": ["Name"]},
+		{"type": "Literal"}
+	]}`), &want))
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "File", "children": [
+		{"type": "Identifier", "roles": ["Reference"], "token": "x"}
+	]}`), &got))
+
+	assert.Equal(t, []string{
+		`children.0.roles.0: want "Name", got "Reference"`,
+		`children.0.token: want <missing>, got "x"`,
+		"children: want 2 elements, got 1",
+	}, snapshotDiff(want, got, "", nil))
+
+	assert.Empty(t, snapshotDiff(want, want, "", nil))
+	assert.Equal(t, []string{`(root): want {"type":"File"}, got "File"`},
+		snapshotDiff(map[string]any{"type": "File"}, "File", "", nil))
+}
+
+func TestCollectFixtures(t *testing.T) {
+	t.Parallel()
+
+	_, dir := writeMappingFixtures(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.jsonfix"+snapshotSuffix), []byte("{}"), 0o600))
+
+	fixtures, err := collectFixtures(dir, []string{".JSONFIX"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.jsonfix"), filepath.Join(dir, "nested", "b.jsonfix")}, fixtures)
+}
+
+func TestRunMappingTest(t *testing.T) {
+	t.Parallel()
+
+	mappingPath, dir := writeMappingFixtures(t)
+	ctx := context.Background()
+
+	var out bytes.Buffer
+
+	// Without snapshots every fixture fails.
+	err := runMappingTest(ctx, mappingPath, dir, false, &out)
+	require.ErrorIs(t, err, ErrMappingTestFailed)
+	assert.Contains(t, out.String(), "missing snapshot")
+
+	out.Reset()
+	require.NoError(t, runMappingTest(ctx, mappingPath, dir, true, &out))
+	assert.Contains(t, out.String(), "2 fixtures: 0 passed, 0 failed, 2 updated")
+	assert.FileExists(t, filepath.Join(dir, "a.jsonfix"+snapshotSuffix))
+
+	out.Reset()
+	require.NoError(t, runMappingTest(ctx, mappingPath, dir, false, &out))
+	assert.Contains(t, out.String(), "2 fixtures: 2 passed, 0 failed, 0 updated")
+
+	// A mapping change shows up as a snapshot difference.
+	changed := bytes.Replace([]byte(testJSONMapping), []byte(`type: "Dict"`), []byte(`type: "Block"`), 1)
+	require.NoError(t, os.WriteFile(mappingPath, changed, 0o600))
+
+	out.Reset()
+	err = runMappingTest(ctx, mappingPath, dir, false, &out)
+	require.ErrorIs(t, err, ErrMappingTestFailed)
+	assert.Contains(t, out.String(), `want "Dict", got "Block"`)
+}
+
+func TestRunMappingTest_Errors(t *testing.T) {
+	t.Parallel()
+
+	mappingPath, _ := writeMappingFixtures(t)
+
+	err := runMappingTest(context.Background(), mappingPath, t.TempDir(), false, io.Discard)
+	require.ErrorIs(t, err, ErrNoFixtures)
+
+	broken := filepath.Join(t.TempDir(), "broken.uastmap")
+	require.NoError(t, os.WriteFile(broken, []byte("not a mapping <-"), 0o600))
+
+	err = runMappingTest(context.Background(), broken, t.TempDir(), false, io.Discard)
+	require.Error(t, err)
+}
+
+func TestMappingStore_HotReload(t *testing.T) {
+	t.Parallel()
+
+	mappingPath, _ := writeMappingFixtures(t)
+	dir := filepath.Dir(mappingPath)
+
+	store, err := newMappingStore(dir, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	require.Contains(t, store.Maps(), "json")
+	assert.Equal(t, []string{".jsonfix"}, store.Maps()["json"].Extensions)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = store.watch(ctx) }() //nolint:errcheck // stopped by cancel.
+
+	// Give the watcher time to register the directory.
+	time.Sleep(100 * time.Millisecond)
+
+	changed := bytes.Replace([]byte(testJSONMapping), []byte(".jsonfix"), []byte(".jsonfix2"), 1)
+	require.NoError(t, os.WriteFile(mappingPath, changed, 0o600))
+
+	assert.Eventually(t, func() bool {
+		return store.Maps()["json"].UAST == string(changed)
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// A broken edit keeps the previous mapping.
+	require.NoError(t, os.WriteFile(mappingPath, []byte("not a mapping <-"), 0o600))
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, string(changed), store.Maps()["json"].UAST)
+
+	require.NoError(t, os.Remove(mappingPath))
+	assert.Eventually(t, func() bool {
+		_, found := store.Maps()["json"]
+
+		return !found
+	}, 5*time.Second, 20*time.Millisecond)
+}
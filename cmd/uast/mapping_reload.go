@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+)
+
+// mappingFileExt is the extension of mapping DSL files.
+const mappingFileExt = ".uastmap"
+
+// mappingStore holds the mappings of a directory and reloads them when
+// their files change, so the server parses with the mapping being edited.
+type mappingStore struct {
+	dir    string
+	logger *slog.Logger
+
+	mu   sync.RWMutex
+	maps map[string]uast.Map
+}
+
+// newMappingStore loads every mapping file of dir. A mapping that fails to
+// load is logged and left out.
+func newMappingStore(dir string, logger *slog.Logger) (*mappingStore, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read mappings dir: %w", err)
+	}
+
+	store := &mappingStore{dir: dir, logger: logger, maps: make(map[string]uast.Map)}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == mappingFileExt {
+			store.reload(filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	return store, nil
+}
+
+// Maps returns a copy of the loaded mappings. It is safe on a nil store.
+func (store *mappingStore) Maps() map[string]uast.Map {
+	if store == nil {
+		return nil
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return maps.Clone(store.maps)
+}
+
+// reload loads the mapping file at path. A removed file drops its mapping;
+// a mapping that no longer loads keeps its previous version.
+func (store *mappingStore) reload(path string) {
+	name := strings.TrimSuffix(filepath.Base(path), mappingFileExt)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		store.mu.Lock()
+		delete(store.maps, name)
+		store.mu.Unlock()
+
+		store.logger.Info("mapping removed", "mapping", name)
+
+		return
+	}
+
+	if err != nil {
+		store.logger.Error("mapping read failed", "mapping", name, "error", err)
+
+		return
+	}
+
+	parser, err := loadMapping(path, data)
+	if err != nil {
+		store.logger.Error("mapping reload failed, keeping the previous version", "mapping", name, "error", err)
+
+		return
+	}
+
+	store.mu.Lock()
+	store.maps[name] = uast.Map{UAST: string(data), Extensions: parser.Extensions()}
+	store.mu.Unlock()
+
+	store.logger.Info("mapping loaded", "mapping", name, "extensions", parser.Extensions())
+}
+
+// watch reloads mappings as their files change until ctx is done.
+func (store *mappingStore) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	err = watcher.Add(store.dir)
+	if err != nil {
+		return fmt.Errorf("watch %s: %w", store.dir, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if filepath.Ext(event.Name) == mappingFileExt && event.Op != fsnotify.Chmod {
+				store.reload(event.Name)
+			}
+		case watchErr, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			store.logger.Warn("mapping watcher error", "error", watchErr)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -30,10 +31,13 @@ const (
 )
 
 // ParseRequest holds the request body for the parse API endpoint.
+// Filename, when set, selects the parser by extension and takes precedence
+// over Language.
 type ParseRequest struct {
 	UASTMaps map[string]uast.Map `json:"uastmaps,omitempty"`
 	Code     string              `json:"code"`
 	Language string              `json:"language"`
+	Filename string              `json:"filename,omitempty"`
 }
 
 // QueryRequest holds the request body for the query API endpoint.
@@ -57,27 +61,35 @@ type QueryResponse struct {
 func serverCmd() *cobra.Command {
 	var port string
 
-	var staticDir string
+	var staticDir, mappingsDir string
 
 	cmd := &cobra.Command{
 		Use:   "server",
 		Short: "Start UAST development server",
-		Long:  `Start a web server that provides UAST parsing and querying via HTTP API`,
+		Long: `Start a web server that provides UAST parsing and querying via HTTP API.
+
+With --mappings, the .uastmap files of a directory override the embedded
+mappings and are reloaded whenever they change, so parse requests always
+use the mapping being edited.`,
 		Run: func(_ *cobra.Command, _ []string) {
-			startServer(port, staticDir)
+			startServer(port, staticDir, mappingsDir)
 		},
 	}
 
 	cmd.Flags().StringVarP(&port, "port", "p", "8080", "port to listen on")
 	cmd.Flags().StringVarP(&staticDir, "static", "s", "", "directory to serve static files from")
+	cmd.Flags().StringVarP(&mappingsDir, "mappings", "m", "", "directory of .uastmap files to load and hot-reload")
 
 	return cmd
 }
 
 // newServerMux creates the HTTP mux with all API routes wrapped in tracing middleware.
-func newServerMux(tracer trace.Tracer) http.Handler {
+// Parse requests use the mappings of store, which may be nil.
+func newServerMux(tracer trace.Tracer, store *mappingStore) http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/parse", handleParse)
+	mux.HandleFunc("/api/parse", func(responseWriter http.ResponseWriter, request *http.Request) {
+		serveParse(responseWriter, request, store)
+	})
 	mux.HandleFunc("/api/query", handleQuery)
 	mux.HandleFunc("/api/mappings", handleGetMappingsList)
 	mux.HandleFunc("/api/mappings/", handleGetMapping)
@@ -87,7 +99,7 @@ func newServerMux(tracer trace.Tracer) http.Handler {
 	return observability.HTTPMiddleware(tracer, logger, mux)
 }
 
-func startServer(port, staticDir string) {
+func startServer(port, staticDir, mappingsDir string) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 
 	cfg := observability.DefaultConfig()
@@ -108,7 +120,32 @@ func startServer(port, staticDir string) {
 		}
 	}()
 
-	handler := newServerMux(providers.Tracer)
+	var store *mappingStore
+
+	if mappingsDir != "" {
+		var storeErr error
+
+		store, storeErr = newMappingStore(mappingsDir, logger)
+		if storeErr != nil {
+			logger.Error("mappings load failed", "error", storeErr)
+
+			return
+		}
+
+		watchCtx, cancelWatch := context.WithCancel(context.Background())
+		defer cancelWatch()
+
+		go func() {
+			watchErr := store.watch(watchCtx)
+			if watchErr != nil {
+				logger.Error("mapping hot-reload stopped", "error", watchErr)
+			}
+		}()
+
+		logger.Info("hot-reloading mappings", "dir", mappingsDir)
+	}
+
+	handler := newServerMux(providers.Tracer, store)
 
 	// Serve static files if directory is provided.
 	if staticDir != "" {
@@ -156,6 +193,12 @@ func writeJSON(ctx context.Context, responseWriter http.ResponseWriter, value an
 }
 
 func handleParse(responseWriter http.ResponseWriter, request *http.Request) {
+	serveParse(responseWriter, request, nil)
+}
+
+// serveParse parses the code of a request with the mappings of store, if
+// any, and those of the request, which take precedence.
+func serveParse(responseWriter http.ResponseWriter, request *http.Request, store *mappingStore) {
 	if request.Method != http.MethodPost {
 		http.Error(responseWriter, "Method not allowed", http.StatusMethodNotAllowed)
 
@@ -183,12 +226,22 @@ func handleParse(responseWriter http.ResponseWriter, request *http.Request) {
 	}
 
 	// Add custom UAST maps if provided.
-	if len(req.UASTMaps) > 0 {
-		parser = parser.WithMap(req.UASTMaps)
+	customMaps := store.Maps()
+	if customMaps == nil {
+		customMaps = make(map[string]uast.Map, len(req.UASTMaps))
+	}
+
+	maps.Copy(customMaps, req.UASTMaps)
+
+	if len(customMaps) > 0 {
+		parser = parser.WithMap(customMaps)
 	}
 
 	// Create filename with proper extension.
-	filename := fmt.Sprintf("input.%s", getFileExtension(req.Language))
+	filename := req.Filename
+	if filename == "" {
+		filename = fmt.Sprintf("input.%s", getFileExtension(req.Language))
+	}
 
 	// Parse the code.
 	parsedNode, parseErr := parser.Parse(request.Context(), filename, []byte(req.Code))
@@ -228,7 +228,7 @@ func TestUASTServer_MiddlewareWrapsRoutes(t *testing.T) {
 	t.Parallel()
 
 	tracer := noop.NewTracerProvider().Tracer("test")
-	handler := newServerMux(tracer)
+	handler := newServerMux(tracer, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/mappings", http.NoBody)
 	rec := httptest.NewRecorder()
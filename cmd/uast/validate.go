@@ -22,40 +22,67 @@ const complianceMax = 100
 const exitCodeValidationFailure = 2
 
 func validateCmd() *cobra.Command {
-	var schemaPath string
+	var opts validateOptions
 
 	var colorize, nocolor bool
 
 	cmd := &cobra.Command{
-		Use:   "validate <file.json|->",
-		Short: "Validate a UAST JSON file against the UAST schema",
-		Long: `Validate a UAST JSON file against the canonical UAST schema.
+		Use:   "validate <file.json|dir|->...",
+		Short: "Validate UAST JSON files against the UAST schema",
+		Long: `Validate UAST JSON files against the canonical UAST schema.
+
+A single file gets a detailed report. Directories and several files are
+validated in parallel with one line per failure. With --sources, supported
+source files are parsed through the UAST mappings and their trees validated,
+which checks grammar and mapping changes.
+
+A baseline file records known failures: they are not reported, and the
+command fails only on new ones. --update-baseline rewrites it from the
+current failures.
 
 Examples:
   uast validate mytree.json
   uast validate - < mytree.json
   uast validate --schema custom-schema.json mytree.json
+  uast validate testdata/uast/
+  uast validate --sources -w 8 pkg/
+  uast validate --sources --baseline uast-baseline.json --update-baseline pkg/
+  uast validate --sources --baseline uast-baseline.json pkg/
 `,
-		Args: cobra.ExactArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
-			return runValidate(args[0], schemaPath, false, colorize, nocolor)
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !isBatchValidation(args, opts) {
+				return runValidate(args[0], opts.schemaPath, false, colorize, nocolor)
+			}
+
+			setColor(colorize, nocolor)
+
+			return runValidateBatch(args, opts, cmd.OutOrStdout())
 		},
 	}
 
-	cmd.Flags().StringVar(&schemaPath, "schema", "pkg/uast/spec/uast-schema.json", "path to UAST JSON schema")
+	cmd.Flags().StringVar(&opts.schemaPath, "schema", "pkg/uast/spec/uast-schema.json", "path to UAST JSON schema")
+	cmd.Flags().StringVar(&opts.baselinePath, "baseline", "", "baseline file of known failures to suppress")
+	cmd.Flags().BoolVar(&opts.updateBaseline, "update-baseline", false, "write the current failures to the baseline file")
+	cmd.Flags().BoolVar(&opts.sources, "sources", false, "parse source files and validate their UAST instead of JSON files")
+	cmd.Flags().IntVarP(&opts.workers, "workers", "w", 0, "number of parallel workers (default: number of CPUs)")
 	cmd.Flags().BoolVar(&colorize, "color", false, "force colored output")
 	cmd.Flags().BoolVar(&nocolor, "no-color", false, "disable colored output")
 
 	return cmd
 }
 
-func runValidate(inputPath, schemaPath string, quiet, colorize, nocolor bool) error {
-	// Color setup.
+// setColor applies the --color and --no-color flags.
+func setColor(colorize, nocolor bool) {
 	if nocolor {
 		color.NoColor = true
 	} else if colorize {
 		color.NoColor = false
 	}
+}
+
+func runValidate(inputPath, schemaPath string, quiet, colorize, nocolor bool) error {
+	setColor(colorize, nocolor)
 
 	inputReader, inputLabel := loadInput(inputPath)
 
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+)
+
+// Sentinel errors for batch validation.
+var (
+	ErrValidationFailed   = errors.New("validation failed")
+	ErrNoValidationInputs = errors.New("no files to validate")
+	ErrBaselineRequired   = errors.New("--update-baseline needs --baseline")
+)
+
+// baselineVersion is the format version of baseline files.
+const baselineVersion = 1
+
+// baselineFilePerm is the permission of written baseline files.
+const baselineFilePerm = 0o644
+
+// arrayIndexPattern matches the array indices of a schema error field, such
+// as the 0 and 2 of children.0.roles.2.
+var arrayIndexPattern = regexp.MustCompile(`(^|\.)\d+(\.|$)`)
+
+// validateOptions configures a batch validation run.
+type validateOptions struct {
+	schemaPath     string
+	baselinePath   string
+	updateBaseline bool
+	sources        bool
+	workers        int
+}
+
+// validationFailure is one error found in one file.
+type validationFailure struct {
+	File  string
+	Error string
+}
+
+// fileValidation is the result of validating one file.
+type fileValidation struct {
+	File     string
+	Failures []validationFailure
+}
+
+// validationBaseline lists the known failures a run does not report.
+type validationBaseline struct {
+	Version  int             `json:"version"`
+	Failures []baselineEntry `json:"failures"`
+}
+
+// baselineEntry is a known failure and how often it occurs in the file.
+type baselineEntry struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+	Count int    `json:"count"`
+}
+
+// validationSummary is the outcome of a batch run.
+type validationSummary struct {
+	Files []fileValidation
+	// New holds the failures the baseline does not cover, per file.
+	New map[string][]validationFailure
+	// Suppressed counts the failures the baseline covers.
+	Suppressed int
+	// Stale counts the baseline failures that no longer occur.
+	Stale int
+}
+
+// isBatchValidation reports whether the arguments need batch mode rather
+// than the detailed report of a single UAST JSON file.
+func isBatchValidation(args []string, opts validateOptions) bool {
+	if len(args) != 1 || opts.baselinePath != "" || opts.sources {
+		return true
+	}
+
+	info, err := os.Stat(args[0])
+
+	return err == nil && info.IsDir()
+}
+
+// runValidateBatch validates every file under paths in parallel and reports
+// the failures the baseline does not suppress.
+func runValidateBatch(paths []string, opts validateOptions, writer io.Writer) error {
+	if opts.updateBaseline && opts.baselinePath == "" {
+		return ErrBaselineRequired
+	}
+
+	schema, err := gojsonschema.NewSchema(loadSchema(opts.schemaPath))
+	if err != nil {
+		return fmt.Errorf("compile schema: %w", err)
+	}
+
+	var parser *uast.Parser
+
+	if opts.sources {
+		parser, err = uast.NewParser()
+		if err != nil {
+			return fmt.Errorf("failed to initialize parser: %w", err)
+		}
+	}
+
+	files, err := collectValidationFiles(paths, parser)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return ErrNoValidationInputs
+	}
+
+	results := validateFiles(files, schema, opts)
+
+	if opts.updateBaseline {
+		baseline := newValidationBaseline(results)
+
+		err = writeBaseline(opts.baselinePath, baseline)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(writer, "Wrote %d known failures of %d files to %s\n",
+			len(baseline.Failures), len(files), opts.baselinePath)
+
+		return nil
+	}
+
+	baseline := &validationBaseline{Version: baselineVersion}
+
+	if opts.baselinePath != "" {
+		baseline, err = readBaseline(opts.baselinePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	summary := applyBaseline(results, baseline)
+	printValidationSummary(summary, writer)
+
+	newCount := 0
+	for _, failures := range summary.New {
+		newCount += len(failures)
+	}
+
+	if newCount > 0 {
+		return fmt.Errorf("%w: %d new failures in %d files", ErrValidationFailed, newCount, len(summary.New))
+	}
+
+	return nil
+}
+
+// collectValidationFiles expands directories to the files to validate:
+// UAST JSON files, or the supported source files when parser is set.
+func collectValidationFiles(paths []string, parser *uast.Parser) ([]string, error) {
+	var files []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", path, err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+
+			continue
+		}
+
+		err = filepath.Walk(path, func(walkPath string, walkInfo os.FileInfo, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+
+			if walkInfo.IsDir() {
+				if walkPath != path && isHiddenDir(walkInfo.Name()) {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if isValidationInput(walkPath, parser) {
+				files = append(files, walkPath)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", path, err)
+		}
+	}
+
+	slices.Sort(files)
+
+	return slices.Compact(files), nil
+}
+
+func isValidationInput(path string, parser *uast.Parser) bool {
+	if parser != nil {
+		return parser.IsSupported(path)
+	}
+
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+// validateFiles validates files with a worker pool. Each worker parses with
+// its own Parser; the compiled schema is shared.
+func validateFiles(files []string, schema *gojsonschema.Schema, opts validateOptions) []fileValidation {
+	workers := opts.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	workers = min(workers, len(files))
+
+	results := make([]fileValidation, len(files))
+	indexCh := make(chan int, workers)
+
+	var wg sync.WaitGroup
+
+	for range workers {
+		wg.Go(func() {
+			var parser *uast.Parser
+
+			if opts.sources {
+				var err error
+
+				parser, err = uast.NewParser()
+				if err != nil {
+					for idx := range indexCh {
+						results[idx] = failedValidation(files[idx], "initialize parser: "+err.Error())
+					}
+
+					return
+				}
+			}
+
+			for idx := range indexCh {
+				results[idx] = validateFile(files[idx], schema, parser)
+			}
+		})
+	}
+
+	for idx := range files {
+		indexCh <- idx
+	}
+
+	close(indexCh)
+	wg.Wait()
+
+	return results
+}
+
+// validateFile validates one UAST JSON file, or the UAST of one source file
+// when parser is set.
+func validateFile(file string, schema *gojsonschema.Schema, parser *uast.Parser) fileValidation {
+	label := baselinePath(file)
+
+	data, _, err := safeReadFile(file)
+	if err != nil {
+		return failedValidation(label, "read: "+err.Error())
+	}
+
+	var input gojsonschema.JSONLoader
+
+	if parser != nil {
+		parsed, parseErr := parser.Parse(context.Background(), file, data)
+		if parseErr != nil {
+			return failedValidation(label, "parse: "+parseErr.Error())
+		}
+
+		input = gojsonschema.NewGoLoader(parsed.ToMap())
+	} else {
+		if !json.Valid(data) {
+			return failedValidation(label, "invalid JSON")
+		}
+
+		input = gojsonschema.NewBytesLoader(data)
+	}
+
+	result, err := schema.Validate(input)
+	if err != nil {
+		return failedValidation(label, "schema validation: "+err.Error())
+	}
+
+	validation := fileValidation{File: label}
+
+	for _, verr := range result.Errors() {
+		validation.Failures = append(validation.Failures, validationFailure{
+			File:  label,
+			Error: failureKey(verr.Field(), verr.Description()),
+		})
+	}
+
+	return validation
+}
+
+func failedValidation(file, message string) fileValidation {
+	return fileValidation{File: file, Failures: []validationFailure{{File: file, Error: message}}}
+}
+
+// failureKey identifies a schema error independently of where in the tree
+// it occurs, so that baselines survive edits that move nodes around.
+func failureKey(field, description string) string {
+	for arrayIndexPattern.MatchString(field) {
+		field = arrayIndexPattern.ReplaceAllString(field, "${1}*${2}")
+	}
+
+	return field + ": " + description
+}
+
+// baselinePath returns the slash-separated path a file is recorded under,
+// relative to the working directory when possible.
+func baselinePath(file string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if abs, absErr := filepath.Abs(file); absErr == nil {
+			if rel, relErr := filepath.Rel(wd, abs); relErr == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+	}
+
+	return filepath.ToSlash(file)
+}
+
+// newValidationBaseline records the failures of results as known.
+func newValidationBaseline(results []fileValidation) *validationBaseline {
+	baseline := &validationBaseline{Version: baselineVersion, Failures: []baselineEntry{}}
+
+	for _, result := range results {
+		counts := make(map[string]int)
+
+		var order []string
+
+		for _, failure := range result.Failures {
+			if counts[failure.Error] == 0 {
+				order = append(order, failure.Error)
+			}
+
+			counts[failure.Error]++
+		}
+
+		slices.Sort(order)
+
+		for _, key := range order {
+			baseline.Failures = append(baseline.Failures, baselineEntry{File: result.File, Error: key, Count: counts[key]})
+		}
+	}
+
+	return baseline
+}
+
+// applyBaseline splits the failures of results into new and suppressed ones.
+// A failure is suppressed while its file has no more occurrences of it than
+// the baseline records.
+func applyBaseline(results []fileValidation, baseline *validationBaseline) *validationSummary {
+	known := make(map[validationFailure]int, len(baseline.Failures))
+	for _, entry := range baseline.Failures {
+		known[validationFailure{File: entry.File, Error: entry.Error}] += max(entry.Count, 1)
+	}
+
+	summary := &validationSummary{Files: results, New: make(map[string][]validationFailure)}
+
+	for _, result := range results {
+		for _, failure := range result.Failures {
+			if known[failure] > 0 {
+				known[failure]--
+				summary.Suppressed++
+
+				continue
+			}
+
+			summary.New[result.File] = append(summary.New[result.File], failure)
+		}
+	}
+
+	for _, remaining := range known {
+		summary.Stale += remaining
+	}
+
+	return summary
+}
+
+func readBaseline(path string) (*validationBaseline, error) {
+	data, _, err := safeReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+
+	var baseline validationBaseline
+
+	err = json.Unmarshal(data, &baseline)
+	if err != nil {
+		return nil, fmt.Errorf("parse baseline %s: %w", path, err)
+	}
+
+	return &baseline, nil
+}
+
+func writeBaseline(path string, baseline *validationBaseline) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode baseline: %w", err)
+	}
+
+	err = os.WriteFile(path, append(data, '\n'), baselineFilePerm)
+	if err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+
+	return nil
+}
+
+func printValidationSummary(summary *validationSummary, writer io.Writer) {
+	var failing int
+
+	for _, result := range summary.Files {
+		if len(result.Failures) > 0 {
+			failing++
+		}
+
+		failures := summary.New[result.File]
+		if len(failures) == 0 {
+			continue
+		}
+
+		color.New(color.FgRed).Fprintf(writer, "FAIL %s\n", result.File)
+
+		for _, failure := range failures {
+			color.New(color.FgRed).Fprintf(writer, "  - %s\n", failure.Error)
+		}
+	}
+
+	line := fmt.Sprintf("Validated %d files: %d valid, %d failing", len(summary.Files), len(summary.Files)-failing, failing)
+	if summary.Suppressed > 0 {
+		line += fmt.Sprintf(" (%d known failures suppressed by the baseline)", summary.Suppressed)
+	}
+
+	if len(summary.New) == 0 {
+		color.New(color.FgGreen).Fprintln(writer, line)
+	} else {
+		color.New(color.FgYellow).Fprintln(writer, line)
+	}
+
+	if summary.Stale > 0 {
+		color.New(color.FgCyan).Fprintf(writer,
+			"%d baseline failures no longer occur; run with --update-baseline to prune them\n", summary.Stale)
+	}
+}
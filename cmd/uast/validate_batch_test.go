@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	validUAST   = `{"type": "Function", "roles": ["Function"], "children": [{"type": "Identifier", "token": "main"}]}`
+	invalidUAST = `{"type": "Function", "children": [{"type": "Bogus"}, {"type": "Identifier", "roles": ["Nope"]}]}`
+)
+
+func writeValidationFixtures(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o750))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".hidden"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "valid.json"), []byte(validUAST), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "invalid.json"), []byte(invalidUAST), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "broken.json"), []byte("{"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden", "skipped.json"), []byte("{"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not UAST"), 0o600))
+
+	return dir
+}
+
+func TestFailureKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "children.*.roles.*: bad role", failureKey("children.0.roles.12", "bad role"))
+	assert.Equal(t, "children.*.*: x", failureKey("children.3.4", "x"))
+	assert.Equal(t, "(root): x", failureKey("(root)", "x"))
+}
+
+func TestCollectValidationFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := writeValidationFixtures(t)
+
+	files, err := collectValidationFiles([]string{dir, filepath.Join(dir, "valid.json")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "nested", "broken.json"),
+		filepath.Join(dir, "nested", "invalid.json"),
+		filepath.Join(dir, "valid.json"),
+	}, files)
+}
+
+func TestApplyBaseline(t *testing.T) {
+	t.Parallel()
+
+	results := []fileValidation{
+		{File: "a.json", Failures: []validationFailure{
+			{File: "a.json", Error: "children.*.type: bad"},
+			{File: "a.json", Error: "children.*.type: bad"},
+			{File: "a.json", Error: "roles.*: bad"},
+		}},
+		{File: "b.json"},
+	}
+
+	baseline := newValidationBaseline(results)
+	assert.Equal(t, []baselineEntry{
+		{File: "a.json", Error: "children.*.type: bad", Count: 2},
+		{File: "a.json", Error: "roles.*: bad", Count: 1},
+	}, baseline.Failures)
+
+	summary := applyBaseline(results, baseline)
+	assert.Empty(t, summary.New)
+	assert.Equal(t, 3, summary.Suppressed)
+	assert.Zero(t, summary.Stale)
+
+	// A third occurrence is new; the fixed roles error is stale.
+	results[0].Failures = append(results[0].Failures[:2], validationFailure{File: "a.json", Error: "children.*.type: bad"})
+
+	summary = applyBaseline(results, baseline)
+	assert.Len(t, summary.New["a.json"], 1)
+	assert.Equal(t, 2, summary.Suppressed)
+	assert.Equal(t, 1, summary.Stale)
+}
+
+func TestRunValidateBatch_Baseline(t *testing.T) {
+	t.Parallel()
+
+	dir := writeValidationFixtures(t)
+	opts := validateOptions{workers: 2}
+
+	var out bytes.Buffer
+
+	err := runValidateBatch([]string{dir}, opts, &out)
+	require.ErrorIs(t, err, ErrValidationFailed)
+	assert.Contains(t, out.String(), "Validated 3 files: 1 valid, 2 failing")
+	assert.Contains(t, out.String(), "invalid JSON")
+	assert.Contains(t, out.String(), "children.*.type")
+
+	opts.baselinePath = filepath.Join(t.TempDir(), "baseline.json")
+	opts.updateBaseline = true
+
+	out.Reset()
+	require.NoError(t, runValidateBatch([]string{dir}, opts, &out))
+	assert.Contains(t, out.String(), "Wrote")
+
+	opts.updateBaseline = false
+
+	out.Reset()
+	require.NoError(t, runValidateBatch([]string{dir}, opts, &out))
+	assert.Contains(t, out.String(), "suppressed by the baseline")
+	assert.NotContains(t, out.String(), "FAIL")
+
+	// A new failure in a baselined file is reported.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "valid.json"), []byte(`{"type": "Nope"}`), 0o600))
+
+	out.Reset()
+	err = runValidateBatch([]string{dir}, opts, &out)
+	require.ErrorIs(t, err, ErrValidationFailed)
+	assert.Contains(t, out.String(), "valid.json")
+}
+
+func TestRunValidateBatch_Errors(t *testing.T) {
+	t.Parallel()
+
+	err := runValidateBatch([]string{t.TempDir()}, validateOptions{updateBaseline: true}, &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrBaselineRequired)
+
+	err = runValidateBatch([]string{t.TempDir()}, validateOptions{}, &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrNoValidationInputs)
+}
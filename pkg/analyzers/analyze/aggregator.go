@@ -1,5 +1,7 @@
 package analyze
 
+import "github.com/Sumatoshi-tech/codefang/pkg/codec"
+
 // AggregatorFunc is the factory signature for creating an Aggregator
 // from options. Concrete analyzers provide this via their registration.
 type AggregatorFunc func(opts AggregatorOptions) (Aggregator, error)
@@ -48,6 +50,14 @@ type Aggregator interface {
 	Close() error
 }
 
+// TickStreamer is implemented by aggregators that can merge their spilled
+// state tick by tick. StreamTicks calls fn with every TICK in ascending tick
+// order, as FlushAllTicks would return them after Collect, and consumes the
+// accumulated state.
+type TickStreamer interface {
+	StreamTicks(fn func(TICK) error) error
+}
+
 // AggregatorSpillInfo describes the on-disk spill state of an Aggregator.
 // Used by the checkpoint system to save and restore spill directories.
 type AggregatorSpillInfo struct {
@@ -56,6 +66,25 @@ type AggregatorSpillInfo struct {
 
 	// Count is the number of spill files written.
 	Count int `json:"count,omitempty"`
+
+	// Codec names the codec the spill files were written with. Empty means
+	// uncompressed.
+	Codec string `json:"codec,omitempty"`
+
+	// Written is the total size of the spill files written so far, including
+	// files already collected and removed.
+	Written int64 `json:"written,omitempty"`
+}
+
+// SpillCodec returns the codec the spill files were written with, or nil
+// when they are uncompressed or the codec is unknown.
+func (i AggregatorSpillInfo) SpillCodec() codec.Codec {
+	c, err := codec.Parse(i.Codec)
+	if err != nil || i.Codec == "" {
+		return nil
+	}
+
+	return c
 }
 
 // AggregatorOptions configures an Aggregator instance.
@@ -66,10 +95,13 @@ type AggregatorOptions struct {
 	// in memory before spilling to disk. Zero means no limit.
 	SpillBudget int64
 
-	// SpillDir is the directory for spill files. Empty means the
-	// system default temporary directory.
+	// SpillDir is the parent directory in which spill directories are
+	// created. Empty means the system default temporary directory.
 	SpillDir string
 
+	// SpillCodec compresses spill files. Nil means uncompressed.
+	SpillCodec codec.Codec
+
 	// Sampling is the commit sampling rate. Zero means no sampling
 	// (process every commit).
 	Sampling int
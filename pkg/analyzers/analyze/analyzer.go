@@ -98,6 +98,13 @@ type ResultAggregator interface {
 	GetResult() Report
 }
 
+// RootedAggregator is implemented by aggregators that need the root of the
+// analyzed tree, e.g. to relate "_source_file" paths to each other or to read
+// project files. SetRoot is called once, before the first Aggregate.
+type RootedAggregator interface {
+	SetRoot(root string) error
+}
+
 // Factory manages registration and execution of static analyzers.
 type Factory struct {
 	analyzers   map[string]StaticAnalyzer
@@ -30,6 +30,9 @@ type BaseHistoryAnalyzer[M any] struct {
 	EstimatedTCSize    int64
 	ConfigOptions      []pipeline.ConfigurationOption
 
+	// Caps declares the analyzer's Capabilities. Nil means FullCapabilities.
+	Caps *Capabilities
+
 	// Hooks.
 	ComputeMetricsFn MetricComputer[M]
 	TicksToReportFn  func(ctx context.Context, ticks []TICK) Report
@@ -71,6 +74,15 @@ func (b *BaseHistoryAnalyzer[M]) CPUHeavy() bool {
 	return b.CPUHeavyFlag
 }
 
+// Capabilities returns Caps, or FullCapabilities when Caps is nil.
+func (b *BaseHistoryAnalyzer[M]) Capabilities() Capabilities {
+	if b.Caps == nil {
+		return FullCapabilities()
+	}
+
+	return *b.Caps
+}
+
 // WorkingStateSize returns the estimated bytes of analyzer-internal working state.
 func (b *BaseHistoryAnalyzer[M]) WorkingStateSize() int64 {
 	return b.EstimatedStateSize
@@ -0,0 +1,202 @@
+package analyze
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// SinkPolicy selects what a BufferedSink does when its buffer is full.
+type SinkPolicy string
+
+const (
+	// SinkPolicyBlock makes WriteTC wait for buffer space (lossless, default).
+	SinkPolicyBlock SinkPolicy = "block"
+
+	// SinkPolicyDrop discards the record and counts it as dropped, so a slow
+	// consumer never stalls the pipeline.
+	SinkPolicyDrop SinkPolicy = "drop"
+)
+
+// ErrUnknownSinkPolicy is returned for unrecognized --sink-policy values.
+var ErrUnknownSinkPolicy = errors.New("unknown sink policy")
+
+// ParseSinkPolicy parses a policy name. Empty input maps to SinkPolicyBlock.
+func ParseSinkPolicy(s string) (SinkPolicy, error) {
+	switch policy := SinkPolicy(s); policy {
+	case "":
+		return SinkPolicyBlock, nil
+	case SinkPolicyBlock, SinkPolicyDrop:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want block or drop)", ErrUnknownSinkPolicy, s)
+	}
+}
+
+// BufferedSinkOptions configures a BufferedSink.
+type BufferedSinkOptions struct {
+	// Buffer is the number of records queued between the pipeline and the
+	// wrapped sink. Values below 1 are treated as 1.
+	Buffer int
+
+	// Policy selects the behavior when the buffer is full.
+	Policy SinkPolicy
+
+	// SampleEvery keeps only every Nth record. Values below 2 keep all records.
+	SampleEvery int
+}
+
+// BufferedSinkStats counts what happened to the records passed to a BufferedSink.
+type BufferedSinkStats struct {
+	Written uint64 // records delivered to the wrapped sink.
+	Dropped uint64 // records discarded because the buffer was full.
+	Sampled uint64 // records skipped by sampling.
+	Failed  uint64 // records the wrapped sink returned an error for.
+}
+
+// sinkRecord is one queued WriteTC call, or a Flush marker when flushed is set.
+type sinkRecord struct {
+	tc      TC
+	flag    string
+	flushed chan struct{}
+}
+
+// BufferedSink decouples the pipeline from a slow TCSink. Records are queued
+// in a bounded channel and written by a single background goroutine, so the
+// wrapped sink sees calls in queue order and needs no locking of its own.
+//
+// WriteTC and Flush are safe for concurrent use. Close must be called once,
+// after the last WriteTC, to flush the queue.
+type BufferedSink struct {
+	next        TCSink
+	policy      SinkPolicy
+	sampleEvery uint64
+
+	queue chan sinkRecord
+	done  chan struct{}
+
+	onWritten TCSink // set by OnWritten before the first WriteTC.
+
+	seen    atomic.Uint64
+	written atomic.Uint64
+	dropped atomic.Uint64
+	sampled atomic.Uint64
+	failed  atomic.Uint64
+
+	firstErr error // written by run only; read after done is closed.
+}
+
+// NewBufferedSink wraps next and starts the background writer.
+func NewBufferedSink(next TCSink, opts BufferedSinkOptions) *BufferedSink {
+	buffer := max(opts.Buffer, 1)
+
+	policy := opts.Policy
+	if policy == "" {
+		policy = SinkPolicyBlock
+	}
+
+	s := &BufferedSink{
+		next:        next,
+		policy:      policy,
+		sampleEvery: uint64(max(opts.SampleEvery, 1)),
+		queue:       make(chan sinkRecord, buffer),
+		done:        make(chan struct{}),
+	}
+
+	go s.run()
+
+	return s
+}
+
+// WriteTC queues one record. Never returns an error: write failures of the
+// wrapped sink are reported by Close and counted in Stats.
+func (s *BufferedSink) WriteTC(tc TC, analyzerFlag string) error {
+	if tc.Data == nil {
+		return nil
+	}
+
+	if (s.seen.Add(1)-1)%s.sampleEvery != 0 {
+		s.sampled.Add(1)
+
+		return nil
+	}
+
+	rec := sinkRecord{tc: tc, flag: analyzerFlag}
+
+	if s.policy == SinkPolicyBlock {
+		s.queue <- rec
+
+		return nil
+	}
+
+	select {
+	case s.queue <- rec:
+	default:
+		s.dropped.Add(1)
+	}
+
+	return nil
+}
+
+// OnWritten registers fn to be called by the background writer after the
+// wrapped sink has written a record without error. Dropped, sampled and failed
+// records are not reported. Must be called before the first WriteTC.
+func (s *BufferedSink) OnWritten(fn TCSink) {
+	s.onWritten = fn
+}
+
+// Flush waits until the records queued before the call have been written and
+// their OnWritten callbacks have run. Must not be called after Close.
+func (s *BufferedSink) Flush() {
+	flushed := make(chan struct{})
+	s.queue <- sinkRecord{flushed: flushed}
+	<-flushed
+}
+
+// Close flushes the queued records, stops the background writer and returns
+// the first error of the wrapped sink, if any.
+func (s *BufferedSink) Close() error {
+	close(s.queue)
+	<-s.done
+
+	return s.firstErr
+}
+
+// Stats returns the record counters. Final once Close has returned.
+func (s *BufferedSink) Stats() BufferedSinkStats {
+	return BufferedSinkStats{
+		Written: s.written.Load(),
+		Dropped: s.dropped.Load(),
+		Sampled: s.sampled.Load(),
+		Failed:  s.failed.Load(),
+	}
+}
+
+func (s *BufferedSink) run() {
+	defer close(s.done)
+
+	for rec := range s.queue {
+		if rec.flushed != nil {
+			close(rec.flushed)
+
+			continue
+		}
+
+		err := s.next(rec.tc, rec.flag)
+		if err != nil {
+			s.failed.Add(1)
+
+			if s.firstErr == nil {
+				s.firstErr = err
+			}
+
+			continue
+		}
+
+		s.written.Add(1)
+
+		if s.onWritten != nil {
+			_ = s.onWritten(rec.tc, rec.flag)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package analyze_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestParseSinkPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := analyze.ParseSinkPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, analyze.SinkPolicyBlock, policy)
+
+	policy, err = analyze.ParseSinkPolicy("drop")
+	require.NoError(t, err)
+	assert.Equal(t, analyze.SinkPolicyDrop, policy)
+
+	_, err = analyze.ParseSinkPolicy("lossy")
+	require.ErrorIs(t, err, analyze.ErrUnknownSinkPolicy)
+}
+
+func TestBufferedSink_BlockDeliversAllInOrder(t *testing.T) {
+	t.Parallel()
+
+	var got []int
+
+	sink := analyze.NewBufferedSink(func(tc analyze.TC, _ string) error {
+		got = append(got, tc.Tick)
+
+		return nil
+	}, analyze.BufferedSinkOptions{Buffer: 2})
+
+	for i := range 10 {
+		require.NoError(t, sink.WriteTC(analyze.TC{Tick: i, Data: i}, "a"))
+	}
+
+	require.NoError(t, sink.WriteTC(analyze.TC{Tick: 99}, "a")) // nil Data is skipped.
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, got)
+	assert.Equal(t, analyze.BufferedSinkStats{Written: 10}, sink.Stats())
+}
+
+func TestBufferedSink_DropWhenFull(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var once sync.Once
+
+	sink := analyze.NewBufferedSink(func(analyze.TC, string) error {
+		once.Do(func() { close(started) })
+		<-release
+
+		return nil
+	}, analyze.BufferedSinkOptions{Buffer: 1, Policy: analyze.SinkPolicyDrop})
+
+	// The first record is taken by the writer, the second fills the buffer.
+	require.NoError(t, sink.WriteTC(analyze.TC{Data: 1}, "a"))
+	<-started
+	require.NoError(t, sink.WriteTC(analyze.TC{Data: 2}, "a"))
+
+	for range 3 {
+		require.NoError(t, sink.WriteTC(analyze.TC{Data: 3}, "a"))
+	}
+
+	close(release)
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, analyze.BufferedSinkStats{Written: 2, Dropped: 3}, sink.Stats())
+}
+
+func TestBufferedSink_SampleEvery(t *testing.T) {
+	t.Parallel()
+
+	var got []int
+
+	sink := analyze.NewBufferedSink(func(tc analyze.TC, _ string) error {
+		got = append(got, tc.Tick)
+
+		return nil
+	}, analyze.BufferedSinkOptions{Buffer: 16, SampleEvery: 3})
+
+	for i := range 7 {
+		require.NoError(t, sink.WriteTC(analyze.TC{Tick: i, Data: i}, "a"))
+	}
+
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, []int{0, 3, 6}, got)
+	assert.Equal(t, analyze.BufferedSinkStats{Written: 3, Sampled: 4}, sink.Stats())
+}
+
+func TestBufferedSink_CloseReturnsFirstError(t *testing.T) {
+	t.Parallel()
+
+	errFirst := errors.New("first")
+	errs := []error{errFirst, errors.New("second"), nil}
+
+	sink := analyze.NewBufferedSink(func(analyze.TC, string) error {
+		err := errs[0]
+		errs = errs[1:]
+
+		return err
+	}, analyze.BufferedSinkOptions{})
+
+	for range 3 {
+		require.NoError(t, sink.WriteTC(analyze.TC{Data: 1}, "a"))
+	}
+
+	require.ErrorIs(t, sink.Close(), errFirst)
+	assert.Equal(t, analyze.BufferedSinkStats{Written: 1, Failed: 2}, sink.Stats())
+}
+
+func TestBufferedSink_OnWrittenReportsOnlyWrittenRecords(t *testing.T) {
+	t.Parallel()
+
+	errFail := errors.New("fail")
+
+	sink := analyze.NewBufferedSink(func(tc analyze.TC, _ string) error {
+		if tc.Tick == 1 {
+			return errFail
+		}
+
+		return nil
+	}, analyze.BufferedSinkOptions{})
+
+	var confirmed []int
+
+	sink.OnWritten(func(tc analyze.TC, flag string) error {
+		assert.Equal(t, "a", flag)
+
+		confirmed = append(confirmed, tc.Tick)
+
+		return nil
+	})
+
+	for i := range 3 {
+		require.NoError(t, sink.WriteTC(analyze.TC{Tick: i, Data: i}, "a"))
+	}
+
+	require.ErrorIs(t, sink.Close(), errFail)
+	assert.Equal(t, []int{0, 2}, confirmed)
+}
+
+func TestBufferedSink_FlushWaitsForQueuedRecords(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+
+	sink := analyze.NewBufferedSink(func(analyze.TC, string) error {
+		<-release
+
+		return nil
+	}, analyze.BufferedSinkOptions{Buffer: 4, Policy: analyze.SinkPolicyDrop})
+
+	var confirmed atomic.Int32
+
+	sink.OnWritten(func(analyze.TC, string) error {
+		confirmed.Add(1)
+
+		return nil
+	})
+
+	for i := range 3 {
+		require.NoError(t, sink.WriteTC(analyze.TC{Tick: i, Data: i}, "a"))
+	}
+
+	flushed := make(chan struct{})
+
+	go func() {
+		sink.Flush()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		t.Fatal("Flush returned with records still queued")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-flushed
+
+	assert.Equal(t, int32(3), confirmed.Load(), "callbacks have run when Flush returns")
+
+	require.NoError(t, sink.Close())
+	assert.Equal(t, analyze.BufferedSinkStats{Written: 3}, sink.Stats(), "the flush marker is not a record")
+}
@@ -0,0 +1,103 @@
+package analyze
+
+import (
+	"fmt"
+	"io"
+)
+
+// ReportKeyBlobCache is the Report key that carries the blob cache efficiency
+// of the run as a *BlobCacheStats, to help size --blob-cache-size.
+const ReportKeyBlobCache = "blob_cache"
+
+// BlobCacheStats summarizes how well the blob cache served a run.
+type BlobCacheStats struct {
+	// MaxSize is the configured cache size and PeakSize the largest size
+	// reached, in bytes. A peak well below the maximum means the cache can
+	// shrink without losing hits.
+	MaxSize  int64 `json:"max_size"`
+	PeakSize int64 `json:"peak_size"`
+
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+
+	// BytesServed is the total size of the blobs returned from the cache.
+	BytesServed int64 `json:"bytes_served"`
+
+	// Admitted, Rejected, Evicted and Promoted count blobs entering the cache,
+	// kept out of it, evicted from it, and promoted to its protected segment.
+	// Many evictions with few hits mean the cache is too small.
+	Admitted int64 `json:"admitted"`
+	Rejected int64 `json:"rejected"`
+	Evicted  int64 `json:"evicted"`
+	Promoted int64 `json:"promoted"`
+
+	// Extensions breaks the counters down by file extension, most bytes
+	// served first.
+	Extensions []BlobCacheExtension `json:"extensions,omitempty"`
+}
+
+// BlobCacheExtension holds the blob cache counters of one file extension.
+type BlobCacheExtension struct {
+	// Extension is the lower-cased extension with its dot, or "" for files
+	// without one.
+	Extension string `json:"extension"`
+
+	Hits        int64 `json:"hits"`
+	BytesServed int64 `json:"bytes_served"`
+	Admitted    int64 `json:"admitted"`
+	Rejected    int64 `json:"rejected"`
+	Evicted     int64 `json:"evicted"`
+}
+
+// HitRate returns the share of blob lookups served from the cache (0.0 to 1.0).
+func (s *BlobCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+// BlobCacheFromReports returns the first BlobCacheStats found in the reports,
+// or nil when the run had no blob cache.
+func BlobCacheFromReports(results map[HistoryAnalyzer]Report) *BlobCacheStats {
+	for _, report := range results {
+		if bs, ok := report[ReportKeyBlobCache].(*BlobCacheStats); ok && bs != nil {
+			return bs
+		}
+	}
+
+	return nil
+}
+
+// PrintBlobCache writes the blob cache section in the same style as PrintQuality.
+// Writes nothing when bs is nil.
+func PrintBlobCache(writer io.Writer, bs *BlobCacheStats) {
+	if bs == nil {
+		return
+	}
+
+	fmt.Fprintln(writer, ReportKeyBlobCache+":")
+	fmt.Fprintf(writer, "  max_size: %d\n", bs.MaxSize)
+	fmt.Fprintf(writer, "  peak_size: %d\n", bs.PeakSize)
+	fmt.Fprintf(writer, "  hits: %d\n", bs.Hits)
+	fmt.Fprintf(writer, "  misses: %d\n", bs.Misses)
+	fmt.Fprintf(writer, "  hit_rate: %.3f\n", bs.HitRate())
+	fmt.Fprintf(writer, "  bytes_served: %d\n", bs.BytesServed)
+	fmt.Fprintf(writer, "  admitted: %d\n", bs.Admitted)
+	fmt.Fprintf(writer, "  rejected: %d\n", bs.Rejected)
+	fmt.Fprintf(writer, "  evicted: %d\n", bs.Evicted)
+	fmt.Fprintf(writer, "  promoted: %d\n", bs.Promoted)
+
+	if len(bs.Extensions) == 0 {
+		return
+	}
+
+	fmt.Fprintln(writer, "  extensions:")
+
+	for _, e := range bs.Extensions {
+		fmt.Fprintf(writer, "    - {extension: %q, hits: %d, bytes_served: %d, admitted: %d, rejected: %d, evicted: %d}\n",
+			e.Extension, e.Hits, e.BytesServed, e.Admitted, e.Rejected, e.Evicted)
+	}
+}
@@ -0,0 +1,48 @@
+package analyze
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintBlobCache_Empty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	PrintBlobCache(&buf, nil)
+
+	assert.Empty(t, buf.String())
+}
+
+func TestPrintBlobCache_WritesCounters(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	PrintBlobCache(&buf, &BlobCacheStats{
+		MaxSize:     1000,
+		PeakSize:    600,
+		Hits:        3,
+		Misses:      1,
+		BytesServed: 300,
+		Extensions:  []BlobCacheExtension{{Extension: ".go", Hits: 3, BytesServed: 300}},
+	})
+
+	out := buf.String()
+	assert.Contains(t, out, "blob_cache:\n")
+	assert.Contains(t, out, "peak_size: 600\n")
+	assert.Contains(t, out, "hit_rate: 0.750\n")
+	assert.Contains(t, out, `extension: ".go", hits: 3, bytes_served: 300`)
+}
+
+func TestBlobCacheFromReports(t *testing.T) {
+	t.Parallel()
+
+	bs := &BlobCacheStats{Hits: 1}
+
+	assert.Nil(t, BlobCacheFromReports(map[HistoryAnalyzer]Report{nil: {}}))
+	assert.Same(t, bs, BlobCacheFromReports(map[HistoryAnalyzer]Report{nil: {ReportKeyBlobCache: bs}}))
+}
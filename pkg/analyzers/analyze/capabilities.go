@@ -0,0 +1,85 @@
+package analyze
+
+// MemoryClass ranks how much state a history analyzer keeps as history grows.
+type MemoryClass int
+
+// Memory classes, from least to most state.
+const (
+	// MemoryLow is bounded or per-tick state (counters, small maps).
+	MemoryLow MemoryClass = iota
+	// MemoryMedium grows with the number of files or authors.
+	MemoryMedium
+	// MemoryHigh grows with files times history, e.g. line ownership or
+	// co-change matrices.
+	MemoryHigh
+)
+
+// String returns the lowercase name of the class.
+func (m MemoryClass) String() string {
+	switch m {
+	case MemoryLow:
+		return "low"
+	case MemoryMedium:
+		return "medium"
+	case MemoryHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// Capabilities declares which plumbing data a history analyzer reads, so the
+// pipeline can skip producing data nobody consumes.
+type Capabilities struct {
+	// NeedsBlobs is set when the analyzer reads blob contents.
+	NeedsBlobs bool
+	// NeedsDiffs is set when the analyzer reads line diffs of modified files,
+	// directly or through line stats.
+	NeedsDiffs bool
+	// NeedsUAST is set when the analyzer reads UAST changes.
+	NeedsUAST bool
+	// Memory is the analyzer's memory class.
+	Memory MemoryClass
+}
+
+// CapabilityProvider is optionally implemented by history analyzers to
+// declare their Capabilities. Analyzers that do not implement it are assumed
+// to need everything.
+type CapabilityProvider interface {
+	Capabilities() Capabilities
+}
+
+// FullCapabilities is assumed for analyzers that do not declare capabilities.
+func FullCapabilities() Capabilities {
+	return Capabilities{NeedsBlobs: true, NeedsDiffs: true, NeedsUAST: true, Memory: MemoryHigh}
+}
+
+// CapabilitiesOf returns the declared capabilities of a, or FullCapabilities.
+// Diffs and UAST are computed from blobs, so either implies NeedsBlobs.
+func CapabilitiesOf(a HistoryAnalyzer) Capabilities {
+	provider, ok := a.(CapabilityProvider)
+	if !ok {
+		return FullCapabilities()
+	}
+
+	caps := provider.Capabilities()
+	caps.NeedsBlobs = caps.NeedsBlobs || caps.NeedsDiffs || caps.NeedsUAST
+
+	return caps
+}
+
+// CombinedCapabilities returns what a pipeline running all analyzers needs:
+// the union of their data needs and the highest memory class.
+func CombinedCapabilities(analyzers []HistoryAnalyzer) Capabilities {
+	var combined Capabilities
+
+	for _, a := range analyzers {
+		caps := CapabilitiesOf(a)
+		combined.NeedsBlobs = combined.NeedsBlobs || caps.NeedsBlobs
+		combined.NeedsDiffs = combined.NeedsDiffs || caps.NeedsDiffs
+		combined.NeedsUAST = combined.NeedsUAST || caps.NeedsUAST
+		combined.Memory = max(combined.Memory, caps.Memory)
+	}
+
+	return combined
+}
@@ -0,0 +1,53 @@
+package analyze_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+type undeclaredLeaf struct {
+	analyze.HistoryAnalyzer
+}
+
+type declaredLeaf struct {
+	analyze.HistoryAnalyzer
+
+	caps analyze.Capabilities
+}
+
+func (d declaredLeaf) Capabilities() analyze.Capabilities { return d.caps }
+
+func TestCapabilitiesOf_UndeclaredNeedsEverything(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, analyze.FullCapabilities(), analyze.CapabilitiesOf(undeclaredLeaf{}))
+	assert.Equal(t, analyze.FullCapabilities(), (&analyze.BaseHistoryAnalyzer[any]{}).Capabilities())
+}
+
+func TestCapabilitiesOf_DiffsAndUASTImplyBlobs(t *testing.T) {
+	t.Parallel()
+
+	diffs := declaredLeaf{caps: analyze.Capabilities{NeedsDiffs: true}}
+	uast := declaredLeaf{caps: analyze.Capabilities{NeedsUAST: true}}
+
+	assert.True(t, analyze.CapabilitiesOf(diffs).NeedsBlobs)
+	assert.True(t, analyze.CapabilitiesOf(uast).NeedsBlobs)
+	assert.False(t, analyze.CapabilitiesOf(uast).NeedsDiffs)
+}
+
+func TestCombinedCapabilities(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, analyze.Capabilities{}, analyze.CombinedCapabilities(nil))
+
+	combined := analyze.CombinedCapabilities([]analyze.HistoryAnalyzer{
+		declaredLeaf{caps: analyze.Capabilities{Memory: analyze.MemoryHigh}},
+		declaredLeaf{caps: analyze.Capabilities{NeedsUAST: true, Memory: analyze.MemoryLow}},
+	})
+
+	assert.Equal(t, analyze.Capabilities{NeedsBlobs: true, NeedsUAST: true, Memory: analyze.MemoryHigh}, combined)
+	assert.Equal(t, "high", combined.Memory.String())
+}
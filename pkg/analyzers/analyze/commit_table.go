@@ -0,0 +1,148 @@
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+// ReportKeyCommitTable is the Report key that carries the run's commit table
+// as a []CommitRow. Present only when the run was asked for it.
+const ReportKeyCommitTable = "commit_table"
+
+// commitTableSection names the commits table in text, yaml, json and binary output.
+const commitTableSection = "commits"
+
+// CommitRow holds the facts about one analyzed commit, so output consumers can
+// join analyzer data with commits without a second git log pass.
+type CommitRow struct {
+	Hash            string   `json:"hash"                       yaml:"hash"`
+	Author          string   `json:"author"                     yaml:"author"`
+	Tick            int      `json:"tick"                       yaml:"tick"`
+	Timestamp       string   `json:"timestamp"                  yaml:"timestamp"`
+	FilesChanged    int      `json:"files_changed"              yaml:"files_changed"`
+	Insertions      int      `json:"insertions"                 yaml:"insertions"`
+	Deletions       int      `json:"deletions"                  yaml:"deletions"`
+	Languages       []string `json:"languages,omitempty"        yaml:"languages,omitempty"`
+	MessageLanguage string   `json:"message_language,omitempty" yaml:"message_language,omitempty"`
+
+	// CoAuthors names the identities of the Co-authored-by trailers.
+	CoAuthors []string `json:"co_authors,omitempty" yaml:"co_authors,omitempty"`
+	// Trailers holds every trailer of the commit message, in order.
+	Trailers []CommitTrailer `json:"trailers,omitempty" yaml:"trailers,omitempty"`
+	// Note is the commit's git note under the default notes reference.
+	Note string `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+// CommitTrailer is one "Key: value" trailer of a commit message.
+type CommitTrailer struct {
+	Key   string `json:"key"   yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// CommitTable is the trailing document that carries the commit table in json
+// and binary history output.
+type CommitTable struct {
+	Commits []CommitRow `json:"commits" yaml:"commits"`
+}
+
+// CommitTableFromReports returns the first commit table found in the reports,
+// or nil when the run did not record one.
+func CommitTableFromReports(results map[HistoryAnalyzer]Report) []CommitRow {
+	for _, report := range results {
+		if rows, ok := report[ReportKeyCommitTable].([]CommitRow); ok && len(rows) > 0 {
+			return rows
+		}
+	}
+
+	return nil
+}
+
+// CommitTimeline returns the tick and time of the commits of rows, which tie
+// the tick axes of plot pages to the dates of events. Rows without a
+// timestamp are skipped.
+func CommitTimeline(rows []CommitRow) []plotpage.CommitTime {
+	timeline := make([]plotpage.CommitTime, 0, len(rows))
+
+	for _, row := range rows {
+		at, err := time.Parse(time.RFC3339, row.Timestamp)
+		if err != nil {
+			continue
+		}
+
+		timeline = append(timeline, plotpage.CommitTime{Tick: row.Tick, Time: at})
+	}
+
+	return timeline
+}
+
+// PrintCommitTable writes the commit table in the same style as PrintQuality.
+// Writes nothing when rows is empty.
+func PrintCommitTable(writer io.Writer, rows []CommitRow) {
+	if len(rows) == 0 {
+		return
+	}
+
+	fmt.Fprintln(writer, commitTableSection+":")
+
+	for _, r := range rows {
+		fmt.Fprintf(writer,
+			"  - {hash: %s, author: %q, tick: %d, timestamp: %s, files_changed: %d, insertions: %d, deletions: %d, languages: [%s], message_language: %s, co_authors: [%s], trailers: %d}\n",
+			r.Hash, r.Author, r.Tick, r.Timestamp, r.FilesChanged, r.Insertions, r.Deletions, strings.Join(r.Languages, ", "),
+			r.MessageLanguage, strings.Join(r.CoAuthors, ", "), len(r.Trailers))
+	}
+}
+
+// writeCommitTable writes the commit table as a trailing json document or binary
+// envelope. Writes nothing when rows is empty or format has no trailing document.
+func writeCommitTable(writer io.Writer, rows []CommitRow, format string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	table := CommitTable{Commits: rows}
+
+	switch format {
+	case FormatJSON:
+		err := json.NewEncoder(writer).Encode(table)
+		if err != nil {
+			return fmt.Errorf("json encode commit table: %w", err)
+		}
+	case FormatBinary:
+		err := encodeBinaryEnvelope(table, writer)
+		if err != nil {
+			return fmt.Errorf("binary encode commit table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// parseCommitTable decodes a trailing commit table payload. Returns false when
+// the payload is not a commit table.
+func parseCommitTable(payload []byte) ([]CommitRow, bool) {
+	var doc map[string]json.RawMessage
+
+	err := json.Unmarshal(payload, &doc)
+	if err != nil || len(doc) != 1 {
+		return nil, false
+	}
+
+	raw, ok := doc[commitTableSection]
+	if !ok {
+		return nil, false
+	}
+
+	var rows []CommitRow
+
+	err = json.Unmarshal(raw, &rows)
+	if err != nil {
+		return nil, false
+	}
+
+	return rows, true
+}
@@ -0,0 +1,108 @@
+package analyze
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCommitRows() []CommitRow {
+	return []CommitRow{
+		{
+			Hash: testHashA, Author: "alice", Tick: 0, Timestamp: "2024-01-01T00:00:00Z",
+			FilesChanged: 2, Insertions: 10, Deletions: 3, Languages: []string{"Go", "Markdown"},
+			MessageLanguage: "en", CoAuthors: []string{"carol"},
+			Trailers: []CommitTrailer{{Key: "Co-authored-by", Value: "Carol <carol@example.com>"}},
+		},
+		{Hash: testHashB, Author: "bob", Tick: 1, Timestamp: "2024-01-02T00:00:00Z", FilesChanged: 1, Deletions: 4},
+	}
+}
+
+func TestCommitTableFromReports(t *testing.T) {
+	t.Parallel()
+
+	rows := testCommitRows()
+
+	assert.Nil(t, CommitTableFromReports(map[HistoryAnalyzer]Report{nil: {}}))
+	assert.Equal(t, rows, CommitTableFromReports(map[HistoryAnalyzer]Report{nil: {ReportKeyCommitTable: rows}}))
+}
+
+func TestCommitTimeline(t *testing.T) {
+	t.Parallel()
+
+	rows := append(testCommitRows(), CommitRow{Hash: testHashA, Tick: 2})
+
+	timeline := CommitTimeline(rows)
+	require.Len(t, timeline, 2, "rows without a timestamp are skipped")
+	assert.Equal(t, 1, timeline[1].Tick)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), timeline[1].Time.UTC())
+}
+
+func TestPrintCommitTable(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	PrintCommitTable(&buf, nil)
+	assert.Empty(t, buf.String())
+
+	PrintCommitTable(&buf, testCommitRows())
+
+	out := buf.String()
+	assert.Contains(t, out, "commits:\n")
+	assert.Contains(t, out, "hash: "+testHashA)
+	assert.Contains(t, out, "insertions: 10")
+	assert.Contains(t, out, "languages: [Go, Markdown], message_language: en, co_authors: [carol], trailers: 1")
+}
+
+func TestWriteCommitTable_JSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, writeCommitTable(&buf, testCommitRows(), FormatJSON))
+
+	var table CommitTable
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &table))
+	assert.Equal(t, testCommitRows(), table.Commits)
+}
+
+func TestWriteCommitTable_PlotWritesNothing(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, writeCommitTable(&buf, testCommitRows(), FormatPlot))
+	assert.Empty(t, buf.String())
+}
+
+func TestDecodeBinaryInputModel_TrailingCommitTable(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, writeCommitTable(&buf, testCommitRows(), FormatBinary))
+
+	registry, err := NewRegistry(nil, nil)
+	require.NoError(t, err)
+
+	model, err := DecodeBinaryInputModel(buf.Bytes(), nil, registry)
+	require.NoError(t, err)
+	assert.Empty(t, model.Analyzers)
+	assert.Equal(t, testCommitRows(), model.Commits)
+}
+
+func TestParseCommitTable_RejectsReports(t *testing.T) {
+	t.Parallel()
+
+	_, ok := parseCommitTable([]byte(`{"commits": [], "aggregate": {}}`))
+	assert.False(t, ok)
+
+	_, ok = parseCommitTable([]byte(`{"commits": 3}`))
+	assert.False(t, ok)
+}
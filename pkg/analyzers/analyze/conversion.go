@@ -34,8 +34,11 @@ type AnalyzerResult struct {
 
 // UnifiedModel is the canonical intermediate model for run output conversion.
 type UnifiedModel struct {
-	Version   string           `json:"version"   yaml:"version"`
-	Analyzers []AnalyzerResult `json:"analyzers" yaml:"analyzers"`
+	Version   string           `json:"version"           yaml:"version"`
+	Analyzers []AnalyzerResult `json:"analyzers"         yaml:"analyzers"`
+	Commits   []CommitRow      `json:"commits,omitempty" yaml:"commits,omitempty"`
+	// Seed is the seed of the history run, recorded in converted time series.
+	Seed uint64 `json:"seed,omitempty" yaml:"seed,omitempty"`
 }
 
 // NewUnifiedModel builds a canonical model from analyzer results.
@@ -129,7 +132,7 @@ func ResolveFormats(format string, hasStatic, hasHistory bool) (staticFmt, histo
 	}
 
 	if hasHistory {
-		normalizedFormat, validationErr := ValidateUniversalFormat(format)
+		normalizedFormat, validationErr := ValidateFormat(format, HistoryOutputFormats())
 		if validationErr != nil {
 			return "", "", fmt.Errorf("%w: %w", ErrInvalidHistoryFormat, validationErr)
 		}
@@ -149,6 +152,7 @@ func staticOutputFormats() []string {
 		FormatYAML,
 		FormatPlot,
 		FormatBinary,
+		FormatGraph,
 	}
 }
 
@@ -263,6 +267,17 @@ func DecodeBinaryInputModel(
 		}
 	}
 
+	// A history run with --with-commit-table appends the table as a last envelope.
+	var commits []CommitRow
+
+	if len(payloads) == len(orderedIDs)+1 {
+		rows, ok := parseCommitTable(payloads[len(payloads)-1])
+		if ok {
+			commits = rows
+			payloads = payloads[:len(payloads)-1]
+		}
+	}
+
 	if len(payloads) != len(orderedIDs) {
 		return UnifiedModel{}, fmt.Errorf(
 			"%w: payloads=%d analyzers=%d",
@@ -294,7 +309,10 @@ func DecodeBinaryInputModel(
 		})
 	}
 
-	return NewUnifiedModel(results), nil
+	model := NewUnifiedModel(results)
+	model.Commits = commits
+
+	return model, nil
 }
 
 // PlotRenderer is a function that renders a UnifiedModel as a plot to the given writer.
@@ -375,6 +393,8 @@ func WriteConvertedOutput(model UnifiedModel, outputFormat string, writer io.Wri
 			return fmt.Errorf("%w: plot renderer not registered", ErrUnsupportedFormat)
 		}
 
+		plotpage.SetTimeline(CommitTimeline(model.Commits))
+
 		return plotRendererFn(model, writer)
 	default:
 		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, outputFormat)
@@ -469,7 +489,7 @@ func writeConvertedTimeSeries(model UnifiedModel, writer io.Writer) error {
 	}
 
 	commitMeta := buildOrderedCommitMetaFromReports(reports)
-	ts := BuildMergedTimeSeriesDirect(nil, commitMeta, 0)
+	ts := BuildMergedTimeSeriesDirect(nil, commitMeta, 0, model.Seed)
 
 	return WriteMergedTimeSeries(ts, writer)
 }
@@ -0,0 +1,156 @@
+package analyze
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrDerivedMetricCycle is returned when derived metrics declare a circular ordering.
+var ErrDerivedMetricCycle = errors.New("derived metrics: dependency cycle")
+
+// DerivedMetricFunc computes a derived metric from finalized history reports.
+// Reports are keyed by analyzer flag ("devs", "burndown", ...) and may be
+// modified in place, typically by adding new keys to one of the reports.
+type DerivedMetricFunc func(reports map[string]Report) error
+
+// DerivedMetric is a post-processing plugin that runs after every history
+// analyzer has produced its report. It reads the results of one or more
+// analyzers and writes new sections into them.
+type DerivedMetric struct {
+	// Name identifies the metric in After lists and error messages.
+	Name string
+	// Requires lists analyzer flags whose reports must be present; the metric
+	// is skipped when any of them was not part of the run.
+	Requires []string
+	// After lists derived metrics that must run first when they are registered.
+	After []string
+	// Compute produces the metric.
+	Compute DerivedMetricFunc
+}
+
+// derivedMetrics holds registered derived metrics in registration order.
+var (
+	derivedMetricsMu sync.RWMutex
+	derivedMetrics   []DerivedMetric
+)
+
+// RegisterDerivedMetric registers a derived metric. Registering a name again
+// replaces the earlier registration, so repeated setup is harmless.
+func RegisterDerivedMetric(dm DerivedMetric) {
+	derivedMetricsMu.Lock()
+	defer derivedMetricsMu.Unlock()
+
+	for i := range derivedMetrics {
+		if derivedMetrics[i].Name == dm.Name {
+			derivedMetrics[i] = dm
+
+			return
+		}
+	}
+
+	derivedMetrics = append(derivedMetrics, dm)
+}
+
+// RegisteredDerivedMetrics returns the registered derived metrics in execution order.
+func RegisteredDerivedMetrics() ([]DerivedMetric, error) {
+	derivedMetricsMu.RLock()
+	snap := slices.Clone(derivedMetrics)
+	derivedMetricsMu.RUnlock()
+
+	return OrderDerivedMetrics(snap)
+}
+
+// OrderDerivedMetrics sorts metrics so each runs after the metrics named in its
+// After list. Unknown names in After are ignored. Metrics without constraints
+// between them keep their input order.
+func OrderDerivedMetrics(metrics []DerivedMetric) ([]DerivedMetric, error) {
+	index := make(map[string]int, len(metrics))
+	for i, dm := range metrics {
+		index[dm.Name] = i
+	}
+
+	pending := make([]int, len(metrics))
+	dependents := make([][]int, len(metrics))
+
+	for i, dm := range metrics {
+		for _, dep := range dm.After {
+			j, ok := index[dep]
+			if !ok {
+				continue
+			}
+
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	ordered := make([]DerivedMetric, 0, len(metrics))
+	done := make([]bool, len(metrics))
+
+	// Repeatedly take the first ready metric to keep the input order stable.
+	for len(ordered) < len(metrics) {
+		next := -1
+
+		for i := range metrics {
+			if !done[i] && pending[i] == 0 {
+				next = i
+
+				break
+			}
+		}
+
+		if next < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrDerivedMetricCycle, blockedNames(metrics, done))
+		}
+
+		done[next] = true
+		ordered = append(ordered, metrics[next])
+
+		for _, d := range dependents[next] {
+			pending[d]--
+		}
+	}
+
+	return ordered, nil
+}
+
+func blockedNames(metrics []DerivedMetric, done []bool) []string {
+	var names []string
+
+	for i, dm := range metrics {
+		if !done[i] {
+			names = append(names, dm.Name)
+		}
+	}
+
+	return names
+}
+
+// RunDerivedMetrics computes metrics, in order, over reports. Metrics whose
+// required analyzers are missing are skipped.
+func RunDerivedMetrics(reports map[string]Report, metrics []DerivedMetric) error {
+	for _, dm := range metrics {
+		if !hasReports(reports, dm.Requires) {
+			continue
+		}
+
+		err := dm.Compute(reports)
+		if err != nil {
+			return fmt.Errorf("derived metric %s: %w", dm.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func hasReports(reports map[string]Report, flags []string) bool {
+	for _, flag := range flags {
+		if _, ok := reports[flag]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,99 @@
+package analyze_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func derivedNames(metrics []analyze.DerivedMetric) []string {
+	names := make([]string, len(metrics))
+	for i, dm := range metrics {
+		names[i] = dm.Name
+	}
+
+	return names
+}
+
+func TestOrderDerivedMetrics_RespectsAfter(t *testing.T) {
+	t.Parallel()
+
+	ordered, err := analyze.OrderDerivedMetrics([]analyze.DerivedMetric{
+		{Name: "c", After: []string{"b"}},
+		{Name: "a"},
+		{Name: "b", After: []string{"a", "unknown"}},
+		{Name: "d"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, derivedNames(ordered))
+}
+
+func TestOrderDerivedMetrics_KeepsInputOrder(t *testing.T) {
+	t.Parallel()
+
+	ordered, err := analyze.OrderDerivedMetrics([]analyze.DerivedMetric{{Name: "z"}, {Name: "y"}, {Name: "x"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"z", "y", "x"}, derivedNames(ordered))
+}
+
+func TestOrderDerivedMetrics_Cycle(t *testing.T) {
+	t.Parallel()
+
+	_, err := analyze.OrderDerivedMetrics([]analyze.DerivedMetric{
+		{Name: "free"},
+		{Name: "a", After: []string{"b"}},
+		{Name: "b", After: []string{"a"}},
+	})
+	require.ErrorIs(t, err, analyze.ErrDerivedMetricCycle)
+	assert.Contains(t, err.Error(), "a b")
+}
+
+func TestRunDerivedMetrics_SkipsMissingRequirements(t *testing.T) {
+	t.Parallel()
+
+	var ran []string
+
+	record := func(name string) analyze.DerivedMetricFunc {
+		return func(_ map[string]analyze.Report) error {
+			ran = append(ran, name)
+
+			return nil
+		}
+	}
+
+	reports := map[string]analyze.Report{"devs": {}}
+
+	err := analyze.RunDerivedMetrics(reports, []analyze.DerivedMetric{
+		{Name: "needs-devs", Requires: []string{"devs"}, Compute: record("needs-devs")},
+		{Name: "needs-both", Requires: []string{"devs", "burndown"}, Compute: record("needs-both")},
+		{Name: "needs-none", Compute: record("needs-none")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"needs-devs", "needs-none"}, ran)
+}
+
+func TestRegisterDerivedMetric_ReplacesByName(t *testing.T) {
+	t.Parallel()
+
+	const name = "analyze_test.replace"
+
+	analyze.RegisterDerivedMetric(analyze.DerivedMetric{Name: name, Requires: []string{"first"}})
+	analyze.RegisterDerivedMetric(analyze.DerivedMetric{Name: name, Requires: []string{"second"}})
+
+	registered, err := analyze.RegisteredDerivedMetrics()
+	require.NoError(t, err)
+
+	var matches []analyze.DerivedMetric
+
+	for _, dm := range registered {
+		if dm.Name == name {
+			matches = append(matches, dm)
+		}
+	}
+
+	require.Len(t, matches, 1)
+	assert.Equal(t, []string{"second"}, matches[0].Requires)
+}
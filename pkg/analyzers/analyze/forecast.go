@@ -0,0 +1,255 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+	"github.com/Sumatoshi-tech/codefang/pkg/forecast"
+)
+
+// ForecastSeries is a regularly spaced series of a report worth projecting,
+// such as lines of code or active contributors: Values[i] is observed at
+// tick Start + i*Step.
+type ForecastSeries struct {
+	// Name identifies the series within its analyzer, e.g. "loc".
+	Name string
+	// Label titles the series in charts, e.g. "Lines of code".
+	Label  string
+	Start  int
+	Step   int
+	Values []float64
+	// TickSize is the duration of a tick; zero means the default of 24 hours.
+	TickSize time.Duration
+}
+
+// ForecastSeriesFunc extracts the series to forecast from a finalized report.
+type ForecastSeriesFunc func(report Report) []ForecastSeries
+
+// forecastSeriesFuncs maps analyzer flags to their series extractors.
+var (
+	forecastSeriesMu    sync.RWMutex
+	forecastSeriesFuncs = make(map[string]ForecastSeriesFunc)
+	forecastHorizon     time.Duration
+)
+
+// RegisterForecastSeries registers the series extractor of the analyzer with
+// the given flag.
+func RegisterForecastSeries(analyzerFlag string, fn ForecastSeriesFunc) {
+	forecastSeriesMu.Lock()
+	defer forecastSeriesMu.Unlock()
+
+	forecastSeriesFuncs[analyzerFlag] = fn
+}
+
+// SetForecastHorizon sets how far ahead the timeseries and plot output of
+// history runs project the registered series. Zero disables forecasts.
+func SetForecastHorizon(horizon time.Duration) {
+	forecastSeriesMu.Lock()
+	defer forecastSeriesMu.Unlock()
+
+	forecastHorizon = horizon
+}
+
+// TickValue is an observed value of a forecast series.
+type TickValue struct {
+	Tick  int     `json:"tick"`
+	Value float64 `json:"value"`
+}
+
+// ForecastPoint is a projected value of a series with its 95% prediction interval.
+type ForecastPoint struct {
+	Tick  int     `json:"tick"`
+	Value float64 `json:"value"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// SeriesForecast is the projection of one series, as emitted in the
+// forecasts of the timeseries output.
+type SeriesForecast struct {
+	Analyzer string          `json:"analyzer"`
+	Series   string          `json:"series"`
+	Label    string          `json:"label"`
+	Method   forecast.Method `json:"method"`
+	Observed []TickValue     `json:"observed"`
+	Forecast []ForecastPoint `json:"forecast"`
+}
+
+// BuildForecasts projects the registered series of the leaf reports over
+// the horizon set by SetForecastHorizon. Series too short to forecast are
+// left out. It returns nil when forecasts are disabled.
+func BuildForecasts(leaves []HistoryAnalyzer, results map[HistoryAnalyzer]Report) []SeriesForecast {
+	forecastSeriesMu.RLock()
+	horizon := forecastHorizon
+	forecastSeriesMu.RUnlock()
+
+	if horizon <= 0 {
+		return nil
+	}
+
+	var forecasts []SeriesForecast
+
+	for _, leaf := range leaves {
+		forecastSeriesMu.RLock()
+		fn := forecastSeriesFuncs[leaf.Flag()]
+		forecastSeriesMu.RUnlock()
+
+		report := results[leaf]
+		if fn == nil || report == nil {
+			continue
+		}
+
+		for _, series := range fn(report) {
+			projection, ok := projectSeries(series, horizon)
+			if ok {
+				projection.Analyzer = leaf.Flag()
+				forecasts = append(forecasts, projection)
+			}
+		}
+	}
+
+	return forecasts
+}
+
+// projectSeries forecasts series over horizon, converted to whole steps.
+// The series are counts and sizes, so projections are clamped at zero.
+func projectSeries(series ForecastSeries, horizon time.Duration) (SeriesForecast, bool) {
+	tickSize := series.TickSize
+	if tickSize <= 0 {
+		tickSize = defaultTickSizeHours * time.Hour
+	}
+
+	step := max(series.Step, 1)
+	steps := int(math.Ceil(float64(horizon) / float64(tickSize*time.Duration(step))))
+
+	projection, err := forecast.Auto(series.Values, steps)
+	if err != nil {
+		return SeriesForecast{}, false
+	}
+
+	observed := make([]TickValue, len(series.Values))
+	for i, value := range series.Values {
+		observed[i] = TickValue{Tick: series.Start + i*step, Value: value}
+	}
+
+	last := observed[len(observed)-1].Tick
+	points := make([]ForecastPoint, len(projection.Points))
+
+	for i, p := range projection.Points {
+		points[i] = ForecastPoint{
+			Tick:  last + p.Step*step,
+			Value: max(p.Value, 0),
+			Lower: max(p.Lower, 0),
+			Upper: max(p.Upper, 0),
+		}
+	}
+
+	return SeriesForecast{
+		Series:   series.Name,
+		Label:    series.Label,
+		Method:   projection.Method,
+		Observed: observed,
+		Forecast: points,
+	}, true
+}
+
+// ForecastSections renders each forecast as a chart of its observed values
+// followed by a dashed projection inside its shaded prediction interval.
+func ForecastSections(forecasts []SeriesForecast) []plotpage.Section {
+	sections := make([]plotpage.Section, 0, len(forecasts))
+
+	for _, f := range forecasts {
+		sections = append(sections, plotpage.Section{
+			Title: "Forecast: " + f.Label,
+			Subtitle: fmt.Sprintf("%s, projected %d ticks ahead by %s with a 95%% prediction interval.",
+				f.Analyzer, f.Forecast[len(f.Forecast)-1].Tick-f.Observed[len(f.Observed)-1].Tick, f.Method),
+			Chart: plotpage.WrapChart(forecastChart(f)),
+		})
+	}
+
+	return sections
+}
+
+const (
+	forecastBandOpacity = 0.2
+	forecastLineWidth   = 2
+)
+
+func forecastChart(f SeriesForecast) *charts.Line {
+	co := plotpage.DefaultChartOpts()
+	palette := plotpage.GetChartPalette(plotpage.ThemeDark)
+
+	total := len(f.Observed) + len(f.Forecast)
+	labels := make([]string, 0, total)
+	observed := make([]opts.LineData, 0, total)
+	projected := make([]opts.LineData, 0, total)
+	lower := make([]opts.LineData, 0, total)
+	band := make([]opts.LineData, 0, total)
+	gap := opts.LineData{Value: "-"}
+
+	for i, v := range f.Observed {
+		labels = append(labels, strconv.Itoa(v.Tick))
+		observed = append(observed, opts.LineData{Value: v.Value})
+
+		// The projection and the band start at the last observation, so the
+		// dashed line continues the solid one.
+		if i == len(f.Observed)-1 {
+			projected = append(projected, opts.LineData{Value: v.Value})
+			lower = append(lower, opts.LineData{Value: v.Value})
+			band = append(band, opts.LineData{Value: 0})
+		} else {
+			projected, lower, band = append(projected, gap), append(lower, gap), append(band, gap)
+		}
+	}
+
+	for _, p := range f.Forecast {
+		labels = append(labels, strconv.Itoa(p.Tick))
+		observed = append(observed, gap)
+		projected = append(projected, opts.LineData{Value: p.Value})
+		lower = append(lower, opts.LineData{Value: p.Lower})
+		band = append(band, opts.LineData{Value: p.Upper - p.Lower})
+	}
+
+	color := palette.Primary[0]
+
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithInitializationOpts(co.Init("100%", "400px")),
+		charts.WithTooltipOpts(co.Tooltip("axis")),
+		charts.WithDataZoomOpts(co.DataZoom()...),
+		charts.WithXAxisOpts(co.XAxis("Time (tick)")),
+		charts.WithYAxisOpts(co.YAxis(f.Label)),
+		charts.WithGridOpts(co.Grid()),
+		charts.WithLegendOpts(co.Legend()),
+	)
+	line.SetXAxis(labels)
+	line.AddSeries("Observed", observed,
+		charts.WithItemStyleOpts(opts.ItemStyle{Color: color}),
+		charts.WithLineStyleOpts(opts.LineStyle{Color: color, Width: forecastLineWidth}),
+	)
+	line.AddSeries("Forecast", projected,
+		charts.WithItemStyleOpts(opts.ItemStyle{Color: color}),
+		charts.WithLineStyleOpts(opts.LineStyle{Color: color, Width: forecastLineWidth, Type: "dashed"}),
+	)
+	// The interval is drawn as a transparent lower bound with the width of
+	// the interval stacked on top of it.
+	line.AddSeries("Lower bound", lower,
+		charts.WithLineChartOpts(opts.LineChart{Stack: "interval", ShowSymbol: opts.Bool(false)}),
+		charts.WithLineStyleOpts(opts.LineStyle{Opacity: opts.Float(0)}),
+	)
+	line.AddSeries("95% interval", band,
+		charts.WithLineChartOpts(opts.LineChart{Stack: "interval", ShowSymbol: opts.Bool(false)}),
+		charts.WithItemStyleOpts(opts.ItemStyle{Color: color}),
+		charts.WithLineStyleOpts(opts.LineStyle{Opacity: opts.Float(0)}),
+		charts.WithAreaStyleOpts(opts.AreaStyle{Color: color, Opacity: opts.Float(forecastBandOpacity)}),
+	)
+
+	return line
+}
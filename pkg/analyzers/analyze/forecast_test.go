@@ -0,0 +1,69 @@
+package analyze
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/forecast"
+)
+
+// TestBuildForecasts sets the forecast horizon and registry of the process,
+// so it does not run in parallel.
+func TestBuildForecasts(t *testing.T) {
+	t.Cleanup(func() {
+		SetForecastHorizon(0)
+		RegisterForecastSeries("sizes", nil)
+	})
+
+	RegisterForecastSeries("sizes", func(report Report) []ForecastSeries {
+		values, _ := report["values"].([]float64)
+
+		return []ForecastSeries{
+			{Name: "size", Label: "Size", Start: 4, Step: 2, Values: values},
+			{Name: "short", Label: "Short", Values: values[:2]},
+		}
+	})
+
+	leaf := namedLeaf{flag: "sizes"}
+	other := namedLeaf{flag: "other"}
+	leaves := []HistoryAnalyzer{leaf, other}
+	results := map[HistoryAnalyzer]Report{leaf: {"values": []float64{10, 8, 6, 4}}, other: {}}
+
+	assert.Nil(t, BuildForecasts(leaves, results), "no horizon, no forecasts")
+
+	SetForecastHorizon(5 * 24 * time.Hour)
+
+	forecasts := BuildForecasts(leaves, results)
+	require.Len(t, forecasts, 1, "series too short to forecast are left out")
+
+	f := forecasts[0]
+	assert.Equal(t, "sizes", f.Analyzer)
+	assert.Equal(t, forecast.MethodLinear, f.Method)
+	assert.Equal(t, TickValue{Tick: 10, Value: 4}, f.Observed[3])
+	require.Len(t, f.Forecast, 3, "5 days are 3 steps of 2 ticks")
+	assert.Equal(t, 16, f.Forecast[2].Tick)
+	assert.InDelta(t, 0, f.Forecast[2].Value, 1e-9, "projections stop at zero")
+
+	var buf bytes.Buffer
+
+	err := OutputHistoryResults(leaves, results, FormatTimeSeries, &buf, 7)
+	require.NoError(t, err)
+
+	var ts MergedTimeSeries
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &ts))
+	assert.Equal(t, uint64(7), ts.Seed)
+	require.Len(t, ts.Forecasts, 1)
+	assert.Equal(t, "size", ts.Forecasts[0].Series)
+
+	buf.Reset()
+
+	err = OutputHistoryResults([]HistoryAnalyzer{leaf}, results, FormatPlot, &buf, 0)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Forecast: Size")
+	assert.Contains(t, buf.String(), `"dashed"`)
+}
@@ -24,6 +24,10 @@ const (
 	// FormatNDJSON is the streaming output format that writes one JSON line
 	// per TC as commits are processed. No aggregator, no buffering.
 	FormatNDJSON = "ndjson"
+
+	// FormatGraph is the output format that writes the graphs of analyzers
+	// implementing GraphGenerator as GraphML. It is not available in mixed runs.
+	FormatGraph = "graph"
 )
 
 var (
@@ -58,6 +62,11 @@ func ValidateFormat(format string, supported []string) (string, error) {
 	return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
 }
 
+// HistoryOutputFormats returns the output formats supported by history-only runs.
+func HistoryOutputFormats() []string {
+	return append(UniversalFormats(), FormatGraph)
+}
+
 // ValidateUniversalFormat checks whether a format belongs to the universal contract.
 func ValidateUniversalFormat(format string) (string, error) {
 	normalized := NormalizeFormat(format)
@@ -54,3 +54,18 @@ func TestValidateFormat_CustomSet(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, FormatBinary, normalized)
 }
+
+func TestResolveFormats_GraphIsNotMixed(t *testing.T) {
+	t.Parallel()
+
+	_, historyFmt, err := ResolveFormats("graph", false, true)
+	require.NoError(t, err)
+	require.Equal(t, FormatGraph, historyFmt)
+
+	_, _, err = ResolveFormats("graph", true, true)
+	require.ErrorIs(t, err, ErrInvalidMixedFormat)
+
+	staticFmt, _, err := ResolveFormats("graph", true, false)
+	require.NoError(t, err)
+	require.Equal(t, FormatGraph, staticFmt)
+}
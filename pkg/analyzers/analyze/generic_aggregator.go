@@ -1,9 +1,9 @@
 package analyze
 
 import (
-	"fmt"
+	"slices"
 	"sort"
-	"strconv"
+	"time"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/spillstore"
 )
@@ -11,10 +11,13 @@ import (
 // GenericAggregator manages per-tick state accumulation, spilling, and collection.
 // S is the tick accumulator state (e.g., *TickAccumulator).
 // T is the final tick data representation (e.g., *TickData).
+//
+// Spills are sorted runs of per-tick states, so StreamTicks can merge them
+// tick by tick without reloading every spilled state at once.
 type GenericAggregator[S any, T any] struct {
 	Opts       AggregatorOptions
 	ByTick     map[int]S
-	SpillStore *spillstore.SpillStore[S]
+	SpillStore *spillstore.RunStore[S]
 
 	// Delegate Hooks.
 	ExtractTCFn  func(TC, map[int]S) error
@@ -31,10 +34,13 @@ func NewGenericAggregator[S any, T any](
 	sizeFn func(S) int64,
 	buildFn func(int, S) (TICK, error),
 ) *GenericAggregator[S, T] {
+	store := spillstore.NewRuns[S](opts.SpillDir)
+	store.SetCodec(opts.SpillCodec)
+
 	return &GenericAggregator[S, T]{
 		Opts:         opts,
 		ByTick:       make(map[int]S),
-		SpillStore:   spillstore.New[S](),
+		SpillStore:   store,
 		ExtractTCFn:  extractFn,
 		MergeStateFn: mergeFn,
 		SizeStateFn:  sizeFn,
@@ -108,11 +114,7 @@ func (a *GenericAggregator[S, T]) Spill() (int64, error) {
 
 	size := a.EstimatedStateSize()
 
-	for tick, state := range a.ByTick {
-		a.SpillStore.Put(formatTickKey(tick), state)
-	}
-
-	errSpill := a.SpillStore.Spill()
+	errSpill := a.SpillStore.Spill(a.ByTick)
 	if errSpill != nil {
 		return 0, errSpill
 	}
@@ -128,25 +130,32 @@ func (a *GenericAggregator[S, T]) Collect() error {
 		return nil
 	}
 
-	collected, err := a.SpillStore.CollectWith(a.MergeStateFn)
+	collected, err := a.SpillStore.Collect(a.ByTick, a.MergeStateFn)
 	if err != nil {
 		return err
 	}
 
-	for k, v := range collected {
-		tick, errParse := parseTickKey(k)
-		if errParse != nil {
-			continue // skip invalid keys silently or log them.
-		}
+	a.ByTick = collected
+
+	return nil
+}
+
+// StreamTicks merges the spilled runs with the in-memory state and calls fn
+// with each tick's TICK in ascending order. Only one merged state per tick is
+// alive at a time. The accumulated state is consumed: afterwards the
+// aggregator is empty.
+func (a *GenericAggregator[S, T]) StreamTicks(fn func(TICK) error) error {
+	current := a.ByTick
+	a.ByTick = make(map[int]S)
 
-		if existing, ok := a.ByTick[tick]; ok {
-			a.ByTick[tick] = a.MergeStateFn(existing, v)
-		} else {
-			a.ByTick[tick] = v
+	return a.SpillStore.Merge(current, a.MergeStateFn, func(tick int, state S) error {
+		t, err := a.BuildTickFn(tick, state)
+		if err != nil {
+			return err
 		}
-	}
 
-	return nil
+		return fn(t)
+	})
 }
 
 // EstimatedStateSize returns the current in-memory footprint of the accumulated state.
@@ -162,8 +171,10 @@ func (a *GenericAggregator[S, T]) EstimatedStateSize() int64 {
 // SpillState returns the current on-disk spill state for checkpoint persistence.
 func (a *GenericAggregator[S, T]) SpillState() AggregatorSpillInfo {
 	return AggregatorSpillInfo{
-		Dir:   a.SpillStore.SpillDir(),
-		Count: a.SpillStore.SpillCount(),
+		Dir:     a.SpillStore.SpillDir(),
+		Count:   a.SpillStore.SpillCount(),
+		Codec:   a.SpillStore.Codec(),
+		Written: a.SpillStore.BytesWritten(),
 	}
 }
 
@@ -171,6 +182,7 @@ func (a *GenericAggregator[S, T]) SpillState() AggregatorSpillInfo {
 func (a *GenericAggregator[S, T]) RestoreSpillState(info AggregatorSpillInfo) {
 	if info.Count > 0 && info.Dir != "" {
 		a.SpillStore.RestoreFromDir(info.Dir, info.Count)
+		a.SpillStore.SetCodec(info.SpillCodec())
 	}
 }
 
@@ -185,16 +197,26 @@ func (a *GenericAggregator[S, T]) Close() error {
 	return nil
 }
 
-// Internal helpers for keys.
-func formatTickKey(tick int) string {
-	return strconv.Itoa(tick)
-}
+// CollectTickCommits lists the commits of ticks in history order, for
+// analyzers whose tick data is a list of commits. The commits of a tick are
+// ordered by timeOf, as parallel workers may deliver them out of order.
+// Ticks whose data is not a non-nil *D are skipped.
+func CollectTickCommits[D any, C any](ticks []TICK, commitsOf func(*D) []C, timeOf func(C) time.Time) []C {
+	var commits []C
 
-func parseTickKey(key string) (int, error) {
-	val, err := strconv.Atoi(key)
-	if err != nil {
-		return 0, fmt.Errorf("parse tick key: %w", err)
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*D)
+		if !ok || td == nil {
+			continue
+		}
+
+		tickCommits := slices.Clone(commitsOf(td))
+		slices.SortStableFunc(tickCommits, func(x, y C) int {
+			return timeOf(x).Compare(timeOf(y))
+		})
+
+		commits = append(commits, tickCommits...)
 	}
 
-	return val, nil
+	return commits
 }
@@ -2,10 +2,12 @@ package analyze_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
 )
 
 type DummyState struct {
@@ -215,3 +217,102 @@ func TestGenericAggregator_RestoreSpillState(t *testing.T) {
 
 	require.NoError(t, agg1.Close()) // cleans up dir.
 }
+
+func TestGenericAggregator_RestoreSpillStateWithCodec(t *testing.T) {
+	t.Parallel()
+
+	zstdCodec, err := codec.Parse(codec.Zstd)
+	require.NoError(t, err)
+
+	agg1 := analyze.NewGenericAggregator[*DummyState, *DummyTickData](
+		analyze.AggregatorOptions{SpillBudget: 10, SpillCodec: zstdCodec},
+		extractTC, mergeState, sizeState, buildTick,
+	)
+	require.NoError(t, agg1.Add(analyze.TC{Tick: 1, Data: 10}))
+	require.NoError(t, agg1.Add(analyze.TC{Tick: 2, Data: 20})) // triggers spill.
+
+	info := agg1.SpillState()
+	require.Equal(t, codec.Zstd, info.Codec)
+
+	// The restoring aggregator has no codec configured; it must read the
+	// spills with the codec recorded in the spill state.
+	agg2 := setupAggregator(0)
+
+	defer func() { require.NoError(t, agg2.Close()) }()
+
+	agg2.RestoreSpillState(info)
+
+	require.NoError(t, agg2.Collect())
+
+	ticks, err := agg2.FlushAllTicks()
+	require.NoError(t, err)
+	require.Len(t, ticks, 2)
+
+	require.NoError(t, agg1.Close())
+}
+
+func TestGenericAggregator_StreamTicks(t *testing.T) {
+	t.Parallel()
+
+	agg := setupAggregator(10)
+
+	defer func() { require.NoError(t, agg.Close()) }()
+
+	require.NoError(t, agg.Add(analyze.TC{Tick: 2, Data: 4}))
+	require.NoError(t, agg.Add(analyze.TC{Tick: 1, Data: 5})) // triggers spill.
+	require.NoError(t, agg.Add(analyze.TC{Tick: 1, Data: 2}))
+	require.Equal(t, 1, agg.SpillState().Count)
+
+	var (
+		ticks  []int
+		totals []int
+	)
+
+	err := agg.StreamTicks(func(tick analyze.TICK) error {
+		data, ok := tick.Data.(*DummyTickData)
+		require.True(t, ok)
+
+		ticks = append(ticks, tick.Tick)
+		totals = append(totals, data.Total)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []int{1, 2}, ticks)
+	require.Equal(t, []int{7, 4}, totals)
+	require.Equal(t, 0, agg.SpillState().Count)
+	require.Zero(t, agg.EstimatedStateSize())
+}
+
+type timedCommit struct {
+	Hash string
+	Time time.Time
+}
+
+type commitTickData struct {
+	Commits []timedCommit
+}
+
+func TestCollectTickCommits(t *testing.T) {
+	t.Parallel()
+
+	at := func(minute int) time.Time { return time.Date(2024, 1, 1, 0, minute, 0, 0, time.UTC) }
+
+	ticks := []analyze.TICK{
+		{Tick: 0, Data: &commitTickData{Commits: []timedCommit{{"b", at(2)}, {"a", at(1)}}}},
+		{Tick: 1, Data: (*commitTickData)(nil)},
+		{Tick: 2, Data: "not a tick of commits"},
+		{Tick: 3, Data: &commitTickData{Commits: []timedCommit{{"c", at(0)}}}},
+	}
+
+	commits := analyze.CollectTickCommits(ticks,
+		func(td *commitTickData) []timedCommit { return td.Commits },
+		func(c timedCommit) time.Time { return c.Time })
+
+	require.Equal(t, []timedCommit{{"a", at(1)}, {"b", at(2)}, {"c", at(0)}}, commits)
+
+	first, ok := ticks[0].Data.(*commitTickData)
+	require.True(t, ok)
+	require.Equal(t, "b", first.Commits[0].Hash, "the tick data is not reordered in place")
+}
@@ -144,3 +144,18 @@ type Parallelizable interface {
 	// in the worker have consumed it.
 	ReleaseSnapshot(snapshot PlumbingSnapshot)
 }
+
+// CommitPreparer is optionally implemented by sequential analyzers whose
+// Consume starts with work that depends only on the commit's own data (blob
+// line counts, diffs of blob pairs) and not on state built from earlier commits.
+//
+// With commit lookahead enabled, the framework calls PrepareCommit for the next
+// commit on a separate goroutine while Consume runs for the current one, and
+// always before Consume of the prepared commit. PrepareCommit must not touch
+// state that Consume mutates, and its results may only serve as a cache:
+// Consume must produce the same output when PrepareCommit was never called.
+// The context holds the commit's changes, blobs, file diffs and, when the UAST
+// stage runs, its UAST changes; the trees must not be kept past the call.
+type CommitPreparer interface {
+	PrepareCommit(ac *Context)
+}
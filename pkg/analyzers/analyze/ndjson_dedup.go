@@ -0,0 +1,95 @@
+package analyze
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrMalformedNDJSON is returned by DedupNDJSON for lines that are not NDJSON records.
+var ErrMalformedNDJSON = errors.New("malformed ndjson record")
+
+// DedupStats counts the records seen by DedupNDJSON.
+type DedupStats struct {
+	Records    int // records read.
+	Duplicates int // records dropped as duplicates.
+}
+
+// ndjsonRecordKey is the identity of an NDJSON record.
+type ndjsonRecordKey struct {
+	Analyzer string `json:"analyzer"`
+	Hash     string `json:"hash"`
+}
+
+// DedupNDJSON copies --format ndjson records from r to w, dropping every
+// record whose (analyzer, hash) pair was already copied. Use it to merge the
+// outputs of interrupted and resumed runs into one stream. Kept lines are
+// written unchanged; blank lines are skipped.
+func DedupNDJSON(r io.Reader, w io.Writer) (DedupStats, error) {
+	var stats DedupStats
+
+	seen := make(map[ndjsonRecordKey]struct{})
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := br.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return stats, fmt.Errorf("read ndjson: %w", readErr)
+		}
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			keep, err := dedupLine(line, lineNo, seen, &stats)
+			if err != nil {
+				return stats, err
+			}
+
+			if keep {
+				_, err = bw.Write(line)
+				if err == nil && line[len(line)-1] != '\n' {
+					err = bw.WriteByte('\n')
+				}
+
+				if err != nil {
+					return stats, fmt.Errorf("write ndjson: %w", err)
+				}
+			}
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	err := bw.Flush()
+	if err != nil {
+		return stats, fmt.Errorf("write ndjson: %w", err)
+	}
+
+	return stats, nil
+}
+
+// dedupLine reports whether line is the first record with its identity.
+func dedupLine(line []byte, lineNo int, seen map[ndjsonRecordKey]struct{}, stats *DedupStats) (bool, error) {
+	var key ndjsonRecordKey
+
+	err := json.Unmarshal(line, &key)
+	if err != nil || key.Analyzer == "" || key.Hash == "" {
+		return false, fmt.Errorf("%w: line %d", ErrMalformedNDJSON, lineNo)
+	}
+
+	stats.Records++
+
+	if _, dup := seen[key]; dup {
+		stats.Duplicates++
+
+		return false, nil
+	}
+
+	seen[key] = struct{}{}
+
+	return true, nil
+}
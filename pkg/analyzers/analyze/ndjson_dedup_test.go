@@ -0,0 +1,49 @@
+package analyze_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestDedupNDJSON_DropsRepeatedRecords(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Join([]string{
+		`{"seq":1,"hash":"a","analyzer":"devs","data":{}}`,
+		`{"seq":2,"hash":"a","analyzer":"quality","data":{}}`,
+		``,
+		`{"seq":3,"hash":"b","analyzer":"devs","data":{}}`,
+		// Resumed run repeats b and continues with c.
+		`{"seq":3,"hash":"b","analyzer":"devs","data":{}}`,
+		`{"seq":4,"hash":"c","analyzer":"devs","data":{}}`,
+	}, "\n")
+
+	var out bytes.Buffer
+
+	stats, err := analyze.DedupNDJSON(strings.NewReader(input), &out)
+	require.NoError(t, err)
+
+	assert.Equal(t, analyze.DedupStats{Records: 5, Duplicates: 1}, stats)
+	assert.Equal(t, strings.Join([]string{
+		`{"seq":1,"hash":"a","analyzer":"devs","data":{}}`,
+		`{"seq":2,"hash":"a","analyzer":"quality","data":{}}`,
+		`{"seq":3,"hash":"b","analyzer":"devs","data":{}}`,
+		`{"seq":4,"hash":"c","analyzer":"devs","data":{}}`,
+	}, "\n")+"\n", out.String())
+}
+
+func TestDedupNDJSON_MalformedLine(t *testing.T) {
+	t.Parallel()
+
+	input := "{\"hash\":\"a\",\"analyzer\":\"devs\"}\nnot json\n"
+
+	_, err := analyze.DedupNDJSON(strings.NewReader(input), &bytes.Buffer{})
+	require.ErrorIs(t, err, analyze.ErrMalformedNDJSON)
+	assert.Contains(t, err.Error(), "line 2")
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-echarts/go-echarts/v2/components"
 
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/graphexport"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/version"
@@ -28,12 +29,19 @@ type SectionGenerator interface {
 	GenerateSections(report Report) ([]plotpage.Section, error)
 }
 
+// GraphGenerator interface for analyzers that can export developer networks.
+type GraphGenerator interface {
+	GenerateGraphs(report Report) ([]graphexport.Graph, error)
+}
+
 // OutputHistoryResults outputs the results for all selected history leaves.
+// The seed of the run is recorded in time-series output.
 func OutputHistoryResults(
 	leaves []HistoryAnalyzer,
 	results map[HistoryAnalyzer]Report,
 	format string,
 	writer io.Writer,
+	seed uint64,
 ) error {
 	if writer == nil {
 		writer = os.Stdout
@@ -45,16 +53,34 @@ func OutputHistoryResults(
 	}
 
 	if format == FormatTimeSeries {
-		return outputMergedTimeSeries(leaves, results, writer)
+		return outputMergedTimeSeries(leaves, results, writer, seed)
+	}
+
+	if format == FormatGraph {
+		graphs, err := collectGraphs(leaves, results)
+		if err != nil {
+			return err
+		}
+
+		return graphexport.WriteGraphML(writer, graphs...)
 	}
 
 	rawOutput := format == FormatJSON || format == FormatPlot || format == FormatBinary
 	if !rawOutput {
 		PrintHeader(writer)
+		PrintQuality(writer, QualityFromReports(results))
+		PrintBlobCache(writer, BlobCacheFromReports(results))
+		PrintCommitTable(writer, CommitTableFromReports(results))
 	}
 
-	if format == FormatPlot && len(leaves) > 1 {
-		return outputCombinedPlot(leaves, results, writer)
+	if format == FormatPlot {
+		plotpage.SetTimeline(CommitTimeline(CommitTableFromReports(results)))
+
+		// Forecasts are sections of their own, so they need the combined page.
+		forecasts := BuildForecasts(leaves, results)
+		if len(leaves) > 1 || len(forecasts) > 0 {
+			return outputCombinedPlot(leaves, results, forecasts, writer)
+		}
 	}
 
 	for _, leaf := range leaves {
@@ -73,6 +99,10 @@ func OutputHistoryResults(
 		}
 	}
 
+	if rawOutput {
+		return writeCommitTable(writer, CommitTableFromReports(results), format)
+	}
+
 	return nil
 }
 
@@ -83,15 +113,62 @@ func outputMergedTimeSeries(
 	leaves []HistoryAnalyzer,
 	results map[HistoryAnalyzer]Report,
 	writer io.Writer,
+	seed uint64,
 ) error {
 	active := collectProviderData(leaves, results)
 	commitMeta := buildOrderedCommitMeta(leaves, results)
 
-	ts := BuildMergedTimeSeriesDirect(active, commitMeta, 0)
+	ts := BuildMergedTimeSeriesDirect(active, commitMeta, 0, seed)
+	ts.Forecasts = BuildForecasts(leaves, results)
 
 	return WriteMergedTimeSeries(ts, writer)
 }
 
+// OutputGraphFiles writes the networks of every GraphGenerator leaf to dir as
+// <network>.graphml and <network>.gexf.
+func OutputGraphFiles(
+	leaves []HistoryAnalyzer,
+	results map[HistoryAnalyzer]Report,
+	dir string,
+) error {
+	graphs, err := collectGraphs(leaves, results)
+	if err != nil {
+		return err
+	}
+
+	return graphexport.WriteFiles(dir, graphs)
+}
+
+// collectGraphs gathers the networks of all leaves that implement GraphGenerator.
+// Graph IDs are prefixed with the leaf flag, so they are unique across analyzers.
+func collectGraphs(leaves []HistoryAnalyzer, results map[HistoryAnalyzer]Report) ([]graphexport.Graph, error) {
+	var graphs []graphexport.Graph
+
+	for _, leaf := range leaves {
+		gen, ok := leaf.(GraphGenerator)
+		if !ok {
+			continue
+		}
+
+		report := results[leaf]
+		if report == nil {
+			continue
+		}
+
+		generated, err := gen.GenerateGraphs(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate graphs for %s: %w", leaf.Name(), err)
+		}
+
+		for _, g := range generated {
+			g.ID = leaf.Flag() + "-" + g.ID
+			graphs = append(graphs, g)
+		}
+	}
+
+	return graphs, nil
+}
+
 // collectProviderData iterates leaves sorted by flag, type-asserts each to
 // CommitTimeSeriesProvider, and collects non-empty per-commit data.
 func collectProviderData(
@@ -230,6 +307,7 @@ func assembleOrderedCommitMeta(
 func outputCombinedPlot(
 	leaves []HistoryAnalyzer,
 	results map[HistoryAnalyzer]Report,
+	forecasts []SeriesForecast,
 	writer io.Writer,
 ) error {
 	page := buildCombinedPage(leaves)
@@ -246,6 +324,8 @@ func outputCombinedPlot(
 		}
 	}
 
+	page.Add(ForecastSections(forecasts)...)
+
 	err := page.Render(writer)
 	if err != nil {
 		return fmt.Errorf("render page: %w", err)
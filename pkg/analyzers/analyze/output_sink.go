@@ -0,0 +1,384 @@
+package analyze
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/storage"
+)
+
+// OutputSink is a destination of run output. A run hands streaming NDJSON
+// records to WriteTC as they are produced and its rendered report to
+// WriteReport once, at the end. Close is called after the last write.
+//
+// Implementations must be safe for concurrent WriteTC calls.
+type OutputSink interface {
+	WriteTC(tc TC, analyzerFlag string) error
+	WriteReport(format string, report []byte) error
+	Close() error
+}
+
+// Output sink types accepted by NewOutputSink.
+const (
+	OutputSinkStdout  = "stdout"
+	OutputSinkFile    = "file"
+	OutputSinkWebhook = "webhook"
+)
+
+// Webhook sink defaults.
+const (
+	defaultWebhookBatchSize = 500
+	defaultWebhookTimeout   = 30 * time.Second
+)
+
+var (
+	// ErrUnknownOutputSink is returned for an unrecognized sink type.
+	ErrUnknownOutputSink = errors.New("unknown output sink")
+	// ErrOutputSinkTarget is returned when a sink lacks its path or URL.
+	ErrOutputSinkTarget = errors.New("output sink target missing")
+	// ErrWebhookStatus is returned when a webhook answers with a non-2xx status.
+	ErrWebhookStatus = errors.New("webhook rejected output")
+)
+
+// OutputSinkSpec describes one output sink.
+type OutputSinkSpec struct {
+	// Type is one of OutputSinkStdout, OutputSinkFile or OutputSinkWebhook.
+	Type string
+	// Path is the file a file sink writes to. It is truncated on open.
+	Path string
+	// URL is the endpoint a webhook sink posts to.
+	URL string
+	// Headers are added to every webhook request, e.g. for authorization.
+	Headers map[string]string
+	// BatchSize is the number of NDJSON records per webhook request.
+	// Values below 1 mean 500.
+	BatchSize int
+	// Timeout bounds each webhook request. Zero means 30s.
+	Timeout time.Duration
+}
+
+// NewOutputSink opens the sink described by spec. Stdout sinks write to stdout.
+func NewOutputSink(spec OutputSinkSpec, stdout io.Writer) (OutputSink, error) {
+	switch spec.Type {
+	case OutputSinkStdout:
+		return NewWriterSink(stdout), nil
+	case OutputSinkFile:
+		if spec.Path == "" {
+			return nil, fmt.Errorf("%w: file sink needs a path", ErrOutputSinkTarget)
+		}
+
+		return NewFileSink(spec.Path)
+	case OutputSinkWebhook:
+		if spec.URL == "" {
+			return nil, fmt.Errorf("%w: webhook sink needs a url", ErrOutputSinkTarget)
+		}
+
+		return NewWebhookSink(spec), nil
+	default:
+		return nil, fmt.Errorf("%w: %q (want stdout, file or webhook)", ErrUnknownOutputSink, spec.Type)
+	}
+}
+
+// NewOutputSinks opens every sink of specs and fans them out behind one
+// MultiSink. Sinks opened before a failure are closed again.
+func NewOutputSinks(specs []OutputSinkSpec, stdout io.Writer) (*MultiSink, error) {
+	sinks := make([]OutputSink, 0, len(specs))
+
+	for _, spec := range specs {
+		sink, err := NewOutputSink(spec, stdout)
+		if err != nil {
+			closeErr := NewMultiSink(sinks...).Close()
+
+			return nil, errors.Join(err, closeErr)
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	return NewMultiSink(sinks...), nil
+}
+
+// MultiSink writes everything it receives to each of its sinks. A failing
+// sink does not stop the others; their errors are joined.
+type MultiSink struct {
+	sinks []OutputSink
+}
+
+// NewMultiSink creates a MultiSink over sinks.
+func NewMultiSink(sinks ...OutputSink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+// WriteTC writes the record to every sink.
+func (m *MultiSink) WriteTC(tc TC, analyzerFlag string) error {
+	var errs []error
+
+	for _, sink := range m.sinks {
+		errs = append(errs, sink.WriteTC(tc, analyzerFlag))
+	}
+
+	return errors.Join(errs...)
+}
+
+// WriteReport writes the report to every sink.
+func (m *MultiSink) WriteReport(format string, report []byte) error {
+	var errs []error
+
+	for _, sink := range m.sinks {
+		errs = append(errs, sink.WriteReport(format, report))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close closes every sink.
+func (m *MultiSink) Close() error {
+	var errs []error
+
+	for _, sink := range m.sinks {
+		errs = append(errs, sink.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// WriterSink writes records as NDJSON lines and reports verbatim to an
+// [io.Writer]. Closing it does not close the writer.
+type WriterSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+	lines  *StreamingSink
+}
+
+// NewWriterSink creates a WriterSink that writes to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{writer: w, lines: NewStreamingSink(w)}
+}
+
+// WriteTC writes one NDJSON line for the record.
+func (s *WriterSink) WriteTC(tc TC, analyzerFlag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lines.WriteTC(tc, analyzerFlag)
+}
+
+// WriteReport writes the report.
+func (s *WriterSink) WriteReport(_ string, report []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.writer.Write(report)
+	if err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	return nil
+}
+
+// Close is a no-op.
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// FileSink is a WriterSink over a file it owns. The path may be an object
+// storage URL such as s3://bucket/report.json; see [storage.Create].
+type FileSink struct {
+	*WriterSink
+
+	path string
+	file io.WriteCloser
+}
+
+// NewFileSink creates or truncates the file at path and writes to it.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := storage.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("open output file: %w", err)
+	}
+
+	return &FileSink{WriterSink: NewWriterSink(file), path: path, file: file}, nil
+}
+
+// Close closes the file. For object storage this uploads it.
+func (s *FileSink) Close() error {
+	err := s.file.Close()
+	if err != nil {
+		return fmt.Errorf("close output file %s: %w", s.path, err)
+	}
+
+	return nil
+}
+
+// WebhookSink posts output to an HTTP endpoint. Records are sent as NDJSON
+// bodies of up to BatchSize lines; the report is sent in one request with a
+// content type matching its format.
+type WebhookSink struct {
+	url       string
+	headers   map[string]string
+	batchSize int
+	client    *http.Client
+
+	mu      sync.Mutex
+	batch   bytes.Buffer
+	encoder *json.Encoder
+	pending int
+}
+
+// NewWebhookSink creates a WebhookSink for spec.URL.
+func NewWebhookSink(spec OutputSinkSpec) *WebhookSink {
+	timeout := spec.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	batchSize := spec.BatchSize
+	if batchSize < 1 {
+		batchSize = defaultWebhookBatchSize
+	}
+
+	sink := &WebhookSink{
+		url:       spec.URL,
+		headers:   spec.Headers,
+		batchSize: batchSize,
+		client:    &http.Client{Timeout: timeout},
+	}
+	sink.encoder = json.NewEncoder(&sink.batch)
+
+	return sink
+}
+
+// WriteTC queues one NDJSON line and posts the batch once it is full.
+// Skips TCs with nil Data.
+func (s *WebhookSink) WriteTC(tc TC, analyzerFlag string) error {
+	if tc.Data == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.encoder.Encode(newNDJSONLine(tc, analyzerFlag))
+	if err != nil {
+		return fmt.Errorf("ndjson encode: %w", err)
+	}
+
+	s.pending++
+
+	if s.pending < s.batchSize {
+		return nil
+	}
+
+	return s.flushLocked()
+}
+
+// WriteReport posts the report.
+func (s *WebhookSink) WriteReport(format string, report []byte) error {
+	return s.post(reportContentType(format), report)
+}
+
+// Close posts the records still queued.
+func (s *WebhookSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.flushLocked()
+}
+
+func (s *WebhookSink) flushLocked() error {
+	if s.pending == 0 {
+		return nil
+	}
+
+	err := s.post(ndjsonContentType, s.batch.Bytes())
+
+	s.batch.Reset()
+	s.pending = 0
+
+	return err
+}
+
+func (s *WebhookSink) post(contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // drained so the connection is reused.
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s: status %d", ErrWebhookStatus, s.url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// ndjsonContentType is the media type of NDJSON bodies.
+const ndjsonContentType = "application/x-ndjson"
+
+// reportContentType returns the media type of a report in format.
+func reportContentType(format string) string {
+	switch format {
+	case FormatJSON:
+		return "application/json"
+	case FormatYAML:
+		return "application/yaml"
+	case FormatNDJSON, FormatTimeSeries:
+		return ndjsonContentType
+	case FormatPlot:
+		return "text/html"
+	case FormatText, FormatCompact:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// ReportWriter collects a rendered report for an OutputSink. Renderers write
+// to it like to any [io.Writer]; Flush hands what was written to the sink.
+type ReportWriter struct {
+	sink   OutputSink
+	format string
+	buf    bytes.Buffer
+}
+
+// NewReportWriter creates a ReportWriter for a report in format.
+func NewReportWriter(sink OutputSink, format string) *ReportWriter {
+	return &ReportWriter{sink: sink, format: format}
+}
+
+// Write buffers p.
+func (w *ReportWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+// Flush writes the buffered report to the sink, if anything was written.
+func (w *ReportWriter) Flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	err := w.sink.WriteReport(w.format, w.buf.Bytes())
+	w.buf.Reset()
+
+	return err
+}
@@ -0,0 +1,199 @@
+package analyze_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// webhookRequest is one request received by a test webhook.
+type webhookRequest struct {
+	contentType string
+	auth        string
+	body        string
+}
+
+// newTestWebhook starts a server that records requests and answers with status.
+func newTestWebhook(t *testing.T, status int) (*httptest.Server, func() []webhookRequest) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []webhookRequest
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) //nolint:errcheck // test server.
+
+		mu.Lock()
+		requests = append(requests, webhookRequest{
+			contentType: r.Header.Get("Content-Type"),
+			auth:        r.Header.Get("Authorization"),
+			body:        string(body),
+		})
+		mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]webhookRequest(nil), requests...)
+	}
+}
+
+func TestWebhookSink_BatchesRecords(t *testing.T) {
+	t.Parallel()
+
+	server, received := newTestWebhook(t, http.StatusAccepted)
+
+	sink := analyze.NewWebhookSink(analyze.OutputSinkSpec{
+		URL:       server.URL,
+		Headers:   map[string]string{"Authorization": "Bearer secret"},
+		BatchSize: 2,
+	})
+
+	for i := range 3 {
+		require.NoError(t, sink.WriteTC(analyze.TC{Tick: i, Data: i}, "devs"))
+	}
+
+	require.NoError(t, sink.WriteTC(analyze.TC{Tick: 9}, "devs"), "nil data is skipped")
+	require.Len(t, received(), 1)
+
+	require.NoError(t, sink.Close())
+
+	requests := received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "application/x-ndjson", requests[0].contentType)
+	assert.Equal(t, "Bearer secret", requests[0].auth)
+	assert.Equal(t, 2, strings.Count(requests[0].body, "\n"))
+	assert.Equal(t, 1, strings.Count(requests[1].body, "\n"))
+	assert.Contains(t, requests[1].body, `"tick":2`)
+
+	require.NoError(t, sink.Close(), "closing again has nothing to send")
+	assert.Len(t, received(), 2)
+}
+
+func TestWebhookSink_WriteReport(t *testing.T) {
+	t.Parallel()
+
+	server, received := newTestWebhook(t, http.StatusOK)
+
+	sink := analyze.NewWebhookSink(analyze.OutputSinkSpec{URL: server.URL})
+	require.NoError(t, sink.WriteReport(analyze.FormatJSON, []byte(`{"ok":true}`)))
+	require.NoError(t, sink.Close())
+
+	requests := received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "application/json", requests[0].contentType)
+	assert.JSONEq(t, `{"ok":true}`, requests[0].body)
+}
+
+func TestWebhookSink_RejectedStatus(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newTestWebhook(t, http.StatusInternalServerError)
+
+	sink := analyze.NewWebhookSink(analyze.OutputSinkSpec{URL: server.URL})
+
+	err := sink.WriteReport(analyze.FormatYAML, []byte("a: 1\n"))
+	require.ErrorIs(t, err, analyze.ErrWebhookStatus)
+}
+
+func TestNewOutputSinks_FansOut(t *testing.T) {
+	t.Parallel()
+
+	server, received := newTestWebhook(t, http.StatusOK)
+	path := filepath.Join(t.TempDir(), "report.json")
+
+	var stdout bytes.Buffer
+
+	sinks, err := analyze.NewOutputSinks([]analyze.OutputSinkSpec{
+		{Type: analyze.OutputSinkStdout},
+		{Type: analyze.OutputSinkFile, Path: path},
+		{Type: analyze.OutputSinkWebhook, URL: server.URL},
+	}, &stdout)
+	require.NoError(t, err)
+
+	report := analyze.NewReportWriter(sinks, analyze.FormatJSON)
+	_, err = fmt.Fprint(report, `{"devs":1}`)
+	require.NoError(t, err)
+	require.NoError(t, report.Flush())
+	require.NoError(t, report.Flush(), "an empty report is not written")
+	require.NoError(t, sinks.Close())
+
+	assert.JSONEq(t, `{"devs":1}`, stdout.String())
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"devs":1}`, string(written))
+
+	require.Len(t, received(), 1)
+	assert.JSONEq(t, `{"devs":1}`, received()[0].body)
+}
+
+func TestNewOutputSink_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := analyze.NewOutputSink(analyze.OutputSinkSpec{Type: "kafka"}, io.Discard)
+	require.ErrorIs(t, err, analyze.ErrUnknownOutputSink)
+
+	_, err = analyze.NewOutputSink(analyze.OutputSinkSpec{Type: analyze.OutputSinkFile}, io.Discard)
+	require.ErrorIs(t, err, analyze.ErrOutputSinkTarget)
+
+	_, err = analyze.NewOutputSink(analyze.OutputSinkSpec{Type: analyze.OutputSinkWebhook}, io.Discard)
+	require.ErrorIs(t, err, analyze.ErrOutputSinkTarget)
+
+	_, err = analyze.NewOutputSinks([]analyze.OutputSinkSpec{
+		{Type: analyze.OutputSinkStdout},
+		{Type: "s3"},
+	}, io.Discard)
+	require.ErrorIs(t, err, analyze.ErrUnknownOutputSink)
+}
+
+// failingSink fails every write.
+type failingSink struct{ closed bool }
+
+var errSinkDown = errors.New("sink down")
+
+func (s *failingSink) WriteTC(analyze.TC, string) error { return errSinkDown }
+
+func (s *failingSink) WriteReport(string, []byte) error { return errSinkDown }
+
+func (s *failingSink) Close() error {
+	s.closed = true
+
+	return nil
+}
+
+func TestMultiSink_KeepsWritingPastFailures(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	failing := &failingSink{}
+	sinks := analyze.NewMultiSink(failing, analyze.NewWriterSink(&buf))
+
+	err := sinks.WriteTC(analyze.TC{Data: 1}, "devs")
+	require.ErrorIs(t, err, errSinkDown)
+	assert.Contains(t, buf.String(), `"analyzer":"devs"`)
+
+	require.NoError(t, sinks.Close())
+	assert.True(t, failing.closed)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/graphexport"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 )
 
@@ -20,11 +21,46 @@ func TestOutputHistoryResults_NDJSONReturnsNil(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := OutputHistoryResults(nil, nil, FormatNDJSON, &buf)
+	err := OutputHistoryResults(nil, nil, FormatNDJSON, &buf, 0)
 	require.NoError(t, err)
 	assert.Empty(t, buf.String(), "NDJSON format should produce no output from OutputHistoryResults")
 }
 
+type namedLeaf struct {
+	HistoryAnalyzer
+
+	flag string
+}
+
+func (n namedLeaf) Name() string { return n.flag }
+func (n namedLeaf) Flag() string { return n.flag }
+
+type graphLeaf struct {
+	namedLeaf
+}
+
+func (g graphLeaf) GenerateGraphs(_ Report) ([]graphexport.Graph, error) {
+	return []graphexport.Graph{{ID: "people", Nodes: []graphexport.Node{{ID: "0", Label: "alice", Weight: 1}}}}, nil
+}
+
+func TestOutputHistoryResults_GraphWritesGraphML(t *testing.T) {
+	t.Parallel()
+
+	couples := graphLeaf{namedLeaf{flag: "couples"}}
+	burndown := namedLeaf{flag: "burndown"}
+
+	var buf bytes.Buffer
+
+	err := OutputHistoryResults(
+		[]HistoryAnalyzer{couples, burndown},
+		map[HistoryAnalyzer]Report{couples: {}, burndown: {}},
+		FormatGraph, &buf, 0)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `<graph id="couples-people" edgedefault="undirected">`)
+	assert.Contains(t, buf.String(), `<data key="n_label">alice</data>`)
+	assert.NotContains(t, buf.String(), "burndown")
+}
+
 func TestBuildOrderedCommitMetaFromReports_WithMetadata(t *testing.T) {
 	t.Parallel()
 
@@ -18,6 +18,8 @@ type PreparedCommit struct {
 	Err       error
 	// AuthorID is the resolved author identifier.
 	AuthorID int
+	// CoAuthorIDs are the resolved Co-authored-by identifiers.
+	CoAuthorIDs []int
 	// Tick is the time tick for this commit.
 	Tick int
 }
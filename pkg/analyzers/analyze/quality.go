@@ -0,0 +1,84 @@
+package analyze
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReportKeyRunQuality is the Report key that carries the run's data-quality
+// summary (commits that failed and were skipped) as a *QualityStats.
+// Distinct from the "quality" analyzer, which measures code quality.
+const ReportKeyRunQuality = "run_quality"
+
+// CommitFailure records one commit that could not be processed by a stage.
+type CommitFailure struct {
+	// Hash is the hex hash of the failed commit.
+	Hash string `json:"hash"`
+
+	// Index is the commit's position in the analyzed sequence.
+	Index int `json:"index"`
+
+	// Stage is "pipeline" for blob/diff failures, otherwise the analyzer name.
+	Stage string `json:"stage"`
+
+	// Attempts is the number of times the stage was tried.
+	Attempts int `json:"attempts"`
+
+	// Error is the final error message.
+	Error string `json:"error"`
+}
+
+// QualityStats summarizes the commits that were skipped during a run.
+type QualityStats struct {
+	// SkippedCommits is the number of distinct commits with at least one failure.
+	SkippedCommits int `json:"skipped_commits"`
+
+	// Timeouts is the number of failures caused by an analyzer timeout.
+	Timeouts int `json:"timeouts,omitempty"`
+
+	// Disabled lists the analyzers that got stuck past their timeout and
+	// consumed no commits after that, in the order they got stuck.
+	Disabled []string `json:"disabled,omitempty"`
+
+	// Failures lists every recorded failure in processing order.
+	Failures []CommitFailure `json:"failures,omitempty"`
+}
+
+// QualityFromReports returns the first QualityStats found in the reports,
+// or nil when the run recorded no failures.
+func QualityFromReports(results map[HistoryAnalyzer]Report) *QualityStats {
+	for _, report := range results {
+		if qs, ok := report[ReportKeyRunQuality].(*QualityStats); ok && qs != nil {
+			return qs
+		}
+	}
+
+	return nil
+}
+
+// PrintQuality writes the quality section in the same style as PrintHeader.
+// Writes nothing when qs is nil or empty.
+func PrintQuality(writer io.Writer, qs *QualityStats) {
+	if qs == nil || len(qs.Failures) == 0 {
+		return
+	}
+
+	fmt.Fprintln(writer, ReportKeyRunQuality+":")
+	fmt.Fprintf(writer, "  skipped_commits: %d\n", qs.SkippedCommits)
+
+	if qs.Timeouts > 0 {
+		fmt.Fprintf(writer, "  timeouts: %d\n", qs.Timeouts)
+	}
+
+	if len(qs.Disabled) > 0 {
+		fmt.Fprintf(writer, "  disabled: [%s]\n", strings.Join(qs.Disabled, ", "))
+	}
+
+	fmt.Fprintln(writer, "  failures:")
+
+	for _, f := range qs.Failures {
+		fmt.Fprintf(writer, "    - {hash: %s, index: %d, stage: %s, attempts: %d, error: %q}\n",
+			f.Hash, f.Index, f.Stage, f.Attempts, f.Error)
+	}
+}
@@ -0,0 +1,51 @@
+package analyze
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintQuality_Empty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	PrintQuality(&buf, nil)
+	PrintQuality(&buf, &QualityStats{})
+
+	assert.Empty(t, buf.String())
+}
+
+func TestPrintQuality_ListsFailures(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	PrintQuality(&buf, &QualityStats{
+		SkippedCommits: 1,
+		Timeouts:       1,
+		Disabled:       []string{"sentiment"},
+		Failures: []CommitFailure{
+			{Hash: testHashA, Index: 7, Stage: "pipeline", Attempts: 3, Error: "corrupt blob"},
+		},
+	})
+
+	out := buf.String()
+	assert.Contains(t, out, "run_quality:\n")
+	assert.Contains(t, out, "skipped_commits: 1\n")
+	assert.Contains(t, out, "timeouts: 1\n")
+	assert.Contains(t, out, "disabled: [sentiment]\n")
+	assert.Contains(t, out, "hash: "+testHashA)
+	assert.Contains(t, out, `error: "corrupt blob"`)
+}
+
+func TestQualityFromReports(t *testing.T) {
+	t.Parallel()
+
+	qs := &QualityStats{SkippedCommits: 2}
+
+	assert.Nil(t, QualityFromReports(map[HistoryAnalyzer]Report{nil: {}}))
+	assert.Same(t, qs, QualityFromReports(map[HistoryAnalyzer]Report{nil: {ReportKeyRunQuality: qs}}))
+}
@@ -0,0 +1,30 @@
+package analyze
+
+import "math"
+
+// ReportKeySampleFactor is the Report key that carries the commit sampling
+// factor of the run, the float64 ratio of walked to analyzed commits. It is
+// only set when the history was sampled.
+const ReportKeySampleFactor = "sample_factor"
+
+// SampleFactor returns the commit sampling factor recorded in report, or 1
+// when the history was not sampled.
+func SampleFactor(report Report) float64 {
+	if f, ok := report[ReportKeySampleFactor].(float64); ok && f > 1 {
+		return f
+	}
+
+	return 1
+}
+
+// ScaleCount scales n, a count of sampled commits, by factor to estimate the
+// count over the full history. Counts derived from diffs, such as changed
+// lines, need no scaling: each sampled commit is diffed against the previous
+// sampled one, so its diff holds the changes of the commits skipped between.
+func ScaleCount(n int, factor float64) int {
+	if factor <= 1 {
+		return n
+	}
+
+	return int(math.Round(float64(n) * factor))
+}
@@ -0,0 +1,23 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleFactor(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 1.0, SampleFactor(Report{}), 0)
+	assert.InDelta(t, 1.0, SampleFactor(Report{ReportKeySampleFactor: 0.5}), 0)
+	assert.InDelta(t, 2.5, SampleFactor(Report{ReportKeySampleFactor: 2.5}), 0)
+}
+
+func TestScaleCount(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 7, ScaleCount(7, 1))
+	assert.Equal(t, 7, ScaleCount(7, 0))
+	assert.Equal(t, 18, ScaleCount(7, 2.5))
+}
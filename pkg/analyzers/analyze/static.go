@@ -12,6 +12,7 @@ import (
 	"runtime"
 	"sync"
 
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/graphexport"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
 )
@@ -53,6 +54,15 @@ func (svc *StaticService) AnalyzeFolder(ctx context.Context, rootPath string, an
 	analyzersToRun := svc.resolveAnalyzerList(analyzerList)
 	aggregators := svc.initAggregators(analyzersToRun)
 
+	for name, aggregator := range aggregators {
+		if rooted, ok := aggregator.(RootedAggregator); ok {
+			err := rooted.SetRoot(rootPath)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+
 	files, err := svc.collectFiles(rootPath)
 	if err != nil {
 		return nil, err
@@ -410,6 +420,36 @@ func (svc *StaticService) FormatCompact(results map[string]Report, noColor bool,
 	return svc.Renderer.RenderCompact(sections, noColor, writer)
 }
 
+// FormatGraph writes the graphs of the analyzers that implement GraphGenerator
+// as one GraphML document. Graph IDs are prefixed with the analyzer name.
+func (svc *StaticService) FormatGraph(analyzerNames []string, results map[string]Report, writer io.Writer) error {
+	var graphs []graphexport.Graph
+
+	for _, analyzerName := range analyzerNames {
+		report, ok := results[analyzerName]
+		if !ok {
+			continue
+		}
+
+		gen, ok := svc.FindAnalyzer(analyzerName).(GraphGenerator)
+		if !ok {
+			continue
+		}
+
+		generated, err := gen.GenerateGraphs(report)
+		if err != nil {
+			return fmt.Errorf("failed to generate graphs for %s: %w", analyzerName, err)
+		}
+
+		for _, g := range generated {
+			g.ID = analyzerName + "-" + g.ID
+			graphs = append(graphs, g)
+		}
+	}
+
+	return graphexport.WriteGraphML(writer, graphs...)
+}
+
 // FormatPerAnalyzer renders results using per-analyzer formatters (YAML, plot, or binary).
 func (svc *StaticService) FormatPerAnalyzer(
 	analyzerNames []string,
@@ -485,6 +525,8 @@ func (svc *StaticService) RunAndFormat(
 		return svc.FormatPerAnalyzer(analyzerNames, results, format, writer)
 	case FormatText:
 		return svc.FormatText(results, verbose, noColor, writer)
+	case FormatGraph:
+		return svc.FormatGraph(analyzerNames, results, writer)
 	default:
 		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
 	}
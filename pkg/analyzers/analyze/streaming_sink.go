@@ -13,7 +13,10 @@ import (
 type TCSink func(tc TC, analyzerFlag string) error
 
 // NDJSONLine is the JSON structure for one NDJSON output line.
+// Hash and Analyzer identify a record: each analyzer emits at most one
+// record per commit. Seq orders records and is omitted when zero.
 type NDJSONLine struct {
+	Seq       uint64 `json:"seq,omitempty"`
 	Hash      string `json:"hash"`
 	Tick      int    `json:"tick"`
 	AuthorID  int    `json:"author_id"`
@@ -42,12 +45,26 @@ func (s *StreamingSink) WriteTC(tc TC, analyzerFlag string) error {
 		return nil
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.encoder.Encode(newNDJSONLine(tc, analyzerFlag))
+	if err != nil {
+		return fmt.Errorf("ndjson encode: %w", err)
+	}
+
+	return nil
+}
+
+// newNDJSONLine builds the NDJSON record of a TC.
+func newNDJSONLine(tc TC, analyzerFlag string) NDJSONLine {
 	var ts string
 	if !tc.Timestamp.IsZero() {
 		ts = tc.Timestamp.Format(time.RFC3339)
 	}
 
-	line := NDJSONLine{
+	return NDJSONLine{
+		Seq:       tc.Seq,
 		Hash:      tc.CommitHash.String(),
 		Tick:      tc.Tick,
 		AuthorID:  tc.AuthorID,
@@ -55,14 +72,4 @@ func (s *StreamingSink) WriteTC(tc TC, analyzerFlag string) error {
 		Analyzer:  analyzerFlag,
 		Data:      tc.Data,
 	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	err := s.encoder.Encode(line)
-	if err != nil {
-		return fmt.Errorf("ndjson encode: %w", err)
-	}
-
-	return nil
 }
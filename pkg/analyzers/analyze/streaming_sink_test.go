@@ -48,6 +48,22 @@ func TestStreamingSink_WriteTC_SingleLine(t *testing.T) {
 	assert.NotNil(t, line["data"])
 }
 
+func TestStreamingSink_WriteTC_Seq(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	sink := analyze.NewStreamingSink(&buf)
+
+	require.NoError(t, sink.WriteTC(analyze.TC{Seq: 7, Data: 1}, "devs"))
+	require.NoError(t, sink.WriteTC(analyze.TC{Data: 1}, "devs"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], `{"seq":7,"hash":`), lines[0])
+	assert.NotContains(t, lines[1], `"seq"`, "zero seq is omitted")
+}
+
 func TestStreamingSink_WriteTC_NilData(t *testing.T) {
 	t.Parallel()
 
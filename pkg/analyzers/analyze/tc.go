@@ -23,6 +23,11 @@ type TC struct {
 	// Timestamp is the commit's author time.
 	Timestamp time.Time
 
+	// Seq is the output sequence number stamped by the Runner in TCSink
+	// (NDJSON) mode. It grows by one per emitted record in emission order and
+	// continues across checkpoint resumes. Zero outside TCSink mode.
+	Seq uint64
+
 	// Data carries the analyzer-specific per-commit payload.
 	// The concrete type is defined by each analyzer.
 	Data any
@@ -40,7 +40,10 @@ type MergedTimeSeries struct {
 	Version       string             `json:"version"`
 	TickSizeHours float64            `json:"tick_size_hours"`
 	Analyzers     []string           `json:"analyzers"`
+	Seed          uint64             `json:"seed"`
 	Commits       []MergedCommitData `json:"commits"`
+	// Forecasts projects key series ahead; see SetForecastHorizon.
+	Forecasts []SeriesForecast `json:"forecasts,omitempty"`
 }
 
 // TimeSeriesModelVersion is the schema version for unified time-series output.
@@ -77,10 +80,13 @@ type AnalyzerData struct {
 // BuildMergedTimeSeriesDirect builds a unified time-series from pre-extracted
 // per-analyzer commit data. Callers collect AnalyzerData via
 // CommitTimeSeriesProvider.ExtractCommitTimeSeries on each leaf analyzer.
+// The seed of the run is recorded as is, so that sampled and randomized
+// results can be reproduced.
 func BuildMergedTimeSeriesDirect(
 	active []AnalyzerData,
 	commitMeta []CommitMeta,
 	tickSizeHours float64,
+	seed uint64,
 ) *MergedTimeSeries {
 	if tickSizeHours <= 0 {
 		tickSizeHours = defaultTickSizeHours
@@ -98,6 +104,7 @@ func BuildMergedTimeSeriesDirect(
 		Version:       TimeSeriesModelVersion,
 		TickSizeHours: tickSizeHours,
 		Analyzers:     analyzerNames,
+		Seed:          seed,
 		Commits:       commits,
 	}
 }
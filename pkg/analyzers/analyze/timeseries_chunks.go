@@ -0,0 +1,218 @@
+package analyze
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ChunkedTimeSeries writes the partitioned time series of a streaming run
+// chunk by chunk. Observe feeds the TCs of the time-series leaves into fresh
+// per-chunk aggregators; FlushChunk turns them into the commits of the chunk,
+// hands those to a PartitionWriter and drops them, so only one chunk of
+// commits is held at a time.
+type ChunkedTimeSeries struct {
+	// AuthorName resolves the author identity of a commit. When nil, commits
+	// carry no author.
+	AuthorName func(authorID int) string
+
+	leaves []seriesLeaf
+	byFlag map[string]HistoryAnalyzer
+	writer *PartitionWriter
+	seed   uint64
+
+	mu     sync.Mutex
+	aggs   map[string]Aggregator
+	meta   []CommitMeta
+	hashes map[string]struct{}
+	err    error
+}
+
+// seriesLeaf is a leaf that contributes to the time series.
+type seriesLeaf struct {
+	HistoryAnalyzer
+	CommitTimeSeriesProvider
+}
+
+// NewChunkedTimeSeries returns a ChunkedTimeSeries of the leaves that
+// implement CommitTimeSeriesProvider, writing to writer.
+func NewChunkedTimeSeries(leaves []HistoryAnalyzer, writer *PartitionWriter, seed uint64) *ChunkedTimeSeries {
+	c := &ChunkedTimeSeries{
+		byFlag: make(map[string]HistoryAnalyzer),
+		writer: writer,
+		seed:   seed,
+	}
+
+	for _, leaf := range leaves {
+		if provider, ok := leaf.(CommitTimeSeriesProvider); ok {
+			c.leaves = append(c.leaves, seriesLeaf{leaf, provider})
+			c.byFlag[leaf.Flag()] = leaf
+		}
+	}
+
+	slices.SortFunc(c.leaves, func(a, b seriesLeaf) int { return cmp.Compare(a.Flag(), b.Flag()) })
+
+	c.reset()
+
+	return c
+}
+
+// Observe adds the TC of the leaf with the given flag to the current chunk.
+// It has the signature of a TCSink, so it can serve as a TCObserver, and is
+// safe for concurrent use.
+func (c *ChunkedTimeSeries) Observe(tc TC, flag string) error {
+	leaf, ok := c.byFlag[flag]
+	if !ok || tc.Data == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	agg := c.aggs[flag]
+	if agg == nil {
+		agg = leaf.NewAggregator(AggregatorOptions{})
+		if agg == nil {
+			return nil
+		}
+
+		c.aggs[flag] = agg
+	}
+
+	err := agg.Add(tc)
+	if err != nil {
+		err = fmt.Errorf("time series of %s: %w", flag, err)
+		c.fail(err)
+
+		return err
+	}
+
+	c.recordMeta(tc)
+
+	return nil
+}
+
+// FlushChunk writes the commits observed since the previous flush and
+// releases them. The first error is kept and returned by Close, so
+// FlushChunk can run from a progress callback.
+func (c *ChunkedTimeSeries) FlushChunk(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fail(c.flush(ctx))
+}
+
+// Close flushes the last chunk, closes the partition files and writes the
+// manifest with the given forecasts.
+func (c *ChunkedTimeSeries) Close(ctx context.Context, forecasts []SeriesForecast) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fail(c.flush(ctx))
+
+	if c.err != nil {
+		c.writer.Abort()
+
+		return c.err
+	}
+
+	return c.writer.Close(forecasts)
+}
+
+// Abort drops the current chunk and closes the partition files without
+// writing the manifest.
+func (c *ChunkedTimeSeries) Abort() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closeAggregators(c.aggs)
+	c.reset()
+	c.writer.Abort()
+}
+
+// flush builds the time series of the current chunk and writes it.
+func (c *ChunkedTimeSeries) flush(ctx context.Context) error {
+	aggs, meta := c.aggs, c.meta
+	c.reset()
+
+	defer c.closeAggregators(aggs)
+
+	if c.err != nil || len(meta) == 0 {
+		return nil
+	}
+
+	var active []AnalyzerData
+
+	for _, leaf := range c.leaves {
+		agg := aggs[leaf.Flag()]
+		if agg == nil {
+			continue
+		}
+
+		ticks, err := agg.FlushAllTicks()
+		if err != nil {
+			return fmt.Errorf("time series of %s: %w", leaf.Flag(), err)
+		}
+
+		report, err := leaf.ReportFromTICKs(ctx, ticks)
+		if err != nil {
+			return fmt.Errorf("time series of %s: %w", leaf.Flag(), err)
+		}
+
+		data := leaf.ExtractCommitTimeSeries(report)
+		if len(data) > 0 {
+			active = append(active, AnalyzerData{Flag: leaf.Flag(), Data: data})
+		}
+	}
+
+	slices.SortStableFunc(meta, func(a, b CommitMeta) int { return cmp.Compare(a.Tick, b.Tick) })
+
+	return c.writer.WriteChunk(BuildMergedTimeSeriesDirect(active, meta, 0, c.seed))
+}
+
+// recordMeta records the metadata of the commit of tc the first time one of
+// its TCs is observed.
+func (c *ChunkedTimeSeries) recordMeta(tc TC) {
+	hash := tc.CommitHash.String()
+	if _, seen := c.hashes[hash]; seen {
+		return
+	}
+
+	c.hashes[hash] = struct{}{}
+
+	meta := CommitMeta{Hash: hash, Tick: tc.Tick}
+
+	if !tc.Timestamp.IsZero() {
+		meta.Timestamp = tc.Timestamp.Format(time.RFC3339)
+	}
+
+	if c.AuthorName != nil {
+		meta.Author = c.AuthorName(tc.AuthorID)
+	}
+
+	c.meta = append(c.meta, meta)
+}
+
+func (c *ChunkedTimeSeries) reset() {
+	c.aggs = make(map[string]Aggregator)
+	c.meta = nil
+	c.hashes = make(map[string]struct{})
+}
+
+func (c *ChunkedTimeSeries) closeAggregators(aggs map[string]Aggregator) {
+	for flag, agg := range aggs {
+		c.fail(agg.Close())
+
+		delete(aggs, flag)
+	}
+}
+
+// fail keeps the first error.
+func (c *ChunkedTimeSeries) fail(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
@@ -0,0 +1,175 @@
+package analyze
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// listAggregator keeps the added TCs in one TICK.
+type listAggregator struct {
+	Aggregator
+
+	tcs    []TC
+	closed *int
+}
+
+func (a *listAggregator) Add(tc TC) error {
+	a.tcs = append(a.tcs, tc)
+
+	return nil
+}
+
+func (a *listAggregator) FlushAllTicks() ([]TICK, error) {
+	return []TICK{{Data: a.tcs}}, nil
+}
+
+func (a *listAggregator) Close() error {
+	*a.closed++
+
+	return nil
+}
+
+// seriesTestLeaf reports the Data of every TC as its commit's time series.
+type seriesTestLeaf struct {
+	namedLeaf
+
+	closed *int
+}
+
+func (l seriesTestLeaf) NewAggregator(_ AggregatorOptions) Aggregator {
+	return &listAggregator{closed: l.closed}
+}
+
+func (l seriesTestLeaf) ReportFromTICKs(_ context.Context, ticks []TICK) (Report, error) {
+	return Report{"tcs": ticks[0].Data}, nil
+}
+
+func (l seriesTestLeaf) ExtractCommitTimeSeries(report Report) map[string]any {
+	tcs, _ := report["tcs"].([]TC) //nolint:errcheck // the test report always holds TCs.
+	data := make(map[string]any, len(tcs))
+
+	for _, tc := range tcs {
+		data[tc.CommitHash.String()] = tc.Data
+	}
+
+	return data
+}
+
+func readPartitionHashes(t *testing.T, path string) []string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	var hashes []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line MergedCommitData
+
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+
+		hashes = append(hashes, line.Hash)
+	}
+
+	require.NoError(t, scanner.Err())
+
+	return hashes
+}
+
+func TestChunkedTimeSeries_WritesEveryChunk(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	closed := 0
+	devs := seriesTestLeaf{namedLeaf: namedLeaf{flag: "devs"}, closed: &closed}
+	burndown := namedLeaf{flag: "burndown"}
+
+	writer, err := NewPartitionWriter(PartitionMonth, dir, 9)
+	require.NoError(t, err)
+
+	series := NewChunkedTimeSeries([]HistoryAnalyzer{devs, burndown}, writer, 9)
+	series.AuthorName = func(id int) string { return []string{"alice", "bob"}[id] }
+
+	commit := func(hash string, day, author int) TC {
+		return TC{
+			CommitHash: gitlib.NewHash(hash),
+			Tick:       day,
+			AuthorID:   author,
+			Timestamp:  time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC),
+			Data:       map[string]int{"day": day},
+		}
+	}
+
+	jan := commit(testHashA, 20, 0)
+	feb := commit(testHashB, 40, 1)
+
+	require.NoError(t, series.Observe(jan, "devs"))
+	require.NoError(t, series.Observe(jan, "burndown"), "leaves without a time series are ignored")
+	series.FlushChunk(context.Background())
+
+	assert.Equal(t, 1, closed, "the chunk's aggregator is released after the flush")
+	assert.Equal(t, []string{testHashA}, readPartitionHashes(t, filepath.Join(dir, "2024-01.ndjson")),
+		"the first chunk is written before the run ends")
+
+	require.NoError(t, series.Observe(feb, "devs"))
+	require.NoError(t, series.Close(context.Background(), nil))
+
+	manifest, err := os.ReadFile(filepath.Join(dir, TimeSeriesManifestFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), `"2024-02.ndjson"`)
+	assert.Contains(t, string(manifest), `"devs"`)
+
+	assert.Equal(t, []string{testHashB}, readPartitionHashes(t, filepath.Join(dir, "2024-02.ndjson")))
+
+	data, err := os.ReadFile(filepath.Join(dir, "2024-02.ndjson"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"author":"bob"`)
+}
+
+func TestPartitionWriter_ContinuesClosedPartition(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	writer, err := NewPartitionWriter(PartitionTick, dir, 0)
+	require.NoError(t, err)
+
+	chunk := func(hash string, tick int) *MergedTimeSeries {
+		return &MergedTimeSeries{
+			Analyzers: []string{"devs"},
+			Commits:   []MergedCommitData{{Hash: hash, Tick: tick, Analyzers: map[string]any{"devs": tick}}},
+		}
+	}
+
+	require.NoError(t, writer.WriteChunk(chunk("a", 1)))
+	require.NoError(t, writer.WriteChunk(chunk("b", 1)))
+	require.NoError(t, writer.WriteChunk(chunk("c", 2)))
+	require.NoError(t, writer.WriteChunk(chunk("d", 1)))
+	require.NoError(t, writer.Close(nil))
+
+	assert.Equal(t, []string{"a", "b"}, readPartitionHashes(t, filepath.Join(dir, "tick-000001.ndjson")),
+		"a partition stays open across consecutive chunks")
+	assert.Equal(t, []string{"d"}, readPartitionHashes(t, filepath.Join(dir, "tick-000001.1.ndjson")),
+		"a closed partition continues in a new file")
+
+	data, err := os.ReadFile(filepath.Join(dir, TimeSeriesManifestFile))
+	require.NoError(t, err)
+
+	var manifest TimeSeriesManifest
+
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, []string{"tick-000001.ndjson", "tick-000002.ndjson", "tick-000001.1.ndjson"}, manifest.Files)
+}
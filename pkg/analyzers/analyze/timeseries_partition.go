@@ -0,0 +1,295 @@
+package analyze
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/storage"
+)
+
+// OutputPartition selects how partitioned time-series output is split into files.
+type OutputPartition string
+
+const (
+	// PartitionNone writes the time series as a single JSON document (default).
+	PartitionNone OutputPartition = ""
+
+	// PartitionTick writes one file per tick.
+	PartitionTick OutputPartition = "tick"
+
+	// PartitionMonth writes one file per calendar month (UTC) of the commit timestamp.
+	PartitionMonth OutputPartition = "month"
+)
+
+// TimeSeriesManifestFile is the name of the manifest written next to the partition files.
+const TimeSeriesManifestFile = "manifest.json"
+
+// partitionFileExt is the extension of partition files; each holds one commit per line.
+const partitionFileExt = ".ndjson"
+
+// unknownPartition is the partition key for commits without a parsable timestamp.
+const unknownPartition = "unknown"
+
+// ErrUnknownOutputPartition is returned for unrecognized --output-partition values.
+var ErrUnknownOutputPartition = errors.New("unknown output partition")
+
+// ParseOutputPartition parses a partition name. Empty input maps to PartitionNone.
+func ParseOutputPartition(s string) (OutputPartition, error) {
+	switch partition := OutputPartition(s); partition {
+	case PartitionNone, PartitionTick, PartitionMonth:
+		return partition, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want tick or month)", ErrUnknownOutputPartition, s)
+	}
+}
+
+// TimeSeriesManifest describes a partitioned time-series output directory.
+// It carries the MergedTimeSeries header fields that partition files omit.
+type TimeSeriesManifest struct {
+	Version       string           `json:"version"`
+	TickSizeHours float64          `json:"tick_size_hours"`
+	Analyzers     []string         `json:"analyzers"`
+	Seed          uint64           `json:"seed"`
+	Partition     OutputPartition  `json:"partition"`
+	Files         []string         `json:"files"`
+	Forecasts     []SeriesForecast `json:"forecasts,omitempty"`
+}
+
+// WritePartitionedTimeSeries writes ts into dir as one NDJSON file per
+// partition plus a manifest. Files are listed in the manifest in the order of
+// their first commit. Existing files with the same names are overwritten. dir
+// may be an object storage URL; see package storage.
+func WritePartitionedTimeSeries(ts *MergedTimeSeries, partition OutputPartition, dir string) error {
+	w, err := NewPartitionWriter(partition, dir, ts.Seed)
+	if err != nil {
+		return err
+	}
+
+	err = w.WriteChunk(ts)
+	if err != nil {
+		w.Abort()
+
+		return err
+	}
+
+	return w.Close(ts.Forecasts)
+}
+
+// PartitionWriter writes a partitioned time series chunk by chunk, so the
+// commits of a chunk can be released once written. A partition file stays
+// open while consecutive chunks add commits to it and is closed by the first
+// chunk that does not. Commits of a closed partition that arrive later, such
+// as commits with out-of-order timestamps, go to a continuation file
+// <key>.<n>.ndjson listed after it in the manifest.
+type PartitionWriter struct {
+	partition OutputPartition
+	dir       string
+	manifest  TimeSeriesManifest
+	open      map[string]*partitionFile
+	parts     map[string]int
+}
+
+// partitionFile is an open partition file.
+type partitionFile struct {
+	file    io.WriteCloser
+	buf     *bufio.Writer
+	encoder *json.Encoder
+	name    string
+}
+
+// NewPartitionWriter creates dir and returns a writer of its partition
+// files. dir may be an object storage URL; see package storage.
+func NewPartitionWriter(partition OutputPartition, dir string, seed uint64) (*PartitionWriter, error) {
+	if partition == PartitionNone {
+		return nil, fmt.Errorf("%w: %q (want tick or month)", ErrUnknownOutputPartition, partition)
+	}
+
+	err := storage.MkdirAll(dir)
+	if err != nil {
+		return nil, fmt.Errorf("create output dir: %w", err)
+	}
+
+	return &PartitionWriter{
+		partition: partition,
+		dir:       dir,
+		manifest: TimeSeriesManifest{
+			Version:       TimeSeriesModelVersion,
+			TickSizeHours: defaultTickSizeHours,
+			Seed:          seed,
+			Partition:     partition,
+			Files:         []string{},
+		},
+		open:  make(map[string]*partitionFile),
+		parts: make(map[string]int),
+	}, nil
+}
+
+// WriteChunk appends the commits of one chunk's time series to their
+// partition files and flushes them, then closes the files of the partitions
+// the chunk did not touch.
+func (w *PartitionWriter) WriteChunk(ts *MergedTimeSeries) error {
+	w.addHeader(ts)
+
+	touched := make(map[string]bool)
+
+	for _, commit := range ts.Commits {
+		key := partitionKey(commit, w.partition)
+		touched[key] = true
+
+		pf, err := w.file(key)
+		if err != nil {
+			return err
+		}
+
+		err = pf.encoder.Encode(commit)
+		if err != nil {
+			return fmt.Errorf("encode partition %s: %w", pf.name, err)
+		}
+	}
+
+	for key, pf := range w.open {
+		if touched[key] {
+			err := pf.buf.Flush()
+			if err != nil {
+				return fmt.Errorf("write partition %s: %w", pf.name, err)
+			}
+
+			continue
+		}
+
+		err := w.closeFile(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close closes the open partition files and writes the manifest with the
+// given forecasts.
+func (w *PartitionWriter) Close(forecasts []SeriesForecast) error {
+	for key := range w.open {
+		err := w.closeFile(key)
+		if err != nil {
+			w.Abort()
+
+			return err
+		}
+	}
+
+	w.manifest.Forecasts = forecasts
+
+	return writeManifest(storage.Join(w.dir, TimeSeriesManifestFile), w.manifest)
+}
+
+// Abort closes the open partition files without flushing them or writing
+// the manifest.
+func (w *PartitionWriter) Abort() {
+	for key, pf := range w.open {
+		pf.file.Close()
+		delete(w.open, key)
+	}
+}
+
+// addHeader records the header fields of a chunk's time series in the
+// manifest. Analyzers are added in the order they first appear.
+func (w *PartitionWriter) addHeader(ts *MergedTimeSeries) {
+	if ts.Version != "" {
+		w.manifest.Version = ts.Version
+	}
+
+	if ts.TickSizeHours > 0 {
+		w.manifest.TickSizeHours = ts.TickSizeHours
+	}
+
+	for _, name := range ts.Analyzers {
+		if !slices.Contains(w.manifest.Analyzers, name) {
+			w.manifest.Analyzers = append(w.manifest.Analyzers, name)
+		}
+	}
+}
+
+// file returns the open file of the partition key, creating it if needed.
+func (w *PartitionWriter) file(key string) (*partitionFile, error) {
+	if pf, ok := w.open[key]; ok {
+		return pf, nil
+	}
+
+	name := key + partitionFileExt
+	if part := w.parts[key]; part > 0 {
+		name = fmt.Sprintf("%s.%d%s", key, part, partitionFileExt)
+	}
+
+	f, err := storage.Create(storage.Join(w.dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("create partition file: %w", err)
+	}
+
+	buf := bufio.NewWriter(f)
+	pf := &partitionFile{file: f, buf: buf, encoder: json.NewEncoder(buf), name: name}
+
+	w.open[key] = pf
+	w.parts[key]++
+	w.manifest.Files = append(w.manifest.Files, name)
+
+	return pf, nil
+}
+
+// closeFile flushes and closes the open file of the partition key.
+func (w *PartitionWriter) closeFile(key string) error {
+	pf := w.open[key]
+	delete(w.open, key)
+
+	err := pf.buf.Flush()
+	if err != nil {
+		pf.file.Close()
+
+		return fmt.Errorf("write partition %s: %w", pf.name, err)
+	}
+
+	err = pf.file.Close()
+	if err != nil {
+		return fmt.Errorf("close partition %s: %w", pf.name, err)
+	}
+
+	return nil
+}
+
+// partitionKey returns the file name stem of the partition holding commit.
+func partitionKey(commit MergedCommitData, partition OutputPartition) string {
+	if partition == PartitionTick {
+		return fmt.Sprintf("tick-%06d", commit.Tick)
+	}
+
+	ts, err := time.Parse(time.RFC3339, commit.Timestamp)
+	if err != nil {
+		return unknownPartition
+	}
+
+	return ts.UTC().Format("2006-01")
+}
+
+func writeManifest(path string, manifest TimeSeriesManifest) error {
+	f, err := storage.Create(path)
+	if err != nil {
+		return fmt.Errorf("create timeseries manifest: %w", err)
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(manifest)
+	if err != nil {
+		f.Close()
+
+		return fmt.Errorf("encode timeseries manifest: %w", err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,124 @@
+package analyze_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func partitionTestSeries() *analyze.MergedTimeSeries {
+	commit := func(hash, ts string, tick int) analyze.MergedCommitData {
+		return analyze.MergedCommitData{
+			Hash: hash, Timestamp: ts, Tick: tick,
+			Analyzers: map[string]any{"devs": map[string]any{"added": tick}},
+		}
+	}
+
+	return &analyze.MergedTimeSeries{
+		Version:       analyze.TimeSeriesModelVersion,
+		TickSizeHours: 24,
+		Analyzers:     []string{"devs"},
+		Seed:          42,
+		Commits: []analyze.MergedCommitData{
+			commit("a", "2024-01-30T10:00:00Z", 0),
+			commit("b", "2024-02-01T03:00:00+05:00", 2),
+			commit("c", "2024-02-15T10:00:00Z", 16),
+			commit("d", "", 16),
+		},
+	}
+}
+
+func readPartition(t *testing.T, path string) []string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	var hashes []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]any
+
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		assert.Contains(t, line, "devs")
+
+		hash, ok := line["hash"].(string)
+		require.True(t, ok)
+
+		hashes = append(hashes, hash)
+	}
+
+	require.NoError(t, scanner.Err())
+
+	return hashes
+}
+
+func readManifest(t *testing.T, dir string) analyze.TimeSeriesManifest {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(dir, analyze.TimeSeriesManifestFile))
+	require.NoError(t, err)
+
+	var manifest analyze.TimeSeriesManifest
+
+	require.NoError(t, json.Unmarshal(data, &manifest))
+
+	return manifest
+}
+
+func TestParseOutputPartition(t *testing.T) {
+	t.Parallel()
+
+	partition, err := analyze.ParseOutputPartition("")
+	require.NoError(t, err)
+	assert.Equal(t, analyze.PartitionNone, partition)
+
+	partition, err = analyze.ParseOutputPartition("month")
+	require.NoError(t, err)
+	assert.Equal(t, analyze.PartitionMonth, partition)
+
+	_, err = analyze.ParseOutputPartition("week")
+	require.ErrorIs(t, err, analyze.ErrUnknownOutputPartition)
+}
+
+func TestWritePartitionedTimeSeries_ByMonth(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "out")
+
+	require.NoError(t, analyze.WritePartitionedTimeSeries(partitionTestSeries(), analyze.PartitionMonth, dir))
+
+	manifest := readManifest(t, dir)
+	assert.Equal(t, analyze.TimeSeriesModelVersion, manifest.Version)
+	assert.Equal(t, []string{"devs"}, manifest.Analyzers)
+	assert.Equal(t, uint64(42), manifest.Seed)
+	assert.Equal(t, analyze.PartitionMonth, manifest.Partition)
+	// Commit b is 2024-01-31 in UTC; commit d has no timestamp.
+	assert.Equal(t, []string{"2024-01.ndjson", "2024-02.ndjson", "unknown.ndjson"}, manifest.Files)
+
+	assert.Equal(t, []string{"a", "b"}, readPartition(t, filepath.Join(dir, "2024-01.ndjson")))
+	assert.Equal(t, []string{"c"}, readPartition(t, filepath.Join(dir, "2024-02.ndjson")))
+	assert.Equal(t, []string{"d"}, readPartition(t, filepath.Join(dir, "unknown.ndjson")))
+}
+
+func TestWritePartitionedTimeSeries_ByTick(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	require.NoError(t, analyze.WritePartitionedTimeSeries(partitionTestSeries(), analyze.PartitionTick, dir))
+
+	manifest := readManifest(t, dir)
+	assert.Equal(t, []string{"tick-000000.ndjson", "tick-000002.ndjson", "tick-000016.ndjson"}, manifest.Files)
+	assert.Equal(t, []string{"c", "d"}, readPartition(t, filepath.Join(dir, "tick-000016.ndjson")))
+}
@@ -73,7 +73,7 @@ func TestMergedCommitData_MarshalJSON_Flattened(t *testing.T) {
 func TestBuildMergedTimeSeriesDirect_EmptyData(t *testing.T) {
 	t.Parallel()
 
-	ts := analyze.BuildMergedTimeSeriesDirect(nil, nil, 0)
+	ts := analyze.BuildMergedTimeSeriesDirect(nil, nil, 0, 0)
 
 	if ts.Version != analyze.TimeSeriesModelVersion {
 		t.Errorf("expected version=%s, got %s", analyze.TimeSeriesModelVersion, ts.Version)
@@ -110,7 +110,7 @@ func TestBuildMergedTimeSeriesDirect_SingleAnalyzer(t *testing.T) {
 		{Hash: "bbb222", Timestamp: "2024-01-02T00:00:00Z", Author: "bob", Tick: 1},
 	}
 
-	ts := analyze.BuildMergedTimeSeriesDirect(active, meta, 0)
+	ts := analyze.BuildMergedTimeSeriesDirect(active, meta, 0, 0)
 
 	if len(ts.Analyzers) != 1 {
 		t.Fatalf("expected 1 analyzer, got %d", len(ts.Analyzers))
@@ -167,7 +167,7 @@ func TestBuildMergedTimeSeriesDirect_MultiAnalyzer(t *testing.T) {
 		{Hash: "commit1", Timestamp: "2024-01-01T00:00:00Z", Author: "alice", Tick: 0},
 	}
 
-	ts := analyze.BuildMergedTimeSeriesDirect(active, meta, 0)
+	ts := analyze.BuildMergedTimeSeriesDirect(active, meta, 0, 0)
 
 	if len(ts.Commits) != 1 {
 		t.Fatalf("expected 1 commit, got %d", len(ts.Commits))
@@ -191,13 +191,23 @@ func TestBuildMergedTimeSeriesDirect_MultiAnalyzer(t *testing.T) {
 func TestBuildMergedTimeSeriesDirect_CustomTickSize(t *testing.T) {
 	t.Parallel()
 
-	ts := analyze.BuildMergedTimeSeriesDirect(nil, nil, 12)
+	ts := analyze.BuildMergedTimeSeriesDirect(nil, nil, 12, 0)
 
 	if ts.TickSizeHours != 12 {
 		t.Errorf("expected tick_size_hours=12, got %f", ts.TickSizeHours)
 	}
 }
 
+func TestBuildMergedTimeSeriesDirect_RecordsSeed(t *testing.T) {
+	t.Parallel()
+
+	ts := analyze.BuildMergedTimeSeriesDirect(nil, nil, 0, 42)
+
+	if ts.Seed != 42 {
+		t.Errorf("expected seed=42, got %d", ts.Seed)
+	}
+}
+
 func TestBuildMergedTimeSeriesDirect_CommitOrderFollowsMeta(t *testing.T) {
 	t.Parallel()
 
@@ -219,7 +229,7 @@ func TestBuildMergedTimeSeriesDirect_CommitOrderFollowsMeta(t *testing.T) {
 		{Hash: "commit3", Tick: 2},
 	}
 
-	ts := analyze.BuildMergedTimeSeriesDirect(active, meta, 0)
+	ts := analyze.BuildMergedTimeSeriesDirect(active, meta, 0, 0)
 
 	if len(ts.Commits) != 3 {
 		t.Fatalf("expected 3 commits, got %d", len(ts.Commits))
@@ -255,7 +265,7 @@ func TestBuildMergedTimeSeriesDirect_SkipsCommitsNotInMeta(t *testing.T) {
 		{Hash: "known", Tick: 0},
 	}
 
-	ts := analyze.BuildMergedTimeSeriesDirect(active, meta, 0)
+	ts := analyze.BuildMergedTimeSeriesDirect(active, meta, 0, 0)
 
 	// Only "known" should appear since "unknown" is not in meta.
 	if len(ts.Commits) != 1 {
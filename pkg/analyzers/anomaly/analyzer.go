@@ -82,6 +82,7 @@ func NewAnalyzer() *Analyzer {
 			Mode:        analyze.ModeHistory,
 		},
 		Sequential: false,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, NeedsDiffs: true, Memory: analyze.MemoryLow},
 		ConfigOptions: []pipeline.ConfigurationOption{
 			{
 				Name:        ConfigAnomalyThreshold,
@@ -7,6 +7,51 @@ import (
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 )
 
+// DerivedExternalAnomalies is the name of the derived metric that detects
+// anomalies in other analyzers' time series.
+const DerivedExternalAnomalies = "anomaly.external"
+
+// RegisterDerivedMetrics registers the anomaly derived metrics with the analyze package.
+func RegisterDerivedMetrics() {
+	analyze.RegisterDerivedMetric(analyze.DerivedMetric{
+		Name:     DerivedExternalAnomalies,
+		Requires: []string{analyzerNameAnomaly},
+		Compute:  enrichExternal,
+	})
+}
+
+// enrichExternal runs EnrichFromReports with the window and threshold the
+// anomaly analyzer recorded in its own report.
+func enrichExternal(reports map[string]analyze.Report) error {
+	anomalyReport := reports[analyzerNameAnomaly]
+
+	otherReports := make(map[string]analyze.Report, len(reports))
+
+	for flag, rep := range reports {
+		if flag != analyzerNameAnomaly {
+			otherReports[flag] = rep
+		}
+	}
+
+	if len(otherReports) == 0 {
+		return nil
+	}
+
+	window := DefaultAnomalyWindowSize
+	if w, ok := anomalyReport["window_size"].(int); ok && w > 0 {
+		window = w
+	}
+
+	threshold := DefaultAnomalyThreshold
+	if th, ok := anomalyReport["threshold"].(float32); ok && th > 0 {
+		threshold = th
+	}
+
+	EnrichFromReports(anomalyReport, otherReports, window, float64(threshold))
+
+	return nil
+}
+
 // EnrichFromReports runs Z-score anomaly detection on external analyzer time
 // series and injects the results into the anomaly report. It iterates over
 // registered TimeSeriesExtractors, calls ComputeZScores on each dimension,
@@ -192,3 +192,38 @@ func TestDetectExternalAnomalies_MismatchedLengths(t *testing.T) {
 	assert.Empty(t, anomalies)
 	assert.Empty(t, summaries)
 }
+
+func TestEnrichExternal_UsesReportSettings(t *testing.T) {
+	t.Parallel()
+	withIsolatedRegistry(t)
+
+	RegisterTimeSeriesExtractor("test-source", func(_ analyze.Report) ([]int, map[string][]float64) {
+		return []int{0, 1, 2, 3, 4}, map[string][]float64{
+			"metric_a": {1.0, 1.0, 1.0, 1.0, 100.0},
+		}
+	})
+
+	reports := map[string]analyze.Report{
+		"anomaly": {
+			"threshold":   float32(2.0),
+			"window_size": 3,
+		},
+		"test-source": {},
+	}
+
+	require.NoError(t, enrichExternal(reports))
+
+	extAnomalies, ok := reports["anomaly"]["external_anomalies"].([]ExternalAnomaly)
+	require.True(t, ok)
+	assert.NotEmpty(t, extAnomalies)
+	assert.NotContains(t, reports["test-source"], "external_anomalies")
+}
+
+func TestEnrichExternal_OnlyAnomalyReport(t *testing.T) {
+	t.Parallel()
+
+	reports := map[string]analyze.Report{"anomaly": {}}
+
+	require.NoError(t, enrichExternal(reports))
+	assert.NotContains(t, reports["anomaly"], "external_anomalies")
+}
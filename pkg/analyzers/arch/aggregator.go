@@ -0,0 +1,105 @@
+package arch
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+// Aggregator collects the imports of every file and checks them against the
+// architecture rules once all files are in.
+type Aggregator struct {
+	root        string
+	modulePath  string
+	arch        *importmodel.Architecture
+	fileImports map[string][]string // Slash-separated path relative to root -> imports.
+	totalFiles  int
+}
+
+// NewAggregator creates a new Aggregator.
+func NewAggregator() *Aggregator {
+	return &Aggregator{fileImports: make(map[string][]string)}
+}
+
+// SetRoot loads the arch.yaml and the go.mod module path of the tree at
+// root. A tree without arch.yaml is reported as not configured.
+func (a *Aggregator) SetRoot(root string) error {
+	info, err := os.Stat(root)
+	if err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+
+	a.root = root
+	a.modulePath = importmodel.ReadModulePath(filepath.Join(root, "go.mod"))
+
+	arch, err := importmodel.LoadArchitecture(filepath.Join(root, importmodel.ArchitectureFile))
+
+	switch {
+	case err == nil:
+		a.arch = arch
+	case errors.Is(err, fs.ErrNotExist):
+	default:
+		return err
+	}
+
+	return nil
+}
+
+// Aggregate records the imports of each "_source_file" stamped file.
+func (a *Aggregator) Aggregate(results map[string]analyze.Report) {
+	for _, report := range results {
+		a.totalFiles++
+
+		sites, ok := report[KeyFileImports].([]map[string]any)
+		if !ok || a.root == "" {
+			continue
+		}
+
+		for _, site := range sites {
+			file, _ := site["_source_file"].(string)
+			imps, _ := site[KeyImports].([]string)
+
+			rel, err := filepath.Rel(a.root, file)
+			if file == "" || err != nil {
+				continue
+			}
+
+			a.fileImports[filepath.ToSlash(rel)] = imps
+		}
+	}
+}
+
+// GetResult checks the collected imports and returns the violations.
+func (a *Aggregator) GetResult() analyze.Report {
+	result := analyze.Report{
+		KeyConfigured: a.arch != nil,
+		KeyTotalFiles: a.totalFiles,
+	}
+
+	if a.arch == nil {
+		return result
+	}
+
+	packages := make(map[string]bool, len(a.fileImports))
+	checked := make(map[string]bool)
+
+	for file, imps := range a.fileImports {
+		pkg := path.Dir(file)
+		packages[pkg] = true
+
+		for _, imp := range imps {
+			checked[pkg+"\x00"+imp] = true
+		}
+	}
+
+	result[KeyPackages] = len(packages)
+	result[KeyImportsChecked] = len(checked)
+	result[KeyViolations] = a.arch.Violations(a.fileImports, a.modulePath)
+
+	return result
+}
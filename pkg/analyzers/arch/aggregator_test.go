@@ -0,0 +1,97 @@
+package arch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+const testRules = `
+layers:
+  - name: app
+    packages: ["cmd/**"]
+  - name: core
+    packages: ["pkg/**"]
+forbidden:
+  - from: "pkg/**"
+    import: "os/exec"
+    reason: "libraries must not spawn processes"
+`
+
+// fileReport returns the per-file report of path as the static service
+// passes it to the aggregator.
+func fileReport(path string, imports ...string) map[string]analyze.Report {
+	return map[string]analyze.Report{"arch": {
+		KeyFileImports: []map[string]any{{KeyImports: imports, "_source_file": path}},
+	}}
+}
+
+func TestAggregator_Violations(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/mod\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, importmodel.ArchitectureFile), []byte(testRules), 0o600))
+
+	agg := NewAggregator()
+	require.NoError(t, agg.SetRoot(root))
+
+	agg.Aggregate(fileReport(filepath.Join(root, "cmd", "main.go"), "example.com/mod/pkg/a", "os/exec"))
+	agg.Aggregate(fileReport(filepath.Join(root, "pkg", "a", "a.go"), "example.com/mod/cmd", "os/exec", "fmt"))
+
+	result := agg.GetResult()
+
+	assert.Equal(t, true, result[KeyConfigured])
+	assert.Equal(t, 2, result[KeyPackages])
+	assert.Equal(t, 5, result[KeyImportsChecked])
+	assert.Equal(t, []importmodel.Violation{
+		{
+			Rule: importmodel.RuleForbiddenImport, From: "pkg/a", Import: "os/exec",
+			Detail: "libraries must not spawn processes",
+		},
+		{
+			Rule: importmodel.RuleLayer, From: "pkg/a", Import: "example.com/mod/cmd",
+			To: "cmd", Detail: "layer core may not depend on layer app",
+		},
+	}, result[KeyViolations])
+
+	m := ComputeAllMetrics(result)
+	assert.InDelta(t, 0.6, m.Conformance, 1e-9)
+	assert.Equal(t, map[string]int{importmodel.RuleForbiddenImport: 1, importmodel.RuleLayer: 1}, m.ViolationsByRule)
+
+	section := NewReportSection(result)
+	assert.Equal(t, StatusViolations, section.StatusMessage())
+	require.Len(t, section.AllIssues(), 2)
+	assert.Equal(t, "pkg/a -> os/exec", section.AllIssues()[0].Location)
+}
+
+func TestAggregator_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	agg := NewAggregator()
+	require.NoError(t, agg.SetRoot(t.TempDir()))
+	agg.Aggregate(fileReport("main.go", "fmt"))
+
+	result := agg.GetResult()
+	assert.Equal(t, false, result[KeyConfigured])
+
+	section := NewReportSection(result)
+	assert.InDelta(t, analyze.ScoreInfoOnly, section.Score(), 0)
+	assert.Equal(t, StatusNotConfigured, section.StatusMessage())
+	assert.Empty(t, section.KeyMetrics())
+}
+
+func TestAggregator_InvalidRules(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, importmodel.ArchitectureFile), []byte("forbidden: [{}]"), 0o600))
+
+	require.ErrorIs(t, NewAggregator().SetRoot(root), importmodel.ErrInvalidArchitecture)
+}
@@ -0,0 +1,151 @@
+// Package arch checks imports against the architecture rules of arch.yaml:
+// allowed layer dependencies, forbidden imports and ownership boundaries.
+package arch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/renderer"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/terminal"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/imports"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// Report keys of the static analyzer.
+const (
+	// KeyFileImports is the per-file collection that the static service stamps
+	// with "_source_file", so the aggregator knows which package imports what.
+	KeyFileImports    = "file_imports"
+	KeyImports        = "imports"
+	KeyConfigured     = "configured"
+	KeyPackages       = "packages"
+	KeyImportsChecked = "imports_checked"
+	KeyViolations     = "violations"
+	KeyTotalFiles     = "total_files"
+)
+
+// Analyzer checks the imports of a source tree against its arch.yaml.
+type Analyzer struct{}
+
+// NewAnalyzer creates a new Analyzer.
+func NewAnalyzer() *Analyzer {
+	return &Analyzer{}
+}
+
+// Name returns the name of the analyzer.
+func (a *Analyzer) Name() string {
+	return "arch"
+}
+
+// Flag returns the CLI flag for the analyzer.
+func (a *Analyzer) Flag() string {
+	return "arch-analysis"
+}
+
+// Description returns a human-readable description of the analyzer.
+func (a *Analyzer) Description() string {
+	return a.Descriptor().Description
+}
+
+// Descriptor returns stable analyzer metadata.
+func (a *Analyzer) Descriptor() analyze.Descriptor {
+	return analyze.NewDescriptor(
+		analyze.ModeStatic,
+		a.Name(),
+		"Checks imports against the layer, forbidden import and boundary rules of arch.yaml",
+	)
+}
+
+// ListConfigurationOptions returns the configuration options for the analyzer.
+func (a *Analyzer) ListConfigurationOptions() []pipeline.ConfigurationOption {
+	return []pipeline.ConfigurationOption{}
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (a *Analyzer) Configure(_ map[string]any) error {
+	return nil
+}
+
+// Thresholds returns the scoring thresholds for the analysis.
+func (a *Analyzer) Thresholds() analyze.Thresholds {
+	return nil
+}
+
+// CreateAggregator returns a new aggregator for collecting results.
+func (a *Analyzer) CreateAggregator() analyze.ResultAggregator {
+	return NewAggregator()
+}
+
+// Analyze extracts the imports of one file. The rules are checked by the
+// aggregator, which knows every package of the tree.
+func (a *Analyzer) Analyze(root *node.Node) (analyze.Report, error) {
+	return analyze.Report{
+		KeyFileImports: []map[string]any{{KeyImports: imports.ExtractImports(root)}},
+	}, nil
+}
+
+// FormatReport writes the formatted analysis report to the given writer.
+func (a *Analyzer) FormatReport(report analyze.Report, w io.Writer) error {
+	section := NewReportSection(report)
+	config := terminal.NewConfig()
+	r := renderer.NewSectionRenderer(config.Width, false, config.NoColor)
+
+	_, err := fmt.Fprint(w, r.Render(section))
+	if err != nil {
+		return fmt.Errorf("formatreport: %w", err)
+	}
+
+	return nil
+}
+
+// FormatReportJSON writes the analysis report in JSON format.
+func (a *Analyzer) FormatReportJSON(report analyze.Report, w io.Writer) error {
+	jsonData, err := json.MarshalIndent(ComputeAllMetrics(report), "", "  ")
+	if err != nil {
+		return fmt.Errorf("formatreportjson: %w", err)
+	}
+
+	_, err = fmt.Fprint(w, string(jsonData))
+	if err != nil {
+		return fmt.Errorf("formatreportjson: %w", err)
+	}
+
+	return nil
+}
+
+// FormatReportYAML writes the analysis report in YAML format.
+func (a *Analyzer) FormatReportYAML(report analyze.Report, w io.Writer) error {
+	data, err := yaml.Marshal(ComputeAllMetrics(report))
+	if err != nil {
+		return fmt.Errorf("formatreportyaml: %w", err)
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return fmt.Errorf("formatreportyaml: %w", err)
+	}
+
+	return nil
+}
+
+// FormatReportBinary writes the report in binary envelope format.
+func (a *Analyzer) FormatReportBinary(report analyze.Report, w io.Writer) error {
+	err := reportutil.EncodeBinaryEnvelope(ComputeAllMetrics(report), w)
+	if err != nil {
+		return fmt.Errorf("formatreportbinary: %w", err)
+	}
+
+	return nil
+}
+
+// CreateReportSection creates a ReportSection from report data.
+func (a *Analyzer) CreateReportSection(report analyze.Report) analyze.ReportSection {
+	return NewReportSection(report)
+}
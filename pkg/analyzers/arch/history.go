@@ -0,0 +1,402 @@
+package arch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/imports"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+)
+
+// Configuration option keys for the arch history analyzer.
+const (
+	ConfigArchRules       = "Arch.Rules"
+	ConfigArchMaxFileSize = "Arch.MaxFileSize"
+
+	defaultMaxFileSize = 1 << 20
+	// eventSize estimates the bytes of one Event held by the aggregator.
+	eventSize = 160
+)
+
+// Report keys of the history analyzer.
+const (
+	KeyCommits     = "commits"
+	KeyAuthorIndex = "author_index"
+	KeyTickSize    = "tick_size"
+)
+
+// ErrParserNotInitialized indicates Consume ran before Initialize.
+var ErrParserNotInitialized = errors.New("parser not initialized")
+
+// CommitEvents are the violations one commit introduced and resolved.
+type CommitEvents struct {
+	Hash     gitlib.Hash
+	Tick     int
+	AuthorID int
+	Events   []Event
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data,
+// with commits in the order they were analyzed.
+type TickData struct {
+	Commits []CommitEvents
+}
+
+// HistoryAnalyzer replays the commit history against the architecture rules
+// and records which commit introduced, and which resolved, every violation.
+type HistoryAnalyzer struct {
+	*analyze.BaseHistoryAnalyzer[*HistoryMetrics]
+
+	TreeDiff  *plumbing.TreeDiffAnalyzer
+	BlobCache *plumbing.BlobCacheAnalyzer
+	Identity  *plumbing.IdentityDetector
+	Ticks     *plumbing.TicksSinceStart
+
+	// RulesPath is an arch.yaml to check against; empty means the arch.yaml
+	// of the repository's HEAD.
+	RulesPath   string
+	MaxFileSize int
+
+	parser             *uast.Parser
+	state              *tracker // Nil when there are no rules.
+	reversedPeopleDict []string
+	tickSize           time.Duration
+}
+
+// NewHistoryAnalyzer creates a new HistoryAnalyzer.
+func NewHistoryAnalyzer() *HistoryAnalyzer {
+	a := &HistoryAnalyzer{MaxFileSize: defaultMaxFileSize}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*HistoryMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/arch",
+			Mode: analyze.ModeHistory,
+			Description: "Checks every commit against the architecture rules of arch.yaml and reports " +
+				"when each violation was introduced and resolved, and by whom.",
+		},
+		Sequential: true,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, Memory: analyze.MemoryMedium},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigArchRules,
+				Description: "Architecture rules file to check history against. Empty uses the arch.yaml at HEAD.",
+				Flag:        "arch-rules",
+				Type:        pipeline.PathConfigurationOption,
+				Default:     "",
+			},
+			{
+				Name:        ConfigArchMaxFileSize,
+				Description: "Specifies the file size threshold. Files that exceed it are not checked.",
+				Flag:        "arch-max-file-size",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultMaxFileSize,
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*HistoryMetrics, error) {
+			return ComputeHistoryMetrics(report), nil
+		},
+		AggregatorFn: newHistoryAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (h *HistoryAnalyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigArchRules].(string); exists {
+		h.RulesPath = val
+	}
+
+	if val, exists := facts[ConfigArchMaxFileSize].(int); exists && val > 0 {
+		h.MaxFileSize = val
+	}
+
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		h.reversedPeopleDict = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		h.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize loads the architecture rules and the UAST parser. Without
+// rules, every commit is skipped and the report says so.
+func (h *HistoryAnalyzer) Initialize(repository *gitlib.Repository) error {
+	if h.MaxFileSize <= 0 {
+		h.MaxFileSize = defaultMaxFileSize
+	}
+
+	arch, err := h.loadRules(repository)
+	if err != nil {
+		return err
+	}
+
+	h.state = nil
+	if arch != nil {
+		h.state = newTracker(arch)
+	}
+
+	h.parser, err = uast.NewParser()
+	if err != nil {
+		return fmt.Errorf("failed to initialize UAST parser: %w", err)
+	}
+
+	return nil
+}
+
+// loadRules reads RulesPath, or the arch.yaml committed at HEAD. It returns
+// a nil Architecture when HEAD has no arch.yaml.
+func (h *HistoryAnalyzer) loadRules(repository *gitlib.Repository) (*importmodel.Architecture, error) {
+	if h.RulesPath != "" {
+		return importmodel.LoadArchitecture(h.RulesPath)
+	}
+
+	if repository == nil {
+		return nil, nil //nolint:nilnil // No repository means no rules.
+	}
+
+	head, err := repository.Head()
+	if err != nil {
+		return nil, fmt.Errorf("load architecture: %w", err)
+	}
+
+	commit, err := repository.LookupCommit(context.Background(), head)
+	if err != nil {
+		return nil, fmt.Errorf("load architecture: %w", err)
+	}
+	defer commit.Free()
+
+	file, err := commit.File(importmodel.ArchitectureFile)
+	if err != nil {
+		return nil, nil //nolint:nilnil // HEAD has no arch.yaml.
+	}
+
+	data, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("load architecture: %w", err)
+	}
+
+	return importmodel.ParseArchitecture(data)
+}
+
+// Consume updates the tracked imports with the files the commit changed and
+// emits the violations that opened or closed.
+func (h *HistoryAnalyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if h.state == nil {
+		return analyze.TC{}, nil
+	}
+
+	if h.parser == nil {
+		return analyze.TC{}, ErrParserNotInitialized
+	}
+
+	var removed []string
+
+	updated := make(map[string][]string)
+
+	for _, change := range h.TreeDiff.Changes {
+		switch change.Action {
+		case gitlib.Delete:
+			removed = append(removed, change.From.Name)
+		case gitlib.Modify, gitlib.Insert:
+			if change.Action == gitlib.Modify && change.From.Name != change.To.Name {
+				removed = append(removed, change.From.Name)
+			}
+
+			h.readFile(ctx, change.To, updated)
+		}
+	}
+
+	events := h.state.apply(removed, updated)
+	if len(events) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	return analyze.TC{
+		Data:       &CommitEvents{Events: events},
+		CommitHash: ac.Commit.Hash(),
+	}, nil
+}
+
+// readFile adds the imports of entry to updated, and follows the module path
+// of the root go.mod. Files the parser does not support are not tracked.
+func (h *HistoryAnalyzer) readFile(ctx context.Context, entry gitlib.ChangeEntry, updated map[string][]string) {
+	blob := h.BlobCache.Cache[entry.Hash]
+	if blob == nil || blob.Size() > int64(h.MaxFileSize) {
+		return
+	}
+
+	if entry.Name == "go.mod" {
+		h.state.resolver.ModulePath = importmodel.ParseModulePath(blob.Data)
+
+		return
+	}
+
+	if !h.parser.IsSupported(entry.Name) {
+		return
+	}
+
+	root, err := h.parser.Parse(ctx, entry.Name, blob.Data)
+	if err != nil {
+		return
+	}
+
+	updated[entry.Name] = imports.ExtractImports(root)
+}
+
+// Fork creates copies of the analyzer that share the tracked tree.
+func (h *HistoryAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *h
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.BlobCache = &plumbing.BlobCacheAnalyzer{}
+		clone.Identity = &plumbing.IdentityDetector{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (h *HistoryAnalyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (h *HistoryAnalyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes:   h.TreeDiff.Changes,
+		BlobCache: h.BlobCache.Cache,
+		Tick:      h.Ticks.Tick,
+		AuthorID:  h.Identity.AuthorID,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (h *HistoryAnalyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	h.TreeDiff.Changes = snapshot.Changes
+	h.BlobCache.Cache = snapshot.BlobCache
+	h.Ticks.Tick = snapshot.Tick
+	h.Identity.AuthorID = snapshot.AuthorID
+}
+
+// ReleaseSnapshot is a no-op for arch.
+func (h *HistoryAnalyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (h *HistoryAnalyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return h.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (h *HistoryAnalyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return h.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the commits with events in history order.
+func (h *HistoryAnalyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	var commits []CommitEvents
+
+	for _, tick := range ticks {
+		if td, ok := tick.Data.(*TickData); ok && td != nil {
+			commits = append(commits, td.Commits...)
+		}
+	}
+
+	return analyze.Report{
+		KeyConfigured:  h.state != nil,
+		KeyCommits:     commits,
+		KeyAuthorIndex: h.reversedPeopleDict,
+		KeyTickSize:    h.tickSize,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	ce, ok := tc.Data.(*CommitEvents)
+	if !ok || ce == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{}
+		byTick[tc.Tick] = state
+	}
+
+	state.Commits = append(state.Commits, CommitEvents{
+		Hash:     tc.CommitHash,
+		Tick:     tc.Tick,
+		AuthorID: tc.AuthorID,
+		Events:   ce.Events,
+	})
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming != nil {
+		existing.Commits = append(existing.Commits, incoming.Commits...)
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	var events int
+
+	for _, ce := range state.Commits {
+		events += len(ce.Events)
+	}
+
+	return int64(events) * eventSize
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || len(state.Commits) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newHistoryAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
@@ -0,0 +1,108 @@
+package arch
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+// CommitRef identifies the commit that changed a violation.
+type CommitRef struct {
+	Hash   string `json:"hash"   yaml:"hash"`
+	Author string `json:"author" yaml:"author"`
+	Tick   int    `json:"tick"   yaml:"tick"`
+}
+
+// ViolationHistory is one violation with the commits that introduced and,
+// unless it is still open, resolved it. A violation that comes back after
+// being resolved starts a new ViolationHistory.
+type ViolationHistory struct {
+	importmodel.Violation `yaml:",inline"`
+
+	Introduced CommitRef  `json:"introduced"         yaml:"introduced"`
+	Resolved   *CommitRef `json:"resolved,omitempty" yaml:"resolved,omitempty"`
+}
+
+// AuthorViolations counts the violations one author introduced and resolved.
+type AuthorViolations struct {
+	Author     string `json:"author"     yaml:"author"`
+	Introduced int    `json:"introduced" yaml:"introduced"`
+	Resolved   int    `json:"resolved"   yaml:"resolved"`
+}
+
+// HistoryMetrics is the history of the architecture violations.
+type HistoryMetrics struct {
+	// Configured is false when there were no rules to check against.
+	Configured bool               `json:"configured" yaml:"configured"`
+	Open       int                `json:"open"       yaml:"open"`
+	Resolved   int                `json:"resolved"   yaml:"resolved"`
+	Violations []ViolationHistory `json:"violations" yaml:"violations"`
+	// Authors is sorted by introduced violations, most first.
+	Authors []AuthorViolations `json:"authors" yaml:"authors"`
+}
+
+// ComputeHistoryMetrics replays the commits of a history report.
+func ComputeHistoryMetrics(report analyze.Report) *HistoryMetrics {
+	configured, _ := report[KeyConfigured].(bool)
+	commits, _ := report[KeyCommits].([]CommitEvents)
+	names, _ := report[KeyAuthorIndex].([]string)
+
+	m := &HistoryMetrics{Configured: configured}
+	open := make(map[string]int) // Violation key -> index in m.Violations.
+	authors := make(map[string]*AuthorViolations)
+
+	for _, ce := range commits {
+		ref := CommitRef{Hash: ce.Hash.String(), Author: authorName(names, ce.AuthorID), Tick: ce.Tick}
+
+		author := authors[ref.Author]
+		if author == nil {
+			author = &AuthorViolations{Author: ref.Author}
+			authors[ref.Author] = author
+		}
+
+		for _, event := range ce.Events {
+			key := event.Violation.Key()
+
+			if !event.Resolved {
+				open[key] = len(m.Violations)
+				m.Violations = append(m.Violations, ViolationHistory{Violation: event.Violation, Introduced: ref})
+				author.Introduced++
+
+				continue
+			}
+
+			if i, ok := open[key]; ok {
+				m.Violations[i].Resolved = &ref
+				author.Resolved++
+
+				delete(open, key)
+			}
+		}
+	}
+
+	m.Open = len(open)
+	m.Resolved = len(m.Violations) - m.Open
+
+	for _, author := range authors {
+		if author.Introduced > 0 || author.Resolved > 0 {
+			m.Authors = append(m.Authors, *author)
+		}
+	}
+
+	slices.SortFunc(m.Authors, func(x, y AuthorViolations) int {
+		return cmp.Or(cmp.Compare(y.Introduced, x.Introduced), cmp.Compare(x.Author, y.Author))
+	})
+
+	return m
+}
+
+func authorName(names []string, id int) string {
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return identity.AuthorMissingName
+}
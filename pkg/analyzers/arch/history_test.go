@@ -0,0 +1,119 @@
+package arch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+func testTracker(t *testing.T) *tracker {
+	t.Helper()
+
+	arch, err := importmodel.ParseArchitecture([]byte(testRules))
+	require.NoError(t, err)
+
+	state := newTracker(arch)
+	state.resolver.ModulePath = "example.com/mod"
+
+	return state
+}
+
+func TestTracker_IntroduceAndResolve(t *testing.T) {
+	t.Parallel()
+
+	state := testTracker(t)
+
+	events := state.apply(nil, map[string][]string{
+		"cmd/main.go": {"example.com/mod/pkg/a"},
+		"pkg/a/a.go":  {"os/exec"},
+		"pkg/a/b.go":  {"os/exec"},
+	})
+	require.Len(t, events, 1)
+	assert.Equal(t, importmodel.RuleForbiddenImport, events[0].Violation.Rule)
+	assert.False(t, events[0].Resolved)
+
+	// The violation stays open while b.go still imports os/exec.
+	assert.Empty(t, state.apply([]string{"pkg/a/a.go"}, nil))
+
+	events = state.apply(nil, map[string][]string{"pkg/a/b.go": {"fmt", "example.com/mod/cmd"}})
+	require.Len(t, events, 2)
+	assert.Equal(t, Event{
+		Violation: importmodel.Violation{
+			Rule: importmodel.RuleForbiddenImport, From: "pkg/a", Import: "os/exec",
+			Detail: "libraries must not spawn processes",
+		},
+		Resolved: true,
+	}, events[0])
+	assert.Equal(t, importmodel.RuleLayer, events[1].Violation.Rule)
+	assert.False(t, events[1].Resolved)
+}
+
+func TestTracker_Rename(t *testing.T) {
+	t.Parallel()
+
+	state := testTracker(t)
+
+	require.Len(t, state.apply(nil, map[string][]string{"pkg/a/a.go": {"os/exec"}}), 1)
+
+	// Moving the file out of the layer resolves the violation.
+	events := state.apply([]string{"pkg/a/a.go"}, map[string][]string{"tools/a.go": {"os/exec"}})
+	require.Len(t, events, 1)
+	assert.True(t, events[0].Resolved)
+}
+
+func TestComputeHistoryMetrics(t *testing.T) {
+	t.Parallel()
+
+	violation := importmodel.Violation{Rule: importmodel.RuleLayer, From: "pkg/a", Import: "example.com/mod/cmd"}
+	other := importmodel.Violation{Rule: importmodel.RuleForbiddenImport, From: "pkg/a", Import: "os/exec"}
+
+	report := analyze.Report{
+		KeyConfigured:  true,
+		KeyAuthorIndex: []string{"alice", "bob"},
+		KeyCommits: []CommitEvents{
+			{Hash: gitlib.NewHash("1111111111111111111111111111111111111111"), Tick: 0, AuthorID: 0, Events: []Event{
+				{Violation: violation},
+				{Violation: other},
+			}},
+			{Hash: gitlib.NewHash("2222222222222222222222222222222222222222"), Tick: 3, AuthorID: 1, Events: []Event{
+				{Violation: violation, Resolved: true},
+			}},
+			{Hash: gitlib.NewHash("3333333333333333333333333333333333333333"), Tick: 5, AuthorID: 0, Events: []Event{
+				{Violation: violation},
+			}},
+		},
+	}
+
+	m := ComputeHistoryMetrics(report)
+
+	assert.True(t, m.Configured)
+	assert.Equal(t, 2, m.Open)
+	assert.Equal(t, 1, m.Resolved)
+	require.Len(t, m.Violations, 3)
+	assert.Equal(t, "alice", m.Violations[0].Introduced.Author)
+	require.NotNil(t, m.Violations[0].Resolved)
+	assert.Equal(t, CommitRef{Hash: "2222222222222222222222222222222222222222", Author: "bob", Tick: 3}, *m.Violations[0].Resolved)
+	assert.Nil(t, m.Violations[2].Resolved)
+	assert.Equal(t, []AuthorViolations{
+		{Author: "alice", Introduced: 3},
+		{Author: "bob", Resolved: 1},
+	}, m.Authors)
+}
+
+func TestHistoryAnalyzer_ConsumeWithoutRules(t *testing.T) {
+	t.Parallel()
+
+	h := NewHistoryAnalyzer()
+	require.NoError(t, h.Configure(map[string]any{ConfigArchMaxFileSize: 10}))
+	assert.Equal(t, 10, h.MaxFileSize)
+	assert.Equal(t, "arch", h.Flag())
+
+	tc, err := h.Consume(t.Context(), &analyze.Context{})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+}
@@ -0,0 +1,47 @@
+package arch
+
+import (
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+// ComputedMetrics is the conformance of a source tree to its arch.yaml.
+type ComputedMetrics struct {
+	// Configured is false when the tree has no arch.yaml.
+	Configured     bool `json:"configured"      yaml:"configured"`
+	Packages       int  `json:"packages"        yaml:"packages"`
+	ImportsChecked int  `json:"imports_checked" yaml:"imports_checked"`
+	// Conformance is the share of checked imports without violations.
+	Conformance      float64                 `json:"conformance"        yaml:"conformance"`
+	ViolationsByRule map[string]int          `json:"violations_by_rule" yaml:"violations_by_rule"`
+	Violations       []importmodel.Violation `json:"violations"         yaml:"violations"`
+}
+
+// ComputeAllMetrics computes the conformance metrics of a static report.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	violations, _ := report[KeyViolations].([]importmodel.Violation)
+	configured, _ := report[KeyConfigured].(bool)
+
+	m := &ComputedMetrics{
+		Configured:       configured,
+		Packages:         reportutil.GetInt(report, KeyPackages),
+		ImportsChecked:   reportutil.GetInt(report, KeyImportsChecked),
+		ViolationsByRule: make(map[string]int),
+		Violations:       violations,
+	}
+
+	violating := make(map[string]bool, len(violations))
+
+	for _, v := range violations {
+		m.ViolationsByRule[v.Rule]++
+		violating[v.From+"\x00"+v.Import] = true
+	}
+
+	m.Conformance = 1
+	if m.ImportsChecked > 0 {
+		m.Conformance = 1 - reportutil.Pct(len(violating), m.ImportsChecked)
+	}
+
+	return m
+}
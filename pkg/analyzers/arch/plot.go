@@ -0,0 +1,125 @@
+package arch
+
+import (
+	"html"
+	"io"
+	"strconv"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+)
+
+// shortHashLen is the length of commit hashes in tables.
+const shortHashLen = 8
+
+// RegisterPlotSections registers the arch plot section renderers with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("static/arch", func(report analyze.Report) ([]plotpage.Section, error) {
+		return generateStaticSections(report), nil
+	})
+	analyze.RegisterPlotSections("history/arch", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&HistoryAnalyzer{}).GenerateSections(report)
+	})
+}
+
+// FormatReportPlot renders the static conformance report as an HTML page.
+func (a *Analyzer) FormatReportPlot(report analyze.Report, w io.Writer) error {
+	page := plotpage.NewPage(
+		"Architecture Conformance",
+		"Imports checked against the layer, forbidden import and boundary rules of arch.yaml",
+	)
+
+	page.Add(generateStaticSections(report)...)
+
+	return page.Render(w)
+}
+
+func generateStaticSections(report analyze.Report) []plotpage.Section {
+	m := ComputeAllMetrics(report)
+
+	table := plotpage.NewTable([]string{"Rule", "Package", "Import", "Detail"}).WithSearch("Filter violations...")
+	for _, v := range m.Violations {
+		table.AddRow(
+			ruleLabels[v.Rule],
+			html.EscapeString(v.From),
+			html.EscapeString(v.Import),
+			html.EscapeString(v.Detail),
+		)
+	}
+
+	return []plotpage.Section{
+		{
+			Title:    "Architecture Violations",
+			Subtitle: "Conformance " + reportutil.FormatPercent(m.Conformance) + " of " + strconv.Itoa(m.ImportsChecked) + " checked imports.",
+			Chart:    table,
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Layer violation = a package depends on a layer its own layer may not use",
+					"Forbidden import = an import banned by a forbidden rule of arch.yaml",
+					"Boundary violation = an import of another team's internal package",
+				},
+			},
+		},
+	}
+}
+
+// GenerateSections returns the sections for combined reports.
+func (h *HistoryAnalyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeHistoryMetrics(report)
+
+	violations := plotpage.NewTable([]string{"Rule", "Package", "Import", "Introduced", "By", "Resolved"}).
+		WithSearch("Filter violations...")
+
+	for _, v := range m.Violations {
+		resolved := ""
+		if v.Resolved != nil {
+			resolved = shortHash(v.Resolved.Hash)
+		}
+
+		violations.AddRow(
+			ruleLabels[v.Rule],
+			html.EscapeString(v.From),
+			html.EscapeString(v.Import),
+			shortHash(v.Introduced.Hash),
+			html.EscapeString(v.Introduced.Author),
+			resolved,
+		)
+	}
+
+	authors := plotpage.NewTable([]string{"Author", "Introduced", "Resolved"})
+	for _, a := range m.Authors {
+		authors.AddRow(html.EscapeString(a.Author), strconv.Itoa(a.Introduced), strconv.Itoa(a.Resolved))
+	}
+
+	return []plotpage.Section{
+		{
+			Title:    "Architecture Violation History",
+			Subtitle: strconv.Itoa(m.Open) + " open and " + strconv.Itoa(m.Resolved) + " resolved violations, with the commits behind them.",
+			Chart:    violations,
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Introduced = the commit that first made the import break a rule",
+					"An empty Resolved column means the violation is still in the tree",
+				},
+			},
+		},
+		{
+			Title:    "Violations by Author",
+			Subtitle: "Architecture violations introduced and resolved per author.",
+			Chart:    authors,
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Many introductions by one author often point at rules that are unclear or unknown to them",
+				},
+			},
+		},
+	}, nil
+}
+
+func shortHash(hash string) string {
+	return hash[:min(len(hash), shortHashLen)]
+}
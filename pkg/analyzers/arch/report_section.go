@@ -0,0 +1,132 @@
+package arch
+
+import (
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+// Section rendering constants.
+const (
+	SectionTitle = "ARCHITECTURE"
+
+	// Metric labels.
+	MetricPackages       = "Packages"
+	MetricImportsChecked = "Imports Checked"
+	MetricConformance    = "Conformance"
+	MetricViolations     = "Violations"
+
+	// StatusNotConfigured is the message for trees without arch.yaml.
+	StatusNotConfigured = "No " + importmodel.ArchitectureFile + " found; add one to check the architecture"
+	StatusConforming    = "Conforming - no architecture violations"
+	StatusViolations    = "Architecture violations found"
+)
+
+// ruleLabels names the rules of importmodel in issues and distributions.
+var ruleLabels = map[string]string{
+	importmodel.RuleLayer:           "Layer violation",
+	importmodel.RuleForbiddenImport: "Forbidden import",
+	importmodel.RuleBoundary:        "Boundary violation",
+}
+
+// ReportSection implements analyze.ReportSection for architecture conformance.
+// Its score is the share of checked imports without violations.
+type ReportSection struct {
+	analyze.BaseReportSection
+
+	metrics *ComputedMetrics
+}
+
+// NewReportSection creates a ReportSection from an arch report.
+func NewReportSection(report analyze.Report) *ReportSection {
+	if report == nil {
+		report = analyze.Report{}
+	}
+
+	m := ComputeAllMetrics(report)
+
+	section := &ReportSection{
+		BaseReportSection: analyze.BaseReportSection{
+			Title:      SectionTitle,
+			Message:    StatusConforming,
+			ScoreValue: m.Conformance,
+		},
+		metrics: m,
+	}
+
+	switch {
+	case !m.Configured:
+		section.Message = StatusNotConfigured
+		section.ScoreValue = analyze.ScoreInfoOnly
+	case len(m.Violations) > 0:
+		section.Message = StatusViolations
+	}
+
+	return section
+}
+
+// KeyMetrics returns the key metrics for the architecture section.
+func (s *ReportSection) KeyMetrics() []analyze.Metric {
+	if !s.metrics.Configured {
+		return nil
+	}
+
+	return []analyze.Metric{
+		{Label: MetricPackages, Value: reportutil.FormatInt(s.metrics.Packages)},
+		{Label: MetricImportsChecked, Value: reportutil.FormatInt(s.metrics.ImportsChecked)},
+		{Label: MetricConformance, Value: reportutil.FormatPercent(s.metrics.Conformance)},
+		{Label: MetricViolations, Value: reportutil.FormatInt(len(s.metrics.Violations))},
+	}
+}
+
+// Distribution returns the violations by rule.
+func (s *ReportSection) Distribution() []analyze.DistributionItem {
+	total := len(s.metrics.Violations)
+	if total == 0 {
+		return nil
+	}
+
+	var items []analyze.DistributionItem
+
+	for _, rule := range []string{importmodel.RuleLayer, importmodel.RuleForbiddenImport, importmodel.RuleBoundary} {
+		if count := s.metrics.ViolationsByRule[rule]; count > 0 {
+			items = append(items, analyze.DistributionItem{
+				Label:   ruleLabels[rule],
+				Percent: reportutil.Pct(count, total),
+				Count:   count,
+			})
+		}
+	}
+
+	return items
+}
+
+// TopIssues returns the first n violations.
+func (s *ReportSection) TopIssues(n int) []analyze.Issue {
+	issues := s.AllIssues()
+	if n >= len(issues) {
+		return issues
+	}
+
+	return issues[:n]
+}
+
+// AllIssues returns every violation as a poor-severity issue.
+func (s *ReportSection) AllIssues() []analyze.Issue {
+	issues := make([]analyze.Issue, 0, len(s.metrics.Violations))
+
+	for _, v := range s.metrics.Violations {
+		issues = append(issues, violationIssue(v))
+	}
+
+	return issues
+}
+
+func violationIssue(v importmodel.Violation) analyze.Issue {
+	return analyze.Issue{
+		Name:     ruleLabels[v.Rule],
+		Location: v.From + " -> " + v.Import,
+		Value:    v.Detail,
+		Severity: analyze.SeverityPoor,
+	}
+}
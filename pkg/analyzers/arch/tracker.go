@@ -0,0 +1,117 @@
+package arch
+
+import (
+	"maps"
+	"path"
+	"slices"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+// Event is a violation introduced or resolved by one commit.
+type Event struct {
+	Violation importmodel.Violation
+	Resolved  bool
+}
+
+// tracker holds the imports of every file of the tree at the current commit
+// and the violations they make. A violation stays open while at least one
+// file makes it.
+type tracker struct {
+	arch       *importmodel.Architecture
+	resolver   *importmodel.Resolver
+	files      map[string][]string
+	violations map[string][]importmodel.Violation // File -> violations it makes.
+	open       map[string]int                     // Violation key -> files making it.
+}
+
+func newTracker(arch *importmodel.Architecture) *tracker {
+	return &tracker{
+		arch:       arch,
+		resolver:   importmodel.NewResolver(""),
+		files:      make(map[string][]string),
+		violations: make(map[string][]importmodel.Violation),
+		open:       make(map[string]int),
+	}
+}
+
+// apply removes and updates files, re-checks them and returns the violations
+// that opened or closed, sorted by package, rule and import. Only the changed
+// files are re-checked.
+func (t *tracker) apply(removed []string, updated map[string][]string) []Event {
+	for _, file := range removed {
+		if _, ok := t.files[file]; ok {
+			t.resolver.RemoveFile(file)
+			delete(t.files, file)
+		}
+	}
+
+	for file, imps := range updated {
+		if _, ok := t.files[file]; !ok {
+			t.resolver.AddFile(file)
+		}
+
+		t.files[file] = imps
+	}
+
+	changed := slices.Concat(removed, slices.Collect(maps.Keys(updated)))
+	slices.Sort(changed)
+
+	touched := make(map[string]importmodel.Violation)
+	wasOpen := make(map[string]bool)
+
+	note := func(v importmodel.Violation) {
+		key := v.Key()
+		if _, ok := touched[key]; !ok {
+			touched[key] = v
+			wasOpen[key] = t.open[key] > 0
+		}
+	}
+
+	for _, file := range slices.Compact(changed) {
+		for _, v := range t.violations[file] {
+			note(v)
+			t.open[v.Key()]--
+		}
+
+		delete(t.violations, file)
+
+		imps, ok := t.files[file]
+		if !ok {
+			continue
+		}
+
+		found := t.arch.Check(path.Dir(file), imps, t.resolver)
+		for _, v := range found {
+			note(v)
+			t.open[v.Key()]++
+		}
+
+		if len(found) > 0 {
+			t.violations[file] = found
+		}
+	}
+
+	return t.events(touched, wasOpen)
+}
+
+func (t *tracker) events(touched map[string]importmodel.Violation, wasOpen map[string]bool) []Event {
+	var events []Event
+
+	for key, v := range touched {
+		isOpen := t.open[key] > 0
+		if !isOpen {
+			delete(t.open, key)
+		}
+
+		if isOpen != wasOpen[key] {
+			events = append(events, Event{Violation: v, Resolved: !isOpen})
+		}
+	}
+
+	slices.SortFunc(events, func(x, y Event) int {
+		return importmodel.CompareViolations(x.Violation, y.Violation)
+	})
+
+	return events
+}
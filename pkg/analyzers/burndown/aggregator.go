@@ -3,6 +3,7 @@ package burndown
 import (
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"maps"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
 )
 
 // Memory estimation constants for aggregator state size.
@@ -28,6 +30,7 @@ type Aggregator struct {
 	matrix          []map[int]int64
 	fileHistories   map[PathID]sparseHistory
 	fileOwnership   map[PathID]map[int]int // pathID -> authorID -> lines (snapshot, not delta).
+	dirHistories    map[string]sparseHistory
 
 	// Configuration carried from the analyzer.
 	opts               analyze.AggregatorOptions
@@ -44,9 +47,10 @@ type Aggregator struct {
 	endTime  time.Time
 
 	// Spill state.
-	spillDir string
-	spillN   int
-	closed   bool
+	spillDir     string
+	spillN       int
+	spillWritten int64
+	closed       bool
 }
 
 func newAggregator(
@@ -61,6 +65,7 @@ func newAggregator(
 		globalHistory:      sparseHistory{},
 		peopleHistories:    map[int]sparseHistory{},
 		fileHistories:      map[PathID]sparseHistory{},
+		dirHistories:       map[string]sparseHistory{},
 		opts:               opts,
 		granularity:        granularity,
 		sampling:           sampling,
@@ -91,6 +96,8 @@ func (a *Aggregator) Add(tc analyze.TC) error {
 		a.mergeFileOwnership(cr.FileOwnership)
 	}
 
+	mergeDirHistories(a.dirHistories, cr.DirDeltas)
+
 	if tc.Tick > a.lastTick {
 		a.lastTick = tc.Tick
 	}
@@ -146,6 +153,7 @@ func (a *Aggregator) FlushTick(tick int) (analyze.TICK, error) {
 		Matrix:          a.cloneMatrix(),
 		FileHistories:   a.cloneFileHistories(),
 		FileOwnership:   a.cloneFileOwnership(),
+		DirHistories:    a.cloneDirHistories(),
 	}
 
 	return analyze.TICK{
@@ -252,6 +260,7 @@ type spillSnapshot struct {
 	Matrix          []map[int]int64
 	FileHistories   map[PathID]sparseHistory
 	FileOwnership   map[PathID]map[int]int
+	DirHistories    map[string]sparseHistory
 }
 
 // Spill writes accumulated state to disk to free memory.
@@ -273,6 +282,7 @@ func (a *Aggregator) Spill() (int64, error) {
 		Matrix:          a.matrix,
 		FileHistories:   a.fileHistories,
 		FileOwnership:   a.fileOwnership,
+		DirHistories:    a.dirHistories,
 	}
 
 	path := filepath.Join(a.spillDir, fmt.Sprintf("agg_%03d.gob", a.spillN))
@@ -282,7 +292,12 @@ func (a *Aggregator) Spill() (int64, error) {
 		return 0, fmt.Errorf("burndown aggregator: create spill: %w", err)
 	}
 
-	err = gob.NewEncoder(f).Encode(snap)
+	err = writeSpill(f, a.opts.SpillCodec, snap)
+
+	var size int64
+	if info, statErr := f.Stat(); statErr == nil {
+		size = info.Size()
+	}
 
 	closeErr := f.Close()
 
@@ -295,11 +310,13 @@ func (a *Aggregator) Spill() (int64, error) {
 	}
 
 	a.spillN++
+	a.spillWritten += size
 	a.globalHistory = sparseHistory{}
 	a.peopleHistories = map[int]sparseHistory{}
 	a.matrix = nil
 	a.fileHistories = map[PathID]sparseHistory{}
 	a.fileOwnership = nil
+	a.dirHistories = map[string]sparseHistory{}
 
 	return sizeBefore, nil
 }
@@ -309,14 +326,9 @@ func (a *Aggregator) ensureSpillDir() error {
 		return nil
 	}
 
-	dir := a.opts.SpillDir
-	if dir == "" {
-		d, err := os.MkdirTemp("", "codefang-burndown-agg-*")
-		if err != nil {
-			return fmt.Errorf("burndown aggregator: create spill dir: %w", err)
-		}
-
-		dir = d
+	dir, err := os.MkdirTemp(a.opts.SpillDir, AggregatorSpillDirPattern)
+	if err != nil {
+		return fmt.Errorf("burndown aggregator: create spill dir: %w", err)
 	}
 
 	a.spillDir = dir
@@ -329,7 +341,7 @@ func (a *Aggregator) Collect() error {
 	for i := range a.spillN {
 		path := filepath.Join(a.spillDir, fmt.Sprintf("agg_%03d.gob", i))
 
-		snap, err := readSpill(path)
+		snap, err := readSpill(path, a.opts.SpillCodec)
 		if err != nil {
 			return err
 		}
@@ -354,6 +366,8 @@ func (a *Aggregator) Collect() error {
 
 			maps.Copy(a.fileOwnership, snap.FileOwnership)
 		}
+
+		mergeDirHistories(a.dirHistories, snap.DirHistories)
 	}
 
 	a.cleanupSpillFiles()
@@ -361,7 +375,18 @@ func (a *Aggregator) Collect() error {
 	return nil
 }
 
-func readSpill(path string) (*spillSnapshot, error) {
+func writeSpill(f *os.File, c codec.Codec, snap *spillSnapshot) error {
+	w, err := codec.OrNone(c).NewWriter(f)
+	if err != nil {
+		return err
+	}
+
+	err = gob.NewEncoder(w).Encode(snap)
+
+	return errors.Join(err, w.Close())
+}
+
+func readSpill(path string, c codec.Codec) (*spillSnapshot, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("burndown aggregator: open spill: %w", err)
@@ -369,9 +394,16 @@ func readSpill(path string) (*spillSnapshot, error) {
 
 	defer f.Close()
 
+	r, err := codec.OrNone(c).NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("burndown aggregator: open spill: %w", err)
+	}
+
+	defer r.Close()
+
 	var snap spillSnapshot
 
-	err = gob.NewDecoder(f).Decode(&snap)
+	err = gob.NewDecoder(r).Decode(&snap)
 	if err != nil {
 		return nil, fmt.Errorf("burndown aggregator: decode spill: %w", err)
 	}
@@ -380,7 +412,7 @@ func readSpill(path string) (*spillSnapshot, error) {
 }
 
 func (a *Aggregator) cleanupSpillFiles() {
-	if a.spillDir != "" && a.opts.SpillDir == "" {
+	if a.spillDir != "" {
 		os.RemoveAll(a.spillDir)
 	}
 
@@ -406,6 +438,10 @@ func (a *Aggregator) EstimatedStateSize() int64 {
 		size += estimateSparseHistorySize(history)
 	}
 
+	for _, history := range a.dirHistories {
+		size += estimateSparseHistorySize(history)
+	}
+
 	return size
 }
 
@@ -421,13 +457,19 @@ func estimateSparseHistorySize(history sparseHistory) int64 {
 
 // SpillState returns the current on-disk spill state for checkpoint persistence.
 func (a *Aggregator) SpillState() analyze.AggregatorSpillInfo {
-	return analyze.AggregatorSpillInfo{Dir: a.spillDir, Count: a.spillN}
+	return analyze.AggregatorSpillInfo{
+		Dir:     a.spillDir,
+		Count:   a.spillN,
+		Codec:   codec.NameOf(a.opts.SpillCodec),
+		Written: a.spillWritten,
+	}
 }
 
 // RestoreSpillState points the aggregator at a previously-saved spill directory.
 func (a *Aggregator) RestoreSpillState(info analyze.AggregatorSpillInfo) {
 	a.spillDir = info.Dir
 	a.spillN = info.Count
+	a.opts.SpillCodec = info.SpillCodec()
 }
 
 // Close releases all resources. Idempotent.
@@ -490,6 +532,11 @@ func ticksToReport(
 		addFilesToReport(report, merged, converter, lastTick, pathInterner)
 	}
 
+	// Convert directory histories.
+	if len(merged.DirHistories) > 0 {
+		addDirsToReport(report, merged, converter, lastTick)
+	}
+
 	return report
 }
 
@@ -507,6 +554,7 @@ func mergeAllTicks(ticks []analyze.TICK) *TickResult {
 				GlobalHistory:   sparseHistory{},
 				PeopleHistories: nil,
 				FileHistories:   map[PathID]sparseHistory{},
+				DirHistories:    map[string]sparseHistory{},
 			}
 		}
 
@@ -516,6 +564,7 @@ func mergeAllTicks(ticks []analyze.TICK) *TickResult {
 
 		mergeTickFileHistories(merged, tr.FileHistories)
 		mergeTickFileOwnership(merged, tr.FileOwnership)
+		mergeDirHistories(merged.DirHistories, tr.DirHistories)
 	}
 
 	return merged
@@ -4,6 +4,7 @@ import (
 	"maps"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/checkpoint"
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 )
 
 // Checkpoint size estimation constants.
@@ -57,13 +58,13 @@ type shardState struct {
 }
 
 // SaveCheckpoint writes the analyzer state to the given directory.
-func (b *HistoryAnalyzer) SaveCheckpoint(dir string) error {
-	return newPersister().Save(dir, b.buildCheckpointState)
+func (b *HistoryAnalyzer) SaveCheckpoint(dir string, c *crypt.Cipher) error {
+	return newPersister().Save(dir, c, b.buildCheckpointState)
 }
 
 // LoadCheckpoint restores the analyzer state from the given directory.
-func (b *HistoryAnalyzer) LoadCheckpoint(dir string) error {
-	return newPersister().Load(dir, b.restoreFromCheckpoint)
+func (b *HistoryAnalyzer) LoadCheckpoint(dir string, c *crypt.Cipher) error {
+	return newPersister().Load(dir, c, b.restoreFromCheckpoint)
 }
 
 // CheckpointSize returns an estimated size of the checkpoint in bytes.
@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 )
 
 func TestHistoryAnalyzer_CheckpointRoundTrip(t *testing.T) {
@@ -38,7 +40,7 @@ func TestHistoryAnalyzer_CheckpointRoundTrip(t *testing.T) {
 	}
 
 	// Save checkpoint.
-	err := original.SaveCheckpoint(dir)
+	err := original.SaveCheckpoint(dir, nil)
 	require.NoError(t, err)
 
 	// Create new analyzer and restore.
@@ -50,7 +52,7 @@ func TestHistoryAnalyzer_CheckpointRoundTrip(t *testing.T) {
 		restored.shards[i] = &Shard{}
 	}
 
-	err = restored.LoadCheckpoint(dir)
+	err = restored.LoadCheckpoint(dir, nil)
 	require.NoError(t, err)
 
 	// Verify path interner.
@@ -106,8 +108,8 @@ func TestHistoryAnalyzer_Checkpointable(t *testing.T) {
 
 	// Verify HistoryAnalyzer implements Checkpointable interface.
 	var analyzer interface {
-		SaveCheckpoint(dir string) error
-		LoadCheckpoint(dir string) error
+		SaveCheckpoint(dir string, c *crypt.Cipher) error
+		LoadCheckpoint(dir string, c *crypt.Cipher) error
 		CheckpointSize() int64
 	} = NewHistoryAnalyzer()
 
@@ -0,0 +1,146 @@
+package burndown
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+const (
+	// dirDepthPrefix is the --burndown-dirs key that groups by path prefix.
+	dirDepthPrefix = "depth="
+	// dirsByProject is the --burndown-dirs value that groups by project root.
+	dirsByProject = "projects"
+)
+
+// parseDirDepth parses a --burndown-dirs value such as "depth=2" or
+// "projects". Empty input disables per-directory tracking and returns 0;
+// "projects" returns 0 and byProject set.
+func parseDirDepth(s string) (depth int, byProject bool, err error) {
+	if s == "" {
+		return 0, false, nil
+	}
+
+	if s == dirsByProject {
+		return 0, true, nil
+	}
+
+	value, ok := strings.CutPrefix(s, dirDepthPrefix)
+	if !ok {
+		return 0, false, fmt.Errorf("%w: %q (want depth=N or projects)", errInvalidBurndownDirs, s)
+	}
+
+	depth, err = strconv.Atoi(value)
+	if err != nil || depth < 1 {
+		return 0, false, fmt.Errorf("%w: %q (depth must be a positive integer)", errInvalidBurndownDirs, s)
+	}
+
+	return depth, false, nil
+}
+
+// tracksDirs reports whether per-directory histories are recorded.
+func (b *HistoryAnalyzer) tracksDirs() bool {
+	return b.DirDepth > 0 || b.DirsByProject
+}
+
+// dirOf returns the directory key the lines of path are counted under: the
+// root of its project when grouping by project, otherwise its path prefix.
+// Files outside every project count under [pkgplumbing.ProjectRoot].
+func (b *HistoryAnalyzer) dirOf(path string) string {
+	if !b.DirsByProject {
+		return pkgplumbing.DirPrefix(path, b.DirDepth)
+	}
+
+	if root := b.Projects.Of(path); root != "" {
+		return root
+	}
+
+	return pkgplumbing.ProjectRoot
+}
+
+func (b *HistoryAnalyzer) updateDir(shard *Shard, dir string, currentTime, previousTime, delta int) {
+	_, curTick := b.unpackPersonWithTick(currentTime)
+	_, prevTick := b.unpackPersonWithTick(previousTime)
+
+	history := shard.deltas.dirDeltas[dir]
+	if history == nil {
+		history = sparseHistory{}
+		shard.deltas.dirDeltas[dir] = history
+	}
+
+	currentHistory := history[curTick]
+	if currentHistory == nil {
+		currentHistory = map[int]int64{}
+		history[curTick] = currentHistory
+	}
+
+	currentHistory[prevTick] += int64(delta)
+}
+
+func (b *HistoryAnalyzer) collectDirDeltas(result *CommitResult, shard *Shard) {
+	if !b.tracksDirs() {
+		return
+	}
+
+	for dir, history := range shard.deltas.dirDeltas {
+		if len(history) == 0 {
+			continue
+		}
+
+		if result.DirDeltas == nil {
+			result.DirDeltas = map[string]sparseHistory{}
+		}
+
+		if result.DirDeltas[dir] == nil {
+			result.DirDeltas[dir] = sparseHistory{}
+		}
+
+		mergeSparseHistory(result.DirDeltas[dir], history)
+	}
+}
+
+// mergeDirHistories merges per-directory sparse histories from src into dst.
+func mergeDirHistories(dst, src map[string]sparseHistory) {
+	for dir, history := range src {
+		if len(history) == 0 {
+			continue
+		}
+
+		if dst[dir] == nil {
+			dst[dir] = sparseHistory{}
+		}
+
+		mergeSparseHistory(dst[dir], history)
+	}
+}
+
+func (a *Aggregator) cloneDirHistories() map[string]sparseHistory {
+	if len(a.dirHistories) == 0 {
+		return nil
+	}
+
+	result := make(map[string]sparseHistory, len(a.dirHistories))
+
+	for dir, history := range a.dirHistories {
+		result[dir] = cloneSparseHistory(history)
+	}
+
+	return result
+}
+
+func addDirsToReport(report analyze.Report, merged *TickResult, converter *HistoryAnalyzer, lastTick int) {
+	dirHistories := make(map[string]DenseHistory, len(merged.DirHistories))
+
+	for dir, history := range merged.DirHistories {
+		if len(history) == 0 {
+			continue
+		}
+
+		dirHistories[dir] = converter.groupSparseHistory(history, lastTick)
+	}
+
+	report["DirHistories"] = dirHistories
+}
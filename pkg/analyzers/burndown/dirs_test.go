@@ -0,0 +1,139 @@
+package burndown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestParseDirDepth(t *testing.T) {
+	t.Parallel()
+
+	depth, byProject, err := parseDirDepth("")
+	require.NoError(t, err)
+	assert.Zero(t, depth)
+	assert.False(t, byProject)
+
+	depth, byProject, err = parseDirDepth("depth=2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, depth)
+	assert.False(t, byProject)
+
+	depth, byProject, err = parseDirDepth("projects")
+	require.NoError(t, err)
+	assert.Zero(t, depth)
+	assert.True(t, byProject)
+
+	for _, bad := range []string{"2", "depth=0", "depth=x", "level=2", "project"} {
+		_, _, err = parseDirDepth(bad)
+		require.ErrorIs(t, err, errInvalidBurndownDirs, bad)
+	}
+}
+
+func TestDirHistories_CollectedPerPrefix(t *testing.T) {
+	t.Parallel()
+
+	b := NewHistoryAnalyzer()
+	b.Goroutines = 1
+	require.NoError(t, b.Configure(map[string]any{ConfigBurndownDirs: "depth=1"}))
+	require.NoError(t, b.Initialize(nil))
+	b.resetDeltaBuffers()
+
+	hash := gitlib.NewHash("1111111111111111111111111111111111111111")
+	cache := map[gitlib.Hash]*pkgplumbing.CachedBlob{
+		hash: gitlib.NewCachedBlobWithHashForTest(hash, []byte("a\nb\nc\n")),
+	}
+
+	shard := b.shards[0]
+
+	for _, name := range []string{"pkg/x/a.go", "pkg/y/b.go", "README"} {
+		change := &gitlib.Change{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: name, Hash: hash}}
+		require.NoError(t, b.handleInsertion(shard, change, credit{}, cache))
+	}
+
+	result := b.collectDeltas()
+	assert.Equal(t, map[string]sparseHistory{
+		"pkg": {0: {0: 6}},
+		".":   {0: {0: 3}},
+	}, result.DirDeltas)
+	assert.Nil(t, result.FileDeltas)
+}
+
+func TestDirHistories_CollectedPerProject(t *testing.T) {
+	t.Parallel()
+
+	b := NewHistoryAnalyzer()
+	b.Goroutines = 1
+	require.NoError(t, b.Configure(map[string]any{
+		ConfigBurndownDirs:       "projects",
+		pkgplumbing.FactProjects: pkgplumbing.DiscoverProjects([]string{"svc/api/go.mod", "web/package.json"}),
+	}))
+	require.NoError(t, b.Initialize(nil))
+	b.resetDeltaBuffers()
+
+	hash := gitlib.NewHash("1111111111111111111111111111111111111111")
+	cache := map[gitlib.Hash]*pkgplumbing.CachedBlob{
+		hash: gitlib.NewCachedBlobWithHashForTest(hash, []byte("a\nb\nc\n")),
+	}
+
+	shard := b.shards[0]
+
+	for _, name := range []string{"svc/api/a.go", "svc/api/internal/b.go", "web/index.js", "README"} {
+		change := &gitlib.Change{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: name, Hash: hash}}
+		require.NoError(t, b.handleInsertion(shard, change, credit{}, cache))
+	}
+
+	result := b.collectDeltas()
+	assert.Equal(t, map[string]sparseHistory{
+		"svc/api": {0: {0: 6}},
+		"web":     {0: {0: 3}},
+		".":       {0: {0: 3}},
+	}, result.DirDeltas)
+}
+
+func TestDirHistories_AggregatorToMetrics(t *testing.T) {
+	t.Parallel()
+
+	agg := newTestAggregator()
+
+	require.NoError(t, agg.Add(analyze.TC{
+		Data: &CommitResult{
+			GlobalDeltas: sparseHistory{0: {0: 10}},
+			DirDeltas:    map[string]sparseHistory{"cmd": {0: {0: 4}}, "pkg": {0: {0: 6}}},
+		},
+	}))
+	require.NoError(t, agg.Add(analyze.TC{
+		Tick: 40,
+		Data: &CommitResult{
+			GlobalDeltas: sparseHistory{40: {0: -3}},
+			DirDeltas:    map[string]sparseHistory{"pkg": {40: {0: -3}}},
+		},
+	}))
+
+	ticks, err := agg.FlushAllTicks()
+	require.NoError(t, err)
+
+	report := ticksToReport(context.Background(), ticks, 30, 30, 0, false, 24*time.Hour, nil, nil)
+
+	dirs, ok := report["DirHistories"].(map[string]DenseHistory)
+	require.True(t, ok)
+	assert.Len(t, dirs, 2)
+
+	metrics, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+	require.Len(t, metrics.DirSurvival, 2)
+
+	assert.Equal(t, "cmd", metrics.DirSurvival[0].Dir)
+	assert.Equal(t, int64(4), metrics.DirSurvival[0].CurrentLines)
+	assert.Equal(t, "pkg", metrics.DirSurvival[1].Dir)
+	assert.Equal(t, int64(6), metrics.DirSurvival[1].PeakLines)
+	assert.Equal(t, int64(3), metrics.DirSurvival[1].CurrentLines)
+	assert.InDelta(t, 0.5, metrics.DirSurvival[1].SurvivalRate, 1e-9)
+}
@@ -0,0 +1,46 @@
+package burndown
+
+import (
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// RegisterForecastSeries registers the size of the codebase over time as a
+// series to forecast.
+func RegisterForecastSeries() {
+	analyze.RegisterForecastSeries(analyzerNameBurndown, extractForecastSeries)
+}
+
+// extractForecastSeries returns the alive lines (or tokens) of every sample
+// of the global history. Calendar ticks have no fixed duration to project
+// over, so histories sampled by calendar are not forecast.
+func extractForecastSeries(report analyze.Report) []analyze.ForecastSeries {
+	data, err := ParseReportData(report)
+	if err != nil || len(data.GlobalHistory) == 0 || data.Calendar != nil {
+		return nil
+	}
+
+	values := make([]float64, len(data.GlobalHistory))
+
+	for i, sample := range data.GlobalHistory {
+		var total int64
+		for _, lines := range sample {
+			total += lines
+		}
+
+		values[i] = float64(total)
+	}
+
+	series := analyze.ForecastSeries{
+		Name:     "loc",
+		Label:    "Lines of code",
+		Step:     max(data.Sampling, 1),
+		Values:   values,
+		TickSize: data.TickSize,
+	}
+
+	if data.Unit == UnitToken {
+		series.Name, series.Label = "tokens", "Tokens of code"
+	}
+
+	return []analyze.ForecastSeries{series}
+}
@@ -0,0 +1,37 @@
+package burndown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestExtractForecastSeries(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		"GlobalHistory": DenseHistory{{100, 0}, {90, 40}, {80, 70}},
+		"Sampling":      30,
+		"TickSize":      12 * time.Hour,
+	}
+
+	series := extractForecastSeries(report)
+	require.Len(t, series, 1)
+	assert.Equal(t, "loc", series[0].Name)
+	assert.Equal(t, 30, series[0].Step)
+	assert.Equal(t, 12*time.Hour, series[0].TickSize)
+	assert.Equal(t, []float64{100, 130, 150}, series[0].Values)
+
+	report["GranularityUnit"] = UnitToken
+	assert.Equal(t, "tokens", extractForecastSeries(report)[0].Name)
+
+	report["TickCalendar"] = &pkgplumbing.TickCalendar{Granularity: pkgplumbing.TickMonth}
+	assert.Nil(t, extractForecastSeries(report), "calendar ticks are not forecast")
+
+	assert.Nil(t, extractForecastSeries(analyze.Report{}))
+}
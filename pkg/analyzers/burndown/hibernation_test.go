@@ -1,6 +1,7 @@
 package burndown
 
 import (
+	"os"
 	"testing"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/burndown"
@@ -337,6 +338,111 @@ func TestHibernate_Boot_SpillsAndRestoresFileHistories(t *testing.T) {
 	}
 }
 
+func TestBoot_LeavesColdFilesOnDisk(t *testing.T) {
+	t.Parallel()
+
+	analyzer := createAnalyzerWithShards(1)
+	defer analyzer.CleanupSpills()
+
+	analyzer.ColdChunks = 1
+
+	id := analyzer.pathInterner.Intern("cold.go")
+	shard := analyzer.shards[0]
+	analyzer.ensureCapacity(shard, id)
+
+	file := burndown.NewFile(1, 100)
+	file.Update(2, 50, 20, 10)
+	lenBefore := file.Len()
+	shard.filesByID[id] = file
+	shard.activeIDs = append(shard.activeIDs, id)
+	analyzer.touch(shard, id)
+
+	// First chunk boundary: the file was just touched, so it comes back.
+	err := analyzer.Hibernate()
+	if err != nil {
+		t.Fatalf("Hibernate() failed: %v", err)
+	}
+
+	err = analyzer.Boot()
+	if err != nil {
+		t.Fatalf("Boot() failed: %v", err)
+	}
+
+	if shard.filesByID[id] == nil {
+		t.Fatal("recently touched file should be restored by Boot")
+	}
+
+	// Second boundary without touching it: the file stays on disk.
+	err = analyzer.Hibernate()
+	if err != nil {
+		t.Fatalf("Hibernate() failed: %v", err)
+	}
+
+	err = analyzer.Boot()
+	if err != nil {
+		t.Fatalf("Boot() failed: %v", err)
+	}
+
+	if shard.filesByID[id] != nil {
+		t.Fatal("cold file should stay on disk after Boot")
+	}
+
+	if len(analyzer.shardSpills[0].cold) != 1 {
+		t.Fatalf("cold index: got %d entries, want 1", len(analyzer.shardSpills[0].cold))
+	}
+
+	// First access reloads it and drops the spill file nobody needs any more.
+	reloaded, err := analyzer.fileByID(shard, id)
+	if err != nil {
+		t.Fatalf("fileByID() failed: %v", err)
+	}
+
+	if reloaded == nil || reloaded.Len() != lenBefore {
+		t.Fatalf("reloaded file: got %v, want Len %d", reloaded, lenBefore)
+	}
+
+	if len(analyzer.shardSpills[0].cold) != 0 {
+		t.Error("cold index should be empty after reload")
+	}
+
+	entries, err := os.ReadDir(analyzer.shardSpills[0].dir)
+	if err != nil {
+		t.Fatalf("ReadDir() failed: %v", err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("spill files left behind: %d", len(entries))
+	}
+}
+
+func TestHibernate_InMemoryWhenDiskDisabled(t *testing.T) {
+	t.Parallel()
+
+	analyzer := createAnalyzerWithShards(1)
+	defer analyzer.CleanupSpills()
+
+	analyzer.HibernationToDisk = false
+
+	id := analyzer.pathInterner.Intern("mem.go")
+	shard := analyzer.shards[0]
+	analyzer.ensureCapacity(shard, id)
+	shard.filesByID[id] = burndown.NewFile(1, 10)
+	shard.activeIDs = append(shard.activeIDs, id)
+
+	err := analyzer.Hibernate()
+	if err != nil {
+		t.Fatalf("Hibernate() failed: %v", err)
+	}
+
+	if shard.filesByID[id] == nil {
+		t.Error("file should stay in memory when hibernation to disk is off")
+	}
+
+	if analyzer.spillDir != "" {
+		t.Error("no spill directory should be created")
+	}
+}
+
 // BenchmarkHibernate measures hibernation performance with realistic shard data.
 func BenchmarkHibernate(b *testing.B) {
 	const (
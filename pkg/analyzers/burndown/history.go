@@ -35,6 +35,8 @@ var (
 	errFileNotExist            = errors.New("file does not exist")
 	errUnexpectedBinary        = errors.New("previous version unexpectedly became binary")
 	errInternalIntegritySource = errors.New("internal integrity error src mismatch")
+	errUnknownGranularityUnit  = errors.New("unknown burndown granularity unit")
+	errInvalidBurndownDirs     = errors.New("invalid burndown dirs setting")
 )
 
 // Configuration constants for burndown analysis.
@@ -45,6 +47,15 @@ const (
 	mrowValue             = 2
 )
 
+// os.MkdirTemp patterns of the directories burndown spills to, which a
+// crashed run leaves behind.
+const (
+	// SpillDirPattern names the hibernated file directories of the analyzer.
+	SpillDirPattern = "codefang-burndown-spill-*"
+	// AggregatorSpillDirPattern names the spill directories of the aggregator.
+	AggregatorSpillDirPattern = "codefang-burndown-agg-*"
+)
+
 // Shard holds per-file burndown data within a partition.
 // Uses PathID-indexed slices and activeIDs so iteration is over a slice (touched list), not map iteration (Track B).
 type Shard struct {
@@ -54,6 +65,8 @@ type Shard struct {
 	deltas            deltaBuffer
 	mergedByID        map[PathID]bool
 	deletionsByID     map[PathID]bool
+	lastChunkByID     map[PathID]int   // chunk that last touched each resident file.
+	spill             *shardSpillState // set by Hibernate; indexes files left on disk.
 	mu                sync.Mutex
 }
 
@@ -75,18 +88,22 @@ type HistoryAnalyzer struct {
 	Identity             *plumbing.IdentityDetector
 	FileDiff             *plumbing.FileDiffAnalyzer
 	TreeDiff             *plumbing.TreeDiffAnalyzer
+	UAST                 *plumbing.UASTChangesAnalyzer // read in token mode only; may be nil.
 	HibernationDirectory string
 	shards               []*Shard
 	shardSpills          []shardSpillState // per-shard spill tracking for file treaps.
 	spillDir             string            // parent temp dir for shard file spills.
 	reversedPeopleDict   []string
+	coAuthorWeight       float64
 	mergedAuthor         int
 	HibernationThreshold int
+	ColdChunks           int // untouched chunks before Boot leaves a file on disk; 0 disables.
 	Granularity          int
 	PeopleNumber         int
 	TickSize             time.Duration
 	Goroutines           int
 	tick                 int
+	chunk                int // number of Hibernate calls so far.
 	isMerge              bool
 	previousTick         int
 	Sampling             int
@@ -95,6 +112,17 @@ type HistoryAnalyzer struct {
 	TrackFiles           bool
 	HibernationToDisk    bool
 	lastCommitTime       time.Time
+	tickCalendar         *pkgplumbing.TickCalendar // nil for fixed-size ticks.
+	GranularityUnit      GranularityUnit
+	DirDepth             int  // 0 disables per-directory histories by prefix.
+	DirsByProject        bool // Group per-directory histories by project root.
+	Projects             pkgplumbing.Projects
+
+	// prepared holds token units computed ahead of Consume by PrepareCommit, per commit.
+	prepared   map[gitlib.Hash]preparedCommit
+	preparedMu sync.Mutex
+	// ahead is the prepared entry of the commit being consumed.
+	ahead preparedCommit
 }
 
 const (
@@ -112,10 +140,16 @@ const (
 	ConfigBurndownHibernationToDisk = "Burndown.HibernationOnDisk"
 	// ConfigBurndownHibernationDirectory defines the hibernation directory configuration constant.
 	ConfigBurndownHibernationDirectory = "Burndown.HibernationDirectory"
+	// ConfigBurndownHibernationColdChunks defines after how many untouched chunks a file stays on disk.
+	ConfigBurndownHibernationColdChunks = "Burndown.HibernationColdChunks"
 	// ConfigBurndownDebug defines the debug mode configuration constant.
 	ConfigBurndownDebug = "Burndown.Debug"
 	// ConfigBurndownGoroutines defines the goroutines configuration constant.
 	ConfigBurndownGoroutines = "Burndown.Goroutines"
+	// ConfigBurndownGranularityUnit is the configuration key for the tracked unit (line or token).
+	ConfigBurndownGranularityUnit = "Burndown.GranularityUnit"
+	// ConfigBurndownDirs is the configuration key for per-directory histories ("depth=N" or "projects").
+	ConfigBurndownDirs = "Burndown.Dirs"
 	// DefaultBurndownGranularity defines the default granularity in days.
 	DefaultBurndownGranularity = 30
 	// DefaultBurndownSampling defines the default sampling in ticks.
@@ -123,13 +157,15 @@ const (
 	DefaultBurndownSampling = 30
 	// DefaultBurndownHibernationThreshold defines the default node count threshold for hibernation.
 	DefaultBurndownHibernationThreshold = 1000
+	// DefaultBurndownHibernationColdChunks defines the default number of untouched chunks before a file goes cold.
+	DefaultBurndownHibernationColdChunks = 2
 	// Sentinel value representing the current author.
 	authorSelf = identity.AuthorMissing - 1
 )
 
 // NewHistoryAnalyzer creates a new burndown history analyzer.
 func NewHistoryAnalyzer() *HistoryAnalyzer {
-	ha := &HistoryAnalyzer{}
+	ha := &HistoryAnalyzer{HibernationToDisk: true, coAuthorWeight: plumbing.DefaultCoAuthorWeight}
 
 	ha.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
 		Desc: analyze.Descriptor{
@@ -140,17 +176,28 @@ func NewHistoryAnalyzer() *HistoryAnalyzer {
 		},
 		Sequential:         true,
 		CPUHeavyFlag:       false,
+		Caps:               &analyze.Capabilities{NeedsBlobs: true, NeedsDiffs: true, Memory: analyze.MemoryHigh},
 		EstimatedStateSize: 950 * 1024, //nolint:mnd // Estimated size.
 		EstimatedTCSize:    74 * 1024,  //nolint:mnd // Estimated size.
 		ComputeMetricsFn:   ComputeAllMetrics,
 		AggregatorFn:       ha.NewAggregator,
 		TicksToReportFn: func(ctx context.Context, ticks []analyze.TICK) analyze.Report {
-			return ticksToReport(
+			report := ticksToReport(
 				ctx, ticks,
 				ha.Granularity, ha.Sampling, ha.PeopleNumber,
 				ha.TrackFiles, ha.TickSize,
 				ha.reversedPeopleDict, ha.pathInterner,
 			)
+
+			if ha.tickCalendar != nil && len(report) > 0 {
+				report["TickCalendar"] = ha.tickCalendar
+			}
+
+			if ha.GranularityUnit == UnitToken && len(report) > 0 {
+				report["GranularityUnit"] = ha.GranularityUnit
+			}
+
+			return report
 		},
 	}
 
@@ -197,18 +244,26 @@ func (b *HistoryAnalyzer) ListConfigurationOptions() []pipeline.ConfigurationOpt
 		},
 		{
 			Name:        ConfigBurndownHibernationToDisk,
-			Description: "If true, save hibernated state to disk (no-op with default treap timeline).",
+			Description: "If true, spill file timelines to disk between chunks.",
 			Flag:        "burndown-hibernation-disk",
 			Type:        pipeline.BoolConfigurationOption,
 			Default:     true,
 		},
 		{
 			Name:        ConfigBurndownHibernationDirectory,
-			Description: "Temporary directory for hibernated state (no-op with default treap timeline).",
+			Description: "Parent directory for hibernated state; the system temp directory if empty.",
 			Flag:        "burndown-hibernation-dir",
 			Type:        pipeline.PathConfigurationOption,
 			Default:     "",
 		},
+		{
+			Name: ConfigBurndownHibernationColdChunks,
+			Description: "Chunks a file may stay untouched before it is left on disk " +
+				"and reloaded on first access (0 keeps every file in memory).",
+			Flag:    "burndown-hibernation-cold-chunks",
+			Type:    pipeline.IntConfigurationOption,
+			Default: DefaultBurndownHibernationColdChunks,
+		},
 		{
 			Name:        ConfigBurndownDebug,
 			Description: "Validate the trees at each step.",
@@ -223,11 +278,29 @@ func (b *HistoryAnalyzer) ListConfigurationOptions() []pipeline.ConfigurationOpt
 			Type:        pipeline.IntConfigurationOption,
 			Default:     runtime.NumCPU(),
 		},
+		{
+			Name: ConfigBurndownGranularityUnit,
+			Description: "What to track the age of: line, or token for per-token survival " +
+				"in dense code where one line holds many edits.",
+			Flag:    "burndown-granularity-unit",
+			Type:    pipeline.StringConfigurationOption,
+			Default: string(UnitLine),
+		},
+		{
+			Name: ConfigBurndownDirs,
+			Description: "Record statistics per directory prefix, e.g. depth=2, " +
+				"or per discovered project with projects; cheaper than --burndown-files.",
+			Flag:    "burndown-dirs",
+			Type:    pipeline.StringConfigurationOption,
+			Default: "",
+		},
 	}
 }
 
 // Configure sets up the analyzer with the provided facts.
 func (b *HistoryAnalyzer) Configure(facts map[string]any) error {
+	b.coAuthorWeight = plumbing.CoAuthorWeight(facts)
+
 	if val, exists := facts[ConfigBurndownGranularity].(int); exists {
 		b.Granularity = val
 	}
@@ -259,6 +332,14 @@ func (b *HistoryAnalyzer) Configure(facts map[string]any) error {
 		b.HibernationDirectory = val
 	}
 
+	if val, exists := facts[pkgplumbing.FactStoreDir].(string); exists && b.HibernationDirectory == "" {
+		b.HibernationDirectory = val
+	}
+
+	if val, exists := facts[ConfigBurndownHibernationColdChunks].(int); exists {
+		b.ColdChunks = val
+	}
+
 	if val, exists := facts[ConfigBurndownDebug].(bool); exists {
 		b.Debug = val
 	}
@@ -267,10 +348,41 @@ func (b *HistoryAnalyzer) Configure(facts map[string]any) error {
 		b.Goroutines = val
 	}
 
+	if val, exists := facts[ConfigBurndownGranularityUnit].(string); exists {
+		unit, err := parseGranularityUnit(val)
+		if err != nil {
+			return err
+		}
+
+		b.GranularityUnit = unit
+		// Token mode reads the UAST trees of the changed files.
+		if b.BaseHistoryAnalyzer != nil && b.Caps != nil {
+			b.Caps.NeedsUAST = unit == UnitToken
+		}
+	}
+
+	if val, exists := facts[ConfigBurndownDirs].(string); exists {
+		depth, byProject, err := parseDirDepth(val)
+		if err != nil {
+			return err
+		}
+
+		b.DirDepth = depth
+		b.DirsByProject = byProject
+	}
+
+	if val, exists := facts[pkgplumbing.FactProjects].(pkgplumbing.Projects); exists {
+		b.Projects = val
+	}
+
 	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
 		b.TickSize = val
 	}
 
+	if val, exists := facts[pkgplumbing.FactTickCalendar].(*pkgplumbing.TickCalendar); exists {
+		b.tickCalendar = val
+	}
+
 	return nil
 }
 
@@ -348,6 +460,7 @@ func (b *HistoryAnalyzer) Initialize(repository *gitlib.Repository) error {
 
 	b.shardSpills = make([]shardSpillState, b.Goroutines)
 	b.spillDir = ""
+	b.chunk = 0
 	b.renames = map[string]string{}
 	b.renamesReverse = map[string]map[string]bool{}
 	b.tick = 0
@@ -395,6 +508,42 @@ func (b *HistoryAnalyzer) ensureCapacity(shard *Shard, id PathID) {
 	shard.fileHistoriesByID = newHistories
 }
 
+// fileByID returns the file for id, reloading it from disk first if Boot left
+// it cold, and records that the current chunk touched it. Nil means no file.
+func (b *HistoryAnalyzer) fileByID(shard *Shard, id PathID) (*burndown.File, error) {
+	file := shard.filesByID[id]
+
+	if file == nil && shard.spill != nil {
+		if ref, ok := shard.spill.cold[id]; ok {
+			record, err := shard.spill.readSpillRecord(ref)
+			if err != nil {
+				return nil, fmt.Errorf("reload %s: %w", b.pathInterner.Lookup(id), err)
+			}
+
+			shard.spill.dropCold(id)
+			restoreSpillRecord(shard, record)
+
+			file = shard.filesByID[id]
+			file.ReplaceUpdaters(b.createUpdaters(shard, id))
+		}
+	}
+
+	if file != nil {
+		b.touch(shard, id)
+	}
+
+	return file, nil
+}
+
+// touch records that the current chunk modified the file id.
+func (b *HistoryAnalyzer) touch(shard *Shard, id PathID) {
+	if shard.lastChunkByID == nil {
+		shard.lastChunkByID = map[PathID]int{}
+	}
+
+	shard.lastChunkByID[id] = b.chunk
+}
+
 // removeActiveID removes id from shard.activeIDs (swap-remove) (Track B).
 func (b *HistoryAnalyzer) removeActiveID(shard *Shard, id PathID) {
 	for i, aid := range shard.activeIDs {
@@ -409,12 +558,19 @@ func (b *HistoryAnalyzer) removeActiveID(shard *Shard, id PathID) {
 }
 
 // Consume processes a single commit with the provided dependency results.
-func (b *HistoryAnalyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+func (b *HistoryAnalyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
 	author := b.Identity.AuthorID
 	tick := b.Ticks.Tick
 	isMerge := ac.IsMerge
 	b.isMerge = isMerge
 
+	prepared, ok := b.takePrepared(ac.Commit)
+	if !ok && b.GranularityUnit == UnitToken {
+		prepared = b.prepareTokens(b.TreeDiff.Changes, b.BlobCache.Cache, b.uastChanges(ctx))
+	}
+
+	b.ahead = prepared
+
 	b.resetDeltaBuffers()
 
 	if !isMerge {
@@ -431,16 +587,17 @@ func (b *HistoryAnalyzer) Consume(_ context.Context, ac *analyze.Context) (analy
 
 	cache := b.BlobCache.Cache
 	fileDiffs := b.FileDiff.FileDiffs
+	coAuthors := b.Identity.CoAuthorIDs
 	shardChanges, renames := b.groupChangesByShard(b.TreeDiff.Changes)
 
-	err := b.processShardChanges(shardChanges, author, cache, fileDiffs)
+	err := b.processShardChanges(shardChanges, author, coAuthors, cache, fileDiffs)
 	if err != nil {
 		return analyze.TC{}, err
 	}
 
 	renameRouter := plumbing.ChangeRouter{
 		OnRename: func(_, _ string, change *gitlib.Change) error {
-			return b.handleModificationRename(change, author, cache, fileDiffs)
+			return b.handleModificationRename(change, b.credit(author, coAuthors), cache, fileDiffs)
 		},
 	}
 
@@ -483,16 +640,17 @@ func (b *HistoryAnalyzer) ConsumePrepared(prepared *analyze.PreparedCommit) erro
 	cache := make(map[gitlib.Hash]*pkgplumbing.CachedBlob, len(prepared.Cache))
 	maps.Copy(cache, prepared.Cache)
 
+	coAuthors := prepared.CoAuthorIDs
 	shardChanges, renames := b.groupChangesByShard(prepared.Changes)
 
-	err := b.processShardChanges(shardChanges, author, cache, prepared.FileDiffs)
+	err := b.processShardChanges(shardChanges, author, coAuthors, cache, prepared.FileDiffs)
 	if err != nil {
 		return err
 	}
 
 	renameRouter := plumbing.ChangeRouter{
 		OnRename: func(_, _ string, change *gitlib.Change) error {
-			return b.handleModificationRename(change, author, cache, prepared.FileDiffs)
+			return b.handleModificationRename(change, b.credit(author, coAuthors), cache, prepared.FileDiffs)
 		},
 	}
 
@@ -536,13 +694,15 @@ func (b *HistoryAnalyzer) groupChangesByShard(
 }
 
 // processShardChanges processes grouped changes across shards in parallel.
+// The lines of each change are credited to the author and co-authors.
 func (b *HistoryAnalyzer) processShardChanges(
-	shardChanges [][]*gitlib.Change, author int, cache map[gitlib.Hash]*pkgplumbing.CachedBlob,
-	fileDiffs map[string]pkgplumbing.FileDiffData,
+	shardChanges [][]*gitlib.Change, author int, coAuthors []int,
+	cache map[gitlib.Hash]*pkgplumbing.CachedBlob, fileDiffs map[string]pkgplumbing.FileDiffData,
 ) error {
 	var wg sync.WaitGroup
 
 	errs := make([]error, b.Goroutines)
+	credited := b.credit(author, coAuthors)
 
 	for i := range b.Goroutines {
 		changes := shardChanges[i]
@@ -559,13 +719,13 @@ func (b *HistoryAnalyzer) processShardChanges(
 
 			router := plumbing.ChangeRouter{
 				OnInsert: func(change *gitlib.Change) error {
-					return b.handleInsertion(shard, change, author, cache)
+					return b.handleInsertion(shard, change, credited, cache)
 				},
 				OnDelete: func(change *gitlib.Change) error {
-					return b.handleDeletion(shard, change, author, cache)
+					return b.handleDeletion(shard, change, credited, cache)
 				},
 				OnModify: func(change *gitlib.Change) error {
-					return b.handleModification(shard, change, author, cache, fileDiffs)
+					return b.handleModification(shard, change, credited, cache, fileDiffs)
 				},
 			}
 
@@ -587,6 +747,46 @@ func (b *HistoryAnalyzer) processShardChanges(
 	return nil
 }
 
+// credit names the identities credited with the lines of a commit: its
+// author and its co-authors, each co-author weighing weight against the
+// author's 1.
+type credit struct {
+	author    int
+	coAuthors []int
+	weight    float64
+}
+
+// creditRun is a run of consecutive inserted lines credited to one identity.
+type creditRun struct {
+	author int
+	lines  int
+}
+
+// credit returns the credit of a commit by author and coAuthors.
+func (b *HistoryAnalyzer) credit(author int, coAuthors []int) credit {
+	return credit{author: author, coAuthors: coAuthors, weight: b.coAuthorWeight}
+}
+
+// split divides lines inserted into one file between the credited identities
+// in proportion to their weights, as plumbing.CreditShares does, and returns
+// the runs in author, co-author order. The first run is never omitted.
+func (c credit) split(lines int) []creditRun {
+	if len(c.coAuthors) == 0 {
+		return []creditRun{{author: c.author, lines: lines}}
+	}
+
+	shares := plumbing.CreditShares(lines, len(c.coAuthors), c.weight)
+	runs := []creditRun{{author: c.author, lines: shares[0]}}
+
+	for i, coAuthor := range c.coAuthors {
+		if shares[i+1] > 0 {
+			runs = append(runs, creditRun{author: coAuthor, lines: shares[i+1]})
+		}
+	}
+
+	return runs
+}
+
 // Fork creates a copy of the analyzer for parallel processing.
 func (b *HistoryAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
 	res := make([]analyze.HistoryAnalyzer, n)
@@ -607,6 +807,7 @@ func (b *HistoryAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
 			// Copy configuration.
 			HibernationDirectory: b.HibernationDirectory,
 			HibernationThreshold: b.HibernationThreshold,
+			ColdChunks:           b.ColdChunks,
 			Granularity:          b.Granularity,
 			PeopleNumber:         b.PeopleNumber,
 			TickSize:             b.TickSize,
@@ -615,7 +816,12 @@ func (b *HistoryAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
 			Debug:                b.Debug,
 			TrackFiles:           b.TrackFiles,
 			HibernationToDisk:    b.HibernationToDisk,
+			GranularityUnit:      b.GranularityUnit,
+			DirDepth:             b.DirDepth,
+			DirsByProject:        b.DirsByProject,
+			Projects:             b.Projects,
 			reversedPeopleDict:   b.reversedPeopleDict,
+			coAuthorWeight:       b.coAuthorWeight,
 		}
 
 		// Create fresh shards for this fork.
@@ -699,11 +905,12 @@ func (b *HistoryAnalyzer) mergeTicks(other *HistoryAnalyzer) {
 // SnapshotPlumbing captures the current plumbing state.
 func (b *HistoryAnalyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
 	return plumbing.Snapshot{
-		Changes:   b.TreeDiff.Changes,
-		BlobCache: b.BlobCache.Cache,
-		FileDiffs: b.FileDiff.FileDiffs,
-		Tick:      b.Ticks.Tick,
-		AuthorID:  b.Identity.AuthorID,
+		Changes:     b.TreeDiff.Changes,
+		BlobCache:   b.BlobCache.Cache,
+		FileDiffs:   b.FileDiff.FileDiffs,
+		Tick:        b.Ticks.Tick,
+		AuthorID:    b.Identity.AuthorID,
+		CoAuthorIDs: b.Identity.CoAuthorIDs,
 	}
 }
 
@@ -719,24 +926,33 @@ func (b *HistoryAnalyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
 	b.FileDiff.FileDiffs = snapshot.FileDiffs
 	b.Ticks.Tick = snapshot.Tick
 	b.Identity.AuthorID = snapshot.AuthorID
+	b.Identity.CoAuthorIDs = snapshot.CoAuthorIDs
 }
 
 // ReleaseSnapshot is a no-op for burndown (no UAST resources).
 func (b *HistoryAnalyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
 
 // Hibernate releases resources between processing phases.
-// Spills file treaps and file histories to disk to free memory.
+// When HibernationToDisk is set, spills resident file treaps and file
+// histories to disk to free memory; files that Boot left cold stay where they are.
 // History maps no longer live in shards — they are in the aggregator.
 func (b *HistoryAnalyzer) Hibernate() error {
-	err := b.ensureSpillDir()
-	if err != nil {
-		return fmt.Errorf("burndown spill dir: %w", err)
+	b.dropPrepared()
+	b.ahead = preparedCommit{}
+
+	if b.HibernationToDisk {
+		err := b.ensureSpillDir()
+		if err != nil {
+			return fmt.Errorf("burndown spill dir: %w", err)
+		}
 	}
 
 	for i, shard := range b.shards {
 		shard.mu.Lock()
 
 		if b.spillDir != "" {
+			shard.spill = &b.shardSpills[i]
+
 			// Spill file treaps and file histories to disk, freeing treap nodes.
 			filesErr := spillShardFiles(shard, &b.shardSpills[i], b.spillDir, i)
 			if filesErr != nil {
@@ -753,16 +969,19 @@ func (b *HistoryAnalyzer) Hibernate() error {
 		shard.mu.Unlock()
 	}
 
+	b.chunk++
+
 	return nil
 }
 
-// ensureSpillDir creates the parent temp directory for shard history spills.
+// ensureSpillDir creates the parent temp directory for shard history spills,
+// inside HibernationDirectory when one is configured.
 func (b *HistoryAnalyzer) ensureSpillDir() error {
 	if b.spillDir != "" {
 		return nil
 	}
 
-	dir, err := os.MkdirTemp("", "codefang-burndown-spill-*")
+	dir, err := os.MkdirTemp(b.HibernationDirectory, SpillDirPattern)
 	if err != nil {
 		return fmt.Errorf("create burndown spill dir: %w", err)
 	}
@@ -773,33 +992,25 @@ func (b *HistoryAnalyzer) ensureSpillDir() error {
 }
 
 // Boot performs early initialization before repository processing.
-// Restores spilled file treaps and file histories, re-attaches updaters,
-// and ensures per-shard tracking maps are ready for the next chunk.
+// Restores the recently touched file treaps and file histories from the last
+// spill, re-attaches their updaters, and ensures per-shard tracking maps are
+// ready for the next chunk. Files idle for more than ColdChunks chunks stay on
+// disk until first accessed.
 func (b *HistoryAnalyzer) Boot() error {
 	for i, shard := range b.shards {
 		shard.mu.Lock()
 
-		// Restore file treaps and file histories from the last spill.
-		if b.spillDir != "" && i < len(b.shardSpills) && b.shardSpills[i].fileSpillN > 0 {
-			err := loadSpilledFiles(shard, &b.shardSpills[i])
+		if b.spillDir != "" && i < len(b.shardSpills) {
+			restored, err := bootSpilledFiles(shard, &b.shardSpills[i], b.chunk, b.ColdChunks)
 			if err != nil {
 				shard.mu.Unlock()
 
 				return fmt.Errorf("restore shard %d files: %w", i, err)
 			}
 
-			// Re-attach updaters to restored files.
-			// createUpdaters() closures capture shard map references that were
-			// invalidated when fileHistoriesByID was restored from disk.
-			for _, id := range shard.activeIDs {
-				if int(id) >= len(shard.filesByID) {
-					continue
-				}
-
-				file := shard.filesByID[id]
-				if file != nil {
-					file.ReplaceUpdaters(b.createUpdaters(shard, id))
-				}
+			// Restored files come back without updaters.
+			for _, id := range restored {
+				shard.filesByID[id].ReplaceUpdaters(b.createUpdaters(shard, id))
 			}
 		}
 
@@ -837,6 +1048,27 @@ func (b *HistoryAnalyzer) WorkingStateSize() int64 { return workingStateSize }
 // AvgTCSize returns the estimated bytes of TC payload per commit.
 func (b *HistoryAnalyzer) AvgTCSize() int64 { return avgTCSize }
 
+// SpillDirs returns the directory holding the shard spill files, if any.
+func (b *HistoryAnalyzer) SpillDirs() []string {
+	if b.spillDir == "" {
+		return nil
+	}
+
+	return []string{b.spillDir}
+}
+
+// SpillBytesWritten returns the total size of the shard spill files written
+// so far, including files already removed.
+func (b *HistoryAnalyzer) SpillBytesWritten() int64 {
+	var total int64
+
+	for i := range b.shardSpills {
+		total += b.shardSpills[i].written
+	}
+
+	return total
+}
+
 // CleanupSpills removes all shard spill temp files. Safe to call multiple times.
 func (b *HistoryAnalyzer) CleanupSpills() {
 	for i := range b.shardSpills {
@@ -955,6 +1187,10 @@ func (b *HistoryAnalyzer) resetDeltaBuffers() {
 		if b.TrackFiles {
 			shard.deltas.fileDeltas = map[PathID]sparseHistory{}
 		}
+
+		if b.tracksDirs() {
+			shard.deltas.dirDeltas = map[string]sparseHistory{}
+		}
 	}
 }
 
@@ -969,6 +1205,7 @@ func (b *HistoryAnalyzer) collectDeltas() *CommitResult {
 		b.collectPeopleDeltas(result, shard)
 		b.collectMatrixDeltas(result, shard)
 		b.collectFileDeltas(result, shard)
+		b.collectDirDeltas(result, shard)
 	}
 
 	if b.TrackFiles && b.PeopleNumber > 0 {
@@ -1182,7 +1419,7 @@ func (b *HistoryAnalyzer) updateMatrix(shard *Shard, currentTime, previousTime,
 }
 
 func (b *HistoryAnalyzer) createUpdaters(shard *Shard, pathID PathID) []burndown.Updater {
-	const maxUpdaters = 4 // global + file + author + matrix.
+	const maxUpdaters = 5 // global + file + dir + author + matrix.
 
 	updaters := make([]burndown.Updater, 0, maxUpdaters)
 
@@ -1196,6 +1433,14 @@ func (b *HistoryAnalyzer) createUpdaters(shard *Shard, pathID PathID) []burndown
 		})
 	}
 
+	if b.tracksDirs() {
+		dir := b.dirOf(b.pathInterner.Lookup(pathID))
+
+		updaters = append(updaters, func(currentTime, previousTime, delta int) {
+			b.updateDir(shard, dir, currentTime, previousTime, delta)
+		})
+	}
+
 	if b.PeopleNumber > 0 {
 		updaters = append(updaters, func(currentTime, previousTime, delta int) {
 			b.updateAuthor(shard, currentTime, previousTime, delta)
@@ -1220,14 +1465,14 @@ func (b *HistoryAnalyzer) newFile(
 }
 
 func (b *HistoryAnalyzer) handleInsertion(
-	shard *Shard, change *gitlib.Change, author int, cache map[gitlib.Hash]*pkgplumbing.CachedBlob,
+	shard *Shard, change *gitlib.Change, credited credit, cache map[gitlib.Hash]*pkgplumbing.CachedBlob,
 ) error {
 	blob := cache[change.To.Hash]
 	if blob == nil {
 		return fmt.Errorf("%w for insertion %s (%s)", errMissingBlob, change.To.Name, change.To.Hash)
 	}
 
-	lines, err := blob.CountLines()
+	lines, err := b.unitCount(blob, change.To.Name)
 	if err != nil {
 		return nil
 	}
@@ -1236,13 +1481,28 @@ func (b *HistoryAnalyzer) handleInsertion(
 	id := b.pathInterner.Intern(name)
 	b.ensureCapacity(shard, id)
 
-	if shard.filesByID[id] != nil {
+	existing, err := b.fileByID(shard, id)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
 		return fmt.Errorf("%w: %s", errFileAlreadyExists, name)
 	}
 
-	file := b.newFile(shard, id, author, b.tick, lines)
+	// The lines of a co-authored file are credited in consecutive runs.
+	runs := credited.split(lines)
+	file := b.newFile(shard, id, runs[0].author, b.tick, runs[0].lines)
+	pos := runs[0].lines
+
+	for _, run := range runs[1:] {
+		file.Update(b.packPersonWithTick(run.author, b.tick), pos, run.lines, 0)
+		pos += run.lines
+	}
+
 	shard.filesByID[id] = file
 	shard.activeIDs = append(shard.activeIDs, id)
+	b.touch(shard, id)
 
 	delete(shard.deletionsByID, id)
 
@@ -1254,7 +1514,7 @@ func (b *HistoryAnalyzer) handleInsertion(
 }
 
 func (b *HistoryAnalyzer) handleDeletion(
-	shard *Shard, change *gitlib.Change, author int, cache map[gitlib.Hash]*pkgplumbing.CachedBlob,
+	shard *Shard, change *gitlib.Change, credited credit, cache map[gitlib.Hash]*pkgplumbing.CachedBlob,
 ) error {
 	var name string
 	if change.To.Hash != gitlib.ZeroHash() {
@@ -1266,9 +1526,9 @@ func (b *HistoryAnalyzer) handleDeletion(
 	id := b.pathInterner.Intern(name)
 	b.ensureCapacity(shard, id)
 
-	file := shard.filesByID[id]
-	if file == nil {
-		return nil
+	file, err := b.fileByID(shard, id)
+	if err != nil || file == nil {
+		return err
 	}
 
 	blob := cache[change.From.Hash]
@@ -1276,7 +1536,7 @@ func (b *HistoryAnalyzer) handleDeletion(
 		return fmt.Errorf("%w for deletion %s (%s)", errMissingBlob, name, change.From.Hash)
 	}
 
-	lines, err := blob.CountLines()
+	lines, err := b.unitCount(blob, change.From.Name)
 	if err != nil {
 		return fmt.Errorf("%w: %s", errUnexpectedBinary, name)
 	}
@@ -1290,11 +1550,12 @@ func (b *HistoryAnalyzer) handleDeletion(
 		tick = 0
 	}
 
-	file.Update(b.packPersonWithTick(author, tick), 0, 0, lines)
+	file.Update(b.packPersonWithTick(credited.author, tick), 0, 0, lines)
 	file.Delete()
 
 	shard.filesByID[id] = nil
 	shard.fileHistoriesByID[id] = nil
+	delete(shard.lastChunkByID, id)
 	b.removeActiveID(shard, id)
 
 	stack := []string{name}
@@ -1334,7 +1595,7 @@ func (b *HistoryAnalyzer) handleDeletion(
 }
 
 func (b *HistoryAnalyzer) handleModification(
-	shard *Shard, change *gitlib.Change, author int,
+	shard *Shard, change *gitlib.Change, credited credit,
 	cache map[gitlib.Hash]*pkgplumbing.CachedBlob, diffs map[string]pkgplumbing.FileDiffData,
 ) error {
 	// This method handles modification WITHOUT rename (checked in Consume).
@@ -1345,9 +1606,13 @@ func (b *HistoryAnalyzer) handleModification(
 		shard.mergedByID[id] = true
 	}
 
-	file := shard.filesByID[id]
+	file, err := b.fileByID(shard, id)
+	if err != nil {
+		return err
+	}
+
 	if file == nil {
-		return b.handleInsertion(shard, change, author, cache)
+		return b.handleInsertion(shard, change, credited, cache)
 	}
 
 	blobFrom := cache[change.From.Hash]
@@ -1365,27 +1630,27 @@ func (b *HistoryAnalyzer) handleModification(
 	_, errTo := blobTo.CountLines()
 	if !errors.Is(errFrom, errTo) { // Error comparison is intentional.
 		if errFrom != nil {
-			return b.handleInsertion(shard, change, author, cache)
+			return b.handleInsertion(shard, change, credited, cache)
 		}
 
-		return b.handleDeletion(shard, change, author, cache)
+		return b.handleDeletion(shard, change, credited, cache)
 	} else if errFrom != nil {
 		return nil
 	}
 
-	thisDiffs := diffs[change.To.Name]
+	thisDiffs := b.unitDiff(change, blobFrom, blobTo, diffs[change.To.Name])
 	if file.Len() != thisDiffs.OldLinesOfCode {
 		return fmt.Errorf("%w: %s src %d != %d",
 			errInternalIntegritySource, change.To.Name, thisDiffs.OldLinesOfCode, file.Len())
 	}
 
-	b.applyDiffs(file, thisDiffs, author)
+	b.applyDiffs(file, thisDiffs, credited)
 
 	return nil
 }
 
 func (b *HistoryAnalyzer) handleModificationRename(
-	change *gitlib.Change, author int,
+	change *gitlib.Change, credited credit,
 	cache map[gitlib.Hash]*pkgplumbing.CachedBlob, diffs map[string]pkgplumbing.FileDiffData,
 ) error {
 	// Handles modification WITH rename (From != To).
@@ -1394,12 +1659,16 @@ func (b *HistoryAnalyzer) handleModificationRename(
 	fromID := b.pathInterner.Intern(change.From.Name)
 	b.ensureCapacity(shardFrom, fromID)
 
-	file := shardFrom.filesByID[fromID]
+	file, err := b.fileByID(shardFrom, fromID)
+	if err != nil {
+		return err
+	}
+
 	if file == nil {
 		// Fallback to insertion in To shard.
 		shardTo := b.getShard(change.To.Name)
 
-		return b.handleInsertion(shardTo, change, author, cache)
+		return b.handleInsertion(shardTo, change, credited, cache)
 	}
 
 	if change.To.Name != change.From.Name {
@@ -1431,7 +1700,7 @@ func (b *HistoryAnalyzer) handleModificationRename(
 		if errFrom != nil {
 			shardTo := b.getShard(change.To.Name)
 
-			return b.handleInsertion(shardTo, change, author, cache)
+			return b.handleInsertion(shardTo, change, credited, cache)
 		}
 		// HandleDeletion on new name? Or old?
 		// Logic suggests if it became binary/error, we delete it.
@@ -1439,27 +1708,30 @@ func (b *HistoryAnalyzer) handleModificationRename(
 		// Let's defer to deletion logic on To name.
 		shardTo := b.getShard(change.To.Name)
 
-		return b.handleDeletion(shardTo, change, author, cache)
+		return b.handleDeletion(shardTo, change, credited, cache)
 	} else if errFrom != nil {
 		return nil
 	}
 
-	thisDiffs := diffs[change.To.Name]
+	thisDiffs := b.unitDiff(change, blobFrom, blobTo, diffs[change.To.Name])
 	if file.Len() != thisDiffs.OldLinesOfCode {
 		return fmt.Errorf("%w: %s src %d != %d",
 			errInternalIntegritySource, change.To.Name, thisDiffs.OldLinesOfCode, file.Len())
 	}
 
-	b.applyDiffs(file, thisDiffs, author)
+	b.applyDiffs(file, thisDiffs, credited)
 
 	return nil
 }
 
 // diffApplier holds state for applying a sequence of diffs to a burndown file.
+// Deleted lines are credited to the commit author, inserted lines to the
+// runs of the commit's credit in order.
 type diffApplier struct {
 	b        *HistoryAnalyzer
 	file     *burndown.File
 	author   int
+	runs     []creditRun
 	position int
 	pending  diffmatchpatch.Diff
 }
@@ -1468,20 +1740,55 @@ func (d *diffApplier) packValue() int {
 	return d.b.packPersonWithTick(d.author, d.b.tick)
 }
 
-func (d *diffApplier) applySingle(edit diffmatchpatch.Diff) {
-	length := utf8.RuneCountInString(edit.Text)
-	if edit.Type == diffmatchpatch.DiffInsert {
-		d.file.Update(d.packValue(), d.position, length, 0)
-		d.position += length
-	} else {
-		d.file.Update(d.packValue(), d.position, 0, length)
+// insert inserts length lines at the current position in place of deleted
+// lines, crediting them to the next runs.
+func (d *diffApplier) insert(length, deleted int) {
+	for length > 0 {
+		for len(d.runs) > 1 && d.runs[0].lines == 0 {
+			d.runs = d.runs[1:]
+		}
+
+		run := &d.runs[0]
+
+		if deleted > 0 && run.author != d.author {
+			d.update(d.packValue(), 0, deleted)
+			deleted = 0
+		}
+
+		n := length
+		if len(d.runs) > 1 {
+			n = min(length, run.lines)
+		}
+
+		d.update(d.b.packPersonWithTick(run.author, d.b.tick), n, deleted)
+		run.lines -= n
+		d.position += n
+		length -= n
+		deleted = 0
 	}
 
+	if deleted > 0 {
+		d.update(d.packValue(), 0, deleted)
+	}
+}
+
+func (d *diffApplier) update(value, insLength, delLength int) {
+	d.file.Update(value, d.position, insLength, delLength)
+
 	if d.b.Debug {
 		d.file.Validate()
 	}
 }
 
+func (d *diffApplier) applySingle(edit diffmatchpatch.Diff) {
+	length := utf8.RuneCountInString(edit.Text)
+	if edit.Type == diffmatchpatch.DiffInsert {
+		d.insert(length, 0)
+	} else {
+		d.update(d.packValue(), 0, length)
+	}
+}
+
 func (d *diffApplier) flushPending() {
 	if d.pending.Text != "" {
 		d.applySingle(d.pending)
@@ -1490,16 +1797,8 @@ func (d *diffApplier) flushPending() {
 }
 
 func (d *diffApplier) handleInsert(edit diffmatchpatch.Diff) {
-	length := utf8.RuneCountInString(edit.Text)
-
 	if d.pending.Text != "" {
-		d.file.Update(d.packValue(), d.position, length, utf8.RuneCountInString(d.pending.Text))
-
-		if d.b.Debug {
-			d.file.Validate()
-		}
-
-		d.position += length
+		d.insert(utf8.RuneCountInString(edit.Text), utf8.RuneCountInString(d.pending.Text))
 		d.pending.Text = ""
 	} else {
 		d.pending = edit
@@ -1507,9 +1806,20 @@ func (d *diffApplier) handleInsert(edit diffmatchpatch.Diff) {
 }
 
 func (b *HistoryAnalyzer) applyDiffs(
-	file *burndown.File, thisDiffs pkgplumbing.FileDiffData, author int,
+	file *burndown.File, thisDiffs pkgplumbing.FileDiffData, credited credit,
 ) {
-	da := &diffApplier{b: b, file: file, author: author, pending: diffmatchpatch.Diff{Text: ""}}
+	inserted := 0
+
+	for _, edit := range thisDiffs.Diffs {
+		if edit.Type == diffmatchpatch.DiffInsert {
+			inserted += utf8.RuneCountInString(edit.Text)
+		}
+	}
+
+	da := &diffApplier{
+		b: b, file: file, author: credited.author, runs: credited.split(inserted),
+		pending: diffmatchpatch.Diff{Text: ""},
+	}
 
 	for _, edit := range thisDiffs.Diffs {
 		switch edit.Type {
@@ -1550,7 +1860,11 @@ func (b *HistoryAnalyzer) handleRename(from, to string) error {
 	toID := b.pathInterner.Intern(to)
 	b.ensureCapacity(shardFrom, fromID)
 
-	file := shardFrom.filesByID[fromID]
+	file, err := b.fileByID(shardFrom, fromID)
+	if err != nil {
+		return err
+	}
+
 	if file == nil {
 		return fmt.Errorf("%w: %s > %s", errFileNotExist, from, to)
 	}
@@ -1558,6 +1872,14 @@ func (b *HistoryAnalyzer) handleRename(from, to string) error {
 	shardTo := b.getShard(to)
 	b.ensureCapacity(shardTo, toID)
 
+	// The rename replaces whatever a cold record at the target still holds.
+	if shardTo.spill != nil {
+		shardTo.spill.dropCold(toID)
+	}
+
+	delete(shardFrom.lastChunkByID, fromID)
+	b.touch(shardTo, toID)
+
 	if shardFrom == shardTo {
 		shardFrom.filesByID[fromID] = nil
 		b.removeActiveID(shardFrom, fromID)
@@ -3,15 +3,19 @@ package burndown
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/burndown"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 func TestHistoryAnalyzer_Name(t *testing.T) {
@@ -665,6 +669,22 @@ func TestConfigure_WithPeopleTracking(t *testing.T) {
 	assert.Equal(t, 8, b.Goroutines)
 }
 
+func TestConfigure_StoreDir(t *testing.T) {
+	t.Parallel()
+
+	b := NewHistoryAnalyzer()
+	require.NoError(t, b.Configure(map[string]any{pkgplumbing.FactStoreDir: "/var/lib/codefang"}))
+	assert.Equal(t, "/var/lib/codefang", b.HibernationDirectory)
+
+	// An explicit hibernation directory wins over the run-wide store dir.
+	b = NewHistoryAnalyzer()
+	require.NoError(t, b.Configure(map[string]any{
+		ConfigBurndownHibernationDirectory: "/scratch",
+		pkgplumbing.FactStoreDir:           "/var/lib/codefang",
+	}))
+	assert.Equal(t, "/scratch", b.HibernationDirectory)
+}
+
 func TestConfigure_NegativePeopleCount(t *testing.T) {
 	t.Parallel()
 
@@ -901,3 +921,63 @@ func TestFillDenseHistory(t *testing.T) {
 	// Second sample: forward-filled from first.
 	assert.Equal(t, int64(100), result[1][0])
 }
+
+func TestCoAuthoredCommit_SplitsLinesOfOneFile(t *testing.T) {
+	t.Parallel()
+
+	b := NewHistoryAnalyzer()
+	b.Goroutines = 1
+	b.PeopleNumber = 3
+	require.NoError(t, b.Initialize(nil))
+	b.resetDeltaBuffers()
+
+	oldHash := gitlib.NewHash("1111111111111111111111111111111111111111")
+	newHash := gitlib.NewHash("2222222222222222222222222222222222222222")
+	cache := map[gitlib.Hash]*pkgplumbing.CachedBlob{
+		oldHash: gitlib.NewCachedBlobWithHashForTest(oldHash, []byte(strings.Repeat("old\n", 10))),
+		newHash: gitlib.NewCachedBlobWithHashForTest(newHash, []byte(strings.Repeat("old\n", 10)+strings.Repeat("new\n", 4))),
+	}
+
+	// A pair inserts one file: the author and the co-author get half each.
+	shard := b.shards[0]
+	insert := &gitlib.Change{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: "pair.go", Hash: oldHash}}
+	require.NoError(t, b.handleInsertion(shard, insert, b.credit(0, []int{1}), cache))
+
+	lines := func(author, tick int) int64 {
+		return shard.deltas.peopleDeltas[author][tick][tick]
+	}
+
+	assert.Equal(t, int64(5), lines(0, 0))
+	assert.Equal(t, int64(5), lines(1, 0))
+
+	// A mob of three appends four lines: the remainder goes to the author.
+	b.tick = 1
+	b.resetDeltaBuffers()
+
+	modify := &gitlib.Change{
+		Action: gitlib.Modify,
+		From:   gitlib.ChangeEntry{Name: "pair.go", Hash: oldHash},
+		To:     gitlib.ChangeEntry{Name: "pair.go", Hash: newHash},
+	}
+	diff := pkgplumbing.FileDiffData{
+		OldLinesOfCode: 10,
+		NewLinesOfCode: 14,
+		Diffs: []diffmatchpatch.Diff{
+			{Type: diffmatchpatch.DiffEqual, Text: strings.Repeat("a", 10)},
+			{Type: diffmatchpatch.DiffInsert, Text: "bbbb"},
+		},
+	}
+	require.NoError(t, b.handleModification(shard, modify, b.credit(0, []int{1, 2}), cache,
+		map[string]pkgplumbing.FileDiffData{"pair.go": diff}))
+
+	assert.Equal(t, int64(2), lines(0, 1))
+	assert.Equal(t, int64(1), lines(1, 1))
+	assert.Equal(t, int64(1), lines(2, 1))
+
+	file := shard.filesByID[b.pathInterner.Intern("pair.go")]
+	assert.Equal(t, 14, file.Len())
+
+	// A weight of 0 credits the author alone.
+	b.coAuthorWeight = 0
+	assert.Equal(t, []creditRun{{author: 0, lines: 4}}, b.credit(0, []int{1}).split(4))
+}
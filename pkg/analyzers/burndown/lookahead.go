@@ -0,0 +1,101 @@
+package burndown
+
+import (
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// blobKey identifies a file version: its blob and the name it is read under,
+// which decides whether its tokens come from the UAST.
+type blobKey struct {
+	hash gitlib.Hash
+	name string
+}
+
+// blobPair identifies the diff between two versions of a file.
+type blobPair struct {
+	from blobKey
+	to   blobKey
+}
+
+// changePair returns the versions a modification diffs.
+func changePair(change *gitlib.Change) blobPair {
+	return blobPair{
+		from: blobKey{hash: change.From.Hash, name: change.From.Name},
+		to:   blobKey{hash: change.To.Hash, name: change.To.Name},
+	}
+}
+
+// preparedCommit holds the token-mode units of one commit.
+type preparedCommit struct {
+	// uastCounts holds the number of UAST tokens of every parsed file version.
+	uastCounts map[blobKey]int
+	// diffs holds the token diffs of the modifications.
+	diffs map[blobPair]pkgplumbing.FileDiffData
+}
+
+// PrepareCommit implements analyze.CommitPreparer. It counts the lines of
+// both blobs of every modification and, in token mode, computes the token
+// counts and diffs of the commit, so Consume of the commit finds them ready.
+// Only configuration and the commit's own blobs and UAST trees are read;
+// results are kept per commit until Consume takes them.
+func (b *HistoryAnalyzer) PrepareCommit(ac *analyze.Context) {
+	if ac == nil || ac.Commit == nil {
+		return
+	}
+
+	for _, change := range ac.Changes {
+		if change.Action != gitlib.Modify {
+			continue
+		}
+
+		blobFrom, blobTo := ac.BlobCache[change.From.Hash], ac.BlobCache[change.To.Hash]
+		if blobFrom == nil || blobTo == nil {
+			continue
+		}
+
+		// CountLines caches its result in the blob, so Consume gets it for free.
+		_, _ = blobFrom.CountLines() //nolint:errcheck // Consume reports binary blobs.
+		_, _ = blobTo.CountLines()   //nolint:errcheck // Consume reports binary blobs.
+	}
+
+	if b.GranularityUnit != UnitToken {
+		return
+	}
+
+	prepared := b.prepareTokens(ac.Changes, ac.BlobCache, ac.UASTChanges)
+
+	b.preparedMu.Lock()
+	defer b.preparedMu.Unlock()
+
+	if b.prepared == nil {
+		b.prepared = make(map[gitlib.Hash]preparedCommit)
+	}
+
+	b.prepared[ac.Commit.Hash()] = prepared
+}
+
+// takePrepared removes and returns what PrepareCommit computed for commit.
+func (b *HistoryAnalyzer) takePrepared(commit analyze.CommitLike) (preparedCommit, bool) {
+	if commit == nil {
+		return preparedCommit{}, false
+	}
+
+	b.preparedMu.Lock()
+	defer b.preparedMu.Unlock()
+
+	hash := commit.Hash()
+	prepared, ok := b.prepared[hash]
+	delete(b.prepared, hash)
+
+	return prepared, ok
+}
+
+// dropPrepared discards prepared diffs of commits that were never consumed.
+func (b *HistoryAnalyzer) dropPrepared() {
+	b.preparedMu.Lock()
+	defer b.preparedMu.Unlock()
+
+	b.prepared = nil
+}
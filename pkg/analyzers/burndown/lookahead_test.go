@@ -0,0 +1,84 @@
+package burndown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+)
+
+func lookaheadContext(unitFrom, unitTo string) (*analyze.Context, blobPair) {
+	oldHash := gitlib.NewHash("1111111111111111111111111111111111111111")
+	newHash := gitlib.NewHash("2222222222222222222222222222222222222222")
+
+	ac := &analyze.Context{
+		Commit: gitlib.NewCommitForTest(gitlib.NewHash("3333333333333333333333333333333333333333")),
+		Changes: gitlib.Changes{{
+			Action: gitlib.Modify,
+			From:   gitlib.ChangeEntry{Name: "f.go", Hash: oldHash},
+			To:     gitlib.ChangeEntry{Name: "f.go", Hash: newHash},
+		}},
+		BlobCache: map[gitlib.Hash]*pkgplumbing.CachedBlob{
+			oldHash: gitlib.NewCachedBlobWithHashForTest(oldHash, []byte(unitFrom)),
+			newHash: gitlib.NewCachedBlobWithHashForTest(newHash, []byte(unitTo)),
+		},
+	}
+
+	return ac, changePair(ac.Changes[0])
+}
+
+func TestPrepareCommit_TokenDiffsTakenOnce(t *testing.T) {
+	t.Parallel()
+
+	b := NewHistoryAnalyzer()
+	b.GranularityUnit = UnitToken
+
+	ac, pair := lookaheadContext("x := a + b\n", "x := a + c\n")
+	b.PrepareCommit(ac)
+
+	prepared, ok := b.takePrepared(ac.Commit)
+	require.True(t, ok)
+	require.Contains(t, prepared.diffs, pair)
+	assert.Equal(t, b.tokenDiff(splitTokens("x := a + b\n"), splitTokens("x := a + c\n")), prepared.diffs[pair])
+
+	_, ok = b.takePrepared(ac.Commit)
+	assert.False(t, ok)
+}
+
+func TestPrepareCommit_TokensFromUAST(t *testing.T) {
+	t.Parallel()
+
+	b := NewHistoryAnalyzer()
+	b.GranularityUnit = UnitToken
+
+	ac, pair := lookaheadContext("x := a + b\n", "x := a + c\n")
+	ac.UASTChanges = []uast.Change{{
+		Before: tokenTree("x", "a", "b"),
+		After:  tokenTree("x", "a", "c"),
+		Change: ac.Changes[0],
+	}}
+	b.PrepareCommit(ac)
+
+	prepared, ok := b.takePrepared(ac.Commit)
+	require.True(t, ok)
+	assert.Equal(t, 3, prepared.uastCounts[pair.from])
+	assert.Equal(t, 3, prepared.uastCounts[pair.to])
+	assert.Equal(t, b.tokenDiff([]string{"x", "a", "b"}, []string{"x", "a", "c"}), prepared.diffs[pair])
+}
+
+func TestPrepareCommit_LineModeOnlyCountsLines(t *testing.T) {
+	t.Parallel()
+
+	b := NewHistoryAnalyzer()
+
+	ac, _ := lookaheadContext("a\nb\n", "a\nc\n")
+	b.PrepareCommit(ac)
+
+	_, ok := b.takePrepared(ac.Commit)
+	assert.False(t, ok)
+}
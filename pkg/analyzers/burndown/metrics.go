@@ -1,9 +1,11 @@
 package burndown
 
 import (
+	"sort"
 	"time"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 // Constants for burndown metrics calculations.
@@ -25,6 +27,7 @@ const (
 type ReportData struct {
 	GlobalHistory      DenseHistory
 	FileHistories      map[string]DenseHistory
+	DirHistories       map[string]DenseHistory
 	FileOwnership      map[string]map[int]int
 	PeopleHistories    []DenseHistory
 	PeopleMatrix       DenseHistory
@@ -34,6 +37,8 @@ type ReportData struct {
 	Granularity        int
 	ProjectName        string
 	EndTime            time.Time
+	Calendar           *pkgplumbing.TickCalendar // nil for fixed-size ticks.
+	Unit               GranularityUnit           // empty for lines.
 }
 
 // ParseReportData extracts ReportData from an analyzer report.
@@ -48,6 +53,10 @@ func ParseReportData(report analyze.Report) (*ReportData, error) {
 		data.FileHistories = fh
 	}
 
+	if dh, ok := report["DirHistories"].(map[string]DenseHistory); ok {
+		data.DirHistories = dh
+	}
+
 	if fo, ok := report["FileOwnership"].(map[string]map[int]int); ok {
 		data.FileOwnership = fo
 	}
@@ -86,15 +95,24 @@ func ParseReportData(report analyze.Report) (*ReportData, error) {
 		data.EndTime = et
 	}
 
+	if cal, ok := report["TickCalendar"].(*pkgplumbing.TickCalendar); ok && cal.Granularity.Calendar() {
+		data.Calendar = cal
+	}
+
+	if unit, ok := report["GranularityUnit"].(GranularityUnit); ok {
+		data.Unit = unit
+	}
+
 	return data, nil
 }
 
 // SurvivalData contains code survival statistics for a time period.
 type SurvivalData struct {
-	SampleIndex   int     `json:"sample_index"   yaml:"sample_index"`
-	TotalLines    int64   `json:"total_lines"    yaml:"total_lines"`
-	SurvivalRate  float64 `json:"survival_rate"  yaml:"survival_rate"`
-	BandBreakdown []int64 `json:"band_breakdown" yaml:"band_breakdown"`
+	SampleIndex   int     `json:"sample_index"     yaml:"sample_index"`
+	Period        string  `json:"period,omitempty" yaml:"period,omitempty"`
+	TotalLines    int64   `json:"total_lines"      yaml:"total_lines"`
+	SurvivalRate  float64 `json:"survival_rate"    yaml:"survival_rate"`
+	BandBreakdown []int64 `json:"band_breakdown"   yaml:"band_breakdown"`
 }
 
 // FileSurvivalData contains survival data for a single file.
@@ -105,6 +123,8 @@ type FileSurvivalData struct {
 	TopOwnerID   int         `json:"top_owner_id"         yaml:"top_owner_id"`
 	TopOwnerName string      `json:"top_owner_name"       yaml:"top_owner_name"`
 	TopOwnerPct  float64     `json:"top_owner_percentage" yaml:"top_owner_percentage"`
+	// History is the file's burndown matrix: surviving lines per sample and band.
+	History DenseHistory `json:"history,omitempty" yaml:"history,omitempty"`
 }
 
 // DeveloperSurvivalData contains survival data for a developer's code.
@@ -116,6 +136,14 @@ type DeveloperSurvivalData struct {
 	SurvivalRate float64 `json:"survival_rate" yaml:"survival_rate"`
 }
 
+// DirSurvivalData contains survival data for the code under a directory prefix.
+type DirSurvivalData struct {
+	Dir          string  `json:"dir"           yaml:"dir"`
+	CurrentLines int64   `json:"current_lines" yaml:"current_lines"`
+	PeakLines    int64   `json:"peak_lines"    yaml:"peak_lines"`
+	SurvivalRate float64 `json:"survival_rate" yaml:"survival_rate"`
+}
+
 // InteractionData contains developer interaction statistics.
 type InteractionData struct {
 	AuthorID      int    `json:"author_id"      yaml:"author_id"`
@@ -127,6 +155,7 @@ type InteractionData struct {
 }
 
 // AggregateData contains summary statistics.
+// Unit is "token" when the counts are tokens rather than lines, and empty otherwise.
 type AggregateData struct {
 	TotalCurrentLines   int64   `json:"total_current_lines"   yaml:"total_current_lines"`
 	TotalPeakLines      int64   `json:"total_peak_lines"      yaml:"total_peak_lines"`
@@ -136,6 +165,7 @@ type AggregateData struct {
 	NumSamples          int     `json:"num_samples"           yaml:"num_samples"`
 	TrackedFiles        int     `json:"tracked_files"         yaml:"tracked_files"`
 	TrackedDevelopers   int     `json:"tracked_developers"    yaml:"tracked_developers"`
+	Unit                string  `json:"unit,omitempty"        yaml:"unit,omitempty"`
 }
 
 func computeGlobalSurvival(input *ReportData) []SurvivalData {
@@ -148,6 +178,10 @@ func computeGlobalSurvival(input *ReportData) []SurvivalData {
 
 	for i, sample := range input.GlobalHistory {
 		result[i] = computeSurvivalSample(i, sample, peakLines)
+
+		if input.Calendar != nil {
+			result[i].Period = input.Calendar.Label(i * max(input.Sampling, 1))
+		}
 	}
 
 	return result
@@ -274,6 +308,7 @@ func computeFileSurvival(input FileSurvivalInput) []FileSurvivalData {
 			TopOwnerID:   topOwnerID,
 			TopOwnerName: topOwnerName,
 			TopOwnerPct:  topOwnerPct,
+			History:      input.FileHistories[path],
 		})
 	}
 
@@ -318,6 +353,42 @@ func computeDeveloperSurvival(devID int, history DenseHistory, names []string) D
 	}
 }
 
+// computeDirSurvival summarizes each directory history, sorted by directory.
+func computeDirSurvival(histories map[string]DenseHistory) []DirSurvivalData {
+	if len(histories) == 0 {
+		return nil
+	}
+
+	result := make([]DirSurvivalData, 0, len(histories))
+
+	for dir, history := range histories {
+		if len(history) == 0 {
+			continue
+		}
+
+		peakLines := findPeakLines(history)
+		currentLines := sumPositiveValues(history[len(history)-1])
+
+		var survivalRate float64
+		if peakLines > 0 {
+			survivalRate = float64(currentLines) / float64(peakLines)
+		}
+
+		result = append(result, DirSurvivalData{
+			Dir:          dir,
+			CurrentLines: currentLines,
+			PeakLines:    peakLines,
+			SurvivalRate: survivalRate,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Dir < result[j].Dir
+	})
+
+	return result
+}
+
 func computeInteraction(input InteractionInput) []InteractionData {
 	if len(input.PeopleMatrix) == 0 {
 		return nil
@@ -395,6 +466,10 @@ func computeAggregate(input *ReportData) AggregateData {
 		TrackedDevelopers: len(input.PeopleHistories),
 	}
 
+	if input.Unit == UnitToken {
+		agg.Unit = string(UnitToken)
+	}
+
 	if len(input.GlobalHistory) == 0 {
 		return agg
 	}
@@ -417,11 +492,12 @@ func computeAggregate(input *ReportData) AggregateData {
 
 // ComputedMetrics holds all computed metric results for the burndown analyzer.
 type ComputedMetrics struct {
-	Aggregate         AggregateData           `json:"aggregate"          yaml:"aggregate"`
-	GlobalSurvival    []SurvivalData          `json:"global_survival"    yaml:"global_survival"`
-	FileSurvival      []FileSurvivalData      `json:"file_survival"      yaml:"file_survival"`
-	DeveloperSurvival []DeveloperSurvivalData `json:"developer_survival" yaml:"developer_survival"`
-	Interaction       []InteractionData       `json:"interactions"       yaml:"interactions"`
+	Aggregate         AggregateData           `json:"aggregate"              yaml:"aggregate"`
+	GlobalSurvival    []SurvivalData          `json:"global_survival"        yaml:"global_survival"`
+	FileSurvival      []FileSurvivalData      `json:"file_survival"          yaml:"file_survival"`
+	DeveloperSurvival []DeveloperSurvivalData `json:"developer_survival"     yaml:"developer_survival"`
+	DirSurvival       []DirSurvivalData       `json:"dir_survival,omitempty" yaml:"dir_survival,omitempty"`
+	Interaction       []InteractionData       `json:"interactions"           yaml:"interactions"`
 }
 
 // --- MetricsOutput Interface Implementation ---.
@@ -474,6 +550,7 @@ func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
 		GlobalSurvival:    globalSurvival,
 		FileSurvival:      fileSurvival,
 		DeveloperSurvival: devSurvival,
+		DirSurvival:       computeDirSurvival(input.DirHistories),
 		Interaction:       interaction,
 		Aggregate:         aggregate,
 	}, nil
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 // Test constants to avoid magic strings/numbers.
@@ -96,6 +97,26 @@ func TestGlobalSurvivalMetric_Empty(t *testing.T) {
 	assert.Nil(t, result)
 }
 
+func TestGlobalSurvivalMetric_CalendarPeriods(t *testing.T) {
+	t.Parallel()
+
+	input := &ReportData{
+		GlobalHistory: DenseHistory{{100}, {80}, {60}},
+		Sampling:      2,
+		Calendar: &pkgplumbing.TickCalendar{
+			Granularity: pkgplumbing.TickQuarter,
+			Start:       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	result := computeGlobalSurvival(input)
+
+	require.Len(t, result, 3)
+	assert.Equal(t, "2024-Q1", result[0].Period)
+	assert.Equal(t, "2024-Q3", result[1].Period)
+	assert.Equal(t, "2025-Q1", result[2].Period)
+}
+
 func TestGlobalSurvivalMetric_SingleSample(t *testing.T) {
 	t.Parallel()
 
@@ -188,7 +209,9 @@ func TestFileSurvivalMetric_Empty(t *testing.T) {
 func TestFileSurvivalMetric_SingleFile(t *testing.T) {
 	t.Parallel()
 
+	history := DenseHistory{{100, 0}, {60, 40}}
 	input := FileSurvivalInput{
+		FileHistories: map[string]DenseHistory{testFilePath1: history},
 		FileOwnership: map[string]map[int]int{
 			testFilePath1: {0: 100},
 		},
@@ -199,6 +222,7 @@ func TestFileSurvivalMetric_SingleFile(t *testing.T) {
 
 	require.Len(t, result, 1)
 	assert.Equal(t, testFilePath1, result[0].Path)
+	assert.Equal(t, history, result[0].History)
 	assert.Equal(t, int64(100), result[0].CurrentLines)
 	assert.Equal(t, 0, result[0].TopOwnerID)
 	assert.Equal(t, testDevName1, result[0].TopOwnerName)
@@ -1,8 +1,13 @@
 package burndown
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
 	"encoding/gob"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -10,11 +15,40 @@ import (
 )
 
 // shardSpillState tracks spill files for one shard.
+//
+// Each Hibernate writes the shard's resident files to a new spill file as a
+// sequence of length-prefixed gob records. Boot restores the recently touched
+// files and leaves the cold ones on disk, remembering where each record lives
+// so it can be reloaded on first access. A spill file is removed once no cold
+// file refers to it any more.
 type shardSpillState struct {
 	dir        string
-	fileSpillN int // file treap spill counter.
+	fileSpillN int  // file treap spill counter.
+	pending    bool // the latest spill file has not been booted yet.
+	cold       map[PathID]spillRef
+	refs       map[int]int // spill file number -> cold records still read from it.
+	written    int64       // bytes of spill files written, including removed ones.
 }
 
+// spillRef locates one file record inside a spill file.
+type spillRef struct {
+	file   int
+	offset int64
+	size   int64
+}
+
+// spillRecord is the on-disk form of one file: its treap segments, its
+// history when per-file tracking is on, and the last chunk that touched it.
+type spillRecord struct {
+	PathID    PathID
+	Segments  []burndown.Segment
+	History   sparseHistory
+	LastChunk int
+}
+
+// errShortSpillRecord is returned when a spill record is truncated.
+var errShortSpillRecord = errors.New("short spill record")
+
 // spillDir returns the shard-specific spill directory, creating it on first call.
 func (ss *shardSpillState) spillDir(parentDir string, shardIdx int) (string, error) {
 	if ss.dir != "" {
@@ -91,30 +125,19 @@ func cleanupShardSpills(ss *shardSpillState) {
 	os.RemoveAll(ss.dir)
 	ss.dir = ""
 	ss.fileSpillN = 0
+	ss.pending = false
+	ss.cold = nil
+	ss.refs = nil
 }
 
-// fileEntry holds one file's serialized treap segments for spilling.
-type fileEntry struct {
-	PathID   PathID
-	Segments []burndown.Segment
-}
-
-// fileHistoryEntry holds one file's sparse history for spilling.
-type fileHistoryEntry struct {
-	PathID  PathID
-	History sparseHistory
+func (ss *shardSpillState) filePath(n int) string {
+	return filepath.Join(ss.dir, fmt.Sprintf("files_%03d.gob", n))
 }
 
-// shardFilesSnapshot holds all active files' segments and histories for one shard.
-// Each spill is a complete snapshot — only the latest is authoritative.
-type shardFilesSnapshot struct {
-	Files         []fileEntry
-	FileHistories []fileHistoryEntry
-}
-
-// collectFileEntries extracts segments from active files and frees their treap nodes.
-func collectFileEntries(shard *Shard) []fileEntry {
-	var entries []fileEntry
+// collectSpillRecords extracts every resident file with its history and frees
+// the treap nodes. Cold files are already on disk and are skipped.
+func collectSpillRecords(shard *Shard) []spillRecord {
+	var records []spillRecord
 
 	for _, id := range shard.activeIDs {
 		if int(id) >= len(shard.filesByID) {
@@ -126,76 +149,56 @@ func collectFileEntries(shard *Shard) []fileEntry {
 			continue
 		}
 
-		entries = append(entries, fileEntry{
-			PathID:   id,
-			Segments: file.Segments(),
-		})
-
-		file.Delete()
-
-		shard.filesByID[id] = nil
-	}
-
-	return entries
-}
-
-// collectFileHistoryEntries extracts file histories from active files and clears them.
-func collectFileHistoryEntries(shard *Shard) []fileHistoryEntry {
-	var entries []fileHistoryEntry
-
-	for _, id := range shard.activeIDs {
-		if int(id) >= len(shard.fileHistoriesByID) {
-			continue
+		record := spillRecord{
+			PathID:    id,
+			Segments:  file.Segments(),
+			LastChunk: shard.lastChunkByID[id],
 		}
 
-		history := shard.fileHistoriesByID[id]
-		if len(history) == 0 {
-			continue
+		if int(id) < len(shard.fileHistoriesByID) {
+			record.History = shard.fileHistoriesByID[id]
+			shard.fileHistoriesByID[id] = nil
 		}
 
-		entries = append(entries, fileHistoryEntry{
-			PathID:  id,
-			History: history,
-		})
+		records = append(records, record)
 
-		shard.fileHistoriesByID[id] = nil
+		file.Delete()
+
+		shard.filesByID[id] = nil
+		delete(shard.lastChunkByID, id)
 	}
 
-	return entries
+	return records
 }
 
-// spillShardFiles serializes all active files' treap segments and file histories
-// to a gob file, then frees the treap nodes to reclaim memory.
+// spillShardFiles writes all resident files and their histories to a new
+// spill file, then frees the treap nodes to reclaim memory.
 func spillShardFiles(shard *Shard, ss *shardSpillState, parentDir string, shardIdx int) error {
-	snapshot := shardFilesSnapshot{
-		Files:         collectFileEntries(shard),
-		FileHistories: collectFileHistoryEntries(shard),
-	}
-
-	if len(snapshot.Files) == 0 && len(snapshot.FileHistories) == 0 {
+	records := collectSpillRecords(shard)
+	if len(records) == 0 {
 		return nil
 	}
 
-	dir, err := ss.spillDir(parentDir, shardIdx)
+	_, err := ss.spillDir(parentDir, shardIdx)
 	if err != nil {
 		return err
 	}
 
-	path := filepath.Join(dir, fmt.Sprintf("files_%03d.gob", ss.fileSpillN))
-
-	f, err := os.Create(path)
+	f, err := os.Create(ss.filePath(ss.fileSpillN))
 	if err != nil {
 		return fmt.Errorf("create file spill: %w", err)
 	}
 
-	enc := gob.NewEncoder(f)
+	err = writeSpillRecords(f, records)
 
-	err = enc.Encode(&snapshot)
+	if info, statErr := f.Stat(); statErr == nil {
+		ss.written += info.Size()
+	}
 
 	closeErr := f.Close()
 
 	if err != nil {
-		return fmt.Errorf("encode file snapshot: %w", err)
+		return fmt.Errorf("encode file spill: %w", err)
 	}
 
 	if closeErr != nil {
@@ -203,75 +206,221 @@ func spillShardFiles(shard *Shard, ss *shardSpillState, parentDir string, shardI
 	}
 
 	ss.fileSpillN++
+	ss.pending = true
 
 	return nil
 }
 
-// restoreFileEntries reconstructs file treaps from spilled entries.
-func restoreFileEntries(shard *Shard, entries []fileEntry) {
-	for _, entry := range entries {
-		id := entry.PathID
-		growShardSlices(shard, id)
-		shard.filesByID[id] = burndown.NewFileFromSegments(entry.Segments)
+// writeSpillRecords writes each record as a uvarint length followed by its
+// own gob stream, so any record can later be decoded on its own.
+func writeSpillRecords(w io.Writer, records []spillRecord) error {
+	bw := bufio.NewWriter(w)
+
+	var (
+		buf    bytes.Buffer
+		header [binary.MaxVarintLen64]byte
+	)
+
+	for i := range records {
+		buf.Reset()
+
+		err := gob.NewEncoder(&buf).Encode(&records[i])
+		if err != nil {
+			return err
+		}
+
+		n := binary.PutUvarint(header[:], uint64(buf.Len()))
+
+		_, err = bw.Write(header[:n])
+		if err != nil {
+			return err
+		}
+
+		_, err = bw.Write(buf.Bytes())
+		if err != nil {
+			return err
+		}
 	}
+
+	return bw.Flush()
 }
 
-// restoreFileHistoryEntries restores file histories from spilled entries.
-func restoreFileHistoryEntries(shard *Shard, entries []fileHistoryEntry) {
-	for _, entry := range entries {
-		id := entry.PathID
-		growShardSlices(shard, id)
-		shard.fileHistoriesByID[id] = entry.History
+// readSpillRecords calls fn for every record in r with the record's location.
+func readSpillRecords(r io.Reader, fn func(record *spillRecord, offset, size int64)) error {
+	br := bufio.NewReader(r)
+
+	var offset int64
+
+	for {
+		size, err := binary.ReadUvarint(br)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		offset += int64(uvarintLen(size))
+
+		data := make([]byte, size)
+
+		_, err = io.ReadFull(br, data)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errShortSpillRecord, err)
+		}
+
+		var record spillRecord
+
+		err = gob.NewDecoder(bytes.NewReader(data)).Decode(&record)
+		if err != nil {
+			return err
+		}
+
+		fn(&record, offset, int64(size))
+
+		offset += int64(size)
 	}
 }
 
-// growShardSlices ensures shard slices are large enough to hold the given PathID.
-func growShardSlices(shard *Shard, id PathID) {
-	n := int(id) + 1
+func uvarintLen(v uint64) int {
+	var header [binary.MaxVarintLen64]byte
 
-	if n > len(shard.filesByID) {
-		newFiles := make([]*burndown.File, n)
-		copy(newFiles, shard.filesByID)
-		shard.filesByID = newFiles
+	return binary.PutUvarint(header[:], v)
+}
+
+// bootSpilledFiles restores the files of the latest spill. Files idle for
+// more than coldChunks chunks stay on disk and are only indexed; coldChunks
+// of 0 restores everything.
+func bootSpilledFiles(shard *Shard, ss *shardSpillState, chunk, coldChunks int) ([]PathID, error) {
+	if !ss.pending {
+		return nil, nil
 	}
 
-	if n > len(shard.fileHistoriesByID) {
-		newHistories := make([]sparseHistory, n)
-		copy(newHistories, shard.fileHistoriesByID)
-		shard.fileHistoriesByID = newHistories
+	latest := ss.fileSpillN - 1
+
+	f, err := os.Open(ss.filePath(latest))
+	if err != nil {
+		return nil, fmt.Errorf("open file spill: %w", err)
+	}
+
+	defer f.Close()
+
+	var restored []PathID
+
+	err = readSpillRecords(f, func(record *spillRecord, offset, size int64) {
+		if coldChunks > 0 && chunk-record.LastChunk > coldChunks {
+			ss.markCold(record.PathID, spillRef{file: latest, offset: offset, size: size})
+
+			return
+		}
+
+		restoreSpillRecord(shard, record)
+
+		restored = append(restored, record.PathID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decode file spill: %w", err)
 	}
+
+	ss.pending = false
+	ss.removeUnreferenced(latest)
+
+	return restored, nil
 }
 
-// loadSpilledFiles restores file treaps and file histories from the most recent spill.
-// Only the last spill is authoritative because each spill is a complete snapshot.
-func loadSpilledFiles(shard *Shard, ss *shardSpillState) error {
-	if ss.fileSpillN == 0 {
-		return nil
+func (ss *shardSpillState) markCold(id PathID, ref spillRef) {
+	if ss.cold == nil {
+		ss.cold = map[PathID]spillRef{}
+		ss.refs = map[int]int{}
 	}
 
-	// Only the last spill file matters — it's a complete snapshot.
-	path := filepath.Join(ss.dir, fmt.Sprintf("files_%03d.gob", ss.fileSpillN-1))
+	ss.cold[id] = ref
+	ss.refs[ref.file]++
+}
 
-	f, err := os.Open(path)
-	if os.IsNotExist(err) {
-		return nil // No files were spilled (all empty).
+// dropCold forgets the cold record of id, if any, and removes its spill file
+// when no other cold record needs it.
+func (ss *shardSpillState) dropCold(id PathID) (spillRef, bool) {
+	ref, ok := ss.cold[id]
+	if !ok {
+		return spillRef{}, false
 	}
 
+	delete(ss.cold, id)
+
+	ss.refs[ref.file]--
+	ss.removeUnreferenced(ref.file)
+
+	return ref, true
+}
+
+func (ss *shardSpillState) removeUnreferenced(n int) {
+	if ss.refs[n] > 0 {
+		return
+	}
+
+	delete(ss.refs, n)
+	os.Remove(ss.filePath(n))
+}
+
+// readSpillRecord decodes the single record ref points at.
+func (ss *shardSpillState) readSpillRecord(ref spillRef) (*spillRecord, error) {
+	f, err := os.Open(ss.filePath(ref.file))
 	if err != nil {
-		return fmt.Errorf("open file spill: %w", err)
+		return nil, fmt.Errorf("open file spill: %w", err)
 	}
 
 	defer f.Close()
 
-	var snapshot shardFilesSnapshot
+	data := make([]byte, ref.size)
 
-	err = gob.NewDecoder(f).Decode(&snapshot)
+	_, err = f.ReadAt(data, ref.offset)
 	if err != nil {
-		return fmt.Errorf("decode file snapshot: %w", err)
+		return nil, fmt.Errorf("read file spill: %w", err)
 	}
 
-	restoreFileEntries(shard, snapshot.Files)
-	restoreFileHistoryEntries(shard, snapshot.FileHistories)
+	var record spillRecord
 
-	return nil
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&record)
+	if err != nil {
+		return nil, fmt.Errorf("decode file spill: %w", err)
+	}
+
+	return &record, nil
+}
+
+// restoreSpillRecord reconstructs one file treap and its history.
+// Updaters are attached by the caller.
+func restoreSpillRecord(shard *Shard, record *spillRecord) {
+	id := record.PathID
+	growShardSlices(shard, id)
+	shard.filesByID[id] = burndown.NewFileFromSegments(record.Segments)
+
+	if len(record.History) > 0 {
+		shard.fileHistoriesByID[id] = record.History
+	}
+
+	if shard.lastChunkByID == nil {
+		shard.lastChunkByID = map[PathID]int{}
+	}
+
+	shard.lastChunkByID[id] = record.LastChunk
+}
+
+// growShardSlices ensures shard slices are large enough to hold the given PathID.
+func growShardSlices(shard *Shard, id PathID) {
+	n := int(id) + 1
+
+	if n > len(shard.filesByID) {
+		newFiles := make([]*burndown.File, n)
+		copy(newFiles, shard.filesByID)
+		shard.filesByID = newFiles
+	}
+
+	if n > len(shard.fileHistoriesByID) {
+		newHistories := make([]sparseHistory, n)
+		copy(newHistories, shard.fileHistoriesByID)
+		shard.fileHistoriesByID = newHistories
+	}
 }
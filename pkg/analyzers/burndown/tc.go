@@ -15,6 +15,10 @@ type CommitResult struct {
 	// FileDeltas: pathID -> curTick -> prevTick -> lineCountDelta.
 	FileDeltas map[PathID]sparseHistory
 
+	// DirDeltas: directory prefix -> curTick -> prevTick -> lineCountDelta.
+	// Only populated when per-directory tracking is enabled.
+	DirDeltas map[string]sparseHistory
+
 	// FileOwnership: pathID -> authorID -> surviving line count.
 	// Computed from live file segments (not sparse history) when both
 	// TrackFiles and PeopleNumber > 0. This is a snapshot of current
@@ -30,6 +34,7 @@ type TickResult struct {
 	Matrix          []map[int]int64
 	FileHistories   map[PathID]sparseHistory
 	FileOwnership   map[PathID]map[int]int
+	DirHistories    map[string]sparseHistory
 }
 
 // deltaBuffer holds per-commit delta accumulation for a single shard.
@@ -39,4 +44,5 @@ type deltaBuffer struct {
 	peopleDeltas map[int]sparseHistory
 	matrixDeltas []map[int]int64
 	fileDeltas   map[PathID]sparseHistory
+	dirDeltas    map[string]sparseHistory
 }
@@ -0,0 +1,238 @@
+package burndown
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// GranularityUnit selects what burndown tracks the age of: whole lines or
+// individual source tokens.
+type GranularityUnit string
+
+const (
+	// UnitLine tracks lines, using the FileDiff line diffs.
+	UnitLine GranularityUnit = "line"
+	// UnitToken tracks source tokens: the leaf tokens of the UAST, or for
+	// languages the UAST parser does not support, identifiers and keywords,
+	// numbers, and single punctuation characters. A one-line edit then only
+	// renews the tokens it touched, which keeps survival precise in dense,
+	// long-line code.
+	UnitToken GranularityUnit = "token"
+)
+
+// parseGranularityUnit parses a unit name. Empty input maps to [UnitLine].
+func parseGranularityUnit(s string) (GranularityUnit, error) {
+	switch u := GranularityUnit(s); u {
+	case "":
+		return UnitLine, nil
+	case UnitLine, UnitToken:
+		return u, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want line or token)", errUnknownGranularityUnit, s)
+	}
+}
+
+// unitCount returns the number of tracked units in blob, read under name.
+// The error is pkgplumbing.ErrBinary for binary blobs, as for CountLines.
+func (b *HistoryAnalyzer) unitCount(blob *pkgplumbing.CachedBlob, name string) (int, error) {
+	lines, err := blob.CountLines()
+	if err != nil || b.GranularityUnit != UnitToken {
+		return lines, err
+	}
+
+	if count, ok := b.ahead.uastCounts[blobKey{hash: blob.Hash(), name: name}]; ok {
+		return count, nil
+	}
+
+	return len(splitTokens(string(blob.Data))), nil
+}
+
+// unitDiff returns the diff burndown applies for a modified file. In line mode
+// it is the FileDiff line diff; in token mode it is the token diff of the two
+// versions, prepared for the commit by prepareTokens.
+func (b *HistoryAnalyzer) unitDiff(
+	change *gitlib.Change, blobFrom, blobTo *pkgplumbing.CachedBlob, lineDiff pkgplumbing.FileDiffData,
+) pkgplumbing.FileDiffData {
+	if b.GranularityUnit != UnitToken {
+		return lineDiff
+	}
+
+	if diff, ok := b.ahead.diffs[changePair(change)]; ok {
+		return diff
+	}
+
+	return b.tokenDiff(splitTokens(string(blobFrom.Data)), splitTokens(string(blobTo.Data)))
+}
+
+// prepareTokens computes the token counts and token diffs of a commit.
+// Tokens come from the UAST of every file version in uastChanges; versions
+// without a tree, because the parser does not support their language or
+// failed on them, fall back to splitTokens. The source only depends on the
+// blob and the name it is read under, so a version always yields the same
+// tokens when it is inserted, modified and deleted.
+func (b *HistoryAnalyzer) prepareTokens(
+	changes gitlib.Changes, cache map[gitlib.Hash]*pkgplumbing.CachedBlob, uastChanges []uast.Change,
+) preparedCommit {
+	trees := make(map[blobKey][]string)
+
+	for _, change := range uastChanges {
+		if change.Before != nil {
+			trees[blobKey{hash: change.Change.From.Hash, name: change.Change.From.Name}] = uastTokens(change.Before)
+		}
+
+		if change.After != nil {
+			trees[blobKey{hash: change.Change.To.Hash, name: change.Change.To.Name}] = uastTokens(change.After)
+		}
+	}
+
+	prepared := preparedCommit{
+		uastCounts: make(map[blobKey]int, len(trees)),
+		diffs:      make(map[blobPair]pkgplumbing.FileDiffData),
+	}
+
+	for key, tokens := range trees {
+		prepared.uastCounts[key] = len(tokens)
+	}
+
+	for _, change := range changes {
+		if change.Action != gitlib.Modify {
+			continue
+		}
+
+		blobFrom, blobTo := cache[change.From.Hash], cache[change.To.Hash]
+		if blobFrom == nil || blobTo == nil {
+			continue
+		}
+
+		_, errFrom := blobFrom.CountLines()
+		_, errTo := blobTo.CountLines()
+
+		if errFrom != nil || errTo != nil {
+			continue
+		}
+
+		pair := changePair(change)
+		prepared.diffs[pair] = b.tokenDiff(versionTokens(trees, pair.from, blobFrom), versionTokens(trees, pair.to, blobTo))
+	}
+
+	return prepared
+}
+
+// versionTokens returns the UAST tokens of a file version, or its lexical
+// tokens when it has no tree.
+func versionTokens(trees map[blobKey][]string, key blobKey, blob *pkgplumbing.CachedBlob) []string {
+	if tokens, ok := trees[key]; ok {
+		return tokens
+	}
+
+	return splitTokens(string(blob.Data))
+}
+
+// uastTokens returns the tokens of the leaves of root in source order.
+// Inner nodes are skipped: their token may span the text of their children.
+func uastTokens(root *node.Node) []string {
+	var tokens []string
+
+	root.VisitPreOrder(func(n *node.Node) {
+		if len(n.Children) == 0 && strings.TrimSpace(n.Token) != "" {
+			tokens = append(tokens, n.Token)
+		}
+	})
+
+	return tokens
+}
+
+// tokenDiff diffs two token sequences with the FileDiff algorithm.
+func (b *HistoryAnalyzer) tokenDiff(from, to []string) pkgplumbing.FileDiffData {
+	dmp := diffmatchpatch.New()
+	algorithm := gitlib.DiffMyers
+
+	if b.FileDiff != nil {
+		algorithm = b.FileDiff.Algorithm
+
+		if b.FileDiff.Timeout > 0 {
+			dmp.DiffTimeout = b.FileDiff.Timeout
+		}
+	}
+
+	src, dst, _ := dmp.DiffLinesToRunes(tokenLines(from), tokenLines(to))
+
+	return pkgplumbing.FileDiffData{
+		OldLinesOfCode: len(src),
+		NewLinesOfCode: len(dst),
+		Diffs:          pkgplumbing.DiffLineRunes(dmp, src, dst, algorithm),
+	}
+}
+
+// tokenLines joins tokens one per line, so the line differ treats every token
+// as a single symbol. UAST tokens such as multi-line strings may hold
+// newlines; those are replaced by spaces.
+func tokenLines(tokens []string) string {
+	var sb strings.Builder
+
+	for _, tok := range tokens {
+		sb.WriteString(strings.ReplaceAll(tok, "\n", " "))
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// splitTokens splits source text into tokens: runs of letters, digits and
+// underscores, and every other non-space character on its own. It is the
+// lexical fallback for files without a UAST.
+// Whitespace separates tokens and is not tracked.
+func splitTokens(text string) []string {
+	var tokens []string
+
+	start := -1
+
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+
+			continue
+		}
+
+		if start >= 0 {
+			tokens = append(tokens, text[start:i])
+			start = -1
+		}
+
+		if !unicode.IsSpace(r) && r != utf8.RuneError {
+			tokens = append(tokens, string(r))
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, text[start:])
+	}
+
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// uastChanges returns the UAST changes of the commit being consumed, or nil
+// when the analyzer has no UAST dependency.
+func (b *HistoryAnalyzer) uastChanges(ctx context.Context) []uast.Change {
+	if b.UAST == nil {
+		return nil
+	}
+
+	return b.UAST.Changes(ctx)
+}
@@ -0,0 +1,126 @@
+package burndown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/burndown"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// tokenTree returns a UAST whose leaves carry tokens, under an inner node
+// whose token spans all of them.
+func tokenTree(tokens ...string) *node.Node {
+	root := node.NewNodeWithToken(node.UASTFile, "whole file")
+
+	for _, tok := range tokens {
+		root.AddChild(node.NewNodeWithToken(node.UASTIdentifier, tok))
+	}
+
+	return root
+}
+
+func TestSplitTokens(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		[]string{"x", ":", "=", "foo_1", "(", "a", ",", "42", ")", "/", "/", "é"},
+		splitTokens("x := foo_1(a, 42) // é\n"))
+	assert.Empty(t, splitTokens(" \n\t"))
+}
+
+func TestUASTTokens(t *testing.T) {
+	t.Parallel()
+
+	root := tokenTree("x", " ", "a")
+	root.AddChild(tokenTree("b", "c"))
+
+	assert.Equal(t, []string{"x", "a", "b", "c"}, uastTokens(root))
+}
+
+func TestConfigure_GranularityUnit(t *testing.T) {
+	t.Parallel()
+
+	b := NewHistoryAnalyzer()
+	require.NoError(t, b.Configure(map[string]any{ConfigBurndownGranularityUnit: "token"}))
+	assert.Equal(t, UnitToken, b.GranularityUnit)
+	assert.True(t, b.Capabilities().NeedsUAST, "token mode reads the UAST")
+
+	err := b.Configure(map[string]any{ConfigBurndownGranularityUnit: "word"})
+	require.ErrorIs(t, err, errUnknownGranularityUnit)
+}
+
+func TestTokenUnit_OneLineEditRenewsOnlyChangedTokens(t *testing.T) {
+	t.Parallel()
+
+	b := NewHistoryAnalyzer()
+	b.Goroutines = 1
+	b.GranularityUnit = UnitToken
+	require.NoError(t, b.Initialize(nil))
+	b.resetDeltaBuffers()
+
+	oldHash := gitlib.NewHash("1111111111111111111111111111111111111111")
+	newHash := gitlib.NewHash("2222222222222222222222222222222222222222")
+	cache := map[gitlib.Hash]*pkgplumbing.CachedBlob{
+		oldHash: gitlib.NewCachedBlobWithHashForTest(oldHash, []byte("x := a + b\n")),
+		newHash: gitlib.NewCachedBlobWithHashForTest(newHash, []byte("x := a + c\n")),
+	}
+
+	shard := b.shards[0]
+	insert := &gitlib.Change{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: "f.go", Hash: oldHash}}
+	require.NoError(t, b.handleInsertion(shard, insert, credit{}, cache))
+
+	file := shard.filesByID[b.pathInterner.Intern("f.go")]
+	require.Equal(t, 6, file.Len())
+
+	b.tick = 5
+	modify := &gitlib.Change{
+		Action: gitlib.Modify,
+		From:   gitlib.ChangeEntry{Name: "f.go", Hash: oldHash},
+		To:     gitlib.ChangeEntry{Name: "f.go", Hash: newHash},
+	}
+	// The line diff is ignored in token mode.
+	require.NoError(t, b.handleModification(shard, modify, credit{}, cache, nil))
+
+	assert.Equal(t, 6, file.Len())
+	assert.Equal(t, []burndown.Segment{
+		{Length: 5, Value: burndown.TimeKey(b.packPersonWithTick(0, 0))},
+		{Length: 1, Value: burndown.TimeKey(b.packPersonWithTick(0, 5))},
+	}, file.Segments())
+}
+
+func TestTokenUnit_UASTTokensWithLexicalFallback(t *testing.T) {
+	t.Parallel()
+
+	b := NewHistoryAnalyzer()
+	b.Goroutines = 1
+	b.GranularityUnit = UnitToken
+	require.NoError(t, b.Initialize(nil))
+	b.resetDeltaBuffers()
+
+	hash := gitlib.NewHash("1111111111111111111111111111111111111111")
+	cache := map[gitlib.Hash]*pkgplumbing.CachedBlob{
+		hash: gitlib.NewCachedBlobWithHashForTest(hash, []byte("x := a + b\n")),
+	}
+
+	parsed := &gitlib.Change{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: "f.go", Hash: hash}}
+	unsupported := &gitlib.Change{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: "f.txt", Hash: hash}}
+
+	b.ahead = b.prepareTokens(gitlib.Changes{parsed, unsupported}, cache, []uast.Change{{
+		After:  tokenTree("x", ":=", "a", "+", "b"),
+		Change: parsed,
+	}})
+
+	shard := b.shards[0]
+	require.NoError(t, b.handleInsertion(shard, parsed, credit{}, cache))
+	require.NoError(t, b.handleInsertion(shard, unsupported, credit{}, cache))
+
+	assert.Equal(t, 5, shard.filesByID[b.pathInterner.Intern("f.go")].Len(), "the UAST tokens are tracked")
+	assert.Equal(t, 6, shard.filesByID[b.pathInterner.Intern("f.txt")].Len(),
+		"files without a tree fall back to lexical tokens")
+}
@@ -0,0 +1,552 @@
+// Package codeowners attributes the changed files of every commit to their
+// owners in CODEOWNERS, counts the changes made by authors outside the
+// owning team and flags the commits that bypassed the owners' review.
+package codeowners
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	pkgcodeowners "github.com/Sumatoshi-tech/codefang/pkg/codeowners"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// ConfigCodeOwnersTeams is the configuration option key of the teams file.
+const ConfigCodeOwnersTeams = "CodeOwners.Teams"
+
+// Report keys of the codeowners analyzer.
+const (
+	KeyTicks          = "ticks"
+	KeyOwners         = "owners"
+	KeyOutsideAuthors = "outside_authors"
+	KeyAuthorIndex    = "author_index"
+	KeyRules          = "rules"
+	KeyBypasses       = "bypasses"
+
+	// tickBytes, ownerBytes, authorBytes and bypassBytes estimate the bytes
+	// of one tick, owner entry, outside author entry and bypass held by the
+	// aggregator.
+	tickBytes   = 64
+	ownerBytes  = 96
+	authorBytes = 32
+	bypassBytes = 192
+)
+
+// Changes counts the changed files owned by an owner, or by any owner.
+type Changes struct {
+	Changes int `json:"changes" yaml:"changes"`
+	// Outside counts the changes by authors who are not among the owners.
+	Outside int `json:"outside" yaml:"outside"`
+	// Unresolved counts the changes whose author cannot be told apart from
+	// the owners, because no owner is listed with its members.
+	Unresolved int `json:"unresolved" yaml:"unresolved"`
+}
+
+// Add counts one change made inside, outside or unresolved.
+func (c *Changes) Add(outside, unresolved bool) {
+	c.Changes++
+
+	switch {
+	case unresolved:
+		c.Unresolved++
+	case outside:
+		c.Outside++
+	}
+}
+
+// Merge adds the counts of other.
+func (c *Changes) Merge(other Changes) {
+	c.Changes += other.Changes
+	c.Outside += other.Outside
+	c.Unresolved += other.Unresolved
+}
+
+// Bypass is a commit that changed owned files while neither its author nor
+// any of its co-authors is among their owners, so the change did not go
+// through the owning team.
+type Bypass struct {
+	Hash      gitlib.Hash
+	Tick      int
+	AuthorID  int
+	Time      time.Time
+	Committer string
+	// Owners are the owners of Files, sorted.
+	Owners []string
+	// Files are the owned files changed outside their owners.
+	Files []string
+}
+
+// CommitOwnership is the per-commit payload: the changes of a commit by
+// owner.
+type CommitOwnership struct {
+	// Total counts every owned file once, whatever its number of owners.
+	Total   Changes
+	Unowned int
+	Owners  map[string]*Changes
+	// Bypass is set when the commit changed owned files outside their
+	// owners.
+	Bypass *Bypass
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	Total   Changes
+	Unowned int
+	Owners  map[string]*Changes
+	// OutsideAuthors counts the outside changes of every author by owner.
+	OutsideAuthors map[string]map[int]int
+	// Commits counts the commits that changed owned files.
+	Commits  int
+	Bypasses []Bypass
+}
+
+// TickChanges is the ownership of the changes of one tick.
+type TickChanges struct {
+	Tick    int     `json:"tick"    yaml:"tick"`
+	Total   Changes `json:"total"   yaml:"total"`
+	Unowned int     `json:"unowned" yaml:"unowned"`
+	// Commits counts the commits that changed owned files, Bypasses those
+	// of them that bypassed the owners.
+	Commits  int `json:"commits"  yaml:"commits"`
+	Bypasses int `json:"bypasses" yaml:"bypasses"`
+}
+
+// Analyzer attributes the files every commit changes to their owners in the
+// CODEOWNERS file of the HEAD tree.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	TreeDiff *plumbing.TreeDiffAnalyzer
+	Ticks    *plumbing.TicksSinceStart
+
+	// Owners are the CODEOWNERS rules; nil when the repository has none.
+	Owners *pkgcodeowners.Ruleset
+	// Teams lists the members of the owners named in CODEOWNERS.
+	Teams pkgcodeowners.Teams
+
+	reversedPeopleDict []string
+}
+
+// NewAnalyzer creates a new codeowners analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/codeowners",
+			Mode: analyze.ModeHistory,
+			Description: "Attributes changed files to their owning teams in CODEOWNERS and reports the changes " +
+				"made outside the owning team and the commits that bypassed its review.",
+		},
+		Sequential: false,
+		Caps:       &analyze.Capabilities{Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name: ConfigCodeOwnersTeams,
+				Description: "YAML or JSON file mapping the teams and users of CODEOWNERS to the e-mail addresses " +
+					"of their members.",
+				Flag:    "codeowners-teams",
+				Type:    pipeline.StringConfigurationOption,
+				Default: "",
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
+			return ComputeAllMetrics(report), nil
+		},
+		AggregatorFn: newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigCodeOwnersTeams].(string); exists && val != "" {
+		data, err := os.ReadFile(val)
+		if err != nil {
+			return fmt.Errorf("codeowners: read teams: %w", err)
+		}
+
+		teams, err := pkgcodeowners.ParseTeams(data)
+		if err != nil {
+			return fmt.Errorf("codeowners: %w", err)
+		}
+
+		a.Teams = teams
+	}
+
+	if val, exists := facts[pkgplumbing.FactCodeOwners].(*pkgcodeowners.Ruleset); exists {
+		a.Owners = val
+	}
+
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		a.reversedPeopleDict = val
+	}
+
+	return nil
+}
+
+// Initialize prepares the analyzer for processing commits.
+func (a *Analyzer) Initialize(_ *gitlib.Repository) error {
+	return nil
+}
+
+// Consume attributes the files the commit changed to their owners and
+// checks whether its author, or one of its Co-authored-by trailers, is one
+// of them. A file with several owners counts for each of them, as outside
+// for the owners none of the authors is among. Deleted files count for the
+// owners of their old path. A commit changing a file outside all of its
+// owners is recorded as a bypass, unless the owners list no members. Merge
+// commits are skipped: their changes were recorded on the merged branch.
+func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac == nil || ac.Commit == nil || ac.IsMerge || a.Owners == nil || len(a.TreeDiff.Changes) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	emails := commitEmails(ac.Commit)
+	result := &CommitOwnership{Owners: make(map[string]*Changes)}
+
+	for _, change := range a.TreeDiff.Changes {
+		name := change.To.Name
+		if change.Action == gitlib.Delete {
+			name = change.From.Name
+		}
+
+		owners := a.Owners.Owners(name)
+		if len(owners) == 0 {
+			result.Unowned++
+
+			continue
+		}
+
+		outside, unresolved := a.classify(owners, emails)
+		result.Total.Add(outside, unresolved)
+
+		if outside && !unresolved {
+			result.Bypass = addBypass(result.Bypass, name, owners)
+		}
+
+		for _, owner := range owners {
+			counts := result.Owners[owner]
+			if counts == nil {
+				counts = &Changes{}
+				result.Owners[owner] = counts
+			}
+
+			counts.Add(a.classify([]string{owner}, emails))
+		}
+	}
+
+	if result.Bypass != nil {
+		committer := ac.Commit.Committer()
+		result.Bypass.Committer = cmp.Or(committer.Name, committer.Email)
+		slices.Sort(result.Bypass.Owners)
+	}
+
+	return analyze.TC{Data: result, CommitHash: ac.Commit.Hash()}, nil
+}
+
+// classify tells whether none of the authors with the e-mail addresses is
+// among owners, and whether that cannot be told because no owner lists
+// members.
+func (a *Analyzer) classify(owners, emails []string) (outside, unresolved bool) {
+	for _, email := range emails {
+		if a.Teams.IsOwner(owners, email) {
+			return false, false
+		}
+	}
+
+	return true, !a.Teams.Resolvable(owners)
+}
+
+// commitEmails returns the e-mail address of the author of commit followed
+// by those of its Co-authored-by trailers.
+func commitEmails(commit analyze.CommitLike) []string {
+	emails := []string{commit.Author().Email}
+
+	for _, value := range gitlib.TrailerValues(gitlib.ParseTrailers(commit.Message()), gitlib.TrailerCoAuthoredBy) {
+		if signature, ok := gitlib.ParseTrailerSignature(value); ok {
+			emails = append(emails, signature.Email)
+		}
+	}
+
+	return emails
+}
+
+// addBypass records the file changed outside its owners in bypass, creating
+// it on the first file.
+func addBypass(bypass *Bypass, file string, owners []string) *Bypass {
+	if bypass == nil {
+		bypass = &Bypass{}
+	}
+
+	bypass.Files = append(bypass.Files, file)
+
+	for _, owner := range owners {
+		if !slices.Contains(bypass.Owners, owner) {
+			bypass.Owners = append(bypass.Owners, owner)
+		}
+	}
+
+	return bypass
+}
+
+// Fork creates independent copies of the analyzer for parallel processing.
+// The copies share the rules and teams, which are read-only.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes: a.TreeDiff.Changes,
+		Tick:    a.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.TreeDiff.Changes = snapshot.Changes
+	a.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot is a no-op for codeowners.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the changes per tick in tick order, sums them per
+// owner and per outside author, and lists the bypasses in history order.
+// Bypasses of a tick are ordered by time, as parallel workers may deliver
+// them out of order.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	var (
+		series   []TickChanges
+		bypasses []Bypass
+		owners   = make(map[string]Changes)
+		authors  = make(map[string]map[int]int)
+	)
+
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*TickData)
+		if !ok || td == nil {
+			continue
+		}
+
+		series = append(series, TickChanges{
+			Tick: tick.Tick, Total: td.Total, Unowned: td.Unowned, Commits: td.Commits, Bypasses: len(td.Bypasses),
+		})
+
+		for owner, counts := range td.Owners {
+			total := owners[owner]
+			total.Merge(*counts)
+			owners[owner] = total
+		}
+
+		mergeAuthors(authors, td.OutsideAuthors)
+
+		tickBypasses := slices.Clone(td.Bypasses)
+		slices.SortStableFunc(tickBypasses, func(x, y Bypass) int {
+			return x.Time.Compare(y.Time)
+		})
+
+		bypasses = append(bypasses, tickBypasses...)
+	}
+
+	slices.SortFunc(series, func(x, y TickChanges) int { return x.Tick - y.Tick })
+	slices.SortStableFunc(bypasses, func(x, y Bypass) int { return x.Tick - y.Tick })
+
+	rules := 0
+	if a.Owners != nil {
+		rules = a.Owners.Len()
+	}
+
+	return analyze.Report{
+		KeyTicks:          series,
+		KeyOwners:         owners,
+		KeyOutsideAuthors: authors,
+		KeyAuthorIndex:    a.reversedPeopleDict,
+		KeyRules:          rules,
+		KeyBypasses:       bypasses,
+	}
+}
+
+func mergeAuthors(dst, src map[string]map[int]int) {
+	for owner, counts := range src {
+		byAuthor := dst[owner]
+		if byAuthor == nil {
+			byAuthor = make(map[int]int, len(counts))
+			dst[owner] = byAuthor
+		}
+
+		for id, n := range counts {
+			byAuthor[id] += n
+		}
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	commit, ok := tc.Data.(*CommitOwnership)
+	if !ok || commit == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = newTickData()
+		byTick[tc.Tick] = state
+	}
+
+	state.Total.Merge(commit.Total)
+	state.Unowned += commit.Unowned
+
+	if commit.Total.Changes > 0 {
+		state.Commits++
+	}
+
+	if commit.Bypass != nil {
+		bypass := *commit.Bypass
+		bypass.Hash = tc.CommitHash
+		bypass.Tick = tc.Tick
+		bypass.AuthorID = tc.AuthorID
+		bypass.Time = tc.Timestamp
+
+		state.Bypasses = append(state.Bypasses, bypass)
+	}
+
+	for owner, counts := range commit.Owners {
+		addOwner(state, owner, *counts)
+
+		if counts.Outside > 0 {
+			byAuthor := state.OutsideAuthors[owner]
+			if byAuthor == nil {
+				byAuthor = make(map[int]int)
+				state.OutsideAuthors[owner] = byAuthor
+			}
+
+			byAuthor[tc.AuthorID] += counts.Outside
+		}
+	}
+
+	return nil
+}
+
+func newTickData() *TickData {
+	return &TickData{
+		Owners:         make(map[string]*Changes),
+		OutsideAuthors: make(map[string]map[int]int),
+	}
+}
+
+func addOwner(state *TickData, owner string, counts Changes) {
+	if existing := state.Owners[owner]; existing != nil {
+		existing.Merge(counts)
+	} else {
+		state.Owners[owner] = &counts
+	}
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming == nil {
+		return existing
+	}
+
+	existing.Total.Merge(incoming.Total)
+	existing.Unowned += incoming.Unowned
+	existing.Commits += incoming.Commits
+	existing.Bypasses = append(existing.Bypasses, incoming.Bypasses...)
+
+	// Spilled states decode empty maps as nil.
+	if existing.Owners == nil {
+		existing.Owners = make(map[string]*Changes)
+	}
+
+	if existing.OutsideAuthors == nil {
+		existing.OutsideAuthors = make(map[string]map[int]int)
+	}
+
+	for owner, counts := range incoming.Owners {
+		addOwner(existing, owner, *counts)
+	}
+
+	mergeAuthors(existing.OutsideAuthors, incoming.OutsideAuthors)
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	size := tickBytes + int64(len(state.Owners))*ownerBytes + int64(len(state.Bypasses))*bypassBytes
+
+	for _, byAuthor := range state.OutsideAuthors {
+		size += int64(len(byAuthor)) * authorBytes
+	}
+
+	return size
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || state.Total.Changes == 0 && state.Unowned == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
@@ -0,0 +1,218 @@
+package codeowners
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	pkgcodeowners "github.com/Sumatoshi-tech/codefang/pkg/codeowners"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+const testRules = `*              @acme/core
+/payments/     @acme/payments alice@acme.com
+/docs/
+`
+
+func newTestAnalyzer(t *testing.T) *Analyzer {
+	t.Helper()
+
+	a := NewAnalyzer()
+	a.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+	a.Ticks = &plumbing.TicksSinceStart{}
+
+	require.NoError(t, a.Configure(map[string]any{
+		pkgplumbing.FactCodeOwners: pkgcodeowners.Parse([]byte(testRules)),
+	}))
+	require.NoError(t, a.Initialize(nil))
+
+	return a
+}
+
+func testHash(c string) gitlib.Hash {
+	return gitlib.NewHash(c + "000000000000000000000000000000000000000")
+}
+
+func TestAnalyzer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	assert.Equal(t, "history/codeowners", a.Name())
+	assert.NotEmpty(t, a.Description())
+	assert.Len(t, a.ListConfigurationOptions(), 1)
+	assert.False(t, a.SequentialOnly())
+}
+
+func TestAnalyzer_ConfigureTeams(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "teams.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("\"@acme/payments\": [bob@acme.com]\n"), 0o600))
+
+	a := NewAnalyzer()
+	require.NoError(t, a.Configure(map[string]any{ConfigCodeOwnersTeams: path}))
+	assert.Equal(t, pkgcodeowners.Teams{"@acme/payments": {"bob@acme.com"}}, a.Teams)
+
+	require.Error(t, a.Configure(map[string]any{ConfigCodeOwnersTeams: filepath.Join(t.TempDir(), "missing.yaml")}))
+}
+
+func TestAnalyzer_Consume(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+	a.Teams = pkgcodeowners.Teams{"@acme/payments": {"bob@acme.com"}}
+
+	a.TreeDiff.Changes = gitlib.Changes{
+		{Action: gitlib.Modify, From: gitlib.ChangeEntry{Name: "payments/charge.go"}, To: gitlib.ChangeEntry{Name: "payments/charge.go"}},
+		{Action: gitlib.Delete, From: gitlib.ChangeEntry{Name: "payments/refund.go"}},
+		{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: "cmd/main.go"}},
+		{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: "docs/guide.md"}},
+	}
+
+	commit := gitlib.NewTestCommit(testHash("c"), gitlib.Signature{Name: "Alice", Email: "alice@acme.com"}, "Charge")
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	assert.Equal(t, testHash("c"), tc.CommitHash)
+	assert.Equal(t, &CommitOwnership{
+		Total:   Changes{Changes: 3, Unresolved: 1},
+		Unowned: 1,
+		Owners: map[string]*Changes{
+			"@acme/core":     {Changes: 1, Unresolved: 1},
+			"@acme/payments": {Changes: 2, Outside: 2},
+			"alice@acme.com": {Changes: 2},
+		},
+	}, tc.Data)
+
+	outsider := gitlib.NewTestCommit(testHash("d"), gitlib.Signature{Name: "Mallory", Email: "mallory@acme.com"}, "Refund")
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: outsider})
+	require.NoError(t, err)
+
+	data, ok := tc.Data.(*CommitOwnership)
+	require.True(t, ok)
+	assert.Equal(t, Changes{Changes: 3, Outside: 2, Unresolved: 1}, data.Total)
+	assert.Equal(t, &Bypass{
+		Committer: "Mallory",
+		Owners:    []string{"@acme/payments", "alice@acme.com"},
+		Files:     []string{"payments/charge.go", "payments/refund.go"},
+	}, data.Bypass, "unresolved files do not make a bypass")
+
+	paired := gitlib.NewTestCommit(testHash("e"), gitlib.Signature{Name: "Mallory", Email: "mallory@acme.com"},
+		"Refund\n\nCo-authored-by: Bob <bob@acme.com>\n")
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: paired})
+	require.NoError(t, err)
+
+	data, ok = tc.Data.(*CommitOwnership)
+	require.True(t, ok)
+	assert.Equal(t, Changes{Changes: 3, Unresolved: 1}, data.Total, "a co-author among the owners")
+	assert.Equal(t, &Changes{Changes: 2}, data.Owners["@acme/payments"])
+	assert.Nil(t, data.Bypass)
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: outsider, IsMerge: true})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+
+	a.Owners = nil
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: outsider})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data, "repositories without CODEOWNERS emit nothing")
+}
+
+func TestAnalyzer_Fork(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+
+	forks := a.Fork(2)
+	require.Len(t, forks, 2)
+
+	for _, f := range forks {
+		clone, ok := f.(*Analyzer)
+		require.True(t, ok)
+		assert.NotSame(t, a.TreeDiff, clone.TreeDiff)
+		assert.Same(t, a.Owners, clone.Owners)
+	}
+}
+
+func TestAnalyzer_ReportFromTICKs(t *testing.T) {
+	t.Parallel()
+
+	byTick := make(map[int]*TickData)
+
+	for _, tc := range []analyze.TC{
+		{Tick: 2, AuthorID: 1, Data: &CommitOwnership{
+			Total: Changes{Changes: 2, Outside: 2}, Owners: map[string]*Changes{"@acme/web": {Changes: 2, Outside: 2}},
+		}},
+		{Tick: 0, AuthorID: 0, Data: &CommitOwnership{
+			Total: Changes{Changes: 1}, Unowned: 3, Owners: map[string]*Changes{"@acme/web": {Changes: 1}},
+		}},
+		{Tick: 2, AuthorID: 1, Timestamp: time.Unix(100, 0), CommitHash: testHash("f"), Data: &CommitOwnership{
+			Total: Changes{Changes: 1, Outside: 1}, Owners: map[string]*Changes{"@acme/web": {Changes: 1, Outside: 1}},
+			Bypass: &Bypass{Committer: "mallory", Owners: []string{"@acme/web"}, Files: []string{"web/app.js"}},
+		}},
+		{Tick: 1, Data: &CommitOwnership{Unowned: 2}},
+	} {
+		require.NoError(t, extractTC(tc, byTick))
+	}
+
+	ticks := make([]analyze.TICK, 0, len(byTick))
+
+	for _, tick := range []int{2, 0, 1} {
+		built, err := buildTick(tick, byTick[tick])
+		require.NoError(t, err)
+
+		ticks = append(ticks, built)
+	}
+
+	a := newTestAnalyzer(t)
+	a.reversedPeopleDict = []string{"alice", "mallory"}
+
+	report, err := a.ReportFromTICKs(context.Background(), ticks)
+	require.NoError(t, err)
+
+	assert.Equal(t, []TickChanges{
+		{Tick: 0, Total: Changes{Changes: 1}, Unowned: 3, Commits: 1},
+		{Tick: 1, Unowned: 2},
+		{Tick: 2, Total: Changes{Changes: 3, Outside: 3}, Commits: 2, Bypasses: 1},
+	}, report[KeyTicks])
+	assert.Equal(t, []Bypass{{
+		Hash: testHash("f"), Tick: 2, AuthorID: 1, Time: time.Unix(100, 0),
+		Committer: "mallory", Owners: []string{"@acme/web"}, Files: []string{"web/app.js"},
+	}}, report[KeyBypasses])
+	assert.Equal(t, map[string]Changes{"@acme/web": {Changes: 4, Outside: 3}}, report[KeyOwners])
+	assert.Equal(t, map[string]map[int]int{"@acme/web": {1: 3}}, report[KeyOutsideAuthors])
+	assert.Equal(t, 3, report[KeyRules])
+}
+
+func TestMergeState(t *testing.T) {
+	t.Parallel()
+
+	existing := &TickData{Total: Changes{Changes: 1}}
+	incoming := newTickData()
+	incoming.Total = Changes{Changes: 2, Outside: 1}
+	incoming.Unowned = 4
+	incoming.Commits = 2
+	incoming.Bypasses = []Bypass{{Tick: 1}}
+	incoming.Owners["@acme/web"] = &Changes{Changes: 2, Outside: 1}
+	incoming.OutsideAuthors["@acme/web"] = map[int]int{3: 1}
+
+	merged := mergeState(existing, incoming)
+	assert.Equal(t, Changes{Changes: 3, Outside: 1}, merged.Total)
+	assert.Equal(t, 4, merged.Unowned)
+	assert.Equal(t, 2, merged.Commits)
+	assert.Len(t, merged.Bypasses, 1)
+	assert.Equal(t, &Changes{Changes: 2, Outside: 1}, merged.Owners["@acme/web"])
+	assert.Equal(t, map[int]int{3: 1}, merged.OutsideAuthors["@acme/web"])
+	assert.Positive(t, sizeState(merged))
+}
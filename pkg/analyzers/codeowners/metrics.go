@@ -0,0 +1,255 @@
+package codeowners
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+// maxOutsideAuthors is the number of outside authors listed per owner.
+const maxOutsideAuthors = 5
+
+// Summary is the ownership of a set of changes.
+type Summary struct {
+	Changes    int `json:"changes"    yaml:"changes"`
+	Outside    int `json:"outside"    yaml:"outside"`
+	Unresolved int `json:"unresolved" yaml:"unresolved"`
+	// OutsideRatio is the share of outside changes among the changes whose
+	// authors could be resolved.
+	OutsideRatio float64 `json:"outside_ratio" yaml:"outside_ratio"`
+}
+
+// AuthorChanges counts the changes of one author.
+type AuthorChanges struct {
+	Author  string `json:"author"  yaml:"author"`
+	Changes int    `json:"changes" yaml:"changes"`
+}
+
+// OwnerSummary is the ownership of the changes to the files of one owner.
+type OwnerSummary struct {
+	Owner   string `json:"owner" yaml:"owner"`
+	Summary `yaml:",inline"`
+	// OutsideAuthors are the authors of the most outside changes, most first.
+	OutsideAuthors []AuthorChanges `json:"outside_authors" yaml:"outside_authors"`
+	// Bypasses counts the commits that changed files of the owner outside
+	// their owners.
+	Bypasses int `json:"bypasses" yaml:"bypasses"`
+}
+
+// TickSummary is the ownership of the changes of one tick.
+type TickSummary struct {
+	Tick    int `json:"tick"    yaml:"tick"`
+	Summary `yaml:",inline"`
+	Unowned int `json:"unowned" yaml:"unowned"`
+	// Commits counts the commits that changed owned files.
+	Commits     int     `json:"commits"      yaml:"commits"`
+	Bypasses    int     `json:"bypasses"     yaml:"bypasses"`
+	BypassRatio float64 `json:"bypass_ratio" yaml:"bypass_ratio"`
+}
+
+// BypassCommit is a commit that changed owned files while neither its
+// author nor any of its co-authors is among their owners.
+type BypassCommit struct {
+	Hash      string    `json:"hash"      yaml:"hash"`
+	Author    string    `json:"author"    yaml:"author"`
+	Committer string    `json:"committer" yaml:"committer"`
+	Tick      int       `json:"tick"      yaml:"tick"`
+	Time      time.Time `json:"time"      yaml:"time"`
+	Owners    []string  `json:"owners"    yaml:"owners"`
+	Files     []string  `json:"files"     yaml:"files"`
+}
+
+// Governance is the share of the commits to owned files that bypassed the
+// review of their owners.
+type Governance struct {
+	// Commits counts the commits that changed owned files.
+	Commits     int     `json:"commits"      yaml:"commits"`
+	Bypasses    int     `json:"bypasses"     yaml:"bypasses"`
+	BypassRatio float64 `json:"bypass_ratio" yaml:"bypass_ratio"`
+}
+
+// ComputedMetrics is the ownership of the changes of the history: overall,
+// per owner and per tick, with the commits that bypassed the owners.
+type ComputedMetrics struct {
+	// Rules is the number of rules of the CODEOWNERS file; zero when the
+	// repository has none.
+	Rules   int     `json:"rules"   yaml:"rules"`
+	Owned   Summary `json:"owned"   yaml:"owned"`
+	Unowned int     `json:"unowned" yaml:"unowned"`
+	// OwnedRatio is the share of changes to files with owners.
+	OwnedRatio float64 `json:"owned_ratio" yaml:"owned_ratio"`
+	// Owners are ordered by outside changes, most first.
+	Owners     []OwnerSummary `json:"owners"     yaml:"owners"`
+	Trend      []TickSummary  `json:"trend"      yaml:"trend"`
+	Governance Governance     `json:"governance" yaml:"governance"`
+	// Bypasses are in history order.
+	Bypasses []BypassCommit `json:"bypasses" yaml:"bypasses"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNameCodeOwners = "codeowners"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNameCodeOwners
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// ComputeAllMetrics computes the ownership of the changes of a report.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	series, _ := report[KeyTicks].([]TickChanges)
+	owners, _ := report[KeyOwners].(map[string]Changes)
+	outsideAuthors, _ := report[KeyOutsideAuthors].(map[string]map[int]int)
+	names, _ := report[KeyAuthorIndex].([]string)
+	rules, _ := report[KeyRules].(int)
+	bypasses, _ := report[KeyBypasses].([]Bypass)
+
+	m := &ComputedMetrics{Rules: rules, Trend: make([]TickSummary, len(series))}
+
+	var total Changes
+
+	for i, point := range series {
+		total.Merge(point.Total)
+		m.Unowned += point.Unowned
+		m.Governance.Commits += point.Commits
+		m.Trend[i] = TickSummary{
+			Tick:        point.Tick,
+			Summary:     newSummary(point.Total),
+			Unowned:     point.Unowned,
+			Commits:     point.Commits,
+			Bypasses:    point.Bypasses,
+			BypassRatio: ratio(point.Bypasses, point.Commits),
+		}
+	}
+
+	m.Owned = newSummary(total)
+	m.OwnedRatio = ratio(total.Changes, total.Changes+m.Unowned)
+
+	ownerBypasses := make(map[string]int)
+
+	for _, bypass := range bypasses {
+		m.Bypasses = append(m.Bypasses, BypassCommit{
+			Hash:      bypass.Hash.String(),
+			Author:    authorName(names, bypass.AuthorID),
+			Committer: bypass.Committer,
+			Tick:      bypass.Tick,
+			Time:      bypass.Time,
+			Owners:    bypass.Owners,
+			Files:     bypass.Files,
+		})
+
+		for _, owner := range bypass.Owners {
+			ownerBypasses[owner]++
+		}
+	}
+
+	m.Governance.Bypasses = len(bypasses)
+	m.Governance.BypassRatio = ratio(m.Governance.Bypasses, m.Governance.Commits)
+
+	for owner, counts := range owners {
+		m.Owners = append(m.Owners, OwnerSummary{
+			Owner:          owner,
+			Summary:        newSummary(counts),
+			OutsideAuthors: topAuthors(outsideAuthors[owner], names),
+			Bypasses:       ownerBypasses[owner],
+		})
+	}
+
+	m.scaleCounts(analyze.SampleFactor(report))
+
+	slices.SortFunc(m.Owners, func(x, y OwnerSummary) int {
+		return cmp.Or(cmp.Compare(y.Outside, x.Outside), cmp.Compare(y.Changes, x.Changes), cmp.Compare(x.Owner, y.Owner))
+	})
+
+	return m
+}
+
+// scaleCounts scales the change and commit counts of a sampled history by
+// factor to estimate the full history. Ratios and bypass commits are kept.
+func (m *ComputedMetrics) scaleCounts(factor float64) {
+	if factor <= 1 {
+		return
+	}
+
+	scale := func(n *int) { *n = analyze.ScaleCount(*n, factor) }
+
+	m.Owned.scale(factor)
+	scale(&m.Unowned)
+	scale(&m.Governance.Commits)
+	scale(&m.Governance.Bypasses)
+
+	for i := range m.Owners {
+		m.Owners[i].scale(factor)
+		scale(&m.Owners[i].Bypasses)
+
+		for j := range m.Owners[i].OutsideAuthors {
+			scale(&m.Owners[i].OutsideAuthors[j].Changes)
+		}
+	}
+
+	for i := range m.Trend {
+		m.Trend[i].scale(factor)
+		scale(&m.Trend[i].Unowned)
+		scale(&m.Trend[i].Commits)
+		scale(&m.Trend[i].Bypasses)
+	}
+}
+
+// scale scales the change counts of s by factor.
+func (s *Summary) scale(factor float64) {
+	s.Changes = analyze.ScaleCount(s.Changes, factor)
+	s.Outside = analyze.ScaleCount(s.Outside, factor)
+	s.Unresolved = analyze.ScaleCount(s.Unresolved, factor)
+}
+
+func newSummary(counts Changes) Summary {
+	return Summary{
+		Changes:      counts.Changes,
+		Outside:      counts.Outside,
+		Unresolved:   counts.Unresolved,
+		OutsideRatio: ratio(counts.Outside, counts.Changes-counts.Unresolved),
+	}
+}
+
+// topAuthors returns the authors of the most changes, most first.
+func topAuthors(counts map[int]int, names []string) []AuthorChanges {
+	authors := make([]AuthorChanges, 0, len(counts))
+
+	for id, n := range counts {
+		authors = append(authors, AuthorChanges{Author: authorName(names, id), Changes: n})
+	}
+
+	slices.SortFunc(authors, func(x, y AuthorChanges) int {
+		return cmp.Or(cmp.Compare(y.Changes, x.Changes), cmp.Compare(x.Author, y.Author))
+	})
+
+	return authors[:min(len(authors), maxOutsideAuthors)]
+}
+
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) / float64(total)
+}
+
+func authorName(names []string, id int) string {
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return identity.AuthorMissingName
+}
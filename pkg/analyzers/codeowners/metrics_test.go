@@ -0,0 +1,120 @@
+package codeowners
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+func TestComputeAllMetrics(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{
+		KeyTicks: []TickChanges{
+			{Tick: 0, Total: Changes{Changes: 4, Outside: 1}, Unowned: 2, Commits: 2, Bypasses: 1},
+			{Tick: 1, Total: Changes{Changes: 6, Outside: 3, Unresolved: 2}, Unowned: 8, Commits: 2},
+		},
+		KeyOwners: map[string]Changes{
+			"@acme/web":      {Changes: 6, Outside: 4},
+			"@acme/payments": {Changes: 2, Outside: 0},
+			"@acme/core":     {Changes: 2, Unresolved: 2},
+		},
+		KeyOutsideAuthors: map[string]map[int]int{
+			"@acme/web": {0: 1, 1: 2, 2: 1, 3: 1, 4: 1, 5: 1, 9: 3},
+		},
+		KeyAuthorIndex: []string{"a", "b", "c", "d", "e", "f"},
+		KeyRules:       7,
+		KeyBypasses: []Bypass{{
+			Hash: testHash("a"), Tick: 0, AuthorID: 1, Time: time.Unix(100, 0).UTC(),
+			Committer: "GitHub", Owners: []string{"@acme/core", "@acme/web"}, Files: []string{"web/app.js"},
+		}},
+	})
+
+	assert.Equal(t, 7, m.Rules)
+	assert.Equal(t, 10, m.Owned.Changes)
+	assert.Equal(t, 4, m.Owned.Outside)
+	assert.Equal(t, 2, m.Owned.Unresolved)
+	assert.InDelta(t, 0.5, m.Owned.OutsideRatio, 1e-9)
+	assert.Equal(t, 10, m.Unowned)
+	assert.InDelta(t, 0.5, m.OwnedRatio, 1e-9)
+
+	require.Len(t, m.Trend, 2)
+	assert.InDelta(t, 0.25, m.Trend[0].OutsideRatio, 1e-9)
+	assert.InDelta(t, 0.75, m.Trend[1].OutsideRatio, 1e-9)
+	assert.Equal(t, 8, m.Trend[1].Unowned)
+	assert.InDelta(t, 0.5, m.Trend[0].BypassRatio, 1e-9)
+	assert.Zero(t, m.Trend[1].BypassRatio)
+
+	assert.Equal(t, Governance{Commits: 4, Bypasses: 1, BypassRatio: 0.25}, m.Governance)
+	assert.Equal(t, []BypassCommit{{
+		Hash: testHash("a").String(), Author: "b", Committer: "GitHub", Tick: 0, Time: time.Unix(100, 0).UTC(),
+		Owners: []string{"@acme/core", "@acme/web"}, Files: []string{"web/app.js"},
+	}}, m.Bypasses)
+
+	require.Len(t, m.Owners, 3)
+	assert.Equal(t, "@acme/web", m.Owners[0].Owner)
+	assert.InDelta(t, 4.0/6, m.Owners[0].OutsideRatio, 1e-9)
+	assert.Equal(t, []AuthorChanges{
+		{Author: identity.AuthorMissingName, Changes: 3},
+		{Author: "b", Changes: 2},
+		{Author: "a", Changes: 1},
+		{Author: "c", Changes: 1},
+		{Author: "d", Changes: 1},
+	}, m.Owners[0].OutsideAuthors)
+	assert.Equal(t, 1, m.Owners[0].Bypasses)
+	assert.Equal(t, "@acme/core", m.Owners[1].Owner)
+	assert.Equal(t, 1, m.Owners[1].Bypasses)
+	assert.Zero(t, m.Owners[1].OutsideRatio, "unresolved changes are not outside")
+	assert.Equal(t, "@acme/payments", m.Owners[2].Owner)
+}
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{})
+
+	assert.Zero(t, m.Rules)
+	assert.Equal(t, Summary{}, m.Owned)
+	assert.Zero(t, m.OwnedRatio)
+	assert.Empty(t, m.Owners)
+	assert.Empty(t, m.Trend)
+	assert.Empty(t, m.Bypasses)
+	assert.Equal(t, Governance{}, m.Governance)
+}
+
+func TestComputeAllMetrics_ScalesSampledCounts(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{
+		KeyTicks: []TickChanges{
+			{Tick: 0, Total: Changes{Changes: 4, Outside: 1}, Unowned: 2, Commits: 2, Bypasses: 1},
+		},
+		KeyOwners:                     map[string]Changes{"@acme/web": {Changes: 4, Outside: 1}},
+		KeyBypasses:                   []Bypass{{Hash: testHash("a"), Owners: []string{"@acme/web"}}},
+		analyze.ReportKeySampleFactor: 3.0,
+	})
+
+	assert.Equal(t, Summary{Changes: 12, Outside: 3, OutsideRatio: 0.25}, m.Owned)
+	assert.Equal(t, 6, m.Unowned)
+	assert.Equal(t, Governance{Commits: 6, Bypasses: 3, BypassRatio: 0.5}, m.Governance)
+	assert.Equal(t, 6, m.Trend[0].Commits)
+	assert.Equal(t, 3, m.Owners[0].Bypasses)
+	assert.Len(t, m.Bypasses, 1, "bypass commits are listed as sampled")
+}
+
+func TestGenerateSections(t *testing.T) {
+	t.Parallel()
+
+	sections, err := (&Analyzer{}).GenerateSections(analyze.Report{
+		KeyTicks:  []TickChanges{{Tick: 0, Total: Changes{Changes: 2, Outside: 1}}},
+		KeyOwners: map[string]Changes{"@acme/web": {Changes: 2, Outside: 1}},
+	})
+	require.NoError(t, err)
+	require.Len(t, sections, 4)
+	assert.Equal(t, "Code Ownership", sections[0].Title)
+}
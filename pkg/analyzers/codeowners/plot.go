@@ -0,0 +1,148 @@
+package codeowners
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const (
+	percent      = 100
+	statColumns  = 4
+	shortHashLen = 8
+)
+
+// RegisterPlotSections registers the codeowners plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/codeowners", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeAllMetrics(report)
+
+	return []plotpage.Section{
+		{
+			Title:    "Code Ownership",
+			Subtitle: "Changes to files with owners in CODEOWNERS (" + strconv.Itoa(m.Rules) + " rules).",
+			Chart: plotpage.NewGrid(statColumns,
+				plotpage.NewStat("Owned Changes", formatPercent(m.OwnedRatio)),
+				plotpage.NewStat("Outside Owners", formatPercent(m.Owned.OutsideRatio)),
+				plotpage.NewStat("Bypassed Commits", formatPercent(m.Governance.BypassRatio)),
+				plotpage.NewStat("Unresolved", strconv.Itoa(m.Owned.Unresolved)),
+			),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"<strong>Outside</strong> = changed by an author who is not among the owners of the file",
+					"<strong>Bypassed</strong> = commit changing owned files while neither its author nor its co-authors are owners",
+					"<strong>Unresolved</strong> = owned by teams whose members are unknown; list them with --codeowners-teams",
+				},
+			},
+		},
+		{
+			Title:    "Outside Changes Over Time",
+			Subtitle: "Share of the changes and commits to owned files made outside the owners, per tick.",
+			Chart:    plotpage.WrapChart(buildTrendChart(m.Trend)),
+		},
+		{
+			Title:    "Review Bypasses",
+			Subtitle: strconv.Itoa(len(m.Bypasses)) + " commits changed owned files without an owner among their authors.",
+			Chart:    buildBypassTable(m.Bypasses),
+		},
+		{
+			Title:    "Owners",
+			Subtitle: "Changes to the files of every owner, most outside changes first.",
+			Chart:    buildOwnerTable(m.Owners),
+		},
+	}, nil
+}
+
+func buildTrendChart(trend []TickSummary) *charts.Line {
+	labels := make([]string, len(trend))
+	outside := make([]plotpage.SeriesData, len(trend))
+	bypassed := make([]plotpage.SeriesData, len(trend))
+
+	for i, point := range trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		outside[i] = point.OutsideRatio * percent
+		bypassed[i] = point.BypassRatio * percent
+	}
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: "Outside Changes", Data: outside},
+		{Name: "Bypassed Commits", Data: bypassed},
+	}, "Share (%)")
+}
+
+func buildOwnerTable(owners []OwnerSummary) *plotpage.Table {
+	table := plotpage.NewTable([]string{
+		"Owner", "Changes", "Outside", "Outside Ratio", "Unresolved", "Bypasses", "Outside Authors",
+	}).
+		WithSearch("Filter owners...")
+
+	for _, owner := range owners {
+		authors := make([]string, len(owner.OutsideAuthors))
+		for i, author := range owner.OutsideAuthors {
+			authors[i] = html.EscapeString(author.Author) + " (" + strconv.Itoa(author.Changes) + ")"
+		}
+
+		table.AddRow(
+			html.EscapeString(owner.Owner),
+			strconv.Itoa(owner.Changes),
+			strconv.Itoa(owner.Outside),
+			formatPercent(owner.OutsideRatio),
+			strconv.Itoa(owner.Unresolved),
+			strconv.Itoa(owner.Bypasses),
+			strings.Join(authors, "<br>"),
+		)
+	}
+
+	return table
+}
+
+func buildBypassTable(bypasses []BypassCommit) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Commit", "Author", "Committer", "Tick", "Owners", "Files"}).
+		WithSearch("Filter bypasses...")
+
+	for _, bypass := range bypasses {
+		table.AddRow(
+			shortHash(bypass.Hash),
+			html.EscapeString(bypass.Author),
+			html.EscapeString(bypass.Committer),
+			strconv.Itoa(bypass.Tick),
+			escapeJoin(bypass.Owners),
+			escapeJoin(bypass.Files),
+		)
+	}
+
+	return table
+}
+
+func escapeJoin(values []string) string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = html.EscapeString(value)
+	}
+
+	return strings.Join(escaped, "<br>")
+}
+
+func shortHash(hash string) string {
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+
+	return hash
+}
+
+func formatPercent(ratio float64) string {
+	return strconv.FormatFloat(ratio*percent, 'f', 1, 64) + "%"
+}
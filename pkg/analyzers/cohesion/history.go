@@ -0,0 +1,334 @@
+package cohesion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+)
+
+// Configuration option keys for the cohesion history analyzer.
+const (
+	ConfigCohesionMaxFileSize = "Cohesion.MaxFileSize"
+
+	defaultMaxFileSize = 1 << 20
+	// commitSize and unitSize estimate the bytes of one CommitCohesion and
+	// one UnitCohesion held by the aggregator.
+	commitSize = 96
+	unitSize   = 80
+)
+
+// Report keys of the history analyzer.
+const (
+	KeyCommits  = "commits"
+	KeyTickSize = "tick_size"
+)
+
+// ErrParserNotInitialized indicates Consume ran before Initialize.
+var ErrParserNotInitialized = errors.New("parser not initialized")
+
+// CommitCohesion is the mean LCOM of the classes and packages with at least
+// two functions after one commit, with the units whose cohesion it changed.
+type CommitCohesion struct {
+	Hash        gitlib.Hash
+	Tick        int
+	Time        time.Time
+	Classes     int
+	ClassLCOM   float64
+	Packages    int
+	PackageLCOM float64
+	Units       []UnitCohesion
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data,
+// with commits in the order they were analyzed.
+type TickData struct {
+	Commits []CommitCohesion
+}
+
+// HistoryAnalyzer replays the commit history and records the LCOM of every
+// class and package after every commit that changed it.
+type HistoryAnalyzer struct {
+	*analyze.BaseHistoryAnalyzer[*HistoryMetrics]
+
+	TreeDiff  *plumbing.TreeDiffAnalyzer
+	BlobCache *plumbing.BlobCacheAnalyzer
+	Ticks     *plumbing.TicksSinceStart
+
+	MaxFileSize int
+
+	parser   *uast.Parser
+	analyzer *Analyzer
+	state    *tracker
+	tickSize time.Duration
+}
+
+// NewHistoryAnalyzer creates a new HistoryAnalyzer.
+func NewHistoryAnalyzer() *HistoryAnalyzer {
+	a := &HistoryAnalyzer{MaxFileSize: defaultMaxFileSize}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*HistoryMetrics]{
+		Desc: analyze.Descriptor{
+			ID:          "history/cohesion",
+			Mode:        analyze.ModeHistory,
+			Description: "Tracks the LCOM of every class and package over time.",
+		},
+		Sequential: true,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, Memory: analyze.MemoryMedium},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigCohesionMaxFileSize,
+				Description: "Specifies the file size threshold. Files that exceed it are not analyzed.",
+				Flag:        "cohesion-max-file-size",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultMaxFileSize,
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*HistoryMetrics, error) {
+			return ComputeHistoryMetrics(report), nil
+		},
+		AggregatorFn: newHistoryAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (h *HistoryAnalyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigCohesionMaxFileSize].(int); exists && val > 0 {
+		h.MaxFileSize = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		h.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize resets the tracked tree and loads the UAST parser.
+func (h *HistoryAnalyzer) Initialize(_ *gitlib.Repository) error {
+	if h.MaxFileSize <= 0 {
+		h.MaxFileSize = defaultMaxFileSize
+	}
+
+	h.analyzer = NewAnalyzer()
+	h.state = newTracker(h.analyzer)
+
+	var err error
+
+	h.parser, err = uast.NewParser()
+	if err != nil {
+		return fmt.Errorf("failed to initialize UAST parser: %w", err)
+	}
+
+	return nil
+}
+
+// Consume updates the tracked files with the files the commit changed and
+// emits the units whose cohesion changed.
+func (h *HistoryAnalyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if h.parser == nil || h.state == nil {
+		return analyze.TC{}, ErrParserNotInitialized
+	}
+
+	touched := make(map[unitKey]bool)
+
+	for _, change := range h.TreeDiff.Changes {
+		switch change.Action {
+		case gitlib.Delete:
+			h.state.remove(change.From.Name, touched)
+		case gitlib.Modify, gitlib.Insert:
+			if change.Action == gitlib.Modify && change.From.Name != change.To.Name {
+				h.state.remove(change.From.Name, touched)
+			}
+
+			h.readFile(ctx, change.To, touched)
+		}
+	}
+
+	units := h.state.update(touched)
+	if len(units) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	classes, packages := h.state.totals[UnitClass], h.state.totals[UnitPackage]
+
+	return analyze.TC{
+		Data: &CommitCohesion{
+			Classes:     classes.units,
+			ClassLCOM:   classes.mean(),
+			Packages:    packages.units,
+			PackageLCOM: packages.mean(),
+			Units:       units,
+		},
+		CommitHash: ac.Commit.Hash(),
+	}, nil
+}
+
+// readFile updates the tracked functions of entry. Files that are too large
+// or that the parser does not support are dropped.
+func (h *HistoryAnalyzer) readFile(ctx context.Context, entry gitlib.ChangeEntry, touched map[unitKey]bool) {
+	blob := h.BlobCache.Cache[entry.Hash]
+	if blob == nil || blob.Size() > int64(h.MaxFileSize) || !h.parser.IsSupported(entry.Name) {
+		h.state.remove(entry.Name, touched)
+
+		return
+	}
+
+	root, err := h.parser.Parse(ctx, entry.Name, blob.Data)
+	if err != nil {
+		h.state.remove(entry.Name, touched)
+
+		return
+	}
+
+	h.state.set(entry.Name, h.analyzer.memberFunctions(root), touched)
+}
+
+// Fork creates copies of the analyzer that share the tracked tree.
+func (h *HistoryAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *h
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.BlobCache = &plumbing.BlobCacheAnalyzer{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (h *HistoryAnalyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (h *HistoryAnalyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes:   h.TreeDiff.Changes,
+		BlobCache: h.BlobCache.Cache,
+		Tick:      h.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (h *HistoryAnalyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	h.TreeDiff.Changes = snapshot.Changes
+	h.BlobCache.Cache = snapshot.BlobCache
+	h.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot is a no-op for cohesion.
+func (h *HistoryAnalyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (h *HistoryAnalyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return h.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (h *HistoryAnalyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return h.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the commits that changed any unit in history order.
+func (h *HistoryAnalyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	var commits []CommitCohesion
+
+	for _, tick := range ticks {
+		if td, ok := tick.Data.(*TickData); ok && td != nil {
+			commits = append(commits, td.Commits...)
+		}
+	}
+
+	return analyze.Report{
+		KeyCommits:  commits,
+		KeyTickSize: h.tickSize,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	cc, ok := tc.Data.(*CommitCohesion)
+	if !ok || cc == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{}
+		byTick[tc.Tick] = state
+	}
+
+	commit := *cc
+	commit.Hash = tc.CommitHash
+	commit.Tick = tc.Tick
+	commit.Time = tc.Timestamp
+
+	state.Commits = append(state.Commits, commit)
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming != nil {
+		existing.Commits = append(existing.Commits, incoming.Commits...)
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	var units int
+
+	for _, cc := range state.Commits {
+		units += len(cc.Units)
+	}
+
+	return int64(len(state.Commits))*commitSize + int64(units)*unitSize
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || len(state.Commits) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newHistoryAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
@@ -0,0 +1,145 @@
+package cohesion
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// TickCohesion is the mean LCOM of the classes and packages with at least two
+// functions at the end of a tick.
+type TickCohesion struct {
+	Tick        int     `json:"tick"         yaml:"tick"`
+	Classes     int     `json:"classes"      yaml:"classes"`
+	ClassLCOM   float64 `json:"class_lcom"   yaml:"class_lcom"`
+	Packages    int     `json:"packages"     yaml:"packages"`
+	PackageLCOM float64 `json:"package_lcom" yaml:"package_lcom"`
+}
+
+// UnitPoint is the cohesion of a unit at the end of a tick.
+type UnitPoint struct {
+	Tick      int     `json:"tick"      yaml:"tick"`
+	Functions int     `json:"functions" yaml:"functions"`
+	LCOM      float64 `json:"lcom"      yaml:"lcom"`
+}
+
+// UnitHistory is the cohesion of one class or package across the analysis
+// window. StartLCOM is the LCOM of the first commit that left the unit with
+// at least two functions.
+type UnitHistory struct {
+	Name      string      `json:"name"       yaml:"name"`
+	Functions int         `json:"functions"  yaml:"functions"`
+	StartLCOM float64     `json:"start_lcom" yaml:"start_lcom"`
+	EndLCOM   float64     `json:"end_lcom"   yaml:"end_lcom"`
+	Change    float64     `json:"change"     yaml:"change"`
+	Points    []UnitPoint `json:"points"     yaml:"points"`
+
+	started bool
+}
+
+// HistoryMetrics is the cohesion trend of a repository and of its classes
+// and packages. Improved and Degraded count the units whose LCOM fell and
+// rose over the window.
+type HistoryMetrics struct {
+	Trend    []TickCohesion `json:"trend"    yaml:"trend"`
+	Improved int            `json:"improved" yaml:"improved"`
+	Degraded int            `json:"degraded" yaml:"degraded"`
+	// Classes and Packages list the units that still exist and have at least
+	// two functions, the most degraded first.
+	Classes  []UnitHistory `json:"classes"  yaml:"classes"`
+	Packages []UnitHistory `json:"packages" yaml:"packages"`
+}
+
+// ComputeHistoryMetrics replays the commits of a history report.
+func ComputeHistoryMetrics(report analyze.Report) *HistoryMetrics {
+	commits, _ := report[KeyCommits].([]CommitCohesion)
+
+	m := &HistoryMetrics{}
+	units := map[string]map[string]*UnitHistory{UnitClass: {}, UnitPackage: {}}
+
+	for _, cc := range commits {
+		m.Trend = appendTrend(m.Trend, cc)
+
+		for _, unit := range cc.Units {
+			addUnitPoint(units[unit.Kind], unit, cc.Tick)
+		}
+	}
+
+	m.Classes = m.rankUnits(units[UnitClass])
+	m.Packages = m.rankUnits(units[UnitPackage])
+
+	return m
+}
+
+// appendTrend records the state after cc, replacing the state of an earlier
+// commit of the same tick.
+func appendTrend(trend []TickCohesion, cc CommitCohesion) []TickCohesion {
+	point := TickCohesion{
+		Tick:        cc.Tick,
+		Classes:     cc.Classes,
+		ClassLCOM:   cc.ClassLCOM,
+		Packages:    cc.Packages,
+		PackageLCOM: cc.PackageLCOM,
+	}
+
+	if n := len(trend); n > 0 && trend[n-1].Tick == cc.Tick {
+		trend[n-1] = point
+
+		return trend
+	}
+
+	return append(trend, point)
+}
+
+// addUnitPoint records unit at tick, replacing the point of an earlier
+// commit of the same tick. A removed unit starts over if it comes back.
+func addUnitPoint(histories map[string]*UnitHistory, unit UnitCohesion, tick int) {
+	history := histories[unit.Name]
+	if history == nil || history.Functions == 0 {
+		history = &UnitHistory{Name: unit.Name}
+		histories[unit.Name] = history
+	}
+
+	if !history.started && unit.Functions >= 2 {
+		history.StartLCOM, history.started = unit.LCOM, true
+	}
+
+	history.Functions = unit.Functions
+	history.EndLCOM = unit.LCOM
+	history.Change = history.EndLCOM - history.StartLCOM
+
+	point := UnitPoint{Tick: tick, Functions: unit.Functions, LCOM: unit.LCOM}
+	if n := len(history.Points); n > 0 && history.Points[n-1].Tick == tick {
+		history.Points[n-1] = point
+	} else {
+		history.Points = append(history.Points, point)
+	}
+}
+
+// rankUnits returns the units that still exist and have at least two
+// functions, the most degraded first, and counts the improved and degraded.
+func (m *HistoryMetrics) rankUnits(histories map[string]*UnitHistory) []UnitHistory {
+	result := make([]UnitHistory, 0, len(histories))
+
+	for _, history := range histories {
+		if history.Functions < 2 {
+			continue
+		}
+
+		switch {
+		case history.Change > 0:
+			m.Degraded++
+		case history.Change < 0:
+			m.Improved++
+		}
+
+		result = append(result, *history)
+	}
+
+	slices.SortFunc(result, func(x, y UnitHistory) int {
+		return cmp.Or(cmp.Compare(y.Change, x.Change), cmp.Compare(x.Name, y.Name))
+	})
+
+	return result
+}
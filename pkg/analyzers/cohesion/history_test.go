@@ -0,0 +1,203 @@
+package cohesion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+func TestAnalyzer_MemberFunctions(t *testing.T) {
+	t.Parallel()
+
+	analyzer := NewAnalyzer()
+
+	root := &node.Node{
+		Type: node.UASTFile,
+		Children: []*node.Node{
+			{
+				Type:  node.UASTClass,
+				Roles: []node.Role{node.RoleClass, node.RoleDeclaration},
+				Props: map[string]string{"name": "Stack"},
+				Children: []*node.Node{
+					{
+						Type:  node.UASTMethod,
+						Roles: []node.Role{node.RoleFunction, node.RoleDeclaration},
+						Props: map[string]string{"name": "push"},
+						Children: []*node.Node{
+							// Nested functions belong to the enclosing function.
+							{
+								Type:  node.UASTFunction,
+								Roles: []node.Role{node.RoleFunction},
+								Props: map[string]string{"name": "inner"},
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:  node.UASTMethod,
+				Roles: []node.Role{node.RoleFunction, node.RoleDeclaration},
+				Props: map[string]string{"name": "Len"},
+				Children: []*node.Node{
+					{
+						Type:  node.UASTParameter,
+						Roles: []node.Role{node.RoleParameter},
+						Children: []*node.Node{
+							{Type: node.UASTIdentifier, Token: "q", Roles: []node.Role{node.RoleName}},
+							{Type: node.UASTIdentifier, Token: "*Queue", Roles: []node.Role{node.RoleType}},
+						},
+					},
+					{Type: node.UASTIdentifier, Token: "Len", Roles: []node.Role{node.RoleName}},
+				},
+			},
+			{
+				Type:  node.UASTFunction,
+				Roles: []node.Role{node.RoleFunction, node.RoleDeclaration},
+				Props: map[string]string{"name": "main"},
+			},
+		},
+	}
+
+	members := analyzer.memberFunctions(root)
+	require.Len(t, members, 3)
+	assert.Equal(t, "Stack", members[0].Class)
+	assert.Equal(t, "push", members[0].Name)
+	assert.Equal(t, "Queue", members[1].Class)
+	assert.Equal(t, "Len", members[1].Name)
+	assert.Empty(t, members[2].Class)
+	assert.Equal(t, "main", members[2].Name)
+}
+
+func TestTracker_Update(t *testing.T) {
+	t.Parallel()
+
+	state := newTracker(NewAnalyzer())
+	touched := make(map[unitKey]bool)
+
+	state.set("pkg/a.go", []memberFunction{
+		{Class: "T", Function: Function{Name: "get", Variables: []string{"x"}}},
+		{Class: "T", Function: Function{Name: "set", Variables: []string{"x"}}},
+	}, touched)
+	state.set("pkg/b.go", []memberFunction{
+		{Function: Function{Name: "helper", Variables: []string{"y"}}},
+	}, touched)
+
+	units := state.update(touched)
+	require.Len(t, units, 2)
+	assert.Equal(t, UnitCohesion{Kind: UnitClass, Name: "pkg.T", Functions: 2, LCOM: 0}, units[0])
+	assert.Equal(t, UnitPackage, units[1].Kind)
+	assert.Equal(t, "pkg", units[1].Name)
+	assert.Equal(t, 3, units[1].Functions)
+	assert.InDelta(t, 0.5, units[1].LCOM, 1e-9)
+	assert.Equal(t, 1, state.totals[UnitClass].units)
+	assert.InDelta(t, 0.5, state.totals[UnitPackage].mean(), 1e-9)
+
+	// Dropping the helper only changes the package.
+	touched = make(map[unitKey]bool)
+	state.remove("pkg/b.go", touched)
+
+	units = state.update(touched)
+	require.Len(t, units, 1)
+	assert.Equal(t, UnitCohesion{Kind: UnitPackage, Name: "pkg", Functions: 2, LCOM: 0}, units[0])
+
+	// Removing the last file removes both units.
+	touched = make(map[unitKey]bool)
+	state.remove("pkg/a.go", touched)
+
+	units = state.update(touched)
+	require.Len(t, units, 2)
+	assert.Zero(t, units[0].Functions)
+	assert.Zero(t, units[1].Functions)
+	assert.Zero(t, state.totals[UnitClass].units)
+	assert.Zero(t, state.totals[UnitPackage].units)
+	assert.Empty(t, state.units)
+}
+
+func TestHistoryAnalyzer_ReportAndMetrics(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hash := func(c string) gitlib.Hash {
+		return gitlib.NewHash(c + "000000000000000000000000000000000000000")
+	}
+
+	byTick := make(map[int]*TickData)
+
+	for _, tc := range []analyze.TC{
+		{
+			Tick: 0, CommitHash: hash("a"), Timestamp: start,
+			Data: &CommitCohesion{Classes: 1, ClassLCOM: 0.2, Packages: 1, PackageLCOM: 0.5, Units: []UnitCohesion{
+				{Kind: UnitClass, Name: "app.Order", Functions: 1},
+				{Kind: UnitClass, Name: "app.User", Functions: 2, LCOM: 0.2},
+				{Kind: UnitPackage, Name: "app", Functions: 3, LCOM: 0.5},
+			}},
+		},
+		{
+			Tick: 0, CommitHash: hash("b"), Timestamp: start.Add(time.Hour),
+			Data: &CommitCohesion{Classes: 2, ClassLCOM: 0.4, Packages: 1, PackageLCOM: 0.6, Units: []UnitCohesion{
+				{Kind: UnitClass, Name: "app.Order", Functions: 2, LCOM: 0.6},
+				{Kind: UnitPackage, Name: "app", Functions: 4, LCOM: 0.6},
+			}},
+		},
+		{
+			Tick: 3, CommitHash: hash("c"), Timestamp: start.Add(72 * time.Hour),
+			Data: &CommitCohesion{Classes: 2, ClassLCOM: 0.35, Packages: 1, PackageLCOM: 0.4, Units: []UnitCohesion{
+				{Kind: UnitClass, Name: "app.User", Functions: 2, LCOM: 0.1},
+				{Kind: UnitPackage, Name: "app", Functions: 4, LCOM: 0.4},
+			}},
+		},
+	} {
+		require.NoError(t, extractTC(tc, byTick))
+	}
+
+	ticks := make([]analyze.TICK, 0, len(byTick))
+	for _, tick := range []int{0, 3} {
+		built, err := buildTick(tick, byTick[tick])
+		require.NoError(t, err)
+
+		ticks = append(ticks, built)
+	}
+
+	h := NewHistoryAnalyzer()
+
+	report, err := h.ReportFromTICKs(context.Background(), ticks)
+	require.NoError(t, err)
+
+	commits, ok := report[KeyCommits].([]CommitCohesion)
+	require.True(t, ok)
+	require.Len(t, commits, 3)
+	assert.Equal(t, hash("b"), commits[1].Hash)
+	assert.Equal(t, 3, commits[2].Tick)
+
+	m := ComputeHistoryMetrics(report)
+	assert.Equal(t, []TickCohesion{
+		{Tick: 0, Classes: 2, ClassLCOM: 0.4, Packages: 1, PackageLCOM: 0.6},
+		{Tick: 3, Classes: 2, ClassLCOM: 0.35, Packages: 1, PackageLCOM: 0.4},
+	}, m.Trend)
+
+	// Order's baseline is its first measurable LCOM, not the single-function zero.
+	require.Len(t, m.Classes, 2)
+	assert.Equal(t, "app.Order", m.Classes[0].Name)
+	assert.InDelta(t, 0.6, m.Classes[0].StartLCOM, 1e-9)
+	assert.InDelta(t, 0, m.Classes[0].Change, 1e-9)
+	assert.Equal(t, "app.User", m.Classes[1].Name)
+	assert.InDelta(t, -0.1, m.Classes[1].Change, 1e-9)
+	assert.Len(t, m.Classes[1].Points, 2)
+
+	require.Len(t, m.Packages, 1)
+	assert.InDelta(t, -0.1, m.Packages[0].Change, 1e-9)
+	assert.Equal(t, []UnitPoint{{Tick: 0, Functions: 4, LCOM: 0.6}, {Tick: 3, Functions: 4, LCOM: 0.4}}, m.Packages[0].Points)
+	assert.Equal(t, 2, m.Improved)
+	assert.Zero(t, m.Degraded)
+
+	sections, err := h.GenerateSections(report)
+	require.NoError(t, err)
+	assert.Len(t, sections, 3)
+}
@@ -3,10 +3,12 @@ package cohesion
 import (
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"math"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
@@ -28,6 +30,7 @@ const (
 	pQ1                = 0.25
 	pMedian            = 0.50
 	pQ3                = 0.75
+	historyUnitsLimit  = 20
 )
 
 // ErrInvalidFunctions indicates the report doesn't contain expected functions data.
@@ -38,6 +41,9 @@ func RegisterPlotSections() {
 	analyze.RegisterPlotSections("static/cohesion", func(report analyze.Report) ([]plotpage.Section, error) {
 		return (&Analyzer{}).generateSections(report)
 	})
+	analyze.RegisterPlotSections("history/cohesion", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&HistoryAnalyzer{}).GenerateSections(report)
+	})
 }
 
 // FormatReportPlot generates an HTML plot visualization for cohesion analysis.
@@ -526,3 +532,70 @@ func createEmptyBoxPlot() *charts.BoxPlot {
 
 	return bp
 }
+
+// GenerateSections returns the sections for combined reports.
+func (h *HistoryAnalyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeHistoryMetrics(report)
+
+	return []plotpage.Section{
+		{
+			Title:    "Cohesion Trend",
+			Subtitle: "Mean LCOM of the classes and packages with at least two functions, per tick.",
+			Chart:    plotpage.WrapChart(buildCohesionTrendChart(m.Trend)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"<strong>LCOM-HS</strong> runs from 0 (functions share all variables) to 1 (they share none); lower is better",
+					"A falling line = refactoring is pulling related logic together",
+					"Use <code>--tick-granularity quarter</code> to compare quarter over quarter",
+				},
+			},
+		},
+		{
+			Title: "Class Cohesion",
+			Subtitle: strconv.Itoa(m.Improved) + " units improved and " + strconv.Itoa(m.Degraded) +
+				" degraded over the analyzed history. Most degraded classes first.",
+			Chart: buildUnitTable("Class", m.Classes),
+		},
+		{
+			Title:    "Package Cohesion",
+			Subtitle: "Most degraded packages first.",
+			Chart:    buildUnitTable("Package", m.Packages),
+		},
+	}, nil
+}
+
+// buildCohesionTrendChart draws the mean class and package LCOM per tick.
+func buildCohesionTrendChart(trend []TickCohesion) *charts.Line {
+	labels := make([]string, len(trend))
+	classes := make([]plotpage.SeriesData, len(trend))
+	packages := make([]plotpage.SeriesData, len(trend))
+
+	for i, point := range trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		classes[i] = point.ClassLCOM
+		packages[i] = point.PackageLCOM
+	}
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: "Classes", Data: classes},
+		{Name: "Packages", Data: packages},
+	}, "Mean LCOM")
+}
+
+func buildUnitTable(column string, units []UnitHistory) *plotpage.Table {
+	table := plotpage.NewTable([]string{column, "Functions", "Start LCOM", "End LCOM", "Change"}).
+		WithSearch("Filter...")
+
+	for _, unit := range units[:min(len(units), historyUnitsLimit)] {
+		table.AddRow(
+			html.EscapeString(unit.Name),
+			strconv.Itoa(unit.Functions),
+			fmt.Sprintf("%.2f", unit.StartLCOM),
+			fmt.Sprintf("%.2f", unit.EndLCOM),
+			fmt.Sprintf("%+.2f", unit.Change),
+		)
+	}
+
+	return table
+}
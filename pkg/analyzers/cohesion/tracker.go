@@ -0,0 +1,194 @@
+package cohesion
+
+import (
+	"cmp"
+	"path"
+	"slices"
+)
+
+// UnitCohesion is the LCOM of one class or package after a commit. A unit
+// without functions left was removed.
+type UnitCohesion struct {
+	Kind      string
+	Name      string
+	Functions int
+	LCOM      float64
+}
+
+// unitKey identifies a class or package. Classes belong to a package, as Go
+// methods of one type spread across the files of a package.
+type unitKey struct {
+	Kind    string
+	Package string
+	Class   string
+}
+
+// name is the package, or the package and the class, of the unit.
+func (k unitKey) name() string {
+	if k.Kind == UnitPackage {
+		return k.Package
+	}
+
+	if k.Package == "." {
+		return k.Class
+	}
+
+	return k.Package + "." + k.Class
+}
+
+// kindTotals sums the LCOM of the units of one kind that have at least two
+// functions; LCOM is not defined for fewer.
+type kindTotals struct {
+	units int
+	lcom  float64
+}
+
+func (k *kindTotals) mean() float64 {
+	if k.units == 0 {
+		return 0
+	}
+
+	return k.lcom / float64(k.units)
+}
+
+// tracker holds the functions of every tracked file and the cohesion of every
+// class and package.
+type tracker struct {
+	analyzer *Analyzer
+	files    map[string][]memberFunction
+	dirs     map[string]map[string]bool // Package -> files.
+	units    map[unitKey]UnitCohesion
+	totals   map[string]*kindTotals
+}
+
+func newTracker(analyzer *Analyzer) *tracker {
+	return &tracker{
+		analyzer: analyzer,
+		files:    make(map[string][]memberFunction),
+		dirs:     make(map[string]map[string]bool),
+		units:    make(map[unitKey]UnitCohesion),
+		totals:   map[string]*kindTotals{UnitClass: {}, UnitPackage: {}},
+	}
+}
+
+// set replaces the functions of file and marks the units it touched.
+func (t *tracker) set(file string, members []memberFunction, touched map[unitKey]bool) {
+	dir := path.Dir(file)
+
+	for _, member := range t.files[file] {
+		touch(touched, dir, member.Class)
+	}
+
+	for _, member := range members {
+		touch(touched, dir, member.Class)
+	}
+
+	if len(members) == 0 {
+		t.remove(file, touched)
+
+		return
+	}
+
+	t.files[file] = members
+
+	if t.dirs[dir] == nil {
+		t.dirs[dir] = make(map[string]bool)
+	}
+
+	t.dirs[dir][file] = true
+}
+
+// remove drops file and marks the units it touched.
+func (t *tracker) remove(file string, touched map[unitKey]bool) {
+	dir := path.Dir(file)
+
+	for _, member := range t.files[file] {
+		touch(touched, dir, member.Class)
+	}
+
+	delete(t.files, file)
+	delete(t.dirs[dir], file)
+
+	if len(t.dirs[dir]) == 0 {
+		delete(t.dirs, dir)
+	}
+}
+
+func touch(touched map[unitKey]bool, dir, class string) {
+	touched[unitKey{Kind: UnitPackage, Package: dir}] = true
+
+	if class != "" {
+		touched[unitKey{Kind: UnitClass, Package: dir, Class: class}] = true
+	}
+}
+
+// update recomputes the touched units and returns those whose cohesion
+// changed, sorted by kind and name.
+func (t *tracker) update(touched map[unitKey]bool) []UnitCohesion {
+	var changed []UnitCohesion
+
+	for key := range touched {
+		unit := t.measure(key)
+
+		old, existed := t.units[key]
+		if (existed && old == unit) || (!existed && unit.Functions == 0) {
+			continue
+		}
+
+		t.account(old, -1)
+		t.account(unit, 1)
+
+		if unit.Functions == 0 {
+			delete(t.units, key)
+		} else {
+			t.units[key] = unit
+		}
+
+		changed = append(changed, unit)
+	}
+
+	slices.SortFunc(changed, func(x, y UnitCohesion) int {
+		return cmp.Or(cmp.Compare(x.Kind, y.Kind), cmp.Compare(x.Name, y.Name))
+	})
+
+	return changed
+}
+
+// account adds unit to the totals of its kind with the given sign.
+func (t *tracker) account(unit UnitCohesion, sign int) {
+	totals := t.totals[unit.Kind]
+	if totals == nil || unit.Functions < 2 {
+		return
+	}
+
+	totals.units += sign
+	totals.lcom += float64(sign) * unit.LCOM
+}
+
+// measure computes the cohesion of a unit from the functions of the files of
+// its package.
+func (t *tracker) measure(key unitKey) UnitCohesion {
+	files := make([]string, 0, len(t.dirs[key.Package]))
+	for file := range t.dirs[key.Package] {
+		files = append(files, file)
+	}
+
+	slices.Sort(files)
+
+	var functions []Function
+
+	for _, file := range files {
+		for _, member := range t.files[file] {
+			if key.Kind == UnitPackage || member.Class == key.Class {
+				functions = append(functions, member.Function)
+			}
+		}
+	}
+
+	return UnitCohesion{
+		Kind:      key.Kind,
+		Name:      key.name(),
+		Functions: len(functions),
+		LCOM:      t.analyzer.calculateLCOM(functions),
+	}
+}
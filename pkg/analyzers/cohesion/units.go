@@ -0,0 +1,98 @@
+package cohesion
+
+import (
+	"strings"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// Kinds of the units cohesion is measured over.
+const (
+	UnitClass   = "class"
+	UnitPackage = "package"
+)
+
+// memberFunction is a function with the class it belongs to, if any.
+type memberFunction struct {
+	Class string
+	Function
+}
+
+// memberFunctions returns the top-level functions and methods of root. A
+// method belongs to its enclosing class, or, in Go, to the type of its
+// receiver. Functions nested in other functions are part of the enclosing
+// function.
+func (c *Analyzer) memberFunctions(root *node.Node) []memberFunction {
+	var members []memberFunction
+
+	var visit func(n *node.Node, class string)
+
+	visit = func(n *node.Node, class string) {
+		if n == nil {
+			return
+		}
+
+		if isFunction(n) {
+			fn := c.extractFunction(n)
+			if fn.Name == "" {
+				return
+			}
+
+			if receiver := receiverType(n); receiver != "" {
+				class = receiver
+			}
+
+			members = append(members, memberFunction{Class: class, Function: fn})
+
+			return
+		}
+
+		if n.Type == node.UASTClass || n.HasAnyRole(node.RoleClass) {
+			if name := n.Props["name"]; name != "" {
+				class = name
+			}
+		}
+
+		for _, child := range n.Children {
+			visit(child, class)
+		}
+	}
+
+	visit(root, "")
+
+	return members
+}
+
+func isFunction(n *node.Node) bool {
+	return n.HasAnyType(node.UASTFunction, node.UASTMethod) || n.HasAnyRole(node.RoleFunction)
+}
+
+// receiverType returns the type of the receiver of a Go method: the first
+// type name in the parameter list that precedes the method name.
+func receiverType(n *node.Node) string {
+	if n.Type != node.UASTMethod {
+		return ""
+	}
+
+	for _, child := range n.Children {
+		if child.HasAnyRole(node.RoleName) {
+			return ""
+		}
+
+		if !child.HasAnyRole(node.RoleParameter) {
+			continue
+		}
+
+		var name string
+
+		child.VisitPreOrder(func(desc *node.Node) {
+			if name == "" && desc.HasAnyRole(node.RoleType) && desc.Token != "" {
+				name = strings.TrimLeft(desc.Token, "*")
+			}
+		})
+
+		return name
+	}
+
+	return ""
+}
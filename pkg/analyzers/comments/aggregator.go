@@ -2,6 +2,10 @@
 package comments
 
 import (
+	"maps"
+	"os"
+	"path/filepath"
+
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common"
 )
@@ -17,6 +21,8 @@ type Aggregator struct {
 	*common.Aggregator
 	detailedComments  []map[string]any
 	detailedFunctions []map[string]any
+	documentation     *documentationIndex
+	root              string
 }
 
 // NewAggregator creates a new Aggregator.
@@ -42,7 +48,20 @@ func NewAggregator() *Aggregator {
 		),
 		detailedComments:  make([]map[string]any, 0),
 		detailedFunctions: make([]map[string]any, 0),
+		documentation:     newDocumentationIndex(),
+	}
+}
+
+// SetRoot makes the reported file paths relative to root.
+func (ca *Aggregator) SetRoot(root string) error {
+	info, err := os.Stat(root)
+	if err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
 	}
+
+	ca.root = root
+
+	return nil
 }
 
 // Aggregate overrides the base Aggregate method to collect detailed comments and functions.
@@ -68,6 +87,7 @@ func (ca *Aggregator) collectDetailedData(results map[string]analyze.Report) {
 
 		ca.extractCommentsFromReport(report)
 		ca.extractFunctionsFromReport(report)
+		ca.documentation.add(report, ca.relative)
 	}
 }
 
@@ -85,7 +105,8 @@ func (ca *Aggregator) extractFunctionsFromReport(report analyze.Report) {
 	}
 }
 
-// addDetailedDataToResult adds detailed comments and functions to the result.
+// addDetailedDataToResult adds detailed comments and functions, and the
+// exported API coverage and markers, to the result.
 func (ca *Aggregator) addDetailedDataToResult(result analyze.Report) {
 	if len(ca.detailedComments) > 0 {
 		result["comments"] = ca.detailedComments
@@ -94,6 +115,19 @@ func (ca *Aggregator) addDetailedDataToResult(result analyze.Report) {
 	if len(ca.detailedFunctions) > 0 {
 		result["functions"] = ca.detailedFunctions
 	}
+
+	maps.Copy(result, ca.documentation.result())
+}
+
+// relative returns file relative to the root, with forward slashes.
+func (ca *Aggregator) relative(file string) string {
+	if ca.root != "" {
+		if rel, err := filepath.Rel(ca.root, file); err == nil {
+			file = rel
+		}
+	}
+
+	return filepath.ToSlash(file)
 }
 
 // buildMessage creates a message based on the overall score.
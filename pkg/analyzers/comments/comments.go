@@ -103,15 +103,26 @@ func (c *Analyzer) Analyze(root *node.Node) (analyze.Report, error) {
 	comments := c.findComments(root)
 	functions := c.findFunctions(root)
 
+	return c.analyzeNodes(comments, functions), nil
+}
+
+// analyzeNodes builds the report of the comment and function nodes of a file.
+func (c *Analyzer) analyzeNodes(comments, functions []*node.Node) analyze.Report {
 	if len(comments) == 0 {
-		return c.buildEmptyResult(), nil
+		report := c.buildEmptyResult()
+		c.addDocumentationData(report, comments, functions, nil)
+
+		return report
 	}
 
 	config := c.DefaultConfig()
 	commentDetails := c.analyzeCommentPlacement(comments, functions, config)
 	metrics := c.calculateMetrics(commentDetails, functions)
 
-	return c.buildResult(commentDetails, functions, metrics), nil
+	report := c.buildResult(commentDetails, functions, metrics)
+	c.addDocumentationData(report, comments, functions, metrics.FunctionSummary)
+
+	return report
 }
 
 // FormatReport formats comment analysis results as human-readable text.
@@ -0,0 +1,168 @@
+package comments
+
+import (
+	"cmp"
+	"path"
+	"slices"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// Report keys of the exported API documentation and the comment markers.
+// KeyAPIDocs and KeyMarkers are per-file collections; the others are only set
+// by the Aggregator.
+const (
+	KeyAPIDocs       = "api_docs"
+	KeyMarkers       = "markers"
+	KeyPackageDocs   = "package_docs"
+	KeyExportedAPI   = "exported_api"
+	KeyDocumentedAPI = "documented_api"
+	KeyAPICoverage   = "api_coverage"
+
+	keySourceFile = "_source_file"
+	keyExported   = "exported"
+	keyDocumented = "documented"
+	keyKind       = "kind"
+	keyText       = "text"
+	keyLine       = "line"
+)
+
+// PackageDocumentation is the doc comment coverage of the exported API of
+// one package (directory).
+type PackageDocumentation struct {
+	Package    string  `json:"package"    yaml:"package"`
+	Exported   int     `json:"exported"   yaml:"exported"`
+	Documented int     `json:"documented" yaml:"documented"`
+	Coverage   float64 `json:"coverage"   yaml:"coverage"`
+}
+
+// FileMarker is a Marker with the file it was found in.
+type FileMarker struct {
+	File   string `json:"file" yaml:"file"`
+	Marker `yaml:",inline"`
+}
+
+// apiDocumentation counts the exported functions, methods and types of a
+// file, and how many of them have a doc comment.
+func (c *Analyzer) apiDocumentation(functions []*node.Node, summary map[string]FunctionInfo) (exported, documented int) {
+	for _, function := range functions {
+		name := c.extractTargetName(function)
+		if name == unknownName || !common.IsExported(function, name) {
+			continue
+		}
+
+		exported++
+
+		if summary[name].HasComment {
+			documented++
+		}
+	}
+
+	return exported, documented
+}
+
+// addDocumentationData adds the per-file KeyAPIDocs and KeyMarkers
+// collections to report.
+func (c *Analyzer) addDocumentationData(report analyze.Report, comments, functions []*node.Node, summary map[string]FunctionInfo) {
+	apiDocs := make([]map[string]any, 0, 1)
+
+	if exported, documented := c.apiDocumentation(functions, summary); exported > 0 {
+		apiDocs = append(apiDocs, map[string]any{keyExported: exported, keyDocumented: documented})
+	}
+
+	found := FindMarkers(comments)
+	markers := make([]map[string]any, 0, len(found))
+
+	for _, marker := range found {
+		markers = append(markers, map[string]any{keyKind: marker.Kind, keyText: marker.Text, keyLine: marker.Line})
+	}
+
+	report[KeyAPIDocs] = apiDocs
+	report[KeyMarkers] = markers
+}
+
+// documentationIndex collects the per-file collections of many reports.
+type documentationIndex struct {
+	packages map[string]*PackageDocumentation
+	markers  []FileMarker
+}
+
+func newDocumentationIndex() *documentationIndex {
+	return &documentationIndex{packages: make(map[string]*PackageDocumentation)}
+}
+
+// add records the collections of report, naming files with relative.
+func (d *documentationIndex) add(report analyze.Report, relative func(string) string) {
+	apiDocs, _ := report[KeyAPIDocs].([]map[string]any)
+	for _, entry := range apiDocs {
+		pkg := path.Dir(relative(sourceFile(entry)))
+
+		docs := d.packages[pkg]
+		if docs == nil {
+			docs = &PackageDocumentation{Package: pkg}
+			d.packages[pkg] = docs
+		}
+
+		exported, _ := entry[keyExported].(int)
+		documented, _ := entry[keyDocumented].(int)
+
+		docs.Exported += exported
+		docs.Documented += documented
+	}
+
+	markers, _ := report[KeyMarkers].([]map[string]any)
+	for _, entry := range markers {
+		kind, _ := entry[keyKind].(string)
+		text, _ := entry[keyText].(string)
+		line, _ := entry[keyLine].(int)
+
+		d.markers = append(d.markers, FileMarker{
+			File:   relative(sourceFile(entry)),
+			Marker: Marker{Kind: kind, Text: text, Line: line},
+		})
+	}
+}
+
+// result returns the per-package coverage, least covered first, the markers
+// by file and line, and the exported API totals.
+func (d *documentationIndex) result() analyze.Report {
+	packages := make([]PackageDocumentation, 0, len(d.packages))
+
+	var exported, documented int
+
+	for _, docs := range d.packages {
+		docs.Coverage = safeDiv(float64(docs.Documented), float64(docs.Exported))
+		packages = append(packages, *docs)
+
+		exported += docs.Exported
+		documented += docs.Documented
+	}
+
+	slices.SortFunc(packages, func(x, y PackageDocumentation) int {
+		return cmp.Or(cmp.Compare(x.Coverage, y.Coverage), cmp.Compare(x.Package, y.Package))
+	})
+
+	markers := slices.Clone(d.markers)
+	slices.SortFunc(markers, func(x, y FileMarker) int {
+		return cmp.Or(cmp.Compare(x.File, y.File), cmp.Compare(x.Line, y.Line))
+	})
+
+	return analyze.Report{
+		KeyPackageDocs:   packages,
+		KeyMarkers:       markers,
+		KeyExportedAPI:   exported,
+		KeyDocumentedAPI: documented,
+		KeyAPICoverage:   safeDiv(float64(documented), float64(exported)),
+	}
+}
+
+func sourceFile(entry map[string]any) string {
+	file, _ := entry[keySourceFile].(string)
+	if file == "" {
+		return "."
+	}
+
+	return file
+}
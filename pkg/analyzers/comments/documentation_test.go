@@ -0,0 +1,86 @@
+package comments
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+func namedFunction(name string, start, end uint) *node.Node {
+	return &node.Node{
+		Type:  node.UASTFunction,
+		Roles: []node.Role{node.RoleFunction, node.RoleDeclaration},
+		Props: map[string]string{"name": name},
+		Pos:   &node.Positions{StartLine: start, EndLine: end},
+	}
+}
+
+// documentedFile has a documented exported function, an undocumented one,
+// an unexported one and a TODO.
+func documentedFile() *node.Node {
+	return &node.Node{Type: node.UASTFile, Children: []*node.Node{
+		commentAt(1, "// Run starts the server."),
+		namedFunction("Run", 2, 4),
+		namedFunction("Stop", 6, 8),
+		commentAt(10, "// TODO: inline this"),
+		namedFunction("helper", 11, 13),
+	}}
+}
+
+func TestAnalyzer_Analyze_Documentation(t *testing.T) {
+	t.Parallel()
+
+	report, err := NewAnalyzer().Analyze(documentedFile())
+	require.NoError(t, err)
+
+	assert.Equal(t, []map[string]any{{keyExported: 2, keyDocumented: 1}}, report[KeyAPIDocs])
+	assert.Equal(t, []map[string]any{{keyKind: MarkerTODO, keyText: "inline this", keyLine: 10}}, report[KeyMarkers])
+
+	metrics, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+	assert.Equal(t, 2, metrics.Aggregate.ExportedAPI)
+	assert.InDelta(t, 0.5, metrics.Aggregate.APICoverage, 1e-9)
+	assert.Equal(t, 1, metrics.Aggregate.Markers)
+}
+
+func TestAggregator_Documentation(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	analyzer := NewAnalyzer()
+
+	agg, ok := analyzer.CreateAggregator().(*Aggregator)
+	require.True(t, ok)
+	require.NoError(t, agg.SetRoot(root))
+
+	undocumented := &node.Node{Type: node.UASTFile, Children: []*node.Node{namedFunction("Serve", 1, 3)}}
+
+	for file, tree := range map[string]*node.Node{
+		"pkg/server/server.go": documentedFile(),
+		"pkg/client/client.go": undocumented,
+	} {
+		report, err := analyzer.Analyze(tree)
+		require.NoError(t, err)
+
+		reports := map[string]analyze.Report{"comments": report}
+		analyze.StampSourceFile(reports, filepath.Join(root, file))
+		agg.Aggregate(reports)
+	}
+
+	result := agg.GetResult()
+
+	assert.Equal(t, []PackageDocumentation{
+		{Package: "pkg/client", Exported: 1},
+		{Package: "pkg/server", Exported: 2, Documented: 1, Coverage: 0.5},
+	}, result[KeyPackageDocs])
+	assert.Equal(t, []FileMarker{
+		{File: "pkg/server/server.go", Marker: Marker{Kind: MarkerTODO, Text: "inline this", Line: 10}},
+	}, result[KeyMarkers])
+	assert.Equal(t, 3, result[KeyExportedAPI])
+	assert.InDelta(t, 1.0/3, result[KeyAPICoverage], 1e-9)
+}
@@ -0,0 +1,361 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// Configuration option keys for the comments history analyzer.
+const (
+	ConfigCommentsMaxFileSize = "Comments.MaxFileSize"
+
+	defaultMaxFileSize = 1 << 20
+	// commitSize and eventSize estimate the bytes of one CommitComments and
+	// one MarkerEvent held by the aggregator.
+	commitSize = 96
+	eventSize  = 200
+)
+
+// Report keys of the history analyzer.
+const (
+	KeyCommits     = "commits"
+	KeyAuthorIndex = "author_index"
+	KeyTickSize    = "tick_size"
+)
+
+// ErrParserNotInitialized indicates Consume ran before Initialize.
+var ErrParserNotInitialized = errors.New("parser not initialized")
+
+// CommitComments is the exported API documentation and the open markers
+// after one commit, with the markers it changed.
+type CommitComments struct {
+	Hash       gitlib.Hash
+	Tick       int
+	AuthorID   int
+	Time       time.Time
+	Exported   int
+	Documented int
+	Open       int
+	Events     []MarkerEvent
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data,
+// with commits in the order they were analyzed.
+type TickData struct {
+	Commits []CommitComments
+}
+
+// HistoryAnalyzer replays the commit history and records the exported API
+// documentation coverage after every commit, and which commit introduced,
+// and which resolved, every TODO, FIXME, XXX and HACK marker.
+type HistoryAnalyzer struct {
+	*analyze.BaseHistoryAnalyzer[*HistoryMetrics]
+
+	TreeDiff  *plumbing.TreeDiffAnalyzer
+	BlobCache *plumbing.BlobCacheAnalyzer
+	Identity  *plumbing.IdentityDetector
+	Ticks     *plumbing.TicksSinceStart
+
+	MaxFileSize int
+
+	parser             *uast.Parser
+	analyzer           *Analyzer
+	state              *tracker
+	reversedPeopleDict []string
+	tickSize           time.Duration
+}
+
+// NewHistoryAnalyzer creates a new HistoryAnalyzer.
+func NewHistoryAnalyzer() *HistoryAnalyzer {
+	a := &HistoryAnalyzer{MaxFileSize: defaultMaxFileSize}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*HistoryMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/comments",
+			Mode: analyze.ModeHistory,
+			Description: "Tracks exported API documentation coverage over time and how long " +
+				"TODO, FIXME, XXX and HACK markers stay open.",
+		},
+		Sequential: true,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, Memory: analyze.MemoryMedium},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigCommentsMaxFileSize,
+				Description: "Specifies the file size threshold. Files that exceed it are not analyzed.",
+				Flag:        "comments-max-file-size",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultMaxFileSize,
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*HistoryMetrics, error) {
+			return ComputeHistoryMetrics(report), nil
+		},
+		AggregatorFn: newHistoryAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (h *HistoryAnalyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigCommentsMaxFileSize].(int); exists && val > 0 {
+		h.MaxFileSize = val
+	}
+
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		h.reversedPeopleDict = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		h.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize resets the tracked tree and loads the UAST parser.
+func (h *HistoryAnalyzer) Initialize(_ *gitlib.Repository) error {
+	if h.MaxFileSize <= 0 {
+		h.MaxFileSize = defaultMaxFileSize
+	}
+
+	h.analyzer = NewAnalyzer()
+	h.state = newTracker()
+
+	var err error
+
+	h.parser, err = uast.NewParser()
+	if err != nil {
+		return fmt.Errorf("failed to initialize UAST parser: %w", err)
+	}
+
+	return nil
+}
+
+// Consume updates the tracked files with the files the commit changed and
+// emits the counts and marker events when anything changed.
+func (h *HistoryAnalyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if h.parser == nil || h.state == nil {
+		return analyze.TC{}, ErrParserNotInitialized
+	}
+
+	before := h.state.totals
+
+	var events []MarkerEvent
+
+	for _, change := range h.TreeDiff.Changes {
+		switch change.Action {
+		case gitlib.Delete:
+			events = append(events, h.state.remove(change.From.Name)...)
+		case gitlib.Modify, gitlib.Insert:
+			if change.Action == gitlib.Modify && change.From.Name != change.To.Name {
+				events = append(events, h.state.rename(change.From.Name, change.To.Name)...)
+			}
+
+			events = append(events, h.readFile(ctx, change.To)...)
+		}
+	}
+
+	if len(events) == 0 && h.state.totals == before {
+		return analyze.TC{}, nil
+	}
+
+	return analyze.TC{
+		Data: &CommitComments{
+			Exported:   h.state.exported,
+			Documented: h.state.documented,
+			Open:       h.state.open,
+			Events:     events,
+		},
+		CommitHash: ac.Commit.Hash(),
+	}, nil
+}
+
+// readFile updates the tracked state of entry. Files that are too large or
+// that the parser does not support are dropped.
+func (h *HistoryAnalyzer) readFile(ctx context.Context, entry gitlib.ChangeEntry) []MarkerEvent {
+	blob := h.BlobCache.Cache[entry.Hash]
+	if blob == nil || blob.Size() > int64(h.MaxFileSize) || !h.parser.IsSupported(entry.Name) {
+		return h.state.remove(entry.Name)
+	}
+
+	root, err := h.parser.Parse(ctx, entry.Name, blob.Data)
+	if err != nil {
+		return h.state.remove(entry.Name)
+	}
+
+	return h.state.set(entry.Name, h.analyzer.collectFileComments(root))
+}
+
+// collectFileComments returns the markers and the exported API counts of a file.
+func (c *Analyzer) collectFileComments(root *node.Node) fileComments {
+	comments := c.findComments(root)
+	functions := c.findFunctions(root)
+
+	var summary map[string]FunctionInfo
+
+	if len(comments) > 0 {
+		details := c.analyzeCommentPlacement(comments, functions, c.DefaultConfig())
+		summary = c.calculateMetrics(details, functions).FunctionSummary
+	}
+
+	exported, documented := c.apiDocumentation(functions, summary)
+
+	return fileComments{markers: FindMarkers(comments), exported: exported, documented: documented}
+}
+
+// Fork creates copies of the analyzer that share the tracked tree.
+func (h *HistoryAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *h
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.BlobCache = &plumbing.BlobCacheAnalyzer{}
+		clone.Identity = &plumbing.IdentityDetector{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (h *HistoryAnalyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (h *HistoryAnalyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes:   h.TreeDiff.Changes,
+		BlobCache: h.BlobCache.Cache,
+		Tick:      h.Ticks.Tick,
+		AuthorID:  h.Identity.AuthorID,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (h *HistoryAnalyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	h.TreeDiff.Changes = snapshot.Changes
+	h.BlobCache.Cache = snapshot.BlobCache
+	h.Ticks.Tick = snapshot.Tick
+	h.Identity.AuthorID = snapshot.AuthorID
+}
+
+// ReleaseSnapshot is a no-op for comments.
+func (h *HistoryAnalyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (h *HistoryAnalyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return h.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (h *HistoryAnalyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return h.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the commits that changed anything in history order.
+func (h *HistoryAnalyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	var commits []CommitComments
+
+	for _, tick := range ticks {
+		if td, ok := tick.Data.(*TickData); ok && td != nil {
+			commits = append(commits, td.Commits...)
+		}
+	}
+
+	return analyze.Report{
+		KeyCommits:     commits,
+		KeyAuthorIndex: h.reversedPeopleDict,
+		KeyTickSize:    h.tickSize,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	cc, ok := tc.Data.(*CommitComments)
+	if !ok || cc == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{}
+		byTick[tc.Tick] = state
+	}
+
+	commit := *cc
+	commit.Hash = tc.CommitHash
+	commit.Tick = tc.Tick
+	commit.AuthorID = tc.AuthorID
+	commit.Time = tc.Timestamp
+
+	state.Commits = append(state.Commits, commit)
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming != nil {
+		existing.Commits = append(existing.Commits, incoming.Commits...)
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	var events int
+
+	for _, cc := range state.Commits {
+		events += len(cc.Events)
+	}
+
+	return int64(len(state.Commits))*commitSize + int64(events)*eventSize
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || len(state.Commits) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newHistoryAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
@@ -0,0 +1,201 @@
+package comments
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+// hoursPerDay converts marker ages to days.
+const hoursPerDay = 24
+
+// CommitRef identifies the commit that changed a marker.
+type CommitRef struct {
+	Hash   string    `json:"hash"   yaml:"hash"`
+	Author string    `json:"author" yaml:"author"`
+	Tick   int       `json:"tick"   yaml:"tick"`
+	Time   time.Time `json:"time"   yaml:"time"`
+}
+
+// MarkerHistory is one marker with the commits that introduced and, unless
+// it is still open, resolved it. Ages run to the resolving commit, or to the
+// last analyzed commit for open markers.
+type MarkerHistory struct {
+	File   string `json:"file" yaml:"file"`
+	Marker `yaml:",inline"`
+
+	Introduced CommitRef  `json:"introduced"         yaml:"introduced"`
+	Resolved   *CommitRef `json:"resolved,omitempty" yaml:"resolved,omitempty"`
+	AgeTicks   int        `json:"age_ticks"          yaml:"age_ticks"`
+	AgeDays    float64    `json:"age_days"           yaml:"age_days"`
+}
+
+// TickDocumentation is the documentation state after the last commit of a
+// tick that changed it.
+type TickDocumentation struct {
+	Tick        int     `json:"tick"         yaml:"tick"`
+	Exported    int     `json:"exported"     yaml:"exported"`
+	Documented  int     `json:"documented"   yaml:"documented"`
+	APICoverage float64 `json:"api_coverage" yaml:"api_coverage"`
+	OpenMarkers int     `json:"open_markers" yaml:"open_markers"`
+}
+
+// HistoryMetrics is the history of the exported API documentation and the
+// comment markers.
+type HistoryMetrics struct {
+	Trend    []TickDocumentation `json:"trend"    yaml:"trend"`
+	Open     int                 `json:"open"     yaml:"open"`
+	Resolved int                 `json:"resolved" yaml:"resolved"`
+	// OpenByKind counts the open markers of each kind.
+	OpenByKind          map[string]int `json:"open_by_kind"           yaml:"open_by_kind"`
+	MeanOpenAgeDays     float64        `json:"mean_open_age_days"     yaml:"mean_open_age_days"`
+	MeanResolvedAgeDays float64        `json:"mean_resolved_age_days" yaml:"mean_resolved_age_days"`
+	// Markers lists open markers, oldest first, then resolved ones.
+	Markers []MarkerHistory `json:"markers" yaml:"markers"`
+}
+
+// ComputeHistoryMetrics replays the commits of a history report.
+func ComputeHistoryMetrics(report analyze.Report) *HistoryMetrics {
+	commits, _ := report[KeyCommits].([]CommitComments)
+	names, _ := report[KeyAuthorIndex].([]string)
+
+	m := &HistoryMetrics{OpenByKind: make(map[string]int)}
+	open := make(map[MarkerEvent][]int) // Marker key -> indices in m.Markers.
+
+	for _, cc := range commits {
+		ref := CommitRef{Hash: cc.Hash.String(), Author: authorName(names, cc.AuthorID), Tick: cc.Tick, Time: cc.Time}
+
+		for _, event := range cc.Events {
+			replayEvent(m, open, event, ref)
+		}
+
+		m.Trend = appendTrend(m.Trend, cc)
+	}
+
+	if len(commits) > 0 {
+		computeAges(m, commits[len(commits)-1])
+	}
+
+	sortMarkers(m.Markers)
+
+	return m
+}
+
+// replayEvent applies one marker event to the markers of m.
+func replayEvent(m *HistoryMetrics, open map[MarkerEvent][]int, event MarkerEvent, ref CommitRef) {
+	key := openKey(event.File, event.Marker)
+
+	switch event.Action {
+	case ActionIntroduced:
+		open[key] = append(open[key], len(m.Markers))
+		m.Markers = append(m.Markers, MarkerHistory{File: event.File, Marker: event.Marker, Introduced: ref})
+	case ActionResolved:
+		if i, ok := popOpen(open, key); ok {
+			m.Markers[i].Resolved = &ref
+		}
+	case ActionMoved:
+		if i, ok := popOpen(open, openKey(event.From, event.Marker)); ok {
+			m.Markers[i].File = event.File
+			open[key] = append(open[key], i)
+		}
+	}
+}
+
+// appendTrend records the state after cc, replacing the state of an earlier
+// commit of the same tick.
+func appendTrend(trend []TickDocumentation, cc CommitComments) []TickDocumentation {
+	point := TickDocumentation{
+		Tick:        cc.Tick,
+		Exported:    cc.Exported,
+		Documented:  cc.Documented,
+		APICoverage: safeDiv(float64(cc.Documented), float64(cc.Exported)),
+		OpenMarkers: cc.Open,
+	}
+
+	if n := len(trend); n > 0 && trend[n-1].Tick == cc.Tick {
+		trend[n-1] = point
+
+		return trend
+	}
+
+	return append(trend, point)
+}
+
+// computeAges sets the age of every marker and the open and resolved
+// summaries, measuring open markers up to the last commit.
+func computeAges(m *HistoryMetrics, last CommitComments) {
+	var openDays, resolvedDays float64
+
+	for i := range m.Markers {
+		marker := &m.Markers[i]
+
+		endTick, endTime := last.Tick, last.Time
+		if marker.Resolved != nil {
+			endTick, endTime = marker.Resolved.Tick, marker.Resolved.Time
+		}
+
+		marker.AgeTicks = endTick - marker.Introduced.Tick
+		if !endTime.IsZero() && !marker.Introduced.Time.IsZero() {
+			marker.AgeDays = endTime.Sub(marker.Introduced.Time).Hours() / hoursPerDay
+		}
+
+		if marker.Resolved != nil {
+			m.Resolved++
+			resolvedDays += marker.AgeDays
+
+			continue
+		}
+
+		m.Open++
+		m.OpenByKind[marker.Kind]++
+		openDays += marker.AgeDays
+	}
+
+	m.MeanOpenAgeDays = safeDiv(openDays, float64(m.Open))
+	m.MeanResolvedAgeDays = safeDiv(resolvedDays, float64(m.Resolved))
+}
+
+func sortMarkers(markers []MarkerHistory) {
+	slices.SortStableFunc(markers, func(x, y MarkerHistory) int {
+		xOpen, yOpen := x.Resolved == nil, y.Resolved == nil
+		if xOpen != yOpen {
+			if xOpen {
+				return -1
+			}
+
+			return 1
+		}
+
+		return cmp.Or(cmp.Compare(y.AgeDays, x.AgeDays), cmp.Compare(y.AgeTicks, x.AgeTicks))
+	})
+}
+
+func openKey(file string, marker Marker) MarkerEvent {
+	return MarkerEvent{Marker: markerKey(marker), File: file}
+}
+
+func popOpen(open map[MarkerEvent][]int, key MarkerEvent) (int, bool) {
+	indices := open[key]
+	if len(indices) == 0 {
+		return 0, false
+	}
+
+	if len(indices) == 1 {
+		delete(open, key)
+	} else {
+		open[key] = indices[1:]
+	}
+
+	return indices[0], true
+}
+
+func authorName(names []string, id int) string {
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return identity.AuthorMissingName
+}
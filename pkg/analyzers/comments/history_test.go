@@ -0,0 +1,128 @@
+package comments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func TestTracker_IntroduceAndResolve(t *testing.T) {
+	t.Parallel()
+
+	state := newTracker()
+	todo := Marker{Kind: MarkerTODO, Text: "drop v1", Line: 3}
+	fixme := Marker{Kind: MarkerFIXME, Text: "handle CRLF", Line: 9}
+
+	events := state.set("a.go", fileComments{markers: []Marker{todo}, exported: 2, documented: 1})
+	assert.Equal(t, []MarkerEvent{{Marker: todo, Action: ActionIntroduced, File: "a.go"}}, events)
+	assert.Equal(t, totals{exported: 2, documented: 1, open: 1}, state.totals)
+
+	// Moving a marker within the file is not an event.
+	moved := todo
+	moved.Line = 5
+
+	events = state.set("a.go", fileComments{markers: []Marker{fixme, moved}, exported: 2, documented: 2})
+	assert.Equal(t, []MarkerEvent{{Marker: fixme, Action: ActionIntroduced, File: "a.go"}}, events)
+
+	events = state.remove("a.go")
+	assert.Equal(t, []MarkerEvent{
+		{Marker: fixme, Action: ActionResolved, File: "a.go"},
+		{Marker: moved, Action: ActionResolved, File: "a.go"},
+	}, events)
+	assert.Equal(t, totals{}, state.totals)
+	assert.Empty(t, state.files)
+}
+
+func TestTracker_Rename(t *testing.T) {
+	t.Parallel()
+
+	state := newTracker()
+	todo := Marker{Kind: MarkerTODO, Text: "drop v1", Line: 3}
+
+	state.set("old.go", fileComments{markers: []Marker{todo}, exported: 1})
+
+	events := state.rename("old.go", "new.go")
+	assert.Equal(t, []MarkerEvent{{Marker: todo, Action: ActionMoved, File: "new.go", From: "old.go"}}, events)
+	assert.Empty(t, state.set("new.go", fileComments{markers: []Marker{todo}, exported: 1}))
+	assert.Equal(t, totals{exported: 1, open: 1}, state.totals)
+}
+
+func TestComputeHistoryMetrics(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	todo := Marker{Kind: MarkerTODO, Text: "drop v1", Line: 3}
+	hack := Marker{Kind: MarkerHACK, Text: "retry twice", Line: 7}
+
+	report := analyze.Report{
+		KeyAuthorIndex: []string{"alice", "bob"},
+		KeyCommits: []CommitComments{
+			{
+				Hash: gitlib.NewHash("1111111111111111111111111111111111111111"), Tick: 0, AuthorID: 0, Time: start,
+				Exported: 4, Documented: 1, Open: 2,
+				Events: []MarkerEvent{
+					{Marker: todo, Action: ActionIntroduced, File: "a.go"},
+					{Marker: hack, Action: ActionIntroduced, File: "a.go"},
+				},
+			},
+			{
+				Hash: gitlib.NewHash("2222222222222222222222222222222222222222"), Tick: 0, AuthorID: 1,
+				Time: start.Add(24 * time.Hour), Exported: 4, Documented: 2, Open: 2,
+				Events: []MarkerEvent{{Marker: todo, Action: ActionMoved, File: "b.go", From: "a.go"}},
+			},
+			{
+				Hash: gitlib.NewHash("3333333333333333333333333333333333333333"), Tick: 2, AuthorID: 1,
+				Time: start.Add(10 * 24 * time.Hour), Exported: 4, Documented: 3, Open: 1,
+				Events: []MarkerEvent{{Marker: hack, Action: ActionResolved, File: "a.go"}},
+			},
+		},
+	}
+
+	m := ComputeHistoryMetrics(report)
+
+	assert.Equal(t, []TickDocumentation{
+		{Tick: 0, Exported: 4, Documented: 2, APICoverage: 0.5, OpenMarkers: 2},
+		{Tick: 2, Exported: 4, Documented: 3, APICoverage: 0.75, OpenMarkers: 1},
+	}, m.Trend)
+	assert.Equal(t, 1, m.Open)
+	assert.Equal(t, 1, m.Resolved)
+	assert.Equal(t, map[string]int{MarkerTODO: 1}, m.OpenByKind)
+
+	require.Len(t, m.Markers, 2)
+	assert.Equal(t, "b.go", m.Markers[0].File)
+	assert.Nil(t, m.Markers[0].Resolved)
+	assert.Equal(t, "alice", m.Markers[0].Introduced.Author)
+	assert.Equal(t, 2, m.Markers[0].AgeTicks)
+	assert.InDelta(t, 10.0, m.Markers[0].AgeDays, 1e-9)
+
+	assert.Equal(t, MarkerHACK, m.Markers[1].Kind)
+	require.NotNil(t, m.Markers[1].Resolved)
+	assert.Equal(t, "bob", m.Markers[1].Resolved.Author)
+	assert.InDelta(t, 10.0, m.MeanOpenAgeDays, 1e-9)
+	assert.InDelta(t, 10.0, m.MeanResolvedAgeDays, 1e-9)
+}
+
+func TestExtractTC_StampsCommit(t *testing.T) {
+	t.Parallel()
+
+	byTick := make(map[int]*TickData)
+	when := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, extractTC(analyze.TC{
+		Data:       &CommitComments{Exported: 1, Open: 1},
+		CommitHash: gitlib.NewHash("1111111111111111111111111111111111111111"),
+		Tick:       3,
+		AuthorID:   2,
+		Timestamp:  when,
+	}, byTick))
+
+	require.Len(t, byTick[3].Commits, 1)
+	assert.Equal(t, 2, byTick[3].Commits[0].AuthorID)
+	assert.Equal(t, when, byTick[3].Commits[0].Time)
+	assert.Equal(t, 3, byTick[3].Commits[0].Tick)
+}
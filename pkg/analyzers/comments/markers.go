@@ -0,0 +1,66 @@
+package comments
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/safeconv"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// Marker kinds.
+const (
+	MarkerTODO  = "TODO"
+	MarkerFIXME = "FIXME"
+	MarkerXXX   = "XXX"
+	MarkerHACK  = "HACK"
+
+	// maxMarkerTextLen caps the text of a marker kept in reports, in runes.
+	maxMarkerTextLen = 120
+)
+
+// markerPattern matches a marker keyword and captures the rest of its line,
+// e.g. "TODO(alice): drop v1".
+var markerPattern = regexp.MustCompile(`\b(TODO|FIXME|XXX|HACK)\b(?:\([^)]*\))?[:\s-]*([^\n]*)`)
+
+// Marker is a TODO, FIXME, XXX or HACK note in a comment.
+type Marker struct {
+	Kind string `json:"kind" yaml:"kind"`
+	Text string `json:"text" yaml:"text"`
+	Line int    `json:"line" yaml:"line"`
+}
+
+// FindMarkers returns the markers of the comment nodes, in source order.
+func FindMarkers(comments []*node.Node) []Marker {
+	var markers []Marker
+
+	for _, comment := range comments {
+		line := 0
+		if comment.Pos != nil {
+			line = safeconv.MustUintToInt(comment.Pos.StartLine)
+		}
+
+		for i, text := range strings.Split(comment.Token, "\n") {
+			for _, match := range markerPattern.FindAllStringSubmatch(text, -1) {
+				markers = append(markers, Marker{
+					Kind: match[1],
+					Text: truncateMarkerText(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(match[2]), "*/"))),
+					Line: line + i,
+				})
+			}
+		}
+	}
+
+	return markers
+}
+
+// truncateMarkerText caps text at maxMarkerTextLen runes, cutting on a rune
+// boundary so multi-byte text stays valid UTF-8.
+func truncateMarkerText(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxMarkerTextLen {
+		return text
+	}
+
+	return string(runes[:maxMarkerTextLen-3]) + "..."
+}
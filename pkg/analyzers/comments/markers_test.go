@@ -0,0 +1,55 @@
+package comments
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+func commentAt(line uint, text string) *node.Node {
+	return &node.Node{Type: node.UASTComment, Token: text, Pos: &node.Positions{StartLine: line, EndLine: line}}
+}
+
+func TestFindMarkers(t *testing.T) {
+	t.Parallel()
+
+	markers := FindMarkers([]*node.Node{
+		commentAt(3, "// TODO(alice): drop the v1 API"),
+		commentAt(7, "/* Parses input.\n * FIXME handle CRLF */"),
+		commentAt(9, "// XXX - HACK: works around a race"),
+		commentAt(11, "// Todos and TODOLIST are not markers."),
+	})
+
+	assert.Equal(t, []Marker{
+		{Kind: MarkerTODO, Text: "drop the v1 API", Line: 3},
+		{Kind: MarkerFIXME, Text: "handle CRLF", Line: 8},
+		{Kind: MarkerXXX, Text: "HACK: works around a race", Line: 9},
+	}, markers)
+}
+
+func TestFindMarkers_TruncatesText(t *testing.T) {
+	t.Parallel()
+
+	markers := FindMarkers([]*node.Node{commentAt(1, "// TODO "+strings.Repeat("x", 200))})
+
+	if assert.Len(t, markers, 1) {
+		assert.Len(t, markers[0].Text, maxMarkerTextLen)
+		assert.True(t, strings.HasSuffix(markers[0].Text, "..."))
+	}
+}
+
+func TestFindMarkers_TruncatesOnRuneBoundary(t *testing.T) {
+	t.Parallel()
+
+	markers := FindMarkers([]*node.Node{commentAt(1, "// TODO "+strings.Repeat("исправить ", 30))})
+
+	if assert.Len(t, markers, 1) {
+		assert.True(t, utf8.ValidString(markers[0].Text))
+		assert.Equal(t, maxMarkerTextLen, utf8.RuneCountInString(markers[0].Text))
+		assert.True(t, strings.HasSuffix(markers[0].Text, "..."))
+	}
+}
@@ -20,6 +20,11 @@ type ReportData struct {
 	Comments              []CommentData
 	Functions             []FunctionCommentData
 	Message               string
+	PackageDocumentation  []PackageDocumentation
+	Markers               []FileMarker
+	ExportedAPI           int
+	DocumentedAPI         int
+	APICoverage           float64
 }
 
 // CommentData holds data for a single comment.
@@ -49,10 +54,27 @@ func ParseReportData(report analyze.Report) (*ReportData, error) {
 	parseReportScalars(data, report)
 	data.Comments = parseReportComments(report)
 	data.Functions = parseReportFunctions(report)
+	parseReportDocumentation(data, report)
 
 	return data, nil
 }
 
+// parseReportDocumentation reads the exported API coverage and markers of an
+// aggregated report, or builds them from the collections of a per-file one.
+func parseReportDocumentation(data *ReportData, report analyze.Report) {
+	if _, aggregated := report[KeyPackageDocs]; !aggregated {
+		index := newDocumentationIndex()
+		index.add(report, func(file string) string { return file })
+		report = index.result()
+	}
+
+	data.PackageDocumentation, _ = report[KeyPackageDocs].([]PackageDocumentation)
+	data.Markers, _ = report[KeyMarkers].([]FileMarker)
+	data.ExportedAPI, _ = report[KeyExportedAPI].(int)
+	data.DocumentedAPI, _ = report[KeyDocumentedAPI].(int)
+	data.APICoverage, _ = report[KeyAPICoverage].(float64)
+}
+
 func parseReportScalars(data *ReportData, report analyze.Report) {
 	if v, ok := report["total_comments"].(int); ok {
 		data.TotalComments = v
@@ -224,6 +246,10 @@ type AggregateData struct {
 	DocumentationCoverage float64 `json:"documentation_coverage" yaml:"documentation_coverage"`
 	HealthScore           float64 `json:"health_score"           yaml:"health_score"`
 	Message               string  `json:"message"                yaml:"message"`
+	ExportedAPI           int     `json:"exported_api"           yaml:"exported_api"`
+	DocumentedAPI         int     `json:"documented_api"         yaml:"documented_api"`
+	APICoverage           float64 `json:"api_coverage"           yaml:"api_coverage"`
+	Markers               int     `json:"markers"                yaml:"markers"`
 }
 
 // CommentQualityMetric computes per-comment quality data.
@@ -423,6 +449,10 @@ func (m *AggregateMetric) Compute(input *ReportData) AggregateData {
 		GoodCommentsRatio:     input.GoodCommentsRatio,
 		DocumentationCoverage: input.DocumentationCoverage,
 		Message:               input.Message,
+		ExportedAPI:           input.ExportedAPI,
+		DocumentedAPI:         input.DocumentedAPI,
+		APICoverage:           input.APICoverage,
+		Markers:               len(input.Markers),
 	}
 
 	// Calculate health score based on overall score (0-100).
@@ -436,6 +466,8 @@ type ComputedMetrics struct {
 	CommentQuality        []CommentQualityData        `json:"comment_quality"        yaml:"comment_quality"`
 	FunctionDocumentation []FunctionDocumentationData `json:"function_documentation" yaml:"function_documentation"`
 	UndocumentedFunctions []UndocumentedFunctionData  `json:"undocumented_functions" yaml:"undocumented_functions"`
+	PackageDocumentation  []PackageDocumentation      `json:"package_documentation"  yaml:"package_documentation"`
+	Markers               []FileMarker                `json:"markers"                yaml:"markers"`
 	Aggregate             AggregateData               `json:"aggregate"              yaml:"aggregate"`
 }
 
@@ -480,6 +512,8 @@ func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
 		CommentQuality:        commentQuality,
 		FunctionDocumentation: funcDoc,
 		UndocumentedFunctions: undocumented,
+		PackageDocumentation:  input.PackageDocumentation,
+		Markers:               input.Markers,
 		Aggregate:             aggregate,
 	}, nil
 }
@@ -2,14 +2,17 @@ package comments
 
 import (
 	"errors"
+	"html"
 	"io"
 	"sort"
+	"strconv"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
 )
 
 const (
@@ -17,6 +20,9 @@ const (
 	xAxisRotate       = 45
 	emptyChartHeight  = "400px"
 	pieRadius         = "60%"
+	percentScale      = 100
+	// shortHashLen is the length of commit hashes in tables.
+	shortHashLen = 8
 )
 
 // ErrInvalidFunctionsData indicates the report doesn't contain expected functions data.
@@ -27,6 +33,9 @@ func RegisterPlotSections() {
 	analyze.RegisterPlotSections("static/comments", func(report analyze.Report) ([]plotpage.Section, error) {
 		return (&Analyzer{}).generateSections(report)
 	})
+	analyze.RegisterPlotSections("history/comments", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&HistoryAnalyzer{}).GenerateSections(report)
+	})
 }
 
 // FormatReportPlot generates an HTML plot visualization for comments analysis.
@@ -55,7 +64,7 @@ func (c *Analyzer) generateSections(report analyze.Report) ([]plotpage.Section,
 	pieChart := c.generateDocumentationPieChart(report)
 	gaugeChart := c.generateOverallScoreGauge(report)
 
-	return []plotpage.Section{
+	sections := []plotpage.Section{
 		{
 			Title:    "Overall Documentation Score",
 			Subtitle: "Combined score based on comment quality and placement.",
@@ -97,9 +106,135 @@ func (c *Analyzer) generateSections(report analyze.Report) ([]plotpage.Section,
 				},
 			},
 		},
+	}
+
+	return append(sections, generateDocumentationSections(report)...), nil
+}
+
+// generateDocumentationSections returns the exported API coverage and marker
+// tables, for reports that have them.
+func generateDocumentationSections(report analyze.Report) []plotpage.Section {
+	metrics, err := ComputeAllMetrics(report)
+	if err != nil {
+		return nil
+	}
+
+	var sections []plotpage.Section
+
+	if len(metrics.PackageDocumentation) > 0 {
+		table := plotpage.NewTable([]string{"Package", "Exported", "Documented", "Coverage"}).
+			WithSearch("Filter packages...")
+
+		for _, pkg := range metrics.PackageDocumentation {
+			table.AddRow(
+				html.EscapeString(pkg.Package),
+				strconv.Itoa(pkg.Exported),
+				strconv.Itoa(pkg.Documented),
+				reportutil.FormatPercent(pkg.Coverage),
+			)
+		}
+
+		sections = append(sections, plotpage.Section{
+			Title: "Exported API Documentation",
+			Subtitle: "Doc comment coverage of exported functions, methods and types per package: " +
+				reportutil.FormatPercent(metrics.Aggregate.APICoverage) + " overall.",
+			Chart: table,
+		})
+	}
+
+	if len(metrics.Markers) > 0 {
+		table := plotpage.NewTable([]string{"Marker", "File", "Line", "Text"}).WithSearch("Filter markers...")
+
+		for _, marker := range metrics.Markers {
+			table.AddRow(
+				marker.Kind,
+				html.EscapeString(marker.File),
+				strconv.Itoa(marker.Line),
+				html.EscapeString(marker.Text),
+			)
+		}
+
+		sections = append(sections, plotpage.Section{
+			Title:    "TODO / FIXME Markers",
+			Subtitle: strconv.Itoa(len(metrics.Markers)) + " TODO, FIXME, XXX and HACK markers in comments.",
+			Chart:    table,
+		})
+	}
+
+	return sections
+}
+
+// GenerateSections returns the sections for combined reports.
+func (h *HistoryAnalyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeHistoryMetrics(report)
+
+	markers := plotpage.NewTable([]string{"Marker", "File", "Text", "Introduced", "By", "Age (days)", "Resolved"}).
+		WithSearch("Filter markers...")
+
+	for _, marker := range m.Markers {
+		resolved := ""
+		if marker.Resolved != nil {
+			resolved = shortHash(marker.Resolved.Hash)
+		}
+
+		markers.AddRow(
+			marker.Kind,
+			html.EscapeString(marker.File),
+			html.EscapeString(marker.Text),
+			shortHash(marker.Introduced.Hash),
+			html.EscapeString(marker.Introduced.Author),
+			strconv.FormatFloat(marker.AgeDays, 'f', 1, 64),
+			resolved,
+		)
+	}
+
+	return []plotpage.Section{
+		{
+			Title:    "Documentation Trend",
+			Subtitle: "Exported API doc comment coverage and open markers, per tick.",
+			Chart:    plotpage.WrapChart(buildDocumentationTrendChart(m)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"A falling coverage line = new exported API lands without doc comments",
+					"A rising markers line = TODOs are added faster than they are resolved",
+				},
+			},
+		},
+		{
+			Title: "Marker Aging",
+			Subtitle: strconv.Itoa(m.Open) + " open markers, " + strconv.FormatFloat(m.MeanOpenAgeDays, 'f', 1, 64) +
+				" days old on average; " + strconv.Itoa(m.Resolved) + " resolved.",
+			Chart: markers,
+		},
 	}, nil
 }
 
+func buildDocumentationTrendChart(m *HistoryMetrics) *charts.Line {
+	labels := make([]string, len(m.Trend))
+	coverage := make([]plotpage.SeriesData, len(m.Trend))
+	open := make([]plotpage.SeriesData, len(m.Trend))
+
+	for i, point := range m.Trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		coverage[i] = percentScale * point.APICoverage
+		open[i] = point.OpenMarkers
+	}
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: "API coverage (%)", Data: coverage},
+		{Name: "Open markers", Data: open},
+	}, "Value")
+}
+
+func shortHash(hash string) string {
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+
+	return hash
+}
+
 // reportValue looks up a key in the report, falling back to the "aggregate" sub-map
 // that appears after binary encode -> JSON decode round-trip.
 func reportValue(report analyze.Report, key string) (any, bool) {
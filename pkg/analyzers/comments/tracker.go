@@ -0,0 +1,113 @@
+package comments
+
+// Marker event actions.
+const (
+	ActionIntroduced = "introduced"
+	ActionResolved   = "resolved"
+	ActionMoved      = "moved"
+)
+
+// MarkerEvent is a marker a commit introduced or resolved, or carried to a
+// renamed file. From is the previous path of a moved marker.
+type MarkerEvent struct {
+	Marker
+
+	Action string
+	File   string
+	From   string
+}
+
+// fileComments is what the history analyzer tracks of one file.
+type fileComments struct {
+	markers    []Marker
+	exported   int
+	documented int
+}
+
+// totals are the exported API counts and open markers of all tracked files.
+type totals struct {
+	exported   int
+	documented int
+	open       int
+}
+
+// tracker holds the markers and exported API counts of every tracked file.
+type tracker struct {
+	files map[string]fileComments
+	totals
+}
+
+func newTracker() *tracker {
+	return &tracker{files: make(map[string]fileComments)}
+}
+
+// rename moves the state of from to to, so that the markers of a renamed
+// file keep their introducing commit.
+func (t *tracker) rename(from, to string) []MarkerEvent {
+	fc, ok := t.files[from]
+	if !ok || from == to {
+		return nil
+	}
+
+	events := t.remove(to)
+
+	delete(t.files, from)
+	t.files[to] = fc
+
+	for _, marker := range fc.markers {
+		events = append(events, MarkerEvent{Marker: marker, Action: ActionMoved, File: to, From: from})
+	}
+
+	return events
+}
+
+// remove drops file and resolves its markers.
+func (t *tracker) remove(file string) []MarkerEvent {
+	events := t.set(file, fileComments{})
+	delete(t.files, file)
+
+	return events
+}
+
+// set replaces the state of file and returns the markers it introduced and
+// resolved. Markers match by kind and text, so moving one within the file
+// is not an event.
+func (t *tracker) set(file string, fc fileComments) []MarkerEvent {
+	old := t.files[file]
+
+	t.exported += fc.exported - old.exported
+	t.documented += fc.documented - old.documented
+	t.open += len(fc.markers) - len(old.markers)
+	t.files[file] = fc
+
+	remaining := make(map[Marker]int, len(old.markers))
+	for _, marker := range old.markers {
+		remaining[markerKey(marker)]++
+	}
+
+	var events []MarkerEvent
+
+	for _, marker := range fc.markers {
+		if key := markerKey(marker); remaining[key] > 0 {
+			remaining[key]--
+
+			continue
+		}
+
+		events = append(events, MarkerEvent{Marker: marker, Action: ActionIntroduced, File: file})
+	}
+
+	for _, marker := range old.markers {
+		if key := markerKey(marker); remaining[key] > 0 {
+			remaining[key]--
+
+			events = append(events, MarkerEvent{Marker: marker, Action: ActionResolved, File: file})
+		}
+	}
+
+	return events
+}
+
+func markerKey(marker Marker) Marker {
+	return Marker{Kind: marker.Kind, Text: marker.Text}
+}
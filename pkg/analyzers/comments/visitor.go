@@ -52,15 +52,7 @@ func (v *Visitor) GetReport() analyze.Report {
 		extractor: v.extractor,
 	}
 
-	if len(v.comments) == 0 {
-		return analyzer.buildEmptyResult()
-	}
-
-	config := analyzer.DefaultConfig()
-	commentDetails := analyzer.analyzeCommentPlacement(v.comments, v.functions, config)
-	metrics := analyzer.calculateMetrics(commentDetails, v.functions)
-
-	return analyzer.buildResult(commentDetails, v.functions, metrics)
+	return analyzer.analyzeNodes(v.comments, v.functions)
 }
 
 func (v *Visitor) isFunction(target *node.Node) bool {
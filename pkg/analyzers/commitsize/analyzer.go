@@ -0,0 +1,303 @@
+// Package commitsize measures the distribution of commit sizes per author and
+// per tick and flags mega-commits that are too large to review.
+package commitsize
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Configuration option keys for the commit size analyzer.
+const (
+	ConfigCommitSizeMegaLines = "CommitSize.MegaLines"
+	ConfigCommitSizeMegaFiles = "CommitSize.MegaFiles"
+
+	defaultMegaLines = 1000
+	defaultMegaFiles = 50
+)
+
+// Report keys of the commit size analyzer.
+const (
+	KeyCommits     = "commits"
+	KeyAuthorIndex = "author_index"
+	KeyTickSize    = "tick_size"
+	KeyMegaLines   = "mega_lines"
+	KeyMegaFiles   = "mega_files"
+
+	// commitBytes estimates the bytes of one Commit held by the
+	// aggregator.
+	commitBytes = 96
+)
+
+// Commit is the per-commit payload: how many files and lines a commit
+// changed.
+type Commit struct {
+	Hash     gitlib.Hash
+	Tick     int
+	AuthorID int
+	Time     time.Time
+	Files    int
+	Added    int
+	Removed  int
+}
+
+// Lines returns the number of added and removed lines.
+func (c *Commit) Lines() int {
+	return c.Added + c.Removed
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	Commits []Commit
+}
+
+// Analyzer measures the number of files and lines every commit changes.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	TreeDiff  *plumbing.TreeDiffAnalyzer
+	LineStats *plumbing.LinesStatsCalculator
+	Ticks     *plumbing.TicksSinceStart
+
+	// MegaLines and MegaFiles are the sizes above which a commit is a
+	// mega-commit.
+	MegaLines int
+	MegaFiles int
+
+	reversedPeopleDict []string
+	tickSize           time.Duration
+}
+
+// NewAnalyzer creates a new commit size analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{
+		MegaLines: defaultMegaLines,
+		MegaFiles: defaultMegaFiles,
+	}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/commitsize",
+			Mode: analyze.ModeHistory,
+			Description: "Measures the distribution of files and lines per commit, per author and per tick, " +
+				"and flags mega-commits that are too large to review.",
+		},
+		Sequential: false,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, NeedsDiffs: true, Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigCommitSizeMegaLines,
+				Description: "Flag commits that add and remove more lines than this as mega-commits.",
+				Flag:        "commit-size-mega-lines",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultMegaLines,
+			},
+			{
+				Name:        ConfigCommitSizeMegaFiles,
+				Description: "Flag commits that change more files than this as mega-commits.",
+				Flag:        "commit-size-mega-files",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultMegaFiles,
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
+			return ComputeAllMetrics(report), nil
+		},
+		AggregatorFn: newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigCommitSizeMegaLines].(int); exists && val > 0 {
+		a.MegaLines = val
+	}
+
+	if val, exists := facts[ConfigCommitSizeMegaFiles].(int); exists && val > 0 {
+		a.MegaFiles = val
+	}
+
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		a.reversedPeopleDict = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		a.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize prepares the analyzer for processing commits.
+func (a *Analyzer) Initialize(_ *gitlib.Repository) error {
+	return nil
+}
+
+// Consume emits the size of the commit. Merge commits are skipped: their
+// changes were made, and reviewed, on the merged branch. Commits that change
+// no files are skipped too.
+func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac == nil || ac.IsMerge || len(a.TreeDiff.Changes) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	size := &Commit{Files: len(a.TreeDiff.Changes)}
+
+	for _, stats := range a.LineStats.LineStats {
+		size.Added += stats.Added
+		size.Removed += stats.Removed
+	}
+
+	tc := analyze.TC{Data: size}
+
+	if ac.Commit != nil {
+		tc.CommitHash = ac.Commit.Hash()
+	}
+
+	return tc, nil
+}
+
+// Fork creates independent copies of the analyzer for parallel processing.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.LineStats = &plumbing.LinesStatsCalculator{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes:   a.TreeDiff.Changes,
+		LineStats: a.LineStats.LineStats,
+		Tick:      a.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.TreeDiff.Changes = snapshot.Changes
+	a.LineStats.LineStats = snapshot.LineStats
+	a.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot is a no-op for commitsize.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the commits in history order.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	commits := analyze.CollectTickCommits(ticks, tickCommits, commitTime)
+
+	return analyze.Report{
+		KeyCommits:     commits,
+		KeyAuthorIndex: a.reversedPeopleDict,
+		KeyTickSize:    a.tickSize,
+		KeyMegaLines:   a.MegaLines,
+		KeyMegaFiles:   a.MegaFiles,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func tickCommits(td *TickData) []Commit { return td.Commits }
+
+func commitTime(c Commit) time.Time { return c.Time }
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	cs, ok := tc.Data.(*Commit)
+	if !ok || cs == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{}
+		byTick[tc.Tick] = state
+	}
+
+	commit := *cs
+	commit.Hash = tc.CommitHash
+	commit.Tick = tc.Tick
+	commit.AuthorID = tc.AuthorID
+	commit.Time = tc.Timestamp
+
+	state.Commits = append(state.Commits, commit)
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming != nil {
+		existing.Commits = append(existing.Commits, incoming.Commits...)
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	return int64(len(state.Commits)) * commitBytes
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || len(state.Commits) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
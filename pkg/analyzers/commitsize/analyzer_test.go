@@ -0,0 +1,133 @@
+package commitsize
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func newTestAnalyzer() *Analyzer {
+	a := NewAnalyzer()
+	a.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+	a.LineStats = &plumbing.LinesStatsCalculator{}
+	a.Ticks = &plumbing.TicksSinceStart{}
+
+	return a
+}
+
+func testHash(c string) gitlib.Hash {
+	return gitlib.NewHash(c + "000000000000000000000000000000000000000")
+}
+
+func TestAnalyzer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	assert.Equal(t, "history/commitsize", a.Name())
+	assert.NotEmpty(t, a.Description())
+	assert.Len(t, a.ListConfigurationOptions(), 2)
+	assert.False(t, a.SequentialOnly())
+}
+
+func TestAnalyzer_Configure(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(map[string]any{
+		ConfigCommitSizeMegaLines: 400,
+		ConfigCommitSizeMegaFiles: 0,
+	}))
+	assert.Equal(t, 400, a.MegaLines)
+	assert.Equal(t, defaultMegaFiles, a.MegaFiles)
+}
+
+func TestAnalyzer_Consume(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+
+	main := gitlib.ChangeEntry{Name: "main.go", Hash: testHash("1")}
+	readme := gitlib.ChangeEntry{Name: "README.md", Hash: testHash("2")}
+	logo := gitlib.ChangeEntry{Name: "logo.png", Hash: testHash("3")}
+
+	a.TreeDiff.Changes = gitlib.Changes{
+		{Action: gitlib.Modify, From: main, To: main},
+		{Action: gitlib.Insert, To: readme},
+		{Action: gitlib.Insert, To: logo},
+	}
+	a.LineStats.LineStats = map[gitlib.ChangeEntry]pkgplumbing.LineStats{
+		main:   {Added: 12, Removed: 3},
+		readme: {Added: 40},
+	}
+
+	commit := gitlib.NewTestCommit(testHash("c"), gitlib.Signature{Name: "dev", When: time.Now()}, "docs")
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	assert.Equal(t, testHash("c"), tc.CommitHash)
+	assert.Equal(t, &Commit{Files: 3, Added: 52, Removed: 3}, tc.Data)
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: commit, IsMerge: true})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+
+	a.TreeDiff.Changes = nil
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+}
+
+func TestAnalyzer_Fork(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+	a.MegaLines = 300
+
+	forks := a.Fork(2)
+	require.Len(t, forks, 2)
+
+	for _, f := range forks {
+		clone, ok := f.(*Analyzer)
+		require.True(t, ok)
+		assert.NotSame(t, a.TreeDiff, clone.TreeDiff)
+		assert.NotSame(t, a.LineStats, clone.LineStats)
+		assert.Equal(t, 300, clone.MegaLines)
+	}
+}
+
+func TestAnalyzer_ReportFromTICKs(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	byTick := make(map[int]*TickData)
+
+	for _, tc := range []analyze.TC{
+		{Tick: 0, AuthorID: 1, CommitHash: testHash("b"), Timestamp: start.Add(time.Hour), Data: &Commit{Files: 1, Added: 5}},
+		{Tick: 0, AuthorID: 0, CommitHash: testHash("a"), Timestamp: start, Data: &Commit{Files: 2, Added: 9}},
+	} {
+		require.NoError(t, extractTC(tc, byTick))
+	}
+
+	tick, err := buildTick(0, byTick[0])
+	require.NoError(t, err)
+
+	report, err := NewAnalyzer().ReportFromTICKs(context.Background(), []analyze.TICK{tick})
+	require.NoError(t, err)
+
+	commits, ok := report[KeyCommits].([]Commit)
+	require.True(t, ok)
+	require.Len(t, commits, 2)
+	assert.Equal(t, testHash("a"), commits[0].Hash)
+	assert.Equal(t, 1, commits[1].AuthorID)
+	assert.Equal(t, defaultMegaLines, report[KeyMegaLines])
+}
@@ -0,0 +1,292 @@
+package commitsize
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+// sizeClasses bucket commits by added and removed lines for the histogram.
+var sizeClasses = []struct {
+	name     string
+	lines    string
+	maxLines int
+}{
+	{"XS", "0-10", 10},
+	{"S", "11-50", 50},
+	{"M", "51-250", 250},
+	{"L", "251-1000", 1000},
+	{"XL", ">1000", math.MaxInt},
+}
+
+// Percentiles reported by a Distribution.
+const (
+	p50 = 0.50
+	p75 = 0.75
+	p90 = 0.90
+	p99 = 0.99
+)
+
+// Distribution summarizes a set of commit sizes. Percentiles use the
+// nearest-rank method, so they are sizes of actual commits.
+type Distribution struct {
+	Mean float64 `json:"mean" yaml:"mean"`
+	P50  int     `json:"p50"  yaml:"p50"`
+	P75  int     `json:"p75"  yaml:"p75"`
+	P90  int     `json:"p90"  yaml:"p90"`
+	P99  int     `json:"p99"  yaml:"p99"`
+	Max  int     `json:"max"  yaml:"max"`
+}
+
+// SizeClass counts the commits of one size class.
+type SizeClass struct {
+	Class   string `json:"class"   yaml:"class"`
+	Lines   string `json:"lines"   yaml:"lines"`
+	Commits int    `json:"commits" yaml:"commits"`
+}
+
+// AuthorSizes is the commit size distribution of one author.
+type AuthorSizes struct {
+	Author      string       `json:"author"       yaml:"author"`
+	Commits     int          `json:"commits"      yaml:"commits"`
+	MegaCommits int          `json:"mega_commits" yaml:"mega_commits"`
+	Lines       Distribution `json:"lines"        yaml:"lines"`
+	Files       Distribution `json:"files"        yaml:"files"`
+}
+
+// TickSizes is the commit size distribution of one tick.
+type TickSizes struct {
+	Tick        int          `json:"tick"         yaml:"tick"`
+	Commits     int          `json:"commits"      yaml:"commits"`
+	MegaCommits int          `json:"mega_commits" yaml:"mega_commits"`
+	Lines       Distribution `json:"lines"        yaml:"lines"`
+	Files       Distribution `json:"files"        yaml:"files"`
+}
+
+// MegaCommit is a commit that changed more lines or files than the
+// mega-commit thresholds.
+type MegaCommit struct {
+	Hash    string    `json:"hash"    yaml:"hash"`
+	Author  string    `json:"author"  yaml:"author"`
+	Tick    int       `json:"tick"    yaml:"tick"`
+	Time    time.Time `json:"time"    yaml:"time"`
+	Files   int       `json:"files"   yaml:"files"`
+	Added   int       `json:"added"   yaml:"added"`
+	Removed int       `json:"removed" yaml:"removed"`
+}
+
+// ComputedMetrics holds the commit size distributions of the history.
+type ComputedMetrics struct {
+	Commits   int          `json:"commits"   yaml:"commits"`
+	Lines     Distribution `json:"lines"     yaml:"lines"`
+	Files     Distribution `json:"files"     yaml:"files"`
+	Histogram []SizeClass  `json:"histogram" yaml:"histogram"`
+	// Authors are ordered by number of commits, most first.
+	Authors []AuthorSizes `json:"authors" yaml:"authors"`
+	Trend   []TickSizes   `json:"trend"   yaml:"trend"`
+	// MegaCommits are in history order.
+	MegaCommits []MegaCommit `json:"mega_commits" yaml:"mega_commits"`
+	MegaLines   int          `json:"mega_lines"   yaml:"mega_lines"`
+	MegaFiles   int          `json:"mega_files"   yaml:"mega_files"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNameCommitSize = "commitsize"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNameCommitSize
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// sizes collects the line and file counts of a set of commits.
+type sizes struct {
+	lines []int
+	files []int
+	mega  int
+}
+
+func (s *sizes) add(commit *Commit, mega bool) {
+	s.lines = append(s.lines, commit.Lines())
+	s.files = append(s.files, commit.Files)
+
+	if mega {
+		s.mega++
+	}
+}
+
+// ComputeAllMetrics computes the commit size distributions of a report and
+// flags its mega-commits.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	commits, _ := report[KeyCommits].([]Commit)
+	names, _ := report[KeyAuthorIndex].([]string)
+	megaLines, _ := report[KeyMegaLines].(int)
+	megaFiles, _ := report[KeyMegaFiles].(int)
+
+	m := &ComputedMetrics{Commits: len(commits), MegaLines: megaLines, MegaFiles: megaFiles}
+	m.Histogram = make([]SizeClass, len(sizeClasses))
+
+	for i, class := range sizeClasses {
+		m.Histogram[i] = SizeClass{Class: class.name, Lines: class.lines}
+	}
+
+	var (
+		all      sizes
+		byAuthor = make(map[string]*sizes)
+		byTick   []*sizes
+	)
+
+	for i := range commits {
+		commit := &commits[i]
+		author := authorName(names, commit.AuthorID)
+		mega := (megaLines > 0 && commit.Lines() > megaLines) || (megaFiles > 0 && commit.Files > megaFiles)
+
+		if n := len(m.Trend); n == 0 || m.Trend[n-1].Tick != commit.Tick {
+			m.Trend = append(m.Trend, TickSizes{Tick: commit.Tick})
+			byTick = append(byTick, &sizes{})
+		}
+
+		if byAuthor[author] == nil {
+			byAuthor[author] = &sizes{}
+		}
+
+		all.add(commit, mega)
+		byAuthor[author].add(commit, mega)
+		byTick[len(byTick)-1].add(commit, mega)
+		m.Histogram[sizeClass(commit.Lines())].Commits++
+
+		if mega {
+			m.MegaCommits = append(m.MegaCommits, MegaCommit{
+				Hash: commit.Hash.String(), Author: author, Tick: commit.Tick, Time: commit.Time,
+				Files: commit.Files, Added: commit.Added, Removed: commit.Removed,
+			})
+		}
+	}
+
+	m.Lines = distribution(all.lines)
+	m.Files = distribution(all.files)
+
+	for i, s := range byTick {
+		point := &m.Trend[i]
+		point.Commits = len(s.lines)
+		point.MegaCommits = s.mega
+		point.Lines = distribution(s.lines)
+		point.Files = distribution(s.files)
+	}
+
+	m.Authors = authorSizes(byAuthor)
+	m.scaleCounts(analyze.SampleFactor(report))
+
+	return m
+}
+
+// scaleCounts scales the commit counts of a sampled history by factor to
+// estimate the full history. The size distributions are those of the
+// sampled commits, each of which spans the commits skipped before it.
+func (m *ComputedMetrics) scaleCounts(factor float64) {
+	if factor <= 1 {
+		return
+	}
+
+	scale := func(n *int) { *n = analyze.ScaleCount(*n, factor) }
+
+	scale(&m.Commits)
+
+	for i := range m.Histogram {
+		scale(&m.Histogram[i].Commits)
+	}
+
+	for i := range m.Authors {
+		scale(&m.Authors[i].Commits)
+		scale(&m.Authors[i].MegaCommits)
+	}
+
+	for i := range m.Trend {
+		scale(&m.Trend[i].Commits)
+		scale(&m.Trend[i].MegaCommits)
+	}
+}
+
+func authorSizes(byAuthor map[string]*sizes) []AuthorSizes {
+	result := make([]AuthorSizes, 0, len(byAuthor))
+
+	for author, s := range byAuthor {
+		result = append(result, AuthorSizes{
+			Author:      author,
+			Commits:     len(s.lines),
+			MegaCommits: s.mega,
+			Lines:       distribution(s.lines),
+			Files:       distribution(s.files),
+		})
+	}
+
+	slices.SortFunc(result, func(x, y AuthorSizes) int {
+		return cmp.Or(cmp.Compare(y.Commits, x.Commits), cmp.Compare(x.Author, y.Author))
+	})
+
+	return result
+}
+
+// sizeClass returns the index of the size class of a commit with lines
+// added and removed lines.
+func sizeClass(lines int) int {
+	for i, class := range sizeClasses {
+		if lines <= class.maxLines {
+			return i
+		}
+	}
+
+	return len(sizeClasses) - 1
+}
+
+// distribution summarizes values. values is not modified.
+func distribution(values []int) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	var sum int
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return Distribution{
+		Mean: float64(sum) / float64(len(sorted)),
+		P50:  nearestRank(sorted, p50),
+		P75:  nearestRank(sorted, p75),
+		P90:  nearestRank(sorted, p90),
+		P99:  nearestRank(sorted, p99),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// nearestRank returns the p-th percentile of the sorted, non-empty values.
+func nearestRank(sorted []int, p float64) int {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+
+	return sorted[max(rank, 1)-1]
+}
+
+func authorName(names []string, id int) string {
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return identity.AuthorMissingName
+}
@@ -0,0 +1,106 @@
+package commitsize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestDistribution(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Distribution{}, distribution(nil))
+
+	values := []int{100, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	assert.Equal(t, Distribution{Mean: 14.5, P50: 5, P75: 8, P90: 9, P99: 100, Max: 100}, distribution(values))
+	assert.Equal(t, 100, values[0])
+
+	assert.Equal(t, Distribution{Mean: 7, P50: 7, P75: 7, P90: 7, P99: 7, Max: 7}, distribution([]int{7}))
+}
+
+func TestSizeClass(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, sizeClass(0))
+	assert.Equal(t, 0, sizeClass(10))
+	assert.Equal(t, 1, sizeClass(11))
+	assert.Equal(t, 3, sizeClass(1000))
+	assert.Equal(t, 4, sizeClass(1001))
+}
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{})
+	assert.Zero(t, m.Commits)
+	assert.Len(t, m.Histogram, len(sizeClasses))
+	assert.Empty(t, m.Authors)
+	assert.Empty(t, m.MegaCommits)
+}
+
+func TestComputeAllMetrics(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		KeyAuthorIndex: []string{"alice", "bob"},
+		KeyMegaLines:   100,
+		KeyMegaFiles:   10,
+		KeyCommits: []Commit{
+			{Hash: testHash("a"), Tick: 0, AuthorID: 0, Files: 1, Added: 4, Removed: 1},
+			{Hash: testHash("b"), Tick: 0, AuthorID: 1, Files: 2, Added: 30},
+			{Hash: testHash("c"), Tick: 1, AuthorID: 0, Files: 40, Added: 20, Removed: 20},
+			{Hash: testHash("d"), Tick: 1, AuthorID: 0, Files: 3, Added: 900, Removed: 300},
+		},
+	}
+
+	m := ComputeAllMetrics(report)
+
+	assert.Equal(t, 4, m.Commits)
+	assert.Equal(t, 30, m.Lines.P50)
+	assert.Equal(t, 1200, m.Lines.Max)
+	assert.Equal(t, []int{1, 2, 0, 0, 1}, []int{
+		m.Histogram[0].Commits, m.Histogram[1].Commits, m.Histogram[2].Commits,
+		m.Histogram[3].Commits, m.Histogram[4].Commits,
+	})
+
+	require.Len(t, m.Trend, 2)
+	assert.Equal(t, 2, m.Trend[1].Commits)
+	assert.Equal(t, 2, m.Trend[1].MegaCommits)
+	assert.Equal(t, 30, m.Trend[0].Lines.Max)
+
+	require.Len(t, m.Authors, 2)
+	assert.Equal(t, "alice", m.Authors[0].Author)
+	assert.Equal(t, 3, m.Authors[0].Commits)
+	assert.Equal(t, 2, m.Authors[0].MegaCommits)
+	assert.Equal(t, 3, m.Authors[0].Files.P50)
+
+	require.Len(t, m.MegaCommits, 2)
+	assert.Equal(t, testHash("c").String(), m.MegaCommits[0].Hash)
+	assert.Equal(t, 900, m.MegaCommits[1].Added)
+
+	sections, err := (&Analyzer{}).GenerateSections(report)
+	require.NoError(t, err)
+	assert.Len(t, sections, 4)
+}
+
+func TestComputeAllMetrics_ScalesSampledCounts(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{
+		KeyAuthorIndex: []string{"alice"},
+		KeyCommits: []Commit{
+			{Hash: testHash("a"), Tick: 0, AuthorID: 0, Files: 1, Added: 4},
+			{Hash: testHash("b"), Tick: 0, AuthorID: 0, Files: 2, Added: 30},
+		},
+		analyze.ReportKeySampleFactor: 4.0,
+	})
+
+	assert.Equal(t, 8, m.Commits)
+	assert.Equal(t, 4, m.Histogram[0].Commits)
+	assert.Equal(t, 8, m.Authors[0].Commits)
+	assert.Equal(t, 8, m.Trend[0].Commits)
+	assert.Equal(t, 30, m.Lines.Max, "sizes are those of the sampled commits")
+}
@@ -0,0 +1,130 @@
+package commitsize
+
+import (
+	"html"
+	"strconv"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const shortHashLen = 8
+
+// RegisterPlotSections registers the commit size plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/commitsize", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeAllMetrics(report)
+
+	return []plotpage.Section{
+		{
+			Title:    "Commit Size Trend",
+			Subtitle: "Median and 90th percentile of changed lines per commit, per tick.",
+			Chart:    plotpage.WrapChart(buildTrendChart(m.Trend)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"<strong>Changed lines</strong> = added plus removed lines of a commit",
+					"A rising P90 means more commits are hard to review in one sitting",
+				},
+			},
+		},
+		{
+			Title:    "Size Classes",
+			Subtitle: strconv.Itoa(m.Commits) + " commits by changed lines.",
+			Chart:    plotpage.WrapChart(buildHistogramChart(m.Histogram)),
+		},
+		{
+			Title:    "Authors",
+			Subtitle: "Commit sizes per author, most commits first.",
+			Chart:    buildAuthorTable(m.Authors),
+		},
+		{
+			Title: "Mega-Commits",
+			Subtitle: strconv.Itoa(len(m.MegaCommits)) + " commits changed more than " +
+				strconv.Itoa(m.MegaLines) + " lines or " + strconv.Itoa(m.MegaFiles) + " files.",
+			Chart: buildMegaCommitTable(m.MegaCommits),
+		},
+	}, nil
+}
+
+func buildTrendChart(trend []TickSizes) *charts.Line {
+	labels := make([]string, len(trend))
+	median := make([]plotpage.SeriesData, len(trend))
+	p90 := make([]plotpage.SeriesData, len(trend))
+
+	for i, point := range trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		median[i] = point.Lines.P50
+		p90[i] = point.Lines.P90
+	}
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: "Median", Data: median},
+		{Name: "P90", Data: p90},
+	}, "Changed lines")
+}
+
+func buildHistogramChart(histogram []SizeClass) *charts.Bar {
+	labels := make([]string, len(histogram))
+	commits := make([]plotpage.SeriesData, len(histogram))
+
+	for i, class := range histogram {
+		labels[i] = class.Class + " (" + class.Lines + ")"
+		commits[i] = class.Commits
+	}
+
+	return plotpage.BuildBarChart(nil, labels, []plotpage.BarSeries{{Name: "Commits", Data: commits}}, "Commits")
+}
+
+func buildAuthorTable(authors []AuthorSizes) *plotpage.Table {
+	table := plotpage.NewTable([]string{
+		"Author", "Commits", "Median Lines", "P90 Lines", "Max Lines", "Median Files", "Mega-Commits",
+	}).WithSearch("Filter authors...")
+
+	for _, author := range authors {
+		table.AddRow(
+			html.EscapeString(author.Author),
+			strconv.Itoa(author.Commits),
+			strconv.Itoa(author.Lines.P50),
+			strconv.Itoa(author.Lines.P90),
+			strconv.Itoa(author.Lines.Max),
+			strconv.Itoa(author.Files.P50),
+			strconv.Itoa(author.MegaCommits),
+		)
+	}
+
+	return table
+}
+
+func buildMegaCommitTable(commits []MegaCommit) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Commit", "Author", "Tick", "Files", "Added", "Removed"})
+
+	for _, commit := range commits {
+		table.AddRow(
+			shortHash(commit.Hash),
+			html.EscapeString(commit.Author),
+			strconv.Itoa(commit.Tick),
+			strconv.Itoa(commit.Files),
+			"+"+strconv.Itoa(commit.Added),
+			"-"+strconv.Itoa(commit.Removed),
+		)
+	}
+
+	return table
+}
+
+func shortHash(hash string) string {
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+
+	return hash
+}
@@ -2,6 +2,8 @@ package common
 
 import (
 	"maps"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
 )
@@ -246,6 +248,23 @@ func ExtractNameFromChildren(n *node.Node, childIndex int) (string, bool) {
 	return "", false
 }
 
+// IsExported reports whether the declaration n named name is visible outside
+// its file: marked exported or public, or, without such roles, capitalized
+// as in Go.
+func IsExported(n *node.Node, name string) bool {
+	if n.HasAnyRole(node.RoleExported, node.RolePublic) {
+		return true
+	}
+
+	if n.HasAnyRole(node.RolePrivate) {
+		return false
+	}
+
+	first, _ := utf8.DecodeRuneInString(name)
+
+	return unicode.IsUpper(first)
+}
+
 // mergeNameExtractors merges custom extractors with defaults.
 func mergeNameExtractors(custom, defaults map[string]NameExtractor) map[string]NameExtractor {
 	if custom == nil {
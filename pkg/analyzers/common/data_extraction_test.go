@@ -582,6 +582,20 @@ func TestStandaloneExtractNameFromChildren(t *testing.T) {
 	}
 }
 
+func TestIsExported(t *testing.T) {
+	t.Parallel()
+
+	plain := &node.Node{}
+	public := &node.Node{Roles: []node.Role{node.RolePublic}}
+	private := &node.Node{Roles: []node.Role{node.RolePrivate}}
+
+	require.True(t, IsExported(plain, "Handler"))
+	require.False(t, IsExported(plain, "handler"))
+	require.True(t, IsExported(public, "handler"))
+	require.False(t, IsExported(private, "Handler"))
+	require.False(t, IsExported(plain, ""))
+}
+
 func TestMergeNameExtractors(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,311 @@
+// Package graphexport serializes weighted graphs as GraphML and GEXF for
+// network tools such as Gephi and Cytoscape.
+package graphexport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/storage"
+)
+
+// File extensions written by WriteFiles.
+const (
+	ExtGraphML = ".graphml"
+	ExtGEXF    = ".gexf"
+)
+
+const (
+	graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+	gexfNamespace    = "http://gexf.net/1.3"
+	gexfVersion      = "1.3"
+	creator          = "codefang"
+	filePerm         = 0o600
+)
+
+// Graph is a weighted graph, undirected unless Directed is set.
+type Graph struct {
+	// ID names the graph; WriteFiles uses it as the file name.
+	ID string
+	// Label describes the graph.
+	Label    string
+	Directed bool
+	Nodes    []Node
+	Edges    []Edge
+}
+
+// Node is a vertex of a Graph.
+type Node struct {
+	ID     string
+	Label  string
+	Weight float64
+}
+
+// Edge connects two nodes by ID.
+type Edge struct {
+	Source string
+	Target string
+	Weight float64
+}
+
+type graphMLDoc struct {
+	XMLName xml.Name       `xml:"graphml"`
+	XMLNS   string         `xml:"xmlns,attr"`
+	Keys    []graphMLKey   `xml:"key"`
+	Graphs  []graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphMLData `xml:"data"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes graphs as one GraphML document, one <graph> element each.
+func WriteGraphML(writer io.Writer, graphs ...Graph) error {
+	doc := graphMLDoc{
+		XMLNS: graphMLNamespace,
+		Keys: []graphMLKey{
+			{ID: "g_label", For: "graph", AttrName: "label", AttrType: "string"},
+			{ID: "n_label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "n_weight", For: "node", AttrName: "weight", AttrType: "double"},
+			{ID: "e_weight", For: "edge", AttrName: "weight", AttrType: "double"},
+		},
+	}
+
+	for _, g := range graphs {
+		out := graphMLGraph{
+			ID:          g.ID,
+			EdgeDefault: edgeType(g),
+			Data:        []graphMLData{{Key: "g_label", Value: g.Label}},
+			Nodes:       make([]graphMLNode, 0, len(g.Nodes)),
+			Edges:       make([]graphMLEdge, 0, len(g.Edges)),
+		}
+
+		for _, n := range g.Nodes {
+			out.Nodes = append(out.Nodes, graphMLNode{
+				ID: n.ID,
+				Data: []graphMLData{
+					{Key: "n_label", Value: n.Label},
+					{Key: "n_weight", Value: formatWeight(n.Weight)},
+				},
+			})
+		}
+
+		for _, e := range g.Edges {
+			out.Edges = append(out.Edges, graphMLEdge{
+				Source: e.Source,
+				Target: e.Target,
+				Data:   []graphMLData{{Key: "e_weight", Value: formatWeight(e.Weight)}},
+			})
+		}
+
+		doc.Graphs = append(doc.Graphs, out)
+	}
+
+	return encodeXML(writer, doc, "graphml")
+}
+
+type gexfDoc struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Meta    gexfMeta  `xml:"meta"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfMeta struct {
+	Creator     string `xml:"creator"`
+	Description string `xml:"description"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string         `xml:"defaultedgetype,attr"`
+	Attributes      gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode     `xml:"nodes>node"`
+	Edges           []gexfEdge     `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfEdge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Weight string `xml:"weight,attr"`
+}
+
+// WriteGEXF writes graph as a GEXF 1.3 document. Node weights become the
+// "weight" node attribute; edge weights use the native edge weight.
+func WriteGEXF(writer io.Writer, graph Graph) error {
+	doc := gexfDoc{
+		XMLNS:   gexfNamespace,
+		Version: gexfVersion,
+		Meta:    gexfMeta{Creator: creator, Description: graph.Label},
+		Graph: gexfGraph{
+			DefaultEdgeType: edgeType(graph),
+			Attributes: gexfAttributes{
+				Class:      "node",
+				Attributes: []gexfAttribute{{ID: "weight", Title: "weight", Type: "double"}},
+			},
+			Nodes: make([]gexfNode, 0, len(graph.Nodes)),
+			Edges: make([]gexfEdge, 0, len(graph.Edges)),
+		},
+	}
+
+	for _, n := range graph.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			ID:        n.ID,
+			Label:     n.Label,
+			AttValues: []gexfAttValue{{For: "weight", Value: formatWeight(n.Weight)}},
+		})
+	}
+
+	for i, e := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:     strconv.Itoa(i),
+			Source: e.Source,
+			Target: e.Target,
+			Weight: formatWeight(e.Weight),
+		})
+	}
+
+	return encodeXML(writer, doc, "gexf")
+}
+
+// WriteFiles writes every graph to dir twice, as <ID>.graphml and <ID>.gexf,
+// creating dir if needed. dir may be an object storage URL; see package
+// storage.
+func WriteFiles(dir string, graphs []Graph) error {
+	err := storage.MkdirAll(dir)
+	if err != nil {
+		return fmt.Errorf("create graph directory: %w", err)
+	}
+
+	for _, g := range graphs {
+		err = writeFile(storage.Join(dir, g.ID+ExtGraphML), func(w io.Writer) error { return WriteGraphML(w, g) })
+		if err != nil {
+			return err
+		}
+
+		err = writeFile(storage.Join(dir, g.ID+ExtGEXF), func(w io.Writer) error { return WriteGEXF(w, g) })
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	file, err := createFile(path)
+	if err != nil {
+		return fmt.Errorf("create graph file: %w", err)
+	}
+
+	err = write(file)
+	if err != nil {
+		file.Close()
+
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("close graph file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// createFile creates a local graph file readable by its owner only, or an
+// object in object storage.
+func createFile(path string) (io.WriteCloser, error) {
+	if storage.IsRemote(path) {
+		return storage.Create(path)
+	}
+
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePerm)
+}
+
+func encodeXML(writer io.Writer, doc any, kind string) error {
+	_, err := io.WriteString(writer, xml.Header)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", kind, err)
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(doc)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", kind, err)
+	}
+
+	_, err = io.WriteString(writer, "\n")
+	if err != nil {
+		return fmt.Errorf("write %s: %w", kind, err)
+	}
+
+	return nil
+}
+
+func edgeType(graph Graph) string {
+	if graph.Directed {
+		return "directed"
+	}
+
+	return "undirected"
+}
+
+func formatWeight(weight float64) string {
+	return strconv.FormatFloat(weight, 'g', -1, 64)
+}
@@ -0,0 +1,101 @@
+package graphexport_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/graphexport"
+)
+
+func sampleGraph() graphexport.Graph {
+	return graphexport.Graph{
+		ID:    "people",
+		Label: "Developer coupling",
+		Nodes: []graphexport.Node{
+			{ID: "0", Label: "alice <a@x>", Weight: 12},
+			{ID: "1", Label: "bob", Weight: 3.5},
+		},
+		Edges: []graphexport.Edge{{Source: "0", Target: "1", Weight: 4}},
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, graphexport.WriteGraphML(&buf, sampleGraph(), graphexport.Graph{ID: "empty"}))
+
+	out := buf.String()
+	assert.Contains(t, out, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	assert.Contains(t, out, `<graph id="people" edgedefault="undirected">`)
+	assert.Contains(t, out, `<graph id="empty" edgedefault="undirected">`)
+	assert.Contains(t, out, `<data key="n_label">alice &lt;a@x&gt;</data>`)
+	assert.Contains(t, out, `<data key="n_weight">3.5</data>`)
+	assert.Contains(t, out, `<edge source="0" target="1">`)
+	assert.Contains(t, out, `<data key="e_weight">4</data>`)
+
+	var doc struct {
+		Graphs []struct {
+			ID    string `xml:"id,attr"`
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+		} `xml:"graph"`
+	}
+
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Graphs, 2)
+	assert.Len(t, doc.Graphs[0].Nodes, 2)
+}
+
+func TestWriteGEXF(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, graphexport.WriteGEXF(&buf, sampleGraph()))
+
+	out := buf.String()
+	assert.Contains(t, out, `<gexf xmlns="http://gexf.net/1.3" version="1.3">`)
+	assert.Contains(t, out, `<description>Developer coupling</description>`)
+	assert.Contains(t, out, `<graph defaultedgetype="undirected">`)
+	assert.Contains(t, out, `<node id="0" label="alice &lt;a@x&gt;">`)
+	assert.Contains(t, out, `<attvalue for="weight" value="12"></attvalue>`)
+	assert.Contains(t, out, `<edge id="0" source="0" target="1" weight="4"></edge>`)
+}
+
+func TestWriteFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "graphs")
+
+	require.NoError(t, graphexport.WriteFiles(dir, []graphexport.Graph{sampleGraph()}))
+
+	for _, name := range []string{"people.graphml", "people.gexf"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `alice &lt;a@x&gt;`)
+	}
+}
+
+func TestWriteDirected(t *testing.T) {
+	t.Parallel()
+
+	graph := sampleGraph()
+	graph.Directed = true
+
+	var graphML, gexf bytes.Buffer
+
+	require.NoError(t, graphexport.WriteGraphML(&graphML, graph))
+	require.NoError(t, graphexport.WriteGEXF(&gexf, graph))
+
+	assert.Contains(t, graphML.String(), `<graph id="people" edgedefault="directed">`)
+	assert.Contains(t, gexf.String(), `<graph defaultedgetype="directed">`)
+}
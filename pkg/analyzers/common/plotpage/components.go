@@ -453,6 +453,9 @@ type Table struct {
 	Headers []string
 	Rows    [][]string
 	Striped bool
+	// Search is the placeholder of a box that filters rows by their text.
+	// Empty means no search box.
+	Search string
 }
 
 // NewTable creates a new table.
@@ -474,6 +477,14 @@ func (t *Table) WithStriped(striped bool) *Table {
 	return t
 }
 
+// WithSearch adds a search box, with the given placeholder, that hides rows
+// not containing the typed text.
+func (t *Table) WithSearch(placeholder string) *Table {
+	t.Search = placeholder
+
+	return t
+}
+
 // Render writes the table HTML.
 func (t *Table) Render(w io.Writer) error {
 	// Convert string rows to template.HTML to allow raw HTML in cells.
@@ -489,6 +500,7 @@ func (t *Table) Render(w io.Writer) error {
 		Headers: t.Headers,
 		Rows:    htmlRows,
 		Striped: t.Striped,
+		Search:  t.Search,
 	})
 
 	_, err := w.Write([]byte(html))
@@ -0,0 +1,145 @@
+package plotpage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidEvent indicates an entry of an events file without a valid date
+// or a label.
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Event is a dated occurrence, such as a release, an incident or a team
+// change, that pages mark on their time-series charts.
+type Event struct {
+	Date  time.Time
+	Label string
+	// Kind picks the marker color: release, incident, team or any other.
+	Kind string
+}
+
+// CommitTime is the time of an analyzed commit and the tick it fell in. The
+// commits of a run tie the tick and day axes of its charts to dates.
+type CommitTime struct {
+	Tick int
+	Time time.Time
+}
+
+// eventMarker is an event as the overlay script places it: on date axes by
+// Date, on tick axes by Tick and on day axes by Day, the days since the
+// first commit. Tick and Day are nil outside the analyzed commits.
+type eventMarker struct {
+	Label string `json:"label"`
+	Kind  string `json:"kind"`
+	Date  string `json:"date"`
+	Tick  *int   `json:"tick"`
+	Day   *int   `json:"day"`
+}
+
+const (
+	day        = 24 * time.Hour
+	dateLayout = "2006-01-02"
+)
+
+var (
+	overlayMu       sync.RWMutex
+	overlayEvents   []Event
+	overlayTimeline []CommitTime
+)
+
+// SetEvents sets the events marked on the time-series charts of every page
+// rendered from now on. Nil removes the markers.
+func SetEvents(events []Event) {
+	overlayMu.Lock()
+	defer overlayMu.Unlock()
+
+	overlayEvents = events
+}
+
+// SetTimeline sets the commits that place events on tick and day axes.
+// Without them, events are only marked on date axes.
+func SetTimeline(commits []CommitTime) {
+	commits = slices.Clone(commits)
+	slices.SortStableFunc(commits, func(a, b CommitTime) int { return a.Time.Compare(b.Time) })
+
+	overlayMu.Lock()
+	defer overlayMu.Unlock()
+
+	overlayTimeline = commits
+}
+
+// ParseEvents parses an events file: a YAML or JSON list of entries with a
+// date (YYYY-MM-DD or RFC 3339), a label and an optional kind.
+func ParseEvents(data []byte) ([]Event, error) {
+	var entries []struct {
+		Date  string `yaml:"date"`
+		Label string `yaml:"label"`
+		Kind  string `yaml:"kind"`
+	}
+
+	err := yaml.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("parse events: %w", err)
+	}
+
+	events := make([]Event, 0, len(entries))
+
+	for i, entry := range entries {
+		if entry.Label == "" {
+			return nil, fmt.Errorf("%w: entry %d has no label", ErrInvalidEvent, i+1)
+		}
+
+		date, dateErr := time.Parse(dateLayout, entry.Date)
+		if dateErr != nil {
+			date, dateErr = time.Parse(time.RFC3339, entry.Date)
+		}
+
+		if dateErr != nil {
+			return nil, fmt.Errorf("%w: %q: date %q is not YYYY-MM-DD or RFC 3339", ErrInvalidEvent, entry.Label, entry.Date)
+		}
+
+		events = append(events, Event{Date: date.UTC(), Label: entry.Label, Kind: entry.Kind})
+	}
+
+	return events, nil
+}
+
+// eventMarkers places the registered events on the registered timeline.
+// Events count as lasting their whole day.
+func eventMarkers() []eventMarker {
+	overlayMu.RLock()
+	defer overlayMu.RUnlock()
+
+	markers := make([]eventMarker, 0, len(overlayEvents))
+
+	for _, event := range overlayEvents {
+		start := event.Date.Truncate(day)
+		marker := eventMarker{Label: event.Label, Kind: event.Kind, Date: start.Format(dateLayout)}
+
+		if len(overlayTimeline) > 0 {
+			first, last := overlayTimeline[0].Time, overlayTimeline[len(overlayTimeline)-1].Time
+
+			if start.Add(day).After(first) && !start.After(last) {
+				tick := 0
+
+				for _, commit := range overlayTimeline {
+					if commit.Time.Before(start.Add(day)) {
+						tick = max(tick, commit.Tick)
+					}
+				}
+
+				days := int(start.Sub(first.UTC().Truncate(day)) / day)
+				marker.Tick, marker.Day = &tick, &days
+			}
+		}
+
+		markers = append(markers, marker)
+	}
+
+	return markers
+}
@@ -0,0 +1,71 @@
+package plotpage
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEvents(t *testing.T) {
+	t.Parallel()
+
+	events, err := ParseEvents([]byte(`
+- date: 2026-03-01
+  label: v2.0
+  kind: release
+- {"date": "2026-04-10T15:04:05+02:00", "label": "Outage"}
+`))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, Event{Date: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Label: "v2.0", Kind: "release"}, events[0])
+	assert.Equal(t, time.Date(2026, 4, 10, 13, 4, 5, 0, time.UTC), events[1].Date)
+
+	_, err = ParseEvents([]byte(`[{date: "March", label: x}]`))
+	require.ErrorIs(t, err, ErrInvalidEvent)
+
+	_, err = ParseEvents([]byte(`[{date: 2026-03-01}]`))
+	require.ErrorIs(t, err, ErrInvalidEvent)
+}
+
+// TestEventMarkers sets the package overlay, so it does not run in parallel.
+func TestEventMarkers(t *testing.T) {
+	t.Cleanup(func() {
+		SetEvents(nil)
+		SetTimeline(nil)
+	})
+
+	date := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	SetEvents([]Event{
+		{Date: date(1), Label: "before"},
+		{Date: date(4), Label: "release", Kind: "release"},
+		{Date: date(20), Label: "after"},
+	})
+
+	markers := eventMarkers()
+	require.Len(t, markers, 3)
+	assert.Equal(t, "2026-03-04", markers[1].Date)
+	assert.Nil(t, markers[1].Tick, "no timeline places events on dates only")
+
+	SetTimeline([]CommitTime{
+		{Tick: 5, Time: date(7).Add(9 * time.Hour)},
+		{Tick: 0, Time: date(2).Add(9 * time.Hour)},
+		{Tick: 2, Time: date(4).Add(18 * time.Hour)},
+	})
+
+	markers = eventMarkers()
+	assert.Nil(t, markers[0].Tick, "events before the first commit have no tick")
+	require.NotNil(t, markers[1].Tick)
+	assert.Equal(t, 2, *markers[1].Tick, "a commit later on the event's day counts")
+	assert.Equal(t, 2, *markers[1].Day)
+	assert.Nil(t, markers[2].Day, "events after the last commit have no day")
+
+	var buf bytes.Buffer
+
+	require.NoError(t, NewPage("Events", "").Render(&buf))
+	assert.Contains(t, buf.String(), "overlayEvents([")
+	assert.Contains(t, buf.String(), `"label":"release"`)
+}
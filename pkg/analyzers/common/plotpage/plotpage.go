@@ -129,7 +129,7 @@ func (r HTMLRenderer) Render(w io.Writer, page *Page) error {
 		sectionsHTML.WriteString(string(sectionHTML))
 	}
 
-	scripts, err := renderTemplate("scripts.html", nil)
+	scripts, err := renderTemplate("scripts.html", scriptsData{Events: eventMarkers()})
 	if err != nil {
 		return fmt.Errorf("render scripts: %w", err)
 	}
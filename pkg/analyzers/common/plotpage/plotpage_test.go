@@ -214,6 +214,33 @@ func TestTableRender(t *testing.T) {
 	if !strings.Contains(html, "foo") {
 		t.Error("Expected row data")
 	}
+
+	if strings.Contains(html, `type="search"`) {
+		t.Error("Expected no search box by default")
+	}
+}
+
+func TestTableRender_WithSearch(t *testing.T) {
+	t.Parallel()
+
+	table := NewTable([]string{"File"}).WithSearch("Filter files")
+	table.AddRow("main.go")
+
+	var buf bytes.Buffer
+
+	err := table.Render(&buf)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	html := buf.String()
+	if !strings.Contains(html, `placeholder="Filter files"`) {
+		t.Error("Expected search box")
+	}
+
+	if !strings.Contains(html, "filterTable(this)") {
+		t.Error("Expected search box to filter the table")
+	}
 }
 
 func TestAlertRender(t *testing.T) {
@@ -85,6 +85,11 @@ type pageData struct {
 	Scripts     template.HTML
 }
 
+// scriptsData holds data for the scripts template.
+type scriptsData struct {
+	Events []eventMarker
+}
+
 // headerData holds data for the header template.
 type headerData struct {
 	ProjectName     string
@@ -170,4 +175,5 @@ type tableData struct {
 	Headers []string
 	Rows    [][]template.HTML
 	Striped bool
+	Search  string
 }
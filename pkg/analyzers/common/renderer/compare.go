@@ -0,0 +1,339 @@
+package renderer
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+// metricRounding rounds metric values on the comparison page to hundredths.
+const metricRounding = 100
+
+// metricDirection tells whether a rise of a summary metric is an improvement
+// (1) or a regression (-1). Metrics it lacks are neutral.
+var metricDirection = map[string]int{
+	SummaryMetricHotspots:          -1,
+	SummaryMetricBusFactorMin:      1,
+	SummaryMetricAverageComplexity: -1,
+}
+
+// metricLabels are the short names of the summary metrics on stat cards.
+var metricLabels = map[string]string{
+	SummaryMetricCommits:           "Commits analyzed",
+	SummaryMetricHotspots:          "Hotspots",
+	SummaryMetricBusFactorMin:      "Lowest bus factor",
+	SummaryMetricAverageComplexity: "Average complexity",
+}
+
+// MetricDelta is a summary metric of two runs. InBase and InHead are false
+// when the run lacks the report the metric comes from.
+type MetricDelta struct {
+	Name   string
+	Help   string
+	Base   float64
+	Head   float64
+	InBase bool
+	InHead bool
+}
+
+// Delta returns Head minus Base, or 0 when either run lacks the metric.
+func (d MetricDelta) Delta() float64 {
+	if !d.InBase || !d.InHead {
+		return 0
+	}
+
+	return d.Head - d.Base
+}
+
+// Hotspot is a file the file-history report flags as risky to change.
+type Hotspot struct {
+	Path    string  `json:"path"`
+	Commits int     `json:"commit_count"`
+	Churn   float64 `json:"churn_score"`
+	Risk    string  `json:"risk_level"`
+}
+
+// SurvivalPoint is the number of lines alive at one burndown sample.
+type SurvivalPoint struct {
+	Sample int    `json:"sample_index"`
+	Period string `json:"period"`
+	Lines  int64  `json:"total_lines"`
+}
+
+// Comparison is what changed between a base and a head run.
+type Comparison struct {
+	Metrics         []MetricDelta
+	NewHotspots     []Hotspot
+	RemovedHotspots []Hotspot
+	BaseSurvival    []SurvivalPoint
+	HeadSurvival    []SurvivalPoint
+}
+
+// Compare compares the summary metrics, hotspots and burndown of the base
+// and head models, typically two runs of the same repository.
+func Compare(base, head UnifiedModel) (Comparison, error) {
+	var c Comparison
+
+	baseMetrics, err := SummaryMetrics(base)
+	if err != nil {
+		return c, err
+	}
+
+	headMetrics, err := SummaryMetrics(head)
+	if err != nil {
+		return c, err
+	}
+
+	c.Metrics = mergeMetrics(baseMetrics, headMetrics)
+
+	baseHotspots, err := modelSection[Hotspot](base, fileHistoryID, "hotspots")
+	if err != nil {
+		return c, err
+	}
+
+	headHotspots, err := modelSection[Hotspot](head, fileHistoryID, "hotspots")
+	if err != nil {
+		return c, err
+	}
+
+	c.NewHotspots = hotspotsMissing(headHotspots, baseHotspots)
+	c.RemovedHotspots = hotspotsMissing(baseHotspots, headHotspots)
+
+	c.BaseSurvival, err = modelSection[SurvivalPoint](base, burndownID, "global_survival")
+	if err != nil {
+		return c, err
+	}
+
+	c.HeadSurvival, err = modelSection[SurvivalPoint](head, burndownID, "global_survival")
+	if err != nil {
+		return c, err
+	}
+
+	return c, nil
+}
+
+// mergeMetrics pairs the metrics of both runs by name, in the order of
+// SummaryMetrics.
+func mergeMetrics(base, head []SummaryMetric) []MetricDelta {
+	var deltas []MetricDelta
+
+	find := func(name string) int {
+		return slices.IndexFunc(deltas, func(d MetricDelta) bool { return d.Name == name })
+	}
+
+	for _, m := range base {
+		deltas = append(deltas, MetricDelta{Name: m.Name, Help: m.Help, Base: m.Value, InBase: true})
+	}
+
+	for _, m := range head {
+		i := find(m.Name)
+		if i < 0 {
+			deltas = append(deltas, MetricDelta{Name: m.Name, Help: m.Help})
+			i = len(deltas) - 1
+		}
+
+		deltas[i].Head = m.Value
+		deltas[i].InHead = true
+	}
+
+	return deltas
+}
+
+// modelSection decodes the section key of the report of analyzer id in
+// model, or returns nil when the model lacks the report.
+func modelSection[T any](model UnifiedModel, id, key string) ([]T, error) {
+	var rows []T
+
+	for _, result := range model.Analyzers {
+		if result.ID != id {
+			continue
+		}
+
+		err := decodeSection(result.Report, key, &rows)
+		if err != nil {
+			return nil, fmt.Errorf("compare %s: %w", id, err)
+		}
+	}
+
+	return rows, nil
+}
+
+// hotspotsMissing returns the hotspots of from whose file is no hotspot in
+// other, riskiest first.
+func hotspotsMissing(from, other []Hotspot) []Hotspot {
+	known := make(map[string]bool, len(other))
+	for _, h := range other {
+		known[h.Path] = true
+	}
+
+	var missing []Hotspot
+
+	for _, h := range from {
+		if !known[h.Path] {
+			missing = append(missing, h)
+		}
+	}
+
+	slices.SortFunc(missing, func(a, b Hotspot) int {
+		return cmp.Or(cmp.Compare(b.Churn, a.Churn), cmp.Compare(a.Path, b.Path))
+	})
+
+	return missing
+}
+
+// Render writes the comparison page of the base and head runs named
+// baseName and headName: metric deltas, new and removed hotspots and the
+// burndown of both runs on one chart, each when a run has the report.
+func (c Comparison) Render(writer io.Writer, baseName, headName string) error {
+	page := plotpage.NewPage("Run comparison", baseName+" vs "+headName)
+
+	if len(c.Metrics) > 0 {
+		page.Add(plotpage.Section{
+			Title:    "Metrics",
+			Subtitle: "Head run, with the change since the base run",
+			Chart:    c.metricGrid(),
+		})
+	}
+
+	if len(c.NewHotspots) > 0 || len(c.RemovedHotspots) > 0 {
+		page.Add(
+			plotpage.Section{
+				Title:    "New hotspots",
+				Subtitle: fmt.Sprintf("%d files became hotspots (history/file-history)", len(c.NewHotspots)),
+				Chart:    hotspotTable(c.NewHotspots),
+			},
+			plotpage.Section{
+				Title:    "Removed hotspots",
+				Subtitle: fmt.Sprintf("%d files are no longer hotspots (history/file-history)", len(c.RemovedHotspots)),
+				Chart:    hotspotTable(c.RemovedHotspots),
+			},
+		)
+	}
+
+	if len(c.BaseSurvival) > 0 || len(c.HeadSurvival) > 0 {
+		page.Add(plotpage.Section{
+			Title:    "Burndown",
+			Subtitle: "Surviving lines of both runs (history/burndown)",
+			Chart:    plotpage.WrapChart(c.burndownOverlay(baseName, headName)),
+		})
+	}
+
+	err := page.Render(writer)
+	if err != nil {
+		return fmt.Errorf("render comparison: %w", err)
+	}
+
+	return nil
+}
+
+func (c Comparison) metricGrid() *plotpage.Grid {
+	stats := make([]plotpage.Renderable, 0, len(c.Metrics))
+
+	for _, m := range c.Metrics {
+		value := "-"
+		if m.InHead {
+			value = formatMetric(m.Head)
+		}
+
+		label, ok := metricLabels[m.Name]
+		if !ok {
+			label = m.Name
+		}
+
+		stat := plotpage.NewStat(label, value)
+
+		switch {
+		case !m.InBase:
+			stat.WithTrend("new", plotpage.BadgeInfo)
+		case !m.InHead:
+			stat.WithTrend("was "+formatMetric(m.Base), plotpage.BadgeDefault)
+		default:
+			stat.WithTrend(deltaTrend(m.Delta()), deltaColor(m.Name, m.Delta()))
+		}
+
+		stats = append(stats, stat)
+	}
+
+	return plotpage.NewGrid(statColumns, stats...)
+}
+
+// formatMetric formats a metric value to at most two decimals.
+func formatMetric(value float64) string {
+	return strconv.FormatFloat(math.Round(value*metricRounding)/metricRounding, 'f', -1, 64)
+}
+
+func deltaTrend(delta float64) string {
+	if delta > 0 {
+		return "+" + formatMetric(delta)
+	}
+
+	return formatMetric(delta)
+}
+
+// deltaColor colors a change of metric name by whether it is an improvement.
+func deltaColor(name string, delta float64) plotpage.BadgeColor {
+	switch sign := cmp.Compare(delta, 0) * metricDirection[name]; {
+	case sign > 0:
+		return plotpage.BadgeSuccess
+	case sign < 0:
+		return plotpage.BadgeError
+	default:
+		return plotpage.BadgeDefault
+	}
+}
+
+func hotspotTable(hotspots []Hotspot) *plotpage.Table {
+	table := plotpage.NewTable([]string{"File", "Commits", "Churn score", "Risk"})
+
+	for _, h := range hotspots {
+		table.AddRow(
+			template.HTMLEscapeString(h.Path),
+			strconv.Itoa(h.Commits),
+			fmt.Sprintf("%.2f", h.Churn),
+			template.HTMLEscapeString(h.Risk),
+		)
+	}
+
+	return table
+}
+
+// burndownOverlay charts the surviving lines of both runs by sample. The
+// shorter run is padded with gaps, so samples line up when both runs use the
+// same granularity from the same first commit.
+func (c Comparison) burndownOverlay(baseName, headName string) *charts.Line {
+	longest := c.HeadSurvival
+	if len(c.BaseSurvival) > len(longest) {
+		longest = c.BaseSurvival
+	}
+
+	labels := make([]string, len(longest))
+
+	for i, p := range longest {
+		labels[i] = p.Period
+		if labels[i] == "" {
+			labels[i] = strconv.Itoa(p.Sample)
+		}
+	}
+
+	series := func(points []SurvivalPoint) []plotpage.SeriesData {
+		data := make([]plotpage.SeriesData, len(longest))
+		for i, p := range points {
+			data[i] = p.Lines
+		}
+
+		return data
+	}
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: baseName, Data: series(c.BaseSurvival)},
+		{Name: headName, Data: series(c.HeadSurvival)},
+	}, "Lines")
+}
@@ -0,0 +1,102 @@
+package renderer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func comparisonModel(complexity float64, hotspots []string, survival ...float64) UnifiedModel {
+	rows := make([]any, 0, len(hotspots))
+	for i, path := range hotspots {
+		rows = append(rows, map[string]any{"path": path, "commit_count": 10.0, "churn_score": float64(i + 1)})
+	}
+
+	points := make([]any, 0, len(survival))
+	for i, lines := range survival {
+		points = append(points, map[string]any{"sample_index": float64(i), "total_lines": lines})
+	}
+
+	return NewUnifiedModel([]AnalyzerResult{
+		{
+			ID:     "static/complexity",
+			Mode:   analyze.ModeStatic,
+			Report: analyze.Report{"aggregate": map[string]any{"average_complexity": complexity}},
+		},
+		{
+			ID:     "history/file-history",
+			Mode:   analyze.ModeHistory,
+			Report: analyze.Report{"hotspots": rows},
+		},
+		{
+			ID:     "history/burndown",
+			Mode:   analyze.ModeHistory,
+			Report: analyze.Report{"global_survival": points},
+		},
+	})
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	base := comparisonModel(4, []string{"a.go", "b.go"}, 100, 90)
+	head := comparisonModel(3.5, []string{"b.go", "c.go", "d.go"}, 100, 95, 120)
+
+	c, err := Compare(base, head)
+	require.NoError(t, err)
+
+	require.Len(t, c.Metrics, 2)
+	assert.Equal(t, SummaryMetricAverageComplexity, c.Metrics[0].Name)
+	assert.InDelta(t, -0.5, c.Metrics[0].Delta(), 1e-9)
+	assert.Equal(t, SummaryMetricHotspots, c.Metrics[1].Name)
+	assert.InDelta(t, 1, c.Metrics[1].Delta(), 1e-9)
+
+	require.Len(t, c.NewHotspots, 2)
+	assert.Equal(t, "d.go", c.NewHotspots[0].Path, "riskiest first")
+	require.Len(t, c.RemovedHotspots, 1)
+	assert.Equal(t, "a.go", c.RemovedHotspots[0].Path)
+
+	assert.Len(t, c.BaseSurvival, 2)
+	assert.Equal(t, int64(120), c.HeadSurvival[2].Lines)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, c.Render(&buf, "acme/api/r1", "acme/api/r2"))
+
+	html := buf.String()
+	assert.Contains(t, html, "acme/api/r1 vs acme/api/r2")
+	assert.Contains(t, html, "New hotspots")
+	assert.Contains(t, html, "d.go")
+	assert.Contains(t, html, "Burndown")
+}
+
+func TestCompare_MissingReports(t *testing.T) {
+	t.Parallel()
+
+	c, err := Compare(NewUnifiedModel(nil), comparisonModel(2, nil))
+	require.NoError(t, err)
+
+	require.Len(t, c.Metrics, 2)
+	assert.False(t, c.Metrics[0].InBase)
+	assert.Zero(t, c.Metrics[0].Delta())
+	assert.Empty(t, c.NewHotspots)
+	assert.Empty(t, c.BaseSurvival)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, c.Render(&buf, "base", "head"))
+	assert.NotContains(t, buf.String(), "Burndown")
+}
+
+func TestDeltaColor(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "success", string(deltaColor(SummaryMetricHotspots, -2)))
+	assert.Equal(t, "error", string(deltaColor(SummaryMetricBusFactorMin, -1)))
+	assert.Equal(t, "default", string(deltaColor(SummaryMetricCommits, 10)))
+	assert.Equal(t, "default", string(deltaColor(SummaryMetricHotspots, 0)))
+}
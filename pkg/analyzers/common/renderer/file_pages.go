@@ -0,0 +1,694 @@
+package renderer
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+// ErrUnknownFile is returned when a file page is requested for a path that no
+// report mentions.
+var ErrUnknownFile = errors.New("file not in report")
+
+// ErrUnknownAuthor is returned when an author page is requested for a name
+// that owns no lines in the report.
+var ErrUnknownAuthor = errors.New("author not in report")
+
+// IDs of the analyzers whose reports carry per-file data.
+const (
+	burndownID          = "history/burndown"
+	fileHistoryID       = "history/file-history"
+	couplesID           = "history/couples"
+	devsID              = "history/devs"
+	complexityID        = "static/complexity"
+	complexityHistoryID = "history/complexity"
+)
+
+const (
+	percentScale = 100
+	statColumns  = 4
+	areaOpacity  = 0.6
+)
+
+// FileOwner is one author's share of a file's surviving lines.
+type FileOwner struct {
+	Author string
+	Lines  int
+}
+
+// FileChurn summarizes how often and how much a file changed.
+type FileChurn struct {
+	Commits      int     `json:"commit_count"`
+	Contributors int     `json:"contributor_count"`
+	Added        int     `json:"total_lines_added"`
+	Removed      int     `json:"total_lines_removed"`
+	Score        float64 `json:"churn_score"`
+}
+
+// CouplingPartner is a file that changes together with another.
+type CouplingPartner struct {
+	Path      string
+	CoChanges int64
+	Strength  float64
+}
+
+// FunctionComplexity is the complexity of one function of a file.
+type FunctionComplexity struct {
+	Name       string `json:"name"`
+	File       string `json:"file"`
+	Cyclomatic int    `json:"cyclomatic_complexity"`
+	Cognitive  int    `json:"cognitive_complexity"`
+	Lines      int    `json:"lines_of_code"`
+	Risk       string `json:"risk_level"`
+}
+
+// ComplexityPoint is the summed complexity of a file's functions at a tick.
+type ComplexityPoint struct {
+	Tick       int `json:"tick"`
+	Complexity int `json:"complexity"`
+	Cognitive  int `json:"cognitive"`
+}
+
+// FileDetail is what the reports of a model say about one file.
+type FileDetail struct {
+	Path  string
+	Lines int64
+	// Burndown is the file's burndown matrix: surviving lines per sample and band.
+	Burndown  [][]int64
+	Owners    []FileOwner
+	Churn     *FileChurn
+	Partners  []CouplingPartner
+	Functions []FunctionComplexity
+	// Trend is the file's complexity at every tick its functions changed in.
+	Trend []ComplexityPoint
+}
+
+type fileSurvival struct {
+	Path         string      `json:"path"`
+	CurrentLines int64       `json:"current_lines"`
+	Ownership    map[int]int `json:"ownership"`
+	History      [][]int64   `json:"history"`
+}
+
+type fileTrend struct {
+	File   string            `json:"file"`
+	Points []ComplexityPoint `json:"points"`
+}
+
+type fileChurnRow struct {
+	Path string `json:"path"`
+	FileChurn
+}
+
+type fileCoupling struct {
+	File1     string  `json:"file1"`
+	File2     string  `json:"file2"`
+	CoChanges int64   `json:"co_changes"`
+	Strength  float64 `json:"coupling_strength"`
+}
+
+type namedAuthor struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// FileIndex lists the files mentioned by the reports of a model and builds
+// their detail pages on demand, so a large repository does not render one
+// page per file up front.
+type FileIndex struct {
+	survival  map[string]fileSurvival
+	churn     map[string]FileChurn
+	couplings []fileCoupling
+	functions map[string][]FunctionComplexity
+	trends    map[string][]ComplexityPoint
+	authors   map[int]string
+	paths     []string
+}
+
+// NewFileIndex indexes the per-file data of the burndown, file-history,
+// couples, static complexity and complexity history reports of model.
+func NewFileIndex(model UnifiedModel) (*FileIndex, error) {
+	idx := &FileIndex{
+		survival:  make(map[string]fileSurvival),
+		churn:     make(map[string]FileChurn),
+		functions: make(map[string][]FunctionComplexity),
+		trends:    make(map[string][]ComplexityPoint),
+		authors:   make(map[int]string),
+	}
+
+	var functions []FunctionComplexity
+
+	for _, result := range model.Analyzers {
+		var err error
+
+		switch result.ID {
+		case burndownID:
+			err = idx.indexBurndown(result.Report)
+		case fileHistoryID:
+			err = idx.indexFileHistory(result.Report)
+		case couplesID:
+			err = decodeSection(result.Report, "file_coupling", &idx.couplings)
+		case devsID:
+			err = idx.indexAuthors(result.Report, "developers")
+		case complexityID:
+			err = decodeSection(result.Report, "function_complexity", &functions)
+		case complexityHistoryID:
+			err = idx.indexComplexityHistory(result.Report)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("index %s: %w", result.ID, err)
+		}
+	}
+
+	known := make(map[string]bool)
+
+	for path := range idx.survival {
+		known[path] = true
+	}
+
+	for path := range idx.churn {
+		known[path] = true
+	}
+
+	for path := range idx.trends {
+		known[path] = true
+	}
+
+	for _, c := range idx.couplings {
+		known[c.File1] = true
+		known[c.File2] = true
+	}
+
+	// Static analyzers report the paths they walked, history analyzers
+	// repository-relative ones; attach functions to the history path the
+	// walked path ends with.
+	for _, fn := range functions {
+		path := matchPath(fn.File, known)
+		idx.functions[path] = append(idx.functions[path], fn)
+	}
+
+	for path := range idx.functions {
+		known[path] = true
+	}
+
+	delete(known, "")
+
+	idx.paths = slices.Sorted(maps.Keys(known))
+
+	return idx, nil
+}
+
+func (idx *FileIndex) indexBurndown(report analyze.Report) error {
+	var files []fileSurvival
+
+	err := decodeSection(report, "file_survival", &files)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		idx.survival[f.Path] = f
+	}
+
+	return idx.indexAuthors(report, "developer_survival")
+}
+
+func (idx *FileIndex) indexFileHistory(report analyze.Report) error {
+	var rows []fileChurnRow
+
+	err := decodeSection(report, "file_churn", &rows)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		idx.churn[row.Path] = row.FileChurn
+	}
+
+	return nil
+}
+
+func (idx *FileIndex) indexComplexityHistory(report analyze.Report) error {
+	var trends []fileTrend
+
+	err := decodeSection(report, "file_trends", &trends)
+	if err != nil {
+		return err
+	}
+
+	for _, trend := range trends {
+		idx.trends[trend.File] = trend.Points
+	}
+
+	return nil
+}
+
+func (idx *FileIndex) indexAuthors(report analyze.Report, key string) error {
+	var authors []namedAuthor
+
+	err := decodeSection(report, key, &authors)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range authors {
+		if a.Name != "" {
+			idx.authors[a.ID] = a.Name
+		}
+	}
+
+	return nil
+}
+
+// decodeSection decodes report[key] into dst through JSON, which accepts both
+// typed reports and reports decoded from JSON or binary input. A missing key
+// leaves dst unchanged.
+func decodeSection(report analyze.Report, key string, dst any) error {
+	value, ok := report[key]
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", key, err)
+	}
+
+	err = json.Unmarshal(data, dst)
+	if err != nil {
+		return fmt.Errorf("decode %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// matchPath returns the known path that file ends with, or file itself.
+func matchPath(file string, known map[string]bool) string {
+	file = strings.TrimPrefix(strings.ReplaceAll(file, "\\", "/"), "./")
+
+	for suffix := file; ; {
+		if known[suffix] {
+			return suffix
+		}
+
+		i := strings.IndexByte(suffix, '/')
+		if i < 0 {
+			return file
+		}
+
+		suffix = suffix[i+1:]
+	}
+}
+
+// Paths returns the indexed file paths in lexical order.
+func (idx *FileIndex) Paths() []string {
+	return idx.paths
+}
+
+func (idx *FileIndex) has(path string) bool {
+	_, ok := slices.BinarySearch(idx.paths, path)
+
+	return ok
+}
+
+// Detail builds the detail of path. The second result is false when no
+// report mentions path.
+func (idx *FileIndex) Detail(path string) (FileDetail, bool) {
+	if !idx.has(path) {
+		return FileDetail{}, false
+	}
+
+	detail := FileDetail{Path: path, Functions: idx.functions[path], Trend: idx.trends[path]}
+
+	if s, ok := idx.survival[path]; ok {
+		detail.Lines = s.CurrentLines
+		detail.Burndown = s.History
+
+		for id, lines := range s.Ownership {
+			detail.Owners = append(detail.Owners, FileOwner{Author: idx.authorName(id), Lines: lines})
+		}
+
+		slices.SortFunc(detail.Owners, func(a, b FileOwner) int {
+			return cmp.Or(cmp.Compare(b.Lines, a.Lines), cmp.Compare(a.Author, b.Author))
+		})
+	}
+
+	if c, ok := idx.churn[path]; ok {
+		detail.Churn = &c
+	}
+
+	for _, c := range idx.couplings {
+		switch path {
+		case c.File1:
+			detail.Partners = append(detail.Partners, CouplingPartner{Path: c.File2, CoChanges: c.CoChanges, Strength: c.Strength})
+		case c.File2:
+			detail.Partners = append(detail.Partners, CouplingPartner{Path: c.File1, CoChanges: c.CoChanges, Strength: c.Strength})
+		}
+	}
+
+	slices.SortFunc(detail.Partners, func(a, b CouplingPartner) int {
+		return cmp.Or(cmp.Compare(b.Strength, a.Strength), cmp.Compare(a.Path, b.Path))
+	})
+
+	return detail, true
+}
+
+func (idx *FileIndex) authorName(id int) string {
+	if name, ok := idx.authors[id]; ok {
+		return name
+	}
+
+	return "#" + strconv.Itoa(id)
+}
+
+// RenderIndex writes a page listing every indexed file, with a search box.
+// href returns the link of a file's detail page.
+func (idx *FileIndex) RenderIndex(writer io.Writer, href func(path string) string) error {
+	return idx.renderIndex(writer, href, false)
+}
+
+func (idx *FileIndex) renderIndex(writer io.Writer, href func(path string) string, search bool) error {
+	table := plotpage.NewTable([]string{"File", "Lines", "Commits", "Functions"}).WithSearch("Filter files")
+
+	for _, path := range idx.paths {
+		commits := ""
+		if c, ok := idx.churn[path]; ok {
+			commits = strconv.Itoa(c.Commits)
+		}
+
+		table.AddRow(
+			fmt.Sprintf(`<a class="text-accent hover:underline" href="%s">%s</a>`,
+				template.HTMLEscapeString(href(path)), template.HTMLEscapeString(path)),
+			strconv.FormatInt(idx.survival[path].CurrentLines, 10),
+			commits,
+			strconv.Itoa(len(idx.functions[path])),
+		)
+	}
+
+	sections := []plotpage.Section{{Title: "Files", Subtitle: "Select a file to see its details", Chart: table}}
+
+	err := renderSitePage(writer, "Files", fmt.Sprintf("%d files", len(idx.paths)), sections, search)
+	if err != nil {
+		return fmt.Errorf("render file index: %w", err)
+	}
+
+	return nil
+}
+
+// renderSitePage renders a page of the file site, topped by the site search
+// box when search is set.
+func renderSitePage(writer io.Writer, title, description string, sections []plotpage.Section, search bool) error {
+	page := plotpage.NewPage(title, description)
+
+	if search {
+		page.Add(plotpage.Section{Title: "Search", Chart: searchBox{}})
+	}
+
+	page.Add(sections...)
+
+	return page.Render(writer)
+}
+
+// FilePageRoute is the route of file detail pages served by Handler.
+const FilePageRoute = "/file"
+
+// Handler serves the file site: the file index at /, the detail page of a
+// file at /file?path=<path>, the page of an author at /author?name=<name> and
+// the search index at /search.json. Pages are rendered per request and carry
+// a search box over files, authors and functions.
+func (idx *FileIndex) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		serveHTML(w, idx.renderIndex(w, FilePageHref, true))
+	})
+
+	mux.HandleFunc(FilePageRoute, func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Query().Get("path")
+		if !idx.has(path) {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		serveHTML(w, idx.renderFile(w, path, true))
+	})
+
+	mux.HandleFunc(AuthorPageRoute, func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if _, ok := idx.AuthorFiles(name); !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		serveHTML(w, idx.renderAuthor(w, name, true))
+	})
+
+	mux.HandleFunc(SearchIndexRoute, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		err := idx.WriteSearchIndex(w)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	return mux
+}
+
+// serveHTML reports a render error of an HTML response. The content type is
+// set by the first write.
+func serveHTML(w http.ResponseWriter, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// FilePageHref returns the link of the detail page of path served by Handler.
+func FilePageHref(path string) string {
+	return FilePageRoute + "?path=" + url.QueryEscape(path)
+}
+
+// RenderFile writes the detail page of path: burndown history, owners,
+// churn, coupling partners, function complexity and complexity trend, each
+// when the model has the report.
+func (idx *FileIndex) RenderFile(writer io.Writer, path string) error {
+	return idx.renderFile(writer, path, false)
+}
+
+func (idx *FileIndex) renderFile(writer io.Writer, path string, search bool) error {
+	detail, ok := idx.Detail(path)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFile, path)
+	}
+
+	err := renderSitePage(writer, path, "Per-file detail", fileSections(detail), search)
+	if err != nil {
+		return fmt.Errorf("render file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// RenderAuthor writes the page of author name: the files they own surviving
+// lines of.
+func (idx *FileIndex) RenderAuthor(writer io.Writer, name string) error {
+	return idx.renderAuthor(writer, name, false)
+}
+
+func (idx *FileIndex) renderAuthor(writer io.Writer, name string, search bool) error {
+	files, ok := idx.AuthorFiles(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownAuthor, name)
+	}
+
+	table := plotpage.NewTable([]string{"File", "Lines"}).WithSearch("Filter files")
+
+	for _, f := range files {
+		table.AddRow(
+			fmt.Sprintf(`<a class="text-accent hover:underline" href="%s">%s</a>`,
+				template.HTMLEscapeString(FilePageHref(f.Path)), template.HTMLEscapeString(f.Path)),
+			strconv.Itoa(f.Lines),
+		)
+	}
+
+	sections := []plotpage.Section{{Title: "Owned files", Subtitle: "Surviving lines (history/burndown)", Chart: table}}
+
+	err := renderSitePage(writer, name, "Author", sections, search)
+	if err != nil {
+		return fmt.Errorf("render author %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func fileSections(detail FileDetail) []plotpage.Section {
+	var sections []plotpage.Section
+
+	if len(detail.Burndown) > 0 {
+		sections = append(sections, plotpage.Section{
+			Title:    "Burndown history",
+			Subtitle: "Surviving lines per age band (history/burndown)",
+			Chart:    plotpage.WrapChart(burndownChart(detail.Burndown)),
+		})
+	}
+
+	if len(detail.Owners) > 0 {
+		table := plotpage.NewTable([]string{"Author", "Lines", "Share"})
+
+		for _, o := range detail.Owners {
+			share := 0.0
+			if detail.Lines > 0 {
+				share = float64(o.Lines) / float64(detail.Lines) * percentScale
+			}
+
+			table.AddRow(template.HTMLEscapeString(o.Author), strconv.Itoa(o.Lines), fmt.Sprintf("%.1f%%", share))
+		}
+
+		sections = append(sections, plotpage.Section{
+			Title:    "Owners",
+			Subtitle: fmt.Sprintf("%d surviving lines (history/burndown)", detail.Lines),
+			Chart:    table,
+		})
+	}
+
+	if detail.Churn != nil {
+		c := detail.Churn
+
+		sections = append(sections, plotpage.Section{
+			Title:    "Churn",
+			Subtitle: "history/file-history",
+			Chart: plotpage.NewGrid(statColumns,
+				plotpage.NewStat("Commits", strconv.Itoa(c.Commits)),
+				plotpage.NewStat("Contributors", strconv.Itoa(c.Contributors)),
+				plotpage.NewStat("Lines added", strconv.Itoa(c.Added)),
+				plotpage.NewStat("Lines removed", strconv.Itoa(c.Removed)),
+				plotpage.NewStat("Churn score", fmt.Sprintf("%.2f", c.Score)),
+			),
+		})
+	}
+
+	if len(detail.Partners) > 0 {
+		table := plotpage.NewTable([]string{"File", "Co-changes", "Strength"})
+
+		for _, p := range detail.Partners {
+			table.AddRow(template.HTMLEscapeString(p.Path), strconv.FormatInt(p.CoChanges, 10), fmt.Sprintf("%.2f", p.Strength))
+		}
+
+		sections = append(sections, plotpage.Section{
+			Title:    "Coupling partners",
+			Subtitle: "Files changed together with this one (history/couples)",
+			Chart:    table,
+		})
+	}
+
+	if len(detail.Functions) > 0 {
+		table := plotpage.NewTable([]string{"Function", "Cyclomatic", "Cognitive", "Lines", "Risk"})
+
+		for _, fn := range detail.Functions {
+			table.AddRow(
+				template.HTMLEscapeString(fn.Name),
+				strconv.Itoa(fn.Cyclomatic),
+				strconv.Itoa(fn.Cognitive),
+				strconv.Itoa(fn.Lines),
+				template.HTMLEscapeString(fn.Risk),
+			)
+		}
+
+		sections = append(sections, plotpage.Section{
+			Title:    "Complexity",
+			Subtitle: "Functions of this file (static/complexity)",
+			Chart:    table,
+		})
+	}
+
+	if len(detail.Trend) > 0 {
+		sections = append(sections, plotpage.Section{
+			Title:    "Complexity trend",
+			Subtitle: "Summed complexity of the functions of this file (history/complexity)",
+			Chart:    plotpage.WrapChart(complexityTrendChart(detail.Trend)),
+		})
+	}
+
+	if len(sections) == 0 {
+		sections = append(sections, plotpage.Section{
+			Title: "No details",
+			Chart: plotpage.NewText("No per-file data for this file in the report."),
+		})
+	}
+
+	return sections
+}
+
+// burndownChart stacks the bands of a file's burndown matrix over the samples.
+func burndownChart(history [][]int64) *charts.Line {
+	labels := make([]string, len(history))
+	bands := 0
+
+	for i, sample := range history {
+		labels[i] = strconv.Itoa(i)
+		bands = max(bands, len(sample))
+	}
+
+	series := make([]plotpage.LineSeries, bands)
+
+	for band := range bands {
+		data := make([]plotpage.SeriesData, len(history))
+
+		for i, sample := range history {
+			var lines int64
+			if band < len(sample) {
+				lines = max(sample[band], 0)
+			}
+
+			data[i] = lines
+		}
+
+		series[band] = plotpage.LineSeries{
+			Name: "Band " + strconv.Itoa(band), Data: data, Stack: "total", AreaOpacity: areaOpacity,
+		}
+	}
+
+	return plotpage.BuildLineChart(nil, labels, series, "Lines")
+}
+
+// complexityTrendChart draws a file's cyclomatic and cognitive complexity
+// over the ticks its functions changed in.
+func complexityTrendChart(points []ComplexityPoint) *charts.Line {
+	labels := make([]string, len(points))
+	cyclomatic := make([]plotpage.SeriesData, len(points))
+	cognitive := make([]plotpage.SeriesData, len(points))
+
+	for i, p := range points {
+		labels[i] = strconv.Itoa(p.Tick)
+		cyclomatic[i] = p.Complexity
+		cognitive[i] = p.Cognitive
+	}
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: "Cyclomatic", Data: cyclomatic},
+		{Name: "Cognitive", Data: cognitive},
+	}, "Complexity")
+}
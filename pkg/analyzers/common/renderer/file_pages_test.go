@@ -0,0 +1,213 @@
+package renderer
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func fileModel() UnifiedModel {
+	return NewUnifiedModel([]AnalyzerResult{
+		{
+			ID:   "static/complexity",
+			Mode: analyze.ModeStatic,
+			Report: analyze.Report{
+				"function_complexity": []any{
+					map[string]any{"name": "Pay", "file": "/src/repo/pkg/pay.go", "cyclomatic_complexity": 12.0, "risk_level": "HIGH"},
+					map[string]any{"name": "main", "file": "/src/repo/cmd/main.go", "cyclomatic_complexity": 1.0},
+				},
+			},
+		},
+		{
+			ID:   "history/burndown",
+			Mode: analyze.ModeHistory,
+			Report: analyze.Report{
+				"file_survival": []any{
+					map[string]any{
+						"path": "pkg/pay.go", "current_lines": 30.0, "ownership": map[string]any{"0": 20.0, "1": 10.0},
+						"history": []any{[]any{25.0, 0.0}, []any{18.0, 12.0}},
+					},
+				},
+				"developer_survival": []any{
+					map[string]any{"id": 0.0, "name": "alice"},
+				},
+			},
+		},
+		{
+			ID:   "history/file-history",
+			Mode: analyze.ModeHistory,
+			Report: analyze.Report{
+				"file_churn": []any{
+					map[string]any{"path": "pkg/pay.go", "commit_count": 7.0, "total_lines_added": 40.0},
+				},
+			},
+		},
+		{
+			ID:   "history/complexity",
+			Mode: analyze.ModeHistory,
+			Report: analyze.Report{
+				"file_trends": []any{
+					map[string]any{"file": "pkg/pay.go", "points": []any{
+						map[string]any{"tick": 2.0, "complexity": 9.0, "cognitive": 11.0},
+						map[string]any{"tick": 5.0, "complexity": 12.0, "cognitive": 15.0},
+					}},
+				},
+			},
+		},
+		{
+			ID:   "history/couples",
+			Mode: analyze.ModeHistory,
+			Report: analyze.Report{
+				"file_coupling": []any{
+					map[string]any{"file1": "pkg/pay.go", "file2": "pkg/bill.go", "co_changes": 3.0, "coupling_strength": 0.5},
+					map[string]any{"file1": "pkg/tax.go", "file2": "pkg/pay.go", "co_changes": 5.0, "coupling_strength": 0.8},
+				},
+			},
+		},
+	})
+}
+
+func TestFileIndex_Detail(t *testing.T) {
+	t.Parallel()
+
+	idx, err := NewFileIndex(fileModel())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/src/repo/cmd/main.go", "pkg/bill.go", "pkg/pay.go", "pkg/tax.go"}, idx.Paths())
+
+	detail, ok := idx.Detail("pkg/pay.go")
+	require.True(t, ok)
+
+	assert.Equal(t, int64(30), detail.Lines)
+	assert.Equal(t, [][]int64{{25, 0}, {18, 12}}, detail.Burndown)
+	assert.Equal(t, []ComplexityPoint{{Tick: 2, Complexity: 9, Cognitive: 11}, {Tick: 5, Complexity: 12, Cognitive: 15}},
+		detail.Trend)
+	assert.Equal(t, []FileOwner{{Author: "alice", Lines: 20}, {Author: "#1", Lines: 10}}, detail.Owners)
+	require.NotNil(t, detail.Churn)
+	assert.Equal(t, 7, detail.Churn.Commits)
+	assert.Equal(t, []CouplingPartner{
+		{Path: "pkg/tax.go", CoChanges: 5, Strength: 0.8},
+		{Path: "pkg/bill.go", CoChanges: 3, Strength: 0.5},
+	}, detail.Partners)
+	require.Len(t, detail.Functions, 1)
+	assert.Equal(t, "Pay", detail.Functions[0].Name)
+
+	_, ok = idx.Detail("missing.go")
+	assert.False(t, ok)
+}
+
+func TestFileIndex_RenderFile(t *testing.T) {
+	t.Parallel()
+
+	idx, err := NewFileIndex(fileModel())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, idx.RenderFile(&buf, "pkg/pay.go"))
+
+	html := buf.String()
+	for _, want := range []string{
+		"Burndown history", "Band 1", "Owners", "alice", "Churn", "Coupling partners", "pkg/tax.go",
+		"Complexity", "Pay", "Complexity trend", "Cognitive",
+	} {
+		assert.Contains(t, html, want)
+	}
+
+	buf.Reset()
+	require.NoError(t, idx.RenderFile(&buf, "pkg/tax.go"))
+	assert.NotContains(t, buf.String(), "Burndown history", "files without a matrix have no burndown section")
+	assert.NotContains(t, buf.String(), "Complexity trend")
+
+	require.ErrorIs(t, idx.RenderFile(&buf, "missing.go"), ErrUnknownFile)
+}
+
+func TestFileIndex_Handler(t *testing.T) {
+	t.Parallel()
+
+	idx, err := NewFileIndex(fileModel())
+	require.NoError(t, err)
+
+	index := httptest.NewRecorder()
+	idx.Handler().ServeHTTP(index, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, index.Code)
+	assert.Contains(t, index.Body.String(), `placeholder="Filter files"`)
+	assert.Contains(t, index.Body.String(), FilePageHref("pkg/pay.go"))
+
+	page := httptest.NewRecorder()
+	idx.Handler().ServeHTTP(page, httptest.NewRequest(http.MethodGet, FilePageHref("pkg/pay.go"), nil))
+	assert.Equal(t, http.StatusOK, page.Code)
+	assert.Contains(t, page.Body.String(), "Coupling partners")
+
+	missing := httptest.NewRecorder()
+	idx.Handler().ServeHTTP(missing, httptest.NewRequest(http.MethodGet, FilePageHref("missing.go"), nil))
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+}
+
+func TestSearchTerms(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		[]string{"go", "payment", "paymentservice.go", "pkg", "pkg/paymentservice.go", "service"},
+		searchTerms("pkg/PaymentService.go"))
+	assert.Equal(t,
+		[]string{"go", "payment", "payment_service.go", "service"},
+		searchTerms("payment_service.go"))
+}
+
+func TestFileIndex_SearchIndex(t *testing.T) {
+	t.Parallel()
+
+	idx, err := NewFileIndex(fileModel())
+	require.NoError(t, err)
+
+	si := idx.SearchIndex()
+
+	find := func(term string) []SearchDoc {
+		docs := make([]SearchDoc, 0, len(si.Terms[term]))
+		for _, id := range si.Terms[term] {
+			docs = append(docs, si.Docs[id])
+		}
+
+		return docs
+	}
+
+	assert.Equal(t, []SearchDoc{{Kind: SearchKindFile, Label: "pkg/pay.go", Href: FilePageHref("pkg/pay.go")}}, find("pay.go"))
+	assert.Equal(t, []SearchDoc{{Kind: SearchKindAuthor, Label: "alice", Href: AuthorPageHref("alice")}}, find("alice"))
+	assert.Contains(t, find("pay"), SearchDoc{Kind: SearchKindFunction, Label: "Pay (pkg/pay.go)", Href: FilePageHref("pkg/pay.go")})
+}
+
+func TestFileIndex_HandlerSearchAndAuthors(t *testing.T) {
+	t.Parallel()
+
+	idx, err := NewFileIndex(fileModel())
+	require.NoError(t, err)
+
+	handler := idx.Handler()
+
+	search := httptest.NewRecorder()
+	handler.ServeHTTP(search, httptest.NewRequest(http.MethodGet, SearchIndexRoute, nil))
+	assert.Equal(t, http.StatusOK, search.Code)
+	assert.Contains(t, search.Body.String(), `"alice"`)
+
+	author := httptest.NewRecorder()
+	handler.ServeHTTP(author, httptest.NewRequest(http.MethodGet, AuthorPageHref("alice"), nil))
+	assert.Equal(t, http.StatusOK, author.Code)
+	assert.Contains(t, author.Body.String(), "pkg/pay.go")
+	assert.Contains(t, author.Body.String(), `id="site-search"`)
+
+	unknown := httptest.NewRecorder()
+	handler.ServeHTTP(unknown, httptest.NewRequest(http.MethodGet, AuthorPageHref("bob"), nil))
+	assert.Equal(t, http.StatusNotFound, unknown.Code)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, idx.RenderFile(&buf, "pkg/pay.go"))
+	assert.NotContains(t, buf.String(), `id="site-search"`)
+}
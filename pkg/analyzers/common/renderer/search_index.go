@@ -0,0 +1,248 @@
+package renderer
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Kinds of search documents.
+const (
+	SearchKindFile     = "file"
+	SearchKindAuthor   = "author"
+	SearchKindFunction = "function"
+)
+
+// SearchIndexRoute is the route of the search index served by Handler.
+const SearchIndexRoute = "/search.json"
+
+// AuthorPageRoute is the route of author pages served by Handler.
+const AuthorPageRoute = "/author"
+
+// SearchDoc is one searchable entry of the file site.
+type SearchDoc struct {
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+	Href  string `json:"href"`
+}
+
+// SearchIndex is an inverted index of the file site in the style of lunr:
+// every term maps to the documents containing it. The browser matches query
+// words as term prefixes and intersects the document sets, so no search
+// request reaches the server.
+type SearchIndex struct {
+	Docs  []SearchDoc      `json:"docs"`
+	Terms map[string][]int `json:"terms"`
+}
+
+// AuthorFile is a file an author owns surviving lines of.
+type AuthorFile struct {
+	Path  string
+	Lines int
+}
+
+// SearchIndex builds the search index over the files, authors and functions
+// of idx.
+func (idx *FileIndex) SearchIndex() SearchIndex {
+	si := SearchIndex{Terms: make(map[string][]int)}
+
+	add := func(doc SearchDoc, text string) {
+		id := len(si.Docs)
+		si.Docs = append(si.Docs, doc)
+
+		for _, term := range searchTerms(text) {
+			si.Terms[term] = append(si.Terms[term], id)
+		}
+	}
+
+	for _, path := range idx.paths {
+		add(SearchDoc{Kind: SearchKindFile, Label: path, Href: FilePageHref(path)}, path)
+	}
+
+	for _, name := range idx.authorNames() {
+		add(SearchDoc{Kind: SearchKindAuthor, Label: name, Href: AuthorPageHref(name)}, name)
+	}
+
+	for _, path := range idx.paths {
+		for _, fn := range idx.functions[path] {
+			add(SearchDoc{Kind: SearchKindFunction, Label: fn.Name + " (" + path + ")", Href: FilePageHref(path)}, fn.Name)
+		}
+	}
+
+	return si
+}
+
+// searchTerms returns the lowercase terms of text: the whole text, its last
+// path element and every word, where words are split at non-alphanumerics and
+// lowercase-to-uppercase changes. "pkg/PaymentService.go" yields
+// "pkg/paymentservice.go", "paymentservice.go", "pkg", "payment", "service"
+// and "go".
+func searchTerms(text string) []string {
+	lower := strings.ToLower(text)
+	terms := map[string]bool{lower: true}
+
+	if i := strings.LastIndexByte(lower, '/'); i >= 0 && i < len(lower)-1 {
+		terms[lower[i+1:]] = true
+	}
+
+	var word []rune
+
+	flush := func() {
+		if len(word) > 0 {
+			terms[strings.ToLower(string(word))] = true
+			word = word[:0]
+		}
+	}
+
+	prev := rune(0)
+
+	for _, r := range text {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush()
+
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+
+		prev = r
+	}
+
+	flush()
+	delete(terms, "")
+
+	return slices.Sorted(maps.Keys(terms))
+}
+
+// authorNames returns the names of the authors owning lines of any file.
+func (idx *FileIndex) authorNames() []string {
+	names := make(map[string]bool)
+
+	for _, s := range idx.survival {
+		for id := range s.Ownership {
+			names[idx.authorName(id)] = true
+		}
+	}
+
+	return slices.Sorted(maps.Keys(names))
+}
+
+// AuthorFiles returns the files name owns surviving lines of, most lines
+// first. The second result is false when name owns no lines.
+func (idx *FileIndex) AuthorFiles(name string) ([]AuthorFile, bool) {
+	var files []AuthorFile
+
+	for path, s := range idx.survival {
+		for id, lines := range s.Ownership {
+			if idx.authorName(id) == name {
+				files = append(files, AuthorFile{Path: path, Lines: lines})
+			}
+		}
+	}
+
+	slices.SortFunc(files, func(a, b AuthorFile) int {
+		return cmp.Or(cmp.Compare(b.Lines, a.Lines), cmp.Compare(a.Path, b.Path))
+	})
+
+	return files, len(files) > 0
+}
+
+// AuthorPageHref returns the link of the page of author name served by Handler.
+func AuthorPageHref(name string) string {
+	return AuthorPageRoute + "?name=" + url.QueryEscape(name)
+}
+
+// searchBox is a search field over the index served at SearchIndexRoute.
+// The index is fetched on first use.
+type searchBox struct{}
+
+// Render writes the search field, its result list and the matching script.
+func (searchBox) Render(w io.Writer) error {
+	_, err := io.WriteString(w, searchBoxHTML)
+	if err != nil {
+		return fmt.Errorf("writing search box: %w", err)
+	}
+
+	return nil
+}
+
+// WriteSearchIndex writes the search index of idx as JSON.
+func (idx *FileIndex) WriteSearchIndex(w io.Writer) error {
+	err := json.NewEncoder(w).Encode(idx.SearchIndex())
+	if err != nil {
+		return fmt.Errorf("encode search index: %w", err)
+	}
+
+	return nil
+}
+
+const searchBoxHTML = `<div>
+    <input type="search" id="site-search" placeholder="Search files, authors and functions" autocomplete="off"
+        class="w-full rounded-md border border-stone-200 bg-white px-3 py-2 text-sm text-stone-700 dark:border-stone-700 dark:bg-stone-900 dark:text-stone-300">
+    <ul id="site-search-results" class="mt-2 text-sm"></ul>
+</div>
+<script>
+    (function () {
+        const input = document.getElementById("site-search");
+        const list = document.getElementById("site-search-results");
+        const maxResults = 50;
+        let index = null;
+
+        function load() {
+            if (index) return Promise.resolve(index);
+            return fetch("` + SearchIndexRoute + `")
+                .then((r) => r.json())
+                .then((data) => {
+                    data.keys = Object.keys(data.terms);
+                    index = data;
+                    return index;
+                });
+        }
+
+        function matches(idx, word) {
+            const docs = new Set();
+            idx.keys.forEach((term) => {
+                if (term.startsWith(word)) idx.terms[term].forEach((d) => docs.add(d));
+            });
+            return docs;
+        }
+
+        function search(idx, query) {
+            let result = null;
+            query.toLowerCase().split(/\s+/).filter(Boolean).forEach((word) => {
+                const docs = matches(idx, word);
+                result = result === null ? docs : new Set([...result].filter((d) => docs.has(d)));
+            });
+            return result === null ? [] : [...result];
+        }
+
+        input.addEventListener("input", () => {
+            load().then((idx) => {
+                list.replaceChildren();
+                search(idx, input.value).slice(0, maxResults).forEach((id) => {
+                    const doc = idx.docs[id];
+                    const item = document.createElement("li");
+                    const link = document.createElement("a");
+                    link.href = doc.href;
+                    link.textContent = doc.label;
+                    link.className = "text-accent hover:underline";
+                    const kind = document.createElement("span");
+                    kind.textContent = " " + doc.kind;
+                    kind.className = "text-stone-500";
+                    item.append(link, kind);
+                    list.append(item);
+                });
+            });
+        });
+    })();
+</script>
+`
@@ -0,0 +1,137 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Names of the summary metrics.
+const (
+	SummaryMetricCommits           = "codefang_commits_analyzed"
+	SummaryMetricHotspots          = "codefang_hotspots"
+	SummaryMetricBusFactorMin      = "codefang_bus_factor_min"
+	SummaryMetricAverageComplexity = "codefang_average_complexity"
+)
+
+// SummaryMetric is one headline number of a report.
+type SummaryMetric struct {
+	Name  string
+	Help  string
+	Value float64
+}
+
+type devsSummary struct {
+	Aggregate struct {
+		TotalCommits     int `json:"total_commits"`
+		ProjectBusFactor int `json:"project_bus_factor"`
+	} `json:"aggregate"`
+	BusFactor []struct {
+		BusFactor int `json:"bus_factor"`
+	} `json:"busfactor"`
+}
+
+type fileHistorySummary struct {
+	Aggregate struct {
+		TotalCommits int `json:"total_commits"`
+	} `json:"aggregate"`
+	Hotspots []struct{} `json:"hotspots"`
+}
+
+type complexitySummary struct {
+	Aggregate struct {
+		AverageComplexity float64 `json:"average_complexity"`
+	} `json:"aggregate"`
+}
+
+// SummaryMetrics returns the headline numbers of model: commits analyzed,
+// hotspot count, the lowest bus factor and the average complexity. A number
+// is left out when the model lacks the report it comes from.
+func SummaryMetrics(model UnifiedModel) ([]SummaryMetric, error) {
+	var (
+		metrics []SummaryMetric
+		commits = len(model.Commits)
+	)
+
+	for _, result := range model.Analyzers {
+		switch result.ID {
+		case devsID:
+			var devs devsSummary
+
+			err := decodeSummary(result, &devs)
+			if err != nil {
+				return nil, err
+			}
+
+			if commits == 0 {
+				commits = devs.Aggregate.TotalCommits
+			}
+
+			minBusFactor := devs.Aggregate.ProjectBusFactor
+			for _, bf := range devs.BusFactor {
+				minBusFactor = min(minBusFactor, bf.BusFactor)
+			}
+
+			metrics = append(metrics, SummaryMetric{
+				Name:  SummaryMetricBusFactorMin,
+				Help:  "Lowest bus factor of the project and its languages (history/devs).",
+				Value: float64(minBusFactor),
+			})
+		case fileHistoryID:
+			var history fileHistorySummary
+
+			err := decodeSummary(result, &history)
+			if err != nil {
+				return nil, err
+			}
+
+			if commits == 0 {
+				commits = history.Aggregate.TotalCommits
+			}
+
+			metrics = append(metrics, SummaryMetric{
+				Name:  SummaryMetricHotspots,
+				Help:  "Files changed often enough to be medium, high or critical risk (history/file-history).",
+				Value: float64(len(history.Hotspots)),
+			})
+		case complexityID:
+			var complexity complexitySummary
+
+			err := decodeSummary(result, &complexity)
+			if err != nil {
+				return nil, err
+			}
+
+			metrics = append(metrics, SummaryMetric{
+				Name:  SummaryMetricAverageComplexity,
+				Help:  "Average cyclomatic complexity of all functions (static/complexity).",
+				Value: complexity.Aggregate.AverageComplexity,
+			})
+		}
+	}
+
+	if commits > 0 {
+		metrics = append([]SummaryMetric{{
+			Name:  SummaryMetricCommits,
+			Help:  "Commits analyzed by the history pipeline.",
+			Value: float64(commits),
+		}}, metrics...)
+	}
+
+	return metrics, nil
+}
+
+// decodeSummary decodes the report of result into dst through JSON, keeping
+// only the sections dst declares.
+func decodeSummary(result AnalyzerResult, dst any) error {
+	data, err := json.Marshal(result.Report)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", result.ID, err)
+	}
+
+	err = json.Unmarshal(data, dst)
+	if err != nil {
+		return fmt.Errorf("summarize %s: %w", result.ID, err)
+	}
+
+	return nil
+}
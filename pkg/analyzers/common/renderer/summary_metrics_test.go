@@ -0,0 +1,56 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestSummaryMetrics(t *testing.T) {
+	t.Parallel()
+
+	model := NewUnifiedModel([]AnalyzerResult{
+		{
+			ID:     "static/complexity",
+			Mode:   analyze.ModeStatic,
+			Report: analyze.Report{"aggregate": map[string]any{"average_complexity": 4.5}},
+		},
+		{
+			ID:   "history/devs",
+			Mode: analyze.ModeHistory,
+			Report: analyze.Report{
+				"aggregate": map[string]any{"total_commits": 120.0, "project_bus_factor": 3.0},
+				"busfactor": []any{map[string]any{"language": "Go", "bus_factor": 2.0}},
+			},
+		},
+		{
+			ID:   "history/file-history",
+			Mode: analyze.ModeHistory,
+			Report: analyze.Report{
+				"hotspots": []any{map[string]any{"path": "a.go"}, map[string]any{"path": "b.go"}},
+			},
+		},
+	})
+
+	metrics, err := SummaryMetrics(model)
+	require.NoError(t, err)
+
+	values := make(map[string]float64, len(metrics))
+	for _, m := range metrics {
+		values[m.Name] = m.Value
+	}
+
+	assert.Equal(t, map[string]float64{
+		SummaryMetricCommits:           120,
+		SummaryMetricHotspots:          2,
+		SummaryMetricBusFactorMin:      2,
+		SummaryMetricAverageComplexity: 4.5,
+	}, values)
+
+	metrics, err = SummaryMetrics(NewUnifiedModel(nil))
+	require.NoError(t, err)
+	assert.Empty(t, metrics)
+}
@@ -0,0 +1,377 @@
+package spillstore
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
+)
+
+// RunStore spills int-keyed state as sorted runs so the runs can later be
+// merged in key order one entry at a time.
+//
+// Each Spill writes the given entries to a numbered gob file in ascending key
+// order. Merge performs a k-way merge over all runs plus an in-memory map and
+// hands every key to a callback exactly once, so at most one value per run is
+// held in memory while merging.
+type RunStore[V any] struct {
+	parent  string      // parent for the temp directory; empty means the system default.
+	dir     string      // temp directory; created lazily on first Spill.
+	runN    int         // number of run files written.
+	codec   codec.Codec // compresses run files; nil writes them uncompressed.
+	written int64       // bytes of run files written, including merged ones.
+}
+
+// runEntry is one key/value pair of a run file.
+type runEntry[V any] struct {
+	Key int
+	Val V
+}
+
+// NewRuns creates a RunStore whose temp directory is created inside parent,
+// or the system temp directory when parent is empty.
+func NewRuns[V any](parent string) *RunStore[V] {
+	return &RunStore[V]{parent: parent}
+}
+
+// Spill writes entries as a new sorted run. No-op if entries is empty.
+func (s *RunStore[V]) Spill(entries map[int]V) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if s.dir == "" {
+		dir, err := os.MkdirTemp(s.parent, DirPattern)
+		if err != nil {
+			return fmt.Errorf("spillstore: create temp dir: %w", err)
+		}
+
+		s.dir = dir
+	}
+
+	f, err := os.Create(s.runPath(s.runN))
+	if err != nil {
+		return fmt.Errorf("spillstore: create run file: %w", err)
+	}
+
+	err = writeRun(f, s.codec, entries)
+	size := fileSize(f)
+
+	closeErr := f.Close()
+
+	if err != nil {
+		return fmt.Errorf("spillstore: encode run %d: %w", s.runN, err)
+	}
+
+	if closeErr != nil {
+		return fmt.Errorf("spillstore: close run %d: %w", s.runN, closeErr)
+	}
+
+	s.runN++
+	s.written += size
+
+	return nil
+}
+
+func writeRun[V any](w io.Writer, c codec.Codec, entries map[int]V) error {
+	cw, err := codec.OrNone(c).NewWriter(w)
+	if err != nil {
+		return err
+	}
+
+	enc := gob.NewEncoder(cw)
+
+	keys := make([]int, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		err = enc.Encode(runEntry[V]{Key: k, Val: entries[k]})
+		if err != nil {
+			cw.Close()
+
+			return err
+		}
+	}
+
+	return cw.Close()
+}
+
+// Merge visits every key found in the runs or in current in ascending order.
+// Values of the same key are combined with merge(existing, incoming), oldest
+// run first and current last. Run files are removed once all of them have
+// been read; fn errors stop the merge and leave the runs in place.
+func (s *RunStore[V]) Merge(current map[int]V, merge func(existing, incoming V) V, fn func(key int, val V) error) error {
+	sources := make([]runSource[V], 0, s.runN+1)
+
+	defer func() {
+		for i := range sources {
+			sources[i].close()
+		}
+	}()
+
+	for i := range s.runN {
+		f, err := os.Open(s.runPath(i))
+		if err != nil {
+			return fmt.Errorf("spillstore: open run %d: %w", i, err)
+		}
+
+		r, err := codec.OrNone(s.codec).NewReader(f)
+		if err != nil {
+			f.Close()
+
+			return fmt.Errorf("spillstore: open run %d: %w", i, err)
+		}
+
+		sources = append(sources, runSource[V]{file: f, reader: r, dec: gob.NewDecoder(r)})
+	}
+
+	sources = append(sources, memorySource(current))
+
+	err := mergeSources(sources, merge, fn)
+	if err != nil {
+		return err
+	}
+
+	s.Cleanup()
+	s.runN = 0
+
+	return nil
+}
+
+// Collect merges all runs and current into one map.
+func (s *RunStore[V]) Collect(current map[int]V, merge func(existing, incoming V) V) (map[int]V, error) {
+	result := make(map[int]V, len(current))
+
+	err := s.Merge(current, merge, func(key int, val V) error {
+		result[key] = val
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SetCodec selects the codec compressing run files. Must be called before
+// the first Spill, or after RestoreFromDir with the codec the restored runs
+// were written with.
+func (s *RunStore[V]) SetCodec(c codec.Codec) {
+	s.codec = c
+}
+
+// Codec returns the codec name of the run files, or "" when they are
+// uncompressed. Safe to call on a nil receiver (returns "").
+func (s *RunStore[V]) Codec() string {
+	if s == nil {
+		return ""
+	}
+
+	return codec.NameOf(s.codec)
+}
+
+// BytesWritten returns the total size of all run files written, including
+// runs already merged and removed. Safe to call on a nil receiver.
+func (s *RunStore[V]) BytesWritten() int64 {
+	if s == nil {
+		return 0
+	}
+
+	return s.written
+}
+
+// SpillCount returns the number of run files written.
+// Safe to call on a nil receiver (returns 0).
+func (s *RunStore[V]) SpillCount() int {
+	if s == nil {
+		return 0
+	}
+
+	return s.runN
+}
+
+// SpillDir returns the temp directory path, or empty if no spills occurred.
+// Safe to call on a nil receiver (returns "").
+func (s *RunStore[V]) SpillDir() string {
+	if s == nil {
+		return ""
+	}
+
+	return s.dir
+}
+
+// RestoreFromDir points the store at an existing spill directory with the
+// given number of run files. Used for checkpoint restoration.
+func (s *RunStore[V]) RestoreFromDir(dir string, count int) {
+	s.dir = dir
+	s.runN = count
+}
+
+// Cleanup removes the temp directory. Safe to call multiple times.
+func (s *RunStore[V]) Cleanup() {
+	if s.dir != "" {
+		os.RemoveAll(s.dir)
+		s.dir = ""
+	}
+}
+
+func (s *RunStore[V]) runPath(index int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("run_%03d.gob", index))
+}
+
+// runSource yields the entries of one run in key order. A source without a
+// decoder replays an in-memory slice instead.
+type runSource[V any] struct {
+	file   *os.File
+	reader io.ReadCloser
+	dec    *gob.Decoder
+	mem    []runEntry[V]
+}
+
+func memorySource[V any](current map[int]V) runSource[V] {
+	mem := make([]runEntry[V], 0, len(current))
+	for k, v := range current {
+		mem = append(mem, runEntry[V]{Key: k, Val: v})
+	}
+
+	slices.SortFunc(mem, func(a, b runEntry[V]) int { return a.Key - b.Key })
+
+	return runSource[V]{mem: mem}
+}
+
+// next returns the following entry, or ok=false at the end of the run.
+func (r *runSource[V]) next() (entry runEntry[V], ok bool, err error) {
+	if r.dec == nil {
+		if len(r.mem) == 0 {
+			return entry, false, nil
+		}
+
+		entry, r.mem = r.mem[0], r.mem[1:]
+
+		return entry, true, nil
+	}
+
+	err = r.dec.Decode(&entry)
+	if errors.Is(err, io.EOF) {
+		return entry, false, nil
+	}
+
+	if err != nil {
+		return entry, false, fmt.Errorf("spillstore: decode run: %w", err)
+	}
+
+	return entry, true, nil
+}
+
+func (r *runSource[V]) close() {
+	if r.reader != nil {
+		r.reader.Close()
+		r.reader = nil
+	}
+
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+// mergeSources runs the k-way merge. Ties are resolved by source order so
+// merge sees values oldest first.
+func mergeSources[V any](sources []runSource[V], merge func(V, V) V, fn func(int, V) error) error {
+	h := &runHeap[V]{}
+
+	for i := range sources {
+		err := h.pushNext(sources, i)
+		if err != nil {
+			return err
+		}
+	}
+
+	for h.Len() > 0 {
+		head := heap.Pop(h).(runHead[V]) //nolint:forcetypeassert // runHeap only holds runHead.
+		key, val := head.entry.Key, head.entry.Val
+
+		err := h.pushNext(sources, head.source)
+		if err != nil {
+			return err
+		}
+
+		for h.Len() > 0 && (*h)[0].entry.Key == key {
+			dup := heap.Pop(h).(runHead[V]) //nolint:forcetypeassert // runHeap only holds runHead.
+			val = mergeValues(val, dup.entry.Val, merge)
+
+			err = h.pushNext(sources, dup.source)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = fn(key, val)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mergeValues[V any](existing, incoming V, merge func(V, V) V) V {
+	if merge == nil {
+		return incoming
+	}
+
+	return merge(existing, incoming)
+}
+
+// runHead is the current entry of one source inside the merge heap.
+type runHead[V any] struct {
+	entry  runEntry[V]
+	source int
+}
+
+type runHeap[V any] []runHead[V]
+
+func (h runHeap[V]) Len() int { return len(h) }
+
+func (h runHeap[V]) Less(i, j int) bool {
+	if h[i].entry.Key != h[j].entry.Key {
+		return h[i].entry.Key < h[j].entry.Key
+	}
+
+	return h[i].source < h[j].source
+}
+
+func (h runHeap[V]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *runHeap[V]) Push(x any) { *h = append(*h, x.(runHead[V])) } //nolint:forcetypeassert // heap.Interface.
+
+func (h *runHeap[V]) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+
+	return item
+}
+
+func (h *runHeap[V]) pushNext(sources []runSource[V], i int) error {
+	entry, ok, err := sources[i].next()
+	if err != nil || !ok {
+		return err
+	}
+
+	heap.Push(h, runHead[V]{entry: entry, source: i})
+
+	return nil
+}
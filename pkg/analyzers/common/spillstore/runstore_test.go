@@ -0,0 +1,145 @@
+package spillstore_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/spillstore"
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
+)
+
+func sum(existing, incoming int) int { return existing + incoming }
+
+func TestRunStore_MergeInKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	s := spillstore.NewRuns[int](t.TempDir())
+
+	require.NoError(t, s.Spill(map[int]int{5: 1, 1: 10, 3: 100}))
+	require.NoError(t, s.Spill(map[int]int{3: 2, 9: 7}))
+	assert.Equal(t, 2, s.SpillCount())
+
+	var keys, vals []int
+
+	err := s.Merge(map[int]int{1: 5, 2: 4}, sum, func(key, val int) error {
+		keys = append(keys, key)
+		vals = append(vals, val)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 2, 3, 5, 9}, keys)
+	assert.Equal(t, []int{15, 4, 102, 1, 7}, vals)
+	assert.Zero(t, s.SpillCount())
+	assert.Empty(t, s.SpillDir()) // Cleaned up.
+}
+
+func TestRunStore_MergeOrderOldestFirst(t *testing.T) {
+	t.Parallel()
+
+	s := spillstore.NewRuns[string]("")
+
+	require.NoError(t, s.Spill(map[int]string{1: "a"}))
+	require.NoError(t, s.Spill(map[int]string{1: "b"}))
+
+	collected, err := s.Collect(map[int]string{1: "c"}, func(existing, incoming string) string {
+		return existing + incoming
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{1: "abc"}, collected)
+}
+
+func TestRunStore_CallbackErrorKeepsRuns(t *testing.T) {
+	t.Parallel()
+
+	s := spillstore.NewRuns[int]("")
+	defer s.Cleanup()
+
+	require.NoError(t, s.Spill(map[int]int{1: 1, 2: 2}))
+
+	errStop := errors.New("stop")
+
+	err := s.Merge(nil, sum, func(int, int) error { return errStop })
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, s.SpillCount())
+	assert.NotEmpty(t, s.SpillDir())
+}
+
+func TestRunStore_Codecs(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := codec.Parse(name)
+			require.NoError(t, err)
+
+			s := spillstore.NewRuns[int](t.TempDir())
+			s.SetCodec(c)
+			assert.Equal(t, name, s.Codec())
+
+			require.NoError(t, s.Spill(map[int]int{1: 1, 2: 2}))
+			require.NoError(t, s.Spill(map[int]int{2: 3}))
+
+			// A store restored from the same files reads them with the same codec.
+			restored := spillstore.NewRuns[int]("")
+			restored.RestoreFromDir(s.SpillDir(), s.SpillCount())
+			restored.SetCodec(c)
+
+			collected, err := restored.Collect(map[int]int{3: 4}, sum)
+			require.NoError(t, err)
+			assert.Equal(t, map[int]int{1: 1, 2: 5, 3: 4}, collected)
+		})
+	}
+}
+
+// tickFiles resembles the per-tick state of a file-keyed aggregator.
+type tickFiles struct {
+	Files   map[string]int
+	Authors []string
+}
+
+func BenchmarkRunStore_SpillMerge(b *testing.B) {
+	entries := make(map[int]tickFiles, 200)
+
+	for tick := range 200 {
+		st := tickFiles{Files: make(map[string]int)}
+
+		for i := range 50 {
+			st.Files[fmt.Sprintf("pkg/analyzers/module_%02d/file_%03d.go", i%10, i)] = tick + i
+			st.Authors = append(st.Authors, fmt.Sprintf("developer-%d@example.com", i%7))
+		}
+
+		entries[tick] = st
+	}
+
+	for _, name := range codec.Names() {
+		c, err := codec.Parse(name)
+		require.NoError(b, err)
+
+		b.Run(name, func(b *testing.B) {
+			dir := b.TempDir()
+
+			for b.Loop() {
+				s := spillstore.NewRuns[tickFiles](dir)
+				s.SetCodec(c)
+
+				spillErr := s.Spill(entries)
+				if spillErr != nil {
+					b.Fatal(spillErr)
+				}
+
+				mergeErr := s.Merge(nil, nil, func(int, tickFiles) error { return nil })
+				if mergeErr != nil {
+					b.Fatal(mergeErr)
+				}
+			}
+		})
+	}
+}
@@ -5,12 +5,20 @@ package spillstore
 
 import (
 	"encoding/gob"
+	"errors"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"path/filepath"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
 )
 
+// DirPattern is the os.MkdirTemp pattern of spill directories, which a crashed
+// run leaves behind.
+const DirPattern = "codefang-spill-*"
+
 // SpillStore wraps a map[string]V with transparent disk spilling.
 //
 // During normal (non-streaming) execution, it behaves as a plain map.
@@ -19,8 +27,10 @@ import (
 // Collect() merges all spilled files and the current buffer into one map.
 type SpillStore[V any] struct {
 	current map[string]V
-	dir     string // temp directory; created lazily on first Spill.
-	spillN  int    // number of spill files written.
+	dir     string      // temp directory; created lazily on first Spill.
+	spillN  int         // number of spill files written.
+	codec   codec.Codec // compresses spill files; nil writes them uncompressed.
+	written int64       // bytes of spill files written, including collected ones.
 }
 
 // New creates a SpillStore with an empty in-memory buffer.
@@ -66,7 +76,7 @@ func (s *SpillStore[V]) Spill() error {
 	}
 
 	if s.dir == "" {
-		dir, err := os.MkdirTemp("", "codefang-spill-*")
+		dir, err := os.MkdirTemp("", DirPattern)
 		if err != nil {
 			return fmt.Errorf("spillstore: create temp dir: %w", err)
 		}
@@ -81,7 +91,8 @@ func (s *SpillStore[V]) Spill() error {
 		return fmt.Errorf("spillstore: create spill file: %w", err)
 	}
 
-	err = gob.NewEncoder(f).Encode(s.current)
+	err = encodeFile(f, s.codec, s.current)
+	size := fileSize(f)
 
 	closeErr := f.Close()
 
@@ -94,6 +105,7 @@ func (s *SpillStore[V]) Spill() error {
 	}
 
 	s.spillN++
+	s.written += size
 	s.current = make(map[string]V)
 
 	return nil
@@ -140,6 +152,33 @@ func (s *SpillStore[V]) SpillCount() int {
 	return s.spillN
 }
 
+// SetCodec selects the codec compressing spill files. Must be called before
+// the first Spill, or after RestoreFromDir with the codec the restored files
+// were written with.
+func (s *SpillStore[V]) SetCodec(c codec.Codec) {
+	s.codec = c
+}
+
+// Codec returns the codec name of the spill files, or "" when they are
+// uncompressed. Safe to call on a nil receiver (returns "").
+func (s *SpillStore[V]) Codec() string {
+	if s == nil {
+		return ""
+	}
+
+	return codec.NameOf(s.codec)
+}
+
+// BytesWritten returns the total size of all spill files written, including
+// files already collected and removed. Safe to call on a nil receiver.
+func (s *SpillStore[V]) BytesWritten() int64 {
+	if s == nil {
+		return 0
+	}
+
+	return s.written
+}
+
 // SpillDir returns the temp directory path, or empty if no spills occurred.
 // Safe to call on a nil receiver (returns "").
 func (s *SpillStore[V]) SpillDir() string {
@@ -181,7 +220,7 @@ func (s *SpillStore[V]) readSpillFile(index int) (map[string]V, error) {
 
 	var chunk map[string]V
 
-	err = gob.NewDecoder(f).Decode(&chunk)
+	err = decodeFile(f, s.codec, &chunk)
 	if err != nil {
 		return nil, fmt.Errorf("spillstore: decode spill %d: %w", index, err)
 	}
@@ -189,6 +228,40 @@ func (s *SpillStore[V]) readSpillFile(index int) (map[string]V, error) {
 	return chunk, nil
 }
 
+// encodeFile gob-encodes v into w through c.
+func encodeFile(w io.Writer, c codec.Codec, v any) error {
+	cw, err := codec.OrNone(c).NewWriter(w)
+	if err != nil {
+		return err
+	}
+
+	err = gob.NewEncoder(cw).Encode(v)
+
+	return errors.Join(err, cw.Close())
+}
+
+// fileSize returns the current size of f, or 0 when it cannot be read.
+func fileSize(f *os.File) int64 {
+	info, err := f.Stat()
+	if err != nil {
+		return 0
+	}
+
+	return info.Size()
+}
+
+// decodeFile gob-decodes one value from r through c.
+func decodeFile(r io.Reader, c codec.Codec, v any) error {
+	cr, err := codec.OrNone(c).NewReader(r)
+	if err != nil {
+		return err
+	}
+
+	defer cr.Close()
+
+	return gob.NewDecoder(cr).Decode(v)
+}
+
 func mergeInto[V any](dst, src map[string]V, merge func(V, V) V) {
 	if merge == nil {
 		maps.Copy(dst, src)
@@ -211,6 +284,8 @@ type SliceSpillStore[V any] struct {
 	current []V
 	dir     string
 	spillN  int
+	codec   codec.Codec
+	written int64
 }
 
 // NewSlice creates a SliceSpillStore with an empty buffer.
@@ -241,7 +316,7 @@ func (s *SliceSpillStore[V]) Spill() error {
 	}
 
 	if s.dir == "" {
-		dir, err := os.MkdirTemp("", "codefang-spill-*")
+		dir, err := os.MkdirTemp("", DirPattern)
 		if err != nil {
 			return fmt.Errorf("spillstore: create temp dir: %w", err)
 		}
@@ -256,7 +331,8 @@ func (s *SliceSpillStore[V]) Spill() error {
 		return fmt.Errorf("spillstore: create spill file: %w", err)
 	}
 
-	err = gob.NewEncoder(f).Encode(s.current)
+	err = encodeFile(f, s.codec, s.current)
+	size := fileSize(f)
 
 	closeErr := f.Close()
 
@@ -269,6 +345,7 @@ func (s *SliceSpillStore[V]) Spill() error {
 	}
 
 	s.spillN++
+	s.written += size
 	s.current = nil
 
 	return nil
@@ -307,6 +384,22 @@ func (s *SliceSpillStore[V]) SpillCount() int {
 	return s.spillN
 }
 
+// SetCodec selects the codec compressing spill files. Must be called before
+// the first Spill.
+func (s *SliceSpillStore[V]) SetCodec(c codec.Codec) {
+	s.codec = c
+}
+
+// BytesWritten returns the total size of all spill files written, including
+// files already collected and removed. Safe to call on a nil receiver.
+func (s *SliceSpillStore[V]) BytesWritten() int64 {
+	if s == nil {
+		return 0
+	}
+
+	return s.written
+}
+
 // Cleanup removes the temp directory. Safe to call multiple times.
 func (s *SliceSpillStore[V]) Cleanup() {
 	if s.dir != "" {
@@ -327,7 +420,7 @@ func (s *SliceSpillStore[V]) readSpillFile(index int) ([]V, error) {
 
 	var chunk []V
 
-	err = gob.NewDecoder(f).Decode(&chunk)
+	err = decodeFile(f, s.codec, &chunk)
 	if err != nil {
 		return nil, fmt.Errorf("spillstore: decode spill %d: %w", index, err)
 	}
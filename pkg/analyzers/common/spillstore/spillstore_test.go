@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/spillstore"
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
 )
 
 func TestSpillStore_NoSpill(t *testing.T) {
@@ -243,3 +244,62 @@ func TestSpillStore_PointerValues(t *testing.T) {
 	assert.Equal(t, &testStruct{Name: "hello", Value: 42}, collected["x"])
 	assert.Equal(t, &testStruct{Name: "world", Value: 99}, collected["y"])
 }
+
+func TestSpillStore_Codec(t *testing.T) {
+	t.Parallel()
+
+	c, err := codec.Parse(codec.Zstd)
+	require.NoError(t, err)
+
+	s := spillstore.New[int]()
+	s.SetCodec(c)
+	assert.Equal(t, codec.Zstd, s.Codec())
+
+	s.Put("a", 1)
+	require.NoError(t, s.Spill())
+	s.Put("b", 2)
+	require.NoError(t, s.Spill())
+
+	collected, err := s.Collect()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, collected)
+}
+
+func TestSpillStore_BytesWritten(t *testing.T) {
+	t.Parallel()
+
+	s := spillstore.New[int]()
+	assert.Zero(t, s.BytesWritten())
+
+	s.Put("a", 1)
+	require.NoError(t, s.Spill())
+
+	first := s.BytesWritten()
+	assert.Positive(t, first)
+
+	s.Put("b", 2)
+	require.NoError(t, s.Spill())
+	assert.Greater(t, s.BytesWritten(), first)
+
+	// Cleanup removes the files but not the count of bytes written.
+	s.Cleanup()
+	assert.Greater(t, s.BytesWritten(), first)
+}
+
+func TestSliceSpillStore_Codec(t *testing.T) {
+	t.Parallel()
+
+	c, err := codec.Parse(codec.Snappy)
+	require.NoError(t, err)
+
+	s := spillstore.NewSlice[string]()
+	s.SetCodec(c)
+
+	s.Append("a", "b")
+	require.NoError(t, s.Spill())
+	s.Append("c")
+
+	collected, err := s.Collect()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, collected)
+}
@@ -0,0 +1,444 @@
+package complexity
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// Report keys of the history analyzer.
+const (
+	KeyFunctionHistory = "function_history"
+	KeyTickSize        = "tick_size"
+
+	// functionTickSize estimates the bytes of one FunctionTick held by the
+	// aggregator, with its key.
+	functionTickSize = 160
+)
+
+// FunctionKey identifies a function by its file and its name.
+type FunctionKey struct {
+	File string
+	Name string
+}
+
+// FunctionChange is the complexity of one function before and after a
+// commit. Before is zero for added functions and After for deleted ones.
+type FunctionChange struct {
+	FunctionKey
+
+	Before          int
+	After           int
+	CognitiveBefore int
+	CognitiveAfter  int
+	Added           bool
+	Deleted         bool
+}
+
+// CommitData is the per-commit payload stored in analyze.TC.Data: the
+// functions the commit added, deleted or changed the complexity of.
+type CommitData struct {
+	Changes []FunctionChange
+}
+
+// FunctionTick is the complexity of one function over the commits of a
+// tick: before the earliest change and after the latest one.
+type FunctionTick struct {
+	Start          int
+	End            int
+	CognitiveStart int
+	CognitiveEnd   int
+	// Added is set when the earliest change added the function, Deleted when
+	// the latest one deleted it.
+	Added   bool
+	Deleted bool
+	Commits int
+
+	first time.Time
+	last  time.Time
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	Functions map[FunctionKey]*FunctionTick
+}
+
+// TickComplexity is the complexity of a function at the end of a tick.
+type TickComplexity struct {
+	Tick       int `json:"tick"       yaml:"tick"`
+	Complexity int `json:"complexity" yaml:"complexity"`
+	Cognitive  int `json:"cognitive"  yaml:"cognitive"`
+}
+
+// FunctionHistory is the complexity of one function across the analysis
+// window. Start is the complexity before the first change in the window, or
+// zero for functions added in it.
+type FunctionHistory struct {
+	File           string           `json:"file"            yaml:"file"`
+	Name           string           `json:"name"            yaml:"name"`
+	Start          int              `json:"start"           yaml:"start"`
+	End            int              `json:"end"             yaml:"end"`
+	Growth         int              `json:"growth"          yaml:"growth"`
+	CognitiveStart int              `json:"cognitive_start" yaml:"cognitive_start"`
+	CognitiveEnd   int              `json:"cognitive_end"   yaml:"cognitive_end"`
+	Commits        int              `json:"commits"         yaml:"commits"`
+	Added          bool             `json:"added"           yaml:"added"`
+	Deleted        bool             `json:"deleted"         yaml:"deleted"`
+	Points         []TickComplexity `json:"points"          yaml:"points"`
+}
+
+// HistoryAnalyzer tracks the cyclomatic and cognitive complexity of every
+// function the commits change, using the UAST of the files before and after
+// each commit.
+type HistoryAnalyzer struct {
+	*analyze.BaseHistoryAnalyzer[*HistoryMetrics]
+
+	UAST  *plumbing.UASTChangesAnalyzer
+	Ticks *plumbing.TicksSinceStart
+
+	analyzer *Analyzer
+	tickSize time.Duration
+}
+
+// NewHistoryAnalyzer creates a new HistoryAnalyzer.
+func NewHistoryAnalyzer() *HistoryAnalyzer {
+	a := &HistoryAnalyzer{analyzer: NewAnalyzer()}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*HistoryMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/complexity",
+			Mode: analyze.ModeHistory,
+			Description: "Tracks the complexity of individual functions across commits and reports " +
+				"the functions whose complexity grew the most.",
+		},
+		Sequential:   false,
+		CPUHeavyFlag: true,
+		Caps:         &analyze.Capabilities{NeedsUAST: true, Memory: analyze.MemoryLow},
+		ComputeMetricsFn: func(report analyze.Report) (*HistoryMetrics, error) {
+			return ComputeHistoryMetrics(report), nil
+		},
+		AggregatorFn: newHistoryAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (h *HistoryAnalyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		h.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize prepares the analyzer for processing commits.
+func (h *HistoryAnalyzer) Initialize(_ *gitlib.Repository) error {
+	if h.analyzer == nil {
+		h.analyzer = NewAnalyzer()
+	}
+
+	return nil
+}
+
+// Consume compares the functions of every changed file before and after the
+// commit and emits those whose complexity changed.
+func (h *HistoryAnalyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
+	var changes []FunctionChange
+
+	for _, change := range h.UAST.Changes(ctx) {
+		file := change.Change.To.Name
+		if change.After == nil {
+			file = change.Change.From.Name
+		}
+
+		changes = append(changes, diffFunctions(file, h.analyzer.functionComplexity(change.Before),
+			h.analyzer.functionComplexity(change.After))...)
+	}
+
+	if len(changes) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	tc := analyze.TC{Data: &CommitData{Changes: changes}}
+
+	if ac != nil && ac.Commit != nil {
+		tc.CommitHash = ac.Commit.Hash()
+	}
+
+	return tc, nil
+}
+
+// functionComplexity returns the metrics of the functions of root by name.
+// Of functions sharing a name, the most complex one is kept.
+func (c *Analyzer) functionComplexity(root *node.Node) map[string]FunctionMetrics {
+	if root == nil {
+		return nil
+	}
+
+	functions := make(map[string]FunctionMetrics)
+
+	for _, fn := range c.findFunctions(root) {
+		metrics := c.calculateFunctionMetrics(fn)
+		if metrics.Name == anonymousFunctionName {
+			continue
+		}
+
+		if old, ok := functions[metrics.Name]; !ok || metrics.CyclomaticComplexity > old.CyclomaticComplexity {
+			functions[metrics.Name] = metrics
+		}
+	}
+
+	return functions
+}
+
+// diffFunctions returns the functions of file that were added, deleted or
+// changed complexity between before and after, sorted by name.
+func diffFunctions(file string, before, after map[string]FunctionMetrics) []FunctionChange {
+	var changes []FunctionChange
+
+	for name, metrics := range after {
+		change := FunctionChange{
+			FunctionKey:    FunctionKey{File: file, Name: name},
+			After:          metrics.CyclomaticComplexity,
+			CognitiveAfter: metrics.CognitiveComplexity,
+		}
+
+		old, ok := before[name]
+		if !ok {
+			change.Added = true
+		} else if old.CyclomaticComplexity == metrics.CyclomaticComplexity &&
+			old.CognitiveComplexity == metrics.CognitiveComplexity {
+			continue
+		}
+
+		change.Before = old.CyclomaticComplexity
+		change.CognitiveBefore = old.CognitiveComplexity
+		changes = append(changes, change)
+	}
+
+	for name, metrics := range before {
+		if _, ok := after[name]; ok {
+			continue
+		}
+
+		changes = append(changes, FunctionChange{
+			FunctionKey:     FunctionKey{File: file, Name: name},
+			Before:          metrics.CyclomaticComplexity,
+			CognitiveBefore: metrics.CognitiveComplexity,
+			Deleted:         true,
+		})
+	}
+
+	slices.SortFunc(changes, func(x, y FunctionChange) int {
+		return cmp.Compare(x.Name, y.Name)
+	})
+
+	return changes
+}
+
+// Fork creates independent copies of the analyzer for parallel processing.
+func (h *HistoryAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		res[i] = &HistoryAnalyzer{
+			BaseHistoryAnalyzer: h.BaseHistoryAnalyzer,
+			UAST:                &plumbing.UASTChangesAnalyzer{},
+			Ticks:               &plumbing.TicksSinceStart{},
+			analyzer:            NewAnalyzer(),
+			tickSize:            h.tickSize,
+		}
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (h *HistoryAnalyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (h *HistoryAnalyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		UASTChanges: h.UAST.TransferChanges(),
+		Tick:        h.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (h *HistoryAnalyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	h.UAST.SetChanges(snapshot.UASTChanges)
+	h.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot releases UAST trees owned by the snapshot.
+func (h *HistoryAnalyzer) ReleaseSnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	plumbing.ReleaseSnapshotUAST(snapshot)
+}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (h *HistoryAnalyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return h.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (h *HistoryAnalyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return h.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks joins the ticks of every function into its history,
+// sorted by file and name.
+func (h *HistoryAnalyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	histories := make(map[FunctionKey]*FunctionHistory)
+
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*TickData)
+		if !ok || td == nil {
+			continue
+		}
+
+		for key, ft := range td.Functions {
+			history := histories[key]
+			if history == nil || history.Deleted {
+				history = &FunctionHistory{
+					File: key.File, Name: key.Name,
+					Start: ft.Start, CognitiveStart: ft.CognitiveStart, Added: ft.Added,
+				}
+				histories[key] = history
+			}
+
+			history.End, history.CognitiveEnd = ft.End, ft.CognitiveEnd
+			history.Growth = history.End - history.Start
+			history.Commits += ft.Commits
+			history.Deleted = ft.Deleted
+			history.Points = append(history.Points, TickComplexity{Tick: tick.Tick, Complexity: ft.End, Cognitive: ft.CognitiveEnd})
+		}
+	}
+
+	functions := make([]FunctionHistory, 0, len(histories))
+	for _, history := range histories {
+		functions = append(functions, *history)
+	}
+
+	slices.SortFunc(functions, func(x, y FunctionHistory) int {
+		return cmp.Or(cmp.Compare(x.File, y.File), cmp.Compare(x.Name, y.Name))
+	})
+
+	return analyze.Report{
+		KeyFunctionHistory: functions,
+		KeyTickSize:        h.tickSize,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	cd, ok := tc.Data.(*CommitData)
+	if !ok || cd == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{Functions: make(map[FunctionKey]*FunctionTick)}
+		byTick[tc.Tick] = state
+	}
+
+	for _, change := range cd.Changes {
+		state.add(change.FunctionKey, &FunctionTick{
+			Start:          change.Before,
+			End:            change.After,
+			CognitiveStart: change.CognitiveBefore,
+			CognitiveEnd:   change.CognitiveAfter,
+			Added:          change.Added,
+			Deleted:        change.Deleted,
+			Commits:        1,
+			first:          tc.Timestamp,
+			last:           tc.Timestamp,
+		})
+	}
+
+	return nil
+}
+
+// add merges ft into the tick of key. The earliest change sets the start and
+// the latest one the end, whatever order the commits arrive in.
+func (td *TickData) add(key FunctionKey, ft *FunctionTick) {
+	existing := td.Functions[key]
+	if existing == nil {
+		td.Functions[key] = ft
+
+		return
+	}
+
+	if ft.first.Before(existing.first) {
+		existing.Start, existing.CognitiveStart, existing.Added = ft.Start, ft.CognitiveStart, ft.Added
+		existing.first = ft.first
+	}
+
+	if !ft.last.Before(existing.last) {
+		existing.End, existing.CognitiveEnd, existing.Deleted = ft.End, ft.CognitiveEnd, ft.Deleted
+		existing.last = ft.last
+	}
+
+	existing.Commits += ft.Commits
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming != nil {
+		for key, ft := range incoming.Functions {
+			existing.add(key, ft)
+		}
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	return int64(len(state.Functions)) * functionTickSize
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || len(state.Functions) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newHistoryAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
@@ -0,0 +1,115 @@
+package complexity
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// HistoryMetrics summarizes the complexity history of the functions that
+// still exist at the end of the analysis window.
+type HistoryMetrics struct {
+	Functions   int `json:"functions"    yaml:"functions"`
+	Grown       int `json:"grown"        yaml:"grown"`
+	Shrunk      int `json:"shrunk"       yaml:"shrunk"`
+	TotalGrowth int `json:"total_growth" yaml:"total_growth"`
+	// TopGrowth lists the functions whose complexity grew, most growth first.
+	TopGrowth []FunctionHistory `json:"top_growth" yaml:"top_growth"`
+	// FileTrends holds the complexity of every changed file over time, by path.
+	FileTrends []FileTrend `json:"file_trends" yaml:"file_trends"`
+}
+
+// FileTrend is the summed complexity of the functions of one file at every
+// tick any of them changed in.
+type FileTrend struct {
+	File   string           `json:"file"   yaml:"file"`
+	Points []TickComplexity `json:"points" yaml:"points"`
+}
+
+// ComputeHistoryMetrics ranks the functions of a history report by growth.
+// Deleted functions are skipped.
+func ComputeHistoryMetrics(report analyze.Report) *HistoryMetrics {
+	functions, _ := report[KeyFunctionHistory].([]FunctionHistory)
+
+	m := &HistoryMetrics{}
+
+	for _, fn := range functions {
+		if fn.Deleted {
+			continue
+		}
+
+		m.Functions++
+		m.TotalGrowth += fn.Growth
+
+		switch {
+		case fn.Growth > 0:
+			m.Grown++
+			m.TopGrowth = append(m.TopGrowth, fn)
+		case fn.Growth < 0:
+			m.Shrunk++
+		}
+	}
+
+	slices.SortStableFunc(m.TopGrowth, func(x, y FunctionHistory) int {
+		return cmp.Or(cmp.Compare(y.Growth, x.Growth), cmp.Compare(y.End, x.End))
+	})
+
+	m.FileTrends = computeFileTrends(functions)
+
+	return m
+}
+
+// computeFileTrends groups the function histories by file and sums them.
+// Deleted functions are kept: they count until the tick they were deleted in.
+func computeFileTrends(functions []FunctionHistory) []FileTrend {
+	byFile := make(map[string][]FunctionHistory)
+
+	for _, fn := range functions {
+		byFile[fn.File] = append(byFile[fn.File], fn)
+	}
+
+	trends := make([]FileTrend, 0, len(byFile))
+
+	for _, file := range slices.Sorted(maps.Keys(byFile)) {
+		trends = append(trends, FileTrend{File: file, Points: sumPoints(byFile[file])})
+	}
+
+	return trends
+}
+
+// sumPoints sums the complexity of functions at every tick one of them
+// changed in. Before its first change a function counts with its start
+// complexity, between changes with its latest one.
+func sumPoints(functions []FunctionHistory) []TickComplexity {
+	var ticks []int
+
+	for _, fn := range functions {
+		for _, point := range fn.Points {
+			ticks = append(ticks, point.Tick)
+		}
+	}
+
+	slices.Sort(ticks)
+	ticks = slices.Compact(ticks)
+
+	points := make([]TickComplexity, len(ticks))
+
+	for _, fn := range functions {
+		complexity, cognitive, next := fn.Start, fn.CognitiveStart, 0
+
+		for i, tick := range ticks {
+			if next < len(fn.Points) && fn.Points[next].Tick == tick {
+				complexity, cognitive = fn.Points[next].Complexity, fn.Points[next].Cognitive
+				next++
+			}
+
+			points[i].Tick = tick
+			points[i].Complexity += complexity
+			points[i].Cognitive += cognitive
+		}
+	}
+
+	return points
+}
@@ -0,0 +1,160 @@
+package complexity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// fileWithFunctions builds a file whose functions have the given number of
+// if statements each.
+func fileWithFunctions(branches map[string]int) *node.Node {
+	root := &node.Node{Type: node.UASTFile}
+
+	for name, ifs := range branches {
+		fn := &node.Node{Type: node.UASTFunction, Roles: []node.Role{node.RoleFunction, node.RoleDeclaration}}
+
+		nameNode := node.NewNodeWithToken(node.UASTIdentifier, name)
+		nameNode.Roles = []node.Role{node.RoleName}
+		fn.AddChild(nameNode)
+
+		for range ifs {
+			fn.AddChild(&node.Node{Type: node.UASTIf})
+		}
+
+		root.AddChild(fn)
+	}
+
+	return root
+}
+
+func TestFunctionComplexity(t *testing.T) {
+	t.Parallel()
+
+	analyzer := NewAnalyzer()
+
+	functions := analyzer.functionComplexity(fileWithFunctions(map[string]int{"Parse": 2, "Close": 0}))
+	require.Len(t, functions, 2)
+	assert.Equal(t, 3, functions["Parse"].CyclomaticComplexity)
+	assert.Equal(t, 1, functions["Close"].CyclomaticComplexity)
+
+	assert.Nil(t, analyzer.functionComplexity(nil))
+}
+
+func TestDiffFunctions(t *testing.T) {
+	t.Parallel()
+
+	before := map[string]FunctionMetrics{
+		"Parse": {Name: "Parse", CyclomaticComplexity: 3, CognitiveComplexity: 2},
+		"Close": {Name: "Close", CyclomaticComplexity: 1},
+		"Old":   {Name: "Old", CyclomaticComplexity: 2, CognitiveComplexity: 1},
+	}
+	after := map[string]FunctionMetrics{
+		"Parse": {Name: "Parse", CyclomaticComplexity: 5, CognitiveComplexity: 6},
+		"Close": {Name: "Close", CyclomaticComplexity: 1},
+		"New":   {Name: "New", CyclomaticComplexity: 4, CognitiveComplexity: 3},
+	}
+
+	assert.Equal(t, []FunctionChange{
+		{FunctionKey: FunctionKey{File: "a.go", Name: "New"}, After: 4, CognitiveAfter: 3, Added: true},
+		{FunctionKey: FunctionKey{File: "a.go", Name: "Old"}, Before: 2, CognitiveBefore: 1, Deleted: true},
+		{
+			FunctionKey: FunctionKey{File: "a.go", Name: "Parse"},
+			Before:      3, After: 5, CognitiveBefore: 2, CognitiveAfter: 6,
+		},
+	}, diffFunctions("a.go", before, after))
+}
+
+func TestAggregator_OrdersChangesWithinTick(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := FunctionKey{File: "a.go", Name: "Parse"}
+	byTick := make(map[int]*TickData)
+
+	// The later commit arrives first.
+	require.NoError(t, extractTC(analyze.TC{
+		Tick: 0, Timestamp: start.Add(time.Hour),
+		Data: &CommitData{Changes: []FunctionChange{{FunctionKey: key, Before: 4, After: 7}}},
+	}, byTick))
+	require.NoError(t, extractTC(analyze.TC{
+		Tick: 0, Timestamp: start,
+		Data: &CommitData{Changes: []FunctionChange{{FunctionKey: key, Before: 2, After: 4}}},
+	}, byTick))
+
+	ft := byTick[0].Functions[key]
+	require.NotNil(t, ft)
+	assert.Equal(t, 2, ft.Start)
+	assert.Equal(t, 7, ft.End)
+	assert.Equal(t, 2, ft.Commits)
+
+	other := &TickData{Functions: map[FunctionKey]*FunctionTick{
+		key: {Start: 7, End: 9, Commits: 1, first: start.Add(2 * time.Hour), last: start.Add(2 * time.Hour)},
+	}}
+
+	merged := mergeState(byTick[0], other)
+	assert.Equal(t, 2, merged.Functions[key].Start)
+	assert.Equal(t, 9, merged.Functions[key].End)
+	assert.Equal(t, 3, merged.Functions[key].Commits)
+}
+
+func TestHistoryAnalyzer_ReportAndMetrics(t *testing.T) {
+	t.Parallel()
+
+	parse := FunctionKey{File: "a.go", Name: "Parse"}
+	closeFn := FunctionKey{File: "a.go", Name: "Close"}
+	helper := FunctionKey{File: "b.go", Name: "helper"}
+	old := FunctionKey{File: "b.go", Name: "old"}
+
+	ticks := []analyze.TICK{
+		{Tick: 0, Data: &TickData{Functions: map[FunctionKey]*FunctionTick{
+			parse:   {Start: 2, End: 4, Commits: 1},
+			closeFn: {Start: 3, End: 2, Commits: 1},
+			old:     {Start: 5, End: 0, Deleted: true, Commits: 1},
+		}}},
+		{Tick: 1},
+		{Tick: 3, Data: &TickData{Functions: map[FunctionKey]*FunctionTick{
+			parse:  {Start: 4, End: 8, CognitiveStart: 3, CognitiveEnd: 9, Commits: 2},
+			helper: {End: 3, Added: true, Commits: 1},
+		}}},
+	}
+
+	h := NewHistoryAnalyzer()
+	require.NoError(t, h.Configure(map[string]any{}))
+
+	report, err := h.ReportFromTICKs(context.Background(), ticks)
+	require.NoError(t, err)
+
+	functions, ok := report[KeyFunctionHistory].([]FunctionHistory)
+	require.True(t, ok)
+	require.Len(t, functions, 4)
+
+	assert.Equal(t, FunctionHistory{
+		File: "a.go", Name: "Parse", Start: 2, End: 8, Growth: 6, CognitiveEnd: 9, Commits: 3,
+		Points: []TickComplexity{{Tick: 0, Complexity: 4}, {Tick: 3, Complexity: 8, Cognitive: 9}},
+	}, functions[1])
+
+	m := ComputeHistoryMetrics(report)
+	assert.Equal(t, 3, m.Functions)
+	assert.Equal(t, 2, m.Grown)
+	assert.Equal(t, 1, m.Shrunk)
+	assert.Equal(t, 8, m.TotalGrowth)
+	require.Len(t, m.TopGrowth, 2)
+	assert.Equal(t, "Parse", m.TopGrowth[0].Name)
+	assert.Equal(t, "helper", m.TopGrowth[1].Name)
+	assert.True(t, m.TopGrowth[1].Added)
+	assert.Equal(t, []FileTrend{
+		{File: "a.go", Points: []TickComplexity{{Tick: 0, Complexity: 6}, {Tick: 3, Complexity: 10, Cognitive: 9}}},
+		{File: "b.go", Points: []TickComplexity{{Tick: 0, Complexity: 0}, {Tick: 3, Complexity: 3}}},
+	}, m.FileTrends, "deleted functions stop counting after their deletion")
+
+	sections, err := h.GenerateSections(report)
+	require.NoError(t, err)
+	assert.Len(t, sections, 2)
+}
@@ -25,6 +25,7 @@ type ReportData struct {
 // FunctionData holds complexity data for a single function.
 type FunctionData struct {
 	Name                 string
+	File                 string
 	CyclomaticComplexity int
 	CognitiveComplexity  int
 	NestingDepth         int
@@ -99,6 +100,10 @@ func parseFunctionData(fn map[string]any) FunctionData {
 		fd.Name = name
 	}
 
+	if file, ok := fn["_source_file"].(string); ok {
+		fd.File = file
+	}
+
 	if v, ok := fn["cyclomatic_complexity"].(int); ok {
 		fd.CyclomaticComplexity = v
 	}
@@ -135,6 +140,7 @@ func parseFunctionData(fn map[string]any) FunctionData {
 // FunctionComplexityData contains detailed complexity for a function.
 type FunctionComplexityData struct {
 	Name                 string  `json:"name"                  yaml:"name"`
+	File                 string  `json:"file,omitempty"        yaml:"file,omitempty"`
 	CyclomaticComplexity int     `json:"cyclomatic_complexity" yaml:"cyclomatic_complexity"`
 	CognitiveComplexity  int     `json:"cognitive_complexity"  yaml:"cognitive_complexity"`
 	NestingDepth         int     `json:"nesting_depth"         yaml:"nesting_depth"`
@@ -226,6 +232,7 @@ func (m *FunctionComplexityMetric) Compute(input *ReportData) []FunctionComplexi
 
 		result = append(result, FunctionComplexityData{
 			Name:                 fn.Name,
+			File:                 fn.File,
 			CyclomaticComplexity: fn.CyclomaticComplexity,
 			CognitiveComplexity:  fn.CognitiveComplexity,
 			NestingDepth:         fn.NestingDepth,
@@ -79,6 +79,7 @@ func TestParseReportData_WithFunctions(t *testing.T) {
 				"cognitive_complexity":  CognitiveThresholdModerate,
 				"nesting_depth":         testNestingDepth,
 				"lines_of_code":         testLinesOfCode,
+				"_source_file":          "/repo/main.go",
 			},
 		},
 	}
@@ -92,6 +93,7 @@ func TestParseReportData_WithFunctions(t *testing.T) {
 	assert.Equal(t, CognitiveThresholdModerate, data.Functions[0].CognitiveComplexity)
 	assert.Equal(t, testNestingDepth, data.Functions[0].NestingDepth)
 	assert.Equal(t, testLinesOfCode, data.Functions[0].LinesOfCode)
+	assert.Equal(t, "/repo/main.go", data.Functions[0].File)
 }
 
 func TestParseReportData_WithAssessments(t *testing.T) {
@@ -2,8 +2,11 @@ package complexity
 
 import (
 	"errors"
+	"html"
 	"io"
+	"slices"
 	"sort"
+	"strconv"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
@@ -24,6 +27,7 @@ const (
 	cognitiveYellowLine  = 7
 	cognitiveRedLine     = 15
 	unknownName          = "unknown"
+	trendFunctionsLimit  = 10
 )
 
 // ErrInvalidFunctionsData indicates the report doesn't contain expected functions data.
@@ -34,6 +38,9 @@ func RegisterPlotSections() {
 	analyze.RegisterPlotSections("static/complexity", func(report analyze.Report) ([]plotpage.Section, error) {
 		return (&Analyzer{}).generateSections(report)
 	})
+	analyze.RegisterPlotSections("history/complexity", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&HistoryAnalyzer{}).GenerateSections(report)
+	})
 }
 
 // FormatReportPlot generates an HTML plot visualization for complexity analysis.
@@ -445,3 +452,88 @@ func createEmptyComplexityPie() *charts.Pie {
 
 	return pie
 }
+
+// GenerateSections returns the sections for combined reports.
+func (h *HistoryAnalyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeHistoryMetrics(report)
+
+	table := plotpage.NewTable([]string{"Function", "File", "Start", "End", "Growth", "Cognitive", "Commits"}).
+		WithSearch("Filter functions...")
+
+	for _, fn := range m.TopGrowth {
+		table.AddRow(
+			html.EscapeString(fn.Name),
+			html.EscapeString(fn.File),
+			strconv.Itoa(fn.Start),
+			strconv.Itoa(fn.End),
+			"+"+strconv.Itoa(fn.Growth),
+			strconv.Itoa(fn.CognitiveStart)+" → "+strconv.Itoa(fn.CognitiveEnd),
+			strconv.Itoa(fn.Commits),
+		)
+	}
+
+	return []plotpage.Section{
+		{
+			Title:    "Complexity Trend",
+			Subtitle: "Cyclomatic complexity per tick of the functions that grew the most.",
+			Chart:    plotpage.WrapChart(buildComplexityTrendChart(m.TopGrowth[:min(len(m.TopGrowth), trendFunctionsLimit)])),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"A steadily rising line = a function that accretes branches with every change",
+					"A sudden step = one commit added much of the logic; review it for a split",
+				},
+			},
+		},
+		{
+			Title: "Complexity Growth",
+			Subtitle: strconv.Itoa(m.Grown) + " of " + strconv.Itoa(m.Functions) + " changed functions grew, " +
+				strconv.Itoa(m.Shrunk) + " shrank; net growth " + strconv.Itoa(m.TotalGrowth) + ".",
+			Chart: table,
+		},
+	}, nil
+}
+
+// buildComplexityTrendChart draws one line per function over the ticks any
+// of them changed in, carrying values forward between changes.
+func buildComplexityTrendChart(functions []FunctionHistory) *charts.Line {
+	var ticks []int
+
+	for _, fn := range functions {
+		for _, point := range fn.Points {
+			ticks = append(ticks, point.Tick)
+		}
+	}
+
+	slices.Sort(ticks)
+	ticks = slices.Compact(ticks)
+
+	labels := make([]string, len(ticks))
+	for i, tick := range ticks {
+		labels[i] = strconv.Itoa(tick)
+	}
+
+	series := make([]plotpage.LineSeries, 0, len(functions))
+
+	for _, fn := range functions {
+		data := make([]plotpage.SeriesData, len(ticks))
+
+		var (
+			value any
+			next  int
+		)
+
+		for i, tick := range ticks {
+			if next < len(fn.Points) && fn.Points[next].Tick == tick {
+				value = fn.Points[next].Complexity
+				next++
+			}
+
+			data[i] = value
+		}
+
+		series = append(series, plotpage.LineSeries{Name: fn.Name, Data: data})
+	}
+
+	return plotpage.BuildLineChart(nil, labels, series, "Cyclomatic complexity")
+}
@@ -43,8 +43,11 @@ func newAggregator(
 		people[i] = make(map[string]int)
 	}
 
+	files := spillstore.New[map[string]int]()
+	files.SetCodec(opts.SpillCodec)
+
 	return &Aggregator{
-		files:         spillstore.New[map[string]int](),
+		files:         files,
 		people:        people,
 		peopleCommits: make([]int, peopleNumber+1),
 		opts:          opts,
@@ -209,12 +212,18 @@ func (a *Aggregator) EstimatedStateSize() int64 {
 
 // SpillState returns the current on-disk spill state for checkpoint persistence.
 func (a *Aggregator) SpillState() analyze.AggregatorSpillInfo {
-	return analyze.AggregatorSpillInfo{Dir: a.files.SpillDir(), Count: a.files.SpillCount()}
+	return analyze.AggregatorSpillInfo{
+		Dir:     a.files.SpillDir(),
+		Count:   a.files.SpillCount(),
+		Codec:   a.files.Codec(),
+		Written: a.files.BytesWritten(),
+	}
 }
 
 // RestoreSpillState points the aggregator at a previously-saved spill directory.
 func (a *Aggregator) RestoreSpillState(info analyze.AggregatorSpillInfo) {
 	a.files.RestoreFromDir(info.Dir, info.Count)
+	a.files.SetCodec(info.SpillCodec())
 }
 
 // Close releases all resources. Idempotent.
@@ -2,6 +2,7 @@ package couples
 
 import (
 	"github.com/Sumatoshi-tech/codefang/pkg/checkpoint"
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 )
 
@@ -25,13 +26,13 @@ func newPersister() *checkpoint.Persister[checkpointState] {
 }
 
 // SaveCheckpoint writes the analyzer state to the given directory.
-func (c *HistoryAnalyzer) SaveCheckpoint(dir string) error {
-	return newPersister().Save(dir, c.buildCheckpointState)
+func (c *HistoryAnalyzer) SaveCheckpoint(dir string, cipher *crypt.Cipher) error {
+	return newPersister().Save(dir, cipher, c.buildCheckpointState)
 }
 
 // LoadCheckpoint restores the analyzer state from the given directory.
-func (c *HistoryAnalyzer) LoadCheckpoint(dir string) error {
-	return newPersister().Load(dir, c.restoreFromCheckpoint)
+func (c *HistoryAnalyzer) LoadCheckpoint(dir string, cipher *crypt.Cipher) error {
+	return newPersister().Load(dir, cipher, c.restoreFromCheckpoint)
 }
 
 // buildCheckpointState creates a serializable snapshot of the analyzer state.
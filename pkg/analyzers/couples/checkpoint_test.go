@@ -18,7 +18,7 @@ func TestSaveCheckpoint_CreatesFile(t *testing.T) {
 	c := &HistoryAnalyzer{}
 	require.NoError(t, c.Initialize(nil))
 
-	err := c.SaveCheckpoint(dir)
+	err := c.SaveCheckpoint(dir, nil)
 	require.NoError(t, err)
 
 	expectedPath := filepath.Join(dir, checkpointBasename+".json")
@@ -39,10 +39,10 @@ func TestLoadCheckpoint_RestoresState(t *testing.T) {
 	hash := gitlib.NewHash("1111111111111111111111111111111111111111")
 	original.merges[hash] = true
 
-	require.NoError(t, original.SaveCheckpoint(dir))
+	require.NoError(t, original.SaveCheckpoint(dir, nil))
 
 	restored := &HistoryAnalyzer{}
-	require.NoError(t, restored.LoadCheckpoint(dir))
+	require.NoError(t, restored.LoadCheckpoint(dir, nil))
 
 	assert.True(t, restored.seenFiles["a.go"])
 	assert.True(t, restored.seenFiles["b.go"])
@@ -85,10 +85,10 @@ func TestCheckpointRoundTrip_PreservesAllState(t *testing.T) {
 	original.merges[hash1] = true
 	original.merges[hash2] = true
 
-	require.NoError(t, original.SaveCheckpoint(dir))
+	require.NoError(t, original.SaveCheckpoint(dir, nil))
 
 	restored := &HistoryAnalyzer{}
-	require.NoError(t, restored.LoadCheckpoint(dir))
+	require.NoError(t, restored.LoadCheckpoint(dir, nil))
 
 	assert.Equal(t, original.PeopleNumber, restored.PeopleNumber)
 	assert.Equal(t, original.reversedPeopleDict, restored.reversedPeopleDict)
@@ -0,0 +1,65 @@
+package couples
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/graphexport"
+)
+
+// peopleGraphID names the developer coupling network in graph exports.
+const peopleGraphID = "people"
+
+// unknownDeveloper labels matrix rows without a name, such as the bucket of
+// authors outside the people dictionary.
+const unknownDeveloper = "<unknown>"
+
+// GenerateGraphs returns the developer coupling network: one node per developer
+// weighted by the file changes they made, and one edge per pair weighted by
+// the file changes they share.
+func (c *HistoryAnalyzer) GenerateGraphs(report analyze.Report) ([]graphexport.Graph, error) {
+	data, err := ParseReportData(report)
+	if err != nil {
+		return nil, err
+	}
+
+	return []graphexport.Graph{buildPeopleGraph(data.PeopleMatrix, data.ReversedPeopleDict)}, nil
+}
+
+func buildPeopleGraph(matrix []map[int]int64, names []string) graphexport.Graph {
+	graph := graphexport.Graph{ID: peopleGraphID, Label: "Developer coupling (shared file changes)"}
+
+	for i, row := range matrix {
+		if row[i] == 0 {
+			continue
+		}
+
+		label := getDevName(i, names)
+		if label == "" {
+			label = unknownDeveloper
+		}
+
+		graph.Nodes = append(graph.Nodes, graphexport.Node{ID: strconv.Itoa(i), Label: label, Weight: float64(row[i])})
+
+		partners := make([]int, 0, len(row))
+
+		for j, shared := range row {
+			if j > i && shared > 0 {
+				partners = append(partners, j)
+			}
+		}
+
+		slices.Sort(partners)
+
+		for _, j := range partners {
+			graph.Edges = append(graph.Edges, graphexport.Edge{
+				Source: strconv.Itoa(i),
+				Target: strconv.Itoa(j),
+				Weight: float64(row[j]),
+			})
+		}
+	}
+
+	return graph
+}
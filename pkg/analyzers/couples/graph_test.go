@@ -0,0 +1,53 @@
+package couples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/graphexport"
+)
+
+func TestGenerateGraphs_PeopleCoupling(t *testing.T) {
+	t.Parallel()
+
+	c := NewHistoryAnalyzer()
+
+	report := analyze.Report{
+		"ReversedPeopleDict": []string{"alice", "bob", "carol"},
+		"PeopleMatrix": []map[int]int64{
+			{0: 20, 1: 10, 3: 2},
+			{0: 10, 1: 15},
+			{},
+			{0: 2, 3: 4},
+		},
+	}
+
+	graphs, err := c.GenerateGraphs(report)
+	require.NoError(t, err)
+	require.Len(t, graphs, 1)
+
+	graph := graphs[0]
+	assert.Equal(t, "people", graph.ID)
+	assert.Equal(t, []graphexport.Node{
+		{ID: "0", Label: "alice", Weight: 20},
+		{ID: "1", Label: "bob", Weight: 15},
+		{ID: "3", Label: "<unknown>", Weight: 4},
+	}, graph.Nodes)
+	assert.Equal(t, []graphexport.Edge{
+		{Source: "0", Target: "1", Weight: 10},
+		{Source: "0", Target: "3", Weight: 2},
+	}, graph.Edges)
+}
+
+func TestGenerateGraphs_EmptyReport(t *testing.T) {
+	t.Parallel()
+
+	graphs, err := NewHistoryAnalyzer().GenerateGraphs(analyze.Report{})
+	require.NoError(t, err)
+	require.Len(t, graphs, 1)
+	assert.Empty(t, graphs[0].Nodes)
+	assert.Empty(t, graphs[0].Edges)
+}
@@ -9,9 +9,11 @@ import (
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/codeowners"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/identity"
 	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 const (
@@ -34,6 +36,7 @@ type HistoryAnalyzer struct {
 	reversedPeopleDict []string
 	PeopleNumber       int
 	seenFiles          map[string]bool
+	codeOwners         *codeowners.Ruleset
 }
 
 // NewHistoryAnalyzer creates a new HistoryAnalyzer.
@@ -48,6 +51,7 @@ func NewHistoryAnalyzer() *HistoryAnalyzer {
 			Mode: analyze.ModeHistory,
 		},
 		Sequential: false,
+		Caps:       &analyze.Capabilities{Memory: analyze.MemoryHigh},
 		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
 			if len(report) == 0 {
 				return &ComputedMetrics{}, nil
@@ -59,7 +63,10 @@ func NewHistoryAnalyzer() *HistoryAnalyzer {
 			return newAggregator(opts, a.PeopleNumber, a.reversedPeopleDict, a.lastCommit)
 		},
 		TicksToReportFn: func(ctx context.Context, ticks []analyze.TICK) analyze.Report {
-			return ticksToReport(ctx, ticks, a.reversedPeopleDict, a.PeopleNumber, a.lastCommit)
+			report := ticksToReport(ctx, ticks, a.reversedPeopleDict, a.PeopleNumber, a.lastCommit)
+			addFilesOwners(report, a.codeOwners)
+
+			return report
 		},
 	}
 
@@ -114,9 +121,30 @@ func (c *HistoryAnalyzer) Configure(facts map[string]any) error {
 		c.reversedPeopleDict = rpd
 	}
 
+	if val, exists := facts[pkgplumbing.FactCodeOwners].(*codeowners.Ruleset); exists {
+		c.codeOwners = val
+	}
+
 	return nil
 }
 
+// addFilesOwners adds the CODEOWNERS owners of the report files, so that
+// couplings across team boundaries can be told apart.
+func addFilesOwners(report analyze.Report, owners *codeowners.Ruleset) {
+	files, ok := report["Files"].([]string)
+	if !ok || owners == nil {
+		return
+	}
+
+	filesOwners := make([][]string, len(files))
+
+	for i, file := range files {
+		filesOwners[i] = owners.Owners(file)
+	}
+
+	report["FilesOwners"] = filesOwners
+}
+
 // MapDependencies returns the required plumbing analyzers.
 func (c *HistoryAnalyzer) MapDependencies() []string {
 	return []string{}
@@ -353,6 +381,7 @@ func (c *HistoryAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
 			clone.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
 				Desc:             c.Desc,
 				Sequential:       c.Sequential,
+				Caps:             c.Caps,
 				ComputeMetricsFn: c.ComputeMetricsFn,
 				AggregatorFn:     c.AggregatorFn,
 				TicksToReportFn:  c.TicksToReportFn,
@@ -13,8 +13,10 @@ import (
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/codeowners"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 func TestHistoryAnalyzer_Configure(t *testing.T) {
@@ -40,6 +42,23 @@ func TestHistoryAnalyzer_Configure(t *testing.T) {
 	}
 }
 
+func TestHistoryAnalyzer_ConfigureCodeOwners(t *testing.T) {
+	t.Parallel()
+
+	c := NewHistoryAnalyzer()
+	require.NoError(t, c.Configure(map[string]any{
+		pkgplumbing.FactCodeOwners: codeowners.Parse([]byte("/web/ @acme/web\n")),
+	}))
+
+	report := analyze.Report{"Files": []string{"web/app.js", "main.go"}}
+	addFilesOwners(report, c.codeOwners)
+	assert.Equal(t, [][]string{{"@acme/web"}, nil}, report["FilesOwners"])
+
+	report = analyze.Report{"Files": []string{"web/app.js"}}
+	addFilesOwners(report, nil)
+	assert.NotContains(t, report, "FilesOwners")
+}
+
 func TestHistoryAnalyzer_Initialize(t *testing.T) {
 	t.Parallel()
 
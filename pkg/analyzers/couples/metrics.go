@@ -1,6 +1,7 @@
 package couples
 
 import (
+	"slices"
 	"sort"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
@@ -17,9 +18,14 @@ type ReportData struct {
 	FilesLines         []int
 	FilesMatrix        []map[int]int64
 	ReversedPeopleDict []string
+	// FilesOwners are the CODEOWNERS owners of Files; nil when the
+	// repository has no CODEOWNERS file.
+	FilesOwners [][]string
 }
 
-// ParseReportData extracts ReportData from an analyzer report.
+// ParseReportData extracts ReportData from an analyzer report. The change
+// counts of a sampled history are scaled by its sampling factor to estimate
+// the full history.
 func ParseReportData(report analyze.Report) (*ReportData, error) {
 	data := &ReportData{}
 
@@ -47,9 +53,33 @@ func ParseReportData(report analyze.Report) (*ReportData, error) {
 		data.ReversedPeopleDict = v
 	}
 
+	if v, ok := report["FilesOwners"].([][]string); ok {
+		data.FilesOwners = v
+	}
+
+	if factor := analyze.SampleFactor(report); factor > 1 {
+		data.PeopleMatrix = scaleMatrix(data.PeopleMatrix, factor)
+		data.FilesMatrix = scaleMatrix(data.FilesMatrix, factor)
+	}
+
 	return data, nil
 }
 
+// scaleMatrix returns a copy of matrix with its counts scaled by factor.
+func scaleMatrix(matrix []map[int]int64, factor float64) []map[int]int64 {
+	scaled := make([]map[int]int64, len(matrix))
+
+	for i, row := range matrix {
+		scaled[i] = make(map[int]int64, len(row))
+
+		for j, n := range row {
+			scaled[i][j] = int64(analyze.ScaleCount(int(n), factor))
+		}
+	}
+
+	return scaled
+}
+
 // --- Output Data Types ---.
 
 // FileCouplingData contains coupling data for a file pair.
@@ -58,6 +88,12 @@ type FileCouplingData struct {
 	File2     string  `json:"file2"             yaml:"file2"`
 	CoChanges int64   `json:"co_changes"        yaml:"co_changes"`
 	Strength  float64 `json:"coupling_strength" yaml:"coupling_strength"`
+	// Owners1 and Owners2 are the CODEOWNERS owners of the files.
+	Owners1 []string `json:"owners1,omitempty" yaml:"owners1,omitempty"`
+	Owners2 []string `json:"owners2,omitempty" yaml:"owners2,omitempty"`
+	// CrossOwner is true when both files have owners and share none: the
+	// coupling crosses a team boundary.
+	CrossOwner bool `json:"cross_owner,omitempty" yaml:"cross_owner,omitempty"`
 }
 
 // DeveloperCouplingData contains coupling data for a developer pair.
@@ -74,6 +110,8 @@ type FileOwnershipData struct {
 	Lines          int    `json:"lines"                     yaml:"lines"`
 	Contributors   int    `json:"contributors"              yaml:"contributors"`
 	TopContributor string `json:"top_contributor,omitempty" yaml:"top_contributor,omitempty"`
+	// CodeOwners are the owners of the file in CODEOWNERS.
+	CodeOwners []string `json:"code_owners,omitempty" yaml:"code_owners,omitempty"`
 }
 
 // AggregateData contains summary statistics.
@@ -83,6 +121,9 @@ type AggregateData struct {
 	TotalCoChanges      int64   `json:"total_co_changes"      yaml:"total_co_changes"`
 	AvgCouplingStrength float64 `json:"avg_coupling_strength" yaml:"avg_coupling_strength"`
 	HighlyCoupledPairs  int     `json:"highly_coupled_pairs"  yaml:"highly_coupled_pairs"`
+	// CrossOwnerPairs counts the coupled pairs whose files have different
+	// owners in CODEOWNERS.
+	CrossOwnerPairs int `json:"cross_owner_pairs,omitempty" yaml:"cross_owner_pairs,omitempty"`
 }
 
 // --- Metric Implementations ---.
@@ -143,11 +184,16 @@ func (m *FileCouplingMetric) Compute(input *ReportData) []FileCouplingData {
 				strength = min(float64(coChanges)/avgRevs, 1.0)
 			}
 
+			owners1, owners2 := fileOwners(input, i), fileOwners(input, j)
+
 			result = append(result, FileCouplingData{
-				File1:     file1,
-				File2:     file2,
-				CoChanges: coChanges,
-				Strength:  strength,
+				File1:      file1,
+				File2:      file2,
+				CoChanges:  coChanges,
+				Strength:   strength,
+				Owners1:    owners1,
+				Owners2:    owners2,
+				CrossOwner: isCrossOwner(owners1, owners2),
 			})
 		}
 	}
@@ -291,6 +337,7 @@ func (m *FileOwnershipMetric) Compute(input *ReportData) []FileOwnershipData {
 			File:         file,
 			Lines:        lines,
 			Contributors: contributors,
+			CodeOwners:   fileOwners(input, i),
 		})
 	}
 
@@ -352,6 +399,10 @@ func (m *AggregateMetric) Compute(input *ReportData) AggregateData {
 			}
 
 			acc.addPair(coChanges, row[i], input.FilesMatrix[j][j])
+
+			if isCrossOwner(fileOwners(input, i), fileOwners(input, j)) {
+				agg.CrossOwnerPairs++
+			}
 		}
 	}
 
@@ -365,6 +416,25 @@ func (m *AggregateMetric) Compute(input *ReportData) AggregateData {
 	return agg
 }
 
+// fileOwners returns the CODEOWNERS owners of the file with index idx.
+func fileOwners(input *ReportData, idx int) []string {
+	if idx < len(input.FilesOwners) {
+		return input.FilesOwners[idx]
+	}
+
+	return nil
+}
+
+// isCrossOwner reports whether two files both have owners but none in
+// common.
+func isCrossOwner(owners1, owners2 []string) bool {
+	if len(owners1) == 0 || len(owners2) == 0 {
+		return false
+	}
+
+	return !slices.ContainsFunc(owners1, func(owner string) bool { return slices.Contains(owners2, owner) })
+}
+
 // --- Data Reduction / Bucketing (used by both text and plot renderers) ---.
 
 // OwnershipBucket categorizes files by their contributor count.
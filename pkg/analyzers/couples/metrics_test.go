@@ -66,6 +66,25 @@ func TestParseReportData_AllFields(t *testing.T) {
 	assert.Equal(t, []string{testDev1, testDev2}, result.ReversedPeopleDict)
 }
 
+func TestParseReportData_ScalesSampledCounts(t *testing.T) {
+	t.Parallel()
+
+	filesMatrix := []map[int]int64{{0: 5, 1: 3}, {0: 3, 1: 4}}
+
+	report := analyze.Report{
+		"PeopleMatrix":                []map[int]int64{{0: 10}},
+		"FilesMatrix":                 filesMatrix,
+		analyze.ReportKeySampleFactor: 2.0,
+	}
+
+	result, err := ParseReportData(report)
+
+	require.NoError(t, err)
+	assert.Equal(t, []map[int]int64{{0: 20}}, result.PeopleMatrix)
+	assert.Equal(t, []map[int]int64{{0: 10, 1: 6}, {0: 6, 1: 8}}, result.FilesMatrix)
+	assert.Equal(t, int64(5), filesMatrix[0][0], "the report is left as is")
+}
+
 // --- FileCouplingMetric Tests ---.
 
 func TestNewFileCouplingMetric_Metadata(t *testing.T) {
@@ -452,6 +471,34 @@ func TestCouplesAggregateMetric_HighlyCoupledThreshold(t *testing.T) {
 	assert.Equal(t, 1, result.HighlyCoupledPairs)
 }
 
+func TestCouplingMetrics_CodeOwners(t *testing.T) {
+	t.Parallel()
+
+	input := &ReportData{
+		Files: []string{testFile1, testFile2, testFile3},
+		FilesMatrix: []map[int]int64{
+			{0: 4, 1: 3, 2: 2},
+			{0: 3, 1: 4, 2: 1},
+			{0: 2, 1: 1, 2: 4},
+		},
+		FilesOwners: [][]string{{"@acme/web"}, {"@acme/web", "@acme/core"}, {"@acme/payments"}},
+	}
+
+	couplings := NewFileCouplingMetric().Compute(input)
+	require.Len(t, couplings, 3)
+	assert.Equal(t, []string{"@acme/web"}, couplings[0].Owners1)
+	assert.Equal(t, []string{"@acme/web", "@acme/core"}, couplings[0].Owners2)
+	assert.False(t, couplings[0].CrossOwner, "files sharing an owner")
+	assert.True(t, couplings[1].CrossOwner)
+	assert.True(t, couplings[2].CrossOwner)
+
+	assert.Equal(t, 2, NewAggregateMetric().Compute(input).CrossOwnerPairs)
+	assert.Equal(t, []string{"@acme/payments"}, NewFileOwnershipMetric().Compute(input)[2].CodeOwners)
+
+	input.FilesOwners = [][]string{{"@acme/web"}, nil}
+	assert.False(t, NewFileCouplingMetric().Compute(input)[0].CrossOwner, "unowned files cross no boundary")
+}
+
 // --- ComputeAllMetrics Tests ---.
 
 func TestComputeAllMetrics_Empty(t *testing.T) {
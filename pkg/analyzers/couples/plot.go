@@ -158,10 +158,15 @@ func (c *HistoryAnalyzer) buildChart(report analyze.Report) (heatMap *charts.Hea
 }
 
 // tryDirectExtraction attempts to extract the matrix and names using in-memory keys.
+// The matrix of a sampled history is scaled like in ParseReportData.
 func tryDirectExtraction(report analyze.Report) (matrix []map[int]int64, names []string, matrixFound, namesFound bool) {
 	matrix, matrixFound = report["PeopleMatrix"].([]map[int]int64)
 	names, namesFound = report["ReversedPeopleDict"].([]string)
 
+	if factor := analyze.SampleFactor(report); matrixFound && factor > 1 {
+		matrix = scaleMatrix(matrix, factor)
+	}
+
 	return matrix, names, matrixFound, namesFound
 }
 
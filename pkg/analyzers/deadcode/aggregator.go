@@ -0,0 +1,76 @@
+package deadcode
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// Aggregator collects the symbols of every file and matches declarations to
+// references once all files are in.
+type Aggregator struct {
+	root       string
+	index      *index
+	totalFiles int
+}
+
+// NewAggregator creates a new Aggregator.
+func NewAggregator() *Aggregator {
+	return &Aggregator{index: newIndex()}
+}
+
+// SetRoot makes the reported file paths relative to root.
+func (a *Aggregator) SetRoot(root string) error {
+	info, err := os.Stat(root)
+	if err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+
+	a.root = root
+
+	return nil
+}
+
+// Aggregate records the symbols of each "_source_file" stamped file.
+func (a *Aggregator) Aggregate(results map[string]analyze.Report) {
+	for _, report := range results {
+		a.totalFiles++
+
+		sites, ok := report[KeyFileSymbols].([]map[string]any)
+		if !ok {
+			continue
+		}
+
+		for _, site := range sites {
+			file, _ := site["_source_file"].(string)
+			if file == "" {
+				continue
+			}
+
+			decls, _ := site[KeyDeclarations].([]Declaration)
+			refs, _ := site[KeyReferences].([]string)
+
+			a.index.set(a.relative(file), FileSymbols{Declarations: decls, References: refs})
+		}
+	}
+}
+
+// GetResult returns the exported symbols that no file references.
+func (a *Aggregator) GetResult() analyze.Report {
+	return analyze.Report{
+		KeySymbols:    a.index.symbols,
+		KeyDead:       a.index.deadSymbols(),
+		KeyTotalFiles: a.totalFiles,
+	}
+}
+
+func (a *Aggregator) relative(file string) string {
+	if a.root != "" {
+		if rel, err := filepath.Rel(a.root, file); err == nil {
+			file = rel
+		}
+	}
+
+	return filepath.ToSlash(file)
+}
@@ -0,0 +1,57 @@
+package deadcode
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestAnalyzer_Aggregate(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	a := NewAnalyzer()
+
+	report, err := a.Analyze(goFile())
+	require.NoError(t, err)
+
+	analyze.StampSourceFile(map[string]analyze.Report{"deadcode": report}, filepath.Join(root, "pkg", "server.go"))
+
+	agg, ok := a.CreateAggregator().(*Aggregator)
+	require.True(t, ok)
+	require.NoError(t, agg.SetRoot(root))
+	agg.Aggregate(map[string]analyze.Report{"deadcode": report})
+
+	result := agg.GetResult()
+
+	assert.Equal(t, 5, result[KeySymbols])
+	assert.Equal(t, []DeadSymbol{
+		{File: "pkg/server.go", Name: "Server", Kind: KindType, Line: 1},
+		{File: "pkg/server.go", Name: "Handler", Kind: KindType, Line: 2},
+		{File: "pkg/server.go", Name: "Unused", Kind: KindFunction, Line: 6},
+	}, result[KeyDead])
+
+	m := ComputeAllMetrics(result)
+	assert.InDelta(t, 0.6, m.DeadRatio, 1e-9)
+	assert.Equal(t, map[string]int{KindType: 2, KindFunction: 1}, m.DeadByKind)
+
+	section := NewReportSection(result)
+	assert.Equal(t, StatusDead, section.StatusMessage())
+	assert.InDelta(t, 0.4, section.Score(), 1e-9)
+	require.Len(t, section.AllIssues(), 3)
+	assert.Equal(t, "pkg/server.go:6", section.AllIssues()[2].Location)
+}
+
+func TestReportSection_NoSymbols(t *testing.T) {
+	t.Parallel()
+
+	section := NewReportSection(NewAggregator().GetResult())
+
+	assert.Equal(t, StatusNoSymbols, section.StatusMessage())
+	assert.InDelta(t, analyze.ScoreInfoOnly, section.Score(), 0)
+	assert.Empty(t, section.Distribution())
+}
@@ -0,0 +1,300 @@
+package deadcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+)
+
+// Configuration option keys for the deadcode history analyzer.
+const (
+	ConfigDeadCodeMaxFileSize = "DeadCode.MaxFileSize"
+
+	defaultMaxFileSize = 1 << 20
+	// countsSize estimates the bytes of one TickData held by the aggregator.
+	countsSize = 32
+)
+
+// Report keys of the history analyzer.
+const (
+	KeyTicks    = "ticks"
+	KeyTickSize = "tick_size"
+)
+
+// ErrParserNotInitialized indicates Consume ran before Initialize.
+var ErrParserNotInitialized = errors.New("parser not initialized")
+
+// TickData holds the symbol counts after the last commit of a tick that
+// changed them. It is both the per-commit TC payload and the per-tick state.
+type TickData struct {
+	Symbols int
+	Dead    int
+}
+
+// HistoryAnalyzer replays the commit history and tracks how many exported
+// symbols nothing references after every commit.
+type HistoryAnalyzer struct {
+	*analyze.BaseHistoryAnalyzer[*HistoryMetrics]
+
+	TreeDiff  *plumbing.TreeDiffAnalyzer
+	BlobCache *plumbing.BlobCacheAnalyzer
+	Ticks     *plumbing.TicksSinceStart
+
+	MaxFileSize int
+
+	parser   *uast.Parser
+	index    *index
+	last     TickData
+	tickSize time.Duration
+}
+
+// NewHistoryAnalyzer creates a new HistoryAnalyzer.
+func NewHistoryAnalyzer() *HistoryAnalyzer {
+	a := &HistoryAnalyzer{MaxFileSize: defaultMaxFileSize}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*HistoryMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/deadcode",
+			Mode: analyze.ModeHistory,
+			Description: "Tracks exported functions, methods and types that no file references, " +
+				"showing how dead code accumulates over time.",
+		},
+		Sequential: true,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, Memory: analyze.MemoryMedium},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigDeadCodeMaxFileSize,
+				Description: "Specifies the file size threshold. Files that exceed it are not analyzed.",
+				Flag:        "deadcode-max-file-size",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultMaxFileSize,
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*HistoryMetrics, error) {
+			return ComputeHistoryMetrics(report), nil
+		},
+		AggregatorFn: newHistoryAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (h *HistoryAnalyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigDeadCodeMaxFileSize].(int); exists && val > 0 {
+		h.MaxFileSize = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		h.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize resets the tracked tree and loads the UAST parser.
+func (h *HistoryAnalyzer) Initialize(_ *gitlib.Repository) error {
+	if h.MaxFileSize <= 0 {
+		h.MaxFileSize = defaultMaxFileSize
+	}
+
+	h.index = newIndex()
+	h.last = TickData{}
+
+	var err error
+
+	h.parser, err = uast.NewParser()
+	if err != nil {
+		return fmt.Errorf("failed to initialize UAST parser: %w", err)
+	}
+
+	return nil
+}
+
+// Consume updates the tracked symbols with the files the commit changed and
+// emits the counts when they changed.
+func (h *HistoryAnalyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if h.parser == nil || h.index == nil {
+		return analyze.TC{}, ErrParserNotInitialized
+	}
+
+	for _, change := range h.TreeDiff.Changes {
+		switch change.Action {
+		case gitlib.Delete:
+			h.index.remove(change.From.Name)
+		case gitlib.Modify, gitlib.Insert:
+			if change.Action == gitlib.Modify && change.From.Name != change.To.Name {
+				h.index.remove(change.From.Name)
+			}
+
+			h.readFile(ctx, change.To)
+		}
+	}
+
+	counts := TickData{Symbols: h.index.symbols, Dead: h.index.dead}
+	if counts == h.last {
+		return analyze.TC{}, nil
+	}
+
+	h.last = counts
+
+	return analyze.TC{
+		Data:       &counts,
+		CommitHash: ac.Commit.Hash(),
+	}, nil
+}
+
+// readFile indexes the symbols of entry. Files that are too large or that
+// the parser does not support are dropped from the index.
+func (h *HistoryAnalyzer) readFile(ctx context.Context, entry gitlib.ChangeEntry) {
+	blob := h.BlobCache.Cache[entry.Hash]
+	if blob == nil || blob.Size() > int64(h.MaxFileSize) || !h.parser.IsSupported(entry.Name) {
+		h.index.remove(entry.Name)
+
+		return
+	}
+
+	root, err := h.parser.Parse(ctx, entry.Name, blob.Data)
+	if err != nil {
+		h.index.remove(entry.Name)
+
+		return
+	}
+
+	h.index.set(entry.Name, ExtractSymbols(root))
+}
+
+// Fork creates copies of the analyzer that share the tracked tree.
+func (h *HistoryAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *h
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.BlobCache = &plumbing.BlobCacheAnalyzer{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (h *HistoryAnalyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (h *HistoryAnalyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes:   h.TreeDiff.Changes,
+		BlobCache: h.BlobCache.Cache,
+		Tick:      h.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (h *HistoryAnalyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	h.TreeDiff.Changes = snapshot.Changes
+	h.BlobCache.Cache = snapshot.BlobCache
+	h.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot is a no-op for deadcode.
+func (h *HistoryAnalyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (h *HistoryAnalyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return h.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (h *HistoryAnalyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return h.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the counts of every tick that changed them, and the
+// dead symbols of the last analyzed commit.
+func (h *HistoryAnalyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	var series []TickCounts
+
+	for _, tick := range ticks {
+		if td, ok := tick.Data.(*TickData); ok && td != nil {
+			series = append(series, TickCounts{Tick: tick.Tick, Symbols: td.Symbols, Dead: td.Dead})
+		}
+	}
+
+	var dead []DeadSymbol
+	if h.index != nil {
+		dead = h.index.deadSymbols()
+	}
+
+	return analyze.Report{
+		KeyTicks:    series,
+		KeyDead:     dead,
+		KeyTickSize: h.tickSize,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	counts, ok := tc.Data.(*TickData)
+	if !ok || counts == nil {
+		return nil
+	}
+
+	// Commits arrive in order, so the last one of a tick wins.
+	byTick[tc.Tick] = counts
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if incoming == nil {
+		return existing
+	}
+
+	return incoming
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	return countsSize
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newHistoryAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
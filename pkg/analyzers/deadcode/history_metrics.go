@@ -0,0 +1,50 @@
+package deadcode
+
+import (
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+)
+
+// TickCounts are the exported and unreferenced symbols at the end of a tick.
+type TickCounts struct {
+	Tick      int     `json:"tick"       yaml:"tick"`
+	Symbols   int     `json:"symbols"    yaml:"symbols"`
+	Dead      int     `json:"dead"       yaml:"dead"`
+	DeadRatio float64 `json:"dead_ratio" yaml:"dead_ratio"`
+}
+
+// HistoryMetrics is the accumulation of dead code over the history.
+type HistoryMetrics struct {
+	// Ticks lists the ticks whose commits changed the counts.
+	Ticks []TickCounts `json:"ticks" yaml:"ticks"`
+	// Growth is the change in unreferenced symbols from the first to the
+	// last tick.
+	Growth int `json:"growth" yaml:"growth"`
+	// PeakDead is the most unreferenced symbols at the end of any tick.
+	PeakDead int `json:"peak_dead" yaml:"peak_dead"`
+	// Dead lists the unreferenced symbols of the last analyzed commit.
+	Dead []DeadSymbol `json:"dead" yaml:"dead"`
+}
+
+// ComputeHistoryMetrics computes the dead code trend of a history report.
+func ComputeHistoryMetrics(report analyze.Report) *HistoryMetrics {
+	ticks, _ := report[KeyTicks].([]TickCounts)
+	dead, _ := report[KeyDead].([]DeadSymbol)
+
+	m := &HistoryMetrics{Ticks: make([]TickCounts, 0, len(ticks)), Dead: dead}
+
+	for _, tc := range ticks {
+		if tc.Symbols > 0 {
+			tc.DeadRatio = reportutil.Pct(tc.Dead, tc.Symbols)
+		}
+
+		m.PeakDead = max(m.PeakDead, tc.Dead)
+		m.Ticks = append(m.Ticks, tc)
+	}
+
+	if len(m.Ticks) > 0 {
+		m.Growth = m.Ticks[len(m.Ticks)-1].Dead - m.Ticks[0].Dead
+	}
+
+	return m
+}
@@ -0,0 +1,56 @@
+package deadcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestComputeHistoryMetrics(t *testing.T) {
+	t.Parallel()
+
+	dead := []DeadSymbol{{File: "a.go", Name: "Stop", Kind: KindFunction, Line: 9}}
+
+	m := ComputeHistoryMetrics(analyze.Report{
+		KeyTicks: []TickCounts{
+			{Tick: 0, Symbols: 10, Dead: 1},
+			{Tick: 2, Symbols: 20, Dead: 5},
+			{Tick: 7, Symbols: 16, Dead: 4},
+		},
+		KeyDead: dead,
+	})
+
+	require.Len(t, m.Ticks, 3)
+	assert.InDelta(t, 0.25, m.Ticks[1].DeadRatio, 1e-9)
+	assert.Equal(t, 3, m.Growth)
+	assert.Equal(t, 5, m.PeakDead)
+	assert.Equal(t, dead, m.Dead)
+}
+
+func TestHistoryAnalyzer_Configure(t *testing.T) {
+	t.Parallel()
+
+	h := NewHistoryAnalyzer()
+	require.NoError(t, h.Configure(map[string]any{ConfigDeadCodeMaxFileSize: 10}))
+	assert.Equal(t, 10, h.MaxFileSize)
+	assert.Equal(t, "deadcode", h.Flag())
+
+	_, err := h.Consume(t.Context(), &analyze.Context{})
+	require.ErrorIs(t, err, ErrParserNotInitialized)
+}
+
+func TestHistoryAggregator_LastCommitOfTickWins(t *testing.T) {
+	t.Parallel()
+
+	byTick := make(map[int]*TickData)
+
+	require.NoError(t, extractTC(analyze.TC{Tick: 1, Data: &TickData{Symbols: 3, Dead: 1}}, byTick))
+	require.NoError(t, extractTC(analyze.TC{Tick: 1, Data: &TickData{Symbols: 4, Dead: 2}}, byTick))
+	require.NoError(t, extractTC(analyze.TC{Tick: 2}, byTick))
+
+	assert.Equal(t, map[int]*TickData{1: {Symbols: 4, Dead: 2}}, byTick)
+	assert.Equal(t, &TickData{Dead: 3}, mergeState(&TickData{Dead: 1}, &TickData{Dead: 3}))
+}
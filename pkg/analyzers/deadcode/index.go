@@ -0,0 +1,140 @@
+package deadcode
+
+import (
+	"cmp"
+	"slices"
+)
+
+// DeadSymbol is an exported declaration that no analyzed file references.
+type DeadSymbol struct {
+	File string `json:"file" yaml:"file"`
+	Name string `json:"name" yaml:"name"`
+	Kind string `json:"kind" yaml:"kind"`
+	Line int    `json:"line" yaml:"line"`
+}
+
+// index relates the declarations of every file to the references of every
+// file by name. It keeps the symbol and dead counts up to date as files
+// change, so a history replay pays only for the files a commit touches.
+type index struct {
+	files map[string]FileSymbols
+	decls map[string]int // Name -> exported declarations outside test files.
+	refs  map[string]int // Name -> files that reference it.
+
+	symbols int
+	dead    int
+}
+
+func newIndex() *index {
+	return &index{
+		files: make(map[string]FileSymbols),
+		decls: make(map[string]int),
+		refs:  make(map[string]int),
+	}
+}
+
+// set replaces the symbols of file. Declarations of test files are dropped;
+// their references count.
+func (ix *index) set(file string, symbols FileSymbols) {
+	if IsTestFile(file) {
+		symbols.Declarations = nil
+	}
+
+	old, existed := ix.files[file]
+	touched := touchedNames(old, symbols)
+
+	ix.uncount(touched)
+
+	if existed {
+		ix.apply(old, -1)
+	}
+
+	ix.files[file] = symbols
+	ix.apply(symbols, 1)
+	ix.count(touched)
+}
+
+// remove forgets file.
+func (ix *index) remove(file string) {
+	old, existed := ix.files[file]
+	if !existed {
+		return
+	}
+
+	touched := touchedNames(old, FileSymbols{})
+
+	ix.uncount(touched)
+	ix.apply(old, -1)
+	delete(ix.files, file)
+	ix.count(touched)
+}
+
+// deadSymbols lists the unreferenced declarations, by file and line.
+func (ix *index) deadSymbols() []DeadSymbol {
+	var dead []DeadSymbol
+
+	for file, symbols := range ix.files {
+		for _, d := range symbols.Declarations {
+			if ix.refs[d.Name] == 0 {
+				dead = append(dead, DeadSymbol{File: file, Name: d.Name, Kind: d.Kind, Line: d.Line})
+			}
+		}
+	}
+
+	slices.SortFunc(dead, func(x, y DeadSymbol) int {
+		return cmp.Or(cmp.Compare(x.File, y.File), cmp.Compare(x.Line, y.Line), cmp.Compare(x.Name, y.Name))
+	})
+
+	return dead
+}
+
+func (ix *index) apply(symbols FileSymbols, delta int) {
+	for _, d := range symbols.Declarations {
+		ix.decls[d.Name] += delta
+		if ix.decls[d.Name] == 0 {
+			delete(ix.decls, d.Name)
+		}
+	}
+
+	for _, name := range symbols.References {
+		ix.refs[name] += delta
+		if ix.refs[name] == 0 {
+			delete(ix.refs, name)
+		}
+	}
+}
+
+// uncount and count take the touched names out of and back into the totals.
+func (ix *index) uncount(names map[string]bool) {
+	for name := range names {
+		ix.symbols -= ix.decls[name]
+		if ix.refs[name] == 0 {
+			ix.dead -= ix.decls[name]
+		}
+	}
+}
+
+func (ix *index) count(names map[string]bool) {
+	for name := range names {
+		ix.symbols += ix.decls[name]
+		if ix.refs[name] == 0 {
+			ix.dead += ix.decls[name]
+		}
+	}
+}
+
+func touchedNames(files ...FileSymbols) map[string]bool {
+	names := make(map[string]bool)
+
+	for _, symbols := range files {
+		for _, d := range symbols.Declarations {
+			names[d.Name] = true
+		}
+
+		for _, name := range symbols.References {
+			names[name] = true
+		}
+	}
+
+	return names
+}
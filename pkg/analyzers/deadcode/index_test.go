@@ -0,0 +1,56 @@
+package deadcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex_IncrementalCounts(t *testing.T) {
+	t.Parallel()
+
+	ix := newIndex()
+
+	ix.set("a.go", FileSymbols{Declarations: []Declaration{
+		{Name: "Run", Kind: KindFunction, Line: 3},
+		{Name: "Stop", Kind: KindFunction, Line: 9},
+	}})
+	assert.Equal(t, 2, ix.symbols)
+	assert.Equal(t, 2, ix.dead)
+
+	ix.set("main.go", FileSymbols{References: []string{"Run"}})
+	assert.Equal(t, 1, ix.dead)
+	assert.Equal(t, []DeadSymbol{{File: "a.go", Name: "Stop", Kind: KindFunction, Line: 9}}, ix.deadSymbols())
+
+	// Test files reference symbols but declare none.
+	ix.set("a_test.go", FileSymbols{
+		Declarations: []Declaration{{Name: "TestRun", Kind: KindFunction, Line: 1}},
+		References:   []string{"Stop"},
+	})
+	assert.Equal(t, 2, ix.symbols)
+	assert.Equal(t, 0, ix.dead)
+
+	ix.remove("a_test.go")
+	ix.set("main.go", FileSymbols{})
+	assert.Equal(t, 2, ix.dead)
+
+	ix.remove("a.go")
+	assert.Equal(t, 0, ix.symbols)
+	assert.Equal(t, 0, ix.dead)
+	assert.Empty(t, ix.deadSymbols())
+}
+
+func TestIndex_SharedNames(t *testing.T) {
+	t.Parallel()
+
+	ix := newIndex()
+
+	ix.set("a.go", FileSymbols{Declarations: []Declaration{{Name: "New", Kind: KindFunction, Line: 1}}})
+	ix.set("b.go", FileSymbols{Declarations: []Declaration{{Name: "New", Kind: KindFunction, Line: 1}}})
+	assert.Equal(t, 2, ix.dead)
+
+	// One reference by name keeps every declaration of that name alive.
+	ix.set("c.go", FileSymbols{References: []string{"New"}})
+	assert.Equal(t, 0, ix.dead)
+	assert.Equal(t, 2, ix.symbols)
+}
@@ -0,0 +1,38 @@
+package deadcode
+
+import (
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+)
+
+// ComputedMetrics are the unreferenced exported symbols of a source tree.
+type ComputedMetrics struct {
+	Symbols int `json:"symbols" yaml:"symbols"`
+	Dead    int `json:"dead"    yaml:"dead"`
+	// DeadRatio is the share of exported symbols that nothing references.
+	DeadRatio   float64        `json:"dead_ratio"   yaml:"dead_ratio"`
+	DeadByKind  map[string]int `json:"dead_by_kind" yaml:"dead_by_kind"`
+	DeadSymbols []DeadSymbol   `json:"dead_symbols" yaml:"dead_symbols"`
+}
+
+// ComputeAllMetrics computes the dead code metrics of a static report.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	dead, _ := report[KeyDead].([]DeadSymbol)
+
+	m := &ComputedMetrics{
+		Symbols:     reportutil.GetInt(report, KeySymbols),
+		Dead:        len(dead),
+		DeadByKind:  make(map[string]int),
+		DeadSymbols: dead,
+	}
+
+	for _, d := range dead {
+		m.DeadByKind[d.Kind]++
+	}
+
+	if m.Symbols > 0 {
+		m.DeadRatio = reportutil.Pct(m.Dead, m.Symbols)
+	}
+
+	return m
+}
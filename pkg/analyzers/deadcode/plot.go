@@ -0,0 +1,107 @@
+package deadcode
+
+import (
+	"html"
+	"io"
+	"strconv"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+)
+
+// RegisterPlotSections registers the deadcode plot section renderers with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("static/deadcode", func(report analyze.Report) ([]plotpage.Section, error) {
+		return generateStaticSections(report), nil
+	})
+	analyze.RegisterPlotSections("history/deadcode", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&HistoryAnalyzer{}).GenerateSections(report)
+	})
+}
+
+// FormatReportPlot renders the static dead code report as an HTML page.
+func (a *Analyzer) FormatReportPlot(report analyze.Report, w io.Writer) error {
+	page := plotpage.NewPage(
+		"Dead Code",
+		"Exported functions, methods and types that no analyzed file references",
+	)
+
+	page.Add(generateStaticSections(report)...)
+
+	return page.Render(w)
+}
+
+func generateStaticSections(report analyze.Report) []plotpage.Section {
+	m := ComputeAllMetrics(report)
+
+	return []plotpage.Section{
+		{
+			Title: "Unreferenced Exported Symbols",
+			Subtitle: strconv.Itoa(m.Dead) + " of " + strconv.Itoa(m.Symbols) + " exported symbols (" +
+				reportutil.FormatPercent(m.DeadRatio) + ") are not referenced by name.",
+			Chart: deadTable(m.DeadSymbols),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Symbols are matched by name, so a listed symbol is not referenced anywhere in the analyzed files",
+					"Public APIs used by other repositories and symbols called by reflection show up too",
+					"Action: Delete what is truly unused, or unexport it to make the intent visible",
+				},
+			},
+		},
+	}
+}
+
+// GenerateSections returns the sections for combined reports.
+func (h *HistoryAnalyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeHistoryMetrics(report)
+
+	return []plotpage.Section{
+		{
+			Title:    "Dead Code Accumulation",
+			Subtitle: "Exported symbols and the unreferenced ones among them, per tick.",
+			Chart:    plotpage.WrapChart(buildTrendChart(m)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"A dead line that grows faster than the symbols line = code is left behind by changes",
+					"Drops = cleanups",
+				},
+			},
+		},
+		{
+			Title:    "Unreferenced Symbols Now",
+			Subtitle: strconv.Itoa(len(m.Dead)) + " unreferenced exported symbols at the last analyzed commit.",
+			Chart:    deadTable(m.Dead),
+		},
+	}, nil
+}
+
+func deadTable(dead []DeadSymbol) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Symbol", "Kind", "File", "Line"}).WithSearch("Filter symbols...")
+	for _, d := range dead {
+		table.AddRow(html.EscapeString(d.Name), kindLabels[d.Kind], html.EscapeString(d.File), strconv.Itoa(d.Line))
+	}
+
+	return table
+}
+
+func buildTrendChart(m *HistoryMetrics) *charts.Line {
+	labels := make([]string, len(m.Ticks))
+	symbols := make([]plotpage.SeriesData, len(m.Ticks))
+	dead := make([]plotpage.SeriesData, len(m.Ticks))
+
+	for i, tc := range m.Ticks {
+		labels[i] = strconv.Itoa(tc.Tick)
+		symbols[i] = tc.Symbols
+		dead[i] = tc.Dead
+	}
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: "Exported symbols", Data: symbols},
+		{Name: "Unreferenced", Data: dead},
+	}, "Symbols")
+}
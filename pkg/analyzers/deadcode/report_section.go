@@ -0,0 +1,121 @@
+package deadcode
+
+import (
+	"strconv"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
+)
+
+// Section rendering constants.
+const (
+	SectionTitle = "DEAD CODE"
+
+	// Metric labels.
+	MetricSymbols   = "Exported Symbols"
+	MetricDead      = "Unreferenced"
+	MetricDeadRatio = "Dead Ratio"
+
+	StatusNoSymbols = "No exported symbols found"
+	StatusClean     = "Clean - every exported symbol is referenced"
+	StatusDead      = "Unreferenced exported symbols found"
+)
+
+// kindLabels names the declaration kinds in issues and distributions.
+var kindLabels = map[string]string{
+	KindFunction: "Function",
+	KindMethod:   "Method",
+	KindType:     "Type",
+}
+
+// ReportSection implements analyze.ReportSection for dead code.
+// Its score is the share of exported symbols that are referenced.
+type ReportSection struct {
+	analyze.BaseReportSection
+
+	metrics *ComputedMetrics
+}
+
+// NewReportSection creates a ReportSection from a deadcode report.
+func NewReportSection(report analyze.Report) *ReportSection {
+	if report == nil {
+		report = analyze.Report{}
+	}
+
+	m := ComputeAllMetrics(report)
+
+	section := &ReportSection{
+		BaseReportSection: analyze.BaseReportSection{
+			Title:      SectionTitle,
+			Message:    StatusClean,
+			ScoreValue: 1 - m.DeadRatio,
+		},
+		metrics: m,
+	}
+
+	switch {
+	case m.Symbols == 0:
+		section.Message = StatusNoSymbols
+		section.ScoreValue = analyze.ScoreInfoOnly
+	case m.Dead > 0:
+		section.Message = StatusDead
+	}
+
+	return section
+}
+
+// KeyMetrics returns the key metrics for the dead code section.
+func (s *ReportSection) KeyMetrics() []analyze.Metric {
+	return []analyze.Metric{
+		{Label: MetricSymbols, Value: reportutil.FormatInt(s.metrics.Symbols)},
+		{Label: MetricDead, Value: reportutil.FormatInt(s.metrics.Dead)},
+		{Label: MetricDeadRatio, Value: reportutil.FormatPercent(s.metrics.DeadRatio)},
+	}
+}
+
+// Distribution returns the dead symbols by kind.
+func (s *ReportSection) Distribution() []analyze.DistributionItem {
+	if s.metrics.Dead == 0 {
+		return nil
+	}
+
+	var items []analyze.DistributionItem
+
+	for _, kind := range []string{KindFunction, KindMethod, KindType} {
+		if count := s.metrics.DeadByKind[kind]; count > 0 {
+			items = append(items, analyze.DistributionItem{
+				Label:   kindLabels[kind],
+				Percent: reportutil.Pct(count, s.metrics.Dead),
+				Count:   count,
+			})
+		}
+	}
+
+	return items
+}
+
+// TopIssues returns the first n dead symbols.
+func (s *ReportSection) TopIssues(n int) []analyze.Issue {
+	issues := s.AllIssues()
+	if n >= len(issues) {
+		return issues
+	}
+
+	return issues[:n]
+}
+
+// AllIssues returns every dead symbol as a fair-severity issue.
+func (s *ReportSection) AllIssues() []analyze.Issue {
+	issues := make([]analyze.Issue, 0, len(s.metrics.DeadSymbols))
+
+	for _, d := range s.metrics.DeadSymbols {
+		issues = append(issues, analyze.Issue{
+			Name:     d.Name,
+			Location: d.File + ":" + strconv.Itoa(d.Line),
+			Value:    kindLabels[d.Kind],
+			Severity: analyze.SeverityFair,
+		})
+	}
+
+	return issues
+}
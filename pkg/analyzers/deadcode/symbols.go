@@ -0,0 +1,205 @@
+package deadcode
+
+import (
+	"path"
+	"strings"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common"
+	"github.com/Sumatoshi-tech/codefang/pkg/safeconv"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// Kinds of declarations.
+const (
+	KindFunction = "function"
+	KindMethod   = "method"
+	KindType     = "type"
+)
+
+// implicitMethods are methods that the runtime or the standard library call
+// through an interface, so they are used without a reference by name.
+var implicitMethods = map[string]bool{
+	"String": true, "GoString": true, "Format": true, "Error": true, "Unwrap": true, "Is": true, "As": true,
+	"MarshalJSON": true, "UnmarshalJSON": true, "MarshalYAML": true, "UnmarshalYAML": true,
+	"MarshalText": true, "UnmarshalText": true, "MarshalBinary": true, "UnmarshalBinary": true,
+	"ServeHTTP": true, "Len": true, "Less": true, "Swap": true,
+	"Read": true, "Write": true, "Close": true, "Scan": true, "Value": true,
+}
+
+// testFileSuffixes mark test files, whose declarations are run by a test
+// runner instead of being referenced.
+var testFileSuffixes = []string{
+	"_test.go", "_test.py", "Test.java", "Tests.java", "Test.kt", "Tests.cs",
+	".test.js", ".test.ts", ".test.jsx", ".test.tsx", ".spec.js", ".spec.ts", ".spec.jsx", ".spec.tsx",
+}
+
+// Declaration is an exported function, method or type.
+type Declaration struct {
+	Name string `json:"name" yaml:"name"`
+	Kind string `json:"kind" yaml:"kind"`
+	Line int    `json:"line" yaml:"line"`
+}
+
+// FileSymbols are the exported declarations of one file and the names it
+// references.
+type FileSymbols struct {
+	Declarations []Declaration
+	// References is deduplicated, in source order.
+	References []string
+}
+
+// ExtractSymbols returns the exported top-level declarations of a UAST node
+// tree and every name it references. A name is a reference unless it names
+// a declaration, is the receiver of a method, or is a recursive use of the
+// declaration it appears in. Declarations inside interfaces only reference
+// their name, so the methods implementing them stay alive.
+func ExtractSymbols(root *node.Node) FileSymbols {
+	w := &walker{seen: make(map[string]bool)}
+	w.visit(root, scope{})
+
+	return FileSymbols{Declarations: w.decls, References: w.refs}
+}
+
+// IsTestFile reports whether file is a test file by its name.
+func IsTestFile(file string) bool {
+	base := path.Base(file)
+	if strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py") {
+		return true
+	}
+
+	for _, suffix := range testFileSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scope is what the walker knows about the enclosing nodes.
+type scope struct {
+	self        string // Name of the enclosing top-level declaration.
+	inFunction  bool
+	inInterface bool
+}
+
+type walker struct {
+	decls []Declaration
+	refs  []string
+	seen  map[string]bool
+}
+
+func (w *walker) visit(n *node.Node, sc scope) {
+	if n == nil {
+		return
+	}
+
+	if n.Token != "" && n.HasAnyRole(node.RoleName) && n.Token != sc.self {
+		w.reference(n.Token)
+	}
+
+	kind, name := declarationOf(n)
+	topLevel := kind != "" && !sc.inFunction && !sc.inInterface
+
+	var nameChild, receiver *node.Node
+
+	if topLevel {
+		nameChild = findNameChild(n, name)
+		if kind == KindMethod {
+			receiver = findReceiver(n, nameChild)
+		}
+
+		if common.IsExported(n, name) && (kind != KindMethod || !implicitMethods[name]) {
+			w.decls = append(w.decls, Declaration{Name: name, Kind: kind, Line: startLine(n)})
+		}
+
+		if sc.self == "" {
+			sc.self = name
+		}
+	}
+
+	if kind == KindFunction || kind == KindMethod {
+		sc.inFunction = true
+	}
+
+	if n.Type == node.UASTInterface || n.HasAnyRole(node.RoleInterface) {
+		sc.inInterface = true
+	}
+
+	for _, child := range n.Children {
+		if child == nameChild || child == receiver {
+			continue
+		}
+
+		w.visit(child, sc)
+	}
+}
+
+func (w *walker) reference(name string) {
+	if !w.seen[name] {
+		w.seen[name] = true
+		w.refs = append(w.refs, name)
+	}
+}
+
+// declarationOf returns the kind and name of a function, method or type
+// declaration, or empty strings for any other node.
+func declarationOf(n *node.Node) (kind, name string) {
+	name = n.Props["name"]
+	if name == "" {
+		return "", ""
+	}
+
+	switch {
+	case n.HasAnyRole(node.RoleFunction) && n.HasAnyRole(node.RoleDeclaration):
+		if n.Type == node.UASTMethod || n.HasAnyRole(node.RoleMember) {
+			return KindMethod, name
+		}
+
+		return KindFunction, name
+	case n.HasAnyRole(node.RoleClass, node.RoleStruct, node.RoleInterface, node.RoleEnum):
+		return KindType, name
+	}
+
+	// Declarations such as Go's type specs carry no role themselves; their
+	// name does.
+	if child := findNameChild(n, name); child != nil && child.HasAnyRole(node.RoleType) {
+		return KindType, name
+	}
+
+	return "", ""
+}
+
+func findNameChild(n *node.Node, name string) *node.Node {
+	for _, child := range n.Children {
+		if child.Token == name && child.HasAnyRole(node.RoleName) {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// findReceiver returns the parameter list that precedes the name of a
+// method, as the receiver does in Go.
+func findReceiver(n, nameChild *node.Node) *node.Node {
+	for _, child := range n.Children {
+		if child == nameChild {
+			return nil
+		}
+
+		if child.HasAnyRole(node.RoleParameter) {
+			return child
+		}
+	}
+
+	return nil
+}
+
+func startLine(n *node.Node) int {
+	if n.Pos == nil {
+		return 0
+	}
+
+	return safeconv.MustUintToInt(n.Pos.StartLine)
+}
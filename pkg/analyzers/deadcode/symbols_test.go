@@ -0,0 +1,140 @@
+package deadcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+func ident(name string, roles ...node.Role) *node.Node {
+	return &node.Node{Type: node.UASTIdentifier, Token: name, Roles: append([]node.Role{node.RoleName}, roles...)}
+}
+
+func call(name string) *node.Node {
+	return &node.Node{Type: node.UASTCall, Roles: []node.Role{node.RoleCall}, Children: []*node.Node{ident(name)}}
+}
+
+func function(name string, line uint, body ...*node.Node) *node.Node {
+	return &node.Node{
+		Type:     node.UASTFunction,
+		Roles:    []node.Role{node.RoleFunction, node.RoleDeclaration},
+		Props:    map[string]string{"name": name},
+		Pos:      &node.Positions{StartLine: line},
+		Children: append([]*node.Node{ident(name)}, body...),
+	}
+}
+
+// goFile mirrors the UAST of:
+//
+//	type Server struct{}
+//	type Handler interface{ Handle() }
+//	func (s *Server) Handle() { Run() }
+//	func (s *Server) String() string
+//	func Run() { Run(); helper() }
+//	func Unused() {}
+//	func helper() {}
+func goFile() *node.Node {
+	server := &node.Node{
+		Type:     node.UASTList,
+		Props:    map[string]string{"name": "Server"},
+		Pos:      &node.Positions{StartLine: 1},
+		Children: []*node.Node{ident("Server", node.RoleType), {Type: node.UASTStruct}},
+	}
+	handler := &node.Node{
+		Type:  node.UASTList,
+		Props: map[string]string{"name": "Handler"},
+		Pos:   &node.Positions{StartLine: 2},
+		Children: []*node.Node{ident("Handler", node.RoleType), {
+			Type:  node.UASTInterface,
+			Roles: []node.Role{node.RoleInterface, node.RoleDeclaration},
+			Children: []*node.Node{{
+				Type:     node.UASTMethod,
+				Roles:    []node.Role{node.RoleFunction, node.RoleDeclaration, node.RoleMember},
+				Props:    map[string]string{"name": "Handle"},
+				Children: []*node.Node{ident("Handle")},
+			}},
+		}},
+	}
+	method := func(name string, line uint, body ...*node.Node) *node.Node {
+		receiver := &node.Node{Type: node.UASTParameter, Roles: []node.Role{node.RoleParameter}, Children: []*node.Node{
+			ident("s"), ident("Server", node.RoleType),
+		}}
+
+		return &node.Node{
+			Type:     node.UASTMethod,
+			Roles:    []node.Role{node.RoleFunction, node.RoleDeclaration, node.RoleMember},
+			Props:    map[string]string{"name": name},
+			Pos:      &node.Positions{StartLine: line},
+			Children: append([]*node.Node{receiver, ident(name)}, body...),
+		}
+	}
+
+	return &node.Node{Type: node.UASTFile, Children: []*node.Node{
+		server,
+		handler,
+		method("Handle", 3, call("Run")),
+		method("String", 4),
+		function("Run", 5, call("Run"), call("helper")),
+		function("Unused", 6),
+		function("helper", 7),
+	}}
+}
+
+func TestExtractSymbols(t *testing.T) {
+	t.Parallel()
+
+	symbols := ExtractSymbols(goFile())
+
+	assert.Equal(t, []Declaration{
+		{Name: "Server", Kind: KindType, Line: 1},
+		{Name: "Handler", Kind: KindType, Line: 2},
+		{Name: "Handle", Kind: KindMethod, Line: 3},
+		{Name: "Run", Kind: KindFunction, Line: 5},
+		{Name: "Unused", Kind: KindFunction, Line: 6},
+	}, symbols.Declarations)
+
+	// Receivers, declared names and recursion are not references; interface
+	// methods are.
+	assert.Equal(t, []string{"Handle", "Run", "helper"}, symbols.References)
+}
+
+func TestExtractSymbols_NestedAndPrivate(t *testing.T) {
+	t.Parallel()
+
+	private := function("Hidden", 2)
+	private.Roles = append(private.Roles, node.RolePrivate)
+
+	public := function("visible", 3)
+	public.Roles = append(public.Roles, node.RolePublic)
+
+	root := &node.Node{Type: node.UASTFile, Children: []*node.Node{
+		function("Outer", 1, function("Inner", 1)),
+		private,
+		public,
+	}}
+
+	symbols := ExtractSymbols(root)
+
+	assert.Equal(t, []Declaration{
+		{Name: "Outer", Kind: KindFunction, Line: 1},
+		{Name: "visible", Kind: KindFunction, Line: 3},
+	}, symbols.Declarations)
+	assert.Equal(t, []string{"Inner"}, symbols.References)
+}
+
+func TestIsTestFile(t *testing.T) {
+	t.Parallel()
+
+	for file, want := range map[string]bool{
+		"pkg/a/a_test.go":        true,
+		"tests/test_api.py":      true,
+		"src/FooTest.java":       true,
+		"web/app.spec.ts":        true,
+		"pkg/a/a.go":             false,
+		"pkg/testing/fixture.go": false,
+	} {
+		assert.Equal(t, want, IsTestFile(file), file)
+	}
+}
@@ -0,0 +1,341 @@
+// Package defects classifies fix commits by their messages and ranks files
+// by fault density and a churn-times-past-fixes defect prediction score.
+package defects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Configuration option keys for the defects analyzer.
+const (
+	ConfigDefectsFixPattern   = "Defects.FixPattern"
+	ConfigDefectsIssuePattern = "Defects.IssuePattern"
+)
+
+// Report keys of the defects analyzer.
+const (
+	KeyFiles    = "files"
+	KeyTicks    = "ticks"
+	KeyTickSize = "tick_size"
+
+	// tickBytes and fileBytes estimate the bytes of one TickData and of one
+	// fixed file name held by the aggregator.
+	tickBytes = 64
+	fileBytes = 64
+)
+
+// ErrInvalidPattern indicates a fix or issue pattern that is not a valid
+// regular expression.
+var ErrInvalidPattern = errors.New("invalid defects pattern")
+
+// CommitDefects is the per-commit payload.
+type CommitDefects struct {
+	Fix   bool
+	Churn int
+	// Fixed lists the files a fix changed.
+	Fixed []string
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	Commits  int
+	Fixes    int
+	Churn    int
+	FixChurn int
+	Fixed    map[string]bool
+}
+
+// TickDefects counts the commits, fixes and churn of one tick.
+type TickDefects struct {
+	Tick     int `json:"tick"        yaml:"tick"`
+	Commits  int `json:"commits"     yaml:"commits"`
+	Fixes    int `json:"fixes"       yaml:"fixes"`
+	Churn    int `json:"churn"       yaml:"churn"`
+	FixChurn int `json:"fix_churn"   yaml:"fix_churn"`
+	// FixedFiles is the number of distinct files the fixes of the tick
+	// changed.
+	FixedFiles int `json:"fixed_files" yaml:"fixed_files"`
+}
+
+// Analyzer replays the history, classifies every commit as a fix or not and
+// tracks the churn and fixes of every file.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	TreeDiff  *plumbing.TreeDiffAnalyzer
+	LineStats *plumbing.LinesStatsCalculator
+	Ticks     *plumbing.TicksSinceStart
+
+	// FixPattern matches the subjects of fix commits. IssuePattern, when
+	// set, marks commits whose message links an issue as fixes too.
+	FixPattern   string
+	IssuePattern string
+
+	classifier *classifier
+	ledger     *ledger
+	tickSize   time.Duration
+}
+
+// NewAnalyzer creates a new defects analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{FixPattern: pkgplumbing.FixPattern}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/defects",
+			Mode: analyze.ModeHistory,
+			Description: "Classifies fix commits by their messages and ranks files by fault density " +
+				"and a churn times past fixes defect prediction score.",
+		},
+		Sequential: true,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, NeedsDiffs: true, Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigDefectsFixPattern,
+				Description: "Regular expression matching the subjects of fix commits.",
+				Flag:        "defects-fix-pattern",
+				Type:        pipeline.StringConfigurationOption,
+				Default:     pkgplumbing.FixPattern,
+			},
+			{
+				Name:        ConfigDefectsIssuePattern,
+				Description: "Regular expression matching bug issue links; commits whose message matches are fixes.",
+				Flag:        "defects-issue-pattern",
+				Type:        pipeline.StringConfigurationOption,
+				Default:     "",
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
+			return ComputeAllMetrics(report), nil
+		},
+		AggregatorFn: newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigDefectsFixPattern].(string); exists && val != "" {
+		a.FixPattern = val
+	}
+
+	if val, exists := facts[ConfigDefectsIssuePattern].(string); exists {
+		a.IssuePattern = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		a.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize compiles the patterns and resets the tracked files.
+func (a *Analyzer) Initialize(_ *gitlib.Repository) error {
+	fix, err := regexp.Compile(a.FixPattern)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPattern, err)
+	}
+
+	a.classifier = &classifier{fix: fix}
+
+	if a.IssuePattern != "" {
+		a.classifier.issue, err = regexp.Compile(a.IssuePattern)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPattern, err)
+		}
+	}
+
+	a.ledger = newLedger()
+
+	return nil
+}
+
+// Consume classifies the commit and records its changes. Merge commits are
+// skipped: their changes were made on the merged branch.
+func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac == nil || ac.Commit == nil || ac.IsMerge || len(a.TreeDiff.Changes) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	fix := a.classifier.isFix(ac.Commit.Message())
+	commit := a.ledger.record(a.TreeDiff.Changes, a.LineStats.LineStats, fix, ac.Time)
+
+	return analyze.TC{Data: commit, CommitHash: ac.Commit.Hash()}, nil
+}
+
+// Fork creates copies of the analyzer that share the tracked files.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.LineStats = &plumbing.LinesStatsCalculator{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes:   a.TreeDiff.Changes,
+		LineStats: a.LineStats.LineStats,
+		Tick:      a.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.TreeDiff.Changes = snapshot.Changes
+	a.LineStats.LineStats = snapshot.LineStats
+	a.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot is a no-op for defects.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the counts of every tick and the history of the
+// files that were fixed at least once.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	var series []TickDefects
+
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*TickData)
+		if !ok || td == nil {
+			continue
+		}
+
+		series = append(series, TickDefects{
+			Tick:       tick.Tick,
+			Commits:    td.Commits,
+			Fixes:      td.Fixes,
+			Churn:      td.Churn,
+			FixChurn:   td.FixChurn,
+			FixedFiles: len(td.Fixed),
+		})
+	}
+
+	var files []FileStats
+	if a.ledger != nil {
+		files = a.ledger.fixed()
+	}
+
+	return analyze.Report{
+		KeyFiles:    files,
+		KeyTicks:    series,
+		KeyTickSize: a.tickSize,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	commit, ok := tc.Data.(*CommitDefects)
+	if !ok || commit == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{Fixed: make(map[string]bool)}
+		byTick[tc.Tick] = state
+	}
+
+	state.Commits++
+	state.Churn += commit.Churn
+
+	if commit.Fix {
+		state.Fixes++
+		state.FixChurn += commit.Churn
+	}
+
+	for _, file := range commit.Fixed {
+		state.Fixed[file] = true
+	}
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming == nil {
+		return existing
+	}
+
+	existing.Commits += incoming.Commits
+	existing.Fixes += incoming.Fixes
+	existing.Churn += incoming.Churn
+	existing.FixChurn += incoming.FixChurn
+
+	for file := range incoming.Fixed {
+		existing.Fixed[file] = true
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	return tickBytes + int64(len(state.Fixed))*fileBytes
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || state.Commits == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
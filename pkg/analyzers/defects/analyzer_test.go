@@ -0,0 +1,141 @@
+package defects
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func newTestAnalyzer(t *testing.T) *Analyzer {
+	t.Helper()
+
+	a := NewAnalyzer()
+	a.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+	a.LineStats = &plumbing.LinesStatsCalculator{}
+	a.Ticks = &plumbing.TicksSinceStart{}
+	require.NoError(t, a.Initialize(nil))
+
+	return a
+}
+
+func testHash(c string) gitlib.Hash {
+	return gitlib.NewHash(c + "000000000000000000000000000000000000000")
+}
+
+func TestAnalyzer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	assert.Equal(t, "history/defects", a.Name())
+	assert.NotEmpty(t, a.Description())
+	assert.Len(t, a.ListConfigurationOptions(), 2)
+	assert.True(t, a.SequentialOnly())
+}
+
+func TestAnalyzer_Configure(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(map[string]any{
+		ConfigDefectsFixPattern:   "",
+		ConfigDefectsIssuePattern: `\bBUG-\d+`,
+	}))
+	assert.Equal(t, pkgplumbing.FixPattern, a.FixPattern)
+	assert.Equal(t, `\bBUG-\d+`, a.IssuePattern)
+}
+
+func TestAnalyzer_Initialize_InvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	a.IssuePattern = `BUG-(`
+
+	require.ErrorIs(t, a.Initialize(nil), ErrInvalidPattern)
+}
+
+func TestAnalyzer_Consume(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+	sig := gitlib.Signature{Name: "dev", When: time.Now()}
+
+	before := gitlib.ChangeEntry{Name: "db.go", Hash: testHash("1")}
+	after := gitlib.ChangeEntry{Name: "db.go", Hash: testHash("2")}
+
+	a.TreeDiff.Changes = gitlib.Changes{{Action: gitlib.Modify, From: before, To: after}}
+	a.LineStats.LineStats = map[gitlib.ChangeEntry]pkgplumbing.LineStats{after: {Added: 3, Removed: 1}}
+
+	fix := gitlib.NewTestCommit(testHash("c"), sig, "Fix connection leak")
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: fix})
+	require.NoError(t, err)
+	assert.Equal(t, testHash("c"), tc.CommitHash)
+	assert.Equal(t, &CommitDefects{Fix: true, Churn: 4, Fixed: []string{"db.go"}}, tc.Data)
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: fix, IsMerge: true})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+
+	a.TreeDiff.Changes = nil
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: fix})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+}
+
+func TestAnalyzer_Fork(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+
+	forks := a.Fork(2)
+	require.Len(t, forks, 2)
+
+	for _, f := range forks {
+		clone, ok := f.(*Analyzer)
+		require.True(t, ok)
+		assert.NotSame(t, a.TreeDiff, clone.TreeDiff)
+		assert.NotSame(t, a.LineStats, clone.LineStats)
+		assert.Same(t, a.ledger, clone.ledger)
+	}
+}
+
+func TestAnalyzer_ReportFromTICKs(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+	a.ledger.files["db.go"] = &FileStats{File: "db.go", Changes: 2, Fixes: 1, Churn: 10}
+	a.ledger.files["api.go"] = &FileStats{File: "api.go", Changes: 1, Churn: 5}
+
+	byTick := make(map[int]*TickData)
+
+	for _, tc := range []analyze.TC{
+		{Tick: 0, Data: &CommitDefects{Churn: 6}},
+		{Tick: 0, Data: &CommitDefects{Fix: true, Churn: 4, Fixed: []string{"db.go"}}},
+		{Tick: 0, Data: &CommitDefects{Fix: true, Churn: 2, Fixed: []string{"db.go"}}},
+	} {
+		require.NoError(t, extractTC(tc, byTick))
+	}
+
+	tick, err := buildTick(0, byTick[0])
+	require.NoError(t, err)
+
+	report, err := a.ReportFromTICKs(context.Background(), []analyze.TICK{tick})
+	require.NoError(t, err)
+
+	assert.Equal(t, []TickDefects{{Tick: 0, Commits: 3, Fixes: 2, Churn: 12, FixChurn: 6, FixedFiles: 1}}, report[KeyTicks])
+
+	files, ok := report[KeyFiles].([]FileStats)
+	require.True(t, ok)
+	require.Len(t, files, 1, "only fixed files are reported")
+	assert.Equal(t, "db.go", files[0].File)
+}
@@ -0,0 +1,123 @@
+package defects
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// FileStats is the change and fix history of a file that exists at the end
+// of the analyzed history.
+type FileStats struct {
+	File    string `json:"file"      yaml:"file"`
+	Lines   int    `json:"lines"     yaml:"lines"`
+	Changes int    `json:"changes"   yaml:"changes"`
+	Fixes   int    `json:"fixes"     yaml:"fixes"`
+	// Churn is the number of added and removed lines over all changes,
+	// FixChurn over the fixes only.
+	Churn    int       `json:"churn"     yaml:"churn"`
+	FixChurn int       `json:"fix_churn" yaml:"fix_churn"`
+	LastFix  time.Time `json:"last_fix"  yaml:"last_fix"`
+}
+
+// classifier tells fix commits from other commits by their message.
+type classifier struct {
+	// fix matches the subject, issue the whole message. issue is nil when
+	// issue links do not mark fixes.
+	fix   *regexp.Regexp
+	issue *regexp.Regexp
+}
+
+// isFix reports whether message is the message of a fix.
+func (c *classifier) isFix(message string) bool {
+	line, _, _ := strings.Cut(message, "\n")
+	if c.fix.MatchString(line) {
+		return true
+	}
+
+	return c.issue != nil && c.issue.MatchString(message)
+}
+
+// ledger tracks the change and fix history of every file of the current
+// tree. Renamed files keep their history; deleted files lose it.
+type ledger struct {
+	files map[string]*FileStats
+}
+
+func newLedger() *ledger {
+	return &ledger{files: make(map[string]*FileStats)}
+}
+
+// record applies the changes of a commit to the ledger and returns the
+// commit's payload.
+func (l *ledger) record(
+	changes gitlib.Changes, stats map[gitlib.ChangeEntry]pkgplumbing.LineStats, fix bool, when time.Time,
+) *CommitDefects {
+	commit := &CommitDefects{Fix: fix}
+
+	for _, change := range changes {
+		if change.Action == gitlib.Delete {
+			delete(l.files, change.From.Name)
+
+			continue
+		}
+
+		file := l.file(change)
+		delta := stats[change.To]
+		churn := delta.Added + delta.Removed
+
+		file.Lines = max(file.Lines+delta.Added-delta.Removed, 0)
+		file.Changes++
+		file.Churn += churn
+		commit.Churn += churn
+
+		if fix {
+			file.Fixes++
+			file.FixChurn += churn
+			file.LastFix = when
+			commit.Fixed = append(commit.Fixed, file.File)
+		}
+	}
+
+	return commit
+}
+
+// file returns the stats of the file a change writes, moving them to the new
+// name of a renamed file.
+func (l *ledger) file(change *gitlib.Change) *FileStats {
+	name := change.To.Name
+
+	if change.Action == gitlib.Modify && change.From.Name != name {
+		if file, ok := l.files[change.From.Name]; ok {
+			delete(l.files, change.From.Name)
+			file.File = name
+			l.files[name] = file
+
+			return file
+		}
+	}
+
+	file, ok := l.files[name]
+	if !ok || change.Action == gitlib.Insert {
+		file = &FileStats{File: name}
+		l.files[name] = file
+	}
+
+	return file
+}
+
+// fixed returns the stats of the files that were fixed at least once.
+func (l *ledger) fixed() []FileStats {
+	var files []FileStats
+
+	for _, file := range l.files {
+		if file.Fixes > 0 {
+			files = append(files, *file)
+		}
+	}
+
+	return files
+}
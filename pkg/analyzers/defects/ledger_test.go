@@ -0,0 +1,73 @@
+package defects
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestClassifier(t *testing.T) {
+	t.Parallel()
+
+	keywords := &classifier{fix: regexp.MustCompile(pkgplumbing.FixPattern)}
+
+	for _, message := range []string{"Fix crash on empty input", "fixed #12", "Hotfix: login", "defect in parser"} {
+		assert.True(t, keywords.isFix(message), message)
+	}
+
+	for _, message := range []string{"Add prefix support", "Refactor suffixes", "Update debugger", "Add cache\n\nfix later"} {
+		assert.False(t, keywords.isFix(message), message)
+	}
+
+	issues := &classifier{fix: regexp.MustCompile(pkgplumbing.FixPattern), issue: regexp.MustCompile(`\bBUG-\d+\b`)}
+	assert.True(t, issues.isFix("Handle timeouts\n\nRefs: BUG-42"))
+	assert.False(t, issues.isFix("Handle timeouts\n\nRefs: FEAT-42"))
+}
+
+func TestLedger_Record(t *testing.T) {
+	t.Parallel()
+
+	l := newLedger()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	main := gitlib.ChangeEntry{Name: "main.go", Hash: testHash("1")}
+	util := gitlib.ChangeEntry{Name: "util.go", Hash: testHash("2")}
+
+	commit := l.record(gitlib.Changes{
+		{Action: gitlib.Insert, To: main},
+		{Action: gitlib.Insert, To: util},
+	}, map[gitlib.ChangeEntry]pkgplumbing.LineStats{
+		main: {Added: 100},
+		util: {Added: 20},
+	}, false, start)
+	assert.Equal(t, &CommitDefects{Churn: 120}, commit)
+	assert.Empty(t, l.fixed())
+
+	fixedMain := gitlib.ChangeEntry{Name: "main.go", Hash: testHash("3")}
+	commit = l.record(gitlib.Changes{
+		{Action: gitlib.Modify, From: main, To: fixedMain},
+	}, map[gitlib.ChangeEntry]pkgplumbing.LineStats{
+		fixedMain: {Added: 10, Removed: 4},
+	}, true, start.Add(time.Hour))
+	assert.Equal(t, &CommitDefects{Fix: true, Churn: 14, Fixed: []string{"main.go"}}, commit)
+
+	renamed := gitlib.ChangeEntry{Name: "cmd/main.go", Hash: testHash("3")}
+	l.record(gitlib.Changes{
+		{Action: gitlib.Modify, From: fixedMain, To: renamed},
+		{Action: gitlib.Delete, From: util},
+	}, nil, false, start.Add(2*time.Hour))
+
+	files := l.fixed()
+	require.Len(t, files, 1)
+	assert.Equal(t, FileStats{
+		File: "cmd/main.go", Lines: 106, Changes: 3, Fixes: 1, Churn: 114, FixChurn: 14, LastFix: start.Add(time.Hour),
+	}, files[0])
+	assert.NotContains(t, l.files, "util.go")
+	assert.NotContains(t, l.files, "main.go")
+}
@@ -0,0 +1,120 @@
+package defects
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// linesPerKLOC converts line counts to thousands of lines.
+const linesPerKLOC = 1000
+
+// FileRisk is the fault density and defect prediction score of a file.
+type FileRisk struct {
+	FileStats `yaml:",inline"`
+
+	// FaultDensity is the number of fixes per thousand lines of the
+	// current file, 0 for empty files.
+	FaultDensity float64 `json:"fault_density" yaml:"fault_density"`
+	// Score is the churn times the number of past fixes.
+	Score int `json:"score" yaml:"score"`
+	Rank  int `json:"rank"  yaml:"rank"`
+}
+
+// TickTrend is the fix activity of one tick.
+type TickTrend struct {
+	TickDefects `yaml:",inline"`
+
+	// FixRatio is the share of the commits of the tick that were fixes.
+	FixRatio float64 `json:"fix_ratio" yaml:"fix_ratio"`
+}
+
+// ComputedMetrics holds the defect prediction of the history.
+type ComputedMetrics struct {
+	Commits  int     `json:"commits"   yaml:"commits"`
+	Fixes    int     `json:"fixes"     yaml:"fixes"`
+	FixRatio float64 `json:"fix_ratio" yaml:"fix_ratio"`
+	// Files are the files fixed at least once, ranked by score, highest
+	// first.
+	Files []FileRisk  `json:"files" yaml:"files"`
+	Trend []TickTrend `json:"trend" yaml:"trend"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNameDefects = "defects"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNameDefects
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// ComputeAllMetrics ranks the fixed files of a report and computes the fix
+// ratio per tick. The commit, change and fix counts of a sampled history are
+// scaled by the sampling factor; churn comes from the diffs, which span the
+// commits skipped between samples, and is kept.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	files, _ := report[KeyFiles].([]FileStats)
+	series, _ := report[KeyTicks].([]TickDefects)
+	factor := analyze.SampleFactor(report)
+
+	m := &ComputedMetrics{Trend: make([]TickTrend, len(series))}
+
+	for i, point := range series {
+		point.Commits = analyze.ScaleCount(point.Commits, factor)
+		point.Fixes = analyze.ScaleCount(point.Fixes, factor)
+		m.Commits += point.Commits
+		m.Fixes += point.Fixes
+		m.Trend[i] = TickTrend{TickDefects: point, FixRatio: ratio(point.Fixes, point.Commits)}
+	}
+
+	m.FixRatio = ratio(m.Fixes, m.Commits)
+	m.Files = rankFiles(files, factor)
+
+	return m
+}
+
+// rankFiles computes the fault density and score of files, with their
+// change and fix counts scaled by factor, and ranks them by score, then by
+// number of fixes.
+func rankFiles(files []FileStats, factor float64) []FileRisk {
+	ranked := make([]FileRisk, len(files))
+
+	for i, file := range files {
+		file.Changes = analyze.ScaleCount(file.Changes, factor)
+		file.Fixes = analyze.ScaleCount(file.Fixes, factor)
+		ranked[i] = FileRisk{FileStats: file, Score: file.Churn * file.Fixes}
+
+		if file.Lines > 0 {
+			ranked[i].FaultDensity = float64(file.Fixes) * linesPerKLOC / float64(file.Lines)
+		}
+	}
+
+	slices.SortFunc(ranked, func(x, y FileRisk) int {
+		return cmp.Or(cmp.Compare(y.Score, x.Score), cmp.Compare(y.Fixes, x.Fixes), cmp.Compare(x.File, y.File))
+	})
+
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+	}
+
+	return ranked
+}
+
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) / float64(total)
+}
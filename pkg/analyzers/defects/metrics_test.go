@@ -0,0 +1,87 @@
+package defects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{})
+	assert.Zero(t, m.Commits)
+	assert.Zero(t, m.FixRatio)
+	assert.Empty(t, m.Files)
+	assert.Empty(t, m.Trend)
+}
+
+func TestComputeAllMetrics(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		KeyTicks: []TickDefects{
+			{Tick: 0, Commits: 8, Fixes: 2},
+			{Tick: 1, Commits: 2, Fixes: 2},
+			{Tick: 2},
+		},
+		KeyFiles: []FileStats{
+			{File: "api.go", Lines: 500, Fixes: 1, Churn: 300},
+			{File: "db.go", Lines: 200, Fixes: 4, Churn: 100},
+			{File: "util.go", Lines: 0, Fixes: 2, Churn: 200},
+			{File: "cache.go", Lines: 1000, Fixes: 3, Churn: 100},
+		},
+	}
+
+	m := ComputeAllMetrics(report)
+	assert.Equal(t, 10, m.Commits)
+	assert.Equal(t, 4, m.Fixes)
+	assert.InDelta(t, 0.4, m.FixRatio, 1e-9)
+
+	require.Len(t, m.Trend, 3)
+	assert.InDelta(t, 0.25, m.Trend[0].FixRatio, 1e-9)
+	assert.InDelta(t, 1.0, m.Trend[1].FixRatio, 1e-9)
+	assert.Zero(t, m.Trend[2].FixRatio)
+
+	require.Len(t, m.Files, 4)
+
+	files := make([]string, len(m.Files))
+	for i, file := range m.Files {
+		files[i] = file.File
+		assert.Equal(t, i+1, file.Rank)
+	}
+
+	assert.Equal(t, []string{"db.go", "util.go", "cache.go", "api.go"}, files)
+	assert.Equal(t, 400, m.Files[0].Score)
+	assert.InDelta(t, 20.0, m.Files[0].FaultDensity, 1e-9)
+	assert.Zero(t, m.Files[1].FaultDensity, "empty files have no density")
+}
+
+func TestComputeAllMetrics_ScalesSampledCounts(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		KeyTicks:                      []TickDefects{{Tick: 0, Commits: 4, Fixes: 1, Churn: 90}},
+		KeyFiles:                      []FileStats{{File: "db.go", Lines: 500, Changes: 3, Fixes: 1, Churn: 100}},
+		analyze.ReportKeySampleFactor: 2.0,
+	}
+
+	m := ComputeAllMetrics(report)
+	assert.Equal(t, 8, m.Commits)
+	assert.Equal(t, 2, m.Fixes)
+	assert.InDelta(t, 0.25, m.FixRatio, 1e-9)
+
+	require.Len(t, m.Trend, 1)
+	assert.Equal(t, 8, m.Trend[0].Commits)
+	assert.Equal(t, 90, m.Trend[0].Churn)
+
+	require.Len(t, m.Files, 1)
+	assert.Equal(t, 6, m.Files[0].Changes)
+	assert.Equal(t, 2, m.Files[0].Fixes)
+	assert.Equal(t, 200, m.Files[0].Score)
+	assert.InDelta(t, 4.0, m.Files[0].FaultDensity, 1e-9)
+	assert.Equal(t, 1, report[KeyFiles].([]FileStats)[0].Fixes, "the report is left untouched")
+}
@@ -0,0 +1,119 @@
+package defects
+
+import (
+	"html"
+	"strconv"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const (
+	// maxChartFiles is the number of files the score chart shows.
+	maxChartFiles = 20
+	percent       = 100
+)
+
+// RegisterPlotSections registers the defects plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/defects", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeAllMetrics(report)
+
+	return []plotpage.Section{
+		{
+			Title: "Fix Trend",
+			Subtitle: strconv.Itoa(m.Fixes) + " of " + strconv.Itoa(m.Commits) + " commits (" +
+				formatPercent(m.FixRatio) + ") were fixes.",
+			Chart: plotpage.WrapChart(buildTrendChart(m.Trend)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"<strong>Fixes</strong> = commits whose subject names a fix or whose message links a bug issue",
+					"A rising share of fixes means more effort goes into repairing existing code",
+				},
+			},
+		},
+		{
+			Title:    "Defect Prediction",
+			Subtitle: "Files with the highest churn times past fixes score.",
+			Chart:    plotpage.WrapChart(buildScoreChart(m.Files)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Files that changed a lot and needed many fixes are the most likely to contain the next defect",
+					"Review, test and refactor the top files first",
+				},
+			},
+		},
+		{
+			Title:    "Fault Density",
+			Subtitle: strconv.Itoa(len(m.Files)) + " files needed at least one fix.",
+			Chart:    buildFileTable(m.Files),
+		},
+	}, nil
+}
+
+func buildTrendChart(trend []TickTrend) *charts.Bar {
+	labels := make([]string, len(trend))
+	fixes := make([]plotpage.SeriesData, len(trend))
+	others := make([]plotpage.SeriesData, len(trend))
+
+	for i, point := range trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		fixes[i] = point.Fixes
+		others[i] = point.Commits - point.Fixes
+	}
+
+	return plotpage.BuildBarChart(nil, labels, []plotpage.BarSeries{
+		{Name: "Fixes", Data: fixes},
+		{Name: "Other commits", Data: others},
+	}, "Commits")
+}
+
+func buildScoreChart(files []FileRisk) *charts.Bar {
+	files = files[:min(len(files), maxChartFiles)]
+
+	labels := make([]string, len(files))
+	scores := make([]plotpage.SeriesData, len(files))
+
+	for i, file := range files {
+		labels[i] = file.File
+		scores[i] = file.Score
+	}
+
+	return plotpage.BuildBarChart(nil, labels, []plotpage.BarSeries{{Name: "Score", Data: scores}}, "Churn × fixes")
+}
+
+func buildFileTable(files []FileRisk) *plotpage.Table {
+	table := plotpage.NewTable([]string{
+		"Rank", "File", "Lines", "Changes", "Fixes", "Churn", "Fix Churn", "Fixes / KLOC", "Score",
+	}).WithSearch("Filter files...")
+
+	for _, file := range files {
+		table.AddRow(
+			strconv.Itoa(file.Rank),
+			html.EscapeString(file.File),
+			strconv.Itoa(file.Lines),
+			strconv.Itoa(file.Changes),
+			strconv.Itoa(file.Fixes),
+			strconv.Itoa(file.Churn),
+			strconv.Itoa(file.FixChurn),
+			strconv.FormatFloat(file.FaultDensity, 'f', 2, 64),
+			strconv.Itoa(file.Score),
+		)
+	}
+
+	return table
+}
+
+func formatPercent(ratio float64) string {
+	return strconv.FormatFloat(ratio*percent, 'f', 1, 64) + "%"
+}
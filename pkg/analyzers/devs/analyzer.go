@@ -23,6 +23,18 @@ type CommitDevData struct {
 	Changed   int                              `json:"lines_changed"`
 	AuthorID  int                              `json:"author_id"`
 	Languages map[string]pkgplumbing.LineStats `json:"languages,omitempty"`
+	// Projects holds the line statistics per discovered project root. Only
+	// set when the repository holds projects.
+	Projects map[string]pkgplumbing.LineStats `json:"projects,omitempty"`
+	// Timezones counts commits by author UTC offset in minutes. Only set
+	// when geography is enabled.
+	Timezones map[int]int `json:"timezones,omitempty"`
+	// CoAuthorIDs are the Co-authored-by identities of the commit. The
+	// commit counts for each of them, and its lines are split between them
+	// and the author, each co-author weighing CoAuthorWeight against the
+	// author's 1.
+	CoAuthorIDs    []int   `json:"co_author_ids,omitempty"`
+	CoAuthorWeight float64 `json:"co_author_weight,omitempty"`
 }
 
 // DevTick is the statistics for a development tick and a particular developer.
@@ -30,6 +42,8 @@ type DevTick struct {
 	pkgplumbing.LineStats
 
 	Languages map[string]pkgplumbing.LineStats
+	Projects  map[string]pkgplumbing.LineStats
+	Timezones map[int]int
 	Commits   int
 }
 
@@ -44,6 +58,7 @@ type TickDevData struct {
 const (
 	ConfigDevsConsiderEmptyCommits = "Devs.ConsiderEmptyCommits"
 	ConfigDevsAnonymize            = "Devs.Anonymize"
+	ConfigDevsGeography            = "Devs.Geography"
 
 	defaultHoursPerDay = 24
 )
@@ -61,13 +76,18 @@ type Analyzer struct {
 	merges               map[gitlib.Hash]bool // working state for merge dedup.
 	reversedPeopleDict   []string
 	tickSize             time.Duration
+	coAuthorWeight       float64
+	tickCalendar         *pkgplumbing.TickCalendar // nil for fixed-size ticks.
 	ConsiderEmptyCommits bool
 	Anonymize            bool
+	Geography            bool
+	// Projects are the projects of a monorepo; lines are also counted per project.
+	Projects pkgplumbing.Projects
 }
 
 // NewAnalyzer creates a new devs analyzer.
 func NewAnalyzer() *Analyzer {
-	a := &Analyzer{}
+	a := &Analyzer{coAuthorWeight: plumbing.DefaultCoAuthorWeight}
 	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
 		Desc: analyze.Descriptor{
 			ID:          "history/devs",
@@ -75,6 +95,7 @@ func NewAnalyzer() *Analyzer {
 			Description: "Calculates the number of commits, added, removed and changed lines per developer through time.",
 		},
 		Sequential: true,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, NeedsDiffs: true, Memory: analyze.MemoryMedium},
 		ConfigOptions: []pipeline.ConfigurationOption{
 			{
 				Name:        ConfigDevsConsiderEmptyCommits,
@@ -90,14 +111,20 @@ func NewAnalyzer() *Analyzer {
 				Type:        pipeline.BoolConfigurationOption,
 				Default:     false,
 			},
+			{
+				Name: ConfigDevsGeography,
+				Description: "Infer each developer's timezone from commit UTC offsets and report the team's " +
+					"geographic distribution over time.",
+				Flag:    "dev-geography",
+				Type:    pipeline.BoolConfigurationOption,
+				Default: false,
+			},
 		},
 		ComputeMetricsFn: computeMetricsSafe,
 		AggregatorFn:     newAggregator,
 	}
 
-	a.TicksToReportFn = func(ctx context.Context, ticks []analyze.TICK) analyze.Report {
-		return ticksToReport(ctx, ticks, a.commitsByTick, a.reversedPeopleDict, a.tickSize, a.Anonymize)
-	}
+	a.TicksToReportFn = a.reportFromTicks
 
 	return a
 }
@@ -120,10 +147,16 @@ func (a *Analyzer) Configure(facts map[string]any) error {
 		a.Anonymize = val
 	}
 
+	if val, exists := facts[ConfigDevsGeography].(bool); exists {
+		a.Geography = val
+	}
+
 	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
 		a.reversedPeopleDict = val
 	}
 
+	a.coAuthorWeight = plumbing.CoAuthorWeight(facts)
+
 	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
 		a.tickSize = val
 	}
@@ -132,9 +165,29 @@ func (a *Analyzer) Configure(facts map[string]any) error {
 		a.commitsByTick = val
 	}
 
+	if val, exists := facts[pkgplumbing.FactTickCalendar].(*pkgplumbing.TickCalendar); exists {
+		a.tickCalendar = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactProjects].(pkgplumbing.Projects); exists {
+		a.Projects = val
+	}
+
 	return nil
 }
 
+// reportFromTicks builds the report. The tick calendar, when configured,
+// lets metrics label ticks by period.
+func (a *Analyzer) reportFromTicks(ctx context.Context, ticks []analyze.TICK) analyze.Report {
+	report := ticksToReport(ctx, ticks, a.commitsByTick, a.reversedPeopleDict, a.tickSize, a.Anonymize)
+
+	if a.tickCalendar != nil {
+		report[reportKeyTickCalendar] = a.tickCalendar
+	}
+
+	return report
+}
+
 // Initialize prepares the analyzer for processing commits.
 func (a *Analyzer) Initialize(_ *gitlib.Repository) error {
 	RegisterDevPlotSections()
@@ -172,10 +225,23 @@ func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC,
 		Languages: make(map[string]pkgplumbing.LineStats),
 	}
 
+	// A co-author weight of 0 credits the author alone.
+	if len(a.Identity.CoAuthorIDs) > 0 && a.coAuthorWeight > 0 {
+		cdd.CoAuthorIDs = a.Identity.CoAuthorIDs
+		cdd.CoAuthorWeight = a.coAuthorWeight
+	}
+
 	if !ac.IsMerge {
 		a.accumulateLineStats(cdd)
 	}
 
+	// The author offset is the contributor's own clock; the committer's is
+	// rewritten by rebases and web merges.
+	if a.Geography {
+		_, offset := commit.Author().When.Zone()
+		cdd.Timezones = map[int]int{offset / secondsPerMinute: 1}
+	}
+
 	return analyze.TC{
 		Data:       cdd,
 		CommitHash: commitHash,
@@ -197,6 +263,17 @@ func (a *Analyzer) accumulateLineStats(cdd *CommitDevData) {
 			Removed: cddLangStats.Removed + stats.Removed,
 			Changed: cddLangStats.Changed + stats.Changed,
 		}
+
+		project := a.Projects.Of(changeEntry.Name)
+		if project == "" {
+			continue
+		}
+
+		if cdd.Projects == nil {
+			cdd.Projects = make(map[string]pkgplumbing.LineStats)
+		}
+
+		mergeLanguageStats(cdd.Projects, map[string]pkgplumbing.LineStats{project: stats})
 	}
 }
 
@@ -230,11 +307,12 @@ func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
 // SnapshotPlumbing captures the current plumbing state.
 func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
 	return plumbing.Snapshot{
-		Changes:   a.TreeDiff.Changes,
-		Tick:      a.Ticks.Tick,
-		AuthorID:  a.Identity.AuthorID,
-		Languages: a.Languages.Languages(),
-		LineStats: a.LineStats.LineStats,
+		Changes:     a.TreeDiff.Changes,
+		Tick:        a.Ticks.Tick,
+		AuthorID:    a.Identity.AuthorID,
+		CoAuthorIDs: a.Identity.CoAuthorIDs,
+		Languages:   a.Languages.Languages(),
+		LineStats:   a.LineStats.LineStats,
 	}
 }
 
@@ -248,6 +326,7 @@ func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
 	a.TreeDiff.Changes = snapshot.Changes
 	a.Ticks.Tick = snapshot.Tick
 	a.Identity.AuthorID = snapshot.AuthorID
+	a.Identity.CoAuthorIDs = snapshot.CoAuthorIDs
 	a.Languages.SetLanguages(snapshot.Languages)
 	a.LineStats.LineStats = snapshot.LineStats
 }
@@ -295,9 +374,7 @@ func (a *Analyzer) SerializeTICKs(ticks []analyze.TICK, format string, writer io
 
 	return (&analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
 		ComputeMetricsFn: computeMetricsSafe,
-		TicksToReportFn: func(ctx context.Context, t []analyze.TICK) analyze.Report {
-			return ticksToReport(ctx, t, a.commitsByTick, a.reversedPeopleDict, a.tickSize, a.Anonymize)
-		},
+		TicksToReportFn:  a.reportFromTicks,
 	}).SerializeTICKs(ticks, format, writer)
 }
 
@@ -324,10 +401,19 @@ func (a *Analyzer) ExtractCommitTimeSeries(report analyze.Report) map[string]any
 			"net_change":    cdd.Added - cdd.Removed,
 			"author_id":     cdd.AuthorID,
 		}
+		if len(cdd.CoAuthorIDs) > 0 {
+			entry["co_author_ids"] = cdd.CoAuthorIDs
+			entry["co_author_weight"] = cdd.CoAuthorWeight
+		}
+
 		if len(cdd.Languages) > 0 {
 			entry["languages"] = cdd.Languages
 		}
 
+		if len(cdd.Projects) > 0 {
+			entry["projects"] = cdd.Projects
+		}
+
 		result[hash] = entry
 	}
 
@@ -338,7 +424,9 @@ func (a *Analyzer) ExtractCommitTimeSeries(report analyze.Report) map[string]any
 
 const (
 	commitEntryOverhead = 128 // map entry + struct overhead per commit.
-	bytesPerLangEntry   = 48  // language map entry in CommitDevData.
+	bytesPerLangEntry   = 48  // language or project map entry in CommitDevData.
+	bytesPerTZEntry     = 16  // timezone map entry in CommitDevData.
+	secondsPerMinute    = 60
 )
 
 func extractTC(tc analyze.TC, byTick map[int]*TickDevData) error {
@@ -391,7 +479,8 @@ func sizeState(state *TickDevData) int64 {
 
 	for _, cdd := range state.DevData {
 		size += commitEntryOverhead
-		size += int64(len(cdd.Languages)) * bytesPerLangEntry
+		size += int64(len(cdd.Languages)+len(cdd.Projects)) * bytesPerLangEntry
+		size += int64(len(cdd.Timezones)) * bytesPerTZEntry
 	}
 
 	return size
@@ -437,6 +526,20 @@ func mergeCommitDevData(existing, incoming *CommitDevData) *CommitDevData {
 		}
 	}
 
+	if len(incoming.Projects) > 0 && existing.Projects == nil {
+		existing.Projects = make(map[string]pkgplumbing.LineStats, len(incoming.Projects))
+	}
+
+	mergeLanguageStats(existing.Projects, incoming.Projects)
+
+	for offset, commits := range incoming.Timezones {
+		if existing.Timezones == nil {
+			existing.Timezones = make(map[int]int)
+		}
+
+		existing.Timezones[offset] += commits
+	}
+
 	return existing
 }
 
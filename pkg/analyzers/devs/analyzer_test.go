@@ -81,6 +81,69 @@ func TestAnalyzer_Consume_ReturnsTCWithCommitDevData(t *testing.T) {
 	assert.Equal(t, commitHash, tc.CommitHash)
 }
 
+func TestAnalyzer_Consume_CountsLinesPerProject(t *testing.T) {
+	t.Parallel()
+
+	d := newTestDevAnalyzer()
+	require.NoError(t, d.Configure(map[string]any{
+		pkgplumbing.FactProjects: pkgplumbing.DiscoverProjects([]string{"svc/api/go.mod", "web/package.json"}),
+	}))
+
+	api := gitlib.ChangeEntry{Name: "svc/api/main.go", Hash: gitlib.NewHash("1111111111111111111111111111111111111111")}
+	web := gitlib.ChangeEntry{Name: "web/index.js", Hash: gitlib.NewHash("2222222222222222222222222222222222222222")}
+	other := gitlib.ChangeEntry{Name: "README.md", Hash: gitlib.NewHash("3333333333333333333333333333333333333333")}
+
+	d.TreeDiff.Changes = gitlib.Changes{
+		{Action: gitlib.Insert, To: api}, {Action: gitlib.Insert, To: web}, {Action: gitlib.Insert, To: other},
+	}
+	d.LineStats.LineStats = map[gitlib.ChangeEntry]pkgplumbing.LineStats{
+		api:   {Added: 10, Removed: 3},
+		web:   {Added: 4},
+		other: {Added: 1},
+	}
+
+	commit := gitlib.NewTestCommit(
+		gitlib.NewHash("c100000000000000000000000000000000000002"),
+		gitlib.TestSignature("dev", "dev@test.com"),
+		"monorepo commit",
+	)
+
+	tc, err := d.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+
+	cdd, ok := tc.Data.(*CommitDevData)
+	require.True(t, ok)
+	assert.Equal(t, map[string]pkgplumbing.LineStats{
+		"svc/api": {Added: 10, Removed: 3},
+		"web":     {Added: 4},
+	}, cdd.Projects, "files outside every project are not counted per project")
+	assert.Equal(t, 15, cdd.Added)
+}
+
+func TestAnalyzer_Consume_Geography(t *testing.T) {
+	t.Parallel()
+
+	d := newTestDevAnalyzer()
+	d.Geography = true
+
+	hash1 := gitlib.NewHash("1111111111111111111111111111111111111111")
+	d.TreeDiff.Changes = gitlib.Changes{{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: "test.go", Hash: hash1}}}
+	d.Languages.SetLanguagesForTest(map[gitlib.Hash]string{})
+	d.LineStats.LineStats = map[gitlib.ChangeEntry]pkgplumbing.LineStats{}
+
+	author := gitlib.TestSignature("dev", "dev@test.com")
+	author.When = time.Date(2024, 3, 1, 10, 0, 0, 0, time.FixedZone("IST", 330*60))
+
+	commit := gitlib.NewTestCommit(gitlib.NewHash("c100000000000000000000000000000000000001"), author, "test commit")
+
+	tc, err := d.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+
+	cdd, ok := tc.Data.(*CommitDevData)
+	require.True(t, ok, "TC.Data should be *CommitDevData")
+	assert.Equal(t, map[int]int{330: 1}, cdd.Timezones)
+}
+
 func TestAnalyzer_Consume_EmptyCommitIgnored(t *testing.T) {
 	t.Parallel()
 
@@ -294,6 +357,75 @@ func TestAggregateCommitsToTicks_SameAuthorMultipleCommits(t *testing.T) {
 	assert.Equal(t, 8, dt.Removed)
 }
 
+func TestAggregateCommitsToTicks_CoAuthorsSplitCredit(t *testing.T) {
+	t.Parallel()
+
+	h1 := gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+
+	commitDevData := map[string]*CommitDevData{
+		h1.String(): {
+			Commits: 1, Added: 11, Removed: 4, AuthorID: 0, CoAuthorIDs: []int{1},
+			Languages: map[string]pkgplumbing.LineStats{"Go": {Added: 11, Removed: 4}},
+			Timezones: map[int]int{60: 1},
+		},
+	}
+
+	result := AggregateCommitsToTicks(commitDevData, map[int][]gitlib.Hash{0: {h1}})
+	require.Len(t, result[0], 2)
+
+	author, coAuthor := result[0][0], result[0][1]
+	assert.Equal(t, 1, author.Commits)
+	assert.Equal(t, 1, coAuthor.Commits)
+	assert.Equal(t, 6, author.Added)
+	assert.Equal(t, 5, coAuthor.Added)
+	assert.Equal(t, 2, author.Removed)
+	assert.Equal(t, 2, coAuthor.Removed)
+	assert.Equal(t, 5, coAuthor.Languages["Go"].Added)
+	assert.Equal(t, map[int]int{60: 1}, author.Timezones)
+	assert.Nil(t, coAuthor.Timezones)
+}
+
+func TestAggregateCommitsToTicks_CoAuthorWeight(t *testing.T) {
+	t.Parallel()
+
+	h1 := gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+
+	commitDevData := map[string]*CommitDevData{
+		h1.String(): {Commits: 1, Added: 12, AuthorID: 0, CoAuthorIDs: []int{1}, CoAuthorWeight: 0.5},
+	}
+
+	result := AggregateCommitsToTicks(commitDevData, map[int][]gitlib.Hash{0: {h1}})
+	require.Len(t, result[0], 2)
+
+	assert.Equal(t, 8, result[0][0].Added)
+	assert.Equal(t, 4, result[0][1].Added)
+	assert.Equal(t, 1, result[0][1].Commits)
+}
+
+func TestAnalyzer_Consume_ZeroCoAuthorWeight(t *testing.T) {
+	t.Parallel()
+
+	d := newTestDevAnalyzer()
+	require.NoError(t, d.Configure(map[string]any{plumbing.ConfigIdentityDetectorCoAuthorWeight: 0.0}))
+
+	d.TreeDiff.Changes = gitlib.Changes{&gitlib.Change{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: "test.go"}}}
+	d.Identity.AuthorID = 0
+	d.Identity.CoAuthorIDs = []int{1}
+
+	commit := gitlib.NewTestCommit(
+		gitlib.NewHash("c100000000000000000000000000000000000001"),
+		gitlib.TestSignature("dev", "dev@test.com"),
+		"pair commit",
+	)
+
+	tc, err := d.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+
+	cdd, ok := tc.Data.(*CommitDevData)
+	require.True(t, ok, "TC.Data should be *CommitDevData")
+	assert.Empty(t, cdd.CoAuthorIDs, "a zero weight credits the author alone")
+}
+
 func TestAggregateCommitsToTicks_EmptyInputs(t *testing.T) {
 	t.Parallel()
 
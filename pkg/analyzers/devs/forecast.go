@@ -0,0 +1,53 @@
+package devs
+
+import (
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// contributorWindowDays is the trailing window in which a developer counts
+// as an active contributor of the forecast series.
+const contributorWindowDays = 30
+
+// RegisterForecastSeries registers the number of active contributors over
+// time as a series to forecast.
+func RegisterForecastSeries() {
+	analyze.RegisterForecastSeries(analyzerNameDevs, extractForecastSeries)
+}
+
+// extractForecastSeries returns, for every tick from the first commit to the
+// last, the developers who committed within the trailing contributor window.
+// Calendar ticks have no fixed duration, so they are not forecast.
+func extractForecastSeries(report analyze.Report) []analyze.ForecastSeries {
+	data, err := ParseTickData(report)
+	if err != nil || len(data.Ticks) == 0 || data.Calendar != nil {
+		return nil
+	}
+
+	ticks := sortedKeys(data.Ticks)
+	first, last := ticks[0], ticks[len(ticks)-1]
+	window := max(int(contributorWindowDays*defaultTickHours*time.Hour/data.TickSize), 1)
+	values := make([]float64, 0, last-first+1)
+
+	for tick := first; tick <= last; tick++ {
+		active := make(map[int]struct{})
+
+		for t := max(tick-window+1, first); t <= tick; t++ {
+			for devID := range data.Ticks[t] {
+				active[devID] = struct{}{}
+			}
+		}
+
+		values = append(values, float64(len(active)))
+	}
+
+	return []analyze.ForecastSeries{{
+		Name:     "contributors",
+		Label:    "Active contributors (30 days)",
+		Start:    first,
+		Step:     1,
+		Values:   values,
+		TickSize: data.TickSize,
+	}}
+}
@@ -0,0 +1,44 @@
+package devs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func TestExtractForecastSeries(t *testing.T) {
+	t.Parallel()
+
+	hashA := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	hashB := "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	hashC := "cccccccccccccccccccccccccccccccccccccccc"
+
+	// Weekly ticks make the 30-day window four ticks long.
+	report := analyze.Report{
+		"CommitDevData": map[string]*CommitDevData{
+			hashA: {Commits: 1, AuthorID: 0},
+			hashB: {Commits: 1, AuthorID: 1},
+			hashC: {Commits: 1, AuthorID: 0},
+		},
+		"CommitsByTick": map[int][]gitlib.Hash{
+			2: {gitlib.NewHash(hashA)},
+			3: {gitlib.NewHash(hashB)},
+			9: {gitlib.NewHash(hashC)},
+		},
+		"ReversedPeopleDict": []string{testDevName1, testDevName2},
+		"TickSize":           7 * 24 * time.Hour,
+	}
+
+	series := extractForecastSeries(report)
+	require.Len(t, series, 1)
+	assert.Equal(t, "contributors", series[0].Name)
+	assert.Equal(t, 2, series[0].Start)
+	assert.Equal(t, []float64{1, 2, 2, 2, 1, 0, 0, 1}, series[0].Values)
+
+	assert.Nil(t, extractForecastSeries(analyze.Report{"ReversedPeopleDict": []string{}}))
+}
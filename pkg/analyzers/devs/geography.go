@@ -0,0 +1,284 @@
+package devs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/metrics"
+)
+
+// Regions inferred from UTC offsets. Offsets only bound longitude, so the
+// regions are deliberately coarse.
+const (
+	RegionAmericas     = "Americas"
+	RegionEuropeAfrica = "Europe/Africa"
+	RegionMiddleEastSA = "Middle East/South Asia"
+	RegionEastAsia     = "East Asia"
+	RegionOceania      = "Oceania/Pacific"
+)
+
+// Region boundaries, in minutes east of UTC.
+const (
+	minutesPerHour        = 60
+	americasMinOffset     = -10 * minutesPerHour
+	americasMaxOffset     = -2 * minutesPerHour
+	europeAfricaMaxOffset = 3 * minutesPerHour
+	middleEastSAMaxOffset = 6*minutesPerHour + 30
+	eastAsiaMaxOffset     = 10 * minutesPerHour
+)
+
+// TimezoneData is the inferred home timezone of one developer. Share is the
+// fraction of their commits made at UTCOffset; Offsets counts the distinct
+// offsets seen, which daylight saving time alone makes at least two in many
+// regions.
+type TimezoneData struct {
+	ID        int     `json:"id"         yaml:"id"`
+	Name      string  `json:"name"       yaml:"name"`
+	UTCOffset string  `json:"utc_offset" yaml:"utc_offset"`
+	Region    string  `json:"region"     yaml:"region"`
+	Commits   int     `json:"commits"    yaml:"commits"`
+	Share     float64 `json:"share"      yaml:"share"`
+	Offsets   int     `json:"offsets"    yaml:"offsets"`
+}
+
+// RegionData summarizes the developers whose home timezone falls in a region.
+type RegionData struct {
+	Region     string  `json:"region"     yaml:"region"`
+	Developers int     `json:"developers" yaml:"developers"`
+	Commits    int     `json:"commits"    yaml:"commits"`
+	Share      float64 `json:"share"      yaml:"share"`
+}
+
+// GeographyTickData counts the developers active in each region during a tick,
+// placing each developer by the offset of most of their commits in that tick.
+type GeographyTickData struct {
+	Tick    int            `json:"tick"             yaml:"tick"`
+	Period  string         `json:"period,omitempty" yaml:"period,omitempty"`
+	Regions map[string]int `json:"regions"          yaml:"regions"`
+}
+
+// GeographyData is the team geography sub-report.
+type GeographyData struct {
+	Developers []TimezoneData      `json:"developers" yaml:"developers"`
+	Regions    []RegionData        `json:"regions"    yaml:"regions"`
+	Timeline   []GeographyTickData `json:"timeline"   yaml:"timeline"`
+}
+
+// GeographyMetric infers developer timezones and the team's regional spread.
+type GeographyMetric struct {
+	metrics.MetricMeta
+}
+
+// NewGeographyMetric creates the geography metric.
+func NewGeographyMetric() *GeographyMetric {
+	return &GeographyMetric{
+		MetricMeta: metrics.MetricMeta{
+			MetricName:        "geography",
+			MetricDisplayName: "Team Geography",
+			MetricDescription: "Home timezone of each developer, taken as the UTC offset of most of their commits, " +
+				"and the number of developers per region over time. Regions are coarse bands of UTC offsets. " +
+				"Requires --dev-geography.",
+			MetricType: "distribution",
+		},
+	}
+}
+
+// Compute calculates the geography sub-report. Returns nil when the report
+// carries no timezone data, i.e. the run did not enable geography.
+func (m *GeographyMetric) Compute(input *TickData) *GeographyData {
+	totals := make(map[int]map[int]int)
+
+	for _, devTicks := range input.Ticks {
+		for devID, dt := range devTicks {
+			for offset, commits := range dt.Timezones {
+				if totals[devID] == nil {
+					totals[devID] = make(map[int]int)
+				}
+
+				totals[devID][offset] += commits
+			}
+		}
+	}
+
+	if len(totals) == 0 {
+		return nil
+	}
+
+	developers := make([]TimezoneData, 0, len(totals))
+
+	for devID, offsets := range totals {
+		developers = append(developers, developerTimezone(devID, offsets, input.Names))
+	}
+
+	sort.Slice(developers, func(i, j int) bool {
+		if developers[i].Commits != developers[j].Commits {
+			return developers[i].Commits > developers[j].Commits
+		}
+
+		return developers[i].ID < developers[j].ID
+	})
+
+	return &GeographyData{
+		Developers: developers,
+		Regions:    regionSummary(developers),
+		Timeline:   geographyTimeline(input),
+	}
+}
+
+func developerTimezone(devID int, offsets map[int]int, names []string) TimezoneData {
+	offset, top := dominantOffset(offsets)
+
+	data := TimezoneData{
+		ID:        devID,
+		Name:      devName(devID, names),
+		UTCOffset: FormatUTCOffset(offset),
+		Region:    RegionForOffset(offset),
+		Offsets:   len(offsets),
+	}
+
+	for _, commits := range offsets {
+		data.Commits += commits
+	}
+
+	if data.Commits > 0 {
+		data.Share = float64(top) / float64(data.Commits)
+	}
+
+	return data
+}
+
+func regionSummary(developers []TimezoneData) []RegionData {
+	byRegion := make(map[string]*RegionData)
+	total := 0
+
+	for _, dev := range developers {
+		rd := byRegion[dev.Region]
+		if rd == nil {
+			rd = &RegionData{Region: dev.Region}
+			byRegion[dev.Region] = rd
+		}
+
+		rd.Developers++
+		rd.Commits += dev.Commits
+		total += dev.Commits
+	}
+
+	result := make([]RegionData, 0, len(byRegion))
+
+	for _, rd := range byRegion {
+		if total > 0 {
+			rd.Share = float64(rd.Commits) / float64(total)
+		}
+
+		result = append(result, *rd)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Commits != result[j].Commits {
+			return result[i].Commits > result[j].Commits
+		}
+
+		return result[i].Region < result[j].Region
+	})
+
+	return result
+}
+
+func geographyTimeline(input *TickData) []GeographyTickData {
+	tickKeys := sortedKeys(input.Ticks)
+	result := make([]GeographyTickData, 0, len(tickKeys))
+
+	for _, tick := range tickKeys {
+		gt := GeographyTickData{
+			Tick:    tick,
+			Period:  input.periodLabel(tick),
+			Regions: make(map[string]int),
+		}
+
+		for _, dt := range input.Ticks[tick] {
+			if len(dt.Timezones) == 0 {
+				continue
+			}
+
+			offset, _ := dominantOffset(dt.Timezones)
+			gt.Regions[RegionForOffset(offset)]++
+		}
+
+		result = append(result, gt)
+	}
+
+	return result
+}
+
+// dominantOffset returns the offset with the most commits and its count.
+// Ties go to the offset closer to UTC, then the western one, so results are
+// deterministic.
+func dominantOffset(offsets map[int]int) (offset, commits int) {
+	first := true
+
+	for o, c := range offsets {
+		if first || c > commits || (c == commits && closerToUTC(o, offset)) {
+			offset, commits, first = o, c, false
+		}
+	}
+
+	return offset, commits
+}
+
+func closerToUTC(a, b int) bool {
+	absA, absB := max(a, -a), max(b, -b)
+	if absA != absB {
+		return absA < absB
+	}
+
+	return a < b
+}
+
+// RegionForOffset maps a UTC offset in minutes to a coarse region.
+func RegionForOffset(offset int) string {
+	switch {
+	case offset < americasMinOffset:
+		return RegionOceania
+	case offset <= americasMaxOffset:
+		return RegionAmericas
+	case offset <= europeAfricaMaxOffset:
+		return RegionEuropeAfrica
+	case offset <= middleEastSAMaxOffset:
+		return RegionMiddleEastSA
+	case offset <= eastAsiaMaxOffset:
+		return RegionEastAsia
+	default:
+		return RegionOceania
+	}
+}
+
+// FormatUTCOffset formats an offset in minutes as "UTC+05:30".
+func FormatUTCOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+
+	return fmt.Sprintf("UTC%s%02d:%02d", sign, offset/minutesPerHour, offset%minutesPerHour)
+}
+
+// parseTimezones decodes the tz offset map of a JSON-decoded CommitDevData.
+func parseTimezones(v any) map[int]int {
+	tzAny, isMap := v.(map[string]any)
+	if !isMap || len(tzAny) == 0 {
+		return nil
+	}
+
+	res := make(map[int]int, len(tzAny))
+
+	for key, commits := range tzAny {
+		offset, err := strconv.Atoi(key)
+		if err == nil {
+			res[offset] = intVal(commits)
+		}
+	}
+
+	return res
+}
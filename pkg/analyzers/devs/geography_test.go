@@ -0,0 +1,90 @@
+package devs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeographyMetric_NoTimezones(t *testing.T) {
+	t.Parallel()
+
+	input := &TickData{Ticks: map[int]map[int]*DevTick{0: {0: {Commits: 3}}}}
+
+	assert.Nil(t, NewGeographyMetric().Compute(input))
+}
+
+func TestGeographyMetric_Compute(t *testing.T) {
+	t.Parallel()
+
+	input := &TickData{
+		Names: []string{"alice", "bob", "carol"},
+		Ticks: map[int]map[int]*DevTick{
+			0: {
+				0: {Commits: 3, Timezones: map[int]int{60: 2, 120: 1}},
+				1: {Commits: 2, Timezones: map[int]int{-300: 2}},
+			},
+			1: {
+				0: {Commits: 2, Timezones: map[int]int{120: 2}},
+				2: {Commits: 1, Timezones: map[int]int{330: 1}},
+			},
+		},
+	}
+
+	geo := NewGeographyMetric().Compute(input)
+	require.NotNil(t, geo)
+
+	require.Len(t, geo.Developers, 3)
+	assert.Equal(t, TimezoneData{
+		ID: 0, Name: "alice", UTCOffset: "UTC+02:00", Region: RegionEuropeAfrica,
+		Commits: 5, Share: 0.6, Offsets: 2,
+	}, geo.Developers[0])
+	assert.Equal(t, "UTC-05:00", geo.Developers[1].UTCOffset)
+	assert.Equal(t, RegionAmericas, geo.Developers[1].Region)
+	assert.Equal(t, RegionMiddleEastSA, geo.Developers[2].Region)
+
+	require.Len(t, geo.Regions, 3)
+	assert.Equal(t, RegionData{Region: RegionEuropeAfrica, Developers: 1, Commits: 5, Share: 0.625}, geo.Regions[0])
+
+	require.Len(t, geo.Timeline, 2)
+	assert.Equal(t, map[string]int{RegionEuropeAfrica: 1, RegionAmericas: 1}, geo.Timeline[0].Regions)
+	assert.Equal(t, map[string]int{RegionEuropeAfrica: 1, RegionMiddleEastSA: 1}, geo.Timeline[1].Regions)
+}
+
+func TestRegionForOffset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		offset int
+		want   string
+	}{
+		{-11 * 60, RegionOceania},
+		{-8 * 60, RegionAmericas},
+		{-3 * 60, RegionAmericas},
+		{0, RegionEuropeAfrica},
+		{3 * 60, RegionEuropeAfrica},
+		{5*60 + 30, RegionMiddleEastSA},
+		{8 * 60, RegionEastAsia},
+		{12 * 60, RegionOceania},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, RegionForOffset(tt.offset), tt.offset)
+	}
+}
+
+func TestFormatUTCOffset(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "UTC+00:00", FormatUTCOffset(0))
+	assert.Equal(t, "UTC+05:45", FormatUTCOffset(345))
+	assert.Equal(t, "UTC-03:30", FormatUTCOffset(-210))
+}
+
+func TestParseTimezones(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, parseTimezones(nil))
+	assert.Equal(t, map[int]int{-300: 2, 60: 1}, parseTimezones(map[string]any{"-300": 2.0, "60": 1.0}))
+}
@@ -0,0 +1,79 @@
+package devs
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/graphexport"
+)
+
+// collaborationGraphID names the collaboration network in graph exports.
+const collaborationGraphID = "collaboration"
+
+// devPair is an unordered developer pair with a < b.
+type devPair struct{ a, b int }
+
+// GenerateGraphs returns the collaboration network: one node per developer
+// weighted by commits, and one edge per pair of developers who committed in
+// the same tick, weighted by the number of such ticks.
+func (a *Analyzer) GenerateGraphs(report analyze.Report) ([]graphexport.Graph, error) {
+	data, err := ParseTickData(report)
+	if err != nil {
+		return nil, err
+	}
+
+	return []graphexport.Graph{buildCollaborationGraph(data)}, nil
+}
+
+func buildCollaborationGraph(data *TickData) graphexport.Graph {
+	graph := graphexport.Graph{ID: collaborationGraphID, Label: "Developer collaboration (shared active ticks)"}
+
+	developers := NewDevelopersMetric().Compute(data)
+	slices.SortFunc(developers, func(x, y DeveloperData) int { return cmp.Compare(x.ID, y.ID) })
+
+	for _, dev := range developers {
+		graph.Nodes = append(graph.Nodes, graphexport.Node{
+			ID:     strconv.Itoa(dev.ID),
+			Label:  dev.Name,
+			Weight: float64(dev.Commits),
+		})
+	}
+
+	shared := make(map[devPair]int)
+
+	for _, devTicks := range data.Ticks {
+		active := make([]int, 0, len(devTicks))
+		for id := range devTicks {
+			active = append(active, id)
+		}
+
+		slices.Sort(active)
+
+		for i, x := range active {
+			for _, y := range active[i+1:] {
+				shared[devPair{x, y}]++
+			}
+		}
+	}
+
+	pairs := make([]devPair, 0, len(shared))
+	for pair := range shared {
+		pairs = append(pairs, pair)
+	}
+
+	slices.SortFunc(pairs, func(x, y devPair) int {
+		return cmp.Or(cmp.Compare(x.a, y.a), cmp.Compare(x.b, y.b))
+	})
+
+	for _, pair := range pairs {
+		graph.Edges = append(graph.Edges, graphexport.Edge{
+			Source: strconv.Itoa(pair.a),
+			Target: strconv.Itoa(pair.b),
+			Weight: float64(shared[pair]),
+		})
+	}
+
+	return graph
+}
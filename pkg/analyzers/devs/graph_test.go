@@ -0,0 +1,38 @@
+package devs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/graphexport"
+)
+
+func TestGenerateGraphs_Collaboration(t *testing.T) {
+	t.Parallel()
+
+	ticks := map[int]map[int]*DevTick{
+		0: {0: {Commits: 5}, 1: {Commits: 3}},
+		1: {0: {Commits: 8}, 1: {Commits: 1}, 2: {Commits: 2}},
+		2: {2: {Commits: 4}},
+	}
+	report := ticksToCanonicalReport(ticks, []string{"Alice", "Bob", "Carol"})
+
+	graphs, err := NewAnalyzer().GenerateGraphs(report)
+	require.NoError(t, err)
+	require.Len(t, graphs, 1)
+
+	graph := graphs[0]
+	assert.Equal(t, "collaboration", graph.ID)
+	assert.Equal(t, []graphexport.Node{
+		{ID: "0", Label: "Alice", Weight: 13},
+		{ID: "1", Label: "Bob", Weight: 4},
+		{ID: "2", Label: "Carol", Weight: 6},
+	}, graph.Nodes)
+	assert.Equal(t, []graphexport.Edge{
+		{Source: "0", Target: "1", Weight: 2},
+		{Source: "0", Target: "2", Weight: 1},
+		{Source: "1", Target: "2", Weight: 1},
+	}, graph.Edges)
+}
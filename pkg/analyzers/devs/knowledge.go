@@ -0,0 +1,203 @@
+package devs
+
+import (
+	"path"
+	"sort"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/metrics"
+)
+
+const (
+	// reportKeyFileOwnership carries burndown's surviving lines per file and author,
+	// copied into the devs report by the knowledge map derived metric.
+	reportKeyFileOwnership = "FileOwnership"
+
+	// burndownFlag is the flag of the analyzer that produces file ownership.
+	burndownFlag = "burndown"
+
+	rootDirectory = "."
+)
+
+// DerivedKnowledgeMap is the name of the derived metric that feeds the knowledge map.
+const DerivedKnowledgeMap = "devs.knowledge_map"
+
+// RegisterDerivedMetrics registers the devs derived metrics with the analyze package.
+func RegisterDerivedMetrics() {
+	analyze.RegisterDerivedMetric(analyze.DerivedMetric{
+		Name:     DerivedKnowledgeMap,
+		Requires: []string{analyzerNameDevs, burndownFlag},
+		Compute:  joinFileOwnership,
+	})
+}
+
+// joinFileOwnership copies burndown's per-file ownership into the devs report so
+// the knowledge map can weigh surviving lines by whether their authors are still
+// active. Burndown only reports ownership when it tracked files and people
+// (--burndown-files --burndown-people).
+func joinFileOwnership(reports map[string]analyze.Report) error {
+	fo, ok := reports[burndownFlag][reportKeyFileOwnership].(map[string]map[int]int)
+	if ok && len(fo) > 0 {
+		reports[analyzerNameDevs][reportKeyFileOwnership] = fo
+	}
+
+	return nil
+}
+
+func parseFileOwnership(report analyze.Report) map[string]map[int]int {
+	if fo, ok := report[reportKeyFileOwnership].(map[string]map[int]int); ok {
+		return fo
+	}
+
+	return nil
+}
+
+// KnowledgeData is the at-risk knowledge summary of one directory.
+type KnowledgeData struct {
+	Directory      string  `json:"directory"        yaml:"directory"`
+	Files          int     `json:"files"            yaml:"files"`
+	TotalLines     int     `json:"total_lines"      yaml:"total_lines"`
+	ActiveLines    int     `json:"active_lines"     yaml:"active_lines"`
+	AtRiskLines    int     `json:"at_risk_lines"    yaml:"at_risk_lines"`
+	AtRiskScore    float64 `json:"at_risk_score"    yaml:"at_risk_score"`
+	ActiveOwners   int     `json:"active_owners"    yaml:"active_owners"`
+	DepartedOwners int     `json:"departed_owners"  yaml:"departed_owners"`
+	TopOwnerID     int     `json:"top_owner_id"     yaml:"top_owner_id"`
+	TopOwnerName   string  `json:"top_owner_name"   yaml:"top_owner_name"`
+	TopOwnerPct    float64 `json:"top_owner_pct"    yaml:"top_owner_pct"`
+	TopOwnerActive bool    `json:"top_owner_active" yaml:"top_owner_active"`
+}
+
+// KnowledgeMapMetric computes experience-weighted ownership per directory.
+type KnowledgeMapMetric struct {
+	metrics.MetricMeta
+}
+
+// NewKnowledgeMapMetric creates the knowledge map metric.
+func NewKnowledgeMapMetric() *KnowledgeMapMetric {
+	return &KnowledgeMapMetric{
+		MetricMeta: metrics.MetricMeta{
+			MetricName:        "knowledge_map",
+			MetricDisplayName: "Knowledge Map",
+			MetricDescription: "Surviving lines per directory (from burndown file ownership) split by whether their " +
+				"author is still active. At-risk score = share of lines whose author has not committed in the last " +
+				"90 days. Requires history/burndown with file and people tracking.",
+			MetricType: "risk",
+		},
+	}
+}
+
+// Compute calculates the knowledge map, sorted by at-risk lines (highest first).
+func (m *KnowledgeMapMetric) Compute(input *TickData) []KnowledgeData {
+	if len(input.FileOwnership) == 0 {
+		return nil
+	}
+
+	active := activeAuthors(input)
+	byDir := make(map[string]*knowledgeDir)
+
+	for file, owners := range input.FileOwnership {
+		dir := path.Dir(file)
+
+		kd := byDir[dir]
+		if kd == nil {
+			kd = &knowledgeDir{lines: make(map[int]int)}
+			byDir[dir] = kd
+		}
+
+		kd.files++
+
+		for authorID, lines := range owners {
+			kd.lines[authorID] += lines
+		}
+	}
+
+	result := make([]KnowledgeData, 0, len(byDir))
+
+	for dir, kd := range byDir {
+		result = append(result, kd.summarize(dir, active, input.Names))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AtRiskLines != result[j].AtRiskLines {
+			return result[i].AtRiskLines > result[j].AtRiskLines
+		}
+
+		return result[i].Directory < result[j].Directory
+	})
+
+	return result
+}
+
+// knowledgeDir accumulates surviving lines per author for one directory.
+type knowledgeDir struct {
+	files int
+	lines map[int]int
+}
+
+func (kd *knowledgeDir) summarize(dir string, active map[int]bool, names []string) KnowledgeData {
+	data := KnowledgeData{Directory: dir, Files: kd.files, TopOwnerID: -1}
+	if dir == rootDirectory {
+		data.Directory = "/"
+	}
+
+	topLines := 0
+
+	for authorID, lines := range kd.lines {
+		if lines <= 0 {
+			continue
+		}
+
+		data.TotalLines += lines
+
+		if active[authorID] {
+			data.ActiveLines += lines
+			data.ActiveOwners++
+		} else {
+			data.AtRiskLines += lines
+			data.DepartedOwners++
+		}
+
+		// Ties go to the lower ID so results are deterministic.
+		if lines > topLines || (lines == topLines && authorID < data.TopOwnerID) {
+			topLines = lines
+			data.TopOwnerID = authorID
+		}
+	}
+
+	if data.TotalLines == 0 {
+		return data
+	}
+
+	data.AtRiskScore = float64(data.AtRiskLines) / float64(data.TotalLines)
+	data.TopOwnerName = devName(data.TopOwnerID, names)
+	data.TopOwnerPct = float64(topLines) / float64(data.TotalLines) * percentMultiplier
+	data.TopOwnerActive = active[data.TopOwnerID]
+
+	return data
+}
+
+// activeAuthors returns the authors with a commit inside the active window,
+// using the same threshold as the aggregate ActiveDevelopers count.
+func activeAuthors(input *TickData) map[int]bool {
+	active := make(map[int]bool)
+
+	if len(input.Ticks) == 0 {
+		return active
+	}
+
+	tickKeys := sortedKeys(input.Ticks)
+	threshold := computeActiveThreshold(tickKeys[len(tickKeys)-1], input.TickSize)
+
+	for tick, devTicks := range input.Ticks {
+		if tick < threshold {
+			continue
+		}
+
+		for devID := range devTicks {
+			active[devID] = true
+		}
+	}
+
+	return active
+}
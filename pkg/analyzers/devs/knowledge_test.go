@@ -0,0 +1,122 @@
+package devs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestJoinFileOwnership_CopiesFileOwnership(t *testing.T) {
+	t.Parallel()
+
+	ownership := map[string]map[int]int{"pkg/a.go": {0: 10}}
+	reports := map[string]analyze.Report{
+		"devs":     {},
+		"burndown": {"FileOwnership": ownership},
+	}
+
+	require.NoError(t, joinFileOwnership(reports))
+	assert.Equal(t, ownership, reports["devs"][reportKeyFileOwnership])
+}
+
+func TestJoinFileOwnership_NoOwnership(t *testing.T) {
+	t.Parallel()
+
+	reports := map[string]analyze.Report{"devs": {}, "burndown": {}}
+
+	require.NoError(t, joinFileOwnership(reports))
+	assert.NotContains(t, reports["devs"], reportKeyFileOwnership)
+}
+
+func TestRegisterDerivedMetrics_RequiresBurndown(t *testing.T) {
+	t.Parallel()
+
+	RegisterDerivedMetrics()
+
+	registered, err := analyze.RegisteredDerivedMetrics()
+	require.NoError(t, err)
+
+	var found *analyze.DerivedMetric
+
+	for i := range registered {
+		if registered[i].Name == DerivedKnowledgeMap {
+			found = &registered[i]
+		}
+	}
+
+	require.NotNil(t, found)
+	assert.ElementsMatch(t, []string{"devs", "burndown"}, found.Requires)
+}
+
+func TestKnowledgeMapMetric_NoOwnership(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, NewKnowledgeMapMetric().Compute(&TickData{}))
+}
+
+func TestKnowledgeMapMetric_WeighsByActivity(t *testing.T) {
+	t.Parallel()
+
+	// Alice (0) commits at tick 100; Bob (1) last committed at tick 0, which is
+	// outside the 90-day active window with daily ticks.
+	input := &TickData{
+		Ticks: map[int]map[int]*DevTick{
+			0:   {1: {Commits: 1}},
+			100: {0: {Commits: 1}},
+		},
+		Names:    []string{testDevName1, testDevName2},
+		TickSize: testTickSize,
+		FileOwnership: map[string]map[int]int{
+			"core/a.go": {0: 10, 1: 30},
+			"core/b.go": {1: 20},
+			"web/c.go":  {0: 40},
+			"main.go":   {2: 5},
+		},
+	}
+
+	result := NewKnowledgeMapMetric().Compute(input)
+	require.Len(t, result, 3)
+
+	core := result[0]
+	assert.Equal(t, "core", core.Directory)
+	assert.Equal(t, 2, core.Files)
+	assert.Equal(t, 60, core.TotalLines)
+	assert.Equal(t, 10, core.ActiveLines)
+	assert.Equal(t, 50, core.AtRiskLines)
+	assert.InDelta(t, 50.0/60.0, core.AtRiskScore, 1e-9)
+	assert.Equal(t, 1, core.ActiveOwners)
+	assert.Equal(t, 1, core.DepartedOwners)
+	assert.Equal(t, testDevName2, core.TopOwnerName)
+	assert.InDelta(t, 50.0/60.0*100, core.TopOwnerPct, 1e-9)
+	assert.False(t, core.TopOwnerActive)
+
+	// Authors unknown to devs count as departed.
+	root := result[1]
+	assert.Equal(t, "/", root.Directory)
+	assert.Equal(t, 5, root.AtRiskLines)
+	assert.InDelta(t, 1.0, root.AtRiskScore, 1e-9)
+
+	web := result[2]
+	assert.Equal(t, "web", web.Directory)
+	assert.Equal(t, 0, web.AtRiskLines)
+	assert.Equal(t, testDevName1, web.TopOwnerName)
+	assert.True(t, web.TopOwnerActive)
+}
+
+func TestComputeAllMetrics_KnowledgeMap(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		"ReversedPeopleDict": []string{testDevName1},
+		"FileOwnership":      map[string]map[int]int{"a/x.go": {0: 7}},
+	}
+
+	computed, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+	require.Len(t, computed.KnowledgeMap, 1)
+	assert.Equal(t, "a", computed.KnowledgeMap[0].Directory)
+	assert.Equal(t, 7, computed.KnowledgeMap[0].AtRiskLines)
+}
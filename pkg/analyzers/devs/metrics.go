@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/identity"
 	"github.com/Sumatoshi-tech/codefang/pkg/metrics"
@@ -26,8 +27,24 @@ type TickData struct {
 	Ticks    map[int]map[int]*DevTick
 	Names    []string
 	TickSize time.Duration
+
+	// SampleFactor is the commit sampling ratio the commit counts were scaled
+	// by (0 when the history was not sampled).
+	SampleFactor float64
+
+	// Calendar maps ticks to calendar periods (nil for fixed-size ticks).
+	Calendar *pkgplumbing.TickCalendar
+
+	// FileOwnership is burndown's surviving lines per file and author, present
+	// only when the knowledge map derived metric joined a burndown report into this one.
+	FileOwnership map[string]map[int]int
 }
 
+const (
+	// reportKeyTickCalendar is the report key carrying the *TickCalendar.
+	reportKeyTickCalendar = "TickCalendar"
+)
+
 // AggregateCommitsToTicks builds per-tick per-developer data from per-commit
 // data grouped by the commits_by_tick mapping.
 func AggregateCommitsToTicks(
@@ -51,6 +68,8 @@ func AggregateCommitsToTicks(
 }
 
 // aggregateDevTickFromCommits merges commit-level dev data into per-author DevTick entries for a single tick.
+// A commit with co-authors counts for each of them, and its lines are split between them and the author
+// by the commit's co-author weight; its timezone stays with the author.
 func aggregateDevTickFromCommits(hashes []gitlib.Hash, commitDevData map[string]*CommitDevData) map[int]*DevTick {
 	devTicks := make(map[int]*DevTick)
 
@@ -60,30 +79,69 @@ func aggregateDevTickFromCommits(hashes []gitlib.Hash, commitDevData map[string]
 			continue
 		}
 
-		dt := devTicks[cdd.AuthorID]
-		if dt == nil {
-			dt = &DevTick{Languages: make(map[string]pkgplumbing.LineStats)}
-			devTicks[cdd.AuthorID] = dt
+		authors := append([]int{cdd.AuthorID}, cdd.CoAuthorIDs...)
+
+		for i, author := range authors {
+			dt := devTicks[author]
+			if dt == nil {
+				dt = &DevTick{Languages: make(map[string]pkgplumbing.LineStats)}
+				devTicks[author] = dt
+			}
+
+			dt.Commits += cdd.Commits
+			dt.Added += cdd.creditShare(cdd.Added, i)
+			dt.Removed += cdd.creditShare(cdd.Removed, i)
+			dt.Changed += cdd.creditShare(cdd.Changed, i)
+
+			for lang, stats := range cdd.Languages {
+				ls := dt.Languages[lang]
+				dt.Languages[lang] = pkgplumbing.LineStats{
+					Added:   ls.Added + cdd.creditShare(stats.Added, i),
+					Removed: ls.Removed + cdd.creditShare(stats.Removed, i),
+					Changed: ls.Changed + cdd.creditShare(stats.Changed, i),
+				}
+			}
+
+			for project, stats := range cdd.Projects {
+				if dt.Projects == nil {
+					dt.Projects = make(map[string]pkgplumbing.LineStats)
+				}
+
+				ps := dt.Projects[project]
+				dt.Projects[project] = pkgplumbing.LineStats{
+					Added:   ps.Added + cdd.creditShare(stats.Added, i),
+					Removed: ps.Removed + cdd.creditShare(stats.Removed, i),
+					Changed: ps.Changed + cdd.creditShare(stats.Changed, i),
+				}
+			}
 		}
 
-		dt.Commits += cdd.Commits
-		dt.Added += cdd.Added
-		dt.Removed += cdd.Removed
-		dt.Changed += cdd.Changed
-
-		for lang, stats := range cdd.Languages {
-			ls := dt.Languages[lang]
-			dt.Languages[lang] = pkgplumbing.LineStats{
-				Added:   ls.Added + stats.Added,
-				Removed: ls.Removed + stats.Removed,
-				Changed: ls.Changed + stats.Changed,
+		dt := devTicks[cdd.AuthorID]
+
+		for offset, commits := range cdd.Timezones {
+			if dt.Timezones == nil {
+				dt.Timezones = make(map[int]int)
 			}
+
+			dt.Timezones[offset] += commits
 		}
 	}
 
 	return devTicks
 }
 
+// creditShare returns the part of total credited to the i-th author of the
+// commit, the author being the first and the co-authors following.
+func (cdd *CommitDevData) creditShare(total, i int) int {
+	// Commits recorded without a weight split their lines evenly.
+	weight := cdd.CoAuthorWeight
+	if weight == 0 {
+		weight = plumbing.DefaultCoAuthorWeight
+	}
+
+	return plumbing.CreditShares(total, len(cdd.CoAuthorIDs), weight)[i]
+}
+
 // ParseTickData extracts TickData from an analyzer report.
 func ParseTickData(report analyze.Report) (*TickData, error) {
 	names, err := parseReversedPeopleDict(report)
@@ -105,13 +163,65 @@ func ParseTickData(report analyze.Report) (*TickData, error) {
 		ticks = make(map[int]map[int]*DevTick)
 	}
 
+	sampleFactor := parseSampleFactor(report)
+	scaleDevTicks(ticks, analyze.SampleFactor(report))
+
 	return &TickData{
-		Ticks:    ticks,
-		Names:    names,
-		TickSize: tickSize,
+		Ticks:        ticks,
+		Names:        names,
+		TickSize:     tickSize,
+		SampleFactor: sampleFactor,
+		Calendar:     parseTickCalendar(report),
+
+		FileOwnership: parseFileOwnership(report),
 	}, nil
 }
 
+func parseTickCalendar(report analyze.Report) *pkgplumbing.TickCalendar {
+	if c, ok := report[reportKeyTickCalendar].(*pkgplumbing.TickCalendar); ok && c.Granularity.Calendar() {
+		return c
+	}
+
+	return nil
+}
+
+// periodLabel returns the calendar period name of tick, or "" for fixed-size ticks.
+func (td *TickData) periodLabel(tick int) string {
+	if td.Calendar == nil {
+		return ""
+	}
+
+	return td.Calendar.Label(tick)
+}
+
+func parseSampleFactor(report analyze.Report) float64 {
+	if f := analyze.SampleFactor(report); f > 1 {
+		return f
+	}
+
+	return 0
+}
+
+// scaleDevTicks multiplies sampled per-tick commit counts by factor so they
+// estimate the full history. Line counts need no scaling: sampled commits are
+// diffed against each other, so they hold the lines of the skipped commits.
+// No-op when factor <= 1.
+func scaleDevTicks(ticks map[int]map[int]*DevTick, factor float64) {
+	if factor <= 1 {
+		return
+	}
+
+	for _, devTicks := range ticks {
+		for _, dt := range devTicks {
+			dt.Commits = analyze.ScaleCount(dt.Commits, factor)
+
+			for offset, commits := range dt.Timezones {
+				dt.Timezones[offset] = analyze.ScaleCount(commits, factor)
+			}
+		}
+	}
+}
+
 func parseReversedPeopleDict(report analyze.Report) ([]string, error) {
 	v, ok := report["ReversedPeopleDict"]
 	if !ok {
@@ -181,12 +291,16 @@ func buildCommitDevDataFromMap(cddMap map[string]any) map[string]*CommitDevData
 	for hash, dataAny := range cddMap {
 		if dataMap, isMap := dataAny.(map[string]any); isMap {
 			res[hash] = &CommitDevData{
-				Commits:   intVal(dataMap["commits"]),
-				Added:     intVal(dataMap["lines_added"]),
-				Removed:   intVal(dataMap["lines_removed"]),
-				Changed:   intVal(dataMap["lines_changed"]),
-				AuthorID:  intVal(dataMap["author_id"]),
-				Languages: parseLanguages(dataMap["languages"]),
+				Commits:        intVal(dataMap["commits"]),
+				Added:          intVal(dataMap["lines_added"]),
+				Removed:        intVal(dataMap["lines_removed"]),
+				Changed:        intVal(dataMap["lines_changed"]),
+				AuthorID:       intVal(dataMap["author_id"]),
+				Languages:      parseLanguages(dataMap["languages"]),
+				Projects:       parseProjects(dataMap["projects"]),
+				Timezones:      parseTimezones(dataMap["timezones"]),
+				CoAuthorIDs:    parseAuthorIDs(dataMap["co_author_ids"]),
+				CoAuthorWeight: floatVal(dataMap["co_author_weight"]),
 			}
 		}
 	}
@@ -194,6 +308,33 @@ func buildCommitDevDataFromMap(cddMap map[string]any) map[string]*CommitDevData
 	return res
 }
 
+func parseAuthorIDs(v any) []int {
+	switch val := v.(type) {
+	case []int:
+		return val
+	case []any:
+		ids := make([]int, 0, len(val))
+		for _, id := range val {
+			ids = append(ids, intVal(id))
+		}
+
+		return ids
+	}
+
+	return nil
+}
+
+func floatVal(v any) float64 {
+	switch val := v.(type) {
+	case float64:
+		return val
+	case int:
+		return float64(val)
+	}
+
+	return 0
+}
+
 func intVal(v any) int {
 	switch val := v.(type) {
 	case float64:
@@ -227,6 +368,16 @@ func parseLanguages(v any) map[string]pkgplumbing.LineStats {
 	return res
 }
 
+// parseProjects parses per-project line statistics; nil when there are none.
+func parseProjects(v any) map[string]pkgplumbing.LineStats {
+	res := parseLanguages(v)
+	if len(res) == 0 {
+		return nil
+	}
+
+	return res
+}
+
 func parseCommitsByTick(report analyze.Report) (map[int][]gitlib.Hash, bool) {
 	v, ok := report["CommitsByTick"]
 	if !ok {
@@ -267,17 +418,18 @@ func buildCommitsByTickFromMap(cbtMap map[string]any) map[int][]gitlib.Hash {
 
 // DeveloperData contains computed data for a single developer.
 type DeveloperData struct {
-	ID          int                              `json:"id"            yaml:"id"`
-	Name        string                           `json:"name"          yaml:"name"`
-	Commits     int                              `json:"commits"       yaml:"commits"`
-	Added       int                              `json:"lines_added"   yaml:"lines_added"`
-	Removed     int                              `json:"lines_removed" yaml:"lines_removed"`
-	Changed     int                              `json:"lines_changed" yaml:"lines_changed"`
-	NetLines    int                              `json:"net_lines"     yaml:"net_lines"`
-	Languages   map[string]pkgplumbing.LineStats `json:"languages"     yaml:"languages"`
-	FirstTick   int                              `json:"first_tick"    yaml:"first_tick"`
-	LastTick    int                              `json:"last_tick"     yaml:"last_tick"`
-	ActiveTicks int                              `json:"active_ticks"  yaml:"active_ticks"`
+	ID          int                              `json:"id"                 yaml:"id"`
+	Name        string                           `json:"name"               yaml:"name"`
+	Commits     int                              `json:"commits"            yaml:"commits"`
+	Added       int                              `json:"lines_added"        yaml:"lines_added"`
+	Removed     int                              `json:"lines_removed"      yaml:"lines_removed"`
+	Changed     int                              `json:"lines_changed"      yaml:"lines_changed"`
+	NetLines    int                              `json:"net_lines"          yaml:"net_lines"`
+	Languages   map[string]pkgplumbing.LineStats `json:"languages"          yaml:"languages"`
+	Projects    map[string]pkgplumbing.LineStats `json:"projects,omitempty" yaml:"projects,omitempty"`
+	FirstTick   int                              `json:"first_tick"         yaml:"first_tick"`
+	LastTick    int                              `json:"last_tick"          yaml:"last_tick"`
+	ActiveTicks int                              `json:"active_ticks"       yaml:"active_ticks"`
 }
 
 // LanguageData contains computed data for a programming language.
@@ -306,17 +458,19 @@ type BusFactorData struct {
 
 // ActivityData contains time-series activity for a single tick.
 type ActivityData struct {
-	Tick         int         `json:"tick"          yaml:"tick"`
-	ByDeveloper  map[int]int `json:"by_developer"  yaml:"by_developer"`
-	TotalCommits int         `json:"total_commits" yaml:"total_commits"`
+	Tick         int         `json:"tick"             yaml:"tick"`
+	Period       string      `json:"period,omitempty" yaml:"period,omitempty"`
+	ByDeveloper  map[int]int `json:"by_developer"     yaml:"by_developer"`
+	TotalCommits int         `json:"total_commits"    yaml:"total_commits"`
 }
 
 // ChurnData contains code churn for a single tick.
 type ChurnData struct {
-	Tick    int `json:"tick"          yaml:"tick"`
-	Added   int `json:"lines_added"   yaml:"lines_added"`
-	Removed int `json:"lines_removed" yaml:"lines_removed"`
-	Net     int `json:"net_change"    yaml:"net_change"`
+	Tick    int    `json:"tick"             yaml:"tick"`
+	Period  string `json:"period,omitempty" yaml:"period,omitempty"`
+	Added   int    `json:"lines_added"      yaml:"lines_added"`
+	Removed int    `json:"lines_removed"    yaml:"lines_removed"`
+	Net     int    `json:"net_change"       yaml:"net_change"`
 }
 
 // AggregateData contains summary statistics.
@@ -407,6 +561,12 @@ func updateDevStats(dev *DeveloperData, dt *DevTick, tick int) {
 	}
 
 	mergeLanguageStats(dev.Languages, dt.Languages)
+
+	if len(dt.Projects) > 0 && dev.Projects == nil {
+		dev.Projects = make(map[string]pkgplumbing.LineStats, len(dt.Projects))
+	}
+
+	mergeLanguageStats(dev.Projects, dt.Projects)
 }
 
 func mergeLanguageStats(target, source map[string]pkgplumbing.LineStats) {
@@ -487,6 +647,72 @@ func (m *LanguagesMetric) Compute(developers []DeveloperData) []LanguageData {
 	return result
 }
 
+// ProjectData contains computed data for a project of a monorepo.
+type ProjectData struct {
+	Root              string      `json:"root"               yaml:"root"`
+	TotalLines        int         `json:"total_lines"        yaml:"total_lines"`
+	TotalContribution int         `json:"total_contribution" yaml:"total_contribution"`
+	Contributors      map[int]int `json:"contributors"       yaml:"contributors"`
+}
+
+// ProjectsMetric computes per-project statistics.
+type ProjectsMetric struct {
+	metrics.MetricMeta
+}
+
+// NewProjectsMetric creates the projects metric.
+func NewProjectsMetric() *ProjectsMetric {
+	return &ProjectsMetric{
+		MetricMeta: metrics.MetricMeta{
+			MetricName:        "projects",
+			MetricDisplayName: "Project Statistics",
+			MetricDescription: "Per-project contribution statistics for the projects discovered in a monorepo, " +
+				"showing total lines and contributor breakdown. Projects are sorted by total lines added.",
+			MetricType: "list",
+		},
+	}
+}
+
+// Compute calculates project statistics from developer data. Returns nil
+// when no project was discovered.
+func (m *ProjectsMetric) Compute(developers []DeveloperData) []ProjectData {
+	projectMap := make(map[string]*ProjectData)
+
+	for _, dev := range developers {
+		for root, stats := range dev.Projects {
+			pd := projectMap[root]
+			if pd == nil {
+				pd = &ProjectData{Root: root, Contributors: make(map[int]int)}
+				projectMap[root] = pd
+			}
+
+			pd.TotalLines += stats.Added
+			contribution := stats.Added + stats.Removed
+			pd.TotalContribution += contribution
+			pd.Contributors[dev.ID] += contribution
+		}
+	}
+
+	if len(projectMap) == 0 {
+		return nil
+	}
+
+	result := make([]ProjectData, 0, len(projectMap))
+	for _, pd := range projectMap {
+		result = append(result, *pd)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalLines != result[j].TotalLines {
+			return result[i].TotalLines > result[j].TotalLines
+		}
+
+		return result[i].Root < result[j].Root
+	})
+
+	return result
+}
+
 // BusFactorInput is the input for bus factor computation.
 type BusFactorInput struct {
 	Languages []LanguageData
@@ -659,6 +885,7 @@ func (m *ActivityMetric) Compute(input *TickData) []ActivityData {
 	for i, tick := range tickKeys {
 		ad := ActivityData{
 			Tick:        tick,
+			Period:      input.periodLabel(tick),
 			ByDeveloper: make(map[int]int),
 		}
 
@@ -698,7 +925,7 @@ func (m *ChurnMetric) Compute(input *TickData) []ChurnData {
 	result := make([]ChurnData, len(tickKeys))
 
 	for i, tick := range tickKeys {
-		cd := ChurnData{Tick: tick}
+		cd := ChurnData{Tick: tick, Period: input.periodLabel(tick)}
 
 		for _, dt := range input.Ticks[tick] {
 			cd.Added += dt.Added
@@ -840,14 +1067,18 @@ func computeProjectBusFactor(developers []DeveloperData) int {
 // ComputedMetrics holds all computed metric results for the devs analyzer.
 // This is populated by running each metric's Compute method.
 type ComputedMetrics struct {
-	Ticks      map[int]map[int]*DevTick `json:"-"          yaml:"-"`
-	TickSize   time.Duration            `json:"-"          yaml:"-"`
-	Aggregate  AggregateData            `json:"aggregate"  yaml:"aggregate"`
-	Developers []DeveloperData          `json:"developers" yaml:"developers"`
-	Languages  []LanguageData           `json:"languages"  yaml:"languages"`
-	BusFactor  []BusFactorData          `json:"busfactor"  yaml:"busfactor"`
-	Activity   []ActivityData           `json:"activity"   yaml:"activity"`
-	Churn      []ChurnData              `json:"churn"      yaml:"churn"`
+	Ticks        map[int]map[int]*DevTick `json:"-"                       yaml:"-"`
+	TickSize     time.Duration            `json:"-"                       yaml:"-"`
+	Aggregate    AggregateData            `json:"aggregate"               yaml:"aggregate"`
+	Developers   []DeveloperData          `json:"developers"              yaml:"developers"`
+	Languages    []LanguageData           `json:"languages"               yaml:"languages"`
+	Projects     []ProjectData            `json:"projects,omitempty"      yaml:"projects,omitempty"`
+	BusFactor    []BusFactorData          `json:"busfactor"               yaml:"busfactor"`
+	Activity     []ActivityData           `json:"activity"                yaml:"activity"`
+	Churn        []ChurnData              `json:"churn"                   yaml:"churn"`
+	SampleFactor float64                  `json:"sample_factor,omitempty" yaml:"sample_factor,omitempty"`
+	KnowledgeMap []KnowledgeData          `json:"knowledge_map,omitempty" yaml:"knowledge_map,omitempty"`
+	Geography    *GeographyData           `json:"geography,omitempty"     yaml:"geography,omitempty"`
 }
 
 // ComputeAllMetrics runs all devs metrics and returns the results.
@@ -864,6 +1095,9 @@ func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
 	langMetric := NewLanguagesMetric()
 	languages := langMetric.Compute(developers)
 
+	projectMetric := NewProjectsMetric()
+	projects := projectMetric.Compute(developers)
+
 	busMetric := NewBusFactorMetric()
 	busFactor := busMetric.Compute(BusFactorInput{Languages: languages, Names: input.Names})
 
@@ -873,6 +1107,12 @@ func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
 	churnMetric := NewChurnMetric()
 	churn := churnMetric.Compute(input)
 
+	knowledgeMetric := NewKnowledgeMapMetric()
+	knowledgeMap := knowledgeMetric.Compute(input)
+
+	geographyMetric := NewGeographyMetric()
+	geography := geographyMetric.Compute(input)
+
 	aggMetric := NewAggregateMetric()
 	aggregate := aggMetric.Compute(AggregateInput{
 		Developers: developers,
@@ -886,10 +1126,15 @@ func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
 		TickSize:   input.TickSize,
 		Developers: developers,
 		Languages:  languages,
+		Projects:   projects,
 		BusFactor:  busFactor,
 		Activity:   activity,
 		Churn:      churn,
 		Aggregate:  aggregate,
+
+		SampleFactor: input.SampleFactor,
+		KnowledgeMap: knowledgeMap,
+		Geography:    geography,
 	}, nil
 }
 
@@ -59,6 +59,35 @@ func TestParseTickData_Valid(t *testing.T) {
 	assert.Equal(t, testTickSize, data.TickSize)
 }
 
+func TestParseTickData_ScalesBySampleFactor(t *testing.T) {
+	t.Parallel()
+
+	hash := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+	report := analyze.Report{
+		"CommitDevData": map[string]*CommitDevData{
+			hash: {
+				Commits: testCommits, Added: testLinesAdded, Removed: testLinesRemoved, AuthorID: 0,
+				Languages: map[string]pkgplumbing.LineStats{testLangGo: {Added: testLinesAdded}},
+			},
+		},
+		"CommitsByTick":               map[int][]gitlib.Hash{0: {gitlib.NewHash(hash)}},
+		"ReversedPeopleDict":          []string{testDevName1},
+		"TickSize":                    testTickSize,
+		analyze.ReportKeySampleFactor: 2.5,
+	}
+
+	data, err := ParseTickData(report)
+	require.NoError(t, err)
+
+	dt := data.Ticks[0][0]
+	assert.InDelta(t, 2.5, data.SampleFactor, 0)
+	assert.Equal(t, 25, dt.Commits)
+	assert.Equal(t, testLinesAdded, dt.Added, "sampled diffs already hold the skipped lines")
+	assert.Equal(t, testLinesRemoved, dt.Removed)
+	assert.Equal(t, testLinesAdded, dt.Languages[testLangGo].Added)
+}
+
 func TestParseTickData_EmptyCanonical(t *testing.T) {
 	t.Parallel()
 
@@ -396,6 +425,27 @@ func TestLanguagesMetric_ContributionIncludesRemoved(t *testing.T) {
 	assert.Equal(t, 100, result[0].Contributors[1])   // 10+90.
 }
 
+func TestProjectsMetric_GroupsByProject(t *testing.T) {
+	t.Parallel()
+
+	developers := []DeveloperData{
+		{ID: 0, Projects: map[string]pkgplumbing.LineStats{"svc/api": {Added: 60, Removed: 10}, "web": {Added: 5}}},
+		{ID: 1, Projects: map[string]pkgplumbing.LineStats{"svc/api": {Added: 40}}},
+		{ID: 2},
+	}
+
+	result := NewProjectsMetric().Compute(developers)
+
+	require.Len(t, result, 2)
+	assert.Equal(t, "svc/api", result[0].Root)
+	assert.Equal(t, 100, result[0].TotalLines)
+	assert.Equal(t, 110, result[0].TotalContribution)
+	assert.Equal(t, map[int]int{0: 70, 1: 40}, result[0].Contributors)
+	assert.Equal(t, "web", result[1].Root)
+
+	assert.Nil(t, NewProjectsMetric().Compute([]DeveloperData{{ID: 0}}), "no projects, no list")
+}
+
 // --- BusFactorMetric Tests ---.
 
 func TestBusFactorMetric_Metadata(t *testing.T) {
@@ -592,6 +642,30 @@ func TestActivityMetric_SingleTick(t *testing.T) {
 	assert.Equal(t, 3, result[0].ByDeveloper[1])
 }
 
+func TestActivityMetric_CalendarPeriods(t *testing.T) {
+	t.Parallel()
+
+	input := &TickData{
+		Ticks: map[int]map[int]*DevTick{
+			0: {0: {Commits: 1}},
+			2: {0: {Commits: 1, LineStats: pkgplumbing.LineStats{Added: 4}}},
+		},
+		Calendar: &pkgplumbing.TickCalendar{
+			Granularity: pkgplumbing.TickMonth,
+			Start:       time.Date(2024, 11, 5, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	activity := NewActivityMetric().Compute(input)
+	require.Len(t, activity, 2)
+	assert.Equal(t, "2024-11", activity[0].Period)
+	assert.Equal(t, "2025-01", activity[1].Period)
+
+	churn := NewChurnMetric().Compute(input)
+	require.Len(t, churn, 2)
+	assert.Equal(t, "2025-01", churn[1].Period)
+}
+
 func TestActivityMetric_MultipleTicks(t *testing.T) {
 	t.Parallel()
 
@@ -58,6 +58,12 @@ func (a *Analyzer) generateText(report analyze.Report, writer io.Writer) error {
 		writeChurnSummary(writer, cfg, metrics.Churn)
 	}
 
+	// Team geography, when enabled.
+	if metrics.Geography != nil {
+		fmt.Fprintln(writer)
+		writeGeography(writer, cfg, metrics.Geography)
+	}
+
 	fmt.Fprintln(writer)
 
 	return nil
@@ -159,6 +165,18 @@ func writeChurnSummary(writer io.Writer, cfg terminal.Config, churn []ChurnData)
 	fmt.Fprintf(writer, "%s%-22s %s\n", textIndent, "Net Change", formatInt(net))
 }
 
+func writeGeography(writer io.Writer, cfg terminal.Config, geography *GeographyData) {
+	fmt.Fprintf(writer, "%s%s\n", textIndent,
+		cfg.Colorize("Team Geography", terminal.ColorBlue))
+	fmt.Fprintf(writer, "%s%s\n", textIndent,
+		terminal.DrawSeparator(cfg.Width-len(textIndent)*2))
+
+	for _, region := range geography.Regions {
+		fmt.Fprintf(writer, "%s%-22s %3d devs  %6s commits  %5.1f%%\n",
+			textIndent, region.Region, region.Developers, formatInt(region.Commits), region.Share*percentMultiplier)
+	}
+}
+
 func riskToColor(level string) terminal.Color {
 	switch level {
 	case RiskCritical:
@@ -0,0 +1,427 @@
+// Package dora approximates the DORA delivery metrics from repository
+// history alone: release tags stand in for deployments, the time from a
+// commit to the first release tag after it for the lead time, and reverts
+// and hotfixes after a release for its failures.
+package dora
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/reverts"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Configuration option keys for the DORA analyzer.
+const (
+	ConfigDoraReleasePattern    = "Dora.ReleasePattern"
+	ConfigDoraHotfixPattern     = "Dora.HotfixPattern"
+	ConfigDoraFailureWindowDays = "Dora.FailureWindowDays"
+
+	defaultFailureWindowDays = 7
+
+	// defaultReleasePattern matches version tags such as v1.2.3, 1.2,
+	// release-1.2 or app/v1.2.3, but not pre-releases such as v1.2.3-rc1.
+	defaultReleasePattern = `^(.*[/-])?v?\d+(\.\d+)+$`
+	// defaultHotfixPattern matches the subjects of commits that repair a
+	// release in a hurry.
+	defaultHotfixPattern = `(?i)\b(hot-?fix(es)?|roll(ed)? ?back)\b`
+)
+
+// Report keys of the DORA analyzer.
+const (
+	KeyReleases          = "releases"
+	KeyChanges           = "changes"
+	KeyFailures          = "failures"
+	KeyTickSize          = "tick_size"
+	KeyFailureWindowDays = "failure_window_days"
+
+	// releaseBytes, changeBytes and failureBytes estimate the bytes of one
+	// release, change time and failure held by the aggregator.
+	releaseBytes = 96
+	changeBytes  = 24
+	failureBytes = 48
+
+	day = 24 * time.Hour
+)
+
+// ErrInvalidPattern indicates a release or hotfix pattern that is not a
+// valid regular expression.
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// FailureKind names what marked a commit as a failure of a release.
+type FailureKind string
+
+const (
+	// FailureRevert is a commit made by git revert.
+	FailureRevert FailureKind = "revert"
+	// FailureHotfix is a commit whose subject matches the hotfix pattern.
+	FailureHotfix FailureKind = "hotfix"
+)
+
+// CommitEvent is the per-commit payload: the release tags of the commit and
+// whether it is a change, a revert or a hotfix.
+type CommitEvent struct {
+	// Tags are the release tags pointing to the commit.
+	Tags []string
+	// Change is true for commits that are not merges; their lead time is
+	// measured.
+	Change  bool
+	Failure FailureKind
+}
+
+// Release is a commit with release tags.
+type Release struct {
+	Hash gitlib.Hash
+	Tags []string
+	Tick int
+	Time time.Time
+}
+
+// Failure is a revert or hotfix commit.
+type Failure struct {
+	Hash gitlib.Hash
+	Kind FailureKind
+	Tick int
+	Time time.Time
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	Releases []Release
+	// Changes are the commit times of the changes of the tick.
+	Changes  []time.Time
+	Failures []Failure
+}
+
+// Analyzer finds the release tags, changes, reverts and hotfixes of every
+// commit; the metrics relate them in time.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	Ticks *plumbing.TicksSinceStart
+
+	// ReleasePattern matches the names of the tags that mark a release.
+	ReleasePattern string
+	// HotfixPattern matches the subjects of hotfix commits.
+	HotfixPattern string
+	// FailureWindowDays is how many days after a release a revert or hotfix
+	// counts as its failure.
+	FailureWindowDays int
+
+	// releases maps commits to the names of their release tags. It is
+	// shared by forks and only read after Initialize.
+	releases map[gitlib.Hash][]string
+	hotfixes *regexp.Regexp
+	tickSize time.Duration
+}
+
+// NewAnalyzer creates a new DORA analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{
+		ReleasePattern:    defaultReleasePattern,
+		HotfixPattern:     defaultHotfixPattern,
+		FailureWindowDays: defaultFailureWindowDays,
+	}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/dora",
+			Mode: analyze.ModeHistory,
+			Description: "Approximates the DORA metrics from history: release tag frequency, lead time " +
+				"from commit to release tag, and reverts and hotfixes after releases.",
+		},
+		Sequential: false,
+		Caps:       &analyze.Capabilities{Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigDoraReleasePattern,
+				Description: "Regular expression matching the names of release tags.",
+				Flag:        "dora-release-pattern",
+				Type:        pipeline.StringConfigurationOption,
+				Default:     defaultReleasePattern,
+			},
+			{
+				Name:        ConfigDoraHotfixPattern,
+				Description: "Regular expression matching the subjects of hotfix commits.",
+				Flag:        "dora-hotfix-pattern",
+				Type:        pipeline.StringConfigurationOption,
+				Default:     defaultHotfixPattern,
+			},
+			{
+				Name:        ConfigDoraFailureWindowDays,
+				Description: "Count a revert or hotfix as a failure of the release made this many days before.",
+				Flag:        "dora-failure-window-days",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultFailureWindowDays,
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
+			return ComputeAllMetrics(report), nil
+		},
+		AggregatorFn: newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigDoraReleasePattern].(string); exists && val != "" {
+		a.ReleasePattern = val
+	}
+
+	if val, exists := facts[ConfigDoraHotfixPattern].(string); exists && val != "" {
+		a.HotfixPattern = val
+	}
+
+	if val, exists := facts[ConfigDoraFailureWindowDays].(int); exists && val > 0 {
+		a.FailureWindowDays = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		a.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize compiles the patterns and reads the release tags of the
+// repository when it is not nil.
+func (a *Analyzer) Initialize(repository *gitlib.Repository) error {
+	releases, err := regexp.Compile(a.ReleasePattern)
+	if err != nil {
+		return fmt.Errorf("%w: release: %w", ErrInvalidPattern, err)
+	}
+
+	a.hotfixes, err = regexp.Compile(a.HotfixPattern)
+	if err != nil {
+		return fmt.Errorf("%w: hotfix: %w", ErrInvalidPattern, err)
+	}
+
+	a.releases = make(map[gitlib.Hash][]string)
+
+	if repository == nil {
+		return nil
+	}
+
+	tags, err := repository.Tags()
+	if err != nil {
+		return fmt.Errorf("dora: %w", err)
+	}
+
+	a.setReleases(tags, releases)
+
+	return nil
+}
+
+// setReleases keeps the tags whose names match the release pattern.
+func (a *Analyzer) setReleases(tags map[gitlib.Hash][]string, pattern *regexp.Regexp) {
+	for hash, names := range tags {
+		for _, name := range names {
+			if pattern.MatchString(name) {
+				a.releases[hash] = append(a.releases[hash], name)
+			}
+		}
+
+		slices.Sort(a.releases[hash])
+	}
+}
+
+// Consume emits the release tags of the commit and whether it is a change,
+// a revert or a hotfix. Merge commits can be releases, but their changes
+// were made on the merged branch.
+func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac == nil || ac.Commit == nil {
+		return analyze.TC{}, nil
+	}
+
+	hash := ac.Commit.Hash()
+	event := &CommitEvent{Tags: a.releases[hash], Change: !ac.IsMerge}
+
+	if event.Change {
+		message := ac.Commit.Message()
+
+		switch _, revert := reverts.RevertedHash(message); {
+		case revert:
+			event.Failure = FailureRevert
+		case a.hotfixes.MatchString(subject(message)):
+			event.Failure = FailureHotfix
+		}
+	}
+
+	return analyze.TC{Data: event, CommitHash: hash}, nil
+}
+
+// Fork creates copies of the analyzer that share the release tags.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{Tick: a.Ticks.Tick}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot is a no-op for dora.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the releases, change times and failures of all
+// ticks, each in time order.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	var (
+		releases []Release
+		changes  []time.Time
+		failures []Failure
+	)
+
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*TickData)
+		if !ok || td == nil {
+			continue
+		}
+
+		releases = append(releases, td.Releases...)
+		changes = append(changes, td.Changes...)
+		failures = append(failures, td.Failures...)
+	}
+
+	slices.SortStableFunc(releases, func(x, y Release) int { return x.Time.Compare(y.Time) })
+	slices.SortFunc(changes, time.Time.Compare)
+	slices.SortStableFunc(failures, func(x, y Failure) int { return x.Time.Compare(y.Time) })
+
+	return analyze.Report{
+		KeyReleases:          releases,
+		KeyChanges:           changes,
+		KeyFailures:          failures,
+		KeyTickSize:          a.tickSize,
+		KeyFailureWindowDays: a.FailureWindowDays,
+	}
+}
+
+// subject returns the first line of message.
+func subject(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+
+	return line
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	event, ok := tc.Data.(*CommitEvent)
+	if !ok || event == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{}
+		byTick[tc.Tick] = state
+	}
+
+	if len(event.Tags) > 0 {
+		state.Releases = append(state.Releases, Release{
+			Hash: tc.CommitHash, Tags: event.Tags, Tick: tc.Tick, Time: tc.Timestamp,
+		})
+	}
+
+	if event.Change {
+		state.Changes = append(state.Changes, tc.Timestamp)
+	}
+
+	if event.Failure != "" {
+		state.Failures = append(state.Failures, Failure{
+			Hash: tc.CommitHash, Kind: event.Failure, Tick: tc.Tick, Time: tc.Timestamp,
+		})
+	}
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming == nil {
+		return existing
+	}
+
+	existing.Releases = append(existing.Releases, incoming.Releases...)
+	existing.Changes = append(existing.Changes, incoming.Changes...)
+	existing.Failures = append(existing.Failures, incoming.Failures...)
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	return int64(len(state.Releases))*releaseBytes + int64(len(state.Changes))*changeBytes +
+		int64(len(state.Failures))*failureBytes
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || (len(state.Releases) == 0 && len(state.Changes) == 0 && len(state.Failures) == 0) {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
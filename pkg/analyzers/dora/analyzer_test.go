@@ -0,0 +1,192 @@
+package dora
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func newTestAnalyzer(t *testing.T) *Analyzer {
+	t.Helper()
+
+	a := NewAnalyzer()
+	a.Ticks = &plumbing.TicksSinceStart{}
+	require.NoError(t, a.Initialize(nil))
+
+	return a
+}
+
+func testHash(c string) gitlib.Hash {
+	return gitlib.NewHash(c + "000000000000000000000000000000000000000")
+}
+
+func TestAnalyzer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	assert.Equal(t, "history/dora", a.Name())
+	assert.NotEmpty(t, a.Description())
+	assert.Len(t, a.ListConfigurationOptions(), 3)
+	assert.False(t, a.SequentialOnly())
+}
+
+func TestAnalyzer_Configure(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(map[string]any{
+		ConfigDoraReleasePattern:    `^release-`,
+		ConfigDoraHotfixPattern:     "",
+		ConfigDoraFailureWindowDays: 14,
+	}))
+	assert.Equal(t, `^release-`, a.ReleasePattern)
+	assert.Equal(t, defaultHotfixPattern, a.HotfixPattern)
+	assert.Equal(t, 14, a.FailureWindowDays)
+}
+
+func TestAnalyzer_Initialize_InvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	a.ReleasePattern = `v(`
+	require.ErrorIs(t, a.Initialize(nil), ErrInvalidPattern)
+
+	a = NewAnalyzer()
+	a.HotfixPattern = `hotfix(`
+	require.ErrorIs(t, a.Initialize(nil), ErrInvalidPattern)
+}
+
+func TestDefaultReleasePattern(t *testing.T) {
+	t.Parallel()
+
+	pattern := regexp.MustCompile(defaultReleasePattern)
+
+	for _, name := range []string{"v1.2.3", "1.2", "release-1.2", "app/v1.2.3", "v10.0"} {
+		assert.True(t, pattern.MatchString(name), name)
+	}
+
+	for _, name := range []string{"v1", "v1.2.3-rc1", "nightly", "v1.2.3-beta.1", "latest"} {
+		assert.False(t, pattern.MatchString(name), name)
+	}
+}
+
+func TestAnalyzer_SetReleases(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+	a.setReleases(map[gitlib.Hash][]string{
+		testHash("a"): {"v1.1.0", "v1.0.9"},
+		testHash("b"): {"v1.2.0-rc1"},
+	}, regexp.MustCompile(defaultReleasePattern))
+
+	assert.Equal(t, map[gitlib.Hash][]string{testHash("a"): {"v1.0.9", "v1.1.0"}}, a.releases)
+}
+
+func TestAnalyzer_Consume(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+	a.releases[testHash("a")] = []string{"v1.0.0"}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sig := gitlib.Signature{Name: "dev", When: start}
+
+	consume := func(commit *gitlib.TestCommit, merge bool) *CommitEvent {
+		t.Helper()
+
+		tc, err := a.Consume(context.Background(), &analyze.Context{Commit: commit, Time: start, IsMerge: merge})
+		require.NoError(t, err)
+		assert.Equal(t, commit.Hash(), tc.CommitHash)
+
+		event, ok := tc.Data.(*CommitEvent)
+		require.True(t, ok)
+
+		return event
+	}
+
+	event := consume(gitlib.NewTestCommit(testHash("a"), sig, "Add login"), false)
+	assert.Equal(t, &CommitEvent{Tags: []string{"v1.0.0"}, Change: true}, event)
+
+	revert := "Revert \"Add login\"\n\nThis reverts commit " + testHash("a").String() + "."
+	event = consume(gitlib.NewTestCommit(testHash("b"), sig, revert), false)
+	assert.Equal(t, FailureRevert, event.Failure)
+
+	event = consume(gitlib.NewTestCommit(testHash("c"), sig, "Hotfix: restore login\n\nNo hotfix in the body."), false)
+	assert.Equal(t, FailureHotfix, event.Failure)
+
+	event = consume(gitlib.NewTestCommit(testHash("d"), sig, "Update docs\n\nNot a hotfix."), false)
+	assert.Empty(t, event.Failure)
+
+	event = consume(gitlib.NewTestCommit(testHash("e"), sig, "Merge branch 'hotfix'"), true)
+	assert.False(t, event.Change)
+	assert.Empty(t, event.Failure)
+}
+
+func TestAnalyzer_Fork(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+
+	forks := a.Fork(2)
+	require.Len(t, forks, 2)
+
+	for _, f := range forks {
+		clone, ok := f.(*Analyzer)
+		require.True(t, ok)
+		assert.NotSame(t, a.Ticks, clone.Ticks)
+		assert.Same(t, a.hotfixes, clone.hotfixes)
+	}
+}
+
+func TestAnalyzer_ReportFromTICKs(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	byTick := make(map[int]*TickData)
+
+	for _, tc := range []analyze.TC{
+		{Tick: 1, CommitHash: testHash("c"), Timestamp: start.Add(day), Data: &CommitEvent{Change: true}},
+		{
+			Tick: 0, CommitHash: testHash("b"), Timestamp: start.Add(time.Hour),
+			Data: &CommitEvent{Tags: []string{"v1.0.0"}, Change: true},
+		},
+		{Tick: 0, CommitHash: testHash("a"), Timestamp: start, Data: &CommitEvent{Change: true}},
+		{
+			Tick: 1, CommitHash: testHash("d"), Timestamp: start.Add(2 * day),
+			Data: &CommitEvent{Change: true, Failure: FailureRevert},
+		},
+		{Tick: 1, CommitHash: testHash("e"), Timestamp: start.Add(3 * day), Data: &CommitEvent{}},
+	} {
+		require.NoError(t, extractTC(tc, byTick))
+	}
+
+	ticks := make([]analyze.TICK, 0, len(byTick))
+
+	for tick := range 2 {
+		built, err := buildTick(tick, byTick[tick])
+		require.NoError(t, err)
+
+		ticks = append(ticks, built)
+	}
+
+	report, err := NewAnalyzer().ReportFromTICKs(context.Background(), ticks)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Release{
+		{Hash: testHash("b"), Tags: []string{"v1.0.0"}, Tick: 0, Time: start.Add(time.Hour)},
+	}, report[KeyReleases])
+	assert.Equal(t, []time.Time{start, start.Add(time.Hour), start.Add(day), start.Add(2 * day)}, report[KeyChanges])
+	assert.Equal(t, []Failure{
+		{Hash: testHash("d"), Kind: FailureRevert, Tick: 1, Time: start.Add(2 * day)},
+	}, report[KeyFailures])
+	assert.Equal(t, defaultFailureWindowDays, report[KeyFailureWindowDays])
+}
@@ -0,0 +1,402 @@
+package dora
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// Level is a performance band of the DORA research.
+type Level string
+
+// Performance levels, best first. LevelNone means there is no data to rate.
+const (
+	LevelElite  Level = "elite"
+	LevelHigh   Level = "high"
+	LevelMedium Level = "medium"
+	LevelLow    Level = "low"
+	LevelNone   Level = ""
+)
+
+// Level thresholds. Deployment frequency is in releases per week.
+const (
+	eliteDeploysPerWeek  = 7
+	highDeploysPerWeek   = 1
+	mediumDeploysPerWeek = 7.0 / 30
+
+	eliteLeadTime  = day
+	highLeadTime   = 7 * day
+	mediumLeadTime = 30 * day
+
+	eliteFailureRate  = 0.05
+	highFailureRate   = 0.10
+	mediumFailureRate = 0.15
+
+	eliteRestore  = time.Hour
+	highRestore   = day
+	mediumRestore = 7 * day
+
+	week = 7 * day
+
+	percentileMedian = 0.5
+	percentileP90    = 0.9
+)
+
+// DeploymentFrequency is how often the history was released.
+type DeploymentFrequency struct {
+	Releases int     `json:"releases"        yaml:"releases"`
+	Days     float64 `json:"days"            yaml:"days"`
+	PerWeek  float64 `json:"per_week"        yaml:"per_week"`
+	Level    Level   `json:"level,omitempty" yaml:"level,omitempty"`
+}
+
+// LeadTime is the time from a change to the first release that contains it.
+type LeadTime struct {
+	Changes int `json:"changes" yaml:"changes"`
+	// Unreleased counts the changes made after the last release.
+	Unreleased  int     `json:"unreleased"      yaml:"unreleased"`
+	MedianHours float64 `json:"median_hours"    yaml:"median_hours"`
+	P90Hours    float64 `json:"p90_hours"       yaml:"p90_hours"`
+	Level       Level   `json:"level,omitempty" yaml:"level,omitempty"`
+}
+
+// ChangeFailure is the share of releases followed by a revert or hotfix
+// within the failure window.
+type ChangeFailure struct {
+	FailedReleases int     `json:"failed_releases" yaml:"failed_releases"`
+	Reverts        int     `json:"reverts"         yaml:"reverts"`
+	Hotfixes       int     `json:"hotfixes"        yaml:"hotfixes"`
+	Rate           float64 `json:"rate"            yaml:"rate"`
+	Level          Level   `json:"level,omitempty" yaml:"level,omitempty"`
+}
+
+// TimeToRestore is the time from the first failure of a release to the next
+// release.
+type TimeToRestore struct {
+	Restored    int     `json:"restored"        yaml:"restored"`
+	MedianHours float64 `json:"median_hours"    yaml:"median_hours"`
+	Level       Level   `json:"level,omitempty" yaml:"level,omitempty"`
+}
+
+// ReleaseMetrics describes one release.
+type ReleaseMetrics struct {
+	Tags []string  `json:"tags" yaml:"tags"`
+	Hash string    `json:"hash" yaml:"hash"`
+	Tick int       `json:"tick" yaml:"tick"`
+	Time time.Time `json:"time" yaml:"time"`
+	// Changes counts the changes made since the previous release.
+	Changes int `json:"changes" yaml:"changes"`
+	// LeadTimeHours is the time from the first of those changes to the
+	// release.
+	LeadTimeHours float64 `json:"lead_time_hours" yaml:"lead_time_hours"`
+	Failures      int     `json:"failures"        yaml:"failures"`
+	// RestoreHours is the time from the first failure to the next release,
+	// zero when the release did not fail or was not followed by another.
+	RestoreHours float64 `json:"restore_hours" yaml:"restore_hours"`
+}
+
+// ComputedMetrics holds the approximate DORA metrics of the history and the
+// releases they derive from.
+type ComputedMetrics struct {
+	DeploymentFrequency DeploymentFrequency `json:"deployment_frequency" yaml:"deployment_frequency"`
+	LeadTime            LeadTime            `json:"lead_time"            yaml:"lead_time"`
+	ChangeFailure       ChangeFailure       `json:"change_failure"       yaml:"change_failure"`
+	TimeToRestore       TimeToRestore       `json:"time_to_restore"      yaml:"time_to_restore"`
+	FailureWindowDays   int                 `json:"failure_window_days"  yaml:"failure_window_days"`
+	// Releases are in time order.
+	Releases []ReleaseMetrics `json:"releases" yaml:"releases"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNameDora = "dora"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNameDora
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// ComputeAllMetrics relates the changes and failures of a report to its
+// releases.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	releases, _ := report[KeyReleases].([]Release)
+	changes, _ := report[KeyChanges].([]time.Time)
+	failures, _ := report[KeyFailures].([]Failure)
+
+	windowDays, ok := report[KeyFailureWindowDays].(int)
+	if !ok || windowDays <= 0 {
+		windowDays = defaultFailureWindowDays
+	}
+
+	m := &ComputedMetrics{FailureWindowDays: windowDays, Releases: make([]ReleaseMetrics, len(releases))}
+
+	for i, release := range releases {
+		m.Releases[i] = ReleaseMetrics{
+			Tags: release.Tags, Hash: release.Hash.String(), Tick: release.Tick, Time: release.Time,
+		}
+	}
+
+	m.LeadTime = computeLeadTime(releases, changes, m.Releases)
+	m.DeploymentFrequency = computeFrequency(releases, changes)
+	m.ChangeFailure, m.TimeToRestore = computeFailures(releases, failures, time.Duration(windowDays)*day, m.Releases)
+	m.scaleCounts(analyze.SampleFactor(report))
+
+	return m
+}
+
+// scaleCounts scales the release, change and failure counts of a sampled
+// history by factor to estimate the full history: only the release tags of
+// the sampled commits are seen. The deployment frequency is scaled with them
+// and levelled again; durations and the failure rate are kept.
+func (m *ComputedMetrics) scaleCounts(factor float64) {
+	if factor <= 1 {
+		return
+	}
+
+	scale := func(n *int) { *n = analyze.ScaleCount(*n, factor) }
+
+	scale(&m.DeploymentFrequency.Releases)
+
+	if m.DeploymentFrequency.Releases > 0 {
+		m.DeploymentFrequency.PerWeek *= factor
+		m.DeploymentFrequency.Level = frequencyLevel(m.DeploymentFrequency.PerWeek)
+	}
+
+	scale(&m.LeadTime.Changes)
+	scale(&m.LeadTime.Unreleased)
+	scale(&m.ChangeFailure.FailedReleases)
+	scale(&m.ChangeFailure.Reverts)
+	scale(&m.ChangeFailure.Hotfixes)
+	scale(&m.TimeToRestore.Restored)
+
+	for i := range m.Releases {
+		scale(&m.Releases[i].Changes)
+		scale(&m.Releases[i].Failures)
+	}
+}
+
+// computeLeadTime gives every change to the first release at or after it.
+func computeLeadTime(releases []Release, changes []time.Time, out []ReleaseMetrics) LeadTime {
+	lead := LeadTime{Changes: len(changes)}
+	hours := make([]float64, 0, len(changes))
+
+	for _, change := range changes {
+		i := sort.Search(len(releases), func(j int) bool { return !releases[j].Time.Before(change) })
+		if i == len(releases) {
+			lead.Unreleased++
+
+			continue
+		}
+
+		elapsed := releases[i].Time.Sub(change)
+		hours = append(hours, elapsed.Hours())
+
+		if out[i].Changes == 0 {
+			// Changes are in time order: the first one is the oldest.
+			out[i].LeadTimeHours = elapsed.Hours()
+		}
+
+		out[i].Changes++
+	}
+
+	if len(hours) == 0 {
+		return lead
+	}
+
+	slices.Sort(hours)
+
+	lead.MedianHours = percentile(hours, percentileMedian)
+	lead.P90Hours = percentile(hours, percentileP90)
+	lead.Level = leadTimeLevel(hoursDuration(lead.MedianHours))
+
+	return lead
+}
+
+// computeFrequency spreads the releases over the span of the history.
+func computeFrequency(releases []Release, changes []time.Time) DeploymentFrequency {
+	freq := DeploymentFrequency{Releases: len(releases)}
+	if len(releases) == 0 {
+		return freq
+	}
+
+	first, last := releases[0].Time, releases[len(releases)-1].Time
+
+	if len(changes) > 0 {
+		first, last = minTime(first, changes[0]), maxTime(last, changes[len(changes)-1])
+	}
+
+	// A history shorter than a day counts as one day.
+	span := max(last.Sub(first), day)
+
+	freq.Days = span.Hours() / day.Hours()
+	freq.PerWeek = float64(len(releases)) / (float64(span) / float64(week))
+	freq.Level = frequencyLevel(freq.PerWeek)
+
+	return freq
+}
+
+// computeFailures gives every failure to the last release before it when
+// the release was made within window of it, and measures how long each
+// failed release took to be followed by the next.
+func computeFailures(
+	releases []Release, failures []Failure, window time.Duration, out []ReleaseMetrics,
+) (ChangeFailure, TimeToRestore) {
+	var (
+		failure      ChangeFailure
+		restore      TimeToRestore
+		firstFailure = make(map[int]time.Time)
+	)
+
+	for _, f := range failures {
+		switch f.Kind {
+		case FailureRevert:
+			failure.Reverts++
+		case FailureHotfix:
+			failure.Hotfixes++
+		}
+
+		// The last release strictly before the failure: a hotfix tagged as a
+		// release restores that release rather than failing itself.
+		i := sort.Search(len(releases), func(j int) bool { return !releases[j].Time.Before(f.Time) }) - 1
+		if i < 0 || f.Time.Sub(releases[i].Time) > window {
+			continue
+		}
+
+		if out[i].Failures == 0 {
+			firstFailure[i] = f.Time
+		}
+
+		out[i].Failures++
+	}
+
+	hours := make([]float64, 0, len(firstFailure))
+
+	for i, at := range firstFailure {
+		failure.FailedReleases++
+
+		next := sort.Search(len(releases), func(j int) bool { return !releases[j].Time.Before(at) })
+		if next == len(releases) {
+			continue
+		}
+
+		out[i].RestoreHours = releases[next].Time.Sub(at).Hours()
+		hours = append(hours, out[i].RestoreHours)
+	}
+
+	if len(releases) > 0 {
+		failure.Rate = float64(failure.FailedReleases) / float64(len(releases))
+		failure.Level = failureRateLevel(failure.Rate)
+	}
+
+	if len(hours) > 0 {
+		slices.Sort(hours)
+
+		restore.Restored = len(hours)
+		restore.MedianHours = percentile(hours, percentileMedian)
+		restore.Level = restoreLevel(hoursDuration(restore.MedianHours))
+	}
+
+	return failure, restore
+}
+
+func frequencyLevel(perWeek float64) Level {
+	switch {
+	case perWeek >= eliteDeploysPerWeek:
+		return LevelElite
+	case perWeek >= highDeploysPerWeek:
+		return LevelHigh
+	case perWeek >= mediumDeploysPerWeek:
+		return LevelMedium
+	default:
+		return LevelLow
+	}
+}
+
+func leadTimeLevel(lead time.Duration) Level {
+	switch {
+	case lead < eliteLeadTime:
+		return LevelElite
+	case lead < highLeadTime:
+		return LevelHigh
+	case lead < mediumLeadTime:
+		return LevelMedium
+	default:
+		return LevelLow
+	}
+}
+
+func failureRateLevel(rate float64) Level {
+	switch {
+	case rate <= eliteFailureRate:
+		return LevelElite
+	case rate <= highFailureRate:
+		return LevelHigh
+	case rate <= mediumFailureRate:
+		return LevelMedium
+	default:
+		return LevelLow
+	}
+}
+
+func restoreLevel(restore time.Duration) Level {
+	switch {
+	case restore < eliteRestore:
+		return LevelElite
+	case restore < highRestore:
+		return LevelHigh
+	case restore < mediumRestore:
+		return LevelMedium
+	default:
+		return LevelLow
+	}
+}
+
+// percentile interpolates the p-th percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	idx := p * float64(len(sorted)-1)
+	lower, upper := int(math.Floor(idx)), int(math.Ceil(idx))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(idx-float64(lower))
+}
+
+func hoursDuration(hours float64) time.Duration {
+	return time.Duration(hours * float64(time.Hour))
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+
+	return a
+}
+
+// releaseName joins the tags of a release.
+func releaseName(release ReleaseMetrics) string {
+	return strings.Join(release.Tags, ", ")
+}
@@ -0,0 +1,144 @@
+package dora
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestComputeAllMetrics(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+
+	report := analyze.Report{
+		KeyReleases: []Release{
+			{Hash: testHash("b"), Tags: []string{"v1.0.0"}, Tick: 0, Time: at(10)},
+			{Hash: testHash("d"), Tags: []string{"v1.1.0"}, Tick: 6, Time: at(6 * 24)},
+			{Hash: testHash("f"), Tags: []string{"v1.1.1"}, Tick: 7, Time: at(7 * 24)},
+			{Hash: testHash("g"), Tags: []string{"v1.2.0"}, Tick: 13, Time: at(13 * 24)},
+		},
+		// Two changes for v1.0.0, one for v1.1.0 and v1.1.1, none for v1.2.0
+		// and one unreleased.
+		KeyChanges: []time.Time{at(0), at(10), at(5 * 24), at(7*24 - 2), at(14 * 24)},
+		KeyFailures: []Failure{
+			// Fails v1.1.0 and is restored by v1.1.1 two hours later.
+			{Hash: testHash("e"), Kind: FailureHotfix, Tick: 6, Time: at(7*24 - 2)},
+			// Made after the failure window of v1.2.0.
+			{Hash: testHash("h"), Kind: FailureRevert, Tick: 21, Time: at(21 * 24)},
+		},
+		KeyFailureWindowDays: 7,
+	}
+
+	m := ComputeAllMetrics(report)
+
+	assert.Equal(t, 7, m.FailureWindowDays)
+	require.Len(t, m.Releases, 4)
+
+	assert.Equal(t, DeploymentFrequency{Releases: 4, Days: 14, PerWeek: 2, Level: LevelHigh}, m.DeploymentFrequency)
+
+	// Lead times of the released changes: 10h, 0h, 24h and 2h.
+	assert.Equal(t, 5, m.LeadTime.Changes)
+	assert.Equal(t, 1, m.LeadTime.Unreleased)
+	assert.InDelta(t, 6, m.LeadTime.MedianHours, 1e-9)
+	assert.InDelta(t, 19.8, m.LeadTime.P90Hours, 1e-9)
+	assert.Equal(t, LevelElite, m.LeadTime.Level)
+
+	assert.Equal(t, ChangeFailure{
+		FailedReleases: 1, Reverts: 1, Hotfixes: 1, Rate: 0.25, Level: LevelLow,
+	}, m.ChangeFailure)
+	assert.Equal(t, TimeToRestore{Restored: 1, MedianHours: 2, Level: LevelHigh}, m.TimeToRestore)
+
+	v100, v110, v111, v120 := m.Releases[0], m.Releases[1], m.Releases[2], m.Releases[3]
+	assert.Equal(t, testHash("b").String(), v100.Hash)
+	assert.Equal(t, 2, v100.Changes)
+	assert.InDelta(t, 10, v100.LeadTimeHours, 1e-9)
+	assert.Equal(t, 1, v110.Changes)
+	assert.InDelta(t, 24, v110.LeadTimeHours, 1e-9)
+	assert.Equal(t, 1, v110.Failures)
+	assert.InDelta(t, 2, v110.RestoreHours, 1e-9)
+	assert.Equal(t, 1, v111.Changes)
+	assert.Zero(t, v111.Failures)
+	assert.Zero(t, v120.Changes)
+	assert.Zero(t, v120.Failures)
+}
+
+func TestComputeAllMetrics_ScalesSampledCounts(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(days int) time.Time { return start.AddDate(0, 0, days) }
+
+	report := analyze.Report{
+		KeyReleases: []Release{
+			{Hash: testHash("a"), Tags: []string{"v1.0.0"}, Time: at(0)},
+			{Hash: testHash("b"), Tags: []string{"v1.1.0"}, Time: at(7)},
+		},
+		KeyChanges:                    []time.Time{at(5), at(6), at(14)},
+		KeyFailures:                   []Failure{{Hash: testHash("c"), Kind: FailureRevert, Time: at(1)}},
+		analyze.ReportKeySampleFactor: 8.0,
+	}
+
+	m := ComputeAllMetrics(report)
+
+	// One release a week in the sample is eight a week in the full history.
+	assert.Equal(t, DeploymentFrequency{Releases: 16, Days: 14, PerWeek: 8, Level: LevelElite}, m.DeploymentFrequency)
+	assert.Equal(t, 24, m.LeadTime.Changes)
+	assert.Equal(t, 8, m.LeadTime.Unreleased)
+	assert.Equal(t, 8, m.ChangeFailure.Reverts)
+	assert.Equal(t, 8, m.ChangeFailure.FailedReleases)
+	assert.InDelta(t, 0.5, m.ChangeFailure.Rate, 1e-9)
+	require.Len(t, m.Releases, 2)
+	assert.Equal(t, 16, m.Releases[1].Changes)
+	assert.Equal(t, 8, m.Releases[0].Failures)
+}
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{})
+
+	assert.Equal(t, defaultFailureWindowDays, m.FailureWindowDays)
+	assert.Empty(t, m.Releases)
+	assert.Equal(t, LevelNone, m.DeploymentFrequency.Level)
+	assert.Equal(t, LevelNone, m.LeadTime.Level)
+	assert.Equal(t, LevelNone, m.ChangeFailure.Level)
+	assert.Equal(t, LevelNone, m.TimeToRestore.Level)
+}
+
+func TestLevels(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, LevelElite, frequencyLevel(10))
+	assert.Equal(t, LevelMedium, frequencyLevel(0.5))
+	assert.Equal(t, LevelLow, frequencyLevel(0.1))
+
+	assert.Equal(t, LevelHigh, leadTimeLevel(3*day))
+	assert.Equal(t, LevelLow, leadTimeLevel(60*day))
+
+	assert.Equal(t, LevelElite, failureRateLevel(0.05))
+	assert.Equal(t, LevelMedium, failureRateLevel(0.12))
+
+	assert.Equal(t, LevelElite, restoreLevel(30*time.Minute))
+	assert.Equal(t, LevelMedium, restoreLevel(3*day))
+	assert.Equal(t, LevelLow, restoreLevel(10*day))
+}
+
+func TestGenerateSections(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sections, err := (&Analyzer{}).GenerateSections(analyze.Report{
+		KeyReleases: []Release{{Hash: testHash("a"), Tags: []string{"v1.0.0"}, Time: start}},
+		KeyChanges:  []time.Time{start},
+	})
+	require.NoError(t, err)
+	require.Len(t, sections, 3)
+	assert.Equal(t, "DORA Metrics", sections[0].Title)
+}
@@ -0,0 +1,173 @@
+package dora
+
+import (
+	"html"
+	"strconv"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const (
+	shortHashLen = 8
+	percent      = 100
+	statColumns  = 4
+	hoursPerDay  = 24
+)
+
+// RegisterPlotSections registers the dora plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/dora", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeAllMetrics(report)
+
+	return []plotpage.Section{
+		{
+			Title:    "DORA Metrics",
+			Subtitle: "Approximated from release tags, reverts and hotfixes.",
+			Chart:    buildStats(m),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"<strong>Deployment frequency</strong> = release tags per week",
+					"<strong>Lead time</strong> = median time from a commit to the first release tag after it",
+					"<strong>Change failure rate</strong> = share of releases followed by a revert or hotfix within " +
+						strconv.Itoa(m.FailureWindowDays) + " days",
+					"<strong>Time to restore</strong> = median time from the first failure of a release to the next release",
+				},
+			},
+		},
+		{
+			Title:    "Releases",
+			Subtitle: strconv.Itoa(len(m.Releases)) + " releases, " + strconv.Itoa(m.ChangeFailure.FailedReleases) + " failed.",
+			Chart:    plotpage.WrapChart(buildReleaseChart(m.Releases)),
+		},
+		{
+			Title:    "Release History",
+			Subtitle: "Lead time and failures of every release, in time order.",
+			Chart:    buildReleaseTable(m.Releases),
+		},
+	}, nil
+}
+
+func buildStats(m *ComputedMetrics) *plotpage.Grid {
+	return plotpage.NewGrid(statColumns,
+		levelStat("Deployment Frequency",
+			strconv.FormatFloat(m.DeploymentFrequency.PerWeek, 'f', 2, 64)+"/week", m.DeploymentFrequency.Level),
+		levelStat("Lead Time", formatHours(m.LeadTime.MedianHours), m.LeadTime.Level),
+		levelStat("Change Failure Rate", formatPercent(m.ChangeFailure.Rate), m.ChangeFailure.Level),
+		levelStat("Time to Restore", formatHours(m.TimeToRestore.MedianHours), m.TimeToRestore.Level),
+	)
+}
+
+func levelStat(label, value string, level Level) *plotpage.Stat {
+	stat := plotpage.NewStat(label, value)
+
+	switch level {
+	case LevelElite, LevelHigh:
+		return stat.WithTrend(string(level), plotpage.BadgeSuccess)
+	case LevelMedium:
+		return stat.WithTrend(string(level), plotpage.BadgeWarning)
+	case LevelLow:
+		return stat.WithTrend(string(level), plotpage.BadgeError)
+	case LevelNone:
+		return stat.WithTrend("no data", plotpage.BadgeDefault)
+	}
+
+	return stat
+}
+
+// buildReleaseChart counts the releases and failed releases of every tick
+// from the first release to the last.
+func buildReleaseChart(releases []ReleaseMetrics) *charts.Bar {
+	if len(releases) == 0 {
+		return plotpage.BuildBarChart(nil, nil, nil, "Releases")
+	}
+
+	first, last := releases[0].Tick, releases[0].Tick
+	for _, release := range releases {
+		first, last = min(first, release.Tick), max(last, release.Tick)
+	}
+
+	labels := make([]string, last-first+1)
+	counts := make([]int, len(labels))
+	failed := make([]int, len(labels))
+
+	for i := range labels {
+		labels[i] = strconv.Itoa(first + i)
+	}
+
+	for _, release := range releases {
+		counts[release.Tick-first]++
+
+		if release.Failures > 0 {
+			failed[release.Tick-first]++
+		}
+	}
+
+	released := make([]plotpage.SeriesData, len(labels))
+	failures := make([]plotpage.SeriesData, len(labels))
+
+	for i := range labels {
+		released[i] = counts[i]
+		failures[i] = failed[i]
+	}
+
+	return plotpage.BuildBarChart(nil, labels, []plotpage.BarSeries{
+		{Name: "Releases", Data: released},
+		{Name: "Failed", Data: failures},
+	}, "Releases")
+}
+
+func buildReleaseTable(releases []ReleaseMetrics) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Release", "Commit", "Date", "Changes", "Lead Time", "Failures", "Restored In"}).
+		WithSearch("Filter releases...")
+
+	for _, release := range releases {
+		restored := "-"
+		if release.RestoreHours > 0 {
+			restored = formatHours(release.RestoreHours)
+		}
+
+		table.AddRow(
+			html.EscapeString(releaseName(release)),
+			shortHash(release.Hash),
+			release.Time.Format("2006-01-02"),
+			strconv.Itoa(release.Changes),
+			formatHours(release.LeadTimeHours),
+			strconv.Itoa(release.Failures),
+			restored,
+		)
+	}
+
+	return table
+}
+
+// formatHours prints durations under two days in hours and longer ones in
+// days.
+func formatHours(hours float64) string {
+	if hours < 2*hoursPerDay {
+		return strconv.FormatFloat(hours, 'f', 1, 64) + "h"
+	}
+
+	return strconv.FormatFloat(hours/hoursPerDay, 'f', 1, 64) + "d"
+}
+
+func formatPercent(ratio float64) string {
+	return strconv.FormatFloat(ratio*percent, 'f', 1, 64) + "%"
+}
+
+func shortHash(hash string) string {
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+
+	return hash
+}
@@ -0,0 +1,349 @@
+// Package effort estimates the effort of writing a code base with the basic
+// COCOMO model, from the lines of code of every language as they grow over
+// the history.
+package effort
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Configuration option keys for the effort analyzer.
+const (
+	ConfigEffortModel      = "Effort.Model"
+	ConfigEffortAnnualWage = "Effort.AnnualWage"
+	ConfigEffortOverhead   = "Effort.Overhead"
+
+	// defaultAnnualWage and defaultOverhead are the wage and overhead that
+	// popular COCOMO calculators such as scc and sloccount use.
+	defaultAnnualWage = 56286
+	defaultOverhead   = 2.4
+)
+
+// Report keys of the effort analyzer.
+const (
+	KeyTicks      = "ticks"
+	KeyModel      = "model"
+	KeyAnnualWage = "annual_wage"
+	KeyOverhead   = "overhead"
+
+	// languageBytes estimates the bytes of one language or project entry
+	// held by the aggregator.
+	languageBytes = 48
+)
+
+// TickLines is the net change of the lines of code of every language and
+// every discovered project in one tick, or in one commit before aggregation.
+type TickLines struct {
+	Tick      int
+	Languages map[string]int
+	Projects  map[string]int
+}
+
+// Analyzer counts the lines of code every commit adds and removes per
+// language; the metrics accumulate them into the size of the code base and
+// its COCOMO estimate.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	Languages *plumbing.LanguagesDetectionAnalyzer
+	LineStats *plumbing.LinesStatsCalculator
+	Ticks     *plumbing.TicksSinceStart
+
+	// Model is the COCOMO project class.
+	Model Model
+	// AnnualWage is the yearly salary of one developer.
+	AnnualWage float64
+	// Overhead multiplies salaries into the full cost of a developer.
+	Overhead float64
+	// Projects are the projects of a monorepo, each estimated on its own.
+	Projects pkgplumbing.Projects
+}
+
+// NewAnalyzer creates a new effort analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{
+		Model:      ModelOrganic,
+		AnnualWage: defaultAnnualWage,
+		Overhead:   defaultOverhead,
+	}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/effort",
+			Mode: analyze.ModeHistory,
+			Description: "Estimates the effort, schedule and cost of writing the code base with the basic " +
+				"COCOMO model, per language and over time.",
+		},
+		Sequential: false,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, NeedsDiffs: true, Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigEffortModel,
+				Description: "COCOMO project class: organic, semi-detached or embedded.",
+				Flag:        "effort-model",
+				Type:        pipeline.StringConfigurationOption,
+				Default:     string(ModelOrganic),
+			},
+			{
+				Name:        ConfigEffortAnnualWage,
+				Description: "Yearly salary of one developer, used to price the effort.",
+				Flag:        "effort-annual-wage",
+				Type:        pipeline.FloatConfigurationOption,
+				Default:     float64(defaultAnnualWage),
+			},
+			{
+				Name:        ConfigEffortOverhead,
+				Description: "Factor turning salaries into the full cost of a developer.",
+				Flag:        "effort-overhead",
+				Type:        pipeline.FloatConfigurationOption,
+				Default:     defaultOverhead,
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
+			return ComputeAllMetrics(report), nil
+		},
+		AggregatorFn: newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigEffortModel].(string); exists && val != "" {
+		model, err := ParseModel(val)
+		if err != nil {
+			return err
+		}
+
+		a.Model = model
+	}
+
+	if val, exists := facts[ConfigEffortAnnualWage].(float64); exists && val > 0 {
+		a.AnnualWage = val
+	}
+
+	if val, exists := facts[ConfigEffortOverhead].(float64); exists && val > 0 {
+		a.Overhead = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactProjects].(pkgplumbing.Projects); exists {
+		a.Projects = val
+	}
+
+	return nil
+}
+
+// Initialize prepares the analyzer for processing commits.
+func (a *Analyzer) Initialize(_ *gitlib.Repository) error {
+	return nil
+}
+
+// Consume emits the net lines of code the commit added per language and per
+// discovered project. Only source and test files count: generated code,
+// documentation and configuration are not written by developers the way
+// code is. Merge commits have no line statistics.
+func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac == nil || ac.Commit == nil || ac.IsMerge || len(a.LineStats.LineStats) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	languages := a.Languages.Languages()
+	roles := a.Languages.Roles()
+	delta := &TickLines{Languages: make(map[string]int)}
+
+	if len(a.Projects) > 0 {
+		delta.Projects = make(map[string]int)
+	}
+
+	for entry, stats := range a.LineStats.LineStats {
+		lang := languages[entry.Hash]
+		if lang == "" {
+			continue
+		}
+
+		if role := roles[entry.Hash]; role != pkgplumbing.FileRoleSource && role != pkgplumbing.FileRoleTest {
+			continue
+		}
+
+		lines := stats.Added - stats.Removed
+		delta.Languages[lang] += lines
+
+		if project := a.Projects.Of(entry.Name); project != "" {
+			delta.Projects[project] += lines
+		}
+	}
+
+	unchanged := func(_ string, lines int) bool { return lines == 0 }
+	maps.DeleteFunc(delta.Languages, unchanged)
+	maps.DeleteFunc(delta.Projects, unchanged)
+
+	if len(delta.Languages) == 0 && len(delta.Projects) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	return analyze.TC{Data: delta, CommitHash: ac.Commit.Hash()}, nil
+}
+
+// Fork creates independent copies of the analyzer for parallel processing.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.Languages = &plumbing.LanguagesDetectionAnalyzer{}
+		clone.LineStats = &plumbing.LinesStatsCalculator{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Tick:      a.Ticks.Tick,
+		Languages: a.Languages.Languages(),
+		Roles:     a.Languages.Roles(),
+		LineStats: a.LineStats.LineStats,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.Ticks.Tick = snapshot.Tick
+	a.Languages.SetLanguages(snapshot.Languages)
+	a.Languages.SetRoles(snapshot.Roles)
+	a.LineStats.LineStats = snapshot.LineStats
+}
+
+// ReleaseSnapshot is a no-op for effort.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the line changes of the ticks in tick order.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	series := make([]TickLines, 0, len(ticks))
+
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*TickLines)
+		if !ok || td == nil {
+			continue
+		}
+
+		series = append(series, TickLines{Tick: tick.Tick, Languages: td.Languages, Projects: td.Projects})
+	}
+
+	slices.SortFunc(series, func(x, y TickLines) int { return x.Tick - y.Tick })
+
+	return analyze.Report{
+		KeyTicks:      series,
+		KeyModel:      a.Model,
+		KeyAnnualWage: a.AnnualWage,
+		KeyOverhead:   a.Overhead,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickLines) error {
+	delta, ok := tc.Data.(*TickLines)
+	if !ok || delta == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickLines{Tick: tc.Tick, Languages: make(map[string]int, len(delta.Languages))}
+		byTick[tc.Tick] = state
+	}
+
+	addLines(state, delta)
+
+	return nil
+}
+
+// addLines adds the line changes of delta to state.
+func addLines(state, delta *TickLines) {
+	for lang, lines := range delta.Languages {
+		state.Languages[lang] += lines
+	}
+
+	if len(delta.Projects) > 0 && state.Projects == nil {
+		state.Projects = make(map[string]int, len(delta.Projects))
+	}
+
+	for project, lines := range delta.Projects {
+		state.Projects[project] += lines
+	}
+}
+
+func mergeState(existing, incoming *TickLines) *TickLines {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming == nil {
+		return existing
+	}
+
+	addLines(existing, incoming)
+
+	return existing
+}
+
+func sizeState(state *TickLines) int64 {
+	if state == nil {
+		return 0
+	}
+
+	return int64(len(state.Languages)+len(state.Projects)) * languageBytes
+}
+
+func buildTick(tick int, state *TickLines) (analyze.TICK, error) {
+	if state == nil || len(state.Languages) == 0 && len(state.Projects) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickLines, *TickLines](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
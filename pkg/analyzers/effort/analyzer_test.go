@@ -0,0 +1,178 @@
+package effort
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func newTestAnalyzer() *Analyzer {
+	a := NewAnalyzer()
+	a.Languages = &plumbing.LanguagesDetectionAnalyzer{}
+	a.LineStats = &plumbing.LinesStatsCalculator{}
+	a.Ticks = &plumbing.TicksSinceStart{}
+
+	return a
+}
+
+func testHash(c string) gitlib.Hash {
+	return gitlib.NewHash(c + "000000000000000000000000000000000000000")
+}
+
+func TestAnalyzer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	assert.Equal(t, "history/effort", a.Name())
+	assert.NotEmpty(t, a.Description())
+	assert.Len(t, a.ListConfigurationOptions(), 3)
+	assert.False(t, a.SequentialOnly())
+}
+
+func TestAnalyzer_Configure(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(map[string]any{
+		ConfigEffortModel:      "embedded",
+		ConfigEffortAnnualWage: 90000.0,
+		ConfigEffortOverhead:   0.0,
+	}))
+	assert.Equal(t, ModelEmbedded, a.Model)
+	assert.InDelta(t, 90000, a.AnnualWage, 1e-9)
+	assert.InDelta(t, defaultOverhead, a.Overhead, 1e-9)
+
+	require.ErrorIs(t, a.Configure(map[string]any{ConfigEffortModel: "agile"}), ErrUnknownModel)
+}
+
+func TestAnalyzer_Consume(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+	commit := gitlib.NewTestCommit(testHash("c"), gitlib.Signature{Name: "dev"}, "Add server")
+
+	a.Languages.SetLanguages(map[gitlib.Hash]string{
+		testHash("1"): "Go", testHash("2"): "Go", testHash("3"): "Markdown", testHash("4"): "Go", testHash("5"): "",
+	})
+	a.Languages.SetRoles(map[gitlib.Hash]pkgplumbing.FileRole{
+		testHash("1"): pkgplumbing.FileRoleSource,
+		testHash("2"): pkgplumbing.FileRoleTest,
+		testHash("3"): pkgplumbing.FileRoleDocs,
+		testHash("4"): pkgplumbing.FileRoleGenerated,
+		testHash("5"): pkgplumbing.FileRoleSource,
+	})
+	a.LineStats.LineStats = map[gitlib.ChangeEntry]pkgplumbing.LineStats{
+		{Name: "server.go", Hash: testHash("1")}:      {Added: 120, Removed: 20},
+		{Name: "server_test.go", Hash: testHash("2")}: {Added: 30},
+		{Name: "README.md", Hash: testHash("3")}:      {Added: 40},
+		{Name: "server.pb.go", Hash: testHash("4")}:   {Added: 900},
+		{Name: "logo.png", Hash: testHash("5")}:       {Added: 1},
+	}
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	assert.Equal(t, testHash("c"), tc.CommitHash)
+	assert.Equal(t, &TickLines{Languages: map[string]int{"Go": 130}}, tc.Data)
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: commit, IsMerge: true})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+
+	a.LineStats.LineStats = map[gitlib.ChangeEntry]pkgplumbing.LineStats{
+		{Name: "server.go", Hash: testHash("1")}: {Added: 5, Removed: 5, Changed: 5},
+	}
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data, "changes that keep the size emit nothing")
+}
+
+func TestAnalyzer_ConsumeProjects(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+	require.NoError(t, a.Configure(map[string]any{
+		pkgplumbing.FactProjects: pkgplumbing.DiscoverProjects([]string{"go.mod", "web/package.json"}),
+	}))
+
+	commit := gitlib.NewTestCommit(testHash("c"), gitlib.Signature{Name: "dev"}, "Add app")
+
+	a.Languages.SetLanguages(map[gitlib.Hash]string{testHash("1"): "Go", testHash("2"): "TypeScript"})
+	a.Languages.SetRoles(map[gitlib.Hash]pkgplumbing.FileRole{
+		testHash("1"): pkgplumbing.FileRoleSource,
+		testHash("2"): pkgplumbing.FileRoleSource,
+	})
+	a.LineStats.LineStats = map[gitlib.ChangeEntry]pkgplumbing.LineStats{
+		{Name: "cmd/main.go", Hash: testHash("1")}:    {Added: 50},
+		{Name: "web/src/app.ts", Hash: testHash("2")}: {Added: 80, Removed: 10},
+	}
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	assert.Equal(t, &TickLines{
+		Languages: map[string]int{"Go": 50, "TypeScript": 70},
+		Projects:  map[string]int{".": 50, "web": 70},
+	}, tc.Data)
+}
+
+func TestAnalyzer_Fork(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+
+	forks := a.Fork(2)
+	require.Len(t, forks, 2)
+
+	for _, f := range forks {
+		clone, ok := f.(*Analyzer)
+		require.True(t, ok)
+		assert.NotSame(t, a.Languages, clone.Languages)
+		assert.NotSame(t, a.LineStats, clone.LineStats)
+		assert.Equal(t, a.Model, clone.Model)
+	}
+}
+
+func TestAnalyzer_ReportFromTICKs(t *testing.T) {
+	t.Parallel()
+
+	byTick := make(map[int]*TickLines)
+
+	for _, tc := range []analyze.TC{
+		{Tick: 3, Data: &TickLines{Languages: map[string]int{"Go": -10}}},
+		{Tick: 0, Data: &TickLines{Languages: map[string]int{"Go": 100, "Python": 20}}},
+		{Tick: 3, Data: &TickLines{Languages: map[string]int{"Python": 5}, Projects: map[string]int{"web": 5}}},
+		{Tick: 0, Data: &TickLines{Languages: map[string]int{"Go": 50}}},
+	} {
+		require.NoError(t, extractTC(tc, byTick))
+	}
+
+	ticks := make([]analyze.TICK, 0, len(byTick))
+
+	for _, tick := range []int{3, 0} {
+		built, err := buildTick(tick, byTick[tick])
+		require.NoError(t, err)
+
+		ticks = append(ticks, built)
+	}
+
+	a := NewAnalyzer()
+	a.Model = ModelSemiDetached
+
+	report, err := a.ReportFromTICKs(context.Background(), ticks)
+	require.NoError(t, err)
+
+	assert.Equal(t, []TickLines{
+		{Tick: 0, Languages: map[string]int{"Go": 150, "Python": 20}},
+		{Tick: 3, Languages: map[string]int{"Go": -10, "Python": 5}, Projects: map[string]int{"web": 5}},
+	}, report[KeyTicks])
+	assert.Equal(t, ModelSemiDetached, report[KeyModel])
+	assert.InDelta(t, defaultAnnualWage, report[KeyAnnualWage], 1e-9)
+}
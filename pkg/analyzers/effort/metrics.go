@@ -0,0 +1,177 @@
+package effort
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// LanguageEffort is the share of one language in the code base and in its
+// estimate.
+type LanguageEffort struct {
+	Language     string  `json:"language"      yaml:"language"`
+	Lines        int     `json:"lines"         yaml:"lines"`
+	Share        float64 `json:"share"         yaml:"share"`
+	PersonMonths float64 `json:"person_months" yaml:"person_months"`
+	Cost         float64 `json:"cost"          yaml:"cost"`
+}
+
+// ProjectEffort is the size of one project of a monorepo and its own
+// estimate.
+type ProjectEffort struct {
+	Project  string `json:"project" yaml:"project"`
+	Lines    int    `json:"lines"   yaml:"lines"`
+	Estimate `yaml:",inline"`
+}
+
+// TickEffort is the size of the code base at the end of one tick and its
+// estimate.
+type TickEffort struct {
+	Tick         int     `json:"tick"          yaml:"tick"`
+	Lines        int     `json:"lines"         yaml:"lines"`
+	PersonMonths float64 `json:"person_months" yaml:"person_months"`
+	Cost         float64 `json:"cost"          yaml:"cost"`
+}
+
+// ComputedMetrics is the COCOMO estimate of the code base at the end of the
+// history, split by language, and how it grew.
+type ComputedMetrics struct {
+	Model      Model   `json:"model"       yaml:"model"`
+	AnnualWage float64 `json:"annual_wage" yaml:"annual_wage"`
+	Overhead   float64 `json:"overhead"    yaml:"overhead"`
+	Lines      int     `json:"lines"       yaml:"lines"`
+	Estimate   `yaml:",inline"`
+	// Languages are ordered by lines, most first.
+	Languages []LanguageEffort `json:"languages" yaml:"languages"`
+	// Projects are ordered by lines, most first, and only present when the
+	// repository holds discovered projects.
+	Projects []ProjectEffort `json:"projects,omitempty" yaml:"projects,omitempty"`
+	Trend    []TickEffort    `json:"trend"              yaml:"trend"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNameEffort = "effort"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNameEffort
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// ComputeAllMetrics accumulates the line changes of a report into the size
+// of the code base and estimates it after every tick.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	series, _ := report[KeyTicks].([]TickLines)
+
+	m := &ComputedMetrics{
+		Model:      ModelOrganic,
+		AnnualWage: defaultAnnualWage,
+		Overhead:   defaultOverhead,
+		Trend:      make([]TickEffort, len(series)),
+	}
+
+	if model, ok := report[KeyModel].(Model); ok && model != "" {
+		m.Model = model
+	}
+
+	if wage, ok := report[KeyAnnualWage].(float64); ok && wage > 0 {
+		m.AnnualWage = wage
+	}
+
+	if overhead, ok := report[KeyOverhead].(float64); ok && overhead > 0 {
+		m.Overhead = overhead
+	}
+
+	lines := make(map[string]int)
+	projectLines := make(map[string]int)
+
+	for i, point := range series {
+		for lang, delta := range point.Languages {
+			lines[lang] += delta
+		}
+
+		for project, delta := range point.Projects {
+			projectLines[project] += delta
+		}
+
+		total := totalLines(lines)
+		estimate := m.Model.Estimate(total, m.AnnualWage, m.Overhead)
+		m.Trend[i] = TickEffort{Tick: point.Tick, Lines: total, PersonMonths: estimate.PersonMonths, Cost: estimate.Cost}
+	}
+
+	m.Lines = totalLines(lines)
+	m.Estimate = m.Model.Estimate(m.Lines, m.AnnualWage, m.Overhead)
+	m.Languages = languageEfforts(lines, m.Lines, m.Estimate)
+	m.Projects = projectEfforts(projectLines, m)
+
+	return m
+}
+
+// totalLines sums the lines of the languages. A language with fewer lines
+// than zero, whose files predate the analyzed history, counts as empty.
+func totalLines(lines map[string]int) int {
+	total := 0
+
+	for _, n := range lines {
+		total += max(n, 0)
+	}
+
+	return total
+}
+
+// languageEfforts splits the estimate by the share of every language in the
+// lines. COCOMO grows faster than linearly with size, so an estimate of each
+// language alone would not add up to the total.
+func languageEfforts(lines map[string]int, total int, estimate Estimate) []LanguageEffort {
+	result := make([]LanguageEffort, 0, len(lines))
+
+	for lang, n := range lines {
+		if n <= 0 {
+			continue
+		}
+
+		share := float64(n) / float64(total)
+		result = append(result, LanguageEffort{
+			Language: lang, Lines: n, Share: share,
+			PersonMonths: estimate.PersonMonths * share, Cost: estimate.Cost * share,
+		})
+	}
+
+	slices.SortFunc(result, func(x, y LanguageEffort) int {
+		return cmp.Or(cmp.Compare(y.Lines, x.Lines), cmp.Compare(x.Language, y.Language))
+	})
+
+	return result
+}
+
+// projectEfforts estimates every project on its own: unlike languages, the
+// projects of a monorepo are separate code bases, each written by its team.
+func projectEfforts(lines map[string]int, m *ComputedMetrics) []ProjectEffort {
+	result := make([]ProjectEffort, 0, len(lines))
+
+	for project, n := range lines {
+		if n <= 0 {
+			continue
+		}
+
+		result = append(result, ProjectEffort{
+			Project: project, Lines: n, Estimate: m.Model.Estimate(n, m.AnnualWage, m.Overhead),
+		})
+	}
+
+	slices.SortFunc(result, func(x, y ProjectEffort) int {
+		return cmp.Or(cmp.Compare(y.Lines, x.Lines), cmp.Compare(x.Project, y.Project))
+	})
+
+	return result
+}
@@ -0,0 +1,91 @@
+package effort
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestComputeAllMetrics(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{
+		KeyTicks: []TickLines{
+			{Tick: 0, Languages: map[string]int{"Go": 150, "Python": 20}},
+			// Shell files older than the history are deleted.
+			{Tick: 3, Languages: map[string]int{"Go": -10, "Python": 5, "Shell": -30}},
+		},
+		KeyModel:      ModelOrganic,
+		KeyAnnualWage: 60000.0,
+	})
+
+	assert.Equal(t, ModelOrganic, m.Model)
+	assert.InDelta(t, 60000, m.AnnualWage, 1e-9)
+	assert.InDelta(t, defaultOverhead, m.Overhead, 1e-9)
+	assert.Equal(t, 165, m.Lines)
+	assert.InDelta(t, 0.362, m.PersonMonths, 0.001)
+
+	require.Len(t, m.Trend, 2)
+	assert.Equal(t, 0, m.Trend[0].Tick)
+	assert.Equal(t, 170, m.Trend[0].Lines)
+	assert.Equal(t, 3, m.Trend[1].Tick)
+	assert.Equal(t, 165, m.Trend[1].Lines)
+	assert.InDelta(t, m.PersonMonths, m.Trend[1].PersonMonths, 1e-9)
+
+	require.Len(t, m.Languages, 2)
+	assert.Equal(t, "Go", m.Languages[0].Language)
+	assert.Equal(t, 140, m.Languages[0].Lines)
+	assert.InDelta(t, 140.0/165, m.Languages[0].Share, 1e-9)
+	assert.InDelta(t, m.PersonMonths, m.Languages[0].PersonMonths+m.Languages[1].PersonMonths, 1e-9)
+	assert.InDelta(t, m.Cost, m.Languages[0].Cost+m.Languages[1].Cost, 1e-6)
+}
+
+func TestComputeAllMetrics_Projects(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{
+		KeyTicks: []TickLines{
+			{Tick: 0, Languages: map[string]int{"Go": 3000}, Projects: map[string]int{"services/api": 1000, "web": 2000}},
+			{Tick: 1, Languages: map[string]int{"Go": -500}, Projects: map[string]int{"services/api": -1000, "web": 500}},
+		},
+	})
+
+	require.Len(t, m.Projects, 1, "projects without lines are left out")
+	assert.Equal(t, "web", m.Projects[0].Project)
+	assert.Equal(t, 2500, m.Projects[0].Lines)
+	assert.Equal(t, ModelOrganic.Estimate(2500, defaultAnnualWage, defaultOverhead), m.Projects[0].Estimate)
+}
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{})
+
+	assert.Equal(t, ModelOrganic, m.Model)
+	assert.Zero(t, m.Lines)
+	assert.Equal(t, Estimate{}, m.Estimate)
+	assert.Empty(t, m.Languages)
+	assert.Empty(t, m.Projects)
+	assert.Empty(t, m.Trend)
+}
+
+func TestGenerateSections(t *testing.T) {
+	t.Parallel()
+
+	sections, err := (&Analyzer{}).GenerateSections(analyze.Report{
+		KeyTicks: []TickLines{{Tick: 0, Languages: map[string]int{"Go": 1000}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, sections, 3)
+	assert.Equal(t, "Effort Estimate", sections[0].Title)
+
+	sections, err = (&Analyzer{}).GenerateSections(analyze.Report{
+		KeyTicks: []TickLines{{Tick: 0, Languages: map[string]int{"Go": 1000}, Projects: map[string]int{".": 1000}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, sections, 4)
+	assert.Equal(t, "Projects", sections[3].Title)
+}
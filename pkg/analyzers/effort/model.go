@@ -0,0 +1,89 @@
+package effort
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Model is a project class of the basic COCOMO model.
+type Model string
+
+// Basic COCOMO project classes.
+const (
+	// ModelOrganic is a small team working on a familiar, loosely
+	// constrained project.
+	ModelOrganic Model = "organic"
+	// ModelSemiDetached is a mixed team working on a project of medium
+	// size and constraints.
+	ModelSemiDetached Model = "semi-detached"
+	// ModelEmbedded is a project under tight hardware, software or
+	// operational constraints.
+	ModelEmbedded Model = "embedded"
+)
+
+// ErrUnknownModel indicates a project class that is not a COCOMO model.
+var ErrUnknownModel = errors.New("unknown COCOMO model")
+
+// coefficients are the basic COCOMO coefficients of a model: effort is
+// a·KLOC^b person-months and the schedule c·effort^d months.
+type coefficients struct {
+	a, b, c, d float64
+}
+
+var models = map[Model]coefficients{
+	ModelOrganic:      {a: 2.4, b: 1.05, c: 2.5, d: 0.38},
+	ModelSemiDetached: {a: 3.0, b: 1.12, c: 2.5, d: 0.35},
+	ModelEmbedded:     {a: 3.6, b: 1.20, c: 2.5, d: 0.32},
+}
+
+// ParseModel returns the model named name.
+func ParseModel(name string) (Model, error) {
+	model := Model(name)
+	if _, ok := models[model]; !ok {
+		return "", fmt.Errorf("%w: %q (want organic, semi-detached or embedded)", ErrUnknownModel, name)
+	}
+
+	return model, nil
+}
+
+const (
+	linesPerKLOC  = 1000
+	monthsPerYear = 12
+)
+
+// Estimate is the basic COCOMO estimate of writing a code base.
+type Estimate struct {
+	// PersonMonths is the development effort.
+	PersonMonths float64 `json:"person_months" yaml:"person_months"`
+	// ScheduleMonths is the development time.
+	ScheduleMonths float64 `json:"schedule_months" yaml:"schedule_months"`
+	// People is the average team size over the schedule.
+	People float64 `json:"people" yaml:"people"`
+	// Cost is the effort priced at the annual wage and overhead.
+	Cost float64 `json:"cost" yaml:"cost"`
+}
+
+// Estimate estimates writing lines of code with the model, priced at
+// annualWage per person and year times overhead. Unknown models estimate
+// as organic.
+func (m Model) Estimate(lines int, annualWage, overhead float64) Estimate {
+	if lines <= 0 {
+		return Estimate{}
+	}
+
+	coef, ok := models[m]
+	if !ok {
+		coef = models[ModelOrganic]
+	}
+
+	effort := coef.a * math.Pow(float64(lines)/linesPerKLOC, coef.b)
+	schedule := coef.c * math.Pow(effort, coef.d)
+
+	return Estimate{
+		PersonMonths:   effort,
+		ScheduleMonths: schedule,
+		People:         effort / schedule,
+		Cost:           effort * annualWage / monthsPerYear * overhead,
+	}
+}
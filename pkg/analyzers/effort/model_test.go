@@ -0,0 +1,35 @@
+package effort
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModel(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"organic", "semi-detached", "embedded"} {
+		model, err := ParseModel(name)
+		require.NoError(t, err)
+		assert.Equal(t, Model(name), model)
+	}
+
+	_, err := ParseModel("intermediate")
+	require.ErrorIs(t, err, ErrUnknownModel)
+}
+
+func TestModel_Estimate(t *testing.T) {
+	t.Parallel()
+
+	estimate := ModelOrganic.Estimate(10000, defaultAnnualWage, defaultOverhead)
+	assert.InDelta(t, 26.93, estimate.PersonMonths, 0.01)
+	assert.InDelta(t, 8.74, estimate.ScheduleMonths, 0.01)
+	assert.InDelta(t, 3.08, estimate.People, 0.01)
+	assert.InDelta(t, 303139, estimate.Cost, 1)
+
+	assert.InDelta(t, 57.06, ModelEmbedded.Estimate(10000, defaultAnnualWage, defaultOverhead).PersonMonths, 0.01)
+	assert.Equal(t, ModelOrganic.Estimate(10000, 1, 1), Model("unknown").Estimate(10000, 1, 1))
+	assert.Equal(t, Estimate{}, ModelOrganic.Estimate(0, defaultAnnualWage, defaultOverhead))
+}
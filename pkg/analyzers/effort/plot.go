@@ -0,0 +1,125 @@
+package effort
+
+import (
+	"html"
+	"strconv"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const (
+	percent     = 100
+	statColumns = 4
+)
+
+// RegisterPlotSections registers the effort plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/effort", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeAllMetrics(report)
+
+	sections := []plotpage.Section{
+		{
+			Title:    "Effort Estimate",
+			Subtitle: "Basic COCOMO, " + string(m.Model) + " model, for " + strconv.Itoa(m.Lines) + " lines of code.",
+			Chart: plotpage.NewGrid(statColumns,
+				plotpage.NewStat("Effort", formatFloat(m.PersonMonths)+" person-months"),
+				plotpage.NewStat("Schedule", formatFloat(m.ScheduleMonths)+" months"),
+				plotpage.NewStat("Team Size", formatFloat(m.People)+" people"),
+				plotpage.NewStat("Cost", formatCost(m.Cost)),
+			),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"The estimate is what writing the current code base from scratch would take, not what it took",
+					"Cost prices the effort at " + formatCost(m.AnnualWage) + " a year per developer times " +
+						strconv.FormatFloat(m.Overhead, 'f', -1, 64) + " overhead",
+				},
+			},
+		},
+		{
+			Title:    "Effort Over Time",
+			Subtitle: "Estimated effort of the code base at the end of every tick.",
+			Chart:    plotpage.WrapChart(buildTrendChart(m.Trend)),
+		},
+		{
+			Title:    "Languages",
+			Subtitle: strconv.Itoa(len(m.Languages)) + " languages, with the effort split by their share of the lines.",
+			Chart:    buildLanguageTable(m.Languages),
+		},
+	}
+
+	if len(m.Projects) > 0 {
+		sections = append(sections, plotpage.Section{
+			Title:    "Projects",
+			Subtitle: strconv.Itoa(len(m.Projects)) + " projects discovered in the repository, each estimated on its own.",
+			Chart:    buildProjectTable(m.Projects),
+		})
+	}
+
+	return sections, nil
+}
+
+func buildTrendChart(trend []TickEffort) *charts.Line {
+	labels := make([]string, len(trend))
+	effort := make([]plotpage.SeriesData, len(trend))
+
+	for i, point := range trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		effort[i] = point.PersonMonths
+	}
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: "Effort", Data: effort},
+	}, "Person-Months")
+}
+
+func buildLanguageTable(languages []LanguageEffort) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Language", "Lines", "Share", "Person-Months", "Cost"}).
+		WithSearch("Filter languages...")
+
+	for _, lang := range languages {
+		table.AddRow(
+			html.EscapeString(lang.Language),
+			strconv.Itoa(lang.Lines),
+			strconv.FormatFloat(lang.Share*percent, 'f', 1, 64)+"%",
+			formatFloat(lang.PersonMonths),
+			formatCost(lang.Cost),
+		)
+	}
+
+	return table
+}
+
+func buildProjectTable(projects []ProjectEffort) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Project", "Lines", "Person-Months", "Schedule", "Cost"}).
+		WithSearch("Filter projects...")
+
+	for _, project := range projects {
+		table.AddRow(
+			html.EscapeString(project.Project),
+			strconv.Itoa(project.Lines),
+			formatFloat(project.PersonMonths),
+			formatFloat(project.ScheduleMonths)+" months",
+			formatCost(project.Cost),
+		)
+	}
+
+	return table
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
+
+func formatCost(value float64) string {
+	return strconv.FormatFloat(value, 'f', 0, 64)
+}
@@ -2,7 +2,7 @@ package filehistory
 
 import (
 	"context"
-	"maps"
+	"slices"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/spillstore"
@@ -13,12 +13,16 @@ import (
 const (
 	fileHistoryEntryBytes = 64
 	hashEntryBytes        = 24
+	renameEntryBytes      = 64
+	modeEntryBytes        = 48
 )
 
 // Aggregator implements analyze.Aggregator for the file history analyzer.
 // It accumulates file histories and line stats from the TC stream.
 type Aggregator struct {
+	// files is keyed by rename-stable keys from renames, not by current path.
 	files          *spillstore.SpillStore[FileHistory]
+	renames        *renameChains
 	lastCommitHash gitlib.Hash
 	opts           analyze.AggregatorOptions
 	closed         bool
@@ -26,9 +30,13 @@ type Aggregator struct {
 
 // NewAggregator creates a new aggregator for the file history analyzer.
 func NewAggregator(opts analyze.AggregatorOptions) *Aggregator {
+	files := spillstore.New[FileHistory]()
+	files.SetCodec(opts.SpillCodec)
+
 	return &Aggregator{
-		files: spillstore.New[FileHistory](),
-		opts:  opts,
+		files:   files,
+		renames: newRenameChains(),
+		opts:    opts,
 	}
 }
 
@@ -44,6 +52,7 @@ func (a *Aggregator) Add(tc analyze.TC) error {
 	}
 
 	a.applyPathActions(cd.PathActions)
+	a.applyModeUpdates(cd.ModeUpdates)
 	a.applyLineStatUpdates(cd.LineStatUpdates)
 
 	if a.opts.SpillBudget > 0 && a.EstimatedStateSize() > a.opts.SpillBudget {
@@ -70,15 +79,17 @@ func (a *Aggregator) applyPathActions(actions []PathAction) {
 }
 
 func (a *Aggregator) applyInsert(path string, hash gitlib.Hash) {
-	fh := a.getOrCreate(path)
+	key := a.renames.key(path)
+	fh := a.getOrCreate(key)
 
 	fh.Hashes = []gitlib.Hash{hash}
+	fh.ModeChanges = nil
 
 	if fh.People == nil {
 		fh.People = make(map[int]plumbing.LineStats)
 	}
 
-	a.files.Put(path, *fh)
+	a.files.Put(key, *fh)
 }
 
 func (a *Aggregator) applyModify(pa PathAction) {
@@ -92,7 +103,8 @@ func (a *Aggregator) applyModify(pa PathAction) {
 		return
 	}
 
-	fh := a.getOrCreate(pa.Path)
+	key := a.renames.key(pa.Path)
+	fh := a.getOrCreate(key)
 
 	fh.Hashes = append(fh.Hashes, pa.CommitHash)
 
@@ -100,11 +112,12 @@ func (a *Aggregator) applyModify(pa PathAction) {
 		fh.People = make(map[int]plumbing.LineStats)
 	}
 
-	a.files.Put(pa.Path, *fh)
+	a.files.Put(key, *fh)
 }
 
 func (a *Aggregator) applyDelete(path string, hash gitlib.Hash) {
-	fh := a.getOrCreate(path)
+	key := a.renames.key(path)
+	fh := a.getOrCreate(key)
 
 	fh.Hashes = append(fh.Hashes, hash)
 
@@ -112,27 +125,26 @@ func (a *Aggregator) applyDelete(path string, hash gitlib.Hash) {
 		fh.People = make(map[int]plumbing.LineStats)
 	}
 
-	a.files.Put(path, *fh)
+	a.files.Put(key, *fh)
 }
 
+// applyRename keeps the file under its existing key, so its history continues
+// under the new path. The history may have been spilled; only the new commit
+// is added here and Collect merges the rest back by key.
 func (a *Aggregator) applyRename(fromPath, toPath string, commitHash gitlib.Hash) {
-	fh, ok := a.files.Get(fromPath)
-	if !ok {
-		fh = FileHistory{
-			People: make(map[int]plumbing.LineStats),
-		}
-	}
+	key := a.renames.rename(fromPath, toPath)
+	fh := a.getOrCreate(key)
 
 	fh.Hashes = append(fh.Hashes, commitHash)
 	if fh.People == nil {
 		fh.People = make(map[int]plumbing.LineStats)
 	}
 
-	a.files.Put(toPath, fh)
+	a.files.Put(key, *fh)
 }
 
-func (a *Aggregator) getOrCreate(path string) *FileHistory {
-	fh, ok := a.files.Get(path)
+func (a *Aggregator) getOrCreate(key string) *FileHistory {
+	fh, ok := a.files.Get(key)
 	if !ok {
 		fh = FileHistory{
 			People: make(map[int]plumbing.LineStats),
@@ -142,16 +154,29 @@ func (a *Aggregator) getOrCreate(path string) *FileHistory {
 	return &fh
 }
 
+// applyModeUpdates runs after applyPathActions, so renames of the same
+// commit already point at the file's key.
+func (a *Aggregator) applyModeUpdates(updates []ModeUpdate) {
+	for _, u := range updates {
+		key := a.renames.key(u.Path)
+		fh := a.getOrCreate(key)
+		fh.ModeChanges = append(fh.ModeChanges, u.Change)
+		a.files.Put(key, *fh)
+	}
+}
+
 func (a *Aggregator) applyLineStatUpdates(updates []LineStatUpdate) {
 	for _, u := range updates {
-		fh := a.getOrCreate(u.Path)
+		key := a.renames.key(u.Path)
+		fh := a.getOrCreate(key)
 		oldStats := fh.People[u.AuthorID]
 		fh.People[u.AuthorID] = plumbing.LineStats{
 			Added:   oldStats.Added + u.Stats.Added,
 			Removed: oldStats.Removed + u.Stats.Removed,
 			Changed: oldStats.Changed + u.Stats.Changed,
 		}
-		a.files.Put(u.Path, *fh)
+		fh.addHunks(u.Hunks)
+		a.files.Put(key, *fh)
 	}
 }
 
@@ -162,9 +187,7 @@ func (a *Aggregator) FlushTick(tick int) (analyze.TICK, error) {
 		return analyze.TICK{Tick: tick, Data: &TickData{Files: map[string]FileHistory{}, LastCommitHash: a.lastCommitHash}}, nil
 	}
 
-	files := make(map[string]FileHistory)
-
-	maps.Copy(files, a.files.Current())
+	files := a.filesByPath(a.files.Current())
 
 	return analyze.TICK{
 		Tick: tick,
@@ -175,6 +198,27 @@ func (a *Aggregator) FlushTick(tick int) (analyze.TICK, error) {
 	}, nil
 }
 
+// filesByPath re-keys stored histories by current path and attaches each
+// file's previous paths. Files overwritten by a rename are dropped.
+func (a *Aggregator) filesByPath(byKey map[string]FileHistory) map[string]FileHistory {
+	files := make(map[string]FileHistory, len(byKey))
+
+	for key, fh := range byKey {
+		path, live := a.renames.path(key)
+		if !live {
+			continue
+		}
+
+		if aliases := a.renames.aliases[key]; len(aliases) > 0 {
+			fh.Aliases = slices.Clone(aliases)
+		}
+
+		files[path] = fh
+	}
+
+	return files
+}
+
 // FlushAllTicks returns a single TICK containing all accumulated file history.
 func (a *Aggregator) FlushAllTicks() ([]analyze.TICK, error) {
 	t, err := a.FlushTick(0)
@@ -234,6 +278,12 @@ func mergeFileHistory(existing, incoming FileHistory) FileHistory {
 	}
 
 	existing.Hashes = append(existing.Hashes, incoming.Hashes...)
+	existing.ModeChanges = append(existing.ModeChanges, incoming.ModeChanges...)
+	existing.mergeHunks(incoming)
+
+	if len(existing.Aliases) == 0 {
+		existing.Aliases = incoming.Aliases
+	}
 
 	return existing
 }
@@ -245,6 +295,7 @@ func (a *Aggregator) EstimatedStateSize() int64 {
 	for _, fh := range a.files.Current() {
 		size += fileHistoryEntryBytes
 		size += int64(len(fh.Hashes)) * hashEntryBytes
+		size += int64(len(fh.ModeChanges)) * modeEntryBytes
 
 		for _, stats := range fh.People {
 			_ = stats
@@ -252,20 +303,25 @@ func (a *Aggregator) EstimatedStateSize() int64 {
 		}
 	}
 
+	size += int64(a.renames.size()) * renameEntryBytes
+
 	return size
 }
 
 // SpillState returns the current on-disk spill state for checkpoint persistence.
 func (a *Aggregator) SpillState() analyze.AggregatorSpillInfo {
 	return analyze.AggregatorSpillInfo{
-		Dir:   a.files.SpillDir(),
-		Count: a.files.SpillCount(),
+		Dir:     a.files.SpillDir(),
+		Count:   a.files.SpillCount(),
+		Codec:   a.files.Codec(),
+		Written: a.files.BytesWritten(),
 	}
 }
 
 // RestoreSpillState points the aggregator at a previously-saved spill directory.
 func (a *Aggregator) RestoreSpillState(info analyze.AggregatorSpillInfo) {
 	a.files.RestoreFromDir(info.Dir, info.Count)
+	a.files.SetCodec(info.SpillCodec())
 }
 
 // Close releases all resources. Idempotent.
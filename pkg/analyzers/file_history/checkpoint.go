@@ -1,7 +1,9 @@
 package filehistory
 
 import (
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
 	"github.com/Sumatoshi-tech/codefang/pkg/checkpoint"
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
@@ -11,8 +13,22 @@ const checkpointBasename = "file_history_state"
 
 // fileHistoryCheckpoint is the serializable form of FileHistory.
 type fileHistoryCheckpoint struct {
-	People map[int]pkgplumbing.LineStats `json:"people"`
-	Hashes []string                      `json:"hashes"`
+	People          map[int]pkgplumbing.LineStats `json:"people"`
+	Hashes          []string                      `json:"hashes"`
+	Aliases         []string                      `json:"aliases,omitempty"`
+	Modes           []modeChangeCheckpoint        `json:"modes,omitempty"`
+	Hunks           int                           `json:"hunks,omitempty"`
+	WhitespaceHunks int                           `json:"whitespace_hunks,omitempty"`
+	HunkLines       int                           `json:"hunk_lines,omitempty"`
+}
+
+// modeChangeCheckpoint is the serializable form of ModeChange.
+type modeChangeCheckpoint struct {
+	Hash       string                  `json:"hash"`
+	Kind       plumbing.ModeChangeKind `json:"kind"`
+	From       uint16                  `json:"from"`
+	To         uint16                  `json:"to"`
+	Suspicious bool                    `json:"suspicious,omitempty"`
 }
 
 // checkpointState holds the serializable state of the file history analyzer.
@@ -30,13 +46,13 @@ func newPersister() *checkpoint.Persister[checkpointState] {
 }
 
 // SaveCheckpoint writes the analyzer state to the given directory.
-func (h *HistoryAnalyzer) SaveCheckpoint(dir string) error {
-	return newPersister().Save(dir, h.buildCheckpointState)
+func (h *HistoryAnalyzer) SaveCheckpoint(dir string, c *crypt.Cipher) error {
+	return newPersister().Save(dir, c, h.buildCheckpointState)
 }
 
 // LoadCheckpoint restores the analyzer state from the given directory.
-func (h *HistoryAnalyzer) LoadCheckpoint(dir string) error {
-	return newPersister().Load(dir, h.restoreFromCheckpoint)
+func (h *HistoryAnalyzer) LoadCheckpoint(dir string, c *crypt.Cipher) error {
+	return newPersister().Load(dir, c, h.restoreFromCheckpoint)
 }
 
 // buildCheckpointState creates a serializable snapshot of the analyzer state.
@@ -49,14 +65,24 @@ func (h *HistoryAnalyzer) buildCheckpointState() *checkpointState {
 	// Convert files to serializable form.
 	for name, fh := range h.files {
 		cp := fileHistoryCheckpoint{
-			People: fh.People,
-			Hashes: make([]string, len(fh.Hashes)),
+			People:          fh.People,
+			Hashes:          make([]string, len(fh.Hashes)),
+			Aliases:         fh.Aliases,
+			Hunks:           fh.Hunks,
+			WhitespaceHunks: fh.WhitespaceHunks,
+			HunkLines:       fh.HunkLines,
 		}
 
 		for i, hash := range fh.Hashes {
 			cp.Hashes[i] = hash.String()
 		}
 
+		for _, mc := range fh.ModeChanges {
+			cp.Modes = append(cp.Modes, modeChangeCheckpoint{
+				Hash: mc.Hash.String(), Kind: mc.Kind, From: mc.From, To: mc.To, Suspicious: mc.Suspicious,
+			})
+		}
+
 		state.Files[name] = cp
 	}
 
@@ -74,14 +100,24 @@ func (h *HistoryAnalyzer) restoreFromCheckpoint(state *checkpointState) {
 	h.files = make(map[string]*FileHistory, len(state.Files))
 	for name, cp := range state.Files {
 		fh := &FileHistory{
-			People: cp.People,
-			Hashes: make([]gitlib.Hash, len(cp.Hashes)),
+			People:          cp.People,
+			Hashes:          make([]gitlib.Hash, len(cp.Hashes)),
+			Aliases:         cp.Aliases,
+			Hunks:           cp.Hunks,
+			WhitespaceHunks: cp.WhitespaceHunks,
+			HunkLines:       cp.HunkLines,
 		}
 
 		for i, hashStr := range cp.Hashes {
 			fh.Hashes[i] = gitlib.NewHash(hashStr)
 		}
 
+		for _, mc := range cp.Modes {
+			fh.ModeChanges = append(fh.ModeChanges, ModeChange{
+				Hash: gitlib.NewHash(mc.Hash), Kind: mc.Kind, From: mc.From, To: mc.To, Suspicious: mc.Suspicious,
+			})
+		}
+
 		h.files[name] = fh
 	}
 
@@ -18,7 +18,7 @@ func TestSaveCheckpoint_CreatesFile(t *testing.T) {
 	h := NewAnalyzer()
 	require.NoError(t, h.Initialize(nil))
 
-	err := h.SaveCheckpoint(dir)
+	err := h.SaveCheckpoint(dir, nil)
 	require.NoError(t, err)
 
 	expectedPath := filepath.Join(dir, checkpointBasename+".json")
@@ -39,10 +39,10 @@ func TestLoadCheckpoint_RestoresState(t *testing.T) {
 	}
 	original.merges[gitlib.NewHash("def456")] = true
 
-	require.NoError(t, original.SaveCheckpoint(dir))
+	require.NoError(t, original.SaveCheckpoint(dir, nil))
 
 	restored := NewAnalyzer()
-	require.NoError(t, restored.LoadCheckpoint(dir))
+	require.NoError(t, restored.LoadCheckpoint(dir, nil))
 
 	require.Len(t, restored.files, 1)
 	require.NotNil(t, restored.files["test.go"])
@@ -89,10 +89,10 @@ func TestCheckpointRoundTrip_PreservesAllState(t *testing.T) {
 	original.merges[gitlib.NewHash("merge1")] = true
 	original.merges[gitlib.NewHash("merge2")] = true
 
-	require.NoError(t, original.SaveCheckpoint(dir))
+	require.NoError(t, original.SaveCheckpoint(dir, nil))
 
 	restored := NewAnalyzer()
-	require.NoError(t, restored.LoadCheckpoint(dir))
+	require.NoError(t, restored.LoadCheckpoint(dir, nil))
 
 	// Verify files.
 	require.Len(t, restored.files, 2)
@@ -32,6 +32,58 @@ type HistoryAnalyzer struct {
 type FileHistory struct {
 	People map[int]pkgplumbing.LineStats
 	Hashes []gitlib.Hash
+	// Aliases lists the paths the file had before its current one, oldest
+	// first. The first alias is the file's canonical identity.
+	Aliases []string
+	// ModeChanges lists the executable bit and symbolic link changes of the
+	// file, oldest first.
+	ModeChanges []ModeChange
+	// Hunks counts the diff hunks of the file's modifications, and
+	// WhitespaceHunks those of them that only changed whitespace. Both stay
+	// zero unless per-hunk line statistics are enabled.
+	Hunks           int
+	WhitespaceHunks int
+	// HunkLines is the number of lines the counted hunks added and removed.
+	HunkLines int
+}
+
+// addHunks counts the hunks of one modification of the file.
+func (fh *FileHistory) addHunks(hunks []pkgplumbing.HunkStats) {
+	for _, hunk := range hunks {
+		fh.Hunks++
+		fh.HunkLines += hunk.OldLines + hunk.NewLines
+
+		if hunk.WhitespaceOnly {
+			fh.WhitespaceHunks++
+		}
+	}
+}
+
+// mergeHunks adds the hunk counts of other to the file.
+func (fh *FileHistory) mergeHunks(other FileHistory) {
+	fh.Hunks += other.Hunks
+	fh.WhitespaceHunks += other.WhitespaceHunks
+	fh.HunkLines += other.HunkLines
+}
+
+// ModeChange is a change of the mode of a file in one commit.
+type ModeChange struct {
+	Hash       gitlib.Hash
+	Kind       plumbing.ModeChangeKind
+	From       uint16
+	To         uint16
+	Suspicious bool
+}
+
+// newModeChange records change as made by the commit hash.
+func newModeChange(change plumbing.ModeChange, hash gitlib.Hash) ModeChange {
+	return ModeChange{
+		Hash:       hash,
+		Kind:       change.Kind,
+		From:       change.From,
+		To:         change.To,
+		Suspicious: change.Suspicious,
+	}
 }
 
 // NewAnalyzer creates a new file history analyzer.
@@ -45,6 +97,7 @@ func NewAnalyzer() *HistoryAnalyzer {
 	}
 
 	ha.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Caps:             &analyze.Capabilities{NeedsBlobs: true, NeedsDiffs: true, Memory: analyze.MemoryMedium},
 		ComputeMetricsFn: ComputeAllMetrics,
 		TicksToReportFn: func(ctx context.Context, t []analyze.TICK) analyze.Report {
 			return TicksToReport(ctx, t, ha.repo)
@@ -160,11 +213,19 @@ func (h *HistoryAnalyzer) buildCommitData(changes gitlib.Changes, commit analyze
 
 	_ = router.Route(changes) //nolint:errcheck // errors are always nil from our handlers.
 
+	for _, change := range plumbing.DetectModeChanges(changes) {
+		data.ModeUpdates = append(data.ModeUpdates, ModeUpdate{
+			Path:   change.File,
+			Change: newModeChange(change, commit.Hash()),
+		})
+	}
+
 	for changeEntry, stats := range h.LineStats.LineStats {
 		data.LineStatUpdates = append(data.LineStatUpdates, LineStatUpdate{
 			Path:     changeEntry.Name,
 			AuthorID: author,
 			Stats:    stats,
+			Hunks:    h.LineStats.HunkStats[changeEntry],
 		})
 	}
 
@@ -197,6 +258,7 @@ func (h *HistoryAnalyzer) processFileChanges(changes gitlib.Changes, commit anal
 				fh = oldFH
 			}
 
+			fh.Aliases = append(fh.Aliases, from)
 			fh.Hashes = append(fh.Hashes, commit.Hash())
 
 			return nil
@@ -217,6 +279,7 @@ func (h *HistoryAnalyzer) processAction(change *gitlib.Change, commit analyze.Co
 
 	if change.Action == gitlib.Insert {
 		fh.Hashes = []gitlib.Hash{commit.Hash()}
+		fh.ModeChanges = nil
 	} else {
 		fh.Hashes = append(fh.Hashes, commit.Hash())
 	}
@@ -233,6 +296,15 @@ func (h *HistoryAnalyzer) getOrCreateFileHistory(name string) *FileHistory {
 	return fh
 }
 
+// recordModeChanges appends the mode changes of the commit to the file
+// histories.
+func (h *HistoryAnalyzer) recordModeChanges(changes gitlib.Changes, commit analyze.CommitLike) {
+	for _, change := range plumbing.DetectModeChanges(changes) {
+		fh := h.getOrCreateFileHistory(change.File)
+		fh.ModeChanges = append(fh.ModeChanges, newModeChange(change, commit.Hash()))
+	}
+}
+
 // aggregateLineStats merges line statistics from the current commit into file histories.
 func (h *HistoryAnalyzer) aggregateLineStats(lineStats map[gitlib.ChangeEntry]pkgplumbing.LineStats, author int) {
 	for changeEntry, stats := range lineStats {
@@ -254,6 +326,8 @@ func (h *HistoryAnalyzer) aggregateLineStats(lineStats map[gitlib.ChangeEntry]pk
 			Removed: oldStats.Removed + stats.Removed,
 			Changed: oldStats.Changed + stats.Changed,
 		}
+
+		file.addHunks(h.LineStats.HunkStats[changeEntry])
 	}
 }
 
@@ -270,6 +344,7 @@ func (h *HistoryAnalyzer) Consume(_ context.Context, ac *analyze.Context) (analy
 	}
 
 	h.processFileChanges(h.TreeDiff.Changes, ac.Commit)
+	h.recordModeChanges(h.TreeDiff.Changes, ac.Commit)
 	h.aggregateLineStats(h.LineStats.LineStats, h.Identity.AuthorID)
 
 	data := h.buildCommitData(h.TreeDiff.Changes, ac.Commit, h.Identity.AuthorID)
@@ -291,6 +366,7 @@ func (h *HistoryAnalyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
 	return plumbing.Snapshot{
 		Changes:   h.TreeDiff.Changes,
 		LineStats: h.LineStats.LineStats,
+		HunkStats: h.LineStats.HunkStats,
 		AuthorID:  h.Identity.AuthorID,
 	}
 }
@@ -304,6 +380,7 @@ func (h *HistoryAnalyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
 
 	h.TreeDiff.Changes = snapshot.Changes
 	h.LineStats.LineStats = snapshot.LineStats
+	h.LineStats.HunkStats = snapshot.HunkStats
 	h.Identity.AuthorID = snapshot.AuthorID
 }
 
@@ -373,6 +450,8 @@ func (h *HistoryAnalyzer) mergeFiles(other map[string]*FileHistory) {
 
 		// Append hashes.
 		fh.Hashes = append(fh.Hashes, otherFH.Hashes...)
+		fh.ModeChanges = append(fh.ModeChanges, otherFH.ModeChanges...)
+		fh.mergeHunks(*otherFH)
 	}
 }
 
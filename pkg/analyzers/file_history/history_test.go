@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
@@ -421,3 +422,89 @@ func TestMerge_CombinesMerges(t *testing.T) {
 	require.True(t, main.merges[gitlib.NewHash("abc123")])
 	require.True(t, main.merges[gitlib.NewHash("def456")])
 }
+
+func TestAnalyzer_Consume_ModeChanges(t *testing.T) {
+	t.Parallel()
+
+	h := NewAnalyzer()
+	require.NoError(t, h.Initialize(nil))
+
+	script := gitlib.ChangeEntry{Name: "run.sh", Hash: testHash("s"), Mode: gitlib.FileModeExecutable}
+	readme := gitlib.ChangeEntry{Name: "README.md", Hash: testHash("r"), Mode: gitlib.FileModeRegular}
+	h.TreeDiff.Changes = gitlib.Changes{
+		{Action: gitlib.Insert, To: script},
+		{Action: gitlib.Modify, From: readme, To: gitlib.ChangeEntry{Name: "README.md", Hash: readme.Hash, Mode: gitlib.FileModeExecutable}},
+	}
+
+	commitHash := gitlib.NewHash("c100000000000000000000000000000000000001")
+	commit := gitlib.NewTestCommit(commitHash, gitlib.Signature{When: time.Now()}, "chmod")
+
+	tc, err := h.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+
+	data, ok := tc.Data.(*CommitData)
+	require.True(t, ok)
+	require.Len(t, data.ModeUpdates, 2)
+	assert.Equal(t, "README.md", data.ModeUpdates[1].Path)
+	assert.True(t, data.ModeUpdates[1].Change.Suspicious)
+	assert.Equal(t, commitHash, data.ModeUpdates[1].Change.Hash)
+	require.Len(t, h.files["run.sh"].ModeChanges, 1)
+
+	// The aggregator keeps mode changes with the file across renames.
+	agg := NewAggregator(analyze.AggregatorOptions{})
+	t.Cleanup(func() { _ = agg.Close() })
+
+	require.NoError(t, agg.Add(tc))
+	require.NoError(t, agg.Add(analyze.TC{
+		CommitHash: testHash("m"),
+		Data: &CommitData{PathActions: []PathAction{
+			{FromPath: "run.sh", ToPath: "bin/run.sh", Action: gitlib.Modify, CommitHash: testHash("m")},
+		}},
+	}))
+
+	ticks, err := agg.FlushAllTicks()
+	require.NoError(t, err)
+
+	files, ok := TicksToReport(context.Background(), ticks, nil)["Files"].(map[string]FileHistory)
+	require.True(t, ok)
+	require.Len(t, files["bin/run.sh"].ModeChanges, 1)
+	assert.Equal(t, plumbing.ModeExecutableAdded, files["bin/run.sh"].ModeChanges[0].Kind)
+}
+
+func TestAnalyzer_Consume_HunkStatsReachFileChurn(t *testing.T) {
+	t.Parallel()
+
+	h := NewAnalyzer()
+	require.NoError(t, h.Initialize(nil))
+
+	entry := gitlib.ChangeEntry{Name: "main.go", Hash: testHash("g")}
+	h.TreeDiff.Changes = gitlib.Changes{{Action: gitlib.Modify, From: entry, To: entry}}
+	h.LineStats.LineStats = map[gitlib.ChangeEntry]pkgplumbing.LineStats{entry: {Added: 3, Removed: 1}}
+	h.LineStats.HunkStats = map[gitlib.ChangeEntry][]pkgplumbing.HunkStats{entry: {
+		{OldStart: 2, OldLines: 1, NewStart: 2, NewLines: 3, Stats: pkgplumbing.LineStats{Added: 2, Changed: 1}},
+		{OldStart: 9, OldLines: 1, NewStart: 11, NewLines: 1, WhitespaceOnly: true},
+	}}
+
+	commit := gitlib.NewTestCommit(testHash("h"), gitlib.Signature{When: time.Now()}, "edit")
+
+	tc, err := h.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	assert.Equal(t, 2, h.files["main.go"].Hunks)
+
+	agg := NewAggregator(analyze.AggregatorOptions{})
+	t.Cleanup(func() { _ = agg.Close() })
+
+	require.NoError(t, agg.Add(tc))
+
+	ticks, err := agg.FlushAllTicks()
+	require.NoError(t, err)
+
+	metrics, err := ComputeAllMetrics(TicksToReport(context.Background(), ticks, nil))
+	require.NoError(t, err)
+	require.Len(t, metrics.FileChurn, 1)
+
+	churn := metrics.FileChurn[0]
+	assert.Equal(t, 2, churn.Hunks)
+	assert.Equal(t, 1, churn.WhitespaceOnlyHunks)
+	assert.InDelta(t, 3.0, churn.MeanHunkSize, floatDelta)
+}
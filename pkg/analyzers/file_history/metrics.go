@@ -1,9 +1,13 @@
 package filehistory
 
 import (
+	"cmp"
+	"slices"
 	"sort"
+	"strconv"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
 	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
@@ -12,6 +16,9 @@ import (
 // ReportData is the parsed input data for file history metrics computation.
 type ReportData struct {
 	Files map[string]FileHistory
+	// SampleFactor is the commit sampling factor of the history, 1 when
+	// every commit was analyzed.
+	SampleFactor float64
 }
 
 // ParseReportData extracts ReportData from an analyzer report.
@@ -21,20 +28,34 @@ func ParseReportData(report analyze.Report) (*ReportData, error) {
 		files = make(map[string]FileHistory)
 	}
 
-	return &ReportData{Files: files}, nil
+	return &ReportData{Files: files, SampleFactor: analyze.SampleFactor(report)}, nil
+}
+
+// commitCount returns the number of commits that changed fh, scaled by the
+// sampling factor to estimate the full history.
+func (d *ReportData) commitCount(fh FileHistory) int {
+	return analyze.ScaleCount(len(fh.Hashes), d.SampleFactor)
 }
 
 // --- Output Data Types ---.
 
 // FileChurnData contains churn statistics for a single file.
+// CanonicalPath and Aliases are set only for files that were renamed; the
+// statistics then cover the file's whole history across its names.
+// The hunk fields are set only when per-hunk line statistics are enabled.
 type FileChurnData struct {
-	Path             string  `json:"path"                yaml:"path"`
-	CommitCount      int     `json:"commit_count"        yaml:"commit_count"`
-	ContributorCount int     `json:"contributor_count"   yaml:"contributor_count"`
-	TotalAdded       int     `json:"total_lines_added"   yaml:"total_lines_added"`
-	TotalRemoved     int     `json:"total_lines_removed" yaml:"total_lines_removed"`
-	TotalChanged     int     `json:"total_lines_changed" yaml:"total_lines_changed"`
-	ChurnScore       float64 `json:"churn_score"         yaml:"churn_score"`
+	Path                string   `json:"path"                            yaml:"path"`
+	CanonicalPath       string   `json:"canonical_path,omitempty"        yaml:"canonical_path,omitempty"`
+	Aliases             []string `json:"aliases,omitempty"               yaml:"aliases,omitempty"`
+	CommitCount         int      `json:"commit_count"                    yaml:"commit_count"`
+	ContributorCount    int      `json:"contributor_count"               yaml:"contributor_count"`
+	TotalAdded          int      `json:"total_lines_added"               yaml:"total_lines_added"`
+	TotalRemoved        int      `json:"total_lines_removed"             yaml:"total_lines_removed"`
+	TotalChanged        int      `json:"total_lines_changed"             yaml:"total_lines_changed"`
+	ChurnScore          float64  `json:"churn_score"                     yaml:"churn_score"`
+	Hunks               int      `json:"hunks,omitempty"                 yaml:"hunks,omitempty"`
+	WhitespaceOnlyHunks int      `json:"whitespace_only_hunks,omitempty" yaml:"whitespace_only_hunks,omitempty"`
+	MeanHunkSize        float64  `json:"mean_hunk_size,omitempty"        yaml:"mean_hunk_size,omitempty"`
 }
 
 // FileContributorData contains contributor statistics for a file.
@@ -53,6 +74,17 @@ type HotspotData struct {
 	RiskLevel   string  `json:"risk_level"   yaml:"risk_level"`
 }
 
+// ModeChangeData is a change of the executable bit of a file or the addition
+// of a symbolic link. Modes are in git's octal notation, empty for new files.
+type ModeChangeData struct {
+	Path       string                  `json:"path"       yaml:"path"`
+	Commit     string                  `json:"commit"     yaml:"commit"`
+	Kind       plumbing.ModeChangeKind `json:"kind"       yaml:"kind"`
+	From       string                  `json:"from"       yaml:"from"`
+	To         string                  `json:"to"         yaml:"to"`
+	Suspicious bool                    `json:"suspicious" yaml:"suspicious"`
+}
+
 // AggregateData contains summary statistics.
 type AggregateData struct {
 	TotalFiles             int     `json:"total_files"               yaml:"total_files"`
@@ -61,6 +93,9 @@ type AggregateData struct {
 	AvgCommitsPerFile      float64 `json:"avg_commits_per_file"      yaml:"avg_commits_per_file"`
 	AvgContributorsPerFile float64 `json:"avg_contributors_per_file" yaml:"avg_contributors_per_file"`
 	HighChurnFiles         int     `json:"high_churn_files"          yaml:"high_churn_files"`
+	RenamedFiles           int     `json:"renamed_files"             yaml:"renamed_files"`
+	ModeChanges            int     `json:"mode_changes"              yaml:"mode_changes"`
+	SuspiciousModeChanges  int     `json:"suspicious_mode_changes"   yaml:"suspicious_mode_changes"`
 }
 
 // Hotspot risk thresholds.
@@ -109,7 +144,9 @@ type ComputedMetrics struct {
 	FileChurn        []FileChurnData       `json:"file_churn"        yaml:"file_churn"`
 	FileContributors []FileContributorData `json:"file_contributors" yaml:"file_contributors"`
 	Hotspots         []HotspotData         `json:"hotspots"          yaml:"hotspots"`
-	Aggregate        AggregateData         `json:"aggregate"         yaml:"aggregate"`
+	// ModeChanges list suspicious changes first, then by path, oldest first.
+	ModeChanges []ModeChangeData `json:"mode_changes" yaml:"mode_changes"`
+	Aggregate   AggregateData    `json:"aggregate"         yaml:"aggregate"`
 }
 
 const analyzerNameFileHistory = "file_history"
@@ -140,6 +177,7 @@ func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
 		FileChurn:        computeFileChurn(input),
 		FileContributors: computeFileContributors(input),
 		Hotspots:         computeHotspots(input),
+		ModeChanges:      computeModeChanges(input),
 		Aggregate:        computeAggregate(input),
 	}, nil
 }
@@ -155,13 +193,13 @@ func computeFileChurn(input *ReportData) []FileChurnData {
 			totalChanged += stats.Changed
 		}
 
-		commitCount := len(fh.Hashes)
+		commitCount := input.commitCount(fh)
 		contributorCount := len(fh.People)
 
 		// Churn score: weighted combination of commits and line changes.
 		churnScore := float64(commitCount) + float64(totalAdded+totalRemoved+totalChanged)/churnScoreDivisor
 
-		result = append(result, FileChurnData{
+		churn := FileChurnData{
 			Path:             path,
 			CommitCount:      commitCount,
 			ContributorCount: contributorCount,
@@ -169,7 +207,20 @@ func computeFileChurn(input *ReportData) []FileChurnData {
 			TotalRemoved:     totalRemoved,
 			TotalChanged:     totalChanged,
 			ChurnScore:       churnScore,
-		})
+		}
+
+		if fh.Hunks > 0 {
+			churn.Hunks = fh.Hunks
+			churn.WhitespaceOnlyHunks = fh.WhitespaceHunks
+			churn.MeanHunkSize = float64(fh.HunkLines) / float64(fh.Hunks)
+		}
+
+		if len(fh.Aliases) > 0 {
+			churn.CanonicalPath = fh.Aliases[0]
+			churn.Aliases = fh.Aliases
+		}
+
+		result = append(result, churn)
 	}
 
 	// Sort by churn score descending.
@@ -209,7 +260,7 @@ func computeHotspots(input *ReportData) []HotspotData {
 	result := make([]HotspotData, 0, len(input.Files))
 
 	for path, fh := range input.Files {
-		commitCount := len(fh.Hashes)
+		commitCount := input.commitCount(fh)
 
 		var totalAdded, totalRemoved, totalChanged int
 		for _, stats := range fh.People {
@@ -253,6 +304,53 @@ func computeHotspots(input *ReportData) []HotspotData {
 	return result
 }
 
+func computeModeChanges(input *ReportData) []ModeChangeData {
+	var result []ModeChangeData
+
+	paths := make([]string, 0, len(input.Files))
+	for path := range input.Files {
+		paths = append(paths, path)
+	}
+
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		for _, mc := range input.Files[path].ModeChanges {
+			result = append(result, ModeChangeData{
+				Path:       path,
+				Commit:     mc.Hash.String(),
+				Kind:       mc.Kind,
+				From:       formatMode(mc.From),
+				To:         formatMode(mc.To),
+				Suspicious: mc.Suspicious,
+			})
+		}
+	}
+
+	// Suspicious changes first; the stable sort keeps the path order.
+	slices.SortStableFunc(result, func(x, y ModeChangeData) int {
+		return cmp.Compare(boolRank(y.Suspicious), boolRank(x.Suspicious))
+	})
+
+	return result
+}
+
+func formatMode(mode uint16) string {
+	if mode == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(uint64(mode), 8)
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
 func computeAggregate(input *ReportData) AggregateData {
 	agg := AggregateData{
 		TotalFiles: len(input.Files),
@@ -267,15 +365,28 @@ func computeAggregate(input *ReportData) AggregateData {
 	var totalCommits, highChurnCount int
 
 	for _, fh := range input.Files {
-		totalCommits += len(fh.Hashes)
+		commitCount := input.commitCount(fh)
+		totalCommits += commitCount
 
 		for devID := range fh.People {
 			allContributors[devID] = true
 		}
 
-		if len(fh.Hashes) >= HotspotThresholdMedium {
+		if commitCount >= HotspotThresholdMedium {
 			highChurnCount++
 		}
+
+		if len(fh.Aliases) > 0 {
+			agg.RenamedFiles++
+		}
+
+		agg.ModeChanges += len(fh.ModeChanges)
+
+		for _, mc := range fh.ModeChanges {
+			if mc.Suspicious {
+				agg.SuspiciousModeChanges++
+			}
+		}
 	}
 
 	agg.TotalCommits = totalCommits
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
@@ -498,3 +499,68 @@ func TestComputeAllMetrics_Full(t *testing.T) {
 	assert.Equal(t, 40, result.Aggregate.TotalCommits)     // 35 + 5
 	assert.Equal(t, 2, result.Aggregate.TotalContributors) // devID1, devID2.
 }
+
+func TestComputeAllMetrics_ScalesSampledCommitCounts(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]FileHistory{
+		testFile1: {
+			People: map[int]pkgplumbing.LineStats{testDevID1: {Added: 100}},
+			Hashes: testHashes(6), // 18 commits estimated: a hotspot.
+		},
+	}
+
+	report := analyze.Report{
+		"Files":                       files,
+		analyze.ReportKeySampleFactor: 3.0,
+	}
+
+	result, err := ComputeAllMetrics(report)
+
+	require.NoError(t, err)
+
+	require.Len(t, result.FileChurn, 1)
+	assert.Equal(t, 18, result.FileChurn[0].CommitCount)
+	assert.Equal(t, 100, result.FileChurn[0].TotalAdded)
+
+	require.Len(t, result.Hotspots, 1)
+	assert.Equal(t, 18, result.Hotspots[0].CommitCount)
+
+	assert.Equal(t, 18, result.Aggregate.TotalCommits)
+}
+
+func TestModeChangesMetric(t *testing.T) {
+	t.Parallel()
+
+	input := &ReportData{
+		Files: map[string]FileHistory{
+			"tools/gen": {ModeChanges: []ModeChange{
+				{Hash: testHash("a"), Kind: plumbing.ModeExecutableAdded, To: gitlib.FileModeExecutable},
+				{
+					Hash: testHash("b"), Kind: plumbing.ModeExecutableRemoved,
+					From: gitlib.FileModeExecutable, To: gitlib.FileModeRegular,
+				},
+			}},
+			"docs/index.html": {ModeChanges: []ModeChange{
+				{
+					Hash: testHash("c"), Kind: plumbing.ModeExecutableAdded,
+					From: gitlib.FileModeRegular, To: gitlib.FileModeExecutable, Suspicious: true,
+				},
+			}},
+			"main.go": {},
+		},
+	}
+
+	modes := computeModeChanges(input)
+	require.Len(t, modes, 3)
+	assert.Equal(t, ModeChangeData{
+		Path: "docs/index.html", Commit: testHash("c").String(), Kind: plumbing.ModeExecutableAdded,
+		From: "100644", To: "100755", Suspicious: true,
+	}, modes[0])
+	assert.Empty(t, modes[1].From)
+	assert.Equal(t, plumbing.ModeExecutableRemoved, modes[2].Kind)
+
+	agg := computeAggregate(input)
+	assert.Equal(t, 3, agg.ModeChanges)
+	assert.Equal(t, 1, agg.SuspiciousModeChanges)
+}
@@ -2,8 +2,10 @@ package filehistory
 
 import (
 	"errors"
+	"html"
 	"io"
 	"sort"
+	"strconv"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/components"
@@ -14,6 +16,7 @@ import (
 )
 
 const (
+	shortHashLen     = 8
 	topFilesLimit    = 20
 	xAxisRotate      = 60
 	emptyChartHeight = "400px"
@@ -44,7 +47,7 @@ func (h *HistoryAnalyzer) GenerateSections(report analyze.Report) ([]plotpage.Se
 		return nil, err
 	}
 
-	return []plotpage.Section{
+	sections := []plotpage.Section{
 		{
 			Title:    "Most Modified Files",
 			Subtitle: "Files ranked by total number of commits touching them.",
@@ -60,7 +63,58 @@ func (h *HistoryAnalyzer) GenerateSections(report analyze.Report) ([]plotpage.Se
 				},
 			},
 		},
-	}, nil
+	}
+
+	if files, ok := report["Files"].(map[string]FileHistory); ok {
+		if modes := computeModeChanges(&ReportData{Files: files}); len(modes) > 0 {
+			sections = append(sections, plotpage.Section{
+				Title:    "Mode Changes",
+				Subtitle: strconv.Itoa(len(modes)) + " executable bit changes and new symbolic links.",
+				Chart:    buildModeChangeTable(modes),
+				Hint: plotpage.Hint{
+					Title: "How to interpret:",
+					Items: []string{
+						"<strong>Suspicious</strong> = executable bit on a data, document or source file",
+						"New symbolic links can point outside the repository; check their targets",
+					},
+				},
+			})
+		}
+	}
+
+	return sections, nil
+}
+
+func buildModeChangeTable(modes []ModeChangeData) *plotpage.Table {
+	table := plotpage.NewTable([]string{"File", "Commit", "Change", "Mode", "Suspicious"}).
+		WithSearch("Filter files...")
+
+	for _, mode := range modes {
+		suspicious := ""
+		if mode.Suspicious {
+			suspicious = "yes"
+		}
+
+		modes := mode.To
+		if mode.From != "" {
+			modes = mode.From + " → " + mode.To
+		}
+
+		commit := mode.Commit
+		if len(commit) > shortHashLen {
+			commit = commit[:shortHashLen]
+		}
+
+		table.AddRow(
+			html.EscapeString(mode.Path),
+			commit,
+			string(mode.Kind),
+			modes,
+			suspicious,
+		)
+	}
+
+	return table
 }
 
 // GenerateChart creates a bar chart showing the most modified files (implements PlotGenerator).
@@ -125,8 +179,10 @@ func extractFileHistoryData(report analyze.Report) (labels []string, data []int,
 
 	// Try in-memory key first.
 	if files, filesOK := report["Files"].(map[string]FileHistory); filesOK {
+		input := &ReportData{Files: files, SampleFactor: analyze.SampleFactor(report)}
+
 		for name, hist := range files {
-			items = append(items, fileChurnItem{name, len(hist.Hashes)})
+			items = append(items, fileChurnItem{name, input.commitCount(hist)})
 		}
 	} else {
 		items, err = extractFileChurnFromBinary(report)
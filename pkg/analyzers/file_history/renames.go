@@ -0,0 +1,117 @@
+package filehistory
+
+import "strconv"
+
+// renameChains gives every file a stable storage key that survives renames, so
+// history accumulated under one name keeps growing after the file moves, even
+// when earlier state has been spilled to disk.
+//
+// A file's key is the first path it was seen under. Only files that were
+// renamed, or whose path was reused after a rename, need map entries; every
+// other file is stored under its own path.
+type renameChains struct {
+	// keyOf maps a current path to its key when the two differ.
+	keyOf map[string]string
+	// moved maps a key to the file's current path when the two differ.
+	// An empty path marks a file that was overwritten by a rename.
+	moved map[string]string
+	// aliases maps a key to the file's previous paths, oldest first.
+	aliases map[string][]string
+}
+
+func newRenameChains() *renameChains {
+	return &renameChains{
+		keyOf:   make(map[string]string),
+		moved:   make(map[string]string),
+		aliases: make(map[string][]string),
+	}
+}
+
+// key returns the storage key for the file currently at path, allocating a
+// fresh key when path is the key of a file that has since moved elsewhere.
+func (rc *renameChains) key(path string) string {
+	if k, ok := rc.keyOf[path]; ok {
+		return k
+	}
+
+	if _, taken := rc.moved[path]; !taken {
+		return path
+	}
+
+	k := path
+	for n := 1; ; n++ {
+		k = path + "#" + strconv.Itoa(n)
+
+		_, taken := rc.moved[k]
+		if !taken {
+			break
+		}
+	}
+
+	rc.keyOf[path] = k
+	rc.moved[k] = path
+
+	return k
+}
+
+// rename moves the file at from to to and returns its key.
+func (rc *renameChains) rename(from, to string) string {
+	k := rc.key(from)
+
+	// A rename onto an existing file replaces it; its history stops being reported.
+	if overwritten := rc.liveKey(to); overwritten != "" && overwritten != k {
+		rc.moved[overwritten] = ""
+		delete(rc.keyOf, to)
+	}
+
+	delete(rc.keyOf, from)
+
+	// Recording the move in moved also stops key() from handing a vacated key
+	// path to a new file.
+	if to == k {
+		delete(rc.moved, k)
+	} else {
+		rc.keyOf[to] = k
+		rc.moved[k] = to
+	}
+
+	rc.aliases[k] = append(rc.aliases[k], from)
+
+	return k
+}
+
+// liveKey returns the key of the file currently at path without allocating one,
+// or "" when path is free.
+func (rc *renameChains) liveKey(path string) string {
+	if k, ok := rc.keyOf[path]; ok {
+		return k
+	}
+
+	if _, moved := rc.moved[path]; moved {
+		return ""
+	}
+
+	return path
+}
+
+// path returns the current path of the file stored under key, and false when
+// the file was overwritten by a rename.
+func (rc *renameChains) path(key string) (string, bool) {
+	p, ok := rc.moved[key]
+	if !ok {
+		return key, true
+	}
+
+	return p, p != ""
+}
+
+// size returns the number of tracked entries, for memory estimation.
+func (rc *renameChains) size() int {
+	n := len(rc.keyOf) + len(rc.moved)
+
+	for _, a := range rc.aliases {
+		n += len(a)
+	}
+
+	return n
+}
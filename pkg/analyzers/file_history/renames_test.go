@@ -0,0 +1,161 @@
+package filehistory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestRenameChains_FollowsChain(t *testing.T) {
+	t.Parallel()
+
+	rc := newRenameChains()
+
+	assert.Equal(t, "a.go", rc.key("a.go"))
+	assert.Equal(t, "a.go", rc.rename("a.go", "b.go"))
+	assert.Equal(t, "a.go", rc.rename("b.go", "pkg/c.go"))
+	assert.Equal(t, "a.go", rc.key("pkg/c.go"))
+
+	path, live := rc.path("a.go")
+	assert.True(t, live)
+	assert.Equal(t, "pkg/c.go", path)
+	assert.Equal(t, []string{"a.go", "b.go"}, rc.aliases["a.go"])
+}
+
+func TestRenameChains_ReusedPathGetsNewKey(t *testing.T) {
+	t.Parallel()
+
+	rc := newRenameChains()
+
+	rc.rename("a.go", "b.go")
+
+	// A new file created at the vacated path must not join the moved file's history.
+	k := rc.key("a.go")
+	assert.NotEqual(t, "a.go", k)
+	assert.Equal(t, k, rc.key("a.go"))
+
+	path, live := rc.path(k)
+	assert.True(t, live)
+	assert.Equal(t, "a.go", path)
+}
+
+func TestRenameChains_RenameBack(t *testing.T) {
+	t.Parallel()
+
+	rc := newRenameChains()
+
+	rc.rename("a.go", "b.go")
+	assert.Equal(t, "a.go", rc.rename("b.go", "a.go"))
+
+	path, live := rc.path("a.go")
+	assert.True(t, live)
+	assert.Equal(t, "a.go", path)
+	assert.Equal(t, "a.go", rc.key("a.go"))
+	assert.Equal(t, []string{"a.go", "b.go"}, rc.aliases["a.go"])
+}
+
+func TestRenameChains_RenameOntoExistingFile(t *testing.T) {
+	t.Parallel()
+
+	rc := newRenameChains()
+
+	rc.rename("old.go", "main.go")
+
+	_, live := rc.path("main.go")
+	assert.False(t, live, "the overwritten file is no longer reported")
+
+	path, live := rc.path("old.go")
+	assert.True(t, live)
+	assert.Equal(t, "main.go", path)
+}
+
+func renameTC(hash string, actions []PathAction, updates []LineStatUpdate) analyze.TC {
+	return analyze.TC{
+		CommitHash: gitlib.NewHash(hash),
+		Data:       &CommitData{PathActions: actions, LineStatUpdates: updates},
+	}
+}
+
+func TestAggregator_HistoryFollowsRenamesAcrossSpills(t *testing.T) {
+	t.Parallel()
+
+	const (
+		hash1 = "1111111111111111111111111111111111111111"
+		hash2 = "2222222222222222222222222222222222222222"
+		hash3 = "3333333333333333333333333333333333333333"
+	)
+
+	agg := NewAggregator(analyze.AggregatorOptions{})
+	t.Cleanup(func() { _ = agg.Close() })
+
+	require.NoError(t, agg.Add(renameTC(hash1,
+		[]PathAction{{Path: "a.go", Action: gitlib.Insert, CommitHash: gitlib.NewHash(hash1)}},
+		[]LineStatUpdate{{Path: "a.go", AuthorID: 0, Stats: pkgplumbing.LineStats{Added: 10}}},
+	)))
+
+	_, err := agg.Spill()
+	require.NoError(t, err)
+
+	require.NoError(t, agg.Add(renameTC(hash2,
+		[]PathAction{{FromPath: "a.go", ToPath: "b.go", Action: gitlib.Modify, CommitHash: gitlib.NewHash(hash2)}},
+		[]LineStatUpdate{{Path: "b.go", AuthorID: 1, Stats: pkgplumbing.LineStats{Added: 5}}},
+	)))
+
+	require.NoError(t, agg.Add(renameTC(hash3,
+		[]PathAction{{Path: "a.go", Action: gitlib.Insert, CommitHash: gitlib.NewHash(hash3)}},
+		nil,
+	)))
+
+	require.NoError(t, agg.Collect())
+
+	ticks, err := agg.FlushAllTicks()
+	require.NoError(t, err)
+
+	report := TicksToReport(context.Background(), ticks, nil)
+	files, ok := report["Files"].(map[string]FileHistory)
+	require.True(t, ok)
+	require.Len(t, files, 2)
+
+	moved := files["b.go"]
+	assert.Len(t, moved.Hashes, 2)
+	assert.Equal(t, 10, moved.People[0].Added)
+	assert.Equal(t, 5, moved.People[1].Added)
+	assert.Equal(t, []string{"a.go"}, moved.Aliases)
+
+	recreated := files["a.go"]
+	assert.Len(t, recreated.Hashes, 1)
+	assert.Empty(t, recreated.Aliases)
+}
+
+func TestComputeAllMetrics_RenamedFile(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		"Files": map[string]FileHistory{
+			"pkg/c.go": {
+				Hashes:  []gitlib.Hash{gitlib.NewHash("1111111111111111111111111111111111111111")},
+				Aliases: []string{"a.go", "b.go"},
+			},
+			"d.go": {},
+		},
+	}
+
+	metrics, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+	assert.Equal(t, 1, metrics.Aggregate.RenamedFiles)
+
+	for _, fc := range metrics.FileChurn {
+		if fc.Path == "pkg/c.go" {
+			assert.Equal(t, "a.go", fc.CanonicalPath)
+			assert.Equal(t, []string{"a.go", "b.go"}, fc.Aliases)
+		} else {
+			assert.Empty(t, fc.CanonicalPath)
+		}
+	}
+}
@@ -15,15 +15,25 @@ type PathAction struct {
 }
 
 // LineStatUpdate represents line stat delta for one file/author in a commit.
+// Hunks is set only when per-hunk line statistics are enabled.
 type LineStatUpdate struct {
 	Path     string
 	AuthorID int
 	Stats    plumbing.LineStats
+	Hunks    []plumbing.HunkStats
+}
+
+// ModeUpdate represents a mode change of one file in a commit.
+type ModeUpdate struct {
+	Path   string
+	Change ModeChange
 }
 
 // CommitData is the per-commit TC payload emitted by Consume().
-// It captures path actions (insert/modify/delete/rename) and line stat deltas.
+// It captures path actions (insert/modify/delete/rename), mode changes and
+// line stat deltas.
 type CommitData struct {
 	PathActions     []PathAction
+	ModeUpdates     []ModeUpdate
 	LineStatUpdates []LineStatUpdate
 }
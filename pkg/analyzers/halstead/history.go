@@ -0,0 +1,322 @@
+package halstead
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// Report keys of the history analyzer.
+const (
+	KeyCommits     = "commits"
+	KeyAuthorIndex = "author_index"
+	KeyTickSize    = "tick_size"
+
+	// commitSize and fileDeltaSize estimate the bytes of one CommitHalstead
+	// and one FileDelta held by the aggregator.
+	commitSize    = 160
+	fileDeltaSize = 96
+)
+
+// Delta is the change of the Halstead measures of some code.
+type Delta struct {
+	Volume        float64 `json:"volume"         yaml:"volume"`
+	Difficulty    float64 `json:"difficulty"     yaml:"difficulty"`
+	Effort        float64 `json:"effort"         yaml:"effort"`
+	DeliveredBugs float64 `json:"delivered_bugs" yaml:"delivered_bugs"`
+}
+
+func (d *Delta) add(other Delta) {
+	d.Volume += other.Volume
+	d.Difficulty += other.Difficulty
+	d.Effort += other.Effort
+	d.DeliveredBugs += other.DeliveredBugs
+}
+
+// FileDelta is the Delta of one file in one commit.
+type FileDelta struct {
+	File  string `json:"file" yaml:"file"`
+	Delta `yaml:",inline"`
+}
+
+// CommitHalstead is the Delta of one commit: the sum of the deltas of the
+// files it changed.
+type CommitHalstead struct {
+	Hash     gitlib.Hash
+	Tick     int
+	AuthorID int
+	Time     time.Time
+	Delta
+	Files []FileDelta
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	Commits []CommitHalstead
+}
+
+// HistoryAnalyzer measures the Halstead volume, difficulty and effort of
+// the files each commit changes, before and after the commit, and attributes
+// the differences to the commit and its author.
+type HistoryAnalyzer struct {
+	*analyze.BaseHistoryAnalyzer[*HistoryMetrics]
+
+	UAST  *plumbing.UASTChangesAnalyzer
+	Ticks *plumbing.TicksSinceStart
+
+	analyzer           *Analyzer
+	reversedPeopleDict []string
+	tickSize           time.Duration
+}
+
+// NewHistoryAnalyzer creates a new HistoryAnalyzer.
+func NewHistoryAnalyzer() *HistoryAnalyzer {
+	a := &HistoryAnalyzer{analyzer: NewAnalyzer()}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*HistoryMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/halstead",
+			Mode: analyze.ModeHistory,
+			Description: "Attributes Halstead volume, difficulty and effort deltas to commits and " +
+				"authors to find the changes that add the most cognitive load.",
+		},
+		Sequential:   false,
+		CPUHeavyFlag: true,
+		Caps:         &analyze.Capabilities{NeedsUAST: true, Memory: analyze.MemoryLow},
+		ComputeMetricsFn: func(report analyze.Report) (*HistoryMetrics, error) {
+			return ComputeHistoryMetrics(report), nil
+		},
+		AggregatorFn: newHistoryAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (h *HistoryAnalyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		h.reversedPeopleDict = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		h.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize prepares the analyzer for processing commits.
+func (h *HistoryAnalyzer) Initialize(_ *gitlib.Repository) error {
+	if h.analyzer == nil {
+		h.analyzer = NewAnalyzer()
+	}
+
+	return nil
+}
+
+// Consume measures every changed file before and after the commit and emits
+// the deltas of the files whose measures changed.
+func (h *HistoryAnalyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
+	commit := &CommitHalstead{}
+
+	for _, change := range h.UAST.Changes(ctx) {
+		file := change.Change.To.Name
+		if change.After == nil {
+			file = change.Change.From.Name
+		}
+
+		before, after := h.analyzer.fileMetrics(change.Before), h.analyzer.fileMetrics(change.After)
+
+		delta := Delta{
+			Volume:        after.Volume - before.Volume,
+			Difficulty:    after.Difficulty - before.Difficulty,
+			Effort:        after.Effort - before.Effort,
+			DeliveredBugs: after.DeliveredBugs - before.DeliveredBugs,
+		}
+		if delta == (Delta{}) {
+			continue
+		}
+
+		commit.add(delta)
+		commit.Files = append(commit.Files, FileDelta{File: file, Delta: delta})
+	}
+
+	if len(commit.Files) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	tc := analyze.TC{Data: commit}
+
+	if ac != nil && ac.Commit != nil {
+		tc.CommitHash = ac.Commit.Hash()
+	}
+
+	return tc, nil
+}
+
+// fileMetrics returns the file-level measures of root, which are zero for a
+// missing file or a file without functions.
+func (h *Analyzer) fileMetrics(root *node.Node) *Metrics {
+	if root == nil {
+		return &Metrics{}
+	}
+
+	functions := h.findFunctions(root)
+	if len(functions) == 0 {
+		return &Metrics{}
+	}
+
+	return h.calculateFileLevelMetrics(h.calculateAllFunctionMetrics(functions))
+}
+
+// Fork creates independent copies of the analyzer for parallel processing.
+func (h *HistoryAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		res[i] = &HistoryAnalyzer{
+			BaseHistoryAnalyzer: h.BaseHistoryAnalyzer,
+			UAST:                &plumbing.UASTChangesAnalyzer{},
+			Ticks:               &plumbing.TicksSinceStart{},
+			analyzer:            NewAnalyzer(),
+			reversedPeopleDict:  h.reversedPeopleDict,
+			tickSize:            h.tickSize,
+		}
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (h *HistoryAnalyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (h *HistoryAnalyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		UASTChanges: h.UAST.TransferChanges(),
+		Tick:        h.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (h *HistoryAnalyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	h.UAST.SetChanges(snapshot.UASTChanges)
+	h.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot releases UAST trees owned by the snapshot.
+func (h *HistoryAnalyzer) ReleaseSnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	plumbing.ReleaseSnapshotUAST(snapshot)
+}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (h *HistoryAnalyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return h.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (h *HistoryAnalyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return h.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the commits in history order.
+func (h *HistoryAnalyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	commits := analyze.CollectTickCommits(ticks, tickCommits, commitTime)
+
+	return analyze.Report{
+		KeyCommits:     commits,
+		KeyAuthorIndex: h.reversedPeopleDict,
+		KeyTickSize:    h.tickSize,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func tickCommits(td *TickData) []CommitHalstead { return td.Commits }
+
+func commitTime(c CommitHalstead) time.Time { return c.Time }
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	ch, ok := tc.Data.(*CommitHalstead)
+	if !ok || ch == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{}
+		byTick[tc.Tick] = state
+	}
+
+	commit := *ch
+	commit.Hash = tc.CommitHash
+	commit.Tick = tc.Tick
+	commit.AuthorID = tc.AuthorID
+	commit.Time = tc.Timestamp
+
+	state.Commits = append(state.Commits, commit)
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming != nil {
+		existing.Commits = append(existing.Commits, incoming.Commits...)
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	var files int
+
+	for _, ch := range state.Commits {
+		files += len(ch.Files)
+	}
+
+	return int64(len(state.Commits))*commitSize + int64(files)*fileDeltaSize
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || len(state.Commits) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newHistoryAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
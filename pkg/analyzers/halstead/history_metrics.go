@@ -0,0 +1,146 @@
+package halstead
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+// topCommitsLimit caps the commits listed as adding and as removing the most
+// effort.
+const topCommitsLimit = 20
+
+// CommitImpact is the Delta of one commit with its author.
+type CommitImpact struct {
+	Hash   string    `json:"hash"   yaml:"hash"`
+	Author string    `json:"author" yaml:"author"`
+	Tick   int       `json:"tick"   yaml:"tick"`
+	Time   time.Time `json:"time"   yaml:"time"`
+	Delta  `yaml:",inline"`
+	// Files lists the changed files, most added effort first.
+	Files []FileDelta `json:"files" yaml:"files"`
+}
+
+// AuthorHalstead sums the deltas of the commits of one author. Added counts
+// only the commits that increased effort, so refactorings elsewhere do not
+// hide the load an author added.
+type AuthorHalstead struct {
+	Author  string `json:"author"  yaml:"author"`
+	Commits int    `json:"commits" yaml:"commits"`
+	Added   Delta  `json:"added"   yaml:"added"`
+	Net     Delta  `json:"net"     yaml:"net"`
+}
+
+// TickHalstead is the net Delta of the commits of one tick.
+type TickHalstead struct {
+	Tick    int `json:"tick"    yaml:"tick"`
+	Commits int `json:"commits" yaml:"commits"`
+	Delta   `yaml:",inline"`
+}
+
+// HistoryMetrics attributes the Halstead deltas of the analyzed commits to
+// commits, authors and ticks.
+type HistoryMetrics struct {
+	Total Delta          `json:"total" yaml:"total"`
+	Trend []TickHalstead `json:"trend" yaml:"trend"`
+	// Authors are sorted by added effort, most first.
+	Authors []AuthorHalstead `json:"authors" yaml:"authors"`
+	// TopAdded and TopRemoved list the commits that added and removed the
+	// most effort.
+	TopAdded   []CommitImpact `json:"top_added"   yaml:"top_added"`
+	TopRemoved []CommitImpact `json:"top_removed" yaml:"top_removed"`
+}
+
+// ComputeHistoryMetrics attributes the commits of a history report.
+func ComputeHistoryMetrics(report analyze.Report) *HistoryMetrics {
+	commits, _ := report[KeyCommits].([]CommitHalstead)
+	names, _ := report[KeyAuthorIndex].([]string)
+
+	m := &HistoryMetrics{}
+	authors := make(map[string]*AuthorHalstead)
+
+	var added, removed []CommitImpact
+
+	for _, ch := range commits {
+		impact := newCommitImpact(ch, authorName(names, ch.AuthorID))
+
+		m.Total.add(ch.Delta)
+		m.Trend = appendTrend(m.Trend, ch)
+
+		author := authors[impact.Author]
+		if author == nil {
+			author = &AuthorHalstead{Author: impact.Author}
+			authors[impact.Author] = author
+		}
+
+		author.Commits++
+		author.Net.add(ch.Delta)
+
+		switch {
+		case ch.Effort > 0:
+			author.Added.add(ch.Delta)
+
+			added = append(added, impact)
+		case ch.Effort < 0:
+			removed = append(removed, impact)
+		}
+	}
+
+	m.Authors = sortedAuthors(authors)
+	m.TopAdded = topCommits(added, func(x, y CommitImpact) int { return cmp.Compare(y.Effort, x.Effort) })
+	m.TopRemoved = topCommits(removed, func(x, y CommitImpact) int { return cmp.Compare(x.Effort, y.Effort) })
+
+	return m
+}
+
+func newCommitImpact(ch CommitHalstead, author string) CommitImpact {
+	files := slices.Clone(ch.Files)
+	slices.SortStableFunc(files, func(x, y FileDelta) int {
+		return cmp.Compare(y.Effort, x.Effort)
+	})
+
+	return CommitImpact{Hash: ch.Hash.String(), Author: author, Tick: ch.Tick, Time: ch.Time, Delta: ch.Delta, Files: files}
+}
+
+// appendTrend adds ch to the last point of trend when it is of the same tick.
+func appendTrend(trend []TickHalstead, ch CommitHalstead) []TickHalstead {
+	if n := len(trend); n == 0 || trend[n-1].Tick != ch.Tick {
+		trend = append(trend, TickHalstead{Tick: ch.Tick})
+	}
+
+	point := &trend[len(trend)-1]
+	point.Commits++
+	point.add(ch.Delta)
+
+	return trend
+}
+
+func sortedAuthors(authors map[string]*AuthorHalstead) []AuthorHalstead {
+	result := make([]AuthorHalstead, 0, len(authors))
+	for _, author := range authors {
+		result = append(result, *author)
+	}
+
+	slices.SortFunc(result, func(x, y AuthorHalstead) int {
+		return cmp.Or(cmp.Compare(y.Added.Effort, x.Added.Effort), cmp.Compare(x.Author, y.Author))
+	})
+
+	return result
+}
+
+func topCommits(commits []CommitImpact, compare func(x, y CommitImpact) int) []CommitImpact {
+	slices.SortStableFunc(commits, compare)
+
+	return commits[:min(len(commits), topCommitsLimit)]
+}
+
+func authorName(names []string, id int) string {
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return identity.AuthorMissingName
+}
@@ -0,0 +1,118 @@
+package halstead
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+func TestAnalyzer_FileMetrics(t *testing.T) {
+	t.Parallel()
+
+	analyzer := NewAnalyzer()
+
+	assert.Equal(t, &Metrics{}, analyzer.fileMetrics(nil))
+	assert.Equal(t, &Metrics{}, analyzer.fileMetrics(node.New("file", "File", "", nil, nil, nil)))
+
+	fn := node.New("func1", "Function", "", []node.Role{node.RoleFunction, node.RoleDeclaration},
+		nil, map[string]string{"name": "set"})
+	assignment := node.New("assign1", "Assignment", "=", []node.Role{node.RoleAssignment}, nil, map[string]string{"operator": "="})
+	assignment.AddChild(node.New("id1", "Identifier", "x", []node.Role{node.RoleVariable}, nil, map[string]string{"name": "x"}))
+	assignment.AddChild(node.New("lit1", "Literal", "5", []node.Role{node.RoleLiteral}, nil, map[string]string{"value": "5"}))
+	fn.AddChild(assignment)
+
+	metrics := analyzer.fileMetrics(fn)
+	assert.Positive(t, metrics.Volume)
+	assert.Positive(t, metrics.Effort)
+}
+
+func TestHistoryAnalyzer_ReportAndMetrics(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hash := func(c string) gitlib.Hash {
+		return gitlib.NewHash(c + "000000000000000000000000000000000000000")
+	}
+
+	byTick := make(map[int]*TickData)
+
+	for _, tc := range []analyze.TC{
+		// The later commit of tick 0 arrives first.
+		{
+			Tick: 0, AuthorID: 1, CommitHash: hash("b"), Timestamp: start.Add(time.Hour),
+			Data: &CommitHalstead{Delta: Delta{Volume: -20, Effort: -300}, Files: []FileDelta{
+				{File: "a.go", Delta: Delta{Volume: -20, Effort: -300}},
+			}},
+		},
+		{
+			Tick: 0, AuthorID: 0, CommitHash: hash("a"), Timestamp: start,
+			Data: &CommitHalstead{Delta: Delta{Volume: 50, Difficulty: 2, Effort: 900}, Files: []FileDelta{
+				{File: "a.go", Delta: Delta{Volume: 10, Effort: 100}},
+				{File: "b.go", Delta: Delta{Volume: 40, Difficulty: 2, Effort: 800}},
+			}},
+		},
+		{
+			Tick: 2, AuthorID: 1, CommitHash: hash("c"), Timestamp: start.Add(48 * time.Hour),
+			Data: &CommitHalstead{Delta: Delta{Volume: 30, Effort: 400}, Files: []FileDelta{
+				{File: "c.go", Delta: Delta{Volume: 30, Effort: 400}},
+			}},
+		},
+	} {
+		require.NoError(t, extractTC(tc, byTick))
+	}
+
+	ticks := make([]analyze.TICK, 0, len(byTick))
+	for _, tick := range []int{0, 2} {
+		built, err := buildTick(tick, byTick[tick])
+		require.NoError(t, err)
+
+		ticks = append(ticks, built)
+	}
+
+	h := NewHistoryAnalyzer()
+	require.NoError(t, h.Configure(map[string]any{identity.FactIdentityDetectorReversedPeopleDict: []string{"alice", "bob"}}))
+
+	report, err := h.ReportFromTICKs(context.Background(), ticks)
+	require.NoError(t, err)
+
+	commits, ok := report[KeyCommits].([]CommitHalstead)
+	require.True(t, ok)
+	require.Len(t, commits, 3)
+	assert.Equal(t, hash("a"), commits[0].Hash)
+	assert.Equal(t, hash("b"), commits[1].Hash)
+
+	m := ComputeHistoryMetrics(report)
+	assert.Equal(t, Delta{Volume: 60, Difficulty: 2, Effort: 1000}, m.Total)
+	assert.Equal(t, []TickHalstead{
+		{Tick: 0, Commits: 2, Delta: Delta{Volume: 30, Difficulty: 2, Effort: 600}},
+		{Tick: 2, Commits: 1, Delta: Delta{Volume: 30, Effort: 400}},
+	}, m.Trend)
+
+	require.Len(t, m.Authors, 2)
+	assert.Equal(t, AuthorHalstead{
+		Author: "alice", Commits: 1,
+		Added: Delta{Volume: 50, Difficulty: 2, Effort: 900}, Net: Delta{Volume: 50, Difficulty: 2, Effort: 900},
+	}, m.Authors[0])
+	assert.Equal(t, AuthorHalstead{
+		Author: "bob", Commits: 2,
+		Added: Delta{Volume: 30, Effort: 400}, Net: Delta{Volume: 10, Effort: 100},
+	}, m.Authors[1])
+
+	require.Len(t, m.TopAdded, 2)
+	assert.Equal(t, hash("a").String(), m.TopAdded[0].Hash)
+	assert.Equal(t, "b.go", m.TopAdded[0].Files[0].File)
+	require.Len(t, m.TopRemoved, 1)
+	assert.Equal(t, "bob", m.TopRemoved[0].Author)
+
+	sections, err := h.GenerateSections(report)
+	require.NoError(t, err)
+	assert.Len(t, sections, 3)
+}
@@ -2,8 +2,10 @@ package halstead
 
 import (
 	"errors"
+	"html"
 	"io"
 	"sort"
+	"strconv"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
@@ -28,6 +30,7 @@ const (
 	difficultyLow     = 5
 	difficultyMedium  = 15
 	difficultyHigh    = 30
+	shortHashLen      = 8
 )
 
 // ErrInvalidFunctionsData indicates the report doesn't contain expected functions data.
@@ -38,6 +41,9 @@ func RegisterPlotSections() {
 	analyze.RegisterPlotSections("static/halstead", func(report analyze.Report) ([]plotpage.Section, error) {
 		return (&Analyzer{}).generateSections(report)
 	})
+	analyze.RegisterPlotSections("history/halstead", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&HistoryAnalyzer{}).GenerateSections(report)
+	})
 }
 
 // FormatReportPlot generates an HTML plot visualization for Halstead analysis.
@@ -475,3 +481,94 @@ func createEmptyHalsteadPie() *charts.Pie {
 
 	return pie
 }
+
+// GenerateSections returns the sections for combined reports.
+func (h *HistoryAnalyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeHistoryMetrics(report)
+
+	commits := plotpage.NewTable([]string{"Commit", "Author", "Tick", "Effort", "Volume", "Difficulty", "Top file"}).
+		WithSearch("Filter commits...")
+
+	for _, impact := range m.TopAdded {
+		topFile := ""
+		if len(impact.Files) > 0 {
+			topFile = impact.Files[0].File
+		}
+
+		commits.AddRow(
+			shortHash(impact.Hash),
+			html.EscapeString(impact.Author),
+			strconv.Itoa(impact.Tick),
+			formatSigned(impact.Effort),
+			formatSigned(impact.Volume),
+			formatSigned(impact.Difficulty),
+			html.EscapeString(topFile),
+		)
+	}
+
+	authors := plotpage.NewTable([]string{"Author", "Commits", "Added effort", "Net effort", "Net volume"})
+
+	for _, author := range m.Authors {
+		authors.AddRow(
+			html.EscapeString(author.Author),
+			strconv.Itoa(author.Commits),
+			formatSigned(author.Added.Effort),
+			formatSigned(author.Net.Effort),
+			formatSigned(author.Net.Volume),
+		)
+	}
+
+	return []plotpage.Section{
+		{
+			Title:    "Effort Trend",
+			Subtitle: "Net Halstead effort the commits of each tick added or removed.",
+			Chart:    plotpage.WrapChart(buildEffortTrendChart(m.Trend)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"<strong>Bars above zero</strong> = the code got harder to read in that tick",
+					"<strong>Bars below zero</strong> = simplifications and deletions outweighed additions",
+				},
+			},
+		},
+		{
+			Title:    "Commits Adding the Most Effort",
+			Subtitle: "Net effort " + formatSigned(m.Total.Effort) + " over the analyzed history.",
+			Chart:    commits,
+		},
+		{
+			Title:    "Effort by Author",
+			Subtitle: "Added effort sums only the commits that increased effort.",
+			Chart:    authors,
+		},
+	}, nil
+}
+
+func buildEffortTrendChart(trend []TickHalstead) *charts.Bar {
+	labels := make([]string, len(trend))
+	effort := make([]plotpage.SeriesData, len(trend))
+
+	for i, point := range trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		effort[i] = point.Effort
+	}
+
+	return plotpage.BuildBarChart(nil, labels, []plotpage.BarSeries{{Name: "Net effort", Data: effort}}, "Effort")
+}
+
+// formatSigned formats a delta with its sign.
+func formatSigned(value float64) string {
+	if value > 0 {
+		return "+" + strconv.FormatFloat(value, 'f', 1, 64)
+	}
+
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
+
+func shortHash(hash string) string {
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+
+	return hash
+}
@@ -2,20 +2,58 @@
 package imports
 
 import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
 )
 
 // Aggregator aggregates import analysis results across multiple files.
 type Aggregator struct {
 	allImports map[string]int // Import path -> count.
 	totalFiles int
+
+	// Package dependency graph inputs, set up by SetRoot.
+	root        string
+	modulePath  string
+	arch        *importmodel.Architecture
+	fileImports map[string][]string // Slash-separated path relative to root -> imports.
 }
 
 // NewAggregator creates a new Aggregator.
 func NewAggregator() *Aggregator {
 	return &Aggregator{
-		allImports: make(map[string]int),
+		allImports:  make(map[string]int),
+		fileImports: make(map[string][]string),
+	}
+}
+
+// SetRoot enables the package dependency graph for the tree at root. The
+// go.mod module path resolves Go imports, and an arch.yaml declares the
+// layers that dependencies are checked against.
+func (a *Aggregator) SetRoot(root string) error {
+	info, err := os.Stat(root)
+	if err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+
+	a.root = root
+	a.modulePath = importmodel.ReadModulePath(filepath.Join(root, "go.mod"))
+
+	arch, err := importmodel.LoadArchitecture(filepath.Join(root, importmodel.ArchitectureFile))
+
+	switch {
+	case err == nil:
+		a.arch = arch
+	case errors.Is(err, fs.ErrNotExist):
+	default:
+		return err
 	}
+
+	return nil
 }
 
 // Aggregate combines results from multiple files.
@@ -28,7 +66,26 @@ func (a *Aggregator) Aggregate(results map[string]analyze.Report) {
 				a.allImports[imp]++
 			}
 		}
+
+		if sites, ok := report[KeyFileImports].([]map[string]any); ok && a.root != "" {
+			for _, site := range sites {
+				a.addFile(site)
+			}
+		}
+	}
+}
+
+// addFile records the imports of one "_source_file" stamped site.
+func (a *Aggregator) addFile(site map[string]any) {
+	file, _ := site["_source_file"].(string)
+	imports, _ := site[KeyImports].([]string)
+
+	rel, err := filepath.Rel(a.root, file)
+	if file == "" || err != nil {
+		return
 	}
+
+	a.fileImports[filepath.ToSlash(rel)] = imports
 }
 
 // GetResult returns the aggregated result.
@@ -38,10 +95,24 @@ func (a *Aggregator) GetResult() analyze.Report {
 		imports = append(imports, imp)
 	}
 
-	return analyze.Report{
+	result := analyze.Report{
 		"imports":       imports,
 		"import_counts": a.allImports,
 		"count":         len(a.allImports),
 		"total_files":   a.totalFiles,
 	}
+
+	if len(a.fileImports) > 0 {
+		graph := importmodel.BuildGraph(a.fileImports, a.modulePath)
+
+		result[KeyPackages] = graph.Packages()
+		result[KeyPackageDependencies] = graph.Edges()
+		result[KeyImportCycles] = graph.Cycles()
+
+		if a.arch != nil {
+			result[KeyLayerViolations] = a.arch.LayerViolations(graph)
+		}
+	}
+
+	return result
 }
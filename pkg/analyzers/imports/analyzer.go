@@ -78,11 +78,12 @@ func (a *Analyzer) CreateAggregator() analyze.ResultAggregator {
 
 // Analyze runs the analysis on the given AST root node.
 func (a *Analyzer) Analyze(root *node.Node) (analyze.Report, error) {
-	imports := extractImportsFromUAST(root)
+	imports := ExtractImports(root)
 
 	return analyze.Report{
-		"imports": imports,
-		"count":   len(imports),
+		"imports":      imports,
+		"count":        len(imports),
+		KeyFileImports: []map[string]any{{KeyImports: imports}},
 	}, nil
 }
 
@@ -155,8 +156,9 @@ func (a *Analyzer) FormatReportBinary(report analyze.Report, w io.Writer) error
 	return nil
 }
 
-// extractImportsFromUAST extracts import strings from a UAST node tree.
-func extractImportsFromUAST(root *node.Node) []string {
+// ExtractImports returns the deduplicated import paths of a UAST node tree,
+// in source order.
+func ExtractImports(root *node.Node) []string {
 	var imports []string
 
 	seen := make(map[string]bool)
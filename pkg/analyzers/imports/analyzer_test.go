@@ -90,7 +90,7 @@ func TestExtractImportsFromUAST(t *testing.T) {
 	// 1. Python "import os".
 	root1 := &node.Node{Type: node.UASTImport, Token: "import os"}
 
-	imps1 := extractImportsFromUAST(root1)
+	imps1 := ExtractImports(root1)
 	if len(imps1) != 1 || imps1[0] != "os" {
 		t.Errorf("Python import failed: %v", imps1)
 	}
@@ -98,7 +98,7 @@ func TestExtractImportsFromUAST(t *testing.T) {
 	// 2. Python "from x import y".
 	root2 := &node.Node{Type: node.UASTImport, Token: "from x import y"}
 
-	imps2 := extractImportsFromUAST(root2)
+	imps2 := ExtractImports(root2)
 	if len(imps2) != 1 || imps2[0] != "x" {
 		t.Errorf("Python from import failed: %v", imps2)
 	}
@@ -107,7 +107,7 @@ func TestExtractImportsFromUAST(t *testing.T) {
 	// Actually parser output depends on language.
 	// But `extractImportPath` handles strings.
 	root3 := &node.Node{Type: node.UASTImport, Token: "import React from 'react'"}
-	imps3 := extractImportsFromUAST(root3)
+	imps3 := ExtractImports(root3)
 	// CleanImportPath splits " from " -> 'react' -> react.
 	if len(imps3) != 1 || imps3[0] != "react" {
 		t.Errorf("JS import failed: %v", imps3)
@@ -116,7 +116,7 @@ func TestExtractImportsFromUAST(t *testing.T) {
 	// 4. JS "import './styles.css'".
 	root4 := &node.Node{Type: node.UASTImport, Token: "import './styles.css'"}
 
-	imps4 := extractImportsFromUAST(root4)
+	imps4 := ExtractImports(root4)
 	if len(imps4) != 1 || imps4[0] != "./styles.css" {
 		t.Errorf("JS side-effect import failed: %v", imps4)
 	}
@@ -133,7 +133,7 @@ func TestExtractImportsFromUAST(t *testing.T) {
 		if len(Children) > 0 { ... }
 	*/
 	// If Token empty, checks children.
-	imps5 := extractImportsFromUAST(root5)
+	imps5 := ExtractImports(root5)
 	if len(imps5) != 1 || imps5[0] != "module" {
 		t.Errorf("Child import failed: %v", imps5)
 	}
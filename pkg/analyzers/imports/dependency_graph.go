@@ -0,0 +1,60 @@
+package imports
+
+import (
+	"strconv"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/graphexport"
+)
+
+// Report keys of the package dependency graph.
+const (
+	// KeyFileImports is the per-file collection that the static service stamps
+	// with "_source_file", so the aggregator knows which package imports what.
+	KeyFileImports         = "file_imports"
+	KeyPackages            = "packages"
+	KeyPackageDependencies = "package_dependencies"
+	KeyImportCycles        = "import_cycles"
+	KeyLayerViolations     = "layer_violations"
+)
+
+// dependencyGraphID names the package dependency graph in graph exports.
+const dependencyGraphID = "packages"
+
+// GenerateGraphs returns the package dependency graph of a static report:
+// one node per package weighted by the imports it makes, and one directed
+// edge per dependency weighted by the imports behind it.
+func (a *Analyzer) GenerateGraphs(report analyze.Report) ([]graphexport.Graph, error) {
+	data, err := ParseReportData(report)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := graphexport.Graph{
+		ID:       dependencyGraphID,
+		Label:    "Package dependencies",
+		Directed: true,
+	}
+
+	outgoing := make(map[string]int, len(data.Packages))
+	for _, edge := range data.PackageDependencies {
+		outgoing[edge.From] += edge.Imports
+	}
+
+	ids := make(map[string]string, len(data.Packages))
+
+	for i, pkg := range data.Packages {
+		ids[pkg] = strconv.Itoa(i)
+		graph.Nodes = append(graph.Nodes, graphexport.Node{ID: ids[pkg], Label: pkg, Weight: float64(outgoing[pkg])})
+	}
+
+	for _, edge := range data.PackageDependencies {
+		graph.Edges = append(graph.Edges, graphexport.Edge{
+			Source: ids[edge.From],
+			Target: ids[edge.To],
+			Weight: float64(edge.Imports),
+		})
+	}
+
+	return []graphexport.Graph{graph}, nil
+}
@@ -0,0 +1,118 @@
+package imports
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/graphexport"
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+// fileReport returns the per-file report of path as the static service
+// passes it to the aggregator.
+func fileReport(path string, imports ...string) map[string]analyze.Report {
+	return map[string]analyze.Report{"imports": {
+		"imports":      imports,
+		KeyFileImports: []map[string]any{{KeyImports: imports, "_source_file": path}},
+	}}
+}
+
+func TestAggregator_DependencyGraph(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/mod\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, importmodel.ArchitectureFile), []byte(`
+layers:
+  - name: app
+    packages: ["cmd/**"]
+  - name: core
+    packages: ["pkg/**"]
+`), 0o600))
+
+	agg := NewAggregator()
+	require.NoError(t, agg.SetRoot(root))
+
+	agg.Aggregate(fileReport(filepath.Join(root, "cmd", "main.go"), "example.com/mod/pkg/a", "fmt"))
+	agg.Aggregate(fileReport(filepath.Join(root, "pkg", "a", "a.go"), "example.com/mod/pkg/b"))
+	agg.Aggregate(fileReport(filepath.Join(root, "pkg", "b", "b.go"), "example.com/mod/pkg/a", "example.com/mod/cmd"))
+
+	result := agg.GetResult()
+
+	assert.Equal(t, []string{"cmd", "pkg/a", "pkg/b"}, result[KeyPackages])
+	assert.Equal(t, []importmodel.Edge{
+		{From: "cmd", To: "pkg/a", Imports: 1},
+		{From: "pkg/a", To: "pkg/b", Imports: 1},
+		{From: "pkg/b", To: "cmd", Imports: 1},
+		{From: "pkg/b", To: "pkg/a", Imports: 1},
+	}, result[KeyPackageDependencies])
+	assert.Equal(t, [][]string{{"cmd", "pkg/a", "pkg/b"}}, result[KeyImportCycles])
+	assert.Equal(t, []importmodel.LayerViolation{
+		{From: "pkg/b", To: "cmd", FromLayer: "core", ToLayer: "app"},
+	}, result[KeyLayerViolations])
+
+	section := NewReportSection(result)
+	issues := section.AllIssues()
+	require.GreaterOrEqual(t, len(issues), 2)
+	assert.Equal(t, IssueImportCycle, issues[0].Name)
+	assert.Equal(t, "cmd, pkg/a, pkg/b", issues[0].Location)
+	assert.Equal(t, IssueLayerViolation, issues[1].Name)
+	assert.Equal(t, "pkg/b -> cmd", issues[1].Location)
+	assert.Equal(t, analyze.SeverityPoor, issues[1].Severity)
+
+	computed, err := ComputeAllMetrics(result)
+	require.NoError(t, err)
+	require.NotNil(t, computed.DependencyGraph)
+	assert.Len(t, computed.DependencyGraph.Dependencies, 4)
+}
+
+func TestAggregator_InvalidArchitecture(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, importmodel.ArchitectureFile), []byte("layers: [{}]"), 0o600))
+
+	err := NewAggregator().SetRoot(root)
+	require.ErrorIs(t, err, importmodel.ErrInvalidArchitecture)
+}
+
+func TestAggregator_NoRootSkipsGraph(t *testing.T) {
+	t.Parallel()
+
+	agg := NewAggregator()
+	agg.Aggregate(fileReport("main.go", "fmt"))
+
+	result := agg.GetResult()
+	assert.NotContains(t, result, KeyPackages)
+	assert.Equal(t, 1, result["count"])
+}
+
+func TestAnalyzer_GenerateGraphs(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		KeyPackages: []string{"cmd", "pkg/a"},
+		KeyPackageDependencies: []importmodel.Edge{
+			{From: "cmd", To: "pkg/a", Imports: 3},
+		},
+	}
+
+	graphs, err := NewAnalyzer().GenerateGraphs(report)
+	require.NoError(t, err)
+	require.Len(t, graphs, 1)
+	assert.Equal(t, graphexport.Graph{
+		ID:       "packages",
+		Label:    "Package dependencies",
+		Directed: true,
+		Nodes: []graphexport.Node{
+			{ID: "0", Label: "cmd", Weight: 3},
+			{ID: "1", Label: "pkg/a", Weight: 0},
+		},
+		Edges: []graphexport.Edge{{Source: "0", Target: "1", Weight: 3}},
+	}, graphs[0])
+}
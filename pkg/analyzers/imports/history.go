@@ -82,6 +82,7 @@ func NewHistoryAnalyzer() *HistoryAnalyzer {
 			Mode:        analyze.ModeHistory,
 		},
 		Sequential: false,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, Memory: analyze.MemoryMedium},
 		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
 			if len(report) == 0 {
 				return &ComputedMetrics{}, nil
@@ -199,7 +200,7 @@ func (h *HistoryAnalyzer) extractImports(ctx context.Context, name string, data
 	}
 
 	// Extract imports using logic from analyzer.go (in same package).
-	imports := extractImportsFromUAST(root)
+	imports := ExtractImports(root)
 
 	// Determine language.
 	lang := h.parser.GetLanguage(name)
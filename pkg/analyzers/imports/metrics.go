@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
 	"github.com/Sumatoshi-tech/codefang/pkg/metrics"
 )
 
@@ -21,6 +22,12 @@ const (
 type ReportData struct {
 	Imports []string
 	Count   int
+
+	// Package dependency graph, present in static reports of a folder.
+	Packages            []string
+	PackageDependencies []importmodel.Edge
+	ImportCycles        [][]string
+	LayerViolations     []importmodel.LayerViolation
 }
 
 // ParseReportData extracts ReportData from an analyzer report.
@@ -46,6 +53,11 @@ func ParseReportData(report analyze.Report) (*ReportData, error) {
 		data.Count = int(cv)
 	}
 
+	data.Packages, _ = report[KeyPackages].([]string)
+	data.PackageDependencies, _ = report[KeyPackageDependencies].([]importmodel.Edge)
+	data.ImportCycles, _ = report[KeyImportCycles].([][]string)
+	data.LayerViolations, _ = report[KeyLayerViolations].([]importmodel.LayerViolation)
+
 	return data, nil
 }
 
@@ -330,12 +342,21 @@ func (m *AggregateMetric) Compute(input *ReportData) AggregateData {
 
 // --- Computed Metrics ---.
 
+// DependencyGraphData is the package dependency graph of a static analysis.
+type DependencyGraphData struct {
+	Packages        []string                     `json:"packages"                   yaml:"packages"`
+	Dependencies    []importmodel.Edge           `json:"dependencies"               yaml:"dependencies"`
+	Cycles          [][]string                   `json:"cycles,omitempty"           yaml:"cycles,omitempty"`
+	LayerViolations []importmodel.LayerViolation `json:"layer_violations,omitempty" yaml:"layer_violations,omitempty"`
+}
+
 // ComputedMetrics holds all computed metric results for the imports analyzer.
 type ComputedMetrics struct {
-	ImportList   []ImportData           `json:"import_list"  yaml:"import_list"`
-	Categories   []ImportCategoryData   `json:"categories"   yaml:"categories"`
-	Dependencies []ImportDependencyData `json:"dependencies" yaml:"dependencies"`
-	Aggregate    AggregateData          `json:"aggregate"    yaml:"aggregate"`
+	ImportList      []ImportData           `json:"import_list"                yaml:"import_list"`
+	Categories      []ImportCategoryData   `json:"categories"                 yaml:"categories"`
+	Dependencies    []ImportDependencyData `json:"dependencies"               yaml:"dependencies"`
+	Aggregate       AggregateData          `json:"aggregate"                  yaml:"aggregate"`
+	DependencyGraph *DependencyGraphData   `json:"dependency_graph,omitempty" yaml:"dependency_graph,omitempty"`
 }
 
 // ComputeAllMetrics runs all imports metrics and returns the results.
@@ -357,10 +378,21 @@ func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
 	aggMetric := NewAggregateMetric()
 	aggregate := aggMetric.Compute(input)
 
-	return &ComputedMetrics{
+	computed := &ComputedMetrics{
 		ImportList:   importList,
 		Categories:   categories,
 		Dependencies: dependencies,
 		Aggregate:    aggregate,
-	}, nil
+	}
+
+	if len(input.Packages) > 0 {
+		computed.DependencyGraph = &DependencyGraphData{
+			Packages:        input.Packages,
+			Dependencies:    input.PackageDependencies,
+			Cycles:          input.ImportCycles,
+			LayerViolations: input.LayerViolations,
+		}
+	}
+
+	return computed, nil
 }
@@ -2,6 +2,8 @@ package imports
 
 import (
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/reportutil"
@@ -15,6 +17,13 @@ const (
 	MetricUniqueImports = "Unique Imports"
 	MetricTotalFiles    = "Total Files"
 
+	// Labels of the package dependency graph metrics and issues.
+	MetricPackages        = "Packages"
+	MetricImportCycles    = "Import Cycles"
+	MetricLayerViolations = "Layer Violations"
+	IssueImportCycle      = "Import cycle"
+	IssueLayerViolation   = "Layer violation"
+
 	// KeyImports is the report key for the list of imports.
 	KeyImports      = "imports"
 	KeyCount        = "count"
@@ -56,10 +65,27 @@ func NewReportSection(report analyze.Report) *ReportSection {
 
 // KeyMetrics returns the key metrics for the imports section.
 func (s *ReportSection) KeyMetrics() []analyze.Metric {
-	return []analyze.Metric{
+	metrics := []analyze.Metric{
 		{Label: MetricUniqueImports, Value: reportutil.FormatInt(reportutil.GetInt(s.report, KeyCount))},
 		{Label: MetricTotalFiles, Value: reportutil.FormatInt(reportutil.GetInt(s.report, KeyTotalFiles))},
 	}
+
+	data, _ := ParseReportData(s.report)
+	if len(data.Packages) == 0 {
+		return metrics
+	}
+
+	metrics = append(metrics,
+		analyze.Metric{Label: MetricPackages, Value: reportutil.FormatInt(len(data.Packages))},
+		analyze.Metric{Label: MetricImportCycles, Value: reportutil.FormatInt(len(data.ImportCycles))},
+	)
+
+	if _, checked := s.report[KeyLayerViolations]; checked {
+		metrics = append(metrics,
+			analyze.Metric{Label: MetricLayerViolations, Value: reportutil.FormatInt(len(data.LayerViolations))})
+	}
+
+	return metrics
 }
 
 // Distribution returns nil for imports (no distribution).
@@ -82,20 +108,50 @@ func (s *ReportSection) AllIssues() []analyze.Issue {
 	return s.buildImportIssues()
 }
 
-// buildImportIssues creates issues from import counts, sorted by frequency.
+// buildImportIssues creates issues from import counts, sorted by frequency,
+// after the import cycles and layer violations of the package graph.
 func (s *ReportSection) buildImportIssues() []analyze.Issue {
+	issues := buildGraphIssues(s.report)
+
 	counts := reportutil.GetStringIntMap(s.report, KeyImportCounts)
 	if len(counts) > 0 {
-		return buildIssuesFromCounts(counts)
+		return append(issues, buildIssuesFromCounts(counts)...)
 	}
 
 	// Fallback: use simple imports list.
 	imports := reportutil.GetStringSlice(s.report, KeyImports)
 	if len(imports) == 0 {
-		return nil
+		return issues
+	}
+
+	return append(issues, buildIssuesFromList(imports)...)
+}
+
+// buildGraphIssues creates poor-severity issues for import cycles and layer violations.
+func buildGraphIssues(report analyze.Report) []analyze.Issue {
+	data, _ := ParseReportData(report)
+
+	issues := make([]analyze.Issue, 0, len(data.ImportCycles)+len(data.LayerViolations))
+
+	for _, cycle := range data.ImportCycles {
+		issues = append(issues, analyze.Issue{
+			Name:     IssueImportCycle,
+			Location: strings.Join(cycle, ", "),
+			Value:    strconv.Itoa(len(cycle)) + " packages",
+			Severity: analyze.SeverityPoor,
+		})
 	}
 
-	return buildIssuesFromList(imports)
+	for _, v := range data.LayerViolations {
+		issues = append(issues, analyze.Issue{
+			Name:     IssueLayerViolation,
+			Location: v.From + " -> " + v.To,
+			Value:    v.FromLayer + " -> " + v.ToLayer,
+			Severity: analyze.SeverityPoor,
+		})
+	}
+
+	return issues
 }
 
 // importEntry holds an import name with its count for sorting.
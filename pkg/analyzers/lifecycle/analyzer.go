@@ -0,0 +1,334 @@
+// Package lifecycle provides contributor onboarding and offboarding analytics.
+package lifecycle
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Configuration option keys for the lifecycle analyzer.
+const (
+	ConfigLifecycleInactiveDays = "Lifecycle.InactiveDays"
+	ConfigLifecycleCohortDays   = "Lifecycle.CohortDays"
+
+	// DefaultInactiveDays is how long a contributor must be silent to count as departed.
+	DefaultInactiveDays = 90
+	// DefaultCohortDays is the width of one retention cohort.
+	DefaultCohortDays = 90
+)
+
+// CommitActivity is the per-commit payload: who committed and when (author time).
+type CommitActivity struct {
+	AuthorID int
+	When     time.Time
+}
+
+// AuthorTick summarizes one author's commits within one tick.
+type AuthorTick struct {
+	First   time.Time
+	Last    time.Time
+	Commits int
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	// Authors maps author ID to the author's activity within the tick.
+	Authors map[int]*AuthorTick
+}
+
+// Analyzer tracks when contributors join, ramp up, and leave a project.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	Identity           *plumbing.IdentityDetector
+	Ticks              *plumbing.TicksSinceStart
+	reversedPeopleDict []string
+	tickSize           time.Duration
+	tickCalendar       *pkgplumbing.TickCalendar // nil for fixed-size ticks.
+	InactiveDays       int
+	CohortDays         int
+}
+
+// NewAnalyzer creates a new lifecycle analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{InactiveDays: DefaultInactiveDays, CohortDays: DefaultCohortDays}
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/lifecycle",
+			Mode: analyze.ModeHistory,
+			Description: "Tracks contributor onboarding and offboarding: first/last commits, ramp-up time, " +
+				"active contributors per tick and retention cohorts.",
+		},
+		Sequential: false,
+		Caps:       &analyze.Capabilities{Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigLifecycleInactiveDays,
+				Description: "Days without commits, counted back from the last analyzed commit, after which a contributor has departed.",
+				Flag:        "lifecycle-inactive-days",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     DefaultInactiveDays,
+			},
+			{
+				Name:        ConfigLifecycleCohortDays,
+				Description: "Width in days of one retention cohort; contributors are grouped by when they first committed.",
+				Flag:        "lifecycle-cohort-days",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     DefaultCohortDays,
+			},
+		},
+		ComputeMetricsFn: computeMetricsSafe,
+		AggregatorFn:     newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+func computeMetricsSafe(report analyze.Report) (*ComputedMetrics, error) {
+	if len(report) == 0 {
+		return &ComputedMetrics{}, nil
+	}
+
+	return ComputeAllMetrics(report)
+}
+
+// Configure configures the analyzer with the given facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigLifecycleInactiveDays].(int); exists && val > 0 {
+		a.InactiveDays = val
+	}
+
+	if val, exists := facts[ConfigLifecycleCohortDays].(int); exists && val > 0 {
+		a.CohortDays = val
+	}
+
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		a.reversedPeopleDict = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		a.tickSize = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickCalendar].(*pkgplumbing.TickCalendar); exists {
+		a.tickCalendar = val
+	}
+
+	return nil
+}
+
+// Initialize prepares the analyzer for processing commits.
+func (a *Analyzer) Initialize(_ *gitlib.Repository) error {
+	if a.InactiveDays <= 0 {
+		a.InactiveDays = DefaultInactiveDays
+	}
+
+	if a.CohortDays <= 0 {
+		a.CohortDays = DefaultCohortDays
+	}
+
+	return nil
+}
+
+// Consume records the author and author time of a single commit.
+// Merge commits are skipped: they do not represent authored work.
+func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac.IsMerge {
+		return analyze.TC{}, nil
+	}
+
+	commit := ac.Commit
+
+	return analyze.TC{
+		Data: &CommitActivity{
+			AuthorID: a.Identity.AuthorID,
+			When:     commit.Author().When,
+		},
+		CommitHash: commit.Hash(),
+	}, nil
+}
+
+// Fork creates independent copies of the analyzer for parallel processing.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.Identity = &plumbing.IdentityDetector{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Tick:     a.Ticks.Tick,
+		AuthorID: a.Identity.AuthorID,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.Ticks.Tick = snapshot.Tick
+	a.Identity.AuthorID = snapshot.AuthorID
+}
+
+// ReleaseSnapshot is a no-op for lifecycle.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// Extract properties for GenericAggregator.
+
+const authorEntryOverhead = 96 // map entry + AuthorTick with two timestamps.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	ca, ok := tc.Data.(*CommitActivity)
+	if !ok || ca == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{Authors: make(map[int]*AuthorTick)}
+		byTick[tc.Tick] = state
+	}
+
+	at, ok := state.Authors[ca.AuthorID]
+	if !ok {
+		state.Authors[ca.AuthorID] = &AuthorTick{First: ca.When, Last: ca.When, Commits: 1}
+
+		return nil
+	}
+
+	at.add(&AuthorTick{First: ca.When, Last: ca.When, Commits: 1})
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming == nil {
+		return existing
+	}
+
+	if existing.Authors == nil {
+		existing.Authors = make(map[int]*AuthorTick)
+	}
+
+	for id, in := range incoming.Authors {
+		ext, ok := existing.Authors[id]
+		if !ok {
+			existing.Authors[id] = in
+
+			continue
+		}
+
+		ext.add(in)
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	return int64(len(state.Authors)) * authorEntryOverhead
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || len(state.Authors) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{
+		Tick: tick,
+		Data: state,
+	}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
+
+// add folds other into at, widening the first/last window.
+func (at *AuthorTick) add(other *AuthorTick) {
+	if other.First.Before(at.First) {
+		at.First = other.First
+	}
+
+	if other.Last.After(at.Last) {
+		at.Last = other.Last
+	}
+
+	at.Commits += other.Commits
+}
+
+// reportFromTicks converts aggregated TICKs into the analyze.Report format.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	byTick := make(map[int]map[int]*AuthorTick, len(ticks))
+
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*TickData)
+		if !ok || td == nil {
+			continue
+		}
+
+		byTick[tick.Tick] = td.Authors
+	}
+
+	report := analyze.Report{
+		reportKeyTicks:              byTick,
+		reportKeyReversedPeopleDict: a.reversedPeopleDict,
+		reportKeyTickSize:           a.tickSize,
+		reportKeyInactiveDays:       a.InactiveDays,
+		reportKeyCohortDays:         a.CohortDays,
+	}
+
+	if a.tickCalendar != nil {
+		report[reportKeyTickCalendar] = a.tickCalendar
+	}
+
+	return report
+}
@@ -0,0 +1,118 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func newTestAnalyzer() *Analyzer {
+	a := NewAnalyzer()
+	a.Identity = &plumbing.IdentityDetector{}
+	a.Ticks = &plumbing.TicksSinceStart{}
+
+	return a
+}
+
+func TestAnalyzer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	assert.Equal(t, "history/lifecycle", a.Name())
+	assert.NotEmpty(t, a.Flag())
+	assert.NotEmpty(t, a.Description())
+	assert.Len(t, a.ListConfigurationOptions(), 2)
+	assert.False(t, a.SequentialOnly())
+}
+
+func TestAnalyzer_Configure(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(map[string]any{
+		ConfigLifecycleInactiveDays: 30,
+		ConfigLifecycleCohortDays:   7,
+	}))
+	assert.Equal(t, 30, a.InactiveDays)
+	assert.Equal(t, 7, a.CohortDays)
+
+	require.NoError(t, a.Configure(map[string]any{ConfigLifecycleInactiveDays: 0}))
+	assert.Equal(t, 30, a.InactiveDays, "non-positive values keep the current setting")
+}
+
+func TestAnalyzer_Consume(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+	a.Identity.AuthorID = 2
+
+	when := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	commit := gitlib.NewTestCommit(
+		gitlib.NewHash("c100000000000000000000000000000000000001"),
+		gitlib.Signature{Name: "dev", Email: "dev@test.com", When: when},
+		"test commit",
+	)
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+
+	ca, ok := tc.Data.(*CommitActivity)
+	require.True(t, ok, "TC.Data should be *CommitActivity")
+	assert.Equal(t, 2, ca.AuthorID)
+	assert.Equal(t, when, ca.When)
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: commit, IsMerge: true})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+}
+
+func TestAggregator_WidensAuthorWindow(t *testing.T) {
+	t.Parallel()
+
+	byTick := map[int]*TickData{}
+
+	require.NoError(t, extractTC(analyze.TC{Tick: 0, Data: &CommitActivity{AuthorID: 1, When: day(1)}}, byTick))
+	require.NoError(t, extractTC(analyze.TC{Tick: 0, Data: &CommitActivity{AuthorID: 1, When: day(0)}}, byTick))
+
+	merged := mergeState(byTick[0], &TickData{Authors: map[int]*AuthorTick{
+		1: activity(2, 1),
+		3: activity(2, 1),
+	}})
+
+	assert.Equal(t, &AuthorTick{First: day(0), Last: day(2), Commits: 3}, merged.Authors[1])
+	assert.Len(t, merged.Authors, 2)
+}
+
+func TestAnalyzer_SerializeTICKs_JSON(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	a.reversedPeopleDict = []string{"alice"}
+
+	ticks := []analyze.TICK{
+		{Tick: 0, Data: &TickData{Authors: map[int]*AuthorTick{0: activity(0, 2)}}},
+		{Tick: 1, Data: &TickData{Authors: map[int]*AuthorTick{0: activity(1, 1)}}},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, a.SerializeTICKs(ticks, analyze.FormatJSON, &buf))
+
+	var out ComputedMetrics
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	require.Len(t, out.Contributors, 1)
+	assert.Equal(t, "alice", out.Contributors[0].Name)
+	assert.Equal(t, 3, out.Contributors[0].Commits)
+	assert.Len(t, out.Activity, 2)
+}
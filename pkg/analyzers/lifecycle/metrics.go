@@ -0,0 +1,487 @@
+package lifecycle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Report keys produced by the analyzer.
+const (
+	reportKeyTicks              = "Ticks"
+	reportKeyReversedPeopleDict = "ReversedPeopleDict"
+	reportKeyTickSize           = "TickSize"
+	reportKeyInactiveDays       = "InactiveDays"
+	reportKeyCohortDays         = "CohortDays"
+	reportKeyTickCalendar       = "TickCalendar"
+)
+
+const (
+	hoursPerDay = 24
+
+	// minRampUpActiveTicks is the number of active ticks needed before a steady output level is meaningful.
+	minRampUpActiveTicks = 3
+)
+
+// --- Input Data Types ---.
+
+// ReportData is the parsed input data for lifecycle metrics computation.
+type ReportData struct {
+	Ticks        map[int]map[int]*AuthorTick
+	Names        []string
+	TickSize     time.Duration
+	InactiveDays int
+	CohortDays   int
+	Calendar     *pkgplumbing.TickCalendar // nil for fixed-size ticks.
+	SampleFactor float64                   // 1 when every commit was analyzed.
+}
+
+// ParseReportData extracts ReportData from an analyzer report.
+func ParseReportData(report analyze.Report) (*ReportData, error) {
+	data := &ReportData{
+		InactiveDays: DefaultInactiveDays,
+		CohortDays:   DefaultCohortDays,
+		SampleFactor: analyze.SampleFactor(report),
+	}
+
+	if v, ok := report[reportKeyTicks].(map[int]map[int]*AuthorTick); ok {
+		data.Ticks = v
+	}
+
+	if v, ok := report[reportKeyReversedPeopleDict].([]string); ok {
+		data.Names = v
+	}
+
+	if v, ok := report[reportKeyTickSize].(time.Duration); ok {
+		data.TickSize = v
+	}
+
+	if v, ok := report[reportKeyInactiveDays].(int); ok && v > 0 {
+		data.InactiveDays = v
+	}
+
+	if v, ok := report[reportKeyCohortDays].(int); ok && v > 0 {
+		data.CohortDays = v
+	}
+
+	if c, ok := report[reportKeyTickCalendar].(*pkgplumbing.TickCalendar); ok && c.Granularity.Calendar() {
+		data.Calendar = c
+	}
+
+	return data, nil
+}
+
+// cohortTicks returns the cohort width in ticks, never less than one.
+func (d *ReportData) cohortTicks() int {
+	if d.TickSize <= 0 {
+		return 1
+	}
+
+	cohort := time.Duration(d.CohortDays) * hoursPerDay * time.Hour
+
+	return max(1, int(math.Round(float64(cohort)/float64(d.TickSize))))
+}
+
+// periodLabel names a tick by its calendar period, or "tick N" for fixed-size ticks.
+func (d *ReportData) periodLabel(tick int) string {
+	if d.Calendar != nil {
+		return d.Calendar.Label(tick)
+	}
+
+	return fmt.Sprintf("tick %d", tick)
+}
+
+// --- Output Data Types ---.
+
+// ContributorData describes one contributor's lifecycle.
+// RampUpTicks is omitted for contributors with too few active ticks to have a steady output level.
+type ContributorData struct {
+	ID          int       `json:"id"                      yaml:"id"`
+	Name        string    `json:"name"                    yaml:"name"`
+	FirstCommit time.Time `json:"first_commit"            yaml:"first_commit"`
+	LastCommit  time.Time `json:"last_commit"             yaml:"last_commit"`
+	FirstTick   int       `json:"first_tick"              yaml:"first_tick"`
+	LastTick    int       `json:"last_tick"               yaml:"last_tick"`
+	Commits     int       `json:"commits"                 yaml:"commits"`
+	ActiveTicks int       `json:"active_ticks"            yaml:"active_ticks"`
+	TenureDays  float64   `json:"tenure_days"             yaml:"tenure_days"`
+	RampUpTicks *int      `json:"ramp_up_ticks,omitempty" yaml:"ramp_up_ticks,omitempty"`
+	Cohort      int       `json:"cohort"                  yaml:"cohort"`
+	Departed    bool      `json:"departed"                yaml:"departed"`
+}
+
+// ActivityData contains contributor counts for one tick.
+type ActivityData struct {
+	Tick     int    `json:"tick"             yaml:"tick"`
+	Period   string `json:"period,omitempty" yaml:"period,omitempty"`
+	Active   int    `json:"active"           yaml:"active"`
+	Joined   int    `json:"joined"           yaml:"joined"`
+	Departed int    `json:"departed"         yaml:"departed"`
+}
+
+// CohortData contains the retention curve of contributors who joined in the same period.
+// Retention[k] is the share of the cohort with at least one commit k cohort periods after joining.
+type CohortData struct {
+	Cohort    int       `json:"cohort"     yaml:"cohort"`
+	StartTick int       `json:"start_tick" yaml:"start_tick"`
+	Period    string    `json:"period"     yaml:"period"`
+	Size      int       `json:"size"       yaml:"size"`
+	Retention []float64 `json:"retention"  yaml:"retention"`
+}
+
+// AggregateData contains project-wide lifecycle statistics.
+type AggregateData struct {
+	TotalContributors    int     `json:"total_contributors"     yaml:"total_contributors"`
+	ActiveContributors   int     `json:"active_contributors"    yaml:"active_contributors"`
+	DepartedContributors int     `json:"departed_contributors"  yaml:"departed_contributors"`
+	OneTimeContributors  int     `json:"one_time_contributors"  yaml:"one_time_contributors"`
+	MedianTenureDays     float64 `json:"median_tenure_days"     yaml:"median_tenure_days"`
+	MedianRampUpTicks    float64 `json:"median_ramp_up_ticks"   yaml:"median_ramp_up_ticks"`
+	PeakActive           int     `json:"peak_active"            yaml:"peak_active"`
+	PeakActiveTick       int     `json:"peak_active_tick"       yaml:"peak_active_tick"`
+	InactiveDays         int     `json:"inactive_days"          yaml:"inactive_days"`
+	CohortTicks          int     `json:"cohort_ticks"           yaml:"cohort_ticks"`
+}
+
+// --- Computed Metrics ---.
+
+// ComputedMetrics holds all computed metric results for the lifecycle analyzer.
+type ComputedMetrics struct {
+	Contributors []ContributorData `json:"contributors" yaml:"contributors"`
+	Activity     []ActivityData    `json:"activity"     yaml:"activity"`
+	Cohorts      []CohortData      `json:"cohorts"      yaml:"cohorts"`
+	Aggregate    AggregateData     `json:"aggregate"    yaml:"aggregate"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNameLifecycle = "lifecycle"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNameLifecycle
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// ComputeAllMetrics runs all lifecycle metrics and returns the results.
+func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
+	input, err := ParseReportData(report)
+	if err != nil {
+		return nil, err
+	}
+
+	contributors := computeContributors(input)
+	activity := computeActivity(input, contributors)
+
+	return &ComputedMetrics{
+		Contributors: contributors,
+		Activity:     activity,
+		Cohorts:      computeCohorts(input, contributors),
+		Aggregate:    computeAggregate(input, contributors, activity),
+	}, nil
+}
+
+// --- Metric Implementations ---.
+
+func sortedTicks(ticks map[int]map[int]*AuthorTick) []int {
+	keys := make([]int, 0, len(ticks))
+	for tick := range ticks {
+		keys = append(keys, tick)
+	}
+
+	sort.Ints(keys)
+
+	return keys
+}
+
+// lastCommitTime returns the newest commit time across all ticks.
+func lastCommitTime(ticks map[int]map[int]*AuthorTick) time.Time {
+	var last time.Time
+
+	for _, authors := range ticks {
+		for _, at := range authors {
+			if at.Last.After(last) {
+				last = at.Last
+			}
+		}
+	}
+
+	return last
+}
+
+func computeContributors(input *ReportData) []ContributorData {
+	byAuthor := make(map[int]*ContributorData)
+	perTick := make(map[int][]int) // author ID -> commits per active tick, in tick order.
+	ticks := sortedTicks(input.Ticks)
+
+	for _, tick := range ticks {
+		for id, at := range input.Ticks[tick] {
+			cd, ok := byAuthor[id]
+			if !ok {
+				cd = &ContributorData{
+					ID:          id,
+					Name:        authorName(id, input.Names),
+					FirstCommit: at.First,
+					LastCommit:  at.Last,
+					FirstTick:   tick,
+				}
+				byAuthor[id] = cd
+			}
+
+			if at.First.Before(cd.FirstCommit) {
+				cd.FirstCommit = at.First
+			}
+
+			if at.Last.After(cd.LastCommit) {
+				cd.LastCommit = at.Last
+			}
+
+			cd.LastTick = tick
+			cd.Commits += at.Commits
+			cd.ActiveTicks++
+			perTick[id] = append(perTick[id], tick)
+		}
+	}
+
+	cutoff := lastCommitTime(input.Ticks).AddDate(0, 0, -input.InactiveDays)
+	cohortTicks := input.cohortTicks()
+	firstTick := 0
+
+	if len(ticks) > 0 {
+		firstTick = ticks[0]
+	}
+
+	result := make([]ContributorData, 0, len(byAuthor))
+
+	for id, cd := range byAuthor {
+		cd.TenureDays = cd.LastCommit.Sub(cd.FirstCommit).Hours() / hoursPerDay
+		cd.Departed = cd.LastCommit.Before(cutoff)
+		cd.Cohort = (cd.FirstTick - firstTick) / cohortTicks
+		cd.RampUpTicks = rampUpTicks(input.Ticks, id, perTick[id])
+		cd.Commits = analyze.ScaleCount(cd.Commits, input.SampleFactor)
+
+		result = append(result, *cd)
+	}
+
+	// Sort by join time, then by ID for stable output.
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].FirstCommit.Equal(result[j].FirstCommit) {
+			return result[i].FirstCommit.Before(result[j].FirstCommit)
+		}
+
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+// rampUpTicks returns how many ticks after joining the author first reached
+// their steady output: the median commit count over their active ticks.
+// Returns nil when the author has too few active ticks to judge.
+func rampUpTicks(ticks map[int]map[int]*AuthorTick, id int, active []int) *int {
+	if len(active) < minRampUpActiveTicks {
+		return nil
+	}
+
+	counts := make([]float64, len(active))
+	for i, tick := range active {
+		counts[i] = float64(ticks[tick][id].Commits)
+	}
+
+	steady := median(counts)
+
+	for i, tick := range active {
+		if counts[i] >= steady {
+			ramp := tick - active[0]
+
+			return &ramp
+		}
+	}
+
+	return nil
+}
+
+func computeActivity(input *ReportData, contributors []ContributorData) []ActivityData {
+	joined := make(map[int]int)
+	departed := make(map[int]int)
+
+	for _, cd := range contributors {
+		joined[cd.FirstTick]++
+
+		if cd.Departed {
+			departed[cd.LastTick]++
+		}
+	}
+
+	ticks := sortedTicks(input.Ticks)
+	result := make([]ActivityData, 0, len(ticks))
+
+	for _, tick := range ticks {
+		ad := ActivityData{
+			Tick:     tick,
+			Active:   len(input.Ticks[tick]),
+			Joined:   joined[tick],
+			Departed: departed[tick],
+		}
+
+		if input.Calendar != nil {
+			ad.Period = input.Calendar.Label(tick)
+		}
+
+		result = append(result, ad)
+	}
+
+	return result
+}
+
+func computeCohorts(input *ReportData, contributors []ContributorData) []CohortData {
+	ticks := sortedTicks(input.Ticks)
+	if len(ticks) == 0 {
+		return []CohortData{}
+	}
+
+	firstTick := ticks[0]
+	cohortTicks := input.cohortTicks()
+	lastPeriod := (ticks[len(ticks)-1] - firstTick) / cohortTicks
+
+	// activePeriods[id] is the set of cohort periods in which the author committed.
+	activePeriods := make(map[int]map[int]bool)
+
+	for tick, authors := range input.Ticks {
+		period := (tick - firstTick) / cohortTicks
+
+		for id := range authors {
+			if activePeriods[id] == nil {
+				activePeriods[id] = make(map[int]bool)
+			}
+
+			activePeriods[id][period] = true
+		}
+	}
+
+	members := make(map[int][]int)
+	for _, cd := range contributors {
+		members[cd.Cohort] = append(members[cd.Cohort], cd.ID)
+	}
+
+	result := make([]CohortData, 0, len(members))
+
+	for cohort := 0; cohort <= lastPeriod; cohort++ {
+		ids := members[cohort]
+		if len(ids) == 0 {
+			continue
+		}
+
+		retention := make([]float64, lastPeriod-cohort+1)
+
+		for k := range retention {
+			retained := 0
+
+			for _, id := range ids {
+				if activePeriods[id][cohort+k] {
+					retained++
+				}
+			}
+
+			retention[k] = float64(retained) / float64(len(ids))
+		}
+
+		startTick := firstTick + cohort*cohortTicks
+
+		result = append(result, CohortData{
+			Cohort:    cohort,
+			StartTick: startTick,
+			Period:    input.periodLabel(startTick),
+			Size:      len(ids),
+			Retention: retention,
+		})
+	}
+
+	return result
+}
+
+func computeAggregate(input *ReportData, contributors []ContributorData, activity []ActivityData) AggregateData {
+	agg := AggregateData{
+		TotalContributors: len(contributors),
+		InactiveDays:      input.InactiveDays,
+		CohortTicks:       input.cohortTicks(),
+	}
+
+	tenures := make([]float64, 0, len(contributors))
+	rampUps := make([]float64, 0, len(contributors))
+
+	// A contributor seen in a single sampled commit counts as a one-time one.
+	oneTime := analyze.ScaleCount(1, input.SampleFactor)
+
+	for _, cd := range contributors {
+		if cd.Departed {
+			agg.DepartedContributors++
+		} else {
+			agg.ActiveContributors++
+		}
+
+		if cd.Commits == oneTime {
+			agg.OneTimeContributors++
+		}
+
+		tenures = append(tenures, cd.TenureDays)
+
+		if cd.RampUpTicks != nil {
+			rampUps = append(rampUps, float64(*cd.RampUpTicks))
+		}
+	}
+
+	agg.MedianTenureDays = median(tenures)
+	agg.MedianRampUpTicks = median(rampUps)
+
+	for _, ad := range activity {
+		if ad.Active > agg.PeakActive {
+			agg.PeakActive = ad.Active
+			agg.PeakActiveTick = ad.Tick
+		}
+	}
+
+	return agg
+}
+
+// median returns the median of values, or 0 for an empty slice. values is not modified.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+
+	return sorted[mid]
+}
+
+func authorName(id int, names []string) string {
+	if id == identity.AuthorMissing {
+		return identity.AuthorMissingName
+	}
+
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return fmt.Sprintf("dev_%d", id)
+}
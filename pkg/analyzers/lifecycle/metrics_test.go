@@ -0,0 +1,154 @@
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+var testEpoch = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func day(n int) time.Time { return testEpoch.AddDate(0, 0, n) }
+
+func activity(tick, commits int) *AuthorTick {
+	return &AuthorTick{First: day(tick), Last: day(tick), Commits: commits}
+}
+
+// testReport builds a three-contributor history over six daily ticks:
+// author 0 commits on ticks 0, 1, 2 and 5; author 1 commits once on tick 0;
+// author 2 joins on tick 3.
+func testReport() analyze.Report {
+	return analyze.Report{
+		reportKeyTicks: map[int]map[int]*AuthorTick{
+			0: {0: activity(0, 1), 1: activity(0, 1)},
+			1: {0: activity(1, 3)},
+			2: {0: activity(2, 3)},
+			3: {2: activity(3, 1)},
+			4: {2: activity(4, 2)},
+			5: {0: activity(5, 2)},
+		},
+		reportKeyReversedPeopleDict: []string{"alice", "bob", "carol"},
+		reportKeyTickSize:           24 * time.Hour,
+		reportKeyInactiveDays:       3,
+		reportKeyCohortDays:         2,
+	}
+}
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m, err := ComputeAllMetrics(analyze.Report{})
+	require.NoError(t, err)
+	assert.Empty(t, m.Contributors)
+	assert.Empty(t, m.Activity)
+	assert.Empty(t, m.Cohorts)
+	assert.Equal(t, DefaultInactiveDays, m.Aggregate.InactiveDays)
+}
+
+func TestComputeAllMetrics_Contributors(t *testing.T) {
+	t.Parallel()
+
+	m, err := ComputeAllMetrics(testReport())
+	require.NoError(t, err)
+	require.Len(t, m.Contributors, 3)
+
+	alice := m.Contributors[0]
+	assert.Equal(t, "alice", alice.Name)
+	assert.Equal(t, day(0), alice.FirstCommit)
+	assert.Equal(t, day(5), alice.LastCommit)
+	assert.Equal(t, 9, alice.Commits)
+	assert.Equal(t, 4, alice.ActiveTicks)
+	assert.InDelta(t, 5.0, alice.TenureDays, 1e-9)
+	require.NotNil(t, alice.RampUpTicks, "median output 2.5 is first reached on tick 1")
+	assert.Equal(t, 1, *alice.RampUpTicks)
+	assert.False(t, alice.Departed)
+
+	bob := m.Contributors[1]
+	assert.Equal(t, "bob", bob.Name)
+	assert.True(t, bob.Departed, "no commits in the last 3 days")
+	assert.Nil(t, bob.RampUpTicks)
+
+	carol := m.Contributors[2]
+	assert.Equal(t, 3, carol.FirstTick)
+	assert.Equal(t, 1, carol.Cohort)
+	assert.False(t, carol.Departed)
+}
+
+func TestComputeAllMetrics_ActivityAndCohorts(t *testing.T) {
+	t.Parallel()
+
+	m, err := ComputeAllMetrics(testReport())
+	require.NoError(t, err)
+
+	require.Len(t, m.Activity, 6)
+	assert.Equal(t, ActivityData{Tick: 0, Active: 2, Joined: 2, Departed: 1}, m.Activity[0])
+	assert.Equal(t, ActivityData{Tick: 3, Active: 1, Joined: 1}, m.Activity[3])
+
+	require.Len(t, m.Cohorts, 2)
+	assert.Equal(t, 2, m.Cohorts[0].Size)
+	assert.Equal(t, []float64{1, 0.5, 0.5}, m.Cohorts[0].Retention)
+	assert.Equal(t, 2, m.Cohorts[1].StartTick)
+	assert.Equal(t, []float64{1, 1}, m.Cohorts[1].Retention)
+
+	agg := m.Aggregate
+	assert.Equal(t, 3, agg.TotalContributors)
+	assert.Equal(t, 2, agg.ActiveContributors)
+	assert.Equal(t, 1, agg.DepartedContributors)
+	assert.Equal(t, 1, agg.OneTimeContributors)
+	assert.InDelta(t, 1.0, agg.MedianRampUpTicks, 1e-9)
+	assert.Equal(t, 2, agg.PeakActive)
+	assert.Equal(t, 0, agg.PeakActiveTick)
+	assert.Equal(t, 2, agg.CohortTicks)
+}
+
+func TestComputeAllMetrics_ScalesSampledCommits(t *testing.T) {
+	t.Parallel()
+
+	report := testReport()
+	report[analyze.ReportKeySampleFactor] = 2.0
+
+	m, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+	require.Len(t, m.Contributors, 3)
+
+	assert.Equal(t, 18, m.Contributors[0].Commits)
+	assert.Equal(t, 2, m.Contributors[1].Commits)
+	assert.Equal(t, 1, m.Aggregate.OneTimeContributors, "bob was seen in one sampled commit")
+}
+
+func TestComputeAllMetrics_CalendarCohorts(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		reportKeyTicks: map[int]map[int]*AuthorTick{
+			0: {0: activity(0, 1)},
+			4: {1: activity(120, 1)},
+		},
+		reportKeyTickSize:   pkgplumbing.TickMonth.NominalTickSize(),
+		reportKeyCohortDays: 91,
+		reportKeyTickCalendar: &pkgplumbing.TickCalendar{
+			Granularity: pkgplumbing.TickMonth,
+			Start:       testEpoch,
+		},
+	}
+
+	m, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+	require.Len(t, m.Cohorts, 2)
+	assert.Equal(t, "2024-01", m.Cohorts[0].Period)
+	assert.Equal(t, "2024-04", m.Cohorts[1].Period, "91 days round to three monthly ticks")
+	assert.Equal(t, "2024-05", m.Activity[1].Period)
+}
+
+func TestMedian(t *testing.T) {
+	t.Parallel()
+
+	assert.Zero(t, median(nil))
+	assert.InDelta(t, 2.0, median([]float64{3, 1, 2}), 1e-9)
+	assert.InDelta(t, 2.5, median([]float64{4, 1, 3, 2}), 1e-9)
+}
@@ -0,0 +1,173 @@
+package lifecycle
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const (
+	percentScale = 100
+	// maxRetentionColumns caps the retention table width; later periods are rarely actionable.
+	maxRetentionColumns = 8
+	// recentDeparturesLimit caps the departed-contributors table.
+	recentDeparturesLimit = 20
+)
+
+// RegisterPlotSections registers the lifecycle plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/lifecycle", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	metrics, err := ComputeAllMetrics(report)
+	if err != nil {
+		return nil, err
+	}
+
+	return []plotpage.Section{
+		{
+			Title:    "Active Contributors",
+			Subtitle: "Contributors with at least one commit per time period, with joins and departures.",
+			Chart:    plotpage.WrapChart(buildActivityChart(metrics)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Active line = size of the working team over time",
+					"Joined spikes = onboarding waves; Departed = last commit of contributors who went silent",
+					fmt.Sprintf("A contributor has departed after %d days without commits", metrics.Aggregate.InactiveDays),
+				},
+			},
+		},
+		{
+			Title:    "Retention Cohorts",
+			Subtitle: "Share of each joining cohort still committing N periods later.",
+			Chart:    buildCohortTable(metrics),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Each row groups contributors by when they first committed",
+					"Fast drop-off after period 0 = drive-by contributions or poor onboarding",
+					"Compare rows to see whether retention improves for newer cohorts",
+				},
+			},
+		},
+		{
+			Title:    "Recent Departures",
+			Subtitle: "Contributors who stopped committing, most recent first.",
+			Chart:    buildDeparturesTable(metrics),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Long tenure + recent departure = knowledge that may have left with the person",
+					"Action: Cross-check with ownership and bus factor reports for the files they touched",
+				},
+			},
+		},
+	}, nil
+}
+
+// GenerateChart implements PlotGenerator interface.
+func (a *Analyzer) GenerateChart(report analyze.Report) (components.Charter, error) {
+	metrics, err := ComputeAllMetrics(report)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildActivityChart(metrics), nil
+}
+
+func buildActivityChart(metrics *ComputedMetrics) *charts.Line {
+	labels := make([]string, len(metrics.Activity))
+	active := make([]plotpage.SeriesData, len(metrics.Activity))
+	joined := make([]plotpage.SeriesData, len(metrics.Activity))
+	departed := make([]plotpage.SeriesData, len(metrics.Activity))
+
+	for i, ad := range metrics.Activity {
+		labels[i] = ad.Period
+		if labels[i] == "" {
+			labels[i] = strconv.Itoa(ad.Tick)
+		}
+
+		active[i] = ad.Active
+		joined[i] = ad.Joined
+		departed[i] = ad.Departed
+	}
+
+	palette := plotpage.GetChartPalette(plotpage.ThemeDark)
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: "Active", Data: active, AreaOpacity: 0.2},
+		{Name: "Joined", Data: joined, Color: palette.Semantic.Good},
+		{Name: "Departed", Data: departed, Color: palette.Semantic.Bad},
+	}, "Contributors")
+}
+
+func buildCohortTable(metrics *ComputedMetrics) *plotpage.Table {
+	columns := 0
+	for _, c := range metrics.Cohorts {
+		columns = max(columns, min(len(c.Retention), maxRetentionColumns))
+	}
+
+	headers := []string{"Cohort", "Size"}
+	for k := range columns {
+		headers = append(headers, "+"+strconv.Itoa(k))
+	}
+
+	table := plotpage.NewTable(headers)
+
+	for _, c := range metrics.Cohorts {
+		row := []string{html.EscapeString(c.Period), strconv.Itoa(c.Size)}
+
+		for k := range columns {
+			if k < len(c.Retention) {
+				row = append(row, fmt.Sprintf("%.0f%%", c.Retention[k]*percentScale))
+			} else {
+				row = append(row, "")
+			}
+		}
+
+		table.AddRow(row...)
+	}
+
+	return table
+}
+
+func buildDeparturesTable(metrics *ComputedMetrics) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Contributor", "First Commit", "Last Commit", "Tenure (days)", "Commits"})
+
+	departed := make([]ContributorData, 0, len(metrics.Contributors))
+
+	for _, cd := range metrics.Contributors {
+		if cd.Departed {
+			departed = append(departed, cd)
+		}
+	}
+
+	sort.SliceStable(departed, func(i, j int) bool {
+		return departed[i].LastCommit.After(departed[j].LastCommit)
+	})
+
+	for _, cd := range departed[:min(len(departed), recentDeparturesLimit)] {
+		table.AddRow(
+			html.EscapeString(cd.Name),
+			cd.FirstCommit.Format(time.DateOnly),
+			cd.LastCommit.Format(time.DateOnly),
+			strconv.Itoa(int(cd.TenureDays)),
+			strconv.Itoa(cd.Commits),
+		)
+	}
+
+	return table
+}
@@ -22,8 +22,14 @@ type BlobCacheAnalyzer struct {
 	previousCache           map[gitlib.Hash]*gitlib.CachedBlob
 	Cache                   map[gitlib.Hash]*gitlib.CachedBlob
 	FailOnMissingSubmodules bool
-	Goroutines              int
-	repos                   []*gitlib.Repository
+	// ResolveLFS replaces Git LFS pointers with their objects from the
+	// repository's LFS store. Unresolved pointers count as binary.
+	ResolveLFS bool
+	// TextEncoding says how blob text is transcoded to UTF-8.
+	TextEncoding gitlib.TextEncoding
+	Goroutines   int
+	repos        []*gitlib.Repository
+	lfs          *gitlib.LFSStore
 }
 
 const (
@@ -31,6 +37,10 @@ const (
 	ConfigBlobCacheFailOnMissingSubmodules = "BlobCache.FailOnMissingSubmodules"
 	// ConfigBlobCacheGoroutines is the configuration key for parallel blob loading.
 	ConfigBlobCacheGoroutines = "BlobCache.Goroutines"
+	// ConfigBlobCacheResolveLFS is the configuration key for resolving Git LFS pointers.
+	ConfigBlobCacheResolveLFS = "BlobCache.ResolveLFS"
+	// ConfigBlobCacheTextEncoding is the configuration key for transcoding blob text.
+	ConfigBlobCacheTextEncoding = "BlobCache.TextEncoding"
 )
 
 // Name returns the name of the analyzer.
@@ -74,6 +84,22 @@ func (b *BlobCacheAnalyzer) ListConfigurationOptions() []pipeline.ConfigurationO
 			Type:        pipeline.IntConfigurationOption,
 			Default:     runtime.NumCPU(),
 		},
+		{
+			Name: ConfigBlobCacheResolveLFS,
+			Description: "Replace Git LFS pointer files with their objects from the local LFS store " +
+				"(.git/lfs/objects). Pointers without a local object are treated as binary.",
+			Flag:    "resolve-lfs",
+			Type:    pipeline.BoolConfigurationOption,
+			Default: false,
+		},
+		{
+			Name: ConfigBlobCacheTextEncoding,
+			Description: "How blob text is transcoded to UTF-8: auto detects each blob's encoding, " +
+				"off keeps blobs as stored, and an encoding name such as windows-1252 forces it.",
+			Flag:    "text-encoding",
+			Type:    pipeline.StringConfigurationOption,
+			Default: gitlib.TextEncodingAuto,
+		},
 	}
 }
 
@@ -87,6 +113,19 @@ func (b *BlobCacheAnalyzer) Configure(facts map[string]any) error {
 		b.Goroutines = val
 	}
 
+	if val, exists := facts[ConfigBlobCacheResolveLFS].(bool); exists {
+		b.ResolveLFS = val
+	}
+
+	if val, exists := facts[ConfigBlobCacheTextEncoding].(string); exists {
+		te, err := gitlib.ParseTextEncoding(val)
+		if err != nil {
+			return err
+		}
+
+		b.TextEncoding = te
+	}
+
 	return nil
 }
 
@@ -99,6 +138,13 @@ func (b *BlobCacheAnalyzer) Initialize(repo *gitlib.Repository) error {
 		b.Goroutines = runtime.NumCPU()
 	}
 
+	repo.SetTextEncoding(b.TextEncoding)
+
+	b.lfs = nil
+	if b.ResolveLFS {
+		b.lfs = repo.LFSStore()
+	}
+
 	// Create repository pool for parallel access.
 	// We reuse the main repo for worker 0.
 	b.repos = make([]*gitlib.Repository, b.Goroutines)
@@ -115,6 +161,7 @@ func (b *BlobCacheAnalyzer) Initialize(repo *gitlib.Repository) error {
 			return fmt.Errorf("failed to open repository clone for worker %d: %w", i, err)
 		}
 
+		clonedRepo.SetTextEncoding(b.TextEncoding)
 		b.repos[i] = clonedRepo
 	}
 
@@ -212,7 +259,7 @@ func (b *BlobCacheAnalyzer) handleInsert(
 	cache[hash] = &gitlib.CachedBlob{}
 	newCache[hash] = &gitlib.CachedBlob{}
 	// Try to load the blob.
-	blob, err := gitlib.NewCachedBlobFromRepo(ctx, repo, hash)
+	blob, err := b.loadBlob(ctx, repo, hash)
 	if err == nil {
 		cache[hash] = blob
 		newCache[hash] = blob
@@ -240,7 +287,7 @@ func (b *BlobCacheAnalyzer) handleDelete(
 	// Initialize with empty blob.
 	cache[hash] = &gitlib.CachedBlob{}
 	// Try to load the blob.
-	blob, err := gitlib.NewCachedBlobFromRepo(ctx, repo, hash)
+	blob, err := b.loadBlob(ctx, repo, hash)
 	if err == nil {
 		cache[hash] = blob
 	}
@@ -256,7 +303,7 @@ func (b *BlobCacheAnalyzer) handleModify(
 	cache[toHash] = &gitlib.CachedBlob{}
 	newCache[toHash] = &gitlib.CachedBlob{}
 
-	blob, err := gitlib.NewCachedBlobFromRepo(ctx, repo, toHash)
+	blob, err := b.loadBlob(ctx, repo, toHash)
 	if err == nil {
 		cache[toHash] = blob
 		newCache[toHash] = blob
@@ -275,12 +322,27 @@ func (b *BlobCacheAnalyzer) handleModify(
 
 	cache[fromHash] = &gitlib.CachedBlob{}
 
-	blob, err = gitlib.NewCachedBlobFromRepo(ctx, repo, fromHash)
+	blob, err = b.loadBlob(ctx, repo, fromHash)
 	if err == nil {
 		cache[fromHash] = blob
 	}
 }
 
+// loadBlob loads a blob, resolving it when it is an LFS pointer and
+// ResolveLFS is set.
+func (b *BlobCacheAnalyzer) loadBlob(ctx context.Context, repo *gitlib.Repository, hash gitlib.Hash) (*gitlib.CachedBlob, error) {
+	blob, err := gitlib.NewCachedBlobFromRepo(ctx, repo, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.lfs != nil {
+		blob = b.lfs.Resolve(blob)
+	}
+
+	return blob, nil
+}
+
 // Fork creates a copy of the analyzer for parallel processing.
 func (b *BlobCacheAnalyzer) Fork(n int) []analyze.HistoryAnalyzer {
 	res := make([]analyze.HistoryAnalyzer, n)
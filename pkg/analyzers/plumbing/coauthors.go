@@ -0,0 +1,47 @@
+package plumbing
+
+import "errors"
+
+// DefaultCoAuthorWeight credits each co-author of a commit as much as its author.
+const DefaultCoAuthorWeight = 1.0
+
+// ErrInvalidCoAuthorWeight is returned when the co-author weight is negative or not a finite number.
+var ErrInvalidCoAuthorWeight = errors.New("co-author weight must be a finite non-negative number")
+
+// CoAuthorWeight returns the co-author weight set in facts, or DefaultCoAuthorWeight.
+// The weight is the share of a co-authored commit credited to each co-author
+// relative to the author's share of 1; 0 credits the author alone.
+func CoAuthorWeight(facts map[string]any) float64 {
+	if val, exists := facts[ConfigIdentityDetectorCoAuthorWeight].(float64); exists {
+		return val
+	}
+
+	return DefaultCoAuthorWeight
+}
+
+// CreditShares splits total between the author of a commit, at index 0, and
+// its coAuthors, each co-author weighing weight against the author's 1. The
+// remainder of the rounding goes to the first authors, so the shares add up
+// to total.
+func CreditShares(total, coAuthors int, weight float64) []int {
+	shares := make([]int, coAuthors+1)
+	sum := 1 + float64(coAuthors)*weight
+	credited := 0
+
+	for i := range shares {
+		w := weight
+		if i == 0 {
+			w = 1
+		}
+
+		shares[i] = int(float64(total) * w / sum)
+		credited += shares[i]
+	}
+
+	for i := 0; credited < total; i++ {
+		shares[i%len(shares)]++
+		credited++
+	}
+
+	return shares
+}
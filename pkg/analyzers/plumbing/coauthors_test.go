@@ -0,0 +1,47 @@
+package plumbing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreditShares(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		total     int
+		coAuthors int
+		weight    float64
+		want      []int
+	}{
+		{name: "even", total: 11, coAuthors: 1, weight: 1, want: []int{6, 5}},
+		{name: "half weight", total: 10, coAuthors: 2, weight: 0.5, want: []int{6, 2, 2}},
+		{name: "author alone", total: 7, coAuthors: 2, weight: 0, want: []int{7, 0, 0}},
+		{name: "no co-authors", total: 7, weight: 1, want: []int{7}},
+		{name: "nothing to split", coAuthors: 1, weight: 1, want: []int{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, CreditShares(tt.total, tt.coAuthors, tt.weight))
+		})
+	}
+}
+
+func TestIdentityDetector_ConfigureCoAuthorWeight(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, DefaultCoAuthorWeight, CoAuthorWeight(map[string]any{}), 0)
+	assert.InDelta(t, 0.5, CoAuthorWeight(map[string]any{ConfigIdentityDetectorCoAuthorWeight: 0.5}), 0)
+
+	d := &IdentityDetector{}
+	require.NoError(t, d.Configure(map[string]any{ConfigIdentityDetectorCoAuthorWeight: 0.0}))
+
+	err := d.Configure(map[string]any{ConfigIdentityDetectorCoAuthorWeight: -1.0})
+	require.ErrorIs(t, err, ErrInvalidCoAuthorWeight)
+}
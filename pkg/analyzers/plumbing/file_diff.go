@@ -32,7 +32,15 @@ type FileDiffAnalyzer struct {
 	Goroutines       int
 	CleanupDisabled  bool
 	WhitespaceIgnore bool
-	repo             *gitlib.Repository
+	// Algorithm selects the line diff algorithm, here and in the runtime diff pipeline.
+	Algorithm gitlib.DiffAlgorithm
+	// IgnoreWhitespaceChanges tells LinesStatsCalculator not to count hunks
+	// that only change whitespace, such as formatter runs.
+	IgnoreWhitespaceChanges bool
+	// NormalizeEOL diffs files with CRLF line endings turned into LF, here
+	// and in the runtime diff pipeline.
+	NormalizeEOL bool
+	repo         *gitlib.Repository
 }
 
 const (
@@ -44,6 +52,12 @@ const (
 	ConfigFileDiffTimeout = "FileDiff.Timeout"
 	// ConfigFileDiffGoroutines is the configuration key for the number of parallel diff goroutines.
 	ConfigFileDiffGoroutines = "FileDiff.Goroutines"
+	// ConfigFileDiffIgnoreWhitespaceChanges is the configuration key for not counting whitespace-only changes.
+	ConfigFileDiffIgnoreWhitespaceChanges = "FileDiff.IgnoreWhitespaceChanges"
+	// ConfigFileDiffAlgorithm is the configuration key for the line diff algorithm.
+	ConfigFileDiffAlgorithm = "FileDiff.Algorithm"
+	// ConfigFileDiffNormalizeEOL is the configuration key for ignoring line ending changes.
+	ConfigFileDiffNormalizeEOL = "FileDiff.NormalizeEOL"
 )
 
 // Name returns the name of the analyzer.
@@ -97,6 +111,26 @@ func (f *FileDiffAnalyzer) ListConfigurationOptions() []pipeline.ConfigurationOp
 			Flag:        "diff-goroutines",
 			Type:        pipeline.IntConfigurationOption,
 			Default:     runtime.NumCPU()},
+		{
+			Name:        ConfigFileDiffIgnoreWhitespaceChanges,
+			Description: "Do not count whitespace-only changes in line statistics.",
+			Flag:        "ignore-whitespace",
+			Type:        pipeline.BoolConfigurationOption,
+			Default:     false},
+		{
+			Name: ConfigFileDiffAlgorithm,
+			Description: "Line diff algorithm: myers or patience. " +
+				"Patience improves attribution on files with repeated blocks.",
+			Flag:    "diff-algorithm",
+			Type:    pipeline.StringConfigurationOption,
+			Default: string(gitlib.DiffMyers)},
+		{
+			Name: ConfigFileDiffNormalizeEOL,
+			Description: "Treat CRLF and LF line endings as equal in diffs, so line ending conversions " +
+				"do not count as rewrites of whole files.",
+			Flag:    "normalize-eol",
+			Type:    pipeline.BoolConfigurationOption,
+			Default: false},
 	}
 }
 
@@ -118,6 +152,23 @@ func (f *FileDiffAnalyzer) Configure(facts map[string]any) error {
 		f.Goroutines = val
 	}
 
+	if val, exists := facts[ConfigFileDiffIgnoreWhitespaceChanges].(bool); exists {
+		f.IgnoreWhitespaceChanges = val
+	}
+
+	if val, exists := facts[ConfigFileDiffAlgorithm].(string); exists {
+		algorithm, err := gitlib.ParseDiffAlgorithm(val)
+		if err != nil {
+			return err
+		}
+
+		f.Algorithm = algorithm
+	}
+
+	if val, exists := facts[ConfigFileDiffNormalizeEOL].(bool); exists {
+		f.NormalizeEOL = val
+	}
+
 	return nil
 }
 
@@ -268,7 +319,12 @@ func (f *FileDiffAnalyzer) processChange(
 		return
 	}
 
-	strFrom, strTo := string(blobFrom.Data), string(blobTo.Data)
+	dataFrom, dataTo := blobFrom.Data, blobTo.Data
+	if f.NormalizeEOL {
+		dataFrom, dataTo = pkgplumbing.NormalizeEOL(dataFrom), pkgplumbing.NormalizeEOL(dataTo)
+	}
+
+	strFrom, strTo := string(dataFrom), string(dataTo)
 
 	// Another fast path: if strings are identical, no diff needed.
 	if strFrom == strTo {
@@ -292,7 +348,7 @@ func (f *FileDiffAnalyzer) processChange(
 	dmp.DiffTimeout = f.Timeout
 	src, dst, _ := dmp.DiffLinesToRunes(stripWhitespace(strFrom, f.WhitespaceIgnore), stripWhitespace(strTo, f.WhitespaceIgnore))
 
-	diffs := dmp.DiffMainRunes(src, dst, false)
+	diffs := pkgplumbing.DiffLineRunes(dmp, src, dst, f.Algorithm)
 	if !f.CleanupDisabled {
 		diffs = dmp.DiffCleanupMerge(dmp.DiffCleanupSemanticLossless(diffs))
 	}
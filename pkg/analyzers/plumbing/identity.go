@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 
@@ -22,7 +24,11 @@ type IdentityDetector struct {
 	PeopleDictPath     string
 	ReversedPeopleDict []string
 	AuthorID           int
-	ExactSignatures    bool
+	// CoAuthorIDs holds the identities named by the Co-authored-by trailers of
+	// the last processed commit, without the author and without duplicates.
+	// Analyzers split the commit's credit between AuthorID and CoAuthorIDs.
+	CoAuthorIDs     []int
+	ExactSignatures bool
 	// incrementalEmails and incrementalNames are used when building the dict incrementally
 	// during Consume() when commits aren't available during Configure().
 	incrementalEmails map[int][]string
@@ -36,6 +42,9 @@ const (
 	ConfigIdentityDetectorPeopleDictPath = "IdentityDetector.PeopleDictPath"
 	// ConfigIdentityDetectorExactSignatures is the configuration key for requiring exact author signatures.
 	ConfigIdentityDetectorExactSignatures = "IdentityDetector.ExactSignatures"
+	// ConfigIdentityDetectorCoAuthorWeight is the configuration key for the share of a commit credited
+	// to each co-author.
+	ConfigIdentityDetectorCoAuthorWeight = "IdentityDetector.CoAuthorWeight"
 )
 
 // Name returns the name of the analyzer.
@@ -75,7 +84,13 @@ func (d *IdentityDetector) ListConfigurationOptions() []pipeline.ConfigurationOp
 			"identities and should not be normally used.",
 		Flag:    "exact-signatures",
 		Type:    pipeline.BoolConfigurationOption,
-		Default: false},
+		Default: false}, {
+		Name: ConfigIdentityDetectorCoAuthorWeight,
+		Description: "Share of the lines of a commit with Co-authored-by trailers credited to each " +
+			"co-author, relative to the author's share of 1. 0 credits the author alone.",
+		Flag:    "co-author-weight",
+		Type:    pipeline.FloatConfigurationOption,
+		Default: DefaultCoAuthorWeight},
 	}
 }
 
@@ -93,6 +108,10 @@ func (d *IdentityDetector) Configure(facts map[string]any) error {
 		d.ExactSignatures = val
 	}
 
+	if weight := CoAuthorWeight(facts); weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 1) {
+		return fmt.Errorf("%w: %v", ErrInvalidCoAuthorWeight, weight)
+	}
+
 	if d.PeopleDict != nil && d.ReversedPeopleDict != nil {
 		return nil
 	}
@@ -152,10 +171,61 @@ func (d *IdentityDetector) Consume(_ context.Context, ac *analyze.Context) (anal
 	}
 
 	d.AuthorID = authorID
+	d.CoAuthorIDs = d.resolveCoAuthors(commit.Message(), authorID)
 
 	return analyze.TC{}, nil
 }
 
+// resolveCoAuthors returns the identities of the Co-authored-by trailers of
+// message. Co-authors missing from a finalized dictionary are left out rather
+// than credited to AuthorMissing.
+func (d *IdentityDetector) resolveCoAuthors(message string, authorID int) []int {
+	values := gitlib.TrailerValues(gitlib.ParseTrailers(message), gitlib.TrailerCoAuthoredBy)
+	if len(values) == 0 {
+		return nil
+	}
+
+	var ids []int
+
+	for _, value := range values {
+		signature, ok := gitlib.ParseTrailerSignature(value)
+		if !ok {
+			continue
+		}
+
+		var (
+			id     int
+			exists bool
+		)
+
+		if d.ExactSignatures {
+			id, exists = d.lookupExactSignature(signature)
+		} else {
+			id, exists = d.lookupLooseSignature(signature)
+		}
+
+		if (exists || !d.dictFinalized) && id != authorID && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// commitSignatures returns the author of commit followed by the signatures of
+// its Co-authored-by trailers.
+func commitSignatures(commit *gitlib.Commit) []gitlib.Signature {
+	signatures := []gitlib.Signature{commit.Author()}
+
+	for _, value := range gitlib.TrailerValues(commit.Trailers(), gitlib.TrailerCoAuthoredBy) {
+		if signature, ok := gitlib.ParseTrailerSignature(value); ok {
+			signatures = append(signatures, signature)
+		}
+	}
+
+	return signatures
+}
+
 // lookupExactSignature finds or registers an author using exact signature matching.
 func (d *IdentityDetector) lookupExactSignature(signature gitlib.Signature) (int, bool) {
 	sigStr := strings.ToLower(fmt.Sprintf("%s <%s>", signature.Name, signature.Email))
@@ -237,12 +307,12 @@ func (d *IdentityDetector) generateExactDict(commits []*gitlib.Commit) {
 	size := 0
 
 	for _, commit := range commits {
-		author := commit.Author()
-
-		sig := strings.ToLower(fmt.Sprintf("%s <%s>", author.Name, author.Email))
-		if _, exists := dict[sig]; !exists {
-			dict[sig] = size
-			size++
+		for _, author := range commitSignatures(commit) {
+			sig := strings.ToLower(fmt.Sprintf("%s <%s>", author.Name, author.Email))
+			if _, exists := dict[sig]; !exists {
+				dict[sig] = size
+				size++
+			}
 		}
 	}
 
@@ -263,11 +333,12 @@ func (d *IdentityDetector) generateLooseDict(commits []*gitlib.Commit) {
 	size := 0
 
 	for _, commit := range commits {
-		author := commit.Author()
-		email := strings.ToLower(author.Email)
-		name := strings.ToLower(author.Name)
+		for _, author := range commitSignatures(commit) {
+			email := strings.ToLower(author.Email)
+			name := strings.ToLower(author.Name)
 
-		size = registerLooseIdentity(dict, emails, names, email, name, size)
+			size = registerLooseIdentity(dict, emails, names, email, name, size)
+		}
 	}
 
 	reverseDict := make([]string, size)
@@ -14,6 +14,7 @@ import (
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 // extensionToLanguage maps common file extensions to their programming languages.
@@ -238,15 +239,18 @@ func languageByExtension(filename string) string {
 	return extensionToLanguage[ext]
 }
 
-// LanguagesDetectionAnalyzer detects programming languages of changed files.
-// It uses lazy detection - languages are only computed when Languages() is called.
+// LanguagesDetectionAnalyzer detects programming languages of changed files
+// and classifies their roles: test, generated, docs, config or source.
+// It uses lazy detection - languages are only computed when Languages() or
+// Roles() is called.
 type LanguagesDetectionAnalyzer struct {
 	// Dependencies.
 	TreeDiff  *TreeDiffAnalyzer
 	BlobCache *BlobCacheAnalyzer
 
-	// Output (private, use Languages() accessor).
+	// Output (private, use Languages() and Roles() accessors).
 	languages map[gitlib.Hash]string
+	roles     map[gitlib.Hash]pkgplumbing.FileRole
 	parsed    bool // tracks whether detection was done for current commit.
 }
 
@@ -284,8 +288,13 @@ func (l *LanguagesDetectionAnalyzer) ListConfigurationOptions() []pipeline.Confi
 	return []pipeline.ConfigurationOption{}
 }
 
-// Configure sets up the analyzer with the provided facts.
-func (l *LanguagesDetectionAnalyzer) Configure(_ map[string]any) error {
+// Configure sets up the analyzer with the provided facts and publishes
+// FactFileRoles.
+func (l *LanguagesDetectionAnalyzer) Configure(facts map[string]any) error {
+	if facts != nil {
+		facts[pkgplumbing.FactFileRoles] = pkgplumbing.FileRoleFunc(ClassifyFile)
+	}
+
 	return nil
 }
 
@@ -298,6 +307,7 @@ func (l *LanguagesDetectionAnalyzer) Initialize(_ *gitlib.Repository) error {
 func (l *LanguagesDetectionAnalyzer) Consume(_ context.Context, _ *analyze.Context) (analyze.TC, error) {
 	// Reset state for new commit - detection is lazy.
 	l.languages = nil
+	l.roles = nil
 	l.parsed = false
 
 	return analyze.TC{}, nil
@@ -306,34 +316,72 @@ func (l *LanguagesDetectionAnalyzer) Consume(_ context.Context, _ *analyze.Conte
 // Languages returns detected languages, computing lazily on first call per commit.
 // This avoids expensive language detection when downstream analyzers don't need it.
 func (l *LanguagesDetectionAnalyzer) Languages() map[gitlib.Hash]string {
+	l.detect()
+
+	return l.languages
+}
+
+// Roles returns the roles of the changed files by blob hash, computed with
+// the languages.
+func (l *LanguagesDetectionAnalyzer) Roles() map[gitlib.Hash]pkgplumbing.FileRole {
+	l.detect()
+
+	return l.roles
+}
+
+// detect computes the languages and roles of the current commit once.
+func (l *LanguagesDetectionAnalyzer) detect() {
 	if l.parsed {
-		return l.languages
+		return
 	}
 
 	l.parsed = true
-	changes := l.TreeDiff.Changes
 	cache := l.BlobCache.Cache
-	result := map[gitlib.Hash]string{}
+	languages := map[gitlib.Hash]string{}
+	roles := map[gitlib.Hash]pkgplumbing.FileRole{}
+
+	record := func(entry gitlib.ChangeEntry) {
+		blob := cache[entry.Hash]
+		lang := l.detectLanguage(entry.Name, blob)
+		languages[entry.Hash] = lang
+
+		var data []byte
+		if blob != nil {
+			data = blob.Data
+		}
 
-	for _, change := range changes {
+		roles[entry.Hash] = pkgplumbing.ClassifyFileRole(entry.Name, lang, data)
+	}
+
+	for _, change := range l.TreeDiff.Changes {
 		switch change.Action {
 		case gitlib.Insert:
-			result[change.To.Hash] = l.detectLanguage(
-				change.To.Name, cache[change.To.Hash])
+			record(change.To)
 		case gitlib.Delete:
-			result[change.From.Hash] = l.detectLanguage(
-				change.From.Name, cache[change.From.Hash])
+			record(change.From)
 		case gitlib.Modify:
-			result[change.To.Hash] = l.detectLanguage(
-				change.To.Name, cache[change.To.Hash])
-			result[change.From.Hash] = l.detectLanguage(
-				change.From.Name, cache[change.From.Hash])
+			record(change.To)
+			record(change.From)
 		}
 	}
 
-	l.languages = result
+	l.languages = languages
+	l.roles = roles
+}
+
+// ClassifyFile detects the language of a file and returns its role. It is
+// the [pkgplumbing.FileRoleFunc] published as [pkgplumbing.FactFileRoles].
+func ClassifyFile(name string, data []byte) pkgplumbing.FileRole {
+	var lang string
 
-	return l.languages
+	if data == nil || !enry.IsBinary(data) {
+		lang = languageByExtension(name)
+		if lang == "" && data != nil {
+			lang = enry.GetLanguage(path.Base(name), data)
+		}
+	}
+
+	return pkgplumbing.ClassifyFileRole(name, lang, data)
 }
 
 func (l *LanguagesDetectionAnalyzer) detectLanguage(name string, blob *gitlib.CachedBlob) string {
@@ -363,6 +411,12 @@ func (l *LanguagesDetectionAnalyzer) SetLanguages(languages map[gitlib.Hash]stri
 	l.parsed = true
 }
 
+// SetRoles sets the file roles directly, marking detection as done.
+func (l *LanguagesDetectionAnalyzer) SetRoles(roles map[gitlib.Hash]pkgplumbing.FileRole) {
+	l.roles = roles
+	l.parsed = true
+}
+
 // SetLanguagesForTest sets the languages directly (for testing only).
 func (l *LanguagesDetectionAnalyzer) SetLanguagesForTest(languages map[gitlib.Hash]string) {
 	l.SetLanguages(languages)
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 func TestLanguageByExtension_CommonExtensions(t *testing.T) {
@@ -174,6 +175,45 @@ func TestLanguagesDetectionAnalyzer_Configure(t *testing.T) {
 	ld := &LanguagesDetectionAnalyzer{}
 	err := ld.Configure(nil)
 	require.NoError(t, err)
+
+	facts := map[string]any{}
+	require.NoError(t, ld.Configure(facts))
+
+	classify, ok := facts[pkgplumbing.FactFileRoles].(pkgplumbing.FileRoleFunc)
+	require.True(t, ok)
+	assert.Equal(t, pkgplumbing.FileRoleTest, classify("pkg/a_test.go", []byte("package pkg")))
+	assert.Equal(t, pkgplumbing.FileRoleSource, classify("pkg/a.go", []byte("package pkg")))
+}
+
+func TestLanguagesDetectionAnalyzer_Roles(t *testing.T) {
+	t.Parallel()
+
+	fromHash := gitlib.NewHash("1111111111111111111111111111111111111111")
+	toHash := gitlib.NewHash("2222222222222222222222222222222222222222")
+	testHash := gitlib.NewHash("3333333333333333333333333333333333333333")
+
+	ld := &LanguagesDetectionAnalyzer{
+		TreeDiff: &TreeDiffAnalyzer{Changes: gitlib.Changes{
+			{
+				Action: gitlib.Modify,
+				From:   gitlib.ChangeEntry{Name: "api.go", Hash: fromHash},
+				To:     gitlib.ChangeEntry{Name: "api.go", Hash: toHash},
+			},
+			{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: "api_test.go", Hash: testHash}},
+		}},
+		BlobCache: &BlobCacheAnalyzer{Cache: map[gitlib.Hash]*gitlib.CachedBlob{
+			fromHash: gitlib.NewCachedBlobForTest([]byte("package api")),
+			toHash:   gitlib.NewCachedBlobForTest([]byte("// Code generated by oapi. DO NOT EDIT.\npackage api")),
+			testHash: gitlib.NewCachedBlobForTest([]byte("package api")),
+		}},
+	}
+
+	assert.Equal(t, map[gitlib.Hash]pkgplumbing.FileRole{
+		fromHash: pkgplumbing.FileRoleSource,
+		toHash:   pkgplumbing.FileRoleGenerated,
+		testHash: pkgplumbing.FileRoleTest,
+	}, ld.Roles())
+	assert.Equal(t, "Go", ld.Languages()[testHash])
 }
 
 func TestLanguagesDetectionAnalyzer_Initialize(t *testing.T) {
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -22,10 +24,20 @@ type LinesStatsCalculator struct {
 	BlobCache *BlobCacheAnalyzer
 	FileDiff  *FileDiffAnalyzer
 
+	// PerHunk also records the statistics of every hunk of modified files.
+	PerHunk bool
+
 	// Output.
 	LineStats map[gitlib.ChangeEntry]pkgplumbing.LineStats
+	// HunkStats holds per-hunk statistics of modified files when PerHunk is set.
+	HunkStats map[gitlib.ChangeEntry][]pkgplumbing.HunkStats
 }
 
+const (
+	// ConfigLinesStatsPerHunk is the configuration key for recording per-hunk line statistics.
+	ConfigLinesStatsPerHunk = "LinesStats.PerHunk"
+)
+
 // Name returns the name of the analyzer.
 func (l *LinesStatsCalculator) Name() string {
 	return "LinesStats"
@@ -52,11 +64,22 @@ func (l *LinesStatsCalculator) Descriptor() analyze.Descriptor {
 
 // ListConfigurationOptions returns the configuration options for the analyzer.
 func (l *LinesStatsCalculator) ListConfigurationOptions() []pipeline.ConfigurationOption {
-	return []pipeline.ConfigurationOption{}
+	return []pipeline.ConfigurationOption{
+		{
+			Name:        ConfigLinesStatsPerHunk,
+			Description: "Record line statistics for every hunk of modified files.",
+			Flag:        "line-stats-per-hunk",
+			Type:        pipeline.BoolConfigurationOption,
+			Default:     false},
+	}
 }
 
 // Configure sets up the analyzer with the provided facts.
-func (l *LinesStatsCalculator) Configure(_ map[string]any) error {
+func (l *LinesStatsCalculator) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigLinesStatsPerHunk].(bool); exists {
+		l.PerHunk = val
+	}
+
 	return nil
 }
 
@@ -68,6 +91,7 @@ func (l *LinesStatsCalculator) Initialize(_ *gitlib.Repository) error {
 // Consume processes a single commit with the provided dependency results.
 func (l *LinesStatsCalculator) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
 	result := map[gitlib.ChangeEntry]pkgplumbing.LineStats{}
+	l.HunkStats = nil
 
 	if ac.IsMerge {
 		l.LineStats = result
@@ -75,6 +99,10 @@ func (l *LinesStatsCalculator) Consume(_ context.Context, ac *analyze.Context) (
 		return analyze.TC{}, nil
 	}
 
+	if l.PerHunk {
+		l.HunkStats = map[gitlib.ChangeEntry][]pkgplumbing.HunkStats{}
+	}
+
 	treeDiff := l.TreeDiff.Changes
 	cache := l.BlobCache.Cache
 	fileDiffs := l.FileDiff.FileDiffs
@@ -86,7 +114,7 @@ func (l *LinesStatsCalculator) Consume(_ context.Context, ac *analyze.Context) (
 		case gitlib.Delete:
 			computeDeleteStats(change, cache, result)
 		case gitlib.Modify:
-			computeModifyStats(change, fileDiffs, result)
+			l.computeModifyStats(change, cache, fileDiffs, result)
 		}
 	}
 
@@ -137,8 +165,9 @@ func computeDeleteStats(
 	}
 }
 
-func computeModifyStats(
-	change *gitlib.Change, fileDiffs map[string]pkgplumbing.FileDiffData,
+func (l *LinesStatsCalculator) computeModifyStats(
+	change *gitlib.Change, cache map[gitlib.Hash]*gitlib.CachedBlob,
+	fileDiffs map[string]pkgplumbing.FileDiffData,
 	result map[gitlib.ChangeEntry]pkgplumbing.LineStats,
 ) {
 	thisDiffs, ok := fileDiffs[change.To.Name]
@@ -146,15 +175,147 @@ func computeModifyStats(
 		return
 	}
 
-	added, removed, changed := computeDiffLineStats(thisDiffs.Diffs)
+	ignoreWhitespace := l.FileDiff != nil && l.FileDiff.IgnoreWhitespaceChanges
 
-	result[change.To] = pkgplumbing.LineStats{
-		Added:   added,
-		Removed: removed,
-		Changed: changed,
+	if !ignoreWhitespace && !l.PerHunk {
+		added, removed, changed := computeDiffLineStats(thisDiffs.Diffs)
+
+		result[change.To] = pkgplumbing.LineStats{
+			Added:   added,
+			Removed: removed,
+			Changed: changed,
+		}
+
+		return
+	}
+
+	var oldLines, newLines []string
+
+	if ignoreWhitespace {
+		oldLines = blobLines(cache[change.From.Hash])
+		newLines = blobLines(cache[change.To.Hash])
+	}
+
+	hunks := computeHunkStats(thisDiffs.Diffs, oldLines, newLines, ignoreWhitespace)
+
+	var total pkgplumbing.LineStats
+
+	for _, h := range hunks {
+		total.Added += h.Stats.Added
+		total.Removed += h.Stats.Removed
+		total.Changed += h.Stats.Changed
+	}
+
+	result[change.To] = total
+
+	if l.PerHunk {
+		l.HunkStats[change.To] = hunks
 	}
 }
 
+// computeHunkStats splits line-level diffs into hunks and computes the statistics
+// of each one the same way computeDiffLineStats does for the whole file, so the
+// hunk totals match the file totals. With ignoreWhitespace, a hunk whose old and
+// new lines are equal once all whitespace is removed counts as unchanged.
+func computeHunkStats(
+	diffs []diffmatchpatch.Diff, oldLines, newLines []string, ignoreWhitespace bool,
+) []pkgplumbing.HunkStats {
+	var (
+		hunks     []pkgplumbing.HunkStats
+		hunk      pkgplumbing.HunkStats
+		oldPos    int
+		newPos    int
+		hunkStart = -1
+	)
+
+	flush := func(end int) {
+		if hunkStart < 0 {
+			return
+		}
+
+		added, removed, changed := computeDiffLineStats(diffs[hunkStart:end])
+		hunk.Stats = pkgplumbing.LineStats{Added: added, Removed: removed, Changed: changed}
+
+		if ignoreWhitespace && whitespaceOnlyHunk(hunk, oldLines, newLines) {
+			hunk.Stats = pkgplumbing.LineStats{}
+			hunk.WhitespaceOnly = true
+		}
+
+		hunks = append(hunks, hunk)
+		hunkStart = -1
+	}
+
+	for i, edit := range diffs {
+		n := utf8.RuneCountInString(edit.Text)
+
+		if edit.Type == diffmatchpatch.DiffEqual {
+			flush(i)
+
+			oldPos += n
+			newPos += n
+
+			continue
+		}
+
+		if hunkStart < 0 {
+			hunkStart = i
+			hunk = pkgplumbing.HunkStats{OldStart: oldPos, NewStart: newPos}
+		}
+
+		if edit.Type == diffmatchpatch.DiffDelete {
+			hunk.OldLines += n
+			oldPos += n
+		} else {
+			hunk.NewLines += n
+			newPos += n
+		}
+	}
+
+	flush(len(diffs))
+
+	return hunks
+}
+
+// whitespaceOnlyHunk reports whether the hunk's old and new lines are equal
+// once all whitespace is removed. Hunks outside the known lines never are.
+func whitespaceOnlyHunk(hunk pkgplumbing.HunkStats, oldLines, newLines []string) bool {
+	if hunk.OldStart+hunk.OldLines > len(oldLines) || hunk.NewStart+hunk.NewLines > len(newLines) {
+		return false
+	}
+
+	return stripAllWhitespace(oldLines[hunk.OldStart:hunk.OldStart+hunk.OldLines]) ==
+		stripAllWhitespace(newLines[hunk.NewStart:hunk.NewStart+hunk.NewLines])
+}
+
+func stripAllWhitespace(lines []string) string {
+	var sb strings.Builder
+
+	for _, line := range lines {
+		for _, r := range line {
+			if !unicode.IsSpace(r) {
+				sb.WriteRune(r)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// blobLines splits a text blob into lines the way the line-level diff does.
+// Returns nil for missing or binary blobs.
+func blobLines(blob *gitlib.CachedBlob) []string {
+	if blob == nil || blob.IsBinary() || len(blob.Data) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(blob.Data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
 func computeDiffLineStats(diffs []diffmatchpatch.Diff) (added, removed, changed int) {
 	var removedPending int
 
@@ -0,0 +1,153 @@
+package plumbing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+const (
+	lineStatsOldSrc = "func a() {\n  return 1\n}\n\nfunc b() {}\n"
+	lineStatsNewSrc = "func a() {\n\treturn 1\n}\n\nfunc b() { c() }\n"
+)
+
+// lineDiffs diffs two sources line by line, as FileDiffAnalyzer does.
+func lineDiffs(from, to string) []diffmatchpatch.Diff {
+	dmp := diffmatchpatch.New()
+	src, dst, _ := dmp.DiffLinesToRunes(from, to)
+
+	return dmp.DiffMainRunes(src, dst, false)
+}
+
+// newModifyLineStats builds a LinesStatsCalculator fed with one modified file.
+func newModifyLineStats(from, to string) (*LinesStatsCalculator, gitlib.ChangeEntry) {
+	fromHash := gitlib.NewHash("1111111111111111111111111111111111111111")
+	toHash := gitlib.NewHash("2222222222222222222222222222222222222222")
+	entry := gitlib.ChangeEntry{Name: "main.go", Hash: toHash}
+
+	change := &gitlib.Change{
+		Action: gitlib.Modify,
+		From:   gitlib.ChangeEntry{Name: "main.go", Hash: fromHash},
+		To:     entry,
+	}
+
+	ls := &LinesStatsCalculator{
+		TreeDiff: &TreeDiffAnalyzer{Changes: gitlib.Changes{change}},
+		BlobCache: &BlobCacheAnalyzer{Cache: map[gitlib.Hash]*gitlib.CachedBlob{
+			fromHash: gitlib.NewCachedBlobForTest([]byte(from)),
+			toHash:   gitlib.NewCachedBlobForTest([]byte(to)),
+		}},
+		FileDiff: &FileDiffAnalyzer{FileDiffs: map[string]pkgplumbing.FileDiffData{
+			"main.go": {Diffs: lineDiffs(from, to)},
+		}},
+	}
+
+	return ls, entry
+}
+
+func TestLinesStatsCalculator_CountsWhitespaceByDefault(t *testing.T) {
+	t.Parallel()
+
+	ls, entry := newModifyLineStats(lineStatsOldSrc, lineStatsNewSrc)
+
+	_, err := ls.Consume(context.Background(), &analyze.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, pkgplumbing.LineStats{Changed: 2}, ls.LineStats[entry])
+	assert.Nil(t, ls.HunkStats)
+}
+
+func TestLinesStatsCalculator_IgnoreWhitespace(t *testing.T) {
+	t.Parallel()
+
+	ls, entry := newModifyLineStats(lineStatsOldSrc, lineStatsNewSrc)
+	require.NoError(t, ls.FileDiff.Configure(map[string]any{ConfigFileDiffIgnoreWhitespaceChanges: true}))
+
+	_, err := ls.Consume(context.Background(), &analyze.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, pkgplumbing.LineStats{Changed: 1}, ls.LineStats[entry])
+}
+
+func TestLinesStatsCalculator_PerHunk(t *testing.T) {
+	t.Parallel()
+
+	ls, entry := newModifyLineStats(lineStatsOldSrc, lineStatsNewSrc)
+	require.NoError(t, ls.Configure(map[string]any{ConfigLinesStatsPerHunk: true}))
+	require.NoError(t, ls.FileDiff.Configure(map[string]any{ConfigFileDiffIgnoreWhitespaceChanges: true}))
+
+	_, err := ls.Consume(context.Background(), &analyze.Context{})
+	require.NoError(t, err)
+
+	hunks := ls.HunkStats[entry]
+	require.Len(t, hunks, 2)
+
+	assert.Equal(t, pkgplumbing.HunkStats{OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1, WhitespaceOnly: true}, hunks[0])
+	assert.Equal(t, 4, hunks[1].OldStart)
+	assert.Equal(t, pkgplumbing.LineStats{Changed: 1}, hunks[1].Stats)
+	assert.False(t, hunks[1].WhitespaceOnly)
+}
+
+func TestLinesStatsCalculator_MergeClearsHunkStats(t *testing.T) {
+	t.Parallel()
+
+	ls, _ := newModifyLineStats(lineStatsOldSrc, lineStatsNewSrc)
+	ls.PerHunk = true
+
+	_, err := ls.Consume(context.Background(), &analyze.Context{})
+	require.NoError(t, err)
+	require.NotEmpty(t, ls.HunkStats)
+
+	_, err = ls.Consume(context.Background(), &analyze.Context{IsMerge: true})
+	require.NoError(t, err)
+	assert.Nil(t, ls.HunkStats)
+}
+
+func TestComputeHunkStats_TotalsMatchFileStats(t *testing.T) {
+	t.Parallel()
+
+	diffs := lineDiffs("a\nb\nc\nd\ne\n", "a\nB\nc\nd\nx\ny\n")
+
+	added, removed, changed := computeDiffLineStats(diffs)
+
+	var total pkgplumbing.LineStats
+
+	for _, h := range computeHunkStats(diffs, nil, nil, false) {
+		total.Added += h.Stats.Added
+		total.Removed += h.Stats.Removed
+		total.Changed += h.Stats.Changed
+	}
+
+	assert.Equal(t, pkgplumbing.LineStats{Added: added, Removed: removed, Changed: changed}, total)
+}
+
+func TestLinesStatsCalculator_NormalizeEOL(t *testing.T) {
+	t.Parallel()
+
+	lf := "a\nb\nc\n"
+	crlf := "a\r\nb\r\nc\r\n"
+
+	for _, tt := range []struct {
+		normalize bool
+		want      pkgplumbing.LineStats
+	}{
+		{normalize: false, want: pkgplumbing.LineStats{Changed: 3}},
+		{normalize: true, want: pkgplumbing.LineStats{}},
+	} {
+		ls, entry := newModifyLineStats(lf, crlf)
+		ls.FileDiff = &FileDiffAnalyzer{TreeDiff: ls.TreeDiff, BlobCache: ls.BlobCache, Goroutines: 1}
+		require.NoError(t, ls.FileDiff.Configure(map[string]any{ConfigFileDiffNormalizeEOL: tt.normalize}))
+
+		_, err := ls.FileDiff.Consume(context.Background(), &analyze.Context{})
+		require.NoError(t, err)
+
+		_, err = ls.Consume(context.Background(), &analyze.Context{})
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, ls.LineStats[entry], "normalize=%v", tt.normalize)
+	}
+}
@@ -0,0 +1,118 @@
+package plumbing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// MessageLanguageDetector detects the natural language of commit messages.
+type MessageLanguageDetector struct {
+	// Language is the ISO 639-1 code of the current commit's message, or
+	// pkgplumbing.MessageLanguageUnknown.
+	Language string
+}
+
+// Name returns the name of the analyzer.
+func (m *MessageLanguageDetector) Name() string {
+	return "MessageLanguageDetector"
+}
+
+// Flag returns the CLI flag for the analyzer.
+func (m *MessageLanguageDetector) Flag() string {
+	return "detect-message-language"
+}
+
+// Description returns a human-readable description of the analyzer.
+func (m *MessageLanguageDetector) Description() string {
+	return m.Descriptor().Description
+}
+
+// Descriptor returns stable analyzer metadata.
+func (m *MessageLanguageDetector) Descriptor() analyze.Descriptor {
+	return analyze.NewDescriptor(
+		analyze.ModeHistory,
+		m.Name(),
+		"Detects the natural language each commit message is written in.",
+	)
+}
+
+// ListConfigurationOptions returns the configuration options for the analyzer.
+func (m *MessageLanguageDetector) ListConfigurationOptions() []pipeline.ConfigurationOption {
+	return []pipeline.ConfigurationOption{}
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (m *MessageLanguageDetector) Configure(_ map[string]any) error {
+	return nil
+}
+
+// Initialize prepares the analyzer for processing commits.
+func (m *MessageLanguageDetector) Initialize(_ *gitlib.Repository) error {
+	return nil
+}
+
+// Consume detects the language of the commit's message.
+func (m *MessageLanguageDetector) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	m.Language = pkgplumbing.MessageLanguageUnknown
+
+	if ac != nil && ac.Commit != nil {
+		m.Language = pkgplumbing.DetectMessageLanguage(ac.Commit.Message())
+	}
+
+	return analyze.TC{}, nil
+}
+
+// Fork creates a copy of the analyzer for parallel processing.
+func (m *MessageLanguageDetector) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+	for i := range n {
+		clone := *m
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge combines results from forked analyzer branches.
+func (m *MessageLanguageDetector) Merge(_ []analyze.HistoryAnalyzer) {
+}
+
+// Serialize writes the analysis result to the given writer.
+func (m *MessageLanguageDetector) Serialize(report analyze.Report, format string, writer io.Writer) error {
+	if format == analyze.FormatJSON {
+		err := json.NewEncoder(writer).Encode(report)
+		if err != nil {
+			return fmt.Errorf("json encode: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WorkingStateSize returns 0 — plumbing analyzers are excluded from budget planning.
+func (m *MessageLanguageDetector) WorkingStateSize() int64 { return 0 }
+
+// AvgTCSize returns 0 — plumbing analyzers do not emit meaningful TC payloads.
+func (m *MessageLanguageDetector) AvgTCSize() int64 { return 0 }
+
+// NewAggregator returns nil — plumbing analyzers do not aggregate.
+func (m *MessageLanguageDetector) NewAggregator(_ analyze.AggregatorOptions) analyze.Aggregator {
+	return nil
+}
+
+// SerializeTICKs returns ErrNotImplemented — plumbing analyzers do not produce TICKs.
+func (m *MessageLanguageDetector) SerializeTICKs(_ []analyze.TICK, _ string, _ io.Writer) error {
+	return analyze.ErrNotImplemented
+}
+
+// ReportFromTICKs returns ErrNotImplemented — plumbing analyzers do not produce reports.
+func (m *MessageLanguageDetector) ReportFromTICKs(_ context.Context, _ []analyze.TICK) (analyze.Report, error) {
+	return nil, analyze.ErrNotImplemented
+}
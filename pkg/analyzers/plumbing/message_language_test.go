@@ -0,0 +1,34 @@
+package plumbing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestMessageLanguageDetector_Consume(t *testing.T) {
+	t.Parallel()
+
+	m := &MessageLanguageDetector{}
+	require.NoError(t, m.Initialize(nil))
+
+	commit := gitlib.NewTestCommit(
+		gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+		gitlib.TestSignature("dev", "dev@test.com"),
+		"Fehler beim Laden der Konfiguration behoben",
+	)
+
+	_, err := m.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	assert.Equal(t, "de", m.Language)
+
+	_, err = m.Consume(context.Background(), &analyze.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, pkgplumbing.MessageLanguageUnknown, m.Language)
+}
@@ -0,0 +1,102 @@
+package plumbing
+
+import (
+	"path"
+	"strings"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// ModeChangeKind classifies a file mode change.
+type ModeChangeKind string
+
+// Kinds of a ModeChange.
+const (
+	// ModeExecutableAdded is a new executable file or a file that gained the
+	// executable bit.
+	ModeExecutableAdded ModeChangeKind = "executable_added"
+	// ModeExecutableRemoved is a file that lost the executable bit.
+	ModeExecutableRemoved ModeChangeKind = "executable_removed"
+	// ModeSymlinkAdded is a new symbolic link or a file replaced by one.
+	ModeSymlinkAdded ModeChangeKind = "symlink_added"
+)
+
+// ModeChange is a change of the mode of one file that a commit made.
+type ModeChange struct {
+	File string         `json:"file" yaml:"file"`
+	Kind ModeChangeKind `json:"kind" yaml:"kind"`
+	// From is 0 for new files.
+	From uint16 `json:"from" yaml:"from"`
+	To   uint16 `json:"to"   yaml:"to"`
+	// Suspicious marks executables with the extension of a data or document
+	// format. Git has no setuid bit, so an executable bit where no program is
+	// expected is the closest pattern worth a review.
+	Suspicious bool `json:"suspicious" yaml:"suspicious"`
+}
+
+// dataExtensions are the extensions of files that are never run directly.
+var dataExtensions = map[string]bool{
+	".md": true, ".txt": true, ".rst": true, ".adoc": true, ".pdf": true,
+	".json": true, ".yaml": true, ".yml": true, ".toml": true, ".xml": true, ".csv": true, ".lock": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".ico": true, ".webp": true,
+	".html": true, ".htm": true, ".css": true, ".scss": true,
+	".go": true, ".c": true, ".h": true, ".cc": true, ".cpp": true, ".hpp": true, ".java": true, ".rs": true, ".cs": true,
+	".ts": true, ".tsx": true, ".jsx": true,
+}
+
+// ModeChanges returns the mode changes of the current commit. Entries without
+// a mode, as produced by sources that do not record one, are ignored.
+func (t *TreeDiffAnalyzer) ModeChanges() []ModeChange {
+	return DetectModeChanges(t.Changes)
+}
+
+// DetectModeChanges returns the new executables, executable bit changes and
+// new symbolic links of changes. Deleted files are not reported.
+func DetectModeChanges(changes gitlib.Changes) []ModeChange {
+	var result []ModeChange
+
+	for _, change := range changes {
+		var from gitlib.ChangeEntry
+
+		switch change.Action {
+		case gitlib.Insert:
+		case gitlib.Modify:
+			from = change.From
+		case gitlib.Delete:
+			continue
+		}
+
+		to := change.To
+		if to.Mode == 0 || (change.Action == gitlib.Modify && (from.Mode == 0 || from.Mode == to.Mode)) {
+			continue
+		}
+
+		kind, ok := modeChangeKind(from, to)
+		if !ok {
+			continue
+		}
+
+		result = append(result, ModeChange{
+			File:       to.Name,
+			Kind:       kind,
+			From:       from.Mode,
+			To:         to.Mode,
+			Suspicious: kind == ModeExecutableAdded && dataExtensions[strings.ToLower(path.Ext(to.Name))],
+		})
+	}
+
+	return result
+}
+
+func modeChangeKind(from, to gitlib.ChangeEntry) (ModeChangeKind, bool) {
+	switch {
+	case to.IsSymlink() && !from.IsSymlink():
+		return ModeSymlinkAdded, true
+	case to.IsExecutable() && !from.IsExecutable():
+		return ModeExecutableAdded, true
+	case from.IsExecutable() && !to.IsExecutable():
+		return ModeExecutableRemoved, true
+	default:
+		return "", false
+	}
+}
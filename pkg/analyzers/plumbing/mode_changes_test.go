@@ -0,0 +1,44 @@
+package plumbing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func TestDetectModeChanges(t *testing.T) {
+	t.Parallel()
+
+	entry := func(name string, mode uint16) gitlib.ChangeEntry {
+		return gitlib.ChangeEntry{Name: name, Mode: mode}
+	}
+
+	changes := gitlib.Changes{
+		{Action: gitlib.Insert, To: entry("build.sh", gitlib.FileModeExecutable)},
+		{Action: gitlib.Insert, To: entry("main.go", gitlib.FileModeRegular)},
+		{Action: gitlib.Insert, To: entry("current", gitlib.FileModeSymlink)},
+		{Action: gitlib.Modify, From: entry("config.JSON", gitlib.FileModeRegular), To: entry("config.JSON", gitlib.FileModeExecutable)},
+		{Action: gitlib.Modify, From: entry("tool", gitlib.FileModeExecutable), To: entry("tool", gitlib.FileModeRegular)},
+		{Action: gitlib.Modify, From: entry("run", gitlib.FileModeExecutable), To: entry("bin/run", gitlib.FileModeExecutable)},
+		{Action: gitlib.Delete, From: entry("old.sh", gitlib.FileModeExecutable)},
+		// Sources that do not record modes leave them zero.
+		{Action: gitlib.Insert, To: entry("unknown", 0)},
+		{Action: gitlib.Modify, From: entry("legacy", 0), To: entry("legacy", gitlib.FileModeExecutable)},
+	}
+
+	assert.Equal(t, []ModeChange{
+		{File: "build.sh", Kind: ModeExecutableAdded, To: gitlib.FileModeExecutable},
+		{File: "current", Kind: ModeSymlinkAdded, To: gitlib.FileModeSymlink},
+		{
+			File: "config.JSON", Kind: ModeExecutableAdded,
+			From: gitlib.FileModeRegular, To: gitlib.FileModeExecutable, Suspicious: true,
+		},
+		{File: "tool", Kind: ModeExecutableRemoved, From: gitlib.FileModeExecutable, To: gitlib.FileModeRegular},
+	}, DetectModeChanges(changes))
+
+	td := &TreeDiffAnalyzer{Changes: changes[:1]}
+	assert.Len(t, td.ModeChanges(), 1)
+	assert.Empty(t, DetectModeChanges(nil))
+}
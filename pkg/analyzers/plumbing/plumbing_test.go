@@ -3,10 +3,12 @@ package plumbing
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 func TestTreeDiffAnalyzer_Name(t *testing.T) {
@@ -68,6 +70,27 @@ func TestBlobCacheAnalyzer_Configure(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestBlobCacheAnalyzer_ConfigureResolveLFS(t *testing.T) {
+	t.Parallel()
+
+	bc := &BlobCacheAnalyzer{}
+	require.NoError(t, bc.Configure(map[string]any{ConfigBlobCacheResolveLFS: true}))
+	require.True(t, bc.ResolveLFS)
+}
+
+func TestBlobCacheAnalyzer_ConfigureTextEncoding(t *testing.T) {
+	t.Parallel()
+
+	bc := &BlobCacheAnalyzer{}
+	require.NoError(t, bc.Configure(map[string]any{ConfigBlobCacheTextEncoding: "Windows-1252"}))
+	require.Equal(t, gitlib.TextEncoding{Force: gitlib.EncodingWindows1252}, bc.TextEncoding)
+
+	require.NoError(t, bc.Configure(map[string]any{ConfigBlobCacheTextEncoding: "off"}))
+	require.True(t, bc.TextEncoding.Off)
+
+	require.ErrorIs(t, bc.Configure(map[string]any{ConfigBlobCacheTextEncoding: "klingon"}), gitlib.ErrUnknownEncoding)
+}
+
 func TestFileDiffAnalyzer_Name(t *testing.T) {
 	t.Parallel()
 
@@ -77,6 +100,17 @@ func TestFileDiffAnalyzer_Name(t *testing.T) {
 	}
 }
 
+func TestFileDiffAnalyzer_ConfigureAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	fd := &FileDiffAnalyzer{}
+	require.NoError(t, fd.Configure(map[string]any{ConfigFileDiffAlgorithm: "patience"}))
+	require.Equal(t, gitlib.DiffPatience, fd.Algorithm)
+
+	err := fd.Configure(map[string]any{ConfigFileDiffAlgorithm: "minimal"})
+	require.ErrorIs(t, err, gitlib.ErrUnknownDiffAlgorithm)
+}
+
 func TestLinesStatsCalculator_Name(t *testing.T) {
 	t.Parallel()
 
@@ -104,6 +138,45 @@ func TestTicksSinceStart_Name(t *testing.T) {
 	}
 }
 
+func TestTicksSinceStart_Configure_CalendarGranularity(t *testing.T) {
+	t.Parallel()
+
+	ts := &TicksSinceStart{}
+	facts := map[string]any{ConfigTicksSinceStartGranularity: "month"}
+
+	require.NoError(t, ts.Configure(facts))
+	require.Equal(t, pkgplumbing.TickMonth, facts[pkgplumbing.FactTickGranularity])
+	require.Equal(t, pkgplumbing.TickMonth.NominalTickSize(), facts[pkgplumbing.FactTickSize])
+
+	cal, ok := facts[pkgplumbing.FactTickCalendar].(*pkgplumbing.TickCalendar)
+	require.True(t, ok)
+	require.Equal(t, pkgplumbing.TickMonth, cal.Granularity)
+
+	// Reconfiguring with the published facts keeps the same calendar.
+	require.NoError(t, ts.Configure(facts))
+	require.Same(t, cal, facts[pkgplumbing.FactTickCalendar])
+}
+
+func TestTicksSinceStart_Configure_FixedByDefault(t *testing.T) {
+	t.Parallel()
+
+	ts := &TicksSinceStart{}
+	facts := map[string]any{}
+
+	require.NoError(t, ts.Configure(facts))
+	require.Equal(t, DefaultTicksSinceStartTickSize*time.Hour, facts[pkgplumbing.FactTickSize])
+	require.NotContains(t, facts, pkgplumbing.FactTickCalendar)
+}
+
+func TestTicksSinceStart_Configure_InvalidGranularity(t *testing.T) {
+	t.Parallel()
+
+	ts := &TicksSinceStart{}
+
+	err := ts.Configure(map[string]any{ConfigTicksSinceStartGranularity: "fortnight"})
+	require.ErrorIs(t, err, pkgplumbing.ErrUnknownTickGranularity)
+}
+
 func TestUASTChangesAnalyzer_Name(t *testing.T) {
 	t.Parallel()
 
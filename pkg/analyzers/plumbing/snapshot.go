@@ -18,12 +18,24 @@ type Snapshot struct {
 	BlobCache map[gitlib.Hash]*gitlib.CachedBlob
 	FileDiffs map[string]pkgplumbing.FileDiffData
 	LineStats map[gitlib.ChangeEntry]pkgplumbing.LineStats
+	// HunkStats holds the per-hunk line statistics of modified files, nil
+	// unless they are enabled.
+	HunkStats map[gitlib.ChangeEntry][]pkgplumbing.HunkStats
 	Languages map[gitlib.Hash]string
-	Tick      int
-	AuthorID  int
+	// Roles holds the file roles detected with Languages.
+	Roles    map[gitlib.Hash]pkgplumbing.FileRole
+	Tick     int
+	AuthorID int
+	// CoAuthorIDs holds the Co-authored-by identities of the commit.
+	CoAuthorIDs []int
+	// MessageLanguage is the detected language of the commit message.
+	MessageLanguage string
 	// UASTChanges ownership is transferred to the snapshot.
 	// The consumer must call ReleaseSnapshotUAST to free UAST trees.
 	UASTChanges []uast.Change
+	// Custom holds the per-commit output of custom plumbing analyzers, keyed
+	// by analyzer name.
+	Custom map[string]any
 }
 
 // Clone creates a shallow clone of the snapshot's reference types (maps and slices),
@@ -31,14 +43,19 @@ type Snapshot struct {
 // immutable git objects or UAST nodes.
 func (s Snapshot) Clone() Snapshot {
 	clone := Snapshot{
-		Tick:     s.Tick,
-		AuthorID: s.AuthorID,
+		Tick:            s.Tick,
+		AuthorID:        s.AuthorID,
+		MessageLanguage: s.MessageLanguage,
 	}
 
 	if s.Changes != nil {
 		clone.Changes = slices.Clone(s.Changes)
 	}
 
+	if s.CoAuthorIDs != nil {
+		clone.CoAuthorIDs = slices.Clone(s.CoAuthorIDs)
+	}
+
 	if s.BlobCache != nil {
 		clone.BlobCache = maps.Clone(s.BlobCache)
 	}
@@ -51,14 +68,26 @@ func (s Snapshot) Clone() Snapshot {
 		clone.LineStats = maps.Clone(s.LineStats)
 	}
 
+	if s.HunkStats != nil {
+		clone.HunkStats = maps.Clone(s.HunkStats)
+	}
+
 	if s.Languages != nil {
 		clone.Languages = maps.Clone(s.Languages)
 	}
 
+	if s.Roles != nil {
+		clone.Roles = maps.Clone(s.Roles)
+	}
+
 	if s.UASTChanges != nil {
 		clone.UASTChanges = slices.Clone(s.UASTChanges)
 	}
 
+	if s.Custom != nil {
+		clone.Custom = maps.Clone(s.Custom)
+	}
+
 	return clone
 }
 
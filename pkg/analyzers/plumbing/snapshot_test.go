@@ -37,6 +37,7 @@ func TestSnapshot_Clone(t *testing.T) {
 			h1: "Go",
 		},
 		UASTChanges: []uast.Change{u1},
+		Custom:      map[string]any{"jira": []string{"CF-1"}},
 	}
 
 	clone := s.Clone()
@@ -70,4 +71,8 @@ func TestSnapshot_Clone(t *testing.T) {
 	assert.Equal(t, s.UASTChanges, clone.UASTChanges)
 	clone.UASTChanges[0].Change = &gitlib.Change{}
 	assert.Nil(t, s.UASTChanges[0].Change)
+
+	assert.Equal(t, s.Custom, clone.Custom)
+	clone.Custom["owners"] = nil
+	assert.Len(t, s.Custom, 1)
 }
@@ -18,7 +18,9 @@ type TicksSinceStart struct {
 	tick0        *time.Time
 	commits      map[int][]gitlib.Hash
 	remote       string
+	calendar     *pkgplumbing.TickCalendar // nil unless Granularity is a calendar period.
 	TickSize     time.Duration
+	Granularity  pkgplumbing.TickGranularity
 	previousTick int
 	Tick         int
 }
@@ -28,6 +30,8 @@ const (
 	ConfigTicksSinceStartTickSize = "TicksSinceStart.TickSize"
 	// DefaultTicksSinceStartTickSize is the default tick size in hours.
 	DefaultTicksSinceStartTickSize = 24
+	// ConfigTicksSinceStartGranularity is the configuration key for calendar-aligned tick bucketing.
+	ConfigTicksSinceStartGranularity = "TicksSinceStart.Granularity"
 )
 
 // Name returns the name of the analyzer.
@@ -61,8 +65,15 @@ func (t *TicksSinceStart) ListConfigurationOptions() []pipeline.ConfigurationOpt
 		Description: "How long each 'tick' represents in hours.",
 		Flag:        "tick-size",
 		Type:        pipeline.IntConfigurationOption,
-		Default:     DefaultTicksSinceStartTickSize},
-	}
+		Default:     DefaultTicksSinceStartTickSize,
+	}, {
+		Name: ConfigTicksSinceStartGranularity,
+		Description: "Bucket commits by calendar period instead of fixed ticks: " +
+			"fixed, week (ISO), month or quarter. Calendar periods override tick-size.",
+		Flag:    "tick-granularity",
+		Type:    pipeline.StringConfigurationOption,
+		Default: string(pkgplumbing.TickFixed),
+	}}
 }
 
 // Configure sets up the analyzer with the provided facts.
@@ -73,16 +84,49 @@ func (t *TicksSinceStart) Configure(facts map[string]any) error {
 		t.TickSize = DefaultTicksSinceStartTickSize * time.Hour
 	}
 
+	granularity, err := pkgplumbing.ParseTickGranularity(stringFact(facts, ConfigTicksSinceStartGranularity))
+	if err != nil {
+		return err
+	}
+
+	t.Granularity = granularity
+
+	if granularity.Calendar() {
+		t.TickSize = granularity.NominalTickSize()
+
+		if t.calendar == nil {
+			t.calendar = &pkgplumbing.TickCalendar{}
+		}
+
+		t.calendar.Granularity = granularity
+		facts[pkgplumbing.FactTickCalendar] = t.calendar
+	} else {
+		t.calendar = nil
+	}
+
 	if t.commits == nil {
 		t.commits = map[int][]gitlib.Hash{}
 	}
 
 	facts[pkgplumbing.FactCommitsByTick] = t.commits
 	facts[pkgplumbing.FactTickSize] = t.TickSize
+	facts[pkgplumbing.FactTickGranularity] = t.Granularity
 
 	return nil
 }
 
+// stringFact returns the string or [pkgplumbing.TickGranularity] fact under key, or "".
+func stringFact(facts map[string]any, key string) string {
+	switch v := facts[key].(type) {
+	case string:
+		return v
+	case pkgplumbing.TickGranularity:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
 // Initialize prepares the analyzer for processing commits.
 func (t *TicksSinceStart) Initialize(_ *gitlib.Repository) error {
 	if t.TickSize == 0 {
@@ -109,9 +153,13 @@ func (t *TicksSinceStart) Consume(_ context.Context, ac *analyze.Context) (analy
 	if index == 0 {
 		tick0 := commit.Committer().When
 		*t.tick0 = FloorTime(tick0, t.TickSize)
+
+		if t.calendar != nil {
+			t.calendar.Start = t.calendar.Floor(tick0)
+		}
 	}
 
-	tick := max(int(commit.Committer().When.Sub(*t.tick0)/t.TickSize), t.previousTick)
+	tick := max(t.tickOf(commit.Committer().When), t.previousTick)
 
 	t.previousTick = tick
 
@@ -142,6 +190,16 @@ func (t *TicksSinceStart) Consume(_ context.Context, ac *analyze.Context) (analy
 	return analyze.TC{}, nil
 }
 
+// tickOf returns the tick containing when: a calendar period index when a
+// calendar granularity is configured, otherwise whole TickSizes since tick0.
+func (t *TicksSinceStart) tickOf(when time.Time) int {
+	if t.calendar != nil {
+		return t.calendar.Tick(when)
+	}
+
+	return int(when.Sub(*t.tick0) / t.TickSize)
+}
+
 // FloorTime rounds a timestamp down to the nearest tick boundary.
 func FloorTime(t time.Time, d time.Duration) time.Time {
 	result := t.Round(d)
@@ -0,0 +1,154 @@
+package plumbing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+)
+
+// TrailersExtractor extracts the trailers (Signed-off-by, Co-authored-by,
+// Reviewed-by and the like) and the git note of each commit.
+type TrailersExtractor struct {
+	// Trailers holds the trailers of the current commit's message.
+	Trailers []gitlib.Trailer
+	// Note is the current commit's note under the default notes reference,
+	// without its trailing newline, or "".
+	Note string
+
+	repository *gitlib.Repository
+	notesRef   string
+}
+
+// Name returns the name of the analyzer.
+func (e *TrailersExtractor) Name() string {
+	return "TrailersExtractor"
+}
+
+// Flag returns the CLI flag for the analyzer.
+func (e *TrailersExtractor) Flag() string {
+	return "extract-trailers"
+}
+
+// Description returns a human-readable description of the analyzer.
+func (e *TrailersExtractor) Description() string {
+	return e.Descriptor().Description
+}
+
+// Descriptor returns stable analyzer metadata.
+func (e *TrailersExtractor) Descriptor() analyze.Descriptor {
+	return analyze.NewDescriptor(
+		analyze.ModeHistory,
+		e.Name(),
+		"Extracts the trailers and git notes of each commit.",
+	)
+}
+
+// ListConfigurationOptions returns the configuration options for the analyzer.
+func (e *TrailersExtractor) ListConfigurationOptions() []pipeline.ConfigurationOption {
+	return []pipeline.ConfigurationOption{}
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (e *TrailersExtractor) Configure(_ map[string]any) error {
+	return nil
+}
+
+// Initialize resolves the notes reference of the repository. Notes are not
+// read when the reference does not exist.
+func (e *TrailersExtractor) Initialize(repository *gitlib.Repository) error {
+	e.repository = nil
+	e.notesRef = ""
+
+	if repository == nil {
+		return nil
+	}
+
+	ref, err := repository.NotesRef()
+	if err != nil {
+		return err
+	}
+
+	if repository.HasNotes(ref) {
+		e.repository = repository
+		e.notesRef = ref
+	}
+
+	return nil
+}
+
+// Consume extracts the trailers and the note of the commit.
+func (e *TrailersExtractor) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	e.Trailers = nil
+	e.Note = ""
+
+	if ac == nil || ac.Commit == nil {
+		return analyze.TC{}, nil
+	}
+
+	e.Trailers = gitlib.ParseTrailers(ac.Commit.Message())
+
+	if e.repository != nil {
+		note, err := e.repository.Note(e.notesRef, ac.Commit.Hash())
+		if err != nil {
+			return analyze.TC{}, err
+		}
+
+		e.Note = strings.TrimRight(note, "\n")
+	}
+
+	return analyze.TC{}, nil
+}
+
+// Fork creates a copy of the analyzer for parallel processing.
+func (e *TrailersExtractor) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+	for i := range n {
+		clone := *e
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge combines results from forked analyzer branches.
+func (e *TrailersExtractor) Merge(_ []analyze.HistoryAnalyzer) {
+}
+
+// Serialize writes the analysis result to the given writer.
+func (e *TrailersExtractor) Serialize(report analyze.Report, format string, writer io.Writer) error {
+	if format == analyze.FormatJSON {
+		err := json.NewEncoder(writer).Encode(report)
+		if err != nil {
+			return fmt.Errorf("json encode: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WorkingStateSize returns 0 — plumbing analyzers are excluded from budget planning.
+func (e *TrailersExtractor) WorkingStateSize() int64 { return 0 }
+
+// AvgTCSize returns 0 — plumbing analyzers do not emit meaningful TC payloads.
+func (e *TrailersExtractor) AvgTCSize() int64 { return 0 }
+
+// NewAggregator returns nil — plumbing analyzers do not aggregate.
+func (e *TrailersExtractor) NewAggregator(_ analyze.AggregatorOptions) analyze.Aggregator {
+	return nil
+}
+
+// SerializeTICKs returns ErrNotImplemented — plumbing analyzers do not produce TICKs.
+func (e *TrailersExtractor) SerializeTICKs(_ []analyze.TICK, _ string, _ io.Writer) error {
+	return analyze.ErrNotImplemented
+}
+
+// ReportFromTICKs returns ErrNotImplemented — plumbing analyzers do not produce reports.
+func (e *TrailersExtractor) ReportFromTICKs(_ context.Context, _ []analyze.TICK) (analyze.Report, error) {
+	return nil, analyze.ErrNotImplemented
+}
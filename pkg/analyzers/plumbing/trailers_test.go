@@ -0,0 +1,95 @@
+package plumbing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+const coAuthoredMessage = "Pair on the parser\n\n" +
+	"Co-authored-by: Bob <bob@example.com>\n" +
+	"Co-authored-by: Alice Again <alice@example.com>\n" +
+	"Signed-off-by: Alice <alice@example.com>\n"
+
+func TestTrailersExtractor_Consume(t *testing.T) {
+	t.Parallel()
+
+	e := &TrailersExtractor{}
+	require.NoError(t, e.Initialize(nil))
+
+	commit := gitlib.NewTestCommit(
+		gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+		gitlib.TestSignature("Alice", "alice@example.com"),
+		coAuthoredMessage,
+	)
+
+	_, err := e.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	require.Len(t, e.Trailers, 3)
+	assert.Equal(t, gitlib.Trailer{Key: "Co-authored-by", Value: "Bob <bob@example.com>"}, e.Trailers[0])
+	assert.Empty(t, e.Note)
+
+	_, err = e.Consume(context.Background(), &analyze.Context{})
+	require.NoError(t, err)
+	assert.Nil(t, e.Trailers)
+}
+
+func TestIdentityDetector_CoAuthors(t *testing.T) {
+	t.Parallel()
+
+	d := &IdentityDetector{}
+	require.NoError(t, d.Initialize(nil))
+
+	commit := gitlib.NewTestCommit(
+		gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+		gitlib.TestSignature("Alice", "alice@example.com"),
+		coAuthoredMessage,
+	)
+
+	_, err := d.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+
+	// The second co-author shares the author's email and is the author.
+	bob := d.PeopleDict["bob@example.com"]
+	assert.Equal(t, []int{bob}, d.CoAuthorIDs)
+	assert.NotEqual(t, d.AuthorID, bob)
+
+	solo := gitlib.NewTestCommit(
+		gitlib.NewHash("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"),
+		gitlib.TestSignature("Bob", "bob@example.com"),
+		"Solo work",
+	)
+
+	_, err = d.Consume(context.Background(), &analyze.Context{Commit: solo})
+	require.NoError(t, err)
+	assert.Equal(t, bob, d.AuthorID)
+	assert.Nil(t, d.CoAuthorIDs)
+}
+
+func TestIdentityDetector_CoAuthorsFinalizedDict(t *testing.T) {
+	t.Parallel()
+
+	d := &IdentityDetector{
+		PeopleDict:         map[string]int{"alice@example.com": 0, "alice": 0},
+		ReversedPeopleDict: []string{"alice"},
+	}
+	require.NoError(t, d.Initialize(nil))
+
+	commit := gitlib.NewTestCommit(
+		gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+		gitlib.TestSignature("Alice", "alice@example.com"),
+		coAuthoredMessage,
+	)
+
+	_, err := d.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+
+	// Bob is not in the dictionary, so no one else shares the credit.
+	assert.Equal(t, 0, d.AuthorID)
+	assert.Empty(t, d.CoAuthorIDs)
+}
@@ -154,24 +154,13 @@ func (c *UASTChangesAnalyzer) changesSequential(
 		after := c.parseAfterVersion(ctx, change, cache)
 
 		if before != nil || after != nil {
-			result = append(result, uast.Change{
-				Before: before,
-				After:  after,
-				Change: change,
-			})
+			result = append(result, uast.NewChange(before, after, change))
 		}
 	}
 
 	return result
 }
 
-// uastParseResult holds the result of parsing a single file change.
-type uastParseResult struct {
-	before *node.Node
-	after  *node.Node
-	change *gitlib.Change
-}
-
 // changesParallel parses UAST changes across multiple goroutines.
 // Each file's before/after parsing is independent and thread-safe.
 func (c *UASTChangesAnalyzer) changesParallel(
@@ -180,7 +169,7 @@ func (c *UASTChangesAnalyzer) changesParallel(
 	cache map[gitlib.Hash]*gitlib.CachedBlob,
 ) []uast.Change {
 	jobs := make(chan *gitlib.Change, len(treeChanges))
-	results := make(chan uastParseResult, len(treeChanges))
+	results := make(chan uast.Change, len(treeChanges))
 
 	var wg sync.WaitGroup
 
@@ -195,7 +184,7 @@ func (c *UASTChangesAnalyzer) changesParallel(
 				after := c.parseAfterVersion(ctx, change, cache)
 
 				if before != nil || after != nil {
-					results <- uastParseResult{before, after, change}
+					results <- uast.NewChange(before, after, change)
 				}
 			}
 		}()
@@ -211,12 +200,8 @@ func (c *UASTChangesAnalyzer) changesParallel(
 
 	var changes []uast.Change
 
-	for r := range results {
-		changes = append(changes, uast.Change{
-			Before: r.before,
-			After:  r.after,
-			Change: r.change,
-		})
+	for change := range results {
+		changes = append(changes, change)
 	}
 
 	return changes
@@ -0,0 +1,384 @@
+// Package policy scans the lines every commit adds against regex policy packs
+// and attributes the findings to commits and authors.
+package policy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Configuration option keys for the policy analyzer.
+const (
+	ConfigPolicyPacks        = "Policy.Packs"
+	ConfigPolicySuppressions = "Policy.Suppressions"
+	ConfigPolicyMinSeverity  = "Policy.MinSeverity"
+)
+
+// Report keys of the policy analyzer.
+const (
+	KeyCommits     = "commits"
+	KeyAuthorIndex = "author_index"
+	KeyTickSize    = "tick_size"
+
+	// commitSize and findingSize estimate the bytes of one CommitFindings
+	// and one Finding held by the aggregator.
+	commitSize  = 96
+	findingSize = 112
+)
+
+var (
+	// ErrInvalidPack indicates a malformed policy pack.
+	ErrInvalidPack = errors.New("invalid policy pack")
+	// ErrInvalidSuppressions indicates a malformed suppression file.
+	ErrInvalidSuppressions = errors.New("invalid policy suppressions")
+	// ErrInvalidSeverity indicates an unknown minimum severity.
+	ErrInvalidSeverity = errors.New("invalid policy severity")
+)
+
+// Finding is an added line that matched a rule. Match is redacted.
+type Finding struct {
+	File     string   `json:"file"     yaml:"file"`
+	Line     int      `json:"line"     yaml:"line"`
+	Rule     string   `json:"rule"     yaml:"rule"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	Match    string   `json:"match"    yaml:"match"`
+}
+
+// CommitFindings is the per-commit payload: the findings of the lines a
+// commit added.
+type CommitFindings struct {
+	Hash       gitlib.Hash
+	Tick       int
+	AuthorID   int
+	Time       time.Time
+	Findings   []Finding
+	Suppressed int
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	Commits []CommitFindings
+}
+
+// Analyzer checks the lines every commit adds against the rules of the
+// configured policy packs.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	TreeDiff  *plumbing.TreeDiffAnalyzer
+	BlobCache *plumbing.BlobCacheAnalyzer
+	FileDiff  *plumbing.FileDiffAnalyzer
+	Ticks     *plumbing.TicksSinceStart
+
+	// Packs lists policy pack files; BuiltinPack stands for the built-in rules.
+	Packs        []string
+	Suppressions string
+	MinSeverity  Severity
+
+	scanner            *scanner
+	reversedPeopleDict []string
+	tickSize           time.Duration
+}
+
+// NewAnalyzer creates a new policy analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{
+		Packs:       []string{BuiltinPack},
+		MinSeverity: SeverityLow,
+	}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/policy",
+			Mode: analyze.ModeHistory,
+			Description: "Scans the lines every commit adds for secrets, personal data and other regex policy " +
+				"violations, and attributes the findings to commits and authors.",
+		},
+		Sequential: false,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, NeedsDiffs: true, Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name: ConfigPolicyPacks,
+				Description: "Policy pack files, separated by commas. \"" + BuiltinPack +
+					"\" stands for the built-in secret and personal data rules.",
+				Flag:    "policy-packs",
+				Type:    pipeline.StringsConfigurationOption,
+				Default: []string{BuiltinPack},
+			},
+			{
+				Name:        ConfigPolicySuppressions,
+				Description: "Suppression file listing findings to ignore.",
+				Flag:        "policy-suppressions",
+				Type:        pipeline.PathConfigurationOption,
+				Default:     "",
+			},
+			{
+				Name:        ConfigPolicyMinSeverity,
+				Description: "Lowest severity to report: low, medium, high or critical.",
+				Flag:        "policy-min-severity",
+				Type:        pipeline.StringConfigurationOption,
+				Default:     string(SeverityLow),
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
+			return ComputeAllMetrics(report), nil
+		},
+		AggregatorFn: newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigPolicyPacks].([]string); exists && len(val) > 0 {
+		a.Packs = val
+	}
+
+	if val, exists := facts[ConfigPolicySuppressions].(string); exists {
+		a.Suppressions = val
+	}
+
+	if val, exists := facts[ConfigPolicyMinSeverity].(string); exists && val != "" {
+		if Severity(val).rank() == 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidSeverity, val)
+		}
+
+		a.MinSeverity = Severity(val)
+	}
+
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		a.reversedPeopleDict = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		a.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize loads the policy packs and the suppression file.
+func (a *Analyzer) Initialize(_ *gitlib.Repository) error {
+	rules, err := loadRules(a.Packs)
+	if err != nil {
+		return err
+	}
+
+	suppressions, err := loadSuppressions(a.Suppressions)
+	if err != nil {
+		return err
+	}
+
+	a.scanner = &scanner{rules: rules, suppressions: suppressions, minSeverity: a.MinSeverity}
+
+	return nil
+}
+
+// Consume emits the findings of the lines the commit added. Merge commits
+// are skipped: their lines were checked on the merged branch.
+func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac == nil || ac.IsMerge || a.scanner == nil {
+		return analyze.TC{}, nil
+	}
+
+	commit := &CommitFindings{}
+
+	for _, change := range a.TreeDiff.Changes {
+		findings, suppressed := a.scanChange(change)
+
+		commit.Findings = append(commit.Findings, findings...)
+		commit.Suppressed += suppressed
+	}
+
+	if len(commit.Findings) == 0 && commit.Suppressed == 0 {
+		return analyze.TC{}, nil
+	}
+
+	tc := analyze.TC{Data: commit}
+
+	if ac.Commit != nil {
+		tc.CommitHash = ac.Commit.Hash()
+	}
+
+	return tc, nil
+}
+
+// scanChange checks every line of an added file and the inserted lines of a
+// modified one. Binary files are skipped.
+func (a *Analyzer) scanChange(change *gitlib.Change) ([]Finding, int) {
+	if change.Action == gitlib.Delete {
+		return nil, 0
+	}
+
+	blob := a.BlobCache.Cache[change.To.Hash]
+	if blob == nil || blob.IsBinary() {
+		return nil, 0
+	}
+
+	lines := bytes.Split(blob.Data, []byte{'\n'})
+
+	var added []int
+
+	if change.Action == gitlib.Insert {
+		added = allLines(len(lines))
+	} else if diff, ok := a.FileDiff.FileDiffs[change.To.Name]; ok {
+		added = addedLines(diff.Diffs)
+	}
+
+	return a.scanner.scan(change.To.Name, lines, added)
+}
+
+// Fork creates independent copies of the analyzer for parallel processing.
+// The copies share the read-only scanner.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.BlobCache = &plumbing.BlobCacheAnalyzer{}
+		clone.FileDiff = &plumbing.FileDiffAnalyzer{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes:   a.TreeDiff.Changes,
+		BlobCache: a.BlobCache.Cache,
+		FileDiffs: a.FileDiff.FileDiffs,
+		Tick:      a.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.TreeDiff.Changes = snapshot.Changes
+	a.BlobCache.Cache = snapshot.BlobCache
+	a.FileDiff.FileDiffs = snapshot.FileDiffs
+	a.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot is a no-op for policy.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the commits in history order.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	commits := analyze.CollectTickCommits(ticks, tickCommits, commitTime)
+
+	return analyze.Report{
+		KeyCommits:     commits,
+		KeyAuthorIndex: a.reversedPeopleDict,
+		KeyTickSize:    a.tickSize,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func tickCommits(td *TickData) []CommitFindings { return td.Commits }
+
+func commitTime(c CommitFindings) time.Time { return c.Time }
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	cf, ok := tc.Data.(*CommitFindings)
+	if !ok || cf == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{}
+		byTick[tc.Tick] = state
+	}
+
+	commit := *cf
+	commit.Hash = tc.CommitHash
+	commit.Tick = tc.Tick
+	commit.AuthorID = tc.AuthorID
+	commit.Time = tc.Timestamp
+
+	state.Commits = append(state.Commits, commit)
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming != nil {
+		existing.Commits = append(existing.Commits, incoming.Commits...)
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	var findings int
+
+	for _, cf := range state.Commits {
+		findings += len(cf.Findings)
+	}
+
+	return int64(len(state.Commits))*commitSize + int64(findings)*findingSize
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || len(state.Commits) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
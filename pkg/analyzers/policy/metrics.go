@@ -0,0 +1,242 @@
+package policy
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+// SeverityCounts counts findings per severity.
+type SeverityCounts struct {
+	Critical int `json:"critical" yaml:"critical"`
+	High     int `json:"high"     yaml:"high"`
+	Medium   int `json:"medium"   yaml:"medium"`
+	Low      int `json:"low"      yaml:"low"`
+}
+
+func (c *SeverityCounts) add(severity Severity) {
+	switch severity {
+	case SeverityCritical:
+		c.Critical++
+	case SeverityHigh:
+		c.High++
+	case SeverityMedium:
+		c.Medium++
+	case SeverityLow:
+		c.Low++
+	}
+}
+
+// Total returns the number of findings of all severities.
+func (c SeverityCounts) Total() int {
+	return c.Critical + c.High + c.Medium + c.Low
+}
+
+// FindingRecord is a finding attributed to the commit that added it.
+type FindingRecord struct {
+	Finding `yaml:",inline"`
+
+	Hash   string    `json:"hash"   yaml:"hash"`
+	Author string    `json:"author" yaml:"author"`
+	Tick   int       `json:"tick"   yaml:"tick"`
+	Time   time.Time `json:"time"   yaml:"time"`
+}
+
+// RuleSummary sums the findings of one rule.
+type RuleSummary struct {
+	Rule     string   `json:"rule"     yaml:"rule"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	Findings int      `json:"findings" yaml:"findings"`
+	Files    int      `json:"files"    yaml:"files"`
+	Authors  int      `json:"authors"  yaml:"authors"`
+}
+
+// AuthorSummary sums the findings of one author.
+type AuthorSummary struct {
+	SeverityCounts `yaml:",inline"`
+
+	Author  string `json:"author"  yaml:"author"`
+	Commits int    `json:"commits" yaml:"commits"`
+}
+
+// TickSummary sums the findings of one tick.
+type TickSummary struct {
+	SeverityCounts `yaml:",inline"`
+
+	Tick int `json:"tick" yaml:"tick"`
+}
+
+// ComputedMetrics holds the policy findings of the history with their
+// summaries.
+type ComputedMetrics struct {
+	// Findings lists every finding, oldest first.
+	Findings []FindingRecord `json:"findings" yaml:"findings"`
+	Trend    []TickSummary   `json:"trend"    yaml:"trend"`
+	// Rules and Authors are ordered by severity, then by number of findings.
+	Rules      []RuleSummary   `json:"rules"      yaml:"rules"`
+	Authors    []AuthorSummary `json:"authors"    yaml:"authors"`
+	Total      SeverityCounts  `json:"total"      yaml:"total"`
+	Suppressed int             `json:"suppressed" yaml:"suppressed"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNamePolicy = "policy"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNamePolicy
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// ComputeAllMetrics attributes the findings of a report to commits, rules,
+// authors and ticks.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	commits, _ := report[KeyCommits].([]CommitFindings)
+	names, _ := report[KeyAuthorIndex].([]string)
+
+	m := &ComputedMetrics{}
+	sums := newSummaries()
+
+	for _, cf := range commits {
+		author := authorName(names, cf.AuthorID)
+		m.Suppressed += cf.Suppressed
+
+		if len(cf.Findings) == 0 {
+			continue
+		}
+
+		if n := len(m.Trend); n == 0 || m.Trend[n-1].Tick != cf.Tick {
+			m.Trend = append(m.Trend, TickSummary{Tick: cf.Tick})
+		}
+
+		point := &m.Trend[len(m.Trend)-1]
+		sums.addCommit(author)
+
+		for _, finding := range cf.Findings {
+			m.Findings = append(m.Findings, FindingRecord{
+				Finding: finding, Hash: cf.Hash.String(), Author: author, Tick: cf.Tick, Time: cf.Time,
+			})
+			m.Total.add(finding.Severity)
+			point.add(finding.Severity)
+			sums.addFinding(author, finding)
+		}
+	}
+
+	m.Rules = sums.ruleSummaries()
+	m.Authors = sums.authorSummaries()
+
+	// The findings come from the added lines of the diffs, which span the
+	// commits skipped between samples; only the commits that added them are
+	// undercounted.
+	factor := analyze.SampleFactor(report)
+	for i := range m.Authors {
+		m.Authors[i].Commits = analyze.ScaleCount(m.Authors[i].Commits, factor)
+	}
+
+	return m
+}
+
+// summaries sums the findings per rule and per author.
+type summaries struct {
+	rules       map[string]*RuleSummary
+	ruleFiles   map[string]map[string]bool
+	ruleAuthors map[string]map[string]bool
+	authors     map[string]*AuthorSummary
+}
+
+func newSummaries() *summaries {
+	return &summaries{
+		rules:       make(map[string]*RuleSummary),
+		ruleFiles:   make(map[string]map[string]bool),
+		ruleAuthors: make(map[string]map[string]bool),
+		authors:     make(map[string]*AuthorSummary),
+	}
+}
+
+func (s *summaries) author(name string) *AuthorSummary {
+	summary := s.authors[name]
+	if summary == nil {
+		summary = &AuthorSummary{Author: name}
+		s.authors[name] = summary
+	}
+
+	return summary
+}
+
+func (s *summaries) addCommit(author string) {
+	s.author(author).Commits++
+}
+
+func (s *summaries) addFinding(author string, finding Finding) {
+	s.author(author).add(finding.Severity)
+
+	rule := s.rules[finding.Rule]
+	if rule == nil {
+		rule = &RuleSummary{Rule: finding.Rule, Severity: finding.Severity}
+		s.rules[finding.Rule] = rule
+		s.ruleFiles[finding.Rule] = make(map[string]bool)
+		s.ruleAuthors[finding.Rule] = make(map[string]bool)
+	}
+
+	rule.Findings++
+	s.ruleFiles[finding.Rule][finding.File] = true
+	s.ruleAuthors[finding.Rule][author] = true
+}
+
+func (s *summaries) ruleSummaries() []RuleSummary {
+	result := make([]RuleSummary, 0, len(s.rules))
+
+	for id, rule := range s.rules {
+		rule.Files = len(s.ruleFiles[id])
+		rule.Authors = len(s.ruleAuthors[id])
+		result = append(result, *rule)
+	}
+
+	slices.SortFunc(result, func(x, y RuleSummary) int {
+		return cmp.Or(
+			cmp.Compare(y.Severity.rank(), x.Severity.rank()),
+			cmp.Compare(y.Findings, x.Findings),
+			cmp.Compare(x.Rule, y.Rule),
+		)
+	})
+
+	return result
+}
+
+func (s *summaries) authorSummaries() []AuthorSummary {
+	result := make([]AuthorSummary, 0, len(s.authors))
+	for _, author := range s.authors {
+		result = append(result, *author)
+	}
+
+	slices.SortFunc(result, func(x, y AuthorSummary) int {
+		return cmp.Or(
+			cmp.Compare(y.Critical, x.Critical),
+			cmp.Compare(y.High, x.High),
+			cmp.Compare(y.Total(), x.Total()),
+			cmp.Compare(x.Author, y.Author),
+		)
+	})
+
+	return result
+}
+
+func authorName(names []string, id int) string {
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return identity.AuthorMissingName
+}
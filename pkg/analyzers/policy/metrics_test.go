@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{})
+	assert.Empty(t, m.Findings)
+	assert.Empty(t, m.Rules)
+	assert.Zero(t, m.Total.Total())
+}
+
+func TestComputeAllMetrics_Attribution(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		KeyAuthorIndex: []string{"alice", "bob"},
+		KeyCommits: []CommitFindings{
+			{
+				Hash: testHash("a"), Tick: 0, AuthorID: 0,
+				Findings: []Finding{
+					{File: "a.env", Line: 1, Rule: "email", Severity: SeverityLow},
+					{File: "b.env", Line: 4, Rule: "email", Severity: SeverityLow},
+				},
+			},
+			{Hash: testHash("b"), Tick: 0, AuthorID: 1, Suppressed: 3},
+			{
+				Hash: testHash("c"), Tick: 2, AuthorID: 1,
+				Findings: []Finding{{File: "main.go", Line: 9, Rule: "aws-access-key", Severity: SeverityCritical}},
+			},
+			{
+				Hash: testHash("d"), Tick: 2, AuthorID: 7,
+				Findings: []Finding{{File: "a.env", Line: 2, Rule: "email", Severity: SeverityLow}},
+			},
+		},
+	}
+
+	m := ComputeAllMetrics(report)
+
+	require.Len(t, m.Findings, 4)
+	assert.Equal(t, "alice", m.Findings[0].Author)
+	assert.Equal(t, testHash("c").String(), m.Findings[2].Hash)
+	assert.Equal(t, 3, m.Suppressed)
+	assert.Equal(t, SeverityCounts{Critical: 1, Low: 3}, m.Total)
+
+	assert.Equal(t, []TickSummary{
+		{Tick: 0, SeverityCounts: SeverityCounts{Low: 2}},
+		{Tick: 2, SeverityCounts: SeverityCounts{Critical: 1, Low: 1}},
+	}, m.Trend)
+
+	assert.Equal(t, []RuleSummary{
+		{Rule: "aws-access-key", Severity: SeverityCritical, Findings: 1, Files: 1, Authors: 1},
+		{Rule: "email", Severity: SeverityLow, Findings: 3, Files: 2, Authors: 2},
+	}, m.Rules)
+
+	require.Len(t, m.Authors, 3)
+	assert.Equal(t, "bob", m.Authors[0].Author)
+	assert.Equal(t, 1, m.Authors[0].Commits)
+	assert.Equal(t, "alice", m.Authors[1].Author)
+	assert.Equal(t, 2, m.Authors[1].Low)
+
+	sections, err := (&Analyzer{}).GenerateSections(report)
+	require.NoError(t, err)
+	assert.Len(t, sections, 4)
+}
+
+func TestComputeAllMetrics_ScalesSampledCommits(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		KeyAuthorIndex: []string{"alice"},
+		KeyCommits: []CommitFindings{
+			{
+				Hash: testHash("a"), AuthorID: 0,
+				Findings: []Finding{
+					{File: "a.env", Line: 1, Rule: "email", Severity: SeverityLow},
+					{File: "b.env", Line: 4, Rule: "email", Severity: SeverityLow},
+				},
+			},
+		},
+		analyze.ReportKeySampleFactor: 3.0,
+	}
+
+	m := ComputeAllMetrics(report)
+
+	assert.Len(t, m.Findings, 2)
+	assert.Equal(t, SeverityCounts{Low: 2}, m.Total)
+	require.Len(t, m.Authors, 1)
+	assert.Equal(t, 3, m.Authors[0].Commits)
+	assert.Equal(t, 2, m.Authors[0].Low)
+}
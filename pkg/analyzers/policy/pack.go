@@ -0,0 +1,238 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BuiltinPack is the name under which the built-in rules can be listed
+// among the configured packs.
+const BuiltinPack = "builtin"
+
+// Severity ranks how urgently a finding must be handled.
+type Severity string
+
+// Severities of a Rule, lowest first.
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// severityRanks orders the severities; unknown severities rank 0.
+var severityRanks = map[Severity]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+func (s Severity) rank() int {
+	return severityRanks[s]
+}
+
+// Rule is a regular expression that added lines must not match.
+type Rule struct {
+	ID          string   `yaml:"id"`
+	Description string   `yaml:"description"`
+	Severity    Severity `yaml:"severity"`
+	// Pattern is an RE2 regular expression matched against every added line.
+	Pattern string `yaml:"pattern"`
+	// Paths limits the rule to the files matching one of the path patterns.
+	// Empty checks every file.
+	Paths []string `yaml:"paths"`
+}
+
+// Pack is a policy pack file: a named set of rules.
+type Pack struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// builtinRules cover common credentials and personal data.
+var builtinRules = []Rule{
+	{
+		ID: "private-key", Severity: SeverityCritical,
+		Description: "Private key block",
+		Pattern:     `-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----`,
+	},
+	{
+		ID: "aws-access-key", Severity: SeverityCritical,
+		Description: "AWS access key ID",
+		Pattern:     `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+	},
+	{
+		ID: "github-token", Severity: SeverityCritical,
+		Description: "GitHub token",
+		Pattern:     `\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+	},
+	{
+		ID: "slack-token", Severity: SeverityHigh,
+		Description: "Slack token",
+		Pattern:     `\bxox[abpr]-[A-Za-z0-9-]{10,}`,
+	},
+	{
+		ID: "jwt", Severity: SeverityHigh,
+		Description: "JSON Web Token",
+		Pattern:     `\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`,
+	},
+	{
+		ID: "credit-card", Severity: SeverityHigh,
+		Description: "Visa or Mastercard number",
+		Pattern:     `\b(?:4\d{3}|5[1-5]\d{2})(?:[ -]?\d{4}){3}\b`,
+	},
+	{
+		ID: "hardcoded-secret", Severity: SeverityMedium,
+		Description: "Password, secret or API key assigned a string literal",
+		Pattern:     `(?i)\b(?:password|passwd|secret|api[_-]?key|access[_-]?token)\b\s*[:=]\s*["'][^"'\s]{8,}["']`,
+	},
+	{
+		ID: "us-ssn", Severity: SeverityMedium,
+		Description: "US social security number",
+		Pattern:     `\b\d{3}-\d{2}-\d{4}\b`,
+	},
+	{
+		ID: "email", Severity: SeverityLow,
+		Description: "Email address",
+		Pattern:     `\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`,
+	},
+}
+
+// compiledRule is a Rule with its compiled pattern.
+type compiledRule struct {
+	Rule
+
+	re *regexp.Regexp
+}
+
+// appliesTo reports whether the rule checks file.
+func (r *compiledRule) appliesTo(file string) bool {
+	if len(r.Paths) == 0 {
+		return true
+	}
+
+	for _, pattern := range r.Paths {
+		if matchPath(pattern, file) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadRules reads the packs in order; BuiltinPack stands for the built-in
+// rules. A rule ID defined twice keeps its last definition.
+func loadRules(packs []string) ([]compiledRule, error) {
+	var rules []Rule
+
+	for _, name := range packs {
+		if name == BuiltinPack {
+			rules = append(rules, builtinRules...)
+
+			continue
+		}
+
+		pack, err := loadPack(name)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, pack.Rules...)
+	}
+
+	return compileRules(rules)
+}
+
+func loadPack(name string) (*Pack, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("read policy pack: %w", err)
+	}
+
+	var pack Pack
+
+	err = yaml.Unmarshal(data, &pack)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidPack, name, err)
+	}
+
+	return &pack, nil
+}
+
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	index := make(map[string]int, len(rules))
+	compiled := make([]compiledRule, 0, len(rules))
+
+	for _, rule := range rules {
+		err := validateRule(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: rule %q: %w", ErrInvalidPack, rule.ID, err)
+		}
+
+		if i, seen := index[rule.ID]; seen {
+			compiled[i] = compiledRule{Rule: rule, re: re}
+
+			continue
+		}
+
+		index[rule.ID] = len(compiled)
+		compiled = append(compiled, compiledRule{Rule: rule, re: re})
+	}
+
+	return compiled, nil
+}
+
+func validateRule(rule Rule) error {
+	switch {
+	case rule.ID == "":
+		return fmt.Errorf("%w: rule without id", ErrInvalidPack)
+	case rule.Pattern == "":
+		return fmt.Errorf("%w: rule %q has no pattern", ErrInvalidPack, rule.ID)
+	case rule.Severity.rank() == 0:
+		return fmt.Errorf("%w: rule %q has unknown severity %q", ErrInvalidPack, rule.ID, rule.Severity)
+	}
+
+	for _, pattern := range rule.Paths {
+		_, err := path.Match(strings.TrimSuffix(pattern, "/**"), "")
+		if err != nil {
+			return fmt.Errorf("%w: rule %q: path %q: %w", ErrInvalidPack, rule.ID, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// matchPath reports whether the slash-separated file matches pattern. A
+// pattern without a slash matches the base name, a pattern with a slash the
+// whole path, and a trailing "/**" every file below the matching directories.
+func matchPath(pattern, file string) bool {
+	prefix, tree := strings.CutSuffix(pattern, "/**")
+
+	if !tree {
+		if !strings.Contains(pattern, "/") {
+			file = path.Base(file)
+		}
+
+		matched, _ := path.Match(pattern, file)
+
+		return matched
+	}
+
+	for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if matched, _ := path.Match(prefix, dir); matched {
+			return true
+		}
+	}
+
+	return false
+}
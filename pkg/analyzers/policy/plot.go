@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"html"
+	"strconv"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const shortHashLen = 8
+
+// RegisterPlotSections registers the policy plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/policy", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeAllMetrics(report)
+
+	return []plotpage.Section{
+		{
+			Title:    "Policy Findings",
+			Subtitle: "Findings per tick and severity.",
+			Chart:    plotpage.WrapChart(buildFindingTrendChart(m.Trend)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"<strong>Findings</strong> = added lines that matched a rule of the policy packs",
+					strconv.Itoa(m.Suppressed) + " findings were suppressed by the suppression file or inline markers",
+					"Removing a secret in a later commit does not remove it from history: rotate it",
+				},
+			},
+		},
+		{
+			Title:    "Rules",
+			Subtitle: "Findings per rule, most severe first.",
+			Chart:    buildRuleTable(m.Rules),
+		},
+		{
+			Title:    "Authors",
+			Subtitle: "Findings per author, most severe first.",
+			Chart:    buildAuthorTable(m.Authors),
+		},
+		{
+			Title:    "Findings",
+			Subtitle: strconv.Itoa(len(m.Findings)) + " findings in history order.",
+			Chart:    buildFindingTable(m.Findings),
+		},
+	}, nil
+}
+
+func buildFindingTrendChart(trend []TickSummary) *charts.Bar {
+	labels := make([]string, len(trend))
+	critical := make([]plotpage.SeriesData, len(trend))
+	high := make([]plotpage.SeriesData, len(trend))
+	medium := make([]plotpage.SeriesData, len(trend))
+	low := make([]plotpage.SeriesData, len(trend))
+
+	for i, point := range trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		critical[i] = point.Critical
+		high[i] = point.High
+		medium[i] = point.Medium
+		low[i] = point.Low
+	}
+
+	return plotpage.BuildBarChart(nil, labels, []plotpage.BarSeries{
+		{Name: "Critical", Data: critical},
+		{Name: "High", Data: high},
+		{Name: "Medium", Data: medium},
+		{Name: "Low", Data: low},
+	}, "Findings")
+}
+
+func buildRuleTable(rules []RuleSummary) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Rule", "Severity", "Findings", "Files", "Authors"})
+
+	for _, rule := range rules {
+		table.AddRow(
+			html.EscapeString(rule.Rule),
+			string(rule.Severity),
+			strconv.Itoa(rule.Findings),
+			strconv.Itoa(rule.Files),
+			strconv.Itoa(rule.Authors),
+		)
+	}
+
+	return table
+}
+
+func buildAuthorTable(authors []AuthorSummary) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Author", "Commits", "Critical", "High", "Medium", "Low"})
+
+	for _, author := range authors {
+		table.AddRow(
+			html.EscapeString(author.Author),
+			strconv.Itoa(author.Commits),
+			strconv.Itoa(author.Critical),
+			strconv.Itoa(author.High),
+			strconv.Itoa(author.Medium),
+			strconv.Itoa(author.Low),
+		)
+	}
+
+	return table
+}
+
+func buildFindingTable(findings []FindingRecord) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Commit", "Author", "Tick", "File", "Rule", "Severity", "Match"}).
+		WithSearch("Filter findings...")
+
+	for _, finding := range findings {
+		table.AddRow(
+			shortHash(finding.Hash),
+			html.EscapeString(finding.Author),
+			strconv.Itoa(finding.Tick),
+			html.EscapeString(finding.File)+":"+strconv.Itoa(finding.Line),
+			html.EscapeString(finding.Rule),
+			string(finding.Severity),
+			html.EscapeString(finding.Match),
+		)
+	}
+
+	return table
+}
+
+func shortHash(hash string) string {
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+
+	return hash
+}
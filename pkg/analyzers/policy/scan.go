@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// redactKeep is the number of leading characters of a match kept in reports.
+const redactKeep = 4
+
+// scanner checks added lines against the rules of the configured packs.
+type scanner struct {
+	rules        []compiledRule
+	suppressions []Suppression
+	minSeverity  Severity
+}
+
+// scan returns the findings of the added lines of file and the number of
+// suppressed findings. added holds zero-based line numbers.
+func (s *scanner) scan(file string, lines [][]byte, added []int) (findings []Finding, suppressed int) {
+	rules := s.rulesFor(file)
+	if len(rules) == 0 {
+		return nil, 0
+	}
+
+	for _, index := range added {
+		if index >= len(lines) {
+			break
+		}
+
+		line := lines[index]
+		allowed := bytes.Contains(line, []byte(InlineAllowMarker))
+
+		for _, rule := range rules {
+			loc := rule.re.FindIndex(line)
+			if loc == nil {
+				continue
+			}
+
+			text := string(line[loc[0]:loc[1]])
+			if allowed || s.suppressed(rule.ID, file, text) {
+				suppressed++
+
+				continue
+			}
+
+			findings = append(findings, Finding{
+				File:     file,
+				Line:     index + 1,
+				Rule:     rule.ID,
+				Severity: rule.Severity,
+				Match:    redact(text),
+			})
+		}
+	}
+
+	return findings, suppressed
+}
+
+// rulesFor returns the rules at or above the minimum severity that check
+// file.
+func (s *scanner) rulesFor(file string) []*compiledRule {
+	var rules []*compiledRule
+
+	for i := range s.rules {
+		rule := &s.rules[i]
+		if rule.Severity.rank() >= s.minSeverity.rank() && rule.appliesTo(file) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+func (s *scanner) suppressed(rule, file, text string) bool {
+	for i := range s.suppressions {
+		if s.suppressions[i].matches(rule, file, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addedLines returns the zero-based numbers of the lines a diff inserted.
+// The diff encodes every line as one rune.
+func addedLines(diffs []diffmatchpatch.Diff) []int {
+	var (
+		added []int
+		line  int
+	)
+
+	for _, edit := range diffs {
+		size := utf8.RuneCountInString(edit.Text)
+
+		switch edit.Type {
+		case diffmatchpatch.DiffInsert:
+			for i := range size {
+				added = append(added, line+i)
+			}
+
+			line += size
+		case diffmatchpatch.DiffEqual:
+			line += size
+		case diffmatchpatch.DiffDelete:
+		}
+	}
+
+	return added
+}
+
+// allLines returns the numbers of the n lines of a new file.
+func allLines(n int) []int {
+	lines := make([]int, n)
+	for i := range lines {
+		lines[i] = i
+	}
+
+	return lines
+}
+
+// redact keeps the first characters of a match so reports do not repeat the
+// secrets they flag.
+func redact(text string) string {
+	runes := []rune(text)
+	if len(runes) <= 2*redactKeep {
+		return "****"
+	}
+
+	return string(runes[:redactKeep]) + "****"
+}
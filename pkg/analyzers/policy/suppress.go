@@ -0,0 +1,60 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InlineAllowMarker silences every finding on the line that contains it.
+const InlineAllowMarker = "policy:allow"
+
+// Suppression silences the findings it matches. Empty fields match any
+// finding, but at least one of Rule, Path and Match must be set.
+type Suppression struct {
+	Rule string `yaml:"rule"`
+	// Path is a path pattern as in Rule.Paths.
+	Path string `yaml:"path"`
+	// Match is the exact text the rule matched, such as a well-known test key.
+	Match  string `yaml:"match"`
+	Reason string `yaml:"reason"`
+}
+
+// suppressionFile is the layout of a suppression file.
+type suppressionFile struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+}
+
+// loadSuppressions reads a suppression file; an empty name has none.
+func loadSuppressions(name string) ([]Suppression, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("read policy suppressions: %w", err)
+	}
+
+	var file suppressionFile
+
+	err = yaml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSuppressions, name, err)
+	}
+
+	for i, s := range file.Suppressions {
+		if s.Rule == "" && s.Path == "" && s.Match == "" {
+			return nil, fmt.Errorf("%w: suppression %d matches every finding", ErrInvalidSuppressions, i+1)
+		}
+	}
+
+	return file.Suppressions, nil
+}
+
+func (s *Suppression) matches(rule, file, text string) bool {
+	return (s.Rule == "" || s.Rule == rule) &&
+		(s.Path == "" || matchPath(s.Path, file)) &&
+		(s.Match == "" || s.Match == text)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
 )
 
@@ -83,6 +84,10 @@ type Analyzer struct {
 
 	commitsByTick map[int][]gitlib.Hash
 
+	// fileCache maps the structural hash of a file to its metrics, so that
+	// renamed, reverted or reformatted files are not analyzed again.
+	fileCache map[uint64]*TickQuality
+
 	// Static analyzers (stateless, created in Initialize).
 	complexityAnalyzer *complexity.Analyzer
 	halsteadAnalyzer   *halstead.Analyzer
@@ -90,6 +95,9 @@ type Analyzer struct {
 	cohesionAnalyzer   *cohesion.Analyzer
 }
 
+// maxFileCacheEntries bounds the file cache; it is cleared when full.
+const maxFileCacheEntries = 4096
+
 // NewAnalyzer creates a new quality Analyzer.
 func NewAnalyzer() *Analyzer {
 	a := &Analyzer{
@@ -106,6 +114,7 @@ func NewAnalyzer() *Analyzer {
 			Mode:        analyze.ModeHistory,
 		},
 		Sequential: false,
+		Caps:       &analyze.Capabilities{NeedsUAST: true, Memory: analyze.MemoryLow},
 		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
 			if len(report) == 0 {
 				return &ComputedMetrics{}, nil
@@ -158,7 +167,7 @@ func (a *Analyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC
 			continue
 		}
 
-		a.analyzeNode(change.After, cq)
+		cq.merge(a.fileQuality(change))
 	}
 
 	tc := analyze.TC{Data: cq}
@@ -170,6 +179,32 @@ func (a *Analyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC
 	return tc, nil
 }
 
+// fileQuality returns the metrics of the file after the change, reusing the
+// ones of a file with the same structural hash.
+func (a *Analyzer) fileQuality(change uast.Change) *TickQuality {
+	if change.AfterHashes == nil {
+		tq := &TickQuality{}
+		a.analyzeNode(change.After, tq)
+
+		return tq
+	}
+
+	if tq, found := a.fileCache[change.AfterHashes.File]; found {
+		return tq
+	}
+
+	tq := &TickQuality{}
+	a.analyzeNode(change.After, tq)
+
+	if a.fileCache == nil || len(a.fileCache) >= maxFileCacheEntries {
+		a.fileCache = make(map[uint64]*TickQuality)
+	}
+
+	a.fileCache[change.AfterHashes.File] = tq
+
+	return tq
+}
+
 func (a *Analyzer) analyzeNode(root *node.Node, tq *TickQuality) {
 	a.analyzeComplexity(root, tq)
 	a.analyzeHalstead(root, tq)
@@ -249,9 +284,6 @@ func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
 // collected by the framework, not accumulated inside the analyzer.
 func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
 
-// NeedsUAST returns true to enable the UAST pipeline.
-func (a *Analyzer) NeedsUAST() bool { return true }
-
 // SnapshotPlumbing captures the current plumbing output state.
 func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
 	return plumbing.Snapshot{
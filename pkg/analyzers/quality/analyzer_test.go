@@ -173,6 +173,30 @@ func TestAnalyzer_Consume_NoAccumulation(t *testing.T) {
 	assert.GreaterOrEqual(t, tq2.filesAnalyzed(), 1)
 }
 
+func TestAnalyzer_Consume_ReusesMetricsOfSameStructure(t *testing.T) {
+	t.Parallel()
+
+	ha := newTestAnalyzer()
+	change := uast.NewChange(nil, buildTestFunctionNode(), nil)
+
+	ha.UAST.SetChangesForTest([]uast.Change{change})
+
+	_, err := ha.Consume(context.Background(), &analyze.Context{})
+	require.NoError(t, err)
+	require.Contains(t, ha.fileCache, change.AfterHashes.File)
+
+	// A cached file is not analyzed again.
+	ha.fileCache[change.AfterHashes.File] = &TickQuality{Complexities: []float64{42}}
+	ha.UAST.SetChangesForTest([]uast.Change{uast.NewChange(nil, buildTestFunctionNode(), nil)})
+
+	tc, err := ha.Consume(context.Background(), &analyze.Context{})
+	require.NoError(t, err)
+
+	tq, isTQ := tc.Data.(*TickQuality)
+	require.True(t, isTQ)
+	assert.Equal(t, []float64{42}, tq.Complexities)
+}
+
 func TestAnalyzer_Fork_IndependentSubAnalyzers(t *testing.T) {
 	t.Parallel()
 
@@ -295,6 +319,29 @@ func TestComputeAllMetrics_FromCommitData(t *testing.T) {
 	assert.Equal(t, 3, computed.Aggregate.TotalFilesAnalyzed)
 }
 
+func TestExtractForecastSeries_FillsGaps(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		"commit_quality": map[string]*TickQuality{
+			testHashA: {Complexities: []float64{4, 6}},
+			testHashB: {Complexities: []float64{9}},
+		},
+		"commits_by_tick": map[int][]gitlib.Hash{
+			1: {gitlib.NewHash(testHashA)},
+			4: {gitlib.NewHash(testHashB)},
+		},
+	}
+
+	series := extractForecastSeries(report)
+	require.Len(t, series, 1)
+	assert.Equal(t, "complexity_median", series[0].Name)
+	assert.Equal(t, 1, series[0].Start)
+	assert.Equal(t, []float64{5, 5, 5, 9}, series[0].Values)
+
+	assert.Nil(t, extractForecastSeries(analyze.Report{}))
+}
+
 func TestComputeAllMetrics_FromCanonical(t *testing.T) {
 	t.Parallel()
 
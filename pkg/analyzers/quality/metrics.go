@@ -16,6 +16,41 @@ func RegisterTimeSeriesExtractor() {
 	anomaly.RegisterTimeSeriesExtractor("quality", extractTimeSeries)
 }
 
+// RegisterForecastSeries registers the median complexity of changed code over
+// time as a series to forecast.
+func RegisterForecastSeries() {
+	analyze.RegisterForecastSeries("quality", extractForecastSeries)
+}
+
+// extractForecastSeries returns the median complexity of every tick from the
+// first commit to the last. Ticks without commits keep the previous median.
+func extractForecastSeries(report analyze.Report) []analyze.ForecastSeries {
+	ticks, dimensions := extractTimeSeries(report)
+	if len(ticks) == 0 {
+		return nil
+	}
+
+	medians := dimensions["complexity_median"]
+	first := ticks[0]
+	values := make([]float64, 0, ticks[len(ticks)-1]-first+1)
+
+	for i, tick := range ticks {
+		for len(values) < tick-first {
+			values = append(values, values[len(values)-1])
+		}
+
+		values = append(values, medians[i])
+	}
+
+	return []analyze.ForecastSeries{{
+		Name:   "complexity_median",
+		Label:  "Median complexity",
+		Start:  first,
+		Step:   1,
+		Values: values,
+	}}
+}
+
 func extractTimeSeries(report analyze.Report) (ticks []int, dimensions map[string][]float64) {
 	data, err := ParseReportData(report)
 	if err != nil || len(data.TickQuality) == 0 {
@@ -0,0 +1,386 @@
+// Package reverts detects reverted commits and chains of fixes that fix
+// earlier fixes, and measures how often the changes to a module need a
+// follow-up fix or revert within a few days.
+package reverts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Configuration option keys for the reverts analyzer.
+const (
+	ConfigRevertsFixWindowDays = "Reverts.FixWindowDays"
+	ConfigRevertsModuleDepth   = "Reverts.ModuleDepth"
+	ConfigRevertsFixPattern    = "Reverts.FixPattern"
+
+	defaultFixWindowDays = 14
+	defaultModuleDepth   = 2
+)
+
+// Report keys of the reverts analyzer.
+const (
+	KeyReworks       = "reworks"
+	KeyTicks         = "ticks"
+	KeyModules       = "modules"
+	KeyAuthorIndex   = "author_index"
+	KeyTickSize      = "tick_size"
+	KeyFixWindowDays = "fix_window_days"
+
+	// reworkBytes and moduleBytes estimate the bytes of one CommitRework
+	// and of one module entry held by the aggregator.
+	reworkBytes = 160
+	moduleBytes = 64
+
+	day = 24 * time.Hour
+)
+
+// ErrInvalidFixPattern indicates a fix pattern that is not a valid regular
+// expression.
+var ErrInvalidFixPattern = errors.New("invalid fix pattern")
+
+// ModuleCounts counts the changes to a module and how many of them needed a
+// follow-up fix or revert.
+type ModuleCounts struct {
+	Changes   int `json:"changes"    yaml:"changes"`
+	FollowUps int `json:"follow_ups" yaml:"follow_ups"`
+}
+
+// TickCommits counts the analyzed commits of a tick.
+type TickCommits struct {
+	Tick    int `json:"tick"    yaml:"tick"`
+	Commits int `json:"commits" yaml:"commits"`
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	Commits int
+	Modules map[string]*ModuleCounts
+	// Reworks holds the fixes and reverts of the tick.
+	Reworks []CommitRework
+}
+
+// Analyzer relates every commit to the commits of the fix window before it
+// to find reverts, fix chains and the changes that needed a follow-up.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	TreeDiff *plumbing.TreeDiffAnalyzer
+	Ticks    *plumbing.TicksSinceStart
+
+	// FixWindowDays is how many days after a change a fix or revert of the
+	// same files counts as its follow-up.
+	FixWindowDays int
+	// ModuleDepth is the number of directory levels that name a module.
+	ModuleDepth int
+	// FixPattern matches the subjects of fix commits.
+	FixPattern string
+
+	tracker            *tracker
+	reversedPeopleDict []string
+	tickSize           time.Duration
+}
+
+// NewAnalyzer creates a new reverts analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{
+		FixWindowDays: defaultFixWindowDays,
+		ModuleDepth:   defaultModuleDepth,
+		FixPattern:    pkgplumbing.FixPattern,
+	}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/reverts",
+			Mode: analyze.ModeHistory,
+			Description: "Detects reverts and fix-of-a-fix chains and measures how often the changes " +
+				"to a module needed a follow-up fix within a few days.",
+		},
+		Sequential: true,
+		Caps:       &analyze.Capabilities{Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigRevertsFixWindowDays,
+				Description: "Count a fix or revert as the follow-up of changes to the same files made this many days before.",
+				Flag:        "reverts-fix-window-days",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultFixWindowDays,
+			},
+			{
+				Name:        ConfigRevertsModuleDepth,
+				Description: "Number of directory levels that name a module.",
+				Flag:        "reverts-module-depth",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultModuleDepth,
+			},
+			{
+				Name:        ConfigRevertsFixPattern,
+				Description: "Regular expression matching the subjects of fix commits.",
+				Flag:        "reverts-fix-pattern",
+				Type:        pipeline.StringConfigurationOption,
+				Default:     pkgplumbing.FixPattern,
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
+			return ComputeAllMetrics(report), nil
+		},
+		AggregatorFn: newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigRevertsFixWindowDays].(int); exists && val > 0 {
+		a.FixWindowDays = val
+	}
+
+	if val, exists := facts[ConfigRevertsModuleDepth].(int); exists && val > 0 {
+		a.ModuleDepth = val
+	}
+
+	if val, exists := facts[ConfigRevertsFixPattern].(string); exists && val != "" {
+		a.FixPattern = val
+	}
+
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		a.reversedPeopleDict = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		a.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize compiles the fix pattern and resets the fix window.
+func (a *Analyzer) Initialize(_ *gitlib.Repository) error {
+	fixPattern, err := regexp.Compile(a.FixPattern)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFixPattern, err)
+	}
+
+	a.tracker = newTracker(time.Duration(a.FixWindowDays)*day, a.ModuleDepth, fixPattern)
+
+	return nil
+}
+
+// Consume relates the commit to the fix window and emits the modules it
+// changed and the rework it represents. Merge commits are skipped: their
+// changes were made on the merged branch.
+func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac == nil || ac.Commit == nil || ac.IsMerge || len(a.TreeDiff.Changes) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	hash := ac.Commit.Hash()
+	rework := a.tracker.observe(hash.String(), ac.Time, ac.Commit.Message(), a.TreeDiff.Changes)
+
+	return analyze.TC{Data: rework, CommitHash: hash}, nil
+}
+
+// Fork creates copies of the analyzer that share the fix window.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes: a.TreeDiff.Changes,
+		Tick:    a.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.TreeDiff.Changes = snapshot.Changes
+	a.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot is a no-op for reverts.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the fixes and reverts in history order, the commits
+// per tick and the change counts per module.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	var (
+		reworks []CommitRework
+		series  []TickCommits
+		modules = make(map[string]ModuleCounts)
+	)
+
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*TickData)
+		if !ok || td == nil {
+			continue
+		}
+
+		series = append(series, TickCommits{Tick: tick.Tick, Commits: td.Commits})
+
+		for module, counts := range td.Modules {
+			total := modules[module]
+			total.Changes += counts.Changes
+			total.FollowUps += counts.FollowUps
+			modules[module] = total
+		}
+
+		tickReworks := slices.Clone(td.Reworks)
+		slices.SortStableFunc(tickReworks, func(x, y CommitRework) int {
+			return x.Time.Compare(y.Time)
+		})
+
+		reworks = append(reworks, tickReworks...)
+	}
+
+	return analyze.Report{
+		KeyReworks:       reworks,
+		KeyTicks:         series,
+		KeyModules:       modules,
+		KeyAuthorIndex:   a.reversedPeopleDict,
+		KeyTickSize:      a.tickSize,
+		KeyFixWindowDays: a.FixWindowDays,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	rework, ok := tc.Data.(*CommitRework)
+	if !ok || rework == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{Modules: make(map[string]*ModuleCounts)}
+		byTick[tc.Tick] = state
+	}
+
+	state.Commits++
+
+	for _, module := range rework.Modules {
+		state.module(module).Changes++
+	}
+
+	for module, followUps := range rework.FollowUps {
+		state.module(module).FollowUps += followUps
+	}
+
+	if !rework.Fix && rework.Revert == nil {
+		return nil
+	}
+
+	commit := *rework
+	commit.Hash = tc.CommitHash
+	commit.Tick = tc.Tick
+	commit.AuthorID = tc.AuthorID
+	commit.Time = tc.Timestamp
+
+	state.Reworks = append(state.Reworks, commit)
+
+	return nil
+}
+
+// module returns the counts of module, adding them if missing.
+func (td *TickData) module(module string) *ModuleCounts {
+	counts := td.Modules[module]
+	if counts == nil {
+		counts = &ModuleCounts{}
+		td.Modules[module] = counts
+	}
+
+	return counts
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming == nil {
+		return existing
+	}
+
+	existing.Commits += incoming.Commits
+
+	for module, counts := range incoming.Modules {
+		total := existing.module(module)
+		total.Changes += counts.Changes
+		total.FollowUps += counts.FollowUps
+	}
+
+	existing.Reworks = append(existing.Reworks, incoming.Reworks...)
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	return int64(len(state.Modules))*moduleBytes + int64(len(state.Reworks))*reworkBytes
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || state.Commits == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
@@ -0,0 +1,163 @@
+package reverts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func newTestAnalyzer(t *testing.T) *Analyzer {
+	t.Helper()
+
+	a := NewAnalyzer()
+	a.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+	a.Ticks = &plumbing.TicksSinceStart{}
+	require.NoError(t, a.Initialize(nil))
+
+	return a
+}
+
+func testHash(c string) gitlib.Hash {
+	return gitlib.NewHash(c + "000000000000000000000000000000000000000")
+}
+
+func TestAnalyzer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	assert.Equal(t, "history/reverts", a.Name())
+	assert.NotEmpty(t, a.Description())
+	assert.Len(t, a.ListConfigurationOptions(), 3)
+	assert.True(t, a.SequentialOnly())
+}
+
+func TestAnalyzer_Configure(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(map[string]any{
+		ConfigRevertsFixWindowDays: 30,
+		ConfigRevertsModuleDepth:   0,
+		ConfigRevertsFixPattern:    `^fix`,
+	}))
+	assert.Equal(t, 30, a.FixWindowDays)
+	assert.Equal(t, defaultModuleDepth, a.ModuleDepth)
+	assert.Equal(t, `^fix`, a.FixPattern)
+}
+
+func TestAnalyzer_Initialize_InvalidFixPattern(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	a.FixPattern = `fix(`
+
+	require.ErrorIs(t, a.Initialize(nil), ErrInvalidFixPattern)
+}
+
+func TestAnalyzer_Consume(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sig := gitlib.Signature{Name: "dev", When: start}
+
+	a.TreeDiff.Changes = modify("internal/db/conn.go", testHash("1"), testHash("2"))
+	feature := gitlib.NewTestCommit(testHash("a"), sig, "Pool connections")
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: feature, Time: start})
+	require.NoError(t, err)
+	assert.Equal(t, testHash("a"), tc.CommitHash)
+
+	rework, ok := tc.Data.(*CommitRework)
+	require.True(t, ok)
+	assert.Equal(t, []string{"internal/db"}, rework.Modules)
+	assert.False(t, rework.Fix)
+
+	a.TreeDiff.Changes = modify("internal/db/conn.go", testHash("2"), testHash("3"))
+	fix := gitlib.NewTestCommit(testHash("b"), sig, "Fix connection leak")
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: fix, Time: start.Add(day)})
+	require.NoError(t, err)
+
+	rework, ok = tc.Data.(*CommitRework)
+	require.True(t, ok)
+	assert.True(t, rework.Fix)
+	assert.Equal(t, map[string]int{"internal/db": 1}, rework.FollowUps)
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: fix, Time: start, IsMerge: true})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+
+	a.TreeDiff.Changes = nil
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: fix, Time: start})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+}
+
+func TestAnalyzer_Fork(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+
+	forks := a.Fork(2)
+	require.Len(t, forks, 2)
+
+	for _, f := range forks {
+		clone, ok := f.(*Analyzer)
+		require.True(t, ok)
+		assert.NotSame(t, a.TreeDiff, clone.TreeDiff)
+		assert.Same(t, a.tracker, clone.tracker)
+	}
+}
+
+func TestAnalyzer_ReportFromTICKs(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	byTick := make(map[int]*TickData)
+
+	for _, tc := range []analyze.TC{
+		{
+			Tick: 0, AuthorID: 0, CommitHash: testHash("a"), Timestamp: start,
+			Data: &CommitRework{Modules: []string{"api"}},
+		},
+		{
+			Tick: 0, AuthorID: 1, CommitHash: testHash("c"), Timestamp: start.Add(2 * time.Hour),
+			Data: &CommitRework{Modules: []string{"api"}, Fix: true, Depth: 2, Fixes: testHash("b").String()},
+		},
+		{
+			Tick: 0, AuthorID: 1, CommitHash: testHash("b"), Timestamp: start.Add(time.Hour),
+			Data: &CommitRework{Modules: []string{"api", "db"}, Fix: true, Depth: 1, FollowUps: map[string]int{"api": 1}},
+		},
+	} {
+		require.NoError(t, extractTC(tc, byTick))
+	}
+
+	tick, err := buildTick(0, byTick[0])
+	require.NoError(t, err)
+
+	report, err := NewAnalyzer().ReportFromTICKs(context.Background(), []analyze.TICK{tick})
+	require.NoError(t, err)
+
+	reworks, ok := report[KeyReworks].([]CommitRework)
+	require.True(t, ok)
+	require.Len(t, reworks, 2, "only fixes and reverts are kept")
+	assert.Equal(t, testHash("b"), reworks[0].Hash)
+	assert.Equal(t, 1, reworks[0].AuthorID)
+
+	assert.Equal(t, []TickCommits{{Tick: 0, Commits: 3}}, report[KeyTicks])
+	assert.Equal(t, map[string]ModuleCounts{
+		"api": {Changes: 3, FollowUps: 1},
+		"db":  {Changes: 1},
+	}, report[KeyModules])
+	assert.Equal(t, defaultFixWindowDays, report[KeyFixWindowDays])
+}
@@ -0,0 +1,79 @@
+package reverts
+
+import (
+	"hash/fnv"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// revertedPattern matches the line git revert adds to the message.
+var revertedPattern = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,40})`)
+
+// RevertedHash returns the commit a git revert message names. Reverts
+// written by hand without that line are not recognized.
+func RevertedHash(message string) (string, bool) {
+	match := revertedPattern.FindStringSubmatch(message)
+	if match == nil {
+		return "", false
+	}
+
+	return match[1], true
+}
+
+// subject returns the first line of message.
+func subject(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+
+	return line
+}
+
+// changeSignature fingerprints the changes of a commit, or their inverse,
+// so that a commit undoing exactly the changes of another one has the
+// signature of its inverse. It returns 0 for no changes.
+func changeSignature(changes gitlib.Changes, inverse bool) uint64 {
+	if len(changes) == 0 {
+		return 0
+	}
+
+	keys := make([]string, len(changes))
+
+	for i, change := range changes {
+		from, to := change.From, change.To
+		if inverse {
+			from, to = to, from
+		}
+
+		keys[i] = from.Name + "\x00" + from.Hash.String() + "\x00" + to.Name + "\x00" + to.Hash.String()
+	}
+
+	slices.Sort(keys)
+
+	h := fnv.New64a()
+
+	for _, key := range keys {
+		_, _ = h.Write([]byte(key))
+		_, _ = h.Write([]byte{'\n'})
+	}
+
+	return h.Sum64()
+}
+
+// changedFiles returns the paths a commit changed. Renames list both paths.
+func changedFiles(changes gitlib.Changes) []string {
+	files := make([]string, 0, len(changes))
+
+	for _, change := range changes {
+		if change.From.Name != "" {
+			files = append(files, change.From.Name)
+		}
+
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			files = append(files, change.To.Name)
+		}
+	}
+
+	return files
+}
@@ -0,0 +1,69 @@
+package reverts
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestRevertedHash(t *testing.T) {
+	t.Parallel()
+
+	hash, ok := RevertedHash("Revert \"Add cache\"\n\nThis reverts commit 0123456789abcdef0123456789abcdef01234567.\n")
+	assert.True(t, ok)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", hash)
+
+	_, ok = RevertedHash("Revert the cache for now")
+	assert.False(t, ok)
+}
+
+func TestFixPattern(t *testing.T) {
+	t.Parallel()
+
+	fixes := regexp.MustCompile(pkgplumbing.FixPattern)
+
+	for _, message := range []string{"Fix crash on empty input", "fixes #12", "Hotfix: login", "bugfix in parser", "regression in 2.1"} {
+		assert.True(t, fixes.MatchString(message), message)
+	}
+
+	for _, message := range []string{"Add prefix support", "Refactor suffixes", "Update debugger"} {
+		assert.False(t, fixes.MatchString(message), message)
+	}
+}
+
+func TestChangeSignature(t *testing.T) {
+	t.Parallel()
+
+	before := gitlib.ChangeEntry{Name: "a.go", Hash: testHash("1")}
+	after := gitlib.ChangeEntry{Name: "a.go", Hash: testHash("2")}
+	added := gitlib.ChangeEntry{Name: "b.go", Hash: testHash("3")}
+
+	change := gitlib.Changes{
+		{Action: gitlib.Modify, From: before, To: after},
+		{Action: gitlib.Insert, To: added},
+	}
+	undo := gitlib.Changes{
+		{Action: gitlib.Delete, From: added},
+		{Action: gitlib.Modify, From: after, To: before},
+	}
+
+	assert.Zero(t, changeSignature(nil, false))
+	assert.Equal(t, changeSignature(change, true), changeSignature(undo, false))
+	assert.NotEqual(t, changeSignature(change, false), changeSignature(undo, false))
+}
+
+func TestChangedFiles(t *testing.T) {
+	t.Parallel()
+
+	changes := gitlib.Changes{
+		{Action: gitlib.Modify, From: gitlib.ChangeEntry{Name: "old.go"}, To: gitlib.ChangeEntry{Name: "new.go"}},
+		{Action: gitlib.Insert, To: gitlib.ChangeEntry{Name: "added.go"}},
+		{Action: gitlib.Delete, From: gitlib.ChangeEntry{Name: "gone.go"}},
+	}
+
+	assert.Equal(t, []string{"old.go", "new.go", "added.go", "gone.go"}, changedFiles(changes))
+}
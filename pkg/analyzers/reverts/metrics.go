@@ -0,0 +1,256 @@
+package reverts
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+// ModuleRework is the defect-proneness of one module: the share of its
+// changes that needed a follow-up fix or revert within the fix window.
+type ModuleRework struct {
+	Module    string  `json:"module"     yaml:"module"`
+	Changes   int     `json:"changes"    yaml:"changes"`
+	FollowUps int     `json:"follow_ups" yaml:"follow_ups"`
+	Ratio     float64 `json:"ratio"      yaml:"ratio"`
+}
+
+// RevertRecord is a commit that reverted an earlier one.
+type RevertRecord struct {
+	Hash     string    `json:"hash"     yaml:"hash"`
+	Author   string    `json:"author"   yaml:"author"`
+	Tick     int       `json:"tick"     yaml:"tick"`
+	Time     time.Time `json:"time"     yaml:"time"`
+	Reverted string    `json:"reverted" yaml:"reverted"`
+	Kind     string    `json:"kind"     yaml:"kind"`
+}
+
+// FixChain is a sequence of fixes each of which fixed the one before it.
+type FixChain struct {
+	// Commits and Authors are in history order, the first fix first.
+	Commits []string  `json:"commits" yaml:"commits"`
+	Authors []string  `json:"authors" yaml:"authors"`
+	Length  int       `json:"length"  yaml:"length"`
+	Start   time.Time `json:"start"   yaml:"start"`
+	End     time.Time `json:"end"     yaml:"end"`
+}
+
+// TickRework counts the commits and the rework of one tick.
+type TickRework struct {
+	Tick      int `json:"tick"       yaml:"tick"`
+	Commits   int `json:"commits"    yaml:"commits"`
+	Fixes     int `json:"fixes"      yaml:"fixes"`
+	Reverts   int `json:"reverts"    yaml:"reverts"`
+	FollowUps int `json:"follow_ups" yaml:"follow_ups"`
+}
+
+// ComputedMetrics holds the reverts, fix chains and module defect-proneness
+// of the history.
+type ComputedMetrics struct {
+	Commits int `json:"commits" yaml:"commits"`
+	Fixes   int `json:"fixes"   yaml:"fixes"`
+	// Reverts are in history order.
+	Reverts []RevertRecord `json:"reverts" yaml:"reverts"`
+	// Chains are the fix chains of two or more fixes, longest first.
+	Chains []FixChain `json:"chains" yaml:"chains"`
+	// Modules are ordered by follow-up ratio, highest first.
+	Modules []ModuleRework `json:"modules" yaml:"modules"`
+	Trend   []TickRework   `json:"trend"   yaml:"trend"`
+	// FollowUpRatio is the share of all module changes that needed a
+	// follow-up.
+	FollowUpRatio float64 `json:"follow_up_ratio" yaml:"follow_up_ratio"`
+	FixWindowDays int     `json:"fix_window_days" yaml:"fix_window_days"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNameReverts = "reverts"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNameReverts
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// ComputeAllMetrics computes the reverts, fix chains, module follow-up
+// ratios and trend of a report.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	reworks, _ := report[KeyReworks].([]CommitRework)
+	series, _ := report[KeyTicks].([]TickCommits)
+	modules, _ := report[KeyModules].(map[string]ModuleCounts)
+	names, _ := report[KeyAuthorIndex].([]string)
+	window, _ := report[KeyFixWindowDays].(int)
+
+	m := &ComputedMetrics{FixWindowDays: window}
+	m.Trend = make([]TickRework, len(series))
+	trendIndex := make(map[int]int, len(series))
+
+	for i, point := range series {
+		m.Trend[i] = TickRework{Tick: point.Tick, Commits: point.Commits}
+		trendIndex[point.Tick] = i
+		m.Commits += point.Commits
+	}
+
+	for i := range reworks {
+		rework := &reworks[i]
+
+		var point *TickRework
+		if idx, ok := trendIndex[rework.Tick]; ok {
+			point = &m.Trend[idx]
+		} else {
+			point = &TickRework{}
+		}
+
+		for _, followUps := range rework.FollowUps {
+			point.FollowUps += followUps
+		}
+
+		if rework.Fix {
+			m.Fixes++
+			point.Fixes++
+		}
+
+		if rework.Revert != nil {
+			point.Reverts++
+			m.Reverts = append(m.Reverts, RevertRecord{
+				Hash: rework.Hash.String(), Author: authorName(names, rework.AuthorID),
+				Tick: rework.Tick, Time: rework.Time,
+				Reverted: rework.Revert.Reverted, Kind: rework.Revert.Kind,
+			})
+		}
+	}
+
+	m.Chains = fixChains(reworks, names)
+	m.Modules, m.FollowUpRatio = moduleRework(modules)
+	m.scaleCounts(analyze.SampleFactor(report))
+
+	return m
+}
+
+// scaleCounts scales the commit, fix and change counts of a sampled history
+// by factor to estimate the full history. Ratios and the listed reverts and
+// fix chains are kept.
+func (m *ComputedMetrics) scaleCounts(factor float64) {
+	if factor <= 1 {
+		return
+	}
+
+	scale := func(n *int) { *n = analyze.ScaleCount(*n, factor) }
+
+	scale(&m.Commits)
+	scale(&m.Fixes)
+
+	for i := range m.Modules {
+		scale(&m.Modules[i].Changes)
+		scale(&m.Modules[i].FollowUps)
+	}
+
+	for i := range m.Trend {
+		scale(&m.Trend[i].Commits)
+		scale(&m.Trend[i].Fixes)
+		scale(&m.Trend[i].Reverts)
+		scale(&m.Trend[i].FollowUps)
+	}
+}
+
+// fixChains follows every fix that no later fix continues back to the first
+// fix of its chain. Chains sharing their first fixes are listed separately.
+func fixChains(reworks []CommitRework, names []string) []FixChain {
+	byHash := make(map[string]*CommitRework)
+	continued := make(map[string]bool)
+
+	for i := range reworks {
+		rework := &reworks[i]
+		if !rework.Fix {
+			continue
+		}
+
+		byHash[rework.Hash.String()] = rework
+
+		if rework.Fixes != "" {
+			continued[rework.Fixes] = true
+		}
+	}
+
+	var chains []FixChain
+
+	for i := range reworks {
+		tip := &reworks[i]
+		if !tip.Fix || tip.Fixes == "" || continued[tip.Hash.String()] {
+			continue
+		}
+
+		var links []*CommitRework
+		for link := tip; link != nil; link = byHash[link.Fixes] {
+			links = append(links, link)
+		}
+
+		slices.Reverse(links)
+
+		chain := FixChain{Length: len(links), Start: links[0].Time, End: tip.Time}
+		for _, link := range links {
+			chain.Commits = append(chain.Commits, link.Hash.String())
+			chain.Authors = append(chain.Authors, authorName(names, link.AuthorID))
+		}
+
+		chains = append(chains, chain)
+	}
+
+	slices.SortStableFunc(chains, func(x, y FixChain) int {
+		return cmp.Or(cmp.Compare(y.Length, x.Length), x.Start.Compare(y.Start))
+	})
+
+	return chains
+}
+
+// moduleRework computes the follow-up ratio of every module and of all
+// modules together.
+func moduleRework(modules map[string]ModuleCounts) ([]ModuleRework, float64) {
+	result := make([]ModuleRework, 0, len(modules))
+
+	var changes, followUps int
+
+	for module, counts := range modules {
+		changes += counts.Changes
+		followUps += counts.FollowUps
+		result = append(result, ModuleRework{
+			Module:    module,
+			Changes:   counts.Changes,
+			FollowUps: counts.FollowUps,
+			Ratio:     ratio(counts.FollowUps, counts.Changes),
+		})
+	}
+
+	slices.SortFunc(result, func(x, y ModuleRework) int {
+		return cmp.Or(cmp.Compare(y.Ratio, x.Ratio), cmp.Compare(y.Changes, x.Changes), cmp.Compare(x.Module, y.Module))
+	})
+
+	return result, ratio(followUps, changes)
+}
+
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) / float64(total)
+}
+
+func authorName(names []string, id int) string {
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return identity.AuthorMissingName
+}
@@ -0,0 +1,108 @@
+package reverts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{})
+	assert.Zero(t, m.Commits)
+	assert.Empty(t, m.Reverts)
+	assert.Empty(t, m.Chains)
+	assert.Empty(t, m.Modules)
+	assert.Zero(t, m.FollowUpRatio)
+}
+
+func TestComputeAllMetrics(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := analyze.Report{
+		KeyAuthorIndex:   []string{"alice", "bob"},
+		KeyFixWindowDays: 14,
+		KeyTicks:         []TickCommits{{Tick: 0, Commits: 4}, {Tick: 1, Commits: 6}},
+		KeyModules: map[string]ModuleCounts{
+			"api": {Changes: 8, FollowUps: 4},
+			"db":  {Changes: 2, FollowUps: 2},
+			"ui":  {Changes: 10},
+		},
+		KeyReworks: []CommitRework{
+			{Hash: testHash("a"), Tick: 0, AuthorID: 0, Time: start, Fix: true, Depth: 1, FollowUps: map[string]int{"api": 1}},
+			{
+				Hash: testHash("b"), Tick: 0, AuthorID: 1, Time: start.Add(time.Hour), Fix: true, Depth: 2,
+				Fixes: testHash("a").String(), FollowUps: map[string]int{"api": 1, "db": 1},
+			},
+			{
+				Hash: testHash("c"), Tick: 1, AuthorID: 0, Time: start.Add(day), Fix: true, Depth: 3,
+				Fixes: testHash("b").String(), FollowUps: map[string]int{"api": 1},
+			},
+			{
+				Hash: testHash("d"), Tick: 1, AuthorID: 1, Time: start.Add(2 * day), Fix: true, Depth: 2,
+				Fixes: testHash("a").String(), FollowUps: map[string]int{"api": 1},
+			},
+			{
+				Hash: testHash("e"), Tick: 1, AuthorID: 1, Time: start.Add(3 * day),
+				Revert: &Revert{Reverted: testHash("d").String(), Kind: RevertContent}, FollowUps: map[string]int{"db": 1},
+			},
+		},
+	}
+
+	m := ComputeAllMetrics(report)
+	assert.Equal(t, 10, m.Commits)
+	assert.Equal(t, 4, m.Fixes)
+	assert.Equal(t, 14, m.FixWindowDays)
+	assert.InDelta(t, 0.3, m.FollowUpRatio, 1e-9)
+
+	assert.Equal(t, []TickRework{
+		{Tick: 0, Commits: 4, Fixes: 2, FollowUps: 3},
+		{Tick: 1, Commits: 6, Fixes: 2, Reverts: 1, FollowUps: 3},
+	}, m.Trend)
+
+	require.Len(t, m.Reverts, 1)
+	assert.Equal(t, RevertRecord{
+		Hash: testHash("e").String(), Author: "bob", Tick: 1, Time: start.Add(3 * day),
+		Reverted: testHash("d").String(), Kind: RevertContent,
+	}, m.Reverts[0])
+
+	require.Len(t, m.Chains, 2)
+	assert.Equal(t, 3, m.Chains[0].Length)
+	assert.Equal(t, []string{testHash("a").String(), testHash("b").String(), testHash("c").String()}, m.Chains[0].Commits)
+	assert.Equal(t, []string{"alice", "bob", "alice"}, m.Chains[0].Authors)
+	assert.Equal(t, start, m.Chains[0].Start)
+	assert.Equal(t, start.Add(day), m.Chains[0].End)
+	assert.Equal(t, []string{testHash("a").String(), testHash("d").String()}, m.Chains[1].Commits)
+
+	require.Len(t, m.Modules, 3)
+	assert.Equal(t, ModuleRework{Module: "db", Changes: 2, FollowUps: 2, Ratio: 1}, m.Modules[0])
+	assert.Equal(t, "api", m.Modules[1].Module)
+	assert.InDelta(t, 0.5, m.Modules[1].Ratio, 1e-9)
+	assert.Equal(t, "ui", m.Modules[2].Module)
+}
+
+func TestComputeAllMetrics_ScalesSampledCounts(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		KeyTicks:   []TickCommits{{Tick: 0, Commits: 4}},
+		KeyModules: map[string]ModuleCounts{"db": {Changes: 2, FollowUps: 1}},
+		KeyReworks: []CommitRework{
+			{Hash: testHash("a"), Tick: 0, Fix: true, FollowUps: map[string]int{"db": 1}},
+		},
+		analyze.ReportKeySampleFactor: 2.5,
+	}
+
+	m := ComputeAllMetrics(report)
+	assert.Equal(t, 10, m.Commits)
+	assert.Equal(t, 3, m.Fixes)
+	assert.InDelta(t, 0.5, m.FollowUpRatio, 1e-9)
+	assert.Equal(t, []TickRework{{Tick: 0, Commits: 10, Fixes: 3, FollowUps: 3}}, m.Trend)
+	assert.Equal(t, ModuleRework{Module: "db", Changes: 5, FollowUps: 3, Ratio: 0.5}, m.Modules[0])
+}
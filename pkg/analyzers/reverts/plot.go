@@ -0,0 +1,148 @@
+package reverts
+
+import (
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const (
+	shortHashLen = 8
+	percent      = 100
+)
+
+// RegisterPlotSections registers the reverts plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/reverts", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeAllMetrics(report)
+	window := strconv.Itoa(m.FixWindowDays)
+
+	return []plotpage.Section{
+		{
+			Title:    "Rework Trend",
+			Subtitle: "Fixes, reverts and followed-up changes per tick.",
+			Chart:    plotpage.WrapChart(buildTrendChart(m.Trend)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"<strong>Followed-up changes</strong> = changes that a fix or revert of the same files " +
+						"followed within " + window + " days",
+					"A rising share of fixes means more effort goes into repairing recent work",
+				},
+			},
+		},
+		{
+			Title: "Defect-Prone Modules",
+			Subtitle: formatPercent(m.FollowUpRatio) + " of all module changes needed a follow-up within " +
+				window + " days.",
+			Chart: buildModuleTable(m.Modules),
+		},
+		{
+			Title:    "Reverts",
+			Subtitle: strconv.Itoa(len(m.Reverts)) + " commits reverted earlier commits.",
+			Chart:    buildRevertTable(m.Reverts),
+		},
+		{
+			Title:    "Fix Chains",
+			Subtitle: strconv.Itoa(len(m.Chains)) + " chains of fixes that fixed earlier fixes, longest first.",
+			Chart:    buildChainTable(m.Chains),
+		},
+	}, nil
+}
+
+func buildTrendChart(trend []TickRework) *charts.Bar {
+	labels := make([]string, len(trend))
+	fixes := make([]plotpage.SeriesData, len(trend))
+	reverts := make([]plotpage.SeriesData, len(trend))
+	followUps := make([]plotpage.SeriesData, len(trend))
+
+	for i, point := range trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		fixes[i] = point.Fixes
+		reverts[i] = point.Reverts
+		followUps[i] = point.FollowUps
+	}
+
+	return plotpage.BuildBarChart(nil, labels, []plotpage.BarSeries{
+		{Name: "Fixes", Data: fixes},
+		{Name: "Reverts", Data: reverts},
+		{Name: "Followed-up changes", Data: followUps},
+	}, "Commits")
+}
+
+func buildModuleTable(modules []ModuleRework) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Module", "Changes", "Followed Up", "Follow-Up Ratio"}).
+		WithSearch("Filter modules...")
+
+	for _, module := range modules {
+		table.AddRow(
+			html.EscapeString(module.Module),
+			strconv.Itoa(module.Changes),
+			strconv.Itoa(module.FollowUps),
+			formatPercent(module.Ratio),
+		)
+	}
+
+	return table
+}
+
+func buildRevertTable(reverts []RevertRecord) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Commit", "Author", "Tick", "Reverted", "Detected By"})
+
+	for _, revert := range reverts {
+		table.AddRow(
+			shortHash(revert.Hash),
+			html.EscapeString(revert.Author),
+			strconv.Itoa(revert.Tick),
+			shortHash(revert.Reverted),
+			revert.Kind,
+		)
+	}
+
+	return table
+}
+
+func buildChainTable(chains []FixChain) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Length", "Commits", "Authors", "Start", "End"})
+
+	for _, chain := range chains {
+		commits := make([]string, len(chain.Commits))
+		for i, hash := range chain.Commits {
+			commits[i] = shortHash(hash)
+		}
+
+		table.AddRow(
+			strconv.Itoa(chain.Length),
+			strings.Join(commits, " → "),
+			html.EscapeString(strings.Join(chain.Authors, ", ")),
+			chain.Start.Format(time.DateOnly),
+			chain.End.Format(time.DateOnly),
+		)
+	}
+
+	return table
+}
+
+func formatPercent(ratio float64) string {
+	return strconv.FormatFloat(ratio*percent, 'f', 1, 64) + "%"
+}
+
+func shortHash(hash string) string {
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+
+	return hash
+}
@@ -0,0 +1,234 @@
+package reverts
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Kinds of a Revert.
+const (
+	// RevertMessage is a revert named by the "This reverts commit" line git
+	// revert writes.
+	RevertMessage = "message"
+	// RevertContent is a commit that restores exactly the files an earlier
+	// commit changed.
+	RevertContent = "content"
+)
+
+// Revert names the commit a revert undid.
+type Revert struct {
+	Reverted string `json:"reverted" yaml:"reverted"`
+	Kind     string `json:"kind"     yaml:"kind"`
+}
+
+// CommitRework is the per-commit payload: the modules a commit changed and
+// the rework it represents.
+type CommitRework struct {
+	Hash     gitlib.Hash
+	Tick     int
+	AuthorID int
+	Time     time.Time
+
+	// Modules lists the modules the commit changed, each once.
+	Modules []string
+	// FollowUps counts, per module, the earlier changes this commit is the
+	// first follow-up of.
+	FollowUps map[string]int
+	Fix       bool
+	Revert    *Revert
+	// Depth is the position of a fix in its fix chain: 1 for a fix of
+	// changes that were no fixes, 2 for a fix of a fix, and so on.
+	Depth int
+	// Fixes is the earlier fix of the chain a fix with Depth > 1 follows.
+	Fixes string
+}
+
+// touch is a recent change to a file.
+type touch struct {
+	seq   int
+	hash  string
+	when  time.Time
+	depth int
+}
+
+// recent is a commit within the fix window.
+type recent struct {
+	seq       int
+	hash      string
+	when      time.Time
+	inverse   uint64
+	followups map[string]bool
+}
+
+// tracker remembers the commits of the last fix window and relates every new
+// commit to them.
+type tracker struct {
+	window      time.Duration
+	moduleDepth int
+	fixPattern  *regexp.Regexp
+
+	seq     int
+	commits []*recent
+	bySeq   map[int]*recent
+	inverse map[uint64]*recent
+	files   map[string][]touch
+	// expired counts the commits dropped since files was last swept.
+	expired int
+}
+
+func newTracker(window time.Duration, moduleDepth int, fixPattern *regexp.Regexp) *tracker {
+	return &tracker{
+		window:      window,
+		moduleDepth: moduleDepth,
+		fixPattern:  fixPattern,
+		bySeq:       make(map[int]*recent),
+		inverse:     make(map[uint64]*recent),
+		files:       make(map[string][]touch),
+	}
+}
+
+// observe relates a commit to the commits of the fix window before it and
+// adds it to the window.
+func (t *tracker) observe(hash string, when time.Time, message string, changes gitlib.Changes) *CommitRework {
+	t.expire(when)
+
+	rework := &CommitRework{Fix: t.fixPattern.MatchString(subject(message))}
+	rework.Revert = t.detectRevert(message, changes)
+
+	files := changedFiles(changes)
+	modules := make(map[string]bool, len(files))
+
+	for _, file := range files {
+		module := pkgplumbing.DirPrefix(file, t.moduleDepth)
+		if !modules[module] {
+			modules[module] = true
+			rework.Modules = append(rework.Modules, module)
+		}
+	}
+
+	if rework.Fix || rework.Revert != nil {
+		t.followUp(rework, files)
+	}
+
+	t.seq++
+	current := &recent{seq: t.seq, hash: hash, when: when, inverse: changeSignature(changes, true)}
+	t.commits = append(t.commits, current)
+	t.bySeq[current.seq] = current
+
+	if current.inverse != 0 {
+		t.inverse[current.inverse] = current
+	}
+
+	for _, file := range files {
+		t.files[file] = append(t.files[file], touch{seq: current.seq, hash: hash, when: when, depth: rework.Depth})
+	}
+
+	return rework
+}
+
+// detectRevert recognizes a revert by its message, or by restoring the
+// files of a commit of the fix window.
+func (t *tracker) detectRevert(message string, changes gitlib.Changes) *Revert {
+	if reverted, ok := RevertedHash(message); ok {
+		return &Revert{Reverted: reverted, Kind: RevertMessage}
+	}
+
+	signature := changeSignature(changes, false)
+	if signature == 0 {
+		return nil
+	}
+
+	if original, ok := t.inverse[signature]; ok {
+		return &Revert{Reverted: original.hash, Kind: RevertContent}
+	}
+
+	return nil
+}
+
+// followUp marks the earlier changes to files as followed up by rework and
+// places a fix in its fix chain.
+func (t *tracker) followUp(rework *CommitRework, files []string) {
+	rework.FollowUps = make(map[string]int)
+
+	var parent touch
+
+	for _, file := range files {
+		module := pkgplumbing.DirPrefix(file, t.moduleDepth)
+
+		for _, earlier := range t.files[file] {
+			original := t.bySeq[earlier.seq]
+			if original == nil {
+				continue
+			}
+
+			if !original.followups[module] {
+				if original.followups == nil {
+					original.followups = make(map[string]bool)
+				}
+
+				original.followups[module] = true
+				rework.FollowUps[module]++
+			}
+
+			if earlier.depth > parent.depth || (earlier.depth == parent.depth && earlier.seq > parent.seq) {
+				parent = earlier
+			}
+		}
+	}
+
+	if !rework.Fix {
+		return
+	}
+
+	rework.Depth = parent.depth + 1
+	if parent.depth > 0 {
+		rework.Fixes = parent.hash
+	}
+}
+
+// expire drops the commits older than the fix window before now. Touches of
+// dropped commits are ignored until a sweep removes them; sweeps run once as
+// many commits expired as files are tracked, which keeps them amortized O(1).
+func (t *tracker) expire(now time.Time) {
+	cutoff := now.Add(-t.window)
+
+	drop := 0
+	for drop < len(t.commits) && t.commits[drop].when.Before(cutoff) {
+		old := t.commits[drop]
+		delete(t.bySeq, old.seq)
+
+		if t.inverse[old.inverse] == old {
+			delete(t.inverse, old.inverse)
+		}
+
+		drop++
+	}
+
+	t.commits = t.commits[drop:]
+	t.expired += drop
+
+	if drop == 0 || t.expired < len(t.files) {
+		return
+	}
+
+	t.expired = 0
+
+	for file, touches := range t.files {
+		kept := touches[:0]
+
+		for _, earlier := range touches {
+			if t.bySeq[earlier.seq] != nil {
+				kept = append(kept, earlier)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(t.files, file)
+		} else {
+			t.files[file] = kept
+		}
+	}
+}
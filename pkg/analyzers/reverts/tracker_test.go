@@ -0,0 +1,99 @@
+package reverts
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func modify(name string, from, to gitlib.Hash) gitlib.Changes {
+	return gitlib.Changes{{
+		Action: gitlib.Modify,
+		From:   gitlib.ChangeEntry{Name: name, Hash: from},
+		To:     gitlib.ChangeEntry{Name: name, Hash: to},
+	}}
+}
+
+func newTestTracker() *tracker {
+	return newTracker(7*day, defaultModuleDepth, regexp.MustCompile(pkgplumbing.FixPattern))
+}
+
+func TestTracker_FixChain(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestTracker()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	file := "pkg/cache/lru.go"
+
+	feature := tr.observe("c1", start, "Add LRU cache", modify(file, testHash("1"), testHash("2")))
+	assert.False(t, feature.Fix)
+	assert.Nil(t, feature.Revert)
+	assert.Equal(t, []string{"pkg/cache"}, feature.Modules)
+	assert.Nil(t, feature.FollowUps)
+
+	fix := tr.observe("c2", start.Add(day), "Fix eviction order", modify(file, testHash("2"), testHash("3")))
+	assert.True(t, fix.Fix)
+	assert.Equal(t, 1, fix.Depth)
+	assert.Empty(t, fix.Fixes)
+	assert.Equal(t, map[string]int{"pkg/cache": 1}, fix.FollowUps)
+
+	fixOfFix := tr.observe("c3", start.Add(2*day), "fix: eviction off by one", modify(file, testHash("3"), testHash("4")))
+	assert.True(t, fixOfFix.Fix)
+	assert.Equal(t, 2, fixOfFix.Depth)
+	assert.Equal(t, "c2", fixOfFix.Fixes)
+	assert.Equal(t, map[string]int{"pkg/cache": 1}, fixOfFix.FollowUps, "c1 was followed up already")
+
+	late := tr.observe("c4", start.Add(30*day), "Fix cache size", modify(file, testHash("4"), testHash("5")))
+	assert.Equal(t, 1, late.Depth, "fixes outside the window start a new chain")
+	assert.Empty(t, late.FollowUps)
+}
+
+func TestTracker_Reverts(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestTracker()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tr.observe("c1", start, "Tune pool", modify("pool.go", testHash("1"), testHash("2")))
+
+	undo := tr.observe("c2", start.Add(time.Hour), "Go back to the old pool size", modify("pool.go", testHash("2"), testHash("1")))
+	require.NotNil(t, undo.Revert)
+	assert.Equal(t, Revert{Reverted: "c1", Kind: RevertContent}, *undo.Revert)
+	assert.False(t, undo.Fix)
+	assert.Equal(t, map[string]int{".": 1}, undo.FollowUps)
+
+	named := tr.observe("c3", start.Add(2*time.Hour),
+		"Revert \"Tune pool\"\n\nThis reverts commit abcdef1234567.", modify("other.go", testHash("3"), testHash("4")))
+	require.NotNil(t, named.Revert)
+	assert.Equal(t, Revert{Reverted: "abcdef1234567", Kind: RevertMessage}, *named.Revert)
+
+	unrelated := tr.observe("c4", start.Add(3*time.Hour), "Tweak", modify("pool.go", testHash("1"), testHash("6")))
+	assert.Nil(t, unrelated.Revert)
+}
+
+func TestTracker_Expire(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestTracker()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tr.observe("c1", start, "Add a", modify("a.go", testHash("1"), testHash("2")))
+	tr.observe("c2", start.Add(day), "Add b", modify("b.go", testHash("3"), testHash("4")))
+	tr.observe("c3", start.Add(10*day), "Add c", modify("c.go", testHash("5"), testHash("6")))
+
+	require.Len(t, tr.commits, 1)
+	assert.Equal(t, "c3", tr.commits[0].hash)
+	assert.Len(t, tr.bySeq, 1)
+	assert.Len(t, tr.inverse, 1)
+	assert.NotContains(t, tr.files, "a.go")
+	assert.NotContains(t, tr.files, "b.go")
+
+	revert := tr.observe("c4", start.Add(11*day), "Undo a", modify("a.go", testHash("2"), testHash("1")))
+	assert.Nil(t, revert.Revert, "c1 left the window")
+}
@@ -0,0 +1,411 @@
+// Package sensitive records every change to security-sensitive paths for
+// audit trails.
+package sensitive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Configuration option keys for the sensitive analyzer.
+const (
+	ConfigSensitivePatterns       = "Sensitive.Patterns"
+	ConfigSensitiveMaxCommitLines = "Sensitive.MaxCommitLines"
+	ConfigSensitiveMaxTickChanges = "Sensitive.MaxTickChanges"
+
+	defaultMaxCommitLines = 200
+	defaultMaxTickChanges = 20
+)
+
+// Report keys of the sensitive analyzer.
+const (
+	KeyCommits        = "commits"
+	KeyAuthorIndex    = "author_index"
+	KeyTickSize       = "tick_size"
+	KeyMaxCommitLines = "max_commit_lines"
+	KeyMaxTickChanges = "max_tick_changes"
+
+	// commitSize and fileChangeSize estimate the bytes of one CommitChanges
+	// and one FileChange held by the aggregator.
+	commitSize     = 96
+	fileChangeSize = 96
+)
+
+// Actions of a FileChange.
+const (
+	ActionAdded    = "added"
+	ActionModified = "modified"
+	ActionRenamed  = "renamed"
+	ActionDeleted  = "deleted"
+)
+
+// ErrInvalidPattern indicates a malformed path pattern.
+var ErrInvalidPattern = errors.New("invalid sensitive path pattern")
+
+// FileChange is a change to one sensitive file. Renames list the new path.
+// Mode changes are recorded for every file: Pattern is empty for files that
+// are sensitive only because of their mode change.
+type FileChange struct {
+	File       string                  `json:"file"                 yaml:"file"`
+	Pattern    string                  `json:"pattern"              yaml:"pattern"`
+	Action     string                  `json:"action"               yaml:"action"`
+	Added      int                     `json:"added"                yaml:"added"`
+	Removed    int                     `json:"removed"              yaml:"removed"`
+	Mode       plumbing.ModeChangeKind `json:"mode,omitempty"       yaml:"mode,omitempty"`
+	Suspicious bool                    `json:"suspicious,omitempty" yaml:"suspicious,omitempty"`
+}
+
+// CommitChanges is the per-commit payload: the sensitive files a commit
+// changed.
+type CommitChanges struct {
+	Hash     gitlib.Hash
+	Tick     int
+	AuthorID int
+	Time     time.Time
+	Files    []FileChange
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	Commits []CommitChanges
+}
+
+// Analyzer records the changes to files matching the configured
+// security-sensitive path patterns.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	TreeDiff  *plumbing.TreeDiffAnalyzer
+	LineStats *plumbing.LinesStatsCalculator
+	Ticks     *plumbing.TicksSinceStart
+
+	Patterns       []string
+	MaxCommitLines int
+	MaxTickChanges int
+
+	reversedPeopleDict []string
+	tickSize           time.Duration
+}
+
+// NewAnalyzer creates a new sensitive analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{
+		Patterns:       slices.Clone(defaultPatterns),
+		MaxCommitLines: defaultMaxCommitLines,
+		MaxTickChanges: defaultMaxTickChanges,
+	}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/sensitive",
+			Mode: analyze.ModeHistory,
+			Description: "Records every change to security-sensitive paths and every file mode change with author, " +
+				"tick and diff size, and raises alerts for large changes, bursts of changes and suspicious executables.",
+		},
+		Sequential: false,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, NeedsDiffs: true, Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name: ConfigSensitivePatterns,
+				Description: "Security-sensitive path patterns, separated by commas. A pattern without a slash " +
+					"matches any path element, a trailing \"/**\" matches a whole directory.",
+				Flag:    "sensitive-patterns",
+				Type:    pipeline.StringsConfigurationOption,
+				Default: defaultPatterns,
+			},
+			{
+				Name:        ConfigSensitiveMaxCommitLines,
+				Description: "Alert when one commit adds and removes more lines than this in sensitive files.",
+				Flag:        "sensitive-max-commit-lines",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultMaxCommitLines,
+			},
+			{
+				Name:        ConfigSensitiveMaxTickChanges,
+				Description: "Alert when one tick changes sensitive files more often than this.",
+				Flag:        "sensitive-max-tick-changes",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultMaxTickChanges,
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
+			return ComputeAllMetrics(report), nil
+		},
+		AggregatorFn: newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigSensitivePatterns].([]string); exists {
+		patterns := CleanPatterns(val)
+
+		err := ValidatePatterns(patterns)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPattern, err)
+		}
+
+		if len(patterns) > 0 {
+			a.Patterns = patterns
+		}
+	}
+
+	if val, exists := facts[ConfigSensitiveMaxCommitLines].(int); exists && val > 0 {
+		a.MaxCommitLines = val
+	}
+
+	if val, exists := facts[ConfigSensitiveMaxTickChanges].(int); exists && val > 0 {
+		a.MaxTickChanges = val
+	}
+
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		a.reversedPeopleDict = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		a.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize prepares the analyzer for processing commits.
+func (a *Analyzer) Initialize(_ *gitlib.Repository) error {
+	if len(a.Patterns) == 0 {
+		a.Patterns = slices.Clone(defaultPatterns)
+	}
+
+	return nil
+}
+
+// Consume emits the sensitive files the commit changed with their line
+// statistics. Merge commits are skipped: their changes were recorded on the
+// merged branch.
+func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac == nil || ac.IsMerge {
+		return analyze.TC{}, nil
+	}
+
+	commit := &CommitChanges{}
+	modes := make(map[string]plumbing.ModeChange)
+
+	for _, mode := range a.TreeDiff.ModeChanges() {
+		modes[mode.File] = mode
+	}
+
+	for _, change := range a.TreeDiff.Changes {
+		file, ok := a.fileChange(change, modes)
+		if ok {
+			commit.Files = append(commit.Files, file)
+		}
+	}
+
+	if len(commit.Files) == 0 {
+		return analyze.TC{}, nil
+	}
+
+	tc := analyze.TC{Data: commit}
+
+	if ac.Commit != nil {
+		tc.CommitHash = ac.Commit.Hash()
+	}
+
+	return tc, nil
+}
+
+// fileChange describes change if either of its paths is sensitive, so that
+// moving a file out of a sensitive area is recorded too, or if it changed the
+// file's mode.
+func (a *Analyzer) fileChange(change *gitlib.Change, modes map[string]plumbing.ModeChange) (FileChange, bool) {
+	entry := change.To
+	action := ActionModified
+
+	switch {
+	case change.Action == gitlib.Insert:
+		action = ActionAdded
+	case change.Action == gitlib.Delete:
+		entry, action = change.From, ActionDeleted
+	case change.From.Name != change.To.Name:
+		action = ActionRenamed
+	}
+
+	pattern, ok := matchFile(a.Patterns, entry.Name)
+	if !ok && action == ActionRenamed {
+		pattern, ok = matchFile(a.Patterns, change.From.Name)
+	}
+
+	var mode plumbing.ModeChange
+	if change.Action != gitlib.Delete {
+		mode = modes[entry.Name]
+	}
+
+	if !ok && mode.Kind == "" {
+		return FileChange{}, false
+	}
+
+	stats := a.LineStats.LineStats[entry]
+
+	return FileChange{
+		File:       entry.Name,
+		Pattern:    pattern,
+		Action:     action,
+		Added:      stats.Added,
+		Removed:    stats.Removed,
+		Mode:       mode.Kind,
+		Suspicious: mode.Suspicious,
+	}, true
+}
+
+// Fork creates independent copies of the analyzer for parallel processing.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.LineStats = &plumbing.LinesStatsCalculator{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes:   a.TreeDiff.Changes,
+		LineStats: a.LineStats.LineStats,
+		Tick:      a.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.TreeDiff.Changes = snapshot.Changes
+	a.LineStats.LineStats = snapshot.LineStats
+	a.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot is a no-op for sensitive.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the commits in history order.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	commits := analyze.CollectTickCommits(ticks, tickCommits, commitTime)
+
+	return analyze.Report{
+		KeyCommits:        commits,
+		KeyAuthorIndex:    a.reversedPeopleDict,
+		KeyTickSize:       a.tickSize,
+		KeyMaxCommitLines: a.MaxCommitLines,
+		KeyMaxTickChanges: a.MaxTickChanges,
+	}
+}
+
+// Extract properties for GenericAggregator.
+
+func tickCommits(td *TickData) []CommitChanges { return td.Commits }
+
+func commitTime(c CommitChanges) time.Time { return c.Time }
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	cc, ok := tc.Data.(*CommitChanges)
+	if !ok || cc == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{}
+		byTick[tc.Tick] = state
+	}
+
+	commit := *cc
+	commit.Hash = tc.CommitHash
+	commit.Tick = tc.Tick
+	commit.AuthorID = tc.AuthorID
+	commit.Time = tc.Timestamp
+
+	state.Commits = append(state.Commits, commit)
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming != nil {
+		existing.Commits = append(existing.Commits, incoming.Commits...)
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	var files int
+
+	for _, cc := range state.Commits {
+		files += len(cc.Files)
+	}
+
+	return int64(len(state.Commits))*commitSize + int64(files)*fileChangeSize
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || len(state.Commits) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
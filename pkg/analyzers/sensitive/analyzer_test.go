@@ -0,0 +1,195 @@
+package sensitive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func newTestAnalyzer() *Analyzer {
+	a := NewAnalyzer()
+	a.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+	a.LineStats = &plumbing.LinesStatsCalculator{}
+	a.Ticks = &plumbing.TicksSinceStart{}
+
+	return a
+}
+
+func testHash(c string) gitlib.Hash {
+	return gitlib.NewHash(c + "000000000000000000000000000000000000000")
+}
+
+func TestAnalyzer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	assert.Equal(t, "history/sensitive", a.Name())
+	assert.NotEmpty(t, a.Description())
+	assert.Len(t, a.ListConfigurationOptions(), 3)
+	assert.False(t, a.SequentialOnly())
+}
+
+func TestAnalyzer_Configure(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(map[string]any{
+		ConfigSensitivePatterns:       []string{" billing/** ", "", "*.key"},
+		ConfigSensitiveMaxCommitLines: 50,
+		ConfigSensitiveMaxTickChanges: 3,
+	}))
+	assert.Equal(t, []string{"billing/**", "*.key"}, a.Patterns)
+	assert.Equal(t, 50, a.MaxCommitLines)
+	assert.Equal(t, 3, a.MaxTickChanges)
+
+	require.ErrorIs(t, a.Configure(map[string]any{ConfigSensitivePatterns: []string{"[z-a"}}), ErrInvalidPattern)
+}
+
+func TestAnalyzer_Configure_Defaults(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(map[string]any{ConfigSensitivePatterns: []string{""}}))
+	assert.Equal(t, defaultPatterns, a.Patterns)
+	assert.Equal(t, defaultMaxCommitLines, a.MaxCommitLines)
+	assert.Equal(t, defaultMaxTickChanges, a.MaxTickChanges)
+}
+
+func TestAnalyzer_Consume(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+
+	login := gitlib.ChangeEntry{Name: "pkg/auth/login.go", Hash: testHash("1")}
+	moved := gitlib.ChangeEntry{Name: "pkg/session/token.go", Hash: testHash("2")}
+	key := gitlib.ChangeEntry{Name: "certs/server.key", Hash: testHash("3")}
+	readme := gitlib.ChangeEntry{Name: "README.md", Hash: testHash("4")}
+
+	a.TreeDiff.Changes = gitlib.Changes{
+		{Action: gitlib.Modify, From: login, To: login},
+		{Action: gitlib.Modify, From: gitlib.ChangeEntry{Name: "pkg/auth/token.go"}, To: moved},
+		{Action: gitlib.Delete, From: key},
+		{Action: gitlib.Insert, To: readme},
+	}
+	a.LineStats.LineStats = map[gitlib.ChangeEntry]pkgplumbing.LineStats{
+		login:  {Added: 10, Removed: 4},
+		key:    {Removed: 30},
+		readme: {Added: 5},
+	}
+
+	commit := gitlib.NewTestCommit(testHash("c"), gitlib.Signature{Name: "dev", When: time.Now()}, "change auth")
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	assert.Equal(t, testHash("c"), tc.CommitHash)
+
+	cc, ok := tc.Data.(*CommitChanges)
+	require.True(t, ok)
+	assert.Equal(t, []FileChange{
+		{File: "pkg/auth/login.go", Pattern: "auth", Action: ActionModified, Added: 10, Removed: 4},
+		{File: "pkg/session/token.go", Pattern: "auth", Action: ActionRenamed},
+		{File: "certs/server.key", Pattern: "*.key", Action: ActionDeleted, Removed: 30},
+	}, cc.Files)
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: commit, IsMerge: true})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+}
+
+func TestAnalyzer_Consume_ModeChanges(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+
+	script := gitlib.ChangeEntry{Name: "scripts/deploy.sh", Hash: testHash("1"), Mode: gitlib.FileModeExecutable}
+	link := gitlib.ChangeEntry{Name: "current", Hash: testHash("2"), Mode: gitlib.FileModeSymlink}
+	config := gitlib.ChangeEntry{Name: "config.yaml", Hash: testHash("3"), Mode: gitlib.FileModeRegular}
+	token := gitlib.ChangeEntry{Name: "auth/token.go", Hash: testHash("4"), Mode: gitlib.FileModeRegular}
+	plain := gitlib.ChangeEntry{Name: "docs/guide.md", Hash: testHash("5"), Mode: gitlib.FileModeRegular}
+
+	a.TreeDiff.Changes = gitlib.Changes{
+		{Action: gitlib.Insert, To: script},
+		{Action: gitlib.Insert, To: link},
+		{Action: gitlib.Modify, From: config, To: gitlib.ChangeEntry{Name: config.Name, Hash: config.Hash, Mode: gitlib.FileModeExecutable}},
+		{Action: gitlib.Modify, From: token, To: token},
+		{Action: gitlib.Insert, To: plain},
+	}
+	a.LineStats.LineStats = map[gitlib.ChangeEntry]pkgplumbing.LineStats{script: {Added: 12}}
+
+	commit := gitlib.NewTestCommit(testHash("c"), gitlib.Signature{Name: "dev", When: time.Now()}, "add deploy")
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+
+	cc, ok := tc.Data.(*CommitChanges)
+	require.True(t, ok)
+	assert.Equal(t, []FileChange{
+		{File: "scripts/deploy.sh", Action: ActionAdded, Added: 12, Mode: plumbing.ModeExecutableAdded},
+		{File: "current", Action: ActionAdded, Mode: plumbing.ModeSymlinkAdded},
+		{File: "config.yaml", Action: ActionModified, Mode: plumbing.ModeExecutableAdded, Suspicious: true},
+		{File: "auth/token.go", Pattern: "auth", Action: ActionModified},
+	}, cc.Files)
+}
+
+func TestAnalyzer_Fork(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+	a.Patterns = []string{"vault"}
+
+	forks := a.Fork(2)
+	require.Len(t, forks, 2)
+
+	for _, f := range forks {
+		clone, ok := f.(*Analyzer)
+		require.True(t, ok)
+		assert.NotSame(t, a.TreeDiff, clone.TreeDiff)
+		assert.NotSame(t, a.LineStats, clone.LineStats)
+		assert.Equal(t, []string{"vault"}, clone.Patterns)
+	}
+}
+
+func TestAnalyzer_ReportFromTICKs(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	byTick := make(map[int]*TickData)
+
+	for _, tc := range []analyze.TC{
+		{
+			Tick: 0, AuthorID: 1, CommitHash: testHash("b"), Timestamp: start.Add(time.Hour),
+			Data: &CommitChanges{Files: []FileChange{{File: "auth/a.go", Pattern: "auth", Action: ActionModified, Added: 1}}},
+		},
+		{
+			Tick: 0, AuthorID: 0, CommitHash: testHash("a"), Timestamp: start,
+			Data: &CommitChanges{Files: []FileChange{{File: "auth/b.go", Pattern: "auth", Action: ActionAdded, Added: 9}}},
+		},
+	} {
+		require.NoError(t, extractTC(tc, byTick))
+	}
+
+	tick, err := buildTick(0, byTick[0])
+	require.NoError(t, err)
+
+	a := NewAnalyzer()
+
+	report, err := a.ReportFromTICKs(context.Background(), []analyze.TICK{tick})
+	require.NoError(t, err)
+
+	commits, ok := report[KeyCommits].([]CommitChanges)
+	require.True(t, ok)
+	require.Len(t, commits, 2)
+	assert.Equal(t, testHash("a"), commits[0].Hash)
+	assert.Equal(t, 1, commits[1].AuthorID)
+	assert.Equal(t, defaultMaxCommitLines, report[KeyMaxCommitLines])
+}
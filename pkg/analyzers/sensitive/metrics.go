@@ -0,0 +1,309 @@
+package sensitive
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+// Kinds of an Alert.
+const (
+	// AlertLargeChange flags a commit that changed more lines of sensitive
+	// files than the commit threshold.
+	AlertLargeChange = "large_change"
+	// AlertChangeBurst flags a tick that changed sensitive files more often
+	// than the tick threshold.
+	AlertChangeBurst = "change_burst"
+	// AlertSuspiciousMode flags a commit that made data, document or source
+	// files executable. Value counts the files.
+	AlertSuspiciousMode = "suspicious_mode"
+)
+
+// ChangeRecord is one entry of the audit trail: a commit that changed
+// sensitive files.
+type ChangeRecord struct {
+	Hash    string       `json:"hash"    yaml:"hash"`
+	Author  string       `json:"author"  yaml:"author"`
+	Tick    int          `json:"tick"    yaml:"tick"`
+	Time    time.Time    `json:"time"    yaml:"time"`
+	Added   int          `json:"added"   yaml:"added"`
+	Removed int          `json:"removed" yaml:"removed"`
+	Files   []FileChange `json:"files"   yaml:"files"`
+}
+
+// AreaSummary sums the changes to the files matching one pattern.
+type AreaSummary struct {
+	Pattern string `json:"pattern" yaml:"pattern"`
+	Changes int    `json:"changes" yaml:"changes"`
+	Lines   int    `json:"lines"   yaml:"lines"`
+	Authors int    `json:"authors" yaml:"authors"`
+}
+
+// AuthorSummary sums the changes of one author to sensitive files.
+type AuthorSummary struct {
+	Author  string `json:"author"  yaml:"author"`
+	Commits int    `json:"commits" yaml:"commits"`
+	Changes int    `json:"changes" yaml:"changes"`
+	Lines   int    `json:"lines"   yaml:"lines"`
+}
+
+// TickSummary sums the changes to sensitive files of one tick.
+type TickSummary struct {
+	Tick    int `json:"tick"    yaml:"tick"`
+	Commits int `json:"commits" yaml:"commits"`
+	Changes int `json:"changes" yaml:"changes"`
+	Lines   int `json:"lines"   yaml:"lines"`
+}
+
+// Alert is a commit or a tick that exceeded a threshold. Hash and Author are
+// empty for tick alerts.
+type Alert struct {
+	Kind      string `json:"kind"      yaml:"kind"`
+	Tick      int    `json:"tick"      yaml:"tick"`
+	Hash      string `json:"hash"      yaml:"hash"`
+	Author    string `json:"author"    yaml:"author"`
+	Value     int    `json:"value"     yaml:"value"`
+	Threshold int    `json:"threshold" yaml:"threshold"`
+}
+
+// ComputedMetrics is the audit trail of the changes to sensitive files with
+// its summaries and alerts.
+type ComputedMetrics struct {
+	// Changes lists every commit that changed sensitive files, oldest first.
+	Changes []ChangeRecord  `json:"changes" yaml:"changes"`
+	Trend   []TickSummary   `json:"trend"   yaml:"trend"`
+	Areas   []AreaSummary   `json:"areas"   yaml:"areas"`
+	Authors []AuthorSummary `json:"authors" yaml:"authors"`
+	// Alerts are in history order.
+	Alerts []Alert `json:"alerts" yaml:"alerts"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNameSensitive = "sensitive"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNameSensitive
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// ComputeAllMetrics builds the audit trail of a report and checks it against
+// the thresholds of the report.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	commits, _ := report[KeyCommits].([]CommitChanges)
+	names, _ := report[KeyAuthorIndex].([]string)
+	maxCommitLines, _ := report[KeyMaxCommitLines].(int)
+	maxTickChanges, _ := report[KeyMaxTickChanges].(int)
+
+	m := &ComputedMetrics{}
+	sums := newSummaries()
+
+	for _, cc := range commits {
+		record := newChangeRecord(cc, authorName(names, cc.AuthorID))
+
+		m.Changes = append(m.Changes, record)
+		m.Trend = appendTrend(m.Trend, record)
+		sums.add(record)
+
+		if lines := record.Added + record.Removed; maxCommitLines > 0 && lines > maxCommitLines {
+			m.Alerts = append(m.Alerts, Alert{
+				Kind: AlertLargeChange, Tick: record.Tick, Hash: record.Hash, Author: record.Author,
+				Value: lines, Threshold: maxCommitLines,
+			})
+		}
+
+		if suspicious := countSuspicious(record.Files); suspicious > 0 {
+			m.Alerts = append(m.Alerts, Alert{
+				Kind: AlertSuspiciousMode, Tick: record.Tick, Hash: record.Hash, Author: record.Author,
+				Value: suspicious,
+			})
+		}
+	}
+
+	for pattern, area := range sums.areas {
+		area.Authors = len(sums.areaAuthors[pattern])
+	}
+
+	m.Areas = sortedSummaries(sums.areas, func(x, y *AreaSummary) int {
+		return cmp.Or(cmp.Compare(y.Changes, x.Changes), cmp.Compare(x.Pattern, y.Pattern))
+	})
+	m.Authors = sortedSummaries(sums.authors, func(x, y *AuthorSummary) int {
+		return cmp.Or(cmp.Compare(y.Changes, x.Changes), cmp.Compare(x.Author, y.Author))
+	})
+	m.scaleCounts(analyze.SampleFactor(report))
+	m.Alerts = mergeBurstAlerts(m.Alerts, m.Trend, maxTickChanges)
+
+	return m
+}
+
+// scaleCounts scales the commit and file change counts of a sampled history
+// by factor to estimate the full history, before the change bursts are
+// checked. Line counts come from the diffs, which span the commits skipped
+// between samples, and are kept with the listed changes.
+func (m *ComputedMetrics) scaleCounts(factor float64) {
+	if factor <= 1 {
+		return
+	}
+
+	scale := func(n *int) { *n = analyze.ScaleCount(*n, factor) }
+
+	for i := range m.Trend {
+		scale(&m.Trend[i].Commits)
+		scale(&m.Trend[i].Changes)
+	}
+
+	for i := range m.Areas {
+		scale(&m.Areas[i].Changes)
+	}
+
+	for i := range m.Authors {
+		scale(&m.Authors[i].Commits)
+		scale(&m.Authors[i].Changes)
+	}
+}
+
+// summaries sums the audit trail per area and per author.
+type summaries struct {
+	areas       map[string]*AreaSummary
+	areaAuthors map[string]map[string]bool
+	authors     map[string]*AuthorSummary
+}
+
+func newSummaries() *summaries {
+	return &summaries{
+		areas:       make(map[string]*AreaSummary),
+		areaAuthors: make(map[string]map[string]bool),
+		authors:     make(map[string]*AuthorSummary),
+	}
+}
+
+func (s *summaries) add(record ChangeRecord) {
+	author := s.authors[record.Author]
+	if author == nil {
+		author = &AuthorSummary{Author: record.Author}
+		s.authors[record.Author] = author
+	}
+
+	author.Commits++
+	author.Changes += len(record.Files)
+	author.Lines += record.Added + record.Removed
+
+	for _, file := range record.Files {
+		if file.Pattern == "" {
+			continue
+		}
+
+		area := s.areas[file.Pattern]
+		if area == nil {
+			area = &AreaSummary{Pattern: file.Pattern}
+			s.areas[file.Pattern] = area
+			s.areaAuthors[file.Pattern] = make(map[string]bool)
+		}
+
+		area.Changes++
+		area.Lines += file.Added + file.Removed
+		s.areaAuthors[file.Pattern][record.Author] = true
+	}
+}
+
+func newChangeRecord(cc CommitChanges, author string) ChangeRecord {
+	record := ChangeRecord{
+		Hash:   cc.Hash.String(),
+		Author: author,
+		Tick:   cc.Tick,
+		Time:   cc.Time,
+		Files:  cc.Files,
+	}
+
+	for _, file := range cc.Files {
+		record.Added += file.Added
+		record.Removed += file.Removed
+	}
+
+	return record
+}
+
+func countSuspicious(files []FileChange) int {
+	var count int
+
+	for _, file := range files {
+		if file.Suspicious {
+			count++
+		}
+	}
+
+	return count
+}
+
+// appendTrend adds record to the last point of trend when it is of the same
+// tick.
+func appendTrend(trend []TickSummary, record ChangeRecord) []TickSummary {
+	if n := len(trend); n == 0 || trend[n-1].Tick != record.Tick {
+		trend = append(trend, TickSummary{Tick: record.Tick})
+	}
+
+	point := &trend[len(trend)-1]
+	point.Commits++
+	point.Changes += len(record.Files)
+	point.Lines += record.Added + record.Removed
+
+	return trend
+}
+
+// mergeBurstAlerts adds an alert for every tick of trend with more changes
+// than maxTickChanges after the commit alerts of the same tick.
+func mergeBurstAlerts(alerts []Alert, trend []TickSummary, maxTickChanges int) []Alert {
+	if maxTickChanges <= 0 {
+		return alerts
+	}
+
+	for _, point := range trend {
+		if point.Changes > maxTickChanges {
+			alerts = append(alerts, Alert{
+				Kind: AlertChangeBurst, Tick: point.Tick, Value: point.Changes, Threshold: maxTickChanges,
+			})
+		}
+	}
+
+	slices.SortStableFunc(alerts, func(x, y Alert) int {
+		return cmp.Compare(x.Tick, y.Tick)
+	})
+
+	return alerts
+}
+
+func sortedSummaries[T any](items map[string]*T, compare func(x, y *T) int) []T {
+	sorted := make([]*T, 0, len(items))
+	for _, summary := range items {
+		sorted = append(sorted, summary)
+	}
+
+	slices.SortFunc(sorted, compare)
+
+	result := make([]T, len(sorted))
+	for i, summary := range sorted {
+		result[i] = *summary
+	}
+
+	return result
+}
+
+func authorName(names []string, id int) string {
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return identity.AuthorMissingName
+}
@@ -0,0 +1,123 @@
+package sensitive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{})
+	assert.Empty(t, m.Changes)
+	assert.Empty(t, m.Alerts)
+	assert.Empty(t, m.Areas)
+}
+
+func TestComputeAllMetrics_AuditTrailAndAlerts(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	report := analyze.Report{
+		KeyCommits: []CommitChanges{
+			{Hash: testHash("a"), Tick: 0, AuthorID: 0, Time: start, Files: []FileChange{
+				{File: "auth/login.go", Pattern: "auth", Action: ActionModified, Added: 150, Removed: 80},
+				{File: "certs/ca.pem", Pattern: "*.pem", Action: ActionAdded, Added: 20},
+			}},
+			{Hash: testHash("b"), Tick: 0, AuthorID: 1, Time: start.Add(time.Hour), Files: []FileChange{
+				{File: "auth/token.go", Pattern: "auth", Action: ActionModified, Added: 3, Removed: 1},
+			}},
+			{Hash: testHash("c"), Tick: 2, AuthorID: 7, Time: start.Add(48 * time.Hour), Files: []FileChange{
+				{File: "auth/token.go", Pattern: "auth", Action: ActionDeleted, Removed: 40},
+			}},
+		},
+		KeyAuthorIndex:    []string{"alice", "bob"},
+		KeyMaxCommitLines: 200,
+		KeyMaxTickChanges: 2,
+	}
+
+	m := ComputeAllMetrics(report)
+
+	require.Len(t, m.Changes, 3)
+	assert.Equal(t, "alice", m.Changes[0].Author)
+	assert.Equal(t, 170, m.Changes[0].Added)
+	assert.Equal(t, 80, m.Changes[0].Removed)
+	assert.Equal(t, identity.AuthorMissingName, m.Changes[2].Author)
+
+	assert.Equal(t, []TickSummary{
+		{Tick: 0, Commits: 2, Changes: 3, Lines: 254},
+		{Tick: 2, Commits: 1, Changes: 1, Lines: 40},
+	}, m.Trend)
+
+	assert.Equal(t, []AreaSummary{
+		{Pattern: "auth", Changes: 3, Lines: 274, Authors: 3},
+		{Pattern: "*.pem", Changes: 1, Lines: 20, Authors: 1},
+	}, m.Areas)
+
+	require.Len(t, m.Authors, 3)
+	assert.Equal(t, AuthorSummary{Author: "alice", Commits: 1, Changes: 2, Lines: 250}, m.Authors[0])
+
+	assert.Equal(t, []Alert{
+		{Kind: AlertLargeChange, Tick: 0, Hash: testHash("a").String(), Author: "alice", Value: 250, Threshold: 200},
+		{Kind: AlertChangeBurst, Tick: 0, Value: 3, Threshold: 2},
+	}, m.Alerts)
+
+	sections, err := (&Analyzer{}).GenerateSections(report)
+	require.NoError(t, err)
+	assert.Len(t, sections, 4)
+}
+
+func TestComputeAllMetrics_ModeChanges(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		KeyCommits: []CommitChanges{
+			{Hash: testHash("a"), Tick: 1, AuthorID: 0, Files: []FileChange{
+				{File: "assets/logo.png", Action: ActionModified, Mode: plumbing.ModeExecutableAdded, Suspicious: true},
+				{File: "bin/tool", Action: ActionAdded, Added: 4, Mode: plumbing.ModeExecutableAdded},
+				{File: "auth/login.go", Pattern: "auth", Action: ActionModified, Added: 2},
+			}},
+		},
+		KeyAuthorIndex: []string{"alice"},
+	}
+
+	m := ComputeAllMetrics(report)
+
+	// Files recorded only for their mode change belong to no area.
+	assert.Equal(t, []AreaSummary{{Pattern: "auth", Changes: 1, Lines: 2, Authors: 1}}, m.Areas)
+	assert.Equal(t, []Alert{
+		{Kind: AlertSuspiciousMode, Tick: 1, Hash: testHash("a").String(), Author: "alice", Value: 1},
+	}, m.Alerts)
+}
+
+func TestComputeAllMetrics_ScalesSampledCounts(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		KeyCommits: []CommitChanges{
+			{Hash: testHash("a"), Tick: 0, AuthorID: 0, Files: []FileChange{
+				{File: "auth/login.go", Pattern: "auth", Action: ActionModified, Added: 5, Removed: 1},
+				{File: "auth/token.go", Pattern: "auth", Action: ActionModified, Added: 2},
+			}},
+		},
+		KeyAuthorIndex:                []string{"alice"},
+		KeyMaxTickChanges:             4,
+		analyze.ReportKeySampleFactor: 3.0,
+	}
+
+	m := ComputeAllMetrics(report)
+
+	require.Len(t, m.Changes, 1)
+	assert.Equal(t, []TickSummary{{Tick: 0, Commits: 3, Changes: 6, Lines: 8}}, m.Trend)
+	assert.Equal(t, []AreaSummary{{Pattern: "auth", Changes: 6, Lines: 8, Authors: 1}}, m.Areas)
+	assert.Equal(t, []AuthorSummary{{Author: "alice", Commits: 3, Changes: 6, Lines: 8}}, m.Authors)
+	assert.Equal(t, []Alert{{Kind: AlertChangeBurst, Tick: 0, Value: 6, Threshold: 4}}, m.Alerts)
+}
@@ -0,0 +1,99 @@
+package sensitive
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// defaultPatterns cover the areas most repositories treat as security
+// critical when no patterns are configured.
+var defaultPatterns = []string{
+	"auth",
+	"crypto",
+	"security",
+	"secrets",
+	"*.pem",
+	"*.key",
+	".github/workflows/**",
+}
+
+// DefaultPatterns returns a copy of the built-in sensitive path patterns.
+func DefaultPatterns() []string {
+	return slices.Clone(defaultPatterns)
+}
+
+// CleanPatterns trims the patterns and drops empty ones.
+func CleanPatterns(patterns []string) []string {
+	result := make([]string, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			result = append(result, pattern)
+		}
+	}
+
+	return result
+}
+
+// ValidatePatterns reports the first pattern MatchPattern cannot read.
+// Callers wrap the error with their own sentinel.
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		_, err := path.Match(strings.TrimSuffix(pattern, "/**"), "")
+		if err != nil {
+			return fmt.Errorf("%q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// matchFile returns the first of patterns that matches file.
+func matchFile(patterns []string, file string) (string, bool) {
+	for _, pattern := range patterns {
+		if MatchPattern(pattern, file) {
+			return pattern, true
+		}
+	}
+
+	return "", false
+}
+
+// MatchPattern reports whether the slash-separated file matches pattern.
+// Like in .gitignore, a pattern without a slash matches any element of the
+// path, and a pattern with a slash matches the whole path. A trailing "/**"
+// matches every file below the directories the rest of the pattern matches.
+func MatchPattern(pattern, file string) bool {
+	prefix, tree := strings.CutSuffix(pattern, "/**")
+
+	if !strings.Contains(prefix, "/") {
+		elements := strings.Split(file, "/")
+		if tree {
+			elements = elements[:len(elements)-1]
+		}
+
+		for _, element := range elements {
+			if matched, _ := path.Match(prefix, element); matched {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if !tree {
+		matched, _ := path.Match(prefix, file)
+
+		return matched
+	}
+
+	for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if matched, _ := path.Match(prefix, dir); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package sensitive
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"auth", "pkg/auth/login.go", true},
+		{"auth", "auth", true},
+		{"auth", "pkg/author/name.go", false},
+		{"*.pem", "deploy/certs/server.pem", true},
+		{"*.pem", "server.pem.txt", false},
+		{"secrets/**", "config/secrets/prod.yaml", true},
+		{"secrets/**", "config/secrets", false},
+		{".github/workflows/**", ".github/workflows/release.yml", true},
+		{".github/workflows/**", "docs/.github/workflows/release.yml", false},
+		{"internal/*/keys.go", "internal/crypto/keys.go", true},
+		{"internal/*/keys.go", "pkg/internal/crypto/keys.go", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchPattern(tt.pattern, tt.file), "%s ~ %s", tt.pattern, tt.file)
+	}
+}
+
+func TestMatchFile_FirstPatternWins(t *testing.T) {
+	t.Parallel()
+
+	pattern, ok := matchFile([]string{"crypto", "*.go"}, "pkg/crypto/aes.go")
+	require.True(t, ok)
+	assert.Equal(t, "crypto", pattern)
+
+	_, ok = matchFile([]string{"crypto"}, "pkg/http/server.go")
+	assert.False(t, ok)
+}
+
+func TestValidatePatterns(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidatePatterns(defaultPatterns))
+	require.ErrorIs(t, ValidatePatterns([]string{"auth", "[z-a"}), path.ErrBadPattern)
+	assert.Equal(t, []string{"auth", "crypto/**"}, CleanPatterns([]string{" auth ", "", "crypto/**"}))
+}
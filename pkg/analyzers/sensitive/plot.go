@@ -0,0 +1,137 @@
+package sensitive
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const shortHashLen = 8
+
+// RegisterPlotSections registers the sensitive plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/sensitive", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeAllMetrics(report)
+
+	return []plotpage.Section{
+		{
+			Title:    "Sensitive Changes",
+			Subtitle: "Changes to security-sensitive files per tick.",
+			Chart:    plotpage.WrapChart(buildChangeTrendChart(m.Trend)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"<strong>Changes</strong> = files matching a sensitive pattern that a commit added, modified, renamed or deleted",
+					"Files that became executable or symbolic links are included wherever they are",
+					"Spikes deserve a second look: bulk edits to auth or crypto code are easy to under-review",
+				},
+			},
+		},
+		{
+			Title:    "Alerts",
+			Subtitle: strconv.Itoa(len(m.Alerts)) + " commits and ticks exceeded a threshold.",
+			Chart:    buildAlertTable(m.Alerts),
+		},
+		{
+			Title:    "Sensitive Areas",
+			Subtitle: "Changes per pattern, most changed first.",
+			Chart:    buildAreaTable(m.Areas),
+		},
+		{
+			Title:    "Audit Trail",
+			Subtitle: strconv.Itoa(len(m.Changes)) + " commits changed sensitive files.",
+			Chart:    buildAuditTable(m.Changes),
+		},
+	}, nil
+}
+
+func buildChangeTrendChart(trend []TickSummary) *charts.Bar {
+	labels := make([]string, len(trend))
+	changes := make([]plotpage.SeriesData, len(trend))
+
+	for i, point := range trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		changes[i] = point.Changes
+	}
+
+	return plotpage.BuildBarChart(nil, labels, []plotpage.BarSeries{{Name: "Changes", Data: changes}}, "Changes")
+}
+
+func buildAlertTable(alerts []Alert) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Kind", "Tick", "Commit", "Author", "Value", "Threshold"})
+
+	for _, alert := range alerts {
+		table.AddRow(
+			alert.Kind,
+			strconv.Itoa(alert.Tick),
+			shortHash(alert.Hash),
+			html.EscapeString(alert.Author),
+			strconv.Itoa(alert.Value),
+			strconv.Itoa(alert.Threshold),
+		)
+	}
+
+	return table
+}
+
+func buildAreaTable(areas []AreaSummary) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Pattern", "Changes", "Lines", "Authors"})
+
+	for _, area := range areas {
+		table.AddRow(
+			html.EscapeString(area.Pattern),
+			strconv.Itoa(area.Changes),
+			strconv.Itoa(area.Lines),
+			strconv.Itoa(area.Authors),
+		)
+	}
+
+	return table
+}
+
+func buildAuditTable(changes []ChangeRecord) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Commit", "Author", "Tick", "Added", "Removed", "Files"}).
+		WithSearch("Filter changes...")
+
+	for _, record := range changes {
+		files := make([]string, len(record.Files))
+		for i, file := range record.Files {
+			action := file.Action
+			if file.Mode != "" {
+				action += ", " + string(file.Mode)
+			}
+
+			files[i] = html.EscapeString(file.File) + " (" + action + ")"
+		}
+
+		table.AddRow(
+			shortHash(record.Hash),
+			html.EscapeString(record.Author),
+			strconv.Itoa(record.Tick),
+			"+"+strconv.Itoa(record.Added),
+			"-"+strconv.Itoa(record.Removed),
+			strings.Join(files, "<br>"),
+		)
+	}
+
+	return table
+}
+
+func shortHash(hash string) string {
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+
+	return hash
+}
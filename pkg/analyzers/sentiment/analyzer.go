@@ -3,6 +3,7 @@ package sentiment
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"maps"
 	"regexp"
@@ -26,6 +27,8 @@ import (
 type CommitResult struct {
 	// Comments contains filtered comment texts from this commit's UAST changes.
 	Comments []string
+	// Language is the detected natural language of the commit message.
+	Language string
 }
 
 // TickData is the per-tick aggregated payload for the sentiment analyzer.
@@ -33,6 +36,8 @@ type CommitResult struct {
 type TickData struct {
 	// CommentsByCommit maps commit hash (hex) to comment texts.
 	CommentsByCommit map[string][]string
+	// LanguageByCommit maps commit hash (hex) to its message language.
+	LanguageByCommit map[string]string
 }
 
 // MinCommentLengthThresholdHigh is the minimum character length for a comment to be included in sentiment analysis.
@@ -70,6 +75,7 @@ type Analyzer struct {
 
 	UAST             *plumbing.UASTChangesAnalyzer
 	Ticks            *plumbing.TicksSinceStart
+	MessageLanguage  *plumbing.MessageLanguageDetector
 	commitsByTick    map[int][]gitlib.Hash
 	MinCommentLength int
 	Gap              float32
@@ -85,6 +91,7 @@ func NewAnalyzer() *Analyzer {
 			Mode:        analyze.ModeHistory,
 		},
 		Sequential: false,
+		Caps:       &analyze.Capabilities{NeedsUAST: true, Memory: analyze.MemoryLow},
 		ConfigOptions: []pipeline.ConfigurationOption{
 			{
 				Name:        ConfigCommentSentimentMinLength,
@@ -200,12 +207,17 @@ func (s *Analyzer) Initialize(_ *gitlib.Repository) error {
 
 // Consume processes a single commit and returns a TC with extracted comments.
 // The analyzer does not retain any per-commit state; all output is in the TC.
+// It stops between files once ctx is done.
 func (s *Analyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
 	changes := s.UAST.Changes(ctx)
 
 	var commentNodes []*node.Node
 
 	for _, change := range changes {
+		if ctx.Err() != nil {
+			return analyze.TC{}, fmt.Errorf("sentiment: %w", ctx.Err())
+		}
+
 		if change.After != nil {
 			extractComments(change.After, &commentNodes)
 		}
@@ -213,8 +225,13 @@ func (s *Analyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC
 
 	comments := s.mergeComments(commentNodes)
 
+	result := &CommitResult{Comments: comments}
+	if s.MessageLanguage != nil {
+		result.Language = s.MessageLanguage.Language
+	}
+
 	tc := analyze.TC{
-		Data: &CommitResult{Comments: comments},
+		Data: result,
 	}
 
 	if ac != nil && ac.Commit != nil {
@@ -378,6 +395,7 @@ func (s *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
 		clone := &Analyzer{
 			UAST:             &plumbing.UASTChangesAnalyzer{},
 			Ticks:            &plumbing.TicksSinceStart{},
+			MessageLanguage:  &plumbing.MessageLanguageDetector{},
 			MinCommentLength: s.MinCommentLength,
 			Gap:              s.Gap,
 			commitsByTick:    s.commitsByTick, // shared read-only.
@@ -391,15 +409,18 @@ func (s *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
 // Merge is a no-op. Per-commit results are emitted as TCs.
 func (s *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
 
-// NeedsUAST returns true to enable the UAST pipeline.
-func (s *Analyzer) NeedsUAST() bool { return true }
-
 // SnapshotPlumbing captures the current plumbing output state for parallel execution.
 func (s *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
-	return plumbing.Snapshot{
+	snap := plumbing.Snapshot{
 		UASTChanges: s.UAST.TransferChanges(),
 		Tick:        s.Ticks.Tick,
 	}
+
+	if s.MessageLanguage != nil {
+		snap.MessageLanguage = s.MessageLanguage.Language
+	}
+
+	return snap
 }
 
 // ApplySnapshot restores plumbing state from a previously captured snapshot.
@@ -411,6 +432,10 @@ func (s *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
 
 	s.UAST.SetChanges(ss.UASTChanges)
 	s.Ticks.Tick = ss.Tick
+
+	if s.MessageLanguage != nil {
+		s.MessageLanguage.Language = ss.MessageLanguage
+	}
 }
 
 // ReleaseSnapshot releases UAST trees owned by the snapshot.
@@ -463,6 +488,7 @@ func (s *Analyzer) ExtractCommitTimeSeries(report analyze.Report) map[string]any
 
 type tickAccumulator struct {
 	commentsByCommit map[string][]string
+	languageByCommit map[string]string
 	startTime        time.Time
 	endTime          time.Time
 }
@@ -481,6 +507,7 @@ func extractTC(tc analyze.TC, byTick map[int]*tickAccumulator) error {
 	if !ok {
 		acc = &tickAccumulator{
 			commentsByCommit: make(map[string][]string),
+			languageByCommit: make(map[string]string),
 			startTime:        tc.Timestamp,
 			endTime:          tc.Timestamp,
 		}
@@ -500,6 +527,10 @@ func extractTC(tc analyze.TC, byTick map[int]*tickAccumulator) error {
 	cr, isCommitResult := tc.Data.(*CommitResult)
 	if isCommitResult {
 		acc.commentsByCommit[tc.CommitHash.String()] = cr.Comments
+
+		if cr.Language != "" {
+			acc.languageByCommit[tc.CommitHash.String()] = cr.Language
+		}
 	} else {
 		acc.commentsByCommit[tc.CommitHash.String()] = []string{}
 	}
@@ -524,6 +555,14 @@ func mergeState(existing, incoming *tickAccumulator) *tickAccumulator {
 		maps.Copy(existing.commentsByCommit, incoming.commentsByCommit)
 	}
 
+	if incoming.languageByCommit != nil {
+		if existing.languageByCommit == nil {
+			existing.languageByCommit = make(map[string]string)
+		}
+
+		maps.Copy(existing.languageByCommit, incoming.languageByCommit)
+	}
+
 	if !incoming.startTime.IsZero() && (incoming.startTime.Before(existing.startTime) || existing.startTime.IsZero()) {
 		existing.startTime = incoming.startTime
 	}
@@ -560,7 +599,7 @@ func buildTick(tick int, state *tickAccumulator) (analyze.TICK, error) {
 		Tick:      tick,
 		StartTime: state.startTime,
 		EndTime:   state.endTime,
-		Data:      &TickData{CommentsByCommit: state.commentsByCommit},
+		Data:      &TickData{CommentsByCommit: state.commentsByCommit, LanguageByCommit: state.languageByCommit},
 	}, nil
 }
 
@@ -585,6 +624,7 @@ func ticksToReport(_ context.Context, ticks []analyze.TICK, commitsByTick map[in
 	return analyze.Report{
 		"comments_by_commit": commentsByCommit,
 		"commits_by_tick":    ct,
+		"language_by_commit": buildLanguageByCommitFromTicks(ticks),
 	}
 }
 
@@ -603,6 +643,21 @@ func buildCommentsByCommitFromTicks(ticks []analyze.TICK) map[string][]string {
 	return commentsByCommit
 }
 
+func buildLanguageByCommitFromTicks(ticks []analyze.TICK) map[string]string {
+	languageByCommit := make(map[string]string)
+
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*TickData)
+		if !ok || td == nil || td.LanguageByCommit == nil {
+			continue
+		}
+
+		maps.Copy(languageByCommit, td.LanguageByCommit)
+	}
+
+	return languageByCommit
+}
+
 func buildCommitsByTickFromTicks(ticks []analyze.TICK) map[int][]gitlib.Hash {
 	ct := make(map[int][]gitlib.Hash)
 
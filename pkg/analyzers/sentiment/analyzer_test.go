@@ -100,6 +100,33 @@ func TestAnalyzer_Consume_ReturnsTCWithComments(t *testing.T) {
 	assert.Equal(t, "This is a good comment", cr.Comments[0])
 }
 
+func TestAnalyzer_Consume_RecordsMessageLanguage(t *testing.T) {
+	t.Parallel()
+
+	s := &Analyzer{
+		UAST:             &plumbing.UASTChangesAnalyzer{},
+		Ticks:            &plumbing.TicksSinceStart{},
+		MessageLanguage:  &plumbing.MessageLanguageDetector{Language: "fr"},
+		MinCommentLength: 10,
+	}
+	require.NoError(t, s.Initialize(nil))
+	s.UAST.SetChangesForTest(nil)
+
+	hash1 := gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	commit1 := gitlib.NewTestCommit(hash1, gitlib.TestSignature("dev", "dev@test.com"), "test")
+
+	tc, err := s.Consume(context.Background(), &analyze.Context{Commit: commit1})
+	require.NoError(t, err)
+
+	cr, ok := tc.Data.(*CommitResult)
+	require.True(t, ok, "TC.Data should be *CommitResult")
+	assert.Equal(t, "fr", cr.Language)
+
+	snap, ok := s.SnapshotPlumbing().(plumbing.Snapshot)
+	require.True(t, ok)
+	assert.Equal(t, "fr", snap.MessageLanguage)
+}
+
 func TestAnalyzer_Consume_FiltersShortComments(t *testing.T) {
 	t.Parallel()
 
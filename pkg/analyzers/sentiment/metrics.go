@@ -5,7 +5,9 @@ import (
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/anomaly"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/sentiment/lexicons"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 // RegisterTimeSeriesExtractor registers the sentiment analyzer's time series
@@ -77,9 +79,11 @@ func AggregateCommitsToTicks(
 
 // ReportData is the parsed input data for sentiment metrics computation.
 type ReportData struct {
-	EmotionsByTick map[int]float32
-	CommentsByTick map[int][]string
-	CommitsByTick  map[int][]gitlib.Hash
+	EmotionsByTick   map[int]float32
+	CommentsByTick   map[int][]string
+	CommitsByTick    map[int][]gitlib.Hash
+	CommentsByCommit map[string][]string
+	LanguageByCommit map[string]string
 }
 
 // ParseReportData extracts ReportData from an analyzer report.
@@ -92,6 +96,11 @@ func ParseReportData(report analyze.Report) (*ReportData, error) {
 	}
 
 	commentsByCommit, hasCommit := report["comments_by_commit"].(map[string][]string)
+	data.CommentsByCommit = commentsByCommit
+
+	if v, ok := report["language_by_commit"].(map[string]string); ok {
+		data.LanguageByCommit = v
+	}
 
 	if hasCommit && len(commentsByCommit) > 0 && len(data.CommitsByTick) > 0 {
 		data.CommentsByTick, data.EmotionsByTick = AggregateCommitsToTicks(
@@ -150,6 +159,17 @@ type AggregateData struct {
 	NegativeTicks    int     `json:"negative_ticks"    yaml:"negative_ticks"`
 }
 
+// LanguageData summarizes one natural language: the commits whose messages
+// are written in it, and the added comments written in it with their
+// sentiment.
+type LanguageData struct {
+	Language  string  `json:"language"  yaml:"language"`
+	Name      string  `json:"name"      yaml:"name"`
+	Commits   int     `json:"commits"   yaml:"commits"`
+	Comments  int     `json:"comments"  yaml:"comments"`
+	Sentiment float32 `json:"sentiment" yaml:"sentiment"`
+}
+
 // --- Computed Metrics ---.
 
 // ComputedMetrics holds all computed metric results for the sentiment analyzer.
@@ -158,6 +178,7 @@ type ComputedMetrics struct {
 	Trend               TrendData                `json:"trend"                 yaml:"trend"`
 	LowSentimentPeriods []LowSentimentPeriodData `json:"low_sentiment_periods" yaml:"low_sentiment_periods"`
 	Aggregate           AggregateData            `json:"aggregate"             yaml:"aggregate"`
+	Languages           []LanguageData           `json:"languages,omitempty"   yaml:"languages,omitempty"`
 }
 
 const analyzerNameSentiment = "sentiment"
@@ -184,12 +205,35 @@ func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
 		return nil, err
 	}
 
-	return &ComputedMetrics{
+	m := &ComputedMetrics{
 		TimeSeries:          computeTimeSeries(input),
 		Trend:               computeTrend(input),
 		LowSentimentPeriods: computeLowSentimentPeriods(input),
 		Aggregate:           computeAggregate(input),
-	}, nil
+		Languages:           computeLanguages(input),
+	}
+	m.scaleCounts(analyze.SampleFactor(report))
+
+	return m, nil
+}
+
+// scaleCounts scales the commit counts of a sampled history by factor to
+// estimate the full history. Comments come from the diffs, which span the
+// commits skipped between samples, so their counts are kept.
+func (m *ComputedMetrics) scaleCounts(factor float64) {
+	if factor <= 1 {
+		return
+	}
+
+	m.Aggregate.TotalCommits = analyze.ScaleCount(m.Aggregate.TotalCommits, factor)
+
+	for i := range m.TimeSeries {
+		m.TimeSeries[i].CommitCount = analyze.ScaleCount(m.TimeSeries[i].CommitCount, factor)
+	}
+
+	for i := range m.Languages {
+		m.Languages[i].Commits = analyze.ScaleCount(m.Languages[i].Commits, factor)
+	}
 }
 
 // --- Metric Implementations ---.
@@ -415,3 +459,74 @@ func computeAggregate(input *ReportData) AggregateData {
 
 	return agg
 }
+
+// computeLanguages counts commits by the language of their messages and
+// comments by the language each comment is written in, scoring every
+// language's comments together; most commits first. Reports recorded without
+// message languages yield none.
+func computeLanguages(input *ReportData) []LanguageData {
+	if len(input.LanguageByCommit) == 0 {
+		return nil
+	}
+
+	commentsByLanguage := make(map[string][]string)
+	commitsByLanguage := make(map[string]int)
+
+	for hash, lang := range input.LanguageByCommit {
+		commitsByLanguage[lang]++
+
+		for _, comment := range input.CommentsByCommit[hash] {
+			commentLang := pkgplumbing.DetectTextLanguage(comment)
+			commentsByLanguage[commentLang] = append(commentsByLanguage[commentLang], comment)
+		}
+	}
+
+	for lang := range commentsByLanguage {
+		if _, ok := commitsByLanguage[lang]; !ok {
+			commitsByLanguage[lang] = 0
+		}
+	}
+
+	languages := make([]LanguageData, 0, len(commitsByLanguage))
+
+	for lang, commits := range commitsByLanguage {
+		data := LanguageData{
+			Language: lang,
+			Name:     languageName(lang),
+			Commits:  commits,
+			Comments: len(commentsByLanguage[lang]),
+		}
+
+		if data.Comments > 0 {
+			data.Sentiment = ComputeSentiment(commentsByLanguage[lang])
+		}
+
+		languages = append(languages, data)
+	}
+
+	sort.Slice(languages, func(i, j int) bool {
+		if languages[i].Commits != languages[j].Commits {
+			return languages[i].Commits > languages[j].Commits
+		}
+
+		if languages[i].Comments != languages[j].Comments {
+			return languages[i].Comments > languages[j].Comments
+		}
+
+		return languages[i].Language < languages[j].Language
+	})
+
+	return languages
+}
+
+// languageName returns the display name of a message language code.
+func languageName(lang string) string {
+	switch lang {
+	case "en":
+		return "English"
+	case pkgplumbing.MessageLanguageUnknown:
+		return "Undetermined"
+	default:
+		return lexicons.LanguageName(lexicons.Language(lang))
+	}
+}
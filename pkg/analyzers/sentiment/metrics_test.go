@@ -524,7 +524,7 @@ func TestComputeAllMetrics_FromCommitData(t *testing.T) {
 
 	report := analyze.Report{
 		"comments_by_commit": map[string][]string{
-			testHashA: {"good work on this", "nice refactor here"},
+			testHashA: {"good work on this", "отлично"},
 			testHashB: {"this code is broken"},
 		},
 		"commits_by_tick": map[int][]gitlib.Hash{
@@ -541,6 +541,66 @@ func TestComputeAllMetrics_FromCommitData(t *testing.T) {
 	assert.Equal(t, 3, result.Aggregate.TotalComments)
 }
 
+func TestComputeAllMetrics_Languages(t *testing.T) {
+	t.Parallel()
+
+	const testHashC = "cccccccccccccccccccccccccccccccccccccccc"
+
+	report := analyze.Report{
+		"comments_by_commit": map[string][]string{
+			testHashA: {"good work on this", "отлично"},
+			testHashB: {"this code is broken"},
+			testHashC: {},
+		},
+		"commits_by_tick": map[int][]gitlib.Hash{
+			0: {gitlib.NewHash(testHashA), gitlib.NewHash(testHashB), gitlib.NewHash(testHashC)},
+		},
+		"language_by_commit": map[string]string{testHashA: "en", testHashB: "en", testHashC: "de"},
+	}
+
+	result, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+
+	require.Len(t, result.Languages, 3)
+	assert.Equal(t, "en", result.Languages[0].Language)
+	assert.Equal(t, "English", result.Languages[0].Name)
+	assert.Equal(t, 2, result.Languages[0].Commits)
+	assert.Equal(t, 2, result.Languages[0].Comments, "comments are grouped by their own language")
+	assert.Positive(t, result.Languages[0].Sentiment)
+	assert.Equal(t, LanguageData{Language: "de", Name: "German", Commits: 1}, result.Languages[1])
+	assert.Equal(t, "ru", result.Languages[2].Language)
+	assert.Equal(t, 0, result.Languages[2].Commits)
+	assert.Equal(t, 1, result.Languages[2].Comments)
+	assert.Greater(t, result.Languages[2].Sentiment, float32(SentimentPositiveThreshold))
+}
+
+func TestComputeAllMetrics_ScalesSampledCommits(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		"comments_by_commit": map[string][]string{
+			testHashA: {"good work on this"},
+			testHashB: {"this code is broken"},
+		},
+		"commits_by_tick": map[int][]gitlib.Hash{
+			0: {gitlib.NewHash(testHashA), gitlib.NewHash(testHashB)},
+		},
+		"language_by_commit":          map[string]string{testHashA: "en", testHashB: "en"},
+		analyze.ReportKeySampleFactor: 3.0,
+	}
+
+	result, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+
+	require.Len(t, result.TimeSeries, 1)
+	assert.Equal(t, 6, result.TimeSeries[0].CommitCount)
+	assert.Equal(t, 2, result.TimeSeries[0].CommentCount)
+	assert.Equal(t, 6, result.Aggregate.TotalCommits)
+	assert.Equal(t, 2, result.Aggregate.TotalComments)
+	require.NotEmpty(t, result.Languages)
+	assert.Equal(t, 6, result.Languages[0].Commits)
+}
+
 // --- ComputeAllMetrics Tests ---.
 
 func TestComputeAllMetrics_Empty(t *testing.T) {
@@ -8,32 +8,53 @@ import (
 	"github.com/jonreiter/govader"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/sentiment/lexicons"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 var (
-	vaderAnalyzer *govader.SentimentIntensityAnalyzer
-	vaderOnce     sync.Once
+	vaderMu        sync.Mutex
+	vaderAnalyzers = make(map[string]*govader.SentimentIntensityAnalyzer)
 )
 
-func getVaderAnalyzer() *govader.SentimentIntensityAnalyzer {
-	vaderOnce.Do(func() {
-		vaderAnalyzer = govader.NewSentimentIntensityAnalyzer()
-		injectMultilingualLexicons(vaderAnalyzer)
-	})
+// plainVader is the cache key of VADER without any injected lexicon.
+const plainVader = ""
 
-	return vaderAnalyzer
+// getVaderAnalyzer returns the VADER analyzer for comments written in lang,
+// an ISO 639-1 code from pkgplumbing.DetectTextLanguage. Languages with a
+// lexicon get VADER extended with that lexicon only; English, undetermined
+// and unsupported languages share plain VADER. Analyzers are built once per
+// language.
+func getVaderAnalyzer(lang string) *govader.SentimentIntensityAnalyzer {
+	vaderMu.Lock()
+	defer vaderMu.Unlock()
+
+	if sia, ok := vaderAnalyzers[lang]; ok {
+		return sia
+	}
+
+	sia := vaderAnalyzers[plainVader]
+	if sia == nil {
+		sia = govader.NewSentimentIntensityAnalyzer()
+		vaderAnalyzers[plainVader] = sia
+	}
+
+	if entries := lexicons.ForLanguage(lexicons.Language(lang)); entries != nil {
+		sia = govader.NewSentimentIntensityAnalyzer()
+		injectLexicon(sia, entries)
+	}
+
+	vaderAnalyzers[lang] = sia
+
+	return sia
 }
 
-// injectMultilingualLexicons adds multilingual sentiment entries to VADER's lexicon.
-// VADER's Lexicon field is a public map[string]float64 — we inject non-English
-// entries from the Chen-Skiena dataset so that VADER can score comments in
-// 32+ languages without requiring translation.
+// injectLexicon adds the entries of one language's Chen-Skiena lexicon to
+// VADER's lexicon. VADER's Lexicon field is a public map[string]float64, so
+// comments in that language can be scored without translation.
 //
 // Only words containing non-ASCII characters are injected to avoid overriding
 // VADER's built-in English lexicon with lower-quality bilingual entries.
-func injectMultilingualLexicons(sia *govader.SentimentIntensityAnalyzer) {
-	entries := lexicons.All()
-
+func injectLexicon(sia *govader.SentimentIntensityAnalyzer, entries []lexicons.Entry) {
 	for _, entry := range entries {
 		if isASCIIOnly(entry.Word) {
 			continue
@@ -166,7 +187,8 @@ func applySEDomainAdjustment(text string, compound float64) float64 {
 
 // ComputeSentiment returns a score in [0, 1] for the given comments.
 // 0 = negative, 0.5 = neutral, 1 = positive.
-// Uses VADER with SE-domain adjustments for NLP-based analysis.
+// Uses VADER with SE-domain adjustments for NLP-based analysis; each comment
+// is scored with the lexicon of the language it is written in.
 // Empty comments yield 0 (no comment implies no sentiment signal).
 // Comments are weighted by length (longer comments carry more signal).
 func ComputeSentiment(comments []string) float32 {
@@ -174,8 +196,6 @@ func ComputeSentiment(comments []string) float32 {
 		return 0
 	}
 
-	analyzer := getVaderAnalyzer()
-
 	var weightedSum float64
 
 	var totalWeight float64
@@ -188,7 +208,7 @@ func ComputeSentiment(comments []string) float32 {
 			continue
 		}
 
-		scores := analyzer.PolarityScores(c)
+		scores := getVaderAnalyzer(pkgplumbing.DetectTextLanguage(c)).PolarityScores(c)
 		adjusted := applySEDomainAdjustment(c, scores.Compound)
 
 		weight := commentWeight(len(c), avgLen)
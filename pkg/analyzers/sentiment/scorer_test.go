@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 )
 
 func TestComputeSentiment_Empty(t *testing.T) {
@@ -231,13 +233,28 @@ func TestComputeSentiment_MultilingualScoring(t *testing.T) {
 	})
 }
 
-func TestInjectMultilingualLexicons(t *testing.T) {
+func TestGetVaderAnalyzer_PerLanguageLexicon(t *testing.T) {
 	t.Parallel()
 
-	analyzer := getVaderAnalyzer()
+	plain := getVaderAnalyzer("en")
+	russian := getVaderAnalyzer("ru")
+
+	assert.Same(t, plain, getVaderAnalyzer(pkgplumbing.MessageLanguageUnknown),
+		"languages without a lexicon share plain VADER")
+	assert.Same(t, russian, getVaderAnalyzer("ru"))
+
+	assert.Contains(t, russian.Lexicon, "отлично")
+	assert.NotContains(t, plain.Lexicon, "отлично")
+	assert.NotContains(t, getVaderAnalyzer("de").Lexicon, "отлично",
+		"only the comment language's lexicon is injected")
+	assert.Contains(t, getVaderAnalyzer("de").Lexicon, "ablösung")
+}
+
+func TestComputeSentiment_ScoresEachCommentInItsLanguage(t *testing.T) {
+	t.Parallel()
 
-	assert.Greater(t, len(analyzer.Lexicon), 7500,
-		"lexicon should contain more than base VADER entries after injection")
+	mixed := ComputeSentiment([]string{"отлично", "the code is great"})
+	assert.Greater(t, float64(mixed), float64(SentimentPositiveThreshold))
 }
 
 func TestIsASCIIOnly(t *testing.T) {
@@ -3,6 +3,7 @@ package shotness
 
 import (
 	"context"
+	"fmt"
 	"maps"
 	"sort"
 	"unicode/utf8"
@@ -107,6 +108,7 @@ func NewAnalyzer() *Analyzer {
 			Mode:        analyze.ModeHistory,
 		},
 		Sequential: false,
+		Caps:       &analyze.Capabilities{NeedsDiffs: true, NeedsUAST: true, Memory: analyze.MemoryMedium},
 		ConfigOptions: []pipeline.ConfigurationOption{
 			{
 				Name:        ConfigShotnessDSLStruct,
@@ -275,6 +277,13 @@ func (s *Analyzer) handleModification(
 		s.applyRename(change.Change.From.Name, toName)
 	}
 
+	// The declaration hashes match the default queries; with those, a file
+	// whose structure did not change has no touched functions to look for.
+	hashed := s.usesDefaultDSL()
+	if hashed && change.FileUnchanged() {
+		return
+	}
+
 	nodesBefore, err := s.extractNodes(change.Before)
 	if err != nil {
 		return
@@ -290,13 +299,41 @@ func (s *Analyzer) handleModification(
 		return
 	}
 
-	s.applyDiffEdits(toName, nodesBefore, nodesAfter, diff, allNodes)
+	var unchanged map[string]bool
+
+	if hashed {
+		unchanged = unchangedDeclarations(change, nodesAfter)
+	}
+
+	s.applyDiffEdits(toName, nodesBefore, nodesAfter, unchanged, diff, allNodes)
+}
+
+// usesDefaultDSL reports whether the node queries are the defaults, which
+// select the same functions as the pipeline's declaration hashes.
+func (s *Analyzer) usesDefaultDSL() bool {
+	return s.DSLStruct == DefaultShotnessDSLStruct && s.DSLName == DefaultShotnessDSLName
+}
+
+// unchangedDeclarations returns the names of the nodes whose declaration
+// hash is the same before and after the change. Diff hunks that only touch
+// their lines, such as whitespace edits, do not count as changes.
+func unchangedDeclarations(change uast.Change, nodes map[string]*node.Node) map[string]bool {
+	unchanged := map[string]bool{}
+
+	for name := range nodes {
+		if change.DeclarationUnchanged(name) {
+			unchanged[name] = true
+		}
+	}
+
+	return unchanged
 }
 
 // applyDiffEdits walks the diff edits and records which nodes were touched.
 func (s *Analyzer) applyDiffEdits(
 	toName string,
 	nodesBefore, nodesAfter map[string]*node.Node,
+	unchanged map[string]bool,
 	diff pkgplumbing.FileDiffData,
 	allNodes map[string]bool,
 ) {
@@ -312,10 +349,10 @@ func (s *Analyzer) applyDiffEdits(
 
 		switch edit.Type {
 		case diffmatchpatch.DiffDelete:
-			s.recordTouchedNodes(line2nodeBefore, reversedNodesBefore, lineNumBefore, size, toName, allNodes)
+			s.recordTouchedNodes(line2nodeBefore, reversedNodesBefore, unchanged, lineNumBefore, size, toName, allNodes)
 			lineNumBefore += size
 		case diffmatchpatch.DiffInsert:
-			s.recordTouchedNodes(line2nodeAfter, reversedNodesAfter, lineNumAfter, size, toName, allNodes)
+			s.recordTouchedNodes(line2nodeAfter, reversedNodesAfter, unchanged, lineNumAfter, size, toName, allNodes)
 			lineNumAfter += size
 		case diffmatchpatch.DiffEqual:
 			lineNumBefore += size
@@ -324,10 +361,12 @@ func (s *Analyzer) applyDiffEdits(
 	}
 }
 
-// recordTouchedNodes marks nodes touched by a diff hunk spanning [startLine, startLine+size).
+// recordTouchedNodes marks nodes touched by a diff hunk spanning [startLine, startLine+size),
+// leaving out the unchanged ones.
 func (s *Analyzer) recordTouchedNodes(
 	line2node [][]*node.Node,
 	reversed map[string]string,
+	unchanged map[string]bool,
 	startLine, size int,
 	fileName string,
 	allNodes map[string]bool,
@@ -335,7 +374,7 @@ func (s *Analyzer) recordTouchedNodes(
 	for l := startLine; l < startLine+size; l++ {
 		if l < len(line2node) {
 			for _, n := range line2node[l] {
-				if id, ok := reversed[n.ID]; ok {
+				if id, ok := reversed[n.ID]; ok && !unchanged[id] {
 					s.addNode(id, n, fileName, allNodes)
 				}
 			}
@@ -445,6 +484,7 @@ func resolveEndLine(uastNode *node.Node, pos *node.Positions) int {
 }
 
 // Consume processes a single commit with the provided dependency results.
+// It stops between files once ctx is done, leaving the commit partly counted.
 func (s *Analyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
 	if !s.shouldConsumeCommit(ac.Commit) {
 		return analyze.TC{}, nil
@@ -455,6 +495,10 @@ func (s *Analyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC
 	allNodes := map[string]bool{}
 
 	for _, change := range changesList {
+		if ctx.Err() != nil {
+			return analyze.TC{}, fmt.Errorf("shotness: %w", ctx.Err())
+		}
+
 		switch {
 		case change.After == nil:
 			s.handleDeletion(change)
@@ -595,9 +639,6 @@ func (s *Analyzer) mergeMerges(other map[gitlib.Hash]bool) {
 // SequentialOnly returns false because shotness analysis can be parallelized.
 func (s *Analyzer) SequentialOnly() bool { return false }
 
-// NeedsUAST returns true to enable the UAST pipeline.
-func (s *Analyzer) NeedsUAST() bool { return true }
-
 // SnapshotPlumbing captures the current plumbing output state for parallel execution.
 func (s *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
 	return plumbing.Snapshot{
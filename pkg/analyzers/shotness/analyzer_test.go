@@ -2,15 +2,20 @@ package shotness
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"testing"
 
+	"github.com/sergi/go-diff/diffmatchpatch"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/redact"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
 )
@@ -479,11 +484,13 @@ func TestAnalyzer_SequentialOnly(t *testing.T) {
 	assert.False(t, s.SequentialOnly())
 }
 
-func TestAnalyzer_NeedsUAST(t *testing.T) {
+func TestAnalyzer_Capabilities(t *testing.T) {
 	t.Parallel()
 
-	s := NewAnalyzer()
-	assert.True(t, s.NeedsUAST())
+	caps := analyze.CapabilitiesOf(NewAnalyzer())
+	assert.True(t, caps.NeedsUAST)
+	assert.True(t, caps.NeedsDiffs)
+	assert.True(t, caps.NeedsBlobs)
 }
 
 func TestShouldConsumeCommit_SingleParent(t *testing.T) {
@@ -962,6 +969,69 @@ func TestHandleDeletion(t *testing.T) {
 	assert.Nil(t, s.files["deleted.go"])
 }
 
+// hashTestFile builds a file with two three-line functions, the body of the
+// second one having the given token.
+func hashTestFile(body string) *node.Node {
+	function := func(id, name string, start uint, token string) *node.Node {
+		return &node.Node{
+			ID:    id,
+			Type:  node.UASTFunction,
+			Roles: []node.Role{node.RoleFunction},
+			Props: map[string]string{"name": name},
+			Pos:   &node.Positions{StartLine: start, EndLine: start + 2},
+			Children: []*node.Node{
+				{Type: node.UASTCall, Token: token, Pos: &node.Positions{StartLine: start + 1, EndLine: start + 1}},
+			},
+		}
+	}
+
+	return &node.Node{
+		Type: node.UASTFile,
+		Pos:  &node.Positions{StartLine: 1, EndLine: 6},
+		Children: []*node.Node{
+			function("a", "first", 1, "one()"),
+			function("b", "second", 4, body),
+		},
+	}
+}
+
+func TestHandleModification_SkipsUnchangedDeclarations(t *testing.T) {
+	t.Parallel()
+
+	s := NewAnalyzer()
+	require.NoError(t, s.Initialize(nil))
+	require.NoError(t, s.Configure(map[string]any{}))
+
+	gitChange := &gitlib.Change{
+		Action: gitlib.Modify,
+		From:   gitlib.ChangeEntry{Name: "f.go"},
+		To:     gitlib.ChangeEntry{Name: "f.go"},
+	}
+
+	// Every line is in a hunk, as a reformatting commit would produce.
+	diffs := map[string]pkgplumbing.FileDiffData{"f.go": {
+		OldLinesOfCode: 6,
+		NewLinesOfCode: 6,
+		Diffs: []diffmatchpatch.Diff{
+			{Type: diffmatchpatch.DiffDelete, Text: "aaaaaa"},
+			{Type: diffmatchpatch.DiffInsert, Text: "bbbbbb"},
+		},
+	}}
+
+	allNodes := map[string]bool{}
+	s.handleModification(uast.NewChange(hashTestFile("two()"), hashTestFile("two()"), gitChange), diffs, allNodes)
+	assert.Empty(t, allNodes)
+
+	s.handleModification(uast.NewChange(hashTestFile("two()"), hashTestFile("three()"), gitChange), diffs, allNodes)
+	assert.Equal(t, map[string]bool{"Function_second_f.go": true}, allNodes)
+
+	// Without hashes every function in a hunk is touched.
+	allNodes = map[string]bool{}
+	s.handleModification(uast.Change{Before: hashTestFile("two()"), After: hashTestFile("two()"), Change: gitChange},
+		diffs, allNodes)
+	assert.Len(t, allNodes, 2)
+}
+
 // errMockNotImpl is returned by mock methods that are not implemented.
 var errMockNotImpl = errors.New("mock: not implemented")
 
@@ -991,3 +1061,45 @@ func (m *mockCommit) Files() (*gitlib.FileIter, error) {
 func (m *mockCommit) File(_ string) (*gitlib.File, error) {
 	return nil, errMockNotImpl
 }
+
+func TestRedaction_HidesPathsInNodeKeys(t *testing.T) {
+	t.Parallel()
+
+	const secret = "internal/payroll/run.go"
+
+	redactor := redact.New(redact.Paths, []byte("key"))
+	redactor.ObservePaths(secret, "internal/payroll/tax.go")
+
+	run := NodeSummary{Type: "Function", Name: "Run", File: secret}
+	tax := NodeSummary{Type: "Function", Name: "Tax", File: "internal/payroll/tax.go"}
+
+	cd := &CommitData{
+		NodesTouched: map[string]NodeDelta{
+			run.String(): {Summary: run, CountDelta: 1},
+			tax.String(): {Summary: tax, CountDelta: 1},
+		},
+		Couples: []CouplingPair{{Key1: run.String(), Key2: tax.String()}},
+	}
+
+	// The NDJSON record of the commit.
+	record, err := redactor.Copy(cd)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(record)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "payroll")
+	assert.Contains(t, string(data), "Function_Run_"+redactor.Path(secret))
+
+	// The report of the run.
+	byTick := make(map[int]*TickData)
+	require.NoError(t, extractTC(analyze.TC{Tick: 0, Data: cd}, byTick))
+
+	report := ticksToReport(context.Background(), []analyze.TICK{{Tick: 0, Data: byTick[0]}})
+	redactor.Value(report)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, NewAnalyzer().Serialize(report, analyze.FormatJSON, &buf))
+	assert.NotContains(t, buf.String(), "payroll")
+	assert.Contains(t, buf.String(), redactor.Path(secret))
+}
@@ -0,0 +1,182 @@
+package shotness
+
+import (
+	"sort"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/couples"
+)
+
+const (
+	// DerivedFunctionCoupling is the name of the derived metric that joins
+	// couples' file co-changes into the shotness report.
+	DerivedFunctionCoupling = "shotness.function_coupling"
+
+	// reportKeyFileCoChanges carries couples' co-change counts for the file pairs
+	// that hold coupled shotness nodes, as file1 -> file2 -> count with file1 < file2.
+	reportKeyFileCoChanges = "FileCoChanges"
+
+	// couplesFlag is the flag of the analyzer that produces file co-changes.
+	couplesFlag = "couples"
+)
+
+// RegisterDerivedMetrics registers the shotness derived metrics with the analyze package.
+func RegisterDerivedMetrics() {
+	analyze.RegisterDerivedMetric(analyze.DerivedMetric{
+		Name:     DerivedFunctionCoupling,
+		Requires: []string{analyzerNameShotness, couplesFlag},
+		Compute:  joinFileCoChanges,
+	})
+}
+
+// joinFileCoChanges copies the couples co-change count of every file pair that
+// holds a cross-file shotness coupling into the shotness report.
+func joinFileCoChanges(reports map[string]analyze.Report) error {
+	input, err := ParseReportData(reports[analyzerNameShotness])
+	if err != nil {
+		return err
+	}
+
+	couplesData, err := couples.ParseReportData(reports[couplesFlag])
+	if err != nil {
+		return err
+	}
+
+	fileIndex := make(map[string]int, len(couplesData.Files))
+	for i, f := range couplesData.Files {
+		fileIndex[f] = i
+	}
+
+	coChanges := make(map[string]map[string]int64)
+
+	forEachCrossFilePair(input, func(i, j, _ int) {
+		file1, file2 := orderedPair(input.Nodes[i].File, input.Nodes[j].File)
+
+		fi, ok1 := fileIndex[file1]
+		fj, ok2 := fileIndex[file2]
+
+		if !ok1 || !ok2 || fi >= len(couplesData.FilesMatrix) {
+			return
+		}
+
+		count := couplesData.FilesMatrix[fi][fj]
+		if count == 0 {
+			return
+		}
+
+		if coChanges[file1] == nil {
+			coChanges[file1] = make(map[string]int64)
+		}
+
+		coChanges[file1][file2] = count
+	})
+
+	if len(coChanges) > 0 {
+		reports[analyzerNameShotness][reportKeyFileCoChanges] = coChanges
+	}
+
+	return nil
+}
+
+// forEachCrossFilePair calls fn with the indices of every pair of nodes in
+// different files that changed together at least once.
+func forEachCrossFilePair(input *ReportData, fn func(i, j, coChanges int)) {
+	for i, counters := range input.Counters {
+		if i >= len(input.Nodes) {
+			continue
+		}
+
+		for j, co := range counters {
+			if j <= i || j >= len(input.Nodes) || co == 0 {
+				continue
+			}
+
+			if input.Nodes[i].File == input.Nodes[j].File {
+				continue
+			}
+
+			fn(i, j, co)
+		}
+	}
+}
+
+func orderedPair(a, b string) (first, second string) {
+	if b < a {
+		return b, a
+	}
+
+	return a, b
+}
+
+// FunctionCouplingData is the temporal coupling of two functions in different files.
+// FileCoChanges is how often the two files changed together (from couples);
+// FileShare is the fraction of those commits in which both functions changed too,
+// so a high share pins the file coupling to these two functions.
+type FunctionCouplingData struct {
+	Node1Name     string  `json:"node1_name"        yaml:"node1_name"`
+	Node1File     string  `json:"node1_file"        yaml:"node1_file"`
+	Node2Name     string  `json:"node2_name"        yaml:"node2_name"`
+	Node2File     string  `json:"node2_file"        yaml:"node2_file"`
+	CoChanges     int     `json:"co_changes"        yaml:"co_changes"`
+	Strength      float64 `json:"coupling_strength" yaml:"coupling_strength"`
+	FileCoChanges int64   `json:"file_co_changes"   yaml:"file_co_changes"`
+	FileShare     float64 `json:"file_share"        yaml:"file_share"`
+}
+
+// computeFunctionCoupling joins cross-file node couplings with the file co-change
+// counts. It returns nil unless the couples data was joined into the report.
+func computeFunctionCoupling(input *ReportData) []FunctionCouplingData {
+	if len(input.FileCoChanges) == 0 {
+		return nil
+	}
+
+	var result []FunctionCouplingData
+
+	forEachCrossFilePair(input, func(i, j, coChanges int) {
+		node1, node2 := input.Nodes[i], input.Nodes[j]
+		file1, file2 := orderedPair(node1.File, node2.File)
+		fileCoChanges := input.FileCoChanges[file1][file2]
+
+		if fileCoChanges == 0 {
+			return
+		}
+
+		result = append(result, FunctionCouplingData{
+			Node1Name:     node1.Name,
+			Node1File:     node1.File,
+			Node2Name:     node2.Name,
+			Node2File:     node2.File,
+			CoChanges:     coChanges,
+			Strength:      computeCouplingStrength(coChanges, input.Counters[i][i], selfChanges(input, j)),
+			FileCoChanges: fileCoChanges,
+			FileShare:     min(float64(coChanges)/float64(fileCoChanges), 1.0),
+		})
+	})
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CoChanges != result[j].CoChanges {
+			return result[i].CoChanges > result[j].CoChanges
+		}
+
+		if result[i].Strength != result[j].Strength {
+			return result[i].Strength > result[j].Strength
+		}
+
+		if result[i].Node1File != result[j].Node1File {
+			return result[i].Node1File < result[j].Node1File
+		}
+
+		return result[i].Node1Name < result[j].Node1Name
+	})
+
+	return result
+}
+
+// selfChanges returns how often node idx changed, or 0 when it has no counters.
+func selfChanges(input *ReportData, idx int) int {
+	if idx < len(input.Counters) {
+		return input.Counters[idx][idx]
+	}
+
+	return 0
+}
@@ -0,0 +1,104 @@
+package shotness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// functionCouplingReports returns shotness and couples reports where
+// TestFunc1 (file1.go) and TestFunc3 (file2.go) changed together 3 times and
+// TestFunc1/TestFunc2 share a file.
+func functionCouplingReports() map[string]analyze.Report {
+	return map[string]analyze.Report{
+		"shotness": {
+			"Nodes": []NodeSummary{
+				{Type: testNodeType, Name: testNodeName1, File: testFile1},
+				{Type: testNodeType, Name: testNodeName2, File: testFile1},
+				{Type: testNodeType, Name: testNodeName3, File: testFile2},
+			},
+			"Counters": []map[int]int{
+				{0: 4, 1: 2, 2: 3},
+				{0: 2, 1: 2},
+				{0: 3, 2: 6},
+			},
+		},
+		"couples": {
+			"Files": []string{testFile2, testFile1},
+			"FilesMatrix": []map[int]int64{
+				{0: 8, 1: 5},
+				{0: 5, 1: 6},
+			},
+		},
+	}
+}
+
+func TestJoinFileCoChanges(t *testing.T) {
+	t.Parallel()
+
+	reports := functionCouplingReports()
+
+	require.NoError(t, joinFileCoChanges(reports))
+
+	coChanges, ok := reports["shotness"][reportKeyFileCoChanges].(map[string]map[string]int64)
+	require.True(t, ok)
+	assert.Equal(t, map[string]map[string]int64{testFile1: {testFile2: 5}}, coChanges)
+}
+
+func TestJoinFileCoChanges_NoCouplesFiles(t *testing.T) {
+	t.Parallel()
+
+	reports := functionCouplingReports()
+	reports["couples"] = analyze.Report{}
+
+	require.NoError(t, joinFileCoChanges(reports))
+	assert.NotContains(t, reports["shotness"], reportKeyFileCoChanges)
+}
+
+func TestComputeFunctionCoupling(t *testing.T) {
+	t.Parallel()
+
+	reports := functionCouplingReports()
+	require.NoError(t, joinFileCoChanges(reports))
+
+	metrics, err := ComputeAllMetrics(reports["shotness"])
+	require.NoError(t, err)
+
+	// Only the cross-file pair is reported; TestFunc1/TestFunc2 share file1.go.
+	require.Len(t, metrics.FunctionCoupling, 1)
+
+	fc := metrics.FunctionCoupling[0]
+	assert.Equal(t, testNodeName1, fc.Node1Name)
+	assert.Equal(t, testNodeName3, fc.Node2Name)
+	assert.Equal(t, 3, fc.CoChanges)
+	assert.InDelta(t, 0.5, fc.Strength, floatDelta)
+	assert.Equal(t, int64(5), fc.FileCoChanges)
+	assert.InDelta(t, 0.6, fc.FileShare, floatDelta)
+}
+
+func TestComputeFunctionCoupling_WithoutCouples(t *testing.T) {
+	t.Parallel()
+
+	metrics, err := ComputeAllMetrics(functionCouplingReports()["shotness"])
+	require.NoError(t, err)
+	assert.Nil(t, metrics.FunctionCoupling)
+}
+
+func TestGenerateSections_FunctionCoupling(t *testing.T) {
+	t.Parallel()
+
+	reports := functionCouplingReports()
+
+	without, err := (&Analyzer{}).GenerateSections(reports["shotness"])
+	require.NoError(t, err)
+
+	require.NoError(t, joinFileCoChanges(reports))
+
+	with, err := (&Analyzer{}).GenerateSections(reports["shotness"])
+	require.NoError(t, err)
+	require.Len(t, with, len(without)+1)
+	assert.Equal(t, "Cross-File Function Coupling", with[len(with)-1].Title)
+}
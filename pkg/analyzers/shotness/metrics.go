@@ -12,9 +12,15 @@ import (
 type ReportData struct {
 	Nodes    []NodeSummary
 	Counters []map[int]int
+
+	// FileCoChanges is present only when the function coupling derived metric
+	// joined couples data into the report.
+	FileCoChanges map[string]map[string]int64
 }
 
-// ParseReportData extracts ReportData from an analyzer report.
+// ParseReportData extracts ReportData from an analyzer report. The change
+// counts of a sampled history are scaled by the sampling factor to estimate
+// the full history; the report itself is left untouched.
 func ParseReportData(report analyze.Report) (*ReportData, error) {
 	data := &ReportData{}
 
@@ -26,9 +32,52 @@ func ParseReportData(report analyze.Report) (*ReportData, error) {
 		data.Counters = v
 	}
 
+	if v, ok := report[reportKeyFileCoChanges].(map[string]map[string]int64); ok {
+		data.FileCoChanges = v
+	}
+
+	if factor := analyze.SampleFactor(report); factor > 1 {
+		data.Counters = scaleCounters(data.Counters, factor)
+		data.FileCoChanges = scaleCoChanges(data.FileCoChanges, factor)
+	}
+
 	return data, nil
 }
 
+// scaleCounters returns a copy of counters with its counts scaled by factor.
+func scaleCounters(counters []map[int]int, factor float64) []map[int]int {
+	scaled := make([]map[int]int, len(counters))
+
+	for i, row := range counters {
+		scaled[i] = make(map[int]int, len(row))
+
+		for j, n := range row {
+			scaled[i][j] = analyze.ScaleCount(n, factor)
+		}
+	}
+
+	return scaled
+}
+
+// scaleCoChanges returns a copy of coChanges with its counts scaled by factor.
+func scaleCoChanges(coChanges map[string]map[string]int64, factor float64) map[string]map[string]int64 {
+	if coChanges == nil {
+		return nil
+	}
+
+	scaled := make(map[string]map[string]int64, len(coChanges))
+
+	for file1, row := range coChanges {
+		scaled[file1] = make(map[string]int64, len(row))
+
+		for file2, n := range row {
+			scaled[file1][file2] = int64(analyze.ScaleCount(int(n), factor))
+		}
+	}
+
+	return scaled
+}
+
 // --- Output Data Types ---.
 
 // NodeHotnessData contains hotness information for a code node.
@@ -303,6 +352,8 @@ type ComputedMetrics struct {
 	NodeCoupling []NodeCouplingData `json:"node_coupling" yaml:"node_coupling"`
 	HotspotNodes []HotspotNodeData  `json:"hotspot_nodes" yaml:"hotspot_nodes"`
 	Aggregate    AggregateData      `json:"aggregate"     yaml:"aggregate"`
+
+	FunctionCoupling []FunctionCouplingData `json:"function_coupling,omitempty" yaml:"function_coupling,omitempty"`
 }
 
 const analyzerNameShotness = "shotness"
@@ -334,5 +385,7 @@ func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
 		NodeCoupling: computeNodeCoupling(input),
 		HotspotNodes: computeHotspotNodes(input),
 		Aggregate:    computeAggregate(input),
+
+		FunctionCoupling: computeFunctionCoupling(input),
 	}, nil
 }
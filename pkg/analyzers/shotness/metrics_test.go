@@ -62,6 +62,28 @@ func TestParseReportData_AllFields(t *testing.T) {
 	assert.Equal(t, 10, result.Counters[0][0])
 }
 
+func TestParseReportData_ScalesSampledCounts(t *testing.T) {
+	t.Parallel()
+
+	counters := []map[int]int{
+		{0: 10, 1: 5},
+		{0: 5, 1: 8},
+	}
+
+	report := analyze.Report{
+		"Counters":                    counters,
+		reportKeyFileCoChanges:        map[string]map[string]int64{testFile1: {testFile2: 6}},
+		analyze.ReportKeySampleFactor: 2.0,
+	}
+
+	result, err := ParseReportData(report)
+
+	require.NoError(t, err)
+	assert.Equal(t, []map[int]int{{0: 20, 1: 10}, {0: 10, 1: 16}}, result.Counters)
+	assert.Equal(t, int64(12), result.FileCoChanges[testFile1][testFile2])
+	assert.Equal(t, 10, counters[0][0], "the report is left untouched")
+}
+
 // --- NodeHotnessMetric Tests ---.
 
 func TestNodeHotnessMetric_Empty(t *testing.T) {
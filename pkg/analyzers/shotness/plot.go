@@ -3,9 +3,11 @@ package shotness
 import (
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"path/filepath"
 	"sort"
+	"strconv"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/components"
@@ -69,11 +71,22 @@ func (s *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section,
 	chartOpts := plotpage.DefaultChartOpts()
 	palette := plotpage.GetChartPalette(plotpage.ThemeDark)
 
-	return []plotpage.Section{
+	sections := []plotpage.Section{
 		treeMapSection(nodes, counters, chartOpts),
 		heatMapSection(nodes, counters, chartOpts),
 		barChartSection(nodes, counters, chartOpts, palette),
-	}, nil
+	}
+
+	input, err := ParseReportData(report)
+	if err != nil {
+		return nil, err
+	}
+
+	if fc := computeFunctionCoupling(input); len(fc) > 0 {
+		sections = append(sections, functionCouplingSection(fc))
+	}
+
+	return sections, nil
 }
 
 // GenerateChart creates a bar chart showing the hottest functions.
@@ -98,6 +111,10 @@ func extractShotnessData(report analyze.Report) ([]NodeSummary, []map[int]int, e
 	counters, countersOK := report["Counters"].([]map[int]int)
 
 	if nodesOK && countersOK {
+		if factor := analyze.SampleFactor(report); factor > 1 {
+			counters = scaleCounters(counters, factor)
+		}
+
 		return nodes, counters, nil
 	}
 
@@ -267,6 +284,36 @@ func barChartSection(
 	}
 }
 
+func functionCouplingSection(couplings []FunctionCouplingData) plotpage.Section {
+	table := plotpage.NewTable([]string{"Function", "Coupled Function", "Co-changes", "Strength", "File Co-changes", "Share"})
+
+	for _, c := range couplings[:min(len(couplings), topNNodes)] {
+		table.AddRow(
+			html.EscapeString(formatNodeLabel(c.Node1Name, c.Node1File)),
+			html.EscapeString(formatNodeLabel(c.Node2Name, c.Node2File)),
+			strconv.Itoa(c.CoChanges),
+			fmt.Sprintf("%.0f%%", c.Strength*percentFactor),
+			strconv.FormatInt(c.FileCoChanges, 10),
+			fmt.Sprintf("%.0f%%", c.FileShare*percentFactor),
+		)
+	}
+
+	return plotpage.Section{
+		Title:    "Cross-File Function Coupling",
+		Subtitle: "Functions in different files that change together, joined with file co-changes from couples.",
+		Chart:    table,
+		Hint: plotpage.Hint{
+			Title: "How to interpret:",
+			Items: []string{
+				"Co-changes = commits touching both functions; Strength normalizes by how often each changes",
+				"Share = fraction of the files' co-changes that also touched both functions",
+				"High share = the file coupling is really this function pair; a candidate for an explicit interface",
+				"Look for: Strongly coupled pairs across packages or layers",
+			},
+		},
+	}
+}
+
 func createTreeMap(nodes []NodeSummary, counters []map[int]int, chartOpts *plotpage.ChartOpts) *charts.TreeMap {
 	fileMap, fileTotals := buildFileHierarchy(nodes, counters)
 	rootNodes := buildRootNodes(fileMap, fileTotals)
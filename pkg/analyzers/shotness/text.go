@@ -97,6 +97,11 @@ func (s *Analyzer) generateText(report analyze.Report, writer io.Writer) error {
 		writeStrongestCouplings(writer, cfg, metrics.NodeCoupling)
 	}
 
+	if len(metrics.FunctionCoupling) > 0 {
+		fmt.Fprintln(writer)
+		writeFunctionCoupling(writer, cfg, metrics.FunctionCoupling)
+	}
+
 	fmt.Fprintln(writer)
 
 	return nil
@@ -212,6 +217,33 @@ func writeStrongestCouplings(writer io.Writer, cfg terminal.Config, couplings []
 	}
 }
 
+func writeFunctionCoupling(writer io.Writer, cfg terminal.Config, couplings []FunctionCouplingData) {
+	fmt.Fprintf(writer, "%s%s\n", textIndent,
+		cfg.Colorize("Cross-File Function Coupling", terminal.ColorBlue))
+	fmt.Fprintf(writer, "%s%s\n", textIndent,
+		terminal.DrawSeparator(cfg.Width-len(textIndent)*2))
+
+	shown := min(len(couplings), textMaxCouplings)
+
+	for _, c := range couplings[:shown] {
+		left := terminal.TruncateWithEllipsis(formatNodeLabel(c.Node1Name, c.Node1File), textLabelWidth)
+		right := terminal.TruncateWithEllipsis(formatNodeLabel(c.Node2Name, c.Node2File), textLabelWidth)
+
+		fmt.Fprintf(writer, "%s%-*s %s %-*s %s  (%d of %d file co-changes)\n",
+			textIndent,
+			textLabelWidth, left,
+			cfg.Colorize("↔", terminal.ColorGray),
+			textLabelWidth, right,
+			cfg.Colorize(fmt.Sprintf("%3.0f%%", c.Strength*percentFactor), couplingStrengthColor(c.Strength)),
+			c.CoChanges, c.FileCoChanges)
+	}
+
+	if len(couplings) > textMaxCouplings {
+		fmt.Fprintf(writer, "%s%s\n", textIndent,
+			cfg.Colorize(fmt.Sprintf("  ... and %d more", len(couplings)-textMaxCouplings), terminal.ColorGray))
+	}
+}
+
 // formatNodeLabel builds "name (file)" from the node name and file path.
 func formatNodeLabel(name, file string) string {
 	if file == "" {
@@ -0,0 +1,552 @@
+// Package signing verifies the GPG and SSH signatures of commits and reports
+// signing coverage over time and the unsigned commits that changed protected
+// paths.
+package signing
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/sensitive"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Configuration option keys for the signing analyzer.
+const (
+	ConfigSigningProtectedPaths = "Signing.ProtectedPaths"
+	ConfigSigningAllowedSigners = "Signing.AllowedSigners"
+	ConfigSigningGPGProgram     = "Signing.GPGProgram"
+)
+
+// Report keys of the signing analyzer.
+const (
+	KeyTicks       = "ticks"
+	KeyAuthors     = "authors"
+	KeySigners     = "signers"
+	KeyViolations  = "violations"
+	KeyAuthorIndex = "author_index"
+	KeyTickSize    = "tick_size"
+
+	// tickBytes, authorBytes, signerBytes and violationBytes estimate the
+	// bytes of one tick, author entry, signer entry and violation held by
+	// the aggregator.
+	tickBytes      = 96
+	authorBytes    = 80
+	signerBytes    = 128
+	violationBytes = 192
+)
+
+// ErrInvalidProtectedPath indicates a malformed protected path pattern.
+var ErrInvalidProtectedPath = errors.New("invalid protected path pattern")
+
+// Counts counts commits by the outcome of verifying their signatures.
+type Counts struct {
+	Commits    int `json:"commits"     yaml:"commits"`
+	Signed     int `json:"signed"      yaml:"signed"`
+	Good       int `json:"good"        yaml:"good"`
+	Bad        int `json:"bad"         yaml:"bad"`
+	Expired    int `json:"expired"     yaml:"expired"`
+	UnknownKey int `json:"unknown_key" yaml:"unknown_key"`
+	Unchecked  int `json:"unchecked"   yaml:"unchecked"`
+}
+
+// Add counts one commit with the verification status.
+func (c *Counts) Add(status gitlib.VerificationStatus) {
+	c.Commits++
+
+	if status != gitlib.VerificationUnsigned {
+		c.Signed++
+	}
+
+	switch status {
+	case gitlib.VerificationGood:
+		c.Good++
+	case gitlib.VerificationBad:
+		c.Bad++
+	case gitlib.VerificationExpired:
+		c.Expired++
+	case gitlib.VerificationUnknownKey:
+		c.UnknownKey++
+	case gitlib.VerificationUnchecked:
+		c.Unchecked++
+	case gitlib.VerificationUnsigned:
+	}
+}
+
+// Merge adds the counts of other.
+func (c *Counts) Merge(other Counts) {
+	c.Commits += other.Commits
+	c.Signed += other.Signed
+	c.Good += other.Good
+	c.Bad += other.Bad
+	c.Expired += other.Expired
+	c.UnknownKey += other.UnknownKey
+	c.Unchecked += other.Unchecked
+}
+
+// SignerCount counts the commits signed with one key.
+type SignerCount struct {
+	Signer  string                 `json:"signer"  yaml:"signer"`
+	Key     string                 `json:"key"     yaml:"key"`
+	Format  gitlib.SignatureFormat `json:"format"  yaml:"format"`
+	Commits int                    `json:"commits" yaml:"commits"`
+}
+
+// TickCounts is the signing coverage of one tick.
+type TickCounts struct {
+	Tick   int    `json:"tick"   yaml:"tick"`
+	Counts Counts `json:"counts" yaml:"counts"`
+}
+
+// CommitSigning is the per-commit payload: the verified signature of a
+// commit and the protected files it changed.
+type CommitSigning struct {
+	Hash     gitlib.Hash
+	Tick     int
+	AuthorID int
+	Time     time.Time
+	Format   gitlib.SignatureFormat
+	gitlib.Verification
+	// Protected lists the changed files matching a protected path pattern.
+	// Renames list the new path.
+	Protected []string
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	Counts  Counts
+	Authors map[int]*Counts
+	// Signers is keyed by format and key fingerprint.
+	Signers map[string]*SignerCount
+	// Violations holds the commits of the tick that changed protected files
+	// without a valid signature.
+	Violations []CommitSigning
+}
+
+// signedCommit is implemented by commits that can return their signature.
+// Commits that cannot count as unsigned.
+type signedCommit interface {
+	ExtractSignature() (gitlib.CommitSignature, error)
+}
+
+// Analyzer verifies the signature of every commit and records the protected
+// files of the commits without a valid one.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	TreeDiff *plumbing.TreeDiffAnalyzer
+	Ticks    *plumbing.TicksSinceStart
+
+	// ProtectedPaths are the path patterns whose changes require a signed
+	// commit, in the syntax of the sensitive analyzer's patterns.
+	ProtectedPaths []string
+	// AllowedSigners is the SSH allowed signers file; empty uses the
+	// repository's gpg.ssh.allowedSignersFile.
+	AllowedSigners string
+	// GPGProgram checks OpenPGP signatures; empty uses the repository's
+	// gpg.program or gpg.
+	GPGProgram string
+
+	verifier           *gitlib.SignatureVerifier
+	reversedPeopleDict []string
+	tickSize           time.Duration
+}
+
+// NewAnalyzer creates a new signing analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{ProtectedPaths: sensitive.DefaultPatterns()}
+
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:   "history/signing",
+			Mode: analyze.ModeHistory,
+			Description: "Verifies the GPG and SSH signatures of commits, tracks signing coverage over time " +
+				"and lists the commits that changed protected paths without a valid signature.",
+		},
+		Sequential: false,
+		Caps:       &analyze.Capabilities{Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name: ConfigSigningProtectedPaths,
+				Description: "Path patterns whose changes require a signed commit, separated by commas. " +
+					"Defaults to the sensitive analyzer's patterns.",
+				Flag:    "signing-protected-paths",
+				Type:    pipeline.StringsConfigurationOption,
+				Default: sensitive.DefaultPatterns(),
+			},
+			{
+				Name: ConfigSigningAllowedSigners,
+				Description: "SSH allowed signers file trusted for SSH signatures. " +
+					"Defaults to the repository's gpg.ssh.allowedSignersFile.",
+				Flag:    "signing-allowed-signers",
+				Type:    pipeline.StringConfigurationOption,
+				Default: "",
+			},
+			{
+				Name:        ConfigSigningGPGProgram,
+				Description: "Program that checks OpenPGP signatures. Defaults to the repository's gpg.program or gpg.",
+				Flag:        "signing-gpg-program",
+				Type:        pipeline.StringConfigurationOption,
+				Default:     "",
+			},
+		},
+		ComputeMetricsFn: func(report analyze.Report) (*ComputedMetrics, error) {
+			return ComputeAllMetrics(report), nil
+		},
+		AggregatorFn: newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+// Configure sets up the analyzer with the provided facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigSigningProtectedPaths].([]string); exists {
+		patterns := sensitive.CleanPatterns(val)
+
+		err := sensitive.ValidatePatterns(patterns)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidProtectedPath, err)
+		}
+
+		a.ProtectedPaths = patterns
+	}
+
+	if val, exists := facts[ConfigSigningAllowedSigners].(string); exists {
+		a.AllowedSigners = val
+	}
+
+	if val, exists := facts[ConfigSigningGPGProgram].(string); exists {
+		a.GPGProgram = val
+	}
+
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		a.reversedPeopleDict = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		a.tickSize = val
+	}
+
+	return nil
+}
+
+// Initialize creates the signature verifier, reading the signing settings
+// of the repository when it is not nil.
+func (a *Analyzer) Initialize(repository *gitlib.Repository) error {
+	var err error
+
+	if repository != nil {
+		a.verifier, err = repository.SignatureVerifier(a.AllowedSigners, a.GPGProgram)
+	} else {
+		a.verifier, err = gitlib.NewSignatureVerifier(a.AllowedSigners, a.GPGProgram)
+	}
+
+	if err != nil {
+		return fmt.Errorf("signing: %w", err)
+	}
+
+	return nil
+}
+
+// Consume verifies the signature of the commit and lists the protected
+// files it changed. Merge commits are verified, but their changes are not
+// checked: they were recorded on the merged branch.
+func (a *Analyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac == nil || ac.Commit == nil {
+		return analyze.TC{}, nil
+	}
+
+	var sig gitlib.CommitSignature
+
+	if commit, ok := ac.Commit.(signedCommit); ok {
+		var err error
+
+		sig, err = commit.ExtractSignature()
+		if err != nil {
+			return analyze.TC{}, err
+		}
+	}
+
+	result := &CommitSigning{Format: sig.Format, Verification: a.verifier.Verify(ctx, sig)}
+
+	if !ac.IsMerge {
+		result.Protected = a.protectedFiles()
+	}
+
+	return analyze.TC{Data: result, CommitHash: ac.Commit.Hash()}, nil
+}
+
+// protectedFiles lists the changed files of the commit that match a
+// protected path pattern. Either path of a rename may match, so that moving
+// a file out of a protected area is caught too.
+func (a *Analyzer) protectedFiles() []string {
+	var files []string
+
+	for _, change := range a.TreeDiff.Changes {
+		name := change.To.Name
+		if change.Action == gitlib.Delete {
+			name = change.From.Name
+		}
+
+		renamed := change.Action == gitlib.Modify && change.From.Name != change.To.Name
+
+		if matchProtected(a.ProtectedPaths, name) || (renamed && matchProtected(a.ProtectedPaths, change.From.Name)) {
+			files = append(files, name)
+		}
+	}
+
+	return files
+}
+
+// Fork creates independent copies of the analyzer for parallel processing.
+// The copies share the verifier, which is safe for concurrent use.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Changes: a.TreeDiff.Changes,
+		Tick:    a.Ticks.Tick,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.TreeDiff.Changes = snapshot.Changes
+	a.Ticks.Tick = snapshot.Tick
+}
+
+// ReleaseSnapshot is a no-op for signing.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// reportFromTicks lists the coverage per tick, sums it per author and per
+// signing key, and lists the violations in history order. Violations of a
+// tick are ordered by time, as parallel workers may deliver them out of
+// order.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	var (
+		series     []TickCounts
+		violations []CommitSigning
+		authors    = make(map[int]Counts)
+		signers    = make(map[string]SignerCount)
+	)
+
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*TickData)
+		if !ok || td == nil {
+			continue
+		}
+
+		series = append(series, TickCounts{Tick: tick.Tick, Counts: td.Counts})
+
+		for id, counts := range td.Authors {
+			total := authors[id]
+			total.Merge(*counts)
+			authors[id] = total
+		}
+
+		for key, count := range td.Signers {
+			total := signers[key]
+			total.Signer, total.Key, total.Format = cmp.Or(total.Signer, count.Signer), count.Key, count.Format
+			total.Commits += count.Commits
+			signers[key] = total
+		}
+
+		tickViolations := slices.Clone(td.Violations)
+		slices.SortStableFunc(tickViolations, func(x, y CommitSigning) int {
+			return x.Time.Compare(y.Time)
+		})
+
+		violations = append(violations, tickViolations...)
+	}
+
+	return analyze.Report{
+		KeyTicks:       series,
+		KeyAuthors:     authors,
+		KeySigners:     signers,
+		KeyViolations:  violations,
+		KeyAuthorIndex: a.reversedPeopleDict,
+		KeyTickSize:    a.tickSize,
+	}
+}
+
+// isViolation reports whether a commit with the status must not change
+// protected paths. Signatures that could not be checked are given the
+// benefit of the doubt.
+func isViolation(status gitlib.VerificationStatus) bool {
+	return status != gitlib.VerificationGood && status != gitlib.VerificationUnchecked
+}
+
+// signerKey identifies the signing key of a commit within its format.
+func signerKey(commit *CommitSigning) string {
+	return string(commit.Format) + ":" + commit.Key
+}
+
+// Extract properties for GenericAggregator.
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	commit, ok := tc.Data.(*CommitSigning)
+	if !ok || commit == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = newTickData()
+		byTick[tc.Tick] = state
+	}
+
+	state.Counts.Add(commit.Status)
+
+	author := state.Authors[tc.AuthorID]
+	if author == nil {
+		author = &Counts{}
+		state.Authors[tc.AuthorID] = author
+	}
+
+	author.Add(commit.Status)
+
+	if commit.Status != gitlib.VerificationUnsigned {
+		key := signerKey(commit)
+
+		signer := state.Signers[key]
+		if signer == nil {
+			signer = &SignerCount{Key: commit.Key, Format: commit.Format}
+			state.Signers[key] = signer
+		}
+
+		signer.Signer = cmp.Or(signer.Signer, commit.Signer)
+		signer.Commits++
+	}
+
+	if len(commit.Protected) > 0 && isViolation(commit.Status) {
+		violation := *commit
+		violation.Hash = tc.CommitHash
+		violation.Tick = tc.Tick
+		violation.AuthorID = tc.AuthorID
+		violation.Time = tc.Timestamp
+
+		state.Violations = append(state.Violations, violation)
+	}
+
+	return nil
+}
+
+func newTickData() *TickData {
+	return &TickData{
+		Authors: make(map[int]*Counts),
+		Signers: make(map[string]*SignerCount),
+	}
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming == nil {
+		return existing
+	}
+
+	existing.Counts.Merge(incoming.Counts)
+
+	// Spilled states decode empty maps as nil.
+	if existing.Authors == nil {
+		existing.Authors = make(map[int]*Counts)
+	}
+
+	if existing.Signers == nil {
+		existing.Signers = make(map[string]*SignerCount)
+	}
+
+	for id, counts := range incoming.Authors {
+		if author := existing.Authors[id]; author != nil {
+			author.Merge(*counts)
+		} else {
+			existing.Authors[id] = counts
+		}
+	}
+
+	for key, count := range incoming.Signers {
+		if signer := existing.Signers[key]; signer != nil {
+			signer.Signer = cmp.Or(signer.Signer, count.Signer)
+			signer.Commits += count.Commits
+		} else {
+			existing.Signers[key] = count
+		}
+	}
+
+	existing.Violations = append(existing.Violations, incoming.Violations...)
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	return tickBytes + int64(len(state.Authors))*authorBytes +
+		int64(len(state.Signers))*signerBytes + int64(len(state.Violations))*violationBytes
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || state.Counts.Commits == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{Tick: tick, Data: state}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
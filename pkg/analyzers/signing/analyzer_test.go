@@ -0,0 +1,202 @@
+package signing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/sensitive"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func newTestAnalyzer(t *testing.T) *Analyzer {
+	t.Helper()
+
+	a := NewAnalyzer()
+	a.TreeDiff = &plumbing.TreeDiffAnalyzer{}
+	a.Ticks = &plumbing.TicksSinceStart{}
+
+	require.NoError(t, a.Initialize(nil))
+
+	return a
+}
+
+func testHash(c string) gitlib.Hash {
+	return gitlib.NewHash(c + "000000000000000000000000000000000000000")
+}
+
+func TestAnalyzer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	assert.Equal(t, "history/signing", a.Name())
+	assert.NotEmpty(t, a.Description())
+	assert.Len(t, a.ListConfigurationOptions(), 3)
+	assert.False(t, a.SequentialOnly())
+	assert.Equal(t, sensitive.DefaultPatterns(), a.ProtectedPaths)
+}
+
+func TestAnalyzer_Configure(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(map[string]any{
+		ConfigSigningProtectedPaths: []string{" deploy/** ", "", "*.tf"},
+		ConfigSigningAllowedSigners: "/etc/allowed_signers",
+		ConfigSigningGPGProgram:     "gpg2",
+	}))
+	assert.Equal(t, []string{"deploy/**", "*.tf"}, a.ProtectedPaths)
+	assert.Equal(t, "/etc/allowed_signers", a.AllowedSigners)
+	assert.Equal(t, "gpg2", a.GPGProgram)
+
+	require.ErrorIs(t, a.Configure(map[string]any{ConfigSigningProtectedPaths: []string{"[z-a"}}), ErrInvalidProtectedPath)
+}
+
+func TestAnalyzer_Initialize_MissingAllowedSigners(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	a.AllowedSigners = "/nonexistent/allowed_signers"
+
+	require.ErrorIs(t, a.Initialize(nil), gitlib.ErrAllowedSigners)
+}
+
+func TestAnalyzer_Consume(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+	a.ProtectedPaths = []string{"auth", ".github/workflows/**"}
+
+	login := gitlib.ChangeEntry{Name: "pkg/auth/login.go", Hash: testHash("1")}
+	moved := gitlib.ChangeEntry{Name: "pkg/session/token.go", Hash: testHash("2")}
+	workflow := gitlib.ChangeEntry{Name: ".github/workflows/ci.yml", Hash: testHash("3")}
+	readme := gitlib.ChangeEntry{Name: "README.md", Hash: testHash("4")}
+
+	a.TreeDiff.Changes = gitlib.Changes{
+		{Action: gitlib.Modify, From: login, To: login},
+		{Action: gitlib.Modify, From: gitlib.ChangeEntry{Name: "pkg/auth/token.go"}, To: moved},
+		{Action: gitlib.Delete, From: workflow},
+		{Action: gitlib.Insert, To: readme},
+	}
+
+	author := gitlib.Signature{Name: "dev", When: time.Now()}
+	commit := gitlib.NewTestCommit(testHash("c"), author, "change auth")
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: commit})
+	require.NoError(t, err)
+	assert.Equal(t, testHash("c"), tc.CommitHash)
+
+	cs, ok := tc.Data.(*CommitSigning)
+	require.True(t, ok)
+	assert.Equal(t, gitlib.VerificationUnsigned, cs.Status)
+	assert.Equal(t, []string{"pkg/auth/login.go", "pkg/session/token.go", ".github/workflows/ci.yml"}, cs.Protected)
+
+	// X.509 signatures are recorded as signed but cannot be checked.
+	signed := gitlib.NewTestCommit(testHash("d"), author, "merge").WithSignature(gitlib.CommitSignature{
+		Format: gitlib.SignatureFormatX509, Signature: "-----BEGIN SIGNED MESSAGE-----", Payload: "tree",
+	})
+
+	tc, err = a.Consume(context.Background(), &analyze.Context{Commit: signed, IsMerge: true})
+	require.NoError(t, err)
+
+	cs, ok = tc.Data.(*CommitSigning)
+	require.True(t, ok)
+	assert.Equal(t, gitlib.VerificationUnchecked, cs.Status)
+	assert.Equal(t, gitlib.SignatureFormatX509, cs.Format)
+	assert.Empty(t, cs.Protected)
+}
+
+func TestAnalyzer_Fork(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer(t)
+	a.ProtectedPaths = []string{"vault"}
+
+	forks := a.Fork(2)
+	require.Len(t, forks, 2)
+
+	for _, f := range forks {
+		clone, ok := f.(*Analyzer)
+		require.True(t, ok)
+		assert.NotSame(t, a.TreeDiff, clone.TreeDiff)
+		assert.NotSame(t, a.Ticks, clone.Ticks)
+		assert.Same(t, a.verifier, clone.verifier)
+		assert.Equal(t, []string{"vault"}, clone.ProtectedPaths)
+	}
+}
+
+func TestAggregator_ViolationsAndCounts(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	good := gitlib.Verification{Status: gitlib.VerificationGood, Signer: "jane@example.com", Key: "SHA256:abc"}
+	unsigned := gitlib.Verification{Status: gitlib.VerificationUnsigned}
+	unchecked := gitlib.Verification{Status: gitlib.VerificationUnchecked}
+
+	byTick := make(map[int]*TickData)
+
+	for _, tc := range []analyze.TC{
+		{
+			Tick: 0, AuthorID: 0, CommitHash: testHash("a"), Timestamp: start.Add(time.Hour),
+			Data: &CommitSigning{Format: gitlib.SignatureFormatSSH, Verification: good, Protected: []string{"auth/a.go"}},
+		},
+		{
+			Tick: 0, AuthorID: 1, CommitHash: testHash("b"), Timestamp: start.Add(2 * time.Hour),
+			Data: &CommitSigning{Verification: unsigned, Protected: []string{"auth/b.go"}},
+		},
+		{
+			Tick: 0, AuthorID: 1, CommitHash: testHash("c"), Timestamp: start,
+			Data: &CommitSigning{Verification: unsigned, Protected: []string{"auth/c.go"}},
+		},
+		{
+			Tick: 1, AuthorID: 0, CommitHash: testHash("d"), Timestamp: start.Add(24 * time.Hour),
+			Data: &CommitSigning{Format: gitlib.SignatureFormatOpenPGP, Verification: unchecked, Protected: []string{"auth/d.go"}},
+		},
+		{
+			Tick: 1, AuthorID: 0, CommitHash: testHash("e"), Timestamp: start.Add(25 * time.Hour),
+			Data: &CommitSigning{Verification: unsigned},
+		},
+	} {
+		require.NoError(t, extractTC(tc, byTick))
+	}
+
+	tick0, err := buildTick(0, byTick[0])
+	require.NoError(t, err)
+
+	tick1, err := buildTick(1, mergeState(&TickData{}, byTick[1]))
+	require.NoError(t, err)
+
+	a := NewAnalyzer()
+
+	report, err := a.ReportFromTICKs(context.Background(), []analyze.TICK{tick0, tick1})
+	require.NoError(t, err)
+
+	series, ok := report[KeyTicks].([]TickCounts)
+	require.True(t, ok)
+	require.Len(t, series, 2)
+	assert.Equal(t, Counts{Commits: 3, Signed: 1, Good: 1}, series[0].Counts)
+	assert.Equal(t, Counts{Commits: 2, Signed: 1, Unchecked: 1}, series[1].Counts)
+
+	authors, ok := report[KeyAuthors].(map[int]Counts)
+	require.True(t, ok)
+	assert.Equal(t, Counts{Commits: 3, Signed: 2, Good: 1, Unchecked: 1}, authors[0])
+	assert.Equal(t, Counts{Commits: 2}, authors[1])
+
+	signers, ok := report[KeySigners].(map[string]SignerCount)
+	require.True(t, ok)
+	assert.Len(t, signers, 2)
+	assert.Equal(t, "jane@example.com", signers["ssh:SHA256:abc"].Signer)
+
+	violations, ok := report[KeyViolations].([]CommitSigning)
+	require.True(t, ok)
+	require.Len(t, violations, 2)
+	assert.Equal(t, testHash("c"), violations[0].Hash)
+	assert.Equal(t, testHash("b"), violations[1].Hash)
+	assert.Equal(t, 1, violations[1].AuthorID)
+}
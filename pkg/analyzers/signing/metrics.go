@@ -0,0 +1,214 @@
+package signing
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+// Coverage is the signing coverage of a set of commits: how many are signed
+// and how many have a good signature.
+type Coverage struct {
+	Commits       int     `json:"commits"        yaml:"commits"`
+	Signed        int     `json:"signed"         yaml:"signed"`
+	Verified      int     `json:"verified"       yaml:"verified"`
+	SignedRatio   float64 `json:"signed_ratio"   yaml:"signed_ratio"`
+	VerifiedRatio float64 `json:"verified_ratio" yaml:"verified_ratio"`
+}
+
+// TickCoverage is the signing coverage of one tick.
+type TickCoverage struct {
+	Tick          int     `json:"tick"           yaml:"tick"`
+	Commits       int     `json:"commits"        yaml:"commits"`
+	Signed        int     `json:"signed"         yaml:"signed"`
+	Verified      int     `json:"verified"       yaml:"verified"`
+	SignedRatio   float64 `json:"signed_ratio"   yaml:"signed_ratio"`
+	VerifiedRatio float64 `json:"verified_ratio" yaml:"verified_ratio"`
+}
+
+// AuthorCoverage is the signing coverage of the commits of one author.
+type AuthorCoverage struct {
+	Author      string  `json:"author"       yaml:"author"`
+	Commits     int     `json:"commits"      yaml:"commits"`
+	Signed      int     `json:"signed"       yaml:"signed"`
+	Verified    int     `json:"verified"     yaml:"verified"`
+	SignedRatio float64 `json:"signed_ratio" yaml:"signed_ratio"`
+}
+
+// Violation is a commit that changed protected paths without a valid
+// signature.
+type Violation struct {
+	Hash   string                    `json:"hash"   yaml:"hash"`
+	Author string                    `json:"author" yaml:"author"`
+	Tick   int                       `json:"tick"   yaml:"tick"`
+	Time   time.Time                 `json:"time"   yaml:"time"`
+	Status gitlib.VerificationStatus `json:"status" yaml:"status"`
+	Format gitlib.SignatureFormat    `json:"format" yaml:"format"`
+	Signer string                    `json:"signer" yaml:"signer"`
+	Files  []string                  `json:"files"  yaml:"files"`
+}
+
+// ComputedMetrics is the signing coverage of the history, overall, per tick
+// and per author, with the signing keys in use and the violations.
+type ComputedMetrics struct {
+	Coverage Coverage `json:"coverage" yaml:"coverage"`
+	// Statuses counts all commits by verification outcome.
+	Statuses Counts         `json:"statuses" yaml:"statuses"`
+	Trend    []TickCoverage `json:"trend"    yaml:"trend"`
+	// Authors are ordered by unsigned commits, most first.
+	Authors []AuthorCoverage `json:"authors" yaml:"authors"`
+	// Signers are ordered by signed commits, most first.
+	Signers []SignerCount `json:"signers" yaml:"signers"`
+	// Violations are in history order.
+	Violations []Violation `json:"violations" yaml:"violations"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNameSigning = "signing"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNameSigning
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// ComputeAllMetrics computes the signing coverage and the violations of a
+// report.
+func ComputeAllMetrics(report analyze.Report) *ComputedMetrics {
+	series, _ := report[KeyTicks].([]TickCounts)
+	authors, _ := report[KeyAuthors].(map[int]Counts)
+	signers, _ := report[KeySigners].(map[string]SignerCount)
+	violations, _ := report[KeyViolations].([]CommitSigning)
+	names, _ := report[KeyAuthorIndex].([]string)
+
+	m := &ComputedMetrics{Trend: make([]TickCoverage, len(series))}
+
+	for i, point := range series {
+		m.Statuses.Merge(point.Counts)
+
+		coverage := newCoverage(point.Counts)
+		m.Trend[i] = TickCoverage{
+			Tick: point.Tick, Commits: coverage.Commits, Signed: coverage.Signed, Verified: coverage.Verified,
+			SignedRatio: coverage.SignedRatio, VerifiedRatio: coverage.VerifiedRatio,
+		}
+	}
+
+	m.Coverage = newCoverage(m.Statuses)
+
+	for id, counts := range authors {
+		coverage := newCoverage(counts)
+		m.Authors = append(m.Authors, AuthorCoverage{
+			Author: authorName(names, id), Commits: coverage.Commits, Signed: coverage.Signed,
+			Verified: coverage.Verified, SignedRatio: coverage.SignedRatio,
+		})
+	}
+
+	slices.SortFunc(m.Authors, func(x, y AuthorCoverage) int {
+		return cmp.Or(
+			cmp.Compare(y.Commits-y.Signed, x.Commits-x.Signed),
+			cmp.Compare(y.Commits, x.Commits),
+			cmp.Compare(x.Author, y.Author),
+		)
+	})
+
+	for _, signer := range signers {
+		m.Signers = append(m.Signers, signer)
+	}
+
+	slices.SortFunc(m.Signers, func(x, y SignerCount) int {
+		return cmp.Or(cmp.Compare(y.Commits, x.Commits), cmp.Compare(x.Signer, y.Signer), cmp.Compare(x.Key, y.Key))
+	})
+
+	for _, commit := range violations {
+		m.Violations = append(m.Violations, Violation{
+			Hash:   commit.Hash.String(),
+			Author: authorName(names, commit.AuthorID),
+			Tick:   commit.Tick,
+			Time:   commit.Time,
+			Status: commit.Status,
+			Format: commit.Format,
+			Signer: commit.Signer,
+			Files:  commit.Protected,
+		})
+	}
+
+	m.scaleCounts(analyze.SampleFactor(report))
+
+	return m
+}
+
+// scaleCounts scales the commit counts of a sampled history by factor to
+// estimate the full history. Ratios and the listed violations are kept.
+func (m *ComputedMetrics) scaleCounts(factor float64) {
+	if factor <= 1 {
+		return
+	}
+
+	scale := func(n *int) { *n = analyze.ScaleCount(*n, factor) }
+
+	scale(&m.Coverage.Commits)
+	scale(&m.Coverage.Signed)
+	scale(&m.Coverage.Verified)
+
+	for _, n := range []*int{
+		&m.Statuses.Commits, &m.Statuses.Signed, &m.Statuses.Good, &m.Statuses.Bad,
+		&m.Statuses.Expired, &m.Statuses.UnknownKey, &m.Statuses.Unchecked,
+	} {
+		scale(n)
+	}
+
+	for i := range m.Trend {
+		scale(&m.Trend[i].Commits)
+		scale(&m.Trend[i].Signed)
+		scale(&m.Trend[i].Verified)
+	}
+
+	for i := range m.Authors {
+		scale(&m.Authors[i].Commits)
+		scale(&m.Authors[i].Signed)
+		scale(&m.Authors[i].Verified)
+	}
+
+	for i := range m.Signers {
+		scale(&m.Signers[i].Commits)
+	}
+}
+
+func newCoverage(counts Counts) Coverage {
+	return Coverage{
+		Commits:       counts.Commits,
+		Signed:        counts.Signed,
+		Verified:      counts.Good,
+		SignedRatio:   ratio(counts.Signed, counts.Commits),
+		VerifiedRatio: ratio(counts.Good, counts.Commits),
+	}
+}
+
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) / float64(total)
+}
+
+func authorName(names []string, id int) string {
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return identity.AuthorMissingName
+}
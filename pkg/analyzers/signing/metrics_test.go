@@ -0,0 +1,103 @@
+package signing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+)
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m := ComputeAllMetrics(analyze.Report{})
+	assert.Zero(t, m.Coverage.Commits)
+	assert.Zero(t, m.Coverage.SignedRatio)
+	assert.Empty(t, m.Trend)
+	assert.Empty(t, m.Violations)
+}
+
+func TestComputeAllMetrics_CoverageAndViolations(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	report := analyze.Report{
+		KeyTicks: []TickCounts{
+			{Tick: 0, Counts: Counts{Commits: 4, Signed: 1, Good: 1}},
+			{Tick: 3, Counts: Counts{Commits: 4, Signed: 4, Good: 3, UnknownKey: 1}},
+		},
+		KeyAuthors: map[int]Counts{
+			0: {Commits: 5, Signed: 5, Good: 4, UnknownKey: 1},
+			1: {Commits: 2},
+			7: {Commits: 1},
+		},
+		KeySigners: map[string]SignerCount{
+			"ssh:SHA256:a":     {Signer: "alice", Key: "SHA256:a", Format: gitlib.SignatureFormatSSH, Commits: 4},
+			"openpgp:FEDCBA98": {Signer: "bob", Key: "FEDCBA98", Format: gitlib.SignatureFormatOpenPGP, Commits: 1},
+		},
+		KeyViolations: []CommitSigning{
+			{
+				Hash: testHash("a"), Tick: 0, AuthorID: 1, Time: start,
+				Verification: gitlib.Verification{Status: gitlib.VerificationUnsigned},
+				Protected:    []string{"auth/login.go"},
+			},
+		},
+		KeyAuthorIndex: []string{"alice", "bob"},
+	}
+
+	m := ComputeAllMetrics(report)
+
+	assert.Equal(t, Counts{Commits: 8, Signed: 5, Good: 4, UnknownKey: 1}, m.Statuses)
+	assert.Equal(t, 4, m.Coverage.Verified)
+	assert.InDelta(t, 0.625, m.Coverage.SignedRatio, 1e-9)
+	assert.InDelta(t, 0.5, m.Coverage.VerifiedRatio, 1e-9)
+
+	require.Len(t, m.Trend, 2)
+	assert.Equal(t, 3, m.Trend[1].Tick)
+	assert.InDelta(t, 0.75, m.Trend[1].VerifiedRatio, 1e-9)
+
+	require.Len(t, m.Authors, 3)
+	assert.Equal(t, "bob", m.Authors[0].Author)
+	assert.Equal(t, identity.AuthorMissingName, m.Authors[1].Author)
+	assert.Equal(t, "alice", m.Authors[2].Author)
+	assert.InDelta(t, 1.0, m.Authors[2].SignedRatio, 1e-9)
+
+	require.Len(t, m.Signers, 2)
+	assert.Equal(t, "alice", m.Signers[0].Signer)
+
+	require.Len(t, m.Violations, 1)
+	assert.Equal(t, Violation{
+		Hash: testHash("a").String(), Author: "bob", Tick: 0, Time: start,
+		Status: gitlib.VerificationUnsigned, Files: []string{"auth/login.go"},
+	}, m.Violations[0])
+}
+
+func TestComputeAllMetrics_ScalesSampledCounts(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		KeyTicks:       []TickCounts{{Tick: 0, Counts: Counts{Commits: 4, Signed: 2, Good: 1, Bad: 1}}},
+		KeyAuthors:     map[int]Counts{0: {Commits: 4, Signed: 2, Good: 1, Bad: 1}},
+		KeySigners:     map[string]SignerCount{"ssh:SHA256:a": {Signer: "alice", Commits: 2}},
+		KeyAuthorIndex: []string{"alice"},
+
+		analyze.ReportKeySampleFactor: 2.0,
+	}
+
+	m := ComputeAllMetrics(report)
+
+	assert.Equal(t, Counts{Commits: 8, Signed: 4, Good: 2, Bad: 2}, m.Statuses)
+	assert.Equal(t, Coverage{Commits: 8, Signed: 4, Verified: 2, SignedRatio: 0.5, VerifiedRatio: 0.25}, m.Coverage)
+	require.Len(t, m.Trend, 1)
+	assert.Equal(t, 8, m.Trend[0].Commits)
+	require.Len(t, m.Authors, 1)
+	assert.Equal(t, 4, m.Authors[0].Signed)
+	require.Len(t, m.Signers, 1)
+	assert.Equal(t, 4, m.Signers[0].Commits)
+}
@@ -0,0 +1,14 @@
+package signing
+
+import "github.com/Sumatoshi-tech/codefang/pkg/analyzers/sensitive"
+
+// matchProtected reports whether file matches any of patterns.
+func matchProtected(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if sensitive.MatchPattern(pattern, file) {
+			return true
+		}
+	}
+
+	return false
+}
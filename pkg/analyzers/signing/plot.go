@@ -0,0 +1,143 @@
+package signing
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const (
+	shortHashLen = 8
+	percent      = 100
+)
+
+// RegisterPlotSections registers the signing plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/signing", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	m := ComputeAllMetrics(report)
+
+	return []plotpage.Section{
+		{
+			Title: "Signing Coverage",
+			Subtitle: formatPercent(m.Coverage.SignedRatio) + " of commits are signed, " +
+				formatPercent(m.Coverage.VerifiedRatio) + " with a good signature.",
+			Chart: plotpage.WrapChart(buildCoverageChart(m.Trend)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"<strong>Verified</strong> = signed by a key of the OpenPGP keyring or of the SSH allowed signers",
+					"A gap between signed and verified means signing keys are missing from the trusted keys",
+				},
+			},
+		},
+		{
+			Title:    "Violations",
+			Subtitle: strconv.Itoa(len(m.Violations)) + " commits changed protected paths without a valid signature.",
+			Chart:    buildViolationTable(m.Violations),
+		},
+		{
+			Title:    "Authors",
+			Subtitle: "Signing coverage per author, most unsigned commits first.",
+			Chart:    buildAuthorTable(m.Authors),
+		},
+		{
+			Title:    "Signing Keys",
+			Subtitle: strconv.Itoa(len(m.Signers)) + " keys signed commits.",
+			Chart:    buildSignerTable(m.Signers),
+		},
+	}, nil
+}
+
+func buildCoverageChart(trend []TickCoverage) *charts.Line {
+	labels := make([]string, len(trend))
+	signed := make([]plotpage.SeriesData, len(trend))
+	verified := make([]plotpage.SeriesData, len(trend))
+
+	for i, point := range trend {
+		labels[i] = strconv.Itoa(point.Tick)
+		signed[i] = point.SignedRatio * percent
+		verified[i] = point.VerifiedRatio * percent
+	}
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: "Signed", Data: signed},
+		{Name: "Verified", Data: verified},
+	}, "Commits (%)")
+}
+
+func buildViolationTable(violations []Violation) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Commit", "Author", "Tick", "Status", "Files"}).
+		WithSearch("Filter violations...")
+
+	for _, violation := range violations {
+		files := make([]string, len(violation.Files))
+		for i, file := range violation.Files {
+			files[i] = html.EscapeString(file)
+		}
+
+		table.AddRow(
+			shortHash(violation.Hash),
+			html.EscapeString(violation.Author),
+			strconv.Itoa(violation.Tick),
+			string(violation.Status),
+			strings.Join(files, "<br>"),
+		)
+	}
+
+	return table
+}
+
+func buildAuthorTable(authors []AuthorCoverage) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Author", "Commits", "Signed", "Verified", "Signed Ratio"}).
+		WithSearch("Filter authors...")
+
+	for _, author := range authors {
+		table.AddRow(
+			html.EscapeString(author.Author),
+			strconv.Itoa(author.Commits),
+			strconv.Itoa(author.Signed),
+			strconv.Itoa(author.Verified),
+			formatPercent(author.SignedRatio),
+		)
+	}
+
+	return table
+}
+
+func buildSignerTable(signers []SignerCount) *plotpage.Table {
+	table := plotpage.NewTable([]string{"Signer", "Format", "Key", "Commits"})
+
+	for _, signer := range signers {
+		table.AddRow(
+			html.EscapeString(signer.Signer),
+			string(signer.Format),
+			html.EscapeString(signer.Key),
+			strconv.Itoa(signer.Commits),
+		)
+	}
+
+	return table
+}
+
+func formatPercent(ratio float64) string {
+	return strconv.FormatFloat(ratio*percent, 'f', 1, 64) + "%"
+}
+
+func shortHash(hash string) string {
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+
+	return hash
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/levenshtein"
 	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
 	"github.com/Sumatoshi-tech/codefang/pkg/safeconv"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
@@ -25,6 +26,8 @@ type Typo struct {
 	File    string
 	Commit  gitlib.Hash
 	Line    int
+	// Language is the natural language of the commit message.
+	Language string
 }
 
 // TickData is the aggregated payload stored in analyze.TICK.Data.
@@ -37,6 +40,8 @@ const (
 	DefaultMaximumAllowedTypoDistance = 4
 	// ConfigTyposDatasetMaximumAllowedDistance is the configuration key for the maximum Levenshtein distance.
 	ConfigTyposDatasetMaximumAllowedDistance = "TyposDatasetBuilder.MaximumAllowedDistance"
+	// ConfigTyposDatasetDictionaries is the configuration key for the dictionary languages applied to every commit.
+	ConfigTyposDatasetDictionaries = "TyposDatasetBuilder.Dictionaries"
 )
 
 // Analyzer detects typo-fix identifier pairs across commit history.
@@ -46,13 +51,17 @@ type Analyzer struct {
 	UAST                   *plumbing.UASTChangesAnalyzer
 	FileDiff               *plumbing.FileDiffAnalyzer
 	BlobCache              *plumbing.BlobCacheAnalyzer
+	MessageLanguage        *plumbing.MessageLanguageDetector
 	lcontext               *levenshtein.Context
 	MaximumAllowedDistance int
+	// Dictionaries are the languages whose spelling variants are never
+	// reported as typos. The commit message language is added per commit.
+	Dictionaries []string
 }
 
 // NewAnalyzer creates a new typos analyzer.
 func NewAnalyzer() *Analyzer {
-	a := &Analyzer{}
+	a := &Analyzer{Dictionaries: DefaultDictionaries}
 	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
 		Desc: analyze.Descriptor{
 			ID:          "history/typos",
@@ -60,6 +69,7 @@ func NewAnalyzer() *Analyzer {
 			Mode:        analyze.ModeHistory,
 		},
 		Sequential: false,
+		Caps:       &analyze.Capabilities{NeedsBlobs: true, NeedsDiffs: true, NeedsUAST: true, Memory: analyze.MemoryLow},
 		ConfigOptions: []pipeline.ConfigurationOption{
 			{
 				Name:        ConfigTyposDatasetMaximumAllowedDistance,
@@ -68,6 +78,14 @@ func NewAnalyzer() *Analyzer {
 				Type:        pipeline.IntConfigurationOption,
 				Default:     DefaultMaximumAllowedTypoDistance,
 			},
+			{
+				Name: ConfigTyposDatasetDictionaries,
+				Description: "Languages whose spelling variants, such as color and colour, are not typos, " +
+					"separated by commas. The dictionary of each commit's message language is added.",
+				Flag:    "typos-dictionaries",
+				Type:    pipeline.StringsConfigurationOption,
+				Default: DefaultDictionaries,
+			},
 		},
 		ComputeMetricsFn: computeMetricsSafe,
 		AggregatorFn:     newAggregator,
@@ -96,6 +114,10 @@ func (t *Analyzer) Configure(facts map[string]any) error {
 		t.MaximumAllowedDistance = DefaultMaximumAllowedTypoDistance
 	}
 
+	if val, exists := facts[ConfigTyposDatasetDictionaries].([]string); exists {
+		t.Dictionaries = val
+	}
+
 	return nil
 }
 
@@ -198,10 +220,13 @@ func (t *Analyzer) matchDeleteInsertPairs(
 
 // matchTypoIdentifiers extracts identifiers from the before/after UAST nodes that fall on
 // the focused lines, and returns the matched typo pairs from the given candidates.
+// Pairs that dicts accept as spelling variants of each other are skipped.
 func (t *Analyzer) matchTypoIdentifiers(
 	change uast.Change,
 	result typoCandidateResult,
 	commit gitlib.Hash,
+	language string,
+	dicts []dictionary,
 ) []Typo {
 	removedIdentifiers := collectIdentifiersOnLines(change.Before, result.focusedLinesBefore)
 	addedIdentifiers := collectIdentifiersOnLines(change.After, result.focusedLinesAfter)
@@ -212,15 +237,22 @@ func (t *Analyzer) matchTypoIdentifiers(
 		nodesBefore := removedIdentifiers[cand.Before]
 		nodesAfter := addedIdentifiers[cand.After]
 
-		if len(nodesBefore) == 1 && len(nodesAfter) == 1 {
-			typos = append(typos, Typo{
-				Wrong:   nodesBefore[0].Token,
-				Correct: nodesAfter[0].Token,
-				Commit:  commit,
-				File:    change.Change.To.Name,
-				Line:    cand.After,
-			})
+		if len(nodesBefore) != 1 || len(nodesAfter) != 1 {
+			continue
+		}
+
+		if isSpellingVariant(nodesBefore[0].Token, nodesAfter[0].Token, dicts) {
+			continue
 		}
+
+		typos = append(typos, Typo{
+			Wrong:    nodesBefore[0].Token,
+			Correct:  nodesAfter[0].Token,
+			Commit:   commit,
+			File:     change.Change.To.Name,
+			Line:     cand.After,
+			Language: language,
+		})
 	}
 
 	return typos
@@ -254,6 +286,8 @@ func (t *Analyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC
 	changes := t.UAST.Changes(ctx)
 	cache := t.BlobCache.Cache
 	diffs := t.FileDiff.FileDiffs
+	language := t.messageLanguage()
+	dicts := selectDictionaries(t.Dictionaries, language)
 
 	var typos []Typo
 
@@ -278,7 +312,7 @@ func (t *Analyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC
 			continue
 		}
 
-		typos = append(typos, t.matchTypoIdentifiers(change, result, commit)...)
+		typos = append(typos, t.matchTypoIdentifiers(change, result, commit, language, dicts)...)
 	}
 
 	if len(typos) == 0 {
@@ -291,6 +325,15 @@ func (t *Analyzer) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC
 	}, nil
 }
 
+// messageLanguage returns the language of the current commit's message.
+func (t *Analyzer) messageLanguage() string {
+	if t.MessageLanguage == nil {
+		return pkgplumbing.MessageLanguageUnknown
+	}
+
+	return t.MessageLanguage.Language
+}
+
 func extractIdentifiers(root *node.Node) []*node.Node {
 	var identifiers []*node.Node
 
@@ -312,7 +355,9 @@ func (t *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
 			UAST:                   &plumbing.UASTChangesAnalyzer{},
 			BlobCache:              &plumbing.BlobCacheAnalyzer{},
 			FileDiff:               &plumbing.FileDiffAnalyzer{},
+			MessageLanguage:        &plumbing.MessageLanguageDetector{},
 			MaximumAllowedDistance: t.MaximumAllowedDistance,
+			Dictionaries:           t.Dictionaries,
 			lcontext:               &levenshtein.Context{},
 		}
 		res[i] = clone
@@ -327,15 +372,13 @@ func (t *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
 // CPUHeavy returns true because typo detection performs UAST processing per commit.
 func (t *Analyzer) CPUHeavy() bool { return true }
 
-// NeedsUAST returns true to enable the UAST pipeline.
-func (t *Analyzer) NeedsUAST() bool { return true }
-
 // SnapshotPlumbing captures the current plumbing output state for parallel execution.
 func (t *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
 	return plumbing.Snapshot{
-		UASTChanges: t.UAST.TransferChanges(),
-		BlobCache:   t.BlobCache.Cache,
-		FileDiffs:   t.FileDiff.FileDiffs,
+		UASTChanges:     t.UAST.TransferChanges(),
+		BlobCache:       t.BlobCache.Cache,
+		FileDiffs:       t.FileDiff.FileDiffs,
+		MessageLanguage: t.messageLanguage(),
 	}
 }
 
@@ -349,6 +392,10 @@ func (t *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
 	t.UAST.SetChanges(ss.UASTChanges)
 	t.BlobCache.Cache = ss.BlobCache
 	t.FileDiff.FileDiffs = ss.FileDiffs
+
+	if t.MessageLanguage != nil {
+		t.MessageLanguage.Language = ss.MessageLanguage
+	}
 }
 
 // ReleaseSnapshot releases UAST trees owned by the snapshot.
@@ -52,6 +52,19 @@ func TestAnalyzer_Configure(t *testing.T) {
 	})
 	require.NoError(t, err)
 	assert.Equal(t, 3, h.MaximumAllowedDistance)
+	assert.Equal(t, DefaultDictionaries, h.Dictionaries)
+}
+
+func TestAnalyzer_Configure_Dictionaries(t *testing.T) {
+	t.Parallel()
+
+	h := NewAnalyzer()
+
+	err := h.Configure(map[string]any{
+		ConfigTyposDatasetDictionaries: []string{"en", "pt"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"en", "pt"}, h.Dictionaries)
 }
 
 func TestAnalyzer_Configure_Default(t *testing.T) {
@@ -79,6 +92,7 @@ func TestAnalyzer_Fork(t *testing.T) {
 
 	h := NewAnalyzer()
 	h.MaximumAllowedDistance = 5
+	h.Dictionaries = []string{"de"}
 	require.NoError(t, h.Initialize(nil))
 
 	forks := h.Fork(3)
@@ -89,6 +103,8 @@ func TestAnalyzer_Fork(t *testing.T) {
 		require.True(t, ok, "fork %d should be *Analyzer", i)
 		assert.NotSame(t, h, analyzer)
 		assert.Equal(t, 5, analyzer.MaximumAllowedDistance)
+		assert.Equal(t, []string{"de"}, analyzer.Dictionaries)
+		assert.NotNil(t, analyzer.MessageLanguage)
 		assert.NotNil(t, analyzer.lcontext)
 		assert.NotNil(t, analyzer.UAST)
 		assert.NotNil(t, analyzer.BlobCache)
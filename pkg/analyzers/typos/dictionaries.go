@@ -0,0 +1,231 @@
+package typos
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultDictionaries are the dictionary languages applied to every commit.
+// Identifiers are mostly English whatever language the team writes in.
+var DefaultDictionaries = []string{"en"}
+
+// dictionary holds the accepted spelling variants of one natural language:
+// regional and orthographic spellings of the same word, which are edits
+// close enough to pass the distance threshold but are not typos.
+type dictionary struct {
+	// variants maps every variant spelling to the canonical one.
+	variants map[string]string
+	// fold, when set, rewrites a word before the variant lookup.
+	fold func(word string) string
+}
+
+// canonical returns the spelling word is grouped under.
+func (d dictionary) canonical(word string) string {
+	if d.fold != nil {
+		word = d.fold(word)
+	}
+
+	if canon, ok := d.variants[word]; ok {
+		return canon
+	}
+
+	return word
+}
+
+// newDictionary builds a dictionary from groups of variant spellings; the
+// first spelling of each group is the canonical one.
+func newDictionary(fold func(string) string, groups ...[]string) dictionary {
+	d := dictionary{variants: make(map[string]string), fold: fold}
+
+	for _, group := range groups {
+		for _, word := range group[1:] {
+			d.variants[word] = group[0]
+		}
+	}
+
+	return d
+}
+
+// dictionaries holds the spelling-variant dictionaries by ISO 639-1 code,
+// the codes pkgplumbing.DetectMessageLanguage returns.
+var dictionaries = map[string]dictionary{
+	// American and British English.
+	"en": newDictionary(nil,
+		[]string{"color", "colour"},
+		[]string{"colors", "colours"},
+		[]string{"behavior", "behaviour"},
+		[]string{"behaviors", "behaviours"},
+		[]string{"favor", "favour"},
+		[]string{"favorite", "favourite"},
+		[]string{"honor", "honour"},
+		[]string{"flavor", "flavour"},
+		[]string{"neighbor", "neighbour"},
+		[]string{"neighbors", "neighbours"},
+		[]string{"labeled", "labelled"},
+		[]string{"labeling", "labelling"},
+		[]string{"canceled", "cancelled"},
+		[]string{"canceling", "cancelling"},
+		[]string{"modeled", "modelled"},
+		[]string{"modeling", "modelling"},
+		[]string{"traveled", "travelled"},
+		[]string{"center", "centre"},
+		[]string{"centered", "centred"},
+		[]string{"meter", "metre"},
+		[]string{"liter", "litre"},
+		[]string{"fiber", "fibre"},
+		[]string{"license", "licence"},
+		[]string{"defense", "defence"},
+		[]string{"offense", "offence"},
+		[]string{"analyze", "analyse"},
+		[]string{"analyzer", "analyser"},
+		[]string{"analyzed", "analysed"},
+		[]string{"initialize", "initialise"},
+		[]string{"initialized", "initialised"},
+		[]string{"normalize", "normalise"},
+		[]string{"normalized", "normalised"},
+		[]string{"serialize", "serialise"},
+		[]string{"serialized", "serialised"},
+		[]string{"serializer", "serialiser"},
+		[]string{"optimize", "optimise"},
+		[]string{"optimized", "optimised"},
+		[]string{"organize", "organise"},
+		[]string{"recognize", "recognise"},
+		[]string{"customize", "customise"},
+		[]string{"authorize", "authorise"},
+		[]string{"authorized", "authorised"},
+		[]string{"synchronize", "synchronise"},
+		[]string{"synchronized", "synchronised"},
+		[]string{"finalize", "finalise"},
+		[]string{"finalizer", "finaliser"},
+		[]string{"gray", "grey"},
+		[]string{"catalog", "catalogue"},
+		[]string{"dialog", "dialogue"},
+		[]string{"artifact", "artefact"},
+		[]string{"artifacts", "artefacts"},
+		[]string{"judgment", "judgement"},
+		[]string{"acknowledgment", "acknowledgement"},
+	),
+	// Umlauts and ß, and their ASCII transliterations.
+	"de": newDictionary(germanFold),
+	// Brazilian and European Portuguese, as spelled in ASCII identifiers.
+	"pt": newDictionary(nil,
+		[]string{"registro", "registo"},
+		[]string{"contato", "contacto"},
+		[]string{"ativo", "activo"},
+		[]string{"ativa", "activa"},
+		[]string{"atual", "actual"},
+		[]string{"ator", "actor"},
+		[]string{"projeto", "projecto"},
+		[]string{"objeto", "objecto"},
+		[]string{"diretorio", "directorio"},
+		[]string{"direcao", "direccao"},
+		[]string{"secao", "seccao"},
+		[]string{"acao", "accao"},
+		[]string{"excecao", "excepcao"},
+		[]string{"fato", "facto"},
+	),
+}
+
+// germanFold transliterates umlauts and ß the way ASCII identifiers spell them.
+func germanFold(word string) string {
+	return strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss").Replace(word)
+}
+
+// selectDictionaries returns the dictionaries of the configured languages
+// and of the commit message language, skipping languages without one.
+func selectDictionaries(configured []string, messageLanguage string) []dictionary {
+	var selected []dictionary
+
+	seen := make(map[string]bool)
+
+	for _, lang := range slices.Concat(configured, []string{messageLanguage}) {
+		d, ok := dictionaries[lang]
+		if !ok || seen[lang] {
+			continue
+		}
+
+		seen[lang] = true
+
+		selected = append(selected, d)
+	}
+
+	return selected
+}
+
+// isSpellingVariant reports whether the identifiers wrong and correct only
+// differ in words that one of dicts accepts as spellings of the same word,
+// such as colorName and colourName.
+func isSpellingVariant(wrong, correct string, dicts []dictionary) bool {
+	wrongWords, correctWords := identifierWords(wrong), identifierWords(correct)
+	if len(dicts) == 0 || len(wrongWords) != len(correctWords) {
+		return false
+	}
+
+	differs := false
+
+	for i, word := range wrongWords {
+		if word == correctWords[i] {
+			continue
+		}
+
+		if !sameWord(word, correctWords[i], dicts) {
+			return false
+		}
+
+		differs = true
+	}
+
+	return differs
+}
+
+// sameWord reports whether a dictionary maps a and b to the same spelling.
+func sameWord(a, b string, dicts []dictionary) bool {
+	for _, d := range dicts {
+		if d.canonical(a) == d.canonical(b) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// identifierWords splits an identifier into its lower-case words at
+// underscores, hyphens, digits and camelCase boundaries.
+func identifierWords(identifier string) []string {
+	var (
+		words []string
+		word  []rune
+	)
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(identifier)
+
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r):
+			flush()
+
+			continue
+		case unicode.IsUpper(r) && len(word) > 0:
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if prevLower || nextLower {
+				flush()
+			}
+		}
+
+		word = append(word, r)
+	}
+
+	flush()
+
+	return words
+}
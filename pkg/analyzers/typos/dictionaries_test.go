@@ -0,0 +1,59 @@
+package typos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentifierWords(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"color", "name"}, identifierWords("colorName"))
+	assert.Equal(t, []string{"http", "server", "v"}, identifierWords("HTTPServer_v2"))
+	assert.Equal(t, []string{"größe"}, identifierWords("Größe"))
+	assert.Empty(t, identifierWords("_"))
+}
+
+func TestSelectDictionaries(t *testing.T) {
+	t.Parallel()
+
+	assert.Len(t, selectDictionaries([]string{"en"}, "und"), 1)
+	assert.Len(t, selectDictionaries([]string{"en"}, "en"), 1, "languages are selected once")
+	assert.Len(t, selectDictionaries([]string{"en"}, "pt"), 2, "the message language's dictionary is added")
+	assert.Empty(t, selectDictionaries(nil, "ru"), "languages without a dictionary are skipped")
+}
+
+func TestIsSpellingVariant(t *testing.T) {
+	t.Parallel()
+
+	english := selectDictionaries([]string{"en"}, "und")
+	portuguese := selectDictionaries([]string{"en"}, "pt")
+	german := selectDictionaries(nil, "de")
+
+	tests := []struct {
+		name    string
+		wrong   string
+		correct string
+		dicts   []dictionary
+		want    bool
+	}{
+		{"british_english", "colorName", "colourName", english, true},
+		{"snake_case", "initialize_cache", "initialise_cache", english, true},
+		{"real_typo", "recieve", "receive", english, false},
+		{"variant_and_typo", "colorNmae", "colourName", english, false},
+		{"same_identifier", "colorName", "colorName", english, false},
+		{"message_language", "projetoID", "projectoID", portuguese, true},
+		{"not_selected", "projetoID", "projectoID", english, false},
+		{"transliteration", "groesseBerechnen", "größeBerechnen", german, true},
+		{"no_dictionaries", "colorName", "colourName", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, isSpellingVariant(tt.wrong, tt.correct, tt.dicts))
+		})
+	}
+}
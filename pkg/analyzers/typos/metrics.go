@@ -29,11 +29,12 @@ func ParseReportData(report analyze.Report) (*ReportData, error) {
 
 // TypoData contains information about a single typo fix.
 type TypoData struct {
-	Wrong   string `json:"wrong"   yaml:"wrong"`
-	Correct string `json:"correct" yaml:"correct"`
-	File    string `json:"file"    yaml:"file"`
-	Line    int    `json:"line"    yaml:"line"`
-	Commit  string `json:"commit"  yaml:"commit"`
+	Wrong    string `json:"wrong"              yaml:"wrong"`
+	Correct  string `json:"correct"            yaml:"correct"`
+	File     string `json:"file"               yaml:"file"`
+	Line     int    `json:"line"               yaml:"line"`
+	Commit   string `json:"commit"             yaml:"commit"`
+	Language string `json:"language,omitempty" yaml:"language,omitempty"`
 }
 
 // TypoPatternData contains common typo patterns.
@@ -50,6 +51,13 @@ type FileTypoData struct {
 	FixedTypos int    `json:"fixed_typos" yaml:"fixed_typos"`
 }
 
+// LanguageTypoData counts the typo fixes of the commits whose messages are
+// written in one natural language.
+type LanguageTypoData struct {
+	Language string `json:"language" yaml:"language"`
+	Typos    int    `json:"typos"    yaml:"typos"`
+}
+
 // AggregateData contains summary statistics.
 type AggregateData struct {
 	TotalTypos      int `json:"total_typos"      yaml:"total_typos"`
@@ -62,10 +70,11 @@ type AggregateData struct {
 
 // ComputedMetrics holds all computed metric results for the typos analyzer.
 type ComputedMetrics struct {
-	TypoList  []TypoData        `json:"typo_list"  yaml:"typo_list"`
-	Patterns  []TypoPatternData `json:"patterns"   yaml:"patterns"`
-	FileTypos []FileTypoData    `json:"file_typos" yaml:"file_typos"`
-	Aggregate AggregateData     `json:"aggregate"  yaml:"aggregate"`
+	TypoList  []TypoData         `json:"typo_list"           yaml:"typo_list"`
+	Patterns  []TypoPatternData  `json:"patterns"            yaml:"patterns"`
+	FileTypos []FileTypoData     `json:"file_typos"          yaml:"file_typos"`
+	Aggregate AggregateData      `json:"aggregate"           yaml:"aggregate"`
+	Languages []LanguageTypoData `json:"languages,omitempty" yaml:"languages,omitempty"`
 }
 
 // Analyzer name constant for MetricsOutput interface.
@@ -93,12 +102,19 @@ func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
 		return nil, err
 	}
 
-	return &ComputedMetrics{
+	m := &ComputedMetrics{
 		TypoList:  computeTypoList(input),
 		Patterns:  computeTypoPatterns(input),
 		FileTypos: computeFileTypos(input),
 		Aggregate: computeAggregate(input),
-	}, nil
+		Languages: computeLanguages(input),
+	}
+
+	// The typos come from the diffs, which span the commits skipped between
+	// samples; only the commits that fixed them are undercounted.
+	m.Aggregate.AffectedCommits = analyze.ScaleCount(m.Aggregate.AffectedCommits, analyze.SampleFactor(report))
+
+	return m, nil
 }
 
 // --- Metric Implementations ---.
@@ -108,11 +124,12 @@ func computeTypoList(input *ReportData) []TypoData {
 
 	for _, t := range input.Typos {
 		result = append(result, TypoData{
-			Wrong:   t.Wrong,
-			Correct: t.Correct,
-			File:    t.File,
-			Line:    t.Line,
-			Commit:  t.Commit.String(),
+			Wrong:    t.Wrong,
+			Correct:  t.Correct,
+			File:     t.File,
+			Line:     t.Line,
+			Commit:   t.Commit.String(),
+			Language: t.Language,
 		})
 	}
 
@@ -199,3 +216,30 @@ func computeAggregate(input *ReportData) AggregateData {
 
 	return agg
 }
+
+// computeLanguages counts typo fixes by the language of their commit
+// messages, most typos first. Typos recorded without a language are skipped.
+func computeLanguages(input *ReportData) []LanguageTypoData {
+	counts := make(map[string]int)
+
+	for _, t := range input.Typos {
+		if t.Language != "" {
+			counts[t.Language]++
+		}
+	}
+
+	result := make([]LanguageTypoData, 0, len(counts))
+	for lang, count := range counts {
+		result = append(result, LanguageTypoData{Language: lang, Typos: count})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Typos != result[j].Typos {
+			return result[i].Typos > result[j].Typos
+		}
+
+		return result[i].Language < result[j].Language
+	})
+
+	return result
+}
@@ -273,6 +273,24 @@ func TestTyposAggregateMetric_WithData(t *testing.T) {
 	assert.Equal(t, 2, result.AffectedCommits) // 2 unique commits.
 }
 
+func TestComputeLanguages(t *testing.T) {
+	t.Parallel()
+
+	input := &ReportData{
+		Typos: []Typo{
+			{Wrong: testWrong1, Correct: testCorrect1, Language: "de"},
+			{Wrong: testWrong2, Correct: testCorrect2, Language: "en"},
+			{Wrong: testWrong1, Correct: testCorrect1, Language: "en"},
+			{Wrong: testWrong2, Correct: testCorrect2},
+		},
+	}
+
+	assert.Equal(t, []LanguageTypoData{
+		{Language: "en", Typos: 2},
+		{Language: "de", Typos: 1},
+	}, computeLanguages(input))
+}
+
 // --- ComputeAllMetrics Tests ---.
 
 func TestComputeAllMetrics_Empty(t *testing.T) {
@@ -324,6 +342,24 @@ func TestComputeAllMetrics_Full(t *testing.T) {
 	assert.Equal(t, 3, result.Aggregate.AffectedCommits)
 }
 
+func TestComputeAllMetrics_ScalesSampledCommits(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		"typos": []Typo{
+			{Wrong: testWrong1, Correct: testCorrect1, File: testFile1, Line: testLine1, Commit: testHash("abc")},
+			{Wrong: testWrong2, Correct: testCorrect2, File: testFile1, Line: testLine2, Commit: testHash("abc")},
+		},
+		analyze.ReportKeySampleFactor: 3.0,
+	}
+
+	result, err := ComputeAllMetrics(report)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Aggregate.TotalTypos)
+	assert.Equal(t, 3, result.Aggregate.AffectedCommits)
+}
+
 // --- MetricsOutput Interface Tests ---.
 
 func TestComputedMetrics_AnalyzerName(t *testing.T) {
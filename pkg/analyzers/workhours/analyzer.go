@@ -0,0 +1,342 @@
+// Package workhours provides commit-time (working hours) analytics per developer.
+package workhours
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+const (
+	daysPerWeek  = 7
+	hoursPerDay  = 24
+	defaultStart = 9
+	defaultEnd   = 18
+)
+
+// Configuration option keys for the workhours analyzer.
+const (
+	ConfigWorkHoursDayStart = "WorkHours.DayStart"
+	ConfigWorkHoursDayEnd   = "WorkHours.DayEnd"
+)
+
+// ErrInvalidWorkDay is returned when the configured working day is empty or out of range.
+var ErrInvalidWorkDay = errors.New("invalid working day")
+
+// CommitTime is the per-commit payload: who committed and at which local wall-clock slot.
+type CommitTime struct {
+	AuthorID int
+	Weekday  time.Weekday
+	Hour     int
+}
+
+// AuthorHours is a commit-time histogram for one author.
+// Grid is indexed by [time.Weekday][hour] in the author's own timezone.
+type AuthorHours struct {
+	Grid    [daysPerWeek][hoursPerDay]int
+	Commits int
+}
+
+// TickData is the per-tick aggregated payload stored in analyze.TICK.Data.
+type TickData struct {
+	// Authors maps author ID to the author's histogram within the tick.
+	Authors map[int]*AuthorHours
+}
+
+// Analyzer builds per-author commit-time histograms from commit author timestamps.
+type Analyzer struct {
+	*analyze.BaseHistoryAnalyzer[*ComputedMetrics]
+
+	Identity           *plumbing.IdentityDetector
+	Ticks              *plumbing.TicksSinceStart
+	reversedPeopleDict []string
+	tickSize           time.Duration
+	tickCalendar       *pkgplumbing.TickCalendar // nil for fixed-size ticks.
+	DayStart           int
+	DayEnd             int
+}
+
+// NewAnalyzer creates a new workhours analyzer.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{DayStart: defaultStart, DayEnd: defaultEnd}
+	a.BaseHistoryAnalyzer = &analyze.BaseHistoryAnalyzer[*ComputedMetrics]{
+		Desc: analyze.Descriptor{
+			ID:          "history/workhours",
+			Mode:        analyze.ModeHistory,
+			Description: "Builds per-developer commit-time histograms with after-hours and weekend ratios over time.",
+		},
+		Sequential: false,
+		Caps:       &analyze.Capabilities{Memory: analyze.MemoryLow},
+		ConfigOptions: []pipeline.ConfigurationOption{
+			{
+				Name:        ConfigWorkHoursDayStart,
+				Description: "First hour (0-23, author local time) of the working day.",
+				Flag:        "work-day-start",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultStart,
+			},
+			{
+				Name:        ConfigWorkHoursDayEnd,
+				Description: "Hour (1-24, author local time) at which the working day ends, exclusive.",
+				Flag:        "work-day-end",
+				Type:        pipeline.IntConfigurationOption,
+				Default:     defaultEnd,
+			},
+		},
+		ComputeMetricsFn: computeMetricsSafe,
+		AggregatorFn:     newAggregator,
+	}
+
+	a.TicksToReportFn = a.reportFromTicks
+
+	return a
+}
+
+func computeMetricsSafe(report analyze.Report) (*ComputedMetrics, error) {
+	if len(report) == 0 {
+		return &ComputedMetrics{}, nil
+	}
+
+	return ComputeAllMetrics(report)
+}
+
+// Configure configures the analyzer with the given facts.
+func (a *Analyzer) Configure(facts map[string]any) error {
+	if val, exists := facts[ConfigWorkHoursDayStart].(int); exists {
+		a.DayStart = val
+	}
+
+	if val, exists := facts[ConfigWorkHoursDayEnd].(int); exists {
+		a.DayEnd = val
+	}
+
+	if a.DayStart < 0 || a.DayEnd > hoursPerDay || a.DayStart >= a.DayEnd {
+		return fmt.Errorf("%w: %d-%d (want 0 <= start < end <= 24)", ErrInvalidWorkDay, a.DayStart, a.DayEnd)
+	}
+
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		a.reversedPeopleDict = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickSize].(time.Duration); exists {
+		a.tickSize = val
+	}
+
+	if val, exists := facts[pkgplumbing.FactTickCalendar].(*pkgplumbing.TickCalendar); exists {
+		a.tickCalendar = val
+	}
+
+	return nil
+}
+
+// Initialize prepares the analyzer for processing commits.
+func (a *Analyzer) Initialize(_ *gitlib.Repository) error {
+	if a.DayStart == 0 && a.DayEnd == 0 {
+		a.DayStart, a.DayEnd = defaultStart, defaultEnd
+	}
+
+	return nil
+}
+
+// Consume records the author's local weekday and hour for a single commit.
+// Merge commits are skipped: their timestamps reflect integration, not authoring.
+func (a *Analyzer) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac.IsMerge {
+		return analyze.TC{}, nil
+	}
+
+	commit := ac.Commit
+	when := commit.Author().When
+
+	return analyze.TC{
+		Data: &CommitTime{
+			AuthorID: a.Identity.AuthorID,
+			Weekday:  when.Weekday(),
+			Hour:     when.Hour(),
+		},
+		CommitHash: commit.Hash(),
+	}, nil
+}
+
+// Fork creates independent copies of the analyzer for parallel processing.
+func (a *Analyzer) Fork(n int) []analyze.HistoryAnalyzer {
+	res := make([]analyze.HistoryAnalyzer, n)
+
+	for i := range n {
+		clone := *a
+
+		clone.Identity = &plumbing.IdentityDetector{}
+		clone.Ticks = &plumbing.TicksSinceStart{}
+
+		res[i] = &clone
+	}
+
+	return res
+}
+
+// Merge is a no-op. Per-commit results are emitted as TCs.
+func (a *Analyzer) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// SnapshotPlumbing captures the current plumbing state.
+func (a *Analyzer) SnapshotPlumbing() analyze.PlumbingSnapshot {
+	return plumbing.Snapshot{
+		Tick:     a.Ticks.Tick,
+		AuthorID: a.Identity.AuthorID,
+	}
+}
+
+// ApplySnapshot restores plumbing state from a snapshot.
+func (a *Analyzer) ApplySnapshot(snap analyze.PlumbingSnapshot) {
+	snapshot, ok := snap.(plumbing.Snapshot)
+	if !ok {
+		return
+	}
+
+	a.Ticks.Tick = snapshot.Tick
+	a.Identity.AuthorID = snapshot.AuthorID
+}
+
+// ReleaseSnapshot is a no-op for workhours.
+func (a *Analyzer) ReleaseSnapshot(_ analyze.PlumbingSnapshot) {}
+
+// NewAggregator creates an aggregator for this analyzer.
+func (a *Analyzer) NewAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return a.AggregatorFn(opts)
+}
+
+// ReportFromTICKs converts aggregated TICKs into a Report.
+func (a *Analyzer) ReportFromTICKs(ctx context.Context, ticks []analyze.TICK) (analyze.Report, error) {
+	return a.TicksToReportFn(ctx, ticks), nil
+}
+
+// Extract properties for GenericAggregator.
+
+// authorEntryOverhead approximates one AuthorHours entry: the 7x24 grid plus map overhead.
+const authorEntryOverhead = daysPerWeek*hoursPerDay*8 + 64
+
+func extractTC(tc analyze.TC, byTick map[int]*TickData) error {
+	ct, ok := tc.Data.(*CommitTime)
+	if !ok || ct == nil {
+		return nil
+	}
+
+	state, ok := byTick[tc.Tick]
+	if !ok || state == nil {
+		state = &TickData{Authors: make(map[int]*AuthorHours)}
+		byTick[tc.Tick] = state
+	}
+
+	hours, ok := state.Authors[ct.AuthorID]
+	if !ok {
+		hours = &AuthorHours{}
+		state.Authors[ct.AuthorID] = hours
+	}
+
+	hours.Grid[ct.Weekday][ct.Hour]++
+	hours.Commits++
+
+	return nil
+}
+
+func mergeState(existing, incoming *TickData) *TickData {
+	if existing == nil {
+		return incoming
+	}
+
+	if incoming == nil {
+		return existing
+	}
+
+	if existing.Authors == nil {
+		existing.Authors = make(map[int]*AuthorHours)
+	}
+
+	for id, in := range incoming.Authors {
+		ext, ok := existing.Authors[id]
+		if !ok {
+			existing.Authors[id] = in
+
+			continue
+		}
+
+		ext.add(in)
+	}
+
+	return existing
+}
+
+func sizeState(state *TickData) int64 {
+	if state == nil {
+		return 0
+	}
+
+	return int64(len(state.Authors)) * authorEntryOverhead
+}
+
+func buildTick(tick int, state *TickData) (analyze.TICK, error) {
+	if state == nil || len(state.Authors) == 0 {
+		return analyze.TICK{Tick: tick}, nil
+	}
+
+	return analyze.TICK{
+		Tick: tick,
+		Data: state,
+	}, nil
+}
+
+func newAggregator(opts analyze.AggregatorOptions) analyze.Aggregator {
+	return analyze.NewGenericAggregator[*TickData, *TickData](
+		opts,
+		extractTC,
+		mergeState,
+		sizeState,
+		buildTick,
+	)
+}
+
+// add accumulates other into h.
+func (h *AuthorHours) add(other *AuthorHours) {
+	for day := range daysPerWeek {
+		for hour := range hoursPerDay {
+			h.Grid[day][hour] += other.Grid[day][hour]
+		}
+	}
+
+	h.Commits += other.Commits
+}
+
+// reportFromTicks converts aggregated TICKs into the analyze.Report format.
+func (a *Analyzer) reportFromTicks(_ context.Context, ticks []analyze.TICK) analyze.Report {
+	byTick := make(map[int]map[int]*AuthorHours, len(ticks))
+
+	for _, tick := range ticks {
+		td, ok := tick.Data.(*TickData)
+		if !ok || td == nil {
+			continue
+		}
+
+		byTick[tick.Tick] = td.Authors
+	}
+
+	report := analyze.Report{
+		reportKeyTicks:              byTick,
+		reportKeyReversedPeopleDict: a.reversedPeopleDict,
+		reportKeyTickSize:           a.tickSize,
+		reportKeyDayStart:           a.DayStart,
+		reportKeyDayEnd:             a.DayEnd,
+	}
+
+	if a.tickCalendar != nil {
+		report[reportKeyTickCalendar] = a.tickCalendar
+	}
+
+	return report
+}
@@ -0,0 +1,169 @@
+package workhours
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func newTestAnalyzer() *Analyzer {
+	a := NewAnalyzer()
+	a.Identity = &plumbing.IdentityDetector{}
+	a.Ticks = &plumbing.TicksSinceStart{}
+
+	return a
+}
+
+func testCommit(when time.Time) *gitlib.TestCommit {
+	return gitlib.NewTestCommit(
+		gitlib.NewHash("c100000000000000000000000000000000000001"),
+		gitlib.Signature{Name: "dev", Email: "dev@test.com", When: when},
+		"test commit",
+	)
+}
+
+func TestAnalyzer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	assert.Equal(t, "history/workhours", a.Name())
+	assert.NotEmpty(t, a.Flag())
+	assert.NotEmpty(t, a.Description())
+	assert.Len(t, a.ListConfigurationOptions(), 2)
+	assert.False(t, a.SequentialOnly())
+}
+
+func TestAnalyzer_Configure(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(map[string]any{
+		ConfigWorkHoursDayStart: 8,
+		ConfigWorkHoursDayEnd:   17,
+	}))
+	assert.Equal(t, 8, a.DayStart)
+	assert.Equal(t, 17, a.DayEnd)
+}
+
+func TestAnalyzer_Configure_Defaults(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	require.NoError(t, a.Configure(nil))
+	assert.Equal(t, defaultStart, a.DayStart)
+	assert.Equal(t, defaultEnd, a.DayEnd)
+}
+
+func TestAnalyzer_Configure_InvalidDay(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+
+	err := a.Configure(map[string]any{
+		ConfigWorkHoursDayStart: 18,
+		ConfigWorkHoursDayEnd:   9,
+	})
+	require.ErrorIs(t, err, ErrInvalidWorkDay)
+}
+
+func TestAnalyzer_Consume_UsesAuthorLocalTime(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+	a.Identity.AuthorID = 3
+
+	// 23:30 on Friday in UTC+9 is 14:30 UTC; the author's own clock must win.
+	tokyo := time.FixedZone("JST", 9*60*60)
+	when := time.Date(2024, 3, 1, 23, 30, 0, 0, tokyo)
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{Commit: testCommit(when)})
+	require.NoError(t, err)
+
+	ct, ok := tc.Data.(*CommitTime)
+	require.True(t, ok, "TC.Data should be *CommitTime")
+	assert.Equal(t, 3, ct.AuthorID)
+	assert.Equal(t, time.Friday, ct.Weekday)
+	assert.Equal(t, 23, ct.Hour)
+}
+
+func TestAnalyzer_Consume_SkipsMerges(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+
+	tc, err := a.Consume(context.Background(), &analyze.Context{
+		Commit:  testCommit(time.Now()),
+		IsMerge: true,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, tc.Data)
+}
+
+func TestAnalyzer_Fork(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAnalyzer()
+	a.DayStart = 10
+
+	forks := a.Fork(2)
+	require.Len(t, forks, 2)
+
+	for _, f := range forks {
+		clone, ok := f.(*Analyzer)
+		require.True(t, ok)
+		assert.NotSame(t, a.Identity, clone.Identity)
+		assert.Equal(t, 10, clone.DayStart)
+	}
+}
+
+func TestAggregator_MergesAuthorsPerTick(t *testing.T) {
+	t.Parallel()
+
+	byTick := map[int]*TickData{}
+
+	require.NoError(t, extractTC(analyze.TC{Tick: 1, Data: &CommitTime{AuthorID: 0, Weekday: time.Monday, Hour: 10}}, byTick))
+	require.NoError(t, extractTC(analyze.TC{Tick: 1, Data: &CommitTime{AuthorID: 0, Weekday: time.Monday, Hour: 10}}, byTick))
+	require.NoError(t, extractTC(analyze.TC{Tick: 1, Data: &CommitTime{AuthorID: 1, Weekday: time.Sunday, Hour: 2}}, byTick))
+
+	other := &TickData{Authors: map[int]*AuthorHours{0: {Commits: 1}}}
+	other.Authors[0].Grid[time.Monday][10] = 1
+
+	merged := mergeState(byTick[1], other)
+	assert.Equal(t, 3, merged.Authors[0].Commits)
+	assert.Equal(t, 3, merged.Authors[0].Grid[time.Monday][10])
+	assert.Equal(t, 1, merged.Authors[1].Grid[time.Sunday][2])
+}
+
+func TestAnalyzer_SerializeTICKs_JSON(t *testing.T) {
+	t.Parallel()
+
+	a := NewAnalyzer()
+	a.reversedPeopleDict = []string{"alice"}
+
+	td := &TickData{Authors: map[int]*AuthorHours{0: {Commits: 2}}}
+	td.Authors[0].Grid[time.Tuesday][11] = 1
+	td.Authors[0].Grid[time.Tuesday][22] = 1
+
+	var buf bytes.Buffer
+
+	require.NoError(t, a.SerializeTICKs([]analyze.TICK{{Tick: 0, Data: td}}, analyze.FormatJSON, &buf))
+
+	var out ComputedMetrics
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	require.Len(t, out.Authors, 1)
+	assert.Equal(t, "alice", out.Authors[0].Name)
+	assert.Equal(t, 1, out.Authors[0].AfterHoursCommits)
+	assert.InDelta(t, 0.5, out.Aggregate.AfterHoursRatio, 1e-9)
+}
@@ -0,0 +1,447 @@
+package workhours
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Report keys produced by the analyzer.
+const (
+	reportKeyTicks              = "Ticks"
+	reportKeyReversedPeopleDict = "ReversedPeopleDict"
+	reportKeyTickSize           = "TickSize"
+	reportKeyDayStart           = "DayStart"
+	reportKeyDayEnd             = "DayEnd"
+	reportKeyTickCalendar       = "TickCalendar"
+)
+
+// Trend directions reported in TrendSummaryData.
+const (
+	TrendRising  = "rising"
+	TrendFalling = "falling"
+	TrendStable  = "stable"
+
+	// stableSlopeThreshold is the per-tick change in off-hours ratio below which the trend is stable.
+	stableSlopeThreshold = 0.005
+)
+
+// --- Input Data Types ---.
+
+// ReportData is the parsed input data for workhours metrics computation.
+type ReportData struct {
+	Ticks    map[int]map[int]*AuthorHours
+	Names    []string
+	TickSize time.Duration
+	DayStart int
+	DayEnd   int
+	Calendar *pkgplumbing.TickCalendar // nil for fixed-size ticks.
+}
+
+// ParseReportData extracts ReportData from an analyzer report.
+func ParseReportData(report analyze.Report) (*ReportData, error) {
+	data := &ReportData{DayStart: defaultStart, DayEnd: defaultEnd}
+
+	if v, ok := report[reportKeyTicks].(map[int]map[int]*AuthorHours); ok {
+		data.Ticks = v
+	}
+
+	if v, ok := report[reportKeyReversedPeopleDict].([]string); ok {
+		data.Names = v
+	}
+
+	if v, ok := report[reportKeyTickSize].(time.Duration); ok {
+		data.TickSize = v
+	}
+
+	if v, ok := report[reportKeyDayStart].(int); ok {
+		data.DayStart = v
+	}
+
+	if v, ok := report[reportKeyDayEnd].(int); ok {
+		data.DayEnd = v
+	}
+
+	if c, ok := report[reportKeyTickCalendar].(*pkgplumbing.TickCalendar); ok && c.Granularity.Calendar() {
+		data.Calendar = c
+	}
+
+	return data, nil
+}
+
+// --- Output Data Types ---.
+
+// AuthorData contains the commit-time profile of one developer.
+// WeekdayHistogram is indexed Sunday (0) through Saturday (6).
+type AuthorData struct {
+	ID                int              `json:"id"                  yaml:"id"`
+	Name              string           `json:"name"                yaml:"name"`
+	Commits           int              `json:"commits"             yaml:"commits"`
+	HourHistogram     [hoursPerDay]int `json:"hour_histogram"      yaml:"hour_histogram"`
+	WeekdayHistogram  [daysPerWeek]int `json:"weekday_histogram"   yaml:"weekday_histogram"`
+	AfterHoursCommits int              `json:"after_hours_commits" yaml:"after_hours_commits"`
+	AfterHoursRatio   float64          `json:"after_hours_ratio"   yaml:"after_hours_ratio"`
+	WeekendCommits    int              `json:"weekend_commits"     yaml:"weekend_commits"`
+	WeekendRatio      float64          `json:"weekend_ratio"       yaml:"weekend_ratio"`
+	PeakHour          int              `json:"peak_hour"           yaml:"peak_hour"`
+}
+
+// TrendData contains the off-hours ratios of one tick.
+type TrendData struct {
+	Tick            int     `json:"tick"              yaml:"tick"`
+	Period          string  `json:"period,omitempty"  yaml:"period,omitempty"`
+	Commits         int     `json:"commits"           yaml:"commits"`
+	AfterHoursRatio float64 `json:"after_hours_ratio" yaml:"after_hours_ratio"`
+	WeekendRatio    float64 `json:"weekend_ratio"     yaml:"weekend_ratio"`
+}
+
+// TrendSummaryData contains least-squares slopes of the per-tick ratios.
+type TrendSummaryData struct {
+	AfterHoursSlope float64 `json:"after_hours_slope" yaml:"after_hours_slope"`
+	WeekendSlope    float64 `json:"weekend_slope"     yaml:"weekend_slope"`
+	Direction       string  `json:"direction"         yaml:"direction"`
+}
+
+// AggregateData contains team-wide summary statistics.
+type AggregateData struct {
+	TotalCommits      int              `json:"total_commits"       yaml:"total_commits"`
+	TotalAuthors      int              `json:"total_authors"       yaml:"total_authors"`
+	DayStart          int              `json:"day_start"           yaml:"day_start"`
+	DayEnd            int              `json:"day_end"             yaml:"day_end"`
+	HourHistogram     [hoursPerDay]int `json:"hour_histogram"      yaml:"hour_histogram"`
+	WeekdayHistogram  [daysPerWeek]int `json:"weekday_histogram"   yaml:"weekday_histogram"`
+	AfterHoursCommits int              `json:"after_hours_commits" yaml:"after_hours_commits"`
+	AfterHoursRatio   float64          `json:"after_hours_ratio"   yaml:"after_hours_ratio"`
+	WeekendCommits    int              `json:"weekend_commits"     yaml:"weekend_commits"`
+	WeekendRatio      float64          `json:"weekend_ratio"       yaml:"weekend_ratio"`
+	PeakHour          int              `json:"peak_hour"           yaml:"peak_hour"`
+}
+
+// --- Computed Metrics ---.
+
+// ComputedMetrics holds all computed metric results for the workhours analyzer.
+type ComputedMetrics struct {
+	Authors      []AuthorData     `json:"authors"       yaml:"authors"`
+	Trend        []TrendData      `json:"trend"         yaml:"trend"`
+	TrendSummary TrendSummaryData `json:"trend_summary" yaml:"trend_summary"`
+	Aggregate    AggregateData    `json:"aggregate"     yaml:"aggregate"`
+}
+
+// Analyzer name constant for MetricsOutput interface.
+const analyzerNameWorkHours = "workhours"
+
+// AnalyzerName returns the name of the analyzer.
+func (m *ComputedMetrics) AnalyzerName() string {
+	return analyzerNameWorkHours
+}
+
+// ToJSON returns the metrics as a JSON-serializable object.
+func (m *ComputedMetrics) ToJSON() any {
+	return m
+}
+
+// ToYAML returns the metrics as a YAML-serializable object.
+func (m *ComputedMetrics) ToYAML() any {
+	return m
+}
+
+// ComputeAllMetrics runs all workhours metrics and returns the results.
+func ComputeAllMetrics(report analyze.Report) (*ComputedMetrics, error) {
+	input, err := ParseReportData(report)
+	if err != nil {
+		return nil, err
+	}
+
+	trend := computeTrend(input)
+
+	m := &ComputedMetrics{
+		Authors:      computeAuthors(input),
+		Trend:        trend,
+		TrendSummary: computeTrendSummary(trend),
+		Aggregate:    computeAggregate(input),
+	}
+	m.scaleCounts(analyze.SampleFactor(report))
+
+	return m, nil
+}
+
+// scaleCounts scales the commit counts and histograms of a sampled history
+// by factor to estimate the full history. Ratios and peak hours are kept.
+func (m *ComputedMetrics) scaleCounts(factor float64) {
+	if factor <= 1 {
+		return
+	}
+
+	for i := range m.Authors {
+		a := &m.Authors[i]
+		scaleProfile(factor, &a.Commits, &a.AfterHoursCommits, &a.WeekendCommits, &a.HourHistogram, &a.WeekdayHistogram)
+	}
+
+	for i := range m.Trend {
+		m.Trend[i].Commits = analyze.ScaleCount(m.Trend[i].Commits, factor)
+	}
+
+	agg := &m.Aggregate
+	scaleProfile(factor, &agg.TotalCommits, &agg.AfterHoursCommits, &agg.WeekendCommits, &agg.HourHistogram, &agg.WeekdayHistogram)
+}
+
+// scaleProfile scales the counts of one commit-time profile by factor.
+func scaleProfile(
+	factor float64, commits, afterHours, weekend *int, hours *[hoursPerDay]int, days *[daysPerWeek]int,
+) {
+	for _, n := range []*int{commits, afterHours, weekend} {
+		*n = analyze.ScaleCount(*n, factor)
+	}
+
+	for i := range hours {
+		hours[i] = analyze.ScaleCount(hours[i], factor)
+	}
+
+	for i := range days {
+		days[i] = analyze.ScaleCount(days[i], factor)
+	}
+}
+
+// --- Metric Implementations ---.
+
+// slotCounts classifies a weekday/hour grid. Weekend commits are counted on
+// Saturday and Sunday regardless of hour; after-hours commits are weekday
+// commits outside [dayStart, dayEnd). The two sets are disjoint.
+func slotCounts(grid *[daysPerWeek][hoursPerDay]int, dayStart, dayEnd int) (afterHours, weekend int) {
+	for day := range daysPerWeek {
+		for hour, n := range grid[day] {
+			switch {
+			case isWeekend(time.Weekday(day)):
+				weekend += n
+			case hour < dayStart || hour >= dayEnd:
+				afterHours += n
+			}
+		}
+	}
+
+	return afterHours, weekend
+}
+
+func isWeekend(day time.Weekday) bool {
+	return day == time.Saturday || day == time.Sunday
+}
+
+func histograms(grid *[daysPerWeek][hoursPerDay]int) (hours [hoursPerDay]int, days [daysPerWeek]int) {
+	for day := range daysPerWeek {
+		for hour, n := range grid[day] {
+			hours[hour] += n
+			days[day] += n
+		}
+	}
+
+	return hours, days
+}
+
+// peakHour returns the busiest hour, preferring the earliest on ties.
+func peakHour(hours [hoursPerDay]int) int {
+	peak := 0
+
+	for hour, n := range hours {
+		if n > hours[peak] {
+			peak = hour
+		}
+	}
+
+	return peak
+}
+
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) / float64(total)
+}
+
+func computeAuthors(input *ReportData) []AuthorData {
+	byAuthor := make(map[int]*AuthorHours)
+
+	for _, authors := range input.Ticks {
+		for id, hours := range authors {
+			acc, ok := byAuthor[id]
+			if !ok {
+				acc = &AuthorHours{}
+				byAuthor[id] = acc
+			}
+
+			acc.add(hours)
+		}
+	}
+
+	result := make([]AuthorData, 0, len(byAuthor))
+
+	for id, acc := range byAuthor {
+		hourHist, dayHist := histograms(&acc.Grid)
+		afterHours, weekend := slotCounts(&acc.Grid, input.DayStart, input.DayEnd)
+
+		result = append(result, AuthorData{
+			ID:                id,
+			Name:              authorName(id, input.Names),
+			Commits:           acc.Commits,
+			HourHistogram:     hourHist,
+			WeekdayHistogram:  dayHist,
+			AfterHoursCommits: afterHours,
+			AfterHoursRatio:   ratio(afterHours, acc.Commits),
+			WeekendCommits:    weekend,
+			WeekendRatio:      ratio(weekend, acc.Commits),
+			PeakHour:          peakHour(hourHist),
+		})
+	}
+
+	// Sort by commits descending, then by ID for stable output.
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Commits != result[j].Commits {
+			return result[i].Commits > result[j].Commits
+		}
+
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+func computeTrend(input *ReportData) []TrendData {
+	ticks := make([]int, 0, len(input.Ticks))
+	for tick := range input.Ticks {
+		ticks = append(ticks, tick)
+	}
+
+	sort.Ints(ticks)
+
+	result := make([]TrendData, 0, len(ticks))
+
+	for _, tick := range ticks {
+		var grid AuthorHours
+
+		for _, hours := range input.Ticks[tick] {
+			grid.add(hours)
+		}
+
+		commits := grid.Commits
+		if commits == 0 {
+			continue
+		}
+
+		afterHours, weekend := slotCounts(&grid.Grid, input.DayStart, input.DayEnd)
+
+		td := TrendData{
+			Tick:            tick,
+			Commits:         commits,
+			AfterHoursRatio: ratio(afterHours, commits),
+			WeekendRatio:    ratio(weekend, commits),
+		}
+
+		if input.Calendar != nil {
+			td.Period = input.Calendar.Label(tick)
+		}
+
+		result = append(result, td)
+	}
+
+	return result
+}
+
+func computeTrendSummary(trend []TrendData) TrendSummaryData {
+	xs := make([]float64, len(trend))
+	afterHours := make([]float64, len(trend))
+	weekend := make([]float64, len(trend))
+	offHours := make([]float64, len(trend))
+
+	for i, td := range trend {
+		xs[i] = float64(td.Tick)
+		afterHours[i] = td.AfterHoursRatio
+		weekend[i] = td.WeekendRatio
+		offHours[i] = td.AfterHoursRatio + td.WeekendRatio
+	}
+
+	summary := TrendSummaryData{
+		AfterHoursSlope: linearSlope(xs, afterHours),
+		WeekendSlope:    linearSlope(xs, weekend),
+		Direction:       TrendStable,
+	}
+
+	switch slope := linearSlope(xs, offHours); {
+	case slope > stableSlopeThreshold:
+		summary.Direction = TrendRising
+	case slope < -stableSlopeThreshold:
+		summary.Direction = TrendFalling
+	}
+
+	return summary
+}
+
+// linearSlope returns the least-squares slope of ys over xs, or 0 when undefined.
+func linearSlope(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	if n < 2 {
+		return 0
+	}
+
+	var sumX, sumY, sumXY, sumX2 float64
+
+	for i, x := range xs {
+		sumX += x
+		sumY += ys[i]
+		sumXY += x * ys[i]
+		sumX2 += x * x
+	}
+
+	denom := n*sumX2 - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+
+	return (n*sumXY - sumX*sumY) / denom
+}
+
+func computeAggregate(input *ReportData) AggregateData {
+	var team AuthorHours
+
+	authors := make(map[int]bool)
+
+	for _, byAuthor := range input.Ticks {
+		for id, hours := range byAuthor {
+			authors[id] = true
+
+			team.add(hours)
+		}
+	}
+
+	hourHist, dayHist := histograms(&team.Grid)
+	afterHours, weekend := slotCounts(&team.Grid, input.DayStart, input.DayEnd)
+
+	return AggregateData{
+		TotalCommits:      team.Commits,
+		TotalAuthors:      len(authors),
+		DayStart:          input.DayStart,
+		DayEnd:            input.DayEnd,
+		HourHistogram:     hourHist,
+		WeekdayHistogram:  dayHist,
+		AfterHoursCommits: afterHours,
+		AfterHoursRatio:   ratio(afterHours, team.Commits),
+		WeekendCommits:    weekend,
+		WeekendRatio:      ratio(weekend, team.Commits),
+		PeakHour:          peakHour(hourHist),
+	}
+}
+
+func authorName(id int, names []string) string {
+	if id == identity.AuthorMissing {
+		return identity.AuthorMissingName
+	}
+
+	if id >= 0 && id < len(names) {
+		return names[id]
+	}
+
+	return fmt.Sprintf("dev_%d", id)
+}
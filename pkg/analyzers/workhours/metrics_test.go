@@ -0,0 +1,155 @@
+package workhours
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/identity"
+	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func hoursOf(slots ...[2]int) *AuthorHours {
+	h := &AuthorHours{}
+
+	for _, s := range slots {
+		h.Grid[s[0]][s[1]]++
+		h.Commits++
+	}
+
+	return h
+}
+
+func slot(day time.Weekday, hour int) [2]int { return [2]int{int(day), hour} }
+
+func TestComputeAllMetrics_Empty(t *testing.T) {
+	t.Parallel()
+
+	m, err := ComputeAllMetrics(analyze.Report{})
+	require.NoError(t, err)
+	assert.Empty(t, m.Authors)
+	assert.Empty(t, m.Trend)
+	assert.Equal(t, TrendStable, m.TrendSummary.Direction)
+	assert.Equal(t, defaultStart, m.Aggregate.DayStart)
+}
+
+func TestComputeAllMetrics_AuthorRatios(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		reportKeyTicks: map[int]map[int]*AuthorHours{
+			0: {
+				0:                      hoursOf(slot(time.Monday, 10), slot(time.Monday, 10), slot(time.Tuesday, 21)),
+				identity.AuthorMissing: hoursOf(slot(time.Saturday, 10)),
+			},
+			1: {
+				0: hoursOf(slot(time.Sunday, 23)),
+			},
+		},
+		reportKeyReversedPeopleDict: []string{"alice"},
+		reportKeyDayStart:           9,
+		reportKeyDayEnd:             18,
+	}
+
+	m, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+	require.Len(t, m.Authors, 2)
+
+	alice := m.Authors[0]
+	assert.Equal(t, "alice", alice.Name)
+	assert.Equal(t, 4, alice.Commits)
+	assert.Equal(t, 1, alice.AfterHoursCommits, "weekday 21:00 is after hours")
+	assert.Equal(t, 1, alice.WeekendCommits, "Sunday commits count as weekend, not after hours")
+	assert.InDelta(t, 0.25, alice.AfterHoursRatio, 1e-9)
+	assert.InDelta(t, 0.25, alice.WeekendRatio, 1e-9)
+	assert.Equal(t, 10, alice.PeakHour)
+	assert.Equal(t, 2, alice.WeekdayHistogram[time.Monday])
+
+	assert.Equal(t, identity.AuthorMissingName, m.Authors[1].Name)
+
+	assert.Equal(t, 5, m.Aggregate.TotalCommits)
+	assert.Equal(t, 2, m.Aggregate.TotalAuthors)
+	assert.Equal(t, 2, m.Aggregate.WeekendCommits)
+}
+
+func TestComputeAllMetrics_WorkDayBoundaries(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		reportKeyTicks: map[int]map[int]*AuthorHours{
+			0: {0: hoursOf(slot(time.Wednesday, 8), slot(time.Wednesday, 9), slot(time.Wednesday, 17), slot(time.Wednesday, 18))},
+		},
+		reportKeyDayStart: 9,
+		reportKeyDayEnd:   18,
+	}
+
+	m, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+	assert.Equal(t, 2, m.Aggregate.AfterHoursCommits, "start is inclusive, end is exclusive")
+}
+
+func TestComputeAllMetrics_Trend(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		reportKeyTicks: map[int]map[int]*AuthorHours{
+			0: {0: hoursOf(slot(time.Monday, 10), slot(time.Monday, 11))},
+			1: {0: hoursOf(slot(time.Monday, 10), slot(time.Monday, 20))},
+			2: {0: hoursOf(slot(time.Monday, 22), slot(time.Saturday, 11))},
+		},
+		reportKeyTickCalendar: &pkgplumbing.TickCalendar{
+			Granularity: pkgplumbing.TickMonth,
+			Start:       time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	m, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+	require.Len(t, m.Trend, 3)
+	assert.Equal(t, "2024-03", m.Trend[2].Period)
+	assert.InDelta(t, 0.5, m.Trend[1].AfterHoursRatio, 1e-9)
+	assert.InDelta(t, 0.5, m.Trend[2].WeekendRatio, 1e-9)
+	assert.InDelta(t, 0.25, m.TrendSummary.AfterHoursSlope, 1e-9)
+	assert.Equal(t, TrendRising, m.TrendSummary.Direction)
+}
+
+func TestComputeAllMetrics_ScalesSampledCounts(t *testing.T) {
+	t.Parallel()
+
+	report := analyze.Report{
+		reportKeyTicks: map[int]map[int]*AuthorHours{
+			0: {0: hoursOf(slot(time.Monday, 10), slot(time.Monday, 21), slot(time.Saturday, 10))},
+		},
+		reportKeyDayStart:             9,
+		reportKeyDayEnd:               18,
+		analyze.ReportKeySampleFactor: 2.0,
+	}
+
+	m, err := ComputeAllMetrics(report)
+	require.NoError(t, err)
+	require.Len(t, m.Authors, 1)
+
+	alice := m.Authors[0]
+	assert.Equal(t, 6, alice.Commits)
+	assert.Equal(t, 2, alice.AfterHoursCommits)
+	assert.Equal(t, 2, alice.WeekendCommits)
+	assert.InDelta(t, 1.0/3, alice.AfterHoursRatio, 1e-9)
+	assert.Equal(t, 4, alice.HourHistogram[10])
+	assert.Equal(t, 4, alice.WeekdayHistogram[time.Monday])
+
+	require.Len(t, m.Trend, 1)
+	assert.Equal(t, 6, m.Trend[0].Commits)
+	assert.Equal(t, 6, m.Aggregate.TotalCommits)
+	assert.Equal(t, 2, m.Aggregate.HourHistogram[21])
+}
+
+func TestLinearSlope_Degenerate(t *testing.T) {
+	t.Parallel()
+
+	assert.Zero(t, linearSlope(nil, nil))
+	assert.Zero(t, linearSlope([]float64{1}, []float64{5}))
+	assert.Zero(t, linearSlope([]float64{2, 2}, []float64{1, 3}))
+}
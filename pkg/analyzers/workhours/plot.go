@@ -0,0 +1,139 @@
+package workhours
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/common/plotpage"
+)
+
+const percentScale = 100
+
+// RegisterPlotSections registers the workhours plot section renderer with the analyze package.
+func RegisterPlotSections() {
+	analyze.RegisterPlotSections("history/workhours", func(report analyze.Report) ([]plotpage.Section, error) {
+		return (&Analyzer{}).GenerateSections(report)
+	})
+}
+
+// GenerateSections returns the sections for combined reports.
+func (a *Analyzer) GenerateSections(report analyze.Report) ([]plotpage.Section, error) {
+	metrics, err := ComputeAllMetrics(report)
+	if err != nil {
+		return nil, err
+	}
+
+	agg := metrics.Aggregate
+
+	return []plotpage.Section{
+		{
+			Title: "Commits by Hour of Day",
+			Subtitle: fmt.Sprintf("Team commits by author-local hour; working day is %02d:00-%02d:00.",
+				agg.DayStart, agg.DayEnd),
+			Chart: plotpage.WrapChart(buildHourChart(metrics)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Hours use each author's own timezone, so distributed teams line up",
+					"A second peak late in the evening = regular after-hours work",
+					"Look for: Commit volume that keeps growing past the end of the working day",
+				},
+			},
+		},
+		{
+			Title:    "Commits by Weekday",
+			Subtitle: "Team commits by author-local day of the week.",
+			Chart:    plotpage.WrapChart(buildWeekdayChart(metrics)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Saturday and Sunday bars = weekend work",
+					"A heavy Friday or Monday can indicate release or on-call rhythms",
+				},
+			},
+		},
+		{
+			Title:    "Off-Hours Trend",
+			Subtitle: "Share of commits made after hours and on weekends, per time period.",
+			Chart:    plotpage.WrapChart(buildTrendChart(metrics)),
+			Hint: plotpage.Hint{
+				Title: "How to interpret:",
+				Items: []string{
+					"Rising lines = more work spilling out of normal hours",
+					"Spikes often line up with releases or incidents",
+					"Action: Sustained growth is an early burnout signal worth discussing with the team",
+				},
+			},
+		},
+	}, nil
+}
+
+// GenerateChart implements PlotGenerator interface.
+func (a *Analyzer) GenerateChart(report analyze.Report) (components.Charter, error) {
+	metrics, err := ComputeAllMetrics(report)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildHourChart(metrics), nil
+}
+
+func buildHourChart(metrics *ComputedMetrics) *charts.Bar {
+	labels := make([]string, hoursPerDay)
+	data := make([]plotpage.SeriesData, hoursPerDay)
+
+	for hour, n := range metrics.Aggregate.HourHistogram {
+		labels[hour] = fmt.Sprintf("%02d", hour)
+		data[hour] = n
+	}
+
+	palette := plotpage.GetChartPalette(plotpage.ThemeDark)
+
+	return plotpage.BuildBarChart(nil, labels, []plotpage.BarSeries{
+		{Name: "Commits", Data: data, Color: palette.Semantic.Warning},
+	}, "Commits")
+}
+
+func buildWeekdayChart(metrics *ComputedMetrics) *charts.Bar {
+	labels := make([]string, daysPerWeek)
+	data := make([]plotpage.SeriesData, daysPerWeek)
+
+	// Present Monday first; the histogram is indexed by time.Weekday (Sunday = 0).
+	for i := range daysPerWeek {
+		day := time.Weekday((i + 1) % daysPerWeek)
+		labels[i] = day.String()[:3]
+		data[i] = metrics.Aggregate.WeekdayHistogram[day]
+	}
+
+	palette := plotpage.GetChartPalette(plotpage.ThemeDark)
+
+	return plotpage.BuildBarChart(nil, labels, []plotpage.BarSeries{
+		{Name: "Commits", Data: data, Color: palette.Semantic.Warning},
+	}, "Commits")
+}
+
+func buildTrendChart(metrics *ComputedMetrics) *charts.Line {
+	labels := make([]string, len(metrics.Trend))
+	afterHours := make([]plotpage.SeriesData, len(metrics.Trend))
+	weekend := make([]plotpage.SeriesData, len(metrics.Trend))
+
+	for i, td := range metrics.Trend {
+		labels[i] = td.Period
+		if labels[i] == "" {
+			labels[i] = strconv.Itoa(td.Tick)
+		}
+
+		afterHours[i] = td.AfterHoursRatio * percentScale
+		weekend[i] = td.WeekendRatio * percentScale
+	}
+
+	return plotpage.BuildLineChart(nil, labels, []plotpage.LineSeries{
+		{Name: "After hours", Data: afterHours},
+		{Name: "Weekend", Data: weekend},
+	}, "% of commits")
+}
@@ -0,0 +1,224 @@
+// Package bench holds the results of `codefang bench` runs and compares them
+// against a saved baseline.
+package bench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/storage"
+)
+
+// ReportVersion is the version of the Report JSON layout.
+const ReportVersion = 1
+
+// Metric names recorded per result.
+const (
+	MetricDurationMS       = "duration_ms"
+	MetricCommitsPerSec    = "commits_per_sec"
+	MetricPeakHeapMB       = "peak_heap_mb"
+	MetricHibernateFreedMB = "hibernate_freed_mb"
+	MetricHibernateMS      = "hibernate_ms"
+)
+
+// higherIsBetter lists the metrics that regress when they go down. Every
+// other known metric regresses when it goes up.
+var higherIsBetter = map[string]bool{
+	MetricCommitsPerSec:    true,
+	MetricHibernateFreedMB: true,
+}
+
+// ErrRegression is returned when a run regresses against its baseline.
+var ErrRegression = errors.New("benchmark regression")
+
+// ErrUnsupportedReport is returned by ReadReport for reports of another version.
+var ErrUnsupportedReport = errors.New("unsupported bench report")
+
+// Result holds the metrics of one variant of a scenario, e.g. the "workers"
+// scenario run with variant "4".
+type Result struct {
+	Scenario string             `json:"scenario"`
+	Variant  string             `json:"variant"`
+	Metrics  map[string]float64 `json:"metrics"`
+}
+
+// Key identifies the result across reports.
+func (r Result) Key() string {
+	return r.Scenario + "/" + r.Variant
+}
+
+// Report is the machine-readable output of a bench run.
+type Report struct {
+	Version   int      `json:"version"`
+	Repo      string   `json:"repo"`
+	Analyzers []string `json:"analyzers"`
+	Commits   int      `json:"commits"`
+	GoVersion string   `json:"go_version"`
+	CPUs      int      `json:"cpus"`
+	Results   []Result `json:"results"`
+}
+
+// Regression is one metric that got worse than its baseline by more than the
+// threshold.
+type Regression struct {
+	Scenario  string  `json:"scenario"`
+	Variant   string  `json:"variant"`
+	Metric    string  `json:"metric"`
+	Baseline  float64 `json:"baseline"`
+	Current   float64 `json:"current"`
+	ChangePct float64 `json:"change_pct"`
+}
+
+// String describes the regression for humans.
+func (r Regression) String() string {
+	return fmt.Sprintf("%s/%s %s: %.2f -> %.2f (%+.1f%%)",
+		r.Scenario, r.Variant, r.Metric, r.Baseline, r.Current, r.ChangePct)
+}
+
+// Compare returns the metrics of current that are worse than the same
+// scenario, variant and metric in baseline by more than thresholdPct percent.
+// Results and metrics missing from either report, and baseline values of
+// zero, are not compared.
+func Compare(baseline, current Report, thresholdPct float64) []Regression {
+	base := make(map[string]Result, len(baseline.Results))
+	for _, r := range baseline.Results {
+		base[r.Key()] = r
+	}
+
+	var regressions []Regression
+
+	for _, cur := range current.Results {
+		prev, ok := base[cur.Key()]
+		if !ok {
+			continue
+		}
+
+		for _, metric := range sortedMetrics(cur.Metrics) {
+			was, found := prev.Metrics[metric]
+			if !found || was == 0 {
+				continue
+			}
+
+			now := cur.Metrics[metric]
+			change := (now - was) / was * 100
+
+			worse := change > thresholdPct
+			if higherIsBetter[metric] {
+				worse = -change > thresholdPct
+			}
+
+			if worse {
+				regressions = append(regressions, Regression{
+					Scenario:  cur.Scenario,
+					Variant:   cur.Variant,
+					Metric:    metric,
+					Baseline:  was,
+					Current:   now,
+					ChangePct: change,
+				})
+			}
+		}
+	}
+
+	return regressions
+}
+
+// ReadReport reads a report written by WriteJSON.
+func ReadReport(path string) (Report, error) {
+	data, err := storage.ReadFile(path)
+	if err != nil {
+		return Report{}, fmt.Errorf("read baseline: %w", err)
+	}
+
+	var report Report
+
+	err = json.Unmarshal(data, &report)
+	if err != nil {
+		return Report{}, fmt.Errorf("parse baseline %s: %w", path, err)
+	}
+
+	if report.Version != ReportVersion {
+		return Report{}, fmt.Errorf("%w: %s has version %d (want %d)", ErrUnsupportedReport, path, report.Version, ReportVersion)
+	}
+
+	return report, nil
+}
+
+// WriteJSON writes report as indented JSON.
+func WriteJSON(w io.Writer, report Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(report)
+	if err != nil {
+		return fmt.Errorf("encode bench report: %w", err)
+	}
+
+	return nil
+}
+
+// WriteText writes report as a table, one row per result, followed by the
+// regressions if any.
+func WriteText(w io.Writer, report Report, regressions []Regression) error {
+	var metrics []string
+
+	seen := make(map[string]bool)
+
+	for _, r := range report.Results {
+		for _, m := range sortedMetrics(r.Metrics) {
+			if !seen[m] {
+				seen[m] = true
+				metrics = append(metrics, m)
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(tw, "scenario\tvariant\t%s\t\n", strings.Join(metrics, "\t"))
+
+	for _, r := range report.Results {
+		cells := make([]string, len(metrics))
+
+		for i, m := range metrics {
+			cells[i] = "-"
+
+			if v, ok := r.Metrics[m]; ok {
+				cells[i] = fmt.Sprintf("%.1f", v)
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t\n", r.Scenario, r.Variant, strings.Join(cells, "\t"))
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return fmt.Errorf("write bench report: %w", err)
+	}
+
+	if len(regressions) > 0 {
+		fmt.Fprintf(w, "\n%d regression(s):\n", len(regressions))
+
+		for _, r := range regressions {
+			fmt.Fprintf(w, "  %s\n", r)
+		}
+	}
+
+	return nil
+}
+
+func sortedMetrics(metrics map[string]float64) []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,111 @@
+package bench
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	baseline := Report{Results: []Result{
+		{Scenario: "workers", Variant: "4", Metrics: map[string]float64{
+			MetricDurationMS:    1000,
+			MetricCommitsPerSec: 100,
+			MetricPeakHeapMB:    0,
+		}},
+		{Scenario: "workers", Variant: "8", Metrics: map[string]float64{MetricDurationMS: 1000}},
+	}}
+
+	current := Report{Results: []Result{
+		{Scenario: "workers", Variant: "4", Metrics: map[string]float64{
+			MetricDurationMS:    1200, // 20% slower.
+			MetricCommitsPerSec: 95,   // 5% lower, within threshold.
+			MetricPeakHeapMB:    50,   // Zero baseline, not compared.
+		}},
+		{Scenario: "workers", Variant: "2", Metrics: map[string]float64{MetricDurationMS: 5000}},
+	}}
+
+	regressions := Compare(baseline, current, 10)
+	require.Len(t, regressions, 1)
+	assert.Equal(t, "workers", regressions[0].Scenario)
+	assert.Equal(t, "4", regressions[0].Variant)
+	assert.Equal(t, MetricDurationMS, regressions[0].Metric)
+	assert.InDelta(t, 20, regressions[0].ChangePct, 1e-9)
+}
+
+func TestCompare_HigherIsBetter(t *testing.T) {
+	t.Parallel()
+
+	baseline := Report{Results: []Result{{Scenario: "s", Variant: "v", Metrics: map[string]float64{MetricCommitsPerSec: 100}}}}
+	faster := Report{Results: []Result{{Scenario: "s", Variant: "v", Metrics: map[string]float64{MetricCommitsPerSec: 200}}}}
+	slower := Report{Results: []Result{{Scenario: "s", Variant: "v", Metrics: map[string]float64{MetricCommitsPerSec: 50}}}}
+
+	assert.Empty(t, Compare(baseline, faster, 10))
+	assert.Len(t, Compare(baseline, slower, 10), 1)
+}
+
+func TestReadReport_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	report := Report{
+		Version:   ReportVersion,
+		Repo:      "repo",
+		Analyzers: []string{"burndown"},
+		Commits:   10,
+		Results:   []Result{{Scenario: "hibernation", Variant: "500", Metrics: map[string]float64{MetricHibernateMS: 3}}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, report))
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	got, err := ReadReport(path)
+	require.NoError(t, err)
+	assert.Equal(t, report, got)
+}
+
+func TestReadReport_WrongVersion(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99}`), 0o600))
+
+	_, err := ReadReport(path)
+	require.ErrorIs(t, err, ErrUnsupportedReport)
+}
+
+func TestWriteText(t *testing.T) {
+	t.Parallel()
+
+	report := Report{Results: []Result{
+		{Scenario: "workers", Variant: "1", Metrics: map[string]float64{MetricDurationMS: 12.34}},
+		{Scenario: "hibernation", Variant: "500", Metrics: map[string]float64{MetricHibernateMS: 5}},
+	}}
+	regressions := []Regression{{Scenario: "workers", Variant: "1", Metric: MetricDurationMS, Baseline: 10, Current: 12.34, ChangePct: 23.4}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf, report, regressions))
+
+	out := buf.String()
+	assert.Contains(t, out, "duration_ms")
+	assert.Contains(t, out, "hibernate_ms")
+	assert.Contains(t, out, "12.3")
+	assert.Contains(t, out, "1 regression(s):")
+	assert.Contains(t, out, "workers/1 duration_ms: 10.00 -> 12.34 (+23.4%)")
+}
+
+func TestHeapSampler(t *testing.T) {
+	t.Parallel()
+
+	s := StartHeapSampler(time.Millisecond)
+	assert.Positive(t, s.Stop())
+}
@@ -0,0 +1,64 @@
+package bench
+
+import (
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// heapObjectsMetric is the runtime metric sampled by HeapSampler.
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// HeapSampler records the peak live heap while it runs. Peaks between two
+// samples are missed, so keep the interval well below the length of a run.
+type HeapSampler struct {
+	stop chan struct{}
+	done sync.WaitGroup
+	peak uint64
+}
+
+// StartHeapSampler samples the heap every interval until Stop is called.
+func StartHeapSampler(interval time.Duration) *HeapSampler {
+	s := &HeapSampler{stop: make(chan struct{})}
+	s.peak = heapObjects()
+
+	s.done.Add(1)
+
+	go func() {
+		defer s.done.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.peak = max(s.peak, heapObjects())
+			}
+		}
+	}()
+
+	return s
+}
+
+// Stop takes a last sample and returns the peak heap in bytes.
+func (s *HeapSampler) Stop() uint64 {
+	close(s.stop)
+	s.done.Wait()
+
+	return max(s.peak, heapObjects())
+}
+
+// heapObjects returns the bytes occupied by live and unswept heap objects.
+func heapObjects() uint64 {
+	sample := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(sample)
+
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+
+	return sample[0].Value.Uint64()
+}
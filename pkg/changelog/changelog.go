@@ -0,0 +1,191 @@
+// Package changelog groups the commits between two revisions by
+// Conventional Commits type and module and renders them as Markdown release
+// notes, enriched with the complexity the commits added or removed.
+package changelog
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// DefaultModuleDepth is the default number of directory levels that name a
+// module.
+const DefaultModuleDepth = 2
+
+// typeOther is the section of commits that do not follow the convention or
+// have an unknown type.
+const typeOther = "other"
+
+// sectionTitles are the titles of the known commit types, in the order the
+// sections are rendered.
+var sectionTitles = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+	{"refactor", "Refactoring"},
+	{"revert", "Reverts"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build"},
+	{"ci", "Continuous Integration"},
+	{"style", "Style"},
+	{"chore", "Chores"},
+	{typeOther, "Other Changes"},
+}
+
+// Entry is one commit of the changelog.
+type Entry struct {
+	Hash   string
+	Author string
+	When   time.Time
+	Class  plumbing.CommitClass
+	// Files are the files the commit changed.
+	Files []string
+	// Complexity is the cyclomatic complexity delta of the functions the
+	// commit changed, per file. Nil when complexity was not analyzed.
+	Complexity map[string]int
+}
+
+// ComplexityDelta returns the complexity delta of the commit over all files.
+func (e Entry) ComplexityDelta() int {
+	total := 0
+	for _, delta := range e.Complexity {
+		total += delta
+	}
+
+	return total
+}
+
+// Module is the commits of one section that changed one module.
+type Module struct {
+	Name    string
+	Entries []Entry
+	// Complexity is the complexity delta of the entries within the module.
+	Complexity int
+}
+
+// Section is the commits of one commit type, grouped by module.
+type Section struct {
+	Type    string
+	Title   string
+	Modules []Module
+}
+
+// Changelog is the release notes of the commits between two revisions.
+type Changelog struct {
+	From string
+	To   string
+	// Breaking lists the breaking changes, oldest first. They also appear in
+	// their sections.
+	Breaking []Entry
+	Sections []Section
+	Commits  int
+	Authors  []string
+	// Complexity is the complexity delta of all commits; HasComplexity is
+	// set when complexity was analyzed.
+	Complexity    int
+	HasComplexity bool
+}
+
+// Build groups entries, oldest first, by commit type and module. A commit
+// belongs to the module where it changed the most files.
+func Build(from, to string, entries []Entry, moduleDepth int) *Changelog {
+	notes := &Changelog{From: from, To: to, Commits: len(entries)}
+
+	authors := make(map[string]bool)
+	byType := make(map[string]map[string]*Module)
+
+	for _, entry := range entries {
+		authors[entry.Author] = true
+
+		if entry.Complexity != nil {
+			notes.HasComplexity = true
+			notes.Complexity += entry.ComplexityDelta()
+		}
+
+		if entry.Class.Breaking {
+			notes.Breaking = append(notes.Breaking, entry)
+		}
+
+		kind := sectionType(entry.Class.Type)
+		if byType[kind] == nil {
+			byType[kind] = make(map[string]*Module)
+		}
+
+		name := primaryModule(entry.Files, moduleDepth)
+
+		module, ok := byType[kind][name]
+		if !ok {
+			module = &Module{Name: name}
+			byType[kind][name] = module
+		}
+
+		module.Entries = append(module.Entries, entry)
+
+		for file, delta := range entry.Complexity {
+			if plumbing.DirPrefix(file, moduleDepth) == name {
+				module.Complexity += delta
+			}
+		}
+	}
+
+	notes.Authors = slices.Sorted(maps.Keys(authors))
+
+	for _, section := range sectionTitles {
+		modules, ok := byType[section.Type]
+		if !ok {
+			continue
+		}
+
+		sorted := make([]Module, 0, len(modules))
+		for _, module := range modules {
+			sorted = append(sorted, *module)
+		}
+
+		slices.SortFunc(sorted, func(x, y Module) int {
+			return cmp.Or(cmp.Compare(len(y.Entries), len(x.Entries)), cmp.Compare(x.Name, y.Name))
+		})
+
+		notes.Sections = append(notes.Sections, Section{Type: section.Type, Title: section.Title, Modules: sorted})
+	}
+
+	return notes
+}
+
+// sectionType returns the section of a commit type.
+func sectionType(kind string) string {
+	for _, section := range sectionTitles {
+		if section.Type == kind {
+			return kind
+		}
+	}
+
+	return typeOther
+}
+
+// primaryModule returns the module of the most files, the first by
+// name on ties. Commits that changed no file belong to ".".
+func primaryModule(files []string, depth int) string {
+	counts := make(map[string]int)
+	for _, file := range files {
+		counts[plumbing.DirPrefix(file, depth)]++
+	}
+
+	best := "."
+	bestCount := 0
+
+	for _, module := range slices.Sorted(maps.Keys(counts)) {
+		if counts[module] > bestCount {
+			best, bestCount = module, counts[module]
+		}
+	}
+
+	return best
+}
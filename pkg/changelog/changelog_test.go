@@ -0,0 +1,131 @@
+package changelog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func testEntries() []Entry {
+	return []Entry{
+		{
+			Hash:   "a1b2c3d4e5",
+			Author: "alice",
+			Class:  plumbing.ClassifyCommit("feat(api): add pagination"),
+			Files:  []string{"pkg/api/list.go", "pkg/api/page.go", "docs/api.md"},
+		},
+		{
+			Hash:   "b2c3d4e5f6",
+			Author: "bob",
+			Class:  plumbing.ClassifyCommit("fix!: reject empty names"),
+			Files:  []string{"cmd/app/main.go"},
+		},
+		{
+			Hash:   "c3d4e5f6a7",
+			Author: "alice",
+			Class:  plumbing.ClassifyCommit("Update README"),
+			Files:  []string{"README.md"},
+		},
+		{
+			Hash:   "d4e5f6a7b8",
+			Author: "carol",
+			Class:  plumbing.ClassifyCommit("feat: stream results"),
+			Files:  []string{"pkg/api/stream.go"},
+		},
+	}
+}
+
+func TestBuild_GroupsByTypeAndModule(t *testing.T) {
+	t.Parallel()
+
+	notes := Build("v1.2.0", "v1.3.0", testEntries(), DefaultModuleDepth)
+
+	assert.Equal(t, 4, notes.Commits)
+	assert.Equal(t, []string{"alice", "bob", "carol"}, notes.Authors)
+	assert.False(t, notes.HasComplexity)
+
+	require.Len(t, notes.Breaking, 1)
+	assert.Equal(t, "b2c3d4e5f6", notes.Breaking[0].Hash)
+
+	require.Len(t, notes.Sections, 3)
+	assert.Equal(t, "Features", notes.Sections[0].Title)
+	assert.Equal(t, "Bug Fixes", notes.Sections[1].Title)
+	assert.Equal(t, "Other Changes", notes.Sections[2].Title)
+
+	features := notes.Sections[0].Modules
+	require.Len(t, features, 1)
+	assert.Equal(t, "pkg/api", features[0].Name)
+	assert.Len(t, features[0].Entries, 2)
+
+	assert.Equal(t, ".", notes.Sections[2].Modules[0].Name)
+}
+
+func TestBuild_Complexity(t *testing.T) {
+	t.Parallel()
+
+	entries := testEntries()
+	WithComplexity(entries, map[string]map[string]int{
+		"a1b2c3d4e5": {"pkg/api/list.go": 4, "docs/api.md": 0},
+		"d4e5f6a7b8": {"pkg/api/stream.go": 3, "cmd/app/main.go": -2},
+	})
+
+	notes := Build("v1.2.0", "v1.3.0", entries, DefaultModuleDepth)
+
+	assert.True(t, notes.HasComplexity)
+	assert.Equal(t, 5, notes.Complexity)
+	assert.Equal(t, 7, notes.Sections[0].Modules[0].Complexity)
+	assert.Equal(t, 0, entries[1].ComplexityDelta())
+}
+
+func TestPrimaryModule(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, ".", primaryModule(nil, 2))
+	assert.Equal(t, "pkg/a", primaryModule([]string{"pkg/a/x/y.go", "pkg/a/z.go", "pkg/b/w.go"}, 2))
+	assert.Equal(t, "pkg", primaryModule([]string{"pkg/b/w.go", "pkg/a/z.go"}, 1))
+	assert.Equal(t, "pkg/a", primaryModule([]string{"pkg/b/w.go", "pkg/a/z.go"}, 2))
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	t.Parallel()
+
+	entries := testEntries()
+	WithComplexity(entries, map[string]map[string]int{"a1b2c3d4e5": {"pkg/api/list.go": 4}})
+
+	var out bytes.Buffer
+
+	err := Build("v1.2.0", "v1.3.0", entries, DefaultModuleDepth).WriteMarkdown(&out)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# Changes from v1.2.0 to v1.3.0
+
+4 commits by 3 authors. Cyclomatic complexity of the changed functions: +4.
+
+## Breaking Changes
+
+- reject empty names (b2c3d4e, bob)
+
+## Features
+
+### pkg/api (complexity +4)
+
+- **api:** add pagination (a1b2c3d, alice) complexity +4
+- stream results (d4e5f6a, carol)
+
+## Bug Fixes
+
+### cmd/app
+
+- reject empty names (b2c3d4e, bob)
+
+## Other Changes
+
+### .
+
+- Update README (c3d4e5f, alice)
+`, out.String())
+}
@@ -0,0 +1,89 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+// Entries classifies commits, oldest first, and lists the files each
+// changed against its first parent. Merge commits are skipped: their changes
+// are the commits they merged.
+func Entries(ctx context.Context, repo *gitlib.Repository, commits []*gitlib.Commit) ([]Entry, error) {
+	entries := make([]Entry, 0, len(commits))
+
+	for _, commit := range commits {
+		if commit.NumParents() > 1 {
+			continue
+		}
+
+		files, err := changedFiles(ctx, repo, commit)
+		if err != nil {
+			return nil, fmt.Errorf("commit %s: %w", commit.Hash(), err)
+		}
+
+		author := commit.Author()
+
+		entries = append(entries, Entry{
+			Hash:   commit.Hash().String(),
+			Author: author.Name,
+			When:   author.When,
+			Class:  plumbing.ClassifyCommit(commit.Message()),
+			Files:  files,
+		})
+	}
+
+	return entries, nil
+}
+
+// changedFiles returns the paths of the files commit added, modified or
+// deleted.
+func changedFiles(ctx context.Context, repo *gitlib.Repository, commit *gitlib.Commit) ([]string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Free()
+
+	var changes gitlib.Changes
+
+	if commit.NumParents() == 0 {
+		changes, err = gitlib.InitialTreeChanges(ctx, repo, tree)
+	} else {
+		changes, err = parentChanges(ctx, repo, commit, tree)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(changes))
+
+	for _, change := range changes {
+		if change.Action == gitlib.Delete {
+			files = append(files, change.From.Name)
+		} else {
+			files = append(files, change.To.Name)
+		}
+	}
+
+	return files, nil
+}
+
+func parentChanges(ctx context.Context, repo *gitlib.Repository, commit *gitlib.Commit, tree *gitlib.Tree) (gitlib.Changes, error) {
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return nil, err
+	}
+	defer parent.Free()
+
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return nil, err
+	}
+	defer parentTree.Free()
+
+	return gitlib.TreeDiff(ctx, repo, parentTree, tree)
+}
@@ -0,0 +1,58 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/complexity"
+	"github.com/Sumatoshi-tech/codefang/pkg/codefang"
+)
+
+// complexityAnalyzer is the history analyzer that provides the complexity
+// deltas.
+const complexityAnalyzer = "history/complexity"
+
+// Complexity runs the complexity history analyzer over the commits opts
+// select and returns the cyclomatic complexity delta of every commit, by
+// commit hash and file.
+func Complexity(ctx context.Context, opts codefang.Options) (map[string]map[string]int, error) {
+	deltas := make(map[string]map[string]int)
+
+	opts.Analyzers = []string{complexityAnalyzer}
+	opts.OnRecord = func(record codefang.Record) {
+		data, ok := record.Data.(*complexity.CommitData)
+		if !ok || data == nil {
+			return
+		}
+
+		files := deltas[record.Commit]
+		if files == nil {
+			files = make(map[string]int)
+			deltas[record.Commit] = files
+		}
+
+		for _, change := range data.Changes {
+			files[change.File] += change.After - change.Before
+		}
+	}
+
+	_, err := codefang.Run(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("complexity history: %w", err)
+	}
+
+	return deltas, nil
+}
+
+// WithComplexity sets the complexity of every entry from deltas. Entries
+// without a delta changed no function's complexity.
+func WithComplexity(entries []Entry, deltas map[string]map[string]int) {
+	for i := range entries {
+		files := deltas[entries[i].Hash]
+		if files == nil {
+			files = make(map[string]int)
+		}
+
+		entries[i].Complexity = files
+	}
+}
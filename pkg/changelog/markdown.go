@@ -0,0 +1,97 @@
+package changelog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// shortHashLen is the length of the abbreviated commit hashes.
+const shortHashLen = 7
+
+// WriteMarkdown renders the changelog as Markdown release notes.
+func (c *Changelog) WriteMarkdown(writer io.Writer) error {
+	out := bufio.NewWriter(writer)
+
+	fmt.Fprintf(out, "# Changes from %s to %s\n\n", c.From, c.To)
+	fmt.Fprintf(out, "%s by %s.", plural(c.Commits, "commit"), plural(len(c.Authors), "author"))
+
+	if c.HasComplexity {
+		fmt.Fprintf(out, " Cyclomatic complexity of the changed functions: %s.", signed(c.Complexity))
+	}
+
+	fmt.Fprintln(out)
+
+	if len(c.Breaking) > 0 {
+		fmt.Fprint(out, "\n## Breaking Changes\n\n")
+
+		for _, entry := range c.Breaking {
+			writeEntry(out, entry, false)
+		}
+	}
+
+	for _, section := range c.Sections {
+		fmt.Fprintf(out, "\n## %s\n", section.Title)
+
+		for _, module := range section.Modules {
+			fmt.Fprintf(out, "\n### %s", module.Name)
+
+			if c.HasComplexity && module.Complexity != 0 {
+				fmt.Fprintf(out, " (complexity %s)", signed(module.Complexity))
+			}
+
+			fmt.Fprint(out, "\n\n")
+
+			for _, entry := range module.Entries {
+				writeEntry(out, entry, c.HasComplexity)
+			}
+		}
+	}
+
+	err := out.Flush()
+	if err != nil {
+		return fmt.Errorf("write changelog: %w", err)
+	}
+
+	return nil
+}
+
+// writeEntry writes one list item: the scope, the description, the
+// abbreviated hash, the author and, when withComplexity is set, a non-zero
+// complexity delta.
+func writeEntry(out io.Writer, entry Entry, withComplexity bool) {
+	fmt.Fprint(out, "- ")
+
+	if entry.Class.Scope != "" {
+		fmt.Fprintf(out, "**%s:** ", entry.Class.Scope)
+	}
+
+	fmt.Fprintf(out, "%s (%s, %s)", entry.Class.Description, shortHash(entry.Hash), entry.Author)
+
+	if delta := entry.ComplexityDelta(); withComplexity && delta != 0 {
+		fmt.Fprintf(out, " complexity %s", signed(delta))
+	}
+
+	fmt.Fprintln(out)
+}
+
+func shortHash(hash string) string {
+	return hash[:min(len(hash), shortHashLen)]
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+
+	return strconv.Itoa(count) + " " + noun + "s"
+}
+
+func signed(value int) string {
+	if value > 0 {
+		return "+" + strconv.Itoa(value)
+	}
+
+	return strconv.Itoa(value)
+}
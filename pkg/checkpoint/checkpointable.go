@@ -1,12 +1,16 @@
 package checkpoint
 
+import "github.com/Sumatoshi-tech/codefang/pkg/crypt"
+
 // Checkpointable is an optional interface for analyzers that support checkpointing.
 type Checkpointable interface {
-	// SaveCheckpoint writes analyzer state to the given directory.
-	SaveCheckpoint(dir string) error
+	// SaveCheckpoint writes analyzer state to the given directory. A non-nil
+	// cipher encrypts the state files.
+	SaveCheckpoint(dir string, c *crypt.Cipher) error
 
-	// LoadCheckpoint restores analyzer state from the given directory.
-	LoadCheckpoint(dir string) error
+	// LoadCheckpoint restores analyzer state from the given directory,
+	// decrypting with c when it is non-nil.
+	LoadCheckpoint(dir string, c *crypt.Cipher) error
 
 	// CheckpointSize returns the estimated size of the checkpoint in bytes.
 	CheckpointSize() int64
@@ -8,6 +8,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 )
 
 // mockCheckpointable implements Checkpointable for testing.
@@ -15,7 +17,7 @@ type mockCheckpointable struct {
 	data string
 }
 
-func (m *mockCheckpointable) SaveCheckpoint(dir string) error {
+func (m *mockCheckpointable) SaveCheckpoint(dir string, _ *crypt.Cipher) error {
 	err := os.WriteFile(filepath.Join(dir, "mock.bin"), []byte(m.data), 0o600)
 	if err != nil {
 		return fmt.Errorf("writing mock checkpoint: %w", err)
@@ -24,7 +26,7 @@ func (m *mockCheckpointable) SaveCheckpoint(dir string) error {
 	return nil
 }
 
-func (m *mockCheckpointable) LoadCheckpoint(dir string) error {
+func (m *mockCheckpointable) LoadCheckpoint(dir string, _ *crypt.Cipher) error {
 	data, err := os.ReadFile(filepath.Join(dir, "mock.bin"))
 	if err != nil {
 		return fmt.Errorf("reading mock checkpoint: %w", err)
@@ -52,11 +54,11 @@ func TestCheckpointable_SaveLoad(t *testing.T) {
 	dir := t.TempDir()
 
 	original := &mockCheckpointable{data: "test state data"}
-	err := original.SaveCheckpoint(dir)
+	err := original.SaveCheckpoint(dir, nil)
 	require.NoError(t, err)
 
 	restored := &mockCheckpointable{}
-	err = restored.LoadCheckpoint(dir)
+	err = restored.LoadCheckpoint(dir, nil)
 	require.NoError(t, err)
 
 	assert.Equal(t, original.data, restored.data)
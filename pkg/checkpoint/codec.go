@@ -7,6 +7,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 )
 
 // Codec defines how checkpoint state is serialized and deserialized.
@@ -104,11 +106,24 @@ func (c *GobCodec) Extension() string {
 	return ".gob"
 }
 
+// encryptedExtension is appended to the names of encrypted checkpoint files.
+const encryptedExtension = ".enc"
+
+// stateFilename returns the checkpoint file name of basename.
+func stateFilename(basename string, codec Codec, c *crypt.Cipher) string {
+	if c != nil {
+		return basename + codec.Extension() + encryptedExtension
+	}
+
+	return basename + codec.Extension()
+}
+
 // SaveState saves the given state to a file in the specified directory.
 // The filename is constructed from the basename and the codec's extension.
-func SaveState(dir, basename string, codec Codec, state any) error {
-	filename := basename + codec.Extension()
-	path := filepath.Join(dir, filename)
+// A non-nil c encrypts the file, which then carries an extra ".enc"
+// extension, so a checkpoint is only resumed with the setting it was saved with.
+func SaveState(dir, basename string, codec Codec, c *crypt.Cipher, state any) error {
+	path := filepath.Join(dir, stateFilename(basename, codec, c))
 
 	file, err := os.Create(path)
 	if err != nil {
@@ -116,20 +131,39 @@ func SaveState(dir, basename string, codec Codec, state any) error {
 	}
 	defer file.Close()
 
-	err = codec.Encode(file, state)
+	if c == nil {
+		err = codec.Encode(file, state)
+		if err != nil {
+			return fmt.Errorf("encode checkpoint: %w", err)
+		}
+
+		return nil
+	}
+
+	w, err := c.NewWriter(file)
+	if err != nil {
+		return fmt.Errorf("encrypt checkpoint: %w", err)
+	}
+
+	err = codec.Encode(w, state)
 	if err != nil {
 		return fmt.Errorf("encode checkpoint: %w", err)
 	}
 
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("encrypt checkpoint: %w", err)
+	}
+
 	return nil
 }
 
 // LoadState loads state from a file in the specified directory.
 // The filename is constructed from the basename and the codec's extension.
-// The state parameter must be a pointer to the target struct.
-func LoadState(dir, basename string, codec Codec, state any) error {
-	filename := basename + codec.Extension()
-	path := filepath.Join(dir, filename)
+// The state parameter must be a pointer to the target struct. A non-nil c
+// decrypts a file saved with the same cipher.
+func LoadState(dir, basename string, codec Codec, c *crypt.Cipher, state any) error {
+	path := filepath.Join(dir, stateFilename(basename, codec, c))
 
 	file, err := os.Open(path)
 	if err != nil {
@@ -137,7 +171,16 @@ func LoadState(dir, basename string, codec Codec, state any) error {
 	}
 	defer file.Close()
 
-	err = codec.Decode(file, state)
+	var r io.Reader = file
+
+	if c != nil {
+		r, err = c.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("decrypt checkpoint: %w", err)
+		}
+	}
+
+	err = codec.Decode(r, state)
 	if err != nil {
 		return fmt.Errorf("decode checkpoint: %w", err)
 	}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 )
 
 // testState is a simple struct for testing codecs.
@@ -134,7 +136,7 @@ func TestSaveState_JSON(t *testing.T) {
 
 	state := testState{Name: "save-test", Count: 99}
 
-	err := SaveState(dir, "test_state", codec, state)
+	err := SaveState(dir, "test_state", codec, nil, state)
 	if err != nil {
 		t.Fatalf("SaveState failed: %v", err)
 	}
@@ -156,14 +158,14 @@ func TestLoadState_JSON(t *testing.T) {
 
 	original := testState{Name: "load-test", Count: 77, Values: map[string]int{"k": 5}}
 
-	err := SaveState(dir, "test_state", codec, original)
+	err := SaveState(dir, "test_state", codec, nil, original)
 	if err != nil {
 		t.Fatalf("SaveState failed: %v", err)
 	}
 
 	var loaded testState
 
-	err = LoadState(dir, "test_state", codec, &loaded)
+	err = LoadState(dir, "test_state", codec, nil, &loaded)
 	if err != nil {
 		t.Fatalf("LoadState failed: %v", err)
 	}
@@ -185,7 +187,7 @@ func TestSaveState_Gob(t *testing.T) {
 
 	state := testState{Name: "gob-save", Count: 88}
 
-	err := SaveState(dir, "gob_state", codec, state)
+	err := SaveState(dir, "gob_state", codec, nil, state)
 	if err != nil {
 		t.Fatalf("SaveState failed: %v", err)
 	}
@@ -206,14 +208,14 @@ func TestLoadState_Gob(t *testing.T) {
 
 	original := testState{Name: "gob-load", Count: 66}
 
-	err := SaveState(dir, "gob_state", codec, original)
+	err := SaveState(dir, "gob_state", codec, nil, original)
 	if err != nil {
 		t.Fatalf("SaveState failed: %v", err)
 	}
 
 	var loaded testState
 
-	err = LoadState(dir, "gob_state", codec, &loaded)
+	err = LoadState(dir, "gob_state", codec, nil, &loaded)
 	if err != nil {
 		t.Fatalf("LoadState failed: %v", err)
 	}
@@ -231,7 +233,7 @@ func TestLoadState_FileNotFound(t *testing.T) {
 
 	var state testState
 
-	err := LoadState(dir, "nonexistent", codec, &state)
+	err := LoadState(dir, "nonexistent", codec, nil, &state)
 	if err == nil {
 		t.Error("LoadState should fail for nonexistent file")
 	}
@@ -243,8 +245,50 @@ func TestSaveState_InvalidDirectory(t *testing.T) {
 	codec := NewJSONCodec()
 	state := testState{Name: "test"}
 
-	err := SaveState("/nonexistent/path/that/does/not/exist", "test", codec, state)
+	err := SaveState("/nonexistent/path/that/does/not/exist", "test", codec, nil, state)
 	if err == nil {
 		t.Error("SaveState should fail for invalid directory")
 	}
 }
+
+func TestSaveState_Encrypted(t *testing.T) {
+	t.Parallel()
+
+	c, err := crypt.NewCipher(bytes.Repeat([]byte{5}, crypt.KeySize))
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	original := testState{Name: "confidential commit message", Count: 7}
+
+	err = SaveState(dir, "enc_state", NewJSONCodec(), c, original)
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "enc_state.json.enc"))
+	if err != nil {
+		t.Fatalf("encrypted checkpoint file not created: %v", err)
+	}
+
+	if bytes.Contains(data, []byte("confidential")) {
+		t.Error("checkpoint file contains plaintext")
+	}
+
+	var loaded testState
+
+	err = LoadState(dir, "enc_state", NewJSONCodec(), c, &loaded)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+
+	if loaded.Name != original.Name || loaded.Count != original.Count {
+		t.Errorf("loaded %+v, want %+v", loaded, original)
+	}
+
+	err = LoadState(dir, "enc_state", NewJSONCodec(), nil, &loaded)
+	if err == nil {
+		t.Error("LoadState without encryption should not find the encrypted file")
+	}
+}
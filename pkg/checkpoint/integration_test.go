@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/checkpoint"
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 )
 
 const testRepoPath = "/test/repo"
@@ -21,7 +22,7 @@ type mockAnalyzer struct {
 	processLog []int // Records which commits were processed.
 }
 
-func (m *mockAnalyzer) SaveCheckpoint(dir string) error {
+func (m *mockAnalyzer) SaveCheckpoint(dir string, _ *crypt.Cipher) error {
 	// Write counter and processLog to file.
 	data := make([]byte, 0, len(m.processLog))
 	for _, v := range m.processLog {
@@ -36,7 +37,7 @@ func (m *mockAnalyzer) SaveCheckpoint(dir string) error {
 	return nil
 }
 
-func (m *mockAnalyzer) LoadCheckpoint(dir string) error {
+func (m *mockAnalyzer) LoadCheckpoint(dir string, _ *crypt.Cipher) error {
 	data, err := os.ReadFile(filepath.Join(dir, m.name+".bin"))
 	if err != nil {
 		return fmt.Errorf("reading analyzer checkpoint %s: %w", m.name, err)
@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 )
 
 // MetadataVersion is the current checkpoint metadata format version.
@@ -54,6 +56,9 @@ type Manager struct {
 	RepoHash string
 	MaxAge   time.Duration
 	MaxSize  int64
+
+	// Cipher, when set, encrypts and decrypts the analyzer state files.
+	Cipher *crypt.Cipher
 }
 
 // NewManager creates a new checkpoint manager.
@@ -125,7 +130,7 @@ func (m *Manager) Save(
 			return fmt.Errorf("create analyzer dir: %w", mkdirErr)
 		}
 
-		saveErr := cp.SaveCheckpoint(analyzerDir)
+		saveErr := cp.SaveCheckpoint(analyzerDir, m.Cipher)
 		if saveErr != nil {
 			return fmt.Errorf("save checkpoint for analyzer %d: %w", i, saveErr)
 		}
@@ -186,7 +191,7 @@ func (m *Manager) Load(checkpointables []Checkpointable) (*StreamingState, error
 	for i, cp := range checkpointables {
 		analyzerDir := filepath.Join(cpDir, fmt.Sprintf("analyzer_%d", i))
 
-		loadErr := cp.LoadCheckpoint(analyzerDir)
+		loadErr := cp.LoadCheckpoint(analyzerDir, m.Cipher)
 		if loadErr != nil {
 			return nil, fmt.Errorf("load checkpoint for analyzer %d: %w", i, loadErr)
 		}
@@ -1,6 +1,7 @@
 package checkpoint
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
@@ -8,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 )
 
 func TestManager_New(t *testing.T) {
@@ -162,6 +165,56 @@ func TestManager_SaveLoad_Checkpointables(t *testing.T) {
 	assert.Equal(t, state.ProcessedCommits, loadedState.ProcessedCommits)
 }
 
+type persistedState struct {
+	Data string
+}
+
+// persistedCheckpointable saves its state through a Persister.
+type persistedCheckpointable struct {
+	data string
+}
+
+func (p *persistedCheckpointable) SaveCheckpoint(dir string, c *crypt.Cipher) error {
+	return NewPersister[persistedState]("persisted", NewJSONCodec()).Save(dir, c, func() *persistedState {
+		return &persistedState{Data: p.data}
+	})
+}
+
+func (p *persistedCheckpointable) LoadCheckpoint(dir string, c *crypt.Cipher) error {
+	return NewPersister[persistedState]("persisted", NewJSONCodec()).Load(dir, c, func(s *persistedState) {
+		p.data = s.Data
+	})
+}
+
+func (p *persistedCheckpointable) CheckpointSize() int64 { return int64(len(p.data)) }
+
+func TestManager_SaveLoad_Cipher(t *testing.T) {
+	t.Parallel()
+
+	c, err := crypt.NewCipher(bytes.Repeat([]byte{9}, crypt.KeySize))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	m := NewManager(dir, "abc123")
+	m.Cipher = c
+
+	original := &persistedCheckpointable{data: "confidential commit message"}
+	require.NoError(t, m.Save([]Checkpointable{original}, StreamingState{}, "/path/to/repo", []string{"mock"}))
+
+	data, err := os.ReadFile(filepath.Join(m.CheckpointDir(), "analyzer_0", "persisted.json.enc"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "confidential")
+
+	restored := &persistedCheckpointable{}
+	_, err = m.Load([]Checkpointable{restored})
+	require.NoError(t, err)
+	assert.Equal(t, original.data, restored.data)
+
+	// Another manager of the same process, without the cipher, is unaffected.
+	_, err = NewManager(dir, "abc123").Load([]Checkpointable{&persistedCheckpointable{}})
+	require.Error(t, err)
+}
+
 func TestManager_DefaultValues(t *testing.T) {
 	t.Parallel()
 
@@ -1,5 +1,7 @@
 package checkpoint
 
+import "github.com/Sumatoshi-tech/codefang/pkg/crypt"
+
 // Persister handles checkpoint I/O for a specific state type.
 type Persister[T any] struct {
 	basename string
@@ -14,18 +16,20 @@ func NewPersister[T any](basename string, codec Codec) *Persister[T] {
 	}
 }
 
-// Save writes state to the given directory using the provided build function.
-func (p *Persister[T]) Save(dir string, buildState func() *T) error {
+// Save writes state to the given directory using the provided build function,
+// encrypted with c when it is non-nil.
+func (p *Persister[T]) Save(dir string, c *crypt.Cipher, buildState func() *T) error {
 	state := buildState()
 
-	return SaveState(dir, p.basename, p.codec, state)
+	return SaveState(dir, p.basename, p.codec, c, state)
 }
 
-// Load restores state from the given directory using the provided restore function.
-func (p *Persister[T]) Load(dir string, restoreState func(*T)) error {
+// Load restores state from the given directory using the provided restore function,
+// decrypting with c when it is non-nil.
+func (p *Persister[T]) Load(dir string, c *crypt.Cipher, restoreState func(*T)) error {
 	var state T
 
-	err := LoadState(dir, p.basename, p.codec, &state)
+	err := LoadState(dir, p.basename, p.codec, c, &state)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,245 @@
+package checkpoint
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// sinkJournalFile is the journal file name inside the checkpoint directory.
+const sinkJournalFile = "sink.journal"
+
+// sinkJournalFields is the number of fields of a journal line.
+const sinkJournalFields = 3
+
+// SinkJournalPath returns the path to the streaming output journal.
+func (m *Manager) SinkJournalPath() string {
+	return filepath.Join(m.CheckpointDir(), sinkJournalFile)
+}
+
+// SinkJournal tracks the last commit each analyzer emitted to a streaming
+// output sink (NDJSON), so a resumed run continues the output where the
+// interrupted run stopped instead of repeating the records written after the
+// last checkpoint.
+//
+// Every emitted record appends one "<analyzer> <commit hash> <seq>" line. The
+// per-analyzer last hashes and the last sequence number are stored in
+// StreamingState at each checkpoint; on resume, a journal entry that differs
+// from the checkpointed one marks records that were already written. Each
+// analyzer emits at most one record per commit, in commit order, so those
+// records are exactly the ones up to and including the journaled commit.
+//
+// All methods are safe for concurrent use and on a nil receiver (no-op).
+type SinkJournal struct {
+	mu      sync.Mutex
+	file    *os.File
+	last    map[string]string // last emitted commit per analyzer.
+	pending map[string]string // on resume: skip records up to this commit.
+	seq     uint64            // highest sequence number emitted.
+}
+
+// OpenSinkJournal opens the journal at path. A fresh run (resumed == nil)
+// truncates it; otherwise resumed is the checkpoint state the run resumes from.
+func OpenSinkJournal(path string, resumed *StreamingState) (*SinkJournal, error) {
+	err := os.MkdirAll(filepath.Dir(path), dirPerm)
+	if err != nil {
+		return nil, fmt.Errorf("create sink journal dir: %w", err)
+	}
+
+	j := &SinkJournal{
+		last:    make(map[string]string),
+		pending: make(map[string]string),
+	}
+
+	// O_APPEND keeps writes at the end of the file after Reset truncates it.
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND | os.O_TRUNC
+
+	if resumed != nil {
+		checkpointed := resumed.SinkLastCommits
+
+		j.last, j.seq, err = readSinkJournal(path)
+		if err != nil {
+			return nil, err
+		}
+
+		j.seq = max(j.seq, resumed.SinkSeq)
+
+		for analyzer, hash := range j.last {
+			if checkpointed[analyzer] != hash {
+				j.pending[analyzer] = hash
+			}
+		}
+
+		for analyzer, hash := range checkpointed {
+			if _, ok := j.last[analyzer]; !ok {
+				j.last[analyzer] = hash
+			}
+		}
+
+		flags &^= os.O_TRUNC
+	}
+
+	j.file, err = os.OpenFile(path, flags, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open sink journal: %w", err)
+	}
+
+	return j, nil
+}
+
+// readSinkJournal returns the last journaled commit per analyzer and the
+// highest sequence number. A torn final line from an interrupted write is
+// ignored.
+func readSinkJournal(path string) (last map[string]string, seq uint64, err error) {
+	last = make(map[string]string)
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return last, 0, nil
+	}
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("open sink journal: %w", err)
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != sinkJournalFields {
+			continue
+		}
+
+		lineSeq, parseErr := strconv.ParseUint(fields[2], 10, 64)
+		if parseErr != nil {
+			continue
+		}
+
+		last[fields[0]] = fields[1]
+		seq = max(seq, lineSeq)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, 0, fmt.Errorf("read sink journal: %w", err)
+	}
+
+	return last, seq, nil
+}
+
+// Skip reports whether the record of analyzer for commit hash was already
+// written before the run was interrupted and must not be emitted again.
+func (j *SinkJournal) Skip(analyzer, hash string) bool {
+	if j == nil {
+		return false
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	target, ok := j.pending[analyzer]
+	if !ok {
+		return false
+	}
+
+	if target == hash {
+		delete(j.pending, analyzer)
+	}
+
+	return true
+}
+
+// Record journals that analyzer emitted its record for commit hash with
+// sequence number seq.
+func (j *SinkJournal) Record(analyzer, hash string, seq uint64) error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.last[analyzer] = hash
+	j.seq = max(j.seq, seq)
+
+	_, err := fmt.Fprintf(j.file, "%s %s %d\n", analyzer, hash, seq)
+	if err != nil {
+		return fmt.Errorf("write sink journal: %w", err)
+	}
+
+	return nil
+}
+
+// LastCommits returns the last emitted commit per analyzer, for
+// StreamingState.SinkLastCommits.
+func (j *SinkJournal) LastCommits() map[string]string {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return maps.Clone(j.last)
+}
+
+// LastSeq returns the highest sequence number emitted, for
+// StreamingState.SinkSeq and for numbering the records of a resumed run.
+func (j *SinkJournal) LastSeq() uint64 {
+	if j == nil {
+		return 0
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.seq
+}
+
+// Reset empties the journal file after a checkpoint has been saved. The
+// checkpoint now covers all records written so far.
+func (j *SinkJournal) Reset() error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	err := j.file.Truncate(0)
+	if err != nil {
+		return fmt.Errorf("reset sink journal: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the journal file. Safe to call multiple times.
+func (j *SinkJournal) Close() error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+
+	if err != nil {
+		return fmt.Errorf("close sink journal: %w", err)
+	}
+
+	return nil
+}
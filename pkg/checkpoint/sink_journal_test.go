@@ -0,0 +1,111 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSinkJournal_ResumeSkipsRecordsWrittenAfterCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(t.TempDir(), "abc123")
+
+	j, err := OpenSinkJournal(m.SinkJournalPath(), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, j.Record("devs", "c1", 1))
+	require.NoError(t, j.Record("quality", "c1", 2))
+
+	state := &StreamingState{SinkLastCommits: j.LastCommits(), SinkSeq: j.LastSeq()}
+	require.NoError(t, j.Reset())
+
+	// Chunk 2 is partially written when the run is interrupted.
+	require.NoError(t, j.Record("devs", "c2", 3))
+	require.NoError(t, j.Record("devs", "c3", 4))
+	require.NoError(t, j.Close())
+
+	resumed, err := OpenSinkJournal(m.SinkJournalPath(), state)
+	require.NoError(t, err)
+
+	defer resumed.Close()
+
+	assert.True(t, resumed.Skip("devs", "c2"))
+	assert.True(t, resumed.Skip("devs", "c3"))
+	assert.False(t, resumed.Skip("devs", "c4"))
+	assert.False(t, resumed.Skip("quality", "c2"))
+
+	assert.Equal(t, map[string]string{"devs": "c3", "quality": "c1"}, resumed.LastCommits())
+	assert.Equal(t, uint64(4), resumed.LastSeq())
+}
+
+func TestSinkJournal_StaleEntriesBeforeResetAreIgnored(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cp", sinkJournalFile)
+
+	// Interrupted between saving the checkpoint and resetting the journal.
+	j, err := OpenSinkJournal(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, j.Record("devs", "c1", 7))
+	require.NoError(t, j.Close())
+
+	resumed, err := OpenSinkJournal(path, &StreamingState{SinkLastCommits: map[string]string{"devs": "c1"}, SinkSeq: 7})
+	require.NoError(t, err)
+
+	defer resumed.Close()
+
+	assert.False(t, resumed.Skip("devs", "c2"))
+	assert.Equal(t, uint64(7), resumed.LastSeq())
+}
+
+func TestSinkJournal_ResetThenAppend(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), sinkJournalFile)
+
+	j, err := OpenSinkJournal(path, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, j.Record("devs", "c1", 1))
+	require.NoError(t, j.Reset())
+	require.NoError(t, j.Record("devs", "c2", 2))
+	require.NoError(t, j.Close())
+	require.NoError(t, j.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "devs c2 2\n", string(data))
+}
+
+func TestSinkJournal_TornLineIgnored(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), sinkJournalFile)
+	require.NoError(t, os.WriteFile(path, []byte("devs c1 1\ndevs c2"), 0o600))
+
+	j, err := OpenSinkJournal(path, &StreamingState{})
+	require.NoError(t, err)
+
+	defer j.Close()
+
+	assert.True(t, j.Skip("devs", "c1"))
+	assert.False(t, j.Skip("devs", "c2"))
+	assert.Equal(t, uint64(1), j.LastSeq())
+}
+
+func TestSinkJournal_NilReceiver(t *testing.T) {
+	t.Parallel()
+
+	var j *SinkJournal
+
+	assert.False(t, j.Skip("devs", "c1"))
+	require.NoError(t, j.Record("devs", "c1", 1))
+	assert.Nil(t, j.LastCommits())
+	assert.Zero(t, j.LastSeq())
+	require.NoError(t, j.Reset())
+	require.NoError(t, j.Close())
+}
@@ -8,6 +8,10 @@ type AggregatorSpillEntry struct {
 
 	// Count is the number of spill files in Dir.
 	Count int `json:"count,omitempty"`
+
+	// Codec names the codec compressing the spill files. Empty means
+	// uncompressed.
+	Codec string `json:"codec,omitempty"`
 }
 
 // StreamingState tracks chunk orchestrator progress.
@@ -23,6 +27,15 @@ type StreamingState struct {
 	// Indexed by analyzer position in the Runner.Analyzers slice.
 	// Nil entries mean the analyzer has no aggregator (plumbing, file_history).
 	AggregatorSpills []AggregatorSpillEntry `json:"aggregator_spills,omitempty"`
+
+	// SinkLastCommits records, per analyzer flag, the hash of the last commit
+	// emitted to the streaming output sink (NDJSON) at checkpoint time.
+	// See SinkJournal.
+	SinkLastCommits map[string]string `json:"sink_last_commits,omitempty"`
+
+	// SinkSeq is the sequence number of the last record emitted to the
+	// streaming output sink at checkpoint time.
+	SinkSeq uint64 `json:"sink_seq,omitempty"`
 }
 
 // Metadata holds checkpoint metadata for validation and resume.
@@ -0,0 +1,270 @@
+// Package codec provides the pluggable stream compression used for gob-encoded
+// TCs and aggregator spill files.
+//
+// A codec is chosen per run by name. Every codec wraps plain io.Writer and
+// io.Reader streams, so the same codec serves spill files on disk and
+// connections between processes. Any codec can be combined with encryption
+// at rest; see [Encrypted].
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
+)
+
+// Codec names.
+const (
+	// None writes streams uncompressed.
+	None = "none"
+	// Zstd compresses with zstd at its fastest level, trading some ratio for
+	// the throughput spills need.
+	Zstd = "zstd"
+	// Snappy compresses with the Snappy framing format. It compresses less
+	// than zstd but costs almost no CPU.
+	Snappy = "snappy"
+)
+
+// EncryptedSuffix marks the name of an encrypted codec, as in "zstd+aes-gcm".
+const EncryptedSuffix = "+aes-gcm"
+
+// ErrUnknownCodec is returned by Parse for an unsupported codec name.
+var ErrUnknownCodec = errors.New("unknown codec")
+
+// Codec compresses and decompresses byte streams.
+type Codec interface {
+	// Name returns the codec name accepted by Parse.
+	Name() string
+
+	// NewWriter returns a writer compressing into w. Close flushes the
+	// compressed stream but does not close w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+
+	// NewReader returns a reader decompressing from r. Close releases the
+	// decoder but does not close r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var codecs = map[string]Codec{
+	None:   noneCodec{},
+	Zstd:   zstdCodec{},
+	Snappy: snappyCodec{},
+}
+
+// Names returns the supported codec names in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Parse returns the codec with the given name. An empty name selects None.
+// A name ending in EncryptedSuffix selects the encrypted variant of the codec,
+// keyed from the environment as described in [crypt.KeyFromEnv].
+func Parse(name string) (Codec, error) {
+	if name == "" {
+		return noneCodec{}, nil
+	}
+
+	if base, found := strings.CutSuffix(strings.ToLower(name), EncryptedSuffix); found {
+		c, err := Parse(base)
+		if err != nil {
+			return nil, err
+		}
+
+		cipher, err := crypt.FromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("codec %q: %w", name, err)
+		}
+
+		return Encrypted(c, cipher), nil
+	}
+
+	c, ok := codecs[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q (valid: %s)", ErrUnknownCodec, name, strings.Join(Names(), ", "))
+	}
+
+	return c, nil
+}
+
+// OrNone returns c, or the None codec when c is nil, so zero-value options
+// keep writing uncompressed streams.
+func OrNone(c Codec) Codec {
+	if c == nil {
+		return noneCodec{}
+	}
+
+	return c
+}
+
+// NameOf returns the name of c, or "" for a nil codec.
+func NameOf(c Codec) string {
+	if c == nil {
+		return ""
+	}
+
+	return c.Name()
+}
+
+// Marshal gob-encodes v and compresses the result with c.
+func Marshal(c Codec, v any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := OrNone(c).NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gob.NewEncoder(w).Encode(v)
+	if err != nil {
+		w.Close()
+
+		return nil, fmt.Errorf("codec: encode: %w", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, fmt.Errorf("codec: flush: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decompresses data with c and gob-decodes it into v.
+func Unmarshal(c Codec, data []byte, v any) error {
+	r, err := OrNone(c).NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	err = gob.NewDecoder(r).Decode(v)
+	if err != nil {
+		return fmt.Errorf("codec: decode: %w", err)
+	}
+
+	return nil
+}
+
+// Encrypted returns a codec that compresses with c and encrypts the compressed
+// stream with cipher. Its name is the name of c followed by EncryptedSuffix.
+func Encrypted(c Codec, cipher *crypt.Cipher) Codec {
+	return encryptedCodec{inner: OrNone(c), cipher: cipher}
+}
+
+type encryptedCodec struct {
+	inner  Codec
+	cipher *crypt.Cipher
+}
+
+func (c encryptedCodec) Name() string { return c.inner.Name() + EncryptedSuffix }
+
+func (c encryptedCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	ew, err := c.cipher.NewWriter(w)
+	if err != nil {
+		return nil, err
+	}
+
+	cw, err := c.inner.NewWriter(ew)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainWriter{WriteCloser: cw, outer: ew}, nil
+}
+
+func (c encryptedCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	er, err := c.cipher.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("codec: %w", err)
+	}
+
+	cr, err := c.inner.NewReader(er)
+	if err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+// chainWriter closes the codec writer, then the encrypting writer it feeds.
+type chainWriter struct {
+	io.WriteCloser
+
+	outer io.Closer
+}
+
+func (w chainWriter) Close() error {
+	return errors.Join(w.WriteCloser.Close(), w.outer.Close())
+}
+
+// noneCodec buffers writes but leaves the bytes untouched.
+type noneCodec struct{}
+
+func (noneCodec) Name() string { return None }
+
+func (noneCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return flushCloser{bufio.NewWriter(w)}, nil
+}
+
+func (noneCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(bufio.NewReader(r)), nil
+}
+
+// flushCloser flushes a bufio.Writer on Close.
+type flushCloser struct {
+	*bufio.Writer
+}
+
+func (f flushCloser) Close() error { return f.Flush() }
+
+type zstdCodec struct{}
+
+func (zstdCodec) Name() string { return Zstd }
+
+func (zstdCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	enc, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, fmt.Errorf("codec: zstd writer: %w", err)
+	}
+
+	return enc, nil
+}
+
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, fmt.Errorf("codec: zstd reader: %w", err)
+	}
+
+	return dec.IOReadCloser(), nil
+}
+
+type snappyCodec struct{}
+
+func (snappyCodec) Name() string { return Snappy }
+
+func (snappyCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return s2.NewWriter(w, s2.WriterSnappyCompat(), s2.WriterConcurrency(1)), nil
+}
+
+func (snappyCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(s2.NewReader(r)), nil
+}
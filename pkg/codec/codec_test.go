@@ -0,0 +1,233 @@
+package codec_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
+)
+
+// tickState resembles the per-tick state the aggregators spill: small
+// structs keyed by developer and file, with many repeated strings.
+type tickState struct {
+	Tick     int
+	Commits  map[int]int
+	Files    map[string]int
+	Language []string
+}
+
+func sampleStates(n int) []tickState {
+	states := make([]tickState, n)
+
+	for i := range states {
+		st := tickState{
+			Tick:    i,
+			Commits: make(map[int]int),
+			Files:   make(map[string]int),
+		}
+
+		for dev := range 20 {
+			st.Commits[dev] = i*dev + 1
+			st.Files[fmt.Sprintf("pkg/analyzers/module_%02d/file_%03d.go", dev, i%50)] = dev
+			st.Language = append(st.Language, "Go")
+		}
+
+		states[i] = st
+	}
+
+	return states
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"", "none", "zstd", "snappy", "ZSTD"} {
+		c, err := codec.Parse(name)
+		require.NoError(t, err, name)
+		assert.NotNil(t, c)
+	}
+
+	_, err := codec.Parse("brotli")
+	require.ErrorIs(t, err, codec.ErrUnknownCodec)
+	assert.Contains(t, err.Error(), "none, snappy, zstd")
+}
+
+func TestNames(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{codec.None, codec.Snappy, codec.Zstd}, codec.Names())
+}
+
+func TestOrNone(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, codec.None, codec.OrNone(nil).Name())
+	assert.Empty(t, codec.NameOf(nil))
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	states := sampleStates(100)
+
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := codec.Parse(name)
+			require.NoError(t, err)
+
+			data, err := codec.Marshal(c, states)
+			require.NoError(t, err)
+
+			var got []tickState
+
+			require.NoError(t, codec.Unmarshal(c, data, &got))
+			assert.Equal(t, states, got)
+		})
+	}
+}
+
+func TestCompressionShrinksRepetitiveData(t *testing.T) {
+	t.Parallel()
+
+	states := sampleStates(100)
+
+	plain, err := codec.Marshal(nil, states)
+	require.NoError(t, err)
+
+	for _, name := range []string{codec.Zstd, codec.Snappy} {
+		c, err := codec.Parse(name)
+		require.NoError(t, err)
+
+		data, err := codec.Marshal(c, states)
+		require.NoError(t, err)
+		assert.Less(t, len(data), len(plain)/2, name)
+	}
+}
+
+func TestStreamWriterDoesNotCloseUnderlying(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range codec.Names() {
+		c, err := codec.Parse(name)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		w, err := c.NewWriter(&buf)
+		require.NoError(t, err)
+
+		_, err = io.WriteString(w, "first frame")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		r, err := c.NewReader(&buf)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		assert.Equal(t, "first frame", string(got), name)
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	states := sampleStates(500)
+
+	for _, name := range codec.Names() {
+		c, err := codec.Parse(name)
+		require.NoError(b, err)
+
+		b.Run(name, func(b *testing.B) {
+			var size int
+
+			for b.Loop() {
+				data, marshalErr := codec.Marshal(c, states)
+				if marshalErr != nil {
+					b.Fatal(marshalErr)
+				}
+
+				size = len(data)
+			}
+
+			b.ReportMetric(float64(size), "bytes")
+		})
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	states := sampleStates(500)
+
+	for _, name := range codec.Names() {
+		c, err := codec.Parse(name)
+		require.NoError(b, err)
+
+		data, err := codec.Marshal(c, states)
+		require.NoError(b, err)
+
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				var got []tickState
+
+				unmarshalErr := codec.Unmarshal(c, data, &got)
+				if unmarshalErr != nil {
+					b.Fatal(unmarshalErr)
+				}
+			}
+		})
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	t.Parallel()
+
+	cipher, err := crypt.NewCipher(bytes.Repeat([]byte{3}, crypt.KeySize))
+	require.NoError(t, err)
+
+	states := sampleStates(10)
+
+	for _, name := range codec.Names() {
+		inner, err := codec.Parse(name)
+		require.NoError(t, err)
+
+		c := codec.Encrypted(inner, cipher)
+		assert.Equal(t, name+codec.EncryptedSuffix, c.Name())
+
+		data, err := codec.Marshal(c, states)
+		require.NoError(t, err, name)
+		assert.NotContains(t, string(data), "pkg/", name)
+
+		var got []tickState
+
+		require.NoError(t, codec.Unmarshal(c, data, &got), name)
+		assert.Equal(t, states, got, name)
+
+		require.ErrorIs(t, codec.Unmarshal(c, []byte("plain"), &got), crypt.ErrNotEncrypted, name)
+		require.Error(t, codec.Unmarshal(inner, data, &got), name)
+	}
+}
+
+func TestParse_Encrypted(t *testing.T) {
+	t.Setenv(crypt.EnvKeyCommand, "")
+	t.Setenv(crypt.EnvKey, "")
+
+	_, err := codec.Parse("zstd" + codec.EncryptedSuffix)
+	require.ErrorIs(t, err, crypt.ErrNoKey)
+
+	t.Setenv(crypt.EnvKey, strings.Repeat("ab", crypt.KeySize))
+
+	c, err := codec.Parse("zstd" + codec.EncryptedSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, "zstd+aes-gcm", c.Name())
+
+	_, err = codec.Parse("brotli" + codec.EncryptedSuffix)
+	require.ErrorIs(t, err, codec.ErrUnknownCodec)
+}
@@ -0,0 +1,565 @@
+package codefang
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/anomaly"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/arch"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/burndown"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/codeowners"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/cohesion"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/comments"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/commitsize"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/complexity"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/couples"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/deadcode"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/defects"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/devs"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/dora"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/effort"
+	filehistory "github.com/Sumatoshi-tech/codefang/pkg/analyzers/file_history"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/halstead"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/imports"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/lifecycle"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/policy"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/quality"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/reverts"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/sensitive"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/sentiment"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/shotness"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/signing"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/typos"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/workhours"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// ErrUnknownAnalyzer indicates a requested analyzer is not in the pipeline.
+var ErrUnknownAnalyzer = errors.New("unknown analyzer")
+
+// ErrDuplicateAnalyzer indicates a custom leaf reuses the key or the ID of
+// another leaf.
+var ErrDuplicateAnalyzer = errors.New("duplicate analyzer")
+
+// Pipeline holds the plumbing (core) analyzers and the leaf analyzers of the
+// history pipeline, keyed by their pipeline key ("burndown", "devs", ...).
+type Pipeline struct {
+	Core   []analyze.HistoryAnalyzer
+	Leaves map[string]analyze.HistoryAnalyzer
+
+	// CommitTable keeps the line stats, language, message language and
+	// trailers analyzers in Core after Configure, for the commit table.
+	CommitTable bool
+
+	// custom holds the keys of the leaves added with AddLeaf, in order.
+	custom []string
+}
+
+// AddPlumbing appends custom plumbing analyzers to Core, after the built-in
+// ones, so they consume every commit before the leaves and can feed them
+// per-commit facts, such as the issue keys of the commit message. Add an
+// analyzer after the plumbing it reads, wired with [CorePlumbing], and wire
+// it into the leaves that read it through an exported pointer field, as
+// BuildPipeline does: Configure keeps it only when a selected leaf depends
+// on it.
+func (pl *Pipeline) AddPlumbing(analyzers ...analyze.HistoryAnalyzer) {
+	pl.Core = append(pl.Core, analyzers...)
+}
+
+// AddLeaf adds a custom leaf analyzer under its Flag. It is selected by its
+// descriptor ID, like the built-in leaves.
+func (pl *Pipeline) AddLeaf(leaf analyze.HistoryAnalyzer) error {
+	key := leaf.Flag()
+	if _, exists := pl.Leaves[key]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateAnalyzer, key)
+	}
+
+	id := leaf.Descriptor().ID
+	for _, other := range pl.Leaves {
+		if other.Descriptor().ID == id {
+			return fmt.Errorf("%w: %s", ErrDuplicateAnalyzer, id)
+		}
+	}
+
+	pl.Leaves[key] = leaf
+	pl.custom = append(pl.custom, key)
+
+	return nil
+}
+
+// CorePlumbing returns the first core analyzer of type T, such as
+// *plumbing.TreeDiffAnalyzer, to wire custom analyzers to it.
+func CorePlumbing[T analyze.HistoryAnalyzer](pl *Pipeline) (T, bool) {
+	for _, a := range pl.Core {
+		if typed, ok := a.(T); ok {
+			return typed, true
+		}
+	}
+
+	var zero T
+
+	return zero, false
+}
+
+// Analyzers returns the core analyzers followed by all leaves.
+func (pl *Pipeline) Analyzers() []analyze.HistoryAnalyzer {
+	all := make([]analyze.HistoryAnalyzer, 0, len(pl.Core)+len(pl.Leaves))
+	all = append(all, pl.Core...)
+
+	for _, leaf := range pl.Leaves {
+		all = append(all, leaf)
+	}
+
+	return all
+}
+
+// BuildPipeline wires every core and leaf analyzer to the repository. Pass a
+// nil repository to inspect analyzer metadata only.
+func BuildPipeline(repository *gitlib.Repository) *Pipeline { //nolint:funlen // Expected length for pipeline initialization.
+	treeDiff := &plumbing.TreeDiffAnalyzer{Repository: repository}
+	identity := &plumbing.IdentityDetector{}
+	ticks := &plumbing.TicksSinceStart{}
+	blobCache := &plumbing.BlobCacheAnalyzer{TreeDiff: treeDiff, Repository: repository}
+	fileDiff := &plumbing.FileDiffAnalyzer{BlobCache: blobCache, TreeDiff: treeDiff}
+	lineStats := &plumbing.LinesStatsCalculator{TreeDiff: treeDiff, BlobCache: blobCache, FileDiff: fileDiff}
+	langDetect := &plumbing.LanguagesDetectionAnalyzer{TreeDiff: treeDiff, BlobCache: blobCache}
+	uastChanges := &plumbing.UASTChangesAnalyzer{TreeDiff: treeDiff, BlobCache: blobCache}
+	msgLang := &plumbing.MessageLanguageDetector{}
+	trailers := &plumbing.TrailersExtractor{}
+
+	return &Pipeline{
+		Core: []analyze.HistoryAnalyzer{
+			treeDiff, identity, ticks, blobCache, fileDiff, lineStats, langDetect, uastChanges, msgLang, trailers,
+		},
+		Leaves: map[string]analyze.HistoryAnalyzer{
+			"anomaly": func() *anomaly.Analyzer {
+				a := anomaly.NewAnalyzer()
+				a.TreeDiff = treeDiff
+				a.Ticks = ticks
+				a.LineStats = lineStats
+				a.Languages = langDetect
+				a.Identity = identity
+
+				return a
+			}(),
+			"arch": func() *arch.HistoryAnalyzer {
+				a := arch.NewHistoryAnalyzer()
+				a.TreeDiff = treeDiff
+				a.BlobCache = blobCache
+				a.Identity = identity
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"burndown": func() *burndown.HistoryAnalyzer {
+				a := burndown.NewHistoryAnalyzer()
+				a.BlobCache = blobCache
+				a.Ticks = ticks
+				a.Identity = identity
+				a.FileDiff = fileDiff
+				a.TreeDiff = treeDiff
+				a.UAST = uastChanges
+
+				return a
+			}(),
+			"codeowners": func() *codeowners.Analyzer {
+				a := codeowners.NewAnalyzer()
+				a.TreeDiff = treeDiff
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"cohesion": func() *cohesion.HistoryAnalyzer {
+				a := cohesion.NewHistoryAnalyzer()
+				a.TreeDiff = treeDiff
+				a.BlobCache = blobCache
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"comments": func() *comments.HistoryAnalyzer {
+				a := comments.NewHistoryAnalyzer()
+				a.TreeDiff = treeDiff
+				a.BlobCache = blobCache
+				a.Identity = identity
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"commitsize": func() *commitsize.Analyzer {
+				a := commitsize.NewAnalyzer()
+				a.TreeDiff = treeDiff
+				a.LineStats = lineStats
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"complexity": func() *complexity.HistoryAnalyzer {
+				a := complexity.NewHistoryAnalyzer()
+				a.UAST = uastChanges
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"couples": func() *couples.HistoryAnalyzer {
+				a := couples.NewHistoryAnalyzer()
+				a.Identity = identity
+				a.TreeDiff = treeDiff
+
+				return a
+			}(),
+			"deadcode": func() *deadcode.HistoryAnalyzer {
+				a := deadcode.NewHistoryAnalyzer()
+				a.TreeDiff = treeDiff
+				a.BlobCache = blobCache
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"defects": func() *defects.Analyzer {
+				a := defects.NewAnalyzer()
+				a.TreeDiff = treeDiff
+				a.LineStats = lineStats
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"devs": func() *devs.Analyzer {
+				a := devs.NewAnalyzer()
+				a.Identity = identity
+				a.TreeDiff = treeDiff
+				a.Ticks = ticks
+				a.Languages = langDetect
+				a.LineStats = lineStats
+
+				return a
+			}(),
+			"dora": func() *dora.Analyzer {
+				a := dora.NewAnalyzer()
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"effort": func() *effort.Analyzer {
+				a := effort.NewAnalyzer()
+				a.Languages = langDetect
+				a.LineStats = lineStats
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"file-history": func() *filehistory.HistoryAnalyzer {
+				a := filehistory.NewAnalyzer()
+				a.Identity = identity
+				a.TreeDiff = treeDiff
+				a.LineStats = lineStats
+
+				return a
+			}(),
+			"halstead": func() *halstead.HistoryAnalyzer {
+				a := halstead.NewHistoryAnalyzer()
+				a.UAST = uastChanges
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"imports": func() *imports.HistoryAnalyzer {
+				a := imports.NewHistoryAnalyzer()
+				a.TreeDiff = treeDiff
+				a.BlobCache = blobCache
+				a.Identity = identity
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"lifecycle": func() *lifecycle.Analyzer {
+				a := lifecycle.NewAnalyzer()
+				a.Identity = identity
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"policy": func() *policy.Analyzer {
+				a := policy.NewAnalyzer()
+				a.TreeDiff = treeDiff
+				a.BlobCache = blobCache
+				a.FileDiff = fileDiff
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"quality": func() *quality.Analyzer {
+				a := quality.NewAnalyzer()
+				a.UAST = uastChanges
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"reverts": func() *reverts.Analyzer {
+				a := reverts.NewAnalyzer()
+				a.TreeDiff = treeDiff
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"sensitive": func() *sensitive.Analyzer {
+				a := sensitive.NewAnalyzer()
+				a.TreeDiff = treeDiff
+				a.LineStats = lineStats
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"sentiment": func() *sentiment.Analyzer {
+				a := sentiment.NewAnalyzer()
+				a.UAST = uastChanges
+				a.Ticks = ticks
+				a.MessageLanguage = msgLang
+
+				return a
+			}(),
+			"shotness": func() *shotness.Analyzer {
+				a := shotness.NewAnalyzer()
+				a.FileDiff = fileDiff
+				a.UAST = uastChanges
+
+				return a
+			}(),
+			"signing": func() *signing.Analyzer {
+				a := signing.NewAnalyzer()
+				a.TreeDiff = treeDiff
+				a.Ticks = ticks
+
+				return a
+			}(),
+			"typos": func() *typos.Analyzer {
+				a := typos.NewAnalyzer()
+				a.UAST = uastChanges
+				a.BlobCache = blobCache
+				a.FileDiff = fileDiff
+				a.MessageLanguage = msgLang
+
+				return a
+			}(),
+			"workhours": func() *workhours.Analyzer {
+				a := workhours.NewAnalyzer()
+				a.Identity = identity
+				a.Ticks = ticks
+
+				return a
+			}(),
+		},
+	}
+}
+
+// HistoryLeaves returns unwired instances of all leaf analyzers in registry order.
+func HistoryLeaves() []analyze.HistoryAnalyzer {
+	leaves := BuildPipeline(nil).Leaves
+
+	return []analyze.HistoryAnalyzer{
+		leaves["anomaly"],
+		leaves["arch"],
+		leaves["burndown"],
+		leaves["codeowners"],
+		leaves["cohesion"],
+		leaves["comments"],
+		leaves["commitsize"],
+		leaves["complexity"],
+		leaves["couples"],
+		leaves["deadcode"],
+		leaves["defects"],
+		leaves["devs"],
+		leaves["dora"],
+		leaves["effort"],
+		leaves["file-history"],
+		leaves["halstead"],
+		leaves["imports"],
+		leaves["lifecycle"],
+		leaves["policy"],
+		leaves["quality"],
+		leaves["reverts"],
+		leaves["sensitive"],
+		leaves["sentiment"],
+		leaves["shotness"],
+		leaves["signing"],
+		leaves["typos"],
+		leaves["workhours"],
+	}
+}
+
+// registryLeaves returns the built-in leaves in registry order, followed by
+// the custom leaves in the order they were added.
+func (pl *Pipeline) registryLeaves() []analyze.HistoryAnalyzer {
+	leaves := HistoryLeaves()
+
+	for _, key := range pl.custom {
+		leaves = append(leaves, pl.Leaves[key])
+	}
+
+	return leaves
+}
+
+// Configure selects the leaves by keys, reduces Core to the analyzers they
+// depend on and configures both, with the default value of every
+// configuration option overridden by extraFacts in order. Core analyzers go
+// first so the facts they publish (e.g. FactCommitsByTick) reach the leaves.
+// Returns the selected leaves.
+func (pl *Pipeline) Configure(keys []string, extraFacts ...map[string]any) ([]analyze.HistoryAnalyzer, error) {
+	facts := buildFacts(pl)
+
+	for _, extra := range extraFacts {
+		maps.Copy(facts, extra)
+	}
+
+	selected, err := selectLeaves(pl.Leaves, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	pl.Core = pl.requiredCore(selected)
+
+	err = configureAnalyzers(pl.Core, facts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, leaf := range selected {
+		configErr := leaf.Configure(facts)
+		if configErr != nil {
+			return nil, fmt.Errorf("failed to configure %s: %w", keys[i], configErr)
+		}
+	}
+
+	return selected, nil
+}
+
+func selectLeaves(leaves map[string]analyze.HistoryAnalyzer, keys []string) ([]analyze.HistoryAnalyzer, error) {
+	selected := make([]analyze.HistoryAnalyzer, 0, len(keys))
+
+	for _, name := range keys {
+		leaf, found := leaves[name]
+		if !found {
+			return nil, fmt.Errorf("%w: %s\nAvailable: %s",
+				ErrUnknownAnalyzer, name, strings.Join(slices.Sorted(maps.Keys(leaves)), ", "))
+		}
+
+		selected = append(selected, leaf)
+	}
+
+	return selected, nil
+}
+
+// requiredCore returns the core analyzers, in order, that leaves depend on
+// directly or through other core analyzers. Ticks and identity are always
+// kept because the runner stamps every TC with them.
+func (pl *Pipeline) requiredCore(leaves []analyze.HistoryAnalyzer) []analyze.HistoryAnalyzer {
+	inCore := make(map[analyze.HistoryAnalyzer]bool, len(pl.Core))
+	for _, a := range pl.Core {
+		inCore[a] = true
+	}
+
+	required := make(map[analyze.HistoryAnalyzer]bool, len(pl.Core))
+
+	var require func(a analyze.HistoryAnalyzer)
+
+	require = func(a analyze.HistoryAnalyzer) {
+		for _, dep := range dependencies(a) {
+			if inCore[dep] && !required[dep] {
+				required[dep] = true
+				require(dep)
+			}
+		}
+	}
+
+	for _, a := range pl.Core {
+		if pl.alwaysKept(a) {
+			required[a] = true
+			require(a)
+		}
+	}
+
+	for _, leaf := range leaves {
+		require(leaf)
+	}
+
+	core := make([]analyze.HistoryAnalyzer, 0, len(required))
+
+	for _, a := range pl.Core {
+		if required[a] {
+			core = append(core, a)
+		}
+	}
+
+	return core
+}
+
+func (pl *Pipeline) alwaysKept(a analyze.HistoryAnalyzer) bool {
+	switch a.(type) {
+	case *plumbing.TicksSinceStart, *plumbing.IdentityDetector:
+		return true
+	case *plumbing.LinesStatsCalculator, *plumbing.LanguagesDetectionAnalyzer, *plumbing.MessageLanguageDetector,
+		*plumbing.TrailersExtractor:
+		return pl.CommitTable
+	default:
+		return false
+	}
+}
+
+// dependencies returns the analyzers a holds in its exported pointer fields,
+// which is how BuildPipeline wires plumbing into analyzers.
+func dependencies(a analyze.HistoryAnalyzer) []analyze.HistoryAnalyzer {
+	val := reflect.ValueOf(a)
+	if val.Kind() == reflect.Pointer {
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var deps []analyze.HistoryAnalyzer
+
+	for i := range val.NumField() {
+		field := val.Field(i)
+		if field.Kind() != reflect.Pointer || field.IsNil() || !field.CanInterface() {
+			continue
+		}
+
+		if dep, ok := field.Interface().(analyze.HistoryAnalyzer); ok {
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps
+}
+
+func buildFacts(pl *Pipeline) map[string]any {
+	facts := map[string]any{}
+
+	for _, a := range pl.Analyzers() {
+		for _, opt := range a.ListConfigurationOptions() {
+			if opt.Default != nil {
+				facts[opt.Name] = opt.Default
+			}
+		}
+	}
+
+	return facts
+}
+
+func configureAnalyzers(analyzers []analyze.HistoryAnalyzer, facts map[string]any) error {
+	for _, a := range analyzers {
+		err := a.Configure(facts)
+		if err != nil {
+			return fmt.Errorf("failed to configure %s: %w", a.Name(), err)
+		}
+	}
+
+	return nil
+}
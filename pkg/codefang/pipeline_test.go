@@ -0,0 +1,313 @@
+package codefang
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/effort"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func coreNames(core []analyze.HistoryAnalyzer) []string {
+	names := make([]string, 0, len(core))
+	for _, a := range core {
+		names = append(names, a.Name())
+	}
+
+	return names
+}
+
+func TestConfigure_KeepsOnlyRequiredCore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		keys        []string
+		commitTable bool
+		want        []string
+	}{
+		{keys: []string{"workhours"}, want: []string{"IdentityDetector", "TicksSinceStart"}},
+		{keys: []string{"couples"}, want: []string{"TreeDiff", "IdentityDetector", "TicksSinceStart"}},
+		{
+			keys: []string{"sentiment"},
+			want: []string{"TreeDiff", "IdentityDetector", "TicksSinceStart", "BlobCache", "UASTChanges", "MessageLanguageDetector"},
+		},
+		{
+			keys:        []string{"workhours"},
+			commitTable: true,
+			want: []string{
+				"TreeDiff", "IdentityDetector", "TicksSinceStart", "BlobCache", "FileDiff", "LinesStats", "LanguagesDetection",
+				"MessageLanguageDetector", "TrailersExtractor",
+			},
+		},
+		{
+			keys: []string{"burndown", "devs", "sentiment", "typos"},
+			want: []string{
+				"TreeDiff", "IdentityDetector", "TicksSinceStart", "BlobCache", "FileDiff", "LinesStats", "LanguagesDetection",
+				"UASTChanges", "MessageLanguageDetector",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		pl := BuildPipeline(nil)
+		pl.CommitTable = tt.commitTable
+
+		_, err := pl.Configure(tt.keys)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, coreNames(pl.Core), tt.keys)
+	}
+}
+
+// TestLeafCapabilities_CoverDependencies checks that every leaf declares the
+// coordinator stages the plumbing it reads needs, so that running it alone
+// does not skip them.
+func TestLeafCapabilities_CoverDependencies(t *testing.T) {
+	t.Parallel()
+
+	for key, leaf := range BuildPipeline(nil).Leaves {
+		caps := analyze.CapabilitiesOf(leaf)
+
+		for _, dep := range dependencies(leaf) {
+			switch dep.(type) {
+			case *plumbing.BlobCacheAnalyzer, *plumbing.LanguagesDetectionAnalyzer:
+				assert.True(t, caps.NeedsBlobs, "%s reads %s", key, dep.Name())
+			case *plumbing.FileDiffAnalyzer, *plumbing.LinesStatsCalculator:
+				assert.True(t, caps.NeedsDiffs, "%s reads %s", key, dep.Name())
+			case *plumbing.UASTChangesAnalyzer:
+				assert.True(t, caps.NeedsUAST, "%s reads %s", key, dep.Name())
+			}
+		}
+	}
+}
+
+func TestConfigure_UnknownKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := BuildPipeline(nil).Configure([]string{"nope"})
+	require.ErrorIs(t, err, ErrUnknownAnalyzer)
+}
+
+// TestSentimentRun_NeverTouchesFileDiff runs sentiment over the fixture
+// repository and checks that the FileDiff analyzer built by BuildPipeline
+// never consumed a commit.
+func TestSentimentRun_NeverTouchesFileDiff(t *testing.T) {
+	t.Parallel()
+
+	repo, err := gitlib.LoadRepository(filepath.Join("..", "..", "testdata", "fixture.git"))
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	pl := BuildPipeline(repo)
+
+	var fileDiff *plumbing.FileDiffAnalyzer
+
+	for _, a := range pl.Core {
+		if fd, ok := a.(*plumbing.FileDiffAnalyzer); ok {
+			fileDiff = fd
+		}
+	}
+
+	require.NotNil(t, fileDiff)
+
+	leaves, err := pl.Configure([]string{"sentiment"})
+	require.NoError(t, err)
+	assert.NotContains(t, pl.Core, analyze.HistoryAnalyzer(fileDiff))
+
+	commits, err := gitlib.LoadCommits(context.Background(), repo, gitlib.CommitLoadOptions{FirstParent: true})
+	require.NoError(t, err)
+
+	analyzers := append(append([]analyze.HistoryAnalyzer{}, pl.Core...), leaves...)
+
+	runner := framework.NewRunner(repo, repo.Path(), analyzers...)
+	runner.CoreCount = len(pl.Core)
+
+	reports, err := runner.Run(context.Background(), commits)
+	require.NoError(t, err)
+	assert.Contains(t, reports, leaves[0])
+
+	assert.Nil(t, fileDiff.FileDiffs, "FileDiff consumed a commit")
+}
+
+// commitStamp is a custom plumbing analyzer publishing the hash of the
+// commit it consumed last.
+type commitStamp struct {
+	analyze.BaseHistoryAnalyzer[any]
+
+	TreeDiff *plumbing.TreeDiffAnalyzer
+
+	Hash gitlib.Hash
+}
+
+func newCommitStamp(treeDiff *plumbing.TreeDiffAnalyzer) *commitStamp {
+	s := &commitStamp{TreeDiff: treeDiff}
+	s.Desc = analyze.Descriptor{ID: "plumbing/commit-stamp", Mode: analyze.ModeHistory}
+	s.Caps = &analyze.Capabilities{}
+
+	return s
+}
+
+func (s *commitStamp) Initialize(_ *gitlib.Repository) error { return nil }
+
+func (s *commitStamp) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	s.Hash = ac.Commit.Hash()
+
+	return analyze.TC{}, nil
+}
+
+func (s *commitStamp) NewAggregator(_ analyze.AggregatorOptions) analyze.Aggregator { return nil }
+
+func (s *commitStamp) Fork(n int) []analyze.HistoryAnalyzer {
+	forks := make([]analyze.HistoryAnalyzer, n)
+	for i := range forks {
+		forks[i] = newCommitStamp(s.TreeDiff)
+	}
+
+	return forks
+}
+
+func (s *commitStamp) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// stampChecker is a custom leaf reporting, per commit, whether the commit
+// stamp plumbing ran on the commit before it.
+type stampChecker struct {
+	analyze.BaseHistoryAnalyzer[any]
+
+	Stamp *commitStamp
+}
+
+func newStampChecker(stamp *commitStamp) *stampChecker {
+	c := &stampChecker{Stamp: stamp}
+	c.Desc = analyze.Descriptor{ID: "history/stamp-checker", Mode: analyze.ModeHistory}
+	c.Caps = &analyze.Capabilities{}
+
+	return c
+}
+
+func (c *stampChecker) Initialize(_ *gitlib.Repository) error { return nil }
+
+func (c *stampChecker) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	return analyze.TC{Data: c.Stamp.Hash == ac.Commit.Hash()}, nil
+}
+
+func (c *stampChecker) NewAggregator(_ analyze.AggregatorOptions) analyze.Aggregator { return nil }
+
+func (c *stampChecker) Fork(n int) []analyze.HistoryAnalyzer {
+	forks := make([]analyze.HistoryAnalyzer, n)
+	for i := range forks {
+		forks[i] = newStampChecker(c.Stamp)
+	}
+
+	return forks
+}
+
+func (c *stampChecker) Merge(_ []analyze.HistoryAnalyzer) {}
+
+// extendWithStamp adds the commit stamp plumbing and its checker leaf to pl.
+func extendWithStamp(pl *Pipeline) error {
+	treeDiff, ok := CorePlumbing[*plumbing.TreeDiffAnalyzer](pl)
+	if !ok {
+		return ErrUnknownAnalyzer
+	}
+
+	stamp := newCommitStamp(treeDiff)
+	pl.AddPlumbing(stamp)
+
+	return pl.AddLeaf(newStampChecker(stamp))
+}
+
+func TestConfigure_CustomPlumbing(t *testing.T) {
+	t.Parallel()
+
+	pl := BuildPipeline(nil)
+	require.NoError(t, extendWithStamp(pl))
+
+	_, err := pl.Configure([]string{"stamp-checker"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TreeDiff", "IdentityDetector", "TicksSinceStart", "plumbing/commit-stamp"}, coreNames(pl.Core))
+
+	unused := BuildPipeline(nil)
+	require.NoError(t, extendWithStamp(unused))
+
+	_, err = unused.Configure([]string{"workhours"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"IdentityDetector", "TicksSinceStart"}, coreNames(unused.Core),
+		"custom plumbing no selected leaf reads is dropped")
+}
+
+func TestAddLeaf_Duplicate(t *testing.T) {
+	t.Parallel()
+
+	pl := BuildPipeline(nil)
+	require.NoError(t, extendWithStamp(pl))
+
+	err := pl.AddLeaf(newStampChecker(nil))
+	require.ErrorIs(t, err, ErrDuplicateAnalyzer)
+
+	renamed := newStampChecker(nil)
+	renamed.Desc.ID = "custom/devs"
+
+	err = pl.AddLeaf(renamed)
+	require.ErrorIs(t, err, ErrDuplicateAnalyzer, "the key devs is taken")
+
+	_, err = pl.Configure([]string{"nope"})
+	require.ErrorContains(t, err, "stamp-checker")
+}
+
+// TestRun_CustomPlumbing runs a custom plumbing analyzer and a custom leaf
+// reading it over the fixture repository.
+func TestRun_CustomPlumbing(t *testing.T) {
+	t.Parallel()
+
+	var stamped, records int
+
+	results, err := Run(context.Background(), Options{
+		Path:      filepath.Join("..", "..", "testdata", "fixture.git"),
+		Analyzers: []string{"history/stamp-checker"},
+		Extend:    extendWithStamp,
+		OnRecord: func(r Record) {
+			records++
+
+			if r.Data.(bool) {
+				stamped++
+			}
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, results.Reports, "history/stamp-checker")
+	assert.Positive(t, records)
+	assert.Equal(t, records, stamped, "the plumbing consumed every commit before the leaf")
+}
+
+// TestRun_EffortAlone runs effort as the only leaf over the fixture
+// repository: the line stats it reads must still be computed.
+func TestRun_EffortAlone(t *testing.T) {
+	t.Parallel()
+
+	results, err := Run(context.Background(), Options{
+		Path:      filepath.Join("..", "..", "testdata", "fixture.git"),
+		Analyzers: []string{"history/effort"},
+	})
+	require.NoError(t, err)
+
+	ticks, ok := results.Reports["history/effort"][effort.KeyTicks].([]effort.TickLines)
+	require.True(t, ok)
+	require.NotEmpty(t, ticks)
+
+	var lines int
+
+	for _, tick := range ticks {
+		for _, n := range tick.Languages {
+			lines += n
+		}
+	}
+
+	assert.Positive(t, lines)
+}
@@ -0,0 +1,236 @@
+// Package codefang is the library entry point of codefang. It builds the
+// history pipeline and runs it in-process, so programs can analyze a
+// repository without spawning the CLI and parsing its output.
+package codefang
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/budget"
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// ErrNoAnalyzers is returned when Options select no history analyzer.
+var ErrNoAnalyzers = errors.New("no history analyzers selected")
+
+// ErrNoCommits is returned when the repository has no commits in range.
+var ErrNoCommits = errors.New("no commits to analyze")
+
+// Options configures Run.
+type Options struct {
+	// Path is the local repository path.
+	Path string
+	// Analyzers are history analyzer IDs or glob patterns, e.g.
+	// "history/devs" or "history/*". Empty selects every history analyzer,
+	// custom leaves included.
+	Analyzers []string
+	// Extend, when set, adds custom plumbing and leaf analyzers to the
+	// pipeline, with Pipeline.AddPlumbing and Pipeline.AddLeaf, before the
+	// analyzers are selected and configured.
+	Extend func(pl *Pipeline) error
+	// Limit caps the number of commits analyzed. Zero means no limit.
+	Limit int
+	// Since restricts analysis to commits after this time, in any format
+	// accepted by --since.
+	Since string
+	// From and To restrict analysis to the commits reachable from To, HEAD
+	// when empty, but not from From, e.g. two release tags.
+	From string
+	To   string
+	// FirstParent follows only the first parent of merge commits. It is
+	// forced on when history/burndown is selected.
+	FirstParent bool
+	// Workers is the number of parallel workers. Zero picks a default.
+	Workers int
+	// MemoryBudget is a human-readable budget such as "2GiB". Empty means no
+	// budget.
+	MemoryBudget string
+	// Facts are analyzer configuration values, keyed as the analyzers'
+	// ConfigurationOptions (the same keys as the CLI analyzer flags).
+	Facts map[string]any
+	// OnProgress, when set, is called after every chunk of commits.
+	OnProgress func(Progress)
+	// OnRecord, when set, receives every per-commit result of the selected
+	// analyzers as it is produced. Calls are serialized.
+	OnRecord func(Record)
+}
+
+// Progress describes how far a run has got.
+type Progress = framework.Progress
+
+// Record is one per-commit result of an analyzer.
+type Record struct {
+	// Analyzer is the analyzer ID, e.g. "history/devs".
+	Analyzer string
+	// Commit is the commit hash.
+	Commit string
+	// Tick is the time bucket of the commit.
+	Tick int
+	// AuthorID is the numeric identity of the commit author.
+	AuthorID int
+	// Timestamp is the commit's author time.
+	Timestamp time.Time
+	// Data is the analyzer-specific payload; its type is defined by the analyzer.
+	Data any
+}
+
+// Results holds the outcome of Run.
+type Results struct {
+	// Reports maps analyzer IDs to their final reports.
+	Reports map[string]analyze.Report
+	// Commits is the number of commits analyzed.
+	Commits int
+}
+
+// Run analyzes the history of the repository at opts.Path with the selected
+// history analyzers and returns their reports.
+func Run(ctx context.Context, opts Options) (Results, error) {
+	repository, err := gitlib.LoadRepository(opts.Path)
+	if err != nil {
+		return Results{}, fmt.Errorf("load repository %s: %w", opts.Path, err)
+	}
+	defer repository.Free()
+
+	pl := BuildPipeline(repository)
+
+	if opts.Extend != nil {
+		err = opts.Extend(pl)
+		if err != nil {
+			return Results{}, fmt.Errorf("extend pipeline: %w", err)
+		}
+	}
+
+	keys, err := selectKeys(pl, opts.Analyzers)
+	if err != nil {
+		return Results{}, err
+	}
+
+	leaves, err := pl.Configure(keys, opts.Facts)
+	if err != nil {
+		return Results{}, err
+	}
+
+	firstParent := opts.FirstParent || slices.Contains(keys, "burndown")
+
+	commits, err := gitlib.LoadCommits(ctx, repository, gitlib.CommitLoadOptions{
+		Limit:       opts.Limit,
+		FirstParent: firstParent,
+		Since:       opts.Since,
+		From:        opts.From,
+		To:          opts.To,
+	})
+	if err != nil {
+		return Results{}, err
+	}
+
+	if len(commits) == 0 {
+		return Results{}, ErrNoCommits
+	}
+
+	coordConfig, memBudget, err := framework.BuildConfigFromParams(framework.ConfigParams{
+		Workers:      opts.Workers,
+		MemoryBudget: opts.MemoryBudget,
+	}, budget.SolveForBudget)
+	if err != nil {
+		return Results{}, err
+	}
+
+	coordConfig.FirstParent = firstParent
+
+	analyzers := make([]analyze.HistoryAnalyzer, 0, len(pl.Core)+len(leaves))
+	analyzers = append(analyzers, pl.Core...)
+	analyzers = append(analyzers, leaves...)
+
+	runner := framework.NewRunnerWithConfig(repository, opts.Path, coordConfig, analyzers...)
+	runner.CoreCount = len(pl.Core)
+
+	reports, err := framework.RunStreaming(ctx, runner, commits, analyzers, framework.StreamingConfig{
+		MemBudget:     memBudget,
+		RepoPath:      opts.Path,
+		AnalyzerNames: keys,
+		TCObserver:    recordObserver(leaves, opts.OnRecord),
+		OnProgress:    opts.OnProgress,
+	})
+	if err != nil {
+		return Results{}, fmt.Errorf("run pipeline: %w", err)
+	}
+
+	results := Results{
+		Reports: make(map[string]analyze.Report, len(leaves)),
+		Commits: len(commits),
+	}
+
+	for _, leaf := range leaves {
+		results.Reports[leaf.Descriptor().ID] = reports[leaf]
+	}
+
+	return results, nil
+}
+
+// selectKeys resolves analyzer IDs and glob patterns to the keys of the
+// leaves of pl.
+func selectKeys(pl *Pipeline, patterns []string) ([]string, error) {
+	registry, err := analyze.NewRegistry(nil, pl.registryLeaves())
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := registry.SelectedIDs(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := analyze.HistoryKeysByID(pl.Leaves, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoAnalyzers
+	}
+
+	return keys, nil
+}
+
+// recordObserver adapts onRecord to a TC observer that reports the TCs of
+// leaves only, one at a time.
+func recordObserver(leaves []analyze.HistoryAnalyzer, onRecord func(Record)) analyze.TCSink {
+	if onRecord == nil {
+		return nil
+	}
+
+	ids := make(map[string]string, len(leaves))
+	for _, leaf := range leaves {
+		ids[leaf.Flag()] = leaf.Descriptor().ID
+	}
+
+	var mu sync.Mutex
+
+	return func(tc analyze.TC, flag string) error {
+		id, ok := ids[flag]
+		if !ok {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		onRecord(Record{
+			Analyzer:  id,
+			Commit:    tc.CommitHash.String(),
+			Tick:      tc.Tick,
+			AuthorID:  tc.AuthorID,
+			Timestamp: tc.Timestamp,
+			Data:      tc.Data,
+		})
+
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package codefang
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func TestSelectKeys(t *testing.T) {
+	t.Parallel()
+
+	pl := BuildPipeline(nil)
+
+	keys, err := selectKeys(pl, []string{"history/devs", "history/burndown"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"devs", "burndown"}, keys)
+
+	all, err := selectKeys(pl, nil)
+	require.NoError(t, err)
+	assert.Len(t, all, len(HistoryLeaves()))
+
+	_, err = selectKeys(pl, []string{"static/complexity"})
+	require.ErrorIs(t, err, analyze.ErrUnknownAnalyzerID)
+
+	require.NoError(t, extendWithStamp(pl))
+
+	keys, err = selectKeys(pl, []string{"history/stamp-*"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stamp-checker"}, keys)
+
+	all, err = selectKeys(pl, nil)
+	require.NoError(t, err)
+	assert.Len(t, all, len(HistoryLeaves())+1)
+}
+
+func TestRecordObserver(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, recordObserver(nil, nil))
+
+	pl := BuildPipeline(nil)
+	devs := pl.Leaves["devs"]
+
+	var records []Record
+
+	observe := recordObserver([]analyze.HistoryAnalyzer{devs}, func(r Record) { records = append(records, r) })
+
+	when := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	hash := gitlib.NewHash("abababababababababababababababababababab")
+
+	require.NoError(t, observe(analyze.TC{CommitHash: hash, Tick: 3, AuthorID: 2, Timestamp: when, Data: 1}, devs.Flag()))
+	require.NoError(t, observe(analyze.TC{CommitHash: hash, Data: 1}, pl.Core[0].Flag()))
+
+	assert.Equal(t, []Record{{
+		Analyzer:  devs.Descriptor().ID,
+		Commit:    hash.String(),
+		Tick:      3,
+		AuthorID:  2,
+		Timestamp: when,
+		Data:      1,
+	}}, records)
+}
+
+func TestRun_MissingRepository(t *testing.T) {
+	t.Parallel()
+
+	_, err := Run(context.Background(), Options{Path: t.TempDir(), Analyzers: []string{"history/devs"}})
+	require.Error(t, err)
+}
+
+func TestLeafCapabilities(t *testing.T) {
+	t.Parallel()
+
+	leaves := BuildPipeline(nil).Leaves
+
+	light := analyze.CombinedCapabilities([]analyze.HistoryAnalyzer{leaves["couples"], leaves["lifecycle"]})
+	assert.False(t, light.NeedsBlobs)
+	assert.False(t, light.NeedsDiffs)
+	assert.False(t, light.NeedsUAST)
+	assert.Equal(t, analyze.MemoryHigh, light.Memory)
+
+	sentiment := analyze.CapabilitiesOf(leaves["sentiment"])
+	assert.True(t, sentiment.NeedsUAST)
+	assert.False(t, sentiment.NeedsDiffs)
+
+	for key, leaf := range leaves {
+		_, declared := leaf.(analyze.CapabilityProvider)
+		assert.True(t, declared, key)
+		assert.NotEqual(t, analyze.FullCapabilities(), analyze.CapabilitiesOf(leaf), key)
+	}
+}
@@ -0,0 +1,218 @@
+// Package codeowners parses CODEOWNERS files and resolves the owners of the
+// files of a repository, and whether a commit author is one of them.
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Paths are the locations of the CODEOWNERS file in a repository, in the
+// order GitHub looks for it.
+var Paths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// rule assigns the files matching a pattern to their owners.
+type rule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// Ruleset is a parsed CODEOWNERS file.
+type Ruleset struct {
+	rules []rule
+}
+
+// Teams maps owners, teams or users, to the e-mail addresses or @logins of
+// their members. CODEOWNERS names teams and users but not who is in them,
+// so commit authors are matched against owners through it.
+type Teams map[string][]string
+
+// Parse parses a CODEOWNERS file. Lines whose pattern cannot be compiled are
+// skipped, as GitHub does.
+func Parse(data []byte) *Ruleset {
+	rs := &Ruleset{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		pattern, err := compilePattern(fields[0])
+		if err != nil {
+			continue
+		}
+
+		var owners []string
+
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "#") {
+				break
+			}
+
+			owners = append(owners, field)
+		}
+
+		rs.rules = append(rs.rules, rule{pattern: pattern, owners: owners})
+	}
+
+	return rs
+}
+
+// Len returns the number of rules.
+func (rs *Ruleset) Len() int {
+	return len(rs.rules)
+}
+
+// Owners returns the owners of file, from the last rule matching it. Files
+// no rule matches, and files of a rule without owners, are unowned.
+func (rs *Ruleset) Owners(file string) []string {
+	for i := len(rs.rules) - 1; i >= 0; i-- {
+		if rs.rules[i].pattern.MatchString(file) {
+			return rs.rules[i].owners
+		}
+	}
+
+	return nil
+}
+
+// IsOwner reports whether the author with the e-mail address email is one
+// of owners: the address itself, the user of a GitHub no-reply address, or
+// a member of an owning team or user.
+func (t Teams) IsOwner(owners []string, email string) bool {
+	for _, owner := range owners {
+		if t.isMember(owner, email, true) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Resolvable reports whether authors who are not among owners can be told
+// apart from owners: at least one owner is an e-mail address or has its
+// members listed. Users and teams without members are only recognized
+// through GitHub no-reply addresses.
+func (t Teams) Resolvable(owners []string) bool {
+	for _, owner := range owners {
+		if !strings.HasPrefix(owner, "@") || len(t[owner]) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t Teams) isMember(owner, email string, nested bool) bool {
+	if strings.EqualFold(owner, email) {
+		return true
+	}
+
+	if login, ok := noReplyLogin(email); ok && strings.EqualFold(owner, "@"+login) {
+		return true
+	}
+
+	if !nested {
+		return false
+	}
+
+	for _, member := range t[owner] {
+		if t.isMember(member, email, false) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// noReplyDomain is the domain of the private commit e-mail addresses of
+// GitHub, "ID+login@users.noreply.github.com".
+const noReplyDomain = "@users.noreply.github.com"
+
+// noReplyLogin returns the GitHub login of a no-reply e-mail address.
+func noReplyLogin(email string) (string, bool) {
+	local, ok := strings.CutSuffix(strings.ToLower(email), noReplyDomain)
+	if !ok || local == "" {
+		return "", false
+	}
+
+	if _, login, found := strings.Cut(local, "+"); found {
+		return login, login != ""
+	}
+
+	return local, true
+}
+
+// ParseTeams parses a YAML or JSON map of owners to their members.
+func ParseTeams(data []byte) (Teams, error) {
+	var teams Teams
+
+	err := yaml.Unmarshal(data, &teams)
+	if err != nil {
+		return nil, fmt.Errorf("parse teams: %w", err)
+	}
+
+	return teams, nil
+}
+
+// compilePattern turns a CODEOWNERS pattern into a regular expression over
+// slash-separated paths. Patterns follow the gitignore rules GitHub
+// documents: a pattern with a leading or inner slash is relative to the
+// repository root, other patterns match at any depth, a trailing slash
+// matches everything below a directory, and a pattern whose last part has
+// no wildcard matches a directory as well as a file.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var expr strings.Builder
+
+	expr.WriteString("^")
+
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	last := pattern[strings.LastIndex(pattern, "/")+1:]
+
+	switch {
+	case dirOnly:
+		expr.WriteString("/.*")
+	case !strings.ContainsAny(last, "*?"):
+		expr.WriteString("(?:/.*)?")
+	}
+
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("compile pattern %q: %w", pattern, err)
+	}
+
+	return re, nil
+}
@@ -0,0 +1,114 @@
+package codeowners_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/codeowners"
+)
+
+const testCodeOwners = `# Default owners of everything.
+*                 @acme/core
+
+*.js              @acme/web alice@acme.com
+/docs/            @acme/docs # Technical writers.
+apps/             @acme/apps
+/services/*       @acme/services
+**/logs           @acme/ops
+/services/billing/ @acme/payments @carol
+/services/billing/README.md
+`
+
+func TestRuleset_Owners(t *testing.T) {
+	t.Parallel()
+
+	rs := codeowners.Parse([]byte(testCodeOwners))
+	assert.Equal(t, 8, rs.Len())
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"main.go", []string{"@acme/core"}},
+		{"web/src/app.js", []string{"@acme/web", "alice@acme.com"}},
+		{"docs/guide.md", []string{"@acme/docs"}},
+		{"docs/api/index.md", []string{"@acme/docs"}},
+		{"pkg/docs/guide.md", []string{"@acme/core"}},
+		{"apps/cli/main.go", []string{"@acme/apps"}},
+		{"tools/apps/main.go", []string{"@acme/apps"}},
+		{"services/gateway.go", []string{"@acme/services"}},
+		{"services/gateway/main.go", []string{"@acme/core"}},
+		{"deploy/logs/rotate.sh", []string{"@acme/ops"}},
+		{"logs/app.log", []string{"@acme/ops"}},
+		{"services/billing/invoice.go", []string{"@acme/payments", "@carol"}},
+		{"services/billing/README.md", nil},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, rs.Owners(tt.file), tt.file)
+	}
+}
+
+func TestRuleset_OwnersUnmatched(t *testing.T) {
+	t.Parallel()
+
+	rs := codeowners.Parse([]byte("/docs/ @acme/docs\n"))
+	assert.Nil(t, rs.Owners("main.go"))
+	assert.Nil(t, codeowners.Parse(nil).Owners("main.go"))
+}
+
+func TestRuleset_IsOwner(t *testing.T) {
+	t.Parallel()
+
+	teams := codeowners.Teams{
+		"@acme/payments": {"dave@acme.com", "@erin"},
+		"@erin":          {"erin@acme.com"},
+	}
+
+	tests := []struct {
+		owners []string
+		email  string
+		want   bool
+	}{
+		{[]string{"alice@acme.com"}, "Alice@acme.com", true},
+		{[]string{"@carol"}, "1234+carol@users.noreply.github.com", true},
+		{[]string{"@carol"}, "carol@users.noreply.github.com", true},
+		{[]string{"@carol"}, "carol@acme.com", false},
+		{[]string{"@acme/core", "@acme/payments"}, "dave@acme.com", true},
+		{[]string{"@acme/payments"}, "erin@acme.com", false},
+		{[]string{"@acme/payments"}, "99+erin@users.noreply.github.com", true},
+		{[]string{"@erin"}, "erin@acme.com", true},
+		{[]string{"@acme/payments"}, "mallory@acme.com", false},
+		{nil, "dave@acme.com", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, teams.IsOwner(tt.owners, tt.email), "%v %s", tt.owners, tt.email)
+	}
+
+	assert.True(t, codeowners.Teams(nil).IsOwner([]string{"@carol"}, "carol@users.noreply.github.com"))
+}
+
+func TestTeams_Resolvable(t *testing.T) {
+	t.Parallel()
+
+	teams := codeowners.Teams{"@acme/payments": {"dave@acme.com"}, "@acme/empty": nil}
+
+	assert.True(t, teams.Resolvable([]string{"@acme/payments"}))
+	assert.True(t, teams.Resolvable([]string{"@acme/core", "alice@acme.com"}))
+	assert.False(t, teams.Resolvable([]string{"@acme/core", "@carol", "@acme/empty"}))
+	assert.False(t, teams.Resolvable(nil))
+}
+
+func TestParseTeams(t *testing.T) {
+	t.Parallel()
+
+	teams, err := codeowners.ParseTeams([]byte("\"@acme/web\": [alice@acme.com, \"@bob\"]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, codeowners.Teams{"@acme/web": {"alice@acme.com", "@bob"}}, teams)
+
+	_, err = codeowners.ParseTeams([]byte("- not a map"))
+	require.Error(t, err)
+}
@@ -20,6 +20,7 @@ const (
 	factBurndownGoroutines           = "Burndown.Goroutines"
 	factDevsConsiderEmpty            = "Devs.ConsiderEmptyCommits"
 	factDevsAnonymize                = "Devs.Anonymize"
+	factDevsGeography                = "Devs.Geography"
 	factImportsGoroutines            = "Imports.Goroutines"
 	factImportsMaxFileSize           = "Imports.MaxFileSize"
 	factSentimentMinLength           = "CommentSentiment.MinLength"
@@ -27,6 +28,38 @@ const (
 	factShotnessDSLStruct            = "Shotness.DSLStruct"
 	factShotnessDSLName              = "Shotness.DSLName"
 	factTyposMaxDistance             = "TyposDatasetBuilder.MaximumAllowedDistance"
+	factWorkHoursDayStart            = "WorkHours.DayStart"
+	factWorkHoursDayEnd              = "WorkHours.DayEnd"
+	factLifecycleInactiveDays        = "Lifecycle.InactiveDays"
+	factLifecycleCohortDays          = "Lifecycle.CohortDays"
+	factArchRules                    = "Arch.Rules"
+	factArchMaxFileSize              = "Arch.MaxFileSize"
+	factDeadCodeMaxFileSize          = "DeadCode.MaxFileSize"
+	factCommentsMaxFileSize          = "Comments.MaxFileSize"
+	factCohesionMaxFileSize          = "Cohesion.MaxFileSize"
+	factSensitivePatterns            = "Sensitive.Patterns"
+	factSensitiveMaxCommitLines      = "Sensitive.MaxCommitLines"
+	factSensitiveMaxTickChanges      = "Sensitive.MaxTickChanges"
+	factPolicyPacks                  = "Policy.Packs"
+	factPolicySuppressions           = "Policy.Suppressions"
+	factPolicyMinSeverity            = "Policy.MinSeverity"
+	factCommitSizeMegaLines          = "CommitSize.MegaLines"
+	factCommitSizeMegaFiles          = "CommitSize.MegaFiles"
+	factRevertsFixWindowDays         = "Reverts.FixWindowDays"
+	factRevertsModuleDepth           = "Reverts.ModuleDepth"
+	factRevertsFixPattern            = "Reverts.FixPattern"
+	factDefectsFixPattern            = "Defects.FixPattern"
+	factDefectsIssuePattern          = "Defects.IssuePattern"
+	factSigningProtectedPaths        = "Signing.ProtectedPaths"
+	factSigningAllowedSigners        = "Signing.AllowedSigners"
+	factSigningGPGProgram            = "Signing.GPGProgram"
+	factDoraReleasePattern           = "Dora.ReleasePattern"
+	factDoraHotfixPattern            = "Dora.HotfixPattern"
+	factDoraFailureWindowDays        = "Dora.FailureWindowDays"
+	factEffortModel                  = "Effort.Model"
+	factEffortAnnualWage             = "Effort.AnnualWage"
+	factEffortOverhead               = "Effort.Overhead"
+	factCodeOwnersTeams              = "CodeOwners.Teams"
 )
 
 func TestApplyToFacts_Burndown(t *testing.T) {
@@ -75,6 +108,7 @@ func TestApplyToFacts_Devs(t *testing.T) {
 			Devs: config.DevsConfig{
 				ConsiderEmptyCommits: true,
 				Anonymize:            true,
+				Geography:            true,
 			},
 		},
 	}
@@ -84,6 +118,7 @@ func TestApplyToFacts_Devs(t *testing.T) {
 
 	assert.Equal(t, true, facts[factDevsConsiderEmpty])
 	assert.Equal(t, true, facts[factDevsAnonymize])
+	assert.Equal(t, true, facts[factDevsGeography])
 }
 
 func TestApplyToFacts_Imports(t *testing.T) {
@@ -167,6 +202,260 @@ func TestApplyToFacts_Typos(t *testing.T) {
 	assert.Equal(t, expectedMaxDistance, facts[factTyposMaxDistance])
 }
 
+func TestApplyToFacts_WorkHours_MidnightStart(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			WorkHours: config.WorkHoursConfig{DayStart: 0, DayEnd: 8},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, 0, facts[factWorkHoursDayStart])
+	assert.Equal(t, 8, facts[factWorkHoursDayEnd])
+}
+
+func TestApplyToFacts_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Lifecycle: config.LifecycleConfig{InactiveDays: 60, CohortDays: 30},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, 60, facts[factLifecycleInactiveDays])
+	assert.Equal(t, 30, facts[factLifecycleCohortDays])
+}
+
+func TestApplyToFacts_Arch(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Arch: config.ArchConfig{Rules: "/etc/arch.yaml", MaxFileSize: 4096},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, "/etc/arch.yaml", facts[factArchRules])
+	assert.Equal(t, 4096, facts[factArchMaxFileSize])
+}
+
+func TestApplyToFacts_DeadCode(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			DeadCode: config.DeadCodeConfig{MaxFileSize: 4096},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, 4096, facts[factDeadCodeMaxFileSize])
+}
+
+func TestApplyToFacts_Comments(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Comments: config.CommentsConfig{MaxFileSize: 4096},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, 4096, facts[factCommentsMaxFileSize])
+}
+
+func TestApplyToFacts_Cohesion(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Cohesion: config.CohesionConfig{MaxFileSize: 4096},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, 4096, facts[factCohesionMaxFileSize])
+}
+
+func TestApplyToFacts_Sensitive(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Sensitive: config.SensitiveConfig{
+				Patterns:       []string{"auth", "*.pem"},
+				MaxCommitLines: 50,
+				MaxTickChanges: 5,
+			},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, []string{"auth", "*.pem"}, facts[factSensitivePatterns])
+	assert.Equal(t, 50, facts[factSensitiveMaxCommitLines])
+	assert.Equal(t, 5, facts[factSensitiveMaxTickChanges])
+}
+
+func TestApplyToFacts_Policy(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Policy: config.PolicyConfig{
+				Packs:        []string{"builtin", "policies/company.yaml"},
+				Suppressions: ".policy-ignore.yaml",
+				MinSeverity:  "high",
+			},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, []string{"builtin", "policies/company.yaml"}, facts[factPolicyPacks])
+	assert.Equal(t, ".policy-ignore.yaml", facts[factPolicySuppressions])
+	assert.Equal(t, "high", facts[factPolicyMinSeverity])
+}
+
+func TestApplyToFacts_CommitSize(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			CommitSize: config.CommitSizeConfig{MegaLines: 500, MegaFiles: 25},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, 500, facts[factCommitSizeMegaLines])
+	assert.Equal(t, 25, facts[factCommitSizeMegaFiles])
+}
+
+func TestApplyToFacts_Reverts(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Reverts: config.RevertsConfig{FixWindowDays: 30, ModuleDepth: 1, FixPattern: "^fix"},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, 30, facts[factRevertsFixWindowDays])
+	assert.Equal(t, 1, facts[factRevertsModuleDepth])
+	assert.Equal(t, "^fix", facts[factRevertsFixPattern])
+}
+
+func TestApplyToFacts_Defects(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Defects: config.DefectsConfig{FixPattern: "^fix", IssuePattern: "#[0-9]+"},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, "^fix", facts[factDefectsFixPattern])
+	assert.Equal(t, "#[0-9]+", facts[factDefectsIssuePattern])
+}
+
+func TestApplyToFacts_Signing(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Signing: config.SigningConfig{
+				ProtectedPaths: []string{"deploy/**"},
+				AllowedSigners: "/etc/ssh/allowed_signers",
+				GPGProgram:     "gpg2",
+			},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, []string{"deploy/**"}, facts[factSigningProtectedPaths])
+	assert.Equal(t, "/etc/ssh/allowed_signers", facts[factSigningAllowedSigners])
+	assert.Equal(t, "gpg2", facts[factSigningGPGProgram])
+}
+
+func TestApplyToFacts_Dora(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Dora: config.DoraConfig{ReleasePattern: "^release-", HotfixPattern: "^urgent", FailureWindowDays: 3},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, "^release-", facts[factDoraReleasePattern])
+	assert.Equal(t, "^urgent", facts[factDoraHotfixPattern])
+	assert.Equal(t, 3, facts[factDoraFailureWindowDays])
+}
+
+func TestApplyToFacts_Effort(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			Effort: config.EffortConfig{Model: "embedded", AnnualWage: 90000, Overhead: 1.8},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, "embedded", facts[factEffortModel])
+	assert.InDelta(t, 90000.0, facts[factEffortAnnualWage], 1e-9)
+	assert.InDelta(t, 1.8, facts[factEffortOverhead], 1e-9)
+}
+
+func TestApplyToFacts_CodeOwners(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		History: config.HistoryConfig{
+			CodeOwners: config.CodeOwnersConfig{Teams: "teams.yaml"},
+		},
+	}
+
+	facts := make(map[string]any)
+	cfg.ApplyToFacts(facts)
+
+	assert.Equal(t, "teams.yaml", facts[factCodeOwnersTeams])
+}
+
 func TestApplyToFacts_ZeroValues_SkipsNumericOverrides(t *testing.T) {
 	t.Parallel()
 
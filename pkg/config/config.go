@@ -1,6 +1,11 @@
 package config
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
 
 // Config is the top-level configuration struct for codefang.
 // Field tags use mapstructure for viper unmarshalling.
@@ -9,6 +14,7 @@ type Config struct {
 	Pipeline   PipelineConfig   `mapstructure:"pipeline"`
 	History    HistoryConfig    `mapstructure:"history"`
 	Checkpoint CheckpointConfig `mapstructure:"checkpoint"`
+	Output     OutputConfig     `mapstructure:"output"`
 }
 
 // PipelineConfig holds pipeline resource knobs.
@@ -27,13 +33,28 @@ type PipelineConfig struct {
 
 // HistoryConfig holds per-analyzer configuration for history analyzers.
 type HistoryConfig struct {
-	Burndown  BurndownConfig  `mapstructure:"burndown"`
-	Devs      DevsConfig      `mapstructure:"devs"`
-	Imports   ImportsConfig   `mapstructure:"imports"`
-	Sentiment SentimentConfig `mapstructure:"sentiment"`
-	Shotness  ShotnessConfig  `mapstructure:"shotness"`
-	Typos     TyposConfig     `mapstructure:"typos"`
-	Anomaly   AnomalyConfig   `mapstructure:"anomaly"`
+	Burndown   BurndownConfig   `mapstructure:"burndown"`
+	Devs       DevsConfig       `mapstructure:"devs"`
+	Imports    ImportsConfig    `mapstructure:"imports"`
+	Sentiment  SentimentConfig  `mapstructure:"sentiment"`
+	Shotness   ShotnessConfig   `mapstructure:"shotness"`
+	Typos      TyposConfig      `mapstructure:"typos"`
+	Anomaly    AnomalyConfig    `mapstructure:"anomaly"`
+	WorkHours  WorkHoursConfig  `mapstructure:"workhours"`
+	Lifecycle  LifecycleConfig  `mapstructure:"lifecycle"`
+	Arch       ArchConfig       `mapstructure:"arch"`
+	DeadCode   DeadCodeConfig   `mapstructure:"deadcode"`
+	Comments   CommentsConfig   `mapstructure:"comments"`
+	Cohesion   CohesionConfig   `mapstructure:"cohesion"`
+	Sensitive  SensitiveConfig  `mapstructure:"sensitive"`
+	Policy     PolicyConfig     `mapstructure:"policy"`
+	CommitSize CommitSizeConfig `mapstructure:"commitsize"`
+	Reverts    RevertsConfig    `mapstructure:"reverts"`
+	Defects    DefectsConfig    `mapstructure:"defects"`
+	Signing    SigningConfig    `mapstructure:"signing"`
+	Dora       DoraConfig       `mapstructure:"dora"`
+	Effort     EffortConfig     `mapstructure:"effort"`
+	CodeOwners CodeOwnersConfig `mapstructure:"codeowners"`
 }
 
 // AnomalyConfig holds temporal anomaly detection analyzer settings.
@@ -44,21 +65,23 @@ type AnomalyConfig struct {
 
 // BurndownConfig holds burndown analyzer settings.
 type BurndownConfig struct {
-	Granularity          int    `mapstructure:"granularity"`
-	Sampling             int    `mapstructure:"sampling"`
-	TrackFiles           bool   `mapstructure:"track_files"`
-	TrackPeople          bool   `mapstructure:"track_people"`
-	HibernationThreshold int    `mapstructure:"hibernation_threshold"`
-	HibernationToDisk    bool   `mapstructure:"hibernation_to_disk"`
-	HibernationDirectory string `mapstructure:"hibernation_directory"`
-	Debug                bool   `mapstructure:"debug"`
-	Goroutines           int    `mapstructure:"goroutines"`
+	Granularity           int    `mapstructure:"granularity"`
+	Sampling              int    `mapstructure:"sampling"`
+	TrackFiles            bool   `mapstructure:"track_files"`
+	TrackPeople           bool   `mapstructure:"track_people"`
+	HibernationThreshold  int    `mapstructure:"hibernation_threshold"`
+	HibernationToDisk     bool   `mapstructure:"hibernation_to_disk"`
+	HibernationDirectory  string `mapstructure:"hibernation_directory"`
+	HibernationColdChunks int    `mapstructure:"hibernation_cold_chunks"`
+	Debug                 bool   `mapstructure:"debug"`
+	Goroutines            int    `mapstructure:"goroutines"`
 }
 
 // DevsConfig holds devs analyzer settings.
 type DevsConfig struct {
 	ConsiderEmptyCommits bool `mapstructure:"consider_empty_commits"`
 	Anonymize            bool `mapstructure:"anonymize"`
+	Geography            bool `mapstructure:"geography"`
 }
 
 // ImportsConfig holds imports history analyzer settings.
@@ -84,6 +107,109 @@ type TyposConfig struct {
 	MaxDistance int `mapstructure:"max_distance"`
 }
 
+// WorkHoursConfig holds workhours analyzer settings.
+// Hours are in each author's local time; DayEnd is exclusive.
+type WorkHoursConfig struct {
+	DayStart int `mapstructure:"day_start"`
+	DayEnd   int `mapstructure:"day_end"`
+}
+
+// LifecycleConfig holds contributor lifecycle analyzer settings.
+type LifecycleConfig struct {
+	InactiveDays int `mapstructure:"inactive_days"`
+	CohortDays   int `mapstructure:"cohort_days"`
+}
+
+// ArchConfig holds architecture conformance analyzer settings. An empty
+// Rules uses the arch.yaml committed at HEAD.
+type ArchConfig struct {
+	Rules       string `mapstructure:"rules"`
+	MaxFileSize int    `mapstructure:"max_file_size"`
+}
+
+// DeadCodeConfig holds dead code analyzer settings.
+type DeadCodeConfig struct {
+	MaxFileSize int `mapstructure:"max_file_size"`
+}
+
+// CommentsConfig holds comments history analyzer settings.
+type CommentsConfig struct {
+	MaxFileSize int `mapstructure:"max_file_size"`
+}
+
+// CohesionConfig holds cohesion history analyzer settings.
+type CohesionConfig struct {
+	MaxFileSize int `mapstructure:"max_file_size"`
+}
+
+// SensitiveConfig holds security-sensitive change tracker settings. Empty
+// Patterns uses the analyzer's built-in patterns.
+type SensitiveConfig struct {
+	Patterns       []string `mapstructure:"patterns"`
+	MaxCommitLines int      `mapstructure:"max_commit_lines"`
+	MaxTickChanges int      `mapstructure:"max_tick_changes"`
+}
+
+// PolicyConfig holds regex policy scanner settings. Empty Packs uses the
+// built-in rules.
+type PolicyConfig struct {
+	Packs        []string `mapstructure:"packs"`
+	Suppressions string   `mapstructure:"suppressions"`
+	MinSeverity  string   `mapstructure:"min_severity"`
+}
+
+// CommitSizeConfig holds commit size analyzer settings.
+type CommitSizeConfig struct {
+	MegaLines int `mapstructure:"mega_lines"`
+	MegaFiles int `mapstructure:"mega_files"`
+}
+
+// RevertsConfig holds revert and fix-chain analyzer settings. Empty
+// FixPattern uses the built-in pattern.
+type RevertsConfig struct {
+	FixWindowDays int    `mapstructure:"fix_window_days"`
+	ModuleDepth   int    `mapstructure:"module_depth"`
+	FixPattern    string `mapstructure:"fix_pattern"`
+}
+
+// DefectsConfig holds defect prediction analyzer settings. Empty
+// FixPattern uses the built-in pattern; empty IssuePattern does not classify
+// commits by issue links.
+type DefectsConfig struct {
+	FixPattern   string `mapstructure:"fix_pattern"`
+	IssuePattern string `mapstructure:"issue_pattern"`
+}
+
+// SigningConfig holds commit signature verification analyzer settings. Empty
+// ProtectedPaths uses the sensitive analyzer's built-in patterns; empty
+// AllowedSigners and GPGProgram use the repository's git config.
+type SigningConfig struct {
+	ProtectedPaths []string `mapstructure:"protected_paths"`
+	AllowedSigners string   `mapstructure:"allowed_signers"`
+	GPGProgram     string   `mapstructure:"gpg_program"`
+}
+
+// DoraConfig holds DORA metrics analyzer settings. Empty ReleasePattern and
+// HotfixPattern use the built-in patterns.
+type DoraConfig struct {
+	ReleasePattern    string `mapstructure:"release_pattern"`
+	HotfixPattern     string `mapstructure:"hotfix_pattern"`
+	FailureWindowDays int    `mapstructure:"failure_window_days"`
+}
+
+// EffortConfig holds COCOMO effort estimation analyzer settings.
+type EffortConfig struct {
+	Model      string  `mapstructure:"model"`
+	AnnualWage float64 `mapstructure:"annual_wage"`
+	Overhead   float64 `mapstructure:"overhead"`
+}
+
+// CodeOwnersConfig holds CODEOWNERS analyzer settings. Teams is the path of
+// a YAML file mapping team handles to member emails or @logins.
+type CodeOwnersConfig struct {
+	Teams string `mapstructure:"teams"`
+}
+
 // CheckpointConfig holds checkpoint settings.
 type CheckpointConfig struct {
 	Enabled   bool   `mapstructure:"enabled"`
@@ -92,6 +218,23 @@ type CheckpointConfig struct {
 	ClearPrev bool   `mapstructure:"clear_prev"`
 }
 
+// OutputConfig lists the destinations of run output. Empty Sinks writes to
+// stdout only.
+type OutputConfig struct {
+	Sinks []OutputSinkConfig `mapstructure:"sinks"`
+}
+
+// OutputSinkConfig holds one output destination. Type is stdout, file or
+// webhook; file sinks need Path and webhook sinks need URL.
+type OutputSinkConfig struct {
+	Type      string            `mapstructure:"type"`
+	Path      string            `mapstructure:"path"`
+	URL       string            `mapstructure:"url"`
+	Headers   map[string]string `mapstructure:"headers"`
+	BatchSize int               `mapstructure:"batch_size"`
+	Timeout   string            `mapstructure:"timeout"`
+}
+
 // sentimentGapMax is the upper bound for the sentiment gap value.
 const sentimentGapMax = 1.0
 
@@ -119,10 +262,66 @@ var (
 	ErrInvalidImportsGoroutines = errors.New("history.imports.goroutines must be positive")
 	// ErrInvalidImportsMaxFileSize indicates the max file size is not positive.
 	ErrInvalidImportsMaxFileSize = errors.New("history.imports.max_file_size must be positive")
+	// ErrInvalidArchMaxFileSize indicates the max file size is not positive.
+	ErrInvalidArchMaxFileSize = errors.New("history.arch.max_file_size must be positive")
+	// ErrInvalidDeadCodeMaxFileSize indicates the max file size is not positive.
+	ErrInvalidDeadCodeMaxFileSize = errors.New("history.deadcode.max_file_size must be positive")
+	// ErrInvalidCommentsMaxFileSize indicates the max file size is not positive.
+	ErrInvalidCommentsMaxFileSize = errors.New("history.comments.max_file_size must be positive")
+	// ErrInvalidCohesionMaxFileSize indicates the max file size is not positive.
+	ErrInvalidCohesionMaxFileSize = errors.New("history.cohesion.max_file_size must be positive")
+	// ErrInvalidSensitiveMaxCommitLines indicates the commit line threshold is not positive.
+	ErrInvalidSensitiveMaxCommitLines = errors.New("history.sensitive.max_commit_lines must be positive")
+	// ErrInvalidSensitiveMaxTickChanges indicates the tick change threshold is not positive.
+	ErrInvalidSensitiveMaxTickChanges = errors.New("history.sensitive.max_tick_changes must be positive")
+	// ErrInvalidPolicyMinSeverity indicates an unknown policy severity.
+	ErrInvalidPolicyMinSeverity = errors.New("history.policy.min_severity must be low, medium, high or critical")
+	// ErrInvalidCommitSizeMegaLines indicates the mega-commit line threshold is not positive.
+	ErrInvalidCommitSizeMegaLines = errors.New("history.commitsize.mega_lines must be positive")
+	// ErrInvalidCommitSizeMegaFiles indicates the mega-commit file threshold is not positive.
+	ErrInvalidCommitSizeMegaFiles = errors.New("history.commitsize.mega_files must be positive")
+	// ErrInvalidRevertsFixWindowDays indicates the fix window is not positive.
+	ErrInvalidRevertsFixWindowDays = errors.New("history.reverts.fix_window_days must be positive")
+	// ErrInvalidRevertsModuleDepth indicates the module depth is not positive.
+	ErrInvalidRevertsModuleDepth = errors.New("history.reverts.module_depth must be positive")
+	// ErrInvalidRevertsFixPattern indicates the fix pattern is not a valid regular expression.
+	ErrInvalidRevertsFixPattern = errors.New("history.reverts.fix_pattern must be a valid regular expression")
+	// ErrInvalidDefectsFixPattern indicates the fix pattern is not a valid regular expression.
+	ErrInvalidDefectsFixPattern = errors.New("history.defects.fix_pattern must be a valid regular expression")
+	// ErrInvalidDefectsIssuePattern indicates the issue pattern is not a valid regular expression.
+	ErrInvalidDefectsIssuePattern = errors.New("history.defects.issue_pattern must be a valid regular expression")
+	// ErrInvalidDoraReleasePattern indicates the release pattern is not a valid regular expression.
+	ErrInvalidDoraReleasePattern = errors.New("history.dora.release_pattern must be a valid regular expression")
+	// ErrInvalidDoraHotfixPattern indicates the hotfix pattern is not a valid regular expression.
+	ErrInvalidDoraHotfixPattern = errors.New("history.dora.hotfix_pattern must be a valid regular expression")
+	// ErrInvalidDoraFailureWindowDays indicates the failure window is not positive.
+	ErrInvalidDoraFailureWindowDays = errors.New("history.dora.failure_window_days must be positive")
+	// ErrInvalidEffortModel indicates an unknown COCOMO model.
+	ErrInvalidEffortModel = errors.New("history.effort.model must be organic, semi-detached or embedded")
+	// ErrInvalidEffortAnnualWage indicates the annual wage is negative.
+	ErrInvalidEffortAnnualWage = errors.New("history.effort.annual_wage must be positive")
+	// ErrInvalidEffortOverhead indicates the overhead is negative.
+	ErrInvalidEffortOverhead = errors.New("history.effort.overhead must be positive")
 	// ErrInvalidAnomalyThreshold indicates the threshold is not positive.
 	ErrInvalidAnomalyThreshold = errors.New("history.anomaly.threshold must be positive")
 	// ErrInvalidAnomalyWindowSize indicates the window size is less than 2.
 	ErrInvalidAnomalyWindowSize = errors.New("history.anomaly.window_size must be at least 2")
+	// ErrInvalidWorkHours indicates the working day is empty or outside 0-24.
+	ErrInvalidWorkHours = errors.New("history.workhours requires 0 <= day_start < day_end <= 24")
+	// ErrInvalidLifecycleInactiveDays indicates the inactive days value is negative.
+	ErrInvalidLifecycleInactiveDays = errors.New("history.lifecycle.inactive_days must be positive")
+	// ErrInvalidLifecycleCohortDays indicates the cohort days value is negative.
+	ErrInvalidLifecycleCohortDays = errors.New("history.lifecycle.cohort_days must be positive")
+	// ErrInvalidOutputSinkType indicates an unknown output sink type.
+	ErrInvalidOutputSinkType = errors.New("output.sinks[].type must be stdout, file or webhook")
+	// ErrOutputSinkPathRequired indicates a file sink without a path.
+	ErrOutputSinkPathRequired = errors.New("output.sinks[].path is required for file sinks")
+	// ErrOutputSinkURLRequired indicates a webhook sink without a URL.
+	ErrOutputSinkURLRequired = errors.New("output.sinks[].url is required for webhook sinks")
+	// ErrInvalidOutputSinkBatchSize indicates a negative webhook batch size.
+	ErrInvalidOutputSinkBatchSize = errors.New("output.sinks[].batch_size must be non-negative")
+	// ErrInvalidOutputSinkTimeout indicates a webhook timeout that is not a positive duration.
+	ErrInvalidOutputSinkTimeout = errors.New("output.sinks[].timeout must be a positive duration")
 )
 
 // Validate checks Config invariants and returns the first error found.
@@ -132,7 +331,47 @@ func (c *Config) Validate() error {
 		return pipelineErr
 	}
 
-	return c.validateHistory()
+	historyErr := c.validateHistory()
+	if historyErr != nil {
+		return historyErr
+	}
+
+	archErr := c.validateArch()
+	if archErr != nil {
+		return archErr
+	}
+
+	securityErr := c.validateSecurity()
+	if securityErr != nil {
+		return securityErr
+	}
+
+	commitSizeErr := c.validateCommitSize()
+	if commitSizeErr != nil {
+		return commitSizeErr
+	}
+
+	revertsErr := c.validateReverts()
+	if revertsErr != nil {
+		return revertsErr
+	}
+
+	defectsErr := c.validateDefects()
+	if defectsErr != nil {
+		return defectsErr
+	}
+
+	doraErr := c.validateDora()
+	if doraErr != nil {
+		return doraErr
+	}
+
+	effortErr := c.validateEffort()
+	if effortErr != nil {
+		return effortErr
+	}
+
+	return c.validateOutput()
 }
 
 func (c *Config) validatePipeline() error {
@@ -192,8 +431,191 @@ func (c *Config) validateHistory() error {
 		return ErrInvalidAnomalyWindowSize
 	}
 
+	if c.History.Lifecycle.InactiveDays < 0 {
+		return ErrInvalidLifecycleInactiveDays
+	}
+
+	if c.History.Lifecycle.CohortDays < 0 {
+		return ErrInvalidLifecycleCohortDays
+	}
+
+	return c.validateWorkHours()
+}
+
+func (c *Config) validateArch() error {
+	if c.History.Arch.MaxFileSize < 0 {
+		return ErrInvalidArchMaxFileSize
+	}
+
+	if c.History.DeadCode.MaxFileSize < 0 {
+		return ErrInvalidDeadCodeMaxFileSize
+	}
+
+	if c.History.Comments.MaxFileSize < 0 {
+		return ErrInvalidCommentsMaxFileSize
+	}
+
+	if c.History.Cohesion.MaxFileSize < 0 {
+		return ErrInvalidCohesionMaxFileSize
+	}
+
+	return nil
+}
+
+func (c *Config) validateSecurity() error {
+	if c.History.Sensitive.MaxCommitLines < 0 {
+		return ErrInvalidSensitiveMaxCommitLines
+	}
+
+	if c.History.Sensitive.MaxTickChanges < 0 {
+		return ErrInvalidSensitiveMaxTickChanges
+	}
+
+	switch c.History.Policy.MinSeverity {
+	case "", "low", "medium", "high", "critical":
+	default:
+		return ErrInvalidPolicyMinSeverity
+	}
+
+	return nil
+}
+
+func (c *Config) validateCommitSize() error {
+	if c.History.CommitSize.MegaLines < 0 {
+		return ErrInvalidCommitSizeMegaLines
+	}
+
+	if c.History.CommitSize.MegaFiles < 0 {
+		return ErrInvalidCommitSizeMegaFiles
+	}
+
+	return nil
+}
+
+func (c *Config) validateReverts() error {
+	if c.History.Reverts.FixWindowDays < 0 {
+		return ErrInvalidRevertsFixWindowDays
+	}
+
+	if c.History.Reverts.ModuleDepth < 0 {
+		return ErrInvalidRevertsModuleDepth
+	}
+
+	_, err := regexp.Compile(c.History.Reverts.FixPattern)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRevertsFixPattern, err)
+	}
+
+	return nil
+}
+
+func (c *Config) validateDefects() error {
+	_, err := regexp.Compile(c.History.Defects.FixPattern)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDefectsFixPattern, err)
+	}
+
+	_, err = regexp.Compile(c.History.Defects.IssuePattern)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDefectsIssuePattern, err)
+	}
+
+	return nil
+}
+
+func (c *Config) validateDora() error {
+	_, err := regexp.Compile(c.History.Dora.ReleasePattern)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDoraReleasePattern, err)
+	}
+
+	_, err = regexp.Compile(c.History.Dora.HotfixPattern)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDoraHotfixPattern, err)
+	}
+
+	if c.History.Dora.FailureWindowDays < 0 {
+		return ErrInvalidDoraFailureWindowDays
+	}
+
+	return nil
+}
+
+func (c *Config) validateEffort() error {
+	switch c.History.Effort.Model {
+	case "", "organic", "semi-detached", "embedded":
+	default:
+		return ErrInvalidEffortModel
+	}
+
+	if c.History.Effort.AnnualWage < 0 {
+		return ErrInvalidEffortAnnualWage
+	}
+
+	if c.History.Effort.Overhead < 0 {
+		return ErrInvalidEffortOverhead
+	}
+
+	return nil
+}
+
+func (c *Config) validateOutput() error {
+	for i, sink := range c.Output.Sinks {
+		err := validateOutputSink(sink)
+		if err != nil {
+			return fmt.Errorf("output.sinks[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func validateOutputSink(sink OutputSinkConfig) error {
+	switch sink.Type {
+	case "stdout":
+	case "file":
+		if sink.Path == "" {
+			return ErrOutputSinkPathRequired
+		}
+	case "webhook":
+		if sink.URL == "" {
+			return ErrOutputSinkURLRequired
+		}
+	default:
+		return ErrInvalidOutputSinkType
+	}
+
+	if sink.BatchSize < 0 {
+		return ErrInvalidOutputSinkBatchSize
+	}
+
+	if sink.Timeout == "" {
+		return nil
+	}
+
+	timeout, err := time.ParseDuration(sink.Timeout)
+	if err != nil || timeout <= 0 {
+		return ErrInvalidOutputSinkTimeout
+	}
+
+	return nil
+}
+
+func (c *Config) validateWorkHours() error {
+	wh := c.History.WorkHours
+	if wh.DayStart == 0 && wh.DayEnd == 0 {
+		return nil
+	}
+
+	if wh.DayStart < 0 || wh.DayEnd > hoursPerDay || wh.DayStart >= wh.DayEnd {
+		return ErrInvalidWorkHours
+	}
+
 	return nil
 }
 
 // minAnomalyWindowSize is the minimum valid sliding window for anomaly detection.
 const minAnomalyWindowSize = 2
+
+// hoursPerDay bounds the workhours day_start/day_end settings.
+const hoursPerDay = 24
@@ -15,21 +15,23 @@ const (
 
 // Burndown analyzer defaults.
 const (
-	DefaultBurndownGranularity          = 30
-	DefaultBurndownSampling             = 30
-	DefaultBurndownTrackFiles           = false
-	DefaultBurndownTrackPeople          = false
-	DefaultBurndownHibernationThreshold = 1000
-	DefaultBurndownHibernationToDisk    = true
-	DefaultBurndownHibernationDirectory = ""
-	DefaultBurndownDebug                = false
-	DefaultBurndownGoroutines           = 0
+	DefaultBurndownGranularity           = 30
+	DefaultBurndownSampling              = 30
+	DefaultBurndownTrackFiles            = false
+	DefaultBurndownTrackPeople           = false
+	DefaultBurndownHibernationThreshold  = 1000
+	DefaultBurndownHibernationToDisk     = true
+	DefaultBurndownHibernationDirectory  = ""
+	DefaultBurndownHibernationColdChunks = 2
+	DefaultBurndownDebug                 = false
+	DefaultBurndownGoroutines            = 0
 )
 
 // Devs analyzer defaults.
 const (
 	DefaultDevsConsiderEmptyCommits = false
 	DefaultDevsAnonymize            = false
+	DefaultDevsGeography            = false
 )
 
 // Imports analyzer defaults.
@@ -61,6 +63,73 @@ const (
 	DefaultAnomalyWindowSize = 20
 )
 
+// WorkHours analyzer defaults.
+const (
+	DefaultWorkHoursDayStart = 9
+	DefaultWorkHoursDayEnd   = 18
+)
+
+// Lifecycle analyzer defaults.
+const (
+	DefaultLifecycleInactiveDays = 90
+	DefaultLifecycleCohortDays   = 90
+)
+
+// Arch analyzer defaults.
+const (
+	DefaultArchMaxFileSize = 1 << 20 // 1 MiB.
+)
+
+// DeadCode analyzer defaults.
+const (
+	DefaultDeadCodeMaxFileSize = 1 << 20 // 1 MiB.
+)
+
+// Comments analyzer defaults.
+const (
+	DefaultCommentsMaxFileSize = 1 << 20 // 1 MiB.
+)
+
+// Cohesion analyzer defaults.
+const (
+	DefaultCohesionMaxFileSize = 1 << 20 // 1 MiB.
+)
+
+// Sensitive analyzer defaults.
+const (
+	DefaultSensitiveMaxCommitLines = 200
+	DefaultSensitiveMaxTickChanges = 20
+)
+
+// Commit size analyzer defaults.
+const (
+	DefaultCommitSizeMegaLines = 1000
+	DefaultCommitSizeMegaFiles = 50
+)
+
+// Reverts analyzer defaults.
+const (
+	DefaultRevertsFixWindowDays = 14
+	DefaultRevertsModuleDepth   = 2
+)
+
+// DORA analyzer defaults.
+const (
+	DefaultDoraFailureWindowDays = 7
+)
+
+// Effort analyzer defaults.
+const (
+	DefaultEffortModel      = "organic"
+	DefaultEffortAnnualWage = 56286.0
+	DefaultEffortOverhead   = 2.4
+)
+
+// Policy analyzer defaults.
+const (
+	DefaultPolicyMinSeverity = "low"
+)
+
 // Checkpoint defaults.
 const (
 	DefaultCheckpointEnabled   = true
@@ -89,11 +89,13 @@ func applyDefaults(viperCfg *viper.Viper) {
 	viperCfg.SetDefault("history.burndown.hibernation_threshold", DefaultBurndownHibernationThreshold)
 	viperCfg.SetDefault("history.burndown.hibernation_to_disk", DefaultBurndownHibernationToDisk)
 	viperCfg.SetDefault("history.burndown.hibernation_directory", DefaultBurndownHibernationDirectory)
+	viperCfg.SetDefault("history.burndown.hibernation_cold_chunks", DefaultBurndownHibernationColdChunks)
 	viperCfg.SetDefault("history.burndown.debug", DefaultBurndownDebug)
 	viperCfg.SetDefault("history.burndown.goroutines", DefaultBurndownGoroutines)
 
 	viperCfg.SetDefault("history.devs.consider_empty_commits", DefaultDevsConsiderEmptyCommits)
 	viperCfg.SetDefault("history.devs.anonymize", DefaultDevsAnonymize)
+	viperCfg.SetDefault("history.devs.geography", DefaultDevsGeography)
 
 	viperCfg.SetDefault("history.imports.goroutines", DefaultImportsGoroutines)
 	viperCfg.SetDefault("history.imports.max_file_size", DefaultImportsMaxFileSize)
@@ -109,6 +111,28 @@ func applyDefaults(viperCfg *viper.Viper) {
 	viperCfg.SetDefault("history.anomaly.threshold", DefaultAnomalyThreshold)
 	viperCfg.SetDefault("history.anomaly.window_size", DefaultAnomalyWindowSize)
 
+	viperCfg.SetDefault("history.workhours.day_start", DefaultWorkHoursDayStart)
+	viperCfg.SetDefault("history.workhours.day_end", DefaultWorkHoursDayEnd)
+
+	viperCfg.SetDefault("history.lifecycle.inactive_days", DefaultLifecycleInactiveDays)
+	viperCfg.SetDefault("history.lifecycle.cohort_days", DefaultLifecycleCohortDays)
+
+	viperCfg.SetDefault("history.arch.max_file_size", DefaultArchMaxFileSize)
+	viperCfg.SetDefault("history.deadcode.max_file_size", DefaultDeadCodeMaxFileSize)
+	viperCfg.SetDefault("history.comments.max_file_size", DefaultCommentsMaxFileSize)
+	viperCfg.SetDefault("history.cohesion.max_file_size", DefaultCohesionMaxFileSize)
+	viperCfg.SetDefault("history.sensitive.max_commit_lines", DefaultSensitiveMaxCommitLines)
+	viperCfg.SetDefault("history.sensitive.max_tick_changes", DefaultSensitiveMaxTickChanges)
+	viperCfg.SetDefault("history.policy.min_severity", DefaultPolicyMinSeverity)
+	viperCfg.SetDefault("history.commitsize.mega_lines", DefaultCommitSizeMegaLines)
+	viperCfg.SetDefault("history.commitsize.mega_files", DefaultCommitSizeMegaFiles)
+	viperCfg.SetDefault("history.reverts.fix_window_days", DefaultRevertsFixWindowDays)
+	viperCfg.SetDefault("history.reverts.module_depth", DefaultRevertsModuleDepth)
+	viperCfg.SetDefault("history.dora.failure_window_days", DefaultDoraFailureWindowDays)
+	viperCfg.SetDefault("history.effort.model", DefaultEffortModel)
+	viperCfg.SetDefault("history.effort.annual_wage", DefaultEffortAnnualWage)
+	viperCfg.SetDefault("history.effort.overhead", DefaultEffortOverhead)
+
 	viperCfg.SetDefault("checkpoint.enabled", DefaultCheckpointEnabled)
 	viperCfg.SetDefault("checkpoint.dir", DefaultCheckpointDir)
 	viperCfg.SetDefault("checkpoint.resume", DefaultCheckpointResume)
@@ -126,6 +150,21 @@ func (c *Config) ApplyToFacts(facts map[string]any) {
 	c.applyShotnessFacts(facts)
 	c.applyTyposFacts(facts)
 	c.applyAnomalyFacts(facts)
+	c.applyWorkHoursFacts(facts)
+	c.applyLifecycleFacts(facts)
+	c.applyArchFacts(facts)
+	c.applyDeadCodeFacts(facts)
+	c.applyCommentsFacts(facts)
+	c.applyCohesionFacts(facts)
+	c.applySensitiveFacts(facts)
+	c.applyPolicyFacts(facts)
+	c.applyCommitSizeFacts(facts)
+	c.applyRevertsFacts(facts)
+	c.applyDefectsFacts(facts)
+	c.applySigningFacts(facts)
+	c.applyDoraFacts(facts)
+	c.applyEffortFacts(facts)
+	c.applyCodeOwnersFacts(facts)
 }
 
 func (c *Config) applyBurndownFacts(facts map[string]any) {
@@ -150,6 +189,8 @@ func (c *Config) applyBurndownFacts(facts map[string]any) {
 		facts["Burndown.HibernationDirectory"] = c.History.Burndown.HibernationDirectory
 	}
 
+	facts["Burndown.HibernationColdChunks"] = c.History.Burndown.HibernationColdChunks
+
 	facts["Burndown.Debug"] = c.History.Burndown.Debug
 
 	if c.History.Burndown.Goroutines > 0 {
@@ -160,6 +201,7 @@ func (c *Config) applyBurndownFacts(facts map[string]any) {
 func (c *Config) applyDevsFacts(facts map[string]any) {
 	facts["Devs.ConsiderEmptyCommits"] = c.History.Devs.ConsiderEmptyCommits
 	facts["Devs.Anonymize"] = c.History.Devs.Anonymize
+	facts["Devs.Geography"] = c.History.Devs.Geography
 }
 
 func (c *Config) applyImportsFacts(facts map[string]any) {
@@ -207,3 +249,160 @@ func (c *Config) applyAnomalyFacts(facts map[string]any) {
 		facts["TemporalAnomaly.WindowSize"] = c.History.Anomaly.WindowSize
 	}
 }
+
+// applyWorkHoursFacts sets both bounds together once day_end is configured,
+// so a midnight day_start (zero) is still honored.
+func (c *Config) applyWorkHoursFacts(facts map[string]any) {
+	if c.History.WorkHours.DayEnd > 0 {
+		facts["WorkHours.DayStart"] = c.History.WorkHours.DayStart
+		facts["WorkHours.DayEnd"] = c.History.WorkHours.DayEnd
+	}
+}
+
+func (c *Config) applyLifecycleFacts(facts map[string]any) {
+	if c.History.Lifecycle.InactiveDays > 0 {
+		facts["Lifecycle.InactiveDays"] = c.History.Lifecycle.InactiveDays
+	}
+
+	if c.History.Lifecycle.CohortDays > 0 {
+		facts["Lifecycle.CohortDays"] = c.History.Lifecycle.CohortDays
+	}
+}
+
+func (c *Config) applyArchFacts(facts map[string]any) {
+	if c.History.Arch.Rules != "" {
+		facts["Arch.Rules"] = c.History.Arch.Rules
+	}
+
+	if c.History.Arch.MaxFileSize > 0 {
+		facts["Arch.MaxFileSize"] = c.History.Arch.MaxFileSize
+	}
+}
+
+func (c *Config) applyDeadCodeFacts(facts map[string]any) {
+	if c.History.DeadCode.MaxFileSize > 0 {
+		facts["DeadCode.MaxFileSize"] = c.History.DeadCode.MaxFileSize
+	}
+}
+
+func (c *Config) applyCommentsFacts(facts map[string]any) {
+	if c.History.Comments.MaxFileSize > 0 {
+		facts["Comments.MaxFileSize"] = c.History.Comments.MaxFileSize
+	}
+}
+
+func (c *Config) applyCohesionFacts(facts map[string]any) {
+	if c.History.Cohesion.MaxFileSize > 0 {
+		facts["Cohesion.MaxFileSize"] = c.History.Cohesion.MaxFileSize
+	}
+}
+
+func (c *Config) applySensitiveFacts(facts map[string]any) {
+	if len(c.History.Sensitive.Patterns) > 0 {
+		facts["Sensitive.Patterns"] = c.History.Sensitive.Patterns
+	}
+
+	if c.History.Sensitive.MaxCommitLines > 0 {
+		facts["Sensitive.MaxCommitLines"] = c.History.Sensitive.MaxCommitLines
+	}
+
+	if c.History.Sensitive.MaxTickChanges > 0 {
+		facts["Sensitive.MaxTickChanges"] = c.History.Sensitive.MaxTickChanges
+	}
+}
+
+func (c *Config) applyPolicyFacts(facts map[string]any) {
+	if len(c.History.Policy.Packs) > 0 {
+		facts["Policy.Packs"] = c.History.Policy.Packs
+	}
+
+	if c.History.Policy.Suppressions != "" {
+		facts["Policy.Suppressions"] = c.History.Policy.Suppressions
+	}
+
+	if c.History.Policy.MinSeverity != "" {
+		facts["Policy.MinSeverity"] = c.History.Policy.MinSeverity
+	}
+}
+
+func (c *Config) applyCommitSizeFacts(facts map[string]any) {
+	if c.History.CommitSize.MegaLines > 0 {
+		facts["CommitSize.MegaLines"] = c.History.CommitSize.MegaLines
+	}
+
+	if c.History.CommitSize.MegaFiles > 0 {
+		facts["CommitSize.MegaFiles"] = c.History.CommitSize.MegaFiles
+	}
+}
+
+func (c *Config) applyRevertsFacts(facts map[string]any) {
+	if c.History.Reverts.FixWindowDays > 0 {
+		facts["Reverts.FixWindowDays"] = c.History.Reverts.FixWindowDays
+	}
+
+	if c.History.Reverts.ModuleDepth > 0 {
+		facts["Reverts.ModuleDepth"] = c.History.Reverts.ModuleDepth
+	}
+
+	if c.History.Reverts.FixPattern != "" {
+		facts["Reverts.FixPattern"] = c.History.Reverts.FixPattern
+	}
+}
+
+func (c *Config) applyDefectsFacts(facts map[string]any) {
+	if c.History.Defects.FixPattern != "" {
+		facts["Defects.FixPattern"] = c.History.Defects.FixPattern
+	}
+
+	if c.History.Defects.IssuePattern != "" {
+		facts["Defects.IssuePattern"] = c.History.Defects.IssuePattern
+	}
+}
+
+func (c *Config) applySigningFacts(facts map[string]any) {
+	if len(c.History.Signing.ProtectedPaths) > 0 {
+		facts["Signing.ProtectedPaths"] = c.History.Signing.ProtectedPaths
+	}
+
+	if c.History.Signing.AllowedSigners != "" {
+		facts["Signing.AllowedSigners"] = c.History.Signing.AllowedSigners
+	}
+
+	if c.History.Signing.GPGProgram != "" {
+		facts["Signing.GPGProgram"] = c.History.Signing.GPGProgram
+	}
+}
+
+func (c *Config) applyDoraFacts(facts map[string]any) {
+	if c.History.Dora.ReleasePattern != "" {
+		facts["Dora.ReleasePattern"] = c.History.Dora.ReleasePattern
+	}
+
+	if c.History.Dora.HotfixPattern != "" {
+		facts["Dora.HotfixPattern"] = c.History.Dora.HotfixPattern
+	}
+
+	if c.History.Dora.FailureWindowDays > 0 {
+		facts["Dora.FailureWindowDays"] = c.History.Dora.FailureWindowDays
+	}
+}
+
+func (c *Config) applyEffortFacts(facts map[string]any) {
+	if c.History.Effort.Model != "" {
+		facts["Effort.Model"] = c.History.Effort.Model
+	}
+
+	if c.History.Effort.AnnualWage > 0 {
+		facts["Effort.AnnualWage"] = c.History.Effort.AnnualWage
+	}
+
+	if c.History.Effort.Overhead > 0 {
+		facts["Effort.Overhead"] = c.History.Effort.Overhead
+	}
+}
+
+func (c *Config) applyCodeOwnersFacts(facts map[string]any) {
+	if c.History.CodeOwners.Teams != "" {
+		facts["CodeOwners.Teams"] = c.History.CodeOwners.Teams
+	}
+}
@@ -56,6 +56,7 @@ func TestLoadConfig_NoFile_UsesDefaults(t *testing.T) {
 	assert.Equal(t, config.DefaultBurndownHibernationThreshold, cfg.History.Burndown.HibernationThreshold)
 	assert.Equal(t, config.DefaultDevsConsiderEmptyCommits, cfg.History.Devs.ConsiderEmptyCommits)
 	assert.Equal(t, config.DefaultDevsAnonymize, cfg.History.Devs.Anonymize)
+	assert.Equal(t, config.DefaultDevsGeography, cfg.History.Devs.Geography)
 	assert.Equal(t, config.DefaultImportsGoroutines, cfg.History.Imports.Goroutines)
 	assert.Equal(t, config.DefaultImportsMaxFileSize, cfg.History.Imports.MaxFileSize)
 	assert.Equal(t, config.DefaultSentimentMinCommentLength, cfg.History.Sentiment.MinCommentLength)
@@ -63,6 +64,24 @@ func TestLoadConfig_NoFile_UsesDefaults(t *testing.T) {
 	assert.Equal(t, config.DefaultShotnessDSLStruct, cfg.History.Shotness.DSLStruct)
 	assert.Equal(t, config.DefaultShotnessDSLName, cfg.History.Shotness.DSLName)
 	assert.Equal(t, config.DefaultTyposMaxDistance, cfg.History.Typos.MaxDistance)
+	assert.Equal(t, config.DefaultWorkHoursDayStart, cfg.History.WorkHours.DayStart)
+	assert.Equal(t, config.DefaultWorkHoursDayEnd, cfg.History.WorkHours.DayEnd)
+	assert.Equal(t, config.DefaultLifecycleInactiveDays, cfg.History.Lifecycle.InactiveDays)
+	assert.Equal(t, config.DefaultLifecycleCohortDays, cfg.History.Lifecycle.CohortDays)
+	assert.Equal(t, config.DefaultArchMaxFileSize, cfg.History.Arch.MaxFileSize)
+	assert.Equal(t, config.DefaultDeadCodeMaxFileSize, cfg.History.DeadCode.MaxFileSize)
+	assert.Equal(t, config.DefaultCommentsMaxFileSize, cfg.History.Comments.MaxFileSize)
+	assert.Equal(t, config.DefaultCohesionMaxFileSize, cfg.History.Cohesion.MaxFileSize)
+	assert.Equal(t, config.DefaultSensitiveMaxCommitLines, cfg.History.Sensitive.MaxCommitLines)
+	assert.Equal(t, config.DefaultSensitiveMaxTickChanges, cfg.History.Sensitive.MaxTickChanges)
+	assert.Equal(t, config.DefaultPolicyMinSeverity, cfg.History.Policy.MinSeverity)
+	assert.Equal(t, config.DefaultCommitSizeMegaLines, cfg.History.CommitSize.MegaLines)
+	assert.Equal(t, config.DefaultCommitSizeMegaFiles, cfg.History.CommitSize.MegaFiles)
+	assert.Equal(t, config.DefaultRevertsFixWindowDays, cfg.History.Reverts.FixWindowDays)
+	assert.Equal(t, config.DefaultRevertsModuleDepth, cfg.History.Reverts.ModuleDepth)
+	assert.Equal(t, config.DefaultDoraFailureWindowDays, cfg.History.Dora.FailureWindowDays)
+	assert.Equal(t, config.DefaultEffortModel, cfg.History.Effort.Model)
+	assert.InDelta(t, config.DefaultEffortAnnualWage, cfg.History.Effort.AnnualWage, 1e-9)
 	assert.Equal(t, config.DefaultCheckpointEnabled, cfg.Checkpoint.Enabled)
 	assert.Equal(t, config.DefaultCheckpointResume, cfg.Checkpoint.Resume)
 }
@@ -278,3 +297,36 @@ func TestLoadConfig_ExplicitPath_NotFound_ReturnsError(t *testing.T) {
 	require.Error(t, err)
 	assert.Nil(t, cfg)
 }
+
+func TestLoadConfig_OutputSinks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".codefang.yaml")
+	content := `output:
+  sinks:
+    - type: stdout
+    - type: file
+      path: report.json
+    - type: webhook
+      url: https://example.com/hook
+      headers:
+        Authorization: Bearer token
+      batch_size: 50
+      timeout: 5s
+`
+	require.NoError(t, os.WriteFile(cfgPath, []byte(content), 0o600))
+
+	cfg, err := config.LoadConfig(cfgPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Output.Sinks, 3)
+
+	assert.Equal(t, "stdout", cfg.Output.Sinks[0].Type)
+	assert.Equal(t, "report.json", cfg.Output.Sinks[1].Path)
+
+	webhook := cfg.Output.Sinks[2]
+	assert.Equal(t, "https://example.com/hook", webhook.URL)
+	assert.Equal(t, "Bearer token", webhook.Headers["authorization"])
+	assert.Equal(t, 50, webhook.BatchSize)
+	assert.Equal(t, "5s", webhook.Timeout)
+}
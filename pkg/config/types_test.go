@@ -157,6 +157,26 @@ func TestValidate_InvalidTyposMaxDistance_ReturnsError(t *testing.T) {
 	assert.ErrorIs(t, err, config.ErrInvalidTyposMaxDistance)
 }
 
+func TestValidate_InvalidWorkHours_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.WorkHours = config.WorkHoursConfig{DayStart: 18, DayEnd: 9}
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidWorkHours)
+}
+
+func TestValidate_InvalidLifecycleInactiveDays_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.Lifecycle.InactiveDays = -1
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidLifecycleInactiveDays)
+}
+
 func TestValidate_InvalidImportsGoroutines_ReturnsError(t *testing.T) {
 	t.Parallel()
 
@@ -176,3 +196,200 @@ func TestValidate_InvalidImportsMaxFileSize_ReturnsError(t *testing.T) {
 	err := cfg.Validate()
 	assert.ErrorIs(t, err, config.ErrInvalidImportsMaxFileSize)
 }
+
+func TestValidate_InvalidArchMaxFileSize_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.Arch.MaxFileSize = -1
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidArchMaxFileSize)
+}
+
+func TestValidate_InvalidDeadCodeMaxFileSize_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.DeadCode.MaxFileSize = -1
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidDeadCodeMaxFileSize)
+}
+
+func TestValidate_InvalidCommentsMaxFileSize_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.Comments.MaxFileSize = -1
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidCommentsMaxFileSize)
+}
+
+func TestValidate_InvalidCohesionMaxFileSize_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.Cohesion.MaxFileSize = -1
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidCohesionMaxFileSize)
+}
+
+func TestValidate_InvalidSensitiveThresholds_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.Sensitive.MaxCommitLines = -1
+
+	err := cfg.Validate()
+	require.ErrorIs(t, err, config.ErrInvalidSensitiveMaxCommitLines)
+
+	cfg = validConfig()
+	cfg.History.Sensitive.MaxTickChanges = -1
+
+	err = cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidSensitiveMaxTickChanges)
+}
+
+func TestValidate_InvalidPolicyMinSeverity_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.Policy.MinSeverity = "urgent"
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidPolicyMinSeverity)
+}
+
+func TestValidate_InvalidCommitSizeThresholds_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.CommitSize.MegaLines = -1
+
+	err := cfg.Validate()
+	require.ErrorIs(t, err, config.ErrInvalidCommitSizeMegaLines)
+
+	cfg = validConfig()
+	cfg.History.CommitSize.MegaFiles = -1
+
+	err = cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidCommitSizeMegaFiles)
+}
+
+func TestValidate_InvalidReverts_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.Reverts.FixWindowDays = -1
+
+	err := cfg.Validate()
+	require.ErrorIs(t, err, config.ErrInvalidRevertsFixWindowDays)
+
+	cfg = validConfig()
+	cfg.History.Reverts.ModuleDepth = -1
+
+	err = cfg.Validate()
+	require.ErrorIs(t, err, config.ErrInvalidRevertsModuleDepth)
+
+	cfg = validConfig()
+	cfg.History.Reverts.FixPattern = "fix("
+
+	err = cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidRevertsFixPattern)
+}
+
+func TestValidate_InvalidDefectsPatterns_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.Defects.FixPattern = "fix("
+
+	err := cfg.Validate()
+	require.ErrorIs(t, err, config.ErrInvalidDefectsFixPattern)
+
+	cfg = validConfig()
+	cfg.History.Defects.IssuePattern = "[0-9"
+
+	err = cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidDefectsIssuePattern)
+}
+
+func TestValidate_OutputSinks(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.Output.Sinks = []config.OutputSinkConfig{
+		{Type: "stdout"},
+		{Type: "file", Path: "report.json"},
+		{Type: "webhook", URL: "https://example.com/hook", BatchSize: 100, Timeout: "10s"},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cases := []struct {
+		sink config.OutputSinkConfig
+		want error
+	}{
+		{config.OutputSinkConfig{Type: "kafka"}, config.ErrInvalidOutputSinkType},
+		{config.OutputSinkConfig{Type: "file"}, config.ErrOutputSinkPathRequired},
+		{config.OutputSinkConfig{Type: "webhook"}, config.ErrOutputSinkURLRequired},
+		{config.OutputSinkConfig{Type: "webhook", URL: "http://h", BatchSize: -1}, config.ErrInvalidOutputSinkBatchSize},
+		{config.OutputSinkConfig{Type: "webhook", URL: "http://h", Timeout: "soon"}, config.ErrInvalidOutputSinkTimeout},
+		{config.OutputSinkConfig{Type: "webhook", URL: "http://h", Timeout: "0s"}, config.ErrInvalidOutputSinkTimeout},
+	}
+
+	for _, tc := range cases {
+		cfg = validConfig()
+		cfg.Output.Sinks = []config.OutputSinkConfig{{Type: "stdout"}, tc.sink}
+
+		err := cfg.Validate()
+		require.ErrorIs(t, err, tc.want)
+		assert.Contains(t, err.Error(), "output.sinks[1]")
+	}
+}
+
+func TestValidate_InvalidDora_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.Dora.ReleasePattern = "v("
+
+	err := cfg.Validate()
+	require.ErrorIs(t, err, config.ErrInvalidDoraReleasePattern)
+
+	cfg = validConfig()
+	cfg.History.Dora.HotfixPattern = "hotfix("
+
+	err = cfg.Validate()
+	require.ErrorIs(t, err, config.ErrInvalidDoraHotfixPattern)
+
+	cfg = validConfig()
+	cfg.History.Dora.FailureWindowDays = -1
+
+	err = cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidDoraFailureWindowDays)
+}
+
+func TestValidate_InvalidEffort_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.History.Effort.Model = "agile"
+
+	err := cfg.Validate()
+	require.ErrorIs(t, err, config.ErrInvalidEffortModel)
+
+	cfg = validConfig()
+	cfg.History.Effort.AnnualWage = -1
+
+	err = cfg.Validate()
+	require.ErrorIs(t, err, config.ErrInvalidEffortAnnualWage)
+
+	cfg = validConfig()
+	cfg.History.Effort.Overhead = -1
+
+	err = cfg.Validate()
+	assert.ErrorIs(t, err, config.ErrInvalidEffortOverhead)
+}
@@ -0,0 +1,318 @@
+// Package crypt encrypts files at rest, such as checkpoints and aggregator
+// spills, with AES-256-GCM.
+//
+// Streams are split into segments of up to 64 KiB that are sealed one by one,
+// so files of any size are encrypted without being held in memory. Every
+// segment is authenticated on its own and the last one is marked as such, so
+// a reader detects tampering, reordering and truncation.
+//
+// The key comes from the environment; see [KeyFromEnv].
+package crypt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Environment variables the key is read from.
+const (
+	// EnvKey holds the 32-byte key, hex or base64 encoded.
+	EnvKey = "CODEFANG_ENCRYPTION_KEY"
+	// EnvKeyCommand holds a command printing the key, hex or base64 encoded,
+	// e.g. a KMS or secret manager CLI call. It is split on spaces and run
+	// without a shell.
+	EnvKeyCommand = "CODEFANG_ENCRYPTION_KEY_COMMAND"
+)
+
+// KeySize is the key length of AES-256.
+const KeySize = 32
+
+// Stream layout.
+const (
+	segmentSize    = 64 << 10
+	noncePrefixLen = 7
+	lengthLen      = 4
+	keyCommandWait = 30 * time.Second
+)
+
+// magic starts every encrypted stream; its last byte is the format version.
+var magic = []byte("CFAE\x01")
+
+var (
+	// ErrNoKey is returned by KeyFromEnv when no key is configured.
+	ErrNoKey = errors.New("no encryption key: set " + EnvKey + " or " + EnvKeyCommand)
+	// ErrInvalidKey is returned for a key that is not 32 bytes of hex or base64.
+	ErrInvalidKey = errors.New("invalid encryption key")
+	// ErrNotEncrypted is returned when a stream lacks the encrypted header.
+	ErrNotEncrypted = errors.New("stream is not encrypted")
+	// ErrCorrupt is returned when a segment fails authentication, which
+	// means a wrong key or a modified file.
+	ErrCorrupt = errors.New("encrypted stream corrupt or key wrong")
+	// ErrTruncated is returned when a stream ends before its last segment.
+	ErrTruncated = errors.New("encrypted stream truncated")
+)
+
+// Cipher encrypts and decrypts streams with one key. It is safe for
+// concurrent use.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a Cipher for a 32-byte key.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: %d bytes, want %d", ErrInvalidKey, len(key), KeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("crypt: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("crypt: %w", err)
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// FromEnv creates a Cipher for the key of [KeyFromEnv].
+func FromEnv() (*Cipher, error) {
+	key, err := KeyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCipher(key)
+}
+
+// KeyFromEnv returns the key in CODEFANG_ENCRYPTION_KEY or, when that is
+// unset, the key printed by CODEFANG_ENCRYPTION_KEY_COMMAND.
+func KeyFromEnv() ([]byte, error) {
+	if value := os.Getenv(EnvKey); value != "" {
+		return ParseKey(value)
+	}
+
+	command := strings.Fields(os.Getenv(EnvKeyCommand))
+	if len(command) == 0 {
+		return nil, ErrNoKey
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyCommandWait)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, command[0], command[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("run %s: %w", EnvKeyCommand, err)
+	}
+
+	return ParseKey(string(out))
+}
+
+// ParseKey decodes a hex or base64 encoded 32-byte key.
+func ParseKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+
+	if key, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: want %d bytes, hex or base64 encoded", ErrInvalidKey, KeySize)
+}
+
+// NewWriter returns a writer encrypting into w. Close seals the last segment
+// and must be called; it does not close w.
+func (c *Cipher) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	header := make([]byte, len(magic)+noncePrefixLen)
+	copy(header, magic)
+
+	_, err := rand.Read(header[len(magic):])
+	if err != nil {
+		return nil, fmt.Errorf("crypt: nonce: %w", err)
+	}
+
+	_, err = w.Write(header)
+	if err != nil {
+		return nil, fmt.Errorf("crypt: write header: %w", err)
+	}
+
+	return &writer{aead: c.aead, w: w, header: header}, nil
+}
+
+// NewReader returns a reader decrypting from r. Close does not close r.
+func (c *Cipher) NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(magic)+noncePrefixLen)
+
+	_, err := io.ReadFull(br, header)
+	if err != nil || !bytes.Equal(header[:len(magic)], magic) {
+		return nil, ErrNotEncrypted
+	}
+
+	return &reader{aead: c.aead, r: br, header: header}, nil
+}
+
+// IsEncrypted reports whether data starts with the header of an encrypted
+// stream.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// nonce builds the nonce of a segment: the random prefix of the stream, the
+// segment counter and a final-segment flag.
+func nonce(header []byte, counter uint32, last bool) []byte {
+	n := make([]byte, noncePrefixLen+lengthLen+1)
+	copy(n, header[len(magic):])
+	binary.BigEndian.PutUint32(n[noncePrefixLen:], counter)
+
+	if last {
+		n[len(n)-1] = 1
+	}
+
+	return n
+}
+
+type writer struct {
+	aead    cipher.AEAD
+	w       io.Writer
+	header  []byte
+	buf     []byte
+	counter uint32
+	closed  bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	// A full segment is only sealed once more data follows, so that the
+	// last segment can be sealed as last on Close.
+	for len(w.buf) > segmentSize {
+		err := w.seal(w.buf[:segmentSize], false)
+		if err != nil {
+			return 0, err
+		}
+
+		w.buf = w.buf[segmentSize:]
+	}
+
+	return len(p), nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+
+	return w.seal(w.buf, true)
+}
+
+func (w *writer) seal(plain []byte, last bool) error {
+	sealed := w.aead.Seal(make([]byte, lengthLen, lengthLen+len(plain)+w.aead.Overhead()),
+		nonce(w.header, w.counter, last), plain, w.header)
+	binary.BigEndian.PutUint32(sealed, uint32(len(sealed)-lengthLen)) //nolint:gosec // at most segmentSize plus overhead.
+
+	w.counter++
+
+	_, err := w.w.Write(sealed)
+	if err != nil {
+		return fmt.Errorf("crypt: write segment: %w", err)
+	}
+
+	return nil
+}
+
+type reader struct {
+	aead    cipher.AEAD
+	r       io.Reader
+	header  []byte
+	plain   []byte
+	counter uint32
+	done    bool
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		err := r.next()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+
+	return n, nil
+}
+
+// next opens the next segment. A segment that only opens as last must be
+// followed by the end of the stream.
+func (r *reader) next() error {
+	var length [lengthLen]byte
+
+	_, err := io.ReadFull(r.r, length[:])
+	if err != nil {
+		return ErrTruncated
+	}
+
+	size := binary.BigEndian.Uint32(length[:])
+	if size > segmentSize+uint32(r.aead.Overhead()) { //nolint:gosec // overhead is 16.
+		return ErrCorrupt
+	}
+
+	sealed := make([]byte, size)
+
+	_, err = io.ReadFull(r.r, sealed)
+	if err != nil {
+		return ErrTruncated
+	}
+
+	plain, err := r.aead.Open(nil, nonce(r.header, r.counter, false), sealed, r.header)
+	if err != nil {
+		plain, err = r.aead.Open(nil, nonce(r.header, r.counter, true), sealed, r.header)
+		if err != nil {
+			return ErrCorrupt
+		}
+
+		_, err = r.r.Read(length[:1])
+		if !errors.Is(err, io.EOF) {
+			return ErrCorrupt
+		}
+
+		r.done = true
+	}
+
+	r.counter++
+	r.plain = plain
+
+	return nil
+}
+
+func (r *reader) Close() error {
+	return nil
+}
@@ -0,0 +1,157 @@
+package crypt_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
+)
+
+func newTestCipher(t *testing.T) *crypt.Cipher {
+	t.Helper()
+
+	c, err := crypt.NewCipher(bytes.Repeat([]byte{7}, crypt.KeySize))
+	require.NoError(t, err)
+
+	return c
+}
+
+func encrypt(t *testing.T, c *crypt.Cipher, plain []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w, err := c.NewWriter(&buf)
+	require.NoError(t, err)
+
+	_, err = w.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func decrypt(c *crypt.Cipher, sealed []byte) ([]byte, error) {
+	r, err := c.NewReader(bytes.NewReader(sealed))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func TestCipher_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCipher(t)
+
+	large := make([]byte, 200<<10)
+	_, err := rand.Read(large)
+	require.NoError(t, err)
+
+	for name, plain := range map[string][]byte{
+		"empty":         {},
+		"small":         []byte("commit message with a secret"),
+		"one segment":   bytes.Repeat([]byte{1}, 64<<10),
+		"many segments": large,
+	} {
+		sealed := encrypt(t, c, plain)
+		assert.NotContains(t, string(sealed), "secret", name)
+
+		got, err := decrypt(c, sealed)
+		require.NoError(t, err, name)
+		assert.Equal(t, len(plain), len(got), name)
+		assert.True(t, bytes.Equal(plain, got), name)
+	}
+}
+
+func TestCipher_RandomNonce(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCipher(t)
+
+	assert.NotEqual(t, encrypt(t, c, []byte("same")), encrypt(t, c, []byte("same")))
+}
+
+func TestCipher_DetectsTampering(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCipher(t)
+	sealed := encrypt(t, c, bytes.Repeat([]byte("x"), 100<<10))
+
+	flipped := bytes.Clone(sealed)
+	flipped[len(flipped)-1] ^= 1
+
+	_, err := decrypt(c, flipped)
+	require.ErrorIs(t, err, crypt.ErrCorrupt)
+
+	_, err = decrypt(c, sealed[:len(sealed)-10])
+	require.ErrorIs(t, err, crypt.ErrTruncated)
+
+	// Dropping the last segment leaves a stream whose final segment is not
+	// marked as last.
+	_, err = decrypt(c, sealed[:12+4+64<<10+16])
+	require.ErrorIs(t, err, crypt.ErrTruncated)
+
+	_, err = decrypt(c, append(bytes.Clone(sealed), 0))
+	require.ErrorIs(t, err, crypt.ErrCorrupt)
+
+	other, err := crypt.NewCipher(bytes.Repeat([]byte{8}, crypt.KeySize))
+	require.NoError(t, err)
+
+	_, err = decrypt(other, sealed)
+	require.ErrorIs(t, err, crypt.ErrCorrupt)
+
+	_, err = decrypt(c, []byte("plain gob data"))
+	require.ErrorIs(t, err, crypt.ErrNotEncrypted)
+}
+
+func TestParseKey(t *testing.T) {
+	t.Parallel()
+
+	key := bytes.Repeat([]byte{0xab}, crypt.KeySize)
+
+	got, err := crypt.ParseKey(hex.EncodeToString(key))
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	got, err = crypt.ParseKey(base64.StdEncoding.EncodeToString(key) + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	_, err = crypt.ParseKey("abcd")
+	require.ErrorIs(t, err, crypt.ErrInvalidKey)
+
+	_, err = crypt.NewCipher([]byte("short"))
+	require.ErrorIs(t, err, crypt.ErrInvalidKey)
+}
+
+func TestKeyFromEnv(t *testing.T) {
+	key := hex.EncodeToString(bytes.Repeat([]byte{1}, crypt.KeySize))
+
+	t.Setenv(crypt.EnvKey, "")
+	t.Setenv(crypt.EnvKeyCommand, "")
+
+	_, err := crypt.KeyFromEnv()
+	require.ErrorIs(t, err, crypt.ErrNoKey)
+
+	t.Setenv(crypt.EnvKeyCommand, "echo "+key)
+
+	got, err := crypt.KeyFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, bytes.Repeat([]byte{1}, crypt.KeySize), got)
+
+	t.Setenv(crypt.EnvKey, key)
+	t.Setenv(crypt.EnvKeyCommand, "false")
+
+	_, err = crypt.FromEnv()
+	require.NoError(t, err, "the key variable wins over the command")
+}
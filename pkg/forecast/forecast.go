@@ -0,0 +1,172 @@
+// Package forecast projects regularly spaced series forward with a linear
+// trend or Holt's linear exponential smoothing, each with an approximate 95%
+// prediction interval.
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Method names a forecasting model.
+type Method string
+
+const (
+	// MethodLinear fits an ordinary least squares trend line.
+	MethodLinear Method = "linear"
+	// MethodHolt is Holt's linear exponential smoothing: Holt-Winters without
+	// a seasonal component, whose level and trend follow recent changes.
+	MethodHolt Method = "holt"
+)
+
+// ErrTooShort indicates a series with too few observations for the method.
+var ErrTooShort = errors.New("series too short to forecast")
+
+const (
+	// linearMinPoints is the fewest observations a trend line needs to
+	// estimate its residual variance.
+	linearMinPoints = 3
+	// holtMinPoints is the fewest observations Holt's method is fitted to;
+	// Auto falls back to a trend line below it.
+	holtMinPoints = 10
+	// z95 is the standard normal quantile of a two-sided 95% interval.
+	z95 = 1.96
+	// smoothingGrid is the number of steps of the α and β* grid search.
+	smoothingGrid = 19
+)
+
+// Point is a projected value Step observations past the last one, with its
+// prediction interval.
+type Point struct {
+	Step  int     `json:"step"`
+	Value float64 `json:"value"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// Forecast is the projection of a series by one method.
+type Forecast struct {
+	Method Method  `json:"method"`
+	Points []Point `json:"points"`
+}
+
+// Auto forecasts values horizon steps ahead with Holt's method, or with a
+// trend line when the series is too short to fit the smoothing parameters.
+func Auto(values []float64, horizon int) (Forecast, error) {
+	if len(values) >= holtMinPoints {
+		return Holt(values, horizon)
+	}
+
+	return Linear(values, horizon)
+}
+
+// Linear forecasts values horizon steps ahead along their least squares
+// trend line.
+func Linear(values []float64, horizon int) (Forecast, error) {
+	n := len(values)
+	if n < linearMinPoints {
+		return Forecast{}, fmt.Errorf("%w: linear needs %d points, got %d", ErrTooShort, linearMinPoints, n)
+	}
+
+	meanX := float64(n-1) / 2
+
+	var meanY float64
+	for _, v := range values {
+		meanY += v
+	}
+
+	meanY /= float64(n)
+
+	var sxx, sxy float64
+
+	for i, v := range values {
+		dx := float64(i) - meanX
+		sxx += dx * dx
+		sxy += dx * (v - meanY)
+	}
+
+	slope := sxy / sxx
+	intercept := meanY - slope*meanX
+
+	var sse float64
+
+	for i, v := range values {
+		residual := v - (intercept + slope*float64(i))
+		sse += residual * residual
+	}
+
+	sigma := math.Sqrt(sse / float64(n-2))
+	points := make([]Point, horizon)
+
+	for h := 1; h <= horizon; h++ {
+		x := float64(n - 1 + h)
+		value := intercept + slope*x
+		margin := z95 * sigma * math.Sqrt(1+1/float64(n)+(x-meanX)*(x-meanX)/sxx)
+		points[h-1] = Point{Step: h, Value: value, Lower: value - margin, Upper: value + margin}
+	}
+
+	return Forecast{Method: MethodLinear, Points: points}, nil
+}
+
+// Holt forecasts values horizon steps ahead with Holt's linear exponential
+// smoothing. The smoothing parameters minimize the one-step-ahead squared
+// errors over a grid, and the interval follows the variance of the
+// equivalent ETS(A,A,N) state space model.
+func Holt(values []float64, horizon int) (Forecast, error) {
+	n := len(values)
+	if n < holtMinPoints {
+		return Forecast{}, fmt.Errorf("%w: holt needs %d points, got %d", ErrTooShort, holtMinPoints, n)
+	}
+
+	best := holtFit{sse: math.Inf(1)}
+
+	for i := 1; i <= smoothingGrid; i++ {
+		for j := 1; j <= smoothingGrid; j++ {
+			fit := fitHolt(values, float64(i)/(smoothingGrid+1), float64(j)/(smoothingGrid+1))
+			if fit.sse < best.sse {
+				best = fit
+			}
+		}
+	}
+
+	// The first two observations initialize the level and the trend.
+	variance := best.sse / float64(n-2)
+	beta := best.alpha * best.beta
+	points := make([]Point, horizon)
+
+	for h := 1; h <= horizon; h++ {
+		hf := float64(h)
+		value := best.level + hf*best.trend
+		spread := 1 + (hf-1)*(best.alpha*best.alpha+best.alpha*beta*hf+beta*beta*hf*(2*hf-1)/6)
+		margin := z95 * math.Sqrt(variance*spread)
+		points[h-1] = Point{Step: h, Value: value, Lower: value - margin, Upper: value + margin}
+	}
+
+	return Forecast{Method: MethodHolt, Points: points}, nil
+}
+
+// holtFit is the final state of Holt's method over a series for one choice
+// of the level (alpha) and trend (beta) smoothing parameters.
+type holtFit struct {
+	alpha, beta  float64
+	level, trend float64
+	sse          float64
+}
+
+func fitHolt(values []float64, alpha, beta float64) holtFit {
+	level, trend := values[1], values[1]-values[0]
+
+	var sse float64
+
+	for _, v := range values[2:] {
+		errorTerm := v - (level + trend)
+		sse += errorTerm * errorTerm
+
+		previous := level
+		level = alpha*v + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+	}
+
+	return holtFit{alpha: alpha, beta: beta, level: level, trend: trend, sse: sse}
+}
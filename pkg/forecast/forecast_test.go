@@ -0,0 +1,69 @@
+package forecast_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/forecast"
+)
+
+func TestLinear(t *testing.T) {
+	t.Parallel()
+
+	f, err := forecast.Linear([]float64{10, 12, 14, 16, 18}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, forecast.MethodLinear, f.Method)
+	require.Len(t, f.Points, 3)
+	assert.Equal(t, 3, f.Points[2].Step)
+	assert.InDelta(t, 24, f.Points[2].Value, 1e-9)
+	assert.InDelta(t, 24, f.Points[2].Lower, 1e-9, "a perfect fit has no spread")
+
+	f, err = forecast.Linear([]float64{10, 13, 13, 17, 18, 19}, 4)
+	require.NoError(t, err)
+
+	for i, p := range f.Points {
+		assert.Less(t, p.Lower, p.Value)
+		assert.Greater(t, p.Upper, p.Value)
+
+		if i > 0 {
+			assert.Greater(t, p.Upper-p.Lower, f.Points[i-1].Upper-f.Points[i-1].Lower, "the band widens")
+		}
+	}
+
+	_, err = forecast.Linear([]float64{1, 2}, 1)
+	require.ErrorIs(t, err, forecast.ErrTooShort)
+}
+
+func TestHolt(t *testing.T) {
+	t.Parallel()
+
+	// A series whose trend turns: Holt follows the recent slope of 5 rather
+	// than the average slope of the whole series.
+	values := []float64{0, 1, 2, 3, 4, 5, 10, 15, 20, 25, 30, 35}
+
+	f, err := forecast.Holt(values, 2)
+	require.NoError(t, err)
+	assert.Equal(t, forecast.MethodHolt, f.Method)
+	require.Len(t, f.Points, 2)
+	assert.InDelta(t, 45, f.Points[1].Value, 2)
+	assert.Less(t, f.Points[1].Lower, f.Points[1].Value)
+	assert.Greater(t, f.Points[1].Upper-f.Points[1].Lower, f.Points[0].Upper-f.Points[0].Lower)
+
+	_, err = forecast.Holt(values[:5], 1)
+	require.ErrorIs(t, err, forecast.ErrTooShort)
+}
+
+func TestAuto(t *testing.T) {
+	t.Parallel()
+
+	f, err := forecast.Auto([]float64{1, 2, 3, 4}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, forecast.MethodLinear, f.Method)
+
+	f, err = forecast.Auto([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, forecast.MethodHolt, f.Method)
+	assert.InDelta(t, 11, f.Points[0].Value, 1e-6)
+}
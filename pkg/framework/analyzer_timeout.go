@@ -0,0 +1,355 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
+)
+
+// goroutineDumpDebug is the pprof debug level of goroutine dumps: every
+// goroutine with its full stack, as printed by an unrecovered panic.
+const goroutineDumpDebug = 2
+
+// ErrAnalyzerTimeout wraps a leaf analyzer that exceeded its commit or chunk
+// timeout. Timeouts skip the commit for the analyzer under every commit error
+// policy.
+var ErrAnalyzerTimeout = errors.New("analyzer timed out")
+
+// ErrAnalyzerStuck wraps a leaf analyzer whose timed-out Consume did not
+// return within the grace period. Its state, and the plumbing it reads, can
+// no longer be used safely, so the analyzer is disabled for the rest of the
+// run under every commit error policy.
+var ErrAnalyzerStuck = errors.New("analyzer stuck after timeout")
+
+// DefaultTimeoutGrace is how long a timed-out Consume may take to return
+// once its context is cancelled.
+const DefaultTimeoutGrace = 5 * time.Second
+
+// ErrInvalidAnalyzerTimeout is returned for malformed --analyzer-timeout and
+// --analyzer-chunk-timeout values.
+var ErrInvalidAnalyzerTimeout = errors.New("invalid analyzer timeout")
+
+// AnalyzerTimeouts limits the time leaf analyzers may spend in Consume.
+// Default applies to every leaf; PerAnalyzer overrides it by analyzer flag
+// or name. Zero means no limit.
+type AnalyzerTimeouts struct {
+	Default     time.Duration
+	PerAnalyzer map[string]time.Duration
+}
+
+// ParseAnalyzerTimeouts parses timeouts written as "30s,sentiment=2m": an
+// optional default duration followed by per-analyzer overrides, keyed by
+// analyzer flag ("sentiment") or name ("history/sentiment"). Returns the
+// zero value for an empty value.
+func ParseAnalyzerTimeouts(value string) (AnalyzerTimeouts, error) {
+	var timeouts AnalyzerTimeouts
+
+	if strings.TrimSpace(value) == "" {
+		return timeouts, nil
+	}
+
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		key, durationValue, hasKey := strings.Cut(entry, "=")
+		if !hasKey {
+			durationValue = key
+		}
+
+		duration, err := time.ParseDuration(strings.TrimSpace(durationValue))
+		if err != nil || duration < 0 {
+			return AnalyzerTimeouts{}, fmt.Errorf("%w: %q", ErrInvalidAnalyzerTimeout, entry)
+		}
+
+		if !hasKey {
+			timeouts.Default = duration
+
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			return AnalyzerTimeouts{}, fmt.Errorf("%w: %q", ErrInvalidAnalyzerTimeout, entry)
+		}
+
+		if timeouts.PerAnalyzer == nil {
+			timeouts.PerAnalyzer = make(map[string]time.Duration)
+		}
+
+		timeouts.PerAnalyzer[key] = duration
+	}
+
+	return timeouts, nil
+}
+
+// For returns the timeout of the analyzer, or zero when it has none.
+func (t AnalyzerTimeouts) For(a analyze.HistoryAnalyzer) time.Duration {
+	if duration, ok := t.PerAnalyzer[strings.ToLower(a.Flag())]; ok {
+		return duration
+	}
+
+	if duration, ok := t.PerAnalyzer[strings.ToLower(a.Name())]; ok {
+		return duration
+	}
+
+	return t.Default
+}
+
+// IsZero reports whether no analyzer has a timeout.
+func (t AnalyzerTimeouts) IsZero() bool {
+	return t.Default == 0 && len(t.PerAnalyzer) == 0
+}
+
+// timeoutGuard enforces the commit and chunk timeouts of leaf analyzers.
+// Consume cannot be preempted, so a timed-out call gets its context
+// cancelled and grace more time to return: until it does, it may still
+// touch its analyzer's state and the plumbing it reads, which the next
+// commit and the chunk-end Hibernate, Merge and Finalize change. A call that
+// does not return within grace disables its analyzer: the analyzer and its
+// forks consume no more commits, and are left out of hibernation, merging
+// and checkpoints. A nil guard enforces nothing.
+type timeoutGuard struct {
+	commit  AnalyzerTimeouts
+	chunk   AnalyzerTimeouts
+	grace   time.Duration
+	dumpDir string
+	logger  *slog.Logger
+
+	mu       sync.Mutex
+	disabled map[string]bool // names of the analyzers with a stuck call.
+}
+
+// timeoutGuard returns the guard of the runner's timeouts, or nil when none
+// is set.
+func (runner *Runner) timeoutGuard() *timeoutGuard {
+	if runner.CommitTimeouts.IsZero() && runner.ChunkTimeouts.IsZero() {
+		return nil
+	}
+
+	runner.guardOnce.Do(func() {
+		logger := runner.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+
+		grace := runner.TimeoutGrace
+		if grace <= 0 {
+			grace = DefaultTimeoutGrace
+		}
+
+		runner.guard = &timeoutGuard{
+			commit:   runner.CommitTimeouts,
+			chunk:    runner.ChunkTimeouts,
+			grace:    grace,
+			dumpDir:  runner.DumpDir,
+			logger:   logger,
+			disabled: make(map[string]bool),
+		}
+	})
+
+	return runner.guard
+}
+
+// admit returns the time limit of the next Consume of a, which already spent
+// the given time on the current chunk: the smaller of its commit timeout and
+// what is left of its chunk timeout, zero for none. It fails with
+// ErrAnalyzerTimeout when a used up its chunk timeout. A disabled analyzer
+// is admitted without a limit, since run does not call it.
+func (g *timeoutGuard) admit(a analyze.HistoryAnalyzer, spent time.Duration) (time.Duration, error) {
+	if g == nil || g.isDisabled(a) {
+		return 0, nil
+	}
+
+	limit := g.commit.For(a)
+
+	chunkLimit := g.chunk.For(a)
+	if chunkLimit <= 0 {
+		return limit, nil
+	}
+
+	left := chunkLimit - spent
+	if left <= 0 {
+		return 0, fmt.Errorf("%w: %s used up its chunk timeout of %s", ErrAnalyzerTimeout, a.Name(), chunkLimit)
+	}
+
+	if limit <= 0 || left < limit {
+		limit = left
+	}
+
+	return limit, nil
+}
+
+// run calls Consume of a within limit. On timeout, it writes a goroutine
+// dump and waits up to the grace period for the cancelled call to return:
+// it returns an ErrAnalyzerTimeout error naming the dump when it does, and
+// otherwise disables a and returns an ErrAnalyzerStuck error. A zero limit
+// calls Consume directly; a disabled analyzer is not called.
+func (g *timeoutGuard) run(
+	ctx context.Context, a analyze.HistoryAnalyzer, ac *analyze.Context, limit time.Duration,
+) (analyze.TC, error) {
+	if g.isDisabled(a) {
+		return analyze.TC{}, nil
+	}
+
+	if g == nil || limit <= 0 {
+		return consumeSafely(ctx, a, ac)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, limit)
+
+	var (
+		tc   analyze.TC
+		err  error
+		done = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+		defer cancel()
+
+		tc, err = consumeSafely(callCtx, a, ac)
+	}()
+
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return tc, err
+	case <-timer.C:
+	}
+
+	dump, dumpErr := writeGoroutineDump(g.dumpDir, a.Name())
+	if dumpErr != nil {
+		g.logger.WarnContext(ctx, "goroutine dump failed", slog.String("analyzer", a.Name()), slog.Any("error", dumpErr))
+
+		dump = "none"
+	}
+
+	g.logger.WarnContext(ctx, "analyzer timed out",
+		slog.String("analyzer", a.Name()),
+		slog.Duration("timeout", limit),
+		slog.String("goroutine_dump", dump))
+
+	grace := time.NewTimer(g.grace)
+	defer grace.Stop()
+
+	select {
+	case <-done:
+		return analyze.TC{}, fmt.Errorf("%w: %s after %s (goroutine dump: %s)", ErrAnalyzerTimeout, a.Name(), limit, dump)
+	case <-grace.C:
+		g.disable(a)
+
+		return analyze.TC{}, fmt.Errorf("%w: %s did not return %s after its timeout of %s and is disabled (goroutine dump: %s)",
+			ErrAnalyzerStuck, a.Name(), g.grace, limit, dump)
+	}
+}
+
+// disable keeps a and its forks, which share its name, from consuming more
+// commits.
+func (g *timeoutGuard) disable(a analyze.HistoryAnalyzer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.disabled[a.Name()] = true
+}
+
+// isDisabled reports whether a was disabled after a stuck call.
+func (g *timeoutGuard) isDisabled(a analyze.HistoryAnalyzer) bool {
+	if g == nil {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.disabled[a.Name()]
+}
+
+// DisabledAnalyzers returns the sorted names of the analyzers disabled after
+// a stuck Consume, or nil when there are none.
+func (runner *Runner) DisabledAnalyzers() []string {
+	g := runner.timeoutGuard()
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var names []string
+
+	for name := range g.disabled {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// withoutDisabled drops the hibernatables of disabled analyzers, whose stuck
+// call may still be using their state.
+func (runner *Runner) withoutDisabled(hibernatables []streaming.Hibernatable) []streaming.Hibernatable {
+	g := runner.timeoutGuard()
+	if g == nil {
+		return hibernatables
+	}
+
+	return slices.DeleteFunc(slices.Clone(hibernatables), func(h streaming.Hibernatable) bool {
+		a, ok := h.(analyze.HistoryAnalyzer)
+
+		return ok && g.isDisabled(a)
+	})
+}
+
+// consume admits and runs one Consume of a.
+func (g *timeoutGuard) consume(
+	ctx context.Context, a analyze.HistoryAnalyzer, ac *analyze.Context, spent time.Duration,
+) (analyze.TC, error) {
+	limit, err := g.admit(a, spent)
+	if err != nil {
+		return analyze.TC{}, err
+	}
+
+	return g.run(ctx, a, ac, limit)
+}
+
+// leafErrorAborts reports whether a leaf error fails the run: a timeout or
+// a stuck analyzer never does, any other error only under the abort policy.
+func leafErrorAborts(err error, abort bool) bool {
+	return abort && !errors.Is(err, ErrAnalyzerTimeout) && !errors.Is(err, ErrAnalyzerStuck)
+}
+
+// writeGoroutineDump writes the stacks of all goroutines to a new file in
+// dir, or in the system temp directory when dir is empty, and returns its
+// path.
+func writeGoroutineDump(dir, analyzer string) (path string, err error) {
+	name := strings.NewReplacer("/", "-", string(os.PathSeparator), "-").Replace(analyzer)
+
+	file, err := os.CreateTemp(dir, "codefang-timeout-"+name+"-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("create goroutine dump: %w", err)
+	}
+
+	defer func() {
+		err = errors.Join(err, file.Close())
+	}()
+
+	err = pprof.Lookup("goroutine").WriteTo(file, goroutineDumpDebug)
+	if err != nil {
+		return "", fmt.Errorf("write goroutine dump: %w", err)
+	}
+
+	return file.Name(), nil
+}
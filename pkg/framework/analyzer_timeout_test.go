@@ -0,0 +1,244 @@
+package framework_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+)
+
+// hangingLeaf is a stubLeaf that blocks on the commit at hangAt until its
+// context is cancelled, or until release is closed when it ignores its
+// context like a stuck analyzer.
+type hangingLeaf struct {
+	stubLeaf
+
+	hangAt    int
+	ignoreCtx bool
+	release   chan struct{}
+}
+
+func (h *hangingLeaf) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac.Index != h.hangAt {
+		return h.stubLeaf.Consume(ctx, ac)
+	}
+
+	if h.ignoreCtx {
+		<-h.release
+
+		return analyze.TC{}, nil
+	}
+
+	<-ctx.Done()
+
+	return analyze.TC{}, ctx.Err()
+}
+
+// lingeringLeaf is a stubLeaf that keeps writing its state for a while after
+// its call on the commit at hangAt is cancelled. Hibernate, Boot and Merge
+// touch that state too, so the race detector flags any of them running
+// before the timed-out call returns.
+type lingeringLeaf struct {
+	stubLeaf
+
+	hangAt int
+	linger time.Duration
+	state  int
+}
+
+func (l *lingeringLeaf) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac.Index == l.hangAt {
+		<-ctx.Done()
+		time.Sleep(l.linger)
+	}
+
+	l.state++
+
+	return l.stubLeaf.Consume(ctx, ac)
+}
+
+func (l *lingeringLeaf) Fork(n int) []analyze.HistoryAnalyzer {
+	forks := make([]analyze.HistoryAnalyzer, n)
+	for i := range n {
+		forks[i] = &lingeringLeaf{stubLeaf: stubLeaf{name: l.name, cpuHeavy: l.cpuHeavy}, hangAt: l.hangAt, linger: l.linger}
+	}
+
+	return forks
+}
+
+func (l *lingeringLeaf) Merge(branches []analyze.HistoryAnalyzer) {
+	for _, branch := range branches {
+		if fork, ok := branch.(*lingeringLeaf); ok {
+			l.state += fork.state
+		}
+	}
+}
+
+func (l *lingeringLeaf) Hibernate() error {
+	l.state++
+
+	return nil
+}
+
+func (l *lingeringLeaf) Boot() error {
+	l.state++
+
+	return nil
+}
+
+func TestParseAnalyzerTimeouts(t *testing.T) {
+	t.Parallel()
+
+	timeouts, err := framework.ParseAnalyzerTimeouts("30s, Sentiment=2m,history/burndown=0s")
+	require.NoError(t, err)
+	assert.Equal(t, framework.AnalyzerTimeouts{
+		Default: 30 * time.Second,
+		PerAnalyzer: map[string]time.Duration{
+			"sentiment":        2 * time.Minute,
+			"history/burndown": 0,
+		},
+	}, timeouts)
+
+	assert.Equal(t, 2*time.Minute, timeouts.For(&stubLeaf{name: "sentiment"}))
+	assert.Equal(t, 30*time.Second, timeouts.For(&stubLeaf{name: "devs"}))
+
+	empty, err := framework.ParseAnalyzerTimeouts("")
+	require.NoError(t, err)
+	assert.True(t, empty.IsZero())
+
+	for _, value := range []string{"soon", "-1s", "=1s", "sentiment=forever"} {
+		_, err = framework.ParseAnalyzerTimeouts(value)
+		require.ErrorIs(t, err, framework.ErrInvalidAnalyzerTimeout, value)
+	}
+}
+
+func TestRunner_AnalyzerTimeout_SerialLeaf(t *testing.T) {
+	t.Parallel()
+
+	libRepo, path, commits := openThreeCommitRepo(t)
+
+	hanging := &hangingLeaf{stubLeaf: stubLeaf{name: "hanging"}, hangAt: 1}
+	good := &stubLeaf{name: "good"}
+
+	r := framework.NewRunner(libRepo, path, &plumbing.TreeDiffAnalyzer{}, hanging, good)
+	r.CoreCount = 1
+	r.CommitTimeouts = framework.AnalyzerTimeouts{Default: 50 * time.Millisecond}
+	r.DumpDir = t.TempDir()
+
+	_, err := r.Run(context.Background(), commits)
+	require.NoError(t, err, "timeouts skip the commit under the abort policy")
+
+	assert.Equal(t, 2, hanging.consumed, "only the timed-out commit is skipped")
+	assert.Equal(t, 3, good.consumed)
+
+	qs := r.Quality()
+	require.NotNil(t, qs)
+	assert.Equal(t, 1, qs.Timeouts)
+	require.Len(t, qs.Failures, 1)
+	assert.Equal(t, "hanging", qs.Failures[0].Stage)
+	assert.Equal(t, 1, qs.Failures[0].Index)
+	assert.Contains(t, qs.Failures[0].Error, "goroutine dump")
+
+	dumps, err := os.ReadDir(r.DumpDir)
+	require.NoError(t, err)
+	assert.Len(t, dumps, 1)
+}
+
+func TestRunner_AnalyzerTimeout_StuckLeafIsDisabled(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []framework.CommitErrorPolicy{framework.CommitErrorAbort, framework.CommitErrorSkip} {
+		libRepo, path, commits := openThreeCommitRepo(t)
+
+		hanging := &hangingLeaf{stubLeaf: stubLeaf{name: "hanging"}, hangAt: 1, ignoreCtx: true, release: make(chan struct{})}
+		t.Cleanup(func() { close(hanging.release) })
+
+		good := &stubLeaf{name: "good"}
+
+		r := framework.NewRunner(libRepo, path, &plumbing.TreeDiffAnalyzer{}, hanging, good)
+		r.CoreCount = 1
+		r.OnCommitError = policy
+		r.CommitTimeouts = framework.AnalyzerTimeouts{Default: 20 * time.Millisecond}
+		r.TimeoutGrace = 20 * time.Millisecond
+		r.DumpDir = t.TempDir()
+
+		_, err := r.Run(context.Background(), commits)
+		require.NoError(t, err, policy)
+
+		assert.Equal(t, 1, hanging.consumed, "a stuck analyzer consumes no later commits")
+		assert.Equal(t, 3, good.consumed)
+		assert.Equal(t, []string{"hanging"}, r.DisabledAnalyzers())
+
+		qs := r.Quality()
+		require.NotNil(t, qs)
+		assert.Equal(t, []string{"hanging"}, qs.Disabled)
+		require.Len(t, qs.Failures, 1)
+		assert.Contains(t, qs.Failures[0].Error, "is disabled")
+	}
+}
+
+// TestRunner_AnalyzerTimeout_ChunkBoundary times a leaf out on the last
+// commit of a chunk and runs the chunk-end lifecycle right away; run with
+// -race to check that none of it overlaps the timed-out call.
+func TestRunner_AnalyzerTimeout_ChunkBoundary(t *testing.T) {
+	t.Parallel()
+
+	for _, cpuHeavy := range []bool{false, true} {
+		libRepo, path, commits := openThreeCommitRepo(t)
+
+		leaf := &lingeringLeaf{stubLeaf: stubLeaf{name: "lingering", cpuHeavy: cpuHeavy}, hangAt: 0, linger: 20 * time.Millisecond}
+
+		r := framework.NewRunner(libRepo, path, &plumbing.TreeDiffAnalyzer{}, leaf)
+		r.CoreCount = 1
+		r.CommitTimeouts = framework.AnalyzerTimeouts{Default: 20 * time.Millisecond}
+		r.DumpDir = t.TempDir()
+
+		require.NoError(t, r.Initialize())
+
+		_, err := r.ProcessChunk(context.Background(), commits[:1], 0, 0)
+		require.NoError(t, err)
+
+		require.NoError(t, leaf.Hibernate())
+		require.NoError(t, leaf.Boot())
+
+		_, err = r.ProcessChunk(context.Background(), commits[1:], 1, 1)
+		require.NoError(t, err)
+
+		_, err = r.FinalizeWithAggregators(context.Background())
+		require.NoError(t, err)
+
+		qs := r.Quality()
+		require.NotNil(t, qs)
+		assert.Equal(t, 1, qs.Timeouts, "cpuHeavy=%v", cpuHeavy)
+	}
+}
+
+func TestRunner_AnalyzerChunkTimeout(t *testing.T) {
+	t.Parallel()
+
+	libRepo, path, commits := openThreeCommitRepo(t)
+
+	hanging := &hangingLeaf{stubLeaf: stubLeaf{name: "hanging"}, hangAt: 0}
+
+	r := framework.NewRunner(libRepo, path, &plumbing.TreeDiffAnalyzer{}, hanging)
+	r.CoreCount = 1
+	r.ChunkTimeouts = framework.AnalyzerTimeouts{PerAnalyzer: map[string]time.Duration{"hanging": 50 * time.Millisecond}}
+	r.DumpDir = t.TempDir()
+
+	_, err := r.Run(context.Background(), commits)
+	require.NoError(t, err)
+
+	assert.Zero(t, hanging.consumed)
+
+	qs := r.Quality()
+	require.NotNil(t, qs)
+	assert.Equal(t, 3, qs.Timeouts)
+	assert.Equal(t, 3, qs.SkippedCommits)
+}
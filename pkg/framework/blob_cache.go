@@ -1,6 +1,9 @@
 package framework
 
 import (
+	"maps"
+	"path"
+	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -10,15 +13,41 @@ import (
 // DefaultGlobalCacheSize is the default maximum memory size for the global blob cache (128 MB).
 const DefaultGlobalCacheSize = 128 * 1024 * 1024
 
-// GlobalBlobCache provides a cross-commit LRU cache for blob data.
-// It tracks memory usage and evicts least recently used entries when the limit is exceeded.
+// cacheShedDivisor is the factor by which the blob and diff caches shrink
+// each time they are shed under memory pressure.
+const cacheShedDivisor = 2
+
+// protectedSegmentPercent is the share of the blob cache held by the
+// protected segment: blobs that were hit at least once since they entered.
+const protectedSegmentPercent = 80
+
+// GlobalBlobCache provides a cross-commit cache for blob data with a segmented
+// LRU policy. New blobs enter a probation segment; a blob hit there moves to
+// the protected segment, which holds 80% of the cache. Eviction takes the
+// least recently used probation blob first, so a burst of blobs that are read
+// once (a vendored directory, a generated file rewritten every commit) cannot
+// flush blobs that are read again and again.
+//
+// Blobs can be capped per file extension with SetExtensionCaps: a blob whose
+// extension already holds its cap is not admitted.
 type GlobalBlobCache struct {
 	mu          sync.RWMutex
 	entries     map[gitlib.Hash]*cacheEntry
-	head        *cacheEntry // Most recently used.
-	tail        *cacheEntry // Least recently used.
+	probation   cacheList
+	protected   cacheList
 	maxSize     int64
 	currentSize int64
+	peakSize    int64
+
+	extCaps  map[string]int64
+	extBytes map[string]int64
+	extStats map[string]ExtensionCacheStats
+
+	admitted    int64
+	rejected    int64
+	evicted     int64
+	promoted    int64
+	bytesServed int64
 
 	// Metrics (atomic for lock-free reads).
 	hits   atomic.Int64
@@ -27,11 +56,20 @@ type GlobalBlobCache struct {
 
 // cacheEntry is a doubly-linked list node for LRU tracking.
 type cacheEntry struct {
-	hash gitlib.Hash
-	blob *gitlib.CachedBlob
+	hash      gitlib.Hash
+	blob      *gitlib.CachedBlob
+	size      int64
+	ext       string
+	protected bool
+	prev      *cacheEntry
+	next      *cacheEntry
+}
+
+// cacheList is one LRU segment, most recently used first.
+type cacheList struct {
+	head *cacheEntry
+	tail *cacheEntry
 	size int64
-	prev *cacheEntry
-	next *cacheEntry
 }
 
 // NewGlobalBlobCache creates a new global blob cache with the specified maximum size in bytes.
@@ -41,11 +79,29 @@ func NewGlobalBlobCache(maxSize int64) *GlobalBlobCache {
 	}
 
 	return &GlobalBlobCache{
-		entries: make(map[gitlib.Hash]*cacheEntry),
-		maxSize: maxSize,
+		entries:  make(map[gitlib.Hash]*cacheEntry),
+		maxSize:  maxSize,
+		extBytes: make(map[string]int64),
+		extStats: make(map[string]ExtensionCacheStats),
 	}
 }
 
+// SetExtensionCaps caps the bytes cached for blobs of the given file
+// extensions, keyed as returned by BlobExtension. A cap of zero keeps the
+// extension out of the cache.
+func (c *GlobalBlobCache) SetExtensionCaps(caps map[string]int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.extCaps = maps.Clone(caps)
+}
+
+// BlobExtension returns the cache key of a file path: its lower-cased
+// extension with the leading dot, or "" for files without one.
+func BlobExtension(filePath string) string {
+	return strings.ToLower(path.Ext(filePath))
+}
+
 // Get retrieves a blob from the cache. Returns nil if not found.
 func (c *GlobalBlobCache) Get(hash gitlib.Hash) *gitlib.CachedBlob {
 	c.mu.Lock()
@@ -58,8 +114,8 @@ func (c *GlobalBlobCache) Get(hash gitlib.Hash) *gitlib.CachedBlob {
 		return nil
 	}
 
-	c.hits.Add(1)
-	c.moveToFront(entry)
+	c.recordHit(entry)
+	c.touch(entry)
 
 	return entry.blob
 }
@@ -70,41 +126,10 @@ func (c *GlobalBlobCache) Put(hash gitlib.Hash, blob *gitlib.CachedBlob) {
 		return
 	}
 
-	blobSize := int64(len(blob.Data))
-
-	// Don't cache blobs larger than the entire cache.
-	if blobSize > c.maxSize {
-		return
-	}
-
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Check if already exists.
-	if entry, ok := c.entries[hash]; ok {
-		c.moveToFront(entry)
-
-		return
-	}
-
-	// Evict entries until we have room.
-	for c.currentSize+blobSize > c.maxSize && c.tail != nil {
-		c.evictLRU()
-	}
-
-	// Add new entry
-	// Clone the blob to ensure data is detached from any large arena.
-	safeBlob := blob.Clone()
-
-	entry := &cacheEntry{
-		hash: hash,
-		blob: safeBlob,
-		size: blobSize,
-	}
-
-	c.entries[hash] = entry
-	c.currentSize += blobSize
-	c.addToFront(entry)
+	c.put(hash, blob, "")
 }
 
 // GetMulti retrieves multiple blobs from the cache.
@@ -118,8 +143,8 @@ func (c *GlobalBlobCache) GetMulti(hashes []gitlib.Hash) (found map[gitlib.Hash]
 
 	for _, hash := range hashes {
 		if entry, ok := c.entries[hash]; ok {
-			c.hits.Add(1)
-			c.moveToFront(entry)
+			c.recordHit(entry)
+			c.touch(entry)
 			found[hash] = entry.blob
 		} else {
 			c.misses.Add(1)
@@ -133,6 +158,13 @@ func (c *GlobalBlobCache) GetMulti(hashes []gitlib.Hash) (found map[gitlib.Hash]
 
 // PutMulti adds multiple blobs to the cache.
 func (c *GlobalBlobCache) PutMulti(blobs map[gitlib.Hash]*gitlib.CachedBlob) {
+	c.PutMultiWithExtensions(blobs, nil)
+}
+
+// PutMultiWithExtensions adds multiple blobs to the cache, with the file
+// extension of each blob for extension caps and per-extension stats. Blobs
+// missing from exts count under "".
+func (c *GlobalBlobCache) PutMultiWithExtensions(blobs map[gitlib.Hash]*gitlib.CachedBlob, exts map[gitlib.Hash]string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -141,40 +173,97 @@ func (c *GlobalBlobCache) PutMulti(blobs map[gitlib.Hash]*gitlib.CachedBlob) {
 			continue
 		}
 
-		blobSize := int64(len(blob.Data))
+		c.put(hash, blob, exts[hash])
+	}
+}
 
-		// Skip blobs larger than entire cache.
-		if blobSize > c.maxSize {
-			continue
-		}
+// put admits one blob. Must be called with mu held.
+func (c *GlobalBlobCache) put(hash gitlib.Hash, blob *gitlib.CachedBlob, ext string) {
+	// A re-put is not a use: only hits promote, so Promoted and Hits agree.
+	if _, ok := c.entries[hash]; ok {
+		return
+	}
 
-		// Skip if already exists.
-		if entry, ok := c.entries[hash]; ok {
-			c.moveToFront(entry)
+	blobSize := int64(len(blob.Data))
 
-			continue
-		}
+	// Skip blobs larger than the entire cache or beyond their extension cap.
+	extCap, capped := c.extCaps[ext]
+	if blobSize > c.maxSize || (capped && c.extBytes[ext]+blobSize > extCap) {
+		c.rejected++
+		c.updateExt(ext, func(s *ExtensionCacheStats) { s.Rejected++ })
+
+		return
+	}
 
-		// Evict entries until we have room.
-		for c.currentSize+blobSize > c.maxSize && c.tail != nil {
-			c.evictLRU()
+	// Evict entries until we have room.
+	for c.currentSize+blobSize > c.maxSize {
+		if !c.evictLRU() {
+			break
 		}
+	}
 
-		// Add new entry.
-		safeBlob := blob.Clone()
+	// Clone the blob to ensure data is detached from any large arena.
+	entry := &cacheEntry{
+		hash: hash,
+		blob: blob.Clone(),
+		size: blobSize,
+		ext:  ext,
+	}
 
-		entry := &cacheEntry{
-			hash: hash,
-			blob: safeBlob,
-			size: blobSize,
-		}
+	c.entries[hash] = entry
+	c.currentSize += blobSize
+	c.peakSize = max(c.peakSize, c.currentSize)
+	c.extBytes[ext] += blobSize
+	c.probation.pushFront(entry)
 
-		c.entries[hash] = entry
-		c.currentSize += blobSize
-		c.addToFront(entry)
+	c.admitted++
+	c.updateExt(ext, func(s *ExtensionCacheStats) { s.Admitted++ })
+}
+
+// recordHit counts a hit on entry. Must be called with mu held.
+func (c *GlobalBlobCache) recordHit(entry *cacheEntry) {
+	c.hits.Add(1)
+	c.bytesServed += entry.size
+	c.updateExt(entry.ext, func(s *ExtensionCacheStats) {
+		s.Hits++
+		s.BytesServed += entry.size
+	})
+}
+
+// touch marks entry as used: a probation entry is promoted to the protected
+// segment, a protected one becomes its most recently used entry. Must be
+// called with mu held.
+func (c *GlobalBlobCache) touch(entry *cacheEntry) {
+	if entry.protected {
+		c.protected.remove(entry)
+		c.protected.pushFront(entry)
+
+		return
+	}
+
+	c.probation.remove(entry)
+
+	entry.protected = true
+	c.protected.pushFront(entry)
+	c.promoted++
+
+	// Demote the least recently used protected entries that no longer fit.
+	protectedMax := c.maxSize * protectedSegmentPercent / percentDivisor
+	for c.protected.size > protectedMax && c.protected.tail != entry {
+		demoted := c.protected.tail
+		c.protected.remove(demoted)
+
+		demoted.protected = false
+		c.probation.pushFront(demoted)
 	}
 }
 
+func (c *GlobalBlobCache) updateExt(ext string, update func(*ExtensionCacheStats)) {
+	s := c.extStats[ext]
+	update(&s)
+	c.extStats[ext] = s
+}
+
 // Stats returns cache statistics.
 func (c *GlobalBlobCache) Stats() CacheStats {
 	c.mu.RLock()
@@ -186,6 +275,13 @@ func (c *GlobalBlobCache) Stats() CacheStats {
 		Entries:     len(c.entries),
 		CurrentSize: c.currentSize,
 		MaxSize:     c.maxSize,
+		PeakSize:    c.peakSize,
+		Admitted:    c.admitted,
+		Rejected:    c.rejected,
+		Evicted:     c.evicted,
+		Promoted:    c.promoted,
+		BytesServed: c.bytesServed,
+		Extensions:  maps.Clone(c.extStats),
 	}
 }
 
@@ -202,6 +298,96 @@ type CacheStats struct {
 	Entries     int
 	CurrentSize int64
 	MaxSize     int64
+
+	// PeakSize is the largest CurrentSize reached.
+	PeakSize int64
+
+	// Admitted, Rejected, Evicted and Promoted count blobs entering the
+	// cache, kept out of it by size or extension cap, evicted from it, and
+	// moved to the protected segment on their first hit.
+	Admitted int64
+	Rejected int64
+	Evicted  int64
+	Promoted int64
+
+	// BytesServed is the total size of the blobs returned by hits, i.e. the
+	// blob loads the cache saved.
+	BytesServed int64
+
+	// Extensions breaks the counters down by file extension.
+	Extensions map[string]ExtensionCacheStats
+}
+
+// ExtensionCacheStats holds the blob cache counters of one file extension.
+type ExtensionCacheStats struct {
+	Hits        int64
+	BytesServed int64
+	Admitted    int64
+	Rejected    int64
+	Evicted     int64
+}
+
+// Add accumulates the counters of other into s.
+func (s *ExtensionCacheStats) Add(other ExtensionCacheStats) {
+	s.Hits += other.Hits
+	s.BytesServed += other.BytesServed
+	s.Admitted += other.Admitted
+	s.Rejected += other.Rejected
+	s.Evicted += other.Evicted
+}
+
+// Add accumulates the counters of other into s (cross-chunk aggregation).
+// Sizes keep the largest maximum and peak, and the entries and current size
+// of other.
+func (s *CacheStats) Add(other CacheStats) {
+	s.Hits += other.Hits
+	s.Misses += other.Misses
+	s.Entries = other.Entries
+	s.CurrentSize = other.CurrentSize
+	s.MaxSize = max(s.MaxSize, other.MaxSize)
+	s.PeakSize = max(s.PeakSize, other.PeakSize)
+	s.Admitted += other.Admitted
+	s.Rejected += other.Rejected
+	s.Evicted += other.Evicted
+	s.Promoted += other.Promoted
+	s.BytesServed += other.BytesServed
+
+	for ext, es := range other.Extensions {
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]ExtensionCacheStats)
+		}
+
+		merged := s.Extensions[ext]
+		merged.Add(es)
+		s.Extensions[ext] = merged
+	}
+}
+
+// Since returns the counters accumulated between before and s, taken from
+// the same cache. Sizes are those of s.
+func (s CacheStats) Since(before CacheStats) CacheStats {
+	delta := s
+	delta.Hits -= before.Hits
+	delta.Misses -= before.Misses
+	delta.Admitted -= before.Admitted
+	delta.Rejected -= before.Rejected
+	delta.Evicted -= before.Evicted
+	delta.Promoted -= before.Promoted
+	delta.BytesServed -= before.BytesServed
+	delta.Extensions = make(map[string]ExtensionCacheStats, len(s.Extensions))
+
+	for ext, es := range s.Extensions {
+		prev := before.Extensions[ext]
+		delta.Extensions[ext] = ExtensionCacheStats{
+			Hits:        es.Hits - prev.Hits,
+			BytesServed: es.BytesServed - prev.BytesServed,
+			Admitted:    es.Admitted - prev.Admitted,
+			Rejected:    es.Rejected - prev.Rejected,
+			Evicted:     es.Evicted - prev.Evicted,
+		}
+	}
+
+	return delta
 }
 
 // HitRate returns the cache hit rate (0.0 to 1.0).
@@ -220,60 +406,90 @@ func (c *GlobalBlobCache) Clear() {
 	defer c.mu.Unlock()
 
 	c.entries = make(map[gitlib.Hash]*cacheEntry)
-	c.head = nil
-	c.tail = nil
+	c.probation = cacheList{}
+	c.protected = cacheList{}
 	c.currentSize = 0
+	c.extBytes = make(map[string]int64)
 }
 
-// moveToFront moves an entry to the front of the LRU list (most recently used).
-func (c *GlobalBlobCache) moveToFront(entry *cacheEntry) {
-	if entry == c.head {
+// Shrink lowers the maximum size of the cache to maxSize, evicting least
+// recently used entries until the cache fits. A maxSize at or above the
+// current maximum is ignored.
+func (c *GlobalBlobCache) Shrink(maxSize int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if maxSize >= c.maxSize {
 		return
 	}
 
-	c.removeFromList(entry)
-	c.addToFront(entry)
+	c.maxSize = max(maxSize, 0)
+
+	for c.currentSize > c.maxSize {
+		if !c.evictLRU() {
+			break
+		}
+	}
+}
+
+// evictLRU removes the least recently used probation entry, or the least
+// recently used protected entry when probation is empty. Returns false when
+// the cache is empty.
+func (c *GlobalBlobCache) evictLRU() bool {
+	segment := &c.probation
+	if segment.tail == nil {
+		segment = &c.protected
+	}
+
+	entry := segment.tail
+	if entry == nil {
+		return false
+	}
+
+	segment.remove(entry)
+	delete(c.entries, entry.hash)
+
+	c.currentSize -= entry.size
+	c.extBytes[entry.ext] -= entry.size
+	c.evicted++
+	c.updateExt(entry.ext, func(s *ExtensionCacheStats) { s.Evicted++ })
+
+	return true
 }
 
-// addToFront adds an entry to the front of the LRU list.
-func (c *GlobalBlobCache) addToFront(entry *cacheEntry) {
+// pushFront adds an entry as the most recently used of the segment.
+func (l *cacheList) pushFront(entry *cacheEntry) {
 	entry.prev = nil
-	entry.next = c.head
+	entry.next = l.head
 
-	if c.head != nil {
-		c.head.prev = entry
+	if l.head != nil {
+		l.head.prev = entry
 	}
 
-	c.head = entry
+	l.head = entry
 
-	if c.tail == nil {
-		c.tail = entry
+	if l.tail == nil {
+		l.tail = entry
 	}
+
+	l.size += entry.size
 }
 
-// removeFromList removes an entry from the LRU list.
-func (c *GlobalBlobCache) removeFromList(entry *cacheEntry) {
+// remove unlinks an entry from the segment.
+func (l *cacheList) remove(entry *cacheEntry) {
 	if entry.prev != nil {
 		entry.prev.next = entry.next
 	} else {
-		c.head = entry.next
+		l.head = entry.next
 	}
 
 	if entry.next != nil {
 		entry.next.prev = entry.prev
 	} else {
-		c.tail = entry.prev
-	}
-}
-
-// evictLRU removes the least recently used entry.
-func (c *GlobalBlobCache) evictLRU() {
-	if c.tail == nil {
-		return
+		l.tail = entry.prev
 	}
 
-	entry := c.tail
-	c.removeFromList(entry)
-	delete(c.entries, entry.hash)
-	c.currentSize -= entry.size
+	entry.prev = nil
+	entry.next = nil
+	l.size -= entry.size
 }
@@ -117,6 +117,15 @@ func TestGlobalBlobCache_DuplicatePut(t *testing.T) {
 
 	stats := cache.Stats()
 	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, int64(1), stats.Admitted)
+	assert.Zero(t, stats.Promoted, "a re-put is not a hit")
+	assert.Zero(t, stats.Hits)
+
+	require.NotNil(t, cache.Get(hash))
+
+	stats = cache.Stats()
+	assert.Equal(t, int64(1), stats.Promoted)
+	assert.Equal(t, int64(1), stats.Hits)
 }
 
 func TestGlobalBlobCache_GetMulti(t *testing.T) {
@@ -258,3 +267,117 @@ func TestGlobalBlobCache_DefaultSize(t *testing.T) {
 	stats := cache.Stats()
 	assert.Equal(t, int64(framework.DefaultGlobalCacheSize), stats.MaxSize)
 }
+
+func TestGlobalBlobCache_Shrink(t *testing.T) {
+	t.Parallel()
+
+	cache := framework.NewGlobalBlobCache(1000)
+	cache.Put(makeTestHash(1), makeTestBlob(make([]byte, 300)))
+	cache.Put(makeTestHash(2), makeTestBlob(make([]byte, 300)))
+	cache.Put(makeTestHash(3), makeTestBlob(make([]byte, 300)))
+
+	cache.Shrink(2000)
+	assert.Equal(t, int64(1000), cache.Stats().MaxSize, "shrink never grows the cache")
+
+	cache.Shrink(500)
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(500), stats.MaxSize)
+	assert.Equal(t, int64(300), stats.CurrentSize)
+	assert.Nil(t, cache.Get(makeTestHash(1)), "least recently used entries go first")
+	assert.NotNil(t, cache.Get(makeTestHash(3)))
+}
+
+func TestGlobalBlobCache_ScanDoesNotFlushHotBlobs(t *testing.T) {
+	t.Parallel()
+
+	cache := framework.NewGlobalBlobCache(1000)
+	hot := makeTestHash(1)
+
+	cache.Put(hot, makeTestBlob(make([]byte, 200)))
+	require.NotNil(t, cache.Get(hot), "a hit promotes the blob to the protected segment")
+
+	// A scan of blobs read once cycles through probation only.
+	for i := byte(10); i < 30; i++ {
+		cache.Put(makeTestHash(i), makeTestBlob(make([]byte, 200)))
+	}
+
+	assert.NotNil(t, cache.Get(hot))
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(1), stats.Promoted)
+	assert.Equal(t, int64(21), stats.Admitted)
+	assert.Equal(t, int64(16), stats.Evicted)
+	assert.Equal(t, int64(1000), stats.PeakSize)
+	assert.Equal(t, int64(400), stats.BytesServed)
+}
+
+func TestGlobalBlobCache_ExtensionCaps(t *testing.T) {
+	t.Parallel()
+
+	cache := framework.NewGlobalBlobCache(1000)
+	cache.SetExtensionCaps(map[string]int64{".json": 250, ".svg": 0})
+
+	cache.PutMultiWithExtensions(map[gitlib.Hash]*gitlib.CachedBlob{
+		makeTestHash(1): makeTestBlob(make([]byte, 200)),
+		makeTestHash(2): makeTestBlob(make([]byte, 100)),
+		makeTestHash(3): makeTestBlob(make([]byte, 100)),
+	}, map[gitlib.Hash]string{
+		makeTestHash(1): ".json",
+		makeTestHash(2): ".svg",
+		makeTestHash(3): ".go",
+	})
+	cache.PutMultiWithExtensions(map[gitlib.Hash]*gitlib.CachedBlob{
+		makeTestHash(4): makeTestBlob(make([]byte, 100)),
+	}, map[gitlib.Hash]string{makeTestHash(4): ".json"})
+
+	assert.NotNil(t, cache.Get(makeTestHash(1)))
+	assert.Nil(t, cache.Get(makeTestHash(2)), "a zero cap keeps the extension out")
+	assert.NotNil(t, cache.Get(makeTestHash(3)))
+	assert.Nil(t, cache.Get(makeTestHash(4)), "the .json cap is full")
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(2), stats.Rejected)
+	assert.Equal(t, int64(1), stats.Extensions[".json"].Rejected)
+	assert.Equal(t, int64(1), stats.Extensions[".json"].Hits)
+	assert.Equal(t, int64(200), stats.Extensions[".json"].BytesServed)
+	assert.Equal(t, int64(1), stats.Extensions[".svg"].Rejected)
+}
+
+func TestBlobExtension(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, ".json", framework.BlobExtension("data/Fixture.JSON"))
+	assert.Equal(t, ".go", framework.BlobExtension("main.go"))
+	assert.Empty(t, framework.BlobExtension("Makefile"))
+}
+
+func TestCacheStats_SinceAndAdd(t *testing.T) {
+	t.Parallel()
+
+	cache := framework.NewGlobalBlobCache(1000)
+	cache.Put(makeTestHash(1), makeTestBlob(make([]byte, 100)))
+	cache.Get(makeTestHash(1))
+
+	before := cache.Stats()
+
+	cache.Put(makeTestHash(2), makeTestBlob(make([]byte, 300)))
+	cache.Get(makeTestHash(2))
+	cache.Get(makeTestHash(3))
+
+	delta := cache.Stats().Since(before)
+	assert.Equal(t, int64(1), delta.Hits)
+	assert.Equal(t, int64(1), delta.Misses)
+	assert.Equal(t, int64(1), delta.Admitted)
+	assert.Equal(t, int64(300), delta.BytesServed)
+	assert.Equal(t, int64(1), delta.Extensions[""].Hits)
+
+	var total framework.CacheStats
+
+	total.Add(before)
+	total.Add(delta)
+	assert.Equal(t, int64(2), total.Hits)
+	assert.Equal(t, int64(400), total.BytesServed)
+	assert.Equal(t, int64(400), total.PeakSize)
+	assert.Equal(t, int64(2), total.Extensions[""].Admitted)
+}
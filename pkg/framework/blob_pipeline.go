@@ -28,6 +28,17 @@ type BlobPipeline struct {
 	WorkerCount    int
 	BlobCache      *GlobalBlobCache
 	ArenaSize      int
+
+	// SkipBlobs emits tree diff changes without loading any blob.
+	SkipBlobs bool
+
+	// PackIndex, when set, sorts the blob loads of a batch by pack position
+	// and hands each worker a contiguous run of the packs.
+	PackIndex *gitlib.PackIndex
+
+	// LFS, when set, replaces loaded Git LFS pointers with their objects
+	// before they are cached.
+	LFS *gitlib.LFSStore
 }
 
 // NewBlobPipeline creates a new blob pipeline.
@@ -69,6 +80,7 @@ func NewBlobPipelineWithCache(
 type batchBlobState struct {
 	respChans []chan gitlib.BlobBatchResponse // Slice of response channels for sharded requests.
 	results   map[gitlib.Hash]*gitlib.CachedBlob
+	exts      map[gitlib.Hash]string // File extension of each blob, for the cache.
 	once      sync.Once
 }
 
@@ -127,9 +139,15 @@ func (p *BlobPipeline) processBatch(
 		respChan := make(chan gitlib.TreeDiffResponse, 1)
 
 		// With first-parent walk, previous in stream equals parent; diff base must match burndown state.
+		// A sampled walk diffs against the previous sampled commit, so the changes of the skipped
+		// commits are not lost.
 		var prevHash gitlib.Hash
 
+		sampleBase, sampled := commit.SampleBase()
+
 		switch {
+		case sampled:
+			prevHash = sampleBase
 		case commit.NumParents() > 0:
 			prevHash = commit.ParentHash(0)
 		case i > 0:
@@ -162,6 +180,7 @@ func (p *BlobPipeline) processBatch(
 	// Collect Tree Diffs.
 	batchJobs := make([]blobJob, len(batch.Commits))
 	allNeededHashes := make(map[gitlib.Hash]bool)
+	neededExts := make(map[gitlib.Hash]string)
 
 	var lastCommitHash gitlib.Hash
 
@@ -182,8 +201,9 @@ func (p *BlobPipeline) processBatch(
 			},
 		}
 
-		if resp.Error == nil {
+		if resp.Error == nil && !p.SkipBlobs {
 			hashes := p.collectBlobHashes(resp.Changes)
+			collectBlobExtensions(resp.Changes, neededExts)
 
 			bJob.neededHash = hashes
 			for _, h := range hashes {
@@ -216,19 +236,10 @@ func (p *BlobPipeline) processBatch(
 	// Prepare shared batch state.
 	batchState := &batchBlobState{
 		results: make(map[gitlib.Hash]*gitlib.CachedBlob),
+		exts:    neededExts,
 	}
 
-	// Determine sharding.
-	var chunkCount = 1
-	if p.WorkerCount > 1 && len(missingHashes) > p.WorkerCount*2 { // Shard if enough items.
-		chunkCount = p.WorkerCount
-	}
-
-	chunks := make([][]gitlib.Hash, chunkCount)
-	for i, h := range missingHashes {
-		idx := i % chunkCount
-		chunks[idx] = append(chunks[idx], h)
-	}
+	chunks := p.shardHashes(missingHashes)
 
 	// Fire batch requests.
 	for _, chunk := range chunks {
@@ -280,6 +291,37 @@ func (p *BlobPipeline) processBatch(
 	return lastCommitHash
 }
 
+// shardHashes splits the blobs to load into one request per worker when
+// there are enough of them. Without a pack index the blobs are dealt round
+// robin; with one, each worker gets a contiguous run in pack order, so reads
+// follow the pack files instead of jumping around the object database.
+func (p *BlobPipeline) shardHashes(hashes []gitlib.Hash) [][]gitlib.Hash {
+	chunkCount := 1
+	if p.WorkerCount > 1 && len(hashes) > p.WorkerCount*2 { // Shard if enough items.
+		chunkCount = p.WorkerCount
+	}
+
+	chunks := make([][]gitlib.Hash, chunkCount)
+
+	if p.PackIndex != nil {
+		p.PackIndex.Sort(hashes)
+
+		size := (len(hashes) + chunkCount - 1) / chunkCount
+		for i := range chunks {
+			chunks[i] = hashes[min(i*size, len(hashes)):min((i+1)*size, len(hashes))]
+		}
+
+		return chunks
+	}
+
+	for i, h := range hashes {
+		idx := i % chunkCount
+		chunks[idx] = append(chunks[idx], h)
+	}
+
+	return chunks
+}
+
 // runConsumer waits for blob responses and outputs blob data.
 func (p *BlobPipeline) runConsumer(ctx context.Context, jobs <-chan blobJob, out chan<- BlobData) {
 	defer close(out)
@@ -335,6 +377,10 @@ func (p *BlobPipeline) collectBlobResponse(ctx context.Context, job *blobJob) bo
 				// So we can just use resp.Blobs.
 				for _, blob := range resp.Blobs {
 					if blob != nil {
+						if p.LFS != nil {
+							blob = p.LFS.Resolve(blob)
+						}
+
 						// We need the hash. CachedBlob has Hash() method?
 						// Let's check CachedBlob definition.
 						job.batchState.results[blob.Hash()] = blob
@@ -350,7 +396,7 @@ func (p *BlobPipeline) collectBlobResponse(ctx context.Context, job *blobJob) bo
 
 		// Store new blobs in global cache.
 		if p.BlobCache != nil && len(allNewBlobs) > 0 {
-			p.BlobCache.PutMulti(allNewBlobs)
+			p.BlobCache.PutMultiWithExtensions(allNewBlobs, job.batchState.exts)
 		}
 	})
 
@@ -382,6 +428,22 @@ const (
 	FileModeLink   = 0o120000
 )
 
+// collectBlobExtensions records the file extension of every blob of changes
+// in exts, for extension caps and per-extension stats of the blob cache.
+func collectBlobExtensions(changes gitlib.Changes, exts map[gitlib.Hash]string) {
+	for _, change := range changes {
+		switch change.Action {
+		case gitlib.Insert:
+			exts[change.To.Hash] = BlobExtension(change.To.Name)
+		case gitlib.Delete:
+			exts[change.From.Hash] = BlobExtension(change.From.Name)
+		case gitlib.Modify:
+			exts[change.From.Hash] = BlobExtension(change.From.Name)
+			exts[change.To.Hash] = BlobExtension(change.To.Name)
+		}
+	}
+}
+
 func (p *BlobPipeline) collectBlobHashes(changes gitlib.Changes) []gitlib.Hash {
 	hashSet := make(map[gitlib.Hash]bool)
 
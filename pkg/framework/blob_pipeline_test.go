@@ -1,6 +1,7 @@
 package framework_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/framework"
@@ -46,3 +47,32 @@ func TestBlobPipeline_NewBlobPipelineZeroBufferSize(t *testing.T) {
 		t.Errorf("BufferSize = %d, want 1 (normalized)", p.BufferSize)
 	}
 }
+
+func TestBlobPipeline_ShardHashes(t *testing.T) {
+	t.Parallel()
+
+	hashes := make([]gitlib.Hash, 6)
+	for i := range hashes {
+		hashes[i][0] = byte(6 - i)
+	}
+
+	p := framework.NewBlobPipeline(nil, nil, 1, 2)
+
+	chunks := p.ShardHashesForTest(slices.Clone(hashes))
+	if len(chunks) != 2 || chunks[0][0] != hashes[0] || chunks[1][0] != hashes[1] {
+		t.Errorf("round robin shards = %v", chunks)
+	}
+
+	// Loose objects sort by hash, and each worker gets a contiguous run.
+	idx, err := gitlib.LoadPackIndex(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.PackIndex = idx
+	chunks = p.ShardHashesForTest(slices.Clone(hashes))
+
+	if len(chunks) != 2 || chunks[0][0][0] != 1 || chunks[0][2][0] != 3 || chunks[1][0][0] != 4 {
+		t.Errorf("pack order shards = %v", chunks)
+	}
+}
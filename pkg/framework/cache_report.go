@@ -0,0 +1,83 @@
+package framework
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// recordBlobCacheStats accumulates the blob cache counters of one chunk.
+func (runner *Runner) recordBlobCacheStats(stats CacheStats) {
+	runner.blobCacheStats.Add(stats)
+}
+
+// BlobCacheStats returns the blob cache efficiency of the chunks processed so
+// far, or nil when no blob was looked up in the cache.
+func (runner *Runner) BlobCacheStats() *analyze.BlobCacheStats {
+	s := runner.blobCacheStats
+	if s.Hits+s.Misses == 0 {
+		return nil
+	}
+
+	bs := &analyze.BlobCacheStats{
+		MaxSize:     s.MaxSize,
+		PeakSize:    s.PeakSize,
+		Hits:        s.Hits,
+		Misses:      s.Misses,
+		BytesServed: s.BytesServed,
+		Admitted:    s.Admitted,
+		Rejected:    s.Rejected,
+		Evicted:     s.Evicted,
+		Promoted:    s.Promoted,
+		Extensions:  make([]analyze.BlobCacheExtension, 0, len(s.Extensions)),
+	}
+
+	for ext, es := range s.Extensions {
+		bs.Extensions = append(bs.Extensions, analyze.BlobCacheExtension{
+			Extension:   ext,
+			Hits:        es.Hits,
+			BytesServed: es.BytesServed,
+			Admitted:    es.Admitted,
+			Rejected:    es.Rejected,
+			Evicted:     es.Evicted,
+		})
+	}
+
+	slices.SortFunc(bs.Extensions, func(a, b analyze.BlobCacheExtension) int {
+		return cmp.Or(cmp.Compare(b.BytesServed, a.BytesServed), cmp.Compare(a.Extension, b.Extension))
+	})
+
+	return bs
+}
+
+// injectBlobCacheStats adds the blob cache efficiency into every leaf report
+// under analyze.ReportKeyBlobCache when the run used the blob cache.
+func (runner *Runner) injectBlobCacheStats(reports map[analyze.HistoryAnalyzer]analyze.Report) {
+	bs := runner.BlobCacheStats()
+	if bs == nil {
+		return
+	}
+
+	for _, report := range reports {
+		if report != nil {
+			report[analyze.ReportKeyBlobCache] = bs
+		}
+	}
+}
+
+// logBlobCacheStats logs the blob cache efficiency of the run.
+func logBlobCacheStats(ctx context.Context, logger *slog.Logger, runner *Runner) {
+	bs := runner.BlobCacheStats()
+	if bs == nil {
+		return
+	}
+
+	logger.InfoContext(ctx, "blob cache: run summary",
+		"hit_rate", bs.HitRate(), "bytes_served", bs.BytesServed,
+		"max_size", bs.MaxSize, "peak_size", bs.PeakSize,
+		"admitted", bs.Admitted, "rejected", bs.Rejected,
+		"evicted", bs.Evicted, "promoted", bs.Promoted)
+}
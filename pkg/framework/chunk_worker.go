@@ -0,0 +1,475 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+)
+
+// ErrChunkWorker is returned when no chunk worker could run a chunk.
+var ErrChunkWorker = errors.New("chunk worker failed")
+
+// ChunkWorkerPath is the endpoint of a chunk worker that runs chunk pipelines.
+const ChunkWorkerPath = "/api/chunk"
+
+// chunkWorkerTimeout bounds one chunk request. A chunk runs the blob, diff
+// and UAST stages of up to a few thousand commits.
+const chunkWorkerTimeout = 30 * time.Minute
+
+// maxChunkErrorBody is the number of bytes of an error response kept in the
+// returned error.
+const maxChunkErrorBody = 1024
+
+// ChunkWorkers runs the pipelines of chunks on remote chunk workers, each
+// serving ChunkWorkerHandler over a mirror of the analyzed repository.
+// Chunks are handed to the workers in turn; a chunk a worker fails to run
+// is sent to the next one. It is safe for concurrent use.
+type ChunkWorkers struct {
+	// Endpoints are the base URLs of the workers, e.g. "http://worker1:8091".
+	Endpoints []string
+	// Client sends the requests. Nil means a client with a 30m timeout.
+	Client *http.Client
+	// Codec compresses requests and responses. Nil means uncompressed.
+	Codec codec.Codec
+
+	next atomic.Uint64
+}
+
+// NewChunkWorkers creates ChunkWorkers for the workers at endpoints,
+// compressing with zstd.
+func NewChunkWorkers(endpoints []string) *ChunkWorkers {
+	trimmed := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		trimmed[i] = strings.TrimSuffix(endpoint, "/")
+	}
+
+	// Parse knows zstd; a nil codec would only leave the wire uncompressed.
+	wireCodec, err := codec.Parse(codec.Zstd)
+	if err != nil {
+		wireCodec = nil
+	}
+
+	return &ChunkWorkers{
+		Endpoints: trimmed,
+		Client:    &http.Client{Timeout: chunkWorkerTimeout},
+		Codec:     wireCodec,
+	}
+}
+
+// chunkOptions are the coordinator settings that change what a chunk
+// pipeline produces. Settings that only tune its speed, such as worker
+// counts and cache sizes, are left to each worker.
+type chunkOptions struct {
+	FirstParent   bool
+	DiffAlgorithm gitlib.DiffAlgorithm
+	DiffTimeout   time.Duration
+	NormalizeEOL  bool
+	SkipBlobs     bool
+	SkipDiffs     bool
+	ResolveLFS    bool
+	TextEncoding  gitlib.TextEncoding
+	ParseUAST     bool
+}
+
+func chunkOptionsOf(config CoordinatorConfig) chunkOptions {
+	return chunkOptions{
+		FirstParent:   config.FirstParent,
+		DiffAlgorithm: config.DiffAlgorithm,
+		DiffTimeout:   config.DiffTimeout,
+		NormalizeEOL:  config.NormalizeEOL,
+		SkipBlobs:     config.SkipBlobs,
+		SkipDiffs:     config.SkipDiffs,
+		ResolveLFS:    config.ResolveLFS,
+		TextEncoding:  config.TextEncoding,
+		ParseUAST:     config.UASTPipelineWorkers > 0,
+	}
+}
+
+// apply returns config with the options of the aggregator.
+func (o chunkOptions) apply(config CoordinatorConfig) CoordinatorConfig {
+	config.FirstParent = o.FirstParent
+	config.DiffAlgorithm = o.DiffAlgorithm
+	config.DiffTimeout = o.DiffTimeout
+	config.NormalizeEOL = o.NormalizeEOL
+	config.SkipBlobs = o.SkipBlobs
+	config.SkipDiffs = o.SkipDiffs
+	config.ResolveLFS = o.ResolveLFS
+	config.TextEncoding = o.TextEncoding
+	config.ChunkWorkers = nil
+
+	if !o.ParseUAST {
+		config.UASTPipelineWorkers = 0
+	} else if config.UASTPipelineWorkers <= 0 {
+		config.UASTPipelineWorkers = 1
+	}
+
+	return config
+}
+
+// chunkCommit identifies one commit of a chunk request.
+type chunkCommit struct {
+	Hash       gitlib.Hash
+	SampleBase gitlib.Hash
+}
+
+// chunkRequest is the body of a chunk request.
+type chunkRequest struct {
+	Commits []chunkCommit
+	Options chunkOptions
+}
+
+// chunkBlob is a loaded blob of a chunk response.
+type chunkBlob struct {
+	Hash     gitlib.Hash
+	Size     int64
+	Encoding gitlib.Encoding
+	Data     []byte
+}
+
+// chunkCommitData is the CommitData of one commit of a chunk response. The
+// commit itself is not sent: the aggregator holds it at Index.
+type chunkCommitData struct {
+	Index       int
+	Changes     gitlib.Changes
+	Blobs       []chunkBlob
+	FileDiffs   map[string]plumbing.FileDiffData
+	UASTChanges []uast.Change
+	Error       string
+}
+
+// chunkResponse is the body of a chunk response.
+type chunkResponse struct {
+	Commits []chunkCommitData
+	Stats   PipelineStats
+}
+
+// encodeCommitData converts data into its wire form.
+func encodeCommitData(data CommitData) chunkCommitData {
+	wire := chunkCommitData{
+		Index:       data.Index,
+		Changes:     data.Changes,
+		FileDiffs:   data.FileDiffs,
+		UASTChanges: data.UASTChanges,
+	}
+
+	if len(data.BlobCache) > 0 {
+		wire.Blobs = make([]chunkBlob, 0, len(data.BlobCache))
+
+		for hash, blob := range data.BlobCache {
+			if blob == nil {
+				continue
+			}
+
+			wire.Blobs = append(wire.Blobs, chunkBlob{
+				Hash: hash, Size: blob.Size(), Encoding: blob.Encoding(), Data: blob.Data,
+			})
+		}
+	}
+
+	if data.Error != nil {
+		wire.Error = data.Error.Error()
+	}
+
+	return wire
+}
+
+// decodeCommitData rebuilds the CommitData of wire for commits, the commits
+// of the chunk request.
+func decodeCommitData(wire chunkCommitData, commits []*gitlib.Commit) (CommitData, error) {
+	if wire.Index < 0 || wire.Index >= len(commits) {
+		return CommitData{}, fmt.Errorf("%w: commit index %d out of %d", ErrChunkWorker, wire.Index, len(commits))
+	}
+
+	data := CommitData{
+		Commit:      commits[wire.Index],
+		Index:       wire.Index,
+		Changes:     wire.Changes,
+		FileDiffs:   wire.FileDiffs,
+		UASTChanges: wire.UASTChanges,
+	}
+
+	if len(wire.Blobs) > 0 {
+		data.BlobCache = make(map[gitlib.Hash]*gitlib.CachedBlob, len(wire.Blobs))
+
+		for _, blob := range wire.Blobs {
+			data.BlobCache[blob.Hash] = gitlib.RestoreCachedBlob(blob.Hash, blob.Size, blob.Data, blob.Encoding)
+		}
+	}
+
+	if wire.Error != "" {
+		data.Error = fmt.Errorf("%w: %s", ErrChunkWorker, wire.Error)
+	}
+
+	return data, nil
+}
+
+// Run runs the pipeline of commits with the options of config on a worker,
+// trying every worker once, and returns the CommitData of each commit in
+// order with the stats of the worker's pipeline.
+func (cw *ChunkWorkers) Run(
+	ctx context.Context, config CoordinatorConfig, commits []*gitlib.Commit,
+) ([]CommitData, PipelineStats, error) {
+	if len(cw.Endpoints) == 0 {
+		return nil, PipelineStats{}, fmt.Errorf("%w: no workers", ErrChunkWorker)
+	}
+
+	req := chunkRequest{Commits: make([]chunkCommit, len(commits)), Options: chunkOptionsOf(config)}
+
+	for i, commit := range commits {
+		base, _ := commit.SampleBase()
+		req.Commits[i] = chunkCommit{Hash: commit.Hash(), SampleBase: base}
+	}
+
+	body, err := codec.Marshal(cw.Codec, req)
+	if err != nil {
+		return nil, PipelineStats{}, fmt.Errorf("encode chunk request: %w", err)
+	}
+
+	first := cw.next.Add(1) - 1
+
+	var errs []error
+
+	for i := range len(cw.Endpoints) {
+		endpoint := cw.Endpoints[(first+uint64(i))%uint64(len(cw.Endpoints))]
+
+		resp, sendErr := cw.send(ctx, endpoint, body, len(commits))
+		if sendErr == nil {
+			data, decodeErr := decodeChunk(resp, commits)
+			if decodeErr == nil {
+				return data, resp.Stats, nil
+			}
+
+			sendErr = decodeErr
+		}
+
+		if ctx.Err() != nil {
+			return nil, PipelineStats{}, ctx.Err()
+		}
+
+		errs = append(errs, sendErr)
+	}
+
+	return nil, PipelineStats{}, errors.Join(errs...)
+}
+
+// decodeChunk rebuilds the CommitData of resp in commit order.
+func decodeChunk(resp chunkResponse, commits []*gitlib.Commit) ([]CommitData, error) {
+	if len(resp.Commits) != len(commits) {
+		return nil, fmt.Errorf("%w: got %d commits, want %d", ErrChunkWorker, len(resp.Commits), len(commits))
+	}
+
+	data := make([]CommitData, len(resp.Commits))
+
+	for i, wire := range resp.Commits {
+		cd, err := decodeCommitData(wire, commits)
+		if err != nil {
+			return nil, err
+		}
+
+		data[i] = cd
+	}
+
+	return data, nil
+}
+
+// send posts one chunk request to the worker at endpoint.
+func (cw *ChunkWorkers) send(ctx context.Context, endpoint string, body []byte, size int) (chunkResponse, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "codefang.chunk_worker.run",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("chunk_worker.endpoint", endpoint),
+			attribute.Int("chunk.size", size),
+		))
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+ChunkWorkerPath, bytes.NewReader(body))
+	if err != nil {
+		return chunkResponse{}, fmt.Errorf("create chunk request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", codec.OrNone(cw.Codec).Name())
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	client := cw.Client
+	if client == nil {
+		client = &http.Client{Timeout: chunkWorkerTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return chunkResponse{}, fmt.Errorf("%w: %s: %w", ErrChunkWorker, endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxChunkErrorBody))
+
+		return chunkResponse{}, fmt.Errorf("%w: %s: status %d: %s",
+			ErrChunkWorker, endpoint, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return chunkResponse{}, fmt.Errorf("%w: %s: read response: %w", ErrChunkWorker, endpoint, err)
+	}
+
+	var decoded chunkResponse
+
+	err = codec.Unmarshal(cw.Codec, payload, &decoded)
+	if err != nil {
+		return chunkResponse{}, fmt.Errorf("%w: %s: decode response: %w", ErrChunkWorker, endpoint, err)
+	}
+
+	return decoded, nil
+}
+
+// processRemote runs the pipeline of commits on the chunk workers. When no
+// worker can run the chunk, every commit carries the error, so the Runner's
+// commit error policy decides whether the run goes on.
+func (c *Coordinator) processRemote(ctx context.Context, commits []*gitlib.Commit) <-chan CommitData {
+	out := make(chan CommitData)
+
+	go func() {
+		defer close(out)
+
+		data, stats, err := c.config.ChunkWorkers.Run(ctx, c.config, commits)
+		if err != nil {
+			data = make([]CommitData, len(commits))
+			for i, commit := range commits {
+				data[i] = CommitData{Commit: commit, Index: i, Error: err}
+			}
+		}
+
+		c.stats = stats
+
+		for _, cd := range data {
+			select {
+			case out <- cd:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// ChunkWorkerHandler serves chunk requests of ChunkWorkers: it runs the
+// pipeline of the requested commits on the repository at repoPath, a mirror
+// of the analyzed one, with config tuned by the options of the request.
+type ChunkWorkerHandler struct {
+	repoPath string
+	config   CoordinatorConfig
+}
+
+// NewChunkWorkerHandler creates a ChunkWorkerHandler for the repository at
+// repoPath.
+func NewChunkWorkerHandler(repoPath string, config CoordinatorConfig) *ChunkWorkerHandler {
+	return &ChunkWorkerHandler{repoPath: repoPath, config: config}
+}
+
+// ServeHTTP runs one chunk request.
+func (h *ChunkWorkerHandler) ServeHTTP(rw http.ResponseWriter, hr *http.Request) {
+	if hr.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	wireCodec, err := codec.Parse(hr.Header.Get("Content-Encoding"))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	payload, err := io.ReadAll(hr.Body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var req chunkRequest
+
+	err = codec.Unmarshal(wireCodec, payload, &req)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	resp, err := h.run(hr.Context(), req)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	body, err := codec.Marshal(wireCodec, resp)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/octet-stream")
+	rw.Header().Set("Content-Encoding", wireCodec.Name())
+	_, _ = rw.Write(body)
+}
+
+// run runs the pipeline of the commits of req on a fresh repository handle.
+func (h *ChunkWorkerHandler) run(ctx context.Context, req chunkRequest) (chunkResponse, error) {
+	repo, err := gitlib.OpenRepository(h.repoPath)
+	if err != nil {
+		return chunkResponse{}, fmt.Errorf("open repository: %w", err)
+	}
+	defer repo.Free()
+
+	commits := make([]*gitlib.Commit, 0, len(req.Commits))
+
+	defer func() { freeCommits(commits) }()
+
+	for _, cc := range req.Commits {
+		commit, lookupErr := repo.LookupCommit(ctx, cc.Hash)
+		if lookupErr != nil {
+			return chunkResponse{}, fmt.Errorf("commit %s: %w", cc.Hash, lookupErr)
+		}
+
+		commit.SetSampleBase(cc.SampleBase)
+		commits = append(commits, commit)
+	}
+
+	coordinator := NewCoordinator(repo, req.Options.apply(h.config))
+
+	resp := chunkResponse{Commits: make([]chunkCommitData, 0, len(commits))}
+
+	for cd := range coordinator.Process(ctx, commits) {
+		resp.Commits = append(resp.Commits, encodeCommitData(cd))
+	}
+
+	if ctx.Err() != nil {
+		return chunkResponse{}, ctx.Err()
+	}
+
+	resp.Stats = coordinator.Stats()
+
+	return resp, nil
+}
@@ -0,0 +1,206 @@
+package framework
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// fakeChunkWorker serves chunk requests by answering each commit with the
+// CommitData of respond.
+func fakeChunkWorker(t *testing.T, respond func(int, chunkCommit) CommitData) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wireCodec, err := codec.Parse(r.Header.Get("Content-Encoding"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		payload, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		var req chunkRequest
+
+		err = codec.Unmarshal(wireCodec, payload, &req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		resp := chunkResponse{Stats: PipelineStats{BlobCacheHits: int64(len(req.Commits))}}
+		for i, cc := range req.Commits {
+			resp.Commits = append(resp.Commits, encodeCommitData(respond(i, cc)))
+		}
+
+		body, err := codec.Marshal(wireCodec, resp)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		_, _ = w.Write(body)
+	}))
+}
+
+func TestChunkWorkers_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	blobHash := gitlib.NewHash("1111111111111111111111111111111111111111")
+	change := &gitlib.Change{
+		Action: gitlib.Modify,
+		From:   gitlib.ChangeEntry{Name: "main.go", Hash: blobHash, Size: 5},
+		To:     gitlib.ChangeEntry{Name: "main.go", Hash: blobHash, Size: 5},
+	}
+
+	var (
+		mu    sync.Mutex
+		bases []gitlib.Hash
+	)
+
+	server := fakeChunkWorker(t, func(i int, cc chunkCommit) CommitData {
+		mu.Lock()
+		bases = append(bases, cc.SampleBase)
+		mu.Unlock()
+
+		return CommitData{
+			Index:     i,
+			Changes:   gitlib.Changes{change},
+			BlobCache: map[gitlib.Hash]*gitlib.CachedBlob{blobHash: gitlib.NewCachedBlobWithHashForTest(blobHash, []byte("hello"))},
+			FileDiffs: map[string]plumbing.FileDiffData{
+				"main.go": {Diffs: []diffmatchpatch.Diff{{Type: diffmatchpatch.DiffEqual, Text: "hello"}}, OldLinesOfCode: 1, NewLinesOfCode: 1},
+			},
+			UASTChanges: []uast.Change{{After: &node.Node{Type: "File", Token: "main"}, Change: change}},
+		}
+	})
+	defer server.Close()
+
+	first := gitlib.NewCommitForTest(gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"))
+	second := gitlib.NewCommitForTest(gitlib.NewHash("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"))
+	second.SetSampleBase(first.Hash())
+
+	workers := NewChunkWorkers([]string{server.URL + "/"})
+
+	data, stats, err := workers.Run(context.Background(), DefaultCoordinatorConfig(), []*gitlib.Commit{first, second})
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+
+	mu.Lock()
+	assert.Equal(t, []gitlib.Hash{{}, first.Hash()}, bases)
+	mu.Unlock()
+	assert.Equal(t, int64(2), stats.BlobCacheHits)
+
+	for i, cd := range data {
+		assert.Same(t, []*gitlib.Commit{first, second}[i], cd.Commit)
+		assert.Equal(t, i, cd.Index)
+		assert.Equal(t, gitlib.Changes{change}, cd.Changes)
+		require.Contains(t, cd.BlobCache, blobHash)
+		assert.Equal(t, []byte("hello"), cd.BlobCache[blobHash].Data)
+		assert.Equal(t, int64(5), cd.BlobCache[blobHash].Size())
+		assert.Equal(t, 1, cd.FileDiffs["main.go"].NewLinesOfCode)
+		require.Len(t, cd.UASTChanges, 1)
+		assert.Equal(t, "main", cd.UASTChanges[0].After.Token)
+		assert.NoError(t, cd.Error)
+	}
+}
+
+func TestChunkWorkers_CommitError(t *testing.T) {
+	t.Parallel()
+
+	server := fakeChunkWorker(t, func(i int, _ chunkCommit) CommitData {
+		return CommitData{Index: i, Error: assert.AnError}
+	})
+	defer server.Close()
+
+	commit := gitlib.NewCommitForTest(gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"))
+
+	data, _, err := NewChunkWorkers([]string{server.URL}).Run(context.Background(), DefaultCoordinatorConfig(), []*gitlib.Commit{commit})
+	require.NoError(t, err)
+	require.Len(t, data, 1)
+	require.ErrorIs(t, data[0].Error, ErrChunkWorker)
+	assert.Contains(t, data[0].Error.Error(), assert.AnError.Error())
+}
+
+func TestChunkWorkers_FailsOver(t *testing.T) {
+	t.Parallel()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "no such commit", http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	working := fakeChunkWorker(t, func(i int, _ chunkCommit) CommitData { return CommitData{Index: i} })
+	defer working.Close()
+
+	commit := gitlib.NewCommitForTest(gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"))
+	workers := NewChunkWorkers([]string{broken.URL, working.URL})
+
+	for range 2 {
+		data, _, err := workers.Run(context.Background(), DefaultCoordinatorConfig(), []*gitlib.Commit{commit})
+		require.NoError(t, err)
+		assert.Len(t, data, 1)
+	}
+}
+
+func TestChunkWorkers_AllFail(t *testing.T) {
+	t.Parallel()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "no such commit", http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	commit := gitlib.NewCommitForTest(gitlib.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"))
+
+	_, _, err := NewChunkWorkers([]string{broken.URL}).Run(context.Background(), DefaultCoordinatorConfig(), []*gitlib.Commit{commit})
+	require.ErrorIs(t, err, ErrChunkWorker)
+	assert.Contains(t, err.Error(), "no such commit")
+
+	_, _, err = NewChunkWorkers(nil).Run(context.Background(), DefaultCoordinatorConfig(), []*gitlib.Commit{commit})
+	require.ErrorIs(t, err, ErrChunkWorker)
+}
+
+func TestChunkOptions_Apply(t *testing.T) {
+	t.Parallel()
+
+	run := DefaultCoordinatorConfig()
+	run.SkipBlobs = true
+	run.NormalizeEOL = true
+	run.DiffAlgorithm = gitlib.DiffPatience
+	run.UASTPipelineWorkers = 0
+	run.Workers = 64
+	run.ChunkWorkers = NewChunkWorkers([]string{"http://worker:8091"})
+
+	worker := DefaultCoordinatorConfig()
+	worker.Workers = 3
+
+	got := chunkOptionsOf(run).apply(worker)
+
+	assert.True(t, got.SkipBlobs)
+	assert.True(t, got.NormalizeEOL)
+	assert.Equal(t, run.DiffAlgorithm, got.DiffAlgorithm)
+	assert.Zero(t, got.UASTPipelineWorkers)
+	assert.Equal(t, 3, got.Workers)
+	assert.Nil(t, got.ChunkWorkers)
+}
@@ -0,0 +1,189 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// CommitErrorPolicy selects how the Runner reacts when a single commit fails,
+// either in the blob/diff pipeline or inside an analyzer's Consume.
+type CommitErrorPolicy string
+
+const (
+	// CommitErrorAbort stops the run on the first failing commit (default).
+	CommitErrorAbort CommitErrorPolicy = "abort"
+
+	// CommitErrorSkip logs the failure, records it in the run quality section,
+	// and continues with the next commit.
+	CommitErrorSkip CommitErrorPolicy = "skip"
+
+	// CommitErrorRetry re-runs the blob/diff pipeline for a failing commit
+	// before falling back to skip. Analyzer failures are not retried because
+	// analyzers may have partially mutated their state.
+	CommitErrorRetry CommitErrorPolicy = "retry"
+)
+
+// commitRetryAttempts is the total number of pipeline attempts for one commit
+// under CommitErrorRetry, including the original one.
+const commitRetryAttempts = 3
+
+// stagePipeline is the CommitFailure stage for blob/diff pipeline errors.
+const stagePipeline = "pipeline"
+
+// ErrUnknownCommitErrorPolicy is returned for unrecognized --on-commit-error values.
+var ErrUnknownCommitErrorPolicy = errors.New("unknown commit error policy")
+
+// ErrAnalyzerPanic wraps a panic recovered from an analyzer's Consume.
+var ErrAnalyzerPanic = errors.New("analyzer panicked")
+
+// ParseCommitErrorPolicy parses a policy name. Empty input maps to CommitErrorAbort.
+func ParseCommitErrorPolicy(s string) (CommitErrorPolicy, error) {
+	switch policy := CommitErrorPolicy(s); policy {
+	case "":
+		return CommitErrorAbort, nil
+	case CommitErrorAbort, CommitErrorSkip, CommitErrorRetry:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want skip, retry or abort)", ErrUnknownCommitErrorPolicy, s)
+	}
+}
+
+// abortOnCommitError reports whether commit failures should stop the run.
+func (runner *Runner) abortOnCommitError() bool {
+	return runner.OnCommitError == "" || runner.OnCommitError == CommitErrorAbort
+}
+
+// consumeSafely calls a.Consume and converts a panic into an ErrAnalyzerPanic error,
+// so a parser crash on one commit surfaces like any other analyzer error.
+func consumeSafely(ctx context.Context, a analyze.HistoryAnalyzer, ac *analyze.Context) (tc analyze.TC, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %s: %v", ErrAnalyzerPanic, a.Name(), r)
+		}
+	}()
+
+	consumeLabeled(ctx, a, func(ctx context.Context) {
+		tc, err = a.Consume(ctx, ac)
+	})
+
+	return tc, err
+}
+
+// retryCommitData re-runs the pipeline for a commit whose data carries an error.
+// Only active under CommitErrorRetry. A fresh repository handle is used so the
+// retry does not share libgit2 state with the chunk's Coordinator.
+// Returns the last result and the number of attempts made.
+func (runner *Runner) retryCommitData(ctx context.Context, data CommitData) (CommitData, int) {
+	attempts := 1
+
+	if runner.OnCommitError != CommitErrorRetry || data.Commit == nil {
+		return data, attempts
+	}
+
+	repoPath := runner.RepoPath
+	if repoPath == "" && runner.Repo != nil {
+		repoPath = runner.Repo.Path()
+	}
+
+	repo, openErr := gitlib.OpenRepository(repoPath)
+	if openErr != nil {
+		return data, attempts
+	}
+	defer repo.Free()
+
+	for attempts < commitRetryAttempts && data.Error != nil && ctx.Err() == nil {
+		attempts++
+
+		index := data.Index
+		data = NewCoordinator(repo, runner.Config).ProcessSingle(ctx, data.Commit, index)
+		data.Index = index
+	}
+
+	return data, attempts
+}
+
+// recordCommitFailure logs a failed commit with its context and adds it to
+// the run quality stats reported by FinalizeWithAggregators.
+func (runner *Runner) recordCommitFailure(
+	ctx context.Context, hash string, index int, stage string, attempts int, err error,
+) {
+	failure := analyze.CommitFailure{
+		Hash:     hash,
+		Index:    index,
+		Stage:    stage,
+		Attempts: attempts,
+		Error:    err.Error(),
+	}
+
+	if runner.Logger != nil {
+		runner.Logger.WarnContext(ctx, "skipping failed commit",
+			slog.String("hash", failure.Hash),
+			slog.Int("index", index),
+			slog.String("stage", stage),
+			slog.Int("attempts", attempts),
+			slog.String("policy", string(runner.OnCommitError)),
+			slog.Any("error", err))
+	}
+
+	if runner.failedCommits == nil {
+		runner.failedCommits = make(map[string]struct{})
+	}
+
+	key := failure.Hash
+	if key == "" {
+		key = fmt.Sprintf("#%d", index)
+	}
+
+	if _, seen := runner.failedCommits[key]; !seen {
+		runner.failedCommits[key] = struct{}{}
+		runner.quality.SkippedCommits++
+	}
+
+	if errors.Is(err, ErrAnalyzerTimeout) || errors.Is(err, ErrAnalyzerStuck) {
+		runner.quality.Timeouts++
+	}
+
+	if errors.Is(err, ErrAnalyzerStuck) {
+		runner.quality.Disabled = append(runner.quality.Disabled, stage)
+	}
+
+	runner.quality.Failures = append(runner.quality.Failures, failure)
+}
+
+// commitHashString returns the hex hash of a commit, or "" when it is nil.
+func commitHashString(commit analyze.CommitIdentity) string {
+	if gc, ok := commit.(*gitlib.Commit); commit == nil || (ok && gc == nil) {
+		return ""
+	}
+
+	return commit.Hash().String()
+}
+
+// Quality returns the commits skipped so far, or nil when none failed.
+func (runner *Runner) Quality() *analyze.QualityStats {
+	if len(runner.quality.Failures) == 0 {
+		return nil
+	}
+
+	return &runner.quality
+}
+
+// injectRunQuality adds the run quality stats into every leaf report
+// under analyze.ReportKeyRunQuality when at least one commit failed.
+func (runner *Runner) injectRunQuality(reports map[analyze.HistoryAnalyzer]analyze.Report) {
+	qs := runner.Quality()
+	if qs == nil {
+		return
+	}
+
+	for _, report := range reports {
+		if report != nil {
+			report[analyze.ReportKeyRunQuality] = qs
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package framework_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// failingLeaf is a stubLeaf that panics on the commit at failAt.
+type failingLeaf struct {
+	stubLeaf
+
+	failAt int
+}
+
+func (f *failingLeaf) Consume(ctx context.Context, ac *analyze.Context) (analyze.TC, error) {
+	if ac.Index == f.failAt {
+		panic("corrupt blob")
+	}
+
+	return f.stubLeaf.Consume(ctx, ac)
+}
+
+func (f *failingLeaf) Fork(n int) []analyze.HistoryAnalyzer {
+	forks := make([]analyze.HistoryAnalyzer, n)
+	for i := range n {
+		forks[i] = &failingLeaf{stubLeaf: stubLeaf{name: f.name, cpuHeavy: f.cpuHeavy}, failAt: f.failAt}
+	}
+
+	return forks
+}
+
+func openThreeCommitRepo(t *testing.T) (*gitlib.Repository, string, []*gitlib.Commit) {
+	t.Helper()
+
+	repo := framework.NewTestRepo(t)
+	t.Cleanup(repo.Close)
+
+	repo.CreateFile("a.txt", "one")
+	repo.Commit("first")
+	repo.CreateFile("b.txt", "two")
+	repo.Commit("second")
+	repo.CreateFile("c.txt", "three")
+	repo.Commit("third")
+
+	libRepo, err := gitlib.OpenRepository(repo.Path())
+	require.NoError(t, err)
+	t.Cleanup(libRepo.Free)
+
+	commits := framework.CollectCommits(t, libRepo, 0)
+	require.Len(t, commits, 3)
+
+	return libRepo, repo.Path(), commits
+}
+
+func TestParseCommitErrorPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  framework.CommitErrorPolicy
+	}{
+		{"", framework.CommitErrorAbort},
+		{"abort", framework.CommitErrorAbort},
+		{"skip", framework.CommitErrorSkip},
+		{"retry", framework.CommitErrorRetry},
+	}
+
+	for _, tt := range tests {
+		got, err := framework.ParseCommitErrorPolicy(tt.input)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := framework.ParseCommitErrorPolicy("ignore")
+	require.ErrorIs(t, err, framework.ErrUnknownCommitErrorPolicy)
+}
+
+func TestRunner_CommitErrorAbort(t *testing.T) {
+	t.Parallel()
+
+	libRepo, path, commits := openThreeCommitRepo(t)
+
+	r := framework.NewRunner(libRepo, path, &failingLeaf{stubLeaf: stubLeaf{name: "bad"}, failAt: 1})
+
+	_, err := r.Run(context.Background(), commits)
+	require.ErrorIs(t, err, framework.ErrAnalyzerPanic)
+}
+
+func TestRunner_CommitErrorSkip_SerialLeaf(t *testing.T) {
+	t.Parallel()
+
+	libRepo, path, commits := openThreeCommitRepo(t)
+
+	bad := &failingLeaf{stubLeaf: stubLeaf{name: "bad"}, failAt: 1}
+	good := &stubLeaf{name: "good"}
+
+	r := framework.NewRunner(libRepo, path, &plumbing.TreeDiffAnalyzer{}, bad, good)
+	r.CoreCount = 1
+	r.OnCommitError = framework.CommitErrorSkip
+
+	reports, err := r.Run(context.Background(), commits)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, bad.consumed)
+	assert.Equal(t, 3, good.consumed)
+
+	qs, ok := reports[good][analyze.ReportKeyRunQuality].(*analyze.QualityStats)
+	require.True(t, ok)
+	assert.Equal(t, 1, qs.SkippedCommits)
+	require.Len(t, qs.Failures, 1)
+	assert.Equal(t, "bad", qs.Failures[0].Stage)
+	assert.Equal(t, 1, qs.Failures[0].Index)
+	assert.Equal(t, commits[1].Hash().String(), qs.Failures[0].Hash)
+}
+
+func TestRunner_CommitErrorSkip_ParallelLeaf(t *testing.T) {
+	t.Parallel()
+
+	libRepo, path, commits := openThreeCommitRepo(t)
+
+	config := framework.DefaultCoordinatorConfig()
+	config.LeafWorkers = 2
+
+	bad := &failingLeaf{stubLeaf: stubLeaf{name: "bad", cpuHeavy: true}, failAt: 2}
+
+	r := framework.NewRunnerWithConfig(libRepo, path, config, &plumbing.TreeDiffAnalyzer{}, bad)
+	r.CoreCount = 1
+	r.OnCommitError = framework.CommitErrorSkip
+
+	_, err := r.Run(context.Background(), commits)
+	require.NoError(t, err)
+
+	qs := r.Quality()
+	require.NotNil(t, qs)
+	require.Len(t, qs.Failures, 1)
+	assert.Equal(t, 2, qs.Failures[0].Index)
+	assert.Contains(t, qs.Failures[0].Error, "corrupt blob")
+}
+
+// collectCommitData runs the pipeline over commits and marks the commit at
+// failAt as failed, simulating a transient blob read error.
+func collectCommitData(
+	t *testing.T, libRepo *gitlib.Repository, commits []*gitlib.Commit, failAt int,
+) []framework.CommitData {
+	t.Helper()
+
+	var data []framework.CommitData
+
+	for cd := range framework.NewCoordinator(libRepo, framework.DefaultCoordinatorConfig()).Process(context.Background(), commits) {
+		require.NoError(t, cd.Error)
+
+		if cd.Index == failAt {
+			cd.Error = errTransientBlob
+		}
+
+		data = append(data, cd)
+	}
+
+	return data
+}
+
+var errTransientBlob = errors.New("transient blob read error")
+
+func TestRunner_CommitErrorSkip_PipelineError(t *testing.T) {
+	t.Parallel()
+
+	libRepo, path, commits := openThreeCommitRepo(t)
+
+	good := &stubLeaf{name: "good"}
+
+	r := framework.NewRunner(libRepo, path, good)
+	r.OnCommitError = framework.CommitErrorSkip
+	require.NoError(t, r.Initialize())
+
+	_, err := r.ProcessChunkFromData(context.Background(), collectCommitData(t, libRepo, commits, 0), 0, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, good.consumed)
+
+	qs := r.Quality()
+	require.NotNil(t, qs)
+	require.Len(t, qs.Failures, 1)
+	assert.Equal(t, "pipeline", qs.Failures[0].Stage)
+	assert.Equal(t, 1, qs.Failures[0].Attempts)
+}
+
+func TestRunner_CommitErrorRetry_RecoversPipelineError(t *testing.T) {
+	t.Parallel()
+
+	libRepo, path, commits := openThreeCommitRepo(t)
+
+	good := &stubLeaf{name: "good"}
+
+	r := framework.NewRunner(libRepo, path, good)
+	r.OnCommitError = framework.CommitErrorRetry
+	require.NoError(t, r.Initialize())
+
+	_, err := r.ProcessChunkFromData(context.Background(), collectCommitData(t, libRepo, commits, 0), 0, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, good.consumed)
+	assert.Nil(t, r.Quality())
+}
+
+func TestRunner_CommitErrorAbort_PipelineError(t *testing.T) {
+	t.Parallel()
+
+	libRepo, path, commits := openThreeCommitRepo(t)
+
+	r := framework.NewRunner(libRepo, path, &stubLeaf{name: "good"})
+	require.NoError(t, r.Initialize())
+
+	_, err := r.ProcessChunkFromData(context.Background(), collectCommitData(t, libRepo, commits, 0), 0, 0)
+	require.ErrorIs(t, err, errTransientBlob)
+}
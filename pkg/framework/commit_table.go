@@ -0,0 +1,114 @@
+package framework
+
+import (
+	"slices"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// discoverCommitTableProviders remembers the core analyzers the commit table reads
+// line statistics, languages, message languages, trailers and notes from.
+func (runner *Runner) discoverCommitTableProviders(a analyze.HistoryAnalyzer) {
+	if ls, ok := a.(*plumbing.LinesStatsCalculator); ok {
+		runner.lineStatsProvider = ls
+	}
+
+	if ld, ok := a.(*plumbing.LanguagesDetectionAnalyzer); ok {
+		runner.langProvider = ld
+	}
+
+	if ml, ok := a.(*plumbing.MessageLanguageDetector); ok {
+		runner.msgLangProvider = ml
+	}
+
+	if te, ok := a.(*plumbing.TrailersExtractor); ok {
+		runner.trailersProvider = te
+	}
+}
+
+// recordCommitRow appends the commit table row for a commit whose core
+// analyzers have just been consumed. No-op unless CommitTable is set.
+func (runner *Runner) recordCommitRow(ac *analyze.Context) {
+	if !runner.CommitTable {
+		return
+	}
+
+	row := analyze.CommitRow{
+		Hash:         commitHashString(ac.Commit),
+		FilesChanged: len(ac.Changes),
+	}
+
+	if !ac.Time.IsZero() {
+		row.Timestamp = ac.Time.Format(time.RFC3339)
+	}
+
+	if runner.tickProvider != nil {
+		row.Tick = runner.tickProvider.Tick
+	}
+
+	if runner.idProvider != nil {
+		row.Author = runner.authorName(runner.idProvider.AuthorID)
+
+		for _, id := range runner.idProvider.CoAuthorIDs {
+			row.CoAuthors = append(row.CoAuthors, runner.authorName(id))
+		}
+	}
+
+	// Changed lines count as both an insertion and a deletion, as in git diff --stat.
+	if runner.lineStatsProvider != nil {
+		for _, stats := range runner.lineStatsProvider.LineStats {
+			row.Insertions += stats.Added + stats.Changed
+			row.Deletions += stats.Removed + stats.Changed
+		}
+	}
+
+	if runner.langProvider != nil {
+		row.Languages = commitLanguages(runner.langProvider.Languages())
+	}
+
+	if runner.msgLangProvider != nil {
+		row.MessageLanguage = runner.msgLangProvider.Language
+	}
+
+	if runner.trailersProvider != nil {
+		for _, t := range runner.trailersProvider.Trailers {
+			row.Trailers = append(row.Trailers, analyze.CommitTrailer{Key: t.Key, Value: t.Value})
+		}
+
+		row.Note = runner.trailersProvider.Note
+	}
+
+	runner.commitRows = append(runner.commitRows, row)
+}
+
+// commitLanguages returns the distinct detected languages, sorted.
+func commitLanguages(byBlob map[gitlib.Hash]string) []string {
+	var langs []string
+
+	for _, lang := range byBlob {
+		if lang != "" && !slices.Contains(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+
+	slices.Sort(langs)
+
+	return langs
+}
+
+// injectCommitTable adds the recorded commit table into every leaf report
+// under analyze.ReportKeyCommitTable.
+func (runner *Runner) injectCommitTable(reports map[analyze.HistoryAnalyzer]analyze.Report) {
+	if len(runner.commitRows) == 0 {
+		return
+	}
+
+	for _, report := range reports {
+		if report != nil {
+			report[analyze.ReportKeyCommitTable] = runner.commitRows
+		}
+	}
+}
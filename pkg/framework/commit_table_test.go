@@ -0,0 +1,86 @@
+package framework_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// newCommitTableRunner builds a runner with the plumbing the commit table reads.
+func newCommitTableRunner(t *testing.T, leaf analyze.HistoryAnalyzer) (*framework.Runner, []*gitlib.Commit) {
+	t.Helper()
+
+	repo := framework.NewTestRepo(t)
+	t.Cleanup(repo.Close)
+
+	repo.CreateFile("main.go", "package main\n\nfunc main() {}\n")
+	repo.Commit("first")
+	repo.CreateFile("main.go", "package main\n\nfunc main() { run() }\n")
+	repo.CreateFile("run.go", "package main\n\nfunc run() {}\n")
+	repo.Commit("second")
+
+	libRepo, err := gitlib.OpenRepository(repo.Path())
+	require.NoError(t, err)
+	t.Cleanup(libRepo.Free)
+
+	commits := framework.CollectCommits(t, libRepo, 0)
+	require.Len(t, commits, 2)
+	slices.Reverse(commits)
+
+	treeDiff := &plumbing.TreeDiffAnalyzer{Repository: libRepo}
+	blobCache := &plumbing.BlobCacheAnalyzer{TreeDiff: treeDiff, Repository: libRepo}
+	fileDiff := &plumbing.FileDiffAnalyzer{BlobCache: blobCache, TreeDiff: treeDiff}
+	lineStats := &plumbing.LinesStatsCalculator{TreeDiff: treeDiff, BlobCache: blobCache, FileDiff: fileDiff}
+	langDetect := &plumbing.LanguagesDetectionAnalyzer{TreeDiff: treeDiff, BlobCache: blobCache}
+
+	r := framework.NewRunner(libRepo, repo.Path(), treeDiff, blobCache, fileDiff, lineStats, langDetect, leaf)
+	r.CoreCount = 5
+
+	return r, commits
+}
+
+func TestRunner_CommitTable(t *testing.T) {
+	t.Parallel()
+
+	leaf := &stubLeaf{name: "leaf"}
+	r, commits := newCommitTableRunner(t, leaf)
+	r.CommitTable = true
+
+	reports, err := r.Run(context.Background(), commits)
+	require.NoError(t, err)
+
+	rows, ok := reports[leaf][analyze.ReportKeyCommitTable].([]analyze.CommitRow)
+	require.True(t, ok)
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, commits[0].Hash().String(), rows[0].Hash)
+	assert.Equal(t, 1, rows[0].FilesChanged)
+	assert.Equal(t, 3, rows[0].Insertions)
+	assert.Equal(t, 0, rows[0].Deletions)
+	assert.Equal(t, []string{"Go"}, rows[0].Languages)
+	assert.NotEmpty(t, rows[0].Timestamp)
+
+	assert.Equal(t, commits[1].Hash().String(), rows[1].Hash)
+	assert.Equal(t, 2, rows[1].FilesChanged)
+	assert.Positive(t, rows[1].Insertions)
+	assert.Positive(t, rows[1].Deletions)
+}
+
+func TestRunner_CommitTableDisabled(t *testing.T) {
+	t.Parallel()
+
+	leaf := &stubLeaf{name: "leaf"}
+	r, commits := newCommitTableRunner(t, leaf)
+
+	reports, err := r.Run(context.Background(), commits)
+	require.NoError(t, err)
+	assert.NotContains(t, reports[leaf], analyze.ReportKeyCommitTable)
+}
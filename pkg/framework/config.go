@@ -6,12 +6,15 @@ import (
 	"strings"
 
 	"github.com/dustin/go-humanize"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 )
 
 // Sentinel errors for configuration.
 var (
 	ErrInvalidSizeFormat = errors.New("invalid size format")
 	ErrInvalidGCPercent  = errors.New("invalid GC percent")
+	ErrInvalidExtCap     = errors.New("invalid blob cache extension cap")
 )
 
 // Maximum integer values for safe conversion from uint64.
@@ -32,6 +35,10 @@ type ConfigParams struct {
 	MemoryBudget    string
 	GCPercent       int
 	BallastSize     string
+
+	// BlobCacheCaps caps the blob cache per file extension, as a comma
+	// separated list of extension=size pairs (e.g. ".json=64MB,.svg=0").
+	BlobCacheCaps string
 }
 
 // CheckpointParams holds checkpoint-related configuration.
@@ -40,6 +47,9 @@ type CheckpointParams struct {
 	Dir       string
 	Resume    bool
 	ClearPrev bool
+
+	// Cipher, when set, encrypts the analyzer state files of checkpoints.
+	Cipher *crypt.Cipher
 }
 
 // BudgetSolver resolves a memory budget (in bytes) to a CoordinatorConfig.
@@ -56,7 +66,9 @@ const percentDenominator = 100
 const defaultMemoryBudgetCap = int64(4 * 1024 * 1024 * 1024)
 
 // DefaultMemoryBudget returns a sensible memory budget based on available system memory.
-// Returns min(50% of total RAM, 4 GiB), or 0 if detection fails.
+// Returns min(50% of total RAM, 4 GiB), or 0 if detection fails. On Linux a
+// cgroup memory limit below total RAM, such as a Kubernetes container limit,
+// takes the place of total RAM.
 func DefaultMemoryBudget() int64 {
 	total := detectTotalMemoryBytes()
 	if total == 0 {
@@ -84,6 +96,11 @@ func BuildConfigFromParams(params ConfigParams, budgetSolver BudgetSolver) (Coor
 			return CoordinatorConfig{}, 0, runtimeErr
 		}
 
+		cfg.BlobCacheExtCaps, runtimeErr = ParseBlobCacheExtCaps(params.BlobCacheCaps)
+		if runtimeErr != nil {
+			return CoordinatorConfig{}, 0, runtimeErr
+		}
+
 		budgetBytes, parseErr := humanize.ParseBytes(params.MemoryBudget)
 		if parseErr != nil {
 			return CoordinatorConfig{}, 0, fmt.Errorf("failed to parse budget: %w", parseErr)
@@ -106,6 +123,11 @@ func BuildConfigFromParams(params ConfigParams, budgetSolver BudgetSolver) (Coor
 		return config, 0, tuningErr
 	}
 
+	config.BlobCacheExtCaps, tuningErr = ParseBlobCacheExtCaps(params.BlobCacheCaps)
+	if tuningErr != nil {
+		return config, 0, tuningErr
+	}
+
 	// Auto-detect memory budget from system memory when not explicitly set.
 	memBudget := DefaultMemoryBudget()
 
@@ -198,6 +220,39 @@ func ParseOptionalSize(sizeValue string) (int64, error) {
 	return SafeInt64(parsed), nil
 }
 
+// ParseBlobCacheExtCaps parses per-extension blob cache caps written as
+// ".json=64MB,.svg=0". Extensions are lower-cased and get a leading dot when
+// it is missing. Returns nil for an empty value.
+func ParseBlobCacheExtCaps(value string) (map[string]int64, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil //nolint:nilnil // no caps configured.
+	}
+
+	caps := make(map[string]int64)
+
+	for pair := range strings.SplitSeq(value, ",") {
+		ext, size, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		ext = strings.ToLower(strings.TrimSpace(ext))
+
+		if !ok || ext == "" || ext == "." {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidExtCap, pair)
+		}
+
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+
+		capBytes, err := ParseOptionalSize(size)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidExtCap, pair)
+		}
+
+		caps[ext] = capBytes
+	}
+
+	return caps, nil
+}
+
 // SafeInt64 converts uint64 to int64, clamping to maxInt64 to prevent overflow.
 func SafeInt64(v uint64) int64 {
 	if v > uint64(maxInt64) {
@@ -1,6 +1,7 @@
 package framework_test
 
 import (
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -215,3 +216,29 @@ func TestBuildConfigFromParams_RuntimeFlagsWithBudget(t *testing.T) {
 	assert.Equal(t, 220, config.GCPercent)
 	assert.Equal(t, int64(32*1024*1024), config.BallastSize)
 }
+
+func TestDefaultMemoryBudget_DetectedOnDesktopPlatforms(t *testing.T) {
+	t.Parallel()
+
+	switch runtime.GOOS {
+	case "linux", "darwin", "windows":
+		assert.Positive(t, framework.DefaultMemoryBudget())
+	default:
+		t.Skipf("system memory detection is not supported on %s", runtime.GOOS)
+	}
+}
+
+func TestBuildConfigFromParams_BlobCacheCaps(t *testing.T) {
+	t.Parallel()
+
+	config, _, err := framework.BuildConfigFromParams(framework.ConfigParams{BlobCacheCaps: ".json=64MiB, SVG=0"}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int64{".json": 64 * 1024 * 1024, ".svg": 0}, config.BlobCacheExtCaps)
+
+	_, _, err = framework.BuildConfigFromParams(framework.ConfigParams{BlobCacheCaps: ".json"}, nil)
+	require.ErrorIs(t, err, framework.ErrInvalidExtCap)
+
+	_, _, err = framework.BuildConfigFromParams(framework.ConfigParams{BlobCacheCaps: ".json=lots"}, nil)
+	require.ErrorIs(t, err, framework.ErrInvalidExtCap)
+}
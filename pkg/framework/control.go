@@ -0,0 +1,399 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
+)
+
+// Sentinel errors for run control requests.
+var (
+	// ErrCheckpointingDisabled is returned by RequestCheckpoint when the run
+	// does not save checkpoints.
+	ErrCheckpointingDisabled = errors.New("checkpointing is disabled for this run")
+	// ErrRunNotActive is returned by control requests that need a chunk
+	// boundary when no run is in progress or the last chunk has started.
+	ErrRunNotActive = errors.New("no analysis in progress")
+	// ErrBudgetNotLowered is returned by LowerBudget for a budget that is not
+	// below the current one.
+	ErrBudgetNotLowered = errors.New("memory budget can only be lowered")
+)
+
+// CheckpointInfo identifies a saved checkpoint.
+type CheckpointInfo struct {
+	Chunk            int `json:"chunk"`
+	ProcessedCommits int `json:"processed_commits"`
+}
+
+// ControlState is a snapshot of a controlled run, returned by every control
+// endpoint.
+type ControlState struct {
+	Running         bool            `json:"running"`
+	Paused          bool            `json:"paused"`
+	ChunksDone      int             `json:"chunks_done"`
+	Chunks          int             `json:"chunks"`
+	MemoryBudget    int64           `json:"memory_budget"`
+	PendingBudget   int64           `json:"pending_budget,omitempty"`
+	Checkpointing   bool            `json:"checkpointing"`
+	LastCheckpoint  *CheckpointInfo `json:"last_checkpoint,omitempty"`
+	CheckpointWaits int             `json:"checkpoint_waiters,omitempty"`
+}
+
+// Control steers a running analysis from outside the process, through the
+// control endpoints of the diagnostics server. Requests take effect at the
+// next chunk boundary; a chunk in progress always runs to completion. All
+// methods are safe for concurrent use. A Runner with a nil Control cannot be
+// steered.
+type Control struct {
+	mu      sync.Mutex
+	state   ControlState
+	resume  chan struct{} // closed by Resume; nil while running.
+	waiters []chan checkpointResult
+}
+
+type checkpointResult struct {
+	info CheckpointInfo
+	err  error
+}
+
+// NewControl creates a Control for one run.
+func NewControl() *Control {
+	return &Control{}
+}
+
+// State returns a snapshot of the run.
+func (c *Control) State() ControlState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.state
+	state.CheckpointWaits = len(c.waiters)
+
+	return state
+}
+
+// Pause stops the run at the next chunk boundary, after that chunk's
+// checkpoint is saved, until Resume is called.
+func (c *Control) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.resume == nil {
+		c.resume = make(chan struct{})
+		c.state.Paused = true
+	}
+}
+
+// Resume continues a paused run.
+func (c *Control) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.resume != nil {
+		close(c.resume)
+		c.resume = nil
+		c.state.Paused = false
+	}
+}
+
+// RequestCheckpoint waits until a checkpoint covering everything processed
+// so far is saved, so the process can be stopped without losing work. A
+// paused run has already saved it and returns at once; otherwise the call
+// returns after the chunk in progress completes, or when ctx is done.
+func (c *Control) RequestCheckpoint(ctx context.Context) (CheckpointInfo, error) {
+	c.mu.Lock()
+
+	switch {
+	case !c.state.Running:
+		c.mu.Unlock()
+
+		return CheckpointInfo{}, ErrRunNotActive
+	case !c.state.Checkpointing:
+		c.mu.Unlock()
+
+		return CheckpointInfo{}, ErrCheckpointingDisabled
+	case c.state.Paused && c.state.LastCheckpoint != nil && c.state.LastCheckpoint.Chunk == c.state.ChunksDone:
+		info := *c.state.LastCheckpoint
+		c.mu.Unlock()
+
+		return info, nil
+	}
+
+	ch := make(chan checkpointResult, 1)
+	c.waiters = append(c.waiters, ch)
+	c.mu.Unlock()
+
+	select {
+	case res := <-ch:
+		return res.info, res.err
+	case <-ctx.Done():
+		return CheckpointInfo{}, ctx.Err()
+	}
+}
+
+// LowerBudget lowers the memory budget of the run from the next chunk
+// boundary: remaining chunks are re-planned, the Go soft memory limit is
+// lowered, and aggregator state above its share of the new budget is spilled.
+func (c *Control) LowerBudget(budget int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.state.Running {
+		return ErrRunNotActive
+	}
+
+	current := c.state.MemoryBudget
+	if c.state.PendingBudget > 0 {
+		current = c.state.PendingBudget
+	}
+
+	if budget <= 0 || (current > 0 && budget >= current) {
+		return fmt.Errorf("%w: requested %s, current %s", ErrBudgetNotLowered,
+			humanize.IBytes(uint64(max(budget, 0))), humanize.IBytes(uint64(current)))
+	}
+
+	c.state.PendingBudget = budget
+
+	return nil
+}
+
+// start marks the run as active, with startChunk chunks already done.
+func (c *Control) start(memBudget int64, startChunk, chunks int, checkpointing bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state.Running = true
+	c.state.MemoryBudget = memBudget
+	c.state.ChunksDone = startChunk
+	c.state.Chunks = chunks
+	c.state.Checkpointing = checkpointing
+}
+
+// finish marks the run as done and fails outstanding checkpoint requests.
+func (c *Control) finish() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state.Running = false
+	c.state.PendingBudget = 0
+	c.notifyLocked(checkpointResult{err: ErrRunNotActive})
+}
+
+// disableCheckpointing records that the run stopped saving checkpoints.
+func (c *Control) disableCheckpointing() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state.Checkpointing = false
+	c.notifyLocked(checkpointResult{err: ErrCheckpointingDisabled})
+}
+
+// checkpointSaved records a saved checkpoint and answers outstanding
+// checkpoint requests.
+func (c *Control) checkpointSaved(chunkIdx, processedCommits int) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info := CheckpointInfo{Chunk: chunkIdx + 1, ProcessedCommits: processedCommits}
+	c.state.LastCheckpoint = &info
+	c.notifyLocked(checkpointResult{info: info})
+}
+
+func (c *Control) notifyLocked(res checkpointResult) {
+	for _, ch := range c.waiters {
+		ch <- res
+	}
+
+	c.waiters = nil
+}
+
+// pending reports whether the next chunk boundary has work to do.
+func (c *Control) pending() bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.resume != nil || c.state.PendingBudget > 0
+}
+
+// boundary is called between chunks, after chunkIdx completed and its
+// checkpoint was saved. It blocks while the run is paused and returns the
+// lowered memory budget to apply, or zero.
+func (c *Control) boundary(ctx context.Context, logger *slog.Logger, chunkIdx, chunks int) (int64, error) {
+	if c == nil {
+		return 0, nil
+	}
+
+	c.mu.Lock()
+	c.state.ChunksDone = chunkIdx + 1
+	c.state.Chunks = chunks
+
+	// The last chunk gets no checkpoint; no later boundary can answer.
+	if chunkIdx+1 >= chunks-1 {
+		c.notifyLocked(checkpointResult{err: ErrRunNotActive})
+	}
+
+	resume := c.resume
+	c.mu.Unlock()
+
+	if resume != nil {
+		logger.InfoContext(ctx, "control: paused", "chunk", chunkIdx+1, "total", chunks)
+
+		// Hand the memory of the finished chunk back to the host while idle.
+		debug.FreeOSMemory()
+
+		select {
+		case <-resume:
+			logger.InfoContext(ctx, "control: resumed", "chunk", chunkIdx+1)
+		case <-ctx.Done():
+			return 0, fmt.Errorf("control: paused run cancelled: %w", ctx.Err())
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	budget := c.state.PendingBudget
+	if budget > 0 {
+		c.state.MemoryBudget = budget
+		c.state.PendingBudget = 0
+	}
+
+	return budget, nil
+}
+
+// applyControl runs the control boundary between chunks. When the memory
+// budget was lowered it re-plans the chunks after chunkIdx and returns them
+// with the new budget; otherwise chunks and memBudget are returned as is.
+func applyControl(
+	ctx context.Context, logger *slog.Logger, runner *Runner, ap *streaming.AdaptivePlanner,
+	chunks []streaming.ChunkBounds, chunkIdx int, memBudget int64,
+) ([]streaming.ChunkBounds, int64, error) {
+	budget, err := runner.Control.boundary(ctx, logger, chunkIdx, len(chunks))
+	if err != nil || budget <= 0 {
+		return chunks, memBudget, err
+	}
+
+	newChunks := ap.Rebudget(budget, chunkIdx, chunks)
+
+	runner.lowerMemBudget(ctx, logger, budget)
+
+	logger.InfoContext(ctx, "control: memory budget lowered",
+		"budget_mib", budget/streaming.MiB, "old_budget_mib", memBudget/streaming.MiB,
+		"old_chunks", len(chunks), "new_chunks", len(newChunks))
+
+	return newChunks, budget, nil
+}
+
+// Handler returns the control endpoints, served under /control/ by the
+// diagnostics server:
+//
+//	POST /control/pause           stop at the next chunk boundary
+//	POST /control/resume          continue a paused run
+//	POST /control/checkpoint-now  respond once a checkpoint is saved
+//	POST /control/lower-budget    lower the memory budget (?budget=2GB)
+//	GET  /control/status          current state
+//
+// Every endpoint responds with the ControlState as JSON.
+func (c *Control) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /control/status", func(rw http.ResponseWriter, _ *http.Request) {
+		c.writeState(rw, http.StatusOK, nil)
+	})
+
+	mux.HandleFunc("POST /control/pause", func(rw http.ResponseWriter, _ *http.Request) {
+		c.Pause()
+		c.writeState(rw, http.StatusAccepted, nil)
+	})
+
+	mux.HandleFunc("POST /control/resume", func(rw http.ResponseWriter, _ *http.Request) {
+		c.Resume()
+		c.writeState(rw, http.StatusOK, nil)
+	})
+
+	mux.HandleFunc("POST /control/checkpoint-now", func(rw http.ResponseWriter, req *http.Request) {
+		_, err := c.RequestCheckpoint(req.Context())
+		c.writeState(rw, statusFor(err), err)
+	})
+
+	mux.HandleFunc("POST /control/lower-budget", func(rw http.ResponseWriter, req *http.Request) {
+		budget, err := humanize.ParseBytes(req.FormValue("budget"))
+		if err != nil {
+			c.writeState(rw, http.StatusBadRequest, fmt.Errorf("budget: %w", err))
+
+			return
+		}
+
+		err = c.LowerBudget(SafeInt64(budget))
+		if err != nil {
+			c.writeState(rw, statusFor(err), err)
+
+			return
+		}
+
+		c.writeState(rw, http.StatusAccepted, nil)
+	})
+
+	return mux
+}
+
+func statusFor(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, ErrBudgetNotLowered):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrCheckpointingDisabled), errors.Is(err, ErrRunNotActive):
+		return http.StatusConflict
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+// controlResponse is the JSON body of control endpoints.
+type controlResponse struct {
+	ControlState
+
+	Error string `json:"error,omitempty"`
+}
+
+func (c *Control) writeState(rw http.ResponseWriter, status int, err error) {
+	resp := controlResponse{ControlState: c.State()}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+
+	_ = json.NewEncoder(rw).Encode(resp)
+}
@@ -0,0 +1,235 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
+)
+
+func discardControlLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestControl_PauseBlocksBoundaryUntilResume(t *testing.T) {
+	t.Parallel()
+
+	c := NewControl()
+	c.start(0, 0, 4, true)
+	c.Pause()
+
+	done := make(chan struct{})
+
+	go func() {
+		_, err := c.boundary(context.Background(), discardControlLogger(), 0, 4)
+		assert.NoError(t, err)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("boundary returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.True(t, c.State().Paused)
+	c.Resume()
+	<-done
+	assert.False(t, c.State().Paused)
+	assert.Equal(t, 1, c.State().ChunksDone)
+}
+
+func TestControl_PausedBoundaryStopsOnCancel(t *testing.T) {
+	t.Parallel()
+
+	c := NewControl()
+	c.start(0, 0, 4, true)
+	c.Pause()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.boundary(ctx, discardControlLogger(), 0, 4)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestControl_RequestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	c := NewControl()
+
+	_, err := c.RequestCheckpoint(context.Background())
+	require.ErrorIs(t, err, ErrRunNotActive)
+
+	c.start(0, 0, 10, false)
+
+	_, err = c.RequestCheckpoint(context.Background())
+	require.ErrorIs(t, err, ErrCheckpointingDisabled)
+
+	c = NewControl()
+	c.start(0, 0, 10, true)
+
+	got := make(chan CheckpointInfo)
+
+	go func() {
+		info, reqErr := c.RequestCheckpoint(context.Background())
+		assert.NoError(t, reqErr)
+		got <- info
+	}()
+
+	require.Eventually(t, func() bool { return c.State().CheckpointWaits == 1 }, time.Second, time.Millisecond)
+	c.checkpointSaved(2, 300)
+
+	assert.Equal(t, CheckpointInfo{Chunk: 3, ProcessedCommits: 300}, <-got)
+}
+
+func TestControl_RequestCheckpointWhilePausedReturnsLast(t *testing.T) {
+	t.Parallel()
+
+	c := NewControl()
+	c.start(0, 0, 10, true)
+	c.checkpointSaved(0, 100)
+	c.Pause()
+
+	go func() {
+		_, _ = c.boundary(context.Background(), discardControlLogger(), 0, 10)
+	}()
+
+	require.Eventually(t, func() bool { return c.State().ChunksDone == 1 }, time.Second, time.Millisecond)
+
+	info, err := c.RequestCheckpoint(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, CheckpointInfo{Chunk: 1, ProcessedCommits: 100}, info)
+
+	c.Resume()
+}
+
+func TestControl_FinishFailsWaiters(t *testing.T) {
+	t.Parallel()
+
+	c := NewControl()
+	c.start(0, 0, 10, true)
+
+	errs := make(chan error)
+
+	go func() {
+		_, err := c.RequestCheckpoint(context.Background())
+		errs <- err
+	}()
+
+	require.Eventually(t, func() bool { return c.State().CheckpointWaits == 1 }, time.Second, time.Millisecond)
+	c.finish()
+
+	require.ErrorIs(t, <-errs, ErrRunNotActive)
+}
+
+func TestControl_LowerBudget(t *testing.T) {
+	t.Parallel()
+
+	c := NewControl()
+	require.ErrorIs(t, c.LowerBudget(streaming.MiB), ErrRunNotActive)
+
+	c.start(1024*streaming.MiB, 0, 10, false)
+
+	require.ErrorIs(t, c.LowerBudget(2048*streaming.MiB), ErrBudgetNotLowered)
+	require.ErrorIs(t, c.LowerBudget(0), ErrBudgetNotLowered)
+	require.NoError(t, c.LowerBudget(512*streaming.MiB))
+	assert.Equal(t, int64(512*streaming.MiB), c.State().PendingBudget)
+	assert.True(t, c.pending())
+
+	budget, err := c.boundary(context.Background(), discardControlLogger(), 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(512*streaming.MiB), budget)
+	assert.Equal(t, int64(512*streaming.MiB), c.State().MemoryBudget)
+	assert.False(t, c.pending())
+}
+
+func TestApplyControl_LowersRunnerBudget(t *testing.T) {
+	t.Parallel()
+
+	// applyControl lowers the process-wide soft memory limit.
+	prev := debug.SetMemoryLimit(-1)
+	t.Cleanup(func() { debug.SetMemoryLimit(prev) })
+
+	budget := int64(2048 * streaming.MiB)
+	ap := streaming.NewAdaptivePlanner(100000, budget, 500*streaming.KiB, 400*streaming.MiB)
+	chunks := ap.InitialPlan()
+
+	runner := &Runner{Control: NewControl(), MemBudget: budget, AggSpillBudget: 200 * streaming.MiB}
+	runner.Control.start(budget, 0, len(chunks), false)
+	require.NoError(t, runner.Control.LowerBudget(budget/2))
+
+	newChunks, newBudget, err := applyControl(context.Background(), discardControlLogger(), runner, ap, chunks, 0, budget)
+	require.NoError(t, err)
+	assert.Equal(t, budget/2, newBudget)
+	assert.Equal(t, budget/2, runner.MemBudget)
+	assert.Equal(t, int64(100*streaming.MiB), runner.AggSpillBudget)
+	assert.Greater(t, len(newChunks), len(chunks))
+}
+
+func TestControl_Handler(t *testing.T) {
+	t.Parallel()
+
+	c := NewControl()
+	c.start(1024*streaming.MiB, 0, 10, false)
+
+	srv := httptest.NewServer(c.Handler())
+	t.Cleanup(srv.Close)
+
+	post := func(path string) (int, controlResponse) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+path, http.NoBody)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		var body controlResponse
+
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		return resp.StatusCode, body
+	}
+
+	status, body := post("/control/pause")
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.True(t, body.Paused)
+
+	status, body = post("/control/resume")
+	assert.Equal(t, http.StatusOK, status)
+	assert.False(t, body.Paused)
+
+	status, body = post("/control/lower-budget?budget=256MiB")
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, int64(256*streaming.MiB), body.PendingBudget)
+
+	status, body = post("/control/lower-budget?budget=4GiB")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body.Error, "can only be lowered")
+
+	status, _ = post("/control/lower-budget?budget=lots")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, body = post("/control/checkpoint-now")
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Contains(t, body.Error, "checkpointing is disabled")
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/control/pause", http.NoBody)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -4,13 +4,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"os"
 	"reflect"
 	"runtime"
 	"runtime/debug"
 	"strconv"
 	"time"
 
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/uast"
 )
@@ -56,7 +56,6 @@ const memoryLimitRatio = 75
 const percentDivisor = 100
 
 const (
-	procMemInfoPath = "/proc/meminfo"
 	memTotalPrefix  = "MemTotal:"
 	memTotalUnitKiB = "kB"
 	kibibyte        = uint64(1024)
@@ -73,6 +72,17 @@ type PipelineStats struct {
 	BlobCacheMisses int64
 	DiffCacheHits   int64
 	DiffCacheMisses int64
+
+	// DiffDeduped counts diffs shared with an identical blob pair whose diff
+	// was still in flight.
+	DiffDeduped int64
+	// UASTMemoHits and UASTMemoMisses count the parses served from the UAST
+	// memo and the blobs actually parsed.
+	UASTMemoHits   int64
+	UASTMemoMisses int64
+
+	// BlobCache holds the blob cache efficiency counters of the run.
+	BlobCache CacheStats
 }
 
 // Add accumulates another PipelineStats into this one (cross-chunk aggregation).
@@ -84,6 +94,10 @@ func (s *PipelineStats) Add(other PipelineStats) {
 	s.BlobCacheMisses += other.BlobCacheMisses
 	s.DiffCacheHits += other.DiffCacheHits
 	s.DiffCacheMisses += other.DiffCacheMisses
+	s.DiffDeduped += other.DiffDeduped
+	s.UASTMemoHits += other.UASTMemoHits
+	s.UASTMemoMisses += other.UASTMemoMisses
+	s.BlobCache.Add(other.BlobCache)
 }
 
 // CoordinatorConfig configures the pipeline coordinator.
@@ -104,6 +118,11 @@ type CoordinatorConfig struct {
 	// Set to 0 to disable caching.
 	BlobCacheSize int64
 
+	// BlobCacheExtCaps caps the bytes the blob cache holds per file extension
+	// (lowercase, with the leading dot). A cap of 0 keeps the extension out
+	// of the cache.
+	BlobCacheExtCaps map[string]int64
+
 	// DiffCacheSize is the maximum number of diff results to cache.
 	// Set to 0 to disable caching.
 	DiffCacheSize int
@@ -116,6 +135,20 @@ type CoordinatorConfig struct {
 	// in the pipeline stage. Set to 0 to disable the UAST pipeline stage.
 	UASTPipelineWorkers int
 
+	// UASTMemoSize is the maximum number of parsed blobs the UAST pipeline
+	// keeps per chunk, so that blobs shared by several commits are parsed
+	// once. Set to 0 to disable the memo.
+	UASTMemoSize int
+
+	// UASTServiceURL, when set, is the base URL of a "uast server" the UAST
+	// pipeline workers send parse requests to, with W3C trace context.
+	UASTServiceURL string
+
+	// ChunkWorkers, when set, runs the blob, diff and UAST stages on remote
+	// chunk workers instead of in-process. It is shared by the coordinators
+	// of every chunk of a run.
+	ChunkWorkers *ChunkWorkers
+
 	// LeafWorkers is the number of goroutines for parallel leaf analyzer consumption.
 	// Each worker processes a disjoint subset of commits via Fork/Merge.
 	// Set to 0 to disable parallel leaf consumption (serial path).
@@ -135,6 +168,55 @@ type CoordinatorConfig struct {
 	// WorkerTimeout is the maximum time to wait for a worker response before
 	// considering it stalled. Set to 0 to disable the watchdog.
 	WorkerTimeout time.Duration
+
+	// DiffAlgorithm selects the line diff algorithm for blob diffs.
+	// Empty means Myers.
+	DiffAlgorithm gitlib.DiffAlgorithm
+
+	// DiffTimeout bounds a single Go fallback diff when libgit2 fails.
+	// libgit2 diffs have no timeout. Set to 0 for the diffmatchpatch default.
+	DiffTimeout time.Duration
+
+	// NormalizeEOL diffs blobs with CRLF line endings turned into LF, so
+	// line ending conversions do not count as rewrites.
+	NormalizeEOL bool
+
+	// SkipBlobs computes tree diffs without loading blobs. Set when no
+	// analyzer reads blob contents.
+	SkipBlobs bool
+
+	// SkipDiffs passes commits through the diff stage without computing line
+	// diffs. Set when no analyzer reads diffs.
+	SkipDiffs bool
+
+	// PackIndex, when set, orders the blob loads of each commit batch by
+	// their position in the pack files. See EnablePackOrder.
+	PackIndex *gitlib.PackIndex
+
+	// ResolveLFS replaces Git LFS pointers with their objects from the
+	// repository's LFS store as blobs are loaded.
+	ResolveLFS bool
+
+	// TextEncoding says how loaded blobs are transcoded to UTF-8. The
+	// coordinator sets it on every repository handle it reads through.
+	TextEncoding gitlib.TextEncoding
+
+	// Queues, when set, measures the queues between the pipeline stages.
+	// It is shared by the coordinators of every chunk of a run.
+	Queues *PipelineQueues
+}
+
+// WithCapabilities returns the config with the stages caps does not need
+// turned off: blob loading, line diffs and UAST parsing.
+func (c CoordinatorConfig) WithCapabilities(caps analyze.Capabilities) CoordinatorConfig {
+	c.SkipBlobs = c.SkipBlobs || !caps.NeedsBlobs
+	c.SkipDiffs = c.SkipDiffs || !caps.NeedsDiffs
+
+	if !caps.NeedsUAST {
+		c.UASTPipelineWorkers = 0
+	}
+
+	return c
 }
 
 // DefaultCoordinatorConfig returns the default coordinator configuration.
@@ -155,6 +237,7 @@ func DefaultCoordinatorConfig() CoordinatorConfig {
 		BlobCacheSize:       DefaultGlobalCacheSize,
 		DiffCacheSize:       DefaultDiffCacheSize,
 		UASTPipelineWorkers: uastWorkers,
+		UASTMemoSize:        DefaultUASTMemoSize,
 		LeafWorkers:         leafWorkers,
 		BlobArenaSize:       defaultBlobArenaBytes,
 		GCPercent:           0,
@@ -169,6 +252,7 @@ const (
 	repoHandleSize       = 10 * 1024 * 1024  // Per-worker libgit2 handle (Go-visible).
 	workerNativeOverhead = 50 * 1024 * 1024  // Per-worker C/mmap overhead from libgit2.
 	avgDiffEntrySize     = 2 * 1024          // Average cached diff entry.
+	avgUASTTreeSize      = 256 * 1024        // Average memoized UAST tree.
 	avgCommitDataSize    = 64 * 1024         // Average in-flight commit data.
 )
 
@@ -177,8 +261,22 @@ const (
 // everything except analyzer state. This allows the streaming planner to
 // accurately compute how much memory remains for analyzer state growth.
 func (c CoordinatorConfig) EstimatedOverhead() int64 {
-	workers := int64(c.Workers) * (repoHandleSize + int64(c.BlobArenaSize) + workerNativeOverhead)
-	caches := c.BlobCacheSize + int64(c.DiffCacheSize)*avgDiffEntrySize
+	workers := int64(c.Workers) * (repoHandleSize + workerNativeOverhead)
+	caches := int64(0)
+
+	if !c.SkipBlobs {
+		workers += int64(c.Workers) * int64(c.BlobArenaSize)
+		caches += c.BlobCacheSize
+	}
+
+	if !c.SkipDiffs {
+		caches += int64(c.DiffCacheSize) * avgDiffEntrySize
+	}
+
+	if c.UASTPipelineWorkers > 0 {
+		caches += int64(c.UASTMemoSize) * avgUASTTreeSize
+	}
+
 	buffers := int64(c.BufferSize) * avgCommitDataSize
 
 	return runtimeOverhead + workers + caches + buffers
@@ -224,9 +322,16 @@ func NewCoordinator(repo *gitlib.Repository, config CoordinatorConfig) *Coordina
 		config.Workers = 1
 	}
 
+	// Remote chunks need no local workers.
+	if config.ChunkWorkers != nil {
+		return &Coordinator{repo: repo, config: config}
+	}
+
 	seqChan := make(chan gitlib.WorkerRequest, config.BufferSize)
 	poolChan := make(chan gitlib.WorkerRequest, config.BufferSize*config.Workers)
 
+	repo.SetTextEncoding(config.TextEncoding)
+
 	// Sequential worker uses the main repo (for commit stream + tree diffs).
 	seqWorker := gitlib.NewWorker(repo, seqChan)
 
@@ -241,19 +346,21 @@ func NewCoordinator(repo *gitlib.Repository, config CoordinatorConfig) *Coordina
 			panic(fmt.Errorf("failed to open repo for worker: %w", err))
 		}
 
+		newRepo.SetTextEncoding(config.TextEncoding)
 		poolRepos[i] = newRepo
 		poolWorkers[i] = gitlib.NewWorker(newRepo, poolChan)
 	}
 
 	// Create blob cache if configured.
 	var blobCache *GlobalBlobCache
-	if config.BlobCacheSize > 0 {
+	if config.BlobCacheSize > 0 && !config.SkipBlobs {
 		blobCache = NewGlobalBlobCache(config.BlobCacheSize)
+		blobCache.SetExtensionCaps(config.BlobCacheExtCaps)
 	}
 
 	// Create diff cache if configured.
 	var diffCache *DiffCache
-	if config.DiffCacheSize > 0 {
+	if config.DiffCacheSize > 0 && !config.SkipDiffs {
 		diffCache = NewDiffCache(config.DiffCacheSize)
 	}
 
@@ -262,6 +369,13 @@ func NewCoordinator(repo *gitlib.Repository, config CoordinatorConfig) *Coordina
 		blobPipeline.ArenaSize = config.BlobArenaSize
 	}
 
+	blobPipeline.SkipBlobs = config.SkipBlobs
+	blobPipeline.PackIndex = config.PackIndex
+
+	if config.ResolveLFS {
+		blobPipeline.LFS = repo.LFSStore()
+	}
+
 	// Create UAST pipeline if workers are configured.
 	var uastPipeline *UASTPipeline
 
@@ -269,9 +383,20 @@ func NewCoordinator(repo *gitlib.Repository, config CoordinatorConfig) *Coordina
 		parser, err := uast.NewParser()
 		if err == nil {
 			uastPipeline = NewUASTPipeline(parser, config.UASTPipelineWorkers, config.BufferSize)
+			uastPipeline.MemoSize = config.UASTMemoSize
+
+			if config.UASTServiceURL != "" {
+				uastPipeline.Remote = uast.NewRemoteParser(config.UASTServiceURL)
+			}
 		}
 	}
 
+	diffPipeline := NewDiffPipelineWithCache(poolChan, config.BufferSize, diffCache)
+	diffPipeline.Algorithm = config.DiffAlgorithm
+	diffPipeline.Timeout = config.DiffTimeout
+	diffPipeline.NormalizeEOL = config.NormalizeEOL
+	diffPipeline.Skip = config.SkipDiffs
+
 	return &Coordinator{
 		repo:   repo,
 		config: config,
@@ -280,7 +405,7 @@ func NewCoordinator(repo *gitlib.Repository, config CoordinatorConfig) *Coordina
 			Lookahead: config.BufferSize,
 		},
 		blobPipeline: blobPipeline,
-		diffPipeline: NewDiffPipelineWithCache(poolChan, config.BufferSize, diffCache),
+		diffPipeline: diffPipeline,
 		uastPipeline: uastPipeline,
 		blobCache:    blobCache,
 		diffCache:    diffCache,
@@ -374,19 +499,6 @@ func applyBallast(ballastSize int64) []byte {
 	return make([]byte, ballastSize)
 }
 
-func detectTotalMemoryBytes() uint64 {
-	if runtime.GOOS != "linux" {
-		return 0
-	}
-
-	memInfoBytes, err := os.ReadFile(procMemInfoPath)
-	if err != nil {
-		return 0
-	}
-
-	return parseMemTotalBytes(memInfoBytes)
-}
-
 func parseMemTotalBytes(memInfo []byte) uint64 {
 	for line := range bytes.SplitSeq(memInfo, []byte{'\n'}) {
 		if !bytes.HasPrefix(line, []byte(memTotalPrefix)) {
@@ -427,24 +539,43 @@ func scaleBytesByUnit(value uint64, unit string) uint64 {
 // After the returned channel is fully drained, call Stats() to retrieve
 // pipeline timing and cache metrics.
 func (c *Coordinator) Process(ctx context.Context, commits []*gitlib.Commit) <-chan CommitData {
-	// Start all workers.
-	c.seqWorker.Start()
-
-	for _, w := range c.poolWorkers {
-		w.Start()
+	if c.config.ChunkWorkers != nil {
+		return c.processRemote(ctx, commits)
 	}
 
+	// Start all workers. Goroutines inherit the stage label from their creator.
+	doStage(ctx, ProfileStageGit, func(context.Context) {
+		c.seqWorker.Start()
+
+		for _, w := range c.poolWorkers {
+			w.Start()
+		}
+	})
+
 	// Pipeline: Commits -> Blobs -> Diffs -> [UAST].
 	commitChan := c.commitStreamer.Stream(ctx, commits)
 
 	blobHitsBefore, blobMissesBefore := cacheStats(c.blobCache)
 	diffHitsBefore, diffMissesBefore := cacheStats(c.diffCache)
+	blobStatsBefore := c.blobCacheStats()
+
+	var (
+		blobOut            <-chan BlobData
+		diffOut            <-chan CommitData
+		blobDone, diffDone <-chan struct{}
+	)
 
 	blobStart := time.Now()
-	blobOut, blobDone := signalOnDrain(c.blobPipeline.Process(ctx, commitChan))
+
+	doStage(ctx, ProfileStageBlob, func(ctx context.Context) {
+		blobOut, blobDone = signalOnDrain(c.blobPipeline.Process(ctx, commitChan), c.config.Queues.queue(StageBlob, StageDiff))
+	})
 
 	diffStart := time.Now()
-	diffOut, diffDone := signalOnDrain(c.diffPipeline.Process(ctx, blobOut))
+
+	doStage(ctx, ProfileStageDiff, func(ctx context.Context) {
+		diffOut, diffDone = signalOnDrain(c.diffPipeline.Process(ctx, blobOut), c.diffQueue())
+	})
 
 	// Optionally add UAST pipeline stage for pre-computed UAST parsing.
 	var dataChan <-chan CommitData
@@ -458,7 +589,9 @@ func (c *Coordinator) Process(ctx context.Context, commits []*gitlib.Commit) <-c
 
 		var uastOut <-chan CommitData
 
-		uastOut, uastDone = signalOnDrain(c.uastPipeline.Process(ctx, diffOut))
+		doStage(ctx, ProfileStageUAST, func(ctx context.Context) {
+			uastOut, uastDone = signalOnDrain(c.uastPipeline.Process(ctx, diffOut), c.config.Queues.queue(StageUAST, StageLeaf))
+		})
 
 		dataChan = uastOut
 	} else {
@@ -483,6 +616,7 @@ func (c *Coordinator) Process(ctx context.Context, commits []*gitlib.Commit) <-c
 		// All stages are complete. Record timing and cache deltas.
 		c.recordStageTiming(blobDone, blobStart, diffDone, diffStart, uastDone, uastStart)
 		c.recordCacheDeltas(blobHitsBefore, blobMissesBefore, diffHitsBefore, diffMissesBefore)
+		c.stats.BlobCache = c.blobCacheStats().Since(blobStatsBefore)
 
 		// Cleanup: stop workers and free resources.
 		c.stopWorkers()
@@ -496,6 +630,16 @@ func (c *Coordinator) Process(ctx context.Context, commits []*gitlib.Commit) <-c
 	return finalChan
 }
 
+// diffQueue returns the queue after the diff stage, which feeds the UAST
+// stage, or the leaf analyzers when UAST parsing is off.
+func (c *Coordinator) diffQueue() *stageQueue {
+	if c.uastPipeline == nil {
+		return c.config.Queues.queue(StageDiff, StageLeaf)
+	}
+
+	return c.config.Queues.queue(StageDiff, StageUAST)
+}
+
 // recordStageTiming waits for each pipeline stage to finish and records its duration.
 func (c *Coordinator) recordStageTiming(
 	blobDone <-chan struct{}, blobStart time.Time,
@@ -528,6 +672,19 @@ func (c *Coordinator) recordCacheDeltas(blobHitsBefore, blobMissesBefore, diffHi
 		c.stats.DiffCacheHits = c.diffCache.CacheHits() - diffHitsBefore
 		c.stats.DiffCacheMisses = c.diffCache.CacheMisses() - diffMissesBefore
 	}
+
+	c.stats.DiffDeduped = c.diffPipeline.Deduped()
+	c.stats.UASTMemoHits, c.stats.UASTMemoMisses = cacheStats(c.uastPipeline)
+}
+
+// blobCacheStats returns the blob cache statistics, or zero stats when the
+// cache is disabled.
+func (c *Coordinator) blobCacheStats() CacheStats {
+	if c.blobCache == nil {
+		return CacheStats{}
+	}
+
+	return c.blobCache.Stats()
 }
 
 // stopWorkers closes request channels and stops all workers.
@@ -559,16 +716,31 @@ func cacheStats[T cacheStatsProvider](c T) (hits, misses int64) {
 
 // signalOnDrain returns a channel that is closed after all items from src
 // have been forwarded to dst. This enables ending stage spans independently.
-func signalOnDrain[T any](src <-chan T) (forwarded <-chan T, drained <-chan struct{}) {
+// When q is set, it records how long each side of the queue waited for the
+// other.
+func signalOnDrain[T any](src <-chan T, q *stageQueue) (forwarded <-chan T, drained <-chan struct{}) {
 	sig := make(chan struct{})
 	out := make(chan T)
 
 	go func() {
 		defer close(sig)
 		defer close(out)
+		defer q.drained()
+
+		for {
+			waitStart := time.Now()
 
-		for item := range src {
+			item, ok := <-src
+			if !ok {
+				return
+			}
+
+			q.received(time.Since(waitStart), len(src), cap(src)+1)
+
+			sendStart := time.Now()
 			out <- item
+
+			q.delivered(time.Since(sendStart))
 		}
 	}()
 
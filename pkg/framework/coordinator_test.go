@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/framework"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 )
@@ -151,6 +152,71 @@ func TestCoordinator_ProcessTwoCommitsWithModification(t *testing.T) {
 	}
 }
 
+func TestCoordinator_SkipBlobsAndDiffs(t *testing.T) {
+	t.Parallel()
+
+	repo := framework.NewTestRepo(t)
+	defer repo.Close()
+
+	repo.CreateFile("f.txt", "v1")
+	repo.Commit("first")
+	repo.CreateFile("f.txt", "v2")
+	repo.Commit("second")
+
+	libRepo, err := gitlib.OpenRepository(repo.Path())
+	if err != nil {
+		t.Fatalf("OpenRepository: %v", err)
+	}
+	defer libRepo.Free()
+
+	commits := framework.CollectCommits(t, libRepo, 2)
+
+	config := framework.CoordinatorConfig{
+		CommitBatchSize: 1,
+		Workers:         1,
+		BufferSize:      2,
+		BatchConfig:     gitlib.DefaultBatchConfig(),
+		SkipBlobs:       true,
+		SkipDiffs:       true,
+	}
+	coord := framework.NewCoordinator(libRepo, config)
+
+	for d := range coord.Process(context.Background(), commits) {
+		if d.Error != nil {
+			t.Fatalf("commit %d: %v", d.Index, d.Error)
+		}
+
+		if len(d.Changes) == 0 {
+			t.Errorf("commit %d: expected tree changes", d.Index)
+		}
+
+		if len(d.BlobCache) != 0 || len(d.FileDiffs) != 0 {
+			t.Errorf("commit %d: got %d blobs and %d diffs, want none", d.Index, len(d.BlobCache), len(d.FileDiffs))
+		}
+	}
+}
+
+func TestCoordinatorConfig_WithCapabilities(t *testing.T) {
+	t.Parallel()
+
+	base := framework.DefaultCoordinatorConfig()
+
+	none := base.WithCapabilities(analyze.Capabilities{})
+	if !none.SkipBlobs || !none.SkipDiffs || none.UASTPipelineWorkers != 0 {
+		t.Errorf("no needs: got SkipBlobs=%v SkipDiffs=%v UAST workers=%d",
+			none.SkipBlobs, none.SkipDiffs, none.UASTPipelineWorkers)
+	}
+
+	if none.EstimatedOverhead() >= base.EstimatedOverhead() {
+		t.Error("skipping blobs and diffs should lower the estimated overhead")
+	}
+
+	full := base.WithCapabilities(analyze.FullCapabilities())
+	if full.SkipBlobs || full.SkipDiffs || full.UASTPipelineWorkers != base.UASTPipelineWorkers {
+		t.Errorf("full needs changed the config: %+v", full)
+	}
+}
+
 func TestCoordinator_NewCoordinatorNormalizesConfig(t *testing.T) {
 	t.Parallel()
 
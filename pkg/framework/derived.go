@@ -0,0 +1,29 @@
+package framework
+
+import (
+	"fmt"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// runDerivedMetrics applies DerivedMetrics to the leaf reports, keyed by analyzer flag.
+func (runner *Runner) runDerivedMetrics(reports map[analyze.HistoryAnalyzer]analyze.Report) error {
+	if len(runner.DerivedMetrics) == 0 {
+		return nil
+	}
+
+	byFlag := make(map[string]analyze.Report, len(reports))
+
+	for a, report := range reports {
+		if report != nil {
+			byFlag[a.Flag()] = report
+		}
+	}
+
+	err := analyze.RunDerivedMetrics(byFlag, runner.DerivedMetrics)
+	if err != nil {
+		return fmt.Errorf("post-process reports: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package framework_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+)
+
+func TestFinalizeWithAggregators_RunsDerivedMetrics(t *testing.T) {
+	t.Parallel()
+
+	source := &stubLeaf{name: "source"}
+	target := &stubLeaf{name: "target"}
+
+	runner := &framework.Runner{
+		Analyzers: []analyze.HistoryAnalyzer{source, target},
+		DerivedMetrics: []analyze.DerivedMetric{
+			{
+				Name:     "seed",
+				Requires: []string{"source"},
+				Compute: func(reports map[string]analyze.Report) error {
+					reports["source"]["value"] = 42
+
+					return nil
+				},
+			},
+			{
+				Name:     "copy",
+				Requires: []string{"source", "target"},
+				Compute: func(reports map[string]analyze.Report) error {
+					reports["target"]["copied"] = reports["source"]["value"]
+
+					return nil
+				},
+			},
+			{
+				Name:     "skipped",
+				Requires: []string{"absent"},
+				Compute: func(_ map[string]analyze.Report) error {
+					t.Error("metric with a missing analyzer must not run")
+
+					return nil
+				},
+			},
+		},
+	}
+
+	framework.InitAggregatorsForTest(runner)
+
+	reports, err := runner.FinalizeWithAggregators(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 42, reports[target]["copied"])
+}
+
+func TestFinalizeWithAggregators_DerivedMetricError(t *testing.T) {
+	t.Parallel()
+
+	errBoom := errors.New("boom")
+
+	runner := &framework.Runner{
+		Analyzers: []analyze.HistoryAnalyzer{&stubLeaf{name: "source"}},
+		DerivedMetrics: []analyze.DerivedMetric{{
+			Name:    "failing",
+			Compute: func(_ map[string]analyze.Report) error { return errBoom },
+		}},
+	}
+
+	framework.InitAggregatorsForTest(runner)
+
+	_, err := runner.FinalizeWithAggregators(context.Background())
+	require.ErrorIs(t, err, errBoom)
+	assert.Contains(t, err.Error(), "failing")
+}
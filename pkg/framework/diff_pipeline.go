@@ -5,6 +5,8 @@ import (
 	"maps"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
 
@@ -29,6 +31,34 @@ type DiffPipeline struct {
 	PoolWorkerChan chan<- gitlib.WorkerRequest
 	BufferSize     int
 	DiffCache      *DiffCache
+
+	// Algorithm selects the line diff algorithm. Empty means Myers.
+	Algorithm gitlib.DiffAlgorithm
+	// Timeout bounds a single Go fallback diff. Zero keeps the diffmatchpatch default.
+	Timeout time.Duration
+	// NormalizeEOL diffs blobs with CRLF line endings turned into LF.
+	NormalizeEOL bool
+	// Skip passes commits through without computing diffs.
+	Skip bool
+
+	// deduped counts the diffs taken from a request of an earlier commit
+	// still in flight instead of being requested again.
+	deduped atomic.Int64
+}
+
+// maxDiffBatchSize is the number of diff requests sent to the pool workers
+// in one batch.
+const maxDiffBatchSize = 1000
+
+// maxInFlightDiffs bounds the blob pairs remembered for deduplication. Past
+// it, the pairs of older batches are forgotten: by then their results are in
+// the diff cache, when one is configured.
+const maxInFlightDiffs = 4 * maxDiffBatchSize
+
+// Deduped returns the number of diffs of the last Process call shared with
+// an identical blob pair of an earlier commit whose diff was still in flight.
+func (p *DiffPipeline) Deduped() int64 {
+	return p.deduped.Load()
 }
 
 // NewDiffPipeline creates a new diff pipeline.
@@ -60,6 +90,23 @@ type diffJob struct {
 	batchResp       *sharedDiffResponse
 	batchOffset     int
 	batchLen        int
+
+	// shared holds the diffs of blob pairs an earlier job already requested.
+	shared []sharedDiff
+}
+
+// diffRef locates the result of a diff request in a batch response.
+type diffRef struct {
+	resp  *sharedDiffResponse
+	index int
+}
+
+// sharedDiff is a change whose diff is read from the request of an earlier
+// job with the same blob pair.
+type sharedDiff struct {
+	path   string
+	change *gitlib.Change
+	ref    diffRef
 }
 
 // Process receives blob data and outputs commit data with computed diffs.
@@ -72,6 +119,8 @@ func (p *DiffPipeline) Process(ctx context.Context, blobs <-chan BlobData) <-cha
 	// Larger buffer for jobs to accumulate batch.
 	jobs := make(chan diffJob, p.BufferSize*diffJobBufferMultiplier)
 
+	p.deduped.Store(0)
+
 	go p.runDiffProducer(ctx, blobs, jobs)
 	go p.runDiffConsumer(ctx, jobs, out)
 
@@ -87,11 +136,13 @@ func (p *DiffPipeline) runDiffProducer(ctx context.Context, blobs <-chan BlobDat
 	// Since BlobPipeline emits BlobData which already contains multiple diffs per commit,
 	// we are effectively re-batching across commits.
 
-	const maxBatchSize = 1000
-
 	var (
 		currentBatchReqs []gitlib.DiffRequest
 		currentBatchJobs []*diffJob
+		// sharedResp is the response of the current batch, created up front
+		// so that later jobs can refer to its requests.
+		sharedResp = &sharedDiffResponse{}
+		inFlight   = make(map[DiffKey]diffRef)
 	)
 
 	flushBatch := func() {
@@ -99,8 +150,6 @@ func (p *DiffPipeline) runDiffProducer(ctx context.Context, blobs <-chan BlobDat
 			return
 		}
 
-		var sharedResp *sharedDiffResponse
-
 		// Only fire CGO request if there are actual diff requests.
 		if len(currentBatchReqs) > 0 {
 			req := gitlib.DiffBatchRequest{Ctx: ctx, Requests: currentBatchReqs}
@@ -114,10 +163,7 @@ func (p *DiffPipeline) runDiffProducer(ctx context.Context, blobs <-chan BlobDat
 				return
 			}
 
-			// Create a shared state for this batch.
-			sharedResp = &sharedDiffResponse{
-				respChan: respChan,
-			}
+			sharedResp.respChan = respChan
 		}
 
 		// Assign shared response to all jobs and dispatch.
@@ -125,7 +171,7 @@ func (p *DiffPipeline) runDiffProducer(ctx context.Context, blobs <-chan BlobDat
 
 		for _, job := range currentBatchJobs {
 			count := len(job.pendingRequests)
-			if count > 0 && sharedResp != nil {
+			if count > 0 {
 				job.batchResp = sharedResp
 				job.batchOffset = startIdx
 				job.batchLen = count
@@ -142,6 +188,11 @@ func (p *DiffPipeline) runDiffProducer(ctx context.Context, blobs <-chan BlobDat
 		// Reset batch.
 		currentBatchReqs = nil
 		currentBatchJobs = nil
+		sharedResp = &sharedDiffResponse{}
+
+		if len(inFlight) >= maxInFlightDiffs {
+			clear(inFlight)
+		}
 	}
 
 	for blobData := range blobs {
@@ -156,6 +207,8 @@ func (p *DiffPipeline) runDiffProducer(ctx context.Context, blobs <-chan BlobDat
 			return
 		}
 
+		reqs = p.dedupe(job, reqs, inFlight, sharedResp, len(currentBatchReqs))
+
 		if len(reqs) > 0 {
 			currentBatchReqs = append(currentBatchReqs, reqs...)
 			job.pendingRequests = reqs // Keep track for offset calculation.
@@ -163,7 +216,7 @@ func (p *DiffPipeline) runDiffProducer(ctx context.Context, blobs <-chan BlobDat
 
 		currentBatchJobs = append(currentBatchJobs, job)
 
-		if len(currentBatchReqs) >= maxBatchSize {
+		if len(currentBatchReqs) >= maxDiffBatchSize {
 			flushBatch()
 		}
 	}
@@ -190,6 +243,43 @@ func (s *sharedDiffResponse) wait(ctx context.Context) {
 	})
 }
 
+// dedupe moves the requests of job whose blob pair is already in flight to
+// job.shared and registers the others in inFlight at their position in the
+// batch of resp, which already holds offset requests. It returns the requests
+// left to send.
+func (p *DiffPipeline) dedupe(
+	job *diffJob, reqs []gitlib.DiffRequest, inFlight map[DiffKey]diffRef, resp *sharedDiffResponse, offset int,
+) []gitlib.DiffRequest {
+	if len(reqs) == 0 {
+		return reqs
+	}
+
+	kept := reqs[:0]
+	paths := job.paths[:0]
+	changes := job.changes[:0]
+
+	for i, req := range reqs {
+		key := DiffKey{OldHash: req.OldHash, NewHash: req.NewHash}
+
+		if ref, ok := inFlight[key]; ok {
+			job.shared = append(job.shared, sharedDiff{path: job.paths[i], change: job.changes[i], ref: ref})
+			p.deduped.Add(1)
+
+			continue
+		}
+
+		inFlight[key] = diffRef{resp: resp, index: offset + len(kept)}
+		kept = append(kept, req)
+		paths = append(paths, job.paths[i])
+		changes = append(changes, job.changes[i])
+	}
+
+	job.paths = paths
+	job.changes = changes
+
+	return kept
+}
+
 // createDiffJobInternal prepares the job but doesn't fire requests.
 func (p *DiffPipeline) createDiffJobInternal(_ context.Context, blobData BlobData) (*diffJob, []gitlib.DiffRequest) {
 	commitData := CommitData{
@@ -202,7 +292,7 @@ func (p *DiffPipeline) createDiffJobInternal(_ context.Context, blobData BlobDat
 
 	job := &diffJob{data: commitData}
 
-	if commitData.Error != nil {
+	if commitData.Error != nil || p.Skip {
 		return job, nil
 	}
 
@@ -255,6 +345,10 @@ func (p *DiffPipeline) runDiffConsumer(ctx context.Context, jobs <-chan diffJob,
 			}
 		}
 
+		if job.data.Error == nil {
+			p.processSharedDiffs(ctx, &job)
+		}
+
 		select {
 		case out <- job.data:
 		case <-ctx.Done():
@@ -302,12 +396,13 @@ func (p *DiffPipeline) prepareDiffRequest(blobData BlobData) (
 		}
 
 		requests = append(requests, gitlib.DiffRequest{
-			OldHash: change.From.Hash,
-			NewHash: change.To.Hash,
-			OldData: oldBlob.Data,
-			NewData: newBlob.Data,
-			HasOld:  true,
-			HasNew:  true,
+			OldHash:   change.From.Hash,
+			NewHash:   change.To.Hash,
+			OldData:   p.diffData(oldBlob),
+			NewData:   p.diffData(newBlob),
+			HasOld:    true,
+			HasNew:    true,
+			Algorithm: p.Algorithm,
 		})
 		paths = append(paths, change.To.Name)
 		changes = append(changes, change)
@@ -327,32 +422,11 @@ func (p *DiffPipeline) processDiffResponse(
 	diffResults := resp.Results
 
 	for i, path := range paths {
-		oldBlob := data.BlobCache[changes[i].From.Hash]
-		newBlob := data.BlobCache[changes[i].To.Hash]
-
-		// Use Go's counting.
-		oldLines, errOld := oldBlob.CountLines()
-		newLines, errNew := newBlob.CountLines()
-
-		if errOld != nil || errNew != nil {
+		fileDiff, ok := p.fileDiff(data, changes[i], diffResults[i])
+		if !ok {
 			continue
 		}
 
-		diffRes := diffResults[i]
-
-		var fileDiff plumbing.FileDiffData
-
-		if diffRes.Error != nil {
-			fileDiff = p.fileDiffFromGoDiff(oldBlob, newBlob, oldLines, newLines)
-		} else {
-			diffs := convertDiffOpsToDMP(diffRes.Ops)
-			fileDiff = plumbing.FileDiffData{
-				OldLinesOfCode: oldLines,
-				NewLinesOfCode: newLines,
-				Diffs:          diffs,
-			}
-		}
-
 		data.FileDiffs[path] = fileDiff
 
 		// Store in cache.
@@ -363,6 +437,57 @@ func (p *DiffPipeline) processDiffResponse(
 	}
 }
 
+// processSharedDiffs fills the diffs of job that share the request of an
+// earlier job. That job was emitted first, so its response has arrived.
+func (p *DiffPipeline) processSharedDiffs(ctx context.Context, job *diffJob) {
+	for _, shared := range job.shared {
+		shared.ref.resp.wait(ctx)
+
+		if shared.ref.resp.err != nil {
+			job.data.Error = shared.ref.resp.err
+
+			return
+		}
+
+		if shared.ref.index >= len(shared.ref.resp.results) {
+			continue
+		}
+
+		fileDiff, ok := p.fileDiff(job.data, shared.change, shared.ref.resp.results[shared.ref.index])
+		if ok {
+			job.data.FileDiffs[shared.path] = fileDiff
+		}
+	}
+}
+
+// fileDiff builds the diff of change from its C diff result, falling back to
+// the Go diff when the C diff failed. It reports false when the lines of a
+// blob cannot be counted.
+func (p *DiffPipeline) fileDiff(
+	data CommitData, change *gitlib.Change, diffRes gitlib.DiffResult,
+) (plumbing.FileDiffData, bool) {
+	oldBlob := data.BlobCache[change.From.Hash]
+	newBlob := data.BlobCache[change.To.Hash]
+
+	// Use Go's counting.
+	oldLines, errOld := oldBlob.CountLines()
+	newLines, errNew := newBlob.CountLines()
+
+	if errOld != nil || errNew != nil {
+		return plumbing.FileDiffData{}, false
+	}
+
+	if diffRes.Error != nil {
+		return p.fileDiffFromGoDiff(oldBlob, newBlob, oldLines, newLines), true
+	}
+
+	return plumbing.FileDiffData{
+		OldLinesOfCode: oldLines,
+		NewLinesOfCode: newLines,
+		Diffs:          convertDiffOpsToDMP(diffRes.Ops),
+	}, true
+}
+
 func convertDiffOpsToDMP(ops []gitlib.DiffOp) []diffmatchpatch.Diff {
 	diffs := make([]diffmatchpatch.Diff, 0, len(ops))
 
@@ -389,8 +514,17 @@ func convertDiffOpsToDMP(ops []gitlib.DiffOp) []diffmatchpatch.Diff {
 	return diffs
 }
 
+// diffData returns the contents of blob to diff.
+func (p *DiffPipeline) diffData(blob *gitlib.CachedBlob) []byte {
+	if p.NormalizeEOL {
+		return plumbing.NormalizeEOL(blob.Data)
+	}
+
+	return blob.Data
+}
+
 func (p *DiffPipeline) fileDiffFromGoDiff(oldBlob, newBlob *gitlib.CachedBlob, oldLines, newLines int) plumbing.FileDiffData {
-	strFrom, strTo := string(oldBlob.Data), string(newBlob.Data)
+	strFrom, strTo := string(p.diffData(oldBlob)), string(p.diffData(newBlob))
 
 	if strFrom == strTo {
 		return plumbing.FileDiffData{
@@ -401,8 +535,12 @@ func (p *DiffPipeline) fileDiffFromGoDiff(oldBlob, newBlob *gitlib.CachedBlob, o
 	}
 
 	dmp := diffmatchpatch.New()
+	if p.Timeout > 0 {
+		dmp.DiffTimeout = p.Timeout
+	}
+
 	src, dst, _ := dmp.DiffLinesToRunes(strFrom, strTo)
-	diffs := dmp.DiffMainRunes(src, dst, false)
+	diffs := plumbing.DiffLineRunes(dmp, src, dst, p.Algorithm)
 	diffs = dmp.DiffCleanupMerge(dmp.DiffCleanupSemanticLossless(diffs))
 
 	return plumbing.FileDiffData{
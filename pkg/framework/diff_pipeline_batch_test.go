@@ -119,3 +119,113 @@ func TestDiffPipeline_CrossCommitBatching(t *testing.T) {
 		t.Errorf("Expected 2 output items, got %d", count)
 	}
 }
+
+func TestDiffPipeline_DedupesInFlightPairs(t *testing.T) {
+	t.Parallel()
+
+	poolCh := make(chan gitlib.WorkerRequest, 10)
+	pipeline := framework.NewDiffPipeline(poolCh, 10)
+
+	hashA := gitlib.Hash{0: 0xA}
+	hashB := gitlib.Hash{0: 0xB}
+
+	cache := map[gitlib.Hash]*gitlib.CachedBlob{
+		hashA: gitlib.NewCachedBlobWithHashForTest(hashA, []byte("A")),
+		hashB: gitlib.NewCachedBlobWithHashForTest(hashB, []byte("B")),
+	}
+
+	// Three commits make the same change, the last one to two files.
+	inputCh := make(chan framework.BlobData, 3)
+
+	for i, names := range [][]string{{"file1"}, {"file2"}, {"file3", "file4"}} {
+		var changes gitlib.Changes
+
+		for _, name := range names {
+			changes = append(changes, &gitlib.Change{
+				Action: gitlib.Modify,
+				From:   gitlib.ChangeEntry{Hash: hashA, Name: name},
+				To:     gitlib.ChangeEntry{Hash: hashB, Name: name},
+			})
+		}
+
+		inputCh <- framework.BlobData{
+			Commit:    gitlib.NewCommitForTest(gitlib.Hash{0: byte(i + 1)}),
+			Changes:   changes,
+			BlobCache: cache,
+		}
+	}
+
+	close(inputCh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	outCh := pipeline.Process(ctx, inputCh)
+
+	requested := make(chan int, 1)
+
+	go func() {
+		total := 0
+
+		defer func() { requested <- total }()
+
+		for {
+			select {
+			case req := <-poolCh:
+				diffReq, ok := req.(gitlib.DiffBatchRequest)
+				if !ok {
+					continue
+				}
+
+				total += len(diffReq.Requests)
+
+				results := make([]gitlib.DiffResult, len(diffReq.Requests))
+				for i := range results {
+					results[i] = gitlib.DiffResult{
+						OldLines: 1,
+						NewLines: 1,
+						Ops: []gitlib.DiffOp{
+							{Type: gitlib.DiffOpDelete, LineCount: 1},
+							{Type: gitlib.DiffOpInsert, LineCount: 1},
+						},
+					}
+				}
+
+				diffReq.Response <- gitlib.DiffBatchResponse{Results: results}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	files := 0
+
+	for data := range outCh {
+		if data.Error != nil {
+			t.Fatalf("commit %s: %v", data.Commit.Hash(), data.Error)
+		}
+
+		for path, fd := range data.FileDiffs {
+			files++
+
+			if len(fd.Diffs) != 2 {
+				t.Errorf("File %s: got %d diffs, want 2", path, len(fd.Diffs))
+			}
+		}
+	}
+
+	cancel()
+
+	if files != 4 {
+		t.Errorf("got %d file diffs, want 4", files)
+	}
+
+	if total := <-requested; total != 1 {
+		t.Errorf("requested %d diffs, want 1", total)
+	}
+
+	if pipeline.Deduped() != 3 {
+		t.Errorf("Deduped() = %d, want 3", pipeline.Deduped())
+	}
+}
@@ -71,13 +71,73 @@ func TestDiffPipeline_Process_GoFallbackWhenDiffErrors(t *testing.T) {
 	changes, blobCache := getTreeDiffAndBlobs(t, libRepo, commits)
 
 	mockCh := startMockDiffWorker()
-	results := runDiffPipeline(t, commits[0], changes, blobCache, mockCh)
+	results := runDiffPipeline(t, commits[0], changes, blobCache, framework.NewDiffPipeline(mockCh, 1))
 
 	close(mockCh)
 
 	validateDiffResults(t, results)
 }
 
+// TestDiffPipeline_Process_Algorithm checks that the configured algorithm reaches
+// the C diff requests and the Go fallback.
+func TestDiffPipeline_Process_Algorithm(t *testing.T) {
+	t.Parallel()
+
+	repo := framework.NewTestRepo(t)
+	defer repo.Close()
+
+	repo.CreateFile("f.go", "}\n}\nfunc x() {\n}\n")
+	repo.Commit("first")
+	repo.CreateFile("f.go", "func x() {\n}\n}\n}\n")
+	repo.Commit("second")
+
+	libRepo, err := gitlib.OpenRepository(repo.Path())
+	if err != nil {
+		t.Fatalf("OpenRepository: %v", err)
+	}
+	defer libRepo.Free()
+
+	commits := framework.CollectCommits(t, libRepo, 2)
+	changes, blobCache := getTreeDiffAndBlobs(t, libRepo, commits)
+
+	mockCh := make(chan gitlib.WorkerRequest, 2)
+	requested := make(chan gitlib.DiffAlgorithm, 1)
+
+	go func() {
+		for req := range mockCh {
+			if r, ok := req.(gitlib.DiffBatchRequest); ok {
+				requested <- r.Requests[0].Algorithm
+
+				results := make([]gitlib.DiffResult, len(r.Requests))
+				for i := range results {
+					results[i].Error = errInjectedDiff
+				}
+
+				r.Response <- gitlib.DiffBatchResponse{Results: results}
+			}
+		}
+	}()
+
+	p := framework.NewDiffPipeline(mockCh, 1)
+	p.Algorithm = gitlib.DiffPatience
+
+	results := runDiffPipeline(t, commits[0], changes, blobCache, p)
+
+	close(mockCh)
+
+	if got := <-requested; got != gitlib.DiffPatience {
+		t.Errorf("requested algorithm = %q, want patience", got)
+	}
+
+	validateDiffResults(t, results)
+
+	// Patience keeps func x whole instead of matching the repeated braces.
+	diffs := results[0].FileDiffs["f.go"].Diffs
+	if len(diffs) == 0 || diffs[0].Type != diffmatchpatch.DiffDelete || len([]rune(diffs[0].Text)) != 2 {
+		t.Errorf("fallback diff = %v, want a leading 2-line delete", diffs)
+	}
+}
+
 func setupTestRepoWithTwoCommits(repo *framework.TestRepo) {
 	repo.CreateFile("f.txt", "line1\nline2\n")
 	repo.Commit("first")
@@ -206,7 +266,7 @@ func runDiffPipeline(
 	commit *gitlib.Commit,
 	changes gitlib.Changes,
 	blobCache map[gitlib.Hash]*gitlib.CachedBlob,
-	mockCh chan gitlib.WorkerRequest,
+	p *framework.DiffPipeline,
 ) []framework.CommitData {
 	t.Helper()
 
@@ -223,7 +283,6 @@ func runDiffPipeline(
 
 	close(blobs)
 
-	p := framework.NewDiffPipeline(mockCh, 1)
 	ctx := context.Background()
 	out := p.Process(ctx, blobs)
 
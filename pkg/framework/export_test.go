@@ -1,6 +1,7 @@
 package framework
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	pkgplumbing "github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
 )
 
 // TestRepo is a temporary git repo for framework tests.
@@ -332,3 +334,23 @@ func TCCountAccumulatedForTest(runner *Runner) int64 {
 func ResetTCCountForTest(runner *Runner) {
 	runner.ResetTCCount()
 }
+
+// SetSoftLimitEventsForTest sets the soft memory limit events a runner reacts to.
+func SetSoftLimitEventsForTest(runner *Runner, events <-chan streaming.SoftLimitEvent) {
+	runner.softLimitEvents = events
+}
+
+// SetChunkBlobCacheForTest sets the blob cache of the chunk in progress.
+func SetChunkBlobCacheForTest(runner *Runner, cache *GlobalBlobCache) {
+	runner.chunkBlobCache = cache
+}
+
+// RelieveSoftLimitForTest exposes relieveSoftLimit for unit testing.
+func RelieveSoftLimitForTest(ctx context.Context, runner *Runner) {
+	runner.relieveSoftLimit(ctx)
+}
+
+// ShardHashesForTest exposes BlobPipeline.shardHashes for testing.
+func (p *BlobPipeline) ShardHashesForTest(hashes []gitlib.Hash) [][]gitlib.Hash {
+	return p.shardHashes(hashes)
+}
@@ -0,0 +1,76 @@
+package framework
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
+)
+
+// Nice mode tuning.
+const (
+	// niceCPUDivisor is the fraction of the CPUs a nice run may use.
+	niceCPUDivisor = 4
+
+	// niceSleepRatio is the idle time after each chunk, relative to the time
+	// the chunk took. 0.25 keeps the run busy about 80% of its wall time.
+	niceSleepRatio = 0.25
+
+	// maxNiceSleep caps the idle time after a chunk.
+	maxNiceSleep = 10 * time.Second
+)
+
+// EnterNiceMode lowers the footprint of the process for a background run on
+// a shared host: GOMAXPROCS drops to a quarter of the CPUs, and on Linux the
+// CPU nice value and I/O priority of every thread are lowered. The worker
+// pools of config are capped to match. A failure to change the OS priority is
+// logged and otherwise ignored.
+func EnterNiceMode(ctx context.Context, logger *slog.Logger, config *CoordinatorConfig) {
+	procs := max(runtime.NumCPU()/niceCPUDivisor, 1)
+	runtime.GOMAXPROCS(procs)
+
+	config.Workers = min(config.Workers, procs)
+	config.BufferSize = min(config.BufferSize, procs*bufferSizeMultiplier)
+	config.UASTPipelineWorkers = min(config.UASTPipelineWorkers, procs)
+	config.LeafWorkers = min(config.LeafWorkers, procs)
+
+	err := lowerOSPriority()
+	if err != nil {
+		logger.WarnContext(ctx, "nice: could not lower process priority", "error", err)
+	}
+
+	logger.InfoContext(ctx, "nice: running at low priority",
+		"gomaxprocs", procs, "workers", config.Workers)
+}
+
+// niceSleep idles between chunks in nice mode, for a share of the time the
+// last chunk took, so other workloads get the CPU and disk in between.
+func niceSleep(ctx context.Context, runner *Runner, lastChunk time.Duration) error {
+	if !runner.Nice || lastChunk <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(min(time.Duration(float64(lastChunk)*niceSleepRatio), maxNiceSleep))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shedCaches halves the blob and diff caches of the chunks still to come.
+// Called by nice runs under memory pressure, so the run gives memory back
+// instead of competing for it.
+func (runner *Runner) shedCaches(ctx context.Context, logger *slog.Logger) {
+	runner.Config.BlobCacheSize /= cacheShedDivisor
+	runner.Config.DiffCacheSize /= cacheShedDivisor
+
+	logger.InfoContext(ctx, "nice: shrinking caches under memory pressure",
+		"blob_cache_mib", runner.Config.BlobCacheSize/streaming.MiB,
+		"diff_cache_entries", runner.Config.DiffCacheSize)
+}
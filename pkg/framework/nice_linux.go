@@ -0,0 +1,61 @@
+//go:build linux
+
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// OS priorities of a nice run.
+const (
+	// niceValue is the CPU nice value of a nice run (0 normal, 19 lowest).
+	niceValue = 10
+
+	// ioprioBestEffortLowest is the lowest level of the best-effort I/O
+	// class: (IOPRIO_CLASS_BE << IOPRIO_CLASS_SHIFT) | 7. The idle class is
+	// avoided because it can stall the run indefinitely on a busy disk.
+	ioprioBestEffortLowest = 2<<13 | 7
+
+	// ioprioWhoProcess selects a single thread in ioprio_set.
+	ioprioWhoProcess = 1
+)
+
+// lowerOSPriority sets the CPU nice value and I/O priority of every thread of
+// the process. Linux applies both per thread, and threads created later
+// inherit them from the thread that spawns them.
+func lowerOSPriority() error {
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return fmt.Errorf("list threads: %w", err)
+	}
+
+	var errs []error
+
+	for _, task := range tasks {
+		tid, convErr := strconv.Atoi(task.Name())
+		if convErr != nil {
+			continue
+		}
+
+		prioErr := unix.Setpriority(unix.PRIO_PROCESS, tid, niceValue)
+		if errors.Is(prioErr, unix.ESRCH) {
+			continue // The thread exited.
+		}
+
+		if prioErr != nil {
+			errs = append(errs, fmt.Errorf("setpriority %d: %w", tid, prioErr))
+		}
+
+		_, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), ioprioBestEffortLowest)
+		if errno != 0 {
+			errs = append(errs, fmt.Errorf("ioprio_set %d: %w", tid, errno))
+		}
+	}
+
+	return errors.Join(errs...)
+}
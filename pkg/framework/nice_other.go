@@ -0,0 +1,9 @@
+//go:build !linux
+
+package framework
+
+// lowerOSPriority is a no-op outside Linux; nice mode then only lowers
+// GOMAXPROCS, the worker counts, and the pace between chunks.
+func lowerOSPriority() error {
+	return nil
+}
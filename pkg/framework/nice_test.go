@@ -0,0 +1,58 @@
+package framework
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
+)
+
+func TestNiceSleep(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	start := time.Now()
+	require.NoError(t, niceSleep(ctx, &Runner{}, time.Hour))
+	assert.Less(t, time.Since(start), time.Second, "no pause outside nice mode")
+
+	start = time.Now()
+	require.NoError(t, niceSleep(ctx, &Runner{Nice: true}, 200*time.Millisecond))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, niceSleep(cancelled, &Runner{Nice: true}, time.Hour), context.Canceled)
+}
+
+func TestMaxBuffering_Nice(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, maxStreamingBuffering, maxBuffering(&Runner{}))
+	assert.Equal(t, 1, maxBuffering(&Runner{Nice: true}))
+}
+
+func TestHandleMemoryPressure_NiceShedsCaches(t *testing.T) {
+	t.Parallel()
+
+	budget := int64(1000 * streaming.MiB)
+	relaxed := streaming.HeapSnapshot{HeapInuse: budget / 2}
+	pressured := streaming.HeapSnapshot{HeapInuse: budget * 85 / 100}
+
+	runner := &Runner{Nice: true, Config: CoordinatorConfig{BlobCacheSize: 512 * streaming.MiB, DiffCacheSize: 1000}}
+
+	handleMemoryPressure(context.Background(), discardControlLogger(), runner, relaxed, budget)
+	assert.Equal(t, int64(512*streaming.MiB), runner.Config.BlobCacheSize)
+
+	handleMemoryPressure(context.Background(), discardControlLogger(), runner, pressured, budget)
+	assert.Equal(t, int64(256*streaming.MiB), runner.Config.BlobCacheSize)
+	assert.Equal(t, 500, runner.Config.DiffCacheSize)
+
+	normal := &Runner{Config: CoordinatorConfig{BlobCacheSize: 512 * streaming.MiB}}
+	handleMemoryPressure(context.Background(), discardControlLogger(), normal, pressured, budget)
+	assert.Equal(t, int64(512*streaming.MiB), normal.Config.BlobCacheSize)
+}
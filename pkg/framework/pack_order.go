@@ -0,0 +1,33 @@
+package framework
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// EnablePackOrder loads the pack index of repo into config, so every commit
+// batch loads its blobs in pack order rather than hash order. Analyzers still
+// see the commits in history order: each commit gets its own blob snapshot
+// once the batch is loaded. Without packs, or when the index cannot be read,
+// blob loading keeps its default order and a failure is logged.
+func EnablePackOrder(ctx context.Context, logger *slog.Logger, config *CoordinatorConfig, repo *gitlib.Repository) {
+	idx, err := repo.PackIndex()
+	if err != nil {
+		logger.WarnContext(ctx, "pack order: could not read the pack index, keeping hash order", "error", err)
+
+		return
+	}
+
+	if idx.Packs() == 0 {
+		logger.InfoContext(ctx, "pack order: repository has no packs, keeping hash order")
+
+		return
+	}
+
+	config.PackIndex = idx
+
+	logger.InfoContext(ctx, "pack order: loading blobs in pack order",
+		"packs", idx.Packs(), "objects", idx.Objects())
+}
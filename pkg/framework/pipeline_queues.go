@@ -0,0 +1,262 @@
+package framework
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/observability"
+)
+
+// Coordinator stages on either side of a pipeline queue.
+const (
+	StageBlob = "blob"
+	StageDiff = "diff"
+	StageUAST = "uast"
+	StageLeaf = "leaf"
+)
+
+// pipelineDebugPadding is the column padding of the pipeline debug table.
+const pipelineDebugPadding = 2
+
+// stageKnobs names the setting that adds workers to a stage.
+var stageKnobs = map[string]string{
+	StageBlob: "Workers (--workers)",
+	StageDiff: "Workers (--workers)",
+	StageUAST: "UASTPipelineWorkers",
+	StageLeaf: "LeafWorkers",
+}
+
+// PipelineQueues measures the queues between coordinator stages across the
+// chunks of a run: how many commits wait in each queue, and how long each
+// side of it waited for the other. Set it as CoordinatorConfig.Queues.
+type PipelineQueues struct {
+	mu     sync.Mutex
+	queues []*stageQueue
+}
+
+// NewPipelineQueues creates an empty queue tracker.
+func NewPipelineQueues() *PipelineQueues {
+	return &PipelineQueues{}
+}
+
+// queue returns the queue between the from and to stages, creating it on
+// first use. Returns nil on a nil tracker.
+func (pq *PipelineQueues) queue(from, to string) *stageQueue {
+	if pq == nil {
+		return nil
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	for _, q := range pq.queues {
+		if q.from == from && q.to == to {
+			return q
+		}
+	}
+
+	q := &stageQueue{from: from, to: to}
+	pq.queues = append(pq.queues, q)
+
+	return q
+}
+
+// Stats returns the statistics of every queue, in pipeline order.
+func (pq *PipelineQueues) Stats() []QueueStats {
+	if pq == nil {
+		return nil
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	stats := make([]QueueStats, 0, len(pq.queues))
+	for _, q := range pq.queues {
+		stats = append(stats, q.stats())
+	}
+
+	return stats
+}
+
+// PipelineQueues implements observability.PipelineQueueProvider.
+func (pq *PipelineQueues) PipelineQueues() []observability.PipelineQueueStats {
+	stats := pq.Stats()
+
+	out := make([]observability.PipelineQueueStats, 0, len(stats))
+	for _, s := range stats {
+		out = append(out, observability.PipelineQueueStats{
+			Queue:    s.Name(),
+			Depth:    int64(s.Depth),
+			Capacity: int64(s.Capacity),
+			Starved:  s.Starved,
+			Blocked:  s.Blocked,
+		})
+	}
+
+	return out
+}
+
+// QueueStats describes the queue between two coordinator stages.
+type QueueStats struct {
+	From string
+	To   string
+
+	// Capacity is the number of commits the queue holds.
+	Capacity int
+	// Depth is the number of commits waiting in the queue now.
+	Depth int
+	// MaxDepth and MeanDepth are the deepest queue and the mean queue depth
+	// seen by the commits going through it.
+	MaxDepth  int
+	MeanDepth float64
+	// Items is the number of commits that went through the queue.
+	Items int64
+
+	// Starved is the time the To stage waited for commits of the From stage:
+	// the From stage is the slower one.
+	Starved time.Duration
+	// Blocked is the time the From stage waited for the To stage to take its
+	// commits: the To stage is the slower one.
+	Blocked time.Duration
+}
+
+// Name returns the queue name, such as "diff->uast".
+func (s QueueStats) Name() string {
+	return s.From + "->" + s.To
+}
+
+// Bottleneck returns the stage that held the queue up: the To stage when
+// the From stage waited longer for it than the other way round, the From
+// stage otherwise. Returns "" for a queue that never waited.
+func (s QueueStats) Bottleneck() string {
+	switch {
+	case s.Blocked > s.Starved:
+		return s.To
+	case s.Starved > 0:
+		return s.From
+	default:
+		return ""
+	}
+}
+
+// WritePipelineDebug writes the queue statistics as a table, followed by the
+// stage that slowed the pipeline down the most and the setting that adds
+// workers to it.
+func WritePipelineDebug(w io.Writer, stats []QueueStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, pipelineDebugPadding, ' ', 0)
+
+	fmt.Fprintln(tw, "QUEUE\tCOMMITS\tCAPACITY\tMEAN DEPTH\tMAX DEPTH\tSTARVED\tBLOCKED\tBOTTLENECK")
+
+	var (
+		worst     string
+		worstWait time.Duration
+	)
+
+	for _, s := range stats {
+		bottleneck := s.Bottleneck()
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%d\t%s\t%s\t%s\n", s.Name(), s.Items, s.Capacity, s.MeanDepth, s.MaxDepth,
+			s.Starved.Round(time.Millisecond), s.Blocked.Round(time.Millisecond), bottleneck)
+
+		// The To stage slowed everything upstream of it down.
+		if s.Blocked > worstWait && s.Blocked > s.Starved {
+			worst, worstWait = s.To, s.Blocked
+		}
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return fmt.Errorf("write pipeline debug: %w", err)
+	}
+
+	if worst == "" {
+		_, err = fmt.Fprintln(w, "No stage held its upstream stage up.")
+	} else {
+		_, err = fmt.Fprintf(w, "The %s stage held the pipeline up for %s: consider raising %s.\n",
+			worst, worstWait.Round(time.Millisecond), stageKnobs[worst])
+	}
+
+	if err != nil {
+		return fmt.Errorf("write pipeline debug: %w", err)
+	}
+
+	return nil
+}
+
+// stageQueue measures one queue. Its methods are no-ops on a nil queue.
+type stageQueue struct {
+	from, to string
+
+	capacity atomic.Int64
+	depth    atomic.Int64
+	maxDepth atomic.Int64
+	depthSum atomic.Int64
+	items    atomic.Int64
+	starved  atomic.Int64
+	blocked  atomic.Int64
+}
+
+// received records a commit taken from the From stage after waiting for it,
+// with buffered more commits left in a queue of capacity commits.
+func (q *stageQueue) received(waited time.Duration, buffered, capacity int) {
+	if q == nil {
+		return
+	}
+
+	depth := int64(buffered) + 1
+
+	q.starved.Add(int64(waited))
+	q.items.Add(1)
+	q.depthSum.Add(depth)
+	q.depth.Store(depth)
+	q.capacity.Store(int64(capacity))
+
+	for {
+		peak := q.maxDepth.Load()
+		if depth <= peak || q.maxDepth.CompareAndSwap(peak, depth) {
+			return
+		}
+	}
+}
+
+// delivered records a commit handed to the To stage after waiting for it.
+func (q *stageQueue) delivered(waited time.Duration) {
+	if q == nil {
+		return
+	}
+
+	q.blocked.Add(int64(waited))
+	q.depth.Add(-1)
+}
+
+// drained records the end of a chunk: nothing is left in the queue.
+func (q *stageQueue) drained() {
+	if q == nil {
+		return
+	}
+
+	q.depth.Store(0)
+}
+
+func (q *stageQueue) stats() QueueStats {
+	s := QueueStats{
+		From:     q.from,
+		To:       q.to,
+		Capacity: int(q.capacity.Load()),
+		Depth:    int(max(q.depth.Load(), 0)),
+		MaxDepth: int(q.maxDepth.Load()),
+		Items:    q.items.Load(),
+		Starved:  time.Duration(q.starved.Load()),
+		Blocked:  time.Duration(q.blocked.Load()),
+	}
+
+	if s.Items > 0 {
+		s.MeanDepth = float64(q.depthSum.Load()) / float64(s.Items)
+	}
+
+	return s
+}
@@ -0,0 +1,78 @@
+package framework
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalOnDrain_MeasuresQueue(t *testing.T) {
+	t.Parallel()
+
+	queues := NewPipelineQueues()
+	q := queues.queue(StageDiff, StageUAST)
+
+	src := make(chan int, 4)
+	for i := range 4 {
+		src <- i
+	}
+
+	close(src)
+
+	out, drained := signalOnDrain(src, q)
+
+	var got []int
+	for item := range out {
+		got = append(got, item)
+	}
+
+	<-drained
+
+	assert.Equal(t, []int{0, 1, 2, 3}, got)
+	assert.Same(t, q, queues.queue(StageDiff, StageUAST))
+
+	stats := queues.Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "diff->uast", stats[0].Name())
+	assert.Equal(t, int64(4), stats[0].Items)
+	assert.Equal(t, 5, stats[0].Capacity)
+	assert.Zero(t, stats[0].Depth, "a drained queue is empty")
+	assert.Equal(t, 4, stats[0].MaxDepth, "the first commit found three more behind it")
+	assert.InDelta(t, 2.5, stats[0].MeanDepth, 1e-9)
+
+	exported := queues.PipelineQueues()
+	require.Len(t, exported, 1)
+	assert.Equal(t, "diff->uast", exported[0].Queue)
+	assert.Equal(t, int64(5), exported[0].Capacity)
+}
+
+func TestQueueStats_Bottleneck(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, StageLeaf, QueueStats{From: StageUAST, To: StageLeaf, Blocked: time.Second}.Bottleneck())
+	assert.Equal(t, StageUAST, QueueStats{From: StageUAST, To: StageLeaf, Starved: time.Second}.Bottleneck())
+	assert.Empty(t, QueueStats{From: StageUAST, To: StageLeaf}.Bottleneck())
+}
+
+func TestWritePipelineDebug(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, WritePipelineDebug(&buf, []QueueStats{
+		{From: StageDiff, To: StageUAST, Items: 10, Capacity: 9, Blocked: 3 * time.Second, Starved: time.Second},
+		{From: StageUAST, To: StageLeaf, Items: 10, Capacity: 9, Starved: 2 * time.Second},
+	}))
+
+	assert.Contains(t, buf.String(), "diff->uast")
+	assert.Contains(t, buf.String(), "consider raising UASTPipelineWorkers")
+
+	buf.Reset()
+
+	require.NoError(t, WritePipelineDebug(&buf, nil))
+	assert.Contains(t, buf.String(), "No stage held")
+	assert.Nil(t, (*PipelineQueues)(nil).Stats())
+}
@@ -0,0 +1,322 @@
+package framework
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+)
+
+// pprof label keys set on pipeline goroutines when profile labels are enabled.
+const (
+	ProfileLabelStage    = "codefang.stage"
+	ProfileLabelAnalyzer = "codefang.analyzer"
+)
+
+// Values of ProfileLabelStage.
+const (
+	ProfileStageGit      = "git" // libgit2 workers serving blob loads and native diffs.
+	ProfileStageBlob     = "blob"
+	ProfileStageDiff     = "diff"
+	ProfileStageUAST     = "uast"
+	ProfileStageAnalyzer = "analyzer"
+)
+
+// profileLabels enables pprof labels on pipeline stages and analyzer Consume
+// calls. Off by default: labeling every Consume call costs an allocation.
+var profileLabels atomic.Bool
+
+// EnableProfileLabels turns pprof labels on pipeline stages and analyzers on
+// or off. Labels can be filtered with `go tool pprof -tagfocus` or split into
+// separate profiles with SplitCPUProfile.
+func EnableProfileLabels(enabled bool) {
+	profileLabels.Store(enabled)
+}
+
+// doStage calls fn with the stage label set on the current goroutine, so the
+// goroutines fn starts are attributed to stage. Calls fn directly when labels
+// are disabled.
+func doStage(ctx context.Context, stage string, fn func(context.Context)) {
+	if !profileLabels.Load() {
+		fn(ctx)
+
+		return
+	}
+
+	pprof.Do(ctx, pprof.Labels(ProfileLabelStage, stage), fn)
+}
+
+// consumeLabeled calls consume, labeled with the name of a when labels are
+// enabled.
+func consumeLabeled(ctx context.Context, a analyze.HistoryAnalyzer, consume func(context.Context)) {
+	if !profileLabels.Load() {
+		consume(ctx)
+
+		return
+	}
+
+	pprof.Do(ctx, pprof.Labels(ProfileLabelStage, ProfileStageAnalyzer, ProfileLabelAnalyzer, a.Name()), consume)
+}
+
+// Field numbers of the pprof profile.proto messages used by SplitCPUProfile.
+const (
+	profileFieldSample      = 2
+	profileFieldStringTable = 6
+	sampleFieldLabel        = 3
+	labelFieldKey           = 1
+	labelFieldStr           = 2
+)
+
+// ErrMalformedProfile is returned by SplitCPUProfile for unreadable profiles.
+var ErrMalformedProfile = errors.New("malformed profile")
+
+// profileField is one raw top-level field of an encoded profile.
+type profileField struct {
+	num protowire.Number
+	raw []byte // tag and value.
+}
+
+// SplitCPUProfile writes one profile per value of each label key next to the
+// profile at path, holding only the samples carrying that value. For
+// "cpu.prof", the samples labeled codefang.stage=diff go to
+// "cpu.stage-diff.prof". Unlabeled samples are left out. Returns the paths
+// written, sorted.
+func SplitCPUProfile(path string, keys ...string) ([]string, error) {
+	fields, strs, err := readProfileFields(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+
+	for _, key := range keys {
+		groups := make(map[string][]int)
+
+		for i, field := range fields {
+			if field.num != profileFieldSample {
+				continue
+			}
+
+			value, ok, labelErr := sampleLabel(field.raw, strs, key)
+			if labelErr != nil {
+				return nil, labelErr
+			}
+
+			if ok {
+				groups[value] = append(groups[value], i)
+			}
+		}
+
+		for value, samples := range groups {
+			out := splitProfilePath(path, key, value)
+
+			writeErr := writeProfileFields(out, fields, samples)
+			if writeErr != nil {
+				return nil, writeErr
+			}
+
+			written = append(written, out)
+		}
+	}
+
+	sort.Strings(written)
+
+	return written, nil
+}
+
+// readProfileFields decodes the top-level fields and the string table of a
+// gzipped or plain encoded profile.
+func readProfileFields(path string) ([]profileField, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read profile: %w", err)
+	}
+
+	if len(data) > 1 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, zErr := gzip.NewReader(bytes.NewReader(data))
+		if zErr != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrMalformedProfile, zErr)
+		}
+
+		data, err = io.ReadAll(zr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrMalformedProfile, err)
+		}
+	}
+
+	var (
+		fields []profileField
+		strs   []string
+	)
+
+	for len(data) > 0 {
+		num, typ, tagLen := protowire.ConsumeTag(data)
+		if tagLen < 0 {
+			return nil, nil, fmt.Errorf("%w: %w", ErrMalformedProfile, protowire.ParseError(tagLen))
+		}
+
+		valLen := protowire.ConsumeFieldValue(num, typ, data[tagLen:])
+		if valLen < 0 {
+			return nil, nil, fmt.Errorf("%w: %w", ErrMalformedProfile, protowire.ParseError(valLen))
+		}
+
+		raw := data[:tagLen+valLen]
+
+		if num == profileFieldStringTable && typ == protowire.BytesType {
+			s, _ := protowire.ConsumeString(data[tagLen:])
+			strs = append(strs, s)
+		}
+
+		fields = append(fields, profileField{num: num, raw: raw})
+		data = data[tagLen+valLen:]
+	}
+
+	return fields, strs, nil
+}
+
+// sampleLabel returns the string value of label key on an encoded sample field.
+func sampleLabel(raw []byte, strs []string, key string) (string, bool, error) {
+	_, _, tagLen := protowire.ConsumeTag(raw)
+
+	sample, n := protowire.ConsumeBytes(raw[tagLen:])
+	if n < 0 {
+		return "", false, fmt.Errorf("%w: %w", ErrMalformedProfile, protowire.ParseError(n))
+	}
+
+	for len(sample) > 0 {
+		num, typ, tagN := protowire.ConsumeTag(sample)
+		if tagN < 0 {
+			return "", false, fmt.Errorf("%w: %w", ErrMalformedProfile, protowire.ParseError(tagN))
+		}
+
+		valN := protowire.ConsumeFieldValue(num, typ, sample[tagN:])
+		if valN < 0 {
+			return "", false, fmt.Errorf("%w: %w", ErrMalformedProfile, protowire.ParseError(valN))
+		}
+
+		if num == sampleFieldLabel && typ == protowire.BytesType {
+			label, _ := protowire.ConsumeBytes(sample[tagN:])
+
+			k, v := labelStrings(label, strs)
+			if k == key && v != "" {
+				return v, true, nil
+			}
+		}
+
+		sample = sample[tagN+valN:]
+	}
+
+	return "", false, nil
+}
+
+// labelStrings resolves the key and string value of an encoded Label.
+func labelStrings(label []byte, strs []string) (key, value string) {
+	for len(label) > 0 {
+		num, typ, tagN := protowire.ConsumeTag(label)
+		if tagN < 0 {
+			return "", ""
+		}
+
+		if typ != protowire.VarintType {
+			valN := protowire.ConsumeFieldValue(num, typ, label[tagN:])
+			if valN < 0 {
+				return "", ""
+			}
+
+			label = label[tagN+valN:]
+
+			continue
+		}
+
+		v, valN := protowire.ConsumeVarint(label[tagN:])
+		if valN < 0 {
+			return "", ""
+		}
+
+		if v < uint64(len(strs)) {
+			switch num {
+			case labelFieldKey:
+				key = strs[v]
+			case labelFieldStr:
+				value = strs[v]
+			}
+		}
+
+		label = label[tagN+valN:]
+	}
+
+	return key, value
+}
+
+// writeProfileFields writes a gzipped profile with every non-sample field of
+// fields and only the samples at the given indices.
+func writeProfileFields(path string, fields []profileField, samples []int) error {
+	keep := make(map[int]bool, len(samples))
+	for _, i := range samples {
+		keep[i] = true
+	}
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+
+	for i, field := range fields {
+		if field.num == profileFieldSample && !keep[i] {
+			continue
+		}
+
+		_, err := zw.Write(field.raw)
+		if err != nil {
+			return fmt.Errorf("encode profile %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	err := zw.Close()
+	if err != nil {
+		return fmt.Errorf("encode profile %s: %w", filepath.Base(path), err)
+	}
+
+	err = os.WriteFile(path, buf.Bytes(), 0o600)
+	if err != nil {
+		return fmt.Errorf("write profile %s: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+// splitProfilePath returns the path of the split profile for key=value.
+func splitProfilePath(path, key, value string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	name := strings.TrimPrefix(key, "codefang.") + "-" + sanitizeProfileName(value)
+
+	if ext == "" {
+		return base + "." + name
+	}
+
+	return base + "." + name + ext
+}
+
+// sanitizeProfileName replaces characters that are unsafe in file names.
+func sanitizeProfileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
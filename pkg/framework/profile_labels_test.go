@@ -0,0 +1,102 @@
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// encodeLabeledSample encodes a Sample with one location and a string label.
+func encodeLabeledSample(location, key, value uint64) []byte {
+	var label []byte
+	label = protowire.AppendTag(label, labelFieldKey, protowire.VarintType)
+	label = protowire.AppendVarint(label, key)
+	label = protowire.AppendTag(label, labelFieldStr, protowire.VarintType)
+	label = protowire.AppendVarint(label, value)
+
+	var sample []byte
+	sample = protowire.AppendTag(sample, 1, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, location)
+
+	if key != 0 {
+		sample = protowire.AppendTag(sample, sampleFieldLabel, protowire.BytesType)
+		sample = protowire.AppendBytes(sample, label)
+	}
+
+	return sample
+}
+
+func TestSplitCPUProfile(t *testing.T) {
+	t.Parallel()
+
+	var profile []byte
+
+	// String table first entry must be empty.
+	for _, s := range []string{"", ProfileLabelStage, ProfileStageDiff, ProfileStageUAST} {
+		profile = protowire.AppendTag(profile, profileFieldStringTable, protowire.BytesType)
+		profile = protowire.AppendString(profile, s)
+	}
+
+	for _, sample := range [][]byte{
+		encodeLabeledSample(1, 1, 2),
+		encodeLabeledSample(2, 1, 3),
+		encodeLabeledSample(3, 1, 2),
+		encodeLabeledSample(4, 0, 0), // unlabeled.
+	} {
+		profile = protowire.AppendTag(profile, profileFieldSample, protowire.BytesType)
+		profile = protowire.AppendBytes(profile, sample)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cpu.prof")
+	require.NoError(t, os.WriteFile(path, profile, 0o600))
+
+	written, err := SplitCPUProfile(path, ProfileLabelStage)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "cpu.stage-diff.prof"),
+		filepath.Join(dir, "cpu.stage-uast.prof"),
+	}, written)
+
+	fields, strs, err := readProfileFields(written[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", ProfileLabelStage, ProfileStageDiff, ProfileStageUAST}, strs)
+
+	var samples int
+
+	for _, field := range fields {
+		if field.num != profileFieldSample {
+			continue
+		}
+
+		samples++
+
+		value, ok, labelErr := sampleLabel(field.raw, strs, ProfileLabelStage)
+		require.NoError(t, labelErr)
+		assert.True(t, ok)
+		assert.Equal(t, ProfileStageDiff, value)
+	}
+
+	assert.Equal(t, 2, samples)
+}
+
+func TestSplitCPUProfile_Malformed(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cpu.prof")
+	require.NoError(t, os.WriteFile(path, []byte{0xff}, 0o600))
+
+	_, err := SplitCPUProfile(path, ProfileLabelStage)
+	require.ErrorIs(t, err, ErrMalformedProfile)
+}
+
+func TestSplitProfilePath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "out/cpu.analyzer-Burndown_v2.prof", splitProfilePath("out/cpu.prof", ProfileLabelAnalyzer, "Burndown/v2"))
+	assert.Equal(t, "cpu.stage-blob", splitProfilePath("cpu", ProfileLabelStage, ProfileStageBlob))
+}
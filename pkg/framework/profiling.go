@@ -1,11 +1,19 @@
 package framework
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
+	httppprof "net/http/pprof"
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"sync"
+	"syscall"
+	"time"
 )
 
 // MaybeStartCPUProfile starts CPU profiling to the given file.
@@ -62,3 +70,114 @@ func MaybeWriteHeapProfile(path string, logger *slog.Logger) {
 		logger.Error("could not write heap profile", "path", path, "error", writeErr)
 	}
 }
+
+// PprofAddrAuto asks MaybeStartPprofServer to bind an ephemeral loopback port.
+const PprofAddrAuto = "auto"
+
+// pprofAutoAddr is the listen address used for PprofAddrAuto.
+const pprofAutoAddr = "127.0.0.1:0"
+
+// pprofShutdownTimeout bounds how long the pprof server may take to drain on stop.
+const pprofShutdownTimeout = 2 * time.Second
+
+// pprofReadHeaderTimeout guards the debug server against slow-header clients.
+const pprofReadHeaderTimeout = 5 * time.Second
+
+// MaybeStartPprofServer serves net/http/pprof handlers on addr until ctx is
+// cancelled or the returned stop function is called. Returns a no-op and an
+// empty address if addr is empty. PprofAddrAuto binds a free loopback port;
+// if a fixed addr is already in use, the server falls back to a free port on
+// the same host instead of failing the run. The bound address is returned so
+// callers can report where profiles are served. When control is non-nil, its
+// endpoints are served under /control/ on the same server.
+func MaybeStartPprofServer(
+	ctx context.Context, logger *slog.Logger, addr string, control *Control,
+) (func(), string, error) {
+	if addr == "" {
+		return func() {}, "", nil
+	}
+
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	listener, err := listenPprof(addr, logger)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", httppprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+
+	if control != nil {
+		mux.Handle("/control/", control.Handler())
+	}
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: pprofReadHeaderTimeout}
+	boundAddr := listener.Addr().String()
+
+	go func() {
+		serveErr := server.Serve(listener)
+		if serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			logger.Warn("pprof server stopped", "addr", boundAddr, "error", serveErr)
+		}
+	}()
+
+	logger.Info("pprof server listening", "addr", boundAddr)
+
+	var once sync.Once
+
+	stop := func() {
+		once.Do(func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), pprofShutdownTimeout)
+			defer cancel()
+
+			_ = server.Shutdown(shutdownCtx)
+		})
+	}
+
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return stop, boundAddr, nil
+}
+
+// listenPprof binds addr, falling back to an ephemeral port on the same host
+// when the requested port is already taken.
+func listenPprof(addr string, logger *slog.Logger) (net.Listener, error) {
+	if addr == PprofAddrAuto {
+		addr = pprofAutoAddr
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pprof address %q: %w", addr, err)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err == nil {
+		return listener, nil
+	}
+
+	if !errors.Is(err, syscall.EADDRINUSE) {
+		return nil, fmt.Errorf("listen pprof %s: %w", addr, err)
+	}
+
+	fallback := net.JoinHostPort(host, "0")
+
+	listener, fallbackErr := net.Listen("tcp", fallback)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("listen pprof %s: %w", fallback, fallbackErr)
+	}
+
+	logger.Warn("pprof address in use, picked a free port",
+		"requested", addr, "addr", listener.Addr().String())
+
+	return listener, nil
+}
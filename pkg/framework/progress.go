@@ -0,0 +1,29 @@
+package framework
+
+import (
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
+)
+
+// Progress describes how far a streaming run has got.
+type Progress struct {
+	// Chunk is the zero-based index of the chunk just finished.
+	Chunk int
+	// Commits is the number of commits analyzed so far, including commits of
+	// chunks restored from a checkpoint.
+	Commits int
+	// TotalCommits is the number of commits of the run.
+	TotalCommits int
+}
+
+// ProgressFunc receives Progress after every chunk. It is called on the
+// streaming goroutine, so it should return quickly.
+type ProgressFunc func(Progress)
+
+// reportProgress calls OnProgress for a finished chunk.
+func (runner *Runner) reportProgress(idx int, chunk streaming.ChunkBounds) {
+	if runner.OnProgress == nil {
+		return
+	}
+
+	runner.OnProgress(Progress{Chunk: idx, Commits: chunk.End, TotalCommits: runner.progressTotal})
+}
@@ -0,0 +1,79 @@
+package framework
+
+import (
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// observeRedaction records the changed paths and the message of a commit
+// with the Redactor before analyzers see them. No-op without a Redactor.
+func (runner *Runner) observeRedaction(commit *gitlib.Commit, changes gitlib.Changes) {
+	if runner.Redactor == nil {
+		return
+	}
+
+	paths := make([]string, 0, 2*len(changes))
+
+	for _, change := range changes {
+		if change == nil {
+			continue
+		}
+
+		paths = append(paths, change.From.Name, change.To.Name)
+	}
+
+	runner.Redactor.ObservePaths(paths...)
+
+	if commit != nil {
+		runner.Redactor.ObserveMessage(commit.Message())
+	}
+}
+
+// redactTC returns the TC handed to TCSink and TCObserver: a redacted copy
+// when a Redactor is set, so that aggregators keep the original data. A TC
+// that cannot be copied loses its data rather than leak it.
+func (runner *Runner) redactTC(tc analyze.TC) analyze.TC {
+	if runner.Redactor == nil || runner.TCSink == nil && runner.TCObserver == nil {
+		return tc
+	}
+
+	data, err := runner.Redactor.Copy(tc.Data)
+	if err != nil {
+		if runner.Logger != nil {
+			runner.Logger.Warn("redact: record dropped", "commit", tc.CommitHash.String(), "error", err)
+		}
+
+		data = nil
+	}
+
+	tc.Data = data
+
+	return tc
+}
+
+// redactReports pseudonymizes the finished reports in place: the people
+// dictionary, the trailers and notes of the commit table, and every report
+// value equal to an observed identity, path or message.
+func (runner *Runner) redactReports(reports map[analyze.HistoryAnalyzer]analyze.Report) {
+	if runner.Redactor == nil {
+		return
+	}
+
+	if runner.idProvider != nil {
+		runner.Redactor.ObserveAuthors(runner.idProvider.ReversedPeopleDict)
+	}
+
+	for i := range runner.commitRows {
+		row := &runner.commitRows[i]
+
+		for j := range row.Trailers {
+			row.Trailers[j].Value = runner.Redactor.Trailer(row.Trailers[j].Key, row.Trailers[j].Value)
+		}
+
+		row.Note = runner.Redactor.Excerpt(row.Note)
+	}
+
+	for _, report := range reports {
+		runner.Redactor.Value(report)
+	}
+}
@@ -0,0 +1,138 @@
+package framework_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/redact"
+)
+
+// pathLeaf emits the changed paths of every commit and reports them all.
+type pathLeaf struct {
+	stubLeaf
+
+	agg *pathAggregator
+}
+
+type pathRecord struct {
+	Files []string `json:"files"`
+}
+
+func (l *pathLeaf) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	var rec pathRecord
+
+	for _, change := range ac.Changes {
+		rec.Files = append(rec.Files, change.To.Name)
+	}
+
+	return analyze.TC{Data: rec}, nil
+}
+
+func (l *pathLeaf) NewAggregator(_ analyze.AggregatorOptions) analyze.Aggregator { return l.agg }
+
+func (l *pathLeaf) ReportFromTICKs(_ context.Context, _ []analyze.TICK) (analyze.Report, error) {
+	return analyze.Report{"files": l.agg.files, "subject": "Add billing entry point"}, nil
+}
+
+type pathAggregator struct {
+	stubAggregator
+
+	files []string
+}
+
+func (a *pathAggregator) Add(tc analyze.TC) error {
+	rec, ok := tc.Data.(pathRecord)
+	if ok {
+		a.files = append(a.files, rec.Files...)
+	}
+
+	return nil
+}
+
+func newRedactRunner(t *testing.T, leaf analyze.HistoryAnalyzer) (*framework.Runner, []*gitlib.Commit) {
+	t.Helper()
+
+	repo := framework.NewTestRepo(t)
+	t.Cleanup(repo.Close)
+
+	repo.CreateFile("billing/main.go", "package main\n")
+	repo.Commit("Add billing entry point")
+	repo.CreateFile("billing/invoice.go", "package main\n")
+	repo.Commit("Add invoice rounding")
+
+	libRepo, err := gitlib.OpenRepository(repo.Path())
+	require.NoError(t, err)
+	t.Cleanup(libRepo.Free)
+
+	commits := framework.CollectCommits(t, libRepo, 0)
+	slices.Reverse(commits)
+
+	treeDiff := &plumbing.TreeDiffAnalyzer{Repository: libRepo}
+
+	r := framework.NewRunner(libRepo, repo.Path(), treeDiff, leaf)
+	r.CoreCount = 1
+	r.Redactor = redact.New(redact.Paths|redact.Messages, []byte("key"))
+
+	return r, commits
+}
+
+func TestRunner_RedactsReports(t *testing.T) {
+	t.Parallel()
+
+	leaf := &pathLeaf{stubLeaf: stubLeaf{name: "paths"}, agg: &pathAggregator{}}
+	r, commits := newRedactRunner(t, leaf)
+
+	reports, err := r.Run(context.Background(), commits)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{r.Redactor.Path("billing/main.go"), r.Redactor.Path("billing/invoice.go")},
+		reports[leaf]["files"])
+	assert.Equal(t, r.Redactor.String("Add billing entry point"), reports[leaf]["subject"])
+	assert.NotEqual(t, "Add billing entry point", reports[leaf]["subject"])
+}
+
+func TestRunner_RedactsSinkRecords(t *testing.T) {
+	t.Parallel()
+
+	leaf := &pathLeaf{stubLeaf: stubLeaf{name: "paths"}, agg: &pathAggregator{}}
+	r, commits := newRedactRunner(t, leaf)
+
+	var (
+		mu    sync.Mutex
+		lines []string
+	)
+
+	r.TCSink = func(tc analyze.TC, _ string) error {
+		data, err := json.Marshal(tc.Data)
+		require.NoError(t, err)
+
+		mu.Lock()
+		lines = append(lines, string(data))
+		mu.Unlock()
+
+		return nil
+	}
+
+	require.NoError(t, r.Initialize())
+
+	_, err := r.ProcessChunk(context.Background(), commits, 0, 0)
+	require.NoError(t, err)
+
+	require.Len(t, lines, 2)
+
+	for _, line := range lines {
+		assert.NotContains(t, line, "billing")
+	}
+
+	assert.Contains(t, lines[1], r.Redactor.Path("billing/invoice.go"))
+}
@@ -5,7 +5,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -15,8 +18,11 @@ import (
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
 	"github.com/Sumatoshi-tech/codefang/pkg/checkpoint"
+	"github.com/Sumatoshi-tech/codefang/pkg/codec"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/observability"
+	"github.com/Sumatoshi-tech/codefang/pkg/redact"
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
 )
 
 // ErrNotParallelizable is returned when a leaf analyzer does not implement [analyze.Parallelizable].
@@ -34,6 +40,10 @@ type Runner struct {
 	// When nil, falls back to otel.Tracer("codefang").
 	Tracer trace.Tracer
 
+	// AnalysisMetrics, when set, receives the cache counters of every chunk,
+	// recorded under the chunk span so their exemplars link to its trace.
+	AnalysisMetrics *observability.AnalysisMetrics
+
 	// CoreCount is the number of leading analyzers in the Analyzers slice that are
 	// core (plumbing) analyzers. These run sequentially. Analyzers after CoreCount
 	// are leaf analyzers that can be parallelized via Fork/Merge.
@@ -53,6 +63,13 @@ type Runner struct {
 	tickProvider *plumbing.TicksSinceStart
 	idProvider   *plumbing.IdentityDetector
 
+	// lineStatsProvider, langProvider, msgLangProvider and trailersProvider
+	// are discovered the same way and feed the commit table.
+	lineStatsProvider *plumbing.LinesStatsCalculator
+	langProvider      *plumbing.LanguagesDetectionAnalyzer
+	msgLangProvider   *plumbing.MessageLanguageDetector
+	trailersProvider  *plumbing.TrailersExtractor
+
 	// commitMeta accumulates per-commit metadata (timestamp, author) during TC consumption.
 	// Injected into Reports by FinalizeWithAggregators for timeseries output.
 	commitMeta map[string]analyze.CommitMeta
@@ -62,15 +79,142 @@ type Runner struct {
 	// and FinalizeWithAggregators is not called.
 	TCSink analyze.TCSink
 
+	// SinkOnWritten, when set, registers the callback through which an
+	// asynchronous TCSink (see analyze.BufferedSink.OnWritten) confirms the
+	// records it actually wrote. The sink journal then records a TC on
+	// confirmation rather than when TCSink returns.
+	SinkOnWritten func(analyze.TCSink)
+
+	// SinkFlush, when set, waits until an asynchronous TCSink has written
+	// every queued record (see analyze.BufferedSink.Flush). Checkpoints call
+	// it so the sink journal they save covers all records sent before them.
+	SinkFlush func()
+
+	// sinkJournal, when set, journals TCSink output for checkpoint resume.
+	// Nil-safe: without checkpointing no journal is kept.
+	sinkJournal *checkpoint.SinkJournal
+
+	// sinkSeq is the last sequence number stamped on a TC sent to TCSink.
+	// Analyzers are routed concurrently, so records of different analyzers
+	// may reach the sink slightly out of sequence order.
+	sinkSeq atomic.Uint64
+
+	// TCObserver, when set, receives every non-nil TC after stamping, in
+	// addition to its aggregator or TCSink. TCs of different analyzers may be
+	// observed concurrently. Errors are ignored.
+	TCObserver analyze.TCSink
+
+	// Redactor, when set, learns the paths and messages of consumed commits
+	// and pseudonymizes them, along with author identities, in the final
+	// reports and in the TCs handed to TCSink and TCObserver.
+	Redactor *redact.Redactor
+
+	// observeAuthors makes the Redactor learn the people dictionary before
+	// AuthorName first pseudonymizes a name.
+	observeAuthors sync.Once
+
+	// OnProgress, when set, is called after every streaming chunk.
+	OnProgress ProgressFunc
+
+	// progressTotal is the commit count reported in Progress.
+	progressTotal int
+
 	// AggSpillBudget is the maximum bytes of aggregator state to keep in memory
 	// before spilling to disk. Computed by ComputeSchedule from the memory budget.
 	// Zero means no limit (unlimited budget or budget too small to decompose).
 	AggSpillBudget int64
 
+	// SpillCodec compresses aggregator spill files. Nil means uncompressed.
+	SpillCodec codec.Codec
+
+	// SpillDir is the parent directory of aggregator spill directories.
+	// Empty means the system temp directory.
+	SpillDir string
+
+	// Control, when set, lets operators pause, checkpoint, and lower the
+	// memory budget of a streaming run between chunks.
+	Control *Control
+
+	// Nice runs the streaming loop as a background job: one chunk in flight,
+	// an idle pause after every chunk, and smaller caches under memory
+	// pressure. See EnterNiceMode for the process-wide part.
+	Nice bool
+
+	// diskMonitor enforces StreamingConfig.DiskBudget between chunks. Nil
+	// when no disk budget is set.
+	diskMonitor *streaming.DiskMonitor
+
+	// softLimitEvents delivers a streaming.SoftLimitWatcher's events, so
+	// aggregators spill in the middle of a chunk when Go memory approaches
+	// the soft memory limit. Nil outside streaming runs.
+	softLimitEvents <-chan streaming.SoftLimitEvent
+
+	// chunkBlobCache is the blob cache of the pipeline of the chunk in
+	// progress, shrunk on soft limit events. Nil between chunks.
+	chunkBlobCache *GlobalBlobCache
+
 	// tcBytesAccumulated tracks total TC payload bytes consumed since last reset.
 	// Used by three-metric adaptive feedback to measure TC size per commit.
 	tcBytesAccumulated int64
 
+	// OnCommitError selects how a failing commit is handled. Empty means abort.
+	OnCommitError CommitErrorPolicy
+
+	// CommitTimeouts limits the time a leaf analyzer may spend consuming one
+	// commit, ChunkTimeouts the time it may spend on the commits of a chunk;
+	// with parallel leaf workers, on the commits of the chunk given to one
+	// worker. A leaf over its limit is skipped for the commit, or for the
+	// rest of the chunk, and the incident is recorded in the run quality
+	// section whatever OnCommitError says.
+	CommitTimeouts AnalyzerTimeouts
+	ChunkTimeouts  AnalyzerTimeouts
+
+	// DumpDir receives the goroutine dumps written when a leaf analyzer times
+	// out. Empty means the system temp directory.
+	DumpDir string
+
+	// TimeoutGrace is how long a timed-out leaf may take to return once its
+	// context is cancelled before the run fails with ErrAnalyzerStuck. Zero
+	// means DefaultTimeoutGrace.
+	TimeoutGrace time.Duration
+
+	guardOnce sync.Once
+	guard     *timeoutGuard
+
+	// CommitLookahead prepares the next commit for leaves implementing
+	// analyze.CommitPreparer while the current commit is consumed, hiding part
+	// of the per-commit latency of sequential analyzers. Off by default.
+	CommitLookahead bool
+
+	// Logger receives per-commit failure records under skip/retry policies.
+	// When nil, failures are only recorded in the run quality stats.
+	Logger *slog.Logger
+
+	// DerivedMetrics run at the end of FinalizeWithAggregators, after every leaf
+	// report is built, to derive data that needs more than one analyzer.
+	// They must already be ordered (see analyze.OrderDerivedMetrics).
+	DerivedMetrics []analyze.DerivedMetric
+
+	// CommitTable records one analyze.CommitRow per consumed commit and injects
+	// the table into every report under analyze.ReportKeyCommitTable.
+	CommitTable bool
+
+	// SampleFactor is the ratio of walked to analyzed commits of a sampled
+	// history. Above 1, it is injected into every report under
+	// analyze.ReportKeySampleFactor, so analyzers scale their commit counts.
+	SampleFactor float64
+
+	// commitRows accumulates the commit table in consumption order.
+	commitRows []analyze.CommitRow
+
+	// quality accumulates skipped-commit failures for the run quality section.
+	// failedCommits de-duplicates commits that failed in more than one stage.
+	quality       analyze.QualityStats
+	failedCommits map[string]struct{}
+
+	// blobCacheStats accumulates the blob cache counters of every chunk.
+	blobCacheStats CacheStats
+
 	runtimeTuningOnce sync.Once
 	runtimeBallast    []byte
 }
@@ -99,6 +243,35 @@ func NewRunnerWithConfig(
 	}
 }
 
+// leafCapabilities returns the combined capabilities of the leaf analyzers
+// and of the core analyzers that declare theirs, such as custom plumbing.
+// With CoreCount unset every analyzer counts as a leaf; the built-in
+// plumbing analyzers declare no capabilities, so they then count as needing
+// everything. The commit table reads line stats, which need blobs and diffs.
+func (runner *Runner) leafCapabilities() analyze.Capabilities {
+	coreCount := min(runner.CoreCount, len(runner.Analyzers))
+	declared := slices.Clone(runner.Analyzers[coreCount:])
+
+	for _, a := range runner.Analyzers[:coreCount] {
+		if _, ok := a.(analyze.CapabilityProvider); ok {
+			declared = append(declared, a)
+		}
+	}
+
+	caps := analyze.CombinedCapabilities(declared)
+	if runner.CommitTable {
+		caps.NeedsBlobs = true
+		caps.NeedsDiffs = true
+	}
+
+	return caps
+}
+
+// applyCapabilities turns off the coordinator stages no leaf analyzer needs.
+func (runner *Runner) applyCapabilities() {
+	runner.Config = runner.Config.WithCapabilities(runner.leafCapabilities())
+}
+
 // tracer returns the configured tracer, falling back to the global provider.
 func (runner *Runner) tracer() trace.Tracer {
 	if runner.Tracer != nil {
@@ -155,6 +328,8 @@ func (runner *Runner) Initialize() error {
 // plumbing providers (tick + identity) from core analyzers.
 // Called once after all analyzers are initialized.
 func (runner *Runner) initAggregators() {
+	runner.applyCapabilities()
+
 	runner.aggregators = make([]analyze.Aggregator, len(runner.Analyzers))
 	runner.commitMeta = make(map[string]analyze.CommitMeta)
 
@@ -169,6 +344,22 @@ func (runner *Runner) initAggregators() {
 				runner.idProvider = id
 			}
 
+			runner.discoverCommitTableProviders(a)
+
+			// The runtime diff pipeline diffs the way FileDiff is configured.
+			if fd, ok := a.(*plumbing.FileDiffAnalyzer); ok {
+				runner.Config.DiffAlgorithm = fd.Algorithm
+				runner.Config.DiffTimeout = fd.Timeout
+				runner.Config.NormalizeEOL = fd.NormalizeEOL
+			}
+
+			// The runtime blob pipeline resolves LFS pointers and
+			// transcodes text the way BlobCache is configured.
+			if bc, ok := a.(*plumbing.BlobCacheAnalyzer); ok {
+				runner.Config.ResolveLFS = bc.ResolveLFS
+				runner.Config.TextEncoding = bc.TextEncoding
+			}
+
 			continue
 		}
 
@@ -180,6 +371,8 @@ func (runner *Runner) initAggregators() {
 
 		agg := a.NewAggregator(analyze.AggregatorOptions{
 			SpillBudget: runner.AggSpillBudget,
+			SpillDir:    runner.SpillDir,
+			SpillCodec:  runner.SpillCodec,
 		})
 		runner.aggregators[i] = agg // nil for analyzers without aggregators.
 	}
@@ -212,6 +405,7 @@ func (runner *Runner) InitializeForResume(aggSpills []checkpoint.AggregatorSpill
 		agg.RestoreSpillState(analyze.AggregatorSpillInfo{
 			Dir:   entry.Dir,
 			Count: entry.Count,
+			Codec: entry.Codec,
 		})
 	}
 
@@ -232,12 +426,121 @@ func (runner *Runner) AggregatorSpills() []checkpoint.AggregatorSpillEntry {
 		spills[i] = checkpoint.AggregatorSpillEntry{
 			Dir:   info.Dir,
 			Count: info.Count,
+			Codec: info.Codec,
 		}
 	}
 
 	return spills
 }
 
+// SpillUsage returns the spill directories and bytes written of every leaf
+// analyzer that has spilled, combining its aggregator spills with the spills
+// of analyzers implementing streaming.SpillReporter.
+func (runner *Runner) SpillUsage() []streaming.DiskUsage {
+	var usage []streaming.DiskUsage
+
+	for i, a := range runner.Analyzers {
+		if i < runner.CoreCount {
+			continue
+		}
+
+		u := streaming.DiskUsage{Owner: a.Name()}
+
+		if i < len(runner.aggregators) && runner.aggregators[i] != nil {
+			info := runner.aggregators[i].SpillState()
+			u.Written += info.Written
+
+			if info.Dir != "" {
+				u.Dirs = append(u.Dirs, info.Dir)
+			}
+		}
+
+		if sr, ok := a.(streaming.SpillReporter); ok {
+			u.Dirs = append(u.Dirs, sr.SpillDirs()...)
+			u.Written += sr.SpillBytesWritten()
+		}
+
+		if len(u.Dirs) > 0 || u.Written > 0 {
+			usage = append(usage, u)
+		}
+	}
+
+	return usage
+}
+
+// lowerMemBudget applies a memory budget lowered during the run: the Go soft
+// memory limit follows it, the per-aggregator spill budget shrinks in
+// proportion, and aggregators above their new share spill at once.
+func (runner *Runner) lowerMemBudget(ctx context.Context, logger *slog.Logger, budget int64) {
+	if runner.MemBudget > 0 && runner.AggSpillBudget > 0 {
+		ratio := float64(budget) / float64(runner.MemBudget)
+		runner.AggSpillBudget = max(int64(float64(runner.AggSpillBudget)*ratio), 1)
+	}
+
+	runner.MemBudget = budget
+	applyMemoryLimitFromBudget(budget)
+
+	if runner.AggSpillBudget <= 0 {
+		return
+	}
+
+	for i, agg := range runner.aggregators {
+		if agg == nil || agg.EstimatedStateSize() <= runner.AggSpillBudget {
+			continue
+		}
+
+		_, err := agg.Spill()
+		if err != nil {
+			logger.WarnContext(ctx, "control: spill after budget change failed",
+				"analyzer", runner.Analyzers[i].Name(), "error", err)
+		}
+	}
+}
+
+// relieveSoftLimit handles a pending soft memory limit event between
+// commits: every aggregator holding state spills it, and the blob cache of the
+// running pipeline is halved. This frees memory while the chunk is still in
+// progress, before the GC has to run back to back to hold the limit.
+func (runner *Runner) relieveSoftLimit(ctx context.Context) {
+	var event streaming.SoftLimitEvent
+
+	select {
+	case event = <-runner.softLimitEvents:
+	default:
+		return
+	}
+
+	var spilled int
+
+	for i, agg := range runner.aggregators {
+		if agg == nil || agg.EstimatedStateSize() == 0 {
+			continue
+		}
+
+		_, err := agg.Spill()
+		if err != nil {
+			if runner.Logger != nil {
+				runner.Logger.WarnContext(ctx, "soft limit: spill failed",
+					"analyzer", runner.Analyzers[i].Name(), "error", err)
+			}
+
+			continue
+		}
+
+		spilled++
+	}
+
+	if runner.chunkBlobCache != nil {
+		runner.chunkBlobCache.Shrink(runner.chunkBlobCache.Stats().MaxSize / cacheShedDivisor)
+	}
+
+	if runner.Logger != nil {
+		runner.Logger.InfoContext(ctx, "soft limit: spilled aggregators early",
+			"used_mib", event.Used/streaming.MiB, "limit_mib", event.Limit/streaming.MiB,
+			"aggregators", spilled)
+	}
+}
+
 // SpillAggregators forces all aggregators to flush their in-memory state
 // to disk. Called before saving a checkpoint so that spill files are complete.
 func (runner *Runner) SpillAggregators() error {
@@ -274,8 +577,11 @@ func (runner *Runner) addTC(tc analyze.TC, idx int, ac *analyze.Context) {
 	tc.Timestamp = ac.Time
 	runner.recordCommitMeta(tc)
 
+	out := runner.redactTC(tc)
+	runner.observeTC(out, idx)
+
 	if runner.TCSink != nil {
-		runner.sendToSink(tc, idx)
+		runner.sendToSink(out, idx)
 
 		return
 	}
@@ -356,6 +662,21 @@ func (runner *Runner) authorName(authorID int) string {
 	return dict[authorID]
 }
 
+// AuthorName returns the name of an author identity as the reports show it,
+// pseudonymized when the Redactor redacts authors.
+func (runner *Runner) AuthorName(authorID int) string {
+	name := runner.authorName(authorID)
+	if name == "" || runner.Redactor == nil {
+		return name
+	}
+
+	if runner.idProvider != nil {
+		runner.observeAuthors.Do(func() { runner.Redactor.ObserveAuthors(runner.idProvider.ReversedPeopleDict) })
+	}
+
+	return runner.Redactor.Author(name)
+}
+
 // analyzerIndex builds a reverse map from analyzer to its index in runner.Analyzers.
 func (runner *Runner) analyzerIndex() map[analyze.HistoryAnalyzer]int {
 	m := make(map[analyze.HistoryAnalyzer]int, len(runner.Analyzers))
@@ -398,19 +719,55 @@ func (runner *Runner) drainWorkerTCs(workers []*leafWorker) {
 	wg.Wait()
 }
 
-// sendToSink dispatches a TC to the TCSink callback. Errors are silently
-// discarded — sink failures (e.g. broken pipe) should not halt the pipeline.
+// sendToSink stamps a TC with the next sequence number and dispatches it to
+// the TCSink callback. Errors are silently discarded — sink failures (e.g.
+// broken pipe) should not halt the pipeline. After a resume, TCs the
+// interrupted run already wrote are not sent again.
 func (runner *Runner) sendToSink(tc analyze.TC, idx int) {
-	sinkErr := runner.TCSink(tc, runner.Analyzers[idx].Flag())
-	if sinkErr != nil {
+	flag := runner.Analyzers[idx].Flag()
+
+	if runner.sinkJournal.Skip(flag, tc.CommitHash.String()) {
+		return
+	}
+
+	tc.Seq = runner.sinkSeq.Add(1)
+
+	sinkErr := runner.TCSink(tc, flag)
+	if sinkErr != nil || runner.SinkOnWritten != nil {
 		return
 	}
+
+	_ = runner.journalSinkRecord(tc, flag)
+}
+
+// journalSinkRecord records a TC written by the TCSink in the sink journal.
+// It is called by sendToSink, or by the sink itself through SinkOnWritten
+// once a buffered record has been flushed.
+func (runner *Runner) journalSinkRecord(tc analyze.TC, flag string) error {
+	journalErr := runner.sinkJournal.Record(flag, tc.CommitHash.String(), tc.Seq)
+	if journalErr != nil && runner.Logger != nil {
+		runner.Logger.Warn("sink journal write failed", "error", journalErr)
+	}
+
+	return nil
+}
+
+// observeTC passes a stamped TC to the TCObserver, if any.
+func (runner *Runner) observeTC(tc analyze.TC, idx int) {
+	if runner.TCObserver == nil {
+		return
+	}
+
+	_ = runner.TCObserver(tc, runner.Analyzers[idx].Flag())
 }
 
 // routeBufferedTC sends a single buffered TC to the TCSink or its aggregator.
 func (runner *Runner) routeBufferedTC(btc bufferedTC) {
+	out := runner.redactTC(btc.tc)
+	runner.observeTC(out, btc.idx)
+
 	if runner.TCSink != nil {
-		runner.sendToSink(btc.tc, btc.idx)
+		runner.sendToSink(out, btc.idx)
 
 		return
 	}
@@ -436,22 +793,82 @@ func mapIndices(leaves []analyze.HistoryAnalyzer, idxMap map[analyze.HistoryAnal
 	return indices
 }
 
-// consumeAll feeds one commit through all analyzers, accumulating per-analyzer durations.
-func (runner *Runner) consumeAll(ctx context.Context, ac *analyze.Context, durations []time.Duration) error {
+// consumeAll feeds one commit through all analyzers, accumulating per-analyzer usage.
+// Unless the policy is abort, a failing leaf is recorded and skipped for this commit
+// only; a failing core analyzer returns its name and error so the caller can skip
+// the whole commit.
+func (runner *Runner) consumeAll(ctx context.Context, ac *analyze.Context, usage []analyzerUsage) (string, error) {
+	guard := runner.timeoutGuard()
+
 	for i, a := range runner.Analyzers {
 		start := time.Now()
 
-		tc, err := a.Consume(ctx, ac)
+		var (
+			tc  analyze.TC
+			err error
+		)
 
-		durations[i] += time.Since(start)
+		if i < runner.CoreCount {
+			tc, err = consumeSafely(ctx, a, ac)
+		} else {
+			tc, err = guard.consume(ctx, a, ac, usage[i].duration)
+		}
+
+		usage[i].add(start)
 
 		if err != nil {
-			return err
+			if i < runner.CoreCount || leafErrorAborts(err, runner.abortOnCommitError()) {
+				return a.Name(), err
+			}
+
+			runner.recordCommitFailure(ctx, commitHashString(ac.Commit), ac.Index, a.Name(), 1, err)
+
+			continue
 		}
 
 		runner.addTC(tc, i, ac)
 	}
 
+	return "", nil
+}
+
+// consumeCommitData retries and then consumes one commit's pipeline data.
+// Returns a non-nil error only when the run must abort.
+func (runner *Runner) consumeCommitData(
+	ctx context.Context, span trace.Span, data CommitData, indexOffset int, usage []analyzerUsage,
+) error {
+	runner.relieveSoftLimit(ctx)
+
+	data, attempts := runner.retryCommitData(ctx, data)
+	if data.Error != nil {
+		if runner.abortOnCommitError() {
+			observability.RecordSpanError(span, data.Error, observability.ErrTypeDependencyUnavailable, observability.ErrSourceDependency)
+
+			return data.Error
+		}
+
+		runner.recordCommitFailure(ctx, commitHashString(data.Commit), data.Index+indexOffset, stagePipeline, attempts, data.Error)
+
+		return nil
+	}
+
+	analyzeCtx := runner.buildAnalyzeContext(data, indexOffset)
+
+	stage, consumeErr := runner.consumeAll(ctx, analyzeCtx, usage)
+	if consumeErr != nil {
+		if runner.abortOnCommitError() {
+			observability.RecordSpanError(span, consumeErr, observability.ErrTypeInternal, observability.ErrSourceServer)
+
+			return consumeErr
+		}
+
+		runner.recordCommitFailure(ctx, commitHashString(analyzeCtx.Commit), analyzeCtx.Index, stage, 1, consumeErr)
+
+		return nil
+	}
+
+	runner.recordCommitRow(analyzeCtx)
+
 	return nil
 }
 
@@ -495,15 +912,12 @@ func (runner *Runner) ProcessChunk(ctx context.Context, commits []*gitlib.Commit
 }
 
 // reportFromAggregator collects, flushes, and converts aggregated TICKs to a report.
+// Aggregators implementing [analyze.TickStreamer] merge their spills tick by
+// tick instead of reloading all spilled state first.
 func reportFromAggregator(ctx context.Context, agg analyze.Aggregator, a analyze.HistoryAnalyzer) (analyze.Report, error) {
-	collectErr := agg.Collect()
-	if collectErr != nil {
-		return nil, fmt.Errorf("collect %s: %w", a.Name(), collectErr)
-	}
-
-	ticks, flushErr := agg.FlushAllTicks()
-	if flushErr != nil {
-		return nil, fmt.Errorf("flush %s: %w", a.Name(), flushErr)
+	ticks, err := aggregatedTicks(agg, a.Name())
+	if err != nil {
+		return nil, err
 	}
 
 	rep, repErr := a.ReportFromTICKs(ctx, ticks)
@@ -514,11 +928,43 @@ func reportFromAggregator(ctx context.Context, agg analyze.Aggregator, a analyze
 	return rep, nil
 }
 
+// aggregatedTicks returns all TICKs of agg in ascending tick order.
+func aggregatedTicks(agg analyze.Aggregator, name string) ([]analyze.TICK, error) {
+	if streamer, ok := agg.(analyze.TickStreamer); ok {
+		var ticks []analyze.TICK
+
+		err := streamer.StreamTicks(func(t analyze.TICK) error {
+			ticks = append(ticks, t)
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("stream %s: %w", name, err)
+		}
+
+		return ticks, nil
+	}
+
+	collectErr := agg.Collect()
+	if collectErr != nil {
+		return nil, fmt.Errorf("collect %s: %w", name, collectErr)
+	}
+
+	ticks, flushErr := agg.FlushAllTicks()
+	if flushErr != nil {
+		return nil, fmt.Errorf("flush %s: %w", name, flushErr)
+	}
+
+	return ticks, nil
+}
+
 // FinalizeWithAggregators produces reports from all leaf analyzers:
-//   - Analyzers with aggregators: Collect → FlushAllTicks → ReportFromTICKs
+//   - Analyzers with aggregators: Collect → FlushAllTicks → ReportFromTICKs,
+//     or StreamTicks → ReportFromTICKs for [analyze.TickStreamer] aggregators
 //   - Analyzers without aggregators: store empty report.
 //
-// Closes all aggregators before returning.
+// DerivedMetrics then run over the complete set of reports, and the Redactor,
+// if any, pseudonymizes them. Closes all aggregators before returning.
 func (runner *Runner) FinalizeWithAggregators(ctx context.Context) (map[analyze.HistoryAnalyzer]analyze.Report, error) {
 	defer runner.closeAggregators()
 
@@ -555,6 +1001,17 @@ func (runner *Runner) FinalizeWithAggregators(ctx context.Context) (map[analyze.
 	}
 
 	runner.injectCommitMeta(reports)
+	runner.injectCommitTable(reports)
+	runner.injectRunQuality(reports)
+	runner.injectBlobCacheStats(reports)
+	runner.injectSampleFactor(reports)
+
+	err := runner.runDerivedMetrics(reports)
+	if err != nil {
+		return nil, err
+	}
+
+	runner.redactReports(reports)
 
 	return reports, nil
 }
@@ -578,11 +1035,34 @@ func (runner *Runner) injectCommitMeta(reports map[analyze.HistoryAnalyzer]analy
 	}
 }
 
+// injectSampleFactor adds the commit sampling factor into every leaf report
+// under analyze.ReportKeySampleFactor when the history was sampled.
+func (runner *Runner) injectSampleFactor(reports map[analyze.HistoryAnalyzer]analyze.Report) {
+	if runner.SampleFactor <= 1 {
+		return
+	}
+
+	for _, report := range reports {
+		if report != nil {
+			report[analyze.ReportKeySampleFactor] = runner.SampleFactor
+		}
+	}
+}
+
 // ProcessChunkFromData consumes pre-fetched CommitData through analyzers,
 // bypassing Coordinator creation. Used by double-buffered chunk pipelining
 // where the pipeline has already run and collected data.
 // Returns zero PipelineStats since the real stats come from the prefetch Coordinator.
 func (runner *Runner) ProcessChunkFromData(ctx context.Context, data []CommitData, indexOffset, chunkIndex int) (PipelineStats, error) {
+	return PipelineStats{}, runner.processPrefetched(ctx, data, PipelineStats{}, indexOffset, chunkIndex)
+}
+
+// processPrefetched consumes pre-fetched CommitData like ProcessChunkFromData
+// and attributes pStats, the stats of the pipeline that fetched it, to the
+// chunk span.
+func (runner *Runner) processPrefetched(
+	ctx context.Context, data []CommitData, pStats PipelineStats, indexOffset, chunkIndex int,
+) error {
 	ctx, span := runner.tracer().Start(ctx, "codefang.chunk",
 		trace.WithAttributes(
 			attribute.Int("chunk.index", chunkIndex),
@@ -594,31 +1074,24 @@ func (runner *Runner) ProcessChunkFromData(ctx context.Context, data []CommitDat
 		runner.runtimeBallast = applyRuntimeTuning(runner.Config, runner.MemBudget)
 	})
 
-	analyzerDurations := make([]time.Duration, len(runner.Analyzers))
+	usage := make([]analyzerUsage, len(runner.Analyzers))
 
-	for _, cd := range data {
-		if cd.Error != nil {
-			observability.RecordSpanError(span, cd.Error, observability.ErrTypeDependencyUnavailable, observability.ErrSourceDependency)
-			span.End()
-
-			return PipelineStats{}, cd.Error
-		}
+	lookahead, stopLookahead := runner.withLookahead(ctx, commitDataChan(data), indexOffset)
+	defer stopLookahead()
 
-		analyzeCtx := runner.buildAnalyzeContext(cd, indexOffset)
-
-		consumeErr := runner.consumeAll(ctx, analyzeCtx, analyzerDurations)
+	for cd := range lookahead {
+		consumeErr := runner.consumeCommitData(ctx, span, cd, indexOffset, usage)
 		if consumeErr != nil {
-			observability.RecordSpanError(span, consumeErr, observability.ErrTypeInternal, observability.ErrSourceServer)
 			span.End()
 
-			return PipelineStats{}, consumeErr
+			return consumeErr
 		}
 	}
 
-	span.End()
-	runner.emitAnalyzerSpans(ctx, analyzerDurations)
+	runner.emitAnalyzerSpans(ctx, chunkIndex, usage)
+	runner.endChunkSpan(ctx, span, pStats)
 
-	return PipelineStats{}, nil
+	return nil
 }
 
 // processCommits processes commits through the pipeline without Initialize/Finalize.
@@ -638,6 +1111,108 @@ func (runner *Runner) processCommits(ctx context.Context, commits []*gitlib.Comm
 	return runner.processCommitsSerial(ctx, commits, indexOffset, chunkIndex)
 }
 
+// commitPreparers returns the leaf analyzers implementing analyze.CommitPreparer,
+// or nil when CommitLookahead is off.
+func (runner *Runner) commitPreparers() []analyze.CommitPreparer {
+	if !runner.CommitLookahead {
+		return nil
+	}
+
+	var preparers []analyze.CommitPreparer
+
+	for _, leaf := range runner.Analyzers[runner.CoreCount:] {
+		if p, ok := leaf.(analyze.CommitPreparer); ok {
+			preparers = append(preparers, p)
+		}
+	}
+
+	return preparers
+}
+
+// withLookahead forwards dataChan through a goroutine that runs the commit
+// preparers on every commit before handing it on. The hand-off blocks until
+// the consumer takes the commit, so commit N+1 is prepared while commit N is
+// consumed, and always before it is consumed itself. Returns dataChan
+// unchanged when there is nothing to prepare.
+//
+// The consumer must call the returned stop function once it is done, also
+// when it stops before dataChan is exhausted: the goroutine then releases
+// the commit it holds and drains dataChan, so neither it nor the stages
+// feeding dataChan stay blocked.
+func (runner *Runner) withLookahead(
+	ctx context.Context, dataChan <-chan CommitData, indexOffset int,
+) (lookahead <-chan CommitData, stop func()) {
+	preparers := runner.commitPreparers()
+	if len(preparers) == 0 {
+		return dataChan, func() {}
+	}
+
+	out := make(chan CommitData)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(out)
+
+		for data := range dataChan {
+			if data.Error == nil && data.Commit != nil {
+				ac := &analyze.Context{
+					Commit:      data.Commit,
+					Index:       data.Index + indexOffset,
+					Changes:     data.Changes,
+					BlobCache:   data.BlobCache,
+					FileDiffs:   data.FileDiffs,
+					UASTChanges: data.UASTChanges,
+				}
+
+				for _, p := range preparers {
+					p.PrepareCommit(ac)
+				}
+			}
+
+			select {
+			case out <- data:
+			case <-done:
+				releaseCommitData(data)
+				drainCommitData(dataChan)
+
+				return
+			case <-ctx.Done():
+				releaseCommitData(data)
+				drainCommitData(dataChan)
+
+				return
+			}
+		}
+	}()
+
+	return out, func() { close(done) }
+}
+
+// releaseCommitData releases the UAST trees of a commit that is never consumed.
+func releaseCommitData(data CommitData) {
+	releaseSnapshot(plumbing.Snapshot{UASTChanges: data.UASTChanges})
+}
+
+// drainCommitData releases every commit left in dataChan until it is closed.
+func drainCommitData(dataChan <-chan CommitData) {
+	for data := range dataChan {
+		releaseCommitData(data)
+	}
+}
+
+// commitDataChan returns a closed channel holding data, in order.
+func commitDataChan(data []CommitData) <-chan CommitData {
+	ch := make(chan CommitData, len(data))
+
+	for _, cd := range data {
+		ch <- cd
+	}
+
+	close(ch)
+
+	return ch
+}
+
 // splitLeaves partitions leaf analyzers into three groups:
 //   - cpuHeavy: Parallelizable, not SequentialOnly, CPUHeavy — dispatched to W workers via Fork/Merge.
 //   - lightweight: Parallelizable, not SequentialOnly, not CPUHeavy — run on main goroutine.
@@ -673,23 +1248,17 @@ func (runner *Runner) processCommitsSerial(
 		))
 
 	coordinator := NewCoordinator(runner.Repo, runner.Config)
-	dataChan := coordinator.Process(ctx, commits)
+	dataChan, stopLookahead := runner.withLookahead(ctx, coordinator.Process(ctx, commits), indexOffset)
+	defer stopLookahead()
 
-	analyzerDurations := make([]time.Duration, len(runner.Analyzers))
+	runner.chunkBlobCache = coordinator.blobCache
+	defer func() { runner.chunkBlobCache = nil }()
 
-	for data := range dataChan {
-		if data.Error != nil {
-			observability.RecordSpanError(span, data.Error, observability.ErrTypeDependencyUnavailable, observability.ErrSourceDependency)
-			span.End()
-
-			return PipelineStats{}, data.Error
-		}
+	usage := make([]analyzerUsage, len(runner.Analyzers))
 
-		analyzeCtx := runner.buildAnalyzeContext(data, indexOffset)
-
-		consumeErr := runner.consumeAll(ctx, analyzeCtx, analyzerDurations)
+	for data := range dataChan {
+		consumeErr := runner.consumeCommitData(ctx, span, data, indexOffset, usage)
 		if consumeErr != nil {
-			observability.RecordSpanError(span, consumeErr, observability.ErrTypeInternal, observability.ErrSourceServer)
 			span.End()
 
 			return PipelineStats{}, consumeErr
@@ -697,9 +1266,8 @@ func (runner *Runner) processCommitsSerial(
 	}
 
 	pStats := coordinator.Stats()
-	setPipelineAttributes(span, pStats)
-	span.End()
-	runner.emitAnalyzerSpans(ctx, analyzerDurations)
+	runner.emitAnalyzerSpans(ctx, chunkIndex, usage)
+	runner.endChunkSpan(ctx, span, pStats)
 
 	return pStats, nil
 }
@@ -722,34 +1290,73 @@ type bufferedTC struct {
 	time time.Time
 }
 
+// leafFailure records a leaf Consume error caught by a worker under a
+// non-abort commit error policy. Reported on the main goroutine after drain.
+type leafFailure struct {
+	hash  string
+	index int
+	stage string
+	err   error
+}
+
 // leafWorker holds forked leaf analyzers for one worker goroutine.
 type leafWorker struct {
-	leaves    []analyze.HistoryAnalyzer
-	indices   []int // original indices in runner.Analyzers for each leaf.
-	workChan  chan leafWork
-	durations []time.Duration // Accumulated per-leaf-analyzer durations.
-	tcs       []bufferedTC    // buffered TCs for deferred aggregation.
+	leaves     []analyze.HistoryAnalyzer
+	indices    []int // original indices in runner.Analyzers for each leaf.
+	workChan   chan leafWork
+	usage      []analyzerUsage // Accumulated per-leaf-analyzer usage.
+	tcs        []bufferedTC    // buffered TCs for deferred aggregation.
+	skipErrors bool            // record leaf errors in failures instead of stopping.
+	failures   []leafFailure
+	guard      *timeoutGuard // enforces leaf timeouts; nil for none.
 }
 
 // processWork applies the plumbing snapshot, runs leaf Consume(), then releases snapshot resources.
 // TCs with non-nil Data are buffered for deferred aggregation on the main goroutine.
+// A failing leaf stops the worker and leaves the snapshot to the collector,
+// as does a stuck leaf, which may still read it. Disabled leaves are skipped.
 func (w *leafWorker) processWork(ctx context.Context, work leafWork) error {
+	stuck := false
+
 	for i, leaf := range w.leaves {
+		if w.guard.isDisabled(leaf) {
+			continue
+		}
+
 		p, ok := leaf.(analyze.Parallelizable)
 		if !ok {
 			return fmt.Errorf("%w: %s", ErrNotParallelizable, leaf.Name())
 		}
 
-		p.ApplySnapshot(work.snapshot)
-
 		start := time.Now()
 
-		tc, consumeErr := leaf.Consume(ctx, work.analyzeCtx)
+		limit, consumeErr := w.guard.admit(leaf, w.usage[i].duration)
+
+		var tc analyze.TC
+
+		if consumeErr == nil {
+			p.ApplySnapshot(work.snapshot)
+
+			tc, consumeErr = w.guard.run(ctx, leaf, work.analyzeCtx, limit)
+		}
 
-		w.durations[i] += time.Since(start)
+		w.usage[i].add(start)
 
 		if consumeErr != nil {
-			return consumeErr
+			if leafErrorAborts(consumeErr, !w.skipErrors) {
+				return consumeErr
+			}
+
+			stuck = stuck || errors.Is(consumeErr, ErrAnalyzerStuck)
+
+			w.failures = append(w.failures, leafFailure{
+				hash:  commitHashString(work.analyzeCtx.Commit),
+				index: work.analyzeCtx.Index,
+				stage: leaf.Name(),
+				err:   consumeErr,
+			})
+
+			continue
 		}
 
 		if tc.Data != nil {
@@ -761,7 +1368,9 @@ func (w *leafWorker) processWork(ctx context.Context, work leafWork) error {
 	}
 
 	// Release snapshot resources (e.g. UAST trees).
-	releaseSnapshot(work.snapshot)
+	if !stuck {
+		releaseSnapshot(work.snapshot)
+	}
 
 	return nil
 }
@@ -778,9 +1387,9 @@ func newLeafWorkers(leaves []analyze.HistoryAnalyzer, leafIndices []int, w int)
 
 	for i := range w {
 		worker := &leafWorker{
-			workChan:  make(chan leafWork, leafWorkChanBuffer),
-			indices:   leafIndices,
-			durations: make([]time.Duration, len(leaves)),
+			workChan: make(chan leafWork, leafWorkChanBuffer),
+			indices:  leafIndices,
+			usage:    make([]analyzerUsage, len(leaves)),
 		}
 
 		worker.leaves = make([]analyze.HistoryAnalyzer, len(leaves))
@@ -830,11 +1439,16 @@ func startLeafWorkers(ctx context.Context, workers []*leafWorker) (*sync.WaitGro
 	return &wg, workerErrors
 }
 
-// mergeLeafResults merges forked leaf results back into the original leaf analyzers.
-func mergeLeafResults(leaves []analyze.HistoryAnalyzer, workers []*leafWorker) {
+// mergeLeafResults merges forked leaf results back into the original leaf
+// analyzers, except for disabled ones, whose stuck fork may still write its state.
+func mergeLeafResults(leaves []analyze.HistoryAnalyzer, workers []*leafWorker, guard *timeoutGuard) {
 	numWorkers := len(workers)
 
 	for leafIdx, leaf := range leaves {
+		if guard.isDisabled(leaf) {
+			continue
+		}
+
 		forks := make([]analyze.HistoryAnalyzer, numWorkers)
 		for workerIdx, worker := range workers {
 			forks[workerIdx] = worker.leaves[leafIdx]
@@ -899,9 +1513,28 @@ func mergeSnapshotMaps(composite *plumbing.Snapshot, snap plumbing.Snapshot) {
 		composite.Languages = snap.Languages
 	}
 
+	if composite.Roles == nil && snap.Roles != nil {
+		composite.Roles = snap.Roles
+	}
+
 	if composite.UASTChanges == nil && snap.UASTChanges != nil {
 		composite.UASTChanges = snap.UASTChanges
 	}
+
+	if composite.CoAuthorIDs == nil && snap.CoAuthorIDs != nil {
+		composite.CoAuthorIDs = snap.CoAuthorIDs
+	}
+
+	// Leaves carry the outputs of different custom plumbing analyzers.
+	for name, value := range snap.Custom {
+		if composite.Custom == nil {
+			composite.Custom = make(map[string]any, len(snap.Custom))
+		}
+
+		if _, ok := composite.Custom[name]; !ok {
+			composite.Custom[name] = value
+		}
+	}
 }
 
 // mergeSnapshotScalars copies zero-valued scalar fields from snap into composite,
@@ -945,11 +1578,17 @@ func collectSnapshotters(leaves []analyze.HistoryAnalyzer) ([]analyze.Paralleliz
 func (runner *Runner) buildAnalyzeContext(data CommitData, indexOffset int) *analyze.Context {
 	commit := data.Commit
 
+	// A sampled commit is diffed against the previous sampled commit, not
+	// against one of its parents, so it is no merge to the analyzers.
+	_, sampled := commit.SampleBase()
+
 	isMerge := commit.NumParents() > 1
-	if runner.Config.FirstParent {
+	if runner.Config.FirstParent || sampled {
 		isMerge = false
 	}
 
+	runner.observeRedaction(commit, data.Changes)
+
 	return &analyze.Context{
 		Commit:      commit,
 		Index:       data.Index + indexOffset,
@@ -980,13 +1619,23 @@ func (runner *Runner) processCommitsHybrid(
 		))
 
 	coordinator := NewCoordinator(runner.Repo, runner.Config)
-	dataChan := coordinator.Process(ctx, commits)
+	dataChan, stopLookahead := runner.withLookahead(ctx, coordinator.Process(ctx, commits), indexOffset)
+	defer stopLookahead()
+
+	runner.chunkBlobCache = coordinator.blobCache
+	defer func() { runner.chunkBlobCache = nil }()
 
 	core := runner.Analyzers[:runner.CoreCount]
 	idxMap := runner.analyzerIndex()
 
 	numWorkers := runner.Config.LeafWorkers
 	workers := newLeafWorkers(cpuHeavy, mapIndices(cpuHeavy, idxMap), numWorkers)
+
+	for _, worker := range workers {
+		worker.skipErrors = !runner.abortOnCommitError()
+		worker.guard = runner.timeoutGuard()
+	}
+
 	wg, workerErrors := startLeafWorkers(ctx, workers)
 
 	snapshotters, snapErr := collectSnapshotters(append(cpuHeavy, lightweight...))
@@ -1002,7 +1651,7 @@ func (runner *Runner) processCommitsHybrid(
 	mainLeaves = append(mainLeaves, serialLeaves...)
 	mainIndices := mapIndices(mainLeaves, idxMap)
 
-	coreDurations, mainDurations, loopErr := runner.hybridCommitLoop(
+	mainUsage, loopErr := runner.hybridCommitLoop(
 		ctx, dataChan, indexOffset, core, mainLeaves, mainIndices, snapshotters, workers, numWorkers, wg)
 	if loopErr != nil {
 		span.End()
@@ -1018,20 +1667,22 @@ func (runner *Runner) processCommitsHybrid(
 		}
 	}
 
-	mergeLeafResults(cpuHeavy, workers)
+	mergeLeafResults(cpuHeavy, workers, runner.timeoutGuard())
 
 	// Drain buffered TCs from workers into aggregators on the main goroutine.
 	runner.drainWorkerTCs(workers)
 
-	pStats := coordinator.Stats()
-	setPipelineAttributes(span, pStats)
-	span.End()
-
-	// Core durations are not emitted as spans (infrastructure).
-	_ = coreDurations
+	for _, worker := range workers {
+		for _, f := range worker.failures {
+			runner.recordCommitFailure(ctx, f.hash, f.index, f.stage, 1, f.err)
+		}
+	}
 
 	// Emit per-analyzer spans for leaf analyzers.
-	runner.emitHybridAnalyzerSpans(ctx, mainLeaves, mainDurations, cpuHeavy, workers)
+	runner.emitHybridAnalyzerSpans(ctx, chunkIndex, mainLeaves, mainUsage, cpuHeavy, workers)
+
+	pStats := coordinator.Stats()
+	runner.endChunkSpan(ctx, span, pStats)
 
 	return pStats, nil
 }
@@ -1039,7 +1690,7 @@ func (runner *Runner) processCommitsHybrid(
 // hybridCommitLoop iterates over pipeline data, dispatching work to parallel workers
 // and running core/serial analyzers on the main goroutine.
 // mainIndices maps each serial leaf position to its original index in runner.Analyzers.
-// Returns accumulated durations for core and main-goroutine leaf analyzers.
+// Returns the accumulated usage of the main-goroutine leaf analyzers.
 func (runner *Runner) hybridCommitLoop(
 	ctx context.Context,
 	dataChan <-chan CommitData,
@@ -1050,36 +1701,46 @@ func (runner *Runner) hybridCommitLoop(
 	workers []*leafWorker,
 	numWorkers int,
 	wg *sync.WaitGroup,
-) (coreDurations, mainDurations []time.Duration, err error) {
-	coreDurations = make([]time.Duration, len(core))
-	mainDurations = make([]time.Duration, len(serialLeaves))
+) ([]analyzerUsage, error) {
+	mainUsage := make([]analyzerUsage, len(serialLeaves))
+	guard := runner.timeoutGuard()
 
 	var commitIdx int
 
 	for data := range dataChan {
+		runner.relieveSoftLimit(ctx)
+
+		data, attempts := runner.retryCommitData(ctx, data)
 		if data.Error != nil {
-			closeWorkersAndWait(workers, wg)
+			if runner.abortOnCommitError() {
+				closeWorkersAndWait(workers, wg)
 
-			return nil, nil, data.Error
+				return nil, data.Error
+			}
+
+			runner.recordCommitFailure(ctx, commitHashString(data.Commit), data.Index+indexOffset, stagePipeline, attempts, data.Error)
+
+			continue
 		}
 
 		analyzeCtx := runner.buildAnalyzeContext(data, indexOffset)
 
 		// Run core (plumbing) analyzers sequentially.
-		for i, a := range core {
-			start := time.Now()
-
-			_, coreErr := a.Consume(ctx, analyzeCtx)
-
-			coreDurations[i] += time.Since(start)
-
-			if coreErr != nil {
+		coreStage, coreErr := runner.consumeCore(ctx, core, analyzeCtx)
+		if coreErr != nil {
+			if runner.abortOnCommitError() {
 				closeWorkersAndWait(workers, wg)
 
-				return nil, nil, coreErr
+				return nil, coreErr
 			}
+
+			runner.recordCommitFailure(ctx, commitHashString(analyzeCtx.Commit), analyzeCtx.Index, coreStage, 1, coreErr)
+
+			continue
 		}
 
+		runner.recordCommitRow(analyzeCtx)
+
 		// Snapshot plumbing state for parallel workers before serial leaves mutate anything.
 		// Build a composite snapshot from ALL parallel leaves so every plumbing field
 		// (Changes, BlobCache, FileDiffs, UAST, Tick, AuthorID, etc.) is captured.
@@ -1092,14 +1753,20 @@ func (runner *Runner) hybridCommitLoop(
 		for i, a := range serialLeaves {
 			start := time.Now()
 
-			tc, leafErr := a.Consume(ctx, analyzeCtx)
+			tc, leafErr := guard.consume(ctx, a, analyzeCtx, mainUsage[i].duration)
 
-			mainDurations[i] += time.Since(start)
+			mainUsage[i].add(start)
 
 			if leafErr != nil {
-				closeWorkersAndWait(workers, wg)
+				if leafErrorAborts(leafErr, runner.abortOnCommitError()) {
+					closeWorkersAndWait(workers, wg)
+
+					return nil, leafErr
+				}
+
+				runner.recordCommitFailure(ctx, commitHashString(analyzeCtx.Commit), analyzeCtx.Index, a.Name(), 1, leafErr)
 
-				return nil, nil, leafErr
+				continue
 			}
 
 			runner.addTC(tc, mainIndices[i], analyzeCtx)
@@ -1111,7 +1778,20 @@ func (runner *Runner) hybridCommitLoop(
 	// Close all work channels to signal workers to finish.
 	closeWorkersAndWait(workers, wg)
 
-	return coreDurations, mainDurations, nil
+	return mainUsage, nil
+}
+
+// consumeCore runs core (plumbing) analyzers sequentially.
+// Returns the failing analyzer's name and error on the first failure.
+func (runner *Runner) consumeCore(ctx context.Context, core []analyze.HistoryAnalyzer, ac *analyze.Context) (string, error) {
+	for _, a := range core {
+		_, err := consumeSafely(ctx, a, ac)
+		if err != nil {
+			return a.Name(), err
+		}
+	}
+
+	return "", nil
 }
 
 // setPipelineAttributes sets pipeline timing and cache stats as attributes on a chunk span.
@@ -1124,12 +1804,43 @@ func setPipelineAttributes(span trace.Span, ps PipelineStats) {
 		attribute.Int64("cache.blob.misses", ps.BlobCacheMisses),
 		attribute.Int64("cache.diff.hits", ps.DiffCacheHits),
 		attribute.Int64("cache.diff.misses", ps.DiffCacheMisses),
+		attribute.Int64("cache.diff.deduped", ps.DiffDeduped),
+		attribute.Int64("cache.uast.hits", ps.UASTMemoHits),
+		attribute.Int64("cache.uast.misses", ps.UASTMemoMisses),
 	)
 }
 
-// emitAnalyzerSpans creates per-analyzer child spans with accumulated durations.
-// Only leaf analyzers (index >= CoreCount) get spans; core analyzers are infrastructure.
-func (runner *Runner) emitAnalyzerSpans(ctx context.Context, durations []time.Duration) {
+// endChunkSpan sets the pipeline attributes of a chunk span, records the
+// chunk's cache counters under it and ends it.
+func (runner *Runner) endChunkSpan(ctx context.Context, span trace.Span, ps PipelineStats) {
+	setPipelineAttributes(span, ps)
+	runner.recordBlobCacheStats(ps.BlobCache)
+	runner.AnalysisMetrics.RecordChunkCache(ctx, observability.ChunkCacheStats{
+		BlobHits:   ps.BlobCacheHits,
+		BlobMisses: ps.BlobCacheMisses,
+		DiffHits:   ps.DiffCacheHits,
+		DiffMisses: ps.DiffCacheMisses,
+	})
+	span.End()
+}
+
+// analyzerUsage accumulates the time an analyzer spent consuming the commits
+// of one chunk.
+type analyzerUsage struct {
+	duration time.Duration
+	commits  int
+}
+
+// add counts one commit consumed since start.
+func (u *analyzerUsage) add(start time.Time) {
+	u.duration += time.Since(start)
+	u.commits++
+}
+
+// emitAnalyzerSpans creates per-analyzer child spans of a chunk span with
+// accumulated usage. Only leaf analyzers (index >= CoreCount) get spans; core
+// analyzers are infrastructure.
+func (runner *Runner) emitAnalyzerSpans(ctx context.Context, chunkIndex int, usage []analyzerUsage) {
 	tr := runner.tracer()
 	now := time.Now()
 
@@ -1138,17 +1849,15 @@ func (runner *Runner) emitAnalyzerSpans(ctx context.Context, durations []time.Du
 			continue
 		}
 
-		_, aSpan := tr.Start(ctx, "codefang.analyzer."+a.Name(),
-			trace.WithTimestamp(now.Add(-durations[i])))
-		aSpan.End(trace.WithTimestamp(now))
+		emitAnalyzerSpan(ctx, tr, now, a.Name(), chunkIndex, usage[i])
 	}
 }
 
 // emitHybridAnalyzerSpans creates per-analyzer spans for hybrid mode where
-// main-goroutine leaves and worker leaves have separate duration tracking.
+// main-goroutine leaves and worker leaves have separate usage tracking.
 func (runner *Runner) emitHybridAnalyzerSpans(
-	ctx context.Context,
-	mainLeaves []analyze.HistoryAnalyzer, mainDurations []time.Duration,
+	ctx context.Context, chunkIndex int,
+	mainLeaves []analyze.HistoryAnalyzer, mainUsage []analyzerUsage,
 	cpuHeavy []analyze.HistoryAnalyzer, workers []*leafWorker,
 ) {
 	tr := runner.tracer()
@@ -1156,21 +1865,32 @@ func (runner *Runner) emitHybridAnalyzerSpans(
 
 	// Main-goroutine leaves (lightweight + serial).
 	for i, leaf := range mainLeaves {
-		_, aSpan := tr.Start(ctx, "codefang.analyzer."+leaf.Name(),
-			trace.WithTimestamp(now.Add(-mainDurations[i])))
-		aSpan.End(trace.WithTimestamp(now))
+		emitAnalyzerSpan(ctx, tr, now, leaf.Name(), chunkIndex, mainUsage[i])
 	}
 
-	// CPU-heavy leaves: sum durations across all workers.
+	// CPU-heavy leaves: sum usage across all workers.
 	for leafIdx, leaf := range cpuHeavy {
-		var total time.Duration
+		var total analyzerUsage
 
 		for _, worker := range workers {
-			total += worker.durations[leafIdx]
+			total.duration += worker.usage[leafIdx].duration
+			total.commits += worker.usage[leafIdx].commits
 		}
 
-		_, aSpan := tr.Start(ctx, "codefang.analyzer."+leaf.Name(),
-			trace.WithTimestamp(now.Add(-total)))
-		aSpan.End(trace.WithTimestamp(now))
+		emitAnalyzerSpan(ctx, tr, now, leaf.Name(), chunkIndex, total)
 	}
 }
+
+// emitAnalyzerSpan creates the span of one analyzer's consumption of a chunk,
+// ending at end and lasting the accumulated duration.
+func emitAnalyzerSpan(ctx context.Context, tr trace.Tracer, end time.Time, name string, chunkIndex int, usage analyzerUsage) {
+	_, span := tr.Start(ctx, "codefang.analyzer."+name,
+		trace.WithTimestamp(end.Add(-usage.duration)),
+		trace.WithAttributes(
+			attribute.String("analyzer.name", name),
+			attribute.Int("chunk.index", chunkIndex),
+			attribute.Int("analyzer.commits", usage.commits),
+			attribute.Int64("analyzer.duration_ms", usage.duration.Milliseconds()),
+		))
+	span.End(trace.WithTimestamp(end))
+}
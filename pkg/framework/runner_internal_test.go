@@ -1,14 +1,19 @@
 package framework
 
 import (
+	"context"
+	"log/slog"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/checkpoint"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 )
 
@@ -20,6 +25,12 @@ type mockAnalyzer struct {
 
 func (m mockAnalyzer) Flag() string { return m.flag }
 
+type preparingMockAnalyzer struct {
+	mockAnalyzer
+}
+
+func (preparingMockAnalyzer) PrepareCommit(*analyze.Context) {}
+
 func TestRunner_drainWorkerTCs_ConcurrentRouting(t *testing.T) {
 	t.Parallel()
 
@@ -80,3 +91,189 @@ func TestRunner_drainWorkerTCs_ConcurrentRouting(t *testing.T) {
 	assert.Less(t, elapsed, 50*time.Millisecond, "should run concurrently")
 	assert.Equal(t, int32(2), atomic.LoadInt32(&maxActive), "should have 2 concurrent routes")
 }
+
+type capsAnalyzer struct {
+	mockAnalyzer
+
+	caps analyze.Capabilities
+}
+
+func (c capsAnalyzer) Capabilities() analyze.Capabilities { return c.caps }
+
+func TestRunner_sendToSink_JournalsBufferedRecordsOnFlush(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+
+	buffered := analyze.NewBufferedSink(func(analyze.TC, string) error {
+		<-release
+
+		return nil
+	}, analyze.BufferedSinkOptions{Buffer: 4})
+
+	r := &Runner{
+		Analyzers:     []analyze.HistoryAnalyzer{mockAnalyzer{flag: "a0"}},
+		TCSink:        buffered.WriteTC,
+		SinkOnWritten: buffered.OnWritten,
+	}
+
+	cpManager := checkpoint.NewManager(t.TempDir(), "abc123")
+	attachSinkJournal(context.Background(), slog.Default(), r, cpManager, nil)
+	require.NotNil(t, r.sinkJournal)
+
+	defer r.sinkJournal.Close()
+
+	hash := gitlib.Hash{1}
+	r.sendToSink(analyze.TC{CommitHash: hash, Data: 1}, 0)
+
+	assert.Empty(t, r.sinkJournal.LastCommits(), "a queued record is not journaled")
+
+	close(release)
+	require.NoError(t, buffered.Close())
+
+	assert.Equal(t, map[string]string{"a0": hash.String()}, r.sinkJournal.LastCommits())
+	assert.Equal(t, uint64(1), r.sinkJournal.LastSeq())
+}
+
+func TestRunner_sinkCheckpointState_ResumesWithBufferedRecords(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+
+	buffered := analyze.NewBufferedSink(func(analyze.TC, string) error {
+		<-release
+
+		return nil
+	}, analyze.BufferedSinkOptions{Buffer: 4})
+
+	r := &Runner{
+		Analyzers:     []analyze.HistoryAnalyzer{mockAnalyzer{flag: "a0"}},
+		TCSink:        buffered.WriteTC,
+		SinkOnWritten: buffered.OnWritten,
+		SinkFlush:     buffered.Flush,
+	}
+
+	cpManager := checkpoint.NewManager(t.TempDir(), "abc123")
+	attachSinkJournal(context.Background(), slog.Default(), r, cpManager, nil)
+	require.NotNil(t, r.sinkJournal)
+
+	first, second := gitlib.Hash{1}, gitlib.Hash{2}
+	r.sendToSink(analyze.TC{CommitHash: first, Data: 1}, 0)
+
+	// The record is still queued when the checkpoint is taken.
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+
+	lastCommits, seq := sinkCheckpointState(r)
+	assert.Equal(t, map[string]string{"a0": first.String()}, lastCommits, "the checkpoint covers the queued record")
+	assert.Equal(t, uint64(1), seq)
+
+	resetSinkJournal(context.Background(), slog.Default(), r)
+	require.NoError(t, buffered.Close())
+	r.sinkJournal.Close()
+
+	// The run is interrupted before the next checkpoint and resumed.
+	state := &checkpoint.StreamingState{SinkLastCommits: lastCommits, SinkSeq: seq}
+
+	resumed := &Runner{Analyzers: r.Analyzers, TCSink: func(analyze.TC, string) error { return nil }}
+	attachSinkJournal(context.Background(), slog.Default(), resumed, cpManager, state)
+	require.NotNil(t, resumed.sinkJournal)
+
+	defer resumed.sinkJournal.Close()
+
+	assert.False(t, resumed.sinkJournal.Skip("a0", second.String()), "records after the checkpoint are emitted")
+	assert.Equal(t, uint64(1), resumed.sinkJournal.LastSeq())
+}
+
+func TestRunner_withLookahead_StopReleasesPendingCommits(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{
+		Analyzers:       []analyze.HistoryAnalyzer{preparingMockAnalyzer{mockAnalyzer{flag: "p"}}},
+		CommitLookahead: true,
+	}
+
+	dataChan := make(chan CommitData, 3)
+	for i := range 3 {
+		dataChan <- CommitData{Index: i}
+	}
+
+	close(dataChan)
+
+	lookahead, stop := r.withLookahead(context.Background(), dataChan, 0)
+
+	first := <-lookahead
+	assert.Equal(t, 0, first.Index)
+
+	// The consumer stops early, with the next commit pending in the hand-off.
+	stop()
+
+	require.Eventually(t, func() bool { return len(dataChan) == 0 }, time.Second, time.Millisecond,
+		"the remaining commits are drained")
+
+	_, ok := <-lookahead
+	assert.False(t, ok, "the lookahead goroutine exits")
+}
+
+func TestRunner_applyCapabilities(t *testing.T) {
+	t.Parallel()
+
+	core := mockAnalyzer{flag: "core"}
+	leaf := capsAnalyzer{mockAnalyzer: mockAnalyzer{flag: "leaf"}, caps: analyze.Capabilities{Memory: analyze.MemoryHigh}}
+
+	r := &Runner{Analyzers: []analyze.HistoryAnalyzer{core, leaf}, CoreCount: 1, Config: DefaultCoordinatorConfig()}
+	r.applyCapabilities()
+
+	assert.True(t, r.Config.SkipBlobs)
+	assert.True(t, r.Config.SkipDiffs)
+	assert.Zero(t, r.Config.UASTPipelineWorkers)
+	assert.Equal(t, analyze.MemoryHigh, r.leafCapabilities().Memory)
+
+	withTable := &Runner{
+		Analyzers: []analyze.HistoryAnalyzer{core, leaf}, CoreCount: 1,
+		Config: DefaultCoordinatorConfig(), CommitTable: true,
+	}
+	withTable.applyCapabilities()
+
+	assert.False(t, withTable.Config.SkipBlobs, "the commit table needs line stats")
+	assert.False(t, withTable.Config.SkipDiffs)
+
+	// Without CoreCount the undeclared core analyzer counts as a leaf.
+	noCore := &Runner{Analyzers: []analyze.HistoryAnalyzer{core, leaf}, Config: DefaultCoordinatorConfig()}
+	noCore.applyCapabilities()
+
+	assert.False(t, noCore.Config.SkipBlobs)
+	assert.False(t, noCore.Config.SkipDiffs)
+	assert.NotZero(t, noCore.Config.UASTPipelineWorkers)
+
+	// Custom plumbing that declares its capabilities keeps the stages it reads.
+	custom := capsAnalyzer{mockAnalyzer: mockAnalyzer{flag: "jira"}, caps: analyze.Capabilities{NeedsBlobs: true}}
+	withCustom := &Runner{
+		Analyzers: []analyze.HistoryAnalyzer{core, custom, leaf}, CoreCount: 2,
+		Config: DefaultCoordinatorConfig(),
+	}
+	withCustom.applyCapabilities()
+
+	assert.False(t, withCustom.Config.SkipBlobs)
+	assert.True(t, withCustom.Config.SkipDiffs)
+}
+
+type snapshotLeaf struct {
+	analyze.Parallelizable
+
+	snap plumbing.Snapshot
+}
+
+func (s snapshotLeaf) SnapshotPlumbing() analyze.PlumbingSnapshot { return s.snap }
+
+func TestBuildCompositeSnapshot_MergesCustomPlumbing(t *testing.T) {
+	t.Parallel()
+
+	jira := snapshotLeaf{snap: plumbing.Snapshot{Tick: 3, Custom: map[string]any{"jira": "CF-1"}}}
+	owners := snapshotLeaf{snap: plumbing.Snapshot{Custom: map[string]any{"owners": "team-a", "jira": "CF-2"}}}
+
+	composite, ok := buildCompositeSnapshot([]analyze.Parallelizable{jira, owners}).(plumbing.Snapshot)
+	assert.True(t, ok)
+	assert.Equal(t, 3, composite.Tick)
+	assert.Equal(t, map[string]any{"jira": "CF-1", "owners": "team-a"}, composite.Custom)
+	assert.Len(t, jira.snap.Custom, 1, "leaf snapshots are left untouched")
+}
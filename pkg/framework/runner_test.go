@@ -4,6 +4,8 @@ import (
 	"context"
 	"io"
 	"runtime/debug"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/Sumatoshi-tech/codefang/pkg/framework"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/pipeline"
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
 )
 
 // stubLeaf is a minimal HistoryAnalyzer + Parallelizable stub for testing dispatch logic.
@@ -360,6 +363,72 @@ func TestResolveMemoryLimit_UnknownSystem(t *testing.T) {
 	}
 }
 
+// preparingLeaf is a sequential stubLeaf that logs PrepareCommit and Consume calls.
+type preparingLeaf struct {
+	stubLeaf
+
+	mu     sync.Mutex
+	events []string
+}
+
+func (p *preparingLeaf) PrepareCommit(ac *analyze.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, "prepare "+ac.Commit.Hash().String())
+}
+
+func (p *preparingLeaf) Consume(_ context.Context, ac *analyze.Context) (analyze.TC, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, "consume "+ac.Commit.Hash().String())
+
+	return analyze.TC{}, nil
+}
+
+func TestRunner_CommitLookaheadPreparesBeforeConsume(t *testing.T) {
+	t.Parallel()
+
+	repo := framework.NewTestRepo(t)
+	defer repo.Close()
+
+	repo.CreateFile("a.txt", "a")
+	repo.Commit("first")
+	repo.CreateFile("b.txt", "b")
+	repo.Commit("second")
+
+	libRepo, err := gitlib.OpenRepository(repo.Path())
+	require.NoError(t, err)
+	defer libRepo.Free()
+
+	commits := framework.CollectCommits(t, libRepo, 0)
+
+	leaf := &preparingLeaf{stubLeaf: stubLeaf{name: "seq", sequentialOnly: true}}
+	runner := framework.NewRunner(libRepo, repo.Path(), &plumbing.TreeDiffAnalyzer{}, leaf)
+	runner.CoreCount = 1
+	runner.CommitLookahead = true
+
+	require.NoError(t, runner.Initialize())
+
+	_, err = runner.ProcessChunk(context.Background(), commits, 0, 0)
+	require.NoError(t, err)
+
+	leaf.mu.Lock()
+	defer leaf.mu.Unlock()
+
+	require.Len(t, leaf.events, 2*len(commits))
+
+	for _, commit := range commits {
+		hash := commit.Hash().String()
+		prepared := slices.Index(leaf.events, "prepare "+hash)
+		consumed := slices.Index(leaf.events, "consume "+hash)
+
+		require.GreaterOrEqual(t, prepared, 0, hash)
+		require.Less(t, prepared, consumed, hash)
+	}
+}
+
 func TestSplitLeaves_ThreeGroups(t *testing.T) {
 	t.Parallel()
 
@@ -780,6 +849,57 @@ func TestAddTC_RouteToTCSink(t *testing.T) {
 	}
 }
 
+func TestAddTC_SinkStampsSequence(t *testing.T) {
+	t.Parallel()
+
+	var seqs []uint64
+
+	leaf := &stubLeaf{name: "quality"}
+	runner := framework.NewRunner(nil, "", leaf)
+	runner.TCSink = func(tc analyze.TC, _ string) error {
+		seqs = append(seqs, tc.Seq)
+
+		return nil
+	}
+
+	framework.InitAggregatorsForTest(runner)
+
+	for _, hash := range []string{
+		"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+		"cccccccccccccccccccccccccccccccccccccccc",
+	} {
+		tc := analyze.TC{CommitHash: gitlib.NewHash(hash), Data: 1}
+		framework.AddTCForTest(runner, tc, 0, &analyze.Context{Time: time.Now()})
+	}
+
+	if len(seqs) != 2 || seqs[0] != 1 || seqs[1] != 2 {
+		t.Errorf("expected sequence numbers [1 2], got %v", seqs)
+	}
+}
+
+func TestAddTC_ObserverSeesTCsAlongsideAggregation(t *testing.T) {
+	t.Parallel()
+
+	var observed []string
+
+	leaf := &stubLeaf{name: "quality"}
+	runner := framework.NewRunner(nil, "", leaf)
+	runner.TCObserver = func(tc analyze.TC, flag string) error {
+		observed = append(observed, flag+"@"+tc.CommitHash.String())
+
+		return nil
+	}
+
+	framework.InitAggregatorsForTest(runner)
+
+	hash := gitlib.NewHash("dddddddddddddddddddddddddddddddddddddddd")
+	framework.AddTCForTest(runner, analyze.TC{CommitHash: hash, Data: 1}, 0, &analyze.Context{Time: time.Now()})
+	framework.AddTCForTest(runner, analyze.TC{CommitHash: hash}, 0, &analyze.Context{Time: time.Now()})
+
+	assert.Equal(t, []string{"quality@" + hash.String()}, observed)
+	assert.NotNil(t, framework.AggregatorsForTest(runner), "aggregation stays enabled with an observer")
+}
+
 func TestAddTC_NilDataSkipsSink(t *testing.T) {
 	t.Parallel()
 
@@ -1100,3 +1220,39 @@ func TestRunner_InitializeForResume(t *testing.T) {
 	assert.Equal(t, "/tmp/restored-spill", info.Dir)
 	assert.Equal(t, 7, info.Count)
 }
+
+func TestRunner_RelieveSoftLimit(t *testing.T) {
+	t.Parallel()
+
+	full := &stubAggregator{stateSize: 100}
+	empty := &stubAggregator{}
+
+	runner := &framework.Runner{
+		Analyzers: []analyze.HistoryAnalyzer{
+			&stubLeafWithAgg{stubLeaf: stubLeaf{name: "full"}, agg: full},
+			&stubLeafWithAgg{stubLeaf: stubLeaf{name: "empty"}, agg: empty},
+		},
+	}
+
+	framework.InitAggregatorsForTest(runner)
+
+	cache := framework.NewGlobalBlobCache(1024)
+	cache.Put(makeTestHash(1), makeTestBlob(make([]byte, 600)))
+	framework.SetChunkBlobCacheForTest(runner, cache)
+
+	events := make(chan streaming.SoftLimitEvent, 1)
+	framework.SetSoftLimitEventsForTest(runner, events)
+
+	// No pending event: nothing happens.
+	framework.RelieveSoftLimitForTest(context.Background(), runner)
+	assert.False(t, full.spilled)
+	assert.Equal(t, int64(1024), cache.Stats().MaxSize)
+
+	events <- streaming.SoftLimitEvent{Used: 900, Limit: 1000}
+	framework.RelieveSoftLimitForTest(context.Background(), runner)
+
+	assert.True(t, full.spilled)
+	assert.False(t, empty.spilled, "aggregators without state are not spilled")
+	assert.Equal(t, int64(512), cache.Stats().MaxSize)
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/devs"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/crypt"
 	"github.com/Sumatoshi-tech/codefang/pkg/framework"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
@@ -373,7 +374,7 @@ func TestScaleScanning_OutputHistoryResultsJSON(t *testing.T) {
 	// Use OutputHistoryResults — this is what `codefang run --format json` calls.
 	var buf bytes.Buffer
 
-	err = analyze.OutputHistoryResults([]analyze.HistoryAnalyzer{leaf}, results, analyze.FormatJSON, &buf)
+	err = analyze.OutputHistoryResults([]analyze.HistoryAnalyzer{leaf}, results, analyze.FormatJSON, &buf, 0)
 	require.NoError(t, err)
 	require.NotEmpty(t, buf.String())
 
@@ -609,8 +610,10 @@ func TestScaleScanning_CheckpointSaveResumeAcrossChunks(t *testing.T) {
 	var checkpointCount int
 
 	for _, a := range analyzers1 {
-		if cp, ok := a.(interface{ SaveCheckpoint(string) error }); ok {
-			require.NoError(t, cp.SaveCheckpoint(checkpointDir),
+		if cp, ok := a.(interface {
+			SaveCheckpoint(dir string, c *crypt.Cipher) error
+		}); ok {
+			require.NoError(t, cp.SaveCheckpoint(checkpointDir, nil),
 				"SaveCheckpoint for %s", a.Name())
 
 			checkpointCount++
@@ -623,8 +626,10 @@ func TestScaleScanning_CheckpointSaveResumeAcrossChunks(t *testing.T) {
 	require.NoError(t, runner2.Initialize())
 
 	for _, a := range analyzers2 {
-		if cp, ok := a.(interface{ LoadCheckpoint(string) error }); ok {
-			require.NoError(t, cp.LoadCheckpoint(checkpointDir),
+		if cp, ok := a.(interface {
+			LoadCheckpoint(dir string, c *crypt.Cipher) error
+		}); ok {
+			require.NoError(t, cp.LoadCheckpoint(checkpointDir, nil),
 				"LoadCheckpoint for %s", a.Name())
 		}
 	}
@@ -2,9 +2,12 @@ package framework
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"runtime"
 	"runtime/debug"
 	"time"
@@ -43,9 +46,36 @@ type StreamingConfig struct {
 	// and FinalizeWithAggregators is not called — results are nil.
 	TCSink analyze.TCSink
 
+	// SinkOnWritten, when set, registers the callback through which a
+	// buffered TCSink confirms written records; see Runner.SinkOnWritten.
+	SinkOnWritten func(analyze.TCSink)
+
+	// SinkFlush, when set, drains a buffered TCSink; see Runner.SinkFlush.
+	SinkFlush func()
+
 	// AggSpillBudget is the maximum bytes of aggregator state to keep in memory
 	// before spilling to disk. Computed by ComputeSchedule. Zero means no limit.
 	AggSpillBudget int64
+
+	// TCObserver, when set, receives every non-nil TC as commits are
+	// consumed, without replacing aggregation. See Runner.TCObserver.
+	TCObserver analyze.TCSink
+
+	// OnProgress, when set, is called after every chunk.
+	OnProgress ProgressFunc
+
+	// CommitWeights, when set, holds the estimated work of every commit, in
+	// processing order (see gitlib.Repository.ScanWorkload). Chunks are then
+	// cut to carry about equal work rather than equal commit counts; see
+	// streaming.BalanceChunks.
+	CommitWeights []int64
+
+	// DiskBudget is the maximum total bytes of spill and checkpoint files.
+	// When set, disk usage and free space are checked after every chunk and
+	// the run fails with streaming.ErrDiskBudgetExceeded or
+	// streaming.ErrDiskSpaceLow before a write can hit ENOSPC. Zero disables
+	// the checks.
+	DiskBudget int64
 }
 
 // logger returns the configured logger, or a discard logger if nil.
@@ -73,6 +103,9 @@ func RunStreaming(
 	config StreamingConfig,
 ) (map[analyze.HistoryAnalyzer]analyze.Report, error) {
 	logger := config.logger()
+
+	runner.applyCapabilities()
+
 	growthPerCommit := aggregateStateGrowth(analyzers, runner.CoreCount)
 	pipelineOverhead := runner.Config.EstimatedOverhead()
 	workStatePerCommit, avgTCSize := splitStateGrowth(analyzers, runner.CoreCount)
@@ -84,10 +117,10 @@ func RunStreaming(
 		PipelineOverhead:   pipelineOverhead,
 		WorkStatePerCommit: workStatePerCommit,
 		AvgTCSize:          avgTCSize,
-		MaxBuffering:       maxStreamingBuffering,
+		MaxBuffering:       maxBuffering(runner),
 	})
 
-	chunks := schedule.Chunks
+	chunks := streaming.BalanceChunks(schedule.Chunks, config.CommitWeights)
 
 	// Create adaptive planner for feedback-driven replanning of remaining chunks.
 	// Seed with per-slot growth so replanning produces chunks consistent with
@@ -98,11 +131,25 @@ func RunStreaming(
 	}
 
 	ap := streaming.NewAdaptivePlanner(len(commits), config.MemBudget, perSlotGrowth, pipelineOverhead)
+	ap.SetWeights(config.CommitWeights)
 
 	// Align debug.SetMemoryLimit with the user's budget.
 	runner.MemBudget = config.MemBudget
 	runner.TCSink = config.TCSink
+	runner.SinkOnWritten = config.SinkOnWritten
+	runner.SinkFlush = config.SinkFlush
+	runner.AnalysisMetrics = config.AnalysisMetrics
+	runner.Logger = logger
 	runner.AggSpillBudget = schedule.AggSpillBudget
+	runner.TCObserver = config.TCObserver
+	runner.OnProgress = config.OnProgress
+	runner.progressTotal = len(commits)
+	runner.diskMonitor = newDiskMonitor(config, runner.SpillDir)
+
+	softLimit := streaming.NewSoftLimitWatcher(streaming.SoftLimitNotifyRatio)
+	defer softLimit.Stop()
+
+	runner.softLimitEvents = softLimit.C()
 
 	hibernatables := collectHibernatables(analyzers)
 	spillCleaners := collectSpillCleaners(analyzers)
@@ -110,6 +157,8 @@ func RunStreaming(
 
 	// Guard ensures spill temp files are cleaned up on normal exit, error, or signal.
 	spillGuard := streaming.NewSpillCleanupGuard(spillCleaners, logger)
+	spillGuard.TrackUsage(runner.SpillUsage)
+
 	defer spillGuard.Close()
 
 	cpManager := initCheckpointManager(ctx, logger, config.Checkpoint, config.RepoPath, len(analyzers), len(checkpointables))
@@ -119,22 +168,35 @@ func RunStreaming(
 	logger.InfoContext(ctx, "streaming: planning chunks",
 		"commits", len(commits), "chunks", len(chunks),
 		"buffering_factor", schedule.BufferingFactor,
-		"chunk_size", schedule.ChunkSize)
+		"chunk_size", schedule.ChunkSize,
+		"weighted", config.CommitWeights != nil,
+		"memory_class", runner.leafCapabilities().Memory.String(),
+		"skip_blobs", runner.Config.SkipBlobs, "skip_diffs", runner.Config.SkipDiffs)
 
-	startChunk, aggSpills := resolveStartChunk(ctx, logger, cpManager, checkpointables, chunks, config)
+	startChunk, resumed := resolveStartChunk(ctx, logger, cpManager, checkpointables, chunks, config)
 
-	initErr := initOrResume(runner, startChunk, aggSpills)
+	runner.Control.start(config.MemBudget, startChunk, len(chunks), cpManager != nil)
+	defer runner.Control.finish()
+
+	initErr := initOrResume(runner, startChunk, resumed)
 	if initErr != nil {
 		return nil, initErr
 	}
 
+	attachSinkJournal(ctx, logger, runner, cpManager, resumed)
+	defer runner.sinkJournal.Close()
+
 	_, err := runChunks(ctx, logger, runner, commits, chunks, useDoubleBuffer,
 		hibernatables, checkpointables, cpManager, config, startChunk, ap)
 	if err != nil {
 		return nil, err
 	}
 
+	logBlobCacheStats(ctx, logger, runner)
+
 	if cpManager != nil {
+		runner.sinkJournal.Close()
+
 		clearErr := cpManager.Clear()
 		if clearErr != nil {
 			logger.WarnContext(ctx, "failed to clear checkpoint after completion", "error", clearErr)
@@ -164,6 +226,9 @@ func RunStreamingFromIterator(
 	config StreamingConfig,
 ) (map[analyze.HistoryAnalyzer]analyze.Report, error) {
 	logger := config.logger()
+
+	runner.applyCapabilities()
+
 	pipelineOverhead := runner.Config.EstimatedOverhead()
 	workStatePerCommit, avgTCSize := splitStateGrowth(analyzers, runner.CoreCount)
 
@@ -179,25 +244,43 @@ func RunStreamingFromIterator(
 
 	growthPerCommit := aggregateStateGrowth(analyzers, runner.CoreCount)
 	ap := streaming.NewAdaptivePlanner(commitCount, config.MemBudget, growthPerCommit, pipelineOverhead)
-	chunks := schedule.Chunks
+	ap.SetWeights(config.CommitWeights)
+	chunks := streaming.BalanceChunks(schedule.Chunks, config.CommitWeights)
 
 	runner.MemBudget = config.MemBudget
 	runner.TCSink = config.TCSink
+	runner.SinkOnWritten = config.SinkOnWritten
+	runner.SinkFlush = config.SinkFlush
+	runner.AnalysisMetrics = config.AnalysisMetrics
+	runner.Logger = logger
 	runner.AggSpillBudget = schedule.AggSpillBudget
+	runner.TCObserver = config.TCObserver
+	runner.OnProgress = config.OnProgress
+	runner.progressTotal = commitCount
+	runner.diskMonitor = newDiskMonitor(config, runner.SpillDir)
+
+	softLimit := streaming.NewSoftLimitWatcher(streaming.SoftLimitNotifyRatio)
+	defer softLimit.Stop()
+
+	runner.softLimitEvents = softLimit.C()
 
 	hibernatables := collectHibernatables(analyzers)
 	spillCleaners := collectSpillCleaners(analyzers)
 	checkpointables := collectCheckpointables(analyzers)
 
 	spillGuard := streaming.NewSpillCleanupGuard(spillCleaners, logger)
+	spillGuard.TrackUsage(runner.SpillUsage)
+
 	defer spillGuard.Close()
 
 	cpManager := initCheckpointManager(ctx, logger, config.Checkpoint, config.RepoPath, len(analyzers), len(checkpointables))
 
 	logger.InfoContext(ctx, "streaming: planning chunks (iterator mode)",
-		"commits", commitCount, "chunks", len(chunks))
+		"commits", commitCount, "chunks", len(chunks), "weighted", config.CommitWeights != nil,
+		"memory_class", runner.leafCapabilities().Memory.String(),
+		"skip_blobs", runner.Config.SkipBlobs, "skip_diffs", runner.Config.SkipDiffs)
 
-	startChunk, aggSpills := resolveStartChunk(ctx, logger, cpManager, checkpointables, chunks, config)
+	startChunk, resumed := resolveStartChunk(ctx, logger, cpManager, checkpointables, chunks, config)
 
 	// Skip already-processed commits in the iterator.
 	if startChunk > 0 && startChunk < len(chunks) {
@@ -208,22 +291,32 @@ func RunStreamingFromIterator(
 			logger.WarnContext(ctx, "iterator skip failed, starting fresh", "error", skipErr)
 
 			startChunk = 0
-			aggSpills = nil
+			resumed = nil
 		}
 	}
 
-	initErr := initOrResume(runner, startChunk, aggSpills)
+	runner.Control.start(config.MemBudget, startChunk, len(chunks), cpManager != nil)
+	defer runner.Control.finish()
+
+	initErr := initOrResume(runner, startChunk, resumed)
 	if initErr != nil {
 		return nil, initErr
 	}
 
+	attachSinkJournal(ctx, logger, runner, cpManager, resumed)
+	defer runner.sinkJournal.Close()
+
 	_, err := runChunksFromIterator(ctx, logger, runner, iter, commitCount,
 		chunks, hibernatables, checkpointables, cpManager, config, startChunk, ap)
 	if err != nil {
 		return nil, err
 	}
 
+	logBlobCacheStats(ctx, logger, runner)
+
 	if cpManager != nil {
+		runner.sinkJournal.Close()
+
 		clearErr := cpManager.Clear()
 		if clearErr != nil {
 			logger.WarnContext(ctx, "failed to clear checkpoint after completion", "error", clearErr)
@@ -342,6 +435,15 @@ type chunkStats struct {
 	slowestSize   int
 }
 
+// last returns the duration of the most recent chunk, or zero.
+func (s *chunkStats) last() time.Duration {
+	if len(s.chunkDurations) == 0 {
+		return 0
+	}
+
+	return s.chunkDurations[len(s.chunkDurations)-1]
+}
+
 // record updates stats with a chunk's duration.
 func (s *chunkStats) record(dur time.Duration, idx int, chunk streaming.ChunkBounds) {
 	ms := dur.Milliseconds()
@@ -378,6 +480,10 @@ func setAnalysisSpanAttributes(span trace.Span, stats chunkStats) {
 		attribute.Int64("analysis.cache.diff.hits", ps.DiffCacheHits),
 		attribute.Int64("analysis.cache.diff.misses", ps.DiffCacheMisses),
 		attribute.Float64("analysis.cache.diff.hit_pct", hitPercent(ps.DiffCacheHits, ps.DiffCacheMisses)),
+		attribute.Int64("analysis.cache.diff.deduped", ps.DiffDeduped),
+		attribute.Int64("analysis.cache.uast.hits", ps.UASTMemoHits),
+		attribute.Int64("analysis.cache.uast.misses", ps.UASTMemoMisses),
+		attribute.Float64("analysis.cache.uast.hit_pct", hitPercent(ps.UASTMemoHits, ps.UASTMemoMisses)),
 	)
 
 	if stats.count > 0 {
@@ -393,13 +499,9 @@ func setAnalysisSpanAttributes(span trace.Span, stats chunkStats) {
 // recordAnalysisMetrics records analysis-specific OTel metrics from chunk stats.
 func recordAnalysisMetrics(ctx context.Context, am *observability.AnalysisMetrics, stats chunkStats, commitCount int) {
 	am.RecordRun(ctx, observability.AnalysisStats{
-		Commits:         int64(commitCount),
-		Chunks:          stats.count,
-		ChunkDurations:  stats.chunkDurations,
-		BlobCacheHits:   stats.pipeline.BlobCacheHits,
-		BlobCacheMisses: stats.pipeline.BlobCacheMisses,
-		DiffCacheHits:   stats.pipeline.DiffCacheHits,
-		DiffCacheMisses: stats.pipeline.DiffCacheMisses,
+		Commits:        int64(commitCount),
+		Chunks:         stats.count,
+		ChunkDurations: stats.chunkDurations,
 	})
 }
 
@@ -415,6 +517,7 @@ func initCheckpointManager(
 
 	repoHash := checkpoint.RepoHash(repoPath)
 	cpManager := checkpoint.NewManager(cpConfig.Dir, repoHash)
+	cpManager.Cipher = cpConfig.Cipher
 
 	if cpConfig.ClearPrev {
 		clearErr := cpManager.Clear()
@@ -439,28 +542,30 @@ func initCheckpointManager(
 // resume if configured and available. The chunks parameter is used to validate
 // that checkpoint boundaries align with the current plan (which may differ from
 // the original plan if adaptive replanning occurred before a crash).
+// The returned state is nil when the run starts fresh.
 func resolveStartChunk(
 	ctx context.Context, logger *slog.Logger, cpManager *checkpoint.Manager,
 	checkpointables []checkpoint.Checkpointable, chunks []streaming.ChunkBounds, config StreamingConfig,
-) (int, []checkpoint.AggregatorSpillEntry) {
+) (int, *checkpoint.StreamingState) {
 	if cpManager == nil || !config.Checkpoint.Resume || !cpManager.Exists() {
 		return 0, nil
 	}
 
-	resumedChunk, processedCommits, aggSpills, err := tryResumeFromCheckpoint(
-		cpManager, checkpointables, config.RepoPath, config.AnalyzerNames)
+	state, err := tryResumeFromCheckpoint(cpManager, checkpointables, config.RepoPath, config.AnalyzerNames)
 	if err != nil {
 		logger.WarnContext(ctx, "checkpoint: resume failed, starting fresh", "error", err)
 
 		return 0, nil
 	}
 
+	resumedChunk := state.CurrentChunk + 1
+
 	// Validate that chunk boundaries align with the checkpoint.
 	if resumedChunk > 0 && resumedChunk < len(chunks) {
 		expectedStart := chunks[resumedChunk].Start
-		if expectedStart != processedCommits {
+		if expectedStart != state.ProcessedCommits {
 			logger.WarnContext(ctx, "checkpoint: chunk boundary mismatch after adaptive replan, restarting",
-				"expected_start", expectedStart, "checkpoint_processed", processedCommits)
+				"expected_start", expectedStart, "checkpoint_processed", state.ProcessedCommits)
 
 			return 0, nil
 		}
@@ -474,16 +579,43 @@ func resolveStartChunk(
 		))
 	}
 
-	return resumedChunk, aggSpills
+	return resumedChunk, state
 }
 
 // initOrResume initializes the runner for a fresh run or resumes from a checkpoint.
-func initOrResume(runner *Runner, startChunk int, aggSpills []checkpoint.AggregatorSpillEntry) error {
-	if startChunk == 0 {
+func initOrResume(runner *Runner, startChunk int, resumed *checkpoint.StreamingState) error {
+	if startChunk == 0 || resumed == nil {
 		return runner.Initialize()
 	}
 
-	return runner.InitializeForResume(aggSpills)
+	return runner.InitializeForResume(resumed.AggregatorSpills)
+}
+
+// attachSinkJournal journals TCSink output next to the checkpoint so that a
+// resumed run does not write again the records the interrupted run emitted
+// after its last checkpoint. Without a sink or checkpointing there is nothing
+// to journal.
+func attachSinkJournal(
+	ctx context.Context, logger *slog.Logger, runner *Runner,
+	cpManager *checkpoint.Manager, resumed *checkpoint.StreamingState,
+) {
+	if runner.TCSink == nil || cpManager == nil {
+		return
+	}
+
+	journal, err := checkpoint.OpenSinkJournal(cpManager.SinkJournalPath(), resumed)
+	if err != nil {
+		logger.WarnContext(ctx, "checkpoint: sink journal unavailable, resumed output may repeat records", "error", err)
+
+		return
+	}
+
+	runner.sinkJournal = journal
+	runner.sinkSeq.Store(journal.LastSeq())
+
+	if runner.SinkOnWritten != nil {
+		runner.SinkOnWritten(runner.journalSinkRecord)
+	}
 }
 
 // CanResumeWithCheckpoint returns true if all analyzers support checkpointing.
@@ -579,18 +711,18 @@ func tryResumeFromCheckpoint(
 	checkpointables []checkpoint.Checkpointable,
 	repoPath string,
 	analyzerNames []string,
-) (startChunk, processedCommits int, aggSpills []checkpoint.AggregatorSpillEntry, err error) {
+) (*checkpoint.StreamingState, error) {
 	validateErr := cpManager.Validate(repoPath, analyzerNames)
 	if validateErr != nil {
-		return 0, 0, nil, fmt.Errorf("checkpoint validation failed: %w", validateErr)
+		return nil, fmt.Errorf("checkpoint validation failed: %w", validateErr)
 	}
 
 	state, loadErr := cpManager.Load(checkpointables)
 	if loadErr != nil {
-		return 0, 0, nil, fmt.Errorf("checkpoint load failed: %w", loadErr)
+		return nil, fmt.Errorf("checkpoint load failed: %w", loadErr)
 	}
 
-	return state.CurrentChunk + 1, state.ProcessedCommits, state.AggregatorSpills, nil
+	return state, nil
 }
 
 func processChunksWithCheckpoint(
@@ -611,12 +743,26 @@ func processChunksWithCheckpoint(
 	var stats chunkStats
 
 	for i := startChunk; i < len(chunks); i++ {
+		if i > startChunk {
+			var ctrlErr error
+
+			chunks, memBudget, ctrlErr = applyControl(ctx, logger, runner, ap, chunks, i-1, memBudget)
+			if ctrlErr != nil {
+				return stats, ctrlErr
+			}
+
+			ctrlErr = niceSleep(ctx, runner, stats.last())
+			if ctrlErr != nil {
+				return stats, ctrlErr
+			}
+		}
+
 		chunk := chunks[i]
 		logger.InfoContext(ctx, "streaming: processing chunk",
 			"chunk", i+1, "total", len(chunks), "start", chunk.Start, "end", chunk.End)
 
 		if i > startChunk {
-			hibErr := hibernateAndBoot(hibernatables)
+			hibErr := hibernateAndBoot(runner.withoutDisabled(hibernatables))
 			if hibErr != nil {
 				return stats, hibErr
 			}
@@ -638,6 +784,7 @@ func processChunksWithCheckpoint(
 
 		stats.record(time.Since(start), i, chunk)
 		stats.pipeline.Add(pStats)
+		runner.reportProgress(i, chunk)
 
 		after := streaming.TakeHeapSnapshot()
 		obs := buildReplanObservation(i, chunk, before, after, aggSizeBefore, runner, chunks)
@@ -654,9 +801,14 @@ func processChunksWithCheckpoint(
 
 		chunks = newChunks
 
-		handleMemoryPressure(ctx, logger, after, memBudget)
+		handleMemoryPressure(ctx, logger, runner, after, memBudget)
 
 		saveChunkCheckpoint(ctx, logger, runner, cpManager, checkpointables, commits, chunk, chunks, i, repoPath, analyzerNames)
+
+		cpManager, err = checkDiskBudget(ctx, logger, runner, cpManager)
+		if err != nil {
+			return stats, err
+		}
 	}
 
 	return stats, nil
@@ -684,6 +836,20 @@ func processChunksFromIterator(
 	var stats chunkStats
 
 	for i := startChunk; i < len(chunks); i++ {
+		if i > startChunk {
+			var ctrlErr error
+
+			chunks, memBudget, ctrlErr = applyControl(ctx, logger, runner, ap, chunks, i-1, memBudget)
+			if ctrlErr != nil {
+				return stats, ctrlErr
+			}
+
+			ctrlErr = niceSleep(ctx, runner, stats.last())
+			if ctrlErr != nil {
+				return stats, ctrlErr
+			}
+		}
+
 		chunk := chunks[i]
 		chunkSize := chunk.End - chunk.Start
 
@@ -691,7 +857,7 @@ func processChunksFromIterator(
 			"chunk", i+1, "total", len(chunks), "start", chunk.Start, "end", chunk.End)
 
 		if i > startChunk {
-			hibErr := hibernateAndBoot(hibernatables)
+			hibErr := hibernateAndBoot(runner.withoutDisabled(hibernatables))
 			if hibErr != nil {
 				return stats, hibErr
 			}
@@ -719,6 +885,7 @@ func processChunksFromIterator(
 
 		stats.record(time.Since(start), i, chunk)
 		stats.pipeline.Add(pStats)
+		runner.reportProgress(i, chunk)
 
 		after := streaming.TakeHeapSnapshot()
 		obs := buildReplanObservation(i, chunk, before, after, aggSizeBefore, runner, chunks)
@@ -743,7 +910,12 @@ func processChunksFromIterator(
 		// Free all commits in this chunk — they are no longer needed.
 		freeCommits(chunkCommits)
 
-		handleMemoryPressure(ctx, logger, after, memBudget)
+		handleMemoryPressure(ctx, logger, runner, after, memBudget)
+
+		cpManager, err = checkDiskBudget(ctx, logger, runner, cpManager)
+		if err != nil {
+			return stats, err
+		}
 	}
 
 	return stats, nil
@@ -775,6 +947,16 @@ func freeCommits(commits []*gitlib.Commit) {
 	}
 }
 
+// maxBuffering returns the highest buffering factor the scheduler may pick.
+// Nice runs keep a single chunk in flight.
+func maxBuffering(runner *Runner) int {
+	if runner.Nice {
+		return 1
+	}
+
+	return maxStreamingBuffering
+}
+
 // doubleBufferBudgetDivisor is the factor by which available memory is divided
 // when double-buffering is active (two chunks in flight simultaneously).
 const doubleBufferBudgetDivisor = 2
@@ -789,6 +971,8 @@ type prefetchedChunk struct {
 
 // prefetchPipeline opens a fresh repo handle, runs the Coordinator pipeline
 // for the given commits, and collects all CommitData into a prefetchedChunk.
+// Per-commit errors are kept in the collected data so ProcessChunkFromData can
+// apply the Runner's commit error policy.
 // The caller does not need to close the repo; it is freed internally.
 func prefetchPipeline(
 	ctx context.Context, repoPath string, config CoordinatorConfig,
@@ -805,12 +989,6 @@ func prefetchPipeline(
 	var collected []CommitData
 
 	for cd := range dataChan {
-		if cd.Error != nil {
-			repo.Free()
-
-			return prefetchedChunk{err: cd.Error}
-		}
-
 		collected = append(collected, cd)
 	}
 
@@ -887,6 +1065,15 @@ func processChunksDoubleBuffered(
 	}
 
 	for idx := startChunk; idx < len(st.chunks); idx++ {
+		if idx > startChunk {
+			var ctrlErr error
+
+			st.chunks, st.memBudget, ctrlErr = applyControl(ctx, logger, runner, ap, st.chunks, idx-1, st.memBudget)
+			if ctrlErr != nil {
+				return stats, ctrlErr
+			}
+		}
+
 		// Save next chunk boundaries before prefetch so we can detect replan changes.
 		prefetchedNext := st.safeNextChunk(idx)
 		prefetch := st.startNextPrefetch(ctx, idx)
@@ -905,11 +1092,12 @@ func processChunksDoubleBuffered(
 
 		stats.record(dur, idx, st.chunks[idx])
 		stats.pipeline.Add(pStats)
+		st.runner.reportProgress(idx, st.chunks[idx])
 
 		after := streaming.TakeHeapSnapshot()
 		prefetch = st.replanAndDrainStale(ctx, idx, before, after, aggSizeBefore, prefetchedNext, prefetch)
 
-		handleMemoryPressure(ctx, logger, after, st.memBudget)
+		handleMemoryPressure(ctx, logger, st.runner, after, st.memBudget)
 
 		consumed, consumeDur, consumePStats, consumeErr := st.consumePrefetched(ctx, idx, prefetch)
 		if consumeErr != nil {
@@ -919,6 +1107,7 @@ func processChunksDoubleBuffered(
 		if consumed {
 			stats.record(consumeDur, idx+1, st.chunks[idx+1])
 			stats.pipeline.Add(consumePStats)
+			st.runner.reportProgress(idx+1, st.chunks[idx+1])
 
 			idx++ // Skip the prefetched chunk in the loop.
 		}
@@ -1005,7 +1194,7 @@ func (st *doubleBufferState) processCurrentChunk(ctx context.Context, idx, start
 		"chunk", idx+1, "total", len(st.chunks), "start", chunk.Start, "end", chunk.End)
 
 	if idx > startChunk {
-		hibErr := hibernateAndBoot(st.hibernatables)
+		hibErr := hibernateAndBoot(st.runner.withoutDisabled(st.hibernatables))
 		if hibErr != nil {
 			return 0, PipelineStats{}, hibErr
 		}
@@ -1025,6 +1214,13 @@ func (st *doubleBufferState) processCurrentChunk(ctx context.Context, idx, start
 		st.commits, chunk, st.chunks, idx, st.repoPath, st.analyzerNames,
 	)
 
+	var diskErr error
+
+	st.cpManager, diskErr = checkDiskBudget(ctx, st.logger, st.runner, st.cpManager)
+	if diskErr != nil {
+		return 0, PipelineStats{}, diskErr
+	}
+
 	return dur, pStats, nil
 }
 
@@ -1037,6 +1233,14 @@ func (st *doubleBufferState) consumePrefetched(
 		return false, 0, PipelineStats{}, nil
 	}
 
+	// Leave the chunk to the next loop iteration, which passes the control
+	// boundary first and may re-plan it.
+	if st.runner.Control.pending() {
+		drainPrefetch(prefetch)
+
+		return false, 0, PipelineStats{}, nil
+	}
+
 	pf := <-prefetch
 
 	nextIdx := idx + 1
@@ -1049,14 +1253,14 @@ func (st *doubleBufferState) consumePrefetched(
 	st.logger.InfoContext(ctx, "streaming[db]: consuming prefetched chunk",
 		"chunk", nextIdx+1, "total", len(st.chunks), "start", nextChunk.Start, "end", nextChunk.End)
 
-	hibErr := hibernateAndBoot(st.hibernatables)
+	hibErr := hibernateAndBoot(st.runner.withoutDisabled(st.hibernatables))
 	if hibErr != nil {
 		return false, 0, PipelineStats{}, hibErr
 	}
 
 	start := time.Now()
 
-	_, processErr := st.runner.ProcessChunkFromData(ctx, pf.data, nextChunk.Start, nextIdx)
+	processErr := st.runner.processPrefetched(ctx, pf.data, pf.stats, nextChunk.Start, nextIdx)
 	if processErr != nil {
 		return false, 0, PipelineStats{}, fmt.Errorf("chunk %d failed: %w", nextIdx+1, processErr)
 	}
@@ -1068,7 +1272,13 @@ func (st *doubleBufferState) consumePrefetched(
 		nextChunk, st.chunks, nextIdx, st.repoPath, st.analyzerNames,
 	)
 
-	// Use prefetch pipeline stats since ProcessChunkFromData returns zero stats.
+	var diskErr error
+
+	st.cpManager, diskErr = checkDiskBudget(ctx, st.logger, st.runner, st.cpManager)
+	if diskErr != nil {
+		return false, 0, PipelineStats{}, diskErr
+	}
+
 	return true, dur, pf.stats, nil
 }
 
@@ -1106,7 +1316,7 @@ func saveChunkCheckpoint(
 	repoPath string,
 	analyzerNames []string,
 ) {
-	if cpManager == nil || chunkIdx >= len(chunks)-1 {
+	if cpManager == nil || chunkIdx >= len(chunks)-1 || skipCheckpointOfDisabled(ctx, logger, runner) {
 		return
 	}
 
@@ -1118,6 +1328,7 @@ func saveChunkCheckpoint(
 
 	chunkCommits := commits[chunk.Start:chunk.End]
 	lastCommit := chunkCommits[len(chunkCommits)-1]
+	sinkLastCommits, sinkSeq := sinkCheckpointState(runner)
 
 	state := checkpoint.StreamingState{
 		TotalCommits:     len(commits),
@@ -1126,12 +1337,16 @@ func saveChunkCheckpoint(
 		TotalChunks:      len(chunks),
 		LastCommitHash:   lastCommit.Hash().String(),
 		AggregatorSpills: runner.AggregatorSpills(),
+		SinkLastCommits:  sinkLastCommits,
+		SinkSeq:          sinkSeq,
 	}
 
 	saveErr := cpManager.Save(checkpointables, state, repoPath, analyzerNames)
 	if saveErr != nil {
 		logger.WarnContext(ctx, "failed to save checkpoint", "error", saveErr)
 	} else {
+		resetSinkJournal(ctx, logger, runner)
+		runner.Control.checkpointSaved(chunkIdx, chunk.End)
 		logger.InfoContext(ctx, "checkpoint: saved", "chunk", chunkIdx+1)
 
 		trace.SpanFromContext(ctx).AddEvent("checkpoint.saved", trace.WithAttributes(
@@ -1156,7 +1371,7 @@ func saveIteratorCheckpoint(
 	repoPath string,
 	analyzerNames []string,
 ) {
-	if cpManager == nil || chunkIdx >= len(chunks)-1 {
+	if cpManager == nil || chunkIdx >= len(chunks)-1 || skipCheckpointOfDisabled(ctx, logger, runner) {
 		return
 	}
 
@@ -1166,6 +1381,7 @@ func saveIteratorCheckpoint(
 	}
 
 	lastCommit := chunkCommits[len(chunkCommits)-1]
+	sinkLastCommits, sinkSeq := sinkCheckpointState(runner)
 
 	state := checkpoint.StreamingState{
 		TotalCommits:     totalCommits,
@@ -1174,12 +1390,16 @@ func saveIteratorCheckpoint(
 		TotalChunks:      len(chunks),
 		LastCommitHash:   lastCommit.Hash().String(),
 		AggregatorSpills: runner.AggregatorSpills(),
+		SinkLastCommits:  sinkLastCommits,
+		SinkSeq:          sinkSeq,
 	}
 
 	saveErr := cpManager.Save(checkpointables, state, repoPath, analyzerNames)
 	if saveErr != nil {
 		logger.WarnContext(ctx, "failed to save checkpoint", "error", saveErr)
 	} else {
+		resetSinkJournal(ctx, logger, runner)
+		runner.Control.checkpointSaved(chunkIdx, chunk.End)
 		logger.InfoContext(ctx, "checkpoint: saved", "chunk", chunkIdx+1)
 
 		trace.SpanFromContext(ctx).AddEvent("checkpoint.saved", trace.WithAttributes(
@@ -1188,6 +1408,133 @@ func saveIteratorCheckpoint(
 	}
 }
 
+// newDiskMonitor returns the disk monitor for config, or nil when no disk
+// budget is set. The spill and checkpoint dirs are checked for free space
+// from the first chunk, before any spill directory exists.
+func newDiskMonitor(config StreamingConfig, spillDir string) *streaming.DiskMonitor {
+	if config.DiskBudget <= 0 {
+		return nil
+	}
+
+	if spillDir == "" {
+		spillDir = os.TempDir()
+	}
+
+	roots := []string{spillDir}
+
+	if config.Checkpoint.Enabled {
+		roots = append(roots, checkpointRoot(config.Checkpoint.Dir))
+	}
+
+	return &streaming.DiskMonitor{Budget: config.DiskBudget, Roots: roots}
+}
+
+// checkpointRoot returns the closest existing ancestor of the checkpoint
+// directory, which may not have been created yet.
+func checkpointRoot(dir string) string {
+	if dir == "" {
+		dir = checkpoint.DefaultDir()
+	}
+
+	for {
+		_, err := os.Stat(dir)
+		if err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+
+		dir = parent
+	}
+}
+
+// checkDiskBudget checks spill and checkpoint disk usage after a chunk. When
+// the budget is exceeded while a checkpoint exists, the checkpoint is evicted
+// first: it only speeds up a restart, while spill files are needed to finish
+// the run. Returns the checkpoint manager to use for the remaining chunks,
+// nil once the checkpoint has been evicted.
+func checkDiskBudget(
+	ctx context.Context, logger *slog.Logger, runner *Runner, cpManager *checkpoint.Manager,
+) (*checkpoint.Manager, error) {
+	if runner.diskMonitor == nil {
+		return cpManager, nil
+	}
+
+	status, err := runner.diskMonitor.Check(diskUsage(runner, cpManager))
+	if errors.Is(err, streaming.ErrDiskBudgetExceeded) && cpManager != nil {
+		clearErr := cpManager.Clear()
+		if clearErr == nil {
+			logger.WarnContext(ctx, "streaming: disk budget exceeded, evicted checkpoint; the run can no longer resume",
+				"used_mib", status.Total/streaming.MiB, "budget_mib", runner.diskMonitor.Budget/streaming.MiB)
+
+			cpManager = nil
+			runner.Control.disableCheckpointing()
+			status, err = runner.diskMonitor.Check(diskUsage(runner, nil))
+		}
+	}
+
+	if err != nil {
+		return cpManager, err
+	}
+
+	if status.Warning {
+		logger.WarnContext(ctx, "streaming: disk budget warning",
+			"used_mib", status.Total/streaming.MiB,
+			"growth_mib", status.Growth/streaming.MiB,
+			"budget_mib", runner.diskMonitor.Budget/streaming.MiB)
+	}
+
+	return cpManager, nil
+}
+
+// diskUsage returns the spill usage of every analyzer plus the checkpoint.
+func diskUsage(runner *Runner, cpManager *checkpoint.Manager) []streaming.DiskUsage {
+	usage := runner.SpillUsage()
+
+	if cpManager != nil {
+		usage = append(usage, streaming.DiskUsage{Owner: "checkpoint", Dirs: []string{cpManager.CheckpointDir()}})
+	}
+
+	return usage
+}
+
+// skipCheckpointOfDisabled reports whether checkpoints are off because an
+// analyzer was disabled after a stuck Consume, which may still be writing the
+// state a checkpoint would save.
+func skipCheckpointOfDisabled(ctx context.Context, logger *slog.Logger, runner *Runner) bool {
+	disabled := runner.DisabledAnalyzers()
+	if len(disabled) == 0 {
+		return false
+	}
+
+	logger.WarnContext(ctx, "checkpoint: skipped, analyzers disabled after a stuck commit", "analyzers", disabled)
+
+	return true
+}
+
+// sinkCheckpointState waits for a buffered TCSink to write out its queue and
+// returns the sink journal position a checkpoint saves. Without the flush,
+// queued records would be missing from the checkpoint, and their late
+// confirmations would land in the journal after its reset.
+func sinkCheckpointState(runner *Runner) (lastCommits map[string]string, seq uint64) {
+	if runner.SinkFlush != nil && runner.sinkJournal != nil {
+		runner.SinkFlush()
+	}
+
+	return runner.sinkJournal.LastCommits(), runner.sinkJournal.LastSeq()
+}
+
+// resetSinkJournal drops the journal entries covered by a just-saved checkpoint.
+func resetSinkJournal(ctx context.Context, logger *slog.Logger, runner *Runner) {
+	resetErr := runner.sinkJournal.Reset()
+	if resetErr != nil {
+		logger.WarnContext(ctx, "failed to reset sink journal", "error", resetErr)
+	}
+}
+
 // dominantStage returns the name of the pipeline stage with the longest duration.
 func dominantStage(ps PipelineStats) string {
 	switch {
@@ -1288,26 +1635,32 @@ const percentScale = 100.0
 // handleMemoryPressure checks post-chunk heap usage against the budget and
 // takes corrective action. At warning level (>80%), it logs a warning. At
 // critical level (>90%), it forces an immediate GC + FreeOSMemory to reclaim
-// memory before the next chunk starts.
+// memory before the next chunk starts. Nice runs also shrink their caches at
+// either level.
 func handleMemoryPressure(
-	ctx context.Context, logger *slog.Logger,
+	ctx context.Context, logger *slog.Logger, runner *Runner,
 	snapshot streaming.HeapSnapshot, memBudget int64,
 ) {
 	pressure := streaming.CheckMemoryPressure(snapshot.HeapInuse, memBudget)
 
+	if runner.Nice && pressure != streaming.PressureNone {
+		runner.shedCaches(ctx, logger)
+	}
+
 	switch pressure {
 	case streaming.PressureCritical:
 		logger.WarnContext(ctx, "streaming: memory pressure critical, forcing GC",
 			"heap_mib", snapshot.HeapInuse/streaming.MiB,
+			"rss_mib", readRSSMiB(),
 			"budget_mib", memBudget/streaming.MiB,
 			"usage_pct", float64(snapshot.HeapInuse)*percentScale/float64(memBudget))
 
-		runtime.GC()
-		debug.FreeOSMemory()
+		releaseMemory()
 
 	case streaming.PressureWarning:
 		logger.WarnContext(ctx, "streaming: memory pressure warning",
 			"heap_mib", snapshot.HeapInuse/streaming.MiB,
+			"rss_mib", readRSSMiB(),
 			"budget_mib", memBudget/streaming.MiB,
 			"usage_pct", float64(snapshot.HeapInuse)*percentScale/float64(memBudget))
 
@@ -1316,6 +1669,19 @@ func handleMemoryPressure(
 	}
 }
 
+// releaseMemory collects the Go heap and returns free pages from both the Go
+// runtime and the native allocator to the OS.
+func releaseMemory() {
+	runtime.GC()
+	debug.FreeOSMemory()
+	gitlib.ReleaseNativeMemory()
+}
+
+// readRSSMiB returns the process resident set size in MiB (0 if unavailable).
+func readRSSMiB() uint64 {
+	return gitlib.ProcessRSSBytes() / uint64(streaming.MiB)
+}
+
 func hibernateAndBoot(hibernatables []streaming.Hibernatable) error {
 	for _, h := range hibernatables {
 		err := h.Hibernate()
@@ -1327,8 +1693,7 @@ func hibernateAndBoot(hibernatables []streaming.Hibernatable) error {
 	// Force GC to collect memory freed by Hibernate/Spill, then release it
 	// back to the OS. Without this, Go retains freed heap pages and RSS stays
 	// high even after spilling data to disk.
-	runtime.GC()
-	debug.FreeOSMemory()
+	releaseMemory()
 
 	for _, h := range hibernatables {
 		err := h.Boot()
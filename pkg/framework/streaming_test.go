@@ -4,9 +4,12 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/checkpoint"
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
 	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
 )
@@ -207,6 +210,61 @@ func TestProcessChunksDoubleBuffered_IdenticalOutput(t *testing.T) {
 	}
 }
 
+func TestProcessChunksDoubleBuffered_ReportsProgress(t *testing.T) {
+	t.Parallel()
+
+	repo := NewTestRepo(t)
+	defer repo.Close()
+
+	repo.CreateFile("a.txt", "a")
+	repo.Commit("c1")
+	repo.CreateFile("b.txt", "b")
+	repo.Commit("c2")
+	repo.CreateFile("c.txt", "c")
+	repo.Commit("c3")
+
+	libRepo, err := gitlib.OpenRepository(repo.Path())
+	if err != nil {
+		t.Fatalf("OpenRepository: %v", err)
+	}
+	defer libRepo.Free()
+
+	commits := CollectCommits(t, libRepo, 0)
+	chunks := []streaming.ChunkBounds{
+		{Start: 0, End: 1},
+		{Start: 1, End: len(commits)},
+	}
+
+	var progress []Progress
+
+	runner := NewRunnerWithConfig(libRepo, repo.Path(), DefaultCoordinatorConfig(), &plumbing.TreeDiffAnalyzer{})
+	runner.OnProgress = func(p Progress) { progress = append(progress, p) }
+	runner.progressTotal = len(commits)
+
+	initErr := runner.Initialize()
+	if initErr != nil {
+		t.Fatalf("Initialize: %v", initErr)
+	}
+
+	_, dbErr := processChunksDoubleBuffered(
+		context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)),
+		runner, commits, chunks, nil, nil, nil, repo.Path(), nil, 0,
+		streaming.NewAdaptivePlanner(len(commits), 0, 0, 0), 0,
+	)
+	if dbErr != nil {
+		t.Fatalf("processChunksDoubleBuffered: %v", dbErr)
+	}
+
+	want := []Progress{
+		{Chunk: 0, Commits: 1, TotalCommits: len(commits)},
+		{Chunk: 1, Commits: len(commits), TotalCommits: len(commits)},
+	}
+
+	if len(progress) != len(want) || progress[0] != want[0] || progress[1] != want[1] {
+		t.Fatalf("progress = %+v, want %+v", progress, want)
+	}
+}
+
 func TestCanResumeWithCheckpoint(t *testing.T) {
 	t.Parallel()
 
@@ -295,3 +353,51 @@ func TestStreamingConfig_LoggerFallback(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckDiskBudget_EvictsCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	cpManager := checkpoint.NewManager(t.TempDir(), "repo")
+	cpDir := cpManager.CheckpointDir()
+
+	err := os.MkdirAll(cpDir, 0o750)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(cpDir, "state.bin"), make([]byte, 200), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &Runner{diskMonitor: &streaming.DiskMonitor{
+		Budget:    100,
+		FreeSpace: func(string) (int64, bool) { return 0, false },
+	}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	got, err := checkDiskBudget(context.Background(), logger, runner, cpManager)
+	if err != nil {
+		t.Fatalf("checkDiskBudget() error = %v, want checkpoint eviction", err)
+	}
+
+	if got != nil {
+		t.Fatal("checkDiskBudget() should drop the evicted checkpoint manager")
+	}
+
+	_, statErr := os.Stat(cpDir)
+	if !os.IsNotExist(statErr) {
+		t.Fatalf("checkpoint dir should be removed, stat error = %v", statErr)
+	}
+}
+
+func TestCheckDiskBudget_NoMonitor(t *testing.T) {
+	t.Parallel()
+
+	cpManager := checkpoint.NewManager(t.TempDir(), "repo")
+
+	got, err := checkDiskBudget(context.Background(), slog.Default(), &Runner{}, cpManager)
+	if err != nil || got != cpManager {
+		t.Fatalf("checkDiskBudget() = %v, %v; want the manager unchanged", got, err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build darwin
+
+package framework
+
+import "golang.org/x/sys/unix"
+
+// detectTotalMemoryBytes returns physical RAM from the hw.memsize sysctl, or 0 on failure.
+func detectTotalMemoryBytes() uint64 {
+	total, err := unix.SysctlUint64("hw.memsize")
+	if err != nil {
+		return 0
+	}
+
+	return total
+}
@@ -0,0 +1,161 @@
+//go:build linux
+
+package framework
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procMemInfoPath is the Linux kernel's memory summary.
+const procMemInfoPath = "/proc/meminfo"
+
+// Locations of the cgroup hierarchy, relative to the filesystem root.
+const (
+	procSelfCgroupPath = "proc/self/cgroup"
+	cgroupMountPath    = "sys/fs/cgroup"
+
+	// cgroupHybridUnifiedDir is where hybrid v1/v2 hosts mount the v2
+	// hierarchy, under cgroupMountPath.
+	cgroupHybridUnifiedDir = "unified"
+
+	// cgroupV2Marker exists at the root of every v2 hierarchy.
+	cgroupV2Marker = "cgroup.controllers"
+
+	// cgroupV1MemoryController is the name of the v1 memory controller, both
+	// in /proc/self/cgroup and as the subdirectory of its hierarchy.
+	cgroupV1MemoryController = "memory"
+)
+
+// Memory limit files of the cgroup hierarchy.
+var (
+	cgroupV2LimitFiles = []string{"memory.max", "memory.high"}
+	cgroupV1LimitFiles = []string{"memory.limit_in_bytes"}
+)
+
+// detectTotalMemoryBytes returns the memory available to the process: physical
+// RAM from /proc/meminfo, lowered to the cgroup memory limit when the process
+// runs in a container or another memory-limited cgroup. Returns 0 on failure.
+func detectTotalMemoryBytes() uint64 {
+	memInfoBytes, err := os.ReadFile(procMemInfoPath)
+	if err != nil {
+		return 0
+	}
+
+	total := parseMemTotalBytes(memInfoBytes)
+
+	limit := cgroupMemoryLimit("/")
+	if limit > 0 && (total == 0 || limit < total) {
+		return limit
+	}
+
+	return total
+}
+
+// cgroupMemoryLimit returns the lowest memory limit of the cgroup of the
+// process and its ancestors, under the filesystem root, or 0 when there is
+// none. Both cgroup v2 (memory.max, memory.high) and v1
+// (memory.limit_in_bytes) are read.
+func cgroupMemoryLimit(root string) uint64 {
+	data, err := os.ReadFile(filepath.Join(root, procSelfCgroupPath))
+	if err != nil {
+		return 0
+	}
+
+	var limit uint64
+
+	for line := range bytes.SplitSeq(data, []byte{'\n'}) {
+		// Each line is "hierarchy-ID:controller-list:cgroup-path".
+		parts := strings.SplitN(string(line), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		var dir string
+
+		var files []string
+
+		switch {
+		case parts[0] == "0" && parts[1] == "":
+			dir, files = cgroupV2Dir(root), cgroupV2LimitFiles
+		case hasController(parts[1], cgroupV1MemoryController):
+			dir, files = filepath.Join(root, cgroupMountPath, cgroupV1MemoryController), cgroupV1LimitFiles
+		default:
+			continue
+		}
+
+		limit = lowerLimit(limit, hierarchyLimit(dir, parts[2], files))
+	}
+
+	return limit
+}
+
+// cgroupV2Dir returns the mount point of the v2 hierarchy: the cgroup mount
+// itself on unified hosts, its "unified" subdirectory on hybrid ones.
+func cgroupV2Dir(root string) string {
+	dir := filepath.Join(root, cgroupMountPath)
+
+	_, err := os.Stat(filepath.Join(dir, cgroupV2Marker))
+	if err != nil {
+		return filepath.Join(dir, cgroupHybridUnifiedDir)
+	}
+
+	return dir
+}
+
+func hasController(list, controller string) bool {
+	for name := range strings.SplitSeq(list, ",") {
+		if name == controller {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hierarchyLimit returns the lowest limit in files along cgroupPath, from the
+// cgroup itself up to the hierarchy mounted at dir. Inside a container the
+// cgroup path is often not visible under the mount; the walk then only finds
+// the container's own limits at the mount root.
+func hierarchyLimit(dir, cgroupPath string, files []string) uint64 {
+	var limit uint64
+
+	for p := filepath.Clean("/" + cgroupPath); ; p = filepath.Dir(p) {
+		for _, name := range files {
+			limit = lowerLimit(limit, readCgroupLimit(filepath.Join(dir, p, name)))
+		}
+
+		if p == "/" {
+			return limit
+		}
+	}
+}
+
+// readCgroupLimit parses a cgroup memory limit file. "max" (v2) and missing
+// or unreadable files mean no limit and return 0. The v1 "no limit" value is
+// a huge number, which physical RAM then undercuts.
+func readCgroupLimit(path string) uint64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return value
+}
+
+// lowerLimit returns the lower of two limits, where 0 means no limit.
+func lowerLimit(a, b uint64) uint64 {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+
+	return a
+}
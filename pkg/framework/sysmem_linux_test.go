@@ -0,0 +1,98 @@
+//go:build linux
+
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCgroupFiles creates files under root, keyed by their path relative to it.
+func writeCgroupFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+}
+
+func TestCgroupMemoryLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  uint64
+	}{
+		{
+			name: "v2 container with cgroup namespace",
+			files: map[string]string{
+				"proc/self/cgroup":                 "0::/\n",
+				"sys/fs/cgroup/cgroup.controllers": "memory\n",
+				"sys/fs/cgroup/memory.max":         "2147483648\n",
+				"sys/fs/cgroup/memory.high":        "max\n",
+			},
+			want: 2 << 30,
+		},
+		{
+			name: "v2 nested limits take the lowest",
+			files: map[string]string{
+				"proc/self/cgroup":                            "0::/kubepods/pod1/ctr\n",
+				"sys/fs/cgroup/cgroup.controllers":            "memory\n",
+				"sys/fs/cgroup/kubepods/memory.max":           "8589934592\n",
+				"sys/fs/cgroup/kubepods/pod1/memory.max":      "1073741824\n",
+				"sys/fs/cgroup/kubepods/pod1/ctr/memory.max":  "max\n",
+				"sys/fs/cgroup/kubepods/pod1/ctr/memory.high": "4294967296\n",
+			},
+			want: 1 << 30,
+		},
+		{
+			name: "v2 unlimited",
+			files: map[string]string{
+				"proc/self/cgroup":                    "0::/user.slice\n",
+				"sys/fs/cgroup/cgroup.controllers":    "memory\n",
+				"sys/fs/cgroup/user.slice/memory.max": "max\n",
+			},
+			want: 0,
+		},
+		{
+			name: "v1 container without cgroup namespace",
+			files: map[string]string{
+				"proc/self/cgroup":                           "5:cpu,cpuacct:/docker/abc\n4:memory:/docker/abc\n0::/\n",
+				"sys/fs/cgroup/memory/memory.limit_in_bytes": "536870912\n",
+			},
+			want: 512 << 20,
+		},
+		{
+			name: "hybrid host reads the unified hierarchy",
+			files: map[string]string{
+				"proc/self/cgroup":                           "4:memory:/\n0::/job\n",
+				"sys/fs/cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n",
+				"sys/fs/cgroup/unified/job/memory.max":       "3221225472\n",
+			},
+			want: 3 << 30,
+		},
+		{
+			name:  "no cgroup file",
+			files: map[string]string{},
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			writeCgroupFiles(t, root, tt.files)
+
+			assert.Equal(t, tt.want, cgroupMemoryLimit(root))
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package framework
+
+// detectTotalMemoryBytes is unsupported on this platform; callers fall back
+// to fixed defaults when it returns 0.
+func detectTotalMemoryBytes() uint64 {
+	return 0
+}
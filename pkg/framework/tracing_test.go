@@ -218,3 +218,61 @@ func attrMap(stub tracetest.SpanStub) map[string]any {
 
 	return m
 }
+
+// TestRunner_AnalyzerSpanAttributes verifies that every leaf analyzer gets a
+// child span of the chunk span carrying its commit count and duration.
+func TestRunner_AnalyzerSpanAttributes(t *testing.T) {
+	t.Parallel()
+
+	exporter, tracer := newTestProvider(t)
+
+	repo := framework.NewTestRepo(t)
+	defer repo.Close()
+
+	repo.CreateFile("c.txt", "one")
+	repo.Commit("first")
+	repo.CreateFile("c.txt", "two")
+	repo.Commit("second")
+
+	libRepo, err := gitlib.OpenRepository(repo.Path())
+	require.NoError(t, err)
+
+	defer libRepo.Free()
+
+	commits := framework.CollectCommits(t, libRepo, 0)
+	require.Len(t, commits, 2)
+
+	config := framework.DefaultCoordinatorConfig()
+	config.UASTPipelineWorkers = 0
+
+	treeDiff := &plumbing.TreeDiffAnalyzer{}
+	runner := framework.NewRunnerWithConfig(libRepo, repo.Path(), config, treeDiff)
+	runner.Tracer = tracer
+
+	_, err = runner.Run(context.Background(), commits)
+	require.NoError(t, err)
+
+	var chunkStub, analyzerStub *tracetest.SpanStub
+
+	spans := exporter.GetSpans()
+	for i := range spans {
+		switch spans[i].Name {
+		case "codefang.chunk":
+			chunkStub = &spans[i]
+		case "codefang.analyzer." + treeDiff.Name():
+			analyzerStub = &spans[i]
+		}
+	}
+
+	require.NotNil(t, chunkStub, "chunk span should exist")
+	require.NotNil(t, analyzerStub, "analyzer span should exist")
+
+	assert.Equal(t, chunkStub.SpanContext.SpanID(), analyzerStub.Parent.SpanID(),
+		"analyzer span should be child of chunk span")
+
+	attrs := attrMap(*analyzerStub)
+	assert.Equal(t, treeDiff.Name(), attrs["analyzer.name"])
+	assert.Equal(t, int64(0), attrs["chunk.index"])
+	assert.Equal(t, int64(2), attrs["analyzer.commits"])
+	assert.Contains(t, attrs, "analyzer.duration_ms")
+}
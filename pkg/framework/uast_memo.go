@@ -0,0 +1,222 @@
+package framework
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// DefaultUASTMemoSize is the default maximum number of parsed blobs the UAST
+// pipeline keeps per chunk.
+const DefaultUASTMemoSize = 256
+
+// uastMemoKey identifies a parse: the parser picks the language from the
+// file name, so the same blob under another name parses on its own.
+type uastMemoKey struct {
+	hash     gitlib.Hash
+	filename string
+}
+
+// uastMemoEntry holds the master tree of a parsed blob. Consumers get clones:
+// every commit releases its trees to the node pools once its leaves are done.
+type uastMemoEntry struct {
+	// done is closed once the parse finished, successfully or not.
+	done chan struct{}
+
+	// mu guards tree between cloning and eviction.
+	mu   sync.RWMutex
+	tree *node.Node
+	// evicted marks an entry whose tree was released; a nil tree with
+	// evicted false is a blob that does not parse.
+	evicted bool
+
+	prev, next *uastMemoEntry
+	key        uastMemoKey
+}
+
+// uastMemo memoizes the parses of the UAST pipeline, so that a blob touched
+// by several commits of a chunk, such as the new side of one change and the
+// old side of the next, is parsed once. Concurrent parses of the same blob
+// wait for the first one. The least recently used trees are released past
+// maxEntries.
+type uastMemo struct {
+	mu         sync.Mutex
+	entries    map[uastMemoKey]*uastMemoEntry
+	head, tail *uastMemoEntry
+	maxEntries int
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// newUASTMemo creates a memo of at most maxEntries parsed blobs.
+func newUASTMemo(maxEntries int) *uastMemo {
+	if maxEntries <= 0 {
+		maxEntries = DefaultUASTMemoSize
+	}
+
+	return &uastMemo{
+		entries:    make(map[uastMemoKey]*uastMemoEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+// parse returns a tree of the blob hash under filename that the caller owns,
+// calling parseFn only when no parse of the blob is memoized or in flight.
+func (m *uastMemo) parse(
+	ctx context.Context, hash gitlib.Hash, filename string, parseFn func() *node.Node,
+) *node.Node {
+	key := uastMemoKey{hash: hash, filename: filename}
+
+	m.mu.Lock()
+
+	entry, found := m.entries[key]
+	if found {
+		m.moveToFront(entry)
+		m.mu.Unlock()
+		m.hits.Add(1)
+
+		return m.cloneOf(ctx, entry, parseFn)
+	}
+
+	entry = &uastMemoEntry{key: key, done: make(chan struct{})}
+	m.entries[key] = entry
+	m.pushFront(entry)
+	evicted := m.evictOverflow()
+	m.mu.Unlock()
+	m.misses.Add(1)
+
+	releaseEntries(evicted)
+
+	tree := parseFn()
+
+	entry.mu.Lock()
+	entry.tree = tree
+	entry.mu.Unlock()
+	close(entry.done)
+
+	return tree.Clone()
+}
+
+// cloneOf waits for the parse of entry and clones its tree. It parses the
+// blob itself when the tree was evicted in the meantime.
+func (m *uastMemo) cloneOf(ctx context.Context, entry *uastMemoEntry, parseFn func() *node.Node) *node.Node {
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return nil
+	}
+
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+
+	if entry.evicted {
+		return parseFn()
+	}
+
+	return entry.tree.Clone()
+}
+
+// Release releases every memoized tree.
+func (m *uastMemo) Release() {
+	m.mu.Lock()
+
+	entries := make([]*uastMemoEntry, 0, len(m.entries))
+	for _, entry := range m.entries {
+		entries = append(entries, entry)
+	}
+
+	clear(m.entries)
+	m.head, m.tail = nil, nil
+	m.mu.Unlock()
+
+	releaseEntries(entries)
+}
+
+// CacheHits returns the number of parses served from the memo.
+func (m *uastMemo) CacheHits() int64 {
+	return m.hits.Load()
+}
+
+// CacheMisses returns the number of blobs parsed.
+func (m *uastMemo) CacheMisses() int64 {
+	return m.misses.Load()
+}
+
+// evictOverflow unlinks the least recently used entries past maxEntries and
+// returns them. Caller must hold m.mu.
+func (m *uastMemo) evictOverflow() []*uastMemoEntry {
+	var evicted []*uastMemoEntry
+
+	for len(m.entries) > m.maxEntries && m.tail != nil {
+		entry := m.tail
+		m.unlink(entry)
+		delete(m.entries, entry.key)
+
+		evicted = append(evicted, entry)
+	}
+
+	return evicted
+}
+
+// releaseEntries releases the trees of entries, waiting for their parses and
+// for the clones in progress.
+func releaseEntries(entries []*uastMemoEntry) {
+	for _, entry := range entries {
+		go func() {
+			<-entry.done
+
+			entry.mu.Lock()
+			node.ReleaseTree(entry.tree)
+			entry.tree = nil
+			entry.evicted = true
+			entry.mu.Unlock()
+		}()
+	}
+}
+
+// pushFront links entry as the most recently used. Caller must hold m.mu.
+func (m *uastMemo) pushFront(entry *uastMemoEntry) {
+	entry.prev = nil
+	entry.next = m.head
+
+	if m.head != nil {
+		m.head.prev = entry
+	}
+
+	m.head = entry
+
+	if m.tail == nil {
+		m.tail = entry
+	}
+}
+
+// moveToFront marks entry as the most recently used. Caller must hold m.mu.
+func (m *uastMemo) moveToFront(entry *uastMemoEntry) {
+	if m.head == entry {
+		return
+	}
+
+	m.unlink(entry)
+	m.pushFront(entry)
+}
+
+// unlink removes entry from the recency list. Caller must hold m.mu.
+func (m *uastMemo) unlink(entry *uastMemoEntry) {
+	if entry.prev != nil {
+		entry.prev.next = entry.next
+	} else {
+		m.head = entry.next
+	}
+
+	if entry.next != nil {
+		entry.next.prev = entry.prev
+	} else {
+		m.tail = entry.prev
+	}
+
+	entry.prev, entry.next = nil, nil
+}
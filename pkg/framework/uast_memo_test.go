@@ -0,0 +1,94 @@
+package framework
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+func TestUASTMemo_ParsesEachBlobOnce(t *testing.T) {
+	t.Parallel()
+
+	memo := newUASTMemo(4)
+	hash := gitlib.Hash{0: 1}
+
+	var parses atomic.Int64
+
+	parseFn := func() *node.Node {
+		parses.Add(1)
+
+		return node.NewNodeWithToken(node.UASTFile, "main.go")
+	}
+
+	var (
+		wg    sync.WaitGroup
+		trees = make([]*node.Node, 8)
+	)
+
+	wg.Add(len(trees))
+
+	for i := range trees {
+		go func() {
+			defer wg.Done()
+
+			trees[i] = memo.parse(context.Background(), hash, "main.go", parseFn)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int64(1), parses.Load())
+	assert.Equal(t, int64(1), memo.CacheMisses())
+	assert.Equal(t, int64(len(trees)-1), memo.CacheHits())
+
+	for i, tree := range trees {
+		require.NotNil(t, tree)
+		assert.Equal(t, "main.go", tree.Token)
+
+		for _, other := range trees[:i] {
+			assert.NotSame(t, other, tree, "every consumer owns its tree")
+		}
+	}
+
+	other := memo.parse(context.Background(), hash, "main.py", parseFn)
+	require.NotNil(t, other)
+	assert.Equal(t, int64(2), parses.Load(), "the file name selects the parser")
+
+	memo.Release()
+}
+
+func TestUASTMemo_Evicts(t *testing.T) {
+	t.Parallel()
+
+	memo := newUASTMemo(1)
+
+	var parses int
+
+	parseFn := func() *node.Node {
+		parses++
+
+		return node.NewNodeWithToken(node.UASTFile, "")
+	}
+
+	memo.parse(context.Background(), gitlib.Hash{0: 1}, "a.go", parseFn)
+	memo.parse(context.Background(), gitlib.Hash{0: 2}, "a.go", parseFn)
+	memo.parse(context.Background(), gitlib.Hash{0: 1}, "a.go", parseFn)
+
+	assert.Equal(t, 3, parses, "the first blob was evicted by the second")
+	assert.Len(t, memo.entries, 1)
+
+	failed := memo.parse(context.Background(), gitlib.Hash{0: 3}, "a.go", func() *node.Node { return nil })
+	assert.Nil(t, failed)
+	assert.Nil(t, memo.parse(context.Background(), gitlib.Hash{0: 3}, "a.go", parseFn), "failed parses are memoized")
+	assert.Equal(t, 3, parses)
+
+	memo.Release()
+	assert.Empty(t, memo.entries)
+}
@@ -16,6 +16,17 @@ type UASTPipeline struct {
 	Parser     *uast.Parser
 	Workers    int
 	BufferSize int
+
+	// Remote, when set, parses supported files through a parse service
+	// instead of Parser. Parser still decides which files are supported.
+	Remote *uast.RemoteParser
+
+	// MemoSize is the number of parsed blobs kept during a Process call, so
+	// that blobs shared by several commits are parsed once. Zero disables
+	// the memo.
+	MemoSize int
+
+	memo *uastMemo
 }
 
 // NewUASTPipeline creates a new UAST pipeline stage.
@@ -48,6 +59,10 @@ type uastSlot struct {
 func (p *UASTPipeline) Process(ctx context.Context, diffs <-chan CommitData) <-chan CommitData {
 	out := make(chan CommitData, p.BufferSize)
 	slots := make(chan *uastSlot, p.BufferSize)
+
+	if p.MemoSize > 0 {
+		p.memo = newUASTMemo(p.MemoSize)
+	}
 	jobs := make(chan *uastSlot, p.BufferSize)
 
 	go p.dispatch(ctx, diffs, slots, jobs)
@@ -132,6 +147,30 @@ func (p *UASTPipeline) emit(ctx context.Context, slots <-chan *uastSlot, out cha
 	}
 
 	wg.Wait()
+
+	if p.memo != nil {
+		p.memo.Release()
+	}
+}
+
+// CacheHits returns the number of parses of the last Process call served
+// from the memo.
+func (p *UASTPipeline) CacheHits() int64 {
+	if p.memo == nil {
+		return 0
+	}
+
+	return p.memo.CacheHits()
+}
+
+// CacheMisses returns the number of blobs the last Process call parsed
+// through the memo.
+func (p *UASTPipeline) CacheMisses() int64 {
+	if p.memo == nil {
+		return 0
+	}
+
+	return p.memo.CacheMisses()
 }
 
 // intraCommitParallelThreshold is the minimum number of file changes in a commit
@@ -169,24 +208,13 @@ func (p *UASTPipeline) parseCommitSequential(
 		after := p.parseBlob(ctx, change.To.Hash, change.To.Name, cache, change.Action, false)
 
 		if before != nil || after != nil {
-			result = append(result, uast.Change{
-				Before: before,
-				After:  after,
-				Change: change,
-			})
+			result = append(result, uast.NewChange(before, after, change))
 		}
 	}
 
 	return result
 }
 
-// uastFileResult holds the result of parsing a single file change.
-type uastFileResult struct {
-	before *node.Node
-	after  *node.Node
-	change *gitlib.Change
-}
-
 // parseCommitParallel parses files in parallel within a single commit.
 // Uses a bounded goroutine pool to avoid excessive concurrency.
 func (p *UASTPipeline) parseCommitParallel(
@@ -195,7 +223,7 @@ func (p *UASTPipeline) parseCommitParallel(
 	cache map[gitlib.Hash]*gitlib.CachedBlob,
 ) []uast.Change {
 	jobs := make(chan *gitlib.Change, len(changes))
-	results := make(chan uastFileResult, len(changes))
+	results := make(chan uast.Change, len(changes))
 
 	// maxIntraCommitWorkers caps the goroutine count for parsing files within
 	// a single commit. Keeping this small avoids excessive concurrency.
@@ -216,7 +244,7 @@ func (p *UASTPipeline) parseCommitParallel(
 				after := p.parseBlob(ctx, change.To.Hash, change.To.Name, cache, change.Action, false)
 
 				if before != nil || after != nil {
-					results <- uastFileResult{before, after, change}
+					results <- uast.NewChange(before, after, change)
 				}
 			}
 		}()
@@ -231,12 +259,8 @@ func (p *UASTPipeline) parseCommitParallel(
 	close(results)
 
 	var result []uast.Change
-	for r := range results {
-		result = append(result, uast.Change{
-			Before: r.before,
-			After:  r.after,
-			Change: r.change,
-		})
+	for change := range results {
+		result = append(result, change)
 	}
 
 	return result
@@ -270,10 +294,23 @@ func (p *UASTPipeline) parseBlob(
 		return nil
 	}
 
-	parsed, err := p.Parser.Parse(ctx, filename, blob.Data)
-	if err != nil {
-		return nil
+	parse := p.Parser.Parse
+	if p.Remote != nil {
+		parse = p.Remote.Parse
+	}
+
+	parseFn := func() *node.Node {
+		parsed, err := parse(ctx, filename, blob.Data)
+		if err != nil {
+			return nil
+		}
+
+		return parsed
+	}
+
+	if p.memo != nil {
+		return p.memo.parse(ctx, hash, filename, parseFn)
 	}
 
-	return parsed
+	return parseFn()
 }
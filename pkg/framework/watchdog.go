@@ -181,6 +181,8 @@ func (wd *Watchdog) recreateOneWorker() {
 		return
 	}
 
+	newRepo.SetTextEncoding(wd.config.TextEncoding)
+
 	// Old worker goroutine is abandoned; it will exit when CGO returns
 	// and tries to send on the closed/full response channel.
 	// Old repo handle is intentionally leaked (freeing during active CGO would crash).
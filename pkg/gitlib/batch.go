@@ -104,7 +104,7 @@ func (st *blobStreamState) flush(ctx context.Context) bool {
 
 	for i, r := range results {
 		if r.Error == nil {
-			blobs[i] = &CachedBlob{hash: r.Hash, size: r.Size, Data: r.Data}
+			blobs[i] = newCachedBlob(r.Hash, r.Size, r.Data, st.streamer.bridge.repo.textEncoding)
 		}
 	}
 
@@ -321,11 +321,7 @@ func (p *BatchProcessor) LoadBlobsAsCached(hashes []Hash) []*CachedBlob {
 
 	for i, r := range results {
 		if r.Error == nil {
-			cached[i] = &CachedBlob{
-				hash: r.Hash,
-				size: r.Size,
-				Data: r.Data,
-			}
+			cached[i] = newCachedBlob(r.Hash, r.Size, r.Data, p.bridge.repo.textEncoding)
 		}
 	}
 
@@ -22,8 +22,12 @@ const lineCountBinary = -1
 type CachedBlob struct {
 	hash Hash
 	size int64
-	// Data is the read contents of the blob object.
+	// Data is the read contents of the blob object. Text in a legacy
+	// encoding is transcoded to UTF-8 unless the TextEncoding of the
+	// repository is off; see Encoding.
 	Data []byte
+	// encoding is the encoding the blob object is stored in.
+	encoding Encoding
 	// lineCount caches the result of CountLines (-1 = binary).
 	lineCount     int
 	lineCountOnce sync.Once
@@ -32,22 +36,35 @@ type CachedBlob struct {
 	keepAlive any
 }
 
+// newCachedBlob creates a CachedBlob of the raw blob contents data,
+// transcoding them to UTF-8 as te says when they are text in another
+// encoding.
+func newCachedBlob(hash Hash, size int64, data []byte, te TextEncoding) *CachedBlob {
+	text, enc := te.transcode(data)
+
+	return &CachedBlob{hash: hash, size: size, Data: text, encoding: enc}
+}
+
 // NewCachedBlobForTest creates a CachedBlob with the given data for testing purposes.
 func NewCachedBlobForTest(data []byte) *CachedBlob {
-	return &CachedBlob{
-		hash: Hash{},
-		size: int64(len(data)),
-		Data: data,
-	}
+	return newCachedBlob(Hash{}, int64(len(data)), data, TextEncoding{})
+}
+
+// NewCachedBlobWithEncodingForTest creates a CachedBlob with the given data,
+// transcoded as te says, for testing.
+func NewCachedBlobWithEncodingForTest(data []byte, te TextEncoding) *CachedBlob {
+	return newCachedBlob(Hash{}, int64(len(data)), data, te)
 }
 
 // NewCachedBlobWithHashForTest creates a CachedBlob with the given hash and data for testing.
 func NewCachedBlobWithHashForTest(hash Hash, data []byte) *CachedBlob {
-	return &CachedBlob{
-		hash: hash,
-		size: int64(len(data)),
-		Data: data,
-	}
+	return newCachedBlob(hash, int64(len(data)), data, TextEncoding{})
+}
+
+// RestoreCachedBlob rebuilds a blob that another process loaded from its
+// hash, size, stored encoding and already transcoded data.
+func RestoreCachedBlob(hash Hash, size int64, data []byte, enc Encoding) *CachedBlob {
+	return &CachedBlob{hash: hash, size: size, Data: data, encoding: enc}
 }
 
 // NewCachedBlobFromRepo loads and caches a blob from the repository.
@@ -58,11 +75,7 @@ func NewCachedBlobFromRepo(ctx context.Context, repo *Repository, blobHash Hash)
 	}
 	defer blob.Free()
 
-	return &CachedBlob{
-		hash: blobHash,
-		size: blob.Size(),
-		Data: blob.Contents(),
-	}, nil
+	return newCachedBlob(blobHash, blob.Size(), blob.Contents(), repo.textEncoding), nil
 }
 
 // Hash returns the blob hash.
@@ -75,6 +88,16 @@ func (b *CachedBlob) Size() int64 {
 	return b.size
 }
 
+// Encoding returns the encoding the blob is stored in. Data holds UTF-8
+// whatever it is, unless the blob is binary.
+func (b *CachedBlob) Encoding() Encoding {
+	if b.encoding == "" {
+		return EncodingUTF8
+	}
+
+	return b.encoding
+}
+
 // Reader returns a reader for the blob data.
 func (b *CachedBlob) Reader() io.ReadCloser {
 	return io.NopCloser(bytes.NewReader(b.Data))
@@ -90,6 +113,7 @@ func (b *CachedBlob) Clone() *CachedBlob {
 		hash:      b.hash,
 		size:      b.size,
 		Data:      dataCopy,
+		encoding:  b.encoding,
 		lineCount: b.lineCount, // Preserve cached line count.
 		// lineCountOnce is zero value (fresh), but if lineCount is set, we might want to ensure it's not recomputed.
 		// But sync.Once cannot be easily copied in "done" state.
@@ -120,12 +144,7 @@ func (b *CachedBlob) computeLineCount() int {
 		return 0
 	}
 
-	sniff := b.Data
-	if len(sniff) > binarySniffLength {
-		sniff = sniff[:binarySniffLength]
-	}
-
-	if bytes.IndexByte(sniff, 0) >= 0 {
+	if b.IsBinary() {
 		return lineCountBinary
 	}
 
@@ -138,7 +157,9 @@ func (b *CachedBlob) computeLineCount() int {
 	return lines
 }
 
-// IsBinary returns true if the blob appears to be binary.
+// IsBinary returns true if the blob appears to be binary. Git LFS pointers
+// count as binary: their three lines stand for an asset whose lines are
+// unknown until the pointer is resolved.
 func (b *CachedBlob) IsBinary() bool {
 	if len(b.Data) == 0 {
 		return false
@@ -149,5 +170,12 @@ func (b *CachedBlob) IsBinary() bool {
 		sniff = sniff[:binarySniffLength]
 	}
 
-	return bytes.IndexByte(sniff, 0) >= 0
+	return bytes.IndexByte(sniff, 0) >= 0 || b.IsLFSPointer()
+}
+
+// IsLFSPointer reports whether the blob is a Git LFS pointer file.
+func (b *CachedBlob) IsLFSPointer() bool {
+	_, ok := ParseLFSPointer(b.Data)
+
+	return ok
 }
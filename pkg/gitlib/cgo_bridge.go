@@ -2,6 +2,7 @@ package gitlib
 
 /*
 #cgo CFLAGS: -I${SRCDIR}/clib
+#cgo windows LDFLAGS: -lpsapi
 #include "codefang_git.h"
 #include <stdlib.h>
 
@@ -21,6 +22,7 @@ import (
 func init() {
 	// Initialize C library settings
 	// This sets OMP_NUM_THREADS=1 to prevent thread oversubscription
+	// and lets libgit2 open partial clones (extensions.partialclone).
 	C.cf_init()
 }
 
@@ -38,6 +40,20 @@ func ConfigureMemoryLimits(mwindowLimit, cacheLimit int64, mallocArenaMax int) e
 	return nil
 }
 
+// ProcessRSSBytes returns the resident set size of the current process in
+// bytes, or 0 if the platform does not expose it. Works on Linux, macOS and
+// Windows.
+func ProcessRSSBytes() uint64 {
+	return uint64(C.cf_process_rss())
+}
+
+// ReleaseNativeMemory returns freed C heap pages (libgit2, blob and diff
+// buffers) to the OS. Complements debug.FreeOSMemory, which only covers the
+// Go heap. Reports whether the allocator released anything.
+func ReleaseNativeMemory() bool {
+	return C.cf_release_native_memory() != 0
+}
+
 // CGOBridge provides optimized batch operations using the C library.
 // It minimizes CGO overhead by processing multiple items per call.
 type CGOBridge struct {
@@ -106,13 +122,15 @@ type DiffResult struct {
 }
 
 // DiffRequest represents a request to diff two blobs.
+// An empty Algorithm means [DiffMyers].
 type DiffRequest struct {
-	OldHash Hash
-	NewHash Hash
-	OldData []byte
-	NewData []byte
-	HasOld  bool
-	HasNew  bool
+	OldHash   Hash
+	NewHash   Hash
+	OldData   []byte
+	NewData   []byte
+	HasOld    bool
+	HasNew    bool
+	Algorithm DiffAlgorithm
 }
 
 // BatchLoadBlobsArena loads multiple blobs into a provided arena.
@@ -337,11 +355,6 @@ func (b *CGOBridge) TreeDiff(oldTreeHash, newTreeHash Hash) (Changes, error) {
 	// Unsafe pointer arithmetic
 	cChanges := (*[1 << 30]C.cf_change)(unsafe.Pointer(cResult.changes))[:cResult.count:cResult.count]
 
-	const (
-		fileModeCommit = 0o160000
-		fileModeTree   = 0o040000
-	)
-
 	for i := range int(cResult.count) {
 		cChange := cChanges[i]
 
@@ -351,8 +364,9 @@ func (b *CGOBridge) TreeDiff(oldTreeHash, newTreeHash Hash) (Changes, error) {
 		// Exception: If one side is blob and other is not (TypeChange), we might want it?
 		// But status is usually TYPECHANGE for that.
 		// If status is MODIFIED, modes usually match or compatible (exec vs non-exec).
-		if cChange.old_mode == fileModeCommit || cChange.old_mode == fileModeTree ||
-			cChange.new_mode == fileModeCommit || cChange.new_mode == fileModeTree {
+		oldMode, newMode := uint16(cChange.old_mode), uint16(cChange.new_mode)
+		if oldMode == FileModeGitlink || oldMode == FileModeTree ||
+			newMode == FileModeGitlink || newMode == FileModeTree {
 			continue
 		}
 
@@ -365,7 +379,7 @@ func (b *CGOBridge) TreeDiff(oldTreeHash, newTreeHash Hash) (Changes, error) {
 			change.To = ChangeEntry{
 				Name: C.GoString(cChange.new_path),
 				Size: int64(cChange.new_size),
-				Mode: uint16(cChange.new_mode),
+				Mode: newMode,
 			}
 			copy(change.To.Hash[:], C.GoBytes(unsafe.Pointer(&cChange.new_oid[0]), 20))
 
@@ -374,7 +388,7 @@ func (b *CGOBridge) TreeDiff(oldTreeHash, newTreeHash Hash) (Changes, error) {
 			change.From = ChangeEntry{
 				Name: C.GoString(cChange.old_path),
 				Size: int64(cChange.old_size),
-				Mode: uint16(cChange.old_mode),
+				Mode: oldMode,
 			}
 			copy(change.From.Hash[:], C.GoBytes(unsafe.Pointer(&cChange.old_oid[0]), 20))
 
@@ -383,14 +397,14 @@ func (b *CGOBridge) TreeDiff(oldTreeHash, newTreeHash Hash) (Changes, error) {
 			change.From = ChangeEntry{
 				Name: C.GoString(cChange.old_path),
 				Size: int64(cChange.old_size),
-				Mode: uint16(cChange.old_mode),
+				Mode: oldMode,
 			}
 			copy(change.From.Hash[:], C.GoBytes(unsafe.Pointer(&cChange.old_oid[0]), 20))
 
 			change.To = ChangeEntry{
 				Name: C.GoString(cChange.new_path),
 				Size: int64(cChange.new_size),
-				Mode: uint16(cChange.new_mode),
+				Mode: newMode,
 			}
 			copy(change.To.Hash[:], C.GoBytes(unsafe.Pointer(&cChange.new_oid[0]), 20))
 		default:
@@ -403,6 +417,15 @@ func (b *CGOBridge) TreeDiff(oldTreeHash, newTreeHash Hash) (Changes, error) {
 	return changes, nil
 }
 
+// diffAlgorithmFlags maps a diff algorithm to libgit2 git_diff_option_t flags.
+func diffAlgorithmFlags(a DiffAlgorithm) C.uint {
+	if a.AnchorsUniqueLines() {
+		return C.GIT_DIFF_PATIENCE
+	}
+
+	return 0
+}
+
 // BatchDiffBlobs computes diffs for multiple blob pairs in a single CGO call.
 // This minimizes CGO overhead by processing all requests together.
 func (b *CGOBridge) BatchDiffBlobs(requests []DiffRequest) []DiffResult {
@@ -453,6 +476,7 @@ func (b *CGOBridge) BatchDiffBlobs(requests []DiffRequest) []DiffResult {
 				cRequests[i].new_size = C.size_t(len(req.NewData))
 			}
 		}
+		cRequests[i].diff_flags = diffAlgorithmFlags(req.Algorithm)
 	}
 
 	// Prepare C results
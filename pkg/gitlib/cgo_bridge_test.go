@@ -1,6 +1,7 @@
 package gitlib_test
 
 import (
+	"runtime"
 	"testing"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
@@ -47,3 +48,24 @@ func TestBlobResultError(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessRSSBytes(t *testing.T) {
+	t.Parallel()
+
+	switch runtime.GOOS {
+	case "linux", "darwin", "windows":
+		if rss := gitlib.ProcessRSSBytes(); rss == 0 {
+			t.Errorf("ProcessRSSBytes() = 0 on %s, want resident size", runtime.GOOS)
+		}
+	default:
+		t.Skipf("RSS is not reported on %s", runtime.GOOS)
+	}
+}
+
+func TestReleaseNativeMemory(t *testing.T) {
+	t.Parallel()
+
+	// The return value depends on allocator state; only verify it is safe to call repeatedly.
+	gitlib.ReleaseNativeMemory()
+	gitlib.ReleaseNativeMemory()
+}
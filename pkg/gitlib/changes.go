@@ -73,6 +73,7 @@ func TreeDiff(_ context.Context, repo *Repository, oldTree, newTree *Tree) (Chan
 				Name: delta.NewFile.Path,
 				Hash: delta.NewFile.Hash,
 				Size: delta.NewFile.Size,
+				Mode: delta.NewFile.Mode,
 			}
 		case git2go.DeltaDeleted:
 			change.Action = Delete
@@ -80,6 +81,7 @@ func TreeDiff(_ context.Context, repo *Repository, oldTree, newTree *Tree) (Chan
 				Name: delta.OldFile.Path,
 				Hash: delta.OldFile.Hash,
 				Size: delta.OldFile.Size,
+				Mode: delta.OldFile.Mode,
 			}
 		case git2go.DeltaModified, git2go.DeltaRenamed, git2go.DeltaCopied:
 			change.Action = Modify
@@ -87,11 +89,13 @@ func TreeDiff(_ context.Context, repo *Repository, oldTree, newTree *Tree) (Chan
 				Name: delta.OldFile.Path,
 				Hash: delta.OldFile.Hash,
 				Size: delta.OldFile.Size,
+				Mode: delta.OldFile.Mode,
 			}
 			change.To = ChangeEntry{
 				Name: delta.NewFile.Path,
 				Hash: delta.NewFile.Hash,
 				Size: delta.NewFile.Size,
+				Mode: delta.NewFile.Mode,
 			}
 		case git2go.DeltaUnmodified, git2go.DeltaIgnored, git2go.DeltaUntracked,
 			git2go.DeltaTypeChange, git2go.DeltaUnreadable, git2go.DeltaConflicted:
@@ -123,6 +127,7 @@ func InitialTreeChanges(_ context.Context, repo *Repository, tree *Tree) (Change
 			To: ChangeEntry{
 				Name: path,
 				Hash: entry.Hash(),
+				Mode: entry.Mode(),
 			},
 		})
 
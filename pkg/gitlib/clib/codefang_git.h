@@ -98,6 +98,7 @@ typedef struct {
     size_t new_size;        /* Size of new data */
     int has_old;            /* 1 if old_oid is valid */
     int has_new;            /* 1 if new_oid is valid */
+    unsigned int diff_flags; /* git_diff_option_t algorithm flags (e.g. GIT_DIFF_PATIENCE) */
 } cf_diff_request;
 
 /* ============================================================================
@@ -206,8 +207,8 @@ int cf_batch_diff_blobs(
  * ============================================================================ */
 
 /*
- * Initialize global library settings.
- * Should be called once at startup.
+ * Initialize global library settings, including the repository extensions
+ * libgit2 accepts. Should be called once at startup.
  */
 void cf_init();
 
@@ -220,6 +221,20 @@ void cf_init();
  */
 int cf_configure_memory(size_t mwindow_mapped_limit, size_t cache_max_size, int malloc_arena_max);
 
+/*
+ * Return the resident set size of the current process in bytes.
+ * Uses /proc/self/statm on Linux, mach task_info on macOS and
+ * GetProcessMemoryInfo on Windows. Returns 0 when unavailable.
+ */
+size_t cf_process_rss(void);
+
+/*
+ * Return freed native heap pages to the OS.
+ * Uses malloc_trim on glibc, malloc_zone_pressure_relief on macOS and
+ * _heapmin on Windows. Returns 1 if memory was released, 0 otherwise.
+ */
+int cf_release_native_memory(void);
+
 /* ============================================================================
  * Utility Functions
  * ============================================================================ */
@@ -172,6 +172,7 @@ static int compute_single_diff(
 
     /* Compute diff using libgit2 */
     git_diff_options opts = GIT_DIFF_OPTIONS_INIT;
+    opts.flags |= req->diff_flags;
     int err = git_diff_blobs(
         old_blob, NULL,
         new_blob, NULL,
@@ -349,6 +350,7 @@ static void free_preloaded_blobs(cf_preloaded_blob* blobs, int count) {
 static int compute_diff_generic(
     const char* old_data, size_t old_size,
     const char* new_data, size_t new_size,
+    unsigned int diff_flags,
     cf_diff_result* result
 ) {
     /* Initialize result */
@@ -385,7 +387,8 @@ static int compute_diff_generic(
     };
 
     git_diff_options opts = GIT_DIFF_OPTIONS_INIT;
-    
+    opts.flags |= diff_flags;
+
     int err = git_diff_buffers(
         old_data, old_size,
         NULL,  /* old_as_path */
@@ -515,7 +518,7 @@ int cf_batch_diff_blobs(
                 }
             }
 
-            if (!skip && compute_diff_generic(old_data, old_size, new_data, new_size, &results[i]) == CF_OK) {
+            if (!skip && compute_diff_generic(old_data, old_size, new_data, new_size, requests[i].diff_flags, &results[i]) == CF_OK) {
                 success_count++;
             }
         }
@@ -559,7 +562,7 @@ int cf_batch_diff_blobs(
                 }
             }
 
-            if (compute_diff_generic(old_data, old_size, new_data, new_size, &results[i]) == CF_OK) {
+            if (compute_diff_generic(old_data, old_size, new_data, new_size, requests[i].diff_flags, &results[i]) == CF_OK) {
                 success_count++;
             }
         }
@@ -8,11 +8,30 @@
 #ifdef __GLIBC__
 #include <malloc.h>
 #endif
+#if defined(__APPLE__)
+#include <mach/mach.h>
+#include <malloc/malloc.h>
+#elif defined(_WIN32)
+#include <windows.h>
+#include <psapi.h>
+#include <malloc.h>
+#elif defined(__linux__)
+#include <stdio.h>
+#include <unistd.h>
+#endif
 
 #ifdef _OPENMP
 #include <omp.h>
 #endif
 
+/*
+ * Repository extensions libgit2 should accept on top of its built-in ones.
+ * "git clone --filter" sets extensions.partialclone, which libgit2 1.5
+ * does not know and refuses to open; the objects it leaves out are fetched
+ * by the Go side (see promisor.go).
+ */
+static const char *cf_extensions[] = { "partialclone" };
+
 /*
  * Initialize global settings.
  * Should be called once at startup.
@@ -23,6 +42,10 @@ void cf_init() {
     // when running inside Go goroutines.
     omp_set_num_threads(1);
 #endif
+    /* Fails only when out of memory; partial clones then fail to open with
+     * libgit2's own "unsupported extension" error. */
+    git_libgit2_opts(GIT_OPT_SET_EXTENSIONS, cf_extensions,
+                     sizeof(cf_extensions) / sizeof(cf_extensions[0]));
 }
 
 /*
@@ -59,6 +82,67 @@ int cf_configure_memory(size_t mwindow_mapped_limit, size_t cache_max_size, int
     return 0;
 }
 
+/*
+ * Report the resident set size of the current process.
+ *
+ * Each platform exposes RSS differently; all paths return bytes so callers
+ * can treat the value uniformly. Returns 0 when the platform has no source.
+ */
+size_t cf_process_rss(void) {
+#if defined(__APPLE__)
+    mach_task_basic_info_data_t info;
+    mach_msg_type_number_t count = MACH_TASK_BASIC_INFO_COUNT;
+    if (task_info(mach_task_self(), MACH_TASK_BASIC_INFO, (task_info_t)&info, &count) != KERN_SUCCESS) {
+        return 0;
+    }
+    return (size_t)info.resident_size;
+#elif defined(_WIN32)
+    PROCESS_MEMORY_COUNTERS counters;
+    if (!GetProcessMemoryInfo(GetCurrentProcess(), &counters, sizeof(counters))) {
+        return 0;
+    }
+    return (size_t)counters.WorkingSetSize;
+#elif defined(__linux__)
+    unsigned long size_pages = 0;
+    unsigned long resident_pages = 0;
+    FILE* statm = fopen("/proc/self/statm", "r");
+    if (statm == NULL) {
+        return 0;
+    }
+    int matched = fscanf(statm, "%lu %lu", &size_pages, &resident_pages);
+    fclose(statm);
+    if (matched != 2) {
+        return 0;
+    }
+    long page_size = sysconf(_SC_PAGESIZE);
+    if (page_size <= 0) {
+        return 0;
+    }
+    return (size_t)resident_pages * (size_t)page_size;
+#else
+    return 0;
+#endif
+}
+
+/*
+ * Release freed native heap memory back to the OS.
+ *
+ * libgit2 and the blob/diff helpers allocate through the C heap, which the
+ * Go runtime's FreeOSMemory does not touch. Without this, RSS stays high
+ * after hibernation even though the memory is free.
+ */
+int cf_release_native_memory(void) {
+#if defined(__GLIBC__)
+    return malloc_trim(0);
+#elif defined(__APPLE__)
+    return malloc_zone_pressure_relief(NULL, 0) > 0 ? 1 : 0;
+#elif defined(_WIN32)
+    return _heapmin() == 0 ? 1 : 0;
+#else
+    return 0;
+#endif
+}
+
 /*
  * Count lines in a buffer.
  *
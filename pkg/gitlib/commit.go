@@ -23,6 +23,8 @@ type Commit struct {
 	commit   *git2go.Commit
 	repo     *Repository
 	testHash *Hash // used for testing when commit is nil.
+
+	sampleBase Hash // commit a sampled walk yielded before this one.
 }
 
 // NewCommitForTest creates a Commit with the given hash for testing.
@@ -116,6 +118,20 @@ func (c *Commit) ParentHash(n int) Hash {
 	return HashFromOid(c.commit.ParentId(safeconv.MustIntToUint(n)))
 }
 
+// SampleBase returns the commit a sampled walk yielded before this one.
+// Diffing against it instead of the parent keeps the changes of the commits
+// sampled out in between. ok is false outside a sampled walk and for its
+// first commit, which are diffed against their parent.
+func (c *Commit) SampleBase() (base Hash, ok bool) {
+	return c.sampleBase, !c.sampleBase.IsZero()
+}
+
+// SetSampleBase makes the commit diff against base, as if a sampled walk
+// had yielded base before it. A zero base restores the parent.
+func (c *Commit) SetSampleBase(base Hash) {
+	c.sampleBase = base
+}
+
 // TreeHash returns the hash of the tree associated with this commit. Zero when commit is a test double (nil internal).
 func (c *Commit) TreeHash() Hash {
 	if c.commit == nil {
@@ -198,9 +214,11 @@ func (c *Commit) Native() *git2go.Commit {
 
 // CommitIter iterates over commits.
 type CommitIter struct {
-	walk  *git2go.RevWalk
-	repo  *Repository
-	since *time.Time
+	walk    *git2go.RevWalk
+	repo    *Repository
+	since   *time.Time
+	sampler *commitSampler // nil when every commit is yielded.
+	kept    Hash           // last commit the sampler kept, the base of the next one.
 }
 
 // Next returns the next commit in the iteration.
@@ -220,6 +238,11 @@ func (ci *CommitIter) Next() (*Commit, error) {
 			return nil, io.EOF
 		}
 
+		// Sampled-out commits are skipped before the object lookup.
+		if ci.sampler != nil && !ci.sampler.keep(oid) {
+			continue
+		}
+
 		commit, err := ci.repo.repo.LookupCommit(oid)
 		if err != nil {
 			continue
@@ -234,7 +257,14 @@ func (ci *CommitIter) Next() (*Commit, error) {
 			return nil, io.EOF
 		}
 
-		return &Commit{commit: commit, repo: ci.repo}, nil
+		yielded := &Commit{commit: commit, repo: ci.repo}
+
+		if ci.sampler != nil {
+			yielded.sampleBase = ci.kept
+			ci.kept = HashFromOid(oid)
+		}
+
+		return yielded, nil
 	}
 }
 
@@ -281,12 +311,18 @@ func (ci *CommitIter) skip1() error {
 
 	oid := new(git2go.Oid)
 
-	err := ci.walk.Next(oid)
-	if err != nil {
-		ci.walk.Free()
-		ci.walk = nil
+	for {
+		err := ci.walk.Next(oid)
+		if err != nil {
+			ci.walk.Free()
+			ci.walk = nil
 
-		return io.EOF
+			return io.EOF
+		}
+
+		if ci.sampler == nil || ci.sampler.keep(oid) {
+			break
+		}
 	}
 
 	// When a since filter is active, we must look up the commit to check
@@ -308,6 +344,10 @@ func (ci *CommitIter) skip1() error {
 		}
 	}
 
+	if ci.sampler != nil {
+		ci.kept = HashFromOid(oid)
+	}
+
 	return nil
 }
 
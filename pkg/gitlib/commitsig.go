@@ -0,0 +1,71 @@
+package gitlib
+
+import (
+	"fmt"
+	"strings"
+
+	git2go "github.com/libgit2/git2go/v34"
+)
+
+// SignatureFormat is the format of a commit signature.
+type SignatureFormat string
+
+// Commit signature formats, named as git's gpg.format.
+const (
+	SignatureFormatNone    SignatureFormat = ""
+	SignatureFormatOpenPGP SignatureFormat = "openpgp"
+	SignatureFormatSSH     SignatureFormat = "ssh"
+	SignatureFormatX509    SignatureFormat = "x509"
+)
+
+// Armor headers that identify the signature formats.
+const (
+	armorSSH  = "-----BEGIN SSH SIGNATURE-----"
+	armorX509 = "-----BEGIN SIGNED MESSAGE-----"
+)
+
+// CommitSignature is the gpgsig header of a signed commit and the commit
+// content it signs: the raw commit object without that header.
+type CommitSignature struct {
+	Format    SignatureFormat
+	Signature string
+	Payload   string
+}
+
+// Signed reports whether the commit carries a signature.
+func (s CommitSignature) Signed() bool {
+	return s.Signature != ""
+}
+
+// ExtractSignature returns the signature of the commit, read by libgit2.
+// The zero CommitSignature is returned for an unsigned commit and for a
+// test double (nil internal).
+func (c *Commit) ExtractSignature() (CommitSignature, error) {
+	if c.commit == nil {
+		return CommitSignature{}, nil
+	}
+
+	signature, payload, err := c.commit.ExtractSignature()
+	if git2go.IsErrorCode(err, git2go.ErrorCodeNotFound) {
+		return CommitSignature{}, nil
+	}
+
+	if err != nil {
+		return CommitSignature{}, fmt.Errorf("extract signature of %s: %w", c.Hash(), err)
+	}
+
+	return CommitSignature{Format: signatureFormat(signature), Signature: signature, Payload: payload}, nil
+}
+
+// signatureFormat detects the format of an armored signature from its first
+// line. Like git, it takes any other armor for OpenPGP.
+func signatureFormat(signature string) SignatureFormat {
+	switch {
+	case strings.HasPrefix(signature, armorSSH):
+		return SignatureFormatSSH
+	case strings.HasPrefix(signature, armorX509):
+		return SignatureFormatX509
+	default:
+		return SignatureFormatOpenPGP
+	}
+}
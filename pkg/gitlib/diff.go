@@ -219,8 +219,8 @@ func (d *Diff) Delta(index int) (DiffDelta, error) {
 
 	return DiffDelta{
 		Status:   delta.Status,
-		OldFile:  DiffFile{Path: delta.OldFile.Path, Hash: HashFromOid(delta.OldFile.Oid), Size: int64(delta.OldFile.Size)},
-		NewFile:  DiffFile{Path: delta.NewFile.Path, Hash: HashFromOid(delta.NewFile.Oid), Size: int64(delta.NewFile.Size)},
+		OldFile:  newDiffFile(delta.OldFile),
+		NewFile:  newDiffFile(delta.NewFile),
 		Flags:    delta.Flags,
 		NumHunks: 0, // Will be set by ForEach.
 	}, nil
@@ -234,8 +234,8 @@ func (d *Diff) ForEach(
 	err := d.diff.ForEach(func(delta git2go.DiffDelta, progress float64) (git2go.DiffForEachHunkCallback, error) {
 		wrappedDelta := DiffDelta{
 			Status:  delta.Status,
-			OldFile: DiffFile{Path: delta.OldFile.Path, Hash: HashFromOid(delta.OldFile.Oid), Size: int64(delta.OldFile.Size)},
-			NewFile: DiffFile{Path: delta.NewFile.Path, Hash: HashFromOid(delta.NewFile.Oid), Size: int64(delta.NewFile.Size)},
+			OldFile: newDiffFile(delta.OldFile),
+			NewFile: newDiffFile(delta.NewFile),
 			Flags:   delta.Flags,
 		}
 
@@ -286,6 +286,11 @@ type DiffFile struct {
 	Path string
 	Hash Hash
 	Size int64
+	Mode uint16
+}
+
+func newDiffFile(file git2go.DiffFile) DiffFile {
+	return DiffFile{Path: file.Path, Hash: HashFromOid(file.Oid), Size: int64(file.Size), Mode: file.Mode}
 }
 
 // DiffStats wraps libgit2 diff stats.
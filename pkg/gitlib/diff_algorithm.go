@@ -0,0 +1,49 @@
+package gitlib
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DiffAlgorithm selects the line diff algorithm used for blob diffs.
+type DiffAlgorithm string
+
+const (
+	// DiffMyers is the default Myers diff.
+	DiffMyers DiffAlgorithm = "myers"
+	// DiffPatience anchors the diff on lines that occur exactly once on both
+	// sides, which keeps repeated blocks (closing braces, blank lines) from
+	// being matched across unrelated code.
+	DiffPatience DiffAlgorithm = "patience"
+)
+
+// diffHistogram is git's histogram diff, which is not implemented.
+const diffHistogram = "histogram"
+
+var (
+	// ErrUnknownDiffAlgorithm is returned for unrecognized diff algorithm names.
+	ErrUnknownDiffAlgorithm = errors.New("unknown diff algorithm")
+	// ErrUnsupportedDiffAlgorithm is returned for git diff algorithms that are
+	// not implemented.
+	ErrUnsupportedDiffAlgorithm = errors.New("unsupported diff algorithm")
+)
+
+// ParseDiffAlgorithm parses a diff algorithm name. Empty input maps to [DiffMyers].
+func ParseDiffAlgorithm(s string) (DiffAlgorithm, error) {
+	switch a := DiffAlgorithm(s); a {
+	case "":
+		return DiffMyers, nil
+	case DiffMyers, DiffPatience:
+		return a, nil
+	case diffHistogram:
+		return "", fmt.Errorf("%w: %q is not implemented, use patience", ErrUnsupportedDiffAlgorithm, s)
+	default:
+		return "", fmt.Errorf("%w: %q (want myers or patience)", ErrUnknownDiffAlgorithm, s)
+	}
+}
+
+// AnchorsUniqueLines reports whether the algorithm anchors on unique lines,
+// i.e. is patience diff.
+func (a DiffAlgorithm) AnchorsUniqueLines() bool {
+	return a == DiffPatience
+}
@@ -0,0 +1,30 @@
+package gitlib_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func TestParseDiffAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	got, err := gitlib.ParseDiffAlgorithm("")
+	require.NoError(t, err)
+	assert.Equal(t, gitlib.DiffMyers, got)
+	assert.False(t, got.AnchorsUniqueLines())
+
+	got, err = gitlib.ParseDiffAlgorithm("patience")
+	require.NoError(t, err)
+	assert.Equal(t, gitlib.DiffPatience, got)
+	assert.True(t, got.AnchorsUniqueLines())
+
+	_, err = gitlib.ParseDiffAlgorithm("histogram")
+	require.ErrorIs(t, err, gitlib.ErrUnsupportedDiffAlgorithm)
+
+	_, err = gitlib.ParseDiffAlgorithm("minimal")
+	require.ErrorIs(t, err, gitlib.ErrUnknownDiffAlgorithm)
+}
@@ -0,0 +1,254 @@
+package gitlib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Encoding is the character encoding of a blob.
+type Encoding string
+
+// Encodings DetectEncoding tells apart.
+const (
+	// EncodingUTF8 is UTF-8, and so plain ASCII.
+	EncodingUTF8 Encoding = "utf-8"
+	// EncodingUTF16LE is little-endian UTF-16.
+	EncodingUTF16LE Encoding = "utf-16le"
+	// EncodingUTF16BE is big-endian UTF-16.
+	EncodingUTF16BE Encoding = "utf-16be"
+	// EncodingShiftJIS is Shift-JIS, the legacy Japanese encoding.
+	EncodingShiftJIS Encoding = "shift_jis"
+	// EncodingLatin1 is ISO-8859-1. It is the fallback for text that is no
+	// other encoding, as every byte sequence is valid Latin-1.
+	EncodingLatin1 Encoding = "iso-8859-1"
+	// EncodingWindows1252 is the Windows Latin-1 code page. DetectEncoding
+	// never picks it: its curly quotes and dashes read as Shift-JIS or
+	// Latin-1, so it must be forced with a TextEncoding.
+	EncodingWindows1252 Encoding = "windows-1252"
+	// EncodingBinary is content that is not text.
+	EncodingBinary Encoding = "binary"
+)
+
+// utf16MinASCIIRatio is the share of 16-bit units that must carry a NUL
+// byte, always on the same side, for BOM-less data to be taken as UTF-16.
+const utf16MinASCIIRatio = 0.5
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// DetectEncoding guesses the character encoding of data.
+//
+// Byte order marks decide first. UTF-16 without a BOM is recognized by the
+// NUL bytes its ASCII characters carry, all at odd or all at even offsets;
+// other data with NUL bytes is binary. Valid UTF-8 is UTF-8. Otherwise data
+// is Shift-JIS if it decodes strictly as such, and Latin-1 if not.
+func DetectEncoding(data []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		return EncodingUTF8
+	case bytes.HasPrefix(data, utf16LEBOM):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(data, utf16BEBOM):
+		return EncodingUTF16BE
+	}
+
+	sniff := data
+	if len(sniff) > binarySniffLength {
+		sniff = sniff[:binarySniffLength]
+	}
+
+	if bytes.IndexByte(sniff, 0) >= 0 {
+		return detectUTF16(sniff)
+	}
+
+	if utf8.Valid(data) {
+		return EncodingUTF8
+	}
+
+	if isShiftJIS(data) {
+		return EncodingShiftJIS
+	}
+
+	return EncodingLatin1
+}
+
+// detectUTF16 tells BOM-less UTF-16 from binary data with NUL bytes.
+func detectUTF16(sniff []byte) Encoding {
+	var evenNUL, oddNUL int
+
+	for i, c := range sniff {
+		if c != 0 {
+			continue
+		}
+
+		if i%2 == 0 {
+			evenNUL++
+		} else {
+			oddNUL++
+		}
+	}
+
+	units := len(sniff) / 2
+	if units == 0 {
+		return EncodingBinary
+	}
+
+	switch {
+	case evenNUL == 0 && float64(oddNUL) >= float64(units)*utf16MinASCIIRatio:
+		return EncodingUTF16LE
+	case oddNUL == 0 && float64(evenNUL) >= float64(units)*utf16MinASCIIRatio:
+		return EncodingUTF16BE
+	default:
+		return EncodingBinary
+	}
+}
+
+// isShiftJIS reports whether data decodes as Shift-JIS with at least one
+// double-byte character. Lead bytes are limited to the JIS X 0208 rows,
+// which keeps accented Latin-1 text from passing.
+func isShiftJIS(data []byte) bool {
+	var doubleByte int
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		switch {
+		case c < 0x80, c >= 0xA1 && c <= 0xDF:
+			// ASCII and half-width katakana.
+		case c >= 0x81 && c <= 0x9F, c >= 0xE0 && c <= 0xEF:
+			if i+1 >= len(data) {
+				return false
+			}
+
+			trail := data[i+1]
+			if trail < 0x40 || trail == 0x7F || trail > 0xFC {
+				return false
+			}
+
+			doubleByte++
+			i++
+		default:
+			return false
+		}
+	}
+
+	return doubleByte > 0
+}
+
+// textEncoding returns the decoder of enc, or nil when data in enc needs no
+// transcoding.
+func textEncoding(enc Encoding) encoding.Encoding {
+	switch enc {
+	case EncodingUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	case EncodingUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	case EncodingShiftJIS:
+		return japanese.ShiftJIS
+	case EncodingLatin1:
+		return charmap.ISO8859_1
+	case EncodingWindows1252:
+		return charmap.Windows1252
+	default:
+		return nil
+	}
+}
+
+// DecodeToUTF8 transcodes data from enc to UTF-8. UTF-8 and binary data are
+// returned as is. Byte order marks of UTF-16 are dropped.
+func DecodeToUTF8(data []byte, enc Encoding) ([]byte, error) {
+	decoder := textEncoding(enc)
+	if decoder == nil {
+		return data, nil
+	}
+
+	decoded, err := decoder.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", enc, err)
+	}
+
+	return decoded, nil
+}
+
+// Text encoding modes ParseTextEncoding accepts besides encoding names.
+const (
+	// TextEncodingAuto detects the encoding of every blob.
+	TextEncodingAuto = "auto"
+	// TextEncodingOff keeps blobs as stored.
+	TextEncodingOff = "off"
+)
+
+// ErrUnknownEncoding is returned by ParseTextEncoding for an unknown mode or
+// encoding name.
+var ErrUnknownEncoding = errors.New("unknown text encoding")
+
+// TextEncoding selects how the text of blobs is turned into UTF-8. The zero
+// value detects the encoding of every blob.
+type TextEncoding struct {
+	// Off keeps blobs as stored, with no transcoding.
+	Off bool
+	// Force decodes every text blob from this encoding instead of the
+	// detected one. Binary blobs are left alone.
+	Force Encoding
+}
+
+// ParseTextEncoding parses "auto", "off" or the name of an encoding to
+// force, such as "windows-1252".
+func ParseTextEncoding(s string) (TextEncoding, error) {
+	switch name := strings.ToLower(strings.TrimSpace(s)); name {
+	case "", TextEncodingAuto:
+		return TextEncoding{}, nil
+	case TextEncodingOff:
+		return TextEncoding{Off: true}, nil
+	default:
+		enc := Encoding(name)
+		if enc != EncodingUTF8 && textEncoding(enc) == nil {
+			return TextEncoding{}, fmt.Errorf("%w: %q", ErrUnknownEncoding, s)
+		}
+
+		return TextEncoding{Force: enc}, nil
+	}
+}
+
+// String returns the mode as ParseTextEncoding reads it.
+func (t TextEncoding) String() string {
+	switch {
+	case t.Off:
+		return TextEncodingOff
+	case t.Force != "":
+		return string(t.Force)
+	default:
+		return TextEncodingAuto
+	}
+}
+
+// transcode returns data as UTF-8 and the encoding it was read in, falling
+// back to the raw bytes, as binary, when decoding fails.
+func (t TextEncoding) transcode(data []byte) ([]byte, Encoding) {
+	enc := DetectEncoding(data)
+	if t.Off {
+		return data, enc
+	}
+
+	if t.Force != "" && enc != EncodingBinary {
+		enc = t.Force
+	}
+
+	decoded, err := DecodeToUTF8(data, enc)
+	if err != nil {
+		return data, EncodingBinary
+	}
+
+	return decoded, enc
+}
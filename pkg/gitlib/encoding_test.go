@@ -0,0 +1,151 @@
+package gitlib_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// nihongoShiftJIS is "日本語\n" in Shift-JIS.
+var nihongoShiftJIS = []byte{0x93, 0xFA, 0x96, 0x7B, 0x8C, 0xEA, '\n'}
+
+func TestDetectEncoding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+		want gitlib.Encoding
+	}{
+		{"empty", nil, gitlib.EncodingUTF8},
+		{"ascii", []byte("package main\n"), gitlib.EncodingUTF8},
+		{"utf8", []byte("日本語\n"), gitlib.EncodingUTF8},
+		{"utf8 bom", []byte("\xEF\xBB\xBFtext\n"), gitlib.EncodingUTF8},
+		{"utf16le bom", []byte{0xFF, 0xFE, 0x42, 0x30}, gitlib.EncodingUTF16LE},
+		{"utf16be bom", []byte{0xFE, 0xFF, 0x30, 0x42}, gitlib.EncodingUTF16BE},
+		{"utf16le", []byte{'h', 0, 'i', 0, '\n', 0}, gitlib.EncodingUTF16LE},
+		{"utf16be", []byte{0, 'h', 0, 'i', 0, '\n'}, gitlib.EncodingUTF16BE},
+		{"shift-jis", nihongoShiftJIS, gitlib.EncodingShiftJIS},
+		{"latin-1", []byte("caf\xe9\n"), gitlib.EncodingLatin1},
+		{"latin-1 with lead bytes", []byte("d\xe9j\xe0 vu\n"), gitlib.EncodingLatin1},
+		{"binary", []byte("binary\x00data\x00\x01\x02"), gitlib.EncodingBinary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, gitlib.DetectEncoding(tt.data))
+		})
+	}
+}
+
+func TestDecodeToUTF8(t *testing.T) {
+	t.Parallel()
+
+	text, err := gitlib.DecodeToUTF8(nihongoShiftJIS, gitlib.EncodingShiftJIS)
+	require.NoError(t, err)
+	assert.Equal(t, "日本語\n", string(text))
+
+	text, err = gitlib.DecodeToUTF8([]byte("caf\xe9\n"), gitlib.EncodingLatin1)
+	require.NoError(t, err)
+	assert.Equal(t, "café\n", string(text))
+
+	text, err = gitlib.DecodeToUTF8([]byte{0xFF, 0xFE, 'a', 0, '\n', 0}, gitlib.EncodingUTF16LE)
+	require.NoError(t, err)
+	assert.Equal(t, "a\n", string(text))
+
+	raw := []byte("bin\x00ary")
+	text, err = gitlib.DecodeToUTF8(raw, gitlib.EncodingBinary)
+	require.NoError(t, err)
+	assert.Equal(t, raw, text)
+}
+
+func TestCachedBlob_Transcodes(t *testing.T) {
+	t.Parallel()
+
+	utf16 := []byte{0xFF, 0xFE, 'a', 0, '\n', 0, 'b', 0, '\n', 0}
+	blob := gitlib.NewCachedBlobForTest(utf16)
+
+	assert.Equal(t, gitlib.EncodingUTF16LE, blob.Encoding())
+	assert.Equal(t, "a\nb\n", string(blob.Data))
+	assert.Equal(t, int64(len(utf16)), blob.Size())
+	assert.False(t, blob.IsBinary())
+
+	lines, err := blob.CountLines()
+	require.NoError(t, err)
+	assert.Equal(t, 2, lines)
+
+	sjis := gitlib.NewCachedBlobForTest(nihongoShiftJIS)
+	assert.Equal(t, gitlib.EncodingShiftJIS, sjis.Encoding())
+	assert.Equal(t, "日本語\n", string(sjis.Data))
+
+	binary := gitlib.NewCachedBlobForTest([]byte("bin\x00\x01\x02ary"))
+	assert.Equal(t, gitlib.EncodingBinary, binary.Encoding())
+	assert.True(t, binary.IsBinary())
+}
+
+// smartQuotesCP1252 is "“smart” quotes – dash\n" in Windows-1252.
+var smartQuotesCP1252 = []byte("\x93smart\x94 quotes \x96 dash\n")
+
+func TestParseTextEncoding(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]gitlib.TextEncoding{
+		"":             {},
+		"auto":         {},
+		"OFF":          {Off: true},
+		"windows-1252": {Force: gitlib.EncodingWindows1252},
+		"shift_jis":    {Force: gitlib.EncodingShiftJIS},
+		"utf-8":        {Force: gitlib.EncodingUTF8},
+	} {
+		got, err := gitlib.ParseTextEncoding(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"binary", "ebcdic"} {
+		_, err := gitlib.ParseTextEncoding(input)
+		require.ErrorIs(t, err, gitlib.ErrUnknownEncoding, input)
+	}
+
+	assert.Equal(t, "windows-1252", gitlib.TextEncoding{Force: gitlib.EncodingWindows1252}.String())
+	assert.Equal(t, "off", gitlib.TextEncoding{Off: true}.String())
+	assert.Equal(t, "auto", gitlib.TextEncoding{}.String())
+}
+
+func TestCachedBlob_Windows1252(t *testing.T) {
+	t.Parallel()
+
+	// Detection cannot tell Windows-1252 from Latin-1: its curly quotes and
+	// dashes become C1 control characters.
+	auto := gitlib.NewCachedBlobForTest(smartQuotesCP1252)
+	assert.Equal(t, gitlib.EncodingLatin1, auto.Encoding())
+	assert.NotEqual(t, "“smart” quotes – dash\n", string(auto.Data))
+
+	forced := gitlib.NewCachedBlobWithEncodingForTest(smartQuotesCP1252, gitlib.TextEncoding{Force: gitlib.EncodingWindows1252})
+	assert.Equal(t, gitlib.EncodingWindows1252, forced.Encoding())
+	assert.Equal(t, "“smart” quotes – dash\n", string(forced.Data))
+
+	// Forcing an encoding leaves binary blobs alone.
+	raw := []byte("bin\x00\x01\x02ary")
+	binary := gitlib.NewCachedBlobWithEncodingForTest(raw, gitlib.TextEncoding{Force: gitlib.EncodingWindows1252})
+	assert.Equal(t, gitlib.EncodingBinary, binary.Encoding())
+	assert.Equal(t, raw, binary.Data)
+}
+
+func TestCachedBlob_TranscodingOff(t *testing.T) {
+	t.Parallel()
+
+	off := gitlib.TextEncoding{Off: true}
+
+	sjis := gitlib.NewCachedBlobWithEncodingForTest(nihongoShiftJIS, off)
+	assert.Equal(t, gitlib.EncodingShiftJIS, sjis.Encoding(), "the detected encoding is still reported")
+	assert.Equal(t, nihongoShiftJIS, sjis.Data)
+
+	latin1 := gitlib.NewCachedBlobWithEncodingForTest(smartQuotesCP1252, off)
+	assert.Equal(t, smartQuotesCP1252, latin1.Data)
+}
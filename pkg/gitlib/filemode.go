@@ -0,0 +1,21 @@
+package gitlib
+
+// Git file modes of tree entries. Git records only whether a blob is
+// executable, so these are the only modes a file can have.
+const (
+	FileModeRegular    uint16 = 0o100644
+	FileModeExecutable uint16 = 0o100755
+	FileModeSymlink    uint16 = 0o120000
+	FileModeGitlink    uint16 = 0o160000
+	FileModeTree       uint16 = 0o040000
+)
+
+// IsExecutable reports whether the entry is an executable file.
+func (e ChangeEntry) IsExecutable() bool {
+	return e.Mode == FileModeExecutable
+}
+
+// IsSymlink reports whether the entry is a symbolic link.
+func (e ChangeEntry) IsSymlink() bool {
+	return e.Mode == FileModeSymlink
+}
@@ -2307,6 +2307,73 @@ func TestInitialTreeChangesWithSubdirectory(t *testing.T) {
 	assert.Len(t, changes, 3)
 }
 
+func TestTreeDiffFileModes(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+
+	defer tr.cleanup()
+
+	tr.createFile("README.md", "# readme\n")
+	tr.createFile("run.sh", "#!/bin/sh\n")
+	require.NoError(t, os.Chmod(filepath.Join(tr.path, "run.sh"), 0o700)) //nolint:gosec // the test needs an executable file.
+	firstHash := tr.commit("first")
+
+	require.NoError(t, os.Chmod(filepath.Join(tr.path, "README.md"), 0o700)) //nolint:gosec // the test needs an executable file.
+	require.NoError(t, os.Symlink("run.sh", filepath.Join(tr.path, "link")))
+	secondHash := tr.commit("second")
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	trees := make([]*gitlib.Tree, 0, 2)
+
+	for _, hash := range []gitlib.Hash{firstHash, secondHash} {
+		commit, lookupErr := repo.LookupCommit(context.Background(), hash)
+		require.NoError(t, lookupErr)
+
+		defer commit.Free()
+
+		tree, treeErr := commit.Tree()
+		require.NoError(t, treeErr)
+
+		defer tree.Free()
+
+		trees = append(trees, tree)
+	}
+
+	initial, err := gitlib.InitialTreeChanges(context.Background(), repo, trees[0])
+	require.NoError(t, err)
+
+	modes := make(map[string]uint16)
+	for _, change := range initial {
+		modes[change.To.Name] = change.To.Mode
+	}
+
+	assert.Equal(t, map[string]uint16{"README.md": gitlib.FileModeRegular, "run.sh": gitlib.FileModeExecutable}, modes)
+
+	changes, err := gitlib.TreeDiff(context.Background(), repo, trees[0], trees[1])
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+
+	for _, change := range changes {
+		switch change.Action {
+		case gitlib.Insert:
+			assert.Equal(t, "link", change.To.Name)
+			assert.True(t, change.To.IsSymlink())
+		case gitlib.Modify:
+			assert.Equal(t, "README.md", change.To.Name)
+			assert.False(t, change.From.IsExecutable())
+			assert.True(t, change.To.IsExecutable())
+			assert.Equal(t, change.From.Hash, change.To.Hash)
+		case gitlib.Delete:
+			t.Fatalf("unexpected deletion of %s", change.From.Name)
+		}
+	}
+}
+
 func TestFileReaderClose(t *testing.T) {
 	t.Parallel()
 
@@ -2582,3 +2649,52 @@ func TestCommitIter_Skip_PastEnd(t *testing.T) {
 	require.ErrorIs(t, err, io.EOF)
 	iter.Close()
 }
+
+func TestLoadCommits_Range(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	hashes := commitN(tr, 5)
+
+	for name, hash := range map[string]gitlib.Hash{"v1.0.0": hashes[1], "v1.1.0": hashes[3]} {
+		commit, err := tr.native.LookupCommit(hash.ToOid())
+		require.NoError(t, err)
+
+		sig := &git2go.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()}
+		_, err = tr.native.Tags.Create(name, commit, sig, "release "+name)
+		require.NoError(t, err)
+
+		commit.Free()
+	}
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	resolved, err := repo.ResolveRevision("v1.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, hashes[3], resolved)
+
+	commits, err := gitlib.LoadCommits(context.Background(), repo, gitlib.CommitLoadOptions{From: "v1.0.0", To: "v1.1.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []gitlib.Hash{hashes[2], hashes[3]}, commitHashes(commits))
+
+	commits, err = gitlib.LoadCommits(context.Background(), repo, gitlib.CommitLoadOptions{From: "v1.1.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []gitlib.Hash{hashes[4]}, commitHashes(commits))
+
+	_, err = gitlib.LoadCommits(context.Background(), repo, gitlib.CommitLoadOptions{From: "v9.9.9"})
+	require.Error(t, err)
+}
+
+func commitHashes(commits []*gitlib.Commit) []gitlib.Hash {
+	hashes := make([]gitlib.Hash, len(commits))
+	for i, commit := range commits {
+		hashes[i] = commit.Hash()
+	}
+
+	return hashes
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"regexp"
 	"strings"
@@ -17,6 +16,10 @@ type CommitLoadOptions struct {
 	FirstParent bool
 	HeadOnly    bool
 	Since       string
+	// From and To limit the history to the commits reachable from To, HEAD
+	// when empty, but not from From, as in git log From..To.
+	From string
+	To   string
 }
 
 // ErrInvalidTimeFormat is returned when a time string cannot be parsed.
@@ -37,7 +40,7 @@ func LoadRepository(uri string) (*Repository, error) {
 
 	repository, err := OpenRepository(uri)
 	if err != nil {
-		log.Fatalf("failed to open %s: %v", uri, err)
+		return nil, fmt.Errorf("failed to open %s: %w", uri, err)
 	}
 
 	return repository, nil
@@ -111,6 +114,11 @@ func loadHistoryCommits(ctx context.Context, repository *Repository, opts Commit
 		logOpts.Since = &sinceTime
 	}
 
+	err := resolveRange(repository, opts, logOpts)
+	if err != nil {
+		return nil, err
+	}
+
 	iter, err := repository.Log(logOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list commits: %w", err)
@@ -123,6 +131,30 @@ func loadHistoryCommits(ctx context.Context, repository *Repository, opts Commit
 	return commits, nil
 }
 
+// resolveRange sets the tip and the excluded commit of logOpts from the
+// From and To revisions of opts.
+func resolveRange(repository *Repository, opts CommitLoadOptions, logOpts *LogOptions) error {
+	if opts.To != "" {
+		tip, err := repository.ResolveRevision(opts.To)
+		if err != nil {
+			return err
+		}
+
+		logOpts.Tip = &tip
+	}
+
+	if opts.From != "" {
+		exclude, err := repository.ResolveRevision(opts.From)
+		if err != nil {
+			return err
+		}
+
+		logOpts.Exclude = &exclude
+	}
+
+	return nil
+}
+
 func collectCommits(iter *CommitIter, limit int) []*Commit {
 	var commits []*Commit
 
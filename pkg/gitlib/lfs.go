@@ -0,0 +1,158 @@
+package gitlib
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lfsPointerMaxSize is the largest blob git-lfs reads as a pointer file.
+const lfsPointerMaxSize = 1024
+
+// lfsVersionPrefix starts every pointer file, before the spec version.
+const lfsVersionPrefix = "version https://git-lfs.github.com/spec/"
+
+// lfsOIDPrefix prefixes the object ID: git-lfs only hashes with SHA-256.
+const lfsOIDPrefix = "sha256:"
+
+// DefaultLFSMaxSize caps the size of the LFS objects an LFSStore resolves.
+const DefaultLFSMaxSize = 64 << 20
+
+// Errors of LFS object resolution.
+var (
+	// ErrLFSObjectTooLarge is returned for objects larger than LFSStore.MaxSize.
+	ErrLFSObjectTooLarge = errors.New("lfs object too large")
+	// ErrLFSObjectSize is returned for objects whose size differs from the
+	// size their pointer records.
+	ErrLFSObjectSize = errors.New("lfs object size mismatch")
+)
+
+// LFSPointer is the content of a Git LFS pointer file: it stands for the
+// object with the given SHA-256 and size in the LFS store.
+type LFSPointer struct {
+	// OID is the hex SHA-256 of the object.
+	OID  string
+	Size int64
+}
+
+// ParseLFSPointer parses data as a Git LFS pointer file. It reports false for
+// anything else.
+func ParseLFSPointer(data []byte) (LFSPointer, bool) {
+	if len(data) > lfsPointerMaxSize || !bytes.HasPrefix(data, []byte(lfsVersionPrefix)) {
+		return LFSPointer{}, false
+	}
+
+	var (
+		pointer LFSPointer
+		sized   bool
+	)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+
+	for _, line := range lines[1:] {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			return LFSPointer{}, false
+		}
+
+		switch key {
+		case "oid":
+			oid, found := strings.CutPrefix(value, lfsOIDPrefix)
+			if !found || !isSHA256Hex(oid) {
+				return LFSPointer{}, false
+			}
+
+			pointer.OID = oid
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return LFSPointer{}, false
+			}
+
+			pointer.Size, sized = size, true
+		}
+	}
+
+	return pointer, pointer.OID != "" && sized
+}
+
+func isSHA256Hex(s string) bool {
+	if len(s) != hex.EncodedLen(32) {
+		return false
+	}
+
+	_, err := hex.DecodeString(s)
+
+	return err == nil
+}
+
+// LFSStore reads objects from a local Git LFS object store.
+type LFSStore struct {
+	dir string
+	// textEncoding transcodes the objects Resolve reads.
+	textEncoding TextEncoding
+	// MaxSize caps the size of the objects Resolve reads. Larger objects
+	// stay pointers.
+	MaxSize int64
+}
+
+// NewLFSStore returns a store reading the objects below dir, laid out as
+// git-lfs does: dir/ab/cd/abcd....
+func NewLFSStore(dir string) *LFSStore {
+	return &LFSStore{dir: dir, MaxSize: DefaultLFSMaxSize}
+}
+
+// LFSStore returns the default LFS object store of the repository, below
+// its git directory. Objects are only there once git-lfs fetched them.
+func (r *Repository) LFSStore() *LFSStore {
+	store := NewLFSStore(filepath.Join(r.repo.Path(), "lfs", "objects"))
+	store.textEncoding = r.textEncoding
+
+	return store
+}
+
+// Path returns the path the object of pointer has in the store.
+func (s *LFSStore) Path(pointer LFSPointer) string {
+	return filepath.Join(s.dir, pointer.OID[0:2], pointer.OID[2:4], pointer.OID)
+}
+
+// Read returns the object pointer stands for. Objects missing from the
+// store yield an error matching os.ErrNotExist.
+func (s *LFSStore) Read(pointer LFSPointer) ([]byte, error) {
+	if s.MaxSize > 0 && pointer.Size > s.MaxSize {
+		return nil, fmt.Errorf("%w: %s is %d bytes", ErrLFSObjectTooLarge, pointer.OID, pointer.Size)
+	}
+
+	data, err := os.ReadFile(s.Path(pointer))
+	if err != nil {
+		return nil, fmt.Errorf("read lfs object: %w", err)
+	}
+
+	if int64(len(data)) != pointer.Size {
+		return nil, fmt.Errorf("%w: %s is %d bytes, pointer says %d", ErrLFSObjectSize, pointer.OID, len(data), pointer.Size)
+	}
+
+	return data, nil
+}
+
+// Resolve returns blob with the contents of the object it points to when it
+// is an LFS pointer whose object is in the store, and blob itself otherwise.
+// The resolved blob keeps the hash of the pointer, as trees reference it.
+func (s *LFSStore) Resolve(blob *CachedBlob) *CachedBlob {
+	pointer, ok := ParseLFSPointer(blob.Data)
+	if !ok {
+		return blob
+	}
+
+	data, err := s.Read(pointer)
+	if err != nil {
+		return blob
+	}
+
+	return newCachedBlob(blob.hash, int64(len(data)), data, s.textEncoding)
+}
@@ -0,0 +1,120 @@
+package gitlib_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func lfsPointerFile(data []byte) (pointer []byte, oid string) {
+	sum := sha256.Sum256(data)
+	oid = hex.EncodeToString(sum[:])
+
+	return []byte("version https://git-lfs.github.com/spec/v1\n" +
+		"oid sha256:" + oid + "\n" +
+		"size " + strconv.Itoa(len(data)) + "\n"), oid
+}
+
+func writeLFSObject(t *testing.T, dir, oid string, data []byte) {
+	t.Helper()
+
+	objDir := filepath.Join(dir, oid[0:2], oid[2:4])
+	require.NoError(t, os.MkdirAll(objDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(objDir, oid), data, 0o600))
+}
+
+func TestParseLFSPointer(t *testing.T) {
+	t.Parallel()
+
+	data, oid := lfsPointerFile([]byte("asset"))
+
+	pointer, ok := gitlib.ParseLFSPointer(data)
+	require.True(t, ok)
+	assert.Equal(t, gitlib.LFSPointer{OID: oid, Size: 5}, pointer)
+
+	for _, invalid := range []string{
+		"",
+		"version https://git-lfs.github.com/spec/v1\nsize 5\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 5\n",
+		"version https://git-lfs.github.com/spec/v1\noid md5:" + oid + "\nsize 5\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize -1\n",
+		"some text\noid sha256:" + oid + "\nsize 5\n",
+	} {
+		_, ok = gitlib.ParseLFSPointer([]byte(invalid))
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestCachedBlob_LFSPointerIsBinary(t *testing.T) {
+	t.Parallel()
+
+	data, _ := lfsPointerFile([]byte("asset"))
+	blob := gitlib.NewCachedBlobForTest(data)
+
+	assert.True(t, blob.IsLFSPointer())
+	assert.True(t, blob.IsBinary())
+
+	_, err := blob.CountLines()
+	require.ErrorIs(t, err, gitlib.ErrBinary)
+
+	assert.False(t, gitlib.NewCachedBlobForTest([]byte("line\n")).IsLFSPointer())
+}
+
+func TestLFSStore_Resolve(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := gitlib.NewLFSStore(dir)
+
+	content := []byte("line1\nline2\nline3\nline4\n")
+	data, oid := lfsPointerFile(content)
+	hash := gitlib.NewHash("1111111111111111111111111111111111111111")
+	blob := gitlib.NewCachedBlobWithHashForTest(hash, data)
+
+	// Objects git-lfs did not fetch leave the pointer as is.
+	assert.Same(t, blob, store.Resolve(blob))
+
+	writeLFSObject(t, dir, oid, content)
+
+	resolved := store.Resolve(blob)
+	assert.Equal(t, hash, resolved.Hash())
+	assert.Equal(t, content, resolved.Data)
+	assert.False(t, resolved.IsLFSPointer())
+
+	lines, err := resolved.CountLines()
+	require.NoError(t, err)
+	assert.Equal(t, 4, lines)
+
+	text := gitlib.NewCachedBlobForTest([]byte("plain\n"))
+	assert.Same(t, text, store.Resolve(text))
+}
+
+func TestLFSStore_Read(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := gitlib.NewLFSStore(dir)
+
+	content := []byte("0123456789")
+	_, oid := lfsPointerFile(content)
+	writeLFSObject(t, dir, oid, content)
+
+	_, err := store.Read(gitlib.LFSPointer{OID: oid, Size: 4})
+	require.ErrorIs(t, err, gitlib.ErrLFSObjectSize)
+
+	store.MaxSize = 8
+
+	_, err = store.Read(gitlib.LFSPointer{OID: oid, Size: 10})
+	require.ErrorIs(t, err, gitlib.ErrLFSObjectTooLarge)
+
+	_, err = store.Read(gitlib.LFSPointer{OID: "ab" + oid[2:], Size: 1})
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
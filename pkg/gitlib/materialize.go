@@ -0,0 +1,492 @@
+package gitlib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	git2go "github.com/libgit2/git2go/v34"
+)
+
+// Permissions of materialized files and directories, before the umask.
+const (
+	materializeDirMode  = 0o755
+	materializeFileMode = 0o644
+	materializeExecMode = 0o755
+)
+
+// ErrUnsafeTreeEntry is returned for a tree entry whose name could escape the
+// materialization directory or write into a .git directory, including names
+// that only differ from another entry of the same tree in case.
+var ErrUnsafeTreeEntry = errors.New("unsafe tree entry name")
+
+// MaterializeTree writes the files of a tree into dir, creating it when
+// needed, without touching the repository's index or worktree. hash names a
+// tree or a commit. Executable bits and symbolic links are preserved;
+// submodules become empty directories, as in a fresh checkout. Files already
+// in dir are overwritten but never removed, and links or files where the tree
+// has a directory are replaced by it. Trees with entry names that only differ
+// in case are rejected, since they collide on case-insensitive file systems.
+func (r *Repository) MaterializeTree(ctx context.Context, hash Hash, dir string) error {
+	tree, err := r.peelTree(hash)
+	if err != nil {
+		return err
+	}
+	defer tree.Free()
+
+	err = os.MkdirAll(dir, materializeDirMode)
+	if err != nil {
+		return fmt.Errorf("materialize tree: %w", err)
+	}
+
+	return r.materializeTree(ctx, tree, dir)
+}
+
+func (r *Repository) materializeTree(ctx context.Context, tree *git2go.Tree, dir string) error {
+	// On a case-insensitive file system, entries that only differ in case
+	// land on the same path, so a symbolic link "A" would redirect tree "a".
+	folded := make(map[string]string, tree.EntryCount())
+
+	for i := range tree.EntryCount() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		entry := tree.EntryByIndex(i)
+
+		err := checkTreeEntryName(entry.Name)
+		if err != nil {
+			return err
+		}
+
+		key := strings.ToLower(entry.Name)
+		if other, ok := folded[key]; ok {
+			return fmt.Errorf("%w: %q collides with %q", ErrUnsafeTreeEntry, entry.Name, other)
+		}
+
+		folded[key] = entry.Name
+
+		target := filepath.Join(dir, entry.Name)
+
+		switch entry.Filemode {
+		case git2go.FilemodeTree:
+			err = r.materializeSubtree(ctx, entry.Id, target)
+		case git2go.FilemodeCommit:
+			err = materializeDir(target)
+		case git2go.FilemodeLink:
+			err = r.materializeLink(ctx, entry.Id, target)
+		case git2go.FilemodeBlobExecutable:
+			err = r.materializeFile(ctx, entry.Id, target, materializeExecMode)
+		default:
+			err = r.materializeFile(ctx, entry.Id, target, materializeFileMode)
+		}
+
+		if err != nil {
+			return fmt.Errorf("materialize tree: %s: %w", target, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) materializeSubtree(ctx context.Context, oid *git2go.Oid, dir string) error {
+	subtree, err := r.repo.LookupTree(oid)
+	if err != nil {
+		return err
+	}
+	defer subtree.Free()
+
+	err = materializeDir(dir)
+	if err != nil {
+		return err
+	}
+
+	return r.materializeTree(ctx, subtree, dir)
+}
+
+// materializeDir makes dir a real directory. Anything else at dir, such as a
+// symbolic link left by an earlier materialization, is removed rather than
+// followed, so later entries cannot be written outside the target.
+func materializeDir(dir string) error {
+	info, err := os.Lstat(dir)
+
+	switch {
+	case err == nil && info.IsDir():
+		return nil
+	case err == nil:
+		err = os.Remove(dir)
+		if err != nil {
+			return err
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	return os.Mkdir(dir, materializeDirMode)
+}
+
+func (r *Repository) materializeFile(ctx context.Context, oid *git2go.Oid, name string, perm os.FileMode) error {
+	blob, err := r.LookupBlob(ctx, HashFromOid(oid))
+	if err != nil {
+		return err
+	}
+	defer blob.Free()
+
+	// Replace rather than truncate, so a symbolic link left at name by an
+	// earlier materialization is not followed.
+	err = os.Remove(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return os.WriteFile(name, blob.Contents(), perm)
+}
+
+func (r *Repository) materializeLink(ctx context.Context, oid *git2go.Oid, name string) error {
+	blob, err := r.LookupBlob(ctx, HashFromOid(oid))
+	if err != nil {
+		return err
+	}
+	defer blob.Free()
+
+	err = os.Remove(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return os.Symlink(string(blob.Contents()), name)
+}
+
+// checkTreeEntryName rejects the entry names git itself refuses to check out.
+func checkTreeEntryName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || isDotGit(name) {
+		return fmt.Errorf("%w: %q", ErrUnsafeTreeEntry, name)
+	}
+
+	return nil
+}
+
+// isDotGit reports whether name resolves to .git on some file system: in
+// any case, with the trailing dots and spaces NTFS ignores, or as the NTFS
+// short name git~1.
+func isDotGit(name string) bool {
+	trimmed := strings.TrimRight(name, ". ")
+
+	return strings.EqualFold(trimmed, ".git") || strings.EqualFold(trimmed, "git~1")
+}
+
+// peelTree returns the tree hash names, peeling a commit to its tree.
+func (r *Repository) peelTree(hash Hash) (*git2go.Tree, error) {
+	obj, err := r.repo.Lookup(hash.ToOid())
+	if err != nil {
+		return nil, fmt.Errorf("lookup tree: %w", err)
+	}
+	defer obj.Free()
+
+	peeled, err := obj.Peel(git2go.ObjectTree)
+	if err != nil {
+		return nil, fmt.Errorf("lookup tree: %w", err)
+	}
+	defer peeled.Free()
+
+	tree, err := peeled.AsTree()
+	if err != nil {
+		return nil, fmt.Errorf("lookup tree: %w", err)
+	}
+
+	return tree, nil
+}
+
+// TreeFS is a read-only fs.FS view of a tree, reading blobs from the object
+// database as files are opened. It lets code written against fs.FS analyze
+// any revision without a checkout. Symbolic links appear as files of mode
+// fs.ModeSymlink holding the link target, and submodules as empty
+// directories. Modification times are zero.
+//
+// A TreeFS is safe for concurrent use as long as its Repository is.
+type TreeFS struct {
+	repo *Repository
+	root Hash
+}
+
+// TreeFS returns an fs.FS view of the tree hash names, a tree or a commit.
+func (r *Repository) TreeFS(hash Hash) (*TreeFS, error) {
+	tree, err := r.peelTree(hash)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Free()
+
+	return &TreeFS{repo: r, root: HashFromOid(tree.Id())}, nil
+}
+
+// Open implements fs.FS.
+func (t *TreeFS) Open(name string) (fs.File, error) {
+	info, err := t.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		entries, readErr := t.readDir("open", name, info)
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		return &treeDir{info: info, entries: entries}, nil
+	}
+
+	data, err := t.readBlob("open", name, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &treeFile{info: info, Reader: bytes.NewReader(data)}, nil
+}
+
+// Stat implements fs.StatFS.
+func (t *TreeFS) Stat(name string) (fs.FileInfo, error) {
+	info, err := t.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (t *TreeFS) ReadFile(name string) ([]byte, error) {
+	info, err := t.stat("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: errIsDir}
+	}
+
+	return t.readBlob("readfile", name, info)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (t *TreeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	info, err := t.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errNotDir}
+	}
+
+	return t.readDir("readdir", name, info)
+}
+
+var (
+	errIsDir  = errors.New("is a directory")
+	errNotDir = errors.New("not a directory")
+)
+
+// stat resolves name to its tree entry.
+func (t *TreeFS) stat(op, name string) (*treeFileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return &treeFileInfo{name: ".", hash: t.root, mode: git2go.FilemodeTree}, nil
+	}
+
+	tree, err := t.repo.repo.LookupTree(t.root.ToOid())
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	defer tree.Free()
+
+	entry, err := tree.EntryByPath(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	info := &treeFileInfo{name: path.Base(name), hash: HashFromOid(entry.Id), mode: entry.Filemode}
+
+	if !info.IsDir() {
+		size, sizeErr := t.blobSize(info.hash)
+		if sizeErr != nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: sizeErr}
+		}
+
+		info.size = size
+	}
+
+	return info, nil
+}
+
+// blobSize reads the size of a blob from its object header.
+func (t *TreeFS) blobSize(hash Hash) (int64, error) {
+	odb, err := t.repo.repo.Odb()
+	if err != nil {
+		return 0, err
+	}
+	defer odb.Free()
+
+	size, _, err := odb.ReadHeader(hash.ToOid())
+	if err != nil {
+		// Missing from a partial clone: load the blob, which fetches it.
+		blob, lookupErr := t.repo.LookupBlob(context.Background(), hash)
+		if lookupErr != nil {
+			return 0, lookupErr
+		}
+		defer blob.Free()
+
+		return blob.Size(), nil
+	}
+
+	return int64(size), nil
+}
+
+func (t *TreeFS) readBlob(op, name string, info *treeFileInfo) ([]byte, error) {
+	blob, err := t.repo.LookupBlob(context.Background(), info.hash)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	defer blob.Free()
+
+	return bytes.Clone(blob.Contents()), nil
+}
+
+// readDir lists a directory in name order. Submodules list as empty.
+func (t *TreeFS) readDir(op, name string, info *treeFileInfo) ([]fs.DirEntry, error) {
+	if info.mode == git2go.FilemodeCommit {
+		return []fs.DirEntry{}, nil
+	}
+
+	tree, err := t.repo.repo.LookupTree(info.hash.ToOid())
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	defer tree.Free()
+
+	entries := make([]fs.DirEntry, 0, tree.EntryCount())
+
+	for i := range tree.EntryCount() {
+		entry := tree.EntryByIndex(i)
+		entries = append(entries, &treeDirEntry{
+			fs:   t,
+			info: &treeFileInfo{name: entry.Name, hash: HashFromOid(entry.Id), mode: entry.Filemode},
+		})
+	}
+
+	// Trees sort directories as if their names ended in "/"; fs wants plain name order.
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return entries, nil
+}
+
+// treeFileInfo describes one tree entry.
+type treeFileInfo struct {
+	name string
+	hash Hash
+	mode git2go.Filemode
+	size int64
+}
+
+func (i *treeFileInfo) Name() string       { return i.name }
+func (i *treeFileInfo) Size() int64        { return i.size }
+func (i *treeFileInfo) ModTime() time.Time { return time.Time{} }
+func (i *treeFileInfo) IsDir() bool        { return i.Mode().IsDir() }
+func (i *treeFileInfo) Sys() any           { return i.hash }
+
+func (i *treeFileInfo) Mode() fs.FileMode {
+	switch i.mode {
+	case git2go.FilemodeTree, git2go.FilemodeCommit:
+		return fs.ModeDir | materializeDirMode
+	case git2go.FilemodeLink:
+		return fs.ModeSymlink | fs.ModePerm
+	case git2go.FilemodeBlobExecutable:
+		return materializeExecMode
+	default:
+		return materializeFileMode
+	}
+}
+
+// treeDirEntry is a directory entry whose blob size is read on Info.
+type treeDirEntry struct {
+	fs   *TreeFS
+	info *treeFileInfo
+}
+
+func (e *treeDirEntry) Name() string      { return e.info.name }
+func (e *treeDirEntry) IsDir() bool       { return e.info.IsDir() }
+func (e *treeDirEntry) Type() fs.FileMode { return e.info.Mode().Type() }
+
+func (e *treeDirEntry) Info() (fs.FileInfo, error) {
+	if e.info.IsDir() {
+		return e.info, nil
+	}
+
+	size, err := e.fs.blobSize(e.info.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	info := *e.info
+	info.size = size
+
+	return &info, nil
+}
+
+// treeFile is an open file of a TreeFS.
+type treeFile struct {
+	*bytes.Reader
+
+	info *treeFileInfo
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *treeFile) Close() error               { return nil }
+
+// treeDir is an open directory of a TreeFS.
+type treeDir struct {
+	info    *treeFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *treeDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *treeDir) Close() error               { return nil }
+
+func (d *treeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errIsDir}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *treeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+
+	if n <= 0 {
+		d.offset = len(d.entries)
+
+		return rest, nil
+	}
+
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+
+	rest = rest[:min(n, len(rest))]
+	d.offset += len(rest)
+
+	return rest, nil
+}
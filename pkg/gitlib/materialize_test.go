@@ -0,0 +1,175 @@
+package gitlib_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	git2go "github.com/libgit2/git2go/v34"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// newMaterializeRepo creates a repository with two commits. The first has a
+// nested file, an executable script and a symbolic link; the second changes
+// the nested file. Returns the repository and the first commit.
+func newMaterializeRepo(t *testing.T) (*gitlib.Repository, gitlib.Hash) {
+	t.Helper()
+
+	tr := newTestRepo(t)
+	t.Cleanup(tr.cleanup)
+
+	tr.createFile("README.md", "# readme\n")
+	tr.createFile("src/main.go", "package main\n")
+	tr.createFile("run.sh", "#!/bin/sh\n")
+	require.NoError(t, os.Chmod(filepath.Join(tr.path, "run.sh"), 0o700)) //nolint:gosec // the test needs an executable file.
+	require.NoError(t, os.Symlink("README.md", filepath.Join(tr.path, "link")))
+
+	first := tr.commit("first")
+
+	tr.createFile("src/main.go", "package main\n\nfunc main() {}\n")
+	tr.commit("second")
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+	t.Cleanup(repo.Free)
+
+	return repo, first
+}
+
+func TestMaterializeTree_WritesRevision(t *testing.T) {
+	t.Parallel()
+
+	repo, first := newMaterializeRepo(t)
+	dir := filepath.Join(t.TempDir(), "out")
+
+	require.NoError(t, repo.MaterializeTree(context.Background(), first, dir))
+
+	data, err := os.ReadFile(filepath.Join(dir, "src", "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data))
+
+	info, err := os.Stat(filepath.Join(dir, "run.sh"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0o100, "executable bit should be kept")
+
+	target, err := os.Readlink(filepath.Join(dir, "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "README.md", target)
+
+	// Materializing again over the same directory replaces the files.
+	require.NoError(t, repo.MaterializeTree(context.Background(), first, dir))
+}
+
+func TestMaterializeTree_CanceledContext(t *testing.T) {
+	t.Parallel()
+
+	repo, first := newMaterializeRepo(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := repo.MaterializeTree(ctx, first, t.TempDir())
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMaterializeTree_ReplacesSymlinkedDirectory(t *testing.T) {
+	t.Parallel()
+
+	repo, first := newMaterializeRepo(t)
+	dir := filepath.Join(t.TempDir(), "out")
+	outside := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(dir, 0o750))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "src")))
+
+	require.NoError(t, repo.MaterializeTree(context.Background(), first, dir))
+
+	info, err := os.Lstat(filepath.Join(dir, "src"))
+	require.NoError(t, err)
+	assert.True(t, info.IsDir(), "the symbolic link is replaced by a directory")
+	assert.FileExists(t, filepath.Join(dir, "src", "main.go"))
+
+	entries, err := os.ReadDir(outside)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing is written through the link")
+}
+
+// treeWithEntries writes a tree holding an empty blob under each name.
+func treeWithEntries(t *testing.T, tr *testRepo, names ...string) (gitlib.Hash, error) {
+	t.Helper()
+
+	blob, err := tr.native.CreateBlobFromBuffer(nil)
+	require.NoError(t, err)
+
+	builder, err := tr.native.TreeBuilder()
+	require.NoError(t, err)
+
+	defer builder.Free()
+
+	for _, name := range names {
+		err = builder.Insert(name, blob, git2go.FilemodeBlob)
+		if err != nil {
+			return gitlib.Hash{}, err
+		}
+	}
+
+	oid, err := builder.Write()
+	require.NoError(t, err)
+
+	return gitlib.HashFromOid(oid), nil
+}
+
+func TestMaterializeTree_RejectsCaseCollisions(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	t.Cleanup(tr.cleanup)
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+	t.Cleanup(repo.Free)
+
+	tree, err := treeWithEntries(t, tr, "Makefile", "makefile")
+	require.NoError(t, err)
+
+	err = repo.MaterializeTree(context.Background(), tree, t.TempDir())
+	require.ErrorIs(t, err, gitlib.ErrUnsafeTreeEntry)
+
+	for _, name := range []string{".GIT", ".git.", ".git ", "GIT~1"} {
+		tree, insertErr := treeWithEntries(t, tr, name)
+		if insertErr != nil {
+			continue // libgit2 already refuses to write the name.
+		}
+
+		err = repo.MaterializeTree(context.Background(), tree, t.TempDir())
+		require.ErrorIs(t, err, gitlib.ErrUnsafeTreeEntry, name)
+	}
+}
+
+func TestTreeFS_ConformsToFS(t *testing.T) {
+	t.Parallel()
+
+	repo, first := newMaterializeRepo(t)
+
+	treeFS, err := repo.TreeFS(first)
+	require.NoError(t, err)
+
+	require.NoError(t, fstest.TestFS(treeFS, "README.md", "src/main.go", "run.sh", "link"))
+
+	data, err := fs.ReadFile(treeFS, "src/main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data))
+
+	info, err := fs.Stat(treeFS, "link")
+	require.NoError(t, err)
+	assert.Equal(t, fs.ModeSymlink, info.Mode().Type())
+
+	_, err = treeFS.Open("missing.go")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -0,0 +1,47 @@
+package gitlib
+
+import (
+	"fmt"
+
+	git2go "github.com/libgit2/git2go/v34"
+)
+
+// NotesRef returns the notes reference git notes reads by default:
+// core.notesRef when set, refs/notes/commits otherwise.
+func (r *Repository) NotesRef() (string, error) {
+	ref, err := r.repo.Notes.DefaultRef()
+	if err != nil {
+		return "", fmt.Errorf("notes ref: %w", err)
+	}
+
+	return ref, nil
+}
+
+// HasNotes reports whether the notes reference ref exists, so callers can
+// skip per-commit note lookups in repositories without notes.
+func (r *Repository) HasNotes(ref string) bool {
+	reference, err := r.repo.References.Lookup(ref)
+	if err != nil {
+		return false
+	}
+
+	reference.Free()
+
+	return true
+}
+
+// Note returns the note attached to commit under the notes reference ref,
+// or "" when the commit has none.
+func (r *Repository) Note(ref string, commit Hash) (string, error) {
+	note, err := r.repo.Notes.Read(ref, commit.ToOid())
+	if git2go.IsErrorCode(err, git2go.ErrorCodeNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("read note: %w", err)
+	}
+	defer note.Free()
+
+	return note.Message(), nil
+}
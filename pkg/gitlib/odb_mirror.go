@@ -0,0 +1,283 @@
+package gitlib
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	git2go "github.com/libgit2/git2go/v34"
+)
+
+// ODB mirror layout and lookup priority.
+const (
+	// odbMirrorPriority puts the mirror ahead of the default loose (1) and
+	// packed (2) backends of libgit2.
+	odbMirrorPriority = 10
+
+	// odbMirrorKeySize is the number of hex digits of the objects directory
+	// hash naming its mirror directory.
+	odbMirrorKeySize = 16
+
+	odbMirrorTmpSuffix = ".tmp"
+	odbMirrorDirMode   = 0o750
+)
+
+// odbMirrors maps the absolute path of a repository to its active mirror.
+var odbMirrors = struct {
+	sync.Mutex
+	byPath map[string]*ODBMirror
+}{byPath: make(map[string]*ODBMirror)}
+
+// ODBMirror is a read-only copy of the pack files of a repository on fast
+// local storage. While it is open, every Repository opened at the same path
+// looks objects up in the mirror first. Objects the mirror lacks, such as
+// loose objects written after it was made, still come from the repository.
+//
+// Mirrors persist in their cache directory and are refreshed by the next
+// MirrorODB call: packs that changed are copied again and packs that no
+// longer exist in the repository are removed.
+type ODBMirror struct {
+	repoPath string
+	dir      string
+	indexes  []string
+	stats    ODBMirrorStats
+}
+
+// ODBMirrorStats describes the work done by MirrorODB.
+type ODBMirrorStats struct {
+	// Packs is the number of packs in the mirror and Bytes their total size.
+	Packs int
+	Bytes int64
+
+	// CopiedBytes is the size of the packs copied by this call; the rest
+	// were reused from an earlier run.
+	CopiedBytes int64
+
+	// Removed is the number of stale files deleted from the mirror.
+	Removed int
+}
+
+// MirrorODB copies the pack files of the repository at repoPath into a
+// directory under cacheDir and opens the mirror. Call Close when the
+// repositories opened at repoPath are no longer used.
+func MirrorODB(ctx context.Context, repoPath, cacheDir string) (*ODBMirror, error) {
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("odb mirror: %w", err)
+	}
+
+	repo, err := git2go.OpenRepository(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("odb mirror: open repository: %w", err)
+	}
+
+	objectsDir := filepath.Join(repo.Path(), "objects")
+	repo.Free()
+
+	sum := sha256.Sum256([]byte(objectsDir))
+
+	m := &ODBMirror{
+		repoPath: absPath,
+		dir:      filepath.Join(cacheDir, hex.EncodeToString(sum[:])[:odbMirrorKeySize]),
+	}
+
+	err = os.MkdirAll(m.dir, odbMirrorDirMode)
+	if err != nil {
+		return nil, fmt.Errorf("odb mirror: %w", err)
+	}
+
+	err = m.sync(ctx, filepath.Join(objectsDir, "pack"))
+	if err != nil {
+		return nil, err
+	}
+
+	odbMirrors.Lock()
+	odbMirrors.byPath[absPath] = m
+	odbMirrors.Unlock()
+
+	return m, nil
+}
+
+// Dir returns the directory holding the mirrored packs.
+func (m *ODBMirror) Dir() string {
+	return m.dir
+}
+
+// Stats returns what MirrorODB copied, reused and removed.
+func (m *ODBMirror) Stats() ODBMirrorStats {
+	return m.stats
+}
+
+// Close stops attaching the mirror to newly opened repositories. The
+// mirrored files stay in place for the next run.
+func (m *ODBMirror) Close() {
+	odbMirrors.Lock()
+	defer odbMirrors.Unlock()
+
+	if odbMirrors.byPath[m.repoPath] == m {
+		delete(odbMirrors.byPath, m.repoPath)
+	}
+}
+
+// sync brings the mirror in line with the packs of packDir.
+func (m *ODBMirror) sync(ctx context.Context, packDir string) error {
+	sources, err := filepath.Glob(filepath.Join(packDir, "pack-*.idx"))
+	if err != nil {
+		return fmt.Errorf("odb mirror: list packs: %w", err)
+	}
+
+	keep := make(map[string]bool, 2*len(sources))
+
+	for _, idx := range sources {
+		pack := strings.TrimSuffix(idx, ".idx") + ".pack"
+
+		// The index goes last: a mirrored index means its pack is complete.
+		for _, src := range []string{pack, idx} {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			size, copied, copyErr := mirrorFile(src, filepath.Join(m.dir, filepath.Base(src)))
+			if copyErr != nil {
+				return copyErr
+			}
+
+			keep[filepath.Base(src)] = true
+			m.stats.Bytes += size
+
+			if copied {
+				m.stats.CopiedBytes += size
+			}
+		}
+
+		m.indexes = append(m.indexes, filepath.Join(m.dir, filepath.Base(idx)))
+		m.stats.Packs++
+	}
+
+	return m.removeStale(keep)
+}
+
+// removeStale deletes mirrored files whose pack left the repository, and
+// partial copies of an interrupted run.
+func (m *ODBMirror) removeStale(keep map[string]bool) error {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return fmt.Errorf("odb mirror: %w", err)
+	}
+
+	for _, entry := range entries {
+		if keep[entry.Name()] {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(m.dir, entry.Name()))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("odb mirror: remove stale file: %w", err)
+		}
+
+		m.stats.Removed++
+	}
+
+	return nil
+}
+
+// mirrorFile copies src to dst unless dst already has the size and
+// modification time of src. The copy is written to a temporary file and
+// renamed into place. Returns the size of src and whether it was copied.
+func mirrorFile(src, dst string) (size int64, copied bool, err error) {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return 0, false, fmt.Errorf("odb mirror: %w", err)
+	}
+
+	dstInfo, statErr := os.Stat(dst)
+	if statErr == nil && dstInfo.Size() == srcInfo.Size() && dstInfo.ModTime().Equal(srcInfo.ModTime()) {
+		return srcInfo.Size(), false, nil
+	}
+
+	tmp := dst + odbMirrorTmpSuffix
+
+	err = copyFile(src, tmp)
+	if err == nil {
+		err = os.Chtimes(tmp, srcInfo.ModTime(), srcInfo.ModTime())
+	}
+
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+
+	if err != nil {
+		os.Remove(tmp)
+
+		return 0, false, fmt.Errorf("odb mirror: copy %s: %w", filepath.Base(src), err)
+	}
+
+	return srcInfo.Size(), true, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+
+		return err
+	}
+
+	return out.Close()
+}
+
+// attachODBMirror adds the packs of the mirror registered for path, if any,
+// as the first object backends of repo.
+func attachODBMirror(repo *git2go.Repository, path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil //nolint:nilerr // a path that cannot be resolved has no mirror.
+	}
+
+	odbMirrors.Lock()
+	m := odbMirrors.byPath[absPath]
+	odbMirrors.Unlock()
+
+	if m == nil {
+		return nil
+	}
+
+	odb, err := repo.Odb()
+	if err != nil {
+		return fmt.Errorf("odb mirror: %w", err)
+	}
+	defer odb.Free()
+
+	for _, idx := range m.indexes {
+		backend, backendErr := git2go.NewOdbBackendOnePack(idx)
+		if backendErr != nil {
+			return fmt.Errorf("odb mirror: %s: %w", filepath.Base(idx), backendErr)
+		}
+
+		// AddBackend takes ownership of the backend, and frees it on failure.
+		addErr := odb.AddBackend(backend, odbMirrorPriority)
+		if addErr != nil {
+			return fmt.Errorf("odb mirror: %s: %w", filepath.Base(idx), addErr)
+		}
+	}
+
+	return nil
+}
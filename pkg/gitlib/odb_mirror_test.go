@@ -0,0 +1,108 @@
+package gitlib_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// newPackedRepo creates a repository with one commit whose objects live only
+// in a pack file. Returns the repository and the commit hash.
+func newPackedRepo(t *testing.T) (*testRepo, gitlib.Hash) {
+	t.Helper()
+
+	tr := newTestRepo(t)
+	t.Cleanup(tr.cleanup)
+
+	tr.createFile("main.go", "package main\n")
+	head := tr.commit("initial")
+
+	pb, err := tr.native.NewPackbuilder()
+	require.NoError(t, err)
+
+	defer pb.Free()
+
+	objects := filepath.Join(tr.path, ".git", "objects")
+
+	require.NoError(t, pb.InsertCommit(head.ToOid()))
+	require.NoError(t, pb.WriteToFile(filepath.Join(objects, "pack"), 0o600))
+
+	loose, err := filepath.Glob(filepath.Join(objects, "[0-9a-f][0-9a-f]"))
+	require.NoError(t, err)
+
+	for _, dir := range loose {
+		require.NoError(t, os.RemoveAll(dir))
+	}
+
+	return tr, head
+}
+
+func TestMirrorODB_ReadsFromMirror(t *testing.T) {
+	t.Parallel()
+
+	tr, head := newPackedRepo(t)
+	cacheDir := t.TempDir()
+
+	mirror, err := gitlib.MirrorODB(context.Background(), tr.path, cacheDir)
+	require.NoError(t, err)
+
+	stats := mirror.Stats()
+	assert.Equal(t, 1, stats.Packs)
+	assert.Equal(t, stats.Bytes, stats.CopiedBytes)
+
+	// With the repository's own packs gone, objects can only come from the mirror.
+	packs, err := filepath.Glob(filepath.Join(tr.path, ".git", "objects", "pack", "pack-*"))
+	require.NoError(t, err)
+
+	for _, p := range packs {
+		require.NoError(t, os.Remove(p))
+	}
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	commit, err := repo.LookupCommit(context.Background(), head)
+	require.NoError(t, err)
+	assert.Equal(t, "initial", commit.Message())
+	commit.Free()
+	repo.Free()
+
+	mirror.Close()
+
+	repo, err = gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	_, err = repo.LookupCommit(context.Background(), head)
+	require.Error(t, err, "a closed mirror is not attached")
+}
+
+func TestMirrorODB_ReusesAndCleans(t *testing.T) {
+	t.Parallel()
+
+	tr, _ := newPackedRepo(t)
+	cacheDir := t.TempDir()
+
+	first, err := gitlib.MirrorODB(context.Background(), tr.path, cacheDir)
+	require.NoError(t, err)
+	first.Close()
+
+	stale := filepath.Join(first.Dir(), "pack-gone.idx")
+	require.NoError(t, os.WriteFile(stale, []byte("old"), 0o600))
+
+	second, err := gitlib.MirrorODB(context.Background(), tr.path, cacheDir)
+	require.NoError(t, err)
+	second.Close()
+
+	assert.Equal(t, first.Dir(), second.Dir())
+	assert.Zero(t, second.Stats().CopiedBytes, "unchanged packs are reused")
+	assert.Equal(t, 1, second.Stats().Removed)
+	assert.NoFileExists(t, stale)
+}
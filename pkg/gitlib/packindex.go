@@ -0,0 +1,252 @@
+package gitlib
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// Pack index (.idx) version 2 layout. See gitformat-pack(5).
+const (
+	packIdxHeaderSize   = 8
+	packIdxFanoutSize   = 256 * 4
+	packIdxCRCSize      = 4
+	packIdxOffsetSize   = 4
+	packIdxLargeSize    = 8
+	packIdxTrailerSize  = 2 * HashSize
+	packIdxVersion      = 2
+	packIdxLargeOffset  = 1 << 31
+	packIdxOffsetMask   = packIdxLargeOffset - 1
+	packIdxFanoutOffset = packIdxHeaderSize
+	packIdxNamesOffset  = packIdxFanoutOffset + packIdxFanoutSize
+)
+
+// packIdxMagic opens every pack index from version 2 on.
+var packIdxMagic = []byte{0xff, 't', 'O', 'c'}
+
+// ErrInvalidPackIndex is returned for a pack index file that is truncated,
+// corrupt or not in the version 2 format.
+var ErrInvalidPackIndex = errors.New("invalid pack index")
+
+// PackPosition is the location of an object in the pack files of a repository.
+type PackPosition struct {
+	// Pack is the position of the pack in PackIndex load order.
+	Pack int
+
+	// Offset is the byte offset of the object in its pack.
+	Offset uint64
+}
+
+// PackIndex locates objects in the pack files of a repository by reading the
+// pack index files next to them. Callers use it to read objects in pack
+// order, which turns random object access into mostly sequential reads.
+//
+// The index files are held in memory, about 28 bytes per packed object.
+type PackIndex struct {
+	packs []packIdx
+}
+
+// packIdx is one loaded version 2 pack index file.
+type packIdx struct {
+	data  []byte
+	count int
+}
+
+// PackIndex loads the pack index of the repository's object database.
+// Loose objects and objects of alternates are not indexed.
+func (r *Repository) PackIndex() (*PackIndex, error) {
+	return LoadPackIndex(filepath.Join(r.repo.Path(), "objects", "pack"))
+}
+
+// LoadPackIndex loads every pack-*.idx file of a pack directory. A missing
+// directory yields an empty index.
+func LoadPackIndex(packDir string) (*PackIndex, error) {
+	names, err := filepath.Glob(filepath.Join(packDir, "pack-*.idx"))
+	if err != nil {
+		return nil, fmt.Errorf("list pack indexes: %w", err)
+	}
+
+	slices.Sort(names)
+
+	idx := &PackIndex{packs: make([]packIdx, 0, len(names))}
+
+	for _, name := range names {
+		data, readErr := os.ReadFile(name)
+		if readErr != nil {
+			return nil, fmt.Errorf("read pack index: %w", readErr)
+		}
+
+		pack, parseErr := parsePackIdx(data)
+		if parseErr != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(name), parseErr)
+		}
+
+		idx.packs = append(idx.packs, pack)
+	}
+
+	return idx, nil
+}
+
+// parsePackIdx validates the header, fanout table, size and large offsets of
+// a version 2 pack index, so that locate never reads out of bounds.
+func parsePackIdx(data []byte) (packIdx, error) {
+	if len(data) < packIdxNamesOffset+packIdxTrailerSize ||
+		!bytes.Equal(data[:len(packIdxMagic)], packIdxMagic) ||
+		binary.BigEndian.Uint32(data[len(packIdxMagic):packIdxHeaderSize]) != packIdxVersion {
+		return packIdx{}, ErrInvalidPackIndex
+	}
+
+	p := packIdx{data: data}
+
+	prev := uint32(0)
+	for b := range packIdxFanoutSize / 4 {
+		next := p.fanout(b)
+		if next < prev {
+			return packIdx{}, fmt.Errorf("%w: fanout entry %d decreases", ErrInvalidPackIndex, b)
+		}
+
+		prev = next
+	}
+
+	p.count = int(prev)
+
+	offsets := packIdxNamesOffset + p.count*(HashSize+packIdxCRCSize)
+	large := offsets + p.count*packIdxOffsetSize
+
+	if len(data) < large+packIdxTrailerSize {
+		return packIdx{}, fmt.Errorf("%w: %d objects do not fit in %d bytes", ErrInvalidPackIndex, p.count, len(data))
+	}
+
+	largeCount := (len(data) - large - packIdxTrailerSize) / packIdxLargeSize
+
+	for i := range p.count {
+		at := offsets + i*packIdxOffsetSize
+
+		small := binary.BigEndian.Uint32(data[at : at+packIdxOffsetSize])
+		if small&packIdxLargeOffset != 0 && int(small&packIdxOffsetMask) >= largeCount {
+			return packIdx{}, fmt.Errorf("%w: object %d has no large offset entry", ErrInvalidPackIndex, i)
+		}
+	}
+
+	return p, nil
+}
+
+// Packs returns the number of pack files indexed.
+func (idx *PackIndex) Packs() int {
+	return len(idx.packs)
+}
+
+// Objects returns the number of packed objects indexed.
+func (idx *PackIndex) Objects() int {
+	total := 0
+
+	for _, p := range idx.packs {
+		total += p.count
+	}
+
+	return total
+}
+
+// Locate returns the pack position of an object. ok is false for objects
+// that are not in any indexed pack.
+func (idx *PackIndex) Locate(hash Hash) (pos PackPosition, ok bool) {
+	for i, p := range idx.packs {
+		offset, found := p.locate(hash)
+		if found {
+			return PackPosition{Pack: i, Offset: offset}, true
+		}
+	}
+
+	return PackPosition{}, false
+}
+
+// Sort orders hashes by their position in the packs. Objects outside the
+// packs go last, in hash order.
+func (idx *PackIndex) Sort(hashes []Hash) {
+	type located struct {
+		hash   Hash
+		pos    PackPosition
+		packed bool
+	}
+
+	keyed := make([]located, len(hashes))
+
+	for i, h := range hashes {
+		pos, ok := idx.Locate(h)
+		keyed[i] = located{hash: h, pos: pos, packed: ok}
+	}
+
+	slices.SortFunc(keyed, func(a, b located) int {
+		if a.packed != b.packed {
+			if a.packed {
+				return -1
+			}
+
+			return 1
+		}
+
+		return cmp.Or(
+			cmp.Compare(a.pos.Pack, b.pos.Pack),
+			cmp.Compare(a.pos.Offset, b.pos.Offset),
+			bytes.Compare(a.hash[:], b.hash[:]),
+		)
+	})
+
+	for i, k := range keyed {
+		hashes[i] = k.hash
+	}
+}
+
+// locate binary searches the names between the fanout bounds of hash.
+func (p packIdx) locate(hash Hash) (uint64, bool) {
+	lo := 0
+	if hash[0] > 0 {
+		lo = int(p.fanout(int(hash[0]) - 1))
+	}
+
+	hi := int(p.fanout(int(hash[0])))
+
+	i := lo + sort.Search(hi-lo, func(k int) bool {
+		return bytes.Compare(p.name(lo+k), hash[:]) >= 0
+	})
+	if i >= hi || !bytes.Equal(p.name(i), hash[:]) {
+		return 0, false
+	}
+
+	return p.offset(i), true
+}
+
+func (p packIdx) fanout(b int) uint32 {
+	at := packIdxFanoutOffset + b*4
+
+	return binary.BigEndian.Uint32(p.data[at : at+4])
+}
+
+// name returns the object name at position i.
+func (p packIdx) name(i int) []byte {
+	at := packIdxNamesOffset + i*HashSize
+
+	return p.data[at : at+HashSize]
+}
+
+// offset returns the pack offset of the object at position i, following
+// the large offset table for packs over 2 GiB.
+func (p packIdx) offset(i int) uint64 {
+	offsets := packIdxNamesOffset + p.count*(HashSize+packIdxCRCSize)
+	at := offsets + i*packIdxOffsetSize
+	small := binary.BigEndian.Uint32(p.data[at : at+packIdxOffsetSize])
+
+	if small&packIdxLargeOffset == 0 {
+		return uint64(small)
+	}
+
+	large := offsets + p.count*packIdxOffsetSize + int(small&packIdxOffsetMask)*packIdxLargeSize
+
+	return binary.BigEndian.Uint64(p.data[large : large+packIdxLargeSize])
+}
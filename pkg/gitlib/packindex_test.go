@@ -0,0 +1,204 @@
+package gitlib_test
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// writePackIdx writes a version 2 pack index for objects at the given
+// offsets. Offsets of 2 GiB and up go to the large offset table.
+func writePackIdx(t *testing.T, dir, name string, offsets map[gitlib.Hash]uint64) {
+	t.Helper()
+
+	hashes := make([]gitlib.Hash, 0, len(offsets))
+	for h := range offsets {
+		hashes = append(hashes, h)
+	}
+
+	slices.SortFunc(hashes, func(a, b gitlib.Hash) int { return slices.Compare(a[:], b[:]) })
+
+	data := []byte{0xff, 't', 'O', 'c', 0, 0, 0, 2}
+
+	for b := range 256 {
+		count := 0
+
+		for _, h := range hashes {
+			if int(h[0]) <= b {
+				count++
+			}
+		}
+
+		data = binary.BigEndian.AppendUint32(data, uint32(count))
+	}
+
+	var large []uint64
+
+	for _, h := range hashes {
+		data = append(data, h[:]...)
+	}
+
+	data = append(data, make([]byte, 4*len(hashes))...) // CRCs.
+
+	for _, h := range hashes {
+		off := offsets[h]
+		if off >= 1<<31 {
+			data = binary.BigEndian.AppendUint32(data, uint32(1<<31|len(large)))
+			large = append(large, off)
+
+			continue
+		}
+
+		data = binary.BigEndian.AppendUint32(data, uint32(off))
+	}
+
+	for _, off := range large {
+		data = binary.BigEndian.AppendUint64(data, off)
+	}
+
+	data = append(data, make([]byte, 2*gitlib.HashSize)...) // Checksums.
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+}
+
+func packHash(first, last byte) gitlib.Hash {
+	var h gitlib.Hash
+
+	h[0] = first
+	h[gitlib.HashSize-1] = last
+
+	return h
+}
+
+func TestLoadPackIndex_Locate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writePackIdx(t, dir, "pack-a.idx", map[gitlib.Hash]uint64{
+		packHash(0x00, 1): 900,
+		packHash(0x7f, 1): 12,
+		packHash(0x7f, 2): 5 << 30,
+	})
+	writePackIdx(t, dir, "pack-b.idx", map[gitlib.Hash]uint64{
+		packHash(0xff, 1): 40,
+	})
+
+	idx, err := gitlib.LoadPackIndex(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, idx.Packs())
+	assert.Equal(t, 4, idx.Objects())
+
+	pos, ok := idx.Locate(packHash(0x7f, 2))
+	require.True(t, ok)
+	assert.Equal(t, gitlib.PackPosition{Pack: 0, Offset: 5 << 30}, pos)
+
+	pos, ok = idx.Locate(packHash(0xff, 1))
+	require.True(t, ok)
+	assert.Equal(t, gitlib.PackPosition{Pack: 1, Offset: 40}, pos)
+
+	_, ok = idx.Locate(packHash(0x7f, 3))
+	assert.False(t, ok)
+}
+
+func TestPackIndex_Sort(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writePackIdx(t, dir, "pack-a.idx", map[gitlib.Hash]uint64{
+		packHash(0x10, 1): 300,
+		packHash(0x20, 1): 100,
+		packHash(0x30, 1): 200,
+	})
+
+	idx, err := gitlib.LoadPackIndex(dir)
+	require.NoError(t, err)
+
+	loose := packHash(0x01, 9)
+	hashes := []gitlib.Hash{loose, packHash(0x10, 1), packHash(0x30, 1), packHash(0x20, 1)}
+
+	idx.Sort(hashes)
+
+	assert.Equal(t, []gitlib.Hash{packHash(0x20, 1), packHash(0x30, 1), packHash(0x10, 1), loose}, hashes)
+}
+
+func TestLoadPackIndex_Invalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pack-v1.idx"), make([]byte, 2048), 0o600))
+
+	_, err := gitlib.LoadPackIndex(dir)
+	require.ErrorIs(t, err, gitlib.ErrInvalidPackIndex)
+
+	idx, err := gitlib.LoadPackIndex(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Zero(t, idx.Packs())
+}
+
+func TestLoadPackIndex_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	const (
+		fanoutAt = 8
+		countAt  = fanoutAt + 255*4
+	)
+
+	corruptions := map[string]func(data []byte) []byte{
+		"decreasing fanout": func(data []byte) []byte {
+			binary.BigEndian.PutUint32(data[fanoutAt+0x10*4:], 3)
+
+			return data
+		},
+		"count past the end": func(data []byte) []byte {
+			binary.BigEndian.PutUint32(data[countAt:], 1000)
+
+			return data
+		},
+		"missing large offset": func(data []byte) []byte {
+			return append(data[:len(data)-2*gitlib.HashSize-8], make([]byte, 2*gitlib.HashSize)...)
+		},
+	}
+
+	for name, corrupt := range corruptions {
+		dir := t.TempDir()
+		writePackIdx(t, dir, "pack-a.idx", map[gitlib.Hash]uint64{
+			packHash(0x00, 1): 12,
+			packHash(0x20, 1): 5 << 30,
+		})
+
+		path := filepath.Join(dir, "pack-a.idx")
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, corrupt(data), 0o600))
+
+		_, err = gitlib.LoadPackIndex(dir)
+		require.ErrorIs(t, err, gitlib.ErrInvalidPackIndex, name)
+	}
+}
+
+func TestRepository_PackIndex(t *testing.T) {
+	t.Parallel()
+
+	tr, head := newPackedRepo(t)
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	idx, err := repo.PackIndex()
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx.Packs())
+	assert.Equal(t, 3, idx.Objects(), "commit, tree and blob")
+
+	pos, ok := idx.Locate(head)
+	require.True(t, ok)
+	assert.Positive(t, pos.Offset)
+}
@@ -0,0 +1,254 @@
+package gitlib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	git2go "github.com/libgit2/git2go/v34"
+)
+
+// promisorFetchBatchSize caps the objects requested by one git fetch.
+const promisorFetchBatchSize = 5000
+
+// ErrGitNotFound is returned when missing objects of a partial clone must be
+// fetched but no git executable is on the PATH.
+var ErrGitNotFound = errors.New("git executable not found; it is needed to fetch objects of a partial clone")
+
+// ErrInvalidPromisorRemote is returned when the promisor remote name of a
+// partial clone's config would be parsed by git as an option.
+var ErrInvalidPromisorRemote = errors.New("invalid promisor remote name")
+
+// promisors maps the git directory of a partial clone to its fetcher, so
+// every repository handle of a run shares one fetcher and its counters.
+var promisors = struct {
+	sync.Mutex
+	byGitDir map[string]*PromisorFetcher
+}{byGitDir: make(map[string]*PromisorFetcher)}
+
+// PromisorFetcher fetches the objects a partial clone, such as one made with
+// "git clone --filter=blob:none", left out from its promisor remote. libgit2
+// cannot fetch them itself, so the fetcher runs "git fetch" the way git does
+// for its own lazy fetches, with the object IDs of a whole batch at once.
+// Fetches of one repository are serialized.
+type PromisorFetcher struct {
+	gitDir string
+	remote string
+
+	mu sync.Mutex
+
+	fetches  atomic.Int64
+	objects  atomic.Int64
+	failures atomic.Int64
+	duration atomic.Int64
+}
+
+// PromisorStats counts the lazy fetches of a partial clone.
+type PromisorStats struct {
+	// Remote is the promisor remote objects are fetched from.
+	Remote string
+
+	// Fetches is the number of git fetch runs and Objects the number of
+	// objects requested by them. Failures counts the runs that failed.
+	Fetches  int64
+	Objects  int64
+	Failures int64
+
+	// Duration is the total time spent fetching.
+	Duration time.Duration
+}
+
+// Promisor returns the fetcher of a partial clone, or nil for a repository
+// with every object present.
+func (r *Repository) Promisor() *PromisorFetcher {
+	return r.promisor
+}
+
+// promisorFor returns the shared fetcher of repo when it is a partial clone.
+func promisorFor(repo *git2go.Repository) *PromisorFetcher {
+	remote := promisorRemote(repo)
+	if remote == "" {
+		return nil
+	}
+
+	gitDir := filepath.Clean(repo.Path())
+
+	promisors.Lock()
+	defer promisors.Unlock()
+
+	f := promisors.byGitDir[gitDir]
+	if f == nil {
+		f = &PromisorFetcher{gitDir: gitDir, remote: remote}
+		promisors.byGitDir[gitDir] = f
+	}
+
+	return f
+}
+
+// promisorRemote returns the promisor remote of a partial clone, or "".
+// git records it as extensions.partialClone, and from 2.29 on also marks the
+// remote with remote.<name>.promisor.
+func promisorRemote(repo *git2go.Repository) string {
+	cfg, err := repo.Config()
+	if err != nil {
+		return ""
+	}
+	defer cfg.Free()
+
+	remote, err := cfg.LookupString("extensions.partialclone")
+	if err == nil && remote != "" {
+		return remote
+	}
+
+	iter, err := cfg.NewIteratorGlob(`remote\..*\.promisor`)
+	if err != nil {
+		return ""
+	}
+	defer iter.Free()
+
+	for entry, nextErr := iter.Next(); nextErr == nil; entry, nextErr = iter.Next() {
+		if strings.EqualFold(entry.Value, "true") {
+			return strings.TrimSuffix(strings.TrimPrefix(entry.Name, "remote."), ".promisor")
+		}
+	}
+
+	return ""
+}
+
+// Fetch downloads the given objects from the promisor remote, in batches of
+// up to 5000 objects per git fetch.
+func (f *PromisorFetcher) Fetch(ctx context.Context, hashes []Hash) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for start := 0; start < len(hashes); start += promisorFetchBatchSize {
+		err := f.fetchBatch(ctx, hashes[start:min(start+promisorFetchBatchSize, len(hashes))])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchBatch runs one git fetch for hashes. Must be called with mu held.
+func (f *PromisorFetcher) fetchBatch(ctx context.Context, hashes []Hash) error {
+	start := time.Now()
+
+	f.fetches.Add(1)
+	f.objects.Add(int64(len(hashes)))
+
+	defer func() { f.duration.Add(int64(time.Since(start))) }()
+
+	// The name comes from repository config, which a cloned or unpacked
+	// repository may carry from an untrusted source.
+	if strings.HasPrefix(f.remote, "-") {
+		f.failures.Add(1)
+
+		return fmt.Errorf("%w: %q", ErrInvalidPromisorRemote, f.remote)
+	}
+
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		f.failures.Add(1)
+
+		return ErrGitNotFound
+	}
+
+	var stdin bytes.Buffer
+
+	for _, h := range hashes {
+		stdin.WriteString(h.String())
+		stdin.WriteByte('\n')
+	}
+
+	// The flags git itself passes for a lazy fetch of missing objects.
+	cmd := exec.CommandContext(ctx, gitPath, "--git-dir="+f.gitDir,
+		"-c", "fetch.negotiationAlgorithm=noop",
+		"fetch", "--no-tags", "--no-write-fetch-head", "--recurse-submodules=no",
+		"--filter=blob:none", "--stdin", "--", f.remote)
+	cmd.Stdin = &stdin
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		f.failures.Add(1)
+
+		return fmt.Errorf("fetch %d objects from promisor remote %s: %w: %s",
+			len(hashes), f.remote, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// Stats returns the fetch counters.
+func (f *PromisorFetcher) Stats() PromisorStats {
+	return PromisorStats{
+		Remote:   f.remote,
+		Fetches:  f.fetches.Load(),
+		Objects:  f.objects.Load(),
+		Failures: f.failures.Load(),
+		Duration: time.Duration(f.duration.Load()),
+	}
+}
+
+// fetchMissing fetches the blobs of results that failed lookup from the
+// promisor remote of a partial clone, in one batch, and loads them again.
+// Results are returned unchanged for a complete repository or when the
+// fetch fails.
+func (b *CGOBridge) fetchMissing(ctx context.Context, results []BlobResult) []BlobResult {
+	promisor := b.repo.Promisor()
+	if promisor == nil {
+		return results
+	}
+
+	var missing []int
+
+	for i := range results {
+		if errors.Is(results[i].Error, ErrBlobLookup) {
+			missing = append(missing, i)
+		}
+	}
+
+	if len(missing) == 0 {
+		return results
+	}
+
+	hashes := make([]Hash, len(missing))
+	for j, i := range missing {
+		hashes[j] = results[i].Hash
+	}
+
+	err := promisor.Fetch(ctx, hashes)
+	if err != nil {
+		return results
+	}
+
+	b.repo.refreshODB()
+
+	for j, res := range b.BatchLoadBlobs(hashes) {
+		results[missing[j]] = res
+	}
+
+	return results
+}
+
+// refreshODB makes libgit2 pick up packs written by another process.
+func (r *Repository) refreshODB() {
+	odb, err := r.repo.Odb()
+	if err != nil {
+		return
+	}
+	defer odb.Free()
+
+	// A failed refresh only means the retried lookups miss.
+	_ = odb.Refresh()
+}
@@ -0,0 +1,170 @@
+package gitlib_test
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// newPartialClone clones a one-commit repository with --filter=blob:none.
+// Returns the clone path and the hash of its only blob, which the clone
+// does not have.
+func newPartialClone(t *testing.T) (clonePath string, blobHash gitlib.Hash) {
+	t.Helper()
+
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git executable not found")
+	}
+
+	origin := newTestRepo(t)
+	t.Cleanup(origin.cleanup)
+
+	origin.createFile("main.go", "package main\n")
+	origin.commit("initial")
+
+	cfg, err := origin.native.Config()
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetBool("uploadpack.allowFilter", true))
+	cfg.Free()
+
+	obj, err := origin.native.RevparseSingle("HEAD:main.go")
+	require.NoError(t, err)
+
+	blobHash = gitlib.HashFromOid(obj.Id())
+	obj.Free()
+
+	clonePath = filepath.Join(t.TempDir(), "clone")
+
+	out, err := exec.CommandContext(context.Background(), gitPath, "clone", "--quiet", "--no-checkout",
+		"--filter=blob:none", "file://"+origin.path, clonePath).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	return clonePath, blobHash
+}
+
+func TestOpenRepository_PartialCloneExtension(t *testing.T) {
+	t.Parallel()
+
+	clonePath, _ := newPartialClone(t)
+
+	out, err := exec.CommandContext(context.Background(), "git", "-C", clonePath,
+		"config", "extensions.partialclone").Output()
+	require.NoError(t, err, "git clone --filter records the partialclone extension")
+	assert.Equal(t, "origin\n", string(out))
+
+	repo, err := gitlib.OpenRepository(clonePath)
+	require.NoError(t, err, "libgit2 accepts extensions.partialclone")
+
+	defer repo.Free()
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	commit, err := repo.LookupCommit(context.Background(), head)
+	require.NoError(t, err)
+
+	defer commit.Free()
+
+	assert.Equal(t, "initial", strings.TrimSpace(commit.Message()))
+}
+
+func TestPromisor_FullRepositoryHasNone(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	tr.createFile("a.txt", "a")
+	tr.commit("initial")
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	assert.Nil(t, repo.Promisor())
+}
+
+func TestPromisor_LookupBlobFetchesMissing(t *testing.T) {
+	t.Parallel()
+
+	clonePath, blobHash := newPartialClone(t)
+
+	repo, err := gitlib.OpenRepository(clonePath)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	promisor := repo.Promisor()
+	require.NotNil(t, promisor)
+	assert.Equal(t, "origin", promisor.Stats().Remote)
+
+	blob, err := repo.LookupBlob(context.Background(), blobHash)
+	require.NoError(t, err)
+
+	assert.Equal(t, "package main\n", string(blob.Contents()))
+	blob.Free()
+
+	stats := promisor.Stats()
+	assert.Equal(t, int64(1), stats.Fetches)
+	assert.Equal(t, int64(1), stats.Objects)
+	assert.Zero(t, stats.Failures)
+}
+
+func TestPromisor_RejectsOptionLikeRemote(t *testing.T) {
+	t.Parallel()
+
+	clonePath, blobHash := newPartialClone(t)
+
+	out, err := exec.CommandContext(context.Background(), "git", "-C", clonePath,
+		"config", "extensions.partialClone", "--upload-pack=false").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	repo, err := gitlib.OpenRepository(clonePath)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	promisor := repo.Promisor()
+	require.NotNil(t, promisor)
+
+	err = promisor.Fetch(context.Background(), []gitlib.Hash{blobHash})
+	require.ErrorIs(t, err, gitlib.ErrInvalidPromisorRemote)
+	assert.Equal(t, int64(1), promisor.Stats().Failures)
+}
+
+func TestPromisor_WorkerFetchesMissingBatch(t *testing.T) {
+	t.Parallel()
+
+	clonePath, blobHash := newPartialClone(t)
+
+	repo, err := gitlib.OpenRepository(clonePath)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	reqCh := make(chan gitlib.WorkerRequest, 1)
+	worker := gitlib.NewWorker(repo, reqCh)
+	worker.Start()
+
+	respCh := make(chan gitlib.BlobBatchResponse, 1)
+	reqCh <- gitlib.BlobBatchRequest{Ctx: context.Background(), Hashes: []gitlib.Hash{blobHash}, Response: respCh}
+
+	resp := <-respCh
+	require.Len(t, resp.Blobs, 1)
+	require.NotNil(t, resp.Blobs[0])
+	assert.Equal(t, "package main\n", string(resp.Blobs[0].Data))
+
+	close(reqCh)
+	worker.Stop()
+
+	assert.Equal(t, int64(1), repo.Promisor().Stats().Fetches)
+}
@@ -10,18 +10,43 @@ import (
 
 // Repository wraps a libgit2 repository.
 type Repository struct {
-	repo *git2go.Repository
-	path string
+	repo     *git2go.Repository
+	path     string
+	promisor *PromisorFetcher
+	// textEncoding says how blobs read through the repository are
+	// transcoded; see SetTextEncoding.
+	textEncoding TextEncoding
 }
 
-// OpenRepository opens a git repository at the given path.
+// OpenRepository opens a git repository at the given path. Objects are read
+// from the ODBMirror open for the path first, when there is one. Blobs missing
+// from a partial clone are fetched from its promisor remote; see Promisor.
 func OpenRepository(path string) (*Repository, error) {
 	repo, err := git2go.OpenRepository(path)
 	if err != nil {
 		return nil, fmt.Errorf("open repository: %w", err)
 	}
 
-	return &Repository{repo: repo, path: path}, nil
+	err = attachODBMirror(repo, path)
+	if err != nil {
+		repo.Free()
+
+		return nil, err
+	}
+
+	return &Repository{repo: repo, path: path, promisor: promisorFor(repo)}, nil
+}
+
+// SetTextEncoding sets how blobs read through the repository are
+// transcoded to UTF-8. It must be called before the repository is shared
+// with workers; handles opened later start from the zero TextEncoding.
+func (r *Repository) SetTextEncoding(te TextEncoding) {
+	r.textEncoding = te
+}
+
+// TextEncoding returns how blobs read through the repository are transcoded.
+func (r *Repository) TextEncoding() TextEncoding {
+	return r.textEncoding
 }
 
 // Path returns the repository path.
@@ -48,6 +73,24 @@ func (r *Repository) Head() (Hash, error) {
 	return HashFromOid(ref.Target()), nil
 }
 
+// ResolveRevision returns the commit a revision such as a tag, a branch or an
+// abbreviated hash names.
+func (r *Repository) ResolveRevision(rev string) (Hash, error) {
+	obj, err := r.repo.RevparseSingle(rev)
+	if err != nil {
+		return Hash{}, fmt.Errorf("resolve revision %s: %w", rev, err)
+	}
+	defer obj.Free()
+
+	commit, err := obj.Peel(git2go.ObjectCommit)
+	if err != nil {
+		return Hash{}, fmt.Errorf("resolve revision %s: %w", rev, err)
+	}
+	defer commit.Free()
+
+	return HashFromOid(commit.Id()), nil
+}
+
 // LookupCommit returns the commit with the given hash.
 func (r *Repository) LookupCommit(_ context.Context, hash Hash) (*Commit, error) {
 	commit, err := r.repo.LookupCommit(hash.ToOid())
@@ -58,9 +101,21 @@ func (r *Repository) LookupCommit(_ context.Context, hash Hash) (*Commit, error)
 	return &Commit{commit: commit, repo: r}, nil
 }
 
-// LookupBlob returns the blob with the given hash.
-func (r *Repository) LookupBlob(_ context.Context, hash Hash) (*Blob, error) {
+// LookupBlob returns the blob with the given hash, fetching it first when a
+// partial clone left it out.
+func (r *Repository) LookupBlob(ctx context.Context, hash Hash) (*Blob, error) {
 	blob, err := r.repo.LookupBlob(hash.ToOid())
+	if err != nil && r.promisor != nil && git2go.IsErrorCode(err, git2go.ErrorCodeNotFound) {
+		fetchErr := r.promisor.Fetch(ctx, []Hash{hash})
+		if fetchErr != nil {
+			return nil, fmt.Errorf("lookup blob: %w", fetchErr)
+		}
+
+		r.refreshODB()
+
+		blob, err = r.repo.LookupBlob(hash.ToOid())
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("lookup blob: %w", err)
 	}
@@ -93,6 +148,12 @@ type LogOptions struct {
 	Since       *time.Time // Only include commits after this time.
 	FirstParent bool       // Follow only first parent (git log --first-parent).
 	Reverse     bool       // Yield oldest commits first (adds git2go.SortReverse).
+	Tip         *Hash      // Start from this commit instead of HEAD.
+	Exclude     *Hash      // Skip this commit and its ancestors (git log exclude..tip).
+
+	SampleEvery    int            // Keep about one commit in N (0 or 1 = no sampling).
+	SampleStrategy SampleStrategy // How sampled commits are chosen (default uniform).
+	SampleSeed     uint64         // Seed of the random strategy; each seed picks a different sample.
 }
 
 // Log returns a commit iterator starting from HEAD.
@@ -102,20 +163,11 @@ func (r *Repository) Log(opts *LogOptions) (*CommitIter, error) {
 		return nil, fmt.Errorf("create revwalk: %w", err)
 	}
 
-	// Start from HEAD.
-	headRef, err := r.repo.Head()
+	err = r.pushRange(walk, opts)
 	if err != nil {
 		walk.Free()
 
-		return nil, fmt.Errorf("get HEAD: %w", err)
-	}
-	defer headRef.Free()
-
-	err = walk.Push(headRef.Target())
-	if err != nil {
-		walk.Free()
-
-		return nil, fmt.Errorf("push HEAD to revwalk: %w", err)
+		return nil, err
 	}
 
 	// Topological order ensures we never diff against a descendant; prevents
@@ -136,7 +188,45 @@ func (r *Repository) Log(opts *LogOptions) (*CommitIter, error) {
 		since = opts.Since
 	}
 
-	return &CommitIter{walk: walk, repo: r, since: since}, nil
+	sampler, err := newCommitSampler(r.repo, opts)
+	if err != nil {
+		walk.Free()
+
+		return nil, err
+	}
+
+	return &CommitIter{walk: walk, repo: r, since: since, sampler: sampler}, nil
+}
+
+// pushRange starts walk from the tip of opts, HEAD by default, and hides the
+// excluded commit and its ancestors.
+func (r *Repository) pushRange(walk *git2go.RevWalk, opts *LogOptions) error {
+	if opts != nil && opts.Tip != nil {
+		err := walk.Push(opts.Tip.ToOid())
+		if err != nil {
+			return fmt.Errorf("push %s to revwalk: %w", opts.Tip, err)
+		}
+	} else {
+		headRef, err := r.repo.Head()
+		if err != nil {
+			return fmt.Errorf("get HEAD: %w", err)
+		}
+		defer headRef.Free()
+
+		err = walk.Push(headRef.Target())
+		if err != nil {
+			return fmt.Errorf("push HEAD to revwalk: %w", err)
+		}
+	}
+
+	if opts != nil && opts.Exclude != nil {
+		err := walk.Hide(opts.Exclude.ToOid())
+		if err != nil {
+			return fmt.Errorf("hide %s from revwalk: %w", opts.Exclude, err)
+		}
+	}
+
+	return nil
 }
 
 // CommitCount returns the number of commits matching the given log options.
@@ -0,0 +1,149 @@
+package gitlib
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	git2go "github.com/libgit2/git2go/v34"
+)
+
+// SampleStrategy selects which commits a sampled [Repository.Log] yields.
+type SampleStrategy string
+
+const (
+	// SampleUniform keeps every Nth commit of the walk, starting with the first.
+	SampleUniform SampleStrategy = "uniform"
+
+	// SampleRandom keeps each commit with probability 1/N. Selection is derived
+	// from the commit hash and [LogOptions.SampleSeed], so it is deterministic
+	// for a given seed and independent of walk order.
+	SampleRandom SampleStrategy = "random"
+
+	// SampleReleaseTags keeps only commits pointed to by a tag (refs/tags/*).
+	SampleReleaseTags SampleStrategy = "release-tags"
+)
+
+// ErrUnknownSampleStrategy is returned for unrecognized sampling strategies.
+var ErrUnknownSampleStrategy = errors.New("unknown sample strategy")
+
+// ParseSampleStrategy parses a strategy name. Empty input maps to [SampleUniform].
+func ParseSampleStrategy(s string) (SampleStrategy, error) {
+	switch strategy := SampleStrategy(s); strategy {
+	case "":
+		return SampleUniform, nil
+	case SampleUniform, SampleRandom, SampleReleaseTags:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want uniform, random or release-tags)", ErrUnknownSampleStrategy, s)
+	}
+}
+
+// Sampled reports whether the options select a subset of commits.
+func (o *LogOptions) Sampled() bool {
+	return o != nil && (o.SampleEvery > 1 || o.SampleStrategy == SampleReleaseTags)
+}
+
+// commitSampler decides which walked commits a CommitIter yields.
+type commitSampler struct {
+	strategy SampleStrategy
+	every    uint64
+	seed     uint64
+	pos      uint64
+	tagged   map[git2go.Oid]struct{}
+}
+
+// newCommitSampler builds a sampler for opts, or returns nil when sampling is off.
+func newCommitSampler(repo *git2go.Repository, opts *LogOptions) (*commitSampler, error) {
+	if !opts.Sampled() {
+		return nil, nil //nolint:nilnil // nil sampler means "keep everything".
+	}
+
+	strategy, err := ParseSampleStrategy(string(opts.SampleStrategy))
+	if err != nil {
+		return nil, err
+	}
+
+	sampler := &commitSampler{strategy: strategy, every: uint64(max(opts.SampleEvery, 1)), seed: opts.SampleSeed}
+
+	if strategy == SampleReleaseTags {
+		sampler.tagged, err = taggedCommits(repo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sampler, nil
+}
+
+// keep reports whether the commit with the given OID should be yielded.
+// Must be called once per walked commit, in walk order.
+func (s *commitSampler) keep(oid *git2go.Oid) bool {
+	switch s.strategy {
+	case SampleReleaseTags:
+		_, ok := s.tagged[*oid]
+
+		return ok
+	case SampleRandom:
+		return seededHash(binary.BigEndian.Uint64(oid[:8]), s.seed)%s.every == 0
+	default:
+		keep := s.pos%s.every == 0
+		s.pos++
+
+		return keep
+	}
+}
+
+// SplitMix64 finalizer constants (Steele, Lea and Flood, 2014).
+const (
+	mixShift1 = 30
+	mixShift2 = 27
+	mixShift3 = 31
+	mixMul1   = 0xbf58476d1ce4e5b9
+	mixMul2   = 0x94d049bb133111eb
+)
+
+// seededHash mixes seed into the hash prefix of a commit with the SplitMix64
+// finalizer, so that every seed selects a different, uniformly spread sample.
+// Seed zero leaves the prefix as is.
+func seededHash(prefix, seed uint64) uint64 {
+	if seed == 0 {
+		return prefix
+	}
+
+	x := prefix ^ seed
+	x = (x ^ (x >> mixShift1)) * mixMul1
+	x = (x ^ (x >> mixShift2)) * mixMul2
+
+	return x ^ (x >> mixShift3)
+}
+
+// taggedCommits returns the set of commits pointed to by refs/tags/*,
+// peeling annotated tags. Tags that do not resolve to a commit are ignored.
+func taggedCommits(repo *git2go.Repository) (map[git2go.Oid]struct{}, error) {
+	iter, err := repo.NewReferenceIteratorGlob("refs/tags/*")
+	if err != nil {
+		return nil, fmt.Errorf("iterate tags: %w", err)
+	}
+	defer iter.Free()
+
+	tagged := make(map[git2go.Oid]struct{})
+
+	for {
+		ref, nextErr := iter.Next()
+		if nextErr != nil {
+			break
+		}
+
+		obj, peelErr := ref.Peel(git2go.ObjectCommit)
+		if peelErr == nil {
+			tagged[*obj.Id()] = struct{}{}
+
+			obj.Free()
+		}
+
+		ref.Free()
+	}
+
+	return tagged, nil
+}
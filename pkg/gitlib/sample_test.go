@@ -0,0 +1,200 @@
+package gitlib_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	git2go "github.com/libgit2/git2go/v34"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// commitN creates n sequential commits and returns their hashes, oldest first.
+func commitN(tr *testRepo, n int) []gitlib.Hash {
+	tr.t.Helper()
+
+	hashes := make([]gitlib.Hash, 0, n)
+
+	for i := range n {
+		tr.createFile(fmt.Sprintf("%d.txt", i), fmt.Sprint(i))
+		hashes = append(hashes, tr.commit(fmt.Sprintf("commit %d", i)))
+	}
+
+	return hashes
+}
+
+func TestParseSampleStrategy(t *testing.T) {
+	t.Parallel()
+
+	got, err := gitlib.ParseSampleStrategy("")
+	require.NoError(t, err)
+	assert.Equal(t, gitlib.SampleUniform, got)
+
+	got, err = gitlib.ParseSampleStrategy("release-tags")
+	require.NoError(t, err)
+	assert.Equal(t, gitlib.SampleReleaseTags, got)
+
+	_, err = gitlib.ParseSampleStrategy("stratified")
+	require.ErrorIs(t, err, gitlib.ErrUnknownSampleStrategy)
+}
+
+func TestLog_SampleUniform(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	hashes := commitN(tr, 7)
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	opts := &gitlib.LogOptions{Reverse: true, SampleEvery: 3}
+
+	iter, err := repo.Log(opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, []gitlib.Hash{hashes[0], hashes[3], hashes[6]}, collectIterHashes(t, iter))
+
+	count, err := repo.CommitCount(opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestLog_SampleBase(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	hashes := commitN(tr, 7)
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	iter, err := repo.Log(&gitlib.LogOptions{Reverse: true, SampleEvery: 3})
+	require.NoError(t, err)
+
+	var bases []gitlib.Hash
+
+	for {
+		commit, nextErr := iter.Next()
+		if errors.Is(nextErr, io.EOF) {
+			break
+		}
+
+		require.NoError(t, nextErr)
+
+		// The first sampled commit has no base and is diffed against its parent.
+		if base, ok := commit.SampleBase(); ok {
+			bases = append(bases, base)
+		}
+
+		commit.Free()
+	}
+
+	assert.Equal(t, []gitlib.Hash{hashes[0], hashes[3]}, bases)
+}
+
+func TestLog_SampleRandom_DeterministicAndCountConsistent(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	commitN(tr, 20)
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	opts := &gitlib.LogOptions{SampleEvery: 2, SampleStrategy: gitlib.SampleRandom}
+
+	first, err := repo.Log(opts)
+	require.NoError(t, err)
+
+	firstHashes := collectIterHashes(t, first)
+
+	reversed, err := repo.Log(&gitlib.LogOptions{Reverse: true, SampleEvery: 2, SampleStrategy: gitlib.SampleRandom})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, firstHashes, collectIterHashes(t, reversed), "selection must not depend on walk order")
+
+	count, err := repo.CommitCount(opts)
+	require.NoError(t, err)
+	assert.Len(t, firstHashes, count)
+}
+
+func TestLog_SampleRandom_Seed(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	commitN(tr, 40)
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	sample := func(seed uint64) []gitlib.Hash {
+		iter, logErr := repo.Log(&gitlib.LogOptions{SampleEvery: 2, SampleStrategy: gitlib.SampleRandom, SampleSeed: seed})
+		require.NoError(t, logErr)
+
+		return collectIterHashes(t, iter)
+	}
+
+	assert.Equal(t, sample(42), sample(42), "the same seed selects the same commits")
+	assert.NotEqual(t, sample(42), sample(7), "different seeds select different commits")
+}
+
+func TestLog_SampleReleaseTags(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	hashes := commitN(tr, 5)
+
+	tagCommit := func(name string, hash gitlib.Hash, annotated bool) {
+		commit, lookupErr := tr.native.LookupCommit(hash.ToOid())
+		require.NoError(t, lookupErr)
+
+		defer commit.Free()
+
+		if annotated {
+			sig := &git2go.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()}
+			_, err := tr.native.Tags.Create(name, commit, sig, "release "+name)
+			require.NoError(t, err)
+
+			return
+		}
+
+		_, err := tr.native.Tags.CreateLightweight(name, commit, false)
+		require.NoError(t, err)
+	}
+
+	tagCommit("v1.0.0", hashes[1], false)
+	tagCommit("v2.0.0", hashes[3], true)
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	iter, err := repo.Log(&gitlib.LogOptions{Reverse: true, SampleStrategy: gitlib.SampleReleaseTags})
+	require.NoError(t, err)
+
+	assert.Equal(t, []gitlib.Hash{hashes[1], hashes[3]}, collectIterHashes(t, iter))
+}
@@ -0,0 +1,546 @@
+package gitlib
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	git2go "github.com/libgit2/git2go/v34"
+	"golang.org/x/crypto/ssh"
+)
+
+// VerificationStatus is the outcome of verifying a commit signature.
+type VerificationStatus string
+
+// Verification outcomes, after git's %G? placeholder.
+const (
+	// VerificationUnsigned marks a commit without a signature.
+	VerificationUnsigned VerificationStatus = "unsigned"
+	// VerificationGood marks a valid signature by a key of the OpenPGP
+	// keyring or of the SSH allowed signers.
+	VerificationGood VerificationStatus = "good"
+	// VerificationBad marks a signature that does not match the commit, or
+	// one made by a revoked key.
+	VerificationBad VerificationStatus = "bad"
+	// VerificationExpired marks a valid signature that expired or was made
+	// by an expired key.
+	VerificationExpired VerificationStatus = "expired"
+	// VerificationUnknownKey marks a signature by a key missing from the
+	// OpenPGP keyring or from the SSH allowed signers.
+	VerificationUnknownKey VerificationStatus = "unknown_key"
+	// VerificationUnchecked marks a signature that could not be checked: an
+	// X.509 signature, or an OpenPGP one without a gpg program.
+	VerificationUnchecked VerificationStatus = "unchecked"
+)
+
+// sshSigNamespace is the namespace git signs commits in.
+const sshSigNamespace = "git"
+
+// sshSigMagic starts SSH signatures and the data they sign.
+const sshSigMagic = "SSHSIG"
+
+// defaultGPGProgram is the OpenPGP program git runs by default.
+const defaultGPGProgram = "gpg"
+
+// gpgStatusPrefix starts the machine-readable lines gpg writes to --status-fd.
+const gpgStatusPrefix = "[GNUPG:] "
+
+// Errors of signature verification.
+var (
+	// ErrMalformedSSHSignature is returned for an SSH signature that cannot be parsed.
+	ErrMalformedSSHSignature = errors.New("malformed SSH signature")
+	// ErrAllowedSigners is returned for an unreadable SSH allowed signers file.
+	ErrAllowedSigners = errors.New("invalid SSH allowed signers")
+
+	errUnterminatedPrincipals = errors.New("unterminated quoted principals")
+	errSignerTime             = errors.New("time is not YYYYMMDD[HHMM[SS]][Z]")
+)
+
+// Verification is the outcome of verifying the signature of a commit.
+type Verification struct {
+	Status VerificationStatus
+	// Signer is the principal of an allowed SSH key or the user ID of an
+	// OpenPGP key, when known.
+	Signer string
+	// Key is the fingerprint of the signing key, when known.
+	Key string
+}
+
+// allowedSigner is one line of an SSH allowed signers file.
+type allowedSigner struct {
+	principals string
+	key        ssh.PublicKey
+	// validAfter and validBefore bound the times the key may sign at; zero
+	// leaves that side open.
+	validAfter  time.Time
+	validBefore time.Time
+}
+
+// validAt reports whether the key of s may sign at t.
+func (s allowedSigner) validAt(t time.Time) bool {
+	if !s.validAfter.IsZero() && t.Before(s.validAfter) {
+		return false
+	}
+
+	return s.validBefore.IsZero() || t.Before(s.validBefore)
+}
+
+// SignatureVerifier verifies commit signatures the way "git verify-commit"
+// does. SSH signatures are checked in process against the keys of an allowed
+// signers file, in the format of git's gpg.ssh.allowedSignersFile. OpenPGP
+// signatures are checked by running the gpg program against the user's
+// keyring. It is safe for concurrent use.
+type SignatureVerifier struct {
+	allowedSigners []allowedSigner
+	gpgProgram     string
+}
+
+// NewSignatureVerifier creates a verifier trusting the SSH keys listed in the
+// allowedSigners file, which may be "", and running gpgProgram, or gpg when
+// it is "", to check OpenPGP signatures.
+func NewSignatureVerifier(allowedSigners, gpgProgram string) (*SignatureVerifier, error) {
+	v := &SignatureVerifier{gpgProgram: cmp.Or(gpgProgram, defaultGPGProgram)}
+
+	if allowedSigners == "" {
+		return v, nil
+	}
+
+	data, err := os.ReadFile(allowedSigners)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAllowedSigners, err)
+	}
+
+	v.allowedSigners, err = parseAllowedSigners(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrAllowedSigners, allowedSigners, err)
+	}
+
+	return v, nil
+}
+
+// SignatureVerifier creates a verifier for the commits of the repository.
+// Empty arguments default to the repository's gpg.ssh.allowedSignersFile and
+// gpg.openpgp.program or gpg.program settings, as for git verify-commit.
+func (r *Repository) SignatureVerifier(allowedSigners, gpgProgram string) (*SignatureVerifier, error) {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	defer cfg.Free()
+
+	if allowedSigners == "" {
+		allowedSigners = expandHome(configString(cfg, "gpg.ssh.allowedSignersFile"))
+	}
+
+	if gpgProgram == "" {
+		gpgProgram = cmp.Or(configString(cfg, "gpg.openpgp.program"), configString(cfg, "gpg.program"))
+	}
+
+	return NewSignatureVerifier(allowedSigners, gpgProgram)
+}
+
+// Verify checks the signature of a commit.
+func (v *SignatureVerifier) Verify(ctx context.Context, sig CommitSignature) Verification {
+	if !sig.Signed() {
+		return Verification{Status: VerificationUnsigned}
+	}
+
+	switch sig.Format {
+	case SignatureFormatSSH:
+		return v.verifySSH(sig)
+	case SignatureFormatOpenPGP:
+		return v.verifyOpenPGP(ctx, sig)
+	case SignatureFormatX509, SignatureFormatNone:
+		return Verification{Status: VerificationUnchecked}
+	}
+
+	return Verification{Status: VerificationUnchecked}
+}
+
+// verifySSH checks an SSH signature, as ssh-keygen -Y verify does, and
+// looks its key up in the allowed signers. Like git, it checks the validity
+// of the key at the committer time of the payload: a key listed only for
+// other times gives an expired signature.
+func (v *SignatureVerifier) verifySSH(sig CommitSignature) Verification {
+	key, signature, hashAlgorithm, err := parseSSHSignature(sig.Signature)
+	if err != nil {
+		return Verification{Status: VerificationBad}
+	}
+
+	result := Verification{Key: ssh.FingerprintSHA256(key)}
+
+	signed, err := sshSignedData(sig.Payload, hashAlgorithm)
+	if err != nil || key.Verify(signed, signature) != nil {
+		result.Status = VerificationBad
+
+		return result
+	}
+
+	signedAt := payloadTime(sig.Payload)
+	result.Status = VerificationUnknownKey
+
+	for _, signer := range v.allowedSigners {
+		if !bytes.Equal(signer.key.Marshal(), key.Marshal()) {
+			continue
+		}
+
+		if !signer.validAt(signedAt) {
+			result.Status = VerificationExpired
+
+			continue
+		}
+
+		result.Status = VerificationGood
+		result.Signer = signer.principals
+
+		return result
+	}
+
+	return result
+}
+
+// payloadTime returns the committer time of a commit payload, or the current
+// time when it has none, as ssh-keygen does without a verify time.
+func payloadTime(payload string) time.Time {
+	for line := range strings.SplitSeq(payload, "\n") {
+		if line == "" {
+			break
+		}
+
+		rest, ok := strings.CutPrefix(line, "committer ")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) < 2 {
+			break
+		}
+
+		seconds, err := strconv.ParseInt(fields[len(fields)-2], 10, 64)
+		if err != nil {
+			break
+		}
+
+		return time.Unix(seconds, 0)
+	}
+
+	return time.Now()
+}
+
+// sshSignature is the wire format of an SSH signature after its magic
+// preamble, see PROTOCOL.sshsig in OpenSSH.
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// parseSSHSignature decodes an armored SSH signature made in the git
+// namespace.
+func parseSSHSignature(armored string) (ssh.PublicKey, *ssh.Signature, string, error) {
+	body := strings.TrimSpace(armored)
+	body = strings.TrimPrefix(body, armorSSH)
+	body = strings.TrimSuffix(body, "-----END SSH SIGNATURE-----")
+
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w: %w", ErrMalformedSSHSignature, err)
+	}
+
+	rest, ok := bytes.CutPrefix(blob, []byte(sshSigMagic))
+	if !ok {
+		return nil, nil, "", fmt.Errorf("%w: missing magic", ErrMalformedSSHSignature)
+	}
+
+	var wire sshSignature
+
+	err = ssh.Unmarshal(rest, &wire)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w: %w", ErrMalformedSSHSignature, err)
+	}
+
+	if wire.Version != 1 || wire.Namespace != sshSigNamespace {
+		return nil, nil, "", fmt.Errorf("%w: version %d, namespace %q",
+			ErrMalformedSSHSignature, wire.Version, wire.Namespace)
+	}
+
+	key, err := ssh.ParsePublicKey(wire.PublicKey)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w: %w", ErrMalformedSSHSignature, err)
+	}
+
+	signature := new(ssh.Signature)
+
+	err = ssh.Unmarshal(wire.Signature, signature)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w: %w", ErrMalformedSSHSignature, err)
+	}
+
+	return key, signature, wire.HashAlgorithm, nil
+}
+
+// sshSignedData returns the data an SSH signature of payload signs.
+func sshSignedData(payload, hashAlgorithm string) ([]byte, error) {
+	var h hash.Hash
+
+	switch hashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil, fmt.Errorf("%w: hash algorithm %q", ErrMalformedSSHSignature, hashAlgorithm)
+	}
+
+	h.Write([]byte(payload))
+
+	signed := ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sshSigNamespace, "", hashAlgorithm, h.Sum(nil)})
+
+	return append([]byte(sshSigMagic), signed...), nil
+}
+
+// parseAllowedSigners parses an allowed signers file: one
+// "principals [options] key" line per key, see ALLOWED SIGNERS in
+// ssh-keygen(1). Principals may be quoted to hold spaces. Keys whose
+// namespaces option leaves out git are skipped, and so are certificate
+// authorities, whose certificates git signatures do not carry.
+func parseAllowedSigners(data []byte) ([]allowedSigner, error) {
+	var signers []allowedSigner
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		principals, rest, err := cutPrincipals(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(rest))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		signer := allowedSigner{principals: principals, key: key}
+
+		git, err := applySignerOptions(&signer, options)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		if git {
+			signers = append(signers, signer)
+		}
+	}
+
+	return signers, scanner.Err()
+}
+
+// cutPrincipals splits the principals off an allowed signers line, removing
+// the quotes around them.
+func cutPrincipals(line string) (principals, rest string, err error) {
+	quoted, ok := strings.CutPrefix(line, `"`)
+	if !ok {
+		principals, rest, _ = strings.Cut(line, " ")
+
+		return principals, rest, nil
+	}
+
+	principals, rest, ok = strings.Cut(quoted, `"`)
+	if !ok {
+		return "", "", errUnterminatedPrincipals
+	}
+
+	return principals, strings.TrimSpace(rest), nil
+}
+
+// applySignerOptions reads the validity options of an allowed signer into s
+// and reports whether its key may sign git commits.
+func applySignerOptions(s *allowedSigner, options []string) (bool, error) {
+	git := true
+
+	for _, option := range options {
+		if strings.EqualFold(option, "cert-authority") {
+			git = false
+
+			continue
+		}
+
+		name, value, ok := strings.Cut(option, "=")
+		if !ok {
+			continue
+		}
+
+		value = strings.Trim(value, `"`)
+
+		var err error
+
+		switch strings.ToLower(name) {
+		case "namespaces":
+			git = git && slices.ContainsFunc(strings.Split(value, ","), func(ns string) bool {
+				matched, _ := filepath.Match(strings.TrimSpace(ns), sshSigNamespace)
+
+				return matched
+			})
+		case "valid-after":
+			s.validAfter, err = parseSignerTime(value)
+		case "valid-before":
+			s.validBefore, err = parseSignerTime(value)
+		}
+
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return git, nil
+}
+
+// signerTimeLayouts are the time formats of the valid-after and valid-before
+// options, by length: YYYYMMDD, YYYYMMDDHHMM or YYYYMMDDHHMMSS.
+var signerTimeLayouts = map[int]string{
+	len("20060102"):       "20060102",
+	len("200601021504"):   "200601021504",
+	len("20060102150405"): "20060102150405",
+}
+
+// parseSignerTime parses a valid-after or valid-before time. A trailing Z
+// marks UTC; other times are in the local time zone, as for ssh-keygen.
+func parseSignerTime(value string) (time.Time, error) {
+	location := time.Local
+
+	if trimmed, ok := strings.CutSuffix(value, "Z"); ok {
+		value, location = trimmed, time.UTC
+	}
+
+	layout, ok := signerTimeLayouts[len(value)]
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %q", errSignerTime, value)
+	}
+
+	return time.ParseInLocation(layout, value, location)
+}
+
+// verifyOpenPGP checks an OpenPGP signature with the gpg program, passing
+// the signature in a file and the payload on stdin, as git does.
+func (v *SignatureVerifier) verifyOpenPGP(ctx context.Context, sig CommitSignature) Verification {
+	program, err := exec.LookPath(v.gpgProgram)
+	if err != nil {
+		return Verification{Status: VerificationUnchecked}
+	}
+
+	sigFile, err := os.CreateTemp("", "codefang-gpgsig-*")
+	if err != nil {
+		return Verification{Status: VerificationUnchecked}
+	}
+
+	defer os.Remove(sigFile.Name())
+
+	_, err = sigFile.WriteString(sig.Signature)
+
+	closeErr := sigFile.Close()
+	if err != nil || closeErr != nil {
+		return Verification{Status: VerificationUnchecked}
+	}
+
+	cmd := exec.CommandContext(ctx, program, "--status-fd=1", "--verify", sigFile.Name(), "-")
+	cmd.Stdin = strings.NewReader(sig.Payload)
+
+	// gpg exits non-zero for bad signatures and missing keys; the status
+	// lines tell them apart.
+	out, _ := cmd.Output()
+
+	return parseGPGStatus(out)
+}
+
+// parseGPGStatus reads the outcome of gpg --verify from its status lines.
+func parseGPGStatus(out []byte) Verification {
+	result := Verification{Status: VerificationUnchecked}
+
+	for line := range strings.SplitSeq(string(out), "\n") {
+		status, ok := strings.CutPrefix(line, gpgStatusPrefix)
+		if !ok {
+			continue
+		}
+
+		keyword, args, _ := strings.Cut(status, " ")
+
+		switch keyword {
+		case "GOODSIG":
+			result.Status = VerificationGood
+			result.Signer = gpgUserID(args)
+		case "EXPSIG", "EXPKEYSIG":
+			result.Status = VerificationExpired
+			result.Signer = gpgUserID(args)
+		case "BADSIG", "REVKEYSIG":
+			result.Status = VerificationBad
+			result.Signer = gpgUserID(args)
+		case "NO_PUBKEY":
+			result.Status = VerificationUnknownKey
+		case "VALIDSIG":
+			result.Key, _, _ = strings.Cut(args, " ")
+		}
+	}
+
+	return result
+}
+
+// gpgUserID returns the user ID of "<key id> <user id>" status arguments.
+func gpgUserID(args string) string {
+	_, uid, _ := strings.Cut(args, " ")
+
+	return uid
+}
+
+// configString returns a git config value, or "" when it is not set.
+func configString(cfg *git2go.Config, key string) string {
+	value, err := cfg.LookupString(key)
+	if err != nil {
+		return ""
+	}
+
+	return value
+}
+
+// expandHome expands a leading "~/" to the home directory, as git does for
+// path settings.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,259 @@
+package gitlib_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// sshSign signs payload in the git namespace the way ssh-keygen -Y sign
+// does and returns the armored signature.
+func sshSign(t *testing.T, signer ssh.Signer, payload string) string {
+	t.Helper()
+
+	digest := sha512.Sum512([]byte(payload))
+	signed := ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{"git", "", "sha512", digest[:]})
+
+	signature, err := signer.Sign(rand.Reader, append([]byte("SSHSIG"), signed...))
+	require.NoError(t, err)
+
+	blob := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{1, signer.PublicKey().Marshal(), "git", "", "sha512", ssh.Marshal(signature)})...)
+
+	return "-----BEGIN SSH SIGNATURE-----\n" +
+		base64.StdEncoding.EncodeToString(blob) +
+		"\n-----END SSH SIGNATURE-----\n"
+}
+
+func newSSHSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+
+	return signer
+}
+
+// writeAllowedSigners writes an allowed signers file trusting key for
+// principal.
+func writeAllowedSigners(t *testing.T, principal string, key ssh.PublicKey) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "allowed_signers")
+	line := principal + ` namespaces="git" ` + string(ssh.MarshalAuthorizedKey(key))
+
+	require.NoError(t, os.WriteFile(path, []byte("# trusted keys\n"+line), 0o600))
+
+	return path
+}
+
+func TestCommit_ExtractSignature(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	tr.createFile("a.txt", "a")
+	plain := tr.commit("unsigned")
+
+	native, err := tr.native.LookupCommit(plain.ToOid())
+	require.NoError(t, err)
+
+	defer native.Free()
+
+	signer := newSSHSigner(t)
+	armored := sshSign(t, signer, native.ContentToSign())
+
+	oid, err := native.WithSignature(armored, "")
+	require.NoError(t, err)
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	commit, err := repo.LookupCommit(context.Background(), plain)
+	require.NoError(t, err)
+
+	sig, err := commit.ExtractSignature()
+	require.NoError(t, err)
+	assert.False(t, sig.Signed())
+	commit.Free()
+
+	commit, err = repo.LookupCommit(context.Background(), gitlib.HashFromOid(oid))
+	require.NoError(t, err)
+
+	defer commit.Free()
+
+	sig, err = commit.ExtractSignature()
+	require.NoError(t, err)
+	assert.True(t, sig.Signed())
+	assert.Equal(t, gitlib.SignatureFormatSSH, sig.Format)
+	assert.Equal(t, native.ContentToSign(), sig.Payload)
+
+	verifier, err := gitlib.NewSignatureVerifier(writeAllowedSigners(t, "jane@example.com", signer.PublicKey()), "")
+	require.NoError(t, err)
+
+	result := verifier.Verify(context.Background(), sig)
+	assert.Equal(t, gitlib.VerificationGood, result.Status)
+	assert.Equal(t, "jane@example.com", result.Signer)
+	assert.Equal(t, ssh.FingerprintSHA256(signer.PublicKey()), result.Key)
+}
+
+func TestSignatureVerifier_SSH(t *testing.T) {
+	t.Parallel()
+
+	signer := newSSHSigner(t)
+	payload := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage\n"
+	sig := gitlib.CommitSignature{Format: gitlib.SignatureFormatSSH, Signature: sshSign(t, signer, payload), Payload: payload}
+
+	trusting, err := gitlib.NewSignatureVerifier(writeAllowedSigners(t, "jane@example.com", signer.PublicKey()), "")
+	require.NoError(t, err)
+
+	empty, err := gitlib.NewSignatureVerifier("", "")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	assert.Equal(t, gitlib.VerificationGood, trusting.Verify(ctx, sig).Status)
+	assert.Equal(t, gitlib.VerificationUnknownKey, empty.Verify(ctx, sig).Status)
+	assert.Equal(t, gitlib.VerificationUnsigned, empty.Verify(ctx, gitlib.CommitSignature{}).Status)
+
+	tampered := sig
+	tampered.Payload = strings.Replace(payload, "message", "massage", 1)
+	assert.Equal(t, gitlib.VerificationBad, trusting.Verify(ctx, tampered).Status)
+
+	garbled := sig
+	garbled.Signature = "-----BEGIN SSH SIGNATURE-----\nnot base64\n-----END SSH SIGNATURE-----\n"
+	assert.Equal(t, gitlib.VerificationBad, trusting.Verify(ctx, garbled).Status)
+
+	x509 := gitlib.CommitSignature{Format: gitlib.SignatureFormatX509, Signature: "-----BEGIN SIGNED MESSAGE-----", Payload: payload}
+	assert.Equal(t, gitlib.VerificationUnchecked, trusting.Verify(ctx, x509).Status)
+}
+
+func TestNewSignatureVerifier_AllowedSigners(t *testing.T) {
+	t.Parallel()
+
+	signer := newSSHSigner(t)
+	payload := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage\n"
+	sig := gitlib.CommitSignature{Format: gitlib.SignatureFormatSSH, Signature: sshSign(t, signer, payload), Payload: payload}
+
+	path := filepath.Join(t.TempDir(), "allowed_signers")
+	line := `jane@example.com namespaces="file" ` + string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+	require.NoError(t, os.WriteFile(path, []byte(line), 0o600))
+
+	verifier, err := gitlib.NewSignatureVerifier(path, "")
+	require.NoError(t, err)
+	assert.Equal(t, gitlib.VerificationUnknownKey, verifier.Verify(context.Background(), sig).Status)
+
+	require.NoError(t, os.WriteFile(path, []byte("jane@example.com ssh-ed25519 garbage\n"), 0o600))
+
+	_, err = gitlib.NewSignatureVerifier(path, "")
+	require.ErrorIs(t, err, gitlib.ErrAllowedSigners)
+
+	_, err = gitlib.NewSignatureVerifier(filepath.Join(t.TempDir(), "missing"), "")
+	require.ErrorIs(t, err, gitlib.ErrAllowedSigners)
+}
+
+func TestNewSignatureVerifier_AllowedSignersFormat(t *testing.T) {
+	t.Parallel()
+
+	signer := newSSHSigner(t)
+	key := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+
+	// The committer time of the payload is 2024-03-01 UTC.
+	payload := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"author Jane Doe <jane@example.com> 1709251200 +0000\n" +
+		"committer Jane Doe <jane@example.com> 1709251200 +0000\n\nmessage\n"
+	sig := gitlib.CommitSignature{Format: gitlib.SignatureFormatSSH, Signature: sshSign(t, signer, payload), Payload: payload}
+
+	tests := []struct {
+		name   string
+		line   string
+		status gitlib.VerificationStatus
+		signer string
+	}{
+		{"quoted principals", `"Jane Doe,jane@example.com" ` + key, gitlib.VerificationGood, "Jane Doe,jane@example.com"},
+		{"within validity", `jane@example.com valid-after="20240101Z",valid-before="202501011200Z" ` + key, gitlib.VerificationGood, "jane@example.com"},
+		{"expired", `jane@example.com valid-before="20240201000000Z" ` + key, gitlib.VerificationExpired, ""},
+		{"not yet valid", `jane@example.com valid-after="20240401Z" ` + key, gitlib.VerificationExpired, ""},
+		{"one valid line of two", "old@example.com valid-before=\"20230101Z\" " + key + "\nnew@example.com valid-after=\"20230101Z\" " + key,
+			gitlib.VerificationGood, "new@example.com"},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "allowed_signers")
+		require.NoError(t, os.WriteFile(path, []byte(tt.line+"\n"), 0o600))
+
+		verifier, err := gitlib.NewSignatureVerifier(path, "")
+		require.NoError(t, err, tt.name)
+
+		got := verifier.Verify(context.Background(), sig)
+		assert.Equal(t, tt.status, got.Status, tt.name)
+		assert.Equal(t, tt.signer, got.Signer, tt.name)
+	}
+
+	for _, line := range []string{`"Jane Doe ` + key, `jane@example.com valid-after="2024" ` + key} {
+		path := filepath.Join(t.TempDir(), "allowed_signers")
+		require.NoError(t, os.WriteFile(path, []byte(line+"\n"), 0o600))
+
+		_, err := gitlib.NewSignatureVerifier(path, "")
+		require.ErrorIs(t, err, gitlib.ErrAllowedSigners, line)
+	}
+}
+
+func TestSignatureVerifier_OpenPGP(t *testing.T) {
+	t.Parallel()
+
+	// A fake gpg that reports the status lines of a good signature.
+	program := filepath.Join(t.TempDir(), "fake-gpg")
+	script := "#!/bin/sh\ncat >/dev/null\n" +
+		"echo '[GNUPG:] NEWSIG'\n" +
+		"echo '[GNUPG:] GOODSIG 0123456789ABCDEF Jane Doe <jane@example.com>'\n" +
+		"echo '[GNUPG:] VALIDSIG FEDCBA9876543210 2024-01-01 1704067200 0 4 0 22 10 00 FEDCBA9876543210'\n"
+	require.NoError(t, os.WriteFile(program, []byte(script), 0o700)) //nolint:gosec // The fake gpg must be executable.
+
+	verifier, err := gitlib.NewSignatureVerifier("", program)
+	require.NoError(t, err)
+
+	sig := gitlib.CommitSignature{
+		Format:    gitlib.SignatureFormatOpenPGP,
+		Signature: "-----BEGIN PGP SIGNATURE-----\n\n-----END PGP SIGNATURE-----\n",
+		Payload:   "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage\n",
+	}
+
+	result := verifier.Verify(context.Background(), sig)
+	assert.Equal(t, gitlib.VerificationGood, result.Status)
+	assert.Equal(t, "Jane Doe <jane@example.com>", result.Signer)
+	assert.Equal(t, "FEDCBA9876543210", result.Key)
+
+	missing, err := gitlib.NewSignatureVerifier("", filepath.Join(t.TempDir(), "no-such-gpg"))
+	require.NoError(t, err)
+	assert.Equal(t, gitlib.VerificationUnchecked, missing.Verify(context.Background(), sig).Status)
+}
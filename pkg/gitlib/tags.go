@@ -0,0 +1,39 @@
+package gitlib
+
+import (
+	"fmt"
+
+	git2go "github.com/libgit2/git2go/v34"
+)
+
+// Tags returns the names of the tags under refs/tags/ by the commit they
+// point to, peeling annotated tags. Tags that do not resolve to a commit
+// are ignored.
+func (r *Repository) Tags() (map[Hash][]string, error) {
+	iter, err := r.repo.NewReferenceIteratorGlob("refs/tags/*")
+	if err != nil {
+		return nil, fmt.Errorf("iterate tags: %w", err)
+	}
+	defer iter.Free()
+
+	tags := make(map[Hash][]string)
+
+	for {
+		ref, nextErr := iter.Next()
+		if nextErr != nil {
+			break
+		}
+
+		obj, peelErr := ref.Peel(git2go.ObjectCommit)
+		if peelErr == nil {
+			hash := HashFromOid(obj.Id())
+			tags[hash] = append(tags[hash], ref.Shorthand())
+
+			obj.Free()
+		}
+
+		ref.Free()
+	}
+
+	return tags, nil
+}
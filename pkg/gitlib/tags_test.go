@@ -0,0 +1,43 @@
+package gitlib_test
+
+import (
+	"testing"
+	"time"
+
+	git2go "github.com/libgit2/git2go/v34"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func TestRepository_Tags(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	hashes := commitN(tr, 3)
+
+	commit, err := tr.native.LookupCommit(hashes[1].ToOid())
+	require.NoError(t, err)
+
+	defer commit.Free()
+
+	_, err = tr.native.Tags.CreateLightweight("v1.0.0", commit, false)
+	require.NoError(t, err)
+
+	sig := &git2go.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()}
+	_, err = tr.native.Tags.Create("release-1.0", commit, sig, "release 1.0")
+	require.NoError(t, err)
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	tags, err := repo.Tags()
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	assert.ElementsMatch(t, []string{"v1.0.0", "release-1.0"}, tags[hashes[1]])
+}
@@ -16,6 +16,7 @@ type TestCommit struct {
 	committer    Signature
 	message      string
 	parentHashes []Hash
+	signature    CommitSignature
 }
 
 // NewTestCommit creates a new mock commit for testing.
@@ -56,6 +57,16 @@ func (m *TestCommit) Files() (*FileIter, error) { return nil, ErrMockNotImplemen
 // File returns an error for TestCommit (not implemented).
 func (m *TestCommit) File(_ string) (*File, error) { return nil, ErrMockNotImplemented }
 
+// WithSignature sets the signature ExtractSignature returns.
+func (m *TestCommit) WithSignature(sig CommitSignature) *TestCommit {
+	m.signature = sig
+
+	return m
+}
+
+// ExtractSignature returns the signature set by WithSignature.
+func (m *TestCommit) ExtractSignature() (CommitSignature, error) { return m.signature, nil }
+
 // Free is a no-op for TestCommit.
 func (m *TestCommit) Free() {
 	// No resources to release for mock commit.
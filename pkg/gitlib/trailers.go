@@ -0,0 +1,114 @@
+package gitlib
+
+import (
+	"strings"
+)
+
+// Trailer keys codefang interprets.
+const (
+	TrailerSignedOffBy  = "Signed-off-by"
+	TrailerCoAuthoredBy = "Co-authored-by"
+	TrailerReviewedBy   = "Reviewed-by"
+)
+
+// Trailer is one "Key: value" line of the trailer block that ends a commit
+// message, such as "Signed-off-by: Jane Doe <jane@example.com>".
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// Trailers returns the trailers of the commit message.
+func (c *Commit) Trailers() []Trailer {
+	return ParseTrailers(c.Message())
+}
+
+// ParseTrailers returns the trailers of a commit message: the lines of its
+// last paragraph, when every line of that paragraph is a "Key: value" pair
+// or continues the previous pair with leading whitespace. A message of a
+// single paragraph has no trailers, as in git interpret-trailers.
+func ParseTrailers(message string) []Trailer {
+	lines := strings.Split(strings.TrimRight(message, "\n\r\t "), "\n")
+
+	start := len(lines)
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+
+	// The subject paragraph is never a trailer block.
+	if start == 0 {
+		return nil
+	}
+
+	var trailers []Trailer
+
+	for _, line := range lines[start:] {
+		line = strings.TrimRight(line, "\r")
+
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(trailers) == 0 {
+				return nil
+			}
+
+			last := &trailers[len(trailers)-1]
+			last.Value += " " + strings.TrimSpace(line)
+
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || !isTrailerKey(key) {
+			return nil
+		}
+
+		trailers = append(trailers, Trailer{Key: key, Value: strings.TrimSpace(value)})
+	}
+
+	return trailers
+}
+
+// TrailerValues returns the values of the trailers with the given key,
+// compared case-insensitively, in message order.
+func TrailerValues(trailers []Trailer, key string) []string {
+	var values []string
+
+	for _, t := range trailers {
+		if strings.EqualFold(t.Key, key) {
+			values = append(values, t.Value)
+		}
+	}
+
+	return values
+}
+
+// ParseTrailerSignature parses a "Name <email>" trailer value, as carried by
+// Co-authored-by and Signed-off-by. ok is false when the value has no email.
+func ParseTrailerSignature(value string) (sig Signature, ok bool) {
+	open := strings.LastIndexByte(value, '<')
+	if open < 0 || !strings.HasSuffix(value, ">") {
+		return Signature{}, false
+	}
+
+	email := strings.TrimSpace(value[open+1 : len(value)-1])
+	if email == "" {
+		return Signature{}, false
+	}
+
+	return Signature{Name: strings.TrimSpace(value[:open]), Email: email}, true
+}
+
+// isTrailerKey reports whether key is a trailer token: letters, digits and
+// hyphens, not starting with a hyphen.
+func isTrailerKey(key string) bool {
+	if key == "" || key[0] == '-' {
+		return false
+	}
+
+	for _, r := range key {
+		if r != '-' && (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
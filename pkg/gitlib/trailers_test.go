@@ -0,0 +1,121 @@
+package gitlib_test
+
+import (
+	"testing"
+	"time"
+
+	git2go "github.com/libgit2/git2go/v34"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func TestParseTrailers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message string
+		want    []gitlib.Trailer
+	}{
+		{
+			name: "trailer block",
+			message: "Fix parser\n\nLonger body.\n\n" +
+				"Signed-off-by: Jane Doe <jane@example.com>\n" +
+				"Co-authored-by: John Roe <john@example.com>\n",
+			want: []gitlib.Trailer{
+				{Key: "Signed-off-by", Value: "Jane Doe <jane@example.com>"},
+				{Key: "Co-authored-by", Value: "John Roe <john@example.com>"},
+			},
+		},
+		{
+			name:    "continuation line",
+			message: "Subject\n\nReviewed-by: Jane Doe\n  <jane@example.com>\r\n",
+			want:    []gitlib.Trailer{{Key: "Reviewed-by", Value: "Jane Doe <jane@example.com>"}},
+		},
+		{
+			name:    "subject only",
+			message: "Fixes: everything\n",
+		},
+		{
+			name:    "last paragraph is prose",
+			message: "Subject\n\nSigned-off-by: Jane Doe <jane@example.com>\nand some prose\n",
+		},
+		{
+			name:    "key with spaces",
+			message: "Subject\n\nSee also: the docs\n",
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, gitlib.ParseTrailers(tt.message))
+		})
+	}
+}
+
+func TestTrailerValues(t *testing.T) {
+	t.Parallel()
+
+	trailers := gitlib.ParseTrailers("Subject\n\nco-authored-by: A <a@x>\nSigned-off-by: B <b@x>\nCo-Authored-By: C <c@x>\n")
+
+	assert.Equal(t, []string{"A <a@x>", "C <c@x>"}, gitlib.TrailerValues(trailers, gitlib.TrailerCoAuthoredBy))
+	assert.Nil(t, gitlib.TrailerValues(trailers, gitlib.TrailerReviewedBy))
+}
+
+func TestParseTrailerSignature(t *testing.T) {
+	t.Parallel()
+
+	sig, ok := gitlib.ParseTrailerSignature("Jane Doe <jane@example.com>")
+	require.True(t, ok)
+	assert.Equal(t, "Jane Doe", sig.Name)
+	assert.Equal(t, "jane@example.com", sig.Email)
+
+	_, ok = gitlib.ParseTrailerSignature("Jane Doe")
+	assert.False(t, ok)
+
+	_, ok = gitlib.ParseTrailerSignature("Jane Doe <>")
+	assert.False(t, ok)
+}
+
+func TestRepository_Note(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	tr.createFile("a.txt", "a")
+	noted := tr.commit("first")
+	tr.createFile("b.txt", "b")
+	plain := tr.commit("second")
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	ref, err := repo.NotesRef()
+	require.NoError(t, err)
+	assert.Equal(t, "refs/notes/commits", ref)
+	assert.False(t, repo.HasNotes(ref))
+
+	sig := &git2go.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()}
+	_, err = tr.native.Notes.Create(ref, sig, sig, noted.ToOid(), "Reviewed in CR-42\n", false)
+	require.NoError(t, err)
+
+	assert.True(t, repo.HasNotes(ref))
+
+	note, err := repo.Note(ref, noted)
+	require.NoError(t, err)
+	assert.Equal(t, "Reviewed in CR-42\n", note)
+
+	note, err = repo.Note(ref, plain)
+	require.NoError(t, err)
+	assert.Empty(t, note)
+}
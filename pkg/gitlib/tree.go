@@ -92,6 +92,11 @@ func (e *TreeEntry) Type() git2go.ObjectType {
 	return e.entry.Type
 }
 
+// Mode returns the entry file mode.
+func (e *TreeEntry) Mode() uint16 {
+	return uint16(e.entry.Filemode)
+}
+
 // IsBlob returns true if the entry is a blob.
 func (e *TreeEntry) IsBlob() bool {
 	return e.entry.Type == git2go.ObjectBlob
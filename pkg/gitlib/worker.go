@@ -195,17 +195,20 @@ func (w *Worker) handle(req WorkerRequest) {
 			results = w.bridge.BatchLoadBlobs(typedReq.Hashes)
 		}
 
+		results = w.bridge.fetchMissing(typedReq.Ctx, results)
+
 		blobs := make([]*CachedBlob, len(results))
 
 		for i, res := range results {
 			if res.Error == nil {
-				blobs[i] = &CachedBlob{
-					hash:      res.Hash,
-					size:      res.Size,
-					Data:      res.Data,
-					lineCount: res.LineCount,
-					keepAlive: res.KeepAlive,
+				blob := newCachedBlob(res.Hash, res.Size, res.Data, w.repo.textEncoding)
+				if sameBuffer(blob.Data, res.Data) {
+					// Data still points into the loaded buffer.
+					blob.lineCount = res.LineCount
+					blob.keepAlive = res.KeepAlive
 				}
+
+				blobs[i] = blob
 			}
 		}
 
@@ -216,3 +219,9 @@ func (w *Worker) handle(req WorkerRequest) {
 		typedReq.Response <- DiffBatchResponse{Results: results}
 	}
 }
+
+// sameBuffer reports whether a starts where b does, so a blob built from b
+// still reads from its buffer.
+func sameBuffer(a, b []byte) bool {
+	return len(a) > 0 && len(b) > 0 && &a[0] == &b[0]
+}
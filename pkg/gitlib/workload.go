@@ -0,0 +1,184 @@
+package gitlib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	git2go "github.com/libgit2/git2go/v34"
+)
+
+// workloadFileCost is the weight of a changed file regardless of its size:
+// the lookups, tree diff entry and per-file analyzer work it costs.
+const workloadFileCost = 4 * 1024
+
+// CommitWorkload estimates the work of analyzing a commit from the blobs it
+// changes against its first parent, or its sample base in a sampled walk,
+// read from object headers without loading any blob.
+type CommitWorkload struct {
+	// Files is the number of changed files.
+	Files int
+	// BlobBytes is the size of the blobs the pipeline loads: both sides of
+	// modified files, added files and deleted files.
+	BlobBytes int64
+	// DiffBytes is the size of both sides of the modified files, which are
+	// diffed line by line.
+	DiffBytes int64
+}
+
+// Weight returns the workload as a single number, in bytes.
+func (w CommitWorkload) Weight() int64 {
+	return int64(w.Files)*workloadFileCost + w.BlobBytes + w.DiffBytes
+}
+
+// ScanWorkload walks the commits of opts, in the order Log yields them, and
+// returns the workload of each one, like a `git rev-list --objects` pass
+// that weighs the objects instead of listing them. limit stops the walk
+// after that many commits; zero or less scans them all.
+func (r *Repository) ScanWorkload(ctx context.Context, opts *LogOptions, limit int) ([]CommitWorkload, error) {
+	iter, err := r.Log(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	odb, err := r.repo.Odb()
+	if err != nil {
+		return nil, fmt.Errorf("open odb: %w", err)
+	}
+	defer odb.Free()
+
+	var workloads []CommitWorkload
+
+	for limit <= 0 || len(workloads) < limit {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		commit, nextErr := iter.Next()
+		if errors.Is(nextErr, io.EOF) {
+			break
+		}
+
+		if nextErr != nil {
+			return nil, nextErr
+		}
+
+		hash := commit.Hash()
+		workload, scanErr := r.commitWorkload(ctx, odb, commit)
+
+		commit.Free()
+
+		if scanErr != nil {
+			return nil, fmt.Errorf("scan workload of %s: %w", hash, scanErr)
+		}
+
+		workloads = append(workloads, workload)
+	}
+
+	return workloads, nil
+}
+
+// commitWorkload diffs the tree of commit against the tree of its diff base,
+// or the empty tree when it has none, and weighs the changed blobs.
+func (r *Repository) commitWorkload(ctx context.Context, odb *git2go.Odb, commit *Commit) (CommitWorkload, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return CommitWorkload{}, err
+	}
+	defer tree.Free()
+
+	parentTree, err := r.diffBaseTree(ctx, commit)
+	if err != nil {
+		return CommitWorkload{}, err
+	}
+
+	if parentTree != nil {
+		defer parentTree.Free()
+
+		if parentTree.Hash() == tree.Hash() {
+			return CommitWorkload{}, nil
+		}
+	}
+
+	diff, err := r.DiffTreeToTree(parentTree, tree)
+	if err != nil {
+		return CommitWorkload{}, err
+	}
+	defer diff.Free()
+
+	numDeltas, err := diff.NumDeltas()
+	if err != nil {
+		return CommitWorkload{}, err
+	}
+
+	var workload CommitWorkload
+
+	for i := range numDeltas {
+		delta, deltaErr := diff.Delta(i)
+		if deltaErr != nil {
+			continue
+		}
+
+		oldSize := blobHeaderSize(odb, delta.OldFile)
+		newSize := blobHeaderSize(odb, delta.NewFile)
+
+		switch delta.Status {
+		case git2go.DeltaAdded, git2go.DeltaDeleted:
+			workload.BlobBytes += oldSize + newSize
+		case git2go.DeltaModified, git2go.DeltaRenamed, git2go.DeltaCopied:
+			workload.BlobBytes += oldSize + newSize
+			workload.DiffBytes += oldSize + newSize
+		default:
+			continue
+		}
+
+		workload.Files++
+	}
+
+	return workload, nil
+}
+
+// diffBaseTree returns the tree of the sample base of commit in a sampled
+// walk, of its first parent otherwise, or nil when it has neither.
+func (r *Repository) diffBaseTree(ctx context.Context, commit *Commit) (*Tree, error) {
+	var (
+		base *Commit
+		err  error
+	)
+
+	hash, sampled := commit.SampleBase()
+
+	switch {
+	case !sampled && commit.NumParents() == 0:
+		return nil, nil //nolint:nilnil // nil tree means "diff against the empty tree".
+	case sampled:
+		base, err = r.LookupCommit(ctx, hash)
+	default:
+		base, err = commit.Parent(0)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer base.Free()
+
+	return base.Tree()
+}
+
+// blobHeaderSize returns the size of the blob of file from its object
+// header, or zero for submodules, absent sides and objects missing from a
+// partial clone, which are not fetched for an estimate.
+func blobHeaderSize(odb *git2go.Odb, file DiffFile) int64 {
+	if file.Hash.IsZero() || file.Mode == FileModeGitlink {
+		return 0
+	}
+
+	size, _, err := odb.ReadHeader(file.Hash.ToOid())
+	if err != nil {
+		return 0
+	}
+
+	return int64(size)
+}
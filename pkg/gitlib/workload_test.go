@@ -0,0 +1,46 @@
+package gitlib_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+func TestScanWorkload(t *testing.T) {
+	t.Parallel()
+
+	tr := newTestRepo(t)
+	defer tr.cleanup()
+
+	tr.createFile("a.txt", strings.Repeat("a", 100))
+	tr.commit("add a")
+	tr.createFile("a.txt", strings.Repeat("a", 300))
+	tr.createFile("b.txt", strings.Repeat("b", 50))
+	tr.commit("grow a, add b")
+	tr.deleteFile("b.txt")
+	tr.commit("delete b")
+
+	repo, err := gitlib.OpenRepository(tr.path)
+	require.NoError(t, err)
+
+	defer repo.Free()
+
+	workloads, err := repo.ScanWorkload(context.Background(), &gitlib.LogOptions{Reverse: true}, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, []gitlib.CommitWorkload{
+		{Files: 1, BlobBytes: 100},
+		{Files: 2, BlobBytes: 450, DiffBytes: 400},
+		{Files: 1, BlobBytes: 50},
+	}, workloads)
+	assert.Greater(t, workloads[1].Weight(), workloads[0].Weight())
+
+	limited, err := repo.ScanWorkload(context.Background(), &gitlib.LogOptions{Reverse: true}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, workloads[:2], limited)
+}
@@ -0,0 +1,147 @@
+// Package golden compares analyzer reports against golden files. It backs
+// `codefang selftest` and can be used from the tests of custom analyzers:
+//
+//	reports, err := golden.RunHistory(ctx, repo, core, leaves)
+//	...
+//	golden.Check(t, filepath.Join("testdata", golden.FileName(id)), reports[id])
+//
+// Run the tests with CODEFANG_UPDATE_GOLDEN=1 to write the golden files.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that makes Check rewrite golden files
+// instead of comparing against them.
+const UpdateEnv = "CODEFANG_UPDATE_GOLDEN"
+
+// diffContextLines is the number of lines shown around the first difference.
+const diffContextLines = 3
+
+var (
+	// ErrMismatch is returned when a report differs from its golden file.
+	ErrMismatch = errors.New("report differs from golden file")
+	// ErrMissing is returned when a golden file does not exist.
+	ErrMissing = errors.New("golden file missing")
+)
+
+// FileName returns the golden file name for an analyzer ID, e.g.
+// "history_burndown.json" for "history/burndown".
+func FileName(analyzerID string) string {
+	return strings.ReplaceAll(analyzerID, "/", "_") + ".json"
+}
+
+// Normalize re-encodes a JSON report with sorted keys and fixed indentation,
+// so reports compare equal regardless of map ordering and formatting.
+func Normalize(data []byte) ([]byte, error) {
+	var v any
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	err := dec.Decode(&v)
+	if err != nil {
+		return nil, fmt.Errorf("normalize report: %w", err)
+	}
+
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("normalize report: %w", err)
+	}
+
+	return append(out, '\n'), nil
+}
+
+// Verify compares the JSON report got against the golden file at path. With
+// update set, it writes got to path instead.
+func Verify(path string, got []byte, update bool) error {
+	normalized, err := Normalize(got)
+	if err != nil {
+		return err
+	}
+
+	if update {
+		err = os.MkdirAll(filepath.Dir(path), 0o750)
+		if err != nil {
+			return fmt.Errorf("create golden dir: %w", err)
+		}
+
+		err = os.WriteFile(path, normalized, 0o600)
+		if err != nil {
+			return fmt.Errorf("write golden file: %w", err)
+		}
+
+		return nil
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrMissing, path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("read golden file: %w", err)
+	}
+
+	want, err = Normalize(want)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if !bytes.Equal(normalized, want) {
+		return fmt.Errorf("%w: %s\n%s", ErrMismatch, path, firstDifference(want, normalized))
+	}
+
+	return nil
+}
+
+// Check is Verify for tests: it fails t on a mismatch and updates the golden
+// file when UpdateEnv is set.
+func Check(t testing.TB, path string, got []byte) {
+	t.Helper()
+
+	err := Verify(path, got, os.Getenv(UpdateEnv) != "")
+	if err != nil {
+		t.Fatalf("%v\nrerun with %s=1 to update the golden file", err, UpdateEnv)
+	}
+}
+
+// firstDifference renders the lines around the first line where want and got
+// differ.
+func firstDifference(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+
+	line := 0
+	for line < len(wantLines) && line < len(gotLines) && wantLines[line] == gotLines[line] {
+		line++
+	}
+
+	start := max(line-diffContextLines, 0)
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "first difference at line %d:\n", line+1)
+
+	for i := start; i < line; i++ {
+		fmt.Fprintf(&sb, "  %s\n", wantLines[i])
+	}
+
+	for i := line; i < min(line+diffContextLines, len(wantLines)); i++ {
+		fmt.Fprintf(&sb, "- %s\n", wantLines[i])
+	}
+
+	for i := line; i < min(line+diffContextLines, len(gotLines)); i++ {
+		fmt.Fprintf(&sb, "+ %s\n", gotLines[i])
+	}
+
+	return sb.String()
+}
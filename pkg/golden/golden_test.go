@@ -0,0 +1,60 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "history_burndown.json", FileName("history/burndown"))
+}
+
+func TestNormalize_SortsKeysAndKeepsNumbers(t *testing.T) {
+	t.Parallel()
+
+	got, err := Normalize([]byte(`{"b": 1.50, "a": [3, 12345678901234567890]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": [\n    3,\n    12345678901234567890\n  ],\n  \"b\": 1.50\n}\n", string(got))
+}
+
+func TestVerify_UpdateThenMatch(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "history_devs.json")
+
+	require.NoError(t, Verify(path, []byte(`{"x": 1, "y": 2}`), true))
+	require.NoError(t, Verify(path, []byte(`{"y":2,"x":1}`), false))
+}
+
+func TestVerify_Mismatch(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history_devs.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"x": 1}`), 0o600))
+
+	err := Verify(path, []byte(`{"x": 2}`), false)
+	require.ErrorIs(t, err, ErrMismatch)
+	assert.Contains(t, err.Error(), "- ")
+	assert.Contains(t, err.Error(), "\"x\": 1")
+	assert.Contains(t, err.Error(), "\"x\": 2")
+}
+
+func TestVerify_Missing(t *testing.T) {
+	t.Parallel()
+
+	err := Verify(filepath.Join(t.TempDir(), "absent.json"), []byte(`{}`), false)
+	require.ErrorIs(t, err, ErrMissing)
+}
+
+func TestVerify_InvalidReport(t *testing.T) {
+	t.Parallel()
+
+	err := Verify(filepath.Join(t.TempDir(), "r.json"), []byte(`not json`), true)
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package golden
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/framework"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// RunHistory runs the configured core (plumbing) analyzers and leaves over the
+// first-parent history of repo and returns the JSON report of each leaf,
+// keyed by its descriptor ID.
+func RunHistory(
+	ctx context.Context, repo *gitlib.Repository, core, leaves []analyze.HistoryAnalyzer,
+) (map[string][]byte, error) {
+	commits, err := gitlib.LoadCommits(ctx, repo, gitlib.CommitLoadOptions{FirstParent: true})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		for _, c := range commits {
+			c.Free()
+		}
+	}()
+
+	analyzers := make([]analyze.HistoryAnalyzer, 0, len(core)+len(leaves))
+	analyzers = append(analyzers, core...)
+	analyzers = append(analyzers, leaves...)
+
+	config := framework.DefaultCoordinatorConfig()
+	config.FirstParent = true
+
+	runner := framework.NewRunnerWithConfig(repo, repo.Path(), config, analyzers...)
+	runner.CoreCount = len(core)
+
+	results, err := runner.Run(ctx, commits)
+	if err != nil {
+		return nil, fmt.Errorf("run history analyzers: %w", err)
+	}
+
+	reports := make(map[string][]byte, len(leaves))
+
+	for _, leaf := range leaves {
+		var buf bytes.Buffer
+
+		err = leaf.Serialize(results[leaf], analyze.FormatJSON, &buf)
+		if err != nil {
+			return nil, fmt.Errorf("serialize %s: %w", leaf.Name(), err)
+		}
+
+		reports[leaf.Descriptor().ID] = buf.Bytes()
+	}
+
+	return reports, nil
+}
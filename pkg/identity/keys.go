@@ -26,6 +26,10 @@ const (
 	// (Detector.Configure()) which changes the matching algorithm to exact signature (name + email)
 	// correspondence.
 	ConfigIdentityDetectorExactSignatures = "IdentityDetector.ExactSignatures"
+	// ConfigIdentityDetectorCoAuthorWeight is the name of the configuration option
+	// (Detector.Configure()) which sets the share of a commit credited to each of its
+	// Co-authored-by co-authors relative to the author's share of 1.
+	ConfigIdentityDetectorCoAuthorWeight = "IdentityDetector.CoAuthorWeight"
 	// FactIdentityDetectorPeopleCount is the name of the fact which is inserted in
 	// Detector.Configure(). It is equal to the overall number of unique authors
 	// (the length of ReversedPeopleDict).
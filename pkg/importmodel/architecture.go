@@ -0,0 +1,403 @@
+package importmodel
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ArchitectureFile is the name of the architecture file looked up at the
+// root of an analyzed tree.
+const ArchitectureFile = "arch.yaml"
+
+// ErrInvalidArchitecture is returned for architecture files that cannot be used.
+var ErrInvalidArchitecture = errors.New("invalid architecture")
+
+// Rules of an architecture, as reported in Violation.Rule.
+const (
+	RuleLayer           = "layer"
+	RuleForbiddenImport = "forbidden_import"
+	RuleBoundary        = "boundary"
+)
+
+// Architecture declares the rules a code base's imports must follow.
+//
+// Layers are listed from the top layer down. A package may depend on packages
+// of its own layer and of the layers below it, or on the layers its layer
+// allows explicitly; any other layer dependency is a violation. Forbidden
+// imports ban imports outright, and boundaries only let outside packages in
+// through their exported packages.
+type Architecture struct {
+	Layers     []Layer           `yaml:"layers"`
+	Forbidden  []ForbiddenImport `yaml:"forbidden"`
+	Boundaries []Boundary        `yaml:"boundaries"`
+}
+
+// Layer groups packages by pattern. A pattern is a slash-separated package
+// path relative to the analyzed root; a path.Match pattern matches one
+// package, and a trailing "/**" also matches every package below it.
+type Layer struct {
+	Name     string   `yaml:"name"`
+	Packages []string `yaml:"packages"`
+	// Allow names the layers this layer may depend on besides itself,
+	// replacing the default of every layer below it.
+	Allow []string `yaml:"allow"`
+}
+
+// ForbiddenImport bans the imports matching Import in the packages matching
+// From. Import is matched against the import as written and, for imports of
+// analyzed packages, against the package it resolves to.
+type ForbiddenImport struct {
+	// From is a package pattern; empty means every package.
+	From   string `yaml:"from"`
+	Import string `yaml:"import"`
+	Reason string `yaml:"reason"`
+}
+
+// Boundary is a group of packages owned by one team. Packages outside the
+// boundary may only import its Exports.
+type Boundary struct {
+	Name     string   `yaml:"name"`
+	Owner    string   `yaml:"owner"`
+	Packages []string `yaml:"packages"`
+	Exports  []string `yaml:"exports"`
+}
+
+// Violation is an import that breaks a rule of an architecture.
+type Violation struct {
+	Rule string `json:"rule"         yaml:"rule"`
+	// From is the importing package.
+	From   string `json:"from"         yaml:"from"`
+	Import string `json:"import"       yaml:"import"`
+	// To is the analyzed package Import resolves to, empty for external imports.
+	To     string `json:"to,omitempty" yaml:"to,omitempty"`
+	Detail string `json:"detail"       yaml:"detail"`
+}
+
+// Key identifies the violation across runs and commits.
+func (v Violation) Key() string {
+	return v.Rule + "\x00" + v.From + "\x00" + v.Import
+}
+
+// LayerViolation is a dependency on a package of a higher layer.
+type LayerViolation struct {
+	From      string `json:"from"       yaml:"from"`
+	To        string `json:"to"         yaml:"to"`
+	FromLayer string `json:"from_layer" yaml:"from_layer"`
+	ToLayer   string `json:"to_layer"   yaml:"to_layer"`
+}
+
+// LoadArchitecture reads and validates the architecture file at path.
+func LoadArchitecture(path string) (*Architecture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read architecture: %w", err)
+	}
+
+	return ParseArchitecture(data)
+}
+
+// ParseArchitecture decodes and validates an architecture file.
+func ParseArchitecture(data []byte) (*Architecture, error) {
+	var arch Architecture
+
+	err := yaml.Unmarshal(data, &arch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArchitecture, err)
+	}
+
+	err = arch.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &arch, nil
+}
+
+func (a *Architecture) validate() error {
+	seen := make(map[string]bool, len(a.Layers))
+
+	for i, layer := range a.Layers {
+		if layer.Name == "" {
+			return fmt.Errorf("%w: layer %d has no name", ErrInvalidArchitecture, i+1)
+		}
+
+		if seen[layer.Name] {
+			return fmt.Errorf("%w: duplicate layer %q", ErrInvalidArchitecture, layer.Name)
+		}
+
+		seen[layer.Name] = true
+
+		err := validatePatterns("layer "+strconv.Quote(layer.Name), layer.Packages)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, layer := range a.Layers {
+		for _, allowed := range layer.Allow {
+			if !seen[allowed] {
+				return fmt.Errorf("%w: layer %q allows unknown layer %q", ErrInvalidArchitecture, layer.Name, allowed)
+			}
+		}
+	}
+
+	for i, rule := range a.Forbidden {
+		if rule.Import == "" {
+			return fmt.Errorf("%w: forbidden import %d has no import pattern", ErrInvalidArchitecture, i+1)
+		}
+
+		err := validatePatterns("forbidden import "+strconv.Itoa(i+1), []string{rule.From, rule.Import})
+		if err != nil {
+			return err
+		}
+	}
+
+	return a.validateBoundaries()
+}
+
+func (a *Architecture) validateBoundaries() error {
+	seen := make(map[string]bool, len(a.Boundaries))
+
+	for i, boundary := range a.Boundaries {
+		if boundary.Name == "" {
+			return fmt.Errorf("%w: boundary %d has no name", ErrInvalidArchitecture, i+1)
+		}
+
+		if seen[boundary.Name] {
+			return fmt.Errorf("%w: duplicate boundary %q", ErrInvalidArchitecture, boundary.Name)
+		}
+
+		seen[boundary.Name] = true
+
+		err := validatePatterns("boundary "+strconv.Quote(boundary.Name), slices.Concat(boundary.Packages, boundary.Exports))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validatePatterns(owner string, patterns []string) error {
+	for _, pattern := range patterns {
+		_, err := path.Match(strings.TrimSuffix(pattern, "/**"), "")
+		if err != nil {
+			return fmt.Errorf("%w: %s: pattern %q: %w", ErrInvalidArchitecture, owner, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// LayerOf returns the index of the first layer with a pattern matching pkg.
+func (a *Architecture) LayerOf(pkg string) (int, bool) {
+	for i, layer := range a.Layers {
+		for _, pattern := range layer.Packages {
+			if MatchPackage(pattern, pkg) {
+				return i, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// mayDepend reports whether layer from may depend on layer to.
+func (a *Architecture) mayDepend(from, to int) bool {
+	if from == to {
+		return true
+	}
+
+	if allow := a.Layers[from].Allow; len(allow) > 0 {
+		return slices.Contains(allow, a.Layers[to].Name)
+	}
+
+	return to > from
+}
+
+// LayerViolations returns the edges of g that point from a layer to a layer
+// it may not depend on. Packages outside every layer are not checked.
+func (a *Architecture) LayerViolations(g *Graph) []LayerViolation {
+	var violations []LayerViolation
+
+	for _, edge := range g.Edges() {
+		from, fromOK := a.LayerOf(edge.From)
+		to, toOK := a.LayerOf(edge.To)
+
+		if fromOK && toOK && !a.mayDepend(from, to) {
+			violations = append(violations, LayerViolation{
+				From:      edge.From,
+				To:        edge.To,
+				FromLayer: a.Layers[from].Name,
+				ToLayer:   a.Layers[to].Name,
+			})
+		}
+	}
+
+	return violations
+}
+
+// Check returns the violations of the imports made by package from, with
+// r resolving imports to analyzed packages. Each import is reported at most
+// once per rule.
+func (a *Architecture) Check(from string, imports []string, r *Resolver) []Violation {
+	var violations []Violation
+
+	seen := make(map[string]bool, len(imports))
+	add := func(v Violation) {
+		if !seen[v.Key()] {
+			seen[v.Key()] = true
+			violations = append(violations, v)
+		}
+	}
+
+	for _, imp := range imports {
+		to := r.Resolve(imp, from)
+
+		if rule, ok := a.forbiddenRule(from, imp, to); ok {
+			detail := rule.Reason
+			if detail == "" {
+				detail = "import of " + imp + " is forbidden"
+			}
+
+			add(Violation{Rule: RuleForbiddenImport, From: from, Import: imp, To: to, Detail: detail})
+		}
+
+		if to == "" || to == from {
+			continue
+		}
+
+		if detail, ok := a.checkLayers(from, to); ok {
+			add(Violation{Rule: RuleLayer, From: from, Import: imp, To: to, Detail: detail})
+		}
+
+		if detail, ok := a.checkBoundaries(from, to); ok {
+			add(Violation{Rule: RuleBoundary, From: from, Import: imp, To: to, Detail: detail})
+		}
+	}
+
+	return violations
+}
+
+// Violations checks the imports of files, keyed by their slash-separated
+// path relative to the analyzed root, and returns the violations sorted by
+// package, rule and import.
+func (a *Architecture) Violations(files map[string][]string, modulePath string) []Violation {
+	r := NewResolver(modulePath)
+	byPackage := make(map[string][]string, len(files))
+
+	for file, imps := range files {
+		r.AddFile(file)
+
+		pkg := path.Dir(file)
+		byPackage[pkg] = append(byPackage[pkg], imps...)
+	}
+
+	var violations []Violation
+
+	for pkg, imps := range byPackage {
+		violations = append(violations, a.Check(pkg, imps, r)...)
+	}
+
+	SortViolations(violations)
+
+	return violations
+}
+
+// SortViolations sorts violations by package, rule and import.
+func SortViolations(violations []Violation) {
+	slices.SortFunc(violations, CompareViolations)
+}
+
+// CompareViolations orders violations by package, rule and import.
+func CompareViolations(x, y Violation) int {
+	return cmp.Or(cmp.Compare(x.From, y.From), cmp.Compare(x.Rule, y.Rule), cmp.Compare(x.Import, y.Import))
+}
+
+func (a *Architecture) forbiddenRule(from, imp, to string) (ForbiddenImport, bool) {
+	for _, rule := range a.Forbidden {
+		if rule.From != "" && !MatchPackage(rule.From, from) {
+			continue
+		}
+
+		if MatchPackage(rule.Import, imp) || (to != "" && MatchPackage(rule.Import, to)) {
+			return rule, true
+		}
+	}
+
+	return ForbiddenImport{}, false
+}
+
+func (a *Architecture) checkLayers(from, to string) (string, bool) {
+	fromLayer, fromOK := a.LayerOf(from)
+	toLayer, toOK := a.LayerOf(to)
+
+	if !fromOK || !toOK || a.mayDepend(fromLayer, toLayer) {
+		return "", false
+	}
+
+	return "layer " + a.Layers[fromLayer].Name + " may not depend on layer " + a.Layers[toLayer].Name, true
+}
+
+func (a *Architecture) checkBoundaries(from, to string) (string, bool) {
+	target, ok := a.boundaryOf(to)
+	if !ok {
+		return "", false
+	}
+
+	if source, inside := a.boundaryOf(from); inside && source == target {
+		return "", false
+	}
+
+	boundary := a.Boundaries[target]
+	for _, pattern := range boundary.Exports {
+		if MatchPackage(pattern, to) {
+			return "", false
+		}
+	}
+
+	detail := to + " is internal to boundary " + boundary.Name
+	if boundary.Owner != "" {
+		detail += " (owned by " + boundary.Owner + ")"
+	}
+
+	return detail, true
+}
+
+// boundaryOf returns the index of the first boundary with a pattern matching pkg.
+func (a *Architecture) boundaryOf(pkg string) (int, bool) {
+	for i, boundary := range a.Boundaries {
+		for _, pattern := range boundary.Packages {
+			if MatchPackage(pattern, pkg) {
+				return i, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// MatchPackage reports whether the package path pkg matches pattern.
+func MatchPackage(pattern, pkg string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		for dir := pkg; dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if matched, _ := path.Match(prefix, dir); matched {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	matched, _ := path.Match(pattern, pkg)
+
+	return matched
+}
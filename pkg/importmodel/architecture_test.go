@@ -0,0 +1,184 @@
+package importmodel_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+const testArchitecture = `
+layers:
+  - name: cmd
+    packages: ["cmd/**"]
+  - name: framework
+    packages: ["pkg/framework"]
+  - name: core
+    packages: ["pkg/*/**"]
+`
+
+func TestMatchPackage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		pkg     string
+		want    bool
+	}{
+		{"cmd/**", "cmd", true},
+		{"cmd/**", "cmd/codefang/commands", true},
+		{"cmd/**", "cmdline", false},
+		{"pkg/*/**", "pkg/uast/pkg/node", true},
+		{"pkg/*/**", "pkg", false},
+		{"pkg/framework", "pkg/framework", true},
+		{"pkg/framework", "pkg/framework/sub", false},
+		{"pkg/*", "pkg/uast", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, importmodel.MatchPackage(tt.pattern, tt.pkg), "%s ~ %s", tt.pattern, tt.pkg)
+	}
+}
+
+func TestArchitecture_LayerViolations(t *testing.T) {
+	t.Parallel()
+
+	arch, err := importmodel.ParseArchitecture([]byte(testArchitecture))
+	require.NoError(t, err)
+
+	g := importmodel.NewGraph()
+	g.AddDependency("cmd/codefang", "pkg/framework")
+	g.AddDependency("pkg/framework", "pkg/uast")
+	g.AddDependency("pkg/uast", "pkg/framework")
+	g.AddDependency("pkg/gitlib", "cmd/codefang")
+	g.AddDependency("internal/tools", "cmd/codefang")
+
+	assert.Equal(t, []importmodel.LayerViolation{
+		{From: "pkg/gitlib", To: "cmd/codefang", FromLayer: "core", ToLayer: "cmd"},
+		{From: "pkg/uast", To: "pkg/framework", FromLayer: "core", ToLayer: "framework"},
+	}, arch.LayerViolations(g))
+}
+
+func TestParseArchitecture_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, doc := range []string{
+		"layers: [{packages: [cmd]}]",
+		"layers: [{name: a}, {name: a}]",
+		"layers: [{name: a, packages: ['[']}]",
+		"layers: {",
+	} {
+		_, err := importmodel.ParseArchitecture([]byte(doc))
+		require.ErrorIs(t, err, importmodel.ErrInvalidArchitecture, doc)
+	}
+}
+
+func TestLoadArchitecture(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), importmodel.ArchitectureFile)
+	require.NoError(t, os.WriteFile(path, []byte(testArchitecture), 0o600))
+
+	arch, err := importmodel.LoadArchitecture(path)
+	require.NoError(t, err)
+	assert.Len(t, arch.Layers, 3)
+
+	_, err = importmodel.LoadArchitecture(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+const testRules = `
+layers:
+  - name: cmd
+    packages: ["cmd/**"]
+  - name: api
+    packages: ["pkg/api/**"]
+    allow: [store]
+  - name: service
+    packages: ["pkg/service/**"]
+  - name: store
+    packages: ["pkg/store/**"]
+forbidden:
+  - from: "pkg/**"
+    import: "os/exec"
+    reason: "libraries must not spawn processes"
+  - import: "pkg/legacy/**"
+boundaries:
+  - name: billing
+    owner: team-billing
+    packages: ["pkg/service/billing/**"]
+    exports: ["pkg/service/billing"]
+`
+
+func TestArchitecture_Violations(t *testing.T) {
+	t.Parallel()
+
+	arch, err := importmodel.ParseArchitecture([]byte(testRules))
+	require.NoError(t, err)
+
+	violations := arch.Violations(map[string][]string{
+		"cmd/main.go":                              {"example.com/mod/pkg/api", "os/exec"},
+		"pkg/api/api.go":                           {"example.com/mod/pkg/service/billing", "example.com/mod/pkg/store"},
+		"pkg/service/orders/orders.go":             {"example.com/mod/pkg/service/billing/internal/ledger", "os/exec"},
+		"pkg/service/billing/billing.go":           {"example.com/mod/pkg/service/billing/internal/ledger"},
+		"pkg/service/billing/internal/ledger/l.go": {"example.com/mod/pkg/legacy"},
+		"pkg/legacy/legacy.go":                     nil,
+		"pkg/store/store.go":                       nil,
+	}, "example.com/mod")
+
+	assert.Equal(t, []importmodel.Violation{
+		{
+			Rule: importmodel.RuleLayer, From: "pkg/api", Import: "example.com/mod/pkg/service/billing",
+			To: "pkg/service/billing", Detail: "layer api may not depend on layer service",
+		},
+		{
+			Rule: importmodel.RuleForbiddenImport, From: "pkg/service/billing/internal/ledger", Import: "example.com/mod/pkg/legacy",
+			To: "pkg/legacy", Detail: "import of example.com/mod/pkg/legacy is forbidden",
+		},
+		{
+			Rule: importmodel.RuleBoundary, From: "pkg/service/orders", Import: "example.com/mod/pkg/service/billing/internal/ledger",
+			To:     "pkg/service/billing/internal/ledger",
+			Detail: "pkg/service/billing/internal/ledger is internal to boundary billing (owned by team-billing)",
+		},
+		{
+			Rule: importmodel.RuleForbiddenImport, From: "pkg/service/orders", Import: "os/exec",
+			Detail: "libraries must not spawn processes",
+		},
+	}, violations)
+}
+
+func TestArchitecture_LayerViolationsHonorAllow(t *testing.T) {
+	t.Parallel()
+
+	arch, err := importmodel.ParseArchitecture([]byte(testRules))
+	require.NoError(t, err)
+
+	g := importmodel.NewGraph()
+	g.AddDependency("pkg/api", "pkg/store")
+	g.AddDependency("pkg/api", "pkg/service")
+	g.AddDependency("pkg/service", "pkg/store")
+
+	assert.Equal(t, []importmodel.LayerViolation{
+		{From: "pkg/api", To: "pkg/service", FromLayer: "api", ToLayer: "service"},
+	}, arch.LayerViolations(g))
+}
+
+func TestParseArchitecture_InvalidRules(t *testing.T) {
+	t.Parallel()
+
+	for _, doc := range []string{
+		"layers: [{name: a, allow: [b]}]",
+		"forbidden: [{from: pkg}]",
+		"forbidden: [{import: '['}]",
+		"boundaries: [{packages: [pkg]}]",
+		"boundaries: [{name: a}, {name: a}]",
+		"boundaries: [{name: a, exports: ['[']}]",
+	} {
+		_, err := importmodel.ParseArchitecture([]byte(doc))
+		require.ErrorIs(t, err, importmodel.ErrInvalidArchitecture, doc)
+	}
+}
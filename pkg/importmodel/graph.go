@@ -0,0 +1,148 @@
+package importmodel
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Edge is a dependency of one package on another.
+type Edge struct {
+	From string `json:"from"    yaml:"from"`
+	To   string `json:"to"      yaml:"to"`
+	// Imports counts the import statements behind the dependency.
+	Imports int `json:"imports" yaml:"imports"`
+}
+
+// Graph is a directed package dependency graph.
+type Graph struct {
+	deps map[string]map[string]int
+}
+
+// NewGraph creates an empty Graph.
+func NewGraph() *Graph {
+	return &Graph{deps: make(map[string]map[string]int)}
+}
+
+// AddPackage adds pkg as a node, with no dependencies yet.
+func (g *Graph) AddPackage(pkg string) {
+	if g.deps[pkg] == nil {
+		g.deps[pkg] = make(map[string]int)
+	}
+}
+
+// AddDependency records one import of to from from. Self imports are ignored.
+func (g *Graph) AddDependency(from, to string) {
+	g.AddPackage(from)
+	g.AddPackage(to)
+
+	if from != to {
+		g.deps[from][to]++
+	}
+}
+
+// Packages returns all packages in sorted order.
+func (g *Graph) Packages() []string {
+	pkgs := make([]string, 0, len(g.deps))
+	for pkg := range g.deps {
+		pkgs = append(pkgs, pkg)
+	}
+
+	slices.Sort(pkgs)
+
+	return pkgs
+}
+
+// Edges returns all dependencies sorted by source, then target.
+func (g *Graph) Edges() []Edge {
+	var edges []Edge
+
+	for from, targets := range g.deps {
+		for to, n := range targets {
+			edges = append(edges, Edge{From: from, To: to, Imports: n})
+		}
+	}
+
+	slices.SortFunc(edges, func(a, b Edge) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To))
+	})
+
+	return edges
+}
+
+// Cycles returns the strongly connected components with more than one
+// package. Each cycle is sorted, and cycles are ordered by their first package.
+func (g *Graph) Cycles() [][]string {
+	t := &tarjan{
+		graph: g,
+		index: make(map[string]int),
+		low:   make(map[string]int),
+		on:    make(map[string]bool),
+	}
+
+	for _, pkg := range g.Packages() {
+		if _, seen := t.index[pkg]; !seen {
+			t.connect(pkg)
+		}
+	}
+
+	slices.SortFunc(t.cycles, func(a, b []string) int { return cmp.Compare(a[0], b[0]) })
+
+	return t.cycles
+}
+
+// tarjan holds the state of Tarjan's strongly connected components algorithm.
+type tarjan struct {
+	graph  *Graph
+	next   int
+	index  map[string]int
+	low    map[string]int
+	on     map[string]bool
+	stack  []string
+	cycles [][]string
+}
+
+func (t *tarjan) connect(pkg string) {
+	t.index[pkg] = t.next
+	t.low[pkg] = t.next
+	t.next++
+	t.stack = append(t.stack, pkg)
+	t.on[pkg] = true
+
+	targets := make([]string, 0, len(t.graph.deps[pkg]))
+	for to := range t.graph.deps[pkg] {
+		targets = append(targets, to)
+	}
+
+	slices.Sort(targets)
+
+	for _, to := range targets {
+		if _, seen := t.index[to]; !seen {
+			t.connect(to)
+			t.low[pkg] = min(t.low[pkg], t.low[to])
+		} else if t.on[to] {
+			t.low[pkg] = min(t.low[pkg], t.index[to])
+		}
+	}
+
+	if t.low[pkg] != t.index[pkg] {
+		return
+	}
+
+	var component []string
+
+	for {
+		top := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.on[top] = false
+		component = append(component, top)
+
+		if top == pkg {
+			break
+		}
+	}
+
+	if len(component) > 1 {
+		slices.Sort(component)
+		t.cycles = append(t.cycles, component)
+	}
+}
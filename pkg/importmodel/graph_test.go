@@ -0,0 +1,38 @@
+package importmodel_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+func TestGraph_EdgesCountImports(t *testing.T) {
+	t.Parallel()
+
+	g := importmodel.NewGraph()
+	g.AddDependency("cmd", "pkg/a")
+	g.AddDependency("cmd", "pkg/a")
+	g.AddDependency("pkg/a", "pkg/a")
+	g.AddPackage("pkg/b")
+
+	assert.Equal(t, []string{"cmd", "pkg/a", "pkg/b"}, g.Packages())
+	assert.Equal(t, []importmodel.Edge{{From: "cmd", To: "pkg/a", Imports: 2}}, g.Edges())
+	assert.Empty(t, g.Cycles())
+}
+
+func TestGraph_Cycles(t *testing.T) {
+	t.Parallel()
+
+	g := importmodel.NewGraph()
+	g.AddDependency("a", "b")
+	g.AddDependency("b", "c")
+	g.AddDependency("c", "a")
+	g.AddDependency("c", "d")
+	g.AddDependency("x", "y")
+	g.AddDependency("y", "x")
+	g.AddDependency("d", "e")
+
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"x", "y"}}, g.Cycles())
+}
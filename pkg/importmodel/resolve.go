@@ -0,0 +1,134 @@
+package importmodel
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"strings"
+)
+
+// Resolver maps imports to the analyzed packages they refer to. Files are
+// keyed by their slash-separated path relative to the analyzed root, and a
+// file's package is its directory.
+type Resolver struct {
+	// ModulePath is the go.mod module path used to resolve Go imports.
+	ModulePath string
+
+	files map[string]int // Package -> number of files.
+}
+
+// NewResolver creates a Resolver with no known packages.
+func NewResolver(modulePath string) *Resolver {
+	return &Resolver{ModulePath: modulePath, files: make(map[string]int)}
+}
+
+// AddFile makes the package of file known.
+func (r *Resolver) AddFile(file string) {
+	r.files[path.Dir(file)]++
+}
+
+// RemoveFile forgets file; its package stays known while it has other files.
+func (r *Resolver) RemoveFile(file string) {
+	pkg := path.Dir(file)
+
+	r.files[pkg]--
+	if r.files[pkg] <= 0 {
+		delete(r.files, pkg)
+	}
+}
+
+// Known reports whether pkg has analyzed files.
+func (r *Resolver) Known(pkg string) bool {
+	return r.files[pkg] > 0
+}
+
+// Resolve maps an import made in package from to an analyzed package, or
+// returns "" when the import is external. It understands relative imports
+// (JavaScript, Python), module-qualified Go imports and dotted module names.
+func (r *Resolver) Resolve(imp, from string) string {
+	switch {
+	case imp == "." || strings.HasPrefix(imp, "./") || strings.HasPrefix(imp, "../"):
+		return r.packageOrDir(path.Join(from, imp))
+	case r.ModulePath != "" && (imp == r.ModulePath || strings.HasPrefix(imp, r.ModulePath+"/")):
+		rel := strings.TrimPrefix(strings.TrimPrefix(imp, r.ModulePath), "/")
+		if rel == "" {
+			rel = "."
+		}
+
+		if r.Known(rel) {
+			return rel
+		}
+
+		return ""
+	case r.Known(imp):
+		return imp
+	case !strings.Contains(imp, "/"):
+		return r.packageOrDir(strings.ReplaceAll(imp, ".", "/"))
+	default:
+		return ""
+	}
+}
+
+// packageOrDir returns p when it is a known package, or the directory of p
+// when p names a module file inside a known package.
+func (r *Resolver) packageOrDir(p string) string {
+	if r.Known(p) {
+		return p
+	}
+
+	if dir := path.Dir(p); dir != "." && r.Known(dir) {
+		return dir
+	}
+
+	return ""
+}
+
+// BuildGraph builds the package graph of files, keyed by their
+// slash-separated path relative to the analyzed root. Imports that do not
+// resolve to an analyzed package are external and left out.
+func BuildGraph(files map[string][]string, modulePath string) *Graph {
+	graph := NewGraph()
+	resolver := NewResolver(modulePath)
+
+	for file := range files {
+		resolver.AddFile(file)
+		graph.AddPackage(path.Dir(file))
+	}
+
+	for file, imps := range files {
+		from := path.Dir(file)
+
+		for _, imp := range imps {
+			if to := resolver.Resolve(imp, from); to != "" {
+				graph.AddDependency(from, to)
+			}
+		}
+	}
+
+	return graph
+}
+
+// ParseModulePath returns the module path declared in go.mod contents, or ""
+// when there is none.
+func ParseModulePath(goMod []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(goMod))
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`)
+		}
+	}
+
+	return ""
+}
+
+// ReadModulePath returns the module path declared in the go.mod file at
+// goModPath, or "" when there is none.
+func ReadModulePath(goModPath string) string {
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return ""
+	}
+
+	return ParseModulePath(data)
+}
@@ -0,0 +1,81 @@
+package importmodel_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/importmodel"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	t.Parallel()
+
+	r := importmodel.NewResolver("example.com/mod")
+	for _, file := range []string{"main.go", "pkg/a/a.go", "pkg/b/b.go", "web/src/index.js", "web/src/lib/x.js", "app/models/user.py"} {
+		r.AddFile(file)
+	}
+
+	tests := []struct {
+		imp  string
+		from string
+		want string
+	}{
+		{"example.com/mod/pkg/a", "pkg/b", "pkg/a"},
+		{"example.com/mod", "pkg/b", "."},
+		{"example.com/mod/pkg/missing", "pkg/b", ""},
+		{"fmt", "pkg/b", ""},
+		{"github.com/other/pkg/a", "pkg/b", ""},
+		{"./lib", "web/src", "web/src/lib"},
+		{"./lib/util", "web/src", "web/src/lib"},
+		{"../src", "web/src/lib", "web/src"},
+		{"app.models", ".", "app/models"},
+		{"app.models.user", ".", "app/models"},
+		{"pkg/a", ".", "pkg/a"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, r.Resolve(tt.imp, tt.from), tt.imp)
+	}
+}
+
+func TestResolver_RemoveFile(t *testing.T) {
+	t.Parallel()
+
+	r := importmodel.NewResolver("")
+	r.AddFile("pkg/a/a.go")
+	r.AddFile("pkg/a/b.go")
+
+	r.RemoveFile("pkg/a/a.go")
+	assert.True(t, r.Known("pkg/a"))
+
+	r.RemoveFile("pkg/a/b.go")
+	assert.False(t, r.Known("pkg/a"))
+}
+
+func TestBuildGraph(t *testing.T) {
+	t.Parallel()
+
+	g := importmodel.BuildGraph(map[string][]string{
+		"cmd/main.go": {"example.com/mod/pkg/a", "fmt"},
+		"pkg/a/a.go":  {"example.com/mod/pkg/a"},
+	}, "example.com/mod")
+
+	assert.Equal(t, []string{"cmd", "pkg/a"}, g.Packages())
+	assert.Equal(t, []importmodel.Edge{{From: "cmd", To: "pkg/a", Imports: 1}}, g.Edges())
+}
+
+func TestReadModulePath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	goMod := filepath.Join(dir, "go.mod")
+	require.NoError(t, os.WriteFile(goMod, []byte("// comment\nmodule example.com/mod\n\ngo 1.24\n"), 0o600))
+
+	assert.Equal(t, "example.com/mod", importmodel.ReadModulePath(goMod))
+	assert.Empty(t, importmodel.ReadModulePath(filepath.Join(dir, "missing")))
+	assert.Equal(t, "example.com/quoted", importmodel.ParseModulePath([]byte(`module "example.com/quoted"`)))
+}
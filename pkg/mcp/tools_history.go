@@ -167,7 +167,7 @@ func formatHistoryResults(
 ) (*mcpsdk.CallToolResult, ToolOutput, error) {
 	var buf bytes.Buffer
 
-	err := analyze.OutputHistoryResults(selectedLeaves, results, analyze.FormatJSON, &buf)
+	err := analyze.OutputHistoryResults(selectedLeaves, results, analyze.FormatJSON, &buf, 0)
 	if err != nil {
 		return errorResult(fmt.Errorf("format results: %w", err))
 	}
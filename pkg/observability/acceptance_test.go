@@ -60,7 +60,13 @@ func TestAcceptance_EndToEnd(t *testing.T) {
 	// Simulate pipeline: root span, child spans, metrics, logs.
 	ctx, rootSpan := tracer.Start(context.Background(), "codefang.run")
 
-	_, chunkSpan := tracer.Start(ctx, "codefang.chunk")
+	chunkCtx, chunkSpan := tracer.Start(ctx, "codefang.chunk")
+	analysis.RecordChunkCache(chunkCtx, observability.ChunkCacheStats{
+		BlobHits:   100,
+		BlobMisses: 10,
+		DiffHits:   50,
+		DiffMisses: 5,
+	})
 	chunkSpan.End()
 
 	_, analyzeSpan := tracer.Start(ctx, "codefang.analyzer.Burndown")
@@ -70,13 +76,9 @@ func TestAcceptance_EndToEnd(t *testing.T) {
 	red.RecordRequest(ctx, "cli.run", "ok", time.Second)
 
 	analysis.RecordRun(ctx, observability.AnalysisStats{
-		Commits:         acceptanceCommitCount,
-		Chunks:          3,
-		ChunkDurations:  []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
-		BlobCacheHits:   100,
-		BlobCacheMisses: 10,
-		DiffCacheHits:   50,
-		DiffCacheMisses: 5,
+		Commits:        acceptanceCommitCount,
+		Chunks:         3,
+		ChunkDurations: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
 	})
 
 	// Emit a log line within the trace context.
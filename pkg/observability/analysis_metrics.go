@@ -15,8 +15,12 @@ const (
 	metricChunkDuration    = "codefang.analysis.chunk.duration.seconds"
 	metricCacheHitsTotal   = "codefang.analysis.cache.hits.total"
 	metricCacheMissesTotal = "codefang.analysis.cache.misses.total"
+	metricSinkRecordsTotal = "codefang.analysis.sink.records.total"
+	metricPromisorFetches  = "codefang.analysis.promisor.fetches.total"
+	metricPromisorObjects  = "codefang.analysis.promisor.objects.total"
 
-	attrCache = "cache"
+	attrCache   = "cache"
+	attrOutcome = "outcome"
 )
 
 // AnalysisMetrics holds OTel instruments for analysis-specific metrics.
@@ -26,18 +30,40 @@ type AnalysisMetrics struct {
 	chunkDuration metric.Float64Histogram
 	cacheHits     metric.Int64Counter
 	cacheMisses   metric.Int64Counter
+	sinkRecords   metric.Int64Counter
+	promFetches   metric.Int64Counter
+	promObjects   metric.Int64Counter
 }
 
 // AnalysisStats holds the statistics for a single streaming run,
 // decoupled from framework types.
 type AnalysisStats struct {
-	Commits         int64
-	Chunks          int
-	ChunkDurations  []time.Duration
-	BlobCacheHits   int64
-	BlobCacheMisses int64
-	DiffCacheHits   int64
-	DiffCacheMisses int64
+	Commits        int64
+	Chunks         int
+	ChunkDurations []time.Duration
+}
+
+// ChunkCacheStats holds the blob and diff cache counters of one chunk.
+type ChunkCacheStats struct {
+	BlobHits   int64
+	BlobMisses int64
+	DiffHits   int64
+	DiffMisses int64
+}
+
+// SinkStats holds the record outcomes of a buffered streaming output sink.
+type SinkStats struct {
+	Written int64
+	Dropped int64
+	Sampled int64
+	Failed  int64
+}
+
+// PromisorFetchStats holds the lazy fetch counters of a partial clone.
+type PromisorFetchStats struct {
+	Fetches  int64
+	Objects  int64
+	Failures int64
 }
 
 // NewAnalysisMetrics creates analysis metric instruments from the given meter.
@@ -83,12 +109,39 @@ func NewAnalysisMetrics(mt metric.Meter) (*AnalysisMetrics, error) {
 		return nil, fmt.Errorf("create %s: %w", metricCacheMissesTotal, err)
 	}
 
+	sinkRecords, err := mt.Int64Counter(metricSinkRecordsTotal,
+		metric.WithDescription("Streaming output records by outcome"),
+		metric.WithUnit("{record}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create %s: %w", metricSinkRecordsTotal, err)
+	}
+
+	promFetches, err := mt.Int64Counter(metricPromisorFetches,
+		metric.WithDescription("Fetches of missing partial clone objects by outcome"),
+		metric.WithUnit("{fetch}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create %s: %w", metricPromisorFetches, err)
+	}
+
+	promObjects, err := mt.Int64Counter(metricPromisorObjects,
+		metric.WithDescription("Missing partial clone objects requested from the promisor remote"),
+		metric.WithUnit("{object}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create %s: %w", metricPromisorObjects, err)
+	}
+
 	return &AnalysisMetrics{
 		commitsTotal:  commits,
 		chunksTotal:   chunks,
 		chunkDuration: chunkDur,
 		cacheHits:     hits,
 		cacheMisses:   misses,
+		sinkRecords:   sinkRecords,
+		promFetches:   promFetches,
+		promObjects:   promObjects,
 	}, nil
 }
 
@@ -105,12 +158,56 @@ func (am *AnalysisMetrics) RecordRun(ctx context.Context, stats AnalysisStats) {
 	for _, d := range stats.ChunkDurations {
 		am.chunkDuration.Record(ctx, d.Seconds())
 	}
+}
+
+// RecordChunkCache records the cache counters of one chunk. When ctx carries
+// the sampled span of the chunk, the counters' exemplars link to its trace,
+// so a spike in cache misses leads to the chunk that caused it.
+// Safe to call on a nil receiver (no-op).
+func (am *AnalysisMetrics) RecordChunkCache(ctx context.Context, stats ChunkCacheStats) {
+	if am == nil {
+		return
+	}
 
 	blobAttrs := metric.WithAttributes(attribute.String(attrCache, "blob"))
-	am.cacheHits.Add(ctx, stats.BlobCacheHits, blobAttrs)
-	am.cacheMisses.Add(ctx, stats.BlobCacheMisses, blobAttrs)
+	am.cacheHits.Add(ctx, stats.BlobHits, blobAttrs)
+	am.cacheMisses.Add(ctx, stats.BlobMisses, blobAttrs)
 
 	diffAttrs := metric.WithAttributes(attribute.String(attrCache, "diff"))
-	am.cacheHits.Add(ctx, stats.DiffCacheHits, diffAttrs)
-	am.cacheMisses.Add(ctx, stats.DiffCacheMisses, diffAttrs)
+	am.cacheHits.Add(ctx, stats.DiffHits, diffAttrs)
+	am.cacheMisses.Add(ctx, stats.DiffMisses, diffAttrs)
+}
+
+// RecordSink records the record outcomes of a streaming output sink.
+// Safe to call on a nil receiver (no-op).
+func (am *AnalysisMetrics) RecordSink(ctx context.Context, stats SinkStats) {
+	if am == nil {
+		return
+	}
+
+	outcomes := []struct {
+		name  string
+		count int64
+	}{
+		{"written", stats.Written},
+		{"dropped", stats.Dropped},
+		{"sampled", stats.Sampled},
+		{"failed", stats.Failed},
+	}
+
+	for _, o := range outcomes {
+		am.sinkRecords.Add(ctx, o.count, metric.WithAttributes(attribute.String(attrOutcome, o.name)))
+	}
+}
+
+// RecordPromisorFetches records the lazy fetches of a partial clone.
+// Safe to call on a nil receiver (no-op).
+func (am *AnalysisMetrics) RecordPromisorFetches(ctx context.Context, stats PromisorFetchStats) {
+	if am == nil {
+		return
+	}
+
+	am.promFetches.Add(ctx, stats.Fetches-stats.Failures, metric.WithAttributes(attribute.String(attrOutcome, "ok")))
+	am.promFetches.Add(ctx, stats.Failures, metric.WithAttributes(attribute.String(attrOutcome, "failed")))
+	am.promObjects.Add(ctx, stats.Objects)
 }
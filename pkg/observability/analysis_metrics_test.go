@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	"github.com/Sumatoshi-tech/codefang/pkg/observability"
 )
@@ -40,13 +41,9 @@ func TestAnalysisMetrics_RecordRun(t *testing.T) {
 	ctx := context.Background()
 
 	am.RecordRun(ctx, observability.AnalysisStats{
-		Commits:         100,
-		Chunks:          5,
-		ChunkDurations:  []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
-		BlobCacheHits:   50,
-		BlobCacheMisses: 10,
-		DiffCacheHits:   30,
-		DiffCacheMisses: 5,
+		Commits:        100,
+		Chunks:         5,
+		ChunkDurations: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
 	})
 
 	rm := collectMetrics(t, reader)
@@ -65,12 +62,46 @@ func TestAnalysisMetrics_RecordRun(t *testing.T) {
 	require.True(t, ok, "expected Histogram data type")
 	require.NotEmpty(t, hist.DataPoints)
 	assert.Equal(t, uint64(3), hist.DataPoints[0].Count, "should have 3 duration recordings")
+}
+
+func TestAnalysisMetrics_RecordChunkCache_LinksExemplars(t *testing.T) {
+	t.Parallel()
+
+	am, reader := setupAnalysisMeter(t)
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	ctx, chunkSpan := tp.Tracer("test").Start(context.Background(), "codefang.chunk")
+
+	am.RecordChunkCache(ctx, observability.ChunkCacheStats{BlobHits: 50, BlobMisses: 10, DiffHits: 30, DiffMisses: 5})
+	chunkSpan.End()
+
+	rm := collectMetrics(t, reader)
 
 	cacheHits := findMetric(rm, "codefang.analysis.cache.hits.total")
 	require.NotNil(t, cacheHits, "cache hits counter should exist")
 
 	cacheMisses := findMetric(rm, "codefang.analysis.cache.misses.total")
 	require.NotNil(t, cacheMisses, "cache misses counter should exist")
+
+	sum, ok := cacheMisses.Data.(metricdata.Sum[int64])
+	require.True(t, ok, "expected Sum data type")
+	require.Len(t, sum.DataPoints, 2)
+
+	traceID := chunkSpan.SpanContext().TraceID()
+
+	for _, dp := range sum.DataPoints {
+		require.NotEmpty(t, dp.Exemplars, "cache misses should carry an exemplar")
+		assert.Equal(t, traceID[:], dp.Exemplars[0].TraceID, "exemplar should link to the chunk trace")
+	}
+}
+
+func TestAnalysisMetrics_RecordChunkCache_NilReceiver(t *testing.T) {
+	t.Parallel()
+
+	var am *observability.AnalysisMetrics
+
+	// Should not panic.
+	am.RecordChunkCache(context.Background(), observability.ChunkCacheStats{BlobMisses: 1})
 }
 
 func TestAnalysisMetrics_RecordRun_NilReceiver(t *testing.T) {
@@ -84,3 +115,64 @@ func TestAnalysisMetrics_RecordRun_NilReceiver(t *testing.T) {
 		Chunks:  1,
 	})
 }
+
+func TestAnalysisMetrics_RecordSink(t *testing.T) {
+	t.Parallel()
+
+	am, reader := setupAnalysisMeter(t)
+
+	am.RecordSink(context.Background(), observability.SinkStats{Written: 7, Dropped: 3})
+
+	rm := collectMetrics(t, reader)
+
+	records := findMetric(rm, "codefang.analysis.sink.records.total")
+	require.NotNil(t, records, "sink records counter should exist")
+
+	sum, ok := records.Data.(metricdata.Sum[int64])
+	require.True(t, ok, "expected Sum data type")
+
+	byOutcome := make(map[string]int64, len(sum.DataPoints))
+
+	for _, dp := range sum.DataPoints {
+		outcome, _ := dp.Attributes.Value("outcome")
+		byOutcome[outcome.AsString()] = dp.Value
+	}
+
+	assert.Equal(t, int64(7), byOutcome["written"])
+	assert.Equal(t, int64(3), byOutcome["dropped"])
+	assert.Zero(t, byOutcome["failed"])
+}
+
+func TestAnalysisMetrics_RecordPromisorFetches(t *testing.T) {
+	t.Parallel()
+
+	am, reader := setupAnalysisMeter(t)
+
+	am.RecordPromisorFetches(context.Background(), observability.PromisorFetchStats{Fetches: 3, Objects: 120, Failures: 1})
+
+	rm := collectMetrics(t, reader)
+
+	fetches := findMetric(rm, "codefang.analysis.promisor.fetches.total")
+	require.NotNil(t, fetches, "promisor fetches counter should exist")
+
+	sum, ok := fetches.Data.(metricdata.Sum[int64])
+	require.True(t, ok, "expected Sum data type")
+
+	byOutcome := make(map[string]int64, len(sum.DataPoints))
+
+	for _, dp := range sum.DataPoints {
+		outcome, _ := dp.Attributes.Value("outcome")
+		byOutcome[outcome.AsString()] = dp.Value
+	}
+
+	assert.Equal(t, int64(2), byOutcome["ok"])
+	assert.Equal(t, int64(1), byOutcome["failed"])
+
+	objects := findMetric(rm, "codefang.analysis.promisor.objects.total")
+	require.NotNil(t, objects, "promisor objects counter should exist")
+
+	objSum, ok := objects.Data.(metricdata.Sum[int64])
+	require.True(t, ok, "expected Sum data type")
+	require.Len(t, objSum.DataPoints, 1)
+	assert.Equal(t, int64(120), objSum.DataPoints[0].Value)
+}
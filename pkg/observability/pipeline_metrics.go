@@ -0,0 +1,88 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	metricQueueDepth    = "codefang.pipeline.queue.depth"
+	metricQueueCapacity = "codefang.pipeline.queue.capacity"
+	metricQueueWait     = "codefang.pipeline.queue.wait.seconds"
+
+	attrQueue = "queue"
+	attrSide  = "side"
+)
+
+// PipelineQueueStats holds the state of the queue between two coordinator
+// stages, decoupled from framework types.
+type PipelineQueueStats struct {
+	// Queue names the stages on either side, such as "diff->uast".
+	Queue    string
+	Depth    int64
+	Capacity int64
+	// Starved is the time the downstream stage waited for the upstream one.
+	Starved time.Duration
+	// Blocked is the time the upstream stage waited for the downstream one.
+	Blocked time.Duration
+}
+
+// PipelineQueueProvider exposes the coordinator stage queues for OTel export.
+type PipelineQueueProvider interface {
+	PipelineQueues() []PipelineQueueStats
+}
+
+// RegisterPipelineMetrics registers observable gauges that report the depth,
+// capacity and cumulative wait times of the coordinator stage queues. A
+// downstream stage starved for commits points at a slow upstream stage; an
+// upstream stage blocked on its queue points at a slow downstream stage.
+func RegisterPipelineMetrics(mt metric.Meter, provider PipelineQueueProvider) error {
+	depth, err := mt.Int64ObservableGauge(metricQueueDepth,
+		metric.WithDescription("Commits waiting in the queue between two pipeline stages"),
+		metric.WithUnit("{commit}"),
+	)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", metricQueueDepth, err)
+	}
+
+	capacity, err := mt.Int64ObservableGauge(metricQueueCapacity,
+		metric.WithDescription("Commits the queue between two pipeline stages holds"),
+		metric.WithUnit("{commit}"),
+	)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", metricQueueCapacity, err)
+	}
+
+	wait, err := mt.Float64ObservableGauge(metricQueueWait,
+		metric.WithDescription("Time a pipeline stage waited on its queue, by side: "+
+			"starved for the upstream stage or blocked by the downstream stage"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", metricQueueWait, err)
+	}
+
+	_, err = mt.RegisterCallback(func(_ context.Context, obs metric.Observer) error {
+		for _, q := range provider.PipelineQueues() {
+			queueAttr := attribute.String(attrQueue, q.Queue)
+
+			obs.ObserveInt64(depth, q.Depth, metric.WithAttributes(queueAttr))
+			obs.ObserveInt64(capacity, q.Capacity, metric.WithAttributes(queueAttr))
+			obs.ObserveFloat64(wait, q.Starved.Seconds(),
+				metric.WithAttributes(queueAttr, attribute.String(attrSide, "starved")))
+			obs.ObserveFloat64(wait, q.Blocked.Seconds(),
+				metric.WithAttributes(queueAttr, attribute.String(attrSide, "blocked")))
+		}
+
+		return nil
+	}, depth, capacity, wait)
+	if err != nil {
+		return fmt.Errorf("register pipeline metrics callback: %w", err)
+	}
+
+	return nil
+}
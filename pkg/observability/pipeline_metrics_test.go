@@ -0,0 +1,71 @@
+package observability_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/observability"
+)
+
+// stubPipelineQueues implements observability.PipelineQueueProvider for testing.
+type stubPipelineQueues []observability.PipelineQueueStats
+
+func (s stubPipelineQueues) PipelineQueues() []observability.PipelineQueueStats { return s }
+
+func TestPipelineMetrics_Exported(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	err := observability.RegisterPipelineMetrics(mp.Meter("test"), stubPipelineQueues{
+		{Queue: "diff->uast", Depth: 3, Capacity: 8, Starved: time.Second, Blocked: 4 * time.Second},
+		{Queue: "uast->leaf", Depth: 8, Capacity: 8, Blocked: 2 * time.Second},
+	})
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	depth := findMetric(rm, "codefang.pipeline.queue.depth")
+	require.NotNil(t, depth)
+
+	depthGauge, ok := depth.Data.(metricdata.Gauge[int64])
+	require.True(t, ok)
+
+	depths := make(map[string]int64)
+
+	for _, dp := range depthGauge.DataPoints {
+		queue, _ := dp.Attributes.Value("queue")
+		depths[queue.AsString()] = dp.Value
+	}
+
+	assert.Equal(t, map[string]int64{"diff->uast": 3, "uast->leaf": 8}, depths)
+
+	wait := findMetric(rm, "codefang.pipeline.queue.wait.seconds")
+	require.NotNil(t, wait)
+
+	waitGauge, ok := wait.Data.(metricdata.Gauge[float64])
+	require.True(t, ok)
+
+	waits := make(map[string]float64)
+
+	for _, dp := range waitGauge.DataPoints {
+		queue, _ := dp.Attributes.Value("queue")
+		side, _ := dp.Attributes.Value("side")
+		waits[queue.AsString()+"/"+side.AsString()] = dp.Value
+	}
+
+	assert.InDelta(t, 1.0, waits["diff->uast/starved"], 1e-9)
+	assert.InDelta(t, 4.0, waits["diff->uast/blocked"], 1e-9)
+	assert.InDelta(t, 2.0, waits["uast->leaf/blocked"], 1e-9)
+
+	require.NotNil(t, findMetric(rm, "codefang.pipeline.queue.capacity"))
+}
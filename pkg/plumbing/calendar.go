@@ -0,0 +1,149 @@
+package plumbing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TickGranularity selects how commit timestamps are bucketed into ticks.
+type TickGranularity string
+
+const (
+	// TickFixed buckets commits into fixed-size ticks counted from the first commit.
+	TickFixed TickGranularity = "fixed"
+	// TickWeek buckets commits into ISO weeks (Monday 00:00 UTC).
+	TickWeek TickGranularity = "week"
+	// TickMonth buckets commits into calendar months (UTC).
+	TickMonth TickGranularity = "month"
+	// TickQuarter buckets commits into calendar quarters (UTC).
+	TickQuarter TickGranularity = "quarter"
+)
+
+const (
+	daysPerWeek      = 7
+	monthsPerQuarter = 3
+	monthsPerYear    = 12
+	hoursPerDay      = 24
+
+	// hoursPerMonth is the mean Gregorian month length (365.2425 days / 12), rounded.
+	hoursPerMonth = 730
+)
+
+// ErrUnknownTickGranularity is returned for unrecognized tick granularities.
+var ErrUnknownTickGranularity = errors.New("unknown tick granularity")
+
+// ParseTickGranularity parses a granularity name. Empty input maps to [TickFixed].
+func ParseTickGranularity(s string) (TickGranularity, error) {
+	switch g := TickGranularity(s); g {
+	case "":
+		return TickFixed, nil
+	case TickFixed, TickWeek, TickMonth, TickQuarter:
+		return g, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want fixed, week, month or quarter)", ErrUnknownTickGranularity, s)
+	}
+}
+
+// Calendar reports whether the granularity is aligned to calendar periods.
+func (g TickGranularity) Calendar() bool {
+	return g == TickWeek || g == TickMonth || g == TickQuarter
+}
+
+// NominalTickSize returns the average duration of one calendar tick.
+// Duration-based consumers (e.g. "active in the last 90 days") use it as TickSize.
+// Returns 0 for [TickFixed], whose size is configured separately.
+func (g TickGranularity) NominalTickSize() time.Duration {
+	switch g {
+	case TickWeek:
+		return daysPerWeek * hoursPerDay * time.Hour
+	case TickMonth:
+		return hoursPerMonth * time.Hour
+	case TickQuarter:
+		return monthsPerQuarter * hoursPerMonth * time.Hour
+	default:
+		return 0
+	}
+}
+
+// TickCalendar maps commit times to calendar-aligned ticks and back.
+// Tick 0 is the period containing Start. All computations use UTC.
+type TickCalendar struct {
+	Granularity TickGranularity
+	Start       time.Time
+}
+
+// Floor returns the start of the calendar period containing t.
+func (c *TickCalendar) Floor(t time.Time) time.Time {
+	t = t.UTC()
+
+	switch c.Granularity {
+	case TickWeek:
+		daysSinceMonday := (int(t.Weekday()) + daysPerWeek - 1) % daysPerWeek
+
+		return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+	case TickMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case TickQuarter:
+		firstMonth := time.Month((int(t.Month())-1)/monthsPerQuarter*monthsPerQuarter + 1)
+
+		return time.Date(t.Year(), firstMonth, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return t
+	}
+}
+
+// Tick returns the number of calendar periods between Start and t.
+func (c *TickCalendar) Tick(t time.Time) int {
+	start := c.Floor(c.Start)
+	period := c.Floor(t)
+
+	switch c.Granularity {
+	case TickWeek:
+		return int(period.Sub(start).Hours()) / (daysPerWeek * hoursPerDay)
+	case TickMonth:
+		return monthIndex(period) - monthIndex(start)
+	case TickQuarter:
+		return (monthIndex(period) - monthIndex(start)) / monthsPerQuarter
+	default:
+		return 0
+	}
+}
+
+// TickStart returns the start time of the given tick's period.
+func (c *TickCalendar) TickStart(tick int) time.Time {
+	start := c.Floor(c.Start)
+
+	switch c.Granularity {
+	case TickWeek:
+		return start.AddDate(0, 0, tick*daysPerWeek)
+	case TickMonth:
+		return start.AddDate(0, tick, 0)
+	case TickQuarter:
+		return start.AddDate(0, tick*monthsPerQuarter, 0)
+	default:
+		return start
+	}
+}
+
+// Label returns the business name of the tick's period: "2024-W05", "2024-03" or "2024-Q1".
+func (c *TickCalendar) Label(tick int) string {
+	start := c.TickStart(tick)
+
+	switch c.Granularity {
+	case TickWeek:
+		year, week := start.ISOWeek()
+
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case TickMonth:
+		return start.Format("2006-01")
+	case TickQuarter:
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/monthsPerQuarter+1)
+	default:
+		return start.Format(time.DateOnly)
+	}
+}
+
+func monthIndex(t time.Time) int {
+	return t.Year()*monthsPerYear + int(t.Month()) - 1
+}
@@ -0,0 +1,72 @@
+package plumbing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestParseTickGranularity(t *testing.T) {
+	t.Parallel()
+
+	got, err := plumbing.ParseTickGranularity("")
+	require.NoError(t, err)
+	assert.Equal(t, plumbing.TickFixed, got)
+
+	got, err = plumbing.ParseTickGranularity("quarter")
+	require.NoError(t, err)
+	assert.Equal(t, plumbing.TickQuarter, got)
+	assert.True(t, got.Calendar())
+
+	_, err = plumbing.ParseTickGranularity("fortnight")
+	require.ErrorIs(t, err, plumbing.ErrUnknownTickGranularity)
+}
+
+func TestTickCalendar_Week(t *testing.T) {
+	t.Parallel()
+
+	// 2024-01-03 is a Wednesday in ISO week 2024-W01.
+	cal := &plumbing.TickCalendar{
+		Granularity: plumbing.TickWeek,
+		Start:       time.Date(2024, 1, 3, 15, 0, 0, 0, time.UTC),
+	}
+
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), cal.TickStart(0))
+	assert.Equal(t, 0, cal.Tick(time.Date(2024, 1, 7, 23, 59, 0, 0, time.UTC)))
+	assert.Equal(t, 1, cal.Tick(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2024-W01", cal.Label(0))
+	assert.Equal(t, "2024-W05", cal.Label(4))
+}
+
+func TestTickCalendar_MonthAndQuarter(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, 11, 20, 0, 0, 0, 0, time.UTC)
+	when := time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)
+
+	month := &plumbing.TickCalendar{Granularity: plumbing.TickMonth, Start: start}
+	assert.Equal(t, 3, month.Tick(when))
+	assert.Equal(t, "2024-02", month.Label(3))
+
+	quarter := &plumbing.TickCalendar{Granularity: plumbing.TickQuarter, Start: start}
+	assert.Equal(t, 1, quarter.Tick(when))
+	assert.Equal(t, "2023-Q4", quarter.Label(0))
+	assert.Equal(t, "2024-Q1", quarter.Label(1))
+}
+
+func TestTickCalendar_UsesUTC(t *testing.T) {
+	t.Parallel()
+
+	// Monday 01:00 in UTC+3 is still Sunday in UTC.
+	tz := time.FixedZone("UTC+3", 3*60*60)
+	cal := &plumbing.TickCalendar{
+		Granularity: plumbing.TickWeek,
+		Start:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	assert.Equal(t, 0, cal.Tick(time.Date(2024, 1, 8, 1, 0, 0, 0, tz)))
+}
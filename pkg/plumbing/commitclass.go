@@ -0,0 +1,64 @@
+package plumbing
+
+import (
+	"regexp"
+	"strings"
+)
+
+// conventionalSubject matches a Conventional Commits subject:
+// "type(scope)!: description", with optional scope and "!".
+var conventionalSubject = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()\r\n]*)\))?(!)?: +(\S.*)$`)
+
+// FixPattern is the default pattern of the subjects of commits that fix
+// defects, shared by the analyzers that tell fixes from other commits.
+const FixPattern = `(?i)\b(fix(e[sd])?|bug(fix)?|hotfix|defect|regression)\b`
+
+// breakingFooters are the footer tokens that mark a breaking change.
+var breakingFooters = []string{"BREAKING CHANGE:", "BREAKING-CHANGE:"}
+
+// CommitClass is the Conventional Commits classification of a commit
+// message. Messages that do not follow the convention have an empty Type and
+// their subject as Description.
+type CommitClass struct {
+	// Type is the lower-cased commit type, e.g. "feat" or "fix".
+	Type  string
+	Scope string
+	// Breaking is set by a "!" before the colon or a BREAKING CHANGE footer.
+	Breaking    bool
+	Description string
+}
+
+// Conventional reports whether the message followed the Conventional
+// Commits format.
+func (c CommitClass) Conventional() bool {
+	return c.Type != ""
+}
+
+// ClassifyCommit parses the subject and footers of a commit message
+// following the Conventional Commits specification.
+func ClassifyCommit(message string) CommitClass {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	subject = strings.TrimSpace(subject)
+
+	match := conventionalSubject.FindStringSubmatch(subject)
+	if match == nil {
+		return CommitClass{Description: subject}
+	}
+
+	class := CommitClass{
+		Type:        strings.ToLower(match[1]),
+		Scope:       strings.TrimSpace(match[2]),
+		Breaking:    match[3] != "",
+		Description: strings.TrimSpace(match[4]),
+	}
+
+	for line := range strings.SplitSeq(body, "\n") {
+		for _, footer := range breakingFooters {
+			if strings.HasPrefix(line, footer) {
+				class.Breaking = true
+			}
+		}
+	}
+
+	return class
+}
@@ -0,0 +1,55 @@
+package plumbing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestClassifyCommit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message string
+		want    plumbing.CommitClass
+	}{
+		{
+			name:    "type only",
+			message: "feat: add pagination\n\nLong description.",
+			want:    plumbing.CommitClass{Type: "feat", Description: "add pagination"},
+		},
+		{
+			name:    "scope and bang",
+			message: "Fix(api)!: drop v1 endpoints",
+			want:    plumbing.CommitClass{Type: "fix", Scope: "api", Breaking: true, Description: "drop v1 endpoints"},
+		},
+		{
+			name:    "breaking footer",
+			message: "refactor(store): rename options\n\nBREAKING CHANGE: Options.Dir is now Options.Path",
+			want:    plumbing.CommitClass{Type: "refactor", Scope: "store", Breaking: true, Description: "rename options"},
+		},
+		{
+			name:    "not conventional",
+			message: "Handle empty names\n\nfeat: not a subject",
+			want:    plumbing.CommitClass{Description: "Handle empty names"},
+		},
+		{
+			name:    "missing space",
+			message: "feat:add pagination",
+			want:    plumbing.CommitClass{Description: "feat:add pagination"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := plumbing.ClassifyCommit(tt.message)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want.Type != "", got.Conventional())
+		})
+	}
+}
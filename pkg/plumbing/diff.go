@@ -0,0 +1,150 @@
+package plumbing
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// NormalizeEOL returns data with its CRLF line endings turned into LF, so a
+// line diff does not see a change of line endings as a rewrite of every line.
+// Data without CR bytes is returned as is.
+func NormalizeEOL(data []byte) []byte {
+	if bytes.IndexByte(data, '\r') < 0 {
+		return data
+	}
+
+	return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+}
+
+// DiffLineRunes diffs two line-encoded rune sequences produced by
+// DiffLinesToRunes with the given algorithm. Patience splits the input at
+// lines that occur exactly once on both sides and diffs the gaps with
+// dmp, which is Myers diff.
+func DiffLineRunes(
+	dmp *diffmatchpatch.DiffMatchPatch, src, dst []rune, algorithm gitlib.DiffAlgorithm,
+) []diffmatchpatch.Diff {
+	if !algorithm.AnchorsUniqueLines() {
+		return dmp.DiffMainRunes(src, dst, false)
+	}
+
+	return dmp.DiffCleanupMerge(patienceDiff(dmp, src, dst))
+}
+
+func patienceDiff(dmp *diffmatchpatch.DiffMatchPatch, src, dst []rune) []diffmatchpatch.Diff {
+	var diffs []diffmatchpatch.Diff
+
+	prefix := 0
+	for prefix < len(src) && prefix < len(dst) && src[prefix] == dst[prefix] {
+		prefix++
+	}
+
+	if prefix > 0 {
+		diffs = append(diffs, diffmatchpatch.Diff{Type: diffmatchpatch.DiffEqual, Text: string(src[:prefix])})
+	}
+
+	src, dst = src[prefix:], dst[prefix:]
+
+	suffix := 0
+	for suffix < len(src) && suffix < len(dst) && src[len(src)-1-suffix] == dst[len(dst)-1-suffix] {
+		suffix++
+	}
+
+	tail := string(src[len(src)-suffix:])
+	src, dst = src[:len(src)-suffix], dst[:len(dst)-suffix]
+
+	anchors := uniqueLineAnchors(src, dst)
+	if len(anchors) == 0 {
+		if len(src) > 0 || len(dst) > 0 {
+			diffs = append(diffs, dmp.DiffMainRunes(src, dst, false)...)
+		}
+	} else {
+		srcPos, dstPos := 0, 0
+
+		for _, a := range anchors {
+			diffs = append(diffs, patienceDiff(dmp, src[srcPos:a.src], dst[dstPos:a.dst])...)
+			diffs = append(diffs, diffmatchpatch.Diff{Type: diffmatchpatch.DiffEqual, Text: string(src[a.src])})
+			srcPos, dstPos = a.src+1, a.dst+1
+		}
+
+		diffs = append(diffs, patienceDiff(dmp, src[srcPos:], dst[dstPos:])...)
+	}
+
+	if suffix > 0 {
+		diffs = append(diffs, diffmatchpatch.Diff{Type: diffmatchpatch.DiffEqual, Text: tail})
+	}
+
+	return diffs
+}
+
+// lineAnchor is a pair of matching line positions in src and dst.
+type lineAnchor struct {
+	src, dst int
+}
+
+// uniqueLineAnchors returns the longest sequence of lines that occur exactly
+// once in both src and dst and appear in the same order on both sides.
+func uniqueLineAnchors(src, dst []rune) []lineAnchor {
+	srcCount := make(map[rune]int, len(src))
+	for _, r := range src {
+		srcCount[r]++
+	}
+
+	dstPos := make(map[rune]int, len(dst))
+	for i, r := range dst {
+		if srcCount[r] != 1 {
+			continue
+		}
+
+		if _, seen := dstPos[r]; seen {
+			dstPos[r] = -1
+		} else {
+			dstPos[r] = i
+		}
+	}
+
+	var candidates []lineAnchor
+
+	for i, r := range src {
+		if j, ok := dstPos[r]; ok && j >= 0 && srcCount[r] == 1 {
+			candidates = append(candidates, lineAnchor{src: i, dst: j})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Patience sorting: longest increasing subsequence of dst positions.
+	var (
+		tops []int // index into candidates of each pile's top.
+		prev = make([]int, len(candidates))
+	)
+
+	for i, c := range candidates {
+		pile, _ := slices.BinarySearchFunc(tops, c.dst, func(top, dst int) int {
+			return candidates[top].dst - dst
+		})
+
+		prev[i] = -1
+		if pile > 0 {
+			prev[i] = tops[pile-1]
+		}
+
+		if pile == len(tops) {
+			tops = append(tops, i)
+		} else {
+			tops[pile] = i
+		}
+	}
+
+	anchors := make([]lineAnchor, len(tops))
+	for i, k := len(tops)-1, tops[len(tops)-1]; i >= 0; i, k = i-1, prev[k] {
+		anchors[i] = candidates[k]
+	}
+
+	return anchors
+}
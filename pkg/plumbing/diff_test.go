@@ -0,0 +1,72 @@
+package plumbing_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func diffLines(src, dst string, algorithm gitlib.DiffAlgorithm) []diffmatchpatch.Diff {
+	dmp := diffmatchpatch.New()
+	srcRunes, dstRunes, lines := dmp.DiffLinesToRunes(src, dst)
+
+	return dmp.DiffCharsToLines(plumbing.DiffLineRunes(dmp, srcRunes, dstRunes, algorithm), lines)
+}
+
+func TestDiffLineRunes_PatienceKeepsBlocksTogether(t *testing.T) {
+	t.Parallel()
+
+	src := "}\n}\nfunc x() {\n}\n"
+	dst := "func x() {\n}\n}\n}\n"
+
+	// Myers matches the repeated closing braces and splits func x from its body.
+	assert.Equal(t, []diffmatchpatch.Diff{
+		{Type: diffmatchpatch.DiffInsert, Text: "func x() {\n"},
+		{Type: diffmatchpatch.DiffEqual, Text: "}\n}\n"},
+		{Type: diffmatchpatch.DiffDelete, Text: "func x() {\n"},
+		{Type: diffmatchpatch.DiffEqual, Text: "}\n"},
+	}, diffLines(src, dst, gitlib.DiffMyers))
+
+	want := []diffmatchpatch.Diff{
+		{Type: diffmatchpatch.DiffDelete, Text: "}\n}\n"},
+		{Type: diffmatchpatch.DiffEqual, Text: "func x() {\n}\n"},
+		{Type: diffmatchpatch.DiffInsert, Text: "}\n}\n"},
+	}
+	assert.Equal(t, want, diffLines(src, dst, gitlib.DiffPatience))
+}
+
+func TestDiffLineRunes_PatienceReconstructsBothSides(t *testing.T) {
+	t.Parallel()
+
+	src := "a\nb\n}\nc\n}\nd\n\ne\n"
+	dst := "c\n}\nx\na\n}\n\nd\nb\n"
+
+	var gotSrc, gotDst strings.Builder
+
+	for _, d := range diffLines(src, dst, gitlib.DiffPatience) {
+		if d.Type != diffmatchpatch.DiffInsert {
+			gotSrc.WriteString(d.Text)
+		}
+
+		if d.Type != diffmatchpatch.DiffDelete {
+			gotDst.WriteString(d.Text)
+		}
+	}
+
+	assert.Equal(t, src, gotSrc.String())
+	assert.Equal(t, dst, gotDst.String())
+}
+
+func TestNormalizeEOL(t *testing.T) {
+	t.Parallel()
+
+	lf := []byte("a\nb\n")
+	assert.Same(t, &lf[0], &plumbing.NormalizeEOL(lf)[0])
+	assert.Equal(t, "a\nb\nc", string(plumbing.NormalizeEOL([]byte("a\r\nb\r\nc"))))
+	assert.Equal(t, "a\rb\n", string(plumbing.NormalizeEOL([]byte("a\rb\r\n"))))
+}
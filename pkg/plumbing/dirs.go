@@ -0,0 +1,30 @@
+package plumbing
+
+import "strings"
+
+// RootDir is the directory of files at the repository root.
+const RootDir = "."
+
+// DirPrefix returns the directory of path truncated to at most depth
+// components. Files at the repository root, and every file when depth is
+// below 1, map to [RootDir].
+func DirPrefix(path string, depth int) string {
+	slash := strings.LastIndexByte(path, '/')
+	if slash < 0 || depth < 1 {
+		return RootDir
+	}
+
+	dir := path[:slash]
+
+	end := 0
+	for range depth {
+		next := strings.IndexByte(dir[end:], '/')
+		if next < 0 {
+			return dir
+		}
+
+		end += next + 1
+	}
+
+	return dir[:end-1]
+}
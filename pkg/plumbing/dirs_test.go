@@ -0,0 +1,21 @@
+package plumbing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestDirPrefix(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, ".", plumbing.DirPrefix("main.go", 2))
+	assert.Equal(t, "pkg", plumbing.DirPrefix("pkg/main.go", 2))
+	assert.Equal(t, "pkg/a", plumbing.DirPrefix("pkg/a/main.go", 2))
+	assert.Equal(t, "pkg/a", plumbing.DirPrefix("pkg/a/b/c/main.go", 2))
+	assert.Equal(t, "pkg", plumbing.DirPrefix("pkg/a/b/c/main.go", 1))
+	assert.Equal(t, ".", plumbing.DirPrefix("pkg/a/main.go", 0))
+	assert.Equal(t, ".", plumbing.DirPrefix("pkg/a/main.go", -1))
+}
@@ -0,0 +1,183 @@
+package plumbing
+
+import (
+	"bytes"
+	"path"
+	"slices"
+	"strings"
+)
+
+// FileRole is what a file is for, as opposed to the language it is in.
+type FileRole string
+
+// File roles, in the order ClassifyFileRole checks them.
+const (
+	FileRoleGenerated FileRole = "generated"
+	FileRoleTest      FileRole = "test"
+	FileRoleDocs      FileRole = "docs"
+	FileRoleConfig    FileRole = "config"
+	FileRoleSource    FileRole = "source"
+)
+
+// FileRoleFunc classifies a file from its path and contents.
+type FileRoleFunc func(name string, data []byte) FileRole
+
+// generatedHeaderLen is how much of a file is searched for generated-code markers.
+const generatedHeaderLen = 1024
+
+// generatedMarkers are the header comments code generators leave, such as
+// Go's "Code generated ... DO NOT EDIT." and the "@generated" of Facebook tools.
+var generatedMarkers = [][]byte{
+	[]byte("Code generated"),
+	[]byte("DO NOT EDIT"),
+	[]byte("@generated"),
+	[]byte("<auto-generated"),
+	[]byte("autogenerated by"),
+	[]byte("Autogenerated by"),
+}
+
+// generatedSuffixes are file name endings of generated files and lock files.
+var generatedSuffixes = []string{
+	".pb.go", ".pb.gw.go", "_gen.go", ".gen.go", "_generated.go",
+	"_pb2.py", "_pb2_grpc.py",
+	".min.js", ".min.css", ".bundle.js", ".js.map", ".css.map",
+	".designer.cs", ".g.cs", ".g.dart", ".freezed.dart",
+	"package-lock.json", "yarn.lock", "pnpm-lock.yaml", "go.sum", "cargo.lock",
+	"poetry.lock", "gemfile.lock", "composer.lock", "pipfile.lock",
+}
+
+// testDirs are directories whose files are tests in any language.
+var testDirs = []string{"test", "tests", "__tests__", "spec", "specs", "testdata"}
+
+// testNameRules holds the file name conventions for tests per language: a
+// file is a test when its base name has one of the prefixes or suffixes.
+// Matching is case-sensitive, so that FooTest.java is a test and Latest.java
+// is not.
+var testNameRules = map[string]struct{ prefixes, suffixes []string }{
+	"Go":          {suffixes: []string{"_test.go"}},
+	"Python":      {prefixes: []string{"test_"}, suffixes: []string{"_test.py", "_tests.py", "conftest.py"}},
+	"JavaScript":  {suffixes: []string{".test.js", ".spec.js", ".test.jsx", ".spec.jsx", ".test.mjs", ".spec.mjs"}},
+	"TypeScript":  {suffixes: []string{".test.ts", ".spec.ts", ".test.mts", ".spec.mts"}},
+	"TSX":         {suffixes: []string{".test.tsx", ".spec.tsx"}},
+	"Java":        {suffixes: []string{"Test.java", "Tests.java", "IT.java", "TestCase.java"}},
+	"Kotlin":      {suffixes: []string{"Test.kt", "Tests.kt", "Spec.kt"}},
+	"Scala":       {suffixes: []string{"Test.scala", "Spec.scala", "Suite.scala"}},
+	"C#":          {suffixes: []string{"Test.cs", "Tests.cs"}},
+	"Ruby":        {suffixes: []string{"_spec.rb", "_test.rb"}},
+	"PHP":         {suffixes: []string{"Test.php"}},
+	"Rust":        {suffixes: []string{"_test.rs", "_tests.rs"}},
+	"C":           {prefixes: []string{"test_"}, suffixes: []string{"_test.c", "_unittest.c"}},
+	"C++":         {prefixes: []string{"test_"}, suffixes: []string{"_test.cc", "_test.cpp", "_unittest.cc", "_unittest.cpp"}},
+	"Swift":       {suffixes: []string{"Tests.swift", "Test.swift"}},
+	"Dart":        {suffixes: []string{"_test.dart"}},
+	"Elixir":      {suffixes: []string{"_test.exs"}},
+	"Shell":       {suffixes: []string{".bats"}},
+	"Objective-C": {suffixes: []string{"Tests.m", "Test.m"}},
+}
+
+// docsLanguages are the languages of prose.
+var docsLanguages = map[string]bool{
+	"Markdown": true, "reStructuredText": true, "AsciiDoc": true, "TeX": true, "RMarkdown": true, "Text": true,
+}
+
+// docsDirs are directories of documentation.
+var docsDirs = []string{"doc", "docs", "documentation"}
+
+// docsNames are documentation files recognized by their base name.
+var docsNames = []string{"readme", "changelog", "changes", "contributing", "license", "copying", "authors", "notice"}
+
+// configLanguages are the languages of configuration and build files.
+var configLanguages = map[string]bool{
+	"JSON": true, "JSON5": true, "YAML": true, "TOML": true, "INI": true, "XML": true, "Dotenv": true,
+	"HCL": true, "Dockerfile": true, "Makefile": true, "CMake": true,
+}
+
+// ClassifyFileRole returns the role of a file from its path, the language
+// detected for it and the start of its contents, which may be nil. Generated
+// files win over tests, tests over documentation and configuration; a file
+// that is none of these is source.
+func ClassifyFileRole(name, language string, data []byte) FileRole {
+	lower := strings.ToLower(name)
+	lowerBase := path.Base(lower)
+
+	switch {
+	case isGeneratedFile(lowerBase, data):
+		return FileRoleGenerated
+	case isTestFile(name, language):
+		return FileRoleTest
+	case docsLanguages[language] ||
+		language == "" && (hasBaseName(lowerBase, docsNames) || hasDir(lower, docsDirs)):
+		return FileRoleDocs
+	case configLanguages[language] || strings.HasPrefix(lowerBase, "."):
+		return FileRoleConfig
+	default:
+		return FileRoleSource
+	}
+}
+
+func isGeneratedFile(base string, data []byte) bool {
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+
+	header := data[:min(len(data), generatedHeaderLen)]
+
+	for _, marker := range generatedMarkers {
+		if bytes.Contains(header, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTestFile(name, language string) bool {
+	base := path.Base(name)
+
+	if rules, found := testNameRules[language]; found {
+		for _, prefix := range rules.prefixes {
+			if strings.HasPrefix(base, prefix) {
+				return true
+			}
+		}
+
+		for _, suffix := range rules.suffixes {
+			if strings.HasSuffix(base, suffix) {
+				return true
+			}
+		}
+	}
+
+	lower := strings.ToLower(name)
+
+	// Maven and Gradle keep tests under src/test, whatever their name.
+	if strings.Contains("/"+lower, "/src/test/") {
+		return true
+	}
+
+	return language != "" && !docsLanguages[language] && hasDir(lower, testDirs)
+}
+
+// hasDir reports whether one of the directories of a slash-separated path
+// is in dirs.
+func hasDir(name string, dirs []string) bool {
+	dir := path.Dir(name)
+	if dir == "." {
+		return false
+	}
+
+	for part := range strings.SplitSeq(dir, "/") {
+		if slices.Contains(dirs, part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasBaseName reports whether a file name without its extension is in names.
+func hasBaseName(base string, names []string) bool {
+	return slices.Contains(names, strings.TrimSuffix(base, path.Ext(base)))
+}
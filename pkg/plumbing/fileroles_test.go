@@ -0,0 +1,58 @@
+package plumbing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestClassifyFileRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		language string
+		data     string
+		want     plumbing.FileRole
+	}{
+		{"pkg/server/server.go", "Go", "package server", plumbing.FileRoleSource},
+		{"pkg/server/server_test.go", "Go", "package server", plumbing.FileRoleTest},
+		{"api/v1/api.pb.go", "Go", "package v1", plumbing.FileRoleGenerated},
+		{"pkg/enum.go", "Go", "// Code generated by stringer. DO NOT EDIT.\n\npackage pkg", plumbing.FileRoleGenerated},
+		{"app/test_models.py", "Python", "", plumbing.FileRoleTest},
+		{"app/conftest.py", "Python", "", plumbing.FileRoleTest},
+		{"app/testing.py", "Python", "", plumbing.FileRoleSource},
+		{"src/button.spec.tsx", "TSX", "", plumbing.FileRoleTest},
+		{"src/__tests__/button.tsx", "TSX", "", plumbing.FileRoleTest},
+		{"src/main/java/OrderTest.java", "Java", "", plumbing.FileRoleTest},
+		{"src/main/java/Latest.java", "Java", "", plumbing.FileRoleSource},
+		{"src/test/java/Fixtures.java", "Java", "", plumbing.FileRoleTest},
+		{"spec/models/user_spec.rb", "Ruby", "", plumbing.FileRoleTest},
+		{"tests/integration.rs", "Rust", "", plumbing.FileRoleTest},
+		{"tests/README.md", "Markdown", "", plumbing.FileRoleDocs},
+		{"docs/guide.md", "Markdown", "", plumbing.FileRoleDocs},
+		{"LICENSE", "", "", plumbing.FileRoleDocs},
+		{"docs/diagram.svg", "", "", plumbing.FileRoleDocs},
+		{"pkg/changes.go", "Go", "", plumbing.FileRoleSource},
+		{"config/app.yaml", "YAML", "", plumbing.FileRoleConfig},
+		{".gitignore", "", "", plumbing.FileRoleConfig},
+		{"web/package-lock.json", "JSON", "", plumbing.FileRoleGenerated},
+		{"go.sum", "", "", plumbing.FileRoleGenerated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, plumbing.ClassifyFileRole(tt.name, tt.language, []byte(tt.data)))
+		})
+	}
+}
+
+func TestClassifyFileRole_NilData(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, plumbing.FileRoleTest, plumbing.ClassifyFileRole("main_test.go", "Go", nil))
+}
@@ -18,6 +18,36 @@ const (
 	// FactTickSize contains the [time.Duration] of each tick.
 	FactTickSize = "TicksSinceStart.TickSize"
 
+	// FactTickGranularity contains the [TickGranularity] used to bucket commits into ticks.
+	FactTickGranularity = "TicksSinceStart.Granularity"
+
+	// FactTickCalendar contains the *[TickCalendar] mapping ticks to calendar periods.
+	// Only published for calendar granularities; Start is filled once the first commit is seen.
+	FactTickCalendar = "TicksSinceStart.Calendar"
+
+	// FactSeed contains the uint64 seed of the run. Analyzers using randomness
+	// seed their generators with it, so that runs with the same seed give the
+	// same results.
+	FactSeed = "Run.Seed"
+
+	// FactStoreDir contains the parent directory for analyzer working-state
+	// stores that live on disk, such as hibernated burndown files. Analyzer
+	// options naming a directory of their own take precedence.
+	FactStoreDir = "Run.StoreDir"
+
+	// FactFileRoles contains the [FileRoleFunc] of the language detector, so
+	// that analyzers classify test, generated, docs and config files the same way.
+	FactFileRoles = "LanguagesDetection.FileRoles"
+
+	// FactProjects contains the [Projects] discovered in the HEAD tree, so
+	// that analyzers group the files of a monorepo by project.
+	FactProjects = "Run.Projects"
+
+	// FactCodeOwners contains the *codeowners.Ruleset parsed from the
+	// CODEOWNERS file of the HEAD tree, so that analyzers attribute files to
+	// their owning teams.
+	FactCodeOwners = "Run.CodeOwners"
+
 	// DependencyBlobCache identifies the dependency provided by BlobCache.
 	DependencyBlobCache = "blob_cache"
 
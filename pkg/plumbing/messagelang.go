@@ -0,0 +1,277 @@
+package plumbing
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MessageLanguageUnknown is the BCP 47 "undetermined" code returned when a
+// commit message carries too little text to tell its language.
+const MessageLanguageUnknown = "und"
+
+// trailerLine matches git trailers such as "Signed-off-by: ..." whose
+// fixed English keys say nothing about the language of the message.
+var trailerLine = regexp.MustCompile(`^[A-Za-z][A-Za-z-]*-[Bb]y: `)
+
+// wordRE splits a message into letter runs.
+var wordRE = regexp.MustCompile(`\p{L}+`)
+
+// scriptLanguages maps writing systems used by a single language to its
+// ISO 639-1 code. Han is resolved separately because Japanese mixes it with
+// kana.
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// stopwords are frequent function words and commit verbs of the Latin-script
+// languages. Words shared by several languages simply score for each;
+// single letters are left out because they are mostly version and list
+// markers in commit messages.
+var stopwords = map[string][]string{
+	"en": {
+		"the", "and", "to", "of", "for", "in", "with", "is", "on", "from", "when", "not",
+		"fix", "add", "update", "remove", "use", "this", "that", "it",
+	},
+	"de": {
+		"der", "die", "das", "und", "nicht", "mit", "für", "ist", "ein", "eine", "den", "von",
+		"zu", "auf", "beim", "auch", "hinzugefügt", "entfernt", "behoben",
+	},
+	"fr": {
+		"le", "la", "les", "des", "du", "et", "pour", "dans", "une", "un", "est", "pas",
+		"sur", "avec", "lors", "ajout", "correction",
+	},
+	"es": {
+		"el", "los", "las", "del", "para", "en", "una", "con", "por", "que", "se",
+		"al", "añadir", "corregir", "arreglar",
+	},
+	"pt": {
+		"os", "da", "do", "das", "dos", "para", "em", "uma", "com", "não",
+		"que", "ao", "adiciona", "correção", "corrige",
+	},
+	"it": {
+		"il", "lo", "gli", "della", "del", "per", "nel", "una", "con", "che", "non",
+		"è", "aggiunto", "aggiunta", "correzione",
+	},
+	"nl": {
+		"het", "een", "en", "van", "voor", "met", "niet", "is", "op", "bij", "naar",
+		"toegevoegd", "verwijderd", "opgelost",
+	},
+	"pl": {
+		"na", "do", "nie", "się", "dla", "jest", "oraz", "przy",
+		"poprawka", "dodano", "usunięto",
+	},
+	"sv": {
+		"och", "att", "för", "med", "inte", "är", "en", "på", "av", "till", "när",
+		"lägg", "fixa", "lade",
+	},
+	"tr": {
+		"ve", "bir", "için", "ile", "bu", "değil", "olarak", "eklendi", "düzeltildi", "kaldırıldı",
+	},
+	"id": {
+		"dan", "yang", "untuk", "dengan", "di", "tidak", "ini", "ke", "dari",
+		"menambahkan", "perbaikan", "memperbaiki",
+	},
+	"cs": {
+		"se", "na", "pro", "je", "ne", "při", "oprava", "přidán", "přidána",
+	},
+	"vi": {
+		"và", "của", "cho", "không", "thêm", "sửa", "trong", "là", "được", "lỗi",
+	},
+}
+
+// letterMarkers are letters that occur in only one of the Latin-script
+// languages above; every word containing one scores for that language.
+var letterMarkers = map[rune]string{
+	'ß': "de",
+	'ñ': "es",
+	'ã': "pt", 'õ': "pt",
+	'ł': "pl", 'ą': "pl", 'ę': "pl", 'ś': "pl", 'ź': "pl", 'ż': "pl", 'ń': "pl",
+	'ğ': "tr", 'ş': "tr", 'ı': "tr",
+	'ř': "cs", 'ě': "cs", 'ů': "cs",
+	'ơ': "vi", 'ư': "vi", 'đ': "vi", 'ạ': "vi", 'ả': "vi", 'ầ': "vi", 'ệ': "vi", 'ộ': "vi",
+}
+
+// stopwordLanguages inverts stopwords for lookup by word.
+var stopwordLanguages = func() map[string][]string {
+	byWord := make(map[string][]string)
+
+	for lang, words := range stopwords {
+		for _, word := range words {
+			byWord[word] = append(byWord[word], lang)
+		}
+	}
+
+	return byWord
+}()
+
+// DetectMessageLanguage returns the ISO 639-1 code of the natural language a
+// commit message is written in, or MessageLanguageUnknown when the message
+// has no clear winner. Non-Latin scripts decide the language on their own;
+// Latin-script messages are scored by stopwords and language-specific
+// letters, ignoring git trailers.
+func DetectMessageLanguage(message string) string {
+	return DetectTextLanguage(messageText(message))
+}
+
+// DetectTextLanguage returns the ISO 639-1 code of the natural language of a
+// short free text such as a code comment, or MessageLanguageUnknown when the
+// text has no clear winner. It applies the rules of DetectMessageLanguage
+// without dropping trailers.
+func DetectTextLanguage(text string) string {
+	if lang := scriptLanguage(text); lang != "" {
+		return lang
+	}
+
+	scores := make(map[string]int)
+
+	for _, word := range wordRE.FindAllString(strings.ToLower(text), -1) {
+		for _, lang := range stopwordLanguages[word] {
+			scores[lang]++
+		}
+
+		for lang := range wordMarkers(word) {
+			scores[lang]++
+		}
+	}
+
+	return bestScore(scores)
+}
+
+// messageText drops trailer lines from message.
+func messageText(message string) string {
+	lines := strings.Split(message, "\n")
+	kept := lines[:0]
+
+	for _, line := range lines {
+		if !trailerLine.MatchString(strings.TrimSpace(line)) {
+			kept = append(kept, line)
+		}
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+// scriptLanguage returns the language of the dominant non-Latin script in
+// text, or "" when Latin letters dominate.
+func scriptLanguage(text string) string {
+	counts := make(map[string]int)
+	latin, kana, han := 0, 0, 0
+
+	for _, r := range text {
+		switch {
+		case !unicode.IsLetter(r):
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["cyrillic"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["arabic"]++
+		default:
+			for _, script := range scriptLanguages {
+				if unicode.Is(script.table, r) {
+					counts[script.lang]++
+
+					break
+				}
+			}
+		}
+	}
+
+	switch {
+	case kana > 0 && kana+han > latin:
+		return "ja"
+	case han > latin:
+		return "zh"
+	}
+
+	best, bestCount := "", latin
+
+	for script, count := range counts {
+		if count > bestCount || (count == bestCount && count > 0 && script < best) {
+			best, bestCount = script, count
+		}
+	}
+
+	switch best {
+	case "cyrillic":
+		return cyrillicLanguage(text)
+	case "arabic":
+		return arabicLanguage(text)
+	default:
+		return best
+	}
+}
+
+// cyrillicLanguage tells Ukrainian and Bulgarian from Russian by the letters
+// only they use.
+func cyrillicLanguage(text string) string {
+	lower := strings.ToLower(text)
+
+	switch {
+	case strings.ContainsAny(lower, "іїєґ"):
+		return "uk"
+	case strings.Contains(lower, "ъ") && !strings.ContainsAny(lower, "ыэё"):
+		return "bg"
+	default:
+		return "ru"
+	}
+}
+
+// arabicLanguage tells Persian from Arabic by the letters only Persian uses.
+func arabicLanguage(text string) string {
+	if strings.ContainsAny(text, "پچژگکی") {
+		return "fa"
+	}
+
+	return "ar"
+}
+
+// wordMarkers returns the languages whose marker letters occur in word.
+func wordMarkers(word string) map[string]bool {
+	var langs map[string]bool
+
+	for _, r := range word {
+		if lang, ok := letterMarkers[r]; ok {
+			if langs == nil {
+				langs = make(map[string]bool)
+			}
+
+			langs[lang] = true
+		}
+	}
+
+	return langs
+}
+
+// bestScore returns the language with the strictly highest score, or
+// MessageLanguageUnknown on a tie or when nothing scored.
+func bestScore(scores map[string]int) string {
+	best, bestScore, tied := MessageLanguageUnknown, 0, false
+
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = lang, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+
+	if bestScore == 0 || tied {
+		return MessageLanguageUnknown
+	}
+
+	return best
+}
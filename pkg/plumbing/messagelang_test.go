@@ -0,0 +1,53 @@
+package plumbing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestDetectMessageLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Fix off-by-one in the tokenizer", "en"},
+		{"feat(api): add pagination to list endpoints", "en"},
+		{"Fehler beim Laden der Konfiguration behoben", "de"},
+		{"Correction du calcul des totaux pour les factures", "fr"},
+		{"Corregir el cálculo de los totales en la factura", "es"},
+		{"Corrige o cálculo dos totais na fatura e adiciona testes", "pt"},
+		{"Poprawka błędu przy zapisie pliku", "pl"},
+		{"Исправлена ошибка при сохранении файла", "ru"},
+		{"Виправлено помилку під час збереження файлу", "uk"},
+		{"修复登录页面的崩溃问题", "zh"},
+		{"ログイン画面のクラッシュを修正", "ja"},
+		{"로그인 화면 충돌 수정", "ko"},
+		{"Διόρθωση σφάλματος κατά την αποθήκευση", "el"},
+		{"WIP", plumbing.MessageLanguageUnknown},
+		{"", plumbing.MessageLanguageUnknown},
+		{"v1.2.3\n\nSigned-off-by: Alice <alice@example.com>", plumbing.MessageLanguageUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, plumbing.DetectMessageLanguage(tt.message))
+		})
+	}
+}
+
+func TestDetectTextLanguage(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "de", plumbing.DetectTextLanguage("Das ist nicht die richtige Größe"))
+	assert.Equal(t, "ru", plumbing.DetectTextLanguage("отлично работает"))
+	assert.Equal(t, "en", plumbing.DetectTextLanguage("Signed-off-by: the bot"),
+		"trailers are text in a comment")
+	assert.Equal(t, plumbing.MessageLanguageUnknown, plumbing.DetectTextLanguage("TODO"))
+}
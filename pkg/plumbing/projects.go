@@ -0,0 +1,158 @@
+package plumbing
+
+import (
+	"cmp"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ProjectRoot is the root of the project that spans the whole repository.
+const ProjectRoot = "."
+
+// Project is a project root found inside a repository.
+type Project struct {
+	// Root is the directory of the project, [ProjectRoot] for the top of the
+	// repository.
+	Root string `json:"root" yaml:"root"`
+	// Kinds are the build systems whose marker files the root holds, such
+	// as "go" and "npm".
+	Kinds []string `json:"kinds" yaml:"kinds"`
+}
+
+// Projects are the project roots of a repository, ordered by root.
+type Projects []Project
+
+// projectMarkers map the marker files of build systems to their kind.
+var projectMarkers = map[string]string{
+	"go.mod":           "go",
+	"package.json":     "npm",
+	"pom.xml":          "maven",
+	"build.gradle":     "gradle",
+	"build.gradle.kts": "gradle",
+	"Cargo.toml":       "cargo",
+	"pyproject.toml":   "python",
+	"BUILD":            kindBazel,
+	"BUILD.bazel":      kindBazel,
+}
+
+const kindBazel = "bazel"
+
+// skippedProjectDirs hold third-party or fixture code whose marker files
+// do not make a project of the repository.
+var skippedProjectDirs = []string{"vendor", "node_modules", "testdata", "third_party"}
+
+// DiscoverProjects finds the project roots among the file paths of a tree:
+// every directory holding a marker file such as go.mod, package.json,
+// pom.xml or a Bazel BUILD file. Bazel puts a BUILD file in every package,
+// so a BUILD file only marks a project when no directory above it, except
+// the repository root, has one too.
+func DiscoverProjects(files []string) Projects {
+	kinds := make(map[string][]string)
+	var bazel []string
+
+	for _, file := range files {
+		kind, ok := projectMarkers[path.Base(file)]
+		if !ok || inSkippedDir(file) {
+			continue
+		}
+
+		dir := path.Dir(file)
+
+		if kind == kindBazel {
+			bazel = append(bazel, dir)
+
+			continue
+		}
+
+		if !slices.Contains(kinds[dir], kind) {
+			kinds[dir] = append(kinds[dir], kind)
+		}
+	}
+
+	for _, dir := range topBazelPackages(bazel) {
+		if !slices.Contains(kinds[dir], kindBazel) {
+			kinds[dir] = append(kinds[dir], kindBazel)
+		}
+	}
+
+	projects := make(Projects, 0, len(kinds))
+
+	for dir, dirKinds := range kinds {
+		slices.Sort(dirKinds)
+		projects = append(projects, Project{Root: dir, Kinds: dirKinds})
+	}
+
+	slices.SortFunc(projects, func(x, y Project) int { return cmp.Compare(x.Root, y.Root) })
+
+	return projects
+}
+
+// topBazelPackages returns the Bazel packages no other package except the
+// repository root contains.
+func topBazelPackages(dirs []string) []string {
+	slices.Sort(dirs)
+	dirs = slices.Compact(dirs)
+
+	var top []string
+
+	for _, dir := range dirs {
+		nested := slices.ContainsFunc(top, func(parent string) bool {
+			return parent != ProjectRoot && isUnder(dir, parent)
+		})
+
+		if !nested {
+			top = append(top, dir)
+		}
+	}
+
+	return top
+}
+
+// Of returns the root of the innermost project containing file, or "" when
+// no project does.
+func (p Projects) Of(file string) string {
+	best := ""
+
+	for _, project := range p {
+		if !isUnder(path.Dir(file), project.Root) {
+			continue
+		}
+
+		if best == "" || best == ProjectRoot || len(project.Root) > len(best) {
+			best = project.Root
+		}
+	}
+
+	return best
+}
+
+// Roots returns the roots of the projects.
+func (p Projects) Roots() []string {
+	roots := make([]string, len(p))
+
+	for i, project := range p {
+		roots[i] = project.Root
+	}
+
+	return roots
+}
+
+// isUnder reports whether dir is root or one of its subdirectories.
+func isUnder(dir, root string) bool {
+	if root == ProjectRoot || dir == root {
+		return true
+	}
+
+	return strings.HasPrefix(dir, root+"/")
+}
+
+func inSkippedDir(file string) bool {
+	for part := range strings.SplitSeq(path.Dir(file), "/") {
+		if slices.Contains(skippedProjectDirs, part) {
+			return true
+		}
+	}
+
+	return false
+}
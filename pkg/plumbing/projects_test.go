@@ -0,0 +1,78 @@
+package plumbing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/plumbing"
+)
+
+func TestDiscoverProjects(t *testing.T) {
+	t.Parallel()
+
+	projects := plumbing.DiscoverProjects([]string{
+		"go.mod",
+		"main.go",
+		"BUILD.bazel",
+		"services/api/go.mod",
+		"services/api/BUILD.bazel",
+		"services/api/handlers/BUILD.bazel",
+		"services/billing/BUILD",
+		"services/billing/core/BUILD",
+		"web/package.json",
+		"web/node_modules/react/package.json",
+		"web/packages/ui/package.json",
+		"java/orders/pom.xml",
+		"java/orders/build.gradle.kts",
+		"pkg/parser/testdata/go.mod",
+		"vendor/github.com/lib/go.mod",
+		"README.md",
+	})
+
+	assert.Equal(t, plumbing.Projects{
+		{Root: ".", Kinds: []string{"bazel", "go"}},
+		{Root: "java/orders", Kinds: []string{"gradle", "maven"}},
+		{Root: "services/api", Kinds: []string{"bazel", "go"}},
+		{Root: "services/billing", Kinds: []string{"bazel"}},
+		{Root: "web", Kinds: []string{"npm"}},
+		{Root: "web/packages/ui", Kinds: []string{"npm"}},
+	}, projects)
+	assert.Equal(t, []string{".", "java/orders", "services/api", "services/billing", "web", "web/packages/ui"},
+		projects.Roots())
+}
+
+func TestDiscoverProjects_NoMarkers(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, plumbing.DiscoverProjects([]string{"main.c", "Makefile"}))
+}
+
+func TestProjects_Of(t *testing.T) {
+	t.Parallel()
+
+	projects := plumbing.Projects{
+		{Root: ".", Kinds: []string{"go"}},
+		{Root: "a", Kinds: []string{"npm"}},
+		{Root: "web", Kinds: []string{"npm"}},
+		{Root: "web/packages/ui", Kinds: []string{"npm"}},
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"main.go", "."},
+		{"a/index.js", "a"},
+		{"ab/index.js", "."},
+		{"web/src/app.ts", "web"},
+		{"web/packages/ui/button.tsx", "web/packages/ui"},
+		{"web/packages/uikit/button.tsx", "web"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, projects.Of(tt.file), tt.file)
+	}
+
+	assert.Empty(t, plumbing.Projects{{Root: "web"}}.Of("cmd/main.go"))
+}
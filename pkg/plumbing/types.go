@@ -28,3 +28,16 @@ type LineStats struct {
 	// Changed is the number of changed lines by a particular developer in a particular day.
 	Changed int `json:"changed" yaml:"changed"`
 }
+
+// HunkStats holds the line statistics of one diff hunk: a maximal run of
+// inserted and deleted lines between unchanged lines. Line positions are zero-based.
+// WhitespaceOnly is set, and Stats left zero, when the hunk differs only in
+// whitespace and whitespace-only changes are ignored.
+type HunkStats struct {
+	OldStart       int       `json:"old_start"                 yaml:"old_start"`
+	OldLines       int       `json:"old_lines"                 yaml:"old_lines"`
+	NewStart       int       `json:"new_start"                 yaml:"new_start"`
+	NewLines       int       `json:"new_lines"                 yaml:"new_lines"`
+	Stats          LineStats `json:"stats"                     yaml:"stats"`
+	WhitespaceOnly bool      `json:"whitespace_only,omitempty" yaml:"whitespace_only,omitempty"`
+}
@@ -0,0 +1,138 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ErasedAuthor is the default replacement of an erased identity.
+const ErasedAuthor = "erased-author"
+
+// Eraser replaces every trace of one author, identified by email, in stored
+// reports: people dictionary entries holding the email, "Name <email>"
+// signatures, the bare email and the names the author committed under. The
+// email is also replaced where it appears inside longer text, such as a
+// trailer or a note. Counts and other metrics stay, now attributed to the
+// replacement.
+//
+// An Eraser is not safe for concurrent use.
+type Eraser struct {
+	email    string
+	with     string
+	inText   *regexp.Regexp
+	names    map[string]struct{}
+	replaced int
+}
+
+// NewEraser creates an Eraser of the author with email. An empty with
+// defaults to ErasedAuthor.
+func NewEraser(email, with string) *Eraser {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	if with == "" {
+		with = ErasedAuthor
+	}
+
+	return &Eraser{
+		email:  email,
+		with:   with,
+		inText: regexp.MustCompile("(?i)" + regexp.QuoteMeta(email)),
+		names:  make(map[string]struct{}),
+	}
+}
+
+// Learn collects the names of the author from the identities in v that carry
+// the email, so that Value also replaces those names on their own. Call it on
+// every report before Value.
+func (e *Eraser) Learn(v any) {
+	replaceStrings(v, func(s string) (string, bool) {
+		e.learn(s)
+
+		return s, false
+	})
+}
+
+// Value replaces the author in v, in place where possible, and returns v or
+// its replacement.
+func (e *Eraser) Value(v any) any {
+	return replaceStrings(v, e.replace)
+}
+
+// Replaced returns the number of strings Value has replaced so far.
+func (e *Eraser) Replaced() int {
+	return e.replaced
+}
+
+func (e *Eraser) learn(s string) {
+	lower := strings.ToLower(s)
+
+	if name, email, found := strings.Cut(lower, "<"); found {
+		if strings.TrimSuffix(strings.TrimSpace(email), ">") == e.email {
+			e.addName(name)
+		}
+
+		return
+	}
+
+	parts := strings.Split(lower, "|")
+	if len(parts) < 2 || !containsEmail(parts, e.email) {
+		return
+	}
+
+	for _, part := range parts {
+		if !strings.Contains(part, "@") {
+			e.addName(part)
+		}
+	}
+}
+
+func (e *Eraser) addName(name string) {
+	name = strings.TrimSpace(name)
+	if name != "" {
+		e.names[name] = struct{}{}
+	}
+}
+
+func (e *Eraser) replace(s string) (string, bool) {
+	if e.email == "" || s == "" {
+		return s, false
+	}
+
+	if e.isAuthor(strings.ToLower(s)) {
+		e.replaced++
+
+		return e.with, true
+	}
+
+	if !e.inText.MatchString(s) {
+		return s, false
+	}
+
+	e.replaced++
+
+	return e.inText.ReplaceAllLiteralString(s, e.with), true
+}
+
+// isAuthor reports whether lower, a lowercased string, names the author as
+// a whole: a dictionary entry, a signature, the email or a learned name.
+func (e *Eraser) isAuthor(lower string) bool {
+	if _, found := e.names[lower]; found {
+		return true
+	}
+
+	if name, email, found := strings.Cut(lower, "<"); found && !strings.Contains(name, "\n") {
+		return strings.TrimSuffix(strings.TrimSpace(email), ">") == e.email
+	}
+
+	return containsEmail(strings.Split(lower, "|"), e.email)
+}
+
+func containsEmail(parts []string, email string) bool {
+	for _, part := range parts {
+		if strings.TrimSpace(part) == email {
+			return true
+		}
+	}
+
+	return false
+}
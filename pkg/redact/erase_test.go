@@ -0,0 +1,52 @@
+package redact_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/redact"
+)
+
+func TestEraser(t *testing.T) {
+	t.Parallel()
+
+	var report any
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"ReversedPeopleDict": ["alice|alice smith|alice@corp.example", "bob|bob@corp.example"],
+		"developers": [{"name": "alice|alice smith|alice@corp.example", "commits": 12}],
+		"owners": {"alice smith": 3, "bob": 1},
+		"commits": [{"author": "Alice Smith <Alice@Corp.example>", "note": "reviewed by alice@corp.example"}],
+		"files": ["alice.go"]
+	}`), &report))
+
+	e := redact.NewEraser("Alice@corp.example", "")
+	e.Learn(report)
+	report = e.Value(report)
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"ReversedPeopleDict": ["erased-author", "bob|bob@corp.example"],
+		"developers": [{"name": "erased-author", "commits": 12}],
+		"owners": {"erased-author": 3, "bob": 1},
+		"commits": [{"author": "erased-author", "note": "reviewed by erased-author"}],
+		"files": ["alice.go"]
+	}`, string(data))
+	assert.Equal(t, 5, e.Replaced())
+}
+
+func TestEraser_ReplaceWith(t *testing.T) {
+	t.Parallel()
+
+	e := redact.NewEraser("alice@corp.example", "former-staff")
+	report := map[string]any{"dict": []any{"alice <alice@corp.example>"}, "top": "alice"}
+
+	e.Learn(report)
+	e.Value(report)
+
+	assert.Equal(t, map[string]any{"dict": []any{"former-staff"}, "top": "former-staff"}, report)
+}
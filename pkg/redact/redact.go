@@ -0,0 +1,392 @@
+// Package redact pseudonymizes author identities, file paths and commit
+// message excerpts in reports, so that reports can be shared outside the
+// team that owns the repository.
+//
+// A Redactor learns the values to hide while a run consumes commits: the
+// paths each commit changes, its message and, at the end, the identities of
+// the people dictionary. It then replaces every string of a report that
+// equals one of them with a pseudonym, as well as the path that ends a
+// composite key such as a shotness node key. Pseudonyms are keyed hashes, so
+// the same value gets the same pseudonym in every analyzer's output, and in
+// every run that uses the same key.
+//
+// An Eraser removes one author from reports already written, for erasure
+// requests of people who have left.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Mode selects what a Redactor hides. Modes combine as bit flags.
+type Mode uint8
+
+// Redaction modes.
+const (
+	// Authors replaces names and emails of the people dictionary.
+	Authors Mode = 1 << iota
+	// Paths replaces file and directory paths, keeping file extensions.
+	Paths
+	// Messages replaces commit subjects, messages, trailers and notes.
+	Messages
+)
+
+// Mode names accepted by ParseModes.
+const (
+	nameAuthors  = "authors"
+	namePaths    = "paths"
+	nameMessages = "messages"
+	nameAll      = "all"
+)
+
+// EnvKey holds the key pseudonyms are derived from. Without it every run
+// draws a random key, so pseudonyms only match within one run.
+const EnvKey = "CODEFANG_REDACT_KEY"
+
+// Pseudonym layout.
+const (
+	pseudonymHexLen = 10
+	randomKeyLen    = 32
+	// minMessageLen keeps short subjects such as "wip" from matching
+	// unrelated report values.
+	minMessageLen = 8
+)
+
+// ErrUnknownMode is returned by ParseModes for an unsupported mode name.
+var ErrUnknownMode = errors.New("unknown redaction mode")
+
+// ParseModes parses a comma-separated list of authors, paths, messages or all.
+// An empty list yields no modes.
+func ParseModes(list string) (Mode, error) {
+	var modes Mode
+
+	for name := range strings.SplitSeq(list, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+		case nameAuthors:
+			modes |= Authors
+		case namePaths:
+			modes |= Paths
+		case nameMessages:
+			modes |= Messages
+		case nameAll:
+			modes |= Authors | Paths | Messages
+		default:
+			return 0, fmt.Errorf("%w %q (valid: authors, paths, messages, all)", ErrUnknownMode, name)
+		}
+	}
+
+	return modes, nil
+}
+
+// Has reports whether m includes every mode of other.
+func (m Mode) Has(other Mode) bool {
+	return m&other == other
+}
+
+// String returns the comma-separated mode names.
+func (m Mode) String() string {
+	var names []string
+
+	for _, mode := range []struct {
+		mode Mode
+		name string
+	}{{Authors, nameAuthors}, {Paths, namePaths}, {Messages, nameMessages}} {
+		if m.Has(mode.mode) {
+			names = append(names, mode.name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Redactor replaces known identities, paths and messages with pseudonyms.
+// All methods are safe for concurrent use and on a nil receiver, which
+// redacts nothing.
+type Redactor struct {
+	modes Mode
+	key   []byte
+	seed  maphash.Seed
+
+	mu       sync.RWMutex
+	authors  map[string]string
+	paths    map[uint64]struct{}
+	messages map[uint64]struct{}
+}
+
+// New creates a Redactor for modes. A nil key draws a random one.
+func New(modes Mode, key []byte) *Redactor {
+	if key == nil {
+		key = make([]byte, randomKeyLen)
+		_, _ = rand.Read(key) //nolint:errcheck // crypto/rand.Read never fails.
+	}
+
+	return &Redactor{
+		modes:    modes,
+		key:      key,
+		seed:     maphash.MakeSeed(),
+		authors:  make(map[string]string),
+		paths:    make(map[uint64]struct{}),
+		messages: make(map[uint64]struct{}),
+	}
+}
+
+// FromEnv creates a Redactor for modes keyed by CODEFANG_REDACT_KEY, or by a
+// random key when it is unset.
+func FromEnv(modes Mode) *Redactor {
+	var key []byte
+
+	if value := os.Getenv(EnvKey); value != "" {
+		key = []byte(value)
+	}
+
+	return New(modes, key)
+}
+
+// Modes returns the modes of r.
+func (r *Redactor) Modes() Mode {
+	if r == nil {
+		return 0
+	}
+
+	return r.modes
+}
+
+// ObservePaths records file paths to hide, together with their directories.
+func (r *Redactor) ObservePaths(paths ...string) {
+	if r == nil || !r.modes.Has(Paths) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range paths {
+		for p != "" && p != "." && p != "/" {
+			r.paths[maphash.String(r.seed, p)] = struct{}{}
+			p = path.Dir(p)
+		}
+	}
+}
+
+// ObserveMessage records a commit message to hide, whole and by its subject.
+func (r *Redactor) ObserveMessage(message string) {
+	if r == nil || !r.modes.Has(Messages) {
+		return
+	}
+
+	message = strings.TrimSpace(message)
+	subject, _, _ := strings.Cut(message, "\n")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range []string{message, strings.TrimSpace(subject)} {
+		if len(s) >= minMessageLen {
+			r.messages[maphash.String(r.seed, s)] = struct{}{}
+		}
+	}
+}
+
+// ObserveAuthors records the identities of a people dictionary, whose entries
+// join an author's names and emails with "|". Every name and email of an
+// entry maps to the pseudonym of the entry.
+func (r *Redactor) ObserveAuthors(dict []string) {
+	if r == nil || !r.modes.Has(Authors) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range dict {
+		if entry == "" {
+			continue
+		}
+
+		pseudonym := r.pseudonym("author-", authorKey(entry))
+		r.authors[entry] = pseudonym
+
+		for part := range strings.SplitSeq(entry, "|") {
+			if _, taken := r.authors[part]; part != "" && !taken {
+				r.authors[part] = pseudonym
+			}
+		}
+	}
+}
+
+// String returns the pseudonym of s when s is a known author, path or
+// message, and s otherwise.
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+
+	out, _ := r.lookup(s)
+
+	return out
+}
+
+// Author returns the pseudonym of an identity, given as a dictionary entry,
+// a name, an email or "Name <email>". It returns identity unchanged unless
+// authors are redacted.
+func (r *Redactor) Author(identity string) string {
+	if r == nil || !r.modes.Has(Authors) || identity == "" {
+		return identity
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if pseudonym, found := r.authors[identity]; found {
+		return pseudonym
+	}
+
+	if name, email, found := strings.Cut(identity, "<"); found {
+		email = strings.TrimSuffix(strings.TrimSpace(email), ">")
+
+		for _, part := range []string{email, strings.TrimSpace(name)} {
+			if pseudonym, known := r.authors[part]; known {
+				return pseudonym
+			}
+		}
+
+		return r.pseudonym("author-", email)
+	}
+
+	return r.pseudonym("author-", identity)
+}
+
+// Path returns the pseudonym of a slash-separated path. Every directory and
+// the file name are replaced by a hash of the path up to them, so files of
+// one directory share its pseudonym; the file extension is kept. It returns p
+// unchanged unless paths are redacted.
+func (r *Redactor) Path(p string) string {
+	if r == nil || !r.modes.Has(Paths) || p == "" {
+		return p
+	}
+
+	parts := strings.Split(p, "/")
+	out := make([]string, len(parts))
+
+	for i, part := range parts {
+		if part == "" || part == "." || part == ".." {
+			out[i] = part
+
+			continue
+		}
+
+		prefix := strings.Join(parts[:i+1], "/")
+		out[i] = r.pseudonym("", prefix)
+
+		if i == len(parts)-1 {
+			out[i] += path.Ext(part)
+		}
+	}
+
+	return strings.Join(out, "/")
+}
+
+// Excerpt returns the pseudonym of free text taken from a commit message. It
+// returns text unchanged unless messages are redacted.
+func (r *Redactor) Excerpt(text string) string {
+	if r == nil || !r.modes.Has(Messages) || text == "" {
+		return text
+	}
+
+	return r.pseudonym("message-", text)
+}
+
+// Trailer returns the redacted value of a commit message trailer. Values of
+// identity trailers such as Signed-off-by or Co-authored-by are authors;
+// other values are message excerpts.
+func (r *Redactor) Trailer(key, value string) string {
+	if strings.HasSuffix(strings.ToLower(key), "-by") {
+		if r.Modes().Has(Authors) {
+			return r.Author(value)
+		}
+
+		return value
+	}
+
+	return r.Excerpt(value)
+}
+
+func (r *Redactor) lookup(s string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if pseudonym, found := r.authors[s]; found {
+		return pseudonym, true
+	}
+
+	if len(r.paths) == 0 && len(r.messages) == 0 {
+		return s, false
+	}
+
+	h := maphash.String(r.seed, s)
+
+	if _, found := r.paths[h]; found {
+		return r.Path(s), true
+	}
+
+	if _, found := r.messages[h]; found {
+		return r.pseudonym("message-", s), true
+	}
+
+	return r.compositePath(s)
+}
+
+// compositePath redacts the path that ends a composite key, such as the
+// "Type_Name_File" node keys of shotness, keeping the rest of the key. The
+// longest known path after an underscore is replaced. The caller holds mu.
+func (r *Redactor) compositePath(s string) (string, bool) {
+	if len(r.paths) == 0 {
+		return s, false
+	}
+
+	for i, c := range s {
+		if c != '_' {
+			continue
+		}
+
+		if _, found := r.paths[maphash.String(r.seed, s[i+1:])]; found {
+			return s[:i+1] + r.Path(s[i+1:]), true
+		}
+	}
+
+	return s, false
+}
+
+// pseudonym derives a stable pseudonym of value from the key.
+func (r *Redactor) pseudonym(prefix, value string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(prefix))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+
+	return prefix + hex.EncodeToString(mac.Sum(nil))[:pseudonymHexLen]
+}
+
+// authorKey returns the part of a dictionary entry an author's pseudonym is
+// derived from: the first email, which stays the same when names are merged
+// into the entry, or the entry itself.
+func authorKey(entry string) string {
+	for part := range strings.SplitSeq(entry, "|") {
+		if strings.Contains(part, "@") {
+			return strings.ToLower(part)
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,128 @@
+package redact_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/redact"
+)
+
+func TestParseModes(t *testing.T) {
+	t.Parallel()
+
+	modes, err := redact.ParseModes("authors, PATHS")
+	require.NoError(t, err)
+	assert.Equal(t, redact.Authors|redact.Paths, modes)
+	assert.Equal(t, "authors,paths", modes.String())
+
+	modes, err = redact.ParseModes("all")
+	require.NoError(t, err)
+	assert.True(t, modes.Has(redact.Authors|redact.Paths|redact.Messages))
+
+	modes, err = redact.ParseModes("")
+	require.NoError(t, err)
+	assert.Zero(t, modes)
+
+	_, err = redact.ParseModes("authors,emails")
+	require.ErrorIs(t, err, redact.ErrUnknownMode)
+}
+
+func TestRedactor_Path(t *testing.T) {
+	t.Parallel()
+
+	r := redact.New(redact.Paths, []byte("key"))
+
+	file := r.Path("internal/billing/invoice.go")
+	dir := r.Path("internal/billing")
+
+	assert.True(t, strings.HasSuffix(file, ".go"))
+	assert.True(t, strings.HasPrefix(file, dir+"/"), "files share their directory's pseudonym")
+	assert.NotContains(t, file, "billing")
+	assert.NotEqual(t, r.Path("internal/billing/invoice.go"), r.Path("internal/billing/refund.go"))
+
+	again := redact.New(redact.Paths, []byte("key"))
+	assert.Equal(t, file, again.Path("internal/billing/invoice.go"), "the same key gives the same pseudonyms")
+
+	other := redact.New(redact.Paths, []byte("other"))
+	assert.NotEqual(t, file, other.Path("internal/billing/invoice.go"))
+
+	assert.Equal(t, "a/b.go", redact.New(redact.Authors, nil).Path("a/b.go"), "paths mode is off")
+}
+
+func TestRedactor_CompositeKeys(t *testing.T) {
+	t.Parallel()
+
+	r := redact.New(redact.Paths, []byte("key"))
+	r.ObservePaths("internal/pay_roll/run.go")
+
+	key := r.String("Function_Run_internal/pay_roll/run.go")
+	assert.Equal(t, "Function_Run_"+r.Path("internal/pay_roll/run.go"), key)
+	assert.NotContains(t, key, "pay_roll")
+
+	assert.Equal(t, "Function_Run_other.go", r.String("Function_Run_other.go"), "unknown paths are kept")
+	assert.Equal(t, "snake_case", r.String("snake_case"))
+}
+
+func TestRedactor_Authors(t *testing.T) {
+	t.Parallel()
+
+	r := redact.New(redact.Authors, nil)
+	r.ObserveAuthors([]string{"alice|alice smith|alice@corp.example", "bob|bob@corp.example"})
+
+	alice := r.String("alice|alice smith|alice@corp.example")
+	assert.True(t, strings.HasPrefix(alice, "author-"))
+	assert.Equal(t, alice, r.String("alice smith"))
+	assert.Equal(t, alice, r.String("alice@corp.example"))
+	assert.Equal(t, alice, r.Author("Alice S. <alice@corp.example>"))
+	assert.NotEqual(t, alice, r.String("bob"))
+	assert.Equal(t, "carol", r.String("carol"), "unknown values are kept")
+
+	assert.Equal(t, alice, r.Trailer("Co-authored-by", "Alice <alice@corp.example>"))
+	assert.Equal(t, "JIRA-1", r.Trailer("Refs", "JIRA-1"), "messages mode is off")
+}
+
+func TestRedactor_Messages(t *testing.T) {
+	t.Parallel()
+
+	r := redact.New(redact.Messages, nil)
+	r.ObserveMessage("Fix billing rounding for ACME\n\nThe ACME contract rounds up.\n")
+	r.ObserveMessage("wip")
+
+	subject := r.String("Fix billing rounding for ACME")
+	assert.True(t, strings.HasPrefix(subject, "message-"))
+	assert.NotEqual(t, subject, r.String("Fix billing rounding for ACME\n\nThe ACME contract rounds up."))
+	assert.Equal(t, "wip", r.String("wip"), "short subjects are not matched")
+	assert.True(t, strings.HasPrefix(r.Excerpt("note text"), "message-"))
+}
+
+func TestRedactor_Nil(t *testing.T) {
+	t.Parallel()
+
+	var r *redact.Redactor
+
+	r.ObservePaths("a.go")
+	r.ObserveMessage("message text")
+	r.ObserveAuthors([]string{"alice"})
+
+	assert.Equal(t, "a.go", r.String("a.go"))
+	assert.Equal(t, "a.go", r.Path("a.go"))
+	assert.Equal(t, "alice", r.Author("alice"))
+	assert.Equal(t, 1, r.Value(1))
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(redact.EnvKey, "shared")
+
+	a := redact.FromEnv(redact.Paths)
+	b := redact.FromEnv(redact.Paths)
+	assert.Equal(t, a.Path("a/b.go"), b.Path("a/b.go"))
+
+	t.Setenv(redact.EnvKey, "")
+
+	c := redact.FromEnv(redact.Paths)
+	d := redact.FromEnv(redact.Paths)
+	assert.NotEqual(t, c.Path("a/b.go"), d.Path("a/b.go"), "random keys differ")
+}
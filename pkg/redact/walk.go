@@ -0,0 +1,266 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Value replaces, in place, every known string reachable from v: strings in
+// exported struct fields, map keys and values, slice and array elements,
+// behind pointers and interfaces. Values that cannot be changed in place,
+// such as a bare string or a struct held by value in an interface, are
+// replaced in the returned value. Unexported fields are left alone.
+//
+// Value mutates shared data, so it is meant for finished reports.
+func (r *Redactor) Value(v any) any {
+	if r == nil || r.modes == 0 {
+		return v
+	}
+
+	return replaceStrings(v, r.lookup)
+}
+
+// Copy returns a redacted copy of v after a JSON round trip, leaving v
+// untouched. Structs become maps, so Copy suits values that are only
+// encoded afterwards, such as streamed records.
+func (r *Redactor) Copy(v any) (any, error) {
+	if r == nil || r.modes == 0 || v == nil {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("redact: encode: %w", err)
+	}
+
+	var generic any
+
+	err = json.Unmarshal(data, &generic)
+	if err != nil {
+		return nil, fmt.Errorf("redact: decode: %w", err)
+	}
+
+	return r.Value(generic), nil
+}
+
+// visit identifies a map, slice or pointer target already walked, so shared
+// data is redacted once and cycles end.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+type walker struct {
+	replace func(string) (string, bool)
+	seen    map[visit]bool
+}
+
+// replaceStrings walks v, replacing every string for which replace reports
+// a change, and returns v or its replacement.
+func replaceStrings(v any, replace func(string) (string, bool)) any {
+	if v == nil {
+		return v
+	}
+
+	w := walker{replace: replace, seen: make(map[visit]bool)}
+
+	out, changed := w.walk(reflect.ValueOf(v))
+	if !changed {
+		return v
+	}
+
+	return out.Interface()
+}
+
+// walk redacts v and returns its replacement when v itself had to change.
+func (w *walker) walk(v reflect.Value) (reflect.Value, bool) {
+	if !v.IsValid() || !mayHoldStrings(v.Type()) {
+		return v, false
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		out, changed := w.replace(v.String())
+		if !changed {
+			return v, false
+		}
+
+		nv := reflect.New(v.Type()).Elem()
+		nv.SetString(out)
+
+		return nv, true
+	case reflect.Pointer:
+		if v.IsNil() || w.visited(v) {
+			return v, false
+		}
+
+		elem := v.Elem()
+		if nv, changed := w.walk(elem); changed && elem.CanSet() {
+			elem.Set(nv)
+		}
+
+		return v, false
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+
+		nv, changed := w.walk(v.Elem())
+		if !changed {
+			return v, false
+		}
+
+		out := reflect.New(v.Type()).Elem()
+		out.Set(nv)
+
+		return out, true
+	case reflect.Struct:
+		return w.walkStruct(v)
+	case reflect.Map:
+		w.walkMap(v)
+
+		return v, false
+	case reflect.Slice:
+		if v.IsNil() || w.visited(v) {
+			return v, false
+		}
+
+		w.walkElems(v)
+
+		return v, false
+	case reflect.Array:
+		if v.CanAddr() {
+			w.walkElems(v)
+
+			return v, false
+		}
+
+		nv := reflect.New(v.Type()).Elem()
+		nv.Set(v)
+		w.walkElems(nv)
+
+		return nv, true
+	default:
+		return v, false
+	}
+}
+
+func (w *walker) walkStruct(v reflect.Value) (reflect.Value, bool) {
+	inPlace := v.CanAddr()
+
+	target := v
+	if !inPlace {
+		target = reflect.New(v.Type()).Elem()
+		target.Set(v)
+	}
+
+	changed := false
+
+	for i := range target.NumField() {
+		if !target.Type().Field(i).IsExported() {
+			continue
+		}
+
+		field := target.Field(i)
+		if nv, fieldChanged := w.walk(field); fieldChanged {
+			field.Set(nv)
+
+			changed = true
+		}
+	}
+
+	if inPlace {
+		return v, false
+	}
+
+	return target, changed
+}
+
+func (w *walker) walkMap(v reflect.Value) {
+	if v.IsNil() || w.visited(v) {
+		return
+	}
+
+	for _, key := range v.MapKeys() {
+		val := v.MapIndex(key)
+
+		newKey, keyChanged := w.walk(key)
+		newVal, valChanged := w.walk(val)
+
+		if keyChanged {
+			v.SetMapIndex(key, reflect.Value{})
+		}
+
+		if keyChanged || valChanged {
+			v.SetMapIndex(newKey, newVal)
+		}
+	}
+}
+
+func (w *walker) walkElems(v reflect.Value) {
+	for i := range v.Len() {
+		elem := v.Index(i)
+		if nv, changed := w.walk(elem); changed && elem.CanSet() {
+			elem.Set(nv)
+		}
+	}
+}
+
+func (w *walker) visited(v reflect.Value) bool {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+
+	if w.seen[key] {
+		return true
+	}
+
+	w.seen[key] = true
+
+	return false
+}
+
+// stringTypes caches whether values of a type can hold strings.
+var stringTypes sync.Map
+
+// mayHoldStrings reports whether a value of t can reach a string, so that
+// large numeric matrices are skipped without visiting their elements.
+func mayHoldStrings(t reflect.Type) bool {
+	if cached, found := stringTypes.Load(t); found {
+		return cached.(bool) //nolint:forcetypeassert // only bools are stored.
+	}
+
+	// Recursive types reach themselves through a pointer, slice or map;
+	// assuming true while t is resolved ends the recursion.
+	stringTypes.Store(t, true)
+
+	holds := computeHoldsStrings(t)
+	stringTypes.Store(t, holds)
+
+	return holds
+}
+
+func computeHoldsStrings(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return mayHoldStrings(t.Elem())
+	case reflect.Map:
+		return mayHoldStrings(t.Key()) || mayHoldStrings(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if t.Field(i).IsExported() && mayHoldStrings(t.Field(i).Type) {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return false
+	}
+}
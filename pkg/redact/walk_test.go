@@ -0,0 +1,103 @@
+package redact_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/redact"
+)
+
+type fileStat struct {
+	Path    string
+	Authors []string
+	Lines   []int
+	secret  string
+}
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func newTestRedactor() *redact.Redactor {
+	r := redact.New(redact.Authors|redact.Paths|redact.Messages, []byte("key"))
+	r.ObservePaths("cmd/main.go", "internal/billing/invoice.go")
+	r.ObserveAuthors([]string{"alice|alice@corp.example"})
+	r.ObserveMessage("Fix billing rounding for ACME")
+
+	return r
+}
+
+func TestRedactor_Value(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRedactor()
+
+	stat := &fileStat{Path: "cmd/main.go", Authors: []string{"alice"}, Lines: []int{1, 2}, secret: "cmd/main.go"}
+	byValue := fileStat{Path: "internal/billing/invoice.go"}
+
+	report := map[string]any{
+		"files":    map[string]fileStat{"internal/billing/invoice.go": {Path: "internal/billing/invoice.go"}},
+		"stat":     stat,
+		"by_value": byValue,
+		"dirs":     []string{"internal/billing", "vendor"},
+		"subjects": map[string]int{"Fix billing rounding for ACME": 1},
+		"nested":   []any{"alice@corp.example", map[string]any{"path": "cmd"}},
+	}
+
+	out := r.Value(report)
+	assert.Equal(t, report, out, "maps are redacted in place")
+
+	files := report["files"].(map[string]fileStat) //nolint:forcetypeassert // test data.
+	require.Len(t, files, 1)
+
+	for key, val := range files {
+		assert.Equal(t, r.Path("internal/billing/invoice.go"), key)
+		assert.Equal(t, key, val.Path)
+	}
+
+	assert.Equal(t, r.Path("cmd/main.go"), stat.Path)
+	assert.Equal(t, []string{r.Author("alice")}, stat.Authors)
+	assert.Equal(t, "cmd/main.go", stat.secret, "unexported fields are left alone")
+	assert.Equal(t, "internal/billing/invoice.go", byValue.Path, "values are copied, not changed")
+	assert.Equal(t, r.Path("internal/billing/invoice.go"), report["by_value"].(fileStat).Path) //nolint:forcetypeassert // test data.
+	assert.Equal(t, []string{r.Path("internal/billing"), "vendor"}, report["dirs"])
+	assert.NotContains(t, report["subjects"], "Fix billing rounding for ACME")
+
+	nested := report["nested"].([]any) //nolint:forcetypeassert // test data.
+	assert.Equal(t, r.Author("alice"), nested[0])
+	assert.Equal(t, map[string]any{"path": r.Path("cmd")}, nested[1])
+}
+
+func TestRedactor_ValueCycles(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRedactor()
+
+	a := &node{Name: "cmd/main.go"}
+	a.Next = &node{Name: "alice", Next: a}
+
+	r.Value(a)
+
+	assert.Equal(t, r.Path("cmd/main.go"), a.Name)
+	assert.Equal(t, r.Author("alice"), a.Next.Name)
+	assert.Equal(t, r.Path("cmd/main.go"), r.Value("cmd/main.go"))
+}
+
+func TestRedactor_Copy(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRedactor()
+	stat := fileStat{Path: "cmd/main.go", Authors: []string{"alice"}, Lines: []int{3}}
+
+	out, err := r.Copy(stat)
+	require.NoError(t, err)
+	assert.Equal(t, "cmd/main.go", stat.Path, "the original is untouched")
+
+	data, err := json.Marshal(out)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Path":"`+r.Path("cmd/main.go")+`","Authors":["`+r.Author("alice")+`"],"Lines":[3]}`, string(data))
+}
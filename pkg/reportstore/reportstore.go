@@ -0,0 +1,330 @@
+// Package reportstore keeps the reports of many runs under one root, on
+// local disk or in object storage, namespaced by organization, repository
+// and run. A catalog at the root lists every stored run, so tools can list
+// and open historical runs by name instead of by path.
+//
+// The layout under the root is:
+//
+//	catalog.json
+//	<org>/<repo>/<run>/report.json (or report.bin)
+package reportstore
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/storage"
+)
+
+// CatalogFile is the name of the catalog at the root of a store.
+const CatalogFile = "catalog.json"
+
+// CatalogVersion is the schema version of the catalog.
+const CatalogVersion = "codefang.store.v1"
+
+// runIDLayout formats the run IDs made by NewRunID.
+const runIDLayout = "20060102T150405Z"
+
+// Report formats a store accepts: the ones reports can be read back from.
+const (
+	FormatJSON = "json"
+	FormatBin  = "bin"
+)
+
+var (
+	// ErrInvalidNamespace indicates a malformed org/repo[/run] namespace.
+	ErrInvalidNamespace = errors.New("invalid namespace")
+	// ErrUnsupportedFormat indicates a report format a store cannot keep.
+	ErrUnsupportedFormat = errors.New("unsupported store format")
+	// ErrRunNotFound is returned when no stored run matches a namespace.
+	ErrRunNotFound = errors.New("run not found")
+)
+
+// Namespace names a run, or with an empty Run, a repository.
+type Namespace struct {
+	Org  string
+	Repo string
+	Run  string
+}
+
+// ParseNamespace parses "org/repo" or "org/repo/run". Segments may not be
+// empty, "." or "..".
+func ParseNamespace(s string) (Namespace, error) {
+	parts := strings.Split(strings.Trim(s, "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Namespace{}, fmt.Errorf("%w: %q (want org/repo or org/repo/run)", ErrInvalidNamespace, s)
+	}
+
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." || strings.ContainsAny(part, `\:`) {
+			return Namespace{}, fmt.Errorf("%w: %q", ErrInvalidNamespace, s)
+		}
+	}
+
+	ns := Namespace{Org: parts[0], Repo: parts[1]}
+	if len(parts) == 3 {
+		ns.Run = parts[2]
+	}
+
+	return ns, nil
+}
+
+// String returns the namespace as org/repo or org/repo/run.
+func (n Namespace) String() string {
+	if n.Run == "" {
+		return n.Org + "/" + n.Repo
+	}
+
+	return n.Org + "/" + n.Repo + "/" + n.Run
+}
+
+// NewRunID returns the run ID of a run started at t: its UTC timestamp,
+// which sorts in start order.
+func NewRunID(t time.Time) string {
+	return t.UTC().Format(runIDLayout)
+}
+
+// Entry describes one stored run in the catalog.
+type Entry struct {
+	Org       string    `json:"org"`
+	Repo      string    `json:"repo"`
+	Run       string    `json:"run"`
+	Format    string    `json:"format"`
+	Created   time.Time `json:"created"`
+	Analyzers []string  `json:"analyzers,omitempty"`
+	// Encrypted marks a report written with --encrypt-at-rest.
+	Encrypted bool `json:"encrypted,omitempty"`
+	// Path is the report's location relative to the store root.
+	Path string `json:"path"`
+}
+
+// Namespace returns the namespace of the entry's run.
+func (e Entry) Namespace() Namespace {
+	return Namespace{Org: e.Org, Repo: e.Repo, Run: e.Run}
+}
+
+// catalog is the encoded form of catalog.json.
+type catalog struct {
+	Version string  `json:"version"`
+	Runs    []Entry `json:"runs"`
+}
+
+// Store keeps reports under a root location.
+type Store struct {
+	root string
+}
+
+// New returns the store rooted at root, a local directory or a URL such as
+// s3://bucket/codefang.
+func New(root string) *Store {
+	return &Store{root: root}
+}
+
+// Location returns where the report of run ns in format is kept.
+func (s *Store) Location(ns Namespace, format string) (string, error) {
+	if ns.Run == "" {
+		return "", fmt.Errorf("%w: %q has no run", ErrInvalidNamespace, ns)
+	}
+
+	if format != FormatJSON && format != FormatBin {
+		return "", fmt.Errorf("%w: %q (want %s or %s)", ErrUnsupportedFormat, format, FormatJSON, FormatBin)
+	}
+
+	return storage.Join(s.root, ns.Org, ns.Repo, ns.Run, "report."+format), nil
+}
+
+// Prepare creates the local directory of the report of run ns, so that the
+// report can be written to Location. It does nothing for object storage.
+func (s *Store) Prepare(ns Namespace) error {
+	return storage.MkdirAll(storage.Join(s.root, ns.Org, ns.Repo, ns.Run))
+}
+
+// Record adds the run of entry to the catalog, replacing an earlier entry of
+// the same namespace. The catalog is read and rewritten, so runs recorded
+// at the same moment may lose one of the entries; their reports are kept.
+func (s *Store) Record(entry Entry) error {
+	runs, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if entry.Path == "" {
+		entry.Path = strings.Join([]string{entry.Org, entry.Repo, entry.Run, "report." + entry.Format}, "/")
+	}
+
+	runs = slices.DeleteFunc(runs, func(e Entry) bool { return e.Namespace() == entry.Namespace() })
+	runs = append(runs, entry)
+
+	return s.save(runs)
+}
+
+// List returns the stored runs within filter, by org, repo and creation
+// time. An empty Org, Repo or Run in filter matches every value.
+func (s *Store) List(filter Namespace) ([]Entry, error) {
+	runs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(runs, func(e Entry) bool {
+		return filter.Org != "" && e.Org != filter.Org ||
+			filter.Repo != "" && e.Repo != filter.Repo ||
+			filter.Run != "" && e.Run != filter.Run
+	}), nil
+}
+
+// Find returns the stored run ns, or the latest run of the repository when
+// ns has no run.
+func (s *Store) Find(ns Namespace) (Entry, error) {
+	runs, err := s.List(ns)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if len(runs) == 0 {
+		return Entry{}, fmt.Errorf("%w: %s", ErrRunNotFound, ns)
+	}
+
+	return runs[len(runs)-1], nil
+}
+
+// Policy decides which runs a store keeps. A run is kept when it is one of
+// the KeepLast latest runs of its repository or younger than MaxAge; a zero
+// field disables its rule, and a zero Policy keeps every run.
+type Policy struct {
+	KeepLast int
+	MaxAge   time.Duration
+}
+
+// Expired returns the runs policy does not keep at now.
+func (s *Store) Expired(policy Policy, now time.Time) ([]Entry, error) {
+	if policy.KeepLast <= 0 && policy.MaxAge <= 0 {
+		return nil, nil
+	}
+
+	runs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	sortEntries(runs)
+
+	var expired []Entry
+
+	// Runs are sorted oldest first within each repository, so the latest
+	// KeepLast runs of a repository are the last ones before the next.
+	for i, run := range runs {
+		newer := 0
+		for j := i + 1; j < len(runs) && runs[j].Org == run.Org && runs[j].Repo == run.Repo; j++ {
+			newer++
+		}
+
+		if policy.KeepLast > 0 && newer < policy.KeepLast {
+			continue
+		}
+
+		if policy.MaxAge > 0 && now.Sub(run.Created) < policy.MaxAge {
+			continue
+		}
+
+		expired = append(expired, run)
+	}
+
+	return expired, nil
+}
+
+// Remove deletes the reports of runs and drops them from the catalog. Runs
+// deleted before an error are dropped too.
+func (s *Store) Remove(runs []Entry) error {
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	removed := make(map[Namespace]bool, len(runs))
+
+	var removeErr error
+
+	for _, run := range runs {
+		location := s.ReportLocation(run)
+		if !storage.IsRemote(s.root) {
+			location = storage.Join(s.root, run.Org, run.Repo, run.Run)
+		}
+
+		removeErr = storage.Remove(location)
+		if removeErr != nil {
+			break
+		}
+
+		removed[run.Namespace()] = true
+	}
+
+	all = slices.DeleteFunc(all, func(e Entry) bool { return removed[e.Namespace()] })
+
+	return errors.Join(removeErr, s.save(all))
+}
+
+// ReportLocation returns the location of the report of entry.
+func (s *Store) ReportLocation(entry Entry) string {
+	return storage.Join(s.root, strings.Split(entry.Path, "/")...)
+}
+
+// load reads the catalog. A store without one is empty.
+func (s *Store) load() ([]Entry, error) {
+	data, err := storage.ReadFile(storage.Join(s.root, CatalogFile))
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read catalog: %w", err)
+	}
+
+	var c catalog
+
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, fmt.Errorf("decode catalog: %w", err)
+	}
+
+	return c.Runs, nil
+}
+
+// save sorts runs and writes them as the catalog.
+func (s *Store) save(runs []Entry) error {
+	sortEntries(runs)
+
+	data, err := json.MarshalIndent(catalog{Version: CatalogVersion, Runs: runs}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode catalog: %w", err)
+	}
+
+	err = storage.MkdirAll(s.root)
+	if err != nil {
+		return err
+	}
+
+	err = storage.WriteFile(storage.Join(s.root, CatalogFile), append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("write catalog: %w", err)
+	}
+
+	return nil
+}
+
+func sortEntries(runs []Entry) {
+	slices.SortStableFunc(runs, func(a, b Entry) int {
+		return cmp.Or(
+			cmp.Compare(a.Org, b.Org),
+			cmp.Compare(a.Repo, b.Repo),
+			a.Created.Compare(b.Created),
+			cmp.Compare(a.Run, b.Run),
+		)
+	})
+}
@@ -0,0 +1,164 @@
+package reportstore_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/reportstore"
+)
+
+func TestParseNamespace(t *testing.T) {
+	t.Parallel()
+
+	ns, err := reportstore.ParseNamespace("acme/api")
+	require.NoError(t, err)
+	assert.Equal(t, reportstore.Namespace{Org: "acme", Repo: "api"}, ns)
+
+	ns, err = reportstore.ParseNamespace("acme/api/nightly-42")
+	require.NoError(t, err)
+	assert.Equal(t, "acme/api/nightly-42", ns.String())
+
+	for _, bad := range []string{"acme", "acme//x", "acme/../x", "a/b/c/d", `acme/a\b`} {
+		_, err = reportstore.ParseNamespace(bad)
+		require.ErrorIs(t, err, reportstore.ErrInvalidNamespace, bad)
+	}
+}
+
+func TestStore_RecordListFind(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	store := reportstore.New(root)
+
+	runs, err := store.List(reportstore.Namespace{})
+	require.NoError(t, err)
+	assert.Empty(t, runs, "a new store is empty")
+
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, ns := range []reportstore.Namespace{
+		{Org: "acme", Repo: "api", Run: reportstore.NewRunID(start.Add(time.Hour))},
+		{Org: "acme", Repo: "api", Run: reportstore.NewRunID(start)},
+		{Org: "acme", Repo: "web", Run: "r1"},
+		{Org: "globex", Repo: "api", Run: "r1"},
+	} {
+		location, locErr := store.Location(ns, reportstore.FormatJSON)
+		require.NoError(t, locErr)
+		require.NoError(t, store.Prepare(ns))
+		require.NoError(t, os.WriteFile(location, []byte(`{}`), 0o600))
+
+		require.NoError(t, store.Record(reportstore.Entry{
+			Org: ns.Org, Repo: ns.Repo, Run: ns.Run, Format: reportstore.FormatJSON,
+			Created: start.Add(time.Duration(3-i) * time.Minute),
+		}))
+	}
+
+	assert.FileExists(t, filepath.Join(root, reportstore.CatalogFile))
+
+	runs, err = store.List(reportstore.Namespace{Org: "acme", Repo: "api"})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "20261001T120000Z", runs[0].Run, "runs are ordered by creation")
+
+	latest, err := store.Find(reportstore.Namespace{Org: "acme", Repo: "api"})
+	require.NoError(t, err)
+	assert.Equal(t, "20261001T130000Z", latest.Run)
+	assert.Equal(t, filepath.Join(root, "acme", "api", "20261001T130000Z", "report.json"), store.ReportLocation(latest))
+	assert.FileExists(t, store.ReportLocation(latest))
+
+	runs, err = store.List(reportstore.Namespace{Org: "acme"})
+	require.NoError(t, err)
+	assert.Len(t, runs, 3)
+
+	_, err = store.Find(reportstore.Namespace{Org: "acme", Repo: "api", Run: "missing"})
+	require.ErrorIs(t, err, reportstore.ErrRunNotFound)
+}
+
+func TestStore_RecordReplaces(t *testing.T) {
+	t.Parallel()
+
+	store := reportstore.New(t.TempDir())
+	entry := reportstore.Entry{Org: "acme", Repo: "api", Run: "r1", Format: reportstore.FormatJSON}
+
+	require.NoError(t, store.Record(entry))
+
+	entry.Format = reportstore.FormatBin
+	require.NoError(t, store.Record(entry))
+
+	runs, err := store.List(reportstore.Namespace{})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "acme/api/r1/report.bin", runs[0].Path)
+}
+
+func TestStore_Location(t *testing.T) {
+	t.Parallel()
+
+	store := reportstore.New("s3://bucket/codefang")
+
+	location, err := store.Location(reportstore.Namespace{Org: "acme", Repo: "api", Run: "r1"}, reportstore.FormatBin)
+	require.NoError(t, err)
+	assert.Equal(t, "s3://bucket/codefang/acme/api/r1/report.bin", location)
+
+	_, err = store.Location(reportstore.Namespace{Org: "acme", Repo: "api", Run: "r1"}, "text")
+	require.ErrorIs(t, err, reportstore.ErrUnsupportedFormat)
+
+	_, err = store.Location(reportstore.Namespace{Org: "acme", Repo: "api"}, reportstore.FormatJSON)
+	require.ErrorIs(t, err, reportstore.ErrInvalidNamespace)
+}
+
+func TestStore_ExpiredAndRemove(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	store := reportstore.New(root)
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	for _, run := range []struct {
+		repo string
+		age  int
+	}{{"api", 200}, {"api", 100}, {"api", 50}, {"api", 1}, {"web", 300}} {
+		ns := reportstore.Namespace{Org: "acme", Repo: run.repo, Run: fmt.Sprintf("%s-%d", run.repo, run.age)}
+		require.NoError(t, store.Prepare(ns))
+
+		location, err := store.Location(ns, reportstore.FormatJSON)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(location, []byte(`{}`), 0o600))
+		require.NoError(t, store.Record(reportstore.Entry{
+			Org: ns.Org, Repo: ns.Repo, Run: ns.Run, Format: reportstore.FormatJSON,
+			Created: now.Add(-time.Duration(run.age) * 24 * time.Hour),
+		}))
+	}
+
+	expired, err := store.Expired(reportstore.Policy{}, now)
+	require.NoError(t, err)
+	assert.Empty(t, expired, "a zero policy keeps everything")
+
+	expired, err = store.Expired(reportstore.Policy{KeepLast: 1, MaxAge: 90 * 24 * time.Hour}, now)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(expired))
+	for _, run := range expired {
+		names = append(names, run.Run)
+	}
+
+	assert.Equal(t, []string{"api-200", "api-100"}, names, "young runs and the latest run of a repository stay")
+
+	expired, err = store.Expired(reportstore.Policy{KeepLast: 2}, now)
+	require.NoError(t, err)
+	assert.Len(t, expired, 2)
+
+	require.NoError(t, store.Remove(expired))
+	assert.NoDirExists(t, filepath.Join(root, "acme", "api", "api-200"))
+	assert.DirExists(t, filepath.Join(root, "acme", "api", "api-50"))
+
+	runs, err := store.List(reportstore.Namespace{})
+	require.NoError(t, err)
+	assert.Len(t, runs, 3)
+}
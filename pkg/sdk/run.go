@@ -0,0 +1,119 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+)
+
+// Step is one commit fed to Run. Tick and AuthorID stand in for the values
+// the pipeline's plumbing analyzers would assign to the commit.
+type Step struct {
+	Context  *Context
+	Tick     int
+	AuthorID int
+}
+
+// Result holds everything Run observed, for assertions in tests.
+type Result struct {
+	// TCs are the non-empty per-commit results, stamped as the pipeline
+	// stamps them.
+	TCs []TC
+	// Ticks are the aggregated TICKs in ascending tick order.
+	Ticks []TICK
+	// Report is the analyzer's report from Ticks, or an empty report for
+	// analyzers without an aggregator.
+	Report Report
+}
+
+// Run drives a single configured analyzer through steps the way the pipeline
+// does: Initialize (without a repository), Consume per step, aggregation of
+// the TCs and ReportFromTICKs. It runs sequentially, with no plumbing
+// analyzers, so steps must carry whatever Context data the analyzer reads.
+func Run(ctx context.Context, a HistoryAnalyzer, steps []Step, opts AggregatorOptions) (Result, error) {
+	err := a.Initialize(nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("initialize %s: %w", a.Name(), err)
+	}
+
+	agg := a.NewAggregator(opts)
+	if agg != nil {
+		defer agg.Close()
+	}
+
+	var result Result
+
+	for i, step := range steps {
+		ac := step.Context
+		if ac == nil {
+			ac = &Context{Index: i}
+		}
+
+		tc, consumeErr := a.Consume(ctx, ac)
+		if consumeErr != nil {
+			return Result{}, fmt.Errorf("consume step %d: %w", i, consumeErr)
+		}
+
+		if tc.Data == nil {
+			continue
+		}
+
+		tc.Tick = step.Tick
+		tc.AuthorID = step.AuthorID
+		tc.Timestamp = ac.Time
+
+		if ac.Commit != nil {
+			tc.CommitHash = ac.Commit.Hash()
+		}
+
+		result.TCs = append(result.TCs, tc)
+
+		if agg == nil {
+			continue
+		}
+
+		addErr := agg.Add(tc)
+		if addErr != nil {
+			return Result{}, fmt.Errorf("aggregate step %d: %w", i, addErr)
+		}
+	}
+
+	if agg == nil {
+		result.Report = Report{}
+
+		return result, nil
+	}
+
+	result.Ticks, err = ticks(agg)
+	if err != nil {
+		return Result{}, fmt.Errorf("aggregate %s: %w", a.Name(), err)
+	}
+
+	result.Report, err = a.ReportFromTICKs(ctx, result.Ticks)
+	if err != nil {
+		return Result{}, fmt.Errorf("report %s: %w", a.Name(), err)
+	}
+
+	return result, nil
+}
+
+// ticks returns all TICKs of agg in ascending tick order.
+func ticks(agg Aggregator) ([]TICK, error) {
+	if streamer, ok := agg.(TickStreamer); ok {
+		var all []TICK
+
+		err := streamer.StreamTicks(func(t TICK) error {
+			all = append(all, t)
+
+			return nil
+		})
+
+		return all, err
+	}
+
+	err := agg.Collect()
+	if err != nil {
+		return nil, err
+	}
+
+	return agg.FlushAllTicks()
+}
@@ -0,0 +1,109 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+	"github.com/Sumatoshi-tech/codefang/pkg/sdk"
+)
+
+var errConsume = errors.New("consume failed")
+
+// commitCounter counts merge-free commits per tick, using only the SDK.
+type commitCounter struct {
+	sdk.BaseHistoryAnalyzer[map[string]int]
+
+	fail bool
+}
+
+func newCommitCounter() *commitCounter {
+	c := &commitCounter{}
+	c.Desc = sdk.Descriptor{ID: "history/commit-counter", Mode: sdk.ModeHistory}
+	c.TicksToReportFn = func(_ context.Context, ticks []sdk.TICK) sdk.Report {
+		perTick := make(map[int]int, len(ticks))
+		for _, t := range ticks {
+			perTick[t.Tick] = t.Data.(int)
+		}
+
+		return sdk.Report{"per_tick": perTick}
+	}
+
+	return c
+}
+
+func (c *commitCounter) Initialize(_ *sdk.Repository) error { return nil }
+
+func (c *commitCounter) Consume(_ context.Context, ac *sdk.Context) (sdk.TC, error) {
+	if c.fail {
+		return sdk.TC{}, errConsume
+	}
+
+	if ac.IsMerge {
+		return sdk.TC{}, nil
+	}
+
+	return sdk.TC{Data: 1}, nil
+}
+
+func (c *commitCounter) NewAggregator(opts sdk.AggregatorOptions) sdk.Aggregator {
+	return sdk.NewGenericAggregator[int, int](opts,
+		func(tc sdk.TC, byTick map[int]int) error {
+			byTick[tc.Tick] += tc.Data.(int)
+
+			return nil
+		},
+		func(a, b int) int { return a + b },
+		func(int) int64 { return 8 },
+		func(tick, n int) (sdk.TICK, error) { return sdk.TICK{Tick: tick, Data: n}, nil },
+	)
+}
+
+func (c *commitCounter) Fork(n int) []sdk.HistoryAnalyzer {
+	forks := make([]sdk.HistoryAnalyzer, n)
+	for i := range forks {
+		forks[i] = newCommitCounter()
+	}
+
+	return forks
+}
+
+func (c *commitCounter) Merge(_ []sdk.HistoryAnalyzer) {}
+
+func TestRun_AggregatesTicks(t *testing.T) {
+	t.Parallel()
+
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	hash := gitlib.NewHash("1111111111111111111111111111111111111111")
+
+	result, err := sdk.Run(context.Background(), newCommitCounter(), []sdk.Step{
+		{Context: &sdk.Context{Time: when, Commit: gitlib.NewCommitForTest(hash)}, Tick: 0, AuthorID: 7},
+		{Context: &sdk.Context{Time: when}, Tick: 0},
+		{Context: &sdk.Context{IsMerge: true}, Tick: 1},
+		{Tick: 2},
+	}, sdk.AggregatorOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, result.TCs, 3)
+	assert.Equal(t, hash, result.TCs[0].CommitHash)
+	assert.Equal(t, 7, result.TCs[0].AuthorID)
+	assert.Equal(t, when, result.TCs[0].Timestamp)
+
+	require.Len(t, result.Ticks, 2)
+	assert.Equal(t, sdk.Report{"per_tick": map[int]int{0: 2, 2: 1}}, result.Report)
+}
+
+func TestRun_ConsumeError(t *testing.T) {
+	t.Parallel()
+
+	c := newCommitCounter()
+	c.fail = true
+
+	_, err := sdk.Run(context.Background(), c, []sdk.Step{{}}, sdk.AggregatorOptions{})
+	require.ErrorIs(t, err, errConsume)
+}
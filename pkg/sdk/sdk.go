@@ -0,0 +1,96 @@
+// Package sdk is the supported API for writing history analyzers outside this
+// repository. It re-exports the analyzer contract from pkg/analyzers/analyze
+// under names that follow semantic versioning, and provides Run, a minimal
+// runner for unit-testing an analyzer without a git repository.
+//
+// The types are aliases, so an analyzer written against this package plugs
+// into the codefang pipeline unchanged.
+package sdk
+
+import (
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/analyze"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/plumbing"
+	"github.com/Sumatoshi-tech/codefang/pkg/gitlib"
+)
+
+// Analyzer contract.
+type (
+	// HistoryAnalyzer consumes commits one by one and emits a TC per commit.
+	HistoryAnalyzer = analyze.HistoryAnalyzer
+	// Parallelizable is implemented by analyzers that can run on forked workers.
+	Parallelizable = analyze.Parallelizable
+	// CommitPreparer is implemented by analyzers that can prepare a commit ahead of Consume.
+	CommitPreparer = analyze.CommitPreparer
+	// Descriptor holds the stable ID, description and mode of an analyzer.
+	Descriptor = analyze.Descriptor
+	// Context is the per-commit input of Consume.
+	Context = analyze.Context
+	// CommitLike is the commit accessor exposed on Context.
+	CommitLike = analyze.CommitLike
+	// Report is the final output of an analyzer.
+	Report = analyze.Report
+	// Repository is the repository handle passed to Initialize.
+	Repository = gitlib.Repository
+	// Hash is a git object hash.
+	Hash = gitlib.Hash
+	// Capabilities declares which plumbing data an analyzer reads.
+	Capabilities = analyze.Capabilities
+	// CapabilityProvider is implemented by analyzers that declare Capabilities.
+	CapabilityProvider = analyze.CapabilityProvider
+	// MemoryClass ranks how much state an analyzer keeps.
+	MemoryClass = analyze.MemoryClass
+)
+
+// Snapshot is the plumbing output of one commit that Parallelizable leaves
+// hand to their forks. Custom plumbing output travels in its Custom field.
+type Snapshot = plumbing.Snapshot
+
+// Aggregation.
+type (
+	// TC is the per-commit result of Consume.
+	TC = analyze.TC
+	// TICK is the aggregated result of one time bucket.
+	TICK = analyze.TICK
+	// Aggregator collects TCs into TICKs.
+	Aggregator = analyze.Aggregator
+	// AggregatorOptions configures an Aggregator.
+	AggregatorOptions = analyze.AggregatorOptions
+	// TickStreamer is implemented by aggregators that merge spilled ticks in order.
+	TickStreamer = analyze.TickStreamer
+)
+
+// BaseHistoryAnalyzer implements the metadata, configuration and serialization
+// parts of HistoryAnalyzer. Embed it and add Initialize, Consume,
+// NewAggregator, Fork and Merge.
+type BaseHistoryAnalyzer[M any] = analyze.BaseHistoryAnalyzer[M]
+
+// GenericAggregator is an Aggregator over per-tick states of type S that
+// spills to disk when AggregatorOptions.SpillBudget is exceeded.
+type GenericAggregator[S, T any] = analyze.GenericAggregator[S, T]
+
+// NewGenericAggregator creates a GenericAggregator from its hooks: extract
+// folds a TC into the per-tick states, merge combines two states of a tick,
+// size estimates a state in bytes and build turns a state into a TICK.
+func NewGenericAggregator[S, T any](
+	opts AggregatorOptions,
+	extract func(TC, map[int]S) error,
+	merge func(S, S) S,
+	size func(S) int64,
+	build func(int, S) (TICK, error),
+) *GenericAggregator[S, T] {
+	return analyze.NewGenericAggregator[S, T](opts, extract, merge, size, build)
+}
+
+// ModeHistory is the Descriptor mode of history analyzers.
+const ModeHistory = analyze.ModeHistory
+
+// Memory classes.
+const (
+	MemoryLow    = analyze.MemoryLow
+	MemoryMedium = analyze.MemoryMedium
+	MemoryHigh   = analyze.MemoryHigh
+)
+
+// ErrNotImplemented is returned by optional HistoryAnalyzer methods an
+// analyzer does not support.
+var ErrNotImplemented = analyze.ErrNotImplemented
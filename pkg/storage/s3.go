@@ -0,0 +1,296 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SchemeS3 is the URL scheme of S3 locations: s3://bucket/key.
+const SchemeS3 = "s3"
+
+// S3 defaults.
+const (
+	defaultS3Region  = "us-east-1"
+	defaultS3Timeout = 5 * time.Minute
+)
+
+// s3ErrorBodyLimit bounds how much of an error response is quoted.
+const s3ErrorBodyLimit = 512
+
+// ErrS3Status is returned when S3 answers with an unexpected status.
+var ErrS3Status = errors.New("s3 request failed")
+
+// S3 stores objects in Amazon S3 or an S3-compatible service such as MinIO.
+// Requests are signed with AWS Signature Version 4; without credentials they
+// are sent unsigned, which works for public buckets only.
+type S3 struct {
+	// Endpoint is the base URL of an S3-compatible service, addressed with
+	// path-style URLs. Empty means AWS, addressed with virtual-hosted URLs.
+	Endpoint string
+	// Region is the signing region. Empty means us-east-1.
+	Region string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Client sends the requests. Nil means a client with a 5 minute timeout.
+	Client *http.Client
+}
+
+// S3FromEnv configures S3 from the standard AWS environment variables:
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION
+// (or AWS_DEFAULT_REGION) and AWS_ENDPOINT_URL_S3 (or AWS_ENDPOINT_URL).
+func S3FromEnv() *S3 {
+	return &S3{
+		Endpoint:        firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"),
+		Region:          firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// envS3 is the default s3 backend. It reads the environment on every
+// request, so credentials set after startup are picked up.
+type envS3 struct{}
+
+func (envS3) Get(location *url.URL) (io.ReadCloser, error) { return S3FromEnv().Get(location) }
+
+func (envS3) Put(location *url.URL, body []byte) error { return S3FromEnv().Put(location, body) }
+
+func (envS3) Remove(location *url.URL) error { return S3FromEnv().Remove(location) }
+
+// Get downloads the object at s3://bucket/key.
+func (s *S3) Get(location *url.URL) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return resp.Body, nil
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	return nil, statusError(resp)
+}
+
+// Put uploads body as the object at s3://bucket/key. The content type is
+// guessed from the key's extension, so uploaded HTML and JSON are served
+// with the right type.
+func (s *S3) Put(location *url.URL, body []byte) error {
+	resp, err := s.do(http.MethodPut, location, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // drained so the connection is reused.
+
+	return nil
+}
+
+// Remove deletes the object at s3://bucket/key. S3 answers a delete of a
+// missing object with success too.
+func (s *S3) Remove(location *url.URL) error {
+	resp, err := s.do(http.MethodDelete, location, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK &&
+		resp.StatusCode != http.StatusNotFound {
+		return statusError(resp)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // drained so the connection is reused.
+
+	return nil
+}
+
+func (s *S3) do(method string, location *url.URL, body []byte) (*http.Response, error) {
+	bucket := location.Host
+	key := strings.TrimPrefix(location.Path, "/")
+
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("%w: %s (want s3://bucket/key)", ErrInvalidLocation, location.Redacted())
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, s.objectURL(bucket, key), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create s3 request: %w", err)
+	}
+
+	if method == http.MethodPut {
+		contentType := mime.TypeByExtension(path.Ext(key))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if s.AccessKeyID != "" {
+		s.sign(req, hashHex(body), time.Now())
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultS3Timeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s: %w", strings.ToLower(method), err)
+	}
+
+	return resp, nil
+}
+
+// objectURL addresses an object path-style on a custom endpoint and
+// virtual-hosted-style on AWS.
+func (s *S3) objectURL(bucket, key string) string {
+	escaped := escapeS3Key(key)
+
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/") + "/" + bucket + "/" + escaped
+	}
+
+	return "https://" + bucket + ".s3." + s.region() + ".amazonaws.com/" + escaped
+}
+
+func (s *S3) region() string {
+	if s.Region == "" {
+		return defaultS3Region
+	}
+
+	return s.Region
+}
+
+// sign adds the AWS Signature Version 4 headers to req. Every header already
+// set on req is signed, along with the host.
+func (s *S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:len("20060102")]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := slices.Sorted(maps.Keys(headers))
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.region() + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), day)
+	key = hmacSHA256(key, s.region())
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		s.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))))
+}
+
+// escapeS3Key percent-encodes everything in key but unreserved characters
+// and slashes, as Signature Version 4 expects.
+func escapeS3Key(key string) string {
+	var escaped strings.Builder
+
+	for i := range len(key) {
+		c := key[i]
+
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/':
+			escaped.WriteByte(c)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+
+	return escaped.String()
+}
+
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, s3ErrorBodyLimit)) //nolint:errcheck // best-effort detail.
+
+	return fmt.Errorf("%w: status %d: %s", ErrS3Status, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,249 @@
+// Package storage reads and writes reports and output files on local disk or
+// in object storage.
+//
+// A location is either a local path or a URL such as s3://bucket/reports/a.json.
+// The URL scheme selects the Backend registered for it; s3 is registered by
+// default. Remote objects are written in one request when their writer is
+// closed, so nothing touches the local disk.
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Backend stores objects of one URL scheme.
+type Backend interface {
+	// Get opens the object at location.
+	Get(location *url.URL) (io.ReadCloser, error)
+	// Put replaces the object at location with body.
+	Put(location *url.URL, body []byte) error
+}
+
+// Remover is implemented by backends that can delete objects.
+type Remover interface {
+	// Remove deletes the object at location. A missing object is not an error.
+	Remove(location *url.URL) error
+}
+
+var (
+	// ErrUnknownScheme is returned for a URL whose scheme has no Backend.
+	ErrUnknownScheme = errors.New("unknown storage scheme")
+	// ErrNotFound is returned by backends for a missing object.
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidLocation is returned for a URL without a bucket or key.
+	ErrInvalidLocation = errors.New("invalid storage location")
+	// ErrRemoveUnsupported is returned by Remove for a backend without Remover.
+	ErrRemoveUnsupported = errors.New("storage backend cannot remove objects")
+)
+
+// localDirPerm is the permission of directories created by MkdirAll.
+const localDirPerm = 0o750
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Backend{SchemeS3: envS3{}}
+)
+
+// Register makes backend serve the locations of scheme, replacing any
+// backend registered for it before.
+func Register(scheme string, backend Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	backends[scheme] = backend
+}
+
+// IsRemote reports whether location is a URL rather than a local path.
+func IsRemote(location string) bool {
+	scheme, _, found := strings.Cut(location, "://")
+
+	return found && scheme != "" && !strings.ContainsAny(scheme, `/\`)
+}
+
+// Open opens location for reading.
+func Open(location string) (io.ReadCloser, error) {
+	if !IsRemote(location) {
+		file, err := os.Open(location)
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", location, err)
+		}
+
+		return file, nil
+	}
+
+	backend, parsed, err := resolve(location)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := backend.Get(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", location, err)
+	}
+
+	return body, nil
+}
+
+// ReadFile reads the whole of location.
+func ReadFile(location string) ([]byte, error) {
+	body, err := Open(location)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", location, err)
+	}
+
+	return data, nil
+}
+
+// Create creates or truncates location for writing. A remote object is
+// buffered in memory and uploaded when the writer is closed, so callers must
+// check the error of Close.
+func Create(location string) (io.WriteCloser, error) {
+	if !IsRemote(location) {
+		file, err := os.Create(location)
+		if err != nil {
+			return nil, fmt.Errorf("create %s: %w", location, err)
+		}
+
+		return file, nil
+	}
+
+	backend, parsed, err := resolve(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return &objectWriter{backend: backend, location: parsed}, nil
+}
+
+// WriteFile replaces location with data.
+func WriteFile(location string, data []byte) error {
+	writer, err := Create(location)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(data)
+	if err != nil {
+		writer.Close()
+
+		return fmt.Errorf("write %s: %w", location, err)
+	}
+
+	return writer.Close()
+}
+
+// Remove deletes location: a local file or directory with everything in
+// it, or a remote object. A missing location is not an error.
+func Remove(location string) error {
+	if !IsRemote(location) {
+		err := os.RemoveAll(location)
+		if err != nil {
+			return fmt.Errorf("remove %s: %w", location, err)
+		}
+
+		return nil
+	}
+
+	backend, parsed, err := resolve(location)
+	if err != nil {
+		return err
+	}
+
+	remover, ok := backend.(Remover)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRemoveUnsupported, location)
+	}
+
+	err = remover.Remove(parsed)
+	if err != nil {
+		return fmt.Errorf("remove %s: %w", location, err)
+	}
+
+	return nil
+}
+
+// MkdirAll creates a local directory and its parents. Object storage has
+// no directories, so it does nothing for remote locations.
+func MkdirAll(dir string) error {
+	if IsRemote(dir) {
+		return nil
+	}
+
+	err := os.MkdirAll(dir, localDirPerm)
+	if err != nil {
+		return fmt.Errorf("create directory %s: %w", dir, err)
+	}
+
+	return nil
+}
+
+// Join joins path elements to location, with slashes for URLs and the
+// separator of the OS for local paths.
+func Join(location string, elem ...string) string {
+	if !IsRemote(location) {
+		return filepath.Join(append([]string{location}, elem...)...)
+	}
+
+	scheme, rest, _ := strings.Cut(location, "://")
+
+	return scheme + "://" + path.Join(append([]string{rest}, elem...)...)
+}
+
+func resolve(location string) (Backend, *url.URL, error) {
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %w", ErrInvalidLocation, location, err)
+	}
+
+	backendsMu.RLock()
+	backend, found := backends[parsed.Scheme]
+	backendsMu.RUnlock()
+
+	if !found {
+		return nil, nil, fmt.Errorf("%w: %q in %s", ErrUnknownScheme, parsed.Scheme, location)
+	}
+
+	return backend, parsed, nil
+}
+
+// objectWriter buffers a remote object until Close uploads it.
+type objectWriter struct {
+	backend  Backend
+	location *url.URL
+	buf      bytes.Buffer
+	closed   bool
+}
+
+func (w *objectWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *objectWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+
+	err := w.backend.Put(w.location, w.buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("put %s: %w", w.location.Redacted(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package storage_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/storage"
+)
+
+// memBackend keeps objects in memory.
+type memBackend struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memBackend) Get(location *url.URL) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, found := m.objects[location.String()]
+	if !found {
+		return nil, storage.ErrNotFound
+	}
+
+	return io.NopCloser(strings.NewReader(string(data))), nil
+}
+
+func (m *memBackend) Put(location *url.URL, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[location.String()] = append([]byte(nil), body...)
+
+	return nil
+}
+
+func TestIsRemote(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, storage.IsRemote("s3://bucket/key"))
+	assert.False(t, storage.IsRemote("reports/out.json"))
+	assert.False(t, storage.IsRemote("/tmp/a://b"))
+	assert.False(t, storage.IsRemote(`C:\reports\out.json`))
+}
+
+func TestJoin(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "s3://bucket/runs/graph/a.gexf", storage.Join("s3://bucket/runs/", "graph", "a.gexf"))
+	assert.Equal(t, filepath.Join("out", "a.json"), storage.Join("out", "a.json"))
+}
+
+func TestLocalRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := storage.Join(t.TempDir(), "nested")
+	require.NoError(t, storage.MkdirAll(dir))
+
+	location := storage.Join(dir, "report.json")
+	require.NoError(t, storage.WriteFile(location, []byte(`{"a":1}`)))
+
+	data, err := storage.ReadFile(location)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":1}`, string(data))
+
+	_, err = storage.ReadFile(storage.Join(dir, "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, storage.Remove(dir))
+	assert.NoDirExists(t, dir)
+	require.NoError(t, storage.Remove(dir), "a missing location is not an error")
+}
+
+func TestRemoteRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	backend := &memBackend{objects: make(map[string][]byte)}
+	storage.Register("memtest", backend)
+
+	require.NoError(t, storage.MkdirAll("memtest://bucket/site"))
+
+	writer, err := storage.Create("memtest://bucket/site/index.html")
+	require.NoError(t, err)
+
+	_, err = io.WriteString(writer, "<html>")
+	require.NoError(t, err)
+
+	_, err = storage.ReadFile("memtest://bucket/site/index.html")
+	require.ErrorIs(t, err, storage.ErrNotFound, "objects appear on Close")
+
+	require.NoError(t, writer.Close())
+
+	data, err := storage.ReadFile("memtest://bucket/site/index.html")
+	require.NoError(t, err)
+	assert.Equal(t, "<html>", string(data))
+
+	_, err = storage.Create("gopher://bucket/key")
+	require.ErrorIs(t, err, storage.ErrUnknownScheme)
+
+	require.ErrorIs(t, storage.Remove("memtest://bucket/site/index.html"), storage.ErrRemoveUnsupported)
+}
+
+func TestS3_PutGet(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		objects = make(map[string][]byte)
+		types   = make(map[string]string)
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") ||
+			r.Header.Get("X-Amz-Security-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body) //nolint:errcheck // test server.
+			objects[r.URL.Path] = body
+			types[r.URL.Path] = r.Header.Get("Content-Type")
+		case http.MethodGet:
+			body, found := objects[r.URL.Path]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write(body) //nolint:errcheck // test server.
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	s3 := &storage.S3{Endpoint: server.URL, AccessKeyID: "key", SecretAccessKey: "secret", SessionToken: "token"}
+	location, err := url.Parse("s3://reports/runs/index.html")
+	require.NoError(t, err)
+
+	require.NoError(t, s3.Put(location, []byte("<html>")))
+	assert.Equal(t, "text/html; charset=utf-8", types["/reports/runs/index.html"])
+
+	body, err := s3.Get(location)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	assert.Equal(t, "<html>", string(data))
+
+	missing, err := url.Parse("s3://reports/runs/missing.json")
+	require.NoError(t, err)
+
+	_, err = s3.Get(missing)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, s3.Remove(location))
+
+	_, err = s3.Get(location)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	anonymous := &storage.S3{Endpoint: server.URL}
+	require.ErrorIs(t, anonymous.Put(location, nil), storage.ErrS3Status)
+
+	noKey, err := url.Parse("s3://reports")
+	require.NoError(t, err)
+	require.ErrorIs(t, s3.Put(noKey, nil), storage.ErrInvalidLocation)
+}
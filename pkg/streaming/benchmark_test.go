@@ -27,7 +27,7 @@ func BenchmarkCheckpointSave_Burndown(b *testing.B) {
 	b.ReportAllocs()
 
 	for b.Loop() {
-		err = analyzer.SaveCheckpoint(dir)
+		err = analyzer.SaveCheckpoint(dir, nil)
 		if err != nil {
 			b.Fatalf("SaveCheckpoint failed: %v", err)
 		}
@@ -46,7 +46,7 @@ func BenchmarkCheckpointSave_Couples(b *testing.B) {
 	b.ReportAllocs()
 
 	for b.Loop() {
-		err = analyzer.SaveCheckpoint(dir)
+		err = analyzer.SaveCheckpoint(dir, nil)
 		if err != nil {
 			b.Fatalf("SaveCheckpoint failed: %v", err)
 		}
@@ -65,7 +65,7 @@ func BenchmarkCheckpointSave_FileHistory(b *testing.B) {
 	b.ReportAllocs()
 
 	for b.Loop() {
-		err = analyzer.SaveCheckpoint(dir)
+		err = analyzer.SaveCheckpoint(dir, nil)
 		if err != nil {
 			b.Fatalf("SaveCheckpoint failed: %v", err)
 		}
@@ -87,7 +87,7 @@ func BenchmarkCheckpointLoad_Burndown(b *testing.B) {
 		b.Fatalf("Initialize failed: %v", err)
 	}
 
-	err = analyzer.SaveCheckpoint(dir)
+	err = analyzer.SaveCheckpoint(dir, nil)
 	if err != nil {
 		b.Fatalf("SaveCheckpoint failed: %v", err)
 	}
@@ -97,7 +97,7 @@ func BenchmarkCheckpointLoad_Burndown(b *testing.B) {
 	for b.Loop() {
 		loaded := &burndown.HistoryAnalyzer{}
 
-		err = loaded.LoadCheckpoint(dir)
+		err = loaded.LoadCheckpoint(dir, nil)
 		if err != nil {
 			b.Fatalf("LoadCheckpoint failed: %v", err)
 		}
@@ -113,7 +113,7 @@ func BenchmarkCheckpointLoad_Couples(b *testing.B) {
 		b.Fatalf("Initialize failed: %v", err)
 	}
 
-	err = analyzer.SaveCheckpoint(dir)
+	err = analyzer.SaveCheckpoint(dir, nil)
 	if err != nil {
 		b.Fatalf("SaveCheckpoint failed: %v", err)
 	}
@@ -123,7 +123,7 @@ func BenchmarkCheckpointLoad_Couples(b *testing.B) {
 	for b.Loop() {
 		loaded := &couples.HistoryAnalyzer{}
 
-		err = loaded.LoadCheckpoint(dir)
+		err = loaded.LoadCheckpoint(dir, nil)
 		if err != nil {
 			b.Fatalf("LoadCheckpoint failed: %v", err)
 		}
@@ -139,7 +139,7 @@ func BenchmarkCheckpointLoad_FileHistory(b *testing.B) {
 		b.Fatalf("Initialize failed: %v", err)
 	}
 
-	err = analyzer.SaveCheckpoint(dir)
+	err = analyzer.SaveCheckpoint(dir, nil)
 	if err != nil {
 		b.Fatalf("SaveCheckpoint failed: %v", err)
 	}
@@ -149,7 +149,7 @@ func BenchmarkCheckpointLoad_FileHistory(b *testing.B) {
 	for b.Loop() {
 		loaded := filehistory.NewAnalyzer()
 
-		err = loaded.LoadCheckpoint(dir)
+		err = loaded.LoadCheckpoint(dir, nil)
 		if err != nil {
 			b.Fatalf("LoadCheckpoint failed: %v", err)
 		}
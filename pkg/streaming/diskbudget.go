@@ -0,0 +1,199 @@
+package streaming
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Disk budget constants.
+const (
+	// DiskWarningRatio is the fraction of the disk budget at which a warning
+	// is logged.
+	DiskWarningRatio = 0.80
+
+	// MinFreeDiskBytes is the free space kept in reserve on every filesystem
+	// holding spill or checkpoint files.
+	MinFreeDiskBytes = 256 * mib
+)
+
+// Sentinel errors for disk budget enforcement.
+var (
+	// ErrDiskBudgetExceeded indicates spill and checkpoint files outgrew the disk budget.
+	ErrDiskBudgetExceeded = errors.New("disk budget exceeded")
+	// ErrDiskSpaceLow indicates a spill filesystem would drop below MinFreeDiskBytes.
+	ErrDiskSpaceLow = errors.New("not enough free disk space for spill files")
+)
+
+// SpillReporter is an optional interface for analyzers that write spill
+// files themselves rather than through their aggregator. The disk monitor
+// counts their directories against the disk budget.
+type SpillReporter interface {
+	// SpillDirs returns the directories currently holding spill files.
+	SpillDirs() []string
+
+	// SpillBytesWritten returns the total size of the spill files written so
+	// far, including files already removed.
+	SpillBytesWritten() int64
+}
+
+// DiskUsage is the on-disk footprint of one analyzer's spills, or of the
+// checkpoint.
+type DiskUsage struct {
+	Owner   string
+	Dirs    []string
+	Bytes   int64 // size of Dirs now; filled in by DiskMonitor.Check.
+	Written int64 // total bytes written over the run.
+}
+
+// DiskStatus is the outcome of one DiskMonitor check.
+type DiskStatus struct {
+	Usage   []DiskUsage
+	Total   int64
+	Growth  int64 // growth of Total since the previous check.
+	Warning bool  // Total is above DiskWarningRatio of the budget, or the next chunk is expected to exceed it.
+}
+
+// DiskMonitor enforces a disk budget across spill, checkpoint and temporary
+// files. It is checked between chunks and fails the run with a descriptive
+// error before the budget is exceeded by much or a filesystem fills up,
+// rather than letting a write fail with ENOSPC halfway through a spill.
+type DiskMonitor struct {
+	// Budget is the maximum total bytes of spill and checkpoint files.
+	// Zero disables the budget but keeps the free space check.
+	Budget int64
+
+	// Roots are directories whose filesystems must keep MinFreeDiskBytes
+	// free even before anything is spilled there, such as the temp dir.
+	Roots []string
+
+	// FreeSpace returns the bytes available to unprivileged users on the
+	// filesystem holding dir. Nil means FreeDiskBytes.
+	FreeSpace func(dir string) (int64, bool)
+
+	lastTotal int64
+}
+
+// Check measures usage and compares it against the budget and the free space
+// of every filesystem involved. The projected growth of the next chunk is
+// the growth since the previous check.
+func (m *DiskMonitor) Check(usage []DiskUsage) (DiskStatus, error) {
+	status := DiskStatus{Usage: usage}
+
+	for i := range status.Usage {
+		status.Usage[i].Bytes = 0
+
+		for _, dir := range status.Usage[i].Dirs {
+			status.Usage[i].Bytes += DirSize(dir)
+		}
+
+		status.Total += status.Usage[i].Bytes
+	}
+
+	status.Growth = max(status.Total-m.lastTotal, 0)
+	m.lastTotal = status.Total
+
+	if m.Budget > 0 {
+		if status.Total > m.Budget {
+			return status, fmt.Errorf("%w: spill and checkpoint files use %s of %s (%s)",
+				ErrDiskBudgetExceeded, humanize.IBytes(uint64(status.Total)), humanize.IBytes(uint64(m.Budget)),
+				formatUsage(status.Usage))
+		}
+
+		status.Warning = float64(status.Total) >= DiskWarningRatio*float64(m.Budget) ||
+			status.Total+status.Growth > m.Budget
+	}
+
+	return status, m.checkFreeSpace(status)
+}
+
+func (m *DiskMonitor) checkFreeSpace(status DiskStatus) error {
+	freeSpace := m.FreeSpace
+	if freeSpace == nil {
+		freeSpace = FreeDiskBytes
+	}
+
+	dirs := slices.Clone(m.Roots)
+	for _, u := range status.Usage {
+		dirs = append(dirs, u.Dirs...)
+	}
+
+	slices.Sort(dirs)
+
+	for _, dir := range slices.Compact(dirs) {
+		free, ok := freeSpace(dir)
+		if !ok || free-status.Growth >= MinFreeDiskBytes {
+			continue
+		}
+
+		return fmt.Errorf("%w: %s has %s free, the next chunk is expected to write %s and %s is kept in reserve",
+			ErrDiskSpaceLow, dir, humanize.IBytes(uint64(max(free, 0))), humanize.IBytes(uint64(status.Growth)),
+			humanize.IBytes(MinFreeDiskBytes))
+	}
+
+	return nil
+}
+
+// formatUsage lists the owners using disk space, largest first.
+func formatUsage(usage []DiskUsage) string {
+	sorted := slices.Clone(usage)
+	slices.SortStableFunc(sorted, func(a, b DiskUsage) int {
+		switch {
+		case a.Bytes > b.Bytes:
+			return -1
+		case a.Bytes < b.Bytes:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	parts := make([]string, 0, len(sorted))
+
+	for _, u := range sorted {
+		if u.Bytes > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", u.Owner, humanize.IBytes(uint64(u.Bytes))))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// DirSize returns the total size of the regular files below dir. Missing
+// directories and files removed during the walk count as empty.
+func DirSize(dir string) int64 {
+	if dir == "" {
+		return 0
+	}
+
+	var total int64
+
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, infoErr := d.Info()
+		if infoErr == nil {
+			total += info.Size()
+		}
+
+		return nil
+	})
+
+	return total
+}
@@ -0,0 +1,118 @@
+package streaming_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
+)
+
+func writeFile(t *testing.T, dir, name string, size int) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(dir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0o600))
+}
+
+func plentyOfSpace(string) (int64, bool) { return 1 << 40, true }
+
+func TestDirSize(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "a.bin", 100)
+	writeFile(t, filepath.Join(dir, "sub"), "b.bin", 50)
+
+	assert.Equal(t, int64(150), streaming.DirSize(dir))
+	assert.Zero(t, streaming.DirSize(filepath.Join(dir, "missing")))
+	assert.Zero(t, streaming.DirSize(""))
+}
+
+func TestDiskMonitor_WithinBudget(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "spill.bin", 100)
+
+	m := &streaming.DiskMonitor{Budget: 1000, FreeSpace: plentyOfSpace}
+
+	status, err := m.Check([]streaming.DiskUsage{{Owner: "burndown", Dirs: []string{dir}}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), status.Total)
+	assert.Equal(t, int64(100), status.Usage[0].Bytes)
+	assert.False(t, status.Warning)
+}
+
+func TestDiskMonitor_Warning(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := &streaming.DiskMonitor{Budget: 1000, FreeSpace: plentyOfSpace}
+	usage := []streaming.DiskUsage{{Owner: "couples", Dirs: []string{dir}}}
+
+	writeFile(t, dir, "0.bin", 200)
+
+	status, err := m.Check(usage)
+	require.NoError(t, err)
+	assert.False(t, status.Warning)
+
+	// 700 is below the warning ratio, but another chunk growing by 500 would
+	// exceed the budget.
+	writeFile(t, dir, "1.bin", 500)
+
+	status, err = m.Check(usage)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), status.Growth)
+	assert.True(t, status.Warning)
+}
+
+func TestDiskMonitor_BudgetExceeded(t *testing.T) {
+	t.Parallel()
+
+	spills := t.TempDir()
+	checkpoints := t.TempDir()
+
+	writeFile(t, spills, "spill.bin", 800)
+	writeFile(t, checkpoints, "state.bin", 300)
+
+	m := &streaming.DiskMonitor{Budget: 1000, FreeSpace: plentyOfSpace}
+
+	_, err := m.Check([]streaming.DiskUsage{
+		{Owner: "checkpoint", Dirs: []string{checkpoints}},
+		{Owner: "burndown", Dirs: []string{spills}},
+	})
+	require.ErrorIs(t, err, streaming.ErrDiskBudgetExceeded)
+	assert.Contains(t, err.Error(), "burndown 800 B, checkpoint 300 B")
+}
+
+func TestDiskMonitor_FreeSpaceLow(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	m := &streaming.DiskMonitor{
+		Roots: []string{root},
+		FreeSpace: func(string) (int64, bool) {
+			return streaming.MinFreeDiskBytes - 1, true
+		},
+	}
+
+	_, err := m.Check(nil)
+	require.ErrorIs(t, err, streaming.ErrDiskSpaceLow)
+	assert.Contains(t, err.Error(), root)
+}
+
+func TestDiskMonitor_UnknownFreeSpace(t *testing.T) {
+	t.Parallel()
+
+	m := &streaming.DiskMonitor{
+		Roots:     []string{t.TempDir()},
+		FreeSpace: func(string) (int64, bool) { return 0, false },
+	}
+
+	_, err := m.Check(nil)
+	require.NoError(t, err)
+}
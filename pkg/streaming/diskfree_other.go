@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package streaming
+
+// FreeDiskBytes is unsupported on this platform; the free space check is
+// skipped and only the disk budget applies.
+func FreeDiskBytes(string) (int64, bool) {
+	return 0, false
+}
@@ -0,0 +1,18 @@
+//go:build linux || darwin
+
+package streaming
+
+import "golang.org/x/sys/unix"
+
+// FreeDiskBytes returns the bytes available to unprivileged users on the
+// filesystem holding dir. The boolean is false when statfs fails.
+func FreeDiskBytes(dir string) (int64, bool) {
+	var st unix.Statfs_t
+
+	err := unix.Statfs(dir, &st)
+	if err != nil {
+		return 0, false
+	}
+
+	return int64(st.Bavail) * int64(st.Bsize), true
+}
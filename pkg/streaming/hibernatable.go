@@ -25,6 +25,7 @@ type SpillCleanupGuard struct {
 	logger   *slog.Logger
 	sigCh    chan os.Signal
 	once     sync.Once
+	usage    func() []DiskUsage
 }
 
 // NewSpillCleanupGuard registers SIGTERM and SIGINT handlers that invoke
@@ -53,14 +54,44 @@ func NewSpillCleanupGuard(cleaners []SpillCleaner, logger *slog.Logger) *SpillCl
 	return g
 }
 
+// TrackUsage registers a function reporting the spill usage of each analyzer.
+// Close logs the bytes each analyzer wrote before cleaning up.
+func (g *SpillCleanupGuard) TrackUsage(usage func() []DiskUsage) {
+	g.usage = usage
+}
+
 // Close performs spill cleanup (if not already done) and deregisters
 // the signal handler.
 func (g *SpillCleanupGuard) Close() {
+	g.reportWritten()
 	g.cleanup()
 	signal.Stop(g.sigCh)
 	close(g.sigCh)
 }
 
+// reportWritten logs the spill bytes written per analyzer. Skipped on the
+// signal path, where the pipeline may still be writing.
+func (g *SpillCleanupGuard) reportWritten() {
+	if g.usage == nil {
+		return
+	}
+
+	var total int64
+
+	for _, u := range g.usage() {
+		if u.Written <= 0 {
+			continue
+		}
+
+		total += u.Written
+		g.logger.Info("streaming: spill bytes written", "analyzer", u.Owner, "bytes", u.Written)
+	}
+
+	if total > 0 {
+		g.logger.Info("streaming: spill bytes written in total", "bytes", total)
+	}
+}
+
 func (g *SpillCleanupGuard) cleanup() {
 	g.once.Do(func() {
 		for _, c := range g.cleaners {
@@ -56,3 +56,21 @@ func TestSpillCleanupGuard_NilCleaners(t *testing.T) {
 	guard := streaming.NewSpillCleanupGuard(nil, discardLogger())
 	guard.Close()
 }
+
+func TestSpillCleanupGuard_TrackUsage(t *testing.T) {
+	t.Parallel()
+
+	c := &mockSpillCleaner{}
+	calls := 0
+
+	guard := streaming.NewSpillCleanupGuard([]streaming.SpillCleaner{c}, discardLogger())
+	guard.TrackUsage(func() []streaming.DiskUsage {
+		calls++
+
+		return []streaming.DiskUsage{{Owner: "burndown", Written: 1024}}
+	})
+	guard.Close()
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, int32(1), c.calls.Load())
+}
@@ -129,13 +129,13 @@ func TestAllAnalyzers_CheckpointRoundTrip(t *testing.T) {
 			analyzer := tt.setup(t)
 
 			// Save checkpoint.
-			err := analyzer.SaveCheckpoint(dir)
+			err := analyzer.SaveCheckpoint(dir, nil)
 			require.NoError(t, err, "%s.SaveCheckpoint() failed", tt.name)
 
 			// Create fresh analyzer and load.
 			restored := tt.setup(t)
 
-			err = restored.LoadCheckpoint(dir)
+			err = restored.LoadCheckpoint(dir, nil)
 			require.NoError(t, err, "%s.LoadCheckpoint() failed", tt.name)
 		})
 	}
@@ -0,0 +1,47 @@
+//go:build linux
+
+package streaming
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// MemoryBackedFS reports whether dir lives on a memory-backed filesystem
+// (tmpfs or ramfs), and returns the filesystem type. Files spilled there are
+// held in RAM and count against system memory like the state they replace.
+// A dir that does not exist yet is checked through its closest existing
+// ancestor.
+func MemoryBackedFS(dir string) (string, bool) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	var st unix.Statfs_t
+
+	for {
+		err := unix.Statfs(dir, &st)
+		if err == nil {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+
+		dir = parent
+	}
+
+	// f_type is a 32-bit magic number, stored in a wider field on some architectures.
+	switch uint32(st.Type) {
+	case unix.TMPFS_MAGIC:
+		return "tmpfs", true
+	case unix.RAMFS_MAGIC:
+		return "ramfs", true
+	default:
+		return "", false
+	}
+}
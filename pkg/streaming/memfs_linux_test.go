@@ -0,0 +1,31 @@
+//go:build linux
+
+package streaming_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/streaming"
+)
+
+func TestMemoryBackedFS_Shm(t *testing.T) {
+	t.Parallel()
+
+	var st unix.Statfs_t
+
+	if unix.Statfs("/dev/shm", &st) != nil || uint32(st.Type) != unix.TMPFS_MAGIC {
+		t.Skip("/dev/shm is not tmpfs")
+	}
+
+	fsType, ok := streaming.MemoryBackedFS("/dev/shm")
+	assert.True(t, ok)
+	assert.Equal(t, "tmpfs", fsType)
+
+	// Dirs that do not exist yet are checked through their parent.
+	fsType, ok = streaming.MemoryBackedFS("/dev/shm/codefang-missing/spills")
+	assert.True(t, ok)
+	assert.Equal(t, "tmpfs", fsType)
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package streaming
+
+// MemoryBackedFS is only implemented on Linux, where /tmp is commonly tmpfs;
+// elsewhere every dir is reported as disk-backed.
+func MemoryBackedFS(string) (string, bool) {
+	return "", false
+}
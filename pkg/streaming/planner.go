@@ -228,6 +228,7 @@ type AdaptivePlanner struct {
 	alpha            float64
 	replanThreshold  float64
 	replanCount      int
+	weights          []int64 // Per-commit work estimates; nil plans by commit count.
 }
 
 // AdaptiveStats holds telemetry from the adaptive planner.
@@ -277,7 +278,18 @@ func NewAdaptivePlanner(totalCommits int, memBudget, declaredGrowth, pipelineOve
 
 // InitialPlan returns the first set of chunk boundaries using the declared growth rate.
 func (ap *AdaptivePlanner) InitialPlan() []ChunkBounds {
-	return ap.buildPlanner(ap.declaredGrowth).Plan()
+	return BalanceChunks(ap.buildPlanner(ap.declaredGrowth).Plan(), ap.weights)
+}
+
+// SetWeights makes the planner balance re-planned chunks by the given
+// per-commit work estimates, indexed like the commits; see BalanceChunks.
+// Weights not covering every commit are ignored.
+func (ap *AdaptivePlanner) SetWeights(weights []int64) {
+	if len(weights) != ap.totalCommits {
+		weights = nil
+	}
+
+	ap.weights = weights
 }
 
 // Replan examines three per-chunk metric observations (working state growth,
@@ -327,7 +339,7 @@ func (ap *AdaptivePlanner) Replan(obs ReplanObservation) []ChunkBounds {
 	ap.replanCount++
 
 	planner := ap.buildPlanner(newRawGrowth)
-	tailChunks := planner.PlanFrom(obs.Chunk.End)
+	tailChunks := BalanceChunks(planner.PlanFrom(obs.Chunk.End), ap.weights)
 
 	// Splice: keep processed chunks [0..obs.ChunkIndex], append new tail.
 	result := make([]ChunkBounds, obs.ChunkIndex+1, obs.ChunkIndex+1+len(tailChunks))
@@ -337,6 +349,24 @@ func (ap *AdaptivePlanner) Replan(obs ReplanObservation) []ChunkBounds {
 	return result
 }
 
+// Rebudget changes the memory budget and re-computes chunk boundaries for all
+// chunks after chunkIndex at the current growth rate. Like Replan, it never
+// modifies processed chunks [0..chunkIndex].
+func (ap *AdaptivePlanner) Rebudget(memBudget int64, chunkIndex int, current []ChunkBounds) []ChunkBounds {
+	ap.memoryBudget = memBudget
+
+	if chunkIndex < 0 || chunkIndex >= len(current)-1 {
+		return current
+	}
+
+	tailChunks := BalanceChunks(ap.buildPlanner(ap.currentGrowth).PlanFrom(current[chunkIndex].End), ap.weights)
+
+	result := make([]ChunkBounds, chunkIndex+1, chunkIndex+1+len(tailChunks))
+	copy(result, current[:chunkIndex+1])
+
+	return append(result, tailChunks...)
+}
+
 // exceedsThreshold returns true if the observed EMA value diverges from predicted
 // by more than the given threshold fraction.
 func exceedsThreshold(observed, predicted, threshold float64) bool {
@@ -597,3 +627,65 @@ func buildChunks(totalCommits, chunkSize int) []ChunkBounds {
 
 	return chunks
 }
+
+// BalanceChunks re-cuts chunks so that each carries about the same work, as
+// given by per-commit weights indexed like the commits, instead of the same
+// number of commits. The largest chunk of the input stays the cap, as it is
+// what the memory budget allows, so heavy stretches of history are split
+// into more chunks while light ones keep their size. Chunks are not cut
+// below MinChunkSize commits unless the input already is. The chunks are
+// returned unchanged when there is only one or the weights do not cover
+// them.
+func BalanceChunks(chunks []ChunkBounds, weights []int64) []ChunkBounds {
+	if len(chunks) < 2 || chunks[len(chunks)-1].End > len(weights) {
+		return chunks
+	}
+
+	start, end := chunks[0].Start, chunks[len(chunks)-1].End
+
+	maxSize := 0
+	for _, chunk := range chunks {
+		maxSize = max(maxSize, chunk.End-chunk.Start)
+	}
+
+	var total int64
+	for _, weight := range weights[start:end] {
+		total += max(weight, 0)
+	}
+
+	if total <= 0 {
+		return chunks
+	}
+
+	target := (total + int64(len(chunks)) - 1) / int64(len(chunks))
+	minSize := min(MinChunkSize, maxSize)
+
+	balanced := make([]ChunkBounds, 0, len(chunks))
+	chunkStart := start
+
+	var work int64
+
+	for i := start; i < end; i++ {
+		work += max(weights[i], 0)
+
+		size := i + 1 - chunkStart
+		if size >= maxSize || (work >= target && size >= minSize) {
+			balanced = append(balanced, ChunkBounds{Start: chunkStart, End: i + 1})
+			chunkStart = i + 1
+			work = 0
+		}
+	}
+
+	if chunkStart == end {
+		return balanced
+	}
+
+	// Fold a short remainder into the last chunk when the cap allows.
+	if last := len(balanced) - 1; last >= 0 && end-chunkStart < minSize && end-balanced[last].Start <= maxSize {
+		balanced[last].End = end
+
+		return balanced
+	}
+
+	return append(balanced, ChunkBounds{Start: chunkStart, End: end})
+}
@@ -374,6 +374,26 @@ func TestAdaptivePlanner_PreservesProcessedChunks(t *testing.T) {
 	}
 }
 
+func TestAdaptivePlanner_Rebudget(t *testing.T) {
+	t.Parallel()
+
+	ap := NewAdaptivePlanner(100000, 2048*mib, 500*kib, 400*mib)
+	chunks := ap.InitialPlan()
+	require.Greater(t, len(chunks), 3)
+
+	newChunks := ap.Rebudget(1024*mib, 1, chunks)
+
+	// Processed chunks stay, the tail shrinks to fit the lower budget.
+	assert.Equal(t, chunks[:2], newChunks[:2])
+	assert.Greater(t, len(newChunks), len(chunks))
+	assert.Less(t, newChunks[2].End-newChunks[2].Start, chunks[2].End-chunks[2].Start)
+	assert.Equal(t, chunks[1].End, newChunks[2].Start)
+	assert.Equal(t, 100000, newChunks[len(newChunks)-1].End)
+
+	// Nothing is left to re-plan after the last chunk.
+	assert.Equal(t, newChunks, ap.Rebudget(512*mib, len(newChunks)-1, newChunks))
+}
+
 func TestAdaptivePlanner_CoversAllCommits(t *testing.T) {
 	t.Parallel()
 
@@ -1234,3 +1254,72 @@ func assertChunksContiguous(t *testing.T, chunks []ChunkBounds, totalCommits int
 
 	assert.Equal(t, totalCommits, chunks[len(chunks)-1].End)
 }
+
+func TestBalanceChunks(t *testing.T) {
+	t.Parallel()
+
+	chunks := buildChunks(400, 100)
+
+	// The first 100 commits carry ten times the work of the others.
+	weights := make([]int64, 400)
+	for i := range weights {
+		weights[i] = 1
+		if i < 100 {
+			weights[i] = 10
+		}
+	}
+
+	assert.Equal(t, []ChunkBounds{
+		{Start: 0, End: 50},
+		{Start: 50, End: 100},
+		{Start: 100, End: 200},
+		{Start: 200, End: 300},
+		{Start: 300, End: 400},
+	}, BalanceChunks(chunks, weights))
+
+	assert.Equal(t, chunks, BalanceChunks(chunks, weights[:300]), "weights must cover the chunks")
+	assert.Equal(t, chunks, BalanceChunks(chunks, make([]int64, 400)), "no work to balance")
+
+	single := []ChunkBounds{{Start: 0, End: 400}}
+	assert.Equal(t, single, BalanceChunks(single, weights))
+}
+
+func TestAdaptivePlanner_ReplanKeepsBalance(t *testing.T) {
+	t.Parallel()
+
+	const totalCommits = 20000
+
+	weights := make([]int64, totalCommits)
+	for i := range weights {
+		weights[i] = int64(i%1000) + 1
+	}
+
+	ap := NewAdaptivePlanner(totalCommits, 2048*mib, 500*kib, 400*mib)
+	ap.SetWeights(weights)
+
+	chunks := ap.InitialPlan()
+	predicted := int64(750 * kib)
+
+	newChunks := ap.Replan(ReplanObservation{
+		ChunkIndex:          0,
+		Chunk:               chunks[0],
+		WorkGrowthPerCommit: 3 * predicted,
+		TCPayloadPerCommit:  predicted,
+		AggGrowthPerCommit:  predicted,
+		CurrentChunks:       chunks,
+	})
+
+	assert.Equal(t, chunks[0], newChunks[0])
+
+	for i := 1; i < len(newChunks); i++ {
+		assert.Equal(t, newChunks[i-1].End, newChunks[i].Start, "gap between chunk %d and %d", i-1, i)
+	}
+
+	assert.Equal(t, totalCommits, newChunks[len(newChunks)-1].End)
+
+	tail := ap.buildPlanner(ap.currentGrowth).PlanFrom(chunks[0].End)
+	assert.Equal(t, BalanceChunks(tail, weights), newChunks[1:])
+
+	ap.SetWeights(weights[:10])
+	assert.Nil(t, ap.weights, "weights not covering every commit are ignored")
+}
@@ -0,0 +1,156 @@
+package streaming
+
+import (
+	"math"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+)
+
+// Soft memory limit notification constants.
+const (
+	// SoftLimitNotifyRatio is the fraction of the Go soft memory limit at
+	// which a SoftLimitWatcher notifies, leaving room to spill before the GC
+	// starts running back to back to stay under the limit.
+	SoftLimitNotifyRatio = 0.85
+
+	// softLimitRenotifyRatio is the further growth, as a fraction of the
+	// limit, after which a watcher notifies again while usage stays above
+	// the threshold.
+	softLimitRenotifyRatio = 0.05
+)
+
+// Runtime metrics making up the memory that debug.SetMemoryLimit bounds.
+const (
+	metricTotalMemory    = "/memory/classes/total:bytes"
+	metricReleasedMemory = "/memory/classes/heap/released:bytes"
+)
+
+// SoftLimitEvent reports Go memory use above the notification threshold.
+type SoftLimitEvent struct {
+	// Used is the memory counted against the soft limit, in bytes.
+	Used int64
+
+	// Limit is the soft memory limit set with debug.SetMemoryLimit.
+	Limit int64
+}
+
+// SoftLimitWatcher notifies when the memory of the Go runtime approaches the
+// soft memory limit set with debug.SetMemoryLimit. It checks after every GC
+// cycle, so a chunk in progress can spill state before the limit is hit
+// instead of the GC thrashing until the chunk ends.
+//
+// Notifications are coalesced: C holds at most one pending event. While usage
+// stays above the threshold, another event is sent only after usage grows by
+// a further 5% of the limit.
+type SoftLimitWatcher struct {
+	ratio float64
+	ch    chan SoftLimitEvent
+
+	mu           sync.Mutex
+	stopped      bool
+	lastNotified int64
+	samples      []metrics.Sample
+}
+
+// NewSoftLimitWatcher starts a watcher notifying at ratio of the soft limit.
+// Call Stop when done.
+func NewSoftLimitWatcher(ratio float64) *SoftLimitWatcher {
+	w := &SoftLimitWatcher{
+		ratio: ratio,
+		ch:    make(chan SoftLimitEvent, 1),
+		samples: []metrics.Sample{
+			{Name: metricTotalMemory},
+			{Name: metricReleasedMemory},
+		},
+	}
+
+	armGCHook(w)
+
+	return w
+}
+
+// C returns the channel events are delivered on. A nil watcher returns a nil
+// channel, which never delivers.
+func (w *SoftLimitWatcher) C() <-chan SoftLimitEvent {
+	if w == nil {
+		return nil
+	}
+
+	return w.ch
+}
+
+// Stop ends the notifications. Safe to call on a nil watcher.
+func (w *SoftLimitWatcher) Stop() {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopped = true
+}
+
+// gcSentinel is garbage as soon as it is created; its finalizer runs once per
+// GC cycle and re-arms the hook with a new sentinel.
+type gcSentinel struct {
+	w *SoftLimitWatcher
+}
+
+func armGCHook(w *SoftLimitWatcher) {
+	runtime.SetFinalizer(&gcSentinel{w: w}, func(s *gcSentinel) {
+		if s.w.check() {
+			armGCHook(s.w)
+		}
+	})
+}
+
+// check samples memory use after a GC cycle and notifies when needed.
+// Returns false once the watcher is stopped.
+func (w *SoftLimitWatcher) check() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped {
+		return false
+	}
+
+	metrics.Read(w.samples)
+
+	used := int64(w.samples[0].Value.Uint64() - w.samples[1].Value.Uint64())
+
+	event, ok := w.observe(used, debug.SetMemoryLimit(-1))
+	if ok {
+		select {
+		case w.ch <- event:
+		default: // An event is already pending.
+		}
+	}
+
+	return true
+}
+
+// observe decides whether used bytes against limit warrant an event. Must be
+// called with mu held.
+func (w *SoftLimitWatcher) observe(used, limit int64) (SoftLimitEvent, bool) {
+	if limit <= 0 || limit == math.MaxInt64 {
+		return SoftLimitEvent{}, false
+	}
+
+	threshold := int64(float64(limit) * w.ratio)
+	if used < threshold {
+		w.lastNotified = 0
+
+		return SoftLimitEvent{}, false
+	}
+
+	if w.lastNotified > 0 && used < w.lastNotified+int64(float64(limit)*softLimitRenotifyRatio) {
+		return SoftLimitEvent{}, false
+	}
+
+	w.lastNotified = used
+
+	return SoftLimitEvent{Used: used, Limit: limit}, true
+}
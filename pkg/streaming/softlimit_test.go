@@ -0,0 +1,55 @@
+package streaming
+
+import (
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoftLimitWatcher_Observe(t *testing.T) {
+	t.Parallel()
+
+	w := &SoftLimitWatcher{ratio: 0.8}
+
+	_, ok := w.observe(1<<40, math.MaxInt64)
+	assert.False(t, ok, "no limit set")
+
+	_, ok = w.observe(700, 1000)
+	assert.False(t, ok, "below the threshold")
+
+	event, ok := w.observe(800, 1000)
+	assert.True(t, ok)
+	assert.Equal(t, SoftLimitEvent{Used: 800, Limit: 1000}, event)
+
+	_, ok = w.observe(840, 1000)
+	assert.False(t, ok, "coalesced until usage grows by 5% of the limit")
+
+	_, ok = w.observe(850, 1000)
+	assert.True(t, ok)
+
+	_, ok = w.observe(500, 1000)
+	assert.False(t, ok)
+
+	_, ok = w.observe(810, 1000)
+	assert.True(t, ok, "re-armed after dropping below the threshold")
+}
+
+func TestSoftLimitWatcher_StopEndsGCHook(t *testing.T) {
+	t.Parallel()
+
+	w := NewSoftLimitWatcher(SoftLimitNotifyRatio)
+	assert.True(t, w.check())
+
+	w.Stop()
+	assert.False(t, w.check())
+
+	// The hook notices the stop on the next GC cycle and is not re-armed.
+	runtime.GC()
+
+	var nilWatcher *SoftLimitWatcher
+
+	nilWatcher.Stop()
+	assert.Nil(t, nilWatcher.C())
+}
@@ -16,4 +16,87 @@ type Change struct {
 	Before *node.Node
 	After  *node.Node
 	Change *gitlib.Change
+
+	// BeforeHashes and AfterHashes summarize Before and After. NewChange sets
+	// them; they are nil for a missing tree or a Change built by hand.
+	BeforeHashes *DeclarationHashes
+	AfterHashes  *DeclarationHashes
+}
+
+// DeclarationHashes holds the structural hashes of a file and of its
+// declarations, so that consumers can skip what a commit left unchanged
+// without walking both trees.
+type DeclarationHashes struct {
+	// File is the structural hash of the whole tree.
+	File uint64
+	// Declarations maps function names to the structural hashes of their
+	// subtrees. When several functions share a name, the last one wins.
+	Declarations map[string]uint64
+}
+
+// NewChange returns the change between before and after with the hashes of
+// both trees. Pipelines call it on their workers to keep hashing parallel.
+func NewChange(before, after *node.Node, change *gitlib.Change) Change {
+	return Change{
+		Before:       before,
+		After:        after,
+		Change:       change,
+		BeforeHashes: HashDeclarations(before),
+		AfterHashes:  HashDeclarations(after),
+	}
+}
+
+// HashDeclarations hashes root and every function in it that has a name
+// prop. It returns nil for a nil root.
+func HashDeclarations(root *node.Node) *DeclarationHashes {
+	if root == nil {
+		return nil
+	}
+
+	hashes := &DeclarationHashes{File: root.StructuralHash(), Declarations: make(map[string]uint64)}
+
+	// Walk in document order so that the last duplicate wins.
+	stack := []*node.Node{root}
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if current == nil {
+			continue
+		}
+
+		if name := current.Props["name"]; name != "" && current.HasAnyRole(node.RoleFunction) {
+			hashes.Declarations[name] = current.StructuralHash()
+		}
+
+		for idx := len(current.Children) - 1; idx >= 0; idx-- {
+			stack = append(stack, current.Children[idx])
+		}
+	}
+
+	return hashes
+}
+
+// FileUnchanged reports whether Before and After are known to have the same
+// structure.
+func (c Change) FileUnchanged() bool {
+	return c.BeforeHashes != nil && c.AfterHashes != nil && c.BeforeHashes.File == c.AfterHashes.File
+}
+
+// DeclarationUnchanged reports whether the named function is known to have
+// the same structure in Before and After.
+func (c Change) DeclarationUnchanged(name string) bool {
+	if c.BeforeHashes == nil || c.AfterHashes == nil {
+		return false
+	}
+
+	before, found := c.BeforeHashes.Declarations[name]
+	if !found {
+		return false
+	}
+
+	after, found := c.AfterHashes.Declarations[name]
+
+	return found && before == after
 }
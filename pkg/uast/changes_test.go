@@ -618,3 +618,60 @@ func TestDetectChanges_ComplexScenario(t *testing.T) {
 		t.Error("Expected to find at least one modification")
 	}
 }
+
+func TestHashDeclarations(t *testing.T) {
+	t.Parallel()
+
+	function := func(name, body string, start uint) *node.Node {
+		return &node.Node{
+			Type:  node.UASTFunction,
+			Roles: []node.Role{node.RoleFunction},
+			Props: map[string]string{"name": name},
+			Pos:   &node.Positions{StartLine: start, EndLine: start + 1},
+			Children: []*node.Node{
+				{Type: node.UASTCall, Token: body, Pos: &node.Positions{StartLine: start + 1, EndLine: start + 1}},
+			},
+		}
+	}
+
+	before := &node.Node{Type: testGoFileType, Children: []*node.Node{
+		function("add", "sum()", 1),
+		function("sub", "diff()", 3),
+	}}
+	// add moves down unchanged, sub changes, mul is new.
+	after := &node.Node{Type: testGoFileType, Children: []*node.Node{
+		{Type: node.UASTComment, Token: "// math"},
+		function("add", "sum()", 2),
+		function("sub", "neg()", 4),
+		function("mul", "prod()", 6),
+	}}
+
+	change := NewChange(before, after, nil)
+
+	if len(change.AfterHashes.Declarations) != 3 {
+		t.Fatalf("Expected 3 declarations, got %d", len(change.AfterHashes.Declarations))
+	}
+
+	if change.FileUnchanged() {
+		t.Error("Expected the file to have changed")
+	}
+
+	for name, want := range map[string]bool{"add": true, "sub": false, "mul": false, "missing": false} {
+		if got := change.DeclarationUnchanged(name); got != want {
+			t.Errorf("DeclarationUnchanged(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	if !NewChange(before, before, nil).FileUnchanged() {
+		t.Error("Expected an identical file to be unchanged")
+	}
+
+	if HashDeclarations(nil) != nil {
+		t.Error("Expected nil hashes for a nil tree")
+	}
+
+	// Without hashes nothing is known to be unchanged.
+	if (Change{Before: before, After: before}).DeclarationUnchanged("add") {
+		t.Error("Expected a change without hashes to report changes")
+	}
+}
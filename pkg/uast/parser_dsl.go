@@ -32,13 +32,18 @@ const (
 	comparisonParts       = 2
 )
 
-// maxInternLen is the maximum string length eligible for per-parse interning.
+// maxInternLen is the maximum string length eligible for interning.
 // Strings longer than this are unlikely to repeat within a single file.
 const maxInternLen = 32
 
+// maxInternerEntries bounds the interner of a pooled parse context. The
+// interner is kept across parses, so identifiers shared by the files and
+// commits of a repository are allocated once, and cleared past this size.
+const maxInternerEntries = 8192
+
 // Initial capacities for per-parse data structures.
 const (
-	initialInternerCapacity    = 128 // initial capacity for per-parse string interner.
+	initialInternerCapacity    = 128 // initial capacity for the string interner.
 	initialBatchChildrenBuffer = 32  // small reusable child batch buffer per parse.
 )
 
@@ -239,13 +244,17 @@ type parseContext struct {
 }
 
 // acquireParseContext returns a parseContext from the pool or creates a new one.
-// The interner map is cleared (not re-allocated) to avoid GC pressure.
+// The interner map is kept until it outgrows maxInternerEntries, then cleared
+// (not re-allocated) to avoid GC pressure.
 func (parser *DSLParser) acquireParseContext(tree *sitter.Tree, content []byte) *parseContext {
 	var pctx *parseContext
 
 	if pooled, ok := parser.parseCtxPool.Get().(*parseContext); ok {
 		pctx = pooled
-		clear(pctx.interner)
+
+		if len(pctx.interner) > maxInternerEntries {
+			clear(pctx.interner)
+		}
 		pctx.batchChildren = pctx.batchChildren[:0]
 		pctx.cursors = pctx.cursors[:0]
 	} else {
@@ -417,7 +426,7 @@ const cursorThreshold = 8
 // processChildren processes all children of the node.
 func (ctx *parseContext) processChildren(root sitter.Node, mappingRule *mapping.Rule) []*node.Node {
 	childCount := readNamedChildCount(unsafe.Pointer(&root))
-	children := make([]*node.Node, 0, childCount)
+	children := ctx.alloc.NewChildren(safeconv.MustUintToInt(uint(childCount)))
 
 	if childCount < cursorThreshold {
 		return ctx.processChildrenDirect(root, childCount, mappingRule, children)
@@ -771,6 +780,10 @@ func (ctx *parseContext) extractRoles(mappingRule *mapping.Rule, roles *[]node.R
 		return
 	}
 
+	if *roles == nil {
+		*roles = ctx.alloc.NewRoles(len(mappingRule.UASTSpec.Roles))
+	}
+
 	for _, roleStr := range mappingRule.UASTSpec.Roles {
 		if interned, ok := ctx.internedRoles[roleStr]; ok {
 			*roles = append(*roles, interned)
@@ -875,23 +888,13 @@ func (ctx *parseContext) extractDirectChildProperty(root sitter.Node, propStr st
 }
 
 // extractChildText extracts text from a child node.
-// Short strings are interned within the current parse.
+// Short strings are interned.
 func (ctx *parseContext) extractChildText(child sitter.Node) string {
 	start := child.StartByte()
 	end := child.EndByte()
 
 	if safeconv.MustUintToInt(end) <= len(ctx.source) {
-		s := string(ctx.source[start:end])
-
-		if len(s) <= maxInternLen && ctx.interner != nil {
-			if interned, ok := ctx.interner[s]; ok {
-				return interned
-			}
-
-			ctx.interner[s] = s
-		}
-
-		return s
+		return ctx.intern(ctx.source[start:end])
 	}
 
 	return ""
@@ -1192,27 +1195,35 @@ func (ctx *parseContext) computeChildrenSpan(children []*node.Node) *node.Positi
 
 // extractNodeText extracts text from a Tree-sitter node (allocating copy).
 // Use for values that are stored in Node.Token or Node.Props.
-// Short strings (≤ maxInternLen) are interned within the current parse to
-// deduplicate repeated identifiers, keywords, and operators.
+// Short strings (≤ maxInternLen) are interned to deduplicate repeated
+// identifiers, keywords, and operators.
 func (ctx *parseContext) extractNodeText(tsNode sitter.Node) string {
 	start := tsNode.StartByte()
 	end := tsNode.EndByte()
 
 	if safeconv.MustUintToInt(end) <= len(ctx.source) {
-		s := string(ctx.source[start:end])
+		return ctx.intern(ctx.source[start:end])
+	}
 
-		if len(s) <= maxInternLen && ctx.interner != nil {
-			if interned, ok := ctx.interner[s]; ok {
-				return interned
-			}
+	return ""
+}
 
-			ctx.interner[s] = s
-		}
+// intern returns text as a string, sharing one string among equal short
+// texts. Looking up string(text) does not allocate, so only the first
+// occurrence of a text costs an allocation.
+func (ctx *parseContext) intern(text []byte) string {
+	if len(text) > maxInternLen || ctx.interner == nil {
+		return string(text)
+	}
 
-		return s
+	if interned, ok := ctx.interner[string(text)]; ok {
+		return interned
 	}
 
-	return ""
+	s := string(text)
+	ctx.interner[s] = s
+
+	return s
 }
 
 // unsafeNodeText returns a zero-copy string view of a Tree-sitter node's text.
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	sitter "github.com/alexaandru/go-tree-sitter-bare"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
 )
 
 // Sample Go DSL mapping rules for benchmarking.
@@ -616,3 +618,22 @@ func BenchmarkProcessChildren(b *testing.B) {
 		_ = ctx.processChildren(root, mappingRule)
 	}
 }
+
+// BenchmarkParseRelease_Large parses and releases a large Go file, the way
+// history analyzers such as shotness and quality handle every changed file.
+func BenchmarkParseRelease_Large(b *testing.B) {
+	parser := createBenchParser(b)
+	source := []byte(largeGoSource)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for b.Loop() {
+		root, err := parser.Parse(context.Background(), "test.go", source)
+		if err != nil {
+			b.Fatalf("Parse failed: %v", err)
+		}
+
+		node.ReleaseTree(root)
+	}
+}
@@ -1519,3 +1519,23 @@ func World() {}
 		}
 	}
 }
+
+func TestParseContext_Intern(t *testing.T) {
+	t.Parallel()
+
+	ctx := &parseContext{interner: make(map[string]string)}
+
+	first := ctx.intern([]byte("identifier"))
+	second := ctx.intern([]byte("identifier"))
+
+	if first != "identifier" || unsafe.StringData(first) != unsafe.StringData(second) {
+		t.Errorf("expected equal texts to share one string")
+	}
+
+	long := []byte(strings.Repeat("x", maxInternLen+1))
+	ctx.intern(long)
+
+	if len(ctx.interner) != 1 {
+		t.Errorf("expected long texts not to be interned, interner has %d entries", len(ctx.interner))
+	}
+}
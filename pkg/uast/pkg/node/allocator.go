@@ -1,11 +1,32 @@
 package node
 
+// Arena slab sizes. Each slab is a single allocation carved into many
+// nodes, positions, child lists or role lists.
+const (
+	nodeSlabSize     = 256
+	positionSlabSize = 256
+	childSlabSize    = 1024
+	roleSlabSize     = 512
+)
+
 // Allocator is a per-worker free-list allocator for [Node] and [Positions].
 // It eliminates cross-goroutine [sync.Pool] contention by keeping a local free
 // list per parse invocation. Not safe for concurrent use.
+//
+// When the free lists are empty, the allocator works as an arena: nodes,
+// positions and the Children and Roles slices are carved from slabs, so a
+// parse costs one allocation per slab instead of several per node. A slab
+// is never handed out twice and is garbage collected with the last tree
+// that references it, so trees may outlive the allocator and be released
+// with [ReleaseTree] like any other.
 type Allocator struct {
 	nodes []*Node
 	pos   []*Positions
+
+	nodeSlab  []Node
+	posSlab   []Positions
+	childSlab []*Node
+	roleSlab  []Role
 }
 
 // GetNode returns a zeroed Node, reusing from the free list if available.
@@ -17,7 +38,14 @@ func (a *Allocator) GetNode() *Node {
 		return nd
 	}
 
-	return &Node{}
+	if len(a.nodeSlab) == 0 {
+		a.nodeSlab = make([]Node, nodeSlabSize)
+	}
+
+	nd := &a.nodeSlab[0]
+	a.nodeSlab = a.nodeSlab[1:]
+
+	return nd
 }
 
 // PutNode clears the node and returns it to the free list.
@@ -41,7 +69,57 @@ func (a *Allocator) GetPositions() *Positions {
 		return positions
 	}
 
-	return &Positions{}
+	if len(a.posSlab) == 0 {
+		a.posSlab = make([]Positions, positionSlabSize)
+	}
+
+	positions := &a.posSlab[0]
+	a.posSlab = a.posSlab[1:]
+
+	return positions
+}
+
+// NewChildren returns an empty, non-nil Children slice with room for
+// capacity nodes. Appending beyond capacity moves the slice off the arena,
+// so neighboring slices are never overwritten.
+func (a *Allocator) NewChildren(capacity int) []*Node {
+	if capacity == 0 {
+		return []*Node{}
+	}
+
+	if capacity > childSlabSize/4 {
+		return make([]*Node, 0, capacity)
+	}
+
+	if len(a.childSlab) < capacity {
+		a.childSlab = make([]*Node, childSlabSize)
+	}
+
+	children := a.childSlab[:0:capacity]
+	a.childSlab = a.childSlab[capacity:]
+
+	return children
+}
+
+// NewRoles returns an empty Roles slice with room for capacity roles, or
+// nil for no roles.
+func (a *Allocator) NewRoles(capacity int) []Role {
+	if capacity == 0 {
+		return nil
+	}
+
+	if capacity > roleSlabSize/4 {
+		return make([]Role, 0, capacity)
+	}
+
+	if len(a.roleSlab) < capacity {
+		a.roleSlab = make([]Role, roleSlabSize)
+	}
+
+	roles := a.roleSlab[:0:capacity]
+	a.roleSlab = a.roleSlab[capacity:]
+
+	return roles
 }
 
 // PutPositions clears the positions and returns them to the free list.
@@ -140,3 +140,131 @@ func TestAllocator_ReleaseTree(t *testing.T) {
 		t.Errorf("expected 2 positions in free list, got %d", len(alloc.pos))
 	}
 }
+
+func TestAllocator_GetNode_CarvesDistinctNodesAcrossSlabs(t *testing.T) {
+	t.Parallel()
+
+	alloc := &Allocator{}
+	seen := make(map[*Node]bool)
+
+	for range nodeSlabSize*2 + 1 {
+		n := alloc.GetNode()
+		if seen[n] {
+			t.Fatal("GetNode returned the same node twice")
+		}
+
+		seen[n] = true
+	}
+}
+
+func TestAllocator_NewChildren_ReservesCapacity(t *testing.T) {
+	t.Parallel()
+
+	alloc := &Allocator{}
+
+	empty := alloc.NewChildren(0)
+	if empty == nil || len(empty) != 0 {
+		t.Fatalf("expected empty non-nil children, got %#v", empty)
+	}
+
+	first := alloc.NewChildren(2)
+	second := alloc.NewChildren(2)
+
+	if cap(first) != 2 || len(first) != 0 {
+		t.Fatalf("expected len 0 cap 2, got len %d cap %d", len(first), cap(first))
+	}
+
+	a, b, c := &Node{Token: "a"}, &Node{Token: "b"}, &Node{Token: "c"}
+	second = append(second, a)
+	first = append(first, b, c, a)
+
+	// Growing past the reserved capacity must not clobber the neighbor.
+	if second[0] != a {
+		t.Errorf("neighbor slice overwritten: %v", second[0].Token)
+	}
+
+	if len(first) != 3 {
+		t.Errorf("expected 3 children, got %d", len(first))
+	}
+
+	large := alloc.NewChildren(childSlabSize)
+	if cap(large) != childSlabSize {
+		t.Errorf("expected cap %d, got %d", childSlabSize, cap(large))
+	}
+}
+
+func TestAllocator_NewRoles(t *testing.T) {
+	t.Parallel()
+
+	alloc := &Allocator{}
+
+	if roles := alloc.NewRoles(0); roles != nil {
+		t.Errorf("expected nil roles, got %v", roles)
+	}
+
+	roles := append(alloc.NewRoles(2), RoleFunction, RoleDeclaration)
+	next := append(alloc.NewRoles(1), RoleName)
+
+	if roles[1] != RoleDeclaration || next[0] != RoleName {
+		t.Errorf("unexpected roles: %v %v", roles, next)
+	}
+}
+
+// benchNodeTypes are the node types of the allocation benchmark trees.
+var benchNodeTypes = []Type{"Node_0", "Node_1", "Node_2", "Node_3"}
+
+// buildArenaBenchTree builds the tree of buildBenchTree with the nodes,
+// positions and child lists of alloc, as the DSL parser does.
+func buildArenaBenchTree(alloc *Allocator, branching, depth, index int) *Node {
+	nd := alloc.NewNode("", benchNodeTypes[index%len(benchNodeTypes)], "", nil, alloc.NewPositions(1, 1, 0, 1, 1, 1), nil)
+	nd.Children = alloc.NewChildren(branching)
+
+	if depth > 0 {
+		for idx := range branching {
+			nd.Children = append(nd.Children, buildArenaBenchTree(alloc, branching, depth-1, idx))
+		}
+	}
+
+	return nd
+}
+
+// buildHeapBenchTree builds the same tree with one allocation per node,
+// position and child list.
+func buildHeapBenchTree(branching, depth, index int) *Node {
+	nd := &Node{
+		Type: benchNodeTypes[index%len(benchNodeTypes)],
+		Pos:  &Positions{StartLine: 1, StartCol: 1, EndLine: 1, EndCol: 1, EndOffset: 1},
+	}
+	nd.Children = make([]*Node, 0, branching)
+
+	if depth > 0 {
+		for idx := range branching {
+			nd.Children = append(nd.Children, buildHeapBenchTree(branching, depth-1, idx))
+		}
+	}
+
+	return nd
+}
+
+// BenchmarkTreeAllocation compares the allocations of building a parse tree
+// on the heap and in the arena. History analyzers such as shotness and
+// quality build and drop two such trees per changed file and commit.
+func BenchmarkTreeAllocation(b *testing.B) {
+	b.Run("heap", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for b.Loop() {
+			_ = buildHeapBenchTree(benchTreeBranching, benchTreeDepth, 0)
+		}
+	})
+
+	b.Run("arena", func(b *testing.B) {
+		b.ReportAllocs()
+
+		alloc := &Allocator{}
+
+		for b.Loop() {
+			_ = buildArenaBenchTree(alloc, benchTreeBranching, benchTreeDepth, 0)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"hash"
+	"maps"
 	"slices"
 	"strconv"
 	"strings"
@@ -367,6 +368,57 @@ func ReleaseTree(root *Node) {
 	}
 }
 
+// Clone returns a deep copy of the tree rooted at targetNode, built from the
+// node and position pools, so that the copy and the original can be released
+// with [ReleaseTree] independently. Returns nil if targetNode is nil.
+func (targetNode *Node) Clone() *Node {
+	if targetNode == nil {
+		return nil
+	}
+
+	type clonePair struct {
+		src, dst *Node
+	}
+
+	root := cloneNode(targetNode)
+
+	stack := make([]clonePair, 0, defaultStackCap)
+	stack = append(stack, clonePair{src: targetNode, dst: root})
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if len(current.src.Children) == 0 {
+			continue
+		}
+
+		current.dst.Children = make([]*Node, len(current.src.Children))
+
+		for idx, child := range current.src.Children {
+			current.dst.Children[idx] = cloneNode(child)
+			stack = append(stack, clonePair{src: child, dst: current.dst.Children[idx]})
+		}
+	}
+
+	return root
+}
+
+// cloneNode copies src without its children.
+func cloneNode(src *Node) *Node {
+	dst := NewNodeWithToken(src.Type, src.Token)
+	dst.ID = src.ID
+	dst.Roles = slices.Clone(src.Roles)
+	dst.Props = maps.Clone(src.Props)
+
+	if src.Pos != nil {
+		dst.Pos = NewPositions(src.Pos.StartLine, src.Pos.StartCol, src.Pos.StartOffset,
+			src.Pos.EndLine, src.Pos.EndCol, src.Pos.EndOffset)
+	}
+
+	return dst
+}
+
 // Find returns all nodes in the tree (including root) for which predicate(node) is true.
 // Traversal is pre-order. Returns nil if n is nil.
 func (targetNode *Node) Find(predicate func(*Node) bool) []*Node {
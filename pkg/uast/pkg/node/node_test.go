@@ -1068,6 +1068,45 @@ func TestReleaseTree_NilRoot(t *testing.T) {
 	ReleaseTree(nil)
 }
 
+func TestNode_Clone(t *testing.T) {
+	t.Parallel()
+
+	root := buildBenchTree(3, 2)
+	root.Roles = []Role{RoleFunction}
+	root.Props = map[string]string{"name": "main"}
+	root.Pos = NewPositions(1, 2, 3, 4, 5, 6)
+
+	clone := root.Clone()
+
+	if clone == root || clone.Children[0] == root.Children[0] {
+		t.Fatal("Clone shares nodes with the original")
+	}
+
+	if clone.String() != root.String() {
+		t.Fatalf("Clone = %s, want %s", clone.String(), root.String())
+	}
+
+	if *clone.Pos != *root.Pos {
+		t.Fatalf("Clone position = %+v, want %+v", *clone.Pos, *root.Pos)
+	}
+
+	clone.Props["name"] = "other"
+
+	if root.Props["name"] != "main" {
+		t.Fatal("Clone shares props with the original")
+	}
+
+	ReleaseTree(clone)
+
+	if len(root.Children) != 3 || root.Roles[0] != RoleFunction {
+		t.Fatal("releasing the clone changed the original")
+	}
+
+	if (*Node)(nil).Clone() != nil {
+		t.Fatal("Clone of nil is not nil")
+	}
+}
+
 const (
 	benchTreeBranching = 4
 	benchTreeDepth     = 4 // 4^0 + 4^1 + 4^2 + 4^3 + 4^4 = 1 + 4 + 16 + 64 + 256 = 341 nodes.
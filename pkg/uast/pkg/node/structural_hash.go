@@ -0,0 +1,92 @@
+package node
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"maps"
+	"slices"
+)
+
+// Markers that keep absent positions and children apart from present ones.
+const (
+	hashAbsent  = 0
+	hashPresent = 1
+)
+
+// StructuralHash returns a hash of the subtree rooted at the node. It covers
+// types, tokens, roles, props and the line layout relative to the node's own
+// start line; IDs, columns, offsets and the absolute position are left out,
+// so a declaration that only moves within its file keeps its hash.
+func (targetNode *Node) StructuralHash() uint64 {
+	if targetNode == nil {
+		return 0
+	}
+
+	var baseLine uint
+
+	if targetNode.Pos != nil {
+		baseLine = targetNode.Pos.StartLine
+	}
+
+	hasher := structuralHasher{hash: fnv.New64a()}
+	hasher.writeNode(targetNode, baseLine)
+
+	return hasher.hash.Sum64()
+}
+
+// structuralHasher streams a subtree into a hash. Strings and lists are
+// length-prefixed so that adjacent fields cannot run into each other.
+type structuralHasher struct {
+	hash hash.Hash64
+	buf  [hashBufSize]byte
+}
+
+func (hasher *structuralHasher) writeNode(targetNode *Node, baseLine uint) {
+	if targetNode == nil {
+		hasher.writeUint(hashAbsent)
+
+		return
+	}
+
+	hasher.writeUint(hashPresent)
+	hasher.writeString(string(targetNode.Type))
+	hasher.writeString(targetNode.Token)
+
+	hasher.writeUint(uint64(len(targetNode.Roles)))
+
+	for _, role := range targetNode.Roles {
+		hasher.writeString(string(role))
+	}
+
+	hasher.writeUint(uint64(len(targetNode.Props)))
+
+	for _, key := range slices.Sorted(maps.Keys(targetNode.Props)) {
+		hasher.writeString(key)
+		hasher.writeString(targetNode.Props[key])
+	}
+
+	if targetNode.Pos == nil {
+		hasher.writeUint(hashAbsent)
+	} else {
+		hasher.writeUint(hashPresent)
+		hasher.writeUint(uint64(targetNode.Pos.StartLine) - uint64(baseLine))
+		hasher.writeUint(uint64(targetNode.Pos.EndLine) - uint64(baseLine))
+	}
+
+	hasher.writeUint(uint64(len(targetNode.Children)))
+
+	for _, child := range targetNode.Children {
+		hasher.writeNode(child, baseLine)
+	}
+}
+
+func (hasher *structuralHasher) writeString(value string) {
+	hasher.writeUint(uint64(len(value)))
+	hasher.hash.Write([]byte(value))
+}
+
+func (hasher *structuralHasher) writeUint(value uint64) {
+	binary.LittleEndian.PutUint64(hasher.buf[:], value)
+	hasher.hash.Write(hasher.buf[:])
+}
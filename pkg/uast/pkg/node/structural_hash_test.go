@@ -0,0 +1,72 @@
+package node
+
+import "testing"
+
+// hashTestFunction builds a two-line function starting at line start.
+func hashTestFunction(start uint, body string) *Node {
+	return &Node{
+		ID:    "fn",
+		Type:  UASTFunction,
+		Roles: []Role{RoleFunction, RoleDeclaration},
+		Props: map[string]string{"name": "run", "receiver": "s"},
+		Pos:   &Positions{StartLine: start, EndLine: start + 1, StartOffset: start * 10},
+		Children: []*Node{
+			{Type: UASTIdentifier, Token: "run", Roles: []Role{RoleName}, Pos: &Positions{StartLine: start, EndLine: start}},
+			{Type: UASTCall, Token: body, Pos: &Positions{StartLine: start + 1, EndLine: start + 1, StartCol: 2}},
+		},
+	}
+}
+
+func TestStructuralHash_IgnoresAbsolutePosition(t *testing.T) {
+	t.Parallel()
+
+	moved := hashTestFunction(40, "work()")
+	moved.ID = "other"
+	moved.Children[1].Pos.StartCol = 8
+
+	if hashTestFunction(3, "work()").StructuralHash() != moved.StructuralHash() {
+		t.Error("moving a declaration should keep its hash")
+	}
+}
+
+func TestStructuralHash_DetectsChanges(t *testing.T) {
+	t.Parallel()
+
+	base := hashTestFunction(3, "work()").StructuralHash()
+
+	changes := map[string]func(*Node){
+		"token":     func(n *Node) { n.Children[1].Token = "rest()" },
+		"type":      func(n *Node) { n.Children[1].Type = UASTIdentifier },
+		"role":      func(n *Node) { n.Roles = n.Roles[:1] },
+		"prop":      func(n *Node) { n.Props["receiver"] = "t" },
+		"layout":    func(n *Node) { n.Children[1].Pos.StartLine++ },
+		"child":     func(n *Node) { n.Children = n.Children[:1] },
+		"nil child": func(n *Node) { n.Children[0] = nil },
+		"no pos":    func(n *Node) { n.Children[0].Pos = nil },
+	}
+
+	for name, change := range changes {
+		changed := hashTestFunction(3, "work()")
+		change(changed)
+
+		if changed.StructuralHash() == base {
+			t.Errorf("%s change should alter the hash", name)
+		}
+	}
+}
+
+func TestStructuralHash_FieldBoundaries(t *testing.T) {
+	t.Parallel()
+
+	left := &Node{Type: "ab", Token: "c"}
+	right := &Node{Type: "a", Token: "bc"}
+
+	if left.StructuralHash() == right.StructuralHash() {
+		t.Error("type and token should not run into each other")
+	}
+
+	var nilNode *Node
+	if nilNode.StructuralHash() != 0 {
+		t.Error("nil node should hash to zero")
+	}
+}
@@ -0,0 +1,120 @@
+package uast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Sumatoshi-tech/codefang/pkg/uast/pkg/node"
+)
+
+// ErrRemoteParse is returned when a parse service rejects or fails a request.
+var ErrRemoteParse = errors.New("remote parse failed")
+
+// RemoteParsePath is the parse endpoint of the "uast server" API.
+const RemoteParsePath = "/api/parse"
+
+// remoteParseTimeout bounds one parse request when no client is given.
+const remoteParseTimeout = 30 * time.Second
+
+// RemoteParser parses files through the HTTP API of a "uast server". Every
+// request carries the W3C trace context of its ctx, so the server's spans
+// join the caller's trace.
+type RemoteParser struct {
+	// Endpoint is the base URL of the server, e.g. "http://uast:8080".
+	Endpoint string
+	// Client sends the requests. Nil means a client with a 30s timeout.
+	Client *http.Client
+}
+
+// NewRemoteParser creates a RemoteParser for the server at endpoint.
+func NewRemoteParser(endpoint string) *RemoteParser {
+	return &RemoteParser{
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		Client:   &http.Client{Timeout: remoteParseTimeout},
+	}
+}
+
+// remoteParseRequest is the body of a parse request.
+type remoteParseRequest struct {
+	Code     string `json:"code"`
+	Filename string `json:"filename"`
+}
+
+// remoteParseResponse is the body of a parse response.
+type remoteParseResponse struct {
+	UAST  string `json:"uast"`
+	Error string `json:"error,omitempty"`
+}
+
+// Parse sends content to the parse service and decodes the returned UAST.
+// Node IDs assigned by the server are dropped, matching Parser.Parse.
+func (rp *RemoteParser) Parse(ctx context.Context, filename string, content []byte) (*node.Node, error) {
+	ctx, span := otel.Tracer("codefang.uast").Start(ctx, "codefang.uast.remote_parse",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("uast.filename", filename),
+			attribute.Int("file.size", len(content)),
+		))
+	defer span.End()
+
+	body, err := json.Marshal(remoteParseRequest{Code: string(content), Filename: filename})
+	if err != nil {
+		return nil, fmt.Errorf("encode parse request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rp.Endpoint+RemoteParsePath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create parse request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	client := rp.Client
+	if client == nil {
+		client = &http.Client{Timeout: remoteParseTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrRemoteParse, filename, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: status %d", ErrRemoteParse, filename, resp.StatusCode)
+	}
+
+	var parsed remoteParseResponse
+
+	err = json.NewDecoder(resp.Body).Decode(&parsed)
+	if err != nil {
+		return nil, fmt.Errorf("decode parse response: %w", err)
+	}
+
+	if parsed.Error != "" {
+		return nil, fmt.Errorf("%w: %s: %s", ErrRemoteParse, filename, parsed.Error)
+	}
+
+	var root node.Node
+
+	err = json.Unmarshal([]byte(parsed.UAST), &root)
+	if err != nil {
+		return nil, fmt.Errorf("decode UAST of %s: %w", filename, err)
+	}
+
+	root.VisitPreOrder(func(n *node.Node) { n.ID = "" })
+
+	return &root, nil
+}
@@ -0,0 +1,82 @@
+package uast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestRemoteParser_PropagatesTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var (
+		traceparent string
+		request     remoteParseRequest
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		uast := `{"type": "go:file", "id": "61", "children": [{"type": "go:function", "token": "main", "roles": ["Function"]}]}`
+
+		err = json.NewEncoder(w).Encode(remoteParseResponse{UAST: uast})
+		if err != nil {
+			t.Errorf("encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "codefang.chunk")
+
+	defer span.End()
+
+	root, err := NewRemoteParser(server.URL+"/").Parse(ctx, "cmd/main.go", []byte("package main"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if request.Filename != "cmd/main.go" || request.Code != "package main" {
+		t.Errorf("unexpected request %+v", request)
+	}
+
+	wantPrefix := "00-" + span.SpanContext().TraceID().String() + "-"
+	if len(traceparent) < len(wantPrefix) || traceparent[:len(wantPrefix)] != wantPrefix {
+		t.Errorf("traceparent %q does not carry trace %s", traceparent, span.SpanContext().TraceID())
+	}
+
+	if root.Type != "go:file" || root.ID != "" || len(root.Children) != 1 || root.Children[0].Token != "main" {
+		t.Errorf("unexpected UAST %+v", root)
+	}
+}
+
+func TestRemoteParser_ReportsServiceErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		err := json.NewEncoder(w).Encode(remoteParseResponse{Error: "Parse error: boom"})
+		if err != nil {
+			t.Errorf("encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	_, err := NewRemoteParser(server.URL).Parse(context.Background(), "main.go", nil)
+	if !errors.Is(err, ErrRemoteParse) {
+		t.Errorf("expected ErrRemoteParse, got %v", err)
+	}
+}
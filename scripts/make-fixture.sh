@@ -0,0 +1,159 @@
+#!/bin/bash
+# make-fixture.sh - regenerate testdata/fixture.git, the small repository that
+# `codefang selftest` runs the history analyzers over.
+#
+# Authors, dates and contents are fixed so the fixture (and the golden reports
+# in testdata/golden) only change when this script does. After changing it, run
+# `codefang selftest --update` and review the golden diff.
+
+set -euo pipefail
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+ROOT_DIR="$(dirname "$SCRIPT_DIR")"
+FIXTURE="$ROOT_DIR/testdata/fixture.git"
+
+WORK="$(mktemp -d)"
+trap 'rm -rf "$WORK"' EXIT
+
+export GIT_CONFIG_GLOBAL=/dev/null GIT_CONFIG_SYSTEM=/dev/null
+
+TICK=1700000000
+
+# commit AUTHOR EMAIL MESSAGE: commits the index one day after the previous commit.
+commit() {
+	TICK=$((TICK + 86400))
+	GIT_AUTHOR_NAME="$1" GIT_AUTHOR_EMAIL="$2" GIT_AUTHOR_DATE="@$TICK +0000" \
+		GIT_COMMITTER_NAME="$1" GIT_COMMITTER_EMAIL="$2" GIT_COMMITTER_DATE="@$TICK +0000" \
+		git -C "$WORK" commit -q -m "$3"
+}
+
+git -C "$WORK" init -q -b main
+
+mkdir -p "$WORK/cmd" "$WORK/lib"
+
+cat >"$WORK/cmd/main.go" <<'EOF'
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(greet("world"))
+}
+EOF
+
+cat >"$WORK/cmd/greet.go" <<'EOF'
+package main
+
+func greet(name string) string {
+	return "hello " + name
+}
+EOF
+
+git -C "$WORK" add -A
+commit "Alice" "alice@example.com" "Initial commit"
+
+cat >"$WORK/lib/stats.py" <<'EOF'
+def mean(values):
+    return sum(values) / len(values)
+
+
+def spread(values):
+    return max(values) - min(values)
+EOF
+
+git -C "$WORK" add -A
+commit "Bob" "bob@example.com" "Add stats helpers"
+
+cat >"$WORK/cmd/greet.go" <<'EOF'
+package main
+
+import "strings"
+
+func greet(name string) string {
+	if name == "" {
+		return "hello stranger"
+	}
+
+	return "hello " + strings.TrimSpace(name)
+}
+EOF
+
+git -C "$WORK" add -A
+commit "Alice" "alice@example.com" "Handle empty names, fix awful whitespace bug"
+
+git -C "$WORK" checkout -q -b feature
+
+cat >>"$WORK/lib/stats.py" <<'EOF'
+
+
+def median(values):
+    ordered = sorted(values)
+    middle = len(ordered) // 2
+    if len(ordered) % 2:
+        return ordered[middle]
+    return (ordered[middle - 1] + ordered[middle]) / 2
+EOF
+
+git -C "$WORK" add -A
+commit "Carol" "carol@example.com" "Add median"
+
+git -C "$WORK" checkout -q main
+
+cat >"$WORK/cmd/main.go" <<'EOF'
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	name := "world"
+	if len(os.Args) > 1 {
+		name = os.Args[1]
+	}
+
+	fmt.Println(greet(name))
+}
+EOF
+
+git -C "$WORK" add -A
+commit "Bob" "bob@example.com" "Read the name from the command line"
+
+TICK=$((TICK + 86400))
+GIT_AUTHOR_NAME="Alice" GIT_AUTHOR_EMAIL="alice@example.com" GIT_AUTHOR_DATE="@$TICK +0000" \
+	GIT_COMMITTER_NAME="Alice" GIT_COMMITTER_EMAIL="alice@example.com" GIT_COMMITTER_DATE="@$TICK +0000" \
+	git -C "$WORK" merge -q --no-ff -m "Merge feature: median" feature
+
+git -C "$WORK" mv lib/stats.py lib/statistics.py
+commit "Carol" "carol@example.com" "Rename stats module"
+
+git -C "$WORK" rm -q cmd/greet.go
+
+cat >"$WORK/cmd/main.go" <<'EOF'
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	name := "stranger"
+	if len(os.Args) > 1 {
+		name = strings.TrimSpace(os.Args[1])
+	}
+
+	fmt.Println("hello " + name)
+}
+EOF
+
+git -C "$WORK" add -A
+commit "Alice" "alice@example.com" "Inline greet"
+
+rm -rf "$FIXTURE"
+git clone -q --bare "$WORK" "$FIXTURE"
+git -C "$FIXTURE" remote remove origin
+rm -rf "$FIXTURE/hooks" "$FIXTURE/logs" "$FIXTURE/description" "$FIXTURE/info"
+git -C "$FIXTURE" gc -q --aggressive --prune=now
@@ -11,18 +11,31 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/arch"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/burndown"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/codeowners"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/cohesion"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/comments"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/commitsize"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/complexity"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/couples"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/deadcode"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/defects"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/devs"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/dora"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/effort"
 	filehistory "github.com/Sumatoshi-tech/codefang/pkg/analyzers/file_history"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/halstead"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/imports"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/lifecycle"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/policy"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/reverts"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/sensitive"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/sentiment"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/shotness"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/signing"
 	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/typos"
+	"github.com/Sumatoshi-tech/codefang/pkg/analyzers/workhours"
 )
 
 // Schema represents a JSON Schema.
@@ -50,18 +63,37 @@ func main() {
 	}
 
 	analyzers := map[string]any{
-		"devs":         &devs.ComputedMetrics{},
-		"burndown":     &burndown.ComputedMetrics{},
-		"file_history": &filehistory.ComputedMetrics{},
-		"couples":      &couples.ComputedMetrics{},
-		"shotness":     &shotness.ComputedMetrics{},
-		"sentiment":    &sentiment.ComputedMetrics{},
-		"complexity":   &complexity.ComputedMetrics{},
-		"cohesion":     &cohesion.ComputedMetrics{},
-		"halstead":     &halstead.ComputedMetrics{},
-		"comments":     &comments.ComputedMetrics{},
-		"imports":      &imports.ComputedMetrics{},
-		"typos":        &typos.ComputedMetrics{},
+		"devs":               &devs.ComputedMetrics{},
+		"burndown":           &burndown.ComputedMetrics{},
+		"file_history":       &filehistory.ComputedMetrics{},
+		"couples":            &couples.ComputedMetrics{},
+		"shotness":           &shotness.ComputedMetrics{},
+		"sentiment":          &sentiment.ComputedMetrics{},
+		"complexity":         &complexity.ComputedMetrics{},
+		"complexity_history": &complexity.HistoryMetrics{},
+		"cohesion":           &cohesion.ComputedMetrics{},
+		"cohesion_history":   &cohesion.HistoryMetrics{},
+		"halstead":           &halstead.ComputedMetrics{},
+		"halstead_history":   &halstead.HistoryMetrics{},
+		"comments":           &comments.ComputedMetrics{},
+		"comments_history":   &comments.HistoryMetrics{},
+		"imports":            &imports.ComputedMetrics{},
+		"typos":              &typos.ComputedMetrics{},
+		"workhours":          &workhours.ComputedMetrics{},
+		"lifecycle":          &lifecycle.ComputedMetrics{},
+		"arch":               &arch.ComputedMetrics{},
+		"arch_history":       &arch.HistoryMetrics{},
+		"deadcode":           &deadcode.ComputedMetrics{},
+		"deadcode_history":   &deadcode.HistoryMetrics{},
+		"sensitive":          &sensitive.ComputedMetrics{},
+		"policy":             &policy.ComputedMetrics{},
+		"commitsize":         &commitsize.ComputedMetrics{},
+		"reverts":            &reverts.ComputedMetrics{},
+		"defects":            &defects.ComputedMetrics{},
+		"signing":            &signing.ComputedMetrics{},
+		"dora":               &dora.ComputedMetrics{},
+		"effort":             &effort.ComputedMetrics{},
+		"codeowners":         &codeowners.ComputedMetrics{},
 	}
 
 	for name, metrics := range analyzers {